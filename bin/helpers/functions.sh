@@ -6,6 +6,7 @@ function get_linker_ldflags {
     [ -n "$BUILD_COMMIT" ] && echo -n "-X 'github.com/mysteriumnetwork/node/metadata.BuildCommit=${BUILD_COMMIT}' "
     [ -n "$BUILD_NUMBER" ] && echo -n "-X 'github.com/mysteriumnetwork/node/metadata.BuildNumber=${BUILD_NUMBER}' "
     [ -n "$BUILD_VERSION" ] && echo -n "-X 'github.com/mysteriumnetwork/node/metadata.Version=${BUILD_VERSION}' "
+    [ -n "$RELEASE_PUBLIC_KEY" ] && echo -n "-X 'github.com/mysteriumnetwork/node/metadata.ReleasePublicKey=${RELEASE_PUBLIC_KEY}' "
 }
 
 function copy_config {
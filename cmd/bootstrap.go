@@ -20,18 +20,29 @@
 package cmd
 
 import (
+	"crypto/tls"
 	"net"
+	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/consumer/entertainment"
+	"github.com/mysteriumnetwork/node/core/fleet"
+	"github.com/mysteriumnetwork/node/core/management"
 	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/summary"
+	"github.com/mysteriumnetwork/node/metrics"
 	"github.com/mysteriumnetwork/node/services"
 	"github.com/mysteriumnetwork/node/tequilapi"
 	tequilapi_endpoints "github.com/mysteriumnetwork/node/tequilapi/endpoints"
+	"github.com/mysteriumnetwork/node/tequilapi/tlsconfig"
 	"github.com/mysteriumnetwork/node/ui"
 	uinoop "github.com/mysteriumnetwork/node/ui/noop"
 	"github.com/mysteriumnetwork/node/ui/versionmanager"
@@ -43,6 +54,21 @@ func (di *Dependencies) bootstrapTequilapi(nodeOptions node.Options, listener ne
 		return tequilapi.NewNoopAPIServer(), nil
 	}
 
+	// Socket-only listener is created early, TLS and extra socket apply to TCP listener only.
+	if !nodeOptions.TequilapiSocket.TCPDisabled() {
+		if di.tlsConfig != nil {
+			listener = tls.NewListener(listener, di.tlsConfig)
+		}
+
+		if nodeOptions.TequilapiSocket.Enabled() {
+			socket, err := tequilapi.NewUnixListener(nodeOptions.TequilapiSocket.Path, nodeOptions.TequilapiSocket.Mode)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not listen on tequilapi socket")
+			}
+			listener = tequilapi.NewMultiListener(listener, socket)
+		}
+	}
+
 	return tequilapi.NewServer(
 		listener,
 		nodeOptions,
@@ -55,36 +81,86 @@ func (di *Dependencies) bootstrapTequilapi(nodeOptions node.Options, listener ne
 			},
 			func(e *gin.Engine) error {
 				if config.GetBool(config.FlagPProfEnable) {
-					tequilapi_endpoints.AddRoutesForPProf(e)
+					tequilapi_endpoints.AddRoutesForPProf(e, di.JWTAuthenticator, config.GetString(config.FlagPProfToken))
 				}
 				return nil
 			},
 			func(e *gin.Engine) error {
-				e.GET("/healthcheck", tequilapi_endpoints.HealthCheckEndpointFactory(time.Now, os.Getpid).HealthCheck)
+				healthCheck := tequilapi_endpoints.HealthCheckEndpointFactory(time.Now, os.Getpid).HealthCheck
+				e.GET("/healthcheck", healthCheck)
+				e.GET("/healthz", healthCheck)
 				return nil
 			},
 			tequilapi_endpoints.AddRouteForStop(utils.SoftKiller(di.Shutdown)),
 			tequilapi_endpoints.AddRoutesForAuthentication(di.Authenticator, di.JWTAuthenticator),
 			tequilapi_endpoints.AddRoutesForIdentities(di.IdentityManager, di.IdentitySelector, di.IdentityRegistry, di.ConsumerBalanceTracker, di.AddressProvider, di.HermesChannelRepository, di.BCHelper, di.Transactor, di.BeneficiaryProvider, di.IdentityMover, di.PayoutAddressStorage, di.HermesMigrator),
-			tequilapi_endpoints.AddRoutesForConnection(di.MultiConnectionManager, di.StateKeeper, di.ProposalRepository, di.IdentityRegistry, di.EventBus, di.AddressProvider),
+			tequilapi_endpoints.AddRoutesForConnection(di.MultiConnectionManager, di.StateKeeper, di.ProposalRepository, di.IdentityRegistry, di.EventBus, di.AddressProvider, di.MeteredGuard),
 			tequilapi_endpoints.AddRoutesForSessions(di.SessionStorage),
+			tequilapi_endpoints.AddRoutesForConnectionHistory(di.SessionStorage),
+			tequilapi_endpoints.AddRoutesForSessionReceipts(di.ReceiptKeeper),
+			tequilapi_endpoints.AddRoutesForPayments(di.PromiseRecovery, di.DisputeKeeper),
+			tequilapi_endpoints.AddRoutesForPriceBook(di.PriceBookKeeper),
+			tequilapi_endpoints.AddRoutesForConnectionTrace(di.ConnectionTransitions),
+			tequilapi_endpoints.AddRoutesForConnectionEstimate(di.ProposalRepository, di.AddressProvider, di.HermesPromiseSettler),
+			tequilapi_endpoints.AddRoutesForChains(di.ChainSwitcher, di.ConsumerBalanceTracker),
+			tequilapi_endpoints.AddRoutesForManagement(di.ManagementAgent, di.ManagementRemote),
 			tequilapi_endpoints.AddRoutesForConnectionLocation(di.IPResolver, di.LocationResolver, di.LocationResolver),
 			tequilapi_endpoints.AddRoutesForProposals(di.ProposalRepository, di.PricingHelper, di.LocationResolver, di.FilterPresetStorage, di.NATProber),
-			tequilapi_endpoints.AddRoutesForService(di.ServicesManager, services.JSONParsersByType, di.ProposalRepository),
+			tequilapi_endpoints.AddRoutesForService(di.ServicesManager, services.JSONParsersByType, di.ProposalRepository, di.TermsKeeper),
 			tequilapi_endpoints.AddRoutesForAccessPolicies(di.HTTPClient, config.GetString(config.FlagAccessPolicyAddress)),
 			tequilapi_endpoints.AddRoutesForNAT(di.StateKeeper, di.NATProber),
 			tequilapi_endpoints.AddRoutesForNodeUI(versionmanager.NewVersionManager(di.UIServer, di.HTTPClient, di.uiVersionConfig)),
-			tequilapi_endpoints.AddRoutesForNode(di.NodeStatusTracker, di.NodeStatsTracker),
-			tequilapi_endpoints.AddRoutesForTransactor(di.IdentityRegistry, di.Transactor, di.Affiliator, di.HermesPromiseSettler, di.SettlementHistoryStorage, di.AddressProvider, di.BeneficiaryProvider, di.BeneficiarySaver, di.PilvytisAPI),
+			tequilapi_endpoints.AddRoutesForNode(di.NodeStatusTracker, di.NodeStatsTracker, di.ReputationTracker, di.UptimeTracker),
+			tequilapi_endpoints.AddRoutesForUplinkUsage(di.UplinkUsageTracker),
+			tequilapi_endpoints.AddRoutesForServiceSchedule(di.ServiceScheduler),
+			tequilapi_endpoints.AddRoutesForNodeSummary(di.newNodeSummary()),
+			tequilapi_endpoints.AddRoutesForAlerting(di.AlertingEngine),
+			tequilapi_endpoints.AddRoutesForNotifications(di.Notifier),
+			tequilapi_endpoints.AddRoutesForStorage(di.StorageRetention),
+			tequilapi_endpoints.AddRoutesForMetrics(metrics.DefaultRegistry),
+			func(e *gin.Engine) error {
+				if di.Preflight == nil {
+					return nil
+				}
+				return tequilapi_endpoints.AddRoutesForPreflight(di.Preflight)(e)
+			},
+			func(e *gin.Engine) error {
+				if di.DDNSUpdater == nil {
+					return nil
+				}
+				return tequilapi_endpoints.AddRoutesForDDNS(di.DDNSUpdater)(e)
+			},
+			func(e *gin.Engine) error {
+				if di.Blocklist == nil {
+					return nil
+				}
+				return tequilapi_endpoints.AddRoutesForBlocklist(di.Blocklist)(e)
+			},
+			func(e *gin.Engine) error {
+				if di.ServicesManager == nil {
+					return nil
+				}
+				return tequilapi_endpoints.AddRoutesForFleet(di.newFleetOperator(), di.JWTAuthenticator)(e)
+			},
+			func(e *gin.Engine) error {
+				if di.ServicePauser == nil {
+					return nil
+				}
+				return tequilapi_endpoints.AddRoutesForProviderPause(di.ServicePauser)(e)
+			},
+			tequilapi_endpoints.AddRoutesForTransactor(di.IdentityRegistry, di.Transactor, di.Affiliator, di.HermesPromiseSettler, di.SettlementHistoryStorage, di.AddressProvider, di.BeneficiaryProvider, di.BeneficiarySaver, di.PilvytisAPI, di.GasPriceProvider),
 			tequilapi_endpoints.AddRoutesForAffiliator(di.Affiliator),
 			tequilapi_endpoints.AddRoutesForConfig,
 			tequilapi_endpoints.AddRoutesForMMN(di.MMN),
 			tequilapi_endpoints.AddRoutesForFeedback(di.Reporter),
+			tequilapi_endpoints.AddRoutesForDiagnostics(di.DiagnosticsBundler),
+			tequilapi_endpoints.AddRoutesForUpdate(di.Updater),
+			tequilapi_endpoints.AddRoutesForFeatures(di.FeatureFlags),
 			tequilapi_endpoints.AddRoutesForConnectivityStatus(di.SessionConnectivityStatusStorage),
 			tequilapi_endpoints.AddRoutesForDocs,
 			tequilapi_endpoints.AddRoutesForCurrencyExchange(di.PilvytisAPI),
-			tequilapi_endpoints.AddRoutesForPilvytis(di.PilvytisAPI, di.PilvytisOrderIssuer, di.LocationResolver),
-			tequilapi_endpoints.AddRoutesForTerms,
+			tequilapi_endpoints.AddRoutesForPilvytis(di.PaymentGateways, di.PilvytisOrderIssuer, di.LocationResolver),
+			tequilapi_endpoints.AddRoutesForTerms(di.TermsKeeper),
 			tequilapi_endpoints.AddEntertainmentRoutes(entertainment.NewEstimator(
 				config.FlagPaymentPriceGiB.Value,
 				config.FlagPaymentPriceHour.Value,
@@ -109,12 +185,53 @@ func (di *Dependencies) bootstrapNodeUIVersionConfig(nodeOptions node.Options) e
 	return nil
 }
 
+func (di *Dependencies) newFleetOperator() *fleet.Operator {
+	startOptions := func(serviceType string) ([]string, service.Options, error) {
+		opts, err := services.GetStartOptions(serviceType)
+		return opts.AccessPolicyList, opts.TypeOptions, err
+	}
+	return fleet.NewOperator(
+		config.Current,
+		di.ServicesManager,
+		startOptions,
+		di.Storage,
+		di.JWTAuthenticator,
+		config.GetString(config.FlagTequilapiUsername),
+		di.ServiceSessions,
+		di.NodeStatusTracker,
+	)
+}
+
+func (di *Dependencies) newNodeSummary() *summary.Builder {
+	deps := summary.Deps{
+		Identities:   di.IdentityManager,
+		Registry:     di.IdentityRegistry,
+		Balances:     di.ConsumerBalanceTracker,
+		Earnings:     di.HermesChannelRepository,
+		Connections:  di.MultiConnectionManager,
+		SessionStats: di.SessionStorage,
+		Monitoring:   di.NodeStatusTracker,
+		Alerts:       di.AlertingEngine,
+	}
+	if di.ServicesManager != nil {
+		deps.Services = di.ServicesManager
+		deps.Sessions = di.ServiceSessions
+	}
+	return summary.NewBuilder(deps)
+}
+
 func (di *Dependencies) bootstrapUIServer(options node.Options) (err error) {
 	if !options.UI.UIEnabled {
 		di.UIServer = uinoop.NewServer()
 		return nil
 	}
 
+	if options.TequilapiSocket.TCPDisabled() {
+		log.Warn().Msg("UI is disabled, it requires tequilapi to be served over TCP")
+		di.UIServer = uinoop.NewServer()
+		return nil
+	}
+
 	bindAddress := options.UI.UIBindAddress
 	if bindAddress == "" {
 		bindAddress, err = di.IPResolver.GetOutboundIP()
@@ -123,6 +240,55 @@ func (di *Dependencies) bootstrapUIServer(options node.Options) (err error) {
 		}
 		bindAddress = bindAddress + ",127.0.0.1"
 	}
-	di.UIServer = ui.NewServer(bindAddress, options.UI.UIPort, options.TequilapiAddress, options.TequilapiPort, di.JWTAuthenticator, di.HTTPClient, di.uiVersionConfig)
+	di.UIServer = ui.NewServer(bindAddress, options.UI.UIPort, options.TequilapiAddress, options.TequilapiPort, di.JWTAuthenticator, di.HTTPClient, di.uiVersionConfig, di.tlsConfig)
 	return nil
 }
+
+func (di *Dependencies) bootstrapTLS(options node.Options) (err error) {
+	if !options.TequilapiEnabled || !options.TequilapiTLS.Enabled {
+		return nil
+	}
+
+	hosts := []string{options.TequilapiAddress}
+	var currentHosts []string
+	if options.UI.UIBindAddress != "" {
+		hosts = append(hosts, strings.Split(options.UI.UIBindAddress, ",")...)
+	} else if outboundIP, err := di.IPResolver.GetOutboundIP(); err == nil {
+		currentHosts = append(currentHosts, outboundIP)
+	}
+
+	var challenges http.Handler
+	di.tlsConfig, challenges, err = tlsconfig.New(tlsconfig.Options{
+		Dir:          tlsconfig.Dir(options.Directories.Data),
+		Hosts:        hosts,
+		CurrentHosts: currentHosts,
+		Domain:       options.TequilapiTLS.Domain,
+		ACMEEmail:    options.TequilapiTLS.ACMEEmail,
+	})
+	if err != nil || challenges == nil {
+		return err
+	}
+
+	di.acmeServer = &http.Server{Addr: options.TequilapiTLS.ACMEHTTPAddress, Handler: challenges}
+	go func() {
+		log.Info().Msgf("ACME challenges starting on: %s", di.acmeServer.Addr)
+		if err := di.acmeServer.ListenAndServe(); err != http.ErrServerClosed {
+			log.Err(err).Msg("ACME challenge server crashed")
+		}
+	}()
+	return nil
+}
+
+func (di *Dependencies) bootstrapManagement(options node.Options) error {
+	di.ManagementRemote = management.NewRemote(di.P2PDialer, di.Storage, di.NetworkDefinition.BrokerAddresses)
+	if !options.TequilapiEnabled {
+		return nil
+	}
+
+	forwarder := management.NewHTTPForwarder(options.TequilapiAddress, options.TequilapiPort, di.tlsConfig != nil)
+	if options.TequilapiSocket.Enabled() {
+		forwarder = management.NewUnixHTTPForwarder(options.TequilapiSocket.Path)
+	}
+	di.ManagementAgent = management.NewAgent(di.P2PListener, di.Storage, forwarder, 10*time.Minute)
+	return di.ManagementAgent.Subscribe(di.EventBus)
+}
@@ -37,6 +37,14 @@ func (di *Dependencies) bootstrapUIServer(_ node.Options) (err error) {
 	return nil
 }
 
+func (di *Dependencies) bootstrapTLS(_ node.Options) error {
+	return nil
+}
+
+func (di *Dependencies) bootstrapManagement(_ node.Options) error {
+	return nil
+}
+
 func (di *Dependencies) bootstrapNodeUIVersionConfig(_ node.Options) error {
 	noopCfg, _ := versionmanager.NewNoOpVersionConfig()
 	di.uiVersionConfig = noopCfg
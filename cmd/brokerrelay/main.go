@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2021 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/communication/nats"
+	"github.com/mysteriumnetwork/node/communication/relay"
+	"github.com/mysteriumnetwork/node/metadata"
+)
+
+const addressListSeparator = ","
+
+var (
+	listenAddress = flag.String("listen", ":8443", "address the relay listens on")
+	brokerList    = flag.String("broker", strings.Join(metadata.MainnetDefinition.BrokerAddresses, addressListSeparator), "comma-separated list of NATS broker addresses")
+	tlsCert       = flag.String("tls-cert", "", "TLS certificate file, relay serves plain HTTP when empty")
+	tlsKey        = flag.String("tls-key", "", "TLS private key file")
+)
+
+func run() int {
+	flag.Parse()
+
+	var addresses []string
+	for _, address := range strings.Split(*brokerList, addressListSeparator) {
+		address = strings.TrimSpace(address)
+		if address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+
+	brokerURLs, err := nats.ParseServerURIs(addresses)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+
+	conn, err := nats.NewBrokerConnector((&net.Dialer{}).DialContext, nil, nil).Connect(brokerURLs...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	defer conn.Close()
+
+	upstream, ok := conn.(relay.Upstream)
+	if !ok {
+		fmt.Fprintln(os.Stderr, "error: broker connection can not be bridged")
+		return 1
+	}
+
+	server := &http.Server{Addr: *listenAddress, Handler: relay.NewBridgeServer(upstream)}
+	if *tlsCert != "" {
+		err = server.ListenAndServeTLS(*tlsCert, *tlsKey)
+	} else {
+		err = server.ListenAndServe()
+	}
+	fmt.Fprintln(os.Stderr, "error:", err)
+	return 1
+}
+
+func main() {
+	os.Exit(run())
+}
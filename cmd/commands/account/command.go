@@ -68,7 +68,7 @@ var (
 
 	flagToken = cli.StringFlag{
 		Name:  "token",
-		Usage: "Either a referral or affiliate token which can be used when registering",
+		Usage: "Either a referral or affiliate token which can be used when registering or topping up",
 	}
 )
 
@@ -80,7 +80,7 @@ func NewCommand() *cli.Command {
 		Name:        CommandName,
 		Usage:       "Manage your account",
 		Description: "Using account subcommands you can manage your account details and get information about it",
-		Flags:       []cli.Flag{&config.FlagTequilapiAddress, &config.FlagTequilapiPort},
+		Flags:       []cli.Flag{&config.FlagTequilapiAddress, &config.FlagTequilapiPort, &config.FlagTequilapiSocket, &config.FlagRemoteHost, &config.FlagRemoteToken, &config.FlagRemoteCACert},
 		Before: func(ctx *cli.Context) error {
 			tc, err := clio.NewTequilApiClient(ctx)
 			if err != nil {
@@ -111,7 +111,7 @@ func NewCommand() *cli.Command {
 			{
 				Name:  "topup",
 				Usage: "Create a new top-up for your account",
-				Flags: []cli.Flag{&flagAmount, &flagCurrency, &flagGateway, &flagGwData},
+				Flags: []cli.Flag{&flagAmount, &flagCurrency, &flagGateway, &flagGwData, &flagToken},
 				Action: func(ctx *cli.Context) error {
 					cmd.topup(ctx)
 					return nil
@@ -262,9 +262,10 @@ func (c *command) topup(ctx *cli.Context) {
 	}
 
 	resp, err := c.tequilapi.OrderCreate(identity.FromAddress(id.Address), gatewayName, contract.PaymentOrderRequest{
-		MystAmount:  amount,
-		PayCurrency: currency,
-		CallerData:  callerData,
+		MystAmount:    amount,
+		PayCurrency:   currency,
+		ReferralToken: ctx.String(flagToken.Name),
+		CallerData:    callerData,
 	})
 	if err != nil {
 		clio.Error("Failed to create a top-up request, make sure your requested amount is equal or more than 0.0001 BTC")
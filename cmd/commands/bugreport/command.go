@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bugreport
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/mysteriumnetwork/node/cmd/commands/cli/clio"
+	"github.com/mysteriumnetwork/node/config"
+)
+
+// CommandName is the name of the bug report command.
+const CommandName = "bug-report"
+
+const consentNotice = `The diagnostics bundle contains recent logs, node configuration (with secrets redacted),
+NAT type, route table, firewall rules, active sessions and recent errors.
+Review the archive before sharing it with support.`
+
+var flagYes = cli.BoolFlag{
+	Name:  "yes",
+	Usage: "Agree to collect diagnostics without asking",
+}
+
+// NewCommand creates bug report command.
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:      CommandName,
+		Usage:     "Collect a diagnostics bundle for support",
+		ArgsUsage: " ",
+		Flags:     []cli.Flag{&config.FlagTequilapiAddress, &config.FlagTequilapiPort, &config.FlagTequilapiSocket, &flagYes},
+		Action: func(ctx *cli.Context) error {
+			fmt.Fprintln(ctx.App.Writer, consentNotice)
+			if !ctx.Bool(flagYes.Name) && !confirm(ctx) {
+				clio.Warn("Diagnostics collection cancelled")
+				return nil
+			}
+
+			tc, err := clio.NewTequilApiClient(ctx)
+			if err != nil {
+				return err
+			}
+
+			res, err := tc.DiagnosticsBundle()
+			if err != nil {
+				clio.Error("Failed to create diagnostics bundle:", err)
+				return err
+			}
+
+			clio.Success("Diagnostics bundle created:", res.Path)
+			return nil
+		},
+	}
+}
+
+func confirm(ctx *cli.Context) bool {
+	fmt.Fprint(ctx.App.Writer, "Do you agree to collect diagnostics? [y/N]: ")
+	answer, _ := bufio.NewReader(ctx.App.Reader).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
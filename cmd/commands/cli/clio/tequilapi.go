@@ -18,21 +18,62 @@
 package clio
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/mysteriumnetwork/node/config"
 	tequilapi_client "github.com/mysteriumnetwork/node/tequilapi/client"
+	"github.com/mysteriumnetwork/node/tequilapi/tlsconfig"
 
 	"github.com/urfave/cli/v2"
 )
 
 // NewTequilApiClient - initializes and returns a pointer to tequilapi client - also fetches config using it
 func NewTequilApiClient(ctx *cli.Context) (*tequilapi_client.Client, error) {
+	if host := ctx.String(config.FlagRemoteHost.Name); host != "" {
+		baseURL, err := RemoteBaseURL(host)
+		if err != nil {
+			Error(fmt.Sprintf("invalid remote host: %s", host))
+			return nil, err
+		}
+		tlsConfig, err := remoteTLSConfig(ctx.String(config.FlagRemoteCACert.Name))
+		if err != nil {
+			Error("failed to configure TLS for remote node API")
+			return nil, err
+		}
+		client := tequilapi_client.NewRemoteClient(baseURL, ctx.String(config.FlagRemoteToken.Name), tlsConfig)
+		if _, err := client.Healthcheck(); err != nil {
+			Error(fmt.Sprintf("failed to connect to remote node via url: %s", baseURL))
+			return nil, err
+		}
+		return client, nil
+	}
+
+	if socket := TequilAPISocket(ctx); socket != "" {
+		client := tequilapi_client.NewUnixClient(socket)
+		if _, err := client.Healthcheck(); err != nil {
+			Error(fmt.Sprintf("failed to connect to node via socket: %s", socket))
+			return nil, err
+		}
+		return client, nil
+	}
+
 	address := TequilAPIAddress(ctx)
 	port := TequilAPIPort(ctx)
-	client := tequilapi_client.NewClient(address, port)
+	client, err := newTequilapiClient(ctx, address, port)
+	if err != nil {
+		Error("failed to configure TLS for node API")
+		return nil, err
+	}
 
-	_, err := client.Healthcheck()
+	_, err = client.Healthcheck()
 	if err != nil {
 		Error(fmt.Sprintf("failed to connect to node via url: %s:%d", address, port))
 		return nil, err
@@ -40,6 +81,22 @@ func NewTequilApiClient(ctx *cli.Context) (*tequilapi_client.Client, error) {
 	return client, nil
 }
 
+func newTequilapiClient(ctx *cli.Context, address string, port int) (*tequilapi_client.Client, error) {
+	if !ctx.Bool(config.FlagTequilapiTLS.Name) {
+		return tequilapi_client.NewClient(address, port), nil
+	}
+
+	dataDir := config.FlagDataDir.Value
+	if ctx.IsSet(config.FlagDataDir.Name) {
+		dataDir = ctx.String(config.FlagDataDir.Name)
+	}
+	tlsConfig, err := tlsconfig.ClientConfig(tlsconfig.Dir(dataDir), ctx.String(config.FlagTequilapiTLSDomain.Name))
+	if err != nil {
+		return nil, err
+	}
+	return tequilapi_client.NewTLSClient(address, port, tlsConfig), nil
+}
+
 // TequilAPIAddress - wil resolve default tequilapi address or from flag if one is provided
 func TequilAPIAddress(ctx *cli.Context) string {
 	flag := config.FlagTequilapiAddress
@@ -61,3 +118,66 @@ func TequilAPIPort(ctx *cli.Context) int {
 
 	return flag.Value
 }
+
+// TequilAPISocket - will resolve tequilapi unix domain socket path from flag, empty if not provided
+func TequilAPISocket(ctx *cli.Context) string {
+	return ctx.String(config.FlagTequilapiSocket.Name)
+}
+
+// remoteAPIPath is the prefix under which UI server proxies authenticated tequilapi requests.
+const remoteAPIPath = "/tequilapi"
+
+// RemoteBaseURL - resolves API base URL of a remote node from host, which may omit scheme, port and path.
+// Remote node is reached through the API proxy of its UI server, which validates auth tokens.
+// Plain http is allowed for loopback hosts only, as the token would be sent in the clear.
+func RemoteBaseURL(host string) (string, error) {
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported scheme: %s", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return "", errors.New("host is missing")
+	}
+	if u.Scheme == "http" && !isLoopback(u.Hostname()) {
+		return "", errors.New("plain http is allowed for loopback hosts only, use https")
+	}
+	if u.Port() == "" {
+		u.Host = net.JoinHostPort(u.Hostname(), strconv.Itoa(config.FlagUIPort.Value))
+	}
+	if strings.Trim(u.Path, "/") == "" {
+		u.Path = remoteAPIPath
+	}
+	return strings.TrimSuffix(u.String(), "/"), nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// remoteTLSConfig trusts the given PEM certificate for the remote node, or system roots if path is empty.
+func remoteTLSConfig(caCertPath string) (*tls.Config, error) {
+	if caCertPath == "" {
+		return nil, nil
+	}
+
+	certPEM, err := os.ReadFile(caCertPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read remote node certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(certPEM) {
+		return nil, errors.New("could not parse remote node certificate")
+	}
+	return &tls.Config{RootCAs: pool}, nil
+}
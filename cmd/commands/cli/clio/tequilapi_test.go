@@ -0,0 +1,45 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package clio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteBaseURL(t *testing.T) {
+	for host, want := range map[string]string{
+		"10.0.0.5":                         "https://10.0.0.5:4449/tequilapi",
+		"10.0.0.5:4000":                    "https://10.0.0.5:4000/tequilapi",
+		"[::1]":                            "https://[::1]:4449/tequilapi",
+		"https://node.example.com/":        "https://node.example.com:4449/tequilapi",
+		"https://node.example.com:443/api": "https://node.example.com:443/api",
+		"http://127.0.0.1":                 "http://127.0.0.1:4449/tequilapi",
+		"http://localhost:4449/tequilapi/": "http://localhost:4449/tequilapi",
+	} {
+		got, err := RemoteBaseURL(host)
+		assert.NoError(t, err, host)
+		assert.Equal(t, want, got, host)
+	}
+
+	for _, host := range []string{"ftp://node.example.com", "https://", "http://10.0.0.5", "http://node.example.com"} {
+		_, err := RemoteBaseURL(host)
+		assert.Error(t, err, host)
+	}
+}
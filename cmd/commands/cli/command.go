@@ -40,6 +40,7 @@ import (
 	"github.com/mysteriumnetwork/node/config/remote"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/tos"
 	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/mysteriumnetwork/node/money"
@@ -59,6 +60,8 @@ const serviceHelp = `service <action> [args]
 	status	<ServiceID>
 	list
 	sessions
+	pause	[--close-sessions]
+	resume
 
 	example: service start 0x7d5ee3557775aed0b85d691b036769c17349db23 openvpn --openvpn.port=1194 --openvpn.proto=UDP`
 
@@ -67,7 +70,7 @@ func NewCommand() *cli.Command {
 	return &cli.Command{
 		Name:  CommandName,
 		Usage: "Starts a CLI client with a Tequilapi",
-		Flags: []cli.Flag{&config.FlagAgreedTermsConditions, &config.FlagTequilapiAddress, &config.FlagTequilapiPort},
+		Flags: []cli.Flag{&config.FlagAgreedTermsConditions, &config.FlagTequilapiAddress, &config.FlagTequilapiPort, &config.FlagTequilapiSocket, &config.FlagRemoteHost, &config.FlagRemoteToken, &config.FlagRemoteCACert},
 		Action: func(ctx *cli.Context) error {
 			client, err := clio.NewTequilApiClient(ctx)
 			if err != nil {
@@ -149,9 +152,10 @@ func (c *cliApp) handleTOS(ctx *cli.Context) error {
 		return errTermsNotAgreed
 	}
 
-	version := c.config.GetString(contract.TermsVersion)
-	if version != terms.TermsVersion {
-		return fmt.Errorf("you've agreed to terms of use version %s, but version %s is required", version, terms.TermsVersion)
+	for _, key := range []string{contract.TermsConsumerVersion, contract.TermsProviderVersion} {
+		if version := tos.AgreedVersion(c.config, key); version != terms.TermsVersion {
+			return fmt.Errorf("you've agreed to terms of use version %s, but version %s is required", version, terms.TermsVersion)
+		}
 	}
 
 	return nil
@@ -635,6 +639,8 @@ func newAutocompleter(tequilapi *tequilapi_client.Client, proposals []contract.P
 			readline.PcItem("list"),
 			readline.PcItem("status"),
 			readline.PcItem("sessions"),
+			readline.PcItem("pause", readline.PcItem("--close-sessions")),
+			readline.PcItem("resume"),
 		),
 		readline.PcItem(
 			"identities",
@@ -692,6 +698,7 @@ func parseStartFlags(serviceType string, args ...string) (services.StartOptions,
 	config.RegisterFlagsServiceOpenvpn(&flags)
 	config.RegisterFlagsServiceWireguard(&flags)
 	config.RegisterFlagsServiceNoop(&flags)
+	config.RegisterFlagsServiceSpeedtest(&flags)
 
 	set := flag.NewFlagSet("", flag.ContinueOnError)
 	for _, f := range flags {
@@ -706,6 +713,7 @@ func parseStartFlags(serviceType string, args ...string) (services.StartOptions,
 	config.ParseFlagsServiceOpenvpn(ctx)
 	config.ParseFlagsServiceWireguard(ctx)
 	config.ParseFlagsServiceNoop(ctx)
+	config.ParseFlagsServiceSpeedtest(ctx)
 
 	return services.GetStartOptions(serviceType)
 }
@@ -56,6 +56,18 @@ func (c *cliApp) service(args []string) (err error) {
 		return c.serviceList()
 	case "sessions":
 		return c.serviceSessions()
+	case "pause":
+		keepSessions := true
+		for _, arg := range args[1:] {
+			if arg != "--close-sessions" {
+				fmt.Println(serviceHelp)
+				return errUnknownArgument
+			}
+			keepSessions = false
+		}
+		return c.servicePause(keepSessions)
+	case "resume":
+		return c.serviceResume()
 	default:
 		fmt.Println(serviceHelp)
 		return errUnknownSubCommand(args[0])
@@ -94,6 +106,25 @@ func (c *cliApp) serviceStop(id string) (err error) {
 	return nil
 }
 
+func (c *cliApp) servicePause(keepSessions bool) (err error) {
+	status, err := c.tequilapi.ProviderPause(keepSessions)
+	if err != nil {
+		return fmt.Errorf("failed to pause services: %w", err)
+	}
+
+	clio.Success(fmt.Sprintf("Paused %d services, active sessions kept: %t", len(status.Services), status.KeepSessions))
+	return nil
+}
+
+func (c *cliApp) serviceResume() (err error) {
+	if _, err := c.tequilapi.ProviderResume(); err != nil {
+		return fmt.Errorf("failed to resume services: %w", err)
+	}
+
+	clio.Success("Services resumed")
+	return nil
+}
+
 func (c *cliApp) serviceList() (err error) {
 	services, err := c.tequilapi.Services()
 	if err != nil {
@@ -18,7 +18,9 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 
@@ -26,29 +28,49 @@ import (
 
 	"github.com/mysteriumnetwork/node/cmd/commands/cli/clio"
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/config/urfavecli/clicontext"
 	"github.com/mysteriumnetwork/node/tequilapi/client"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 )
 
 // CommandName is the name which is used to call this command
 const CommandName = "config"
 
+// Exit codes of the validate subcommand.
+const (
+	exitCodeInvalid    = 1
+	exitCodeLoadFailed = 2
+)
+
+var (
+	flagValidateFile = cli.StringFlag{
+		Name:  "file",
+		Usage: "Path of the config file to validate, defaults to the config file in the config directory",
+	}
+	flagValidateJSON = cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print validation report as JSON",
+	}
+)
+
 // NewCommand function creates license command.
 func NewCommand() *cli.Command {
 	cmd := &command{}
+	connect := func(ctx *cli.Context) error {
+		var err error
+		cmd.tc, err = clio.NewTequilApiClient(ctx)
+		return err
+	}
 	return &cli.Command{
 		Name:        CommandName,
 		Usage:       "Manage your node config",
 		Description: "Using config subcommands you can view and manage your current node config",
-		Flags:       []cli.Flag{&config.FlagTequilapiAddress, &config.FlagTequilapiPort},
-		Before: func(ctx *cli.Context) error {
-			var err error
-			cmd.tc, err = clio.NewTequilApiClient(ctx)
-			return err
-		},
+		Flags:       []cli.Flag{&config.FlagTequilapiAddress, &config.FlagTequilapiPort, &config.FlagTequilapiSocket, &config.FlagRemoteHost, &config.FlagRemoteToken, &config.FlagRemoteCACert},
 		Subcommands: []*cli.Command{
 			{
-				Name:  "show",
-				Usage: "Show current node config",
+				Name:   "show",
+				Usage:  "Show current node config",
+				Before: connect,
 				Action: func(ctx *cli.Context) error {
 					cmd.show()
 					return nil
@@ -57,8 +79,17 @@ func NewCommand() *cli.Command {
 			{
 				Name:   "set",
 				Usage:  "Set node config value",
+				Before: connect,
 				Action: cmd.set,
 			},
+			{
+				Name:  "validate",
+				Usage: "Validate node config file without starting the node",
+				Description: "Checks option types and ranges, conflicting, unknown and deprecated options. " +
+					"Exits with code 1 if the config has errors and code 2 if it cannot be loaded",
+				Flags:  []cli.Flag{&flagValidateFile, &flagValidateJSON},
+				Action: validate,
+			},
 		},
 	}
 }
@@ -102,6 +133,83 @@ func (c *command) set(ctx *cli.Context) error {
 	return nil
 }
 
+func validate(ctx *cli.Context) error {
+	path := ctx.String(flagValidateFile.Name)
+	if path == "" {
+		path = clicontext.UserConfigLocation(ctx)
+	}
+
+	flags, err := knownFlags()
+	if err != nil {
+		return err
+	}
+
+	report, err := config.ValidateUserConfigFile(path, flags, contract.TermsConsumerAgreed, contract.TermsProviderAgreed, contract.TermsVersion, contract.TermsConsumerVersion, contract.TermsProviderVersion, contract.TermsConsumerAgreedAt, contract.TermsProviderAgreedAt)
+	if err != nil {
+		if ctx.Bool(flagValidateJSON.Name) {
+			printJSON(struct {
+				File  string `json:"file"`
+				Valid bool   `json:"valid"`
+				Error string `json:"error"`
+			}{File: path, Error: err.Error()})
+		} else {
+			clio.Error(fmt.Sprintf("Failed to load %s: %v", path, err))
+		}
+		return cli.Exit("", exitCodeLoadFailed)
+	}
+
+	if ctx.Bool(flagValidateJSON.Name) {
+		printJSON(report)
+	} else {
+		printReport(report)
+	}
+
+	if !report.Valid {
+		return cli.Exit("", exitCodeInvalid)
+	}
+	return nil
+}
+
+// knownFlags returns all flags which can be set in the config file.
+func knownFlags() ([]cli.Flag, error) {
+	var flags []cli.Flag
+	if err := config.RegisterFlagsNode(&flags); err != nil {
+		return nil, err
+	}
+	config.RegisterFlagsServiceStart(&flags)
+	config.RegisterFlagsServiceOpenvpn(&flags)
+	config.RegisterFlagsServiceWireguard(&flags)
+	config.RegisterFlagsServiceNoop(&flags)
+	config.RegisterFlagsServiceSpeedtest(&flags)
+	return flags, nil
+}
+
+func printReport(report config.ValidationReport) {
+	for _, issue := range report.Issues {
+		line := fmt.Sprintf("option %q: %s", issue.Key, issue.Message)
+		if issue.Severity == config.ValidationError {
+			clio.Error(line)
+		} else {
+			clio.Warn(line)
+		}
+	}
+
+	summary := fmt.Sprintf("%s: %d error(s), %d warning(s)", report.File, report.Errors, report.Warnings)
+	if report.Valid {
+		clio.Success(summary)
+	} else {
+		clio.Error(summary)
+	}
+}
+
+func printJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		clio.Error("Failed to print report", err)
+	}
+}
+
 // Orders keys alphabetically and prints a given map.
 func printMapOrdered(m map[string]string) {
 	keys := make([]string, 0, len(m))
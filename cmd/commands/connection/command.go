@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"text/tabwriter"
 	"time"
 
@@ -32,6 +33,7 @@ import (
 	"github.com/mysteriumnetwork/node/config/remote"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/tos"
 	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/money"
@@ -61,7 +63,7 @@ var (
 
 	flagSortType = cli.StringFlag{
 		Name:  "sort",
-		Usage: "Proposal sorting type. One of: quality, bandwidth, latency, uptime or price",
+		Usage: "Proposal sorting type. One of: quality, bandwidth, latency, uptime, price or load",
 		Value: "quality",
 	}
 
@@ -82,7 +84,7 @@ func NewCommand() *cli.Command {
 		Name:        CommandName,
 		Usage:       "Manage your connection",
 		Description: "Using the connection subcommands you can manage your connection or get additional information about it",
-		Flags:       []cli.Flag{&config.FlagTequilapiAddress, &config.FlagTequilapiPort},
+		Flags:       []cli.Flag{&config.FlagTequilapiAddress, &config.FlagTequilapiPort, &config.FlagTequilapiSocket, &config.FlagRemoteHost, &config.FlagRemoteToken, &config.FlagRemoteCACert},
 		Before: func(ctx *cli.Context) error {
 			tc, err := clio.NewTequilApiClient(ctx)
 			if err != nil {
@@ -202,7 +204,7 @@ func (c *command) handleTOS(ctx *cli.Context) error {
 		return errors.New("you must agree with consumer terms of use in order to use this command")
 	}
 
-	version := c.cfg.GetString(contract.TermsVersion)
+	version := tos.AgreedVersion(c.cfg, contract.TermsConsumerVersion)
 	if version != terms.TermsVersion {
 		return fmt.Errorf("you've agreed to terms of use version %s, but version %s is required", version, terms.TermsVersion)
 	}
@@ -226,7 +228,8 @@ func (c *command) up(ctx *cli.Context) {
 		return
 	}
 
-	status, err := c.tequilapi.ConnectionStatus(ctx.Int(flagProxyPort.Name))
+	prep := c.prepareUp(ctx.Int(flagProxyPort.Name))
+	status, err := prep.status, prep.statusErr
 	if err != nil {
 		clio.Warn("Could not get connection status")
 		return
@@ -253,13 +256,13 @@ func (c *command) up(ctx *cli.Context) {
 		}
 	}
 
-	id, err := c.tequilapi.CurrentIdentity("", "")
+	id, err := prep.id, prep.idErr
 	if err != nil {
 		clio.Error("Failed to get your identity")
 		return
 	}
 
-	identityStatus, err := c.tequilapi.Identity(id.Address)
+	identityStatus, err := prep.identity, prep.identityErr
 	if err != nil {
 		clio.Warn("Failed to get identity status")
 		return
@@ -300,6 +303,37 @@ func (c *command) up(ctx *cli.Context) {
 	clio.Success("Connected")
 }
 
+// upPreparation holds results of the queries done before creating a connection.
+type upPreparation struct {
+	status      contract.ConnectionInfoDTO
+	statusErr   error
+	id          contract.IdentityRefDTO
+	idErr       error
+	identity    contract.IdentityDTO
+	identityErr error
+}
+
+// prepareUp runs independent queries needed by connection up concurrently instead of waiting for each in turn.
+func (c *command) prepareUp(proxyPort int) upPreparation {
+	var prep upPreparation
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		prep.status, prep.statusErr = c.tequilapi.ConnectionStatus(proxyPort)
+	}()
+	go func() {
+		defer wg.Done()
+		prep.id, prep.idErr = c.tequilapi.CurrentIdentity("", "")
+		if prep.idErr != nil {
+			return
+		}
+		prep.identity, prep.identityErr = c.tequilapi.Identity(prep.id.Address)
+	}()
+	wg.Wait()
+	return prep
+}
+
 func (c *command) info(ctx *cli.Context) {
 	inf := newConnInfo()
 
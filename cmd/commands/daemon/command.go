@@ -41,6 +41,7 @@ func NewCommand() *cli.Command {
 			config.ParseFlagsServiceOpenvpn(ctx)
 			config.ParseFlagsServiceWireguard(ctx)
 			config.ParseFlagsServiceNoop(ctx)
+			config.ParseFlagsServiceSpeedtest(ctx)
 			config.ParseFlagsNode(ctx)
 
 			nodeOptions := node.GetOptions()
@@ -54,7 +55,13 @@ func NewCommand() *cli.Command {
 			return describeQuit(<-quit)
 		},
 		After: func(ctx *cli.Context) error {
-			return di.Shutdown()
+			if err := di.Shutdown(); err != nil {
+				return err
+			}
+			if di.Updater.RestartPending() {
+				return di.Updater.Restart()
+			}
+			return nil
 		},
 	}
 
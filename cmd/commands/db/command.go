@@ -0,0 +1,258 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package db
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+
+	"github.com/mysteriumnetwork/node/cmd"
+	"github.com/mysteriumnetwork/node/cmd/commands/cli/clio"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/config/urfavecli/clicontext"
+	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrations/history"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrator"
+	"github.com/mysteriumnetwork/node/core/storage/encryption"
+	"github.com/mysteriumnetwork/node/core/storage/retention"
+	"github.com/mysteriumnetwork/node/core/storage/sqlite"
+)
+
+// CommandName is the name of the database command.
+const CommandName = "db"
+
+const (
+	engineBolt   = "bolt"
+	engineSQLite = "sqlite"
+)
+
+var (
+	flagEngine = cli.StringFlag{
+		Name:  "engine",
+		Usage: "Storage engine of the database: bolt or sqlite. The node itself always runs on bolt",
+		Value: engineBolt,
+	}
+	flagTo = cli.StringFlag{
+		Name:  "to",
+		Usage: "Copy migrated BoltDB records into another storage engine: sqlite. The copy is not used by the node, it is kept for evaluation",
+	}
+)
+
+// NewCommand creates database maintenance command.
+// The node must be stopped while the command runs.
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:   CommandName,
+		Usage:  "Manage local node database. Node must be stopped",
+		Flags:  []cli.Flag{&config.FlagIdentityPassphrase},
+		Before: clicontext.LoadUserConfigQuietly,
+		Subcommands: []*cli.Command{
+			{
+				Name:   "migrate",
+				Usage:  "Apply pending database migrations",
+				Flags:  []cli.Flag{&flagTo},
+				Action: migrate,
+			},
+			{
+				Name:   "verify",
+				Usage:  "Check database consistency",
+				Flags:  []cli.Flag{&flagEngine},
+				Action: verify,
+			},
+			{
+				Name:   "compact",
+				Usage:  "Reclaim unused database space",
+				Flags:  []cli.Flag{&flagEngine},
+				Action: compact,
+			},
+			{
+				Name:   "prune",
+				Usage:  "Delete records past their retention periods and compact the database",
+				Action: prune,
+			},
+		},
+	}
+}
+
+func migrate(ctx *cli.Context) error {
+	dir, cipher, err := storageDir(ctx)
+	if err != nil {
+		clio.Error("Could not open database: ", err)
+		return err
+	}
+	bolt, err := cmd.OpenStorage(dir, cipher)
+	if err != nil {
+		clio.Error("Could not open database: ", err)
+		return err
+	}
+	defer bolt.Close()
+
+	m := migrator.NewMigrator(bolt)
+	pending, err := m.Pending(history.Sequence)
+	if err != nil {
+		return err
+	}
+	for _, migration := range pending {
+		clio.Info(fmt.Sprintf("Applying migration %s", migration.Name))
+	}
+	if err := m.RunMigrations(history.Sequence); err != nil {
+		clio.Error("Migration failed: ", err)
+		return err
+	}
+	clio.Success(fmt.Sprintf("Database is up to date, %d migrations applied", len(pending)))
+
+	switch ctx.String(flagTo.Name) {
+	case "":
+		return nil
+	case engineSQLite:
+		db, err := openSQLite(dir, cipher)
+		if err != nil {
+			clio.Error("Could not open database: ", err)
+			return err
+		}
+		defer db.Close()
+
+		count, err := db.ImportBolt(bolt.DB().Bolt)
+		if err != nil {
+			clio.Error("Copying records failed: ", err)
+			return err
+		}
+		clio.Success(fmt.Sprintf("Copied %d records to SQLite", count))
+		return nil
+	default:
+		return errors.Errorf("unknown storage engine %q", ctx.String(flagTo.Name))
+	}
+}
+
+func verify(ctx *cli.Context) error {
+	db, err := open(ctx)
+	if err != nil {
+		clio.Error("Could not open database: ", err)
+		return err
+	}
+	defer db.Close()
+
+	if err := db.Verify(); err != nil {
+		clio.Error("Database verification failed: ", err)
+		return err
+	}
+	clio.Success("Database is consistent")
+	return nil
+}
+
+func compact(ctx *cli.Context) error {
+	db, err := open(ctx)
+	if err != nil {
+		clio.Error("Could not open database: ", err)
+		return err
+	}
+	defer db.Close()
+
+	if err := db.Compact(); err != nil {
+		clio.Error("Database compaction failed: ", err)
+		return err
+	}
+	clio.Success("Database compacted")
+	return nil
+}
+
+func prune(ctx *cli.Context) error {
+	dir, cipher, err := storageDir(ctx)
+	if err != nil {
+		clio.Error("Could not open database: ", err)
+		return err
+	}
+	bolt, err := cmd.OpenStorage(dir, cipher)
+	if err != nil {
+		clio.Error("Could not open database: ", err)
+		return err
+	}
+	defer bolt.Close()
+
+	report, err := retention.NewJob(bolt, 0, cmd.RetentionTargets(bolt)...).Run()
+	for _, result := range report.Results {
+		if result.Error != "" {
+			clio.Warn(fmt.Sprintf("Pruning %s failed: %s", result.Name, result.Error))
+			continue
+		}
+		clio.Info(fmt.Sprintf("Pruned %d %s records", result.Pruned, result.Name))
+	}
+	if err != nil {
+		clio.Error("Database pruning failed: ", err)
+		return err
+	}
+	clio.Success(fmt.Sprintf("Database pruned, %d bytes freed", report.Freed()))
+	return nil
+}
+
+type maintainedStorage interface {
+	storage.Storage
+	storage.Maintainer
+}
+
+func open(ctx *cli.Context) (maintainedStorage, error) {
+	dir, cipher, err := storageDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch engine := ctx.String(flagEngine.Name); engine {
+	case engineBolt:
+		return cmd.OpenStorage(dir, cipher)
+	case engineSQLite:
+		return openSQLite(dir, cipher)
+	default:
+		return nil, errors.Errorf("unknown storage engine %q", engine)
+	}
+}
+
+func openSQLite(dir string, cipher *encryption.Cipher) (*sqlite.SQLite, error) {
+	if !sqlite.Available() {
+		return nil, errors.New("node is built without SQLite support, rebuild it with the sqlite tag")
+	}
+	if cipher == nil {
+		return sqlite.NewStorage(dir)
+	}
+
+	db, err := sqlite.NewStorageWithCodec(dir, encryption.NewCodec(cipher))
+	if err != nil {
+		return nil, err
+	}
+	count, err := encryption.EncryptStorage(db, cipher)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if count > 0 {
+		clio.Info(fmt.Sprintf("Encrypted %d plaintext records", count))
+	}
+	return db, nil
+}
+
+// storageDir returns the storage directory and the storage cipher, if encryption is enabled.
+func storageDir(ctx *cli.Context) (string, *encryption.Cipher, error) {
+	config.ParseFlagsNode(ctx)
+	config.Current.ParseStringFlag(ctx, config.FlagIdentityPassphrase)
+
+	options := node.GetOptions()
+	cipher, err := cmd.StorageCipher(options.Directories.Data)
+	return options.Directories.Storage, cipher, err
+}
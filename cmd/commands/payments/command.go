@@ -0,0 +1,137 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package payments
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/mysteriumnetwork/node/cmd/commands/cli/clio"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	tequilapi_client "github.com/mysteriumnetwork/node/tequilapi/client"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+// CommandName is the name of the payments command.
+const CommandName = "payments"
+
+var (
+	flagIdentity = cli.StringFlag{
+		Name:  "identity",
+		Usage: "Identity to audit. Identity currently in use is audited if empty",
+	}
+	flagRecover = cli.BoolFlag{
+		Name:  "recover",
+		Usage: "Re-request lost or corrupted promises from hermes and reconcile local accounting",
+	}
+	flagJSON = cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print audit result as JSON",
+	}
+)
+
+// NewCommand creates payments command.
+func NewCommand() *cli.Command {
+	var cmd *command
+
+	return &cli.Command{
+		Name:        CommandName,
+		Usage:       "Inspect and repair provider payments",
+		Description: "Using payments subcommands you can check whether locally stored hermes promises match hermes and recover them",
+		Flags:       []cli.Flag{&config.FlagTequilapiAddress, &config.FlagTequilapiPort, &config.FlagTequilapiSocket, &config.FlagRemoteHost, &config.FlagRemoteToken, &config.FlagRemoteCACert},
+		Before: func(ctx *cli.Context) error {
+			tc, err := clio.NewTequilApiClient(ctx)
+			if err != nil {
+				return err
+			}
+
+			cmd = &command{tequilapi: tc}
+			return nil
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:  "audit",
+				Usage: "Compare locally stored hermes promises with hermes",
+				Flags: []cli.Flag{&flagIdentity, &flagRecover, &flagJSON},
+				Action: func(ctx *cli.Context) error {
+					return cmd.audit(ctx)
+				},
+			},
+		},
+	}
+}
+
+type command struct {
+	tequilapi *tequilapi_client.Client
+}
+
+func (c *command) audit(ctx *cli.Context) error {
+	address := ctx.String(flagIdentity.Name)
+	if address == "" {
+		id, err := c.tequilapi.CurrentIdentity("", "")
+		if err != nil {
+			clio.Error("Could not get current identity:", err)
+			return err
+		}
+		address = id.Address
+	}
+
+	var res contract.PromiseAuditResponse
+	var err error
+	if ctx.Bool(flagRecover.Name) {
+		res, err = c.tequilapi.PaymentsRecover(address)
+	} else {
+		res, err = c.tequilapi.PaymentsAudit(address)
+	}
+	if err != nil {
+		clio.Error("Failed to audit payments:", err)
+		return err
+	}
+
+	if ctx.Bool(flagJSON.Name) {
+		out, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	clio.Info("Identity:", res.Identity)
+	clio.Info("Hermes:", res.HermesID)
+	clio.Info("Channel:", res.ChannelID)
+	clio.Infof("Local promise: %s\n", money.New(res.LocalAmount))
+	clio.Infof("Hermes promise: %s\n", money.New(res.HermesAmount))
+	if res.Detail != "" {
+		clio.Info("Detail:", res.Detail)
+	}
+
+	switch {
+	case res.Discrepancy == string(pingpong.PromiseConsistent):
+		clio.Success("Local promises match hermes")
+	case res.Recovered:
+		clio.Success(fmt.Sprintf("Promise discrepancy (%s) recovered", res.Discrepancy))
+	default:
+		clio.Warn(fmt.Sprintf("Promise discrepancy found: %s. Run with --%s to repair it", res.Discrepancy, flagRecover.Name))
+	}
+	return nil
+}
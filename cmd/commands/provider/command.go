@@ -0,0 +1,263 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/mysteriumnetwork/node/cmd/commands/cli/clio"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/datasize"
+	tequilapi_client "github.com/mysteriumnetwork/node/tequilapi/client"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+// CommandName is the name of the provider command.
+const CommandName = "provider"
+
+var (
+	flagIdentity = cli.StringFlag{
+		Name:  "identity",
+		Usage: "Provider identity to start service with. Identity currently in use is used if empty",
+	}
+	flagAccessPolicies = cli.StringSliceFlag{
+		Name:  "access-policies",
+		Usage: "Access policies restricting who may use the service",
+	}
+	flagCloseSessions = cli.BoolFlag{
+		Name:  "close-sessions",
+		Usage: "Close active sessions instead of keeping them while paused",
+	}
+	flagJSON = cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print result as JSON",
+	}
+)
+
+// NewCommand creates provider command.
+func NewCommand() *cli.Command {
+	var cmd *command
+
+	return &cli.Command{
+		Name:        CommandName,
+		Usage:       "Manage provider services and show node stats",
+		Description: "Using provider subcommands you can start, stop and pause services of a local or remote node and see how they are doing",
+		Flags:       []cli.Flag{&config.FlagTequilapiAddress, &config.FlagTequilapiPort, &config.FlagTequilapiSocket, &config.FlagRemoteHost, &config.FlagRemoteToken, &config.FlagRemoteCACert},
+		Before: func(ctx *cli.Context) error {
+			tc, err := clio.NewTequilApiClient(ctx)
+			if err != nil {
+				return err
+			}
+
+			cmd = &command{tequilapi: tc}
+			return nil
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:  "services",
+				Usage: "List running services",
+				Flags: []cli.Flag{&flagJSON},
+				Action: func(ctx *cli.Context) error {
+					return cmd.services(ctx)
+				},
+			},
+			{
+				Name:      "start",
+				Usage:     "Start a service with options configured on the node",
+				ArgsUsage: "[service type]",
+				Flags:     []cli.Flag{&flagIdentity, &flagAccessPolicies},
+				Action: func(ctx *cli.Context) error {
+					return cmd.start(ctx)
+				},
+			},
+			{
+				Name:      "stop",
+				Usage:     "Stop a running service",
+				ArgsUsage: "[service ID]",
+				Action: func(ctx *cli.Context) error {
+					return cmd.stop(ctx)
+				},
+			},
+			{
+				Name:  "pause",
+				Usage: "Pause all services",
+				Flags: []cli.Flag{&flagCloseSessions},
+				Action: func(ctx *cli.Context) error {
+					return cmd.pause(ctx)
+				},
+			},
+			{
+				Name:  "resume",
+				Usage: "Resume paused services",
+				Action: func(ctx *cli.Context) error {
+					return cmd.resume()
+				},
+			},
+			{
+				Name:  "stats",
+				Usage: "Show services, active sessions and today's traffic and earnings",
+				Flags: []cli.Flag{&flagJSON},
+				Action: func(ctx *cli.Context) error {
+					return cmd.stats(ctx)
+				},
+			},
+		},
+	}
+}
+
+type command struct {
+	tequilapi *tequilapi_client.Client
+}
+
+func (c *command) services(ctx *cli.Context) error {
+	services, err := c.tequilapi.Services()
+	if err != nil {
+		clio.Error("Failed to get a list of services:", err)
+		return err
+	}
+
+	if ctx.Bool(flagJSON.Name) {
+		return printJSON(services)
+	}
+
+	if len(services) == 0 {
+		clio.Info("No services are running")
+		return nil
+	}
+	for _, service := range services {
+		clio.Status(service.Status,
+			"ID: "+service.ID,
+			"ProviderID: "+service.ProviderID,
+			"Type: "+service.Type)
+	}
+	return nil
+}
+
+func (c *command) start(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		clio.Warn("Service type is required")
+		return cli.ShowSubcommandHelp(ctx)
+	}
+
+	providerID := ctx.String(flagIdentity.Name)
+	if providerID == "" {
+		id, err := c.tequilapi.CurrentIdentity("", "")
+		if err != nil {
+			clio.Error("Could not get current identity:", err)
+			return err
+		}
+		providerID = id.Address
+	}
+
+	service, err := c.tequilapi.ServiceStart(contract.ServiceStartRequest{
+		ProviderID:     providerID,
+		Type:           ctx.Args().First(),
+		AccessPolicies: contract.ServiceAccessPolicies{IDs: ctx.StringSlice(flagAccessPolicies.Name)},
+	})
+	if err != nil {
+		clio.Error("Failed to start service:", err)
+		return err
+	}
+
+	clio.Status(service.Status,
+		"ID: "+service.ID,
+		"ProviderID: "+service.ProviderID,
+		"Type: "+service.Type)
+	return nil
+}
+
+func (c *command) stop(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		clio.Warn("Service ID is required")
+		return cli.ShowSubcommandHelp(ctx)
+	}
+
+	id := ctx.Args().First()
+	if err := c.tequilapi.ServiceStop(id); err != nil {
+		clio.Error("Failed to stop service:", err)
+		return err
+	}
+
+	clio.Status("Stopping", "ID: "+id)
+	return nil
+}
+
+func (c *command) pause(ctx *cli.Context) error {
+	status, err := c.tequilapi.ProviderPause(!ctx.Bool(flagCloseSessions.Name))
+	if err != nil {
+		clio.Error("Failed to pause services:", err)
+		return err
+	}
+
+	clio.Success(fmt.Sprintf("Paused %d services, active sessions kept: %t", len(status.Services), status.KeepSessions))
+	return nil
+}
+
+func (c *command) resume() error {
+	if _, err := c.tequilapi.ProviderResume(); err != nil {
+		clio.Error("Failed to resume services:", err)
+		return err
+	}
+
+	clio.Success("Services resumed")
+	return nil
+}
+
+func (c *command) stats(ctx *cli.Context) error {
+	summary, err := c.tequilapi.NodeSummary()
+	if err != nil {
+		clio.Error("Failed to get node stats:", err)
+		return err
+	}
+
+	if ctx.Bool(flagJSON.Name) {
+		return printJSON(summary)
+	}
+
+	if summary.Identity != nil {
+		clio.Info("Identity:", summary.Identity.Address)
+		clio.Info("Registration:", summary.Identity.RegistrationStatus)
+		clio.Info("Balance:", summary.Identity.Balance.Human)
+		clio.Info("Unsettled earnings:", summary.Identity.Earnings.Human)
+		clio.Info("Total earnings:", summary.Identity.EarningsTotal.Human)
+	}
+	clio.Info("Monitoring:", summary.MonitoringStatus)
+	for _, service := range summary.Services {
+		clio.Status(service.Status, "ID: "+service.ID, "Type: "+service.Type, "Health: "+service.Health)
+	}
+	clio.Info("Active sessions:", summary.ActiveSessions.Provided)
+	clio.Info("Sessions today:", summary.Today.Sessions)
+	clio.Infof("Traffic today: %s sent, %s received\n", datasize.FromBytes(summary.Today.DataSent), datasize.FromBytes(summary.Today.DataReceived))
+	clio.Info("Earnings today:", summary.Today.Earnings.Human)
+	for _, alert := range summary.Alerts {
+		clio.Warn(alert.Severity+":", alert.Message)
+	}
+	return nil
+}
+
+func printJSON(v interface{}) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
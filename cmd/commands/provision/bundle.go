@@ -0,0 +1,181 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package provision
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/metadata"
+)
+
+// bundleVersion is incremented on incompatible bundle format changes.
+const bundleVersion = 1
+
+// provisionableKeys are the only user config values exported to and applied from bundles.
+// Anything else, including secrets, node specific values and commands run by the node, is left out.
+var provisionableKeys = []string{
+	config.FlagAccessPolicyList.Name,
+	config.FlagAccessPolicyFetchInterval.Name,
+	config.FlagAccessPolicyBlock.Name,
+	config.FlagAccessPolicyBlockExempt.Name,
+	config.FlagPaymentPriceGiB.Name,
+	config.FlagPaymentPriceHour.Name,
+	config.FlagPaymentsProviderFreeTierMegabytes.Name,
+	config.FlagPaymentsProviderFreeTierMinutes.Name,
+	config.FlagPricingAuto.Name,
+	config.FlagPricingAutoServices.Name,
+	config.FlagPricingAutoPercentile.Name,
+	config.FlagPricingAutoInterval.Name,
+	config.FlagPricingAutoMaxChange.Name,
+	config.FlagPricingAutoMinPerHour.Name,
+	config.FlagPricingAutoMaxPerHour.Name,
+	config.FlagPricingAutoMinPerGiB.Name,
+	config.FlagPricingAutoMaxPerGiB.Name,
+	config.FlagWireguardListenPorts.Name,
+	config.FlagWireguardListenSubnet.Name,
+	config.FlagWireguardAccessPolicies.Name,
+	config.FlagWireguardRekeyInterval.Name,
+	config.FlagOpenvpnProtocol.Name,
+	config.FlagOpenvpnPort.Name,
+	config.FlagOpenvpnSubnet.Name,
+	config.FlagOpenvpnNetmask.Name,
+	config.FlagOpenvpnDataCiphers.Name,
+	config.FlagOpenvpnTLSCiphers.Name,
+	config.FlagOpenvpnTLSVersionMin.Name,
+	config.FlagOpenvpnDuplicateCN.Name,
+	config.FlagOpenvpnNoCompression.Name,
+	config.FlagOpenVPNAccessPolicies.Name,
+	config.FlagNoopEnabled.Name,
+	config.FlagNoopAccessPolicies.Name,
+	config.FlagSpeedtestAccessPolicies.Name,
+	config.FlagSpeedtestDuration.Name,
+	config.FlagSpeedtestMaxRate.Name,
+	config.FlagServiceMaxSessions.Name,
+	config.FlagServiceBandwidthCapacity.Name,
+	config.FlagServiceSchedule.Name,
+	config.FlagServiceHealthInterval.Name,
+	config.FlagServiceHealthFailures.Name,
+	config.FlagServiceHealthMaxRestarts.Name,
+	config.FlagServiceRestoreSessions.Name,
+	config.FlagServiceRestoreGrace.Name,
+	config.FlagShaperEnabled.Name,
+	config.FlagShaperBandwidth.Name,
+	config.FlagAbuseWindow.Name,
+	config.FlagAbuseChurnThreshold.Name,
+	config.FlagAbuseZeroPaymentThreshold.Name,
+	config.FlagAbusePortScanThreshold.Name,
+	config.FlagAbuseBlockScore.Name,
+	config.FlagAbuseBlockDuration.Name,
+	config.FlagAbuseRetention.Name,
+	config.FlagCamouflage.Name,
+	config.FlagCamouflageAddress.Name,
+	config.FlagCamouflagePublicPort.Name,
+	config.FlagCamouflageServerName.Name,
+	config.FlagObfuscation.Name,
+	config.FlagOutgoingFirewall.Name,
+	config.FlagPrivacyConsumerIP.Name,
+	config.FlagDDNSProvider.Name,
+	config.FlagDDNSCloudflareZone.Name,
+	config.FlagTequilapiPort.Name,
+}
+
+// Bundle is a sanitized provisioning profile of a node: provisionable part of its user config,
+// including access policies and pricing, without secrets and node specific values.
+type Bundle struct {
+	Version     int                    `json:"version"`
+	CreatedAt   time.Time              `json:"created_at"`
+	NodeVersion string                 `json:"node_version"`
+	Config      map[string]interface{} `json:"config"`
+}
+
+// NewBundle creates bundle from provisionable values of the user config.
+// Skipped keys are returned so that operator knows what has to be configured on a new node.
+func NewBundle(userConfig map[string]interface{}) (Bundle, []string) {
+	flat := map[string]interface{}{}
+	flatten(userConfig, "", flat)
+
+	bundle := Bundle{
+		Version:     bundleVersion,
+		CreatedAt:   time.Now().UTC().Truncate(time.Second),
+		NodeVersion: metadata.VersionAsString(),
+		Config:      map[string]interface{}{},
+	}
+	var skipped []string
+	for key, value := range flat {
+		if !exportable(key) {
+			skipped = append(skipped, key)
+			continue
+		}
+		bundle.Config[key] = value
+	}
+	sort.Strings(skipped)
+	return bundle, skipped
+}
+
+// ReadBundle reads and validates the bundle.
+func ReadBundle(r io.Reader) (Bundle, error) {
+	var bundle Bundle
+	if err := json.NewDecoder(r).Decode(&bundle); err != nil {
+		return bundle, fmt.Errorf("could not parse bundle: %w", err)
+	}
+	if bundle.Version != bundleVersion {
+		return bundle, fmt.Errorf("unsupported bundle version %d, expected %d", bundle.Version, bundleVersion)
+	}
+	if len(bundle.Config) == 0 {
+		return bundle, fmt.Errorf("bundle has no config")
+	}
+	for key := range bundle.Config {
+		if !exportable(key) {
+			return bundle, fmt.Errorf("bundle contains value %q which can't be provisioned", key)
+		}
+	}
+	return bundle, nil
+}
+
+// Write writes the bundle as indented JSON.
+func (b Bundle) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+func exportable(key string) bool {
+	for _, k := range provisionableKeys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
+func flatten(source map[string]interface{}, prefix string, dest map[string]interface{}) {
+	for k, v := range source {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]interface{}); ok {
+			flatten(nested, k, dest)
+			continue
+		}
+		dest[k] = v
+	}
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package provision
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/mysteriumnetwork/node/cmd/commands/cli/clio"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/tequilapi/client"
+)
+
+// CommandName is the name of the provisioning command.
+const CommandName = "provision"
+
+var flagOutput = cli.StringFlag{
+	Name:  "output",
+	Usage: "File to write the bundle to, standard output if empty",
+}
+
+// NewCommand creates provisioning command.
+func NewCommand() *cli.Command {
+	cmd := &command{}
+	return &cli.Command{
+		Name:        CommandName,
+		Usage:       "Clone node setup to other nodes",
+		Description: "Export a provisioning bundle (config, access policies, pricing, without secrets and private keys) and apply it on a new node",
+		Flags:       []cli.Flag{&config.FlagTequilapiAddress, &config.FlagTequilapiPort, &config.FlagTequilapiSocket, &config.FlagRemoteHost, &config.FlagRemoteToken, &config.FlagRemoteCACert},
+		Before: func(ctx *cli.Context) error {
+			var err error
+			cmd.tc, err = clio.NewTequilApiClient(ctx)
+			return err
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:   "export",
+				Usage:  "Export provisioning bundle of this node",
+				Flags:  []cli.Flag{&flagOutput},
+				Action: cmd.export,
+			},
+			{
+				Name:      "apply",
+				Usage:     "Apply provisioning bundle to this node",
+				ArgsUsage: "[BundleFile|-]",
+				Action:    cmd.apply,
+			},
+		},
+	}
+}
+
+type command struct {
+	tc *client.Client
+}
+
+func (c *command) export(ctx *cli.Context) error {
+	userConfig, err := c.tc.FetchUserConfig()
+	if err != nil {
+		clio.Error("Failed to fetch user config: " + err.Error())
+		return err
+	}
+
+	bundle, skipped := NewBundle(userConfig)
+
+	path := ctx.String(flagOutput.Name)
+	if path == "" {
+		// Bundle goes to standard output, keep it clean for redirection.
+		if len(skipped) > 0 {
+			fmt.Fprintln(ctx.App.ErrWriter, "Left out secrets and node specific values: "+strings.Join(skipped, ", "))
+		}
+		return bundle.Write(ctx.App.Writer)
+	}
+	if len(skipped) > 0 {
+		clio.Warn("Left out secrets and node specific values: " + strings.Join(skipped, ", "))
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		clio.Error("Failed to create bundle file: " + err.Error())
+		return err
+	}
+	defer f.Close()
+
+	if err := bundle.Write(f); err != nil {
+		clio.Error("Failed to write bundle: " + err.Error())
+		return err
+	}
+	clio.Success("Provisioning bundle exported: " + path)
+	return nil
+}
+
+func (c *command) apply(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		clio.Error("Exactly 1 argument required, got:", ctx.Args().Len())
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	var r io.Reader = ctx.App.Reader
+	if path := ctx.Args().First(); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			clio.Error("Failed to open bundle file: " + err.Error())
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	bundle, err := ReadBundle(r)
+	if err != nil {
+		clio.Error("Invalid provisioning bundle: " + err.Error())
+		return err
+	}
+
+	if err := c.tc.SetConfig(bundle.Config); err != nil {
+		clio.Error("Failed to apply config: " + err.Error())
+		return err
+	}
+
+	clio.Success(fmt.Sprintf("Applied %d config values from bundle created by node %s", len(bundle.Config), bundle.NodeVersion))
+	clio.Info("Restart services for the changes to take effect")
+	return nil
+}
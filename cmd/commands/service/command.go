@@ -34,12 +34,14 @@ import (
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/config/urfavecli/clicontext"
 	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/core/tos"
 	"github.com/mysteriumnetwork/node/services"
 	"github.com/mysteriumnetwork/node/services/datatransfer"
 	"github.com/mysteriumnetwork/node/services/scraping"
 	"github.com/mysteriumnetwork/node/services/wireguard"
 	"github.com/mysteriumnetwork/node/tequilapi/client"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/tlsconfig"
 )
 
 // NewCommand function creates service command
@@ -56,6 +58,7 @@ func NewCommand(licenseCommandName string) *cli.Command {
 			config.ParseFlagsServiceOpenvpn(ctx)
 			config.ParseFlagsServiceWireguard(ctx)
 			config.ParseFlagsServiceNoop(ctx)
+			config.ParseFlagsServiceSpeedtest(ctx)
 			config.ParseFlagsNode(ctx)
 
 			if err := hasAcceptedTOS(ctx); err != nil {
@@ -72,8 +75,20 @@ func NewCommand(licenseCommandName string) *cli.Command {
 
 			cmd.RegisterSignalCallback(func() { quit <- nil })
 
+			tequilapiClient := client.NewClient(nodeOptions.TequilapiAddress, nodeOptions.TequilapiPort)
+			if nodeOptions.TequilapiTLS.Enabled {
+				tlsConfig, err := tlsconfig.ClientConfig(tlsconfig.Dir(nodeOptions.Directories.Data), nodeOptions.TequilapiTLS.Domain)
+				if err != nil {
+					return err
+				}
+				tequilapiClient = client.NewTLSClient(nodeOptions.TequilapiAddress, nodeOptions.TequilapiPort, tlsConfig)
+			}
+			if nodeOptions.TequilapiSocket.TCPDisabled() {
+				tequilapiClient = client.NewUnixClient(nodeOptions.TequilapiSocket.Path)
+			}
+
 			cmdService := &serviceCommand{
-				tequilapi:    client.NewClient(nodeOptions.TequilapiAddress, nodeOptions.TequilapiPort),
+				tequilapi:    tequilapiClient,
 				errorChannel: quit,
 			}
 			go func() {
@@ -83,7 +98,13 @@ func NewCommand(licenseCommandName string) *cli.Command {
 			return describeQuit(<-quit)
 		},
 		After: func(ctx *cli.Context) error {
-			return di.Shutdown()
+			if err := di.Shutdown(); err != nil {
+				return err
+			}
+			if di.Updater.RestartPending() {
+				return di.Updater.Restart()
+			}
+			return nil
 		},
 	}
 
@@ -91,6 +112,7 @@ func NewCommand(licenseCommandName string) *cli.Command {
 	config.RegisterFlagsServiceOpenvpn(&command.Flags)
 	config.RegisterFlagsServiceWireguard(&command.Flags)
 	config.RegisterFlagsServiceNoop(&command.Flags)
+	config.RegisterFlagsServiceSpeedtest(&command.Flags)
 
 	return command
 }
@@ -119,7 +141,7 @@ func (sc *serviceCommand) Run(ctx *cli.Context) (err error) {
 		serviceTypes = strings.Split(arg, ",")
 	}
 
-	sc.tryRememberTOS(ctx, sc.errorChannel)
+	sc.tryRememberTOS(ctx)
 	providerID := sc.unlockIdentity(
 		ctx.String(config.FlagIdentity.Name),
 		ctx.String(config.FlagIdentityPassphrase.Name),
@@ -157,33 +179,24 @@ func (sc *serviceCommand) unlockIdentity(id, passphrase string) string {
 	}
 }
 
-func (sc *serviceCommand) tryRememberTOS(ctx *cli.Context, errCh chan error) {
+// tryRememberTOS persists terms acceptance given by flag, it completes before services start
+// as the node refuses to start services until provider terms are accepted.
+func (sc *serviceCommand) tryRememberTOS(ctx *cli.Context) {
 	if !ctx.Bool(config.FlagAgreedTermsConditions.Name) {
 		return
 	}
 
-	doUpdate := func() {
-		t := true
-		for i := 0; i < 5; i++ {
-			if err := sc.tequilapi.UpdateTerms(contract.TermsRequest{
-				AgreedProvider: &t,
-				AgreedConsumer: &t,
-				AgreedVersion:  terms.TermsVersion,
-			}); err == nil {
-				return
-			}
-			time.Sleep(time.Second * 2)
-		}
-	}
-
-	go func() {
-		select {
-		case <-errCh:
+	t := true
+	for i := 0; i < 5; i++ {
+		if err := sc.tequilapi.UpdateTerms(contract.TermsRequest{
+			AgreedProvider: &t,
+			AgreedConsumer: &t,
+			AgreedVersion:  terms.TermsVersion,
+		}); err == nil {
 			return
-		default:
-			doUpdate()
 		}
-	}()
+		time.Sleep(time.Second * 2)
+	}
 }
 
 func (sc *serviceCommand) runService(request contract.ServiceStartRequest) {
@@ -203,7 +216,7 @@ func hasAcceptedTOS(ctx *cli.Context) error {
 		return errors.New("You must agree with provider terms of use in order to use this command")
 	}
 
-	version := config.Current.GetString(contract.TermsVersion)
+	version := tos.AgreedVersion(config.Current, contract.TermsProviderVersion)
 	if version != terms.TermsVersion {
 		return fmt.Errorf("you've agreed to terms of use version %s, but version %s is required", version, terms.TermsVersion)
 	}
@@ -0,0 +1,83 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package update
+
+import (
+	"github.com/urfave/cli/v2"
+
+	"github.com/mysteriumnetwork/node/cmd/commands/cli/clio"
+	"github.com/mysteriumnetwork/node/config"
+)
+
+// CommandName is the name of the update command.
+const CommandName = "update"
+
+var (
+	flagCheck = cli.BoolFlag{
+		Name:  "check",
+		Usage: "Only check whether a newer release is available",
+	}
+	flagChannel = cli.StringFlag{
+		Name:  "channel",
+		Usage: "Release channel to update from: stable or beta. Configured channel is used if empty",
+	}
+)
+
+// NewCommand creates update command.
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:      CommandName,
+		Usage:     "Update running node to the latest signed release",
+		ArgsUsage: " ",
+		Flags:     []cli.Flag{&config.FlagTequilapiAddress, &config.FlagTequilapiPort, &config.FlagTequilapiSocket, &flagCheck, &flagChannel},
+		Action: func(ctx *cli.Context) error {
+			tc, err := clio.NewTequilApiClient(ctx)
+			if err != nil {
+				return err
+			}
+
+			channel := ctx.String(flagChannel.Name)
+			if ctx.Bool(flagCheck.Name) {
+				status, err := tc.NodeUpdateStatus(channel)
+				if err != nil {
+					clio.Error("Failed to check for updates:", err)
+					return err
+				}
+				if !status.UpdateAvailable {
+					clio.Success("Node is up to date:", status.CurrentVersion)
+					return nil
+				}
+				clio.Info("Update available:", status.CurrentVersion, "->", status.LatestVersion)
+				return nil
+			}
+
+			status, err := tc.NodeUpdate(channel)
+			if err != nil {
+				clio.Error("Failed to update node:", err)
+				return err
+			}
+			if !status.UpdateAvailable {
+				clio.Success("Node is up to date:", status.CurrentVersion)
+				return nil
+			}
+
+			clio.Success("Updating node to", status.LatestVersion+". Node restarts after active sessions end")
+			return nil
+		},
+	}
+}
@@ -18,49 +18,77 @@
 package cmd
 
 import (
+	"crypto/tls"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"net/http"
 	"net/url"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
+	portmap "github.com/ethereum/go-ethereum/p2p/nat"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
+	"github.com/shopspring/decimal"
 
+	"github.com/mysteriumnetwork/node/communication/broker"
 	"github.com/mysteriumnetwork/node/communication/nats"
+	"github.com/mysteriumnetwork/node/communication/relay"
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/consumer/migration"
 	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/alerting"
 	"github.com/mysteriumnetwork/node/core/auth"
 	"github.com/mysteriumnetwork/node/core/beneficiary"
+	"github.com/mysteriumnetwork/node/core/chains"
+	"github.com/mysteriumnetwork/node/core/clockskew"
 	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/autoconnect"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/ddns"
 	"github.com/mysteriumnetwork/node/core/discovery"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/gasprice"
+	"github.com/mysteriumnetwork/node/core/hooks"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/core/management"
 	"github.com/mysteriumnetwork/node/core/node"
 	nodevent "github.com/mysteriumnetwork/node/core/node/event"
+	"github.com/mysteriumnetwork/node/core/notify"
 	"github.com/mysteriumnetwork/node/core/payout"
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/core/preflight"
+	"github.com/mysteriumnetwork/node/core/pricebook"
+	"github.com/mysteriumnetwork/node/core/pricing"
+	"github.com/mysteriumnetwork/node/core/privacy"
 	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/service/abuse"
 	"github.com/mysteriumnetwork/node/core/state"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrations/history"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrator"
+	"github.com/mysteriumnetwork/node/core/storage/encryption"
+	"github.com/mysteriumnetwork/node/core/storage/retention"
+	"github.com/mysteriumnetwork/node/core/tos"
+	"github.com/mysteriumnetwork/node/crash"
+	"github.com/mysteriumnetwork/node/diagnostics"
 	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/featureflag"
 	"github.com/mysteriumnetwork/node/feedback"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	identity_selector "github.com/mysteriumnetwork/node/identity/selector"
 	"github.com/mysteriumnetwork/node/logconfig"
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/market/mysterium"
 	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/mysteriumnetwork/node/mmn"
@@ -70,6 +98,7 @@ import (
 	"github.com/mysteriumnetwork/node/nat/mapping"
 	"github.com/mysteriumnetwork/node/nat/upnp"
 	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/p2p/camouflage"
 	"github.com/mysteriumnetwork/node/pilvytis"
 	"github.com/mysteriumnetwork/node/requests"
 	"github.com/mysteriumnetwork/node/requests/resolver"
@@ -77,14 +106,20 @@ import (
 	service_noop "github.com/mysteriumnetwork/node/services/noop"
 	service_openvpn "github.com/mysteriumnetwork/node/services/openvpn"
 	"github.com/mysteriumnetwork/node/session/connectivity"
+	"github.com/mysteriumnetwork/node/session/dispute"
 	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/session/receipt"
 	"github.com/mysteriumnetwork/node/sleep"
 	"github.com/mysteriumnetwork/node/tequilapi"
 	"github.com/mysteriumnetwork/node/ui/versionmanager"
+	"github.com/mysteriumnetwork/node/updater"
+	"github.com/mysteriumnetwork/node/utils"
 	"github.com/mysteriumnetwork/node/utils/netutil"
 	paymentClient "github.com/mysteriumnetwork/payments/client"
 	psort "github.com/mysteriumnetwork/payments/client/sort"
+	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/mysteriumnetwork/payments/observer"
+	"github.com/mysteriumnetwork/terms/terms-go"
 )
 
 // UIServer represents our web server
@@ -102,6 +137,7 @@ type Dependencies struct {
 	HTTPClient    *requests.HTTPClient
 
 	NetworkDefinition metadata.NetworkDefinition
+	PrivateNetwork    *metadata.PrivateNetwork
 	MysteriumAPI      *mysterium.MysteriumAPI
 	PricingHelper     *pingpong.Pricer
 	EtherClientL1     *paymentClient.EthMultiClient
@@ -112,12 +148,13 @@ type Dependencies struct {
 
 	EtherClients []*paymentClient.ReconnectableEthClient
 
-	BrokerConnector  *nats.BrokerConnector
-	BrokerConnection nats.Connection
+	BrokerConnector  broker.Connector
+	BrokerConnection broker.Connection
 
 	NATService       nat.NATService
 	NATProber        natprobe.NATProber
 	Storage          *boltdb.Bolt
+	StorageRetention *retention.Job
 	Keystore         *identity.Keystore
 	IdentityManager  identity.Manager
 	SignerFactory    identity.SignerFactory
@@ -133,22 +170,41 @@ type Dependencies struct {
 	QualityClient *quality.MysteriumMORQA
 
 	IPResolver       ip.Resolver
+	ServiceBindings  ip.Bindings
 	LocationResolver *location.Cache
+	GeoIPResolver    *location.DBResolver
+	GeoIPUpdaters    []*location.DBUpdater
+	// ConsumerLocationResolver provides consumer location reported to providers and quality oracle.
+	ConsumerLocationResolver location.OriginResolver
 
 	PolicyOracle *policy.Oracle
+	Blocklist    *policy.Blocklist
 
 	SessionStorage                   *consumer_session.Storage
 	SessionConnectivityStatusStorage connectivity.StatusStorage
+	ReceiptKeeper                    *receipt.Keeper
+	TermsKeeper                      *tos.Keeper
 
 	EventBus eventbus.EventBus
 
+	ChainSwitcher *chains.Switcher
+
 	MultiConnectionManager connection.MultiManager
+	ConnectionTransitions  *connection.TransitionLog
 	ConnectionRegistry     *connection.Registry
-
-	ServicesManager *service.Manager
-	ServiceRegistry *service.Registry
-	ServiceSessions *service.SessionPool
-	ServiceFirewall firewall.IncomingTrafficFirewall
+	PreConnectHooks        *connection.PreConnectHooks
+
+	ServicesManager   *service.Manager
+	ServiceSupervisor *service.Supervisor
+	ServiceScheduler  *service.Scheduler
+	ServicePauser     *service.Pauser
+	ServiceRegistry   *service.Registry
+	ServiceSessions   *service.SessionPool
+	SessionSnapshots  *service.SessionSnapshots
+	ServiceFirewall   firewall.IncomingTrafficFirewall
+	AbuseDetector     *abuse.Detector
+	AutoPricer        *pricing.AutoPricer
+	HookRunner        *hooks.Runner
 
 	PortPool   *port.Pool
 	PortMapper mapping.PortMapper
@@ -158,16 +214,29 @@ type Dependencies struct {
 	P2PDialer   p2p.Dialer
 	P2PListener p2p.Listener
 
+	ManagementAgent  *management.Agent
+	ManagementRemote *management.Remote
+
 	Authenticator    *auth.Authenticator
 	JWTAuthenticator *auth.JWTAuthenticator
 	UIServer         UIServer
 	Transactor       *registry.Transactor
+	TransactorQueue  *registry.TransactorQueue
 	Affiliator       *registry.Affiliator
 	BCHelper         *paymentClient.MultichainBlockchainClient
+	GasPriceProvider *gasprice.Provider
 
 	LogCollector *logconfig.Collector
 	Reporter     *feedback.Reporter
 
+	ErrorLog           *diagnostics.ErrorLog
+	DiagnosticsBundler *diagnostics.Bundler
+
+	Updater *updater.Updater
+
+	FeatureFlags    *featureflag.Flags
+	FeatureFlagFeed *featureflag.Feed
+
 	BeneficiarySaver    *beneficiary.Saver
 	BeneficiaryProvider *beneficiary.Provider
 
@@ -176,6 +245,9 @@ type Dependencies struct {
 	HermesPromiseStorage     *pingpong.HermesPromiseStorage
 	ConsumerBalanceTracker   *pingpong.ConsumerBalanceTracker
 	HermesChannelRepository  *pingpong.HermesChannelRepository
+	PromiseRecovery          *pingpong.PromiseRecovery
+	FreeTier                 *pingpong.FreeTier
+	DisputeKeeper            *dispute.Keeper
 	HermesPromiseSettler     pingpong.HermesPromiseSettler
 	HermesURLGetter          *pingpong.HermesURLGetter
 	HermesCaller             *pingpong.HermesCaller
@@ -188,8 +260,10 @@ type Dependencies struct {
 	MMN *mmn.MMN
 
 	PilvytisAPI         *pilvytis.API
+	PaymentGateways     *pilvytis.Gateways
 	PilvytisTracker     *pilvytis.StatusTracker
 	PilvytisOrderIssuer *pilvytis.OrderIssuer
+	AutoTopup           *pilvytis.AutoTopup
 
 	ObserverAPI *observer.API
 
@@ -198,12 +272,42 @@ type Dependencies struct {
 	PayoutAddressStorage *payout.AddressStorage
 	NodeStatusTracker    *node.MonitoringStatusTracker
 	NodeStatsTracker     *node.StatsTracker
+	EarningsGoalWatcher  *node.EarningsGoalWatcher
+	ReputationTracker    *node.ReputationTracker
+	UptimeTracker        *node.UptimeTracker
+	NATTraversalTracker  *node.NATTraversalTracker
+	AlertingEngine       *alerting.Engine
+	Notifier             *notify.Notifier
+	UplinkUsageTracker   *service.UplinkUsageTracker
+	PriceBookKeeper      *pricebook.Keeper
+	WarmupPool           *connection.WarmupPool
+	AutoConnectPolicy    *autoconnect.Policy
+	NetworkWatcher       *autoconnect.Watcher
+	MeteredGuard         *autoconnect.MeteredGuard
+	Preflight            *preflight.Checker
+	ClockSkewDetector    *clockskew.Detector
+	DDNSUpdater          *ddns.Updater
+	CamouflageServer     *camouflage.Server
 	uiVersionConfig      versionmanager.NodeUIVersionConfig
+	tlsConfig            *tls.Config
+	acmeServer           *http.Server
 }
 
+// earningsGoalCheckInterval limits how often earnings goal progress is rechecked on earnings changes.
+const earningsGoalCheckInterval = 10 * time.Minute
+
 // Bootstrap initiates all container dependencies
 func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 	logconfig.Configure(&nodeOptions.LogOptions)
+	di.ErrorLog = diagnostics.NewErrorLog(100)
+	log.Logger = log.Logger.Hook(di.ErrorLog)
+	log.Logger = log.Logger.Hook(crash.Setup(crash.ReportsDir(nodeOptions.Directories.Data)))
+
+	ipPrivacy, err := privacy.ParseIPMode(nodeOptions.ConsumerIPPrivacy)
+	if err != nil {
+		return err
+	}
+	privacy.SetConsumerIPMode(ipPrivacy)
 
 	netutil.LogNetworkStats()
 
@@ -221,13 +325,31 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 		return err
 	}
 
+	router.Recover()
+
+	di.TermsKeeper = tos.NewKeeper(config.Current, terms.TermsVersion)
+
+	if err := di.bootstrapFeatureFlags(nodeOptions.Directories.Data); err != nil {
+		return err
+	}
+
+	if err := di.bootstrapUpdater(nodeOptions); err != nil {
+		return err
+	}
+
 	if err := di.bootstrapFirewall(nodeOptions.Firewall); err != nil {
 		return err
 	}
 
 	di.bootstrapEventBus()
+	di.bootstrapClockSkewDetector()
 
-	if err := di.bootstrapStorage(nodeOptions.Directories.Storage); err != nil {
+	cipher, err := StorageCipher(nodeOptions.Directories.Data)
+	if err != nil {
+		return err
+	}
+
+	if err := di.bootstrapStorage(nodeOptions.Directories.Storage, cipher); err != nil {
 		return err
 	}
 
@@ -238,6 +360,9 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 	if err := di.bootstrapLocationComponents(nodeOptions); err != nil {
 		return err
 	}
+	if err := di.bootstrapServiceBindings(nodeOptions); err != nil {
+		return err
+	}
 	if err := di.bootstrapResidentCountry(); err != nil {
 		return err
 	}
@@ -257,6 +382,10 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 		return err
 	}
 
+	if err := di.bootstrapTLS(nodeOptions); err != nil {
+		return err
+	}
+
 	di.bootstrapUIServer(nodeOptions)
 	if err := di.bootstrapMMN(); err != nil {
 		return err
@@ -270,6 +399,10 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 	di.PortPool = port.NewFixedRangePool(portRange)
 
 	di.bootstrapP2P()
+	di.bootstrapWarmupPool()
+	if err := di.bootstrapManagement(nodeOptions); err != nil {
+		return err
+	}
 	di.SessionConnectivityStatusStorage = connectivity.NewStatusStorage()
 
 	if err := di.bootstrapServices(nodeOptions); err != nil {
@@ -296,6 +429,8 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 
 	di.handleNATStatusForPublicIP()
 
+	di.Updater.Confirm()
+
 	log.Info().Msg("Mysterium node started!")
 	return nil
 }
@@ -344,8 +479,120 @@ func (di *Dependencies) bootstrapP2P() {
 		return identity.NewVerifierIdentity(id)
 	}
 
-	di.P2PListener = p2p.NewListener(di.BrokerConnection, di.SignerFactory, identity.NewVerifierSigned(), di.IPResolver, di.EventBus)
-	di.P2PDialer = p2p.NewDialer(di.BrokerConnector, di.SignerFactory, verifierFactory, di.IPResolver, di.PortPool, di.EventBus)
+	exchangeLimits := p2p.DefaultExchangeLimits()
+	exchangeLimits.Rate = config.GetFloat64(config.FlagP2PExchangeRate)
+	exchangeLimits.PeerRate = config.GetFloat64(config.FlagP2PExchangePeerRate)
+	exchangeLimits.NewPeerRate = config.GetFloat64(config.FlagP2PExchangeNewPeerRate)
+	if config.GetBool(config.FlagP2PExchangeRequireRegistered) {
+		exchangeLimits.PeerAllowed = func(peerID identity.Identity) error {
+			status, err := di.IdentityRegistry.GetRegistrationStatus(config.GetInt64(config.FlagChainID), peerID)
+			if err != nil {
+				return err
+			}
+			if status != registry.Registered {
+				return fmt.Errorf("identity is not registered: %s", status)
+			}
+			return nil
+		}
+	}
+
+	decrypterFactory := func(id identity.Identity) identity.Decrypter {
+		return identity.NewDecrypter(di.Keystore, id)
+	}
+
+	di.P2PListener = p2p.NewListener(di.BrokerConnection, di.SignerFactory, decrypterFactory, identity.NewVerifierSigned(), di.IPResolver, di.EventBus, exchangeLimits, di.ServiceBindings, p2pTopicACL(), di.ClockSkewDetector.Now)
+	di.P2PDialer = p2p.NewDialer(di.BrokerConnector, di.SignerFactory, decrypterFactory, verifierFactory, di.IPResolver, di.PortPool, di.EventBus)
+}
+
+// p2pTopicACL resolves peer roles of provider p2p channels from the configured identities.
+func p2pTopicACL() p2p.TopicACL {
+	if !config.GetBool(config.FlagP2PACLEnabled) {
+		return p2p.TopicACL{}
+	}
+
+	roles := make(map[identity.Identity]p2p.PeerRole)
+	for _, address := range config.GetStringSlice(config.FlagP2PACLProviderIdentities) {
+		roles[identity.FromAddress(address)] = p2p.PeerRoleProvider
+	}
+	for _, address := range config.GetStringSlice(config.FlagP2PACLMonitoringIdentities) {
+		roles[identity.FromAddress(address)] = p2p.PeerRoleMonitoring
+	}
+
+	acl := p2p.DefaultTopicACL()
+	acl.Role = func(peerID identity.Identity) p2p.PeerRole {
+		if role, ok := roles[peerID]; ok {
+			return role
+		}
+		return p2p.PeerRoleConsumer
+	}
+	return acl
+}
+
+func (di *Dependencies) bootstrapWarmupPool() {
+	if !config.GetBool(config.FlagWarmupEnabled) {
+		return
+	}
+
+	var presetIDs []int
+	for _, preset := range config.GetStringSlice(config.FlagWarmupPresets) {
+		id, err := strconv.Atoi(preset)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Skipping invalid warm-up filter preset %q", preset)
+			continue
+		}
+		presetIDs = append(presetIDs, id)
+	}
+
+	di.WarmupPool = connection.NewWarmupPool(di.P2PDialer, di.ProposalRepository, di.IdentityManager, connection.WarmupConfig{
+		PresetIDs:         presetIDs,
+		ServiceType:       config.GetString(config.FlagWarmupServiceType),
+		Size:              config.GetInt(config.FlagWarmupSize),
+		Interval:          config.GetDuration(config.FlagWarmupInterval),
+		MaxAge:            config.GetDuration(config.FlagWarmupMaxAge),
+		KeepAliveInterval: config.GetDuration(config.FlagWarmupKeepAliveInterval),
+	})
+	di.WarmupPool.Start()
+}
+
+func (di *Dependencies) bootstrapAutoConnect() error {
+	trusted, err := autoconnect.ParseTrustedNetworks(
+		config.GetStringSlice(config.FlagAutoConnectTrustedSSIDs),
+		config.GetStringSlice(config.FlagAutoConnectTrustedGatewayMACs),
+		config.GetStringSlice(config.FlagAutoConnectTrustedCIDRs),
+	)
+	if err != nil {
+		return err
+	}
+	metered, err := autoconnect.ParseMeteredPolicy(config.GetString(config.FlagAutoConnectMetered))
+	if err != nil {
+		return err
+	}
+	if metered == autoconnect.MeteredCap && !config.GetBool(config.FlagAutoConnectMeteredCapHost) {
+		return fmt.Errorf("metered uplink cap throttles all host traffic on the uplink interface, allow it with --%s", config.FlagAutoConnectMeteredCapHost.Name)
+	}
+
+	autoConnectConfig := autoconnect.Config{
+		OnStart:             config.GetBool(config.FlagAutoConnectOnStart),
+		OnNetworkRestore:    config.GetBool(config.FlagAutoConnectOnNetworkRestore),
+		OnUntrustedWiFi:     config.GetBool(config.FlagAutoConnectOnUntrustedWiFi),
+		Trusted:             trusted,
+		DisconnectOnTrusted: config.GetBool(config.FlagAutoConnectDisconnectOnTrusted),
+		Metered:             metered,
+	}
+	di.NetworkWatcher = autoconnect.NewWatcher(di.EventBus, config.GetDuration(config.FlagAutoConnectNetworkCheckInterval))
+	di.AutoConnectPolicy = autoconnect.NewPolicy(di.MultiConnectionManager, di.ProposalRepository, di.NetworkWatcher, di.Storage, di.EventBus, autoConnectConfig)
+	if err := di.AutoConnectPolicy.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+	di.MeteredGuard = autoconnect.NewMeteredGuard(di.NetworkWatcher, di.Storage, metered, int(config.GetUInt64(config.FlagAutoConnectMeteredCap)))
+	if err := di.MeteredGuard.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+
+	if autoConnectConfig.WatchesNetwork() {
+		di.NetworkWatcher.Start()
+	}
+	return nil
 }
 
 func (di *Dependencies) createTequilaListener(nodeOptions node.Options) (net.Listener, error) {
@@ -353,6 +600,10 @@ func (di *Dependencies) createTequilaListener(nodeOptions node.Options) (net.Lis
 		return tequilapi.NewNoopListener()
 	}
 
+	if nodeOptions.TequilapiSocket.TCPDisabled() {
+		return tequilapi.NewUnixListener(nodeOptions.TequilapiSocket.Path, nodeOptions.TequilapiSocket.Mode)
+	}
+
 	tequilaListener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", nodeOptions.TequilapiAddress, nodeOptions.TequilapiPort))
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("the port %v seems to be taken. Either you're already running a node or it is already used by another application", nodeOptions.TequilapiPort))
@@ -360,6 +611,84 @@ func (di *Dependencies) createTequilaListener(nodeOptions node.Options) (net.Lis
 	return tequilaListener, nil
 }
 
+func (di *Dependencies) bootstrapFeatureFlags(dataDir string) error {
+	local, err := featureflag.ParseLocal(config.GetString(config.FlagFeatureFlags))
+	if err != nil {
+		return err
+	}
+	di.FeatureFlags = featureflag.NewFlags(local)
+
+	feedURL := config.GetString(config.FlagFeatureFlagsURL)
+	if feedURL == "" {
+		return nil
+	}
+
+	publicKey, err := hex.DecodeString(config.GetString(config.FlagFeatureFlagsPublicKey))
+	if err != nil {
+		return errors.Wrap(err, "invalid feature flag feed public key")
+	}
+	interval := config.GetDuration(config.FlagFeatureFlagsRefreshInterval)
+	if interval <= 0 {
+		return errors.Errorf("invalid feature flag feed refresh interval %s, it must be positive", interval)
+	}
+
+	di.FeatureFlagFeed = featureflag.NewFeed(
+		di.FeatureFlags,
+		feedURL,
+		publicKey,
+		interval,
+		filepath.Join(dataDir, "features.json"),
+	)
+	if err := di.FeatureFlagFeed.Load(); err != nil {
+		log.Warn().Err(err).Msg("Failed to load cached remote feature flags")
+	}
+	di.FeatureFlagFeed.Start()
+	return nil
+}
+
+func (di *Dependencies) bootstrapUpdater(options node.Options) error {
+	publicKey, err := hex.DecodeString(config.GetString(config.FlagUpdaterPublicKey))
+	if err != nil {
+		return errors.Wrap(err, "invalid updater public key")
+	}
+
+	activeSessions := func() int {
+		if di.StateKeeper == nil {
+			return 0
+		}
+		state := di.StateKeeper.GetState()
+		return len(state.Sessions) + len(state.Connections)
+	}
+
+	di.Updater, err = updater.New(
+		updater.Config{
+			BaseURL:      config.GetString(config.FlagUpdaterURL),
+			Channel:      config.GetString(config.FlagUpdaterChannel),
+			PublicKey:    publicKey,
+			DrainTimeout: config.GetDuration(config.FlagUpdaterDrainTimeout),
+			Allowed: func() bool {
+				return di.FeatureFlags.Enabled(featureflag.SelfUpdate)
+			},
+		},
+		options.Directories.Data,
+		metadata.Version,
+		activeSessions,
+		utils.SoftKiller(di.Shutdown),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := di.Updater.Recover(); err != nil {
+		if errors.Is(err, updater.ErrRolledBack) {
+			// Startup is aborted, the node command restarts into the restored binary once shut down.
+			log.Warn().Err(err).Msg("Restarting into the previous node version")
+		}
+		return err
+	}
+	return nil
+}
+
 func (di *Dependencies) bootstrapStateKeeper(options node.Options) error {
 	deps := state.KeeperDeps{
 		Publisher:                 di.EventBus,
@@ -391,12 +720,33 @@ func (di *Dependencies) registerOpenvpnConnection(nodeOptions node.Options) {
 			nodeOptions.Directories.Runtime,
 			di.SignerFactory,
 			di.IPResolver,
+			di.mtuProber(),
+			firewall.DNSLeakProtection(config.GetString(config.FlagFirewallDNSLeakProtection)),
 		)
 	}
 	di.ConnectionRegistry.Register(service_openvpn.ServiceType, connectionFactory)
 }
 
+var errMTUDiscoveryDisabled = errors.New("path MTU discovery is disabled")
+
+// mtuProber returns path MTU prober for consumer tunnels or nil if discovery is disabled.
+// The feature flag is checked on every probe, so that a remote override applies without restart.
+func (di *Dependencies) mtuProber() netutil.PathMTUProber {
+	if !config.GetBool(config.FlagMTUDiscovery) {
+		return nil
+	}
+	return func(ip net.IP, size int) error {
+		if !di.FeatureFlags.Enabled(featureflag.MTUDiscovery) {
+			return errMTUDiscoveryDisabled
+		}
+		return netutil.PingProbe(ip, size)
+	}
+}
+
 func (di *Dependencies) registerNoopConnection() {
+	if !config.GetBool(config.FlagNoopEnabled) {
+		return
+	}
 	service_noop.Bootstrap()
 	di.ConnectionRegistry.Register(service_noop.ServiceType, service_noop.NewConnection)
 }
@@ -420,6 +770,19 @@ func (di *Dependencies) Shutdown() (err error) {
 		}
 	}
 
+	if di.ServiceSupervisor != nil {
+		di.ServiceSupervisor.Stop()
+	}
+
+	if di.ServiceScheduler != nil {
+		di.ServiceScheduler.Stop()
+	}
+
+	// Sessions are detached before services stop, so their tunnels are left running.
+	if di.SessionSnapshots != nil {
+		di.SessionSnapshots.Save()
+	}
+
 	if di.ServicesManager != nil {
 		if err := di.ServicesManager.Kill(); err != nil {
 			errs = append(errs, err)
@@ -430,12 +793,39 @@ func (di *Dependencies) Shutdown() (err error) {
 		di.PolicyOracle.Stop()
 	}
 
+	if di.Blocklist != nil {
+		di.Blocklist.Stop()
+	}
+
+	if di.FeatureFlagFeed != nil {
+		di.FeatureFlagFeed.Stop()
+	}
+
+	if di.AutoPricer != nil {
+		di.AutoPricer.Stop()
+	}
+
+	for _, updater := range di.GeoIPUpdaters {
+		updater.Stop()
+	}
+
 	if di.NATService != nil {
 		if err := di.NATService.Disable(); err != nil {
 			errs = append(errs, err)
 		}
 	}
 
+	unrouted := make(map[string]bool)
+	for _, binding := range di.ServiceBindings {
+		if unrouted[binding.IP] {
+			continue
+		}
+		unrouted[binding.IP] = true
+		if err := netutil.DeleteSourceRoute(bindingSource(binding), binding.Interface); err != nil {
+			log.Warn().Err(err).Msgf("Failed to delete source route of %s", binding.IP)
+		}
+	}
+
 	if di.EtherClientL1 != nil {
 		di.EtherClientL1.Close()
 	}
@@ -464,11 +854,72 @@ func (di *Dependencies) Shutdown() (err error) {
 		di.QualityClient.Stop()
 	}
 
+	if di.TransactorQueue != nil {
+		di.TransactorQueue.Stop()
+	}
+
+	if di.acmeServer != nil {
+		if err := di.acmeServer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if di.ReputationTracker != nil {
+		di.ReputationTracker.Stop()
+	}
+
+	if di.UptimeTracker != nil {
+		di.UptimeTracker.Stop()
+	}
+
+	if di.AlertingEngine != nil {
+		di.AlertingEngine.Stop()
+	}
+
+	if di.UplinkUsageTracker != nil {
+		di.UplinkUsageTracker.Stop()
+	}
+
+	if di.WarmupPool != nil {
+		di.WarmupPool.Stop()
+	}
+
+	if di.NetworkWatcher != nil {
+		di.NetworkWatcher.Stop()
+	}
+
+	if di.MeteredGuard != nil {
+		di.MeteredGuard.Stop()
+	}
+
+	if di.ClockSkewDetector != nil {
+		di.ClockSkewDetector.Stop()
+	}
+
+	if r, ok := di.IPResolver.(*ip.ConsensusResolver); ok {
+		r.Stop()
+	}
+
+	if di.CamouflageServer != nil {
+		di.CamouflageServer.Stop()
+	}
+
+	if di.ManagementAgent != nil {
+		di.ManagementAgent.Stop()
+	}
+	if di.ManagementRemote != nil {
+		di.ManagementRemote.Stop()
+	}
+
 	if di.ServiceFirewall != nil {
 		di.ServiceFirewall.Teardown()
 	}
 	firewall.Reset()
 
+	if di.StorageRetention != nil {
+		di.StorageRetention.Stop()
+	}
+
 	if di.Storage != nil {
 		if err := di.Storage.Close(); err != nil {
 			errs = append(errs, err)
@@ -480,8 +931,50 @@ func (di *Dependencies) Shutdown() (err error) {
 	return nil
 }
 
-func (di *Dependencies) bootstrapStorage(path string) error {
-	localStorage, err := boltdb.NewStorage(path)
+// StorageCipher returns the cipher of local storage and enables configuration secret encryption,
+// or returns nil if encryption is disabled.
+func StorageCipher(dataDir string) (*encryption.Cipher, error) {
+	source := encryption.KeySource(config.GetString(config.FlagStorageEncryption))
+	passphrase := config.GetString(config.FlagIdentityPassphrase)
+	if source == encryption.KeySourcePassphrase && passphrase != "" && !config.Current.IsSetByCLI(config.FlagIdentityPassphrase.Name) {
+		return nil, encryption.ErrPassphraseStored
+	}
+	key, err := encryption.LoadKey(source, dataDir, passphrase)
+	if err != nil || key == nil {
+		return nil, err
+	}
+
+	cipher, err := encryption.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := []string{config.FlagTequilapiPassword.Name, config.FlagMMNAPIKey.Name, config.FlagDDNSToken.Name, config.FlagDDNSURLTemplate.Name}
+	if source != encryption.KeySourcePassphrase {
+		secrets = append(secrets, config.FlagIdentityPassphrase.Name)
+	}
+	return cipher, config.Current.EnableSecretEncryption(cipher, secrets)
+}
+
+// OpenStorage opens local storage, encrypting it if cipher is given.
+func OpenStorage(path string, cipher *encryption.Cipher) (*boltdb.Bolt, error) {
+	if cipher == nil {
+		return boltdb.NewStorage(path)
+	}
+
+	storage, err := boltdb.NewStorageWithCodec(path, encryption.NewCodec(cipher))
+	if err != nil {
+		return nil, err
+	}
+	count, err := encryption.EncryptStorage(storage, cipher)
+	if count > 0 {
+		log.Info().Msgf("Encrypted %d plaintext storage records", count)
+	}
+	return storage, err
+}
+
+func (di *Dependencies) bootstrapStorage(path string, cipher *encryption.Cipher) error {
+	localStorage, err := OpenStorage(path, cipher)
 	if err != nil {
 		return err
 	}
@@ -500,9 +993,65 @@ func (di *Dependencies) bootstrapStorage(path string) error {
 	di.HermesPromiseStorage = pingpong.NewHermesPromiseStorage(di.Storage)
 	di.SessionStorage = consumer_session.NewSessionStorage(di.Storage)
 	di.SettlementHistoryStorage = pingpong.NewSettlementHistoryStorage(di.Storage)
+
+	di.StorageRetention = retention.NewJob(di.Storage, config.GetDuration(config.FlagStoragePruneInterval), RetentionTargets(di.Storage)...)
+	di.StorageRetention.Start()
+
 	return di.SessionStorage.Subscribe(di.EventBus)
 }
 
+// RetentionTargets returns records of the local storage which are pruned once past their retention periods.
+func RetentionTargets(bolt *boltdb.Bolt) []retention.Target {
+	sessions := consumer_session.NewSessionStorage(bolt)
+	receipts := receipt.NewStorage(bolt)
+	settlements := pingpong.NewSettlementHistoryStorage(bolt)
+	payments := config.GetDuration(config.FlagStorageRetentionPayments)
+
+	return []retention.Target{
+		{Name: "sessions", Retention: config.GetDuration(config.FlagStorageRetentionSessions), Prune: sessions.Prune},
+		{Name: "receipts", Retention: payments, Prune: receipts.Prune},
+		{Name: "settlements", Retention: payments, Prune: settlements.Prune},
+		{Name: "payment-trails", Retention: payments, Prune: dispute.NewStorage(bolt).PruneTrails},
+		{Name: "blocklist-audit", Retention: config.GetDuration(config.FlagStorageRetentionEvents), Prune: func(before time.Time) (int, error) {
+			return policy.PruneBlocklistAudit(bolt, before)
+		}},
+	}
+}
+
+func (di *Dependencies) bootstrapGasPrice() error {
+	strategy := gasprice.StrategyStandard
+	if s := config.GetString(config.FlagPaymentsGasPriceStrategy); s != "" {
+		var err error
+		if strategy, err = gasprice.ParseStrategy(s); err != nil {
+			return err
+		}
+	}
+
+	cfg := gasprice.Config{Strategy: strategy, CacheTTL: 30 * time.Second}
+	if maxGwei := config.GetFloat64(config.FlagPaymentsGasPriceMax); maxGwei > 0 {
+		cfg.MaxPrice = gasprice.GweiToWei(maxGwei)
+	}
+
+	var oracle gasprice.Oracle
+	if url := config.GetString(config.FlagPaymentsGasPriceOracleURL); url != "" {
+		oracle = gasprice.NewHTTPOracle(di.HTTPClient, url)
+	}
+
+	di.GasPriceProvider = gasprice.NewProvider(cfg, oracle, di.BCHelper)
+	return nil
+}
+
+// chainInUse returns an error while connections or services depend on the active chain.
+func (di *Dependencies) chainInUse() error {
+	if len(di.StateKeeper.GetState().Connections) > 0 {
+		return errors.New("connection is active")
+	}
+	if di.ServicesManager != nil && len(di.ServicesManager.List(false)) > 0 {
+		return errors.New("services are running")
+	}
+	return nil
+}
+
 func (di *Dependencies) getHermesURL(nodeOptions node.Options) (string, error) {
 	log.Info().Msgf("Node chain id %v", nodeOptions.ChainID)
 	addr := common.HexToAddress(nodeOptions.Chains.Chain2.HermesID)
@@ -556,6 +1105,7 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 		EventBus:        di.EventBus,
 		Signer:          di.SignerFactory,
 		Chains:          []int64{nodeOptions.Chains.Chain1.ChainID, nodeOptions.Chains.Chain2.ChainID},
+		TimeNow:         di.ClockSkewDetector.Now,
 	})
 
 	if err := di.HermesPromiseHandler.Subscribe(di.EventBus); err != nil {
@@ -568,7 +1118,18 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 
 	di.bootstrapBeneficiarySaver(nodeOptions)
 
+	var p2pDialer p2p.Dialer = di.P2PDialer
+	if di.WarmupPool != nil {
+		p2pDialer = di.WarmupPool
+	}
+
+	di.ReceiptKeeper = receipt.NewKeeper(receipt.NewStorage(di.Storage), di.SignerFactory)
+	if err := di.ReceiptKeeper.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+
 	di.ConnectionRegistry = connection.NewRegistry()
+	di.PreConnectHooks = connection.NewPreConnectHooks()
 	di.MultiConnectionManager = connection.NewMultiConnectionManager(func() connection.Manager {
 		return connection.NewManager(
 			pingpong.ExchangeFactoryFunc(
@@ -582,19 +1143,30 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 			di.ConnectionRegistry.CreateConnection,
 			di.EventBus,
 			di.IPResolver,
-			di.LocationResolver,
+			di.ConsumerLocationResolver,
 			connection.DefaultConfig(),
 			connection.DefaultStatsReportInterval,
 			connection.NewValidator(
 				di.ConsumerBalanceTracker,
 				di.IdentityManager,
 			),
-			di.P2PDialer,
+			di.PreConnectHooks,
+			p2pDialer,
+			di.ReceiptKeeper,
 			di.allowTrustedDomainBypassTunnel,
 			di.disallowTrustedDomainBypassTunnel,
 		)
 	})
 
+	di.ConnectionTransitions = connection.NewTransitionLog(connection.DefaultTransitionLogSize)
+	if err := di.ConnectionTransitions.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+
+	if err := di.bootstrapAutoConnect(); err != nil {
+		return err
+	}
+
 	di.NATProber = natprobe.NewNATProber(di.MultiConnectionManager, di.EventBus)
 
 	di.LogCollector = logconfig.NewCollector(&logconfig.CurrentLogOptions)
@@ -608,6 +1180,10 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 		return err
 	}
 
+	di.ChainSwitcher = chains.NewSwitcher(nodeOptions.Chains.All(), config.Current, di.EventBus, di.chainInUse)
+
+	di.DiagnosticsBundler = diagnostics.NewBundler(nodeOptions.Directories.Data, di.LogCollector, config.Current.GetConfig, di.NATProber, di.StateKeeper, di.ErrorLog)
+
 	di.bootstrapPilvytis(nodeOptions)
 
 	sessionProviderFunc := func(providerID string) (results []node.Session) {
@@ -622,6 +1198,11 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 		di.IdentityManager,
 	)
 
+	di.NATTraversalTracker = node.NewNATTraversalTracker()
+	if err := di.NATTraversalTracker.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+
 	di.NodeStatsTracker = node.NewNodeStatsTracker(
 		di.QualityClient.ProviderStatuses,
 		di.QualityClient.ProviderSessionsList,
@@ -631,8 +1212,92 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 		di.QualityClient.ProviderEarningsSeries,
 		di.QualityClient.ProviderSessionsSeries,
 		di.QualityClient.ProviderTransferredDataSeries,
+		func(id identity.Identity, from, to time.Time) ([]node.SessionItem, error) {
+			filter := consumer_session.NewFilter().
+				SetDirection(consumer_session.DirectionProvided).
+				SetProviderID(id).
+				SetStartedFrom(from).
+				SetStartedTo(to)
+			sessions, err := di.SessionStorage.List(filter)
+			if err != nil {
+				return nil, err
+			}
+
+			items := make([]node.SessionItem, 0, len(sessions))
+			for _, s := range sessions {
+				earning := decimal.Zero
+				if s.Tokens != nil {
+					earning = decimal.NewFromBigInt(s.Tokens, -18)
+				}
+				items = append(items, node.SessionItem{
+					ID:              string(s.SessionID),
+					ConsumerCountry: s.ConsumerCountry,
+					ServiceType:     s.ServiceType,
+					Duration:        int64(s.GetDuration().Seconds()),
+					StartedAt:       s.Started.Unix(),
+					Earning:         earning.String(),
+					Transferred:     int64(s.DataSent + s.DataReceived),
+				})
+			}
+			return items, nil
+		},
 		di.IdentityManager,
+		func() node.EarningsGoalConfig {
+			return node.EarningsGoalConfig{
+				Amount: decimal.NewFromFloat(config.GetFloat64(config.FlagEarningsGoal)),
+				Period: node.EarningsGoalPeriod(config.GetString(config.FlagEarningsGoalPeriod)),
+			}
+		},
+		di.NATTraversalTracker,
 	)
+	di.EarningsGoalWatcher = node.NewEarningsGoalWatcher(di.NodeStatsTracker, di.EventBus, earningsGoalCheckInterval)
+	if err := di.EarningsGoalWatcher.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+
+	proposalsFunc := func() ([]market.ServiceProposal, error) {
+		priced, err := di.ProposalRepository.Proposals(&proposal.Filter{IncludeMonitoringFailed: true})
+		if err != nil {
+			return nil, err
+		}
+		proposals := make([]market.ServiceProposal, len(priced))
+		for i, p := range priced {
+			proposals[i] = p.ServiceProposal
+		}
+		return proposals, nil
+	}
+	di.ReputationTracker = node.NewReputationTracker(proposalsFunc, sessionProviderFunc, di.Storage, di.IdentityManager, time.Hour)
+	di.ReputationTracker.Start()
+
+	di.UptimeTracker = node.NewUptimeTracker(di.Storage, di.NodeStatusTracker, time.Minute)
+	if err := di.UptimeTracker.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+	di.UptimeTracker.Start()
+
+	di.AlertingEngine = alerting.NewEngine(di.Storage, alerting.Deps{
+		Identities:   di.IdentityManager,
+		Balances:     di.ConsumerBalanceTracker,
+		SessionStats: di.SessionStorage,
+		Monitoring:   di.NodeStatusTracker,
+	}, time.Minute)
+	di.AlertingEngine.AddSink("events", alerting.NewEventSink(di.EventBus))
+
+	// Notification secrets are encrypted with a key kept outside of the database, so copies of it don't leak them.
+	notifyKey, err := encryption.LoadFileKey(filepath.Join(nodeOptions.Directories.Data, "notifications.key"))
+	if err != nil {
+		return err
+	}
+	notifyCipher, err := encryption.NewCipher(notifyKey)
+	if err != nil {
+		return err
+	}
+	di.Notifier = notify.NewNotifier(di.Storage, di.HTTPClient, notifyCipher)
+	if err := di.Notifier.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+	di.AlertingEngine.AddSink("notifications", di.Notifier)
+	di.AlertingEngine.Start()
 
 	di.HermesMigrator = di.bootstrapHermesMigrator()
 	if err := di.HermesMigrator.Subscribe(di.EventBus); err != nil {
@@ -666,6 +1331,16 @@ func (di *Dependencies) bootstrapNetworkComponents(options node.Options) (err er
 		network = metadata.TestnetDefinition
 	}
 
+	if optionsNetwork.PrivateNetworkDefinition != "" {
+		privateNetwork, err := metadata.LoadPrivateNetwork(optionsNetwork.PrivateNetworkDefinition)
+		if err != nil {
+			return err
+		}
+		log.Info().Msgf("Running in private network %q with %d allowed identities", privateNetwork.Name, len(privateNetwork.AllowedIdentities))
+		di.PrivateNetwork = privateNetwork
+		network = privateNetwork.Definition
+	}
+
 	// override defined values one by one from options
 	if optionsNetwork.DiscoveryAddress != metadata.DefaultNetwork.DiscoveryAddress {
 		network.DiscoveryAddress = optionsNetwork.DiscoveryAddress
@@ -713,7 +1388,19 @@ func (di *Dependencies) bootstrapNetworkComponents(options node.Options) (err er
 		brokerURLs[i] = brokerURL
 	}
 
-	di.BrokerConnector = nats.NewBrokerConnector(dialer.DialContext, resolver)
+	switch transport := config.GetString(config.FlagBrokerTransport); transport {
+	case config.BrokerTransportHTTPS:
+		relayURL := config.GetString(config.FlagBrokerRelayURL)
+		if relayURL == "" {
+			return fmt.Errorf("broker transport %q requires --%s", transport, config.FlagBrokerRelayURL.Name)
+		}
+		log.Info().Msgf("Using HTTPS broker relay: %s", relayURL)
+		di.BrokerConnector = relay.NewConnector(relayURL, di.HTTPTransport)
+	case "", config.BrokerTransportNATS:
+		di.BrokerConnector = nats.NewBrokerConnector(dialer.DialContext, resolver, di.EventBus)
+	default:
+		return fmt.Errorf("unknown broker transport %q", transport)
+	}
 	if di.BrokerConnection, err = di.BrokerConnector.Connect(brokerURLs...); err != nil {
 		return err
 	}
@@ -782,6 +1469,9 @@ func (di *Dependencies) bootstrapNetworkComponents(options node.Options) (err er
 	di.ObserverAPI = observer.NewAPI(options.ObserverAddress, time.Second*30)
 	di.bootstrapAddressProvider(options)
 	di.HermesURLGetter = pingpong.NewHermesURLGetter(di.BCHelper, di.AddressProvider, di.ObserverAPI)
+	if err := di.bootstrapGasPrice(); err != nil {
+		return err
+	}
 
 	registryStorage := registry.NewRegistrationStatusStorage(di.Storage)
 
@@ -794,6 +1484,16 @@ func (di *Dependencies) bootstrapNetworkComponents(options node.Options) (err er
 	di.SignerFactory = func(id identity.Identity) identity.Signer {
 		return identity.NewSigner(di.Keystore, id)
 	}
+	// Pending transactor requests are encrypted with a key kept outside of the database, like notification secrets.
+	transactorKey, err := encryption.LoadFileKey(filepath.Join(options.Directories.Data, "transactor.key"))
+	if err != nil {
+		return err
+	}
+	transactorCipher, err := encryption.NewCipher(transactorKey)
+	if err != nil {
+		return err
+	}
+	di.TransactorQueue = registry.NewTransactorQueue(di.Storage, transactorCipher, registry.DefaultTransactorQueueConfig())
 	di.Transactor = registry.NewTransactor(
 		di.HTTPClient,
 		options.Transactor.TransactorEndpointAddress,
@@ -802,12 +1502,15 @@ func (di *Dependencies) bootstrapNetworkComponents(options node.Options) (err er
 		di.EventBus,
 		di.BCHelper,
 		options.Transactor.TransactorFeesValidTime,
+		di.TransactorQueue,
 	)
+	di.TransactorQueue.Start(di.Transactor.HandleJob)
 	di.Affiliator = registry.NewAffiliator(di.HTTPClient, options.Affiliator.AffiliatorEndpointAddress)
 
 	registryCfg := registry.IdentityRegistryConfig{
 		TransactorPollInterval: options.Payments.RegistryTransactorPollInterval,
 		TransactorPollTimeout:  options.Payments.RegistryTransactorPollTimeout,
+		StatusCacheTTL:         options.Payments.RegistryStatusCacheTTL,
 	}
 
 	if di.IdentityRegistry, err = registry.NewIdentityRegistryContract(di.EtherClientL2, di.AddressProvider, registryStorage, di.EventBus, di.HermesCaller, di.Transactor, registryCfg); err != nil {
@@ -834,6 +1537,20 @@ func (di *Dependencies) bootstrapEventBus() {
 	di.EventBus = eventbus.New()
 }
 
+func (di *Dependencies) bootstrapClockSkewDetector() {
+	if !config.GetBool(config.FlagClockSkewDetection) {
+		return
+	}
+
+	di.ClockSkewDetector = clockskew.NewDetector(di.EventBus, clockskew.Config{
+		Server:          config.GetString(config.FlagClockNTPServer),
+		Interval:        config.GetDuration(config.FlagClockCheckInterval),
+		Tolerance:       config.GetDuration(config.FlagClockTolerance),
+		DangerThreshold: config.GetDuration(config.FlagClockDangerThreshold),
+	})
+	di.ClockSkewDetector.Start()
+}
+
 func (di *Dependencies) bootstrapIdentityComponents(options node.Options) error {
 	var ks *keystore.KeyStore
 	if options.Keystore.UseLightweight {
@@ -855,6 +1572,9 @@ func (di *Dependencies) bootstrapIdentityComponents(options node.Options) error
 		identity.NewIdentityCache(options.Directories.Keystore, "remember.json"),
 		di.SignerFactory,
 	)
+	if di.PrivateNetwork != nil {
+		di.IdentitySelector = identity_selector.NewAllowlistHandler(di.IdentitySelector, di.PrivateNetwork.IsIdentityAllowed)
+	}
 	di.IdentityMover = identity.NewMover(
 		di.Keystore,
 		di.EventBus,
@@ -879,7 +1599,7 @@ func (di *Dependencies) bootstrapQualityComponents(options node.OptionsQuality)
 	case node.QualityTypeElastic:
 		transport = quality.NewElasticSearchTransport(di.HTTPClient, options.Address, 10*time.Second)
 	case node.QualityTypeMORQA:
-		transport = quality.NewMORQATransport(di.QualityClient, di.LocationResolver)
+		transport = quality.NewMORQATransport(di.QualityClient, di.ConsumerLocationResolver)
 	case node.QualityTypeNone:
 		transport = quality.NewNoopTransport()
 	default:
@@ -906,13 +1626,34 @@ func (di *Dependencies) bootstrapQualityComponents(options node.OptionsQuality)
 	return nil
 }
 
+// newPublicIPResolver creates public IP resolver which detects public IP changes. With consensus
+// enabled, the IP is resolved by querying multiple sources.
+func (di *Dependencies) newPublicIPResolver(ipResolver ip.Resolver, bindAddress string) *ip.ConsensusResolver {
+	var sources []ip.Source
+	if config.GetBool(config.FlagIPConsensus) {
+		sources = append(sources, ip.NewResolverSource("ip-detector", ipResolver))
+		sources = append(sources, ip.NewFallbackSources(di.HTTPClient, 3)...)
+		for _, server := range config.GetStringSlice(config.FlagSTUNservers) {
+			sources = append(sources, ip.NewSTUNSource(server, bindAddress))
+		}
+		sources = append(sources, ip.NewUPnPSource(portmap.UPnP()))
+	}
+
+	return ip.NewConsensusResolver(ipResolver, di.EventBus, ip.ConsensusConfig{
+		Timeout:       10 * time.Second,
+		CacheDuration: 5 * time.Minute,
+		CheckInterval: config.GetDuration(config.FlagIPCheckInterval),
+		MinConfidence: config.GetFloat64(config.FlagIPConsensusMinConfidence),
+	}, sources...)
+}
+
 func (di *Dependencies) bootstrapLocationComponents(options node.Options) (err error) {
 	if err = di.AllowURLAccess(options.Location.IPDetectorURL); err != nil {
 		return errors.Wrap(err, "failed to add firewall exception")
 	}
 
 	ipResolver := ip.NewResolver(di.HTTPClient, options.BindAddress, options.Location.IPDetectorURL, ip.IPFallbackAddresses)
-	di.IPResolver = ip.NewCachedResolver(ipResolver, 5*time.Minute)
+	di.IPResolver = di.newPublicIPResolver(ipResolver, options.BindAddress)
 
 	var resolver location.Resolver
 	switch options.Location.Type {
@@ -934,7 +1675,23 @@ func (di *Dependencies) bootstrapLocationComponents(options node.Options) (err e
 		return err
 	}
 
+	// GeoIP lookups of consumers are served from the local database whatever location type is used.
+	geoIP, ok := resolver.(*location.DBResolver)
+	if !ok {
+		if geoIP, err = location.NewBuiltInResolver(di.IPResolver); err != nil {
+			return err
+		}
+	}
+	di.GeoIPResolver = geoIP
+	if err := di.bootstrapGeoIPUpdaters(options); err != nil {
+		return err
+	}
+
 	di.LocationResolver = location.NewCache(resolver, di.EventBus, time.Minute*5)
+	di.ConsumerLocationResolver, err = location.NewPrivacyResolver(di.LocationResolver, location.PrivacyMode(options.Location.ConsumerPrivacy), options.Location.ConsumerCountry)
+	if err != nil {
+		return err
+	}
 
 	if !config.GetBool(config.FlagProxyMode) {
 		err = di.EventBus.SubscribeAsync(connectionstate.AppTopicConnectionState, di.LocationResolver.HandleConnectionEvent)
@@ -951,6 +1708,85 @@ func (di *Dependencies) bootstrapLocationComponents(options node.Options) (err e
 	return nil
 }
 
+// bootstrapServiceBindings pins configured services to their network interfaces. Traffic from the bound
+// source IPs is routed through the interface, so that replies leave via the same uplink.
+func (di *Dependencies) bootstrapServiceBindings(options node.Options) error {
+	targets, err := ip.ParseBindings(options.ServiceBindings)
+	if err != nil {
+		return err
+	}
+
+	newResolver := func(sourceIP string) ip.Resolver {
+		httpClient := requests.NewHTTPClient(sourceIP, requests.DefaultTimeout)
+		return ip.NewCachedResolver(ip.NewResolver(httpClient, sourceIP, options.Location.IPDetectorURL, ip.IPFallbackAddresses), 5*time.Minute)
+	}
+
+	di.ServiceBindings = make(ip.Bindings)
+	routed := make(map[string]bool)
+	for serviceType, target := range targets {
+		binding, err := ip.NewBinding(target, newResolver)
+		if err != nil {
+			return fmt.Errorf("could not bind service %s to %s: %w", serviceType, target, err)
+		}
+		di.ServiceBindings[serviceType] = binding
+		log.Info().Msgf("Binding service %s to %s (%s)", serviceType, binding.IP, binding.Interface)
+
+		if routed[binding.IP] {
+			continue
+		}
+		routed[binding.IP] = true
+		if err := netutil.AddSourceRoute(bindingSource(binding), binding.Interface); err != nil {
+			log.Warn().Err(err).Msgf("Failed to route traffic from %s through %s", binding.IP, binding.Interface)
+		}
+	}
+	return nil
+}
+
+func bindingSource(binding ip.Binding) net.IPNet {
+	return net.IPNet{IP: net.ParseIP(binding.IP), Mask: net.CIDRMask(32, 32)}
+}
+
+func (di *Dependencies) bootstrapGeoIPUpdaters(options node.Options) error {
+	geoIP := options.Location.GeoIP
+	dir := filepath.Join(options.Directories.Data, "geoip")
+	if (geoIP.CountryURL != "" || geoIP.ASNURL != "") && geoIP.UpdateInterval < location.MinDBUpdateInterval {
+		return errors.Errorf("invalid GeoIP update interval %s, it must be at least %s", geoIP.UpdateInterval, location.MinDBUpdateInterval)
+	}
+
+	if geoIP.CountryURL != "" {
+		if err := di.AllowURLAccess(geoIP.CountryURL); err != nil {
+			return err
+		}
+		di.GeoIPUpdaters = append(di.GeoIPUpdaters, location.NewDBUpdater(
+			di.HTTPClient,
+			geoIP.CountryURL,
+			filepath.Join(dir, "country.mmdb"),
+			geoIP.UpdateInterval,
+			di.GeoIPResolver.CountryDBBuildEpoch,
+			di.GeoIPResolver.SetCountryDB,
+		))
+	}
+
+	if geoIP.ASNURL != "" {
+		if err := di.AllowURLAccess(geoIP.ASNURL); err != nil {
+			return err
+		}
+		di.GeoIPUpdaters = append(di.GeoIPUpdaters, location.NewDBUpdater(
+			di.HTTPClient,
+			geoIP.ASNURL,
+			filepath.Join(dir, "asn.mmdb"),
+			geoIP.UpdateInterval,
+			di.GeoIPResolver.ASNDBBuildEpoch,
+			di.GeoIPResolver.SetASNDB,
+		))
+	}
+
+	for _, updater := range di.GeoIPUpdaters {
+		updater.Start()
+	}
+	return nil
+}
+
 func (di *Dependencies) bootstrapAuthenticator() error {
 	key, err := auth.NewJWTEncryptionKey(di.Storage)
 	if err != nil {
@@ -964,8 +1800,33 @@ func (di *Dependencies) bootstrapAuthenticator() error {
 
 func (di *Dependencies) bootstrapPilvytis(options node.Options) {
 	di.PilvytisAPI = pilvytis.NewAPI(di.HTTPClient, options.PilvytisAddress, di.SignerFactory, di.LocationResolver, di.AddressProvider)
-	di.PilvytisTracker = pilvytis.NewStatusTracker(di.PilvytisAPI, di.IdentityManager, di.EventBus, time.Minute)
-	di.PilvytisOrderIssuer = pilvytis.NewOrderIssuer(di.PilvytisAPI, di.PilvytisTracker)
+
+	var gateways []pilvytis.Gateway
+	if config.GetBool(config.FlagPaymentsDirectTopup) && di.FeatureFlags.Enabled(featureflag.DirectTopup) {
+		gateways = append(gateways, pilvytis.NewDirectGateway(di.Storage, di.AddressProvider, di.BCHelper))
+	}
+	di.PaymentGateways = pilvytis.NewGateways(di.PilvytisAPI, gateways...)
+
+	di.PilvytisTracker = pilvytis.NewStatusTracker(di.PaymentGateways, di.IdentityManager, di.EventBus, time.Minute)
+	di.PilvytisOrderIssuer = pilvytis.NewOrderIssuer(di.PaymentGateways, di.PilvytisTracker)
+
+	var autoTopupAmount string
+	if amount := config.GetFloat64(config.FlagPaymentsAutoTopupAmount); amount > 0 {
+		autoTopupAmount = strconv.FormatFloat(amount, 'f', -1, 64)
+	}
+	autoTopupConfig := pilvytis.AutoTopupConfig{
+		Threshold:   crypto.FloatToBigMyst(config.GetFloat64(config.FlagPaymentsAutoTopupThreshold)),
+		Amount:      autoTopupAmount,
+		Gateway:     config.GetString(config.FlagPaymentsAutoTopupGateway),
+		PayCurrency: config.GetString(config.FlagPaymentsAutoTopupCurrency),
+		Interval:    config.GetDuration(config.FlagPaymentsAutoTopupInterval),
+	}
+	if autoTopupConfig.Enabled() {
+		di.AutoTopup = pilvytis.NewAutoTopup(autoTopupConfig, di.PilvytisOrderIssuer, di.LocationResolver, di.EventBus)
+		if err := di.AutoTopup.Subscribe(di.EventBus); err != nil {
+			log.Warn().Err(err).Msg("Failed to subscribe auto top-up to events")
+		}
+	}
 
 	go di.PilvytisTracker.Track()
 	di.PilvytisTracker.SubscribeAsync(di.EventBus)
@@ -982,6 +1843,12 @@ func (di *Dependencies) bootstrapFirewall(options node.OptionsFirewall) error {
 		return err
 	}
 
+	for _, app := range options.AllowedApps {
+		if _, err := firewall.AllowApplicationAccess(app); err != nil {
+			return err
+		}
+	}
+
 	if options.BlockAlways {
 		bindAddress := "0.0.0.0"
 		resolver := ip.NewResolver(di.HTTPClient, bindAddress, "", ip.IPFallbackAddresses)
@@ -1132,6 +1999,9 @@ func getUDPListenPorts() (port.Range, error) {
 func (di *Dependencies) allowTrustedDomainBypassTunnel() {
 	allow := []string{di.NetworkDefinition.DiscoveryAddress}
 	allow = append(allow, di.NetworkDefinition.BrokerAddresses...)
+	if relayURL := config.GetString(config.FlagBrokerRelayURL); relayURL != "" {
+		allow = append(allow, relayURL)
+	}
 
 	if err := router.ExcludeURL(allow...); err != nil {
 		log.Error().Err(err).Msgf("Failed to exclude routes for trusted domains: %v", allow)
@@ -1141,6 +2011,9 @@ func (di *Dependencies) allowTrustedDomainBypassTunnel() {
 func (di *Dependencies) disallowTrustedDomainBypassTunnel() {
 	allow := []string{di.NetworkDefinition.DiscoveryAddress}
 	allow = append(allow, di.NetworkDefinition.BrokerAddresses...)
+	if relayURL := config.GetString(config.FlagBrokerRelayURL); relayURL != "" {
+		allow = append(allow, relayURL)
+	}
 
 	if err := router.RemoveExcludedURL(allow...); err != nil {
 		log.Error().Err(err).Msgf("Failed to remove excluded routes for trusted domains: %v", allow)
@@ -18,32 +18,61 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
 	"time"
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/ddns"
+	"github.com/mysteriumnetwork/node/core/hooks"
+	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/core/preflight"
+	"github.com/mysteriumnetwork/node/core/pricebook"
+	"github.com/mysteriumnetwork/node/core/pricing"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/service/abuse"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/featureflag"
+	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mmn"
 	"github.com/mysteriumnetwork/node/nat"
 	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/p2p/camouflage"
+	p2pnat "github.com/mysteriumnetwork/node/p2p/nat"
 	"github.com/mysteriumnetwork/node/services/datatransfer"
 	service_noop "github.com/mysteriumnetwork/node/services/noop"
 	service_openvpn "github.com/mysteriumnetwork/node/services/openvpn"
 	openvpn_service "github.com/mysteriumnetwork/node/services/openvpn/service"
 	"github.com/mysteriumnetwork/node/services/scraping"
+	"github.com/mysteriumnetwork/node/services/speedtest"
 	"github.com/mysteriumnetwork/node/services/wireguard"
 	wireguard_connection "github.com/mysteriumnetwork/node/services/wireguard/connection"
 	"github.com/mysteriumnetwork/node/services/wireguard/endpoint"
 	"github.com/mysteriumnetwork/node/services/wireguard/resources"
 	wireguard_service "github.com/mysteriumnetwork/node/services/wireguard/service"
+	"github.com/mysteriumnetwork/node/session/dispute"
 	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/utils/netutil"
+	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
 
+// privateNetworkIdentities returns identities allowed in the private network, nil when running in public network.
+func (di *Dependencies) privateNetworkIdentities() []string {
+	if di.PrivateNetwork == nil {
+		return nil
+	}
+	return di.PrivateNetwork.AllowedIdentities
+}
+
 // bootstrapServices loads all the components required for running services
 func (di *Dependencies) bootstrapServices(nodeOptions node.Options) error {
 	if nodeOptions.Consumer {
@@ -51,6 +80,10 @@ func (di *Dependencies) bootstrapServices(nodeOptions node.Options) error {
 		return nil
 	}
 
+	if err := di.bootstrapPreflight(nodeOptions); err != nil {
+		return err
+	}
+
 	err := di.bootstrapServiceComponents(nodeOptions)
 	if err != nil {
 		return errors.Wrap(err, "service bootstrap failed")
@@ -61,6 +94,7 @@ func (di *Dependencies) bootstrapServices(nodeOptions node.Options) error {
 	di.bootstrapServiceWireguard(nodeOptions)
 	di.bootstrapServiceScraping(nodeOptions)
 	di.bootstrapServiceDataTransfer(nodeOptions)
+	di.bootstrapServiceSpeedtest(nodeOptions)
 
 	return nil
 }
@@ -77,13 +111,14 @@ func (di *Dependencies) bootstrapServiceWireguard(nodeOptions node.Options) {
 			wgOptions := serviceOptions.(wireguard_service.Options)
 
 			svc := wireguard_service.NewManager(
-				di.IPResolver,
+				di.serviceIPResolver(wireguard.ServiceType),
 				loc.Country,
-				di.NATService,
+				di.serviceNATService(wireguard.ServiceType),
 				di.EventBus,
 				wgOptions,
 				di.PortPool,
 				di.ServiceFirewall,
+				di.CamouflageServer,
 			)
 			return svc, nil
 		},
@@ -102,13 +137,14 @@ func (di *Dependencies) bootstrapServiceScraping(nodeOptions node.Options) {
 			wgOptions := serviceOptions.(wireguard_service.Options)
 
 			svc := wireguard_service.NewManager(
-				di.IPResolver,
+				di.serviceIPResolver(scraping.ServiceType),
 				loc.Country,
-				di.NATService,
+				di.serviceNATService(scraping.ServiceType),
 				di.EventBus,
 				wgOptions,
 				di.PortPool,
 				di.ServiceFirewall,
+				nil,
 			)
 			return svc, nil
 		},
@@ -127,13 +163,14 @@ func (di *Dependencies) bootstrapServiceDataTransfer(nodeOptions node.Options) {
 			wgOptions := serviceOptions.(wireguard_service.Options)
 
 			svc := wireguard_service.NewManager(
-				di.IPResolver,
+				di.serviceIPResolver(datatransfer.ServiceType),
 				loc.Country,
-				di.NATService,
+				di.serviceNATService(datatransfer.ServiceType),
 				di.EventBus,
 				wgOptions,
 				di.PortPool,
 				di.ServiceFirewall,
+				nil,
 			)
 			return svc, nil
 		},
@@ -152,14 +189,17 @@ func (di *Dependencies) bootstrapServiceOpenvpn(nodeOptions node.Options) {
 		}
 
 		transportOptions := serviceOptions.(openvpn_service.Options)
+		if err := transportOptions.Hardening.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid OpenVPN hardening options: %w", err)
+		}
 
 		manager := openvpn_service.NewManager(
 			nodeOptions,
 			transportOptions,
 			loc.Country,
-			di.IPResolver,
+			di.serviceIPResolver(service_openvpn.ServiceType),
 			di.ServiceSessions,
-			di.NATService,
+			di.serviceNATService(service_openvpn.ServiceType),
 			di.PortPool,
 			di.EventBus,
 			di.ServiceFirewall,
@@ -170,6 +210,9 @@ func (di *Dependencies) bootstrapServiceOpenvpn(nodeOptions node.Options) {
 }
 
 func (di *Dependencies) bootstrapServiceNoop(nodeOptions node.Options) {
+	if !config.GetBool(config.FlagNoopEnabled) {
+		return
+	}
 	di.ServiceRegistry.Register(
 		service_noop.ServiceType,
 		func(serviceOptions service.Options) (service.Service, error) {
@@ -178,6 +221,23 @@ func (di *Dependencies) bootstrapServiceNoop(nodeOptions node.Options) {
 	)
 }
 
+func (di *Dependencies) bootstrapServiceSpeedtest(nodeOptions node.Options) {
+	di.ServiceRegistry.Register(
+		speedtest.ServiceType,
+		func(serviceOptions service.Options) (service.Service, error) {
+			return speedtest.NewManager(di.EventBus, serviceOptions.(speedtest.Options)), nil
+		},
+	)
+}
+
+// autoPriceBound converts the upper bound of automatic price in MYST, nil if it is unbounded.
+func autoPriceBound(myst float64) *big.Int {
+	if myst <= 0 {
+		return nil
+	}
+	return crypto.FloatToBigMyst(myst)
+}
+
 func (di *Dependencies) bootstrapHermesPromiseSettler(nodeOptions node.Options) error {
 	di.HermesChannelRepository = pingpong.NewHermesChannelRepository(
 		di.HermesPromiseStorage,
@@ -195,6 +255,20 @@ func (di *Dependencies) bootstrapHermesPromiseSettler(nodeOptions node.Options)
 		return errors.Wrap(err, "could not subscribe channel repository to relevant events")
 	}
 
+	di.PromiseRecovery = pingpong.NewPromiseRecovery(
+		di.HermesPromiseStorage,
+		di.HermesCaller,
+		di.AddressProvider,
+		di.Keystore,
+		di.EventBus,
+		di.SignerFactory,
+	)
+
+	di.DisputeKeeper = dispute.NewKeeper(dispute.NewStorage(di.Storage), di.SignerFactory)
+	if err := di.DisputeKeeper.Subscribe(di.EventBus); err != nil {
+		return errors.Wrap(err, "could not subscribe dispute keeper to relevant events")
+	}
+
 	settler := pingpong.NewHermesPromiseSettler(
 		di.Transactor,
 		di.HermesPromiseStorage,
@@ -211,6 +285,8 @@ func (di *Dependencies) bootstrapHermesPromiseSettler(nodeOptions node.Options)
 		di.SettlementHistoryStorage,
 		di.EventBus,
 		di.ObserverAPI,
+		di.GasPriceProvider,
+		di.DisputeKeeper,
 		pingpong.HermesPromiseSettlerConfig{
 			BalanceThreshold:        nodeOptions.Payments.HermesPromiseSettlingThreshold,
 			MaxFeeThreshold:         nodeOptions.Payments.MaxFeeSettlingThreshold,
@@ -230,6 +306,133 @@ func (di *Dependencies) bootstrapHermesPromiseSettler(nodeOptions node.Options)
 	return nil
 }
 
+// bootstrapDDNS keeps the configured hostname pointed at the node public IP.
+func (di *Dependencies) bootstrapDDNS() error {
+	var provider ddns.Provider
+	switch name := config.GetString(config.FlagDDNSProvider); name {
+	case "":
+		return nil
+	case "cloudflare":
+		provider = ddns.NewCloudflare(di.HTTPClient, config.GetString(config.FlagDDNSToken), config.GetString(config.FlagDDNSCloudflareZone))
+	case "duckdns":
+		provider = ddns.NewDuckDNS(di.HTTPClient, config.GetString(config.FlagDDNSToken))
+	case "url":
+		provider = ddns.NewURLTemplate(di.HTTPClient, config.GetString(config.FlagDDNSURLTemplate))
+	default:
+		return fmt.Errorf("unsupported dynamic DNS provider %q", name)
+	}
+
+	hostname := config.GetString(config.FlagDDNSHostname)
+	if hostname == "" {
+		return fmt.Errorf("dynamic DNS hostname is not set, use --%s", config.FlagDDNSHostname.Name)
+	}
+
+	di.DDNSUpdater = ddns.NewUpdater(provider, hostname, di.IPResolver)
+	if err := di.DDNSUpdater.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+	di.DDNSUpdater.Start()
+	return nil
+}
+
+// bootstrapCamouflage starts TLS listener carrying WireGuard traffic of consumers allowed to use HTTPS only.
+func (di *Dependencies) bootstrapCamouflage() error {
+	if !config.GetBool(config.FlagCamouflage) || !di.FeatureFlags.Enabled(featureflag.Camouflage) {
+		return nil
+	}
+
+	server, err := camouflage.NewServer(camouflage.ServerConfig{
+		Address:     config.GetString(config.FlagCamouflageAddress),
+		PublicPort:  config.GetInt(config.FlagCamouflagePublicPort),
+		CertFile:    config.GetString(config.FlagCamouflageCert),
+		KeyFile:     config.GetString(config.FlagCamouflageKey),
+		ServerName:  config.GetString(config.FlagCamouflageServerName),
+		FallbackURL: config.GetString(config.FlagCamouflageFallbackURL),
+	})
+	if err != nil {
+		return err
+	}
+	if err := server.Start(); err != nil {
+		return err
+	}
+
+	di.CamouflageServer = server
+	di.P2PListener.ServeCamouflage(server)
+	return nil
+}
+
+// bootstrapPreflight verifies provider dependencies and refuses to start services
+// on critical failures if checks are enforced.
+func (di *Dependencies) bootstrapPreflight(nodeOptions node.Options) error {
+	mode := config.GetString(config.FlagPreflightMode)
+	switch mode {
+	case "off":
+		return nil
+	case "warn", "enforce":
+	default:
+		return errors.Errorf("unknown preflight mode: %s", mode)
+	}
+
+	var echoServers []string
+	for _, address := range strings.Split(config.GetString(config.FlagPortCheckServers), ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			echoServers = append(echoServers, address)
+		}
+	}
+
+	checks := []preflight.Check{
+		preflight.DiskSpace(nodeOptions.Directories.Data, config.GetUInt64(config.FlagPreflightMinDiskSpace)<<20),
+		preflight.Clock(config.GetString(config.FlagPreflightNTPServer), config.GetDuration(config.FlagPreflightMaxClockSkew)),
+		preflight.UDPReachability(di.PortPool, echoServers),
+	}
+	if !config.GetBool(config.FlagUserspace) && !config.GetBool(config.FlagUserMode) && !config.GetBool(config.FlagProxyMode) {
+		checks = append(checks,
+			preflight.TUN(),
+			preflight.Capabilities(),
+			preflight.WireGuard(endpoint.KernelSpaceSupported),
+		)
+	}
+
+	di.Preflight = preflight.NewChecker(10*time.Second, checks...)
+
+	// Only enforced checks hold startup back, otherwise the report is filled in the background.
+	if mode == "warn" {
+		go func() {
+			logPreflightFailures(di.Preflight.Run(context.Background()))
+		}()
+		return nil
+	}
+
+	report := di.Preflight.Run(context.Background())
+	logPreflightFailures(report)
+	if !report.Passed() {
+		return errors.New("critical provider dependency checks failed, see preflight report for remedies")
+	}
+	return nil
+}
+
+func logPreflightFailures(report preflight.Report) {
+	for _, res := range report.Failures() {
+		log.Warn().Msgf("Preflight check %s failed: %s. %s", res.Name, res.Error, res.Remedy)
+	}
+}
+
+// serviceIPResolver returns IP resolver of the bound service or the default one.
+func (di *Dependencies) serviceIPResolver(serviceType string) ip.Resolver {
+	if binding, ok := di.ServiceBindings.Get(serviceType); ok {
+		return binding.Resolver
+	}
+	return di.IPResolver
+}
+
+// serviceNATService returns NAT service routing consumer traffic through the bound interface of the service.
+func (di *Dependencies) serviceNATService(serviceType string) nat.NATService {
+	if binding, ok := di.ServiceBindings.Get(serviceType); ok {
+		return nat.NewSourceRouteService(di.NATService, binding.Interface)
+	}
+	return di.NATService
+}
+
 // bootstrapServiceComponents initiates ServicesManager dependency
 func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) error {
 	di.NATService = nat.NewService()
@@ -247,8 +450,56 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 	)
 	go di.PolicyOracle.Start()
 
+	blocklist, err := policy.NewBlocklist(di.HTTPClient, policy.BlocklistConfig{
+		FeedURL:       config.GetString(config.FlagAccessPolicyBlocklistURL),
+		FeedSigner:    identity.FromAddress(config.GetString(config.FlagAccessPolicyBlocklistSigner)),
+		FetchInterval: config.GetDuration(config.FlagAccessPolicyBlocklistFetchInterval),
+		Block:         config.GetStringSlice(config.FlagAccessPolicyBlock),
+		Exempt:        config.GetStringSlice(config.FlagAccessPolicyBlockExempt),
+		Allow:         di.privateNetworkIdentities(),
+	}, di.Storage, di.GeoIPResolver)
+	if err != nil {
+		return err
+	}
+	di.Blocklist = blocklist
+	go di.Blocklist.Start()
+
+	di.AbuseDetector = abuse.NewDetector(abuse.Config{
+		Window:               config.GetDuration(config.FlagAbuseWindow),
+		ChurnThreshold:       config.GetInt(config.FlagAbuseChurnThreshold),
+		ZeroPaymentThreshold: config.GetInt(config.FlagAbuseZeroPaymentThreshold),
+		PortScanThreshold:    config.GetInt(config.FlagAbusePortScanThreshold),
+		BlockScore:           config.GetFloat64(config.FlagAbuseBlockScore),
+		BlockDuration:        config.GetDuration(config.FlagAbuseBlockDuration),
+		Retention:            config.GetDuration(config.FlagAbuseRetention),
+	}, di.EventBus)
+	if err := di.AbuseDetector.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+
+	if hookConfig := (hooks.Config{
+		SessionStart: config.GetString(config.FlagHookSessionStart),
+		SessionEnd:   config.GetString(config.FlagHookSessionEnd),
+		Settlement:   config.GetString(config.FlagHookSettlement),
+		Timeout:      config.GetDuration(config.FlagHookTimeout),
+	}); hookConfig.Enabled() {
+		di.HookRunner = hooks.NewRunner(hookConfig, di.HTTPClient)
+		if err := di.HookRunner.Subscribe(di.EventBus); err != nil {
+			return err
+		}
+	}
+
 	di.HermesStatusChecker = pingpong.NewHermesStatusChecker(di.BCHelper, di.ObserverAPI, nodeOptions.Payments.HermesStatusRecheckInterval)
 
+	var freeTierAllowance pingpong.FreeTierAllowance
+	if di.FeatureFlags.Enabled(featureflag.FreeTier) {
+		freeTierAllowance = pingpong.FreeTierAllowance{
+			Data:     config.GetUInt64(config.FlagPaymentsProviderFreeTierMegabytes) * 1024 * 1024,
+			Duration: time.Duration(config.GetUInt64(config.FlagPaymentsProviderFreeTierMinutes)) * time.Minute,
+		}
+	}
+	di.FreeTier = pingpong.NewFreeTier(di.Storage, freeTierAllowance, di.IdentityRegistry)
+
 	newP2PSessionHandler := func(serviceInstance *service.Instance, channel p2p.Channel) *service.SessionManager {
 		paymentEngineFactory := pingpong.InvoiceFactoryCreator(
 			channel, nodeOptions.Payments.ProviderInvoiceFrequency, nodeOptions.Payments.ProviderLimitInvoiceFrequency,
@@ -262,18 +513,73 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 			di.HermesPromiseHandler,
 			di.AddressProvider,
 			di.ObserverAPI,
+			di.FreeTier,
 		)
+		sessionConfig := service.DefaultConfig()
+		sessionConfig.MaxSessions = config.GetInt(config.FlagServiceMaxSessions)
+		var restoredSessions service.RestoredSessions
+		if di.SessionSnapshots != nil {
+			restoredSessions = di.SessionSnapshots
+		}
 		return service.NewSessionManager(
 			serviceInstance,
 			di.ServiceSessions,
 			paymentEngineFactory,
 			di.EventBus,
 			channel,
-			service.DefaultConfig(),
+			sessionConfig,
 			di.PricingHelper,
+			di.AbuseDetector,
+			di.Blocklist,
+			di.ReceiptKeeper,
+			restoredSessions,
+			di.GeoIPResolver,
 		)
 	}
 
+	di.AutoPricer = pricing.NewAutoPricer(pricing.AutoPricingConfig{
+		ServiceTypes: config.GetStringSlice(config.FlagPricingAutoServices),
+		Percentile:   config.GetFloat64(config.FlagPricingAutoPercentile),
+		Interval:     config.GetDuration(config.FlagPricingAutoInterval),
+		MaxChange:    config.GetFloat64(config.FlagPricingAutoMaxChange),
+		Min: market.Price{
+			PricePerHour: crypto.FloatToBigMyst(config.GetFloat64(config.FlagPricingAutoMinPerHour)),
+			PricePerGiB:  crypto.FloatToBigMyst(config.GetFloat64(config.FlagPricingAutoMinPerGiB)),
+		},
+		Max: market.Price{
+			PricePerHour: autoPriceBound(config.GetFloat64(config.FlagPricingAutoMaxPerHour)),
+			PricePerGiB:  autoPriceBound(config.GetFloat64(config.FlagPricingAutoMaxPerGiB)),
+		},
+	}, di.ProposalRepository, di.LocationResolver, di.PricingHelper, di.Storage)
+	if config.GetBool(config.FlagPricingAuto) {
+		di.AutoPricer.Start()
+	}
+
+	loadTracker := service.NewLoadTracker(
+		config.GetInt(config.FlagServiceMaxSessions),
+		config.GetUInt64(config.FlagServiceBandwidthCapacity)*1024,
+	)
+	if err := loadTracker.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+
+	di.PriceBookKeeper = pricebook.NewKeeper(di.Storage)
+
+	di.UplinkUsageTracker = service.NewUplinkUsageTracker(func(serviceType string) (string, error) {
+		if binding, ok := di.ServiceBindings.Get(serviceType); ok {
+			return binding.Interface, nil
+		}
+		outboundIP, err := di.IPResolver.GetOutboundIP()
+		if err != nil {
+			return "", err
+		}
+		return netutil.InterfaceByIP(outboundIP)
+	}, di.Storage, time.Minute)
+	if err := di.UplinkUsageTracker.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+	di.UplinkUsageTracker.Start()
+
 	di.ServicesManager = service.NewManager(
 		di.ServiceRegistry,
 		di.DiscoveryFactory,
@@ -283,7 +589,60 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 		newP2PSessionHandler,
 		di.SessionConnectivityStatusStorage,
 		di.LocationResolver,
+		di.AutoPricer,
+		loadTracker,
+		di.PriceBookKeeper,
+		di.TermsKeeper,
+	)
+
+	if config.GetBool(config.FlagServiceRestoreSessions) {
+		di.SessionSnapshots = service.NewSessionSnapshots(di.Storage, di.ServiceSessions, di.ServicesManager, config.GetDuration(config.FlagServiceRestoreGrace))
+		if err := di.SessionSnapshots.Subscribe(di.EventBus); err != nil {
+			return err
+		}
+		// Interfaces of detached sessions must survive until services start and restore them.
+		for _, snapshot := range di.SessionSnapshots.Pending() {
+			if err := wireguard_service.ReserveDetached(snapshot.State); err != nil {
+				log.Warn().Err(err).Msgf("Could not reserve interface of session %s", snapshot.ID)
+			}
+		}
+	}
+
+	di.ServiceSupervisor = service.NewSupervisor(
+		di.ServicesManager,
+		config.GetDuration(config.FlagServiceHealthInterval),
+		service.RestartPolicy{
+			Failures:    config.GetInt(config.FlagServiceHealthFailures),
+			MaxRestarts: config.GetInt(config.FlagServiceHealthMaxRestarts),
+		},
 	)
+	di.ServiceSupervisor.Start()
+
+	schedule, err := service.ParseAccessSchedule(config.GetStringSlice(config.FlagServiceSchedule))
+	if err != nil {
+		return errors.Wrap(err, "invalid service schedule")
+	}
+	di.ServiceScheduler = service.NewScheduler(di.ServicesManager, schedule, di.Storage, time.Minute)
+	if err := di.ServiceScheduler.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+	di.ServiceScheduler.Start()
+
+	di.ServicePauser = service.NewPauser(di.ServicesManager, di.ServiceSessions)
+
+	publicIPHandler := service.NewPublicIPHandler(di.ServicesManager, di.LocationResolver, p2pnat.RemapUPnPPorts, di.EventBus)
+	if err := publicIPHandler.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+	if r, ok := di.IPResolver.(*ip.ConsensusResolver); ok {
+		r.Start()
+	}
+	if err := di.bootstrapDDNS(); err != nil {
+		return err
+	}
+	if err := di.bootstrapCamouflage(); err != nil {
+		return err
+	}
 
 	serviceCleaner := service.Cleaner{SessionStorage: di.ServiceSessions}
 	if err := di.EventBus.Subscribe(servicestate.AppTopicServiceStatus, serviceCleaner.HandleServiceStatus); err != nil {
@@ -299,6 +658,7 @@ func (di *Dependencies) registerConnections(nodeOptions node.Options) {
 	di.registerWireguardConnection(nodeOptions)
 	di.registerScrapingConnection(nodeOptions)
 	di.registerDataTransferConnection(nodeOptions)
+	di.registerSpeedtestConnection()
 }
 
 func (di *Dependencies) registerWireguardConnection(nodeOptions node.Options) {
@@ -310,8 +670,13 @@ func (di *Dependencies) registerWireguardConnection(nodeOptions node.Options) {
 	}
 	connFactory := func() (connection.Connection, error) {
 		opts := wireguard_connection.Options{
-			DNSScriptDir:     nodeOptions.Directories.Script,
-			HandshakeTimeout: 1 * time.Minute,
+			DNSScriptDir:      nodeOptions.Directories.Script,
+			HandshakeTimeout:  1 * time.Minute,
+			MTUProber:         di.mtuProber(),
+			Obfuscation:       config.GetBool(config.FlagObfuscation),
+			Camouflage:        config.GetBool(config.FlagCamouflage),
+			RekeyInterval:     config.GetDuration(config.FlagWireguardRekeyInterval),
+			DNSLeakProtection: firewall.DNSLeakProtection(config.GetString(config.FlagFirewallDNSLeakProtection)),
 		}
 		return wireguard_connection.NewConnection(opts, di.IPResolver, endpointFactory, handshakeWaiter)
 	}
@@ -329,6 +694,7 @@ func (di *Dependencies) registerScrapingConnection(nodeOptions node.Options) {
 		opts := wireguard_connection.Options{
 			DNSScriptDir:     nodeOptions.Directories.Script,
 			HandshakeTimeout: 1 * time.Minute,
+			MTUProber:        di.mtuProber(),
 		}
 		return wireguard_connection.NewConnection(opts, di.IPResolver, endpointFactory, handshakeWaiter)
 	}
@@ -346,12 +712,20 @@ func (di *Dependencies) registerDataTransferConnection(nodeOptions node.Options)
 		opts := wireguard_connection.Options{
 			DNSScriptDir:     nodeOptions.Directories.Script,
 			HandshakeTimeout: 1 * time.Minute,
+			MTUProber:        di.mtuProber(),
 		}
 		return wireguard_connection.NewConnection(opts, di.IPResolver, endpointFactory, handshakeWaiter)
 	}
 	di.ConnectionRegistry.Register(datatransfer.ServiceType, connFactory)
 }
 
+func (di *Dependencies) registerSpeedtestConnection() {
+	speedtest.Bootstrap()
+	di.ConnectionRegistry.Register(speedtest.ServiceType, func() (connection.Connection, error) {
+		return speedtest.NewConnection(di.EventBus)
+	})
+}
+
 func (di *Dependencies) bootstrapMMN() error {
 	client := mmn.NewClient(di.HTTPClient, config.GetString(config.FlagMMNAPIAddress), di.SignerFactory)
 
@@ -26,11 +26,16 @@ import (
 	"github.com/mysteriumnetwork/node/core/discovery/brokerdiscovery"
 	"github.com/mysteriumnetwork/node/core/discovery/dhtdiscovery"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/pkg/errors"
 )
 
+// proposalsCacheTTL is how long proposals fetched for a filter are shared between lookups,
+// e.g. proposal listing and smart connect attempts following it.
+const proposalsCacheTTL = 30 * time.Second
+
 func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscovery) error {
 	di.FilterPresetStorage = proposal.NewFilterPresetStorage(di.Storage)
 	proposalRepository := discovery.NewRepository()
@@ -41,7 +46,7 @@ func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscove
 		switch discoveryType {
 		case node.DiscoveryTypeAPI:
 			// Broker is the way to announce node presence currently, so enabled by default no matter the users preferences.
-			proposalRegistry.AddRegistry(brokerdiscovery.NewRegistry(di.BrokerConnection))
+			proposalRegistry.AddRegistry(brokerdiscovery.NewRegistry(di.BrokerConnection, di.ClockSkewDetector.Now))
 			proposalRepository.Add(apidiscovery.NewRepository(di.MysteriumAPI))
 
 		case node.DiscoveryTypeBroker:
@@ -51,7 +56,7 @@ func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscove
 				discoveryWorker.AddWorker(brokerRepository)
 			}
 
-			proposalRegistry.AddRegistry(brokerdiscovery.NewRegistry(di.BrokerConnection))
+			proposalRegistry.AddRegistry(brokerdiscovery.NewRegistry(di.BrokerConnection, di.ClockSkewDetector.Now))
 			proposalRepository.Add(brokerRepository)
 
 		case node.DiscoveryTypeDHT:
@@ -77,7 +82,13 @@ func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscove
 		return errors.Wrap(err, "failed to start discovery")
 	}
 
-	di.ProposalRepository = discovery.NewPricedServiceProposalRepository(proposalRepository, di.PricingHelper, di.FilterPresetStorage)
+	var baseRepository proposal.Repository = discovery.NewCachedRepository(proposalRepository, proposalsCacheTTL)
+	if di.PrivateNetwork != nil {
+		baseRepository = discovery.NewPrivateNetworkRepository(baseRepository, di.PrivateNetwork)
+	}
+	// Price proposals by the country providers see, not the one reported by the location service.
+	origin := location.NewGeoIPOriginResolver(di.LocationResolver, di.GeoIPResolver)
+	di.ProposalRepository = discovery.NewPricedServiceProposalRepository(baseRepository, di.PricingHelper, di.FilterPresetStorage, origin)
 	di.DiscoveryFactory = func() service.Discovery {
 		return discovery.NewService(di.IdentityRegistry, proposalRegistry, options.PingInterval, di.SignerFactory, di.EventBus)
 	}
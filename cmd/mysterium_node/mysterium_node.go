@@ -22,13 +22,19 @@ import (
 	"sync"
 
 	"github.com/mysteriumnetwork/node/cmd/commands/account"
+	"github.com/mysteriumnetwork/node/cmd/commands/bugreport"
 	command_cli "github.com/mysteriumnetwork/node/cmd/commands/cli"
 	command_cfg "github.com/mysteriumnetwork/node/cmd/commands/config"
 	"github.com/mysteriumnetwork/node/cmd/commands/connection"
 	"github.com/mysteriumnetwork/node/cmd/commands/daemon"
+	"github.com/mysteriumnetwork/node/cmd/commands/db"
 	"github.com/mysteriumnetwork/node/cmd/commands/license"
+	"github.com/mysteriumnetwork/node/cmd/commands/payments"
+	"github.com/mysteriumnetwork/node/cmd/commands/provider"
+	"github.com/mysteriumnetwork/node/cmd/commands/provision"
 	"github.com/mysteriumnetwork/node/cmd/commands/reset"
 	"github.com/mysteriumnetwork/node/cmd/commands/service"
+	"github.com/mysteriumnetwork/node/cmd/commands/update"
 	"github.com/mysteriumnetwork/node/cmd/commands/version"
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/logconfig"
@@ -53,6 +59,12 @@ var (
 	accountCommand    = account.NewCommand()
 	connectionCommand = connection.NewCommand()
 	configCommand     = command_cfg.NewCommand()
+	bugReportCommand  = bugreport.NewCommand()
+	updateCommand     = update.NewCommand()
+	dbCommand         = db.NewCommand()
+	provisionCommand  = provision.NewCommand()
+	paymentsCommand   = payments.NewCommand()
+	providerCommand   = provider.NewCommand()
 )
 
 func main() {
@@ -99,6 +111,12 @@ func NewCommand() (*cli.App, error) {
 		accountCommand,
 		connectionCommand,
 		configCommand,
+		bugReportCommand,
+		updateCommand,
+		dbCommand,
+		provisionCommand,
+		paymentsCommand,
+		providerCommand,
 	}
 
 	return app, nil
@@ -117,6 +135,12 @@ var uiCommands = map[string]struct{}{
 	connection.CommandName:  {},
 	command_cfg.CommandName: {},
 	reset.CommandName:       {},
+	bugreport.CommandName:   {},
+	update.CommandName:      {},
+	db.CommandName:          {},
+	provision.CommandName:   {},
+	payments.CommandName:    {},
+	provider.CommandName:    {},
 }
 
 // configureLogging returns a func which configures global
@@ -0,0 +1,58 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package broker defines the message broker abstraction used to exchange
+// proposals and p2p configs between peers, independent of the transport.
+package broker
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// Msg is a message delivered via broker.
+type Msg struct {
+	Subject string
+	Reply   string
+	Data    []byte
+}
+
+// MsgHandler handles messages delivered to a subscription.
+type MsgHandler func(msg *Msg)
+
+// Subscription is an active interest in a subject.
+type Subscription interface {
+	Unsubscribe() error
+	IsValid() bool
+}
+
+// Connection represents is publish-subscriber instance which can deliver messages
+type Connection interface {
+	Open() error
+	Close()
+	Servers() []string
+	Publish(subject string, payload []byte) error
+	Subscribe(subject string, handler MsgHandler) (Subscription, error)
+	Request(subject string, payload []byte, timeout time.Duration) (*Msg, error)
+	RequestWithContext(ctx context.Context, subject string, payload []byte) (*Msg, error)
+}
+
+// Connector establishes new connections to the given broker servers.
+type Connector interface {
+	Connect(serverURLs ...*url.URL) (Connection, error)
+}
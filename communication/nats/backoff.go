@@ -0,0 +1,56 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nats
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff computes jittered exponential delays between connection attempts,
+// so that many nodes losing a broker at once don't reconnect in lockstep.
+type Backoff struct {
+	// Initial is the delay before the first retry.
+	Initial time.Duration
+	// Max caps the delay growth.
+	Max time.Duration
+	// Jitter is the share of the delay which is randomized, in range [0, 1].
+	Jitter float64
+}
+
+// DefaultBackoff is used between broker reconnect attempts.
+var DefaultBackoff = Backoff{
+	Initial: 500 * time.Millisecond,
+	Max:     30 * time.Second,
+	Jitter:  0.5,
+}
+
+// Delay returns the delay before the given retry attempt, starting from 1.
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := b.Initial
+	for i := 1; i < attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if delay > b.Max {
+		delay = b.Max
+	}
+	if b.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * b.Jitter * float64(delay))
+	}
+	return delay
+}
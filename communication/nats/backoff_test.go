@@ -0,0 +1,48 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Initial: 100 * time.Millisecond, Max: time.Second}
+
+	assert.Equal(t, 100*time.Millisecond, b.Delay(1))
+	assert.Equal(t, 200*time.Millisecond, b.Delay(2))
+	assert.Equal(t, 800*time.Millisecond, b.Delay(4))
+	assert.Equal(t, time.Second, b.Delay(5))
+	assert.Equal(t, time.Second, b.Delay(1000))
+}
+
+func TestBackoff_DelayJitter(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: time.Second, Jitter: 0.5}
+
+	seen := make(map[time.Duration]struct{})
+	for i := 0; i < 100; i++ {
+		d := b.Delay(3)
+		assert.GreaterOrEqual(t, d, 500*time.Millisecond)
+		assert.LessOrEqual(t, d, time.Second)
+		seen[d] = struct{}{}
+	}
+	assert.Greater(t, len(seen), 1)
+}
@@ -22,16 +22,17 @@ import (
 	"sync"
 	"time"
 
-	"github.com/nats-io/nats.go"
 	"github.com/pkg/errors"
+
+	"github.com/mysteriumnetwork/node/communication/broker"
 )
 
 // NewConnectionMock constructs new NATS connection
 // which delivers published messages to local subscribers
 func NewConnectionMock() *ConnectionMock {
 	return &ConnectionMock{
-		subscriptions: make(map[string][]nats.MsgHandler),
-		queue:         make(chan *nats.Msg),
+		subscriptions: make(map[string][]broker.MsgHandler),
+		queue:         make(chan *broker.Msg),
 		queueShutdown: make(chan bool),
 	}
 }
@@ -46,13 +47,13 @@ func StartConnectionMock() *ConnectionMock {
 
 // ConnectionMock acts as a local connection implementation
 type ConnectionMock struct {
-	subscriptions map[string][]nats.MsgHandler
-	queue         chan *nats.Msg
+	subscriptions map[string][]broker.MsgHandler
+	queue         chan *broker.Msg
 	queueShutdown chan bool
 
-	messageLast *nats.Msg
+	messageLast *broker.Msg
 	m           sync.Mutex
-	requestLast *nats.Msg
+	requestLast *broker.Msg
 	errorMock   error
 }
 
@@ -82,7 +83,7 @@ func (conn *ConnectionMock) GetLastRequest() []byte {
 
 // MockResponse mocks the response
 func (conn *ConnectionMock) MockResponse(subject string, payload []byte) {
-	conn.Subscribe(subject, func(message *nats.Msg) {
+	conn.Subscribe(subject, func(message *broker.Msg) {
 		conn.Publish(message.Reply, payload)
 	})
 }
@@ -110,7 +111,7 @@ func (conn *ConnectionMock) Publish(subject string, payload []byte) error {
 
 	conn.m.Lock()
 	defer conn.m.Unlock()
-	conn.messageLast = &nats.Msg{
+	conn.messageLast = &broker.Msg{
 		Subject: subject,
 		Data:    payload,
 	}
@@ -120,29 +121,29 @@ func (conn *ConnectionMock) Publish(subject string, payload []byte) error {
 }
 
 // Subscribe subscribes to a topic
-func (conn *ConnectionMock) Subscribe(subject string, handler nats.MsgHandler) (*nats.Subscription, error) {
+func (conn *ConnectionMock) Subscribe(subject string, handler broker.MsgHandler) (broker.Subscription, error) {
 	if conn.errorMock != nil {
 		return nil, conn.errorMock
 	}
 
 	conn.subscriptionAdd(subject, handler)
 
-	return &nats.Subscription{}, nil
+	return &subscriptionMock{}, nil
 }
 
 // Request sends a new request
-func (conn *ConnectionMock) Request(subject string, payload []byte, timeout time.Duration) (*nats.Msg, error) {
+func (conn *ConnectionMock) Request(subject string, payload []byte, timeout time.Duration) (*broker.Msg, error) {
 	if conn.errorMock != nil {
 		return nil, conn.errorMock
 	}
 
 	subjectReply := subject + "-reply"
-	responseCh := make(chan *nats.Msg)
-	conn.Subscribe(subjectReply, func(response *nats.Msg) {
+	responseCh := make(chan *broker.Msg)
+	conn.Subscribe(subjectReply, func(response *broker.Msg) {
 		responseCh <- response
 	})
 
-	conn.requestLast = &nats.Msg{
+	conn.requestLast = &broker.Msg{
 		Subject: subject,
 		Reply:   subjectReply,
 		Data:    payload,
@@ -158,18 +159,18 @@ func (conn *ConnectionMock) Request(subject string, payload []byte, timeout time
 }
 
 // RequestWithContext Request sends a new request with context
-func (conn *ConnectionMock) RequestWithContext(ctx context.Context, subject string, payload []byte) (*nats.Msg, error) {
+func (conn *ConnectionMock) RequestWithContext(ctx context.Context, subject string, payload []byte) (*broker.Msg, error) {
 	if conn.errorMock != nil {
 		return nil, conn.errorMock
 	}
 
 	subjectReply := subject + "-reply"
-	responseCh := make(chan *nats.Msg)
-	conn.Subscribe(subjectReply, func(response *nats.Msg) {
+	responseCh := make(chan *broker.Msg)
+	conn.Subscribe(subjectReply, func(response *broker.Msg) {
 		responseCh <- response
 	})
 
-	conn.requestLast = &nats.Msg{
+	conn.requestLast = &broker.Msg{
 		Subject: subject,
 		Reply:   subjectReply,
 		Data:    payload,
@@ -205,16 +206,16 @@ func (conn *ConnectionMock) Servers() []string {
 	return []string{"mockhost"}
 }
 
-func (conn *ConnectionMock) subscriptionAdd(subject string, handler nats.MsgHandler) {
+func (conn *ConnectionMock) subscriptionAdd(subject string, handler broker.MsgHandler) {
 	subscriptions, exist := conn.subscriptions[subject]
 	if exist {
 		subscriptions = append(subscriptions, handler)
 	} else {
-		conn.subscriptions[subject] = []nats.MsgHandler{handler}
+		conn.subscriptions[subject] = []broker.MsgHandler{handler}
 	}
 }
 
-func (conn *ConnectionMock) subscriptionsGet(subject string) (*[]nats.MsgHandler, bool) {
+func (conn *ConnectionMock) subscriptionsGet(subject string) (*[]broker.MsgHandler, bool) {
 	subscriptions, exist := conn.subscriptions[subject]
 	return &subscriptions, exist
 }
@@ -234,3 +235,16 @@ func (conn *ConnectionMock) queueProcessing() {
 		}
 	}
 }
+
+type subscriptionMock struct {
+	unsubscribed bool
+}
+
+func (s *subscriptionMock) Unsubscribe() error {
+	s.unsubscribed = true
+	return nil
+}
+
+func (s *subscriptionMock) IsValid() bool {
+	return !s.unsubscribed
+}
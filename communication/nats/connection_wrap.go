@@ -23,12 +23,15 @@ import (
 	"net"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	nats_lib "github.com/nats-io/nats.go"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 
+	"github.com/mysteriumnetwork/node/communication/broker"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/requests"
 )
 
@@ -37,6 +40,10 @@ const (
 	DefaultBrokerScheme = "nats"
 	// DefaultBrokerPort broker port.
 	DefaultBrokerPort = 4222
+
+	// reconnectBufSize is the amount of publishes kept while reconnecting,
+	// so that short broker blips don't fail proposal pings and dial attempts.
+	reconnectBufSize = 8 * 1024 * 1024
 )
 
 // ParseServerURL validates given NATS server address.
@@ -79,31 +86,49 @@ func newConnection(dialer requests.DialContext, serverURIs ...string) (*Connecti
 		servers: serverURIs,
 		onClose: func() {},
 		dialer:  dialer,
+		backoff: DefaultBackoff,
 	}, nil
 }
 
+var _ broker.Connection = (*ConnectionWrap)(nil)
+
 // ConnectionWrap defines wrapped connection to NATS server(s).
 type ConnectionWrap struct {
 	*nats_lib.Conn
 
-	dialer requests.DialContext
+	dialer    requests.DialContext
+	publisher eventbus.Publisher
+	backoff   Backoff
 
 	servers []string
 	onClose func()
+
+	stateMu sync.RWMutex
+	state   ConnectionState
 }
 
 func (c *ConnectionWrap) connectOptions() nats_lib.Options {
 	options := nats_lib.GetDefaultOptions()
 	options.Servers = c.servers
 	options.MaxReconnect = -1
-	options.ReconnectWait = 1 * time.Second
+	options.CustomReconnectDelayCB = c.backoff.Delay
+	options.ReconnectBufSize = reconnectBufSize
 	options.PingInterval = 10 * time.Second
 	options.Timeout = 10 * time.Second
 	options.RetryOnFailedConnect = true
 
-	options.ClosedCB = func(conn *nats_lib.Conn) { log.Warn().Msg("NATS: connection closed") }
-	options.DisconnectedErrCB = func(nc *nats_lib.Conn, err error) { log.Warn().Err(err).Msg("NATS: disconnected") }
-	options.ReconnectedCB = func(nc *nats_lib.Conn) { log.Warn().Msg("NATS: reconnected") }
+	options.ClosedCB = func(conn *nats_lib.Conn) {
+		log.Warn().Msg("NATS: connection closed")
+		c.setState(StateClosed, nil)
+	}
+	options.DisconnectedErrCB = func(nc *nats_lib.Conn, err error) {
+		log.Warn().Err(err).Msg("NATS: disconnected")
+		c.setState(StateDisconnected, err)
+	}
+	options.ReconnectedCB = func(nc *nats_lib.Conn) {
+		log.Warn().Msg("NATS: reconnected")
+		c.setState(StateReconnected, nil)
+	}
 
 	if c.dialer != nil {
 		options.CustomDialer = &dialer{c.dialer}
@@ -118,11 +143,40 @@ func (c *ConnectionWrap) Open() (err error) {
 	c.Conn, err = c.connectOptions().Connect()
 	if err != nil {
 		log.Warn().Err(err).Msgf("Failed to connect to NATS servers %v, will reconnect again", c.connectOptions().Servers)
+		return nil
+	}
+
+	if c.Conn.IsConnected() {
+		c.setState(StateConnected, nil)
+	} else {
+		c.setState(StateDisconnected, nil)
 	}
 
 	return nil
 }
 
+// State returns the last known connection state.
+func (c *ConnectionWrap) State() ConnectionState {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+
+	return c.state
+}
+
+func (c *ConnectionWrap) setState(state ConnectionState, err error) {
+	c.stateMu.Lock()
+	c.state = state
+	c.stateMu.Unlock()
+
+	if c.publisher != nil {
+		c.publisher.Publish(AppTopicConnectionState, ConnectionStateEvent{
+			State:   state,
+			Servers: c.servers,
+			Error:   err,
+		})
+	}
+}
+
 // Close destructs the connection.
 func (c *ConnectionWrap) Close() {
 	if c.Conn != nil {
@@ -136,6 +190,39 @@ func (c *ConnectionWrap) Servers() []string {
 	return c.servers
 }
 
+// Subscribe expresses interest in the given subject.
+func (c *ConnectionWrap) Subscribe(subject string, handler broker.MsgHandler) (broker.Subscription, error) {
+	sub, err := c.Conn.Subscribe(subject, func(msg *nats_lib.Msg) {
+		handler(fromNATSMsg(msg))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Request sends a request and waits for the first response.
+func (c *ConnectionWrap) Request(subject string, payload []byte, timeout time.Duration) (*broker.Msg, error) {
+	msg, err := c.Conn.Request(subject, payload, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return fromNATSMsg(msg), nil
+}
+
+// RequestWithContext sends a request and waits for the first response until context is done.
+func (c *ConnectionWrap) RequestWithContext(ctx context.Context, subject string, payload []byte) (*broker.Msg, error) {
+	msg, err := c.Conn.RequestWithContext(ctx, subject, payload)
+	if err != nil {
+		return nil, err
+	}
+	return fromNATSMsg(msg), nil
+}
+
+func fromNATSMsg(msg *nats_lib.Msg) *broker.Msg {
+	return &broker.Msg{Subject: msg.Subject, Reply: msg.Reply, Data: msg.Data}
+}
+
 type dialer struct {
 	dialer requests.DialContext
 }
@@ -77,3 +77,37 @@ func TestConnectionWrap_Servers(t *testing.T) {
 	connection, _ := newConnection(nil, "nats://far-server:1234")
 	assert.Equal(t, []string{"nats://far-server:1234"}, connection.Servers())
 }
+
+func TestConnectionWrap_PublishesStateChanges(t *testing.T) {
+	publisher := &statePublisher{}
+	connection, _ := newConnection(nil, "nats://far-server:1234")
+	connection.publisher = publisher
+
+	options := connection.connectOptions()
+	assert.Equal(t, reconnectBufSize, options.ReconnectBufSize)
+	assert.NotNil(t, options.CustomReconnectDelayCB)
+
+	disconnectErr := errors.New("connection reset")
+	options.DisconnectedErrCB(nil, disconnectErr)
+	assert.Equal(t, StateDisconnected, connection.State())
+	options.ReconnectedCB(nil)
+	assert.Equal(t, StateReconnected, connection.State())
+	options.ClosedCB(nil)
+	assert.Equal(t, StateClosed, connection.State())
+
+	assert.Equal(t, []ConnectionStateEvent{
+		{State: StateDisconnected, Servers: []string{"nats://far-server:1234"}, Error: disconnectErr},
+		{State: StateReconnected, Servers: []string{"nats://far-server:1234"}},
+		{State: StateClosed, Servers: []string{"nats://far-server:1234"}},
+	}, publisher.events)
+}
+
+type statePublisher struct {
+	events []ConnectionStateEvent
+}
+
+func (p *statePublisher) Publish(topic string, data interface{}) {
+	if topic == AppTopicConnectionState {
+		p.events = append(p.events, data.(ConnectionStateEvent))
+	}
+}
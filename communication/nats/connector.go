@@ -26,6 +26,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 
+	"github.com/mysteriumnetwork/node/communication/broker"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/requests"
 	"github.com/mysteriumnetwork/node/requests/resolver"
@@ -37,14 +39,17 @@ type BrokerConnector struct {
 	// If ResolveContext is nil, then the transport dials using package net.
 	resolveContext resolver.ResolveContext
 
-	dialer requests.DialContext
+	dialer    requests.DialContext
+	publisher eventbus.Publisher
 }
 
 // NewBrokerConnector creates a new BrokerConnector.
-func NewBrokerConnector(dialer requests.DialContext, resolveContext resolver.ResolveContext) *BrokerConnector {
+// Connection state changes of created connections are published via publisher.
+func NewBrokerConnector(dialer requests.DialContext, resolveContext resolver.ResolveContext, publisher eventbus.Publisher) *BrokerConnector {
 	return &BrokerConnector{
 		resolveContext: resolveContext,
 		dialer:         dialer,
+		publisher:      publisher,
 	}
 }
 
@@ -75,7 +80,7 @@ func (b *BrokerConnector) resolveServers(serverURLs []*url.URL) ([]*url.URL, err
 }
 
 // Connect establishes a new connection to the broker(s).
-func (b *BrokerConnector) Connect(serverURLs ...*url.URL) (Connection, error) {
+func (b *BrokerConnector) Connect(serverURLs ...*url.URL) (broker.Connection, error) {
 	log.Debug().Msgf("Connecting to NATS servers: %v", serverURLs)
 
 	serverURLs, err := b.resolveServers(serverURLs)
@@ -97,6 +102,7 @@ func (b *BrokerConnector) Connect(serverURLs ...*url.URL) (Connection, error) {
 	if err != nil {
 		return nil, err
 	}
+	conn.publisher = b.publisher
 
 	if err := conn.Open(); err != nil {
 		return nil, err
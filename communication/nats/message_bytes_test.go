@@ -21,7 +21,7 @@ import (
 	"testing"
 
 	"github.com/mysteriumnetwork/node/communication"
-	"github.com/nats-io/nats.go"
+	"github.com/mysteriumnetwork/node/communication/broker"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -78,7 +78,7 @@ func TestMessageBytesReceive(t *testing.T) {
 	receiver := &receiverNATS{
 		connection: connection,
 		codec:      communication.NewCodecBytes(),
-		subs:       make(map[string]*nats.Subscription),
+		subs:       make(map[string]broker.Subscription),
 	}
 
 	consumer := &bytesMessageConsumer{messageReceived: make(chan interface{})}
@@ -21,7 +21,7 @@ import (
 	"testing"
 
 	"github.com/mysteriumnetwork/node/communication"
-	"github.com/nats-io/nats.go"
+	"github.com/mysteriumnetwork/node/communication/broker"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -93,7 +93,7 @@ func TestMessageCustomReceive(t *testing.T) {
 	receiver := &receiverNATS{
 		connection: connection,
 		codec:      communication.NewCodecJSON(),
-		subs:       make(map[string]*nats.Subscription),
+		subs:       make(map[string]broker.Subscription),
 	}
 
 	consumer := &customMessageConsumer{messageReceived: make(chan interface{})}
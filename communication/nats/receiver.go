@@ -21,7 +21,7 @@ import (
 	"sync"
 
 	"github.com/mysteriumnetwork/node/communication"
-	"github.com/nats-io/nats.go"
+	"github.com/mysteriumnetwork/node/communication/broker"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
@@ -29,20 +29,20 @@ import (
 // NewReceiver constructs new Receiver's instance which works through NATS connection.
 // Codec packs/unpacks messages to byte payloads.
 // Topic (optional) if need to send messages prefixed topic.
-func NewReceiver(connection Connection, codec communication.Codec, topic string) *receiverNATS {
+func NewReceiver(connection broker.Connection, codec communication.Codec, topic string) *receiverNATS {
 	return &receiverNATS{
 		connection: connection,
 		codec:      codec,
-		subs:       make(map[string]*nats.Subscription),
+		subs:       make(map[string]broker.Subscription),
 	}
 }
 
 type receiverNATS struct {
-	connection Connection
+	connection broker.Connection
 	codec      communication.Codec
 
 	mu   sync.Mutex
-	subs map[string]*nats.Subscription
+	subs map[string]broker.Subscription
 }
 
 func (receiver *receiverNATS) Receive(consumer communication.MessageConsumer) error {
@@ -52,7 +52,7 @@ func (receiver *receiverNATS) Receive(consumer communication.MessageConsumer) er
 	}
 	messageTopic := string(messageEndpoint)
 
-	messageHandler := func(msg *nats.Msg) {
+	messageHandler := func(msg *broker.Msg) {
 		log.WithLevel(levelFor(messageTopic)).Msgf("Message %q received: %s", messageTopic, msg.Data)
 		messagePtr := consumer.NewMessage()
 		err := receiver.codec.Unpack(msg.Data, messagePtr)
@@ -121,7 +121,7 @@ func (receiver *receiverNATS) Respond(consumer communication.RequestConsumer) er
 	}
 	requestTopic := string(requestEndpoint)
 
-	messageHandler := func(msg *nats.Msg) {
+	messageHandler := func(msg *broker.Msg) {
 		log.WithLevel(levelFor(requestTopic)).Msgf("Request %q received: %s", requestTopic, msg.Data)
 		requestPtr := consumer.NewRequest()
 		err := receiver.codec.Unpack(msg.Data, requestPtr)
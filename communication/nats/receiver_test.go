@@ -21,7 +21,7 @@ import (
 	"testing"
 
 	"github.com/mysteriumnetwork/node/communication"
-	"github.com/nats-io/nats.go"
+	"github.com/mysteriumnetwork/node/communication/broker"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -36,7 +36,7 @@ func TestReceiverNew(t *testing.T) {
 		&receiverNATS{
 			connection: connection,
 			codec:      codec,
-			subs:       make(map[string]*nats.Subscription),
+			subs:       make(map[string]broker.Subscription),
 		},
 		NewReceiver(connection, codec, "custom"),
 	)
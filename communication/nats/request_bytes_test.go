@@ -22,7 +22,7 @@ import (
 	"time"
 
 	"github.com/mysteriumnetwork/node/communication"
-	"github.com/nats-io/nats.go"
+	"github.com/mysteriumnetwork/node/communication/broker"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -87,7 +87,7 @@ func TestBytesRespond(t *testing.T) {
 	receiver := &receiverNATS{
 		connection: connection,
 		codec:      communication.NewCodecBytes(),
-		subs:       make(map[string]*nats.Subscription),
+		subs:       make(map[string]broker.Subscription),
 	}
 
 	consumer := &bytesRequestConsumer{}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/mysteriumnetwork/node/communication"
+	"github.com/mysteriumnetwork/node/communication/broker"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
@@ -28,7 +29,7 @@ import (
 // NewSender constructs new Sender's instance which works thru NATS connection.
 // Codec packs/unpacks messages to byte payloads.
 // Topic (optional) if need to send messages prefixed topic.
-func NewSender(connection Connection, codec communication.Codec) *senderNATS {
+func NewSender(connection broker.Connection, codec communication.Codec) *senderNATS {
 	return &senderNATS{
 		connection:     connection,
 		codec:          codec,
@@ -37,7 +38,7 @@ func NewSender(connection Connection, codec communication.Codec) *senderNATS {
 }
 
 type senderNATS struct {
-	connection     Connection
+	connection     broker.Connection
 	codec          communication.Codec
 	timeoutRequest time.Duration
 	messageTopic   string
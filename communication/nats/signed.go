@@ -31,9 +31,9 @@ var (
 	SignatureEncoding = base64.RawURLEncoding
 )
 
-// SignedSubject signs topic to pass command through nats-proxy
-func SignedSubject(signer identity.Signer, topic string) (string, error) {
-	ts := time.Now().Truncate(0).Unix()
+// SignedSubject signs topic with the given timestamp to pass command through nats-proxy
+func SignedSubject(signer identity.Signer, topic string, now time.Time) (string, error) {
+	ts := now.Truncate(0).Unix()
 
 	signature, err := signer.Sign([]byte(fmt.Sprintf("%d.%s", ts, topic)))
 	if err != nil {
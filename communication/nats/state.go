@@ -0,0 +1,42 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nats
+
+// AppTopicConnectionState is the topic broker connection state changes are published to.
+const AppTopicConnectionState = "nats-connection-state"
+
+// ConnectionState describes broker connection state.
+type ConnectionState string
+
+const (
+	// StateConnected means connection to the broker is established.
+	StateConnected ConnectionState = "connected"
+	// StateDisconnected means connection was lost and publishes are buffered until reconnect.
+	StateDisconnected ConnectionState = "disconnected"
+	// StateReconnected means connection was restored and buffered publishes were flushed.
+	StateReconnected ConnectionState = "reconnected"
+	// StateClosed means connection was closed and will not be restored.
+	StateClosed ConnectionState = "closed"
+)
+
+// ConnectionStateEvent is published whenever broker connection state changes.
+type ConnectionStateEvent struct {
+	State   ConnectionState
+	Servers []string
+	Error   error
+}
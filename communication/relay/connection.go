@@ -0,0 +1,460 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/communication/broker"
+	"github.com/mysteriumnetwork/node/communication/nats"
+	"github.com/mysteriumnetwork/node/requests"
+)
+
+// subscriptionQueueSize is the amount of messages buffered per subscription
+// while its handler is busy.
+const subscriptionQueueSize = 64
+
+// ErrConnectionClosed is returned when using a closed relay connection.
+var ErrConnectionClosed = errors.New("relay connection closed")
+
+var _ broker.Connection = (*Connection)(nil)
+
+// Connection is a broker connection which tunnels publishes and subscriptions
+// through an HTTPS relay using long-polling.
+type Connection struct {
+	relayURL string
+	client   *requests.HTTPClient
+	backoff  nats.Backoff
+
+	mu        sync.Mutex
+	sessionID string
+	// subs are keyed by relay subscription ID. Subscriptions made on a previous
+	// session are kept until they are moved to the current one.
+	subs   map[string]*subscription
+	closed bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewConnection creates a relay connection, call Open to start receiving messages.
+func NewConnection(relayURL string, client *requests.HTTPClient) *Connection {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Connection{
+		relayURL: relayURL,
+		client:   client,
+		backoff:  nats.DefaultBackoff,
+		subs:     make(map[string]*subscription),
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// Open creates a relay session and starts polling it for messages.
+func (c *Connection) Open() error {
+	sessionID, err := c.openSession()
+	if err != nil {
+		return fmt.Errorf("failed to open relay session: %w", err)
+	}
+
+	c.mu.Lock()
+	c.sessionID = sessionID
+	c.mu.Unlock()
+
+	go c.pollLoop()
+	return nil
+}
+
+// Close stops polling and removes the relay session.
+func (c *Connection) Close() {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	sessionID := c.sessionID
+	polling := sessionID != ""
+	for _, sub := range c.subs {
+		sub.stop()
+	}
+	c.subs = make(map[string]*subscription)
+	c.mu.Unlock()
+
+	c.cancel()
+	if !polling {
+		return
+	}
+	<-c.done
+
+	req, err := c.newRequest(http.MethodDelete, "v1/sessions/"+sessionID, nil)
+	if err == nil {
+		err = c.client.DoRequest(req)
+	}
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to remove relay session %s", sessionID)
+	}
+}
+
+// Servers returns the relay address the connection goes through.
+func (c *Connection) Servers() []string {
+	return []string{c.relayURL}
+}
+
+// Publish publishes message to the given subject.
+func (c *Connection) Publish(subject string, payload []byte) error {
+	return c.publish(message{Subject: subject, Data: payload})
+}
+
+// Subscribe expresses interest in the given subject.
+func (c *Connection) Subscribe(subject string, handler broker.MsgHandler) (broker.Subscription, error) {
+	sub := newSubscription(c, subject, handler)
+	for {
+		c.mu.Lock()
+		closed, sessionID := c.closed, c.sessionID
+		c.mu.Unlock()
+		if closed {
+			sub.stop()
+			return nil, ErrConnectionClosed
+		}
+
+		id, err := c.subscribe(sessionID, subject)
+
+		c.mu.Lock()
+		switch {
+		case c.closed:
+			c.mu.Unlock()
+			sub.stop()
+			return nil, ErrConnectionClosed
+		case err != nil && c.sessionID != sessionID:
+			// The session was restored meanwhile, subscribe on the new one.
+			c.mu.Unlock()
+			continue
+		case err != nil:
+			c.mu.Unlock()
+			sub.stop()
+			return nil, err
+		}
+		// Subscriptions made on a replaced session are moved by the poll loop.
+		sub.id, sub.sessionID = id, sessionID
+		c.subs[id] = sub
+		c.mu.Unlock()
+		return sub, nil
+	}
+}
+
+// Request sends a request and waits for a reply up to the given timeout.
+func (c *Connection) Request(subject string, payload []byte, timeout time.Duration) (*broker.Msg, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return c.RequestWithContext(ctx, subject, payload)
+}
+
+// RequestWithContext sends a request and waits for a reply until the context is done.
+func (c *Connection) RequestWithContext(ctx context.Context, subject string, payload []byte) (*broker.Msg, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	inbox := inboxPrefix + id
+
+	replies := make(chan *broker.Msg, 1)
+	sub, err := c.Subscribe(inbox, func(msg *broker.Msg) {
+		select {
+		case replies <- msg:
+		default:
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer sub.Unsubscribe()
+
+	if err := c.publish(message{Subject: subject, Reply: inbox, Data: payload}); err != nil {
+		return nil, err
+	}
+
+	select {
+	case msg := <-replies:
+		return msg, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *Connection) publish(msg message) error {
+	req, err := c.newRequest(http.MethodPost, "v1/messages", msg)
+	if err != nil {
+		return err
+	}
+	return c.client.DoRequest(req)
+}
+
+func (c *Connection) openSession() (string, error) {
+	req, err := c.newRequest(http.MethodPost, "v1/sessions", struct{}{})
+	if err != nil {
+		return "", err
+	}
+
+	var resp sessionResponse
+	if err := c.client.DoRequestAndParseResponse(req, &resp); err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+func (c *Connection) subscribe(sessionID, subject string) (string, error) {
+	req, err := c.newRequest(http.MethodPost, "v1/sessions/"+sessionID+"/subscriptions", subscribeRequest{Subject: subject})
+	if err != nil {
+		return "", err
+	}
+
+	var resp subscribeResponse
+	if err := c.client.DoRequestAndParseResponse(req, &resp); err != nil {
+		return "", fmt.Errorf("failed to subscribe to %q: %w", subject, err)
+	}
+	return resp.ID, nil
+}
+
+func (c *Connection) removeSubscription(sessionID, id string) error {
+	req, err := c.newRequest(http.MethodDelete, "v1/sessions/"+sessionID+"/subscriptions/"+id, nil)
+	if err != nil {
+		return err
+	}
+	return c.client.DoRequest(req)
+}
+
+func (c *Connection) unsubscribe(sub *subscription) error {
+	c.mu.Lock()
+	if c.subs[sub.id] != sub {
+		c.mu.Unlock()
+		return nil
+	}
+	delete(c.subs, sub.id)
+	id, sessionID, current := sub.id, sub.sessionID, sub.sessionID == c.sessionID
+	c.mu.Unlock()
+
+	// Relay has already forgotten subscriptions of a replaced session.
+	if !current {
+		return nil
+	}
+	return c.removeSubscription(sessionID, id)
+}
+
+// restoreSession recreates the session after relay forgot it.
+// Its subscriptions are moved to the new session by resubscribe.
+func (c *Connection) restoreSession() error {
+	sessionID, err := c.openSession()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.sessionID = sessionID
+	c.mu.Unlock()
+	return nil
+}
+
+// resubscribe moves subscriptions made on a replaced session to the current one.
+// Subscriptions which fail to move are kept and retried on the next call.
+func (c *Connection) resubscribe() error {
+	c.mu.Lock()
+	sessionID := c.sessionID
+	var stale []*subscription
+	for _, sub := range c.subs {
+		if sub.sessionID != sessionID {
+			stale = append(stale, sub)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, sub := range stale {
+		id, err := c.subscribe(sessionID, sub.subject)
+		if err != nil {
+			return err
+		}
+
+		c.mu.Lock()
+		moved := c.sessionID == sessionID && c.subs[sub.id] == sub
+		if moved {
+			delete(c.subs, sub.id)
+			sub.id, sub.sessionID = id, sessionID
+			c.subs[id] = sub
+		}
+		c.mu.Unlock()
+
+		if !moved {
+			// Unsubscribed or the session was replaced again meanwhile.
+			if err := c.removeSubscription(sessionID, id); err != nil {
+				log.Debug().Err(err).Msgf("Failed to remove relay subscription %s", id)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Connection) pollLoop() {
+	defer close(c.done)
+
+	attempt := 0
+	for {
+		messages, err := c.poll()
+		if c.ctx.Err() != nil {
+			return
+		}
+
+		if isSessionNotFound(err) {
+			log.Warn().Msg("Relay session expired, restoring it")
+			err = c.restoreSession()
+		}
+		if err == nil {
+			err = c.resubscribe()
+		}
+		if err != nil {
+			attempt++
+			delay := c.backoff.Delay(attempt)
+			log.Warn().Err(err).Msgf("Relay poll failed, retrying in %s", delay)
+			select {
+			case <-time.After(delay):
+			case <-c.ctx.Done():
+				return
+			}
+			continue
+		}
+		attempt = 0
+
+		c.dispatch(messages)
+	}
+}
+
+func (c *Connection) poll() ([]message, error) {
+	c.mu.Lock()
+	sessionID := c.sessionID
+	c.mu.Unlock()
+
+	params := url.Values{"wait": []string{pollWait.String()}}
+	req, err := requests.NewGetRequest(c.relayURL, "v1/sessions/"+sessionID+"/messages", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []message
+	err = c.client.DoRequestAndParseResponse(req.WithContext(c.ctx), &messages)
+	return messages, err
+}
+
+func (c *Connection) dispatch(messages []message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, msg := range messages {
+		sub, ok := c.subs[msg.SubscriptionID]
+		if !ok {
+			continue
+		}
+		sub.deliver(&broker.Msg{Subject: msg.Subject, Reply: msg.Reply, Data: msg.Data})
+	}
+}
+
+func (c *Connection) newRequest(method, path string, body interface{}) (*http.Request, error) {
+	switch method {
+	case http.MethodPost:
+		return requests.NewPostRequest(c.relayURL, path, body)
+	default:
+		return http.NewRequest(method, c.relayURL+"/"+path, nil)
+	}
+}
+
+func isSessionNotFound(err error) bool {
+	var apiErr *apierror.APIError
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound
+}
+
+// subscription delivers messages to its handler in order, from its own goroutine,
+// so a slow handler doesn't hold other subscriptions back.
+type subscription struct {
+	conn      *Connection
+	id        string
+	sessionID string
+	subject   string
+
+	queue    chan *broker.Msg
+	stopOnce sync.Once
+	stopped  chan struct{}
+}
+
+func newSubscription(conn *Connection, subject string, handler broker.MsgHandler) *subscription {
+	sub := &subscription{
+		conn:    conn,
+		subject: subject,
+		queue:   make(chan *broker.Msg, subscriptionQueueSize),
+		stopped: make(chan struct{}),
+	}
+	go func() {
+		for {
+			select {
+			case msg := <-sub.queue:
+				handler(msg)
+			case <-sub.stopped:
+				return
+			}
+		}
+	}()
+	return sub
+}
+
+func (s *subscription) deliver(msg *broker.Msg) {
+	select {
+	case s.queue <- msg:
+	case <-s.stopped:
+	default:
+		log.Warn().Msgf("Relay subscription %q is slow, dropping message", s.subject)
+	}
+}
+
+func (s *subscription) stop() {
+	s.stopOnce.Do(func() { close(s.stopped) })
+}
+
+// Unsubscribe removes interest in the subject.
+func (s *subscription) Unsubscribe() error {
+	s.stop()
+	return s.conn.unsubscribe(s)
+}
+
+// IsValid returns whether the subscription is still active.
+func (s *subscription) IsValid() bool {
+	select {
+	case <-s.stopped:
+		return false
+	default:
+		return true
+	}
+}
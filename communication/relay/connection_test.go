@@ -0,0 +1,174 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relay
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/communication/broker"
+)
+
+func newTestRelay(t *testing.T) (*Server, *Connector) {
+	srv := NewServer()
+	srv.maxWait = 100 * time.Millisecond
+	httpSrv := httptest.NewServer(srv)
+	t.Cleanup(httpSrv.Close)
+
+	return srv, NewConnector(httpSrv.URL+"/", &http.Transport{})
+}
+
+func connect(t *testing.T, connector *Connector) broker.Connection {
+	conn, err := connector.Connect()
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+	return conn
+}
+
+func Test_Connection_PublishSubscribe(t *testing.T) {
+	_, connector := newTestRelay(t)
+	publisher, subscriber := connect(t, connector), connect(t, connector)
+
+	received := make(chan *broker.Msg, 2)
+	sub, err := subscriber.Subscribe("provider.*.ping", func(msg *broker.Msg) {
+		received <- msg
+	})
+	require.NoError(t, err)
+	assert.True(t, sub.IsValid())
+
+	require.NoError(t, publisher.Publish("provider.0x1.ping", []byte("hello")))
+	require.NoError(t, publisher.Publish("consumer.0x1.ping", []byte("ignored")))
+
+	select {
+	case msg := <-received:
+		assert.Equal(t, "provider.0x1.ping", msg.Subject)
+		assert.Equal(t, []byte("hello"), msg.Data)
+	case <-time.After(2 * time.Second):
+		t.Fatal("message not delivered")
+	}
+
+	require.NoError(t, sub.Unsubscribe())
+	assert.False(t, sub.IsValid())
+	require.NoError(t, publisher.Publish("provider.0x1.ping", []byte("late")))
+
+	select {
+	case msg := <-received:
+		t.Fatalf("unexpected message after unsubscribe: %s", msg.Data)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func Test_Connection_Request(t *testing.T) {
+	_, connector := newTestRelay(t)
+	requester, responder := connect(t, connector), connect(t, connector)
+
+	_, err := responder.Subscribe("echo", func(msg *broker.Msg) {
+		assert.NoError(t, responder.Publish(msg.Reply, append([]byte("re: "), msg.Data...)))
+	})
+	require.NoError(t, err)
+
+	reply, err := requester.Request("echo", []byte("hi"), 2*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("re: hi"), reply.Data)
+
+	_, err = requester.Request("nobody", []byte("hi"), 200*time.Millisecond)
+	assert.Error(t, err)
+}
+
+func Test_Connection_RestoresExpiredSession(t *testing.T) {
+	srv, connector := newTestRelay(t)
+	publisher, subscriber := connect(t, connector), connect(t, connector)
+
+	received := make(chan *broker.Msg, 1)
+	_, err := subscriber.Subscribe("topic", func(msg *broker.Msg) {
+		received <- msg
+	})
+	require.NoError(t, err)
+
+	srv.mu.Lock()
+	srv.sessions = make(map[string]*relaySession)
+	srv.mu.Unlock()
+
+	assert.Eventually(t, func() bool {
+		if err := publisher.Publish("topic", []byte("again")); err != nil {
+			return false
+		}
+		select {
+		case msg := <-received:
+			return string(msg.Data) == "again"
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 3*time.Second, 50*time.Millisecond)
+}
+
+func Test_Connection_RestoresAllSubscriptionsAfterFailedResubscribe(t *testing.T) {
+	srv := NewServer()
+	srv.maxWait = 100 * time.Millisecond
+	var failSubscribe int32
+	httpSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/subscriptions") && atomic.CompareAndSwapInt32(&failSubscribe, 1, 0) {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	t.Cleanup(httpSrv.Close)
+	connector := NewConnector(httpSrv.URL+"/", &http.Transport{})
+	publisher, subscriber := connect(t, connector), connect(t, connector)
+
+	received := make(chan *broker.Msg, 10)
+	for _, subject := range []string{"first", "second"} {
+		_, err := subscriber.Subscribe(subject, func(msg *broker.Msg) {
+			received <- msg
+		})
+		require.NoError(t, err)
+	}
+
+	atomic.StoreInt32(&failSubscribe, 1)
+	srv.mu.Lock()
+	srv.sessions = make(map[string]*relaySession)
+	srv.mu.Unlock()
+
+	for _, subject := range []string{"first", "second"} {
+		assert.Eventually(t, func() bool {
+			if err := publisher.Publish(subject, []byte("again")); err != nil {
+				return false
+			}
+			select {
+			case msg := <-received:
+				return msg.Subject == subject
+			case <-time.After(100 * time.Millisecond):
+				return false
+			}
+		}, 3*time.Second, 50*time.Millisecond, subject)
+	}
+}
+
+func Test_Connection_Servers(t *testing.T) {
+	conn := NewConnection("https://relay.example", nil)
+
+	assert.Equal(t, []string{"https://relay.example"}, conn.Servers())
+}
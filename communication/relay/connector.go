@@ -0,0 +1,51 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relay
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/communication/broker"
+	"github.com/mysteriumnetwork/node/requests"
+)
+
+// Connector connects to the broker network through an HTTPS relay.
+type Connector struct {
+	relayURL string
+	client   *requests.HTTPClient
+}
+
+// NewConnector creates a relay connector using the given relay address.
+func NewConnector(relayURL string, transport *http.Transport) *Connector {
+	return &Connector{
+		relayURL: strings.TrimSuffix(relayURL, "/"),
+		client:   requests.NewHTTPClientWithTransport(transport, requestTimeout),
+	}
+}
+
+// Connect opens a relay connection. Broker addresses are ignored,
+// relay is responsible for reaching the broker network.
+func (c *Connector) Connect(_ ...*url.URL) (broker.Connection, error) {
+	conn := NewConnection(c.relayURL, c.client)
+	if err := conn.Open(); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
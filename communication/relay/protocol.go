@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package relay implements the broker connection over HTTPS long-polling,
+// for networks where outgoing NATS connections are blocked.
+// The relay bridging its sessions to NATS brokers is run with cmd/brokerrelay.
+package relay
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// pollWait is how long relay holds a poll request open when there are no messages.
+	pollWait = 25 * time.Second
+	// requestTimeout bounds every relay call, it must exceed pollWait.
+	requestTimeout = pollWait + 10*time.Second
+
+	inboxPrefix = "_INBOX."
+)
+
+type sessionResponse struct {
+	ID string `json:"id"`
+}
+
+type subscribeRequest struct {
+	Subject string `json:"subject"`
+}
+
+type subscribeResponse struct {
+	ID string `json:"id"`
+}
+
+type message struct {
+	SubscriptionID string `json:"sid,omitempty"`
+	Subject        string `json:"subject"`
+	Reply          string `json:"reply,omitempty"`
+	Data           []byte `json:"data"`
+}
+
+// subjectMatches checks whether subject matches given pattern,
+// following NATS wildcard rules: "*" matches a single token, ">" matches the rest.
+func subjectMatches(pattern, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	subjectTokens := strings.Split(subject, ".")
+
+	for i, token := range patternTokens {
+		if token == ">" {
+			return len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) {
+			return false
+		}
+		if token != "*" && token != subjectTokens[i] {
+			return false
+		}
+	}
+	return len(patternTokens) == len(subjectTokens)
+}
@@ -0,0 +1,370 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relay
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/communication/broker"
+)
+
+const (
+	// sessionTTL is how long a session is kept without being polled.
+	sessionTTL = 2 * time.Minute
+	// sessionQueueSize caps the amount of undelivered messages per session.
+	sessionQueueSize = 1024
+)
+
+// Upstream is the broker network relay sessions are bridged to, NATS connections implement it.
+type Upstream interface {
+	PublishRequest(subject, reply string, data []byte) error
+	Subscribe(subject string, handler broker.MsgHandler) (broker.Subscription, error)
+}
+
+// Server is an HTTPS relay which routes messages of long-polling sessions,
+// either between its own sessions or to and from the upstream broker network.
+type Server struct {
+	maxWait  time.Duration
+	now      func() time.Time
+	upstream Upstream
+
+	mu       sync.Mutex
+	sessions map[string]*relaySession
+}
+
+type relaySession struct {
+	subs     map[string]string
+	upstream map[string]broker.Subscription
+	queue    []message
+	notify   chan struct{}
+	lastSeen time.Time
+}
+
+// NewServer creates an in-memory relay server, routing messages between its own sessions only.
+func NewServer() *Server {
+	return &Server{
+		maxWait:  pollWait,
+		now:      time.Now,
+		sessions: make(map[string]*relaySession),
+	}
+}
+
+// NewBridgeServer creates a relay server which publishes messages of its sessions to the upstream
+// broker network and delivers them messages of the upstream subscriptions.
+func NewBridgeServer(upstream Upstream) *Server {
+	s := NewServer()
+	s.upstream = upstream
+	return s
+}
+
+// ServeHTTP routes relay API requests.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "v1" {
+		writeError(w, apierror.NotFound("unknown relay endpoint"))
+		return
+	}
+
+	switch {
+	case len(parts) == 2 && parts[1] == "messages" && r.Method == http.MethodPost:
+		s.publish(w, r)
+	case len(parts) == 2 && parts[1] == "sessions" && r.Method == http.MethodPost:
+		s.createSession(w)
+	case len(parts) == 3 && parts[1] == "sessions" && r.Method == http.MethodDelete:
+		s.removeSession(w, parts[2])
+	case len(parts) == 4 && parts[1] == "sessions" && parts[3] == "subscriptions" && r.Method == http.MethodPost:
+		s.subscribe(w, r, parts[2])
+	case len(parts) == 5 && parts[1] == "sessions" && parts[3] == "subscriptions" && r.Method == http.MethodDelete:
+		s.unsubscribe(w, parts[2], parts[4])
+	case len(parts) == 4 && parts[1] == "sessions" && parts[3] == "messages" && r.Method == http.MethodGet:
+		s.poll(w, r, parts[2])
+	default:
+		writeError(w, apierror.NotFound("unknown relay endpoint"))
+	}
+}
+
+func (s *Server) createSession(w http.ResponseWriter) {
+	id, err := newID()
+	if err != nil {
+		writeError(w, apierror.InternalDefault())
+		return
+	}
+
+	s.mu.Lock()
+	expired := s.expireSessions()
+	s.sessions[id] = &relaySession{
+		subs:     make(map[string]string),
+		upstream: make(map[string]broker.Subscription),
+		notify:   make(chan struct{}, 1),
+		lastSeen: s.now(),
+	}
+	s.mu.Unlock()
+	unsubscribeAll(expired)
+
+	writeJSON(w, sessionResponse{ID: id})
+}
+
+func (s *Server) removeSession(w http.ResponseWriter, sessionID string) {
+	s.mu.Lock()
+	var subs []broker.Subscription
+	if session, ok := s.sessions[sessionID]; ok {
+		subs = session.upstreamSubs()
+		delete(s.sessions, sessionID)
+	}
+	s.mu.Unlock()
+	unsubscribeAll(subs)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) subscribe(w http.ResponseWriter, r *http.Request, sessionID string) {
+	var req subscribeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Subject == "" {
+		writeError(w, apierror.ParseFailed())
+		return
+	}
+
+	id, err := newID()
+	if err != nil {
+		writeError(w, apierror.InternalDefault())
+		return
+	}
+
+	s.mu.Lock()
+	session, ok := s.sessions[sessionID]
+	if ok {
+		session.subs[id] = req.Subject
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, apierror.NotFound("relay session not found"))
+		return
+	}
+
+	if s.upstream != nil {
+		sub, err := s.upstream.Subscribe(req.Subject, func(msg *broker.Msg) {
+			s.deliver(sessionID, id, message{Subject: msg.Subject, Reply: msg.Reply, Data: msg.Data})
+		})
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to subscribe to %s upstream", req.Subject)
+			s.mu.Lock()
+			delete(session.subs, id)
+			s.mu.Unlock()
+			writeError(w, apierror.ServiceUnavailable())
+			return
+		}
+
+		s.mu.Lock()
+		_, active := session.subs[id]
+		if active {
+			session.upstream[id] = sub
+		}
+		s.mu.Unlock()
+		// The session was removed or expired while subscribing.
+		if !active {
+			unsubscribeAll([]broker.Subscription{sub})
+		}
+	}
+
+	writeJSON(w, subscribeResponse{ID: id})
+}
+
+func (s *Server) unsubscribe(w http.ResponseWriter, sessionID, subscriptionID string) {
+	s.mu.Lock()
+	session, ok := s.sessions[sessionID]
+	var sub broker.Subscription
+	if ok {
+		sub = session.upstream[subscriptionID]
+		delete(session.subs, subscriptionID)
+		delete(session.upstream, subscriptionID)
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, apierror.NotFound("relay session not found"))
+		return
+	}
+	if sub != nil {
+		unsubscribeAll([]broker.Subscription{sub})
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) publish(w http.ResponseWriter, r *http.Request) {
+	var msg message
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil || msg.Subject == "" {
+		writeError(w, apierror.ParseFailed())
+		return
+	}
+
+	if s.upstream != nil {
+		s.mu.Lock()
+		expired := s.expireSessions()
+		s.mu.Unlock()
+		unsubscribeAll(expired)
+
+		// Sessions receive the message back through their upstream subscriptions.
+		if err := s.upstream.PublishRequest(msg.Subject, msg.Reply, msg.Data); err != nil {
+			log.Warn().Err(err).Msgf("Failed to publish %s upstream", msg.Subject)
+			writeError(w, apierror.ServiceUnavailable())
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	s.mu.Lock()
+	expired := s.expireSessions()
+	for _, session := range s.sessions {
+		for id, pattern := range session.subs {
+			if !subjectMatches(pattern, msg.Subject) {
+				continue
+			}
+			delivery := msg
+			delivery.SubscriptionID = id
+			session.enqueue(delivery)
+		}
+	}
+	s.mu.Unlock()
+	unsubscribeAll(expired)
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// deliver queues the upstream message for the session, unless the subscription was removed meanwhile.
+func (s *Server) deliver(sessionID, subscriptionID string, msg message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return
+	}
+	if _, ok := session.subs[subscriptionID]; !ok {
+		return
+	}
+	msg.SubscriptionID = subscriptionID
+	session.enqueue(msg)
+}
+
+func (s *Server) poll(w http.ResponseWriter, r *http.Request, sessionID string) {
+	wait := s.maxWait
+	if requested, err := time.ParseDuration(r.URL.Query().Get("wait")); err == nil && requested < wait {
+		wait = requested
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		session, ok := s.sessions[sessionID]
+		if !ok {
+			s.mu.Unlock()
+			writeError(w, apierror.NotFound("relay session not found"))
+			return
+		}
+		session.lastSeen = s.now()
+		messages := session.queue
+		session.queue = nil
+		notify := session.notify
+		s.mu.Unlock()
+
+		if len(messages) > 0 {
+			writeJSON(w, messages)
+			return
+		}
+
+		select {
+		case <-notify:
+		case <-timer.C:
+			writeJSON(w, []message{})
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// expireSessions must be called with s.mu held.
+// It returns upstream subscriptions of expired sessions, they must be removed without holding s.mu.
+func (s *Server) expireSessions() []broker.Subscription {
+	var subs []broker.Subscription
+	for id, session := range s.sessions {
+		if s.now().Sub(session.lastSeen) > sessionTTL {
+			subs = append(subs, session.upstreamSubs()...)
+			delete(s.sessions, id)
+		}
+	}
+	return subs
+}
+
+func (rs *relaySession) upstreamSubs() []broker.Subscription {
+	subs := make([]broker.Subscription, 0, len(rs.upstream))
+	for _, sub := range rs.upstream {
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+func unsubscribeAll(subs []broker.Subscription) {
+	for _, sub := range subs {
+		if err := sub.Unsubscribe(); err != nil {
+			log.Warn().Err(err).Msg("Failed to unsubscribe from upstream")
+		}
+	}
+}
+
+func (rs *relaySession) enqueue(msg message) {
+	if len(rs.queue) >= sessionQueueSize {
+		rs.queue = rs.queue[1:]
+	}
+	rs.queue = append(rs.queue, msg)
+
+	select {
+	case rs.notify <- struct{}{}:
+	default:
+	}
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, apiErr *apierror.APIError) {
+	w.Header().Set("Content-Type", apierror.ContentTypeV1)
+	w.WriteHeader(apiErr.Status)
+	_ = json.NewEncoder(w).Encode(apiErr)
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relay
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/communication/broker"
+)
+
+type mockUpstream struct {
+	mu        sync.Mutex
+	published []string
+	subs      map[*mockUpstreamSub]struct{}
+}
+
+type mockUpstreamSub struct {
+	upstream *mockUpstream
+	subject  string
+	handler  broker.MsgHandler
+}
+
+func (m *mockUpstream) PublishRequest(subject, reply string, data []byte) error {
+	m.mu.Lock()
+	m.published = append(m.published, subject)
+	var handlers []broker.MsgHandler
+	for sub := range m.subs {
+		if subjectMatches(sub.subject, subject) {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(&broker.Msg{Subject: subject, Reply: reply, Data: data})
+	}
+	return nil
+}
+
+func (m *mockUpstream) Subscribe(subject string, handler broker.MsgHandler) (broker.Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sub := &mockUpstreamSub{upstream: m, subject: subject, handler: handler}
+	m.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+func (m *mockUpstream) subscriptions() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.subs)
+}
+
+func (s *mockUpstreamSub) Unsubscribe() error {
+	s.upstream.mu.Lock()
+	defer s.upstream.mu.Unlock()
+	delete(s.upstream.subs, s)
+	return nil
+}
+
+func (s *mockUpstreamSub) IsValid() bool {
+	return true
+}
+
+func Test_BridgeServer_RoutesMessagesThroughUpstream(t *testing.T) {
+	upstream := &mockUpstream{subs: make(map[*mockUpstreamSub]struct{})}
+	srv := NewBridgeServer(upstream)
+	srv.maxWait = 100 * time.Millisecond
+	httpSrv := httptest.NewServer(srv)
+	t.Cleanup(httpSrv.Close)
+	conn := connect(t, NewConnector(httpSrv.URL+"/", &http.Transport{}))
+
+	received := make(chan *broker.Msg, 1)
+	sub, err := conn.Subscribe("provider.*.ping", func(msg *broker.Msg) {
+		received <- msg
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, upstream.subscriptions())
+
+	require.NoError(t, upstream.PublishRequest("provider.0x1.ping", "", []byte("from broker")))
+	select {
+	case msg := <-received:
+		assert.Equal(t, "provider.0x1.ping", msg.Subject)
+		assert.Equal(t, []byte("from broker"), msg.Data)
+	case <-time.After(2 * time.Second):
+		t.Fatal("upstream message not delivered")
+	}
+
+	require.NoError(t, conn.Publish("consumer.0x1.ping", []byte("to broker")))
+	upstream.mu.Lock()
+	assert.Equal(t, []string{"provider.0x1.ping", "consumer.0x1.ping"}, upstream.published)
+	upstream.mu.Unlock()
+
+	require.NoError(t, sub.Unsubscribe())
+	assert.Equal(t, 0, upstream.subscriptions())
+}
+
+func Test_BridgeServer_UnsubscribesUpstreamOnSessionClose(t *testing.T) {
+	upstream := &mockUpstream{subs: make(map[*mockUpstreamSub]struct{})}
+	srv := NewBridgeServer(upstream)
+	srv.maxWait = 100 * time.Millisecond
+	httpSrv := httptest.NewServer(srv)
+	t.Cleanup(httpSrv.Close)
+
+	conn, err := NewConnector(httpSrv.URL+"/", &http.Transport{}).Connect()
+	require.NoError(t, err)
+	_, err = conn.Subscribe("topic", func(msg *broker.Msg) {})
+	require.NoError(t, err)
+	assert.Equal(t, 1, upstream.subscriptions())
+
+	conn.Close()
+	assert.Equal(t, 0, upstream.subscriptions())
+}
@@ -20,6 +20,7 @@ package config
 import (
 	"io/ioutil"
 	"math/big"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
@@ -46,13 +47,37 @@ type Config struct {
 	defaults           map[string]interface{}
 	user               map[string]interface{}
 	cli                map[string]interface{}
+	onRestart          map[string]interface{}
 	eventBus           eventbus.EventBus
+	secretCipher       SecretCipher
+	secretKeys         []string
 	mu                 sync.RWMutex
 }
 
 // Current global configuration instance.
 var Current = NewConfig()
 
+// cliOnlyKeys lists keys which may only be set via CLI flags, since they decide which code the node runs.
+// Their values found in the user configuration are ignored.
+var cliOnlyKeys = []string{
+	FlagUpdaterURL.Name,
+	FlagUpdaterPublicKey.Name,
+	FlagHookSessionStart.Name,
+	FlagHookSessionEnd.Name,
+	FlagHookSettlement.Name,
+}
+
+// IsCLIOnly reports whether the value for key may only be set via CLI flag.
+func IsCLIOnly(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range cliOnlyKeys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
 // NewConfig creates a new configuration instance.
 func NewConfig() *Config {
 	return &Config{
@@ -60,6 +85,7 @@ func NewConfig() *Config {
 		defaults:           make(map[string]interface{}),
 		user:               make(map[string]interface{}),
 		cli:                make(map[string]interface{}),
+		onRestart:          make(map[string]interface{}),
 	}
 }
 
@@ -102,8 +128,12 @@ func (cfg *Config) SaveUserConfig() error {
 	if !cfg.userConfigLoaded() {
 		return errors.New("user configuration cannot be saved, because it must be loaded first")
 	}
+	user, err := cfg.userConfigForFile()
+	if err != nil {
+		return err
+	}
 	var out strings.Builder
-	err := toml.NewEncoder(&out).Encode(cfg.user)
+	err = toml.NewEncoder(&out).Encode(user)
 	if err != nil {
 		return errors.Wrap(err, "failed to write configuration as toml")
 	}
@@ -111,7 +141,7 @@ func (cfg *Config) SaveUserConfig() error {
 	if err != nil {
 		return errors.Wrap(err, "failed to write configuration to file")
 	}
-	cfgJson, err := jsonutil.ToJson(cfg.user)
+	cfgJson, err := jsonutil.ToJson(user)
 	if err != nil {
 		return err
 	}
@@ -161,6 +191,29 @@ func (cfg *Config) SetUser(key string, value interface{}) {
 	cfg.set(cfg.user, key, value)
 }
 
+// SetUserOnRestart sets user configuration value for key which is saved to the
+// user configuration file, but takes effect only after the node restart.
+func (cfg *Config) SetUserOnRestart(key string, value interface{}) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	cfg.onRestart[strings.ToLower(key)] = value
+}
+
+// RemoveUserOnRestart discards the value for key set with SetUserOnRestart.
+func (cfg *Config) RemoveUserOnRestart(key string) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	delete(cfg.onRestart, strings.ToLower(key))
+}
+
+// GetUserOnRestart returns the value for key which takes effect after the node restart.
+func (cfg *Config) GetUserOnRestart(key string) (interface{}, bool) {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	value, ok := cfg.onRestart[strings.ToLower(key)]
+	return value, ok
+}
+
 // SetCLI sets value passed via CLI flag for key.
 func (cfg *Config) SetCLI(key string, value interface{}) {
 	cfg.set(cfg.cli, key, value)
@@ -217,7 +270,7 @@ func (cfg *Config) Get(key string) interface{} {
 		return copyValue(cliValue)
 	}
 	userValue := SearchMap(cfg.user, segments)
-	if userValue != nil {
+	if userValue != nil && !IsCLIOnly(key) {
 		log.Debug().Msgf("Returning user config value %v:%v", key, userValue)
 		return copyValue(userValue)
 	}
@@ -226,6 +279,14 @@ func (cfg *Config) Get(key string) interface{} {
 	return copyValue(defaultValue)
 }
 
+// IsSetByCLI reports whether the value for key was passed via CLI flag.
+func (cfg *Config) IsSetByCLI(key string) bool {
+	segments := strings.Split(strings.ToLower(key), ".")
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	return SearchMap(cfg.cli, segments) != nil
+}
+
 // returns scalar values as is. deep-copies maps.
 func copyValue(value interface{}) interface{} {
 	switch v := value.(type) {
@@ -411,6 +472,48 @@ func (cfg *Config) ParseBlockchainNetworkFlag(ctx *cli.Context, flag cli.StringF
 	}
 }
 
+// ParseNetworkDefinitionFlag parses a cli.StringFlag as a path to a private network definition
+// file and sets values of network parameters from it to the application configuration,
+// only parameters given explicitly as CLI flags take precedence over the file.
+func (cfg *Config) ParseNetworkDefinitionFlag(ctx *cli.Context, flag cli.StringFlag) {
+	cfg.ParseStringFlag(ctx, flag)
+	path := cfg.GetString(flag.Name)
+	if path == "" {
+		return
+	}
+
+	network, err := metadata.LoadPrivateNetwork(path)
+	if err != nil {
+		log.Err(err).Msg("Failed to load private network definition")
+		return
+	}
+	values := network.Definition.GetDefaultFlagValues()
+	// Quality metrics of a private network must never reach the public quality oracle.
+	if network.QualityOracleAddress != "" {
+		values[FlagQualityAddress.Name] = network.QualityOracleAddress
+	} else {
+		values[FlagQualityType.Name] = "none"
+	}
+	for flagName, flagValue := range values {
+		if isEmptyFlagValue(flagValue) {
+			continue
+		}
+		cfg.SetDefault(flagName, flagValue)
+		if !ctx.IsSet(flagName) {
+			cfg.SetCLI(flagName, flagValue)
+		}
+	}
+}
+
+// isEmptyFlagValue tells whether a network definition left the value out, such values keep their defaults.
+func isEmptyFlagValue(value any) bool {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Slice {
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
 // GetBool shorthand for getting current configuration value for cli.BoolFlag.
 func GetBool(flag cli.BoolFlag) bool {
 	return Current.GetBool(flag.Name)
@@ -21,6 +21,7 @@ import (
 	"flag"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -53,6 +54,19 @@ func TestUserConfig_Load(t *testing.T) {
 	assert.Equal(t, 31338, cfg.GetInt("openvpn.port"))
 }
 
+func TestConfig_CLIOnlyKeysIgnoreUserValues(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetDefault(FlagUpdaterURL.Name, "")
+	cfg.SetUser(FlagUpdaterURL.Name, "http://attacker.example")
+	cfg.SetUser(FlagUpdaterChannel.Name, "beta")
+
+	assert.Equal(t, "", cfg.GetString(FlagUpdaterURL.Name))
+	assert.Equal(t, "beta", cfg.GetString(FlagUpdaterChannel.Name))
+
+	cfg.SetCLI(FlagUpdaterURL.Name, "http://releases.example")
+	assert.Equal(t, "http://releases.example", cfg.GetString(FlagUpdaterURL.Name))
+}
+
 func TestUserConfig_Save(t *testing.T) {
 	// given
 	configFileName := NewTempFileName(t)
@@ -81,6 +95,31 @@ func TestUserConfig_Save(t *testing.T) {
 	assert.NotContains(t, string(tomlContent), `proto = "tcp"`)
 }
 
+func TestUserConfig_SaveOnRestartValues(t *testing.T) {
+	configFileName := NewTempFileName(t)
+	defer os.Remove(configFileName)
+
+	cfg := NewConfig()
+	err := cfg.LoadUserConfig(configFileName)
+	assert.NoError(t, err)
+
+	cfg.SetUser("chain-id", 80001)
+	cfg.SetUserOnRestart("chain-id", 137)
+	cfg.SetUser("openvpn.port", 22822)
+
+	// running configuration keeps the current value
+	assert.Equal(t, 80001, cfg.GetInt("chain-id"))
+
+	err = cfg.SaveUserConfig()
+	assert.NoError(t, err)
+
+	restarted := NewConfig()
+	err = restarted.LoadUserConfig(configFileName)
+	assert.NoError(t, err)
+	assert.Equal(t, 137, restarted.GetInt("chain-id"))
+	assert.Equal(t, 22822, restarted.GetInt("openvpn.port"))
+}
+
 func NewTempFileName(t *testing.T) string {
 	file, err := ioutil.TempFile("", "*")
 	assert.NoError(t, err)
@@ -143,6 +182,32 @@ func TestConfig_ParseStringSliceFlag(t *testing.T) {
 	}
 }
 
+func TestConfig_ParseNetworkDefinitionFlag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "network.json")
+	must(t, os.WriteFile(path, []byte(`{
+		"name": "corp",
+		"definition": {
+			"DiscoveryAddress": "https://discovery.corp.example/api/v4",
+			"BrokerAddresses": ["nats://broker.corp.example"],
+			"DefaultChainID": 80001
+		}
+	}`), 0600))
+
+	cfg := NewConfig()
+	cfg.SetDefault(FlagMMNAddress.Name, FlagMMNAddress.Value)
+	cfg.SetDefault(FlagQualityType.Name, FlagQualityType.Value)
+	flagSet := flag.NewFlagSet("", flag.ContinueOnError)
+	must(t, FlagNetworkDefinition.Apply(flagSet))
+	must(t, flagSet.Parse([]string{"--" + FlagNetworkDefinition.Name, path}))
+	ctx := cli.NewContext(nil, flagSet, nil)
+
+	cfg.ParseNetworkDefinitionFlag(ctx, FlagNetworkDefinition)
+
+	assert.Equal(t, "https://discovery.corp.example/api/v4", cfg.GetString(FlagDiscoveryAddress.Name))
+	assert.Equal(t, FlagMMNAddress.Value, cfg.GetString(FlagMMNAddress.Name))
+	assert.Equal(t, "none", cfg.GetString(FlagQualityType.Name))
+}
+
 // this can happen when updating config via tequilapi - json unmarshal
 // translates json number to float64 by default if target type is interface{}
 func TestSimilarTypeMerge(t *testing.T) {
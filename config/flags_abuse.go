@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagAbuseWindow period over which consumer session churn and egress patterns are evaluated.
+	FlagAbuseWindow = cli.DurationFlag{
+		Name:  "abuse.window",
+		Usage: "Period over which consumer session churn and egress patterns are evaluated",
+		Value: 10 * time.Minute,
+	}
+	// FlagAbuseChurnThreshold number of sessions per window which flags the consumer.
+	FlagAbuseChurnThreshold = cli.IntFlag{
+		Name:  "abuse.churn-threshold",
+		Usage: "Number of sessions created by a consumer during the abuse window which flags the consumer",
+		Value: 30,
+	}
+	// FlagAbuseZeroPaymentThreshold number of consecutive unpaid sessions which flags the consumer.
+	FlagAbuseZeroPaymentThreshold = cli.IntFlag{
+		Name:  "abuse.zero-payment-threshold",
+		Usage: "Number of consecutive sessions ended without payment which flags the consumer",
+		Value: 5,
+	}
+	// FlagAbusePortScanThreshold number of distinct destination ports per window which flags the consumer.
+	FlagAbusePortScanThreshold = cli.IntFlag{
+		Name:  "abuse.port-scan-threshold",
+		Usage: "Number of distinct destination ports reached by a consumer during the abuse window which flags the consumer",
+		Value: 200,
+	}
+	// FlagAbuseBlockScore abuse score at which the consumer is blocked.
+	FlagAbuseBlockScore = cli.Float64Flag{
+		Name:  "abuse.block-score",
+		Usage: "Abuse score at which the consumer gets blocked. Each heuristic at its threshold adds 1 to the score. Blocking is disabled if 0",
+		Value: 0,
+	}
+	// FlagAbuseBlockDuration how long a consumer stays blocked.
+	FlagAbuseBlockDuration = cli.DurationFlag{
+		Name:  "abuse.block-duration",
+		Usage: "How long an automatically blocked consumer stays blocked",
+		Value: time.Hour,
+	}
+	// FlagAbuseRetention how long stats of consumers without active sessions are kept.
+	FlagAbuseRetention = cli.DurationFlag{
+		Name:  "abuse.retention",
+		Usage: "How long abuse stats of a consumer without active sessions are kept",
+		Value: 24 * time.Hour,
+	}
+)
+
+// RegisterFlagsAbuse function registers abuse detection flags to flag list.
+func RegisterFlagsAbuse(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagAbuseWindow,
+		&FlagAbuseChurnThreshold,
+		&FlagAbuseZeroPaymentThreshold,
+		&FlagAbusePortScanThreshold,
+		&FlagAbuseBlockScore,
+		&FlagAbuseBlockDuration,
+		&FlagAbuseRetention,
+	)
+}
+
+// ParseFlagsAbuse function fills in abuse detection options from CLI context.
+func ParseFlagsAbuse(ctx *cli.Context) {
+	Current.ParseDurationFlag(ctx, FlagAbuseWindow)
+	Current.ParseIntFlag(ctx, FlagAbuseChurnThreshold)
+	Current.ParseIntFlag(ctx, FlagAbuseZeroPaymentThreshold)
+	Current.ParseIntFlag(ctx, FlagAbusePortScanThreshold)
+	Current.ParseFloat64Flag(ctx, FlagAbuseBlockScore)
+	Current.ParseDurationFlag(ctx, FlagAbuseBlockDuration)
+	Current.ParseDurationFlag(ctx, FlagAbuseRetention)
+}
@@ -30,6 +30,11 @@ var (
 		Usage: "Affiliator URL address",
 		Value: metadata.DefaultNetwork.AffiliatorAddress,
 	}
+	// FlagReferralToken referral token used for consumer registration and top-ups.
+	FlagReferralToken = cli.StringFlag{
+		Name:  "referral.token",
+		Usage: "Referral token attached to consumer identity registration and top-up orders when none is given",
+	}
 )
 
 // RegisterFlagsAffiliator function register network flags to flag list
@@ -37,10 +42,20 @@ func RegisterFlagsAffiliator(flags *[]cli.Flag) {
 	*flags = append(
 		*flags,
 		&FlagAffiliatorAddress,
+		&FlagReferralToken,
 	)
 }
 
 // ParseFlagsAffiliator function fills in affiliator options from CLI context
 func ParseFlagsAffiliator(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagAffiliatorAddress)
+	Current.ParseStringFlag(ctx, FlagReferralToken)
+}
+
+// ReferralToken returns the given referral token or the configured one if it is empty.
+func ReferralToken(token string) string {
+	if token != "" {
+		return token
+	}
+	return GetString(FlagReferralToken)
 }
@@ -0,0 +1,122 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagAutoConnectOnStart connects the consumer using the last connection profile once the node is started.
+	FlagAutoConnectOnStart = cli.BoolFlag{
+		Name:  "autoconnect.on-start",
+		Usage: "Connect using the last connection profile when the node starts",
+		Value: false,
+	}
+	// FlagAutoConnectOnNetworkRestore connects the consumer using the last connection profile when connectivity returns.
+	FlagAutoConnectOnNetworkRestore = cli.BoolFlag{
+		Name:  "autoconnect.on-network-restore",
+		Usage: "Connect using the last connection profile when internet connectivity returns",
+		Value: false,
+	}
+	// FlagAutoConnectOnUntrustedWiFi connects the consumer using the last connection profile on open Wi-Fi networks.
+	FlagAutoConnectOnUntrustedWiFi = cli.BoolFlag{
+		Name:  "autoconnect.on-untrusted-wifi",
+		Usage: "Connect using the last connection profile when joining an open Wi-Fi network, where Wi-Fi is detectable",
+		Value: false,
+	}
+	// FlagAutoConnectTrustedSSIDs Wi-Fi networks on which the consumer is never auto-connected.
+	FlagAutoConnectTrustedSSIDs = cli.StringSliceFlag{
+		Name:  "autoconnect.trusted-ssids",
+		Usage: "Trusted Wi-Fi network SSIDs on which the consumer is never auto-connected",
+	}
+	// FlagAutoConnectTrustedGatewayMACs networks, identified by the default gateway hardware address, on which the consumer is never auto-connected.
+	FlagAutoConnectTrustedGatewayMACs = cli.StringSliceFlag{
+		Name:  "autoconnect.trusted-gateway-macs",
+		Usage: "Hardware addresses of default gateways of trusted networks on which the consumer is never auto-connected",
+	}
+	// FlagAutoConnectTrustedCIDRs networks, identified by the local address range, on which the consumer is never auto-connected.
+	FlagAutoConnectTrustedCIDRs = cli.StringSliceFlag{
+		Name:  "autoconnect.trusted-cidrs",
+		Usage: "Local address ranges of trusted networks on which the consumer is never auto-connected, e.g. 192.168.1.0/24",
+	}
+	// FlagAutoConnectDisconnectOnTrusted disconnects the consumer when it joins a trusted network.
+	FlagAutoConnectDisconnectOnTrusted = cli.BoolFlag{
+		Name:  "autoconnect.disconnect-on-trusted",
+		Usage: "Disconnect when joining a trusted network",
+		Value: false,
+	}
+	// FlagAutoConnectMetered policy applied when the OS reports the uplink as metered.
+	FlagAutoConnectMetered = cli.StringFlag{
+		Name:  "autoconnect.metered",
+		Usage: "Policy on metered uplinks, where the OS reports it: ignore, warn, cap (limit uplink throughput while connected, requires --autoconnect.metered-cap-host) or refuse (never auto-connect)",
+		Value: "ignore",
+	}
+	// FlagAutoConnectMeteredCap throughput limit applied with the cap policy on metered uplinks.
+	FlagAutoConnectMeteredCap = cli.Uint64Flag{
+		Name:  "autoconnect.metered-cap",
+		Usage: "Throughput limit in Kbytes applied on metered uplinks with the cap policy, linux only",
+		Value: 256,
+	}
+	// FlagAutoConnectMeteredCapHost confirms the cap policy may throttle the physical uplink interface.
+	FlagAutoConnectMeteredCapHost = cli.BoolFlag{
+		Name:  "autoconnect.metered-cap-host",
+		Usage: "Allow the cap policy to throttle the default route interface, which limits all host traffic and not only the tunnel",
+		Value: false,
+	}
+	// FlagAutoConnectNetworkCheckInterval interval of uplink network checks.
+	FlagAutoConnectNetworkCheckInterval = cli.DurationFlag{
+		Name:  "autoconnect.network-check-interval",
+		Usage: "How often uplink network is checked for changes",
+		Value: 5 * time.Second,
+	}
+)
+
+// RegisterFlagsAutoConnect function registers consumer auto-connect flags to flag list.
+func RegisterFlagsAutoConnect(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagAutoConnectOnStart,
+		&FlagAutoConnectOnNetworkRestore,
+		&FlagAutoConnectOnUntrustedWiFi,
+		&FlagAutoConnectTrustedSSIDs,
+		&FlagAutoConnectTrustedGatewayMACs,
+		&FlagAutoConnectTrustedCIDRs,
+		&FlagAutoConnectDisconnectOnTrusted,
+		&FlagAutoConnectMetered,
+		&FlagAutoConnectMeteredCap,
+		&FlagAutoConnectMeteredCapHost,
+		&FlagAutoConnectNetworkCheckInterval,
+	)
+}
+
+// ParseFlagsAutoConnect function fills in consumer auto-connect options from CLI context.
+func ParseFlagsAutoConnect(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagAutoConnectOnStart)
+	Current.ParseBoolFlag(ctx, FlagAutoConnectOnNetworkRestore)
+	Current.ParseBoolFlag(ctx, FlagAutoConnectOnUntrustedWiFi)
+	Current.ParseStringSliceFlag(ctx, FlagAutoConnectTrustedSSIDs)
+	Current.ParseStringSliceFlag(ctx, FlagAutoConnectTrustedGatewayMACs)
+	Current.ParseStringSliceFlag(ctx, FlagAutoConnectTrustedCIDRs)
+	Current.ParseBoolFlag(ctx, FlagAutoConnectDisconnectOnTrusted)
+	Current.ParseStringFlag(ctx, FlagAutoConnectMetered)
+	Current.ParseUInt64Flag(ctx, FlagAutoConnectMeteredCap)
+	Current.ParseBoolFlag(ctx, FlagAutoConnectMeteredCapHost)
+	Current.ParseDurationFlag(ctx, FlagAutoConnectNetworkCheckInterval)
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// BrokerTransportNATS connects to brokers directly over NATS protocol.
+	BrokerTransportNATS = "nats"
+	// BrokerTransportHTTPS reaches brokers through an HTTPS long-polling relay.
+	BrokerTransportHTTPS = "https"
+)
+
+var (
+	// FlagBrokerTransport selects how the node reaches the broker network.
+	FlagBrokerTransport = cli.StringFlag{
+		Name:  "broker.transport",
+		Usage: "Broker transport: 'nats' connects to brokers directly, 'https' goes through a relay for networks blocking NATS ports",
+		Value: BrokerTransportNATS,
+	}
+	// FlagBrokerRelayURL address of the HTTPS broker relay.
+	FlagBrokerRelayURL = cli.StringFlag{
+		Name:  "broker.relay-url",
+		Usage: "HTTPS relay address used when broker transport is 'https', the relay bridging to NATS brokers is cmd/brokerrelay",
+		Value: "",
+	}
+)
+
+// RegisterFlagsBroker function registers broker flags to flag list.
+func RegisterFlagsBroker(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagBrokerTransport,
+		&FlagBrokerRelayURL,
+	)
+}
+
+// ParseFlagsBroker function fills in broker options from CLI context.
+func ParseFlagsBroker(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagBrokerTransport)
+	Current.ParseStringFlag(ctx, FlagBrokerRelayURL)
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagCamouflage enables WireGuard tunneling over TLS.
+	FlagCamouflage = cli.BoolFlag{
+		Name:  "tls-camouflage",
+		Usage: "Tunnel WireGuard traffic over TLS for networks allowing HTTPS only (provider listens for it, consumer requests it)",
+		Value: false,
+	}
+	// FlagCamouflageAddress TLS camouflage listen address of the provider.
+	FlagCamouflageAddress = cli.StringFlag{
+		Name:  "tls-camouflage.address",
+		Usage: "Address provider listens on for TLS camouflage connections, ports below 1024 require root",
+		Value: ":8443",
+	}
+	// FlagCamouflagePublicPort TLS camouflage port advertised to consumers.
+	FlagCamouflagePublicPort = cli.IntFlag{
+		Name:  "tls-camouflage.public-port",
+		Usage: "Port advertised to consumers when 443 is forwarded to the listen address, 0 advertises the listen port",
+		Value: 0,
+	}
+	// FlagCamouflageCert camouflage certificate file.
+	FlagCamouflageCert = cli.StringFlag{
+		Name:  "tls-camouflage.cert",
+		Usage: "PEM certificate presented to TLS clients, self-signed one is generated when empty",
+		Value: "",
+	}
+	// FlagCamouflageKey camouflage certificate private key file.
+	FlagCamouflageKey = cli.StringFlag{
+		Name:  "tls-camouflage.key",
+		Usage: "PEM private key of the camouflage certificate",
+		Value: "",
+	}
+	// FlagCamouflageServerName server name consumers send in TLS handshake.
+	FlagCamouflageServerName = cli.StringFlag{
+		Name:  "tls-camouflage.server-name",
+		Usage: "Server name consumers send in TLS handshake and the self-signed certificate is issued for",
+		Value: "",
+	}
+	// FlagCamouflageFallbackURL site served to non-VPN clients.
+	FlagCamouflageFallbackURL = cli.StringFlag{
+		Name:  "tls-camouflage.fallback-url",
+		Usage: "Site non-VPN TLS clients are proxied to, empty serves a plain 404 page",
+		Value: "",
+	}
+)
+
+// RegisterFlagsCamouflage function registers TLS camouflage flags to flag list.
+func RegisterFlagsCamouflage(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagCamouflage,
+		&FlagCamouflageAddress,
+		&FlagCamouflagePublicPort,
+		&FlagCamouflageCert,
+		&FlagCamouflageKey,
+		&FlagCamouflageServerName,
+		&FlagCamouflageFallbackURL,
+	)
+}
+
+// ParseFlagsCamouflage function fills in TLS camouflage options from CLI context.
+func ParseFlagsCamouflage(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagCamouflage)
+	Current.ParseStringFlag(ctx, FlagCamouflageAddress)
+	Current.ParseIntFlag(ctx, FlagCamouflagePublicPort)
+	Current.ParseStringFlag(ctx, FlagCamouflageCert)
+	Current.ParseStringFlag(ctx, FlagCamouflageKey)
+	Current.ParseStringFlag(ctx, FlagCamouflageServerName)
+	Current.ParseStringFlag(ctx, FlagCamouflageFallbackURL)
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagClockSkewDetection enables periodic clock skew detection.
+	FlagClockSkewDetection = cli.BoolFlag{
+		Name:  "clock.skew-detection",
+		Usage: "Measure system clock offset using NTP and compensate timestamps of signed protocol messages",
+		Value: false,
+	}
+	// FlagClockNTPServer NTP server used to measure clock skew.
+	FlagClockNTPServer = cli.StringFlag{
+		Name:  "clock.ntp-server",
+		Usage: "NTP server (host:port) used to measure clock skew",
+		Value: "pool.ntp.org:123",
+	}
+	// FlagClockCheckInterval interval between clock skew measurements.
+	FlagClockCheckInterval = cli.DurationFlag{
+		Name:  "clock.check-interval",
+		Usage: "Interval between clock skew measurements",
+		Value: 30 * time.Minute,
+	}
+	// FlagClockTolerance maximum clock skew compensated in protocol timestamps.
+	FlagClockTolerance = cli.DurationFlag{
+		Name:  "clock.tolerance",
+		Usage: "Maximum clock skew compensated in timestamps of signed protocol messages",
+		Value: 30 * time.Second,
+	}
+	// FlagClockDangerThreshold clock skew above which a warning event is raised.
+	FlagClockDangerThreshold = cli.DurationFlag{
+		Name:  "clock.danger-threshold",
+		Usage: "Clock skew above which payments and signed messages are likely to be rejected and a warning is raised",
+		Value: time.Minute,
+	}
+)
+
+// RegisterFlagsClock function registers clock skew detection flags to flag list.
+func RegisterFlagsClock(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagClockSkewDetection,
+		&FlagClockNTPServer,
+		&FlagClockCheckInterval,
+		&FlagClockTolerance,
+		&FlagClockDangerThreshold,
+	)
+}
+
+// ParseFlagsClock function fills in clock skew detection options from CLI context.
+func ParseFlagsClock(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagClockSkewDetection)
+	Current.ParseStringFlag(ctx, FlagClockNTPServer)
+	Current.ParseDurationFlag(ctx, FlagClockCheckInterval)
+	Current.ParseDurationFlag(ctx, FlagClockTolerance)
+	Current.ParseDurationFlag(ctx, FlagClockDangerThreshold)
+}
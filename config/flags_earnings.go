@@ -0,0 +1,51 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagEarningsGoal sets the provider earnings goal.
+	FlagEarningsGoal = cli.Float64Flag{
+		Name:  "earnings.goal",
+		Usage: "Provider earnings goal in MYST per goal period, 0 disables goal tracking",
+		Value: 0,
+	}
+	// FlagEarningsGoalPeriod sets the period earnings goal applies to.
+	FlagEarningsGoalPeriod = cli.StringFlag{
+		Name:  "earnings.goal-period",
+		Usage: "Period earnings goal applies to: 'week' or 'month'",
+		Value: "month",
+	}
+)
+
+// RegisterFlagsEarnings function registers earnings flags to flag list.
+func RegisterFlagsEarnings(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagEarningsGoal,
+		&FlagEarningsGoalPeriod,
+	)
+}
+
+// ParseFlagsEarnings function fills in earnings options from CLI context.
+func ParseFlagsEarnings(ctx *cli.Context) {
+	Current.ParseFloat64Flag(ctx, FlagEarningsGoal)
+	Current.ParseStringFlag(ctx, FlagEarningsGoalPeriod)
+}
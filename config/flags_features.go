@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagFeatureFlags local feature values.
+	FlagFeatureFlags = cli.StringFlag{
+		Name:  "feature-flags",
+		Usage: "Enable or disable node features. Multiple features are joined by comma (e.g mtu-discovery=false,self-update=true)",
+		Value: "",
+	}
+	// FlagFeatureFlagsURL location of the signed remote feature flag feed.
+	FlagFeatureFlagsURL = cli.StringFlag{
+		Name:  "feature-flags.url",
+		Usage: "Location of the signed remote feature flag feed. Remote values override local ones. Disabled if empty",
+		Value: "",
+	}
+	// FlagFeatureFlagsPublicKey key verifying remote feature flag feed signatures.
+	FlagFeatureFlagsPublicKey = cli.StringFlag{
+		Name:  "feature-flags.public-key",
+		Usage: "Hex encoded ed25519 public key verifying remote feature flag feed signatures",
+		Value: "",
+	}
+	// FlagFeatureFlagsRefreshInterval how often the remote feature flag feed is fetched.
+	FlagFeatureFlagsRefreshInterval = cli.DurationFlag{
+		Name:  "feature-flags.refresh-interval",
+		Usage: "How often the remote feature flag feed is fetched",
+		Value: 10 * time.Minute,
+	}
+)
+
+// RegisterFlagsFeatures function registers feature flag options to flag list.
+func RegisterFlagsFeatures(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagFeatureFlags,
+		&FlagFeatureFlagsURL,
+		&FlagFeatureFlagsPublicKey,
+		&FlagFeatureFlagsRefreshInterval,
+	)
+}
+
+// ParseFlagsFeatures function fills in feature flag options from CLI context.
+func ParseFlagsFeatures(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagFeatureFlags)
+	Current.ParseStringFlag(ctx, FlagFeatureFlagsURL)
+	Current.ParseStringFlag(ctx, FlagFeatureFlagsPublicKey)
+	Current.ParseDurationFlag(ctx, FlagFeatureFlagsRefreshInterval)
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagHookSessionStart hook executed when provider session starts.
+	FlagHookSessionStart = cli.StringFlag{
+		Name:  "hooks.on-session-start",
+		Usage: "URL to POST or path of a script to execute with session context when provider session starts. Accepted only as CLI flag",
+	}
+	// FlagHookSessionEnd hook executed when provider session ends.
+	FlagHookSessionEnd = cli.StringFlag{
+		Name:  "hooks.on-session-end",
+		Usage: "URL to POST or path of a script to execute with session context when provider session ends. Accepted only as CLI flag",
+	}
+	// FlagHookSettlement hook executed when provider settlement completes.
+	FlagHookSettlement = cli.StringFlag{
+		Name:  "hooks.on-settlement",
+		Usage: "URL to POST or path of a script to execute when provider settlement completes. Accepted only as CLI flag",
+	}
+	// FlagHookTimeout limits hook execution time.
+	FlagHookTimeout = cli.DurationFlag{
+		Name:  "hooks.timeout",
+		Usage: "Maximum execution time of a single hook",
+		Value: 30 * time.Second,
+	}
+)
+
+// RegisterFlagsHooks function registers session event hook flags to flag list.
+func RegisterFlagsHooks(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagHookSessionStart,
+		&FlagHookSessionEnd,
+		&FlagHookSettlement,
+		&FlagHookTimeout,
+	)
+}
+
+// ParseFlagsHooks function fills in session event hook options from CLI context.
+func ParseFlagsHooks(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagHookSessionStart)
+	Current.ParseStringFlag(ctx, FlagHookSessionEnd)
+	Current.ParseStringFlag(ctx, FlagHookSettlement)
+	Current.ParseDurationFlag(ctx, FlagHookTimeout)
+}
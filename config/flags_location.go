@@ -19,6 +19,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/urfave/cli/v2"
@@ -31,6 +32,24 @@ var (
 		Usage: "Address (URL form) of IP detection service",
 		Value: metadata.DefaultNetwork.LocationAddress,
 	}
+	// FlagIPConsensus enables public IP resolution by consensus of multiple sources.
+	FlagIPConsensus = cli.BoolFlag{
+		Name:  "ip-detector.consensus",
+		Usage: "Resolve public IP by querying IP detection service, HTTPS echo services, STUN servers and router UPnP concurrently and picking the IP most of them agree on",
+		Value: false,
+	}
+	// FlagIPConsensusMinConfidence minimum share of sources agreeing on public IP.
+	FlagIPConsensusMinConfidence = cli.Float64Flag{
+		Name:  "ip-detector.consensus.min-confidence",
+		Usage: "Minimum share of responding sources (0-1) that must agree on public IP, IP detection service is used otherwise",
+		Value: 0.5,
+	}
+	// FlagIPCheckInterval interval of public IP change checks.
+	FlagIPCheckInterval = cli.DurationFlag{
+		Name:  "ip-detector.check-interval",
+		Usage: "Interval of provider public IP change checks, 0 disables checks",
+		Value: 5 * time.Minute,
+	}
 	// FlagLocationType location detector type.
 	FlagLocationType = cli.StringFlag{
 		Name:  "location.type",
@@ -61,26 +80,69 @@ var (
 		Name:  "location.ip-type",
 		Usage: "Service location IP type (residential, datacenter, etc.)",
 	}
+	// FlagLocationConsumerPrivacy consumer location privacy mode.
+	FlagLocationConsumerPrivacy = cli.StringFlag{
+		Name:  "location.consumer-privacy",
+		Usage: "How consumer location is reported in session metadata and quality reports. Options: { off, override, omit }",
+		Value: "off",
+	}
+	// FlagLocationConsumerCountry consumer country reported in override privacy mode.
+	FlagLocationConsumerCountry = cli.StringFlag{
+		Name:  "location.consumer-country",
+		Usage: "Two letter country code reported instead of the detected consumer location when '--location.consumer-privacy=override'",
+	}
+	// FlagLocationGeoIPCountryURL URL of MaxMind-compatible country database updates.
+	FlagLocationGeoIPCountryURL = cli.StringFlag{
+		Name:  "location.geoip.country-url",
+		Usage: "URL of MaxMind-compatible country database used to refresh builtin or mmdb location adapters. Updates are disabled if empty",
+	}
+	// FlagLocationGeoIPASNURL URL of MaxMind-compatible ASN database updates.
+	FlagLocationGeoIPASNURL = cli.StringFlag{
+		Name:  "location.geoip.asn-url",
+		Usage: "URL of MaxMind-compatible ASN database used for ASN lookups. ASN lookups are disabled if empty",
+	}
+	// FlagLocationGeoIPUpdateInterval GeoIP database refresh interval.
+	FlagLocationGeoIPUpdateInterval = cli.DurationFlag{
+		Name:  "location.geoip.update-interval",
+		Usage: "How often GeoIP databases are refreshed, at least 1h",
+		Value: 7 * 24 * time.Hour,
+	}
 )
 
 // RegisterFlagsLocation function registers location flags to flag list.
 func RegisterFlagsLocation(flags *[]cli.Flag) {
 	*flags = append(*flags,
 		&FlagIPDetectorURL,
+		&FlagIPConsensus,
+		&FlagIPConsensusMinConfidence,
+		&FlagIPCheckInterval,
 		&FlagLocationType,
 		&FlagLocationAddress,
 		&FlagLocationCountry,
 		&FlagLocationCity,
 		&FlagLocationIPType,
+		&FlagLocationConsumerPrivacy,
+		&FlagLocationConsumerCountry,
+		&FlagLocationGeoIPCountryURL,
+		&FlagLocationGeoIPASNURL,
+		&FlagLocationGeoIPUpdateInterval,
 	)
 }
 
 // ParseFlagsLocation function fills in location options from CLI context.
 func ParseFlagsLocation(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagIPDetectorURL)
+	Current.ParseBoolFlag(ctx, FlagIPConsensus)
+	Current.ParseFloat64Flag(ctx, FlagIPConsensusMinConfidence)
+	Current.ParseDurationFlag(ctx, FlagIPCheckInterval)
 	Current.ParseStringFlag(ctx, FlagLocationType)
 	Current.ParseStringFlag(ctx, FlagLocationAddress)
 	Current.ParseStringFlag(ctx, FlagLocationCountry)
 	Current.ParseStringFlag(ctx, FlagLocationCity)
 	Current.ParseStringFlag(ctx, FlagLocationIPType)
+	Current.ParseStringFlag(ctx, FlagLocationConsumerPrivacy)
+	Current.ParseStringFlag(ctx, FlagLocationConsumerCountry)
+	Current.ParseStringFlag(ctx, FlagLocationGeoIPCountryURL)
+	Current.ParseStringFlag(ctx, FlagLocationGeoIPASNURL)
+	Current.ParseDurationFlag(ctx, FlagLocationGeoIPUpdateInterval)
 }
@@ -33,6 +33,12 @@ var (
 		Usage: "Defines default blockchain network configuration",
 		Value: string(Mainnet),
 	}
+	// FlagNetworkDefinition points to a private network definition file.
+	FlagNetworkDefinition = cli.StringFlag{
+		Name:  "network.definition",
+		Usage: "Path to a private network definition file (JSON) providing discovery, broker, hermes and oracle addresses and an identity allowlist",
+		Value: "",
+	}
 	// FlagAPIAddress Mysterium API URL
 	// Deprecated: use FlagDiscoveryAddress
 	FlagAPIAddress = cli.StringFlag{
@@ -138,6 +144,24 @@ var (
 		Value:  "echo.mysterium.network:4589",
 		Hidden: true,
 	}
+	// FlagMTUDiscovery enables path MTU discovery for consumer tunnels.
+	FlagMTUDiscovery = cli.BoolFlag{
+		Name:  "mtu-discovery",
+		Usage: "Probe path MTU to the provider and adjust tunnel MTU when connecting",
+		Value: false,
+	}
+	// FlagObfuscation enables obfuscation of WireGuard traffic with peers supporting it.
+	FlagObfuscation = cli.BoolFlag{
+		Name:  "obfuscation",
+		Usage: "Obfuscate WireGuard traffic to get through DPI (requested by consumer and advertised by provider)",
+		Value: false,
+	}
+	// FlagWireguardRekeyInterval sets how often consumer rotates WireGuard keys of a running session.
+	FlagWireguardRekeyInterval = cli.DurationFlag{
+		Name:  "wireguard.rekey-interval",
+		Usage: "Rotate WireGuard keys of long running sessions every given interval (at least 1m), 0 disables rotation. WireGuard handshakes already rotate session keys every 2 minutes",
+		Value: 0,
+	}
 )
 
 // RegisterFlagsNetwork function register network flags to flag list
@@ -161,6 +185,9 @@ func RegisterFlagsNetwork(flags *[]cli.Flag) {
 		&FlagUDPListenPorts,
 		&FlagTraversal,
 		&FlagPortCheckServers,
+		&FlagMTUDiscovery,
+		&FlagObfuscation,
+		&FlagWireguardRekeyInterval,
 	)
 }
 
@@ -183,6 +210,9 @@ func ParseFlagsNetwork(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagUDPListenPorts)
 	Current.ParseStringFlag(ctx, FlagTraversal)
 	Current.ParseStringFlag(ctx, FlagPortCheckServers)
+	Current.ParseBoolFlag(ctx, FlagMTUDiscovery)
+	Current.ParseBoolFlag(ctx, FlagObfuscation)
+	Current.ParseDurationFlag(ctx, FlagWireguardRekeyInterval)
 }
 
 //BlockchainNetwork defines a blockchain network
@@ -228,6 +258,7 @@ func (n BlockchainNetwork) IsLocalnet() bool {
 // ParseFlagsBlockchainNetwork function fills in directory options from CLI context
 func ParseFlagsBlockchainNetwork(ctx *cli.Context) {
 	Current.ParseBlockchainNetworkFlag(ctx, FlagBlockchainNetwork)
+	Current.ParseNetworkDefinitionFlag(ctx, FlagNetworkDefinition)
 }
 
 // RegisterFlagsBlockchainNetwork function registers blockchain network flags to flag list
@@ -235,5 +266,6 @@ func RegisterFlagsBlockchainNetwork(flags *[]cli.Flag) {
 	*flags = append(
 		*flags,
 		&FlagBlockchainNetwork,
+		&FlagNetworkDefinition,
 	)
 }
@@ -82,6 +82,12 @@ var (
 		Usage: "IP address to bind provided services to",
 		Value: "0.0.0.0",
 	}
+	// FlagBindServices binds services to network interfaces or source IPs.
+	FlagBindServices = cli.StringFlag{
+		Name:  "bind.services",
+		Usage: "Comma separated service bindings to a network interface or source IP, e.g. wireguard=eth1,openvpn=192.168.1.10",
+		Value: "",
+	}
 	// FlagFeedbackURL URL of Feedback API.
 	FlagFeedbackURL = cli.StringFlag{
 		Name:  "feedback.url",
@@ -93,6 +99,18 @@ var (
 		Name:  "firewall.killSwitch.always",
 		Usage: "Always block non-tunneled outgoing consumer traffic",
 	}
+	// FlagFirewallAllowedApps lets applications bypass the kill switch (split tunneling).
+	FlagFirewallAllowedApps = cli.StringSliceFlag{
+		Name:  "firewall.killSwitch.allowed-apps",
+		Usage: "Paths of applications allowed to bypass the kill switch (Windows only)",
+		Value: cli.NewStringSlice(),
+	}
+	// FlagFirewallDNSLeakProtection blocks DNS traffic bypassing the tunnel resolvers while connected.
+	FlagFirewallDNSLeakProtection = cli.StringFlag{
+		Name:  "firewall.dns-leak-protection",
+		Usage: "Block DNS traffic not sent to the tunnel resolvers while connected: off, standard (outside of the tunnel only) or strict (also inside of the tunnel)",
+		Value: "standard",
+	}
 	// FlagFirewallProtectedNetworks protects provider's networks from access via VPN
 	FlagFirewallProtectedNetworks = cli.StringFlag{
 		Name:  "firewall.protected.networks",
@@ -110,6 +128,24 @@ var (
 		Usage: "Set the bandwidth limit in Kbytes",
 		Value: 6250,
 	}
+	// FlagStatsResolution sets how often session traffic counters are sampled.
+	FlagStatsResolution = cli.DurationFlag{
+		Name:  "stats.resolution",
+		Usage: "How often session traffic counters are sampled, statistics are still published once per second",
+		Value: 250 * time.Millisecond,
+	}
+	// FlagServiceMaxSessions limits concurrent sessions per service.
+	FlagServiceMaxSessions = cli.IntFlag{
+		Name:  "service.max-sessions",
+		Usage: "Limit of concurrent sessions per service, advertised in proposals (0 - unlimited)",
+		Value: 0,
+	}
+	// FlagServiceBandwidthCapacity sets the advertised provider bandwidth.
+	FlagServiceBandwidthCapacity = cli.Uint64Flag{
+		Name:  "service.bandwidth-capacity",
+		Usage: "Provider bandwidth capacity in Kbytes/s used to advertise bandwidth headroom in proposals (0 - unknown)",
+		Value: 0,
+	}
 	// FlagKeystoreLightweight determines the scrypt memory complexity.
 	FlagKeystoreLightweight = cli.BoolFlag{
 		Name:  "keystore.lightweight",
@@ -121,6 +157,18 @@ var (
 		Name:  "log.http",
 		Usage: "Enable HTTP payload logging",
 	}
+	// FlagLogDedupInterval period for suppressing repeated log messages.
+	FlagLogDedupInterval = cli.DurationFlag{
+		Name:  "log.dedup-interval",
+		Usage: "Suppress repeated identical log messages for the given period, reporting only their count (0 - disabled)",
+		Value: 10 * time.Second,
+	}
+	// FlagLogDedupLevel lowest level of deduplicated log messages.
+	FlagLogDedupLevel = cli.StringFlag{
+		Name:  "log.dedup-level",
+		Usage: "Lowest level of log messages which are deduplicated",
+		Value: zerolog.WarnLevel.String(),
+	}
 	// FlagLogLevel logger level.
 	FlagLogLevel = cli.StringFlag{
 		Name: "log-level",
@@ -201,12 +249,60 @@ var (
 		Usage: "Default password for API authentication",
 		Value: "mystberry",
 	}
+	// FlagTequilapiTLS serves tequilapi and UI over TLS.
+	FlagTequilapiTLS = cli.BoolFlag{
+		Name:  "tequilapi.tls",
+		Usage: "Serve API and UI over TLS with a self-signed certificate stored in the data directory",
+		Value: false,
+	}
+	// FlagTequilapiTLSDomain domain to obtain ACME certificate for.
+	FlagTequilapiTLSDomain = cli.StringFlag{
+		Name:  "tequilapi.tls.domain",
+		Usage: "Obtain certificate for the domain using ACME (HTTP-01 challenge, port 80 of the domain must reach --tequilapi.tls.acme-http-address) instead of self-signed one",
+		Value: "",
+	}
+	// FlagTequilapiTLSACMEHTTPAddress address to answer ACME HTTP-01 challenges on.
+	FlagTequilapiTLSACMEHTTPAddress = cli.StringFlag{
+		Name:  "tequilapi.tls.acme-http-address",
+		Usage: "Address to answer ACME HTTP-01 challenges on, used only with --tequilapi.tls.domain",
+		Value: ":80",
+	}
+	// FlagTequilapiTLSACMEEmail ACME account contact email.
+	FlagTequilapiTLSACMEEmail = cli.StringFlag{
+		Name:  "tequilapi.tls.acme-email",
+		Usage: "Contact email for the ACME account",
+		Value: "",
+	}
+	// FlagTequilapiSocket unix domain socket to serve API on.
+	FlagTequilapiSocket = cli.StringFlag{
+		Name:  "tequilapi.socket",
+		Usage: "Path of a unix domain socket to serve API on in addition to TCP, disabled if empty",
+		Value: "",
+	}
+	// FlagTequilapiSocketMode file permissions of the API unix domain socket.
+	FlagTequilapiSocketMode = cli.StringFlag{
+		Name:  "tequilapi.socket-mode",
+		Usage: "Octal file permissions of the API unix domain socket",
+		Value: "0600",
+	}
+	// FlagTequilapiSocketOnly disables the API TCP listener.
+	FlagTequilapiSocketOnly = cli.BoolFlag{
+		Name:  "tequilapi.socket-only",
+		Usage: "Serve API only on the unix domain socket without opening a TCP port. UI requires TCP and won't work",
+		Value: false,
+	}
 	// FlagPProfEnable enables pprof via TequilAPI.
 	FlagPProfEnable = cli.BoolFlag{
 		Name:  "pprof.enable",
-		Usage: "Enables pprof",
+		Usage: "Enables pprof, goroutine dump and GC stats endpoints on TequilAPI. Requests must carry an API login token or pprof.token",
 		Value: false,
 	}
+	// FlagPProfToken is a static token granting access to debug endpoints.
+	FlagPProfToken = cli.StringFlag{
+		Name:  "pprof.token",
+		Usage: "Bearer token granting access to debug endpoints without logging in, e.g. for collecting profiles from scripts",
+		Value: "",
+	}
 	// FlagUserMode allows running node under current user without sudo.
 	FlagUserMode = cli.BoolFlag{
 		Name:  "usermode",
@@ -247,6 +343,48 @@ var (
 		Usage: "Deprecated flag, use --udp.ports to set range of listen ports",
 		Value: "0:0",
 	}
+	// FlagP2PExchangeRate limits incoming p2p exchange messages handled by provider.
+	FlagP2PExchangeRate = cli.Float64Flag{
+		Name:  "p2p.exchange.rate",
+		Usage: "Maximum number of incoming p2p exchange messages per second handled from all consumers. Unlimited if 0",
+		Value: 20,
+	}
+	// FlagP2PExchangePeerRate limits incoming p2p exchanges handled from a single consumer.
+	FlagP2PExchangePeerRate = cli.Float64Flag{
+		Name:  "p2p.exchange.peer-rate",
+		Usage: "Maximum number of incoming p2p exchanges per second handled from a single consumer identity. Unlimited if 0",
+		Value: 0.2,
+	}
+	// FlagP2PExchangeRequireRegistered allows p2p exchanges only from registered consumer identities.
+	FlagP2PExchangeRequireRegistered = cli.BoolFlag{
+		Name:  "p2p.exchange.require-registered",
+		Usage: "Handle incoming p2p exchanges only from consumer identities registered on chain",
+		Value: true,
+	}
+	// FlagP2PExchangeNewPeerRate limits incoming p2p exchanges handled from consumers not seen recently.
+	FlagP2PExchangeNewPeerRate = cli.Float64Flag{
+		Name:  "p2p.exchange.new-peer-rate",
+		Usage: "Maximum number of incoming p2p exchanges per second handled from consumer identities not seen recently. Unlimited if 0",
+		Value: 2,
+	}
+	// FlagP2PACLEnabled restricts p2p channel topics which peers may call based on their role.
+	FlagP2PACLEnabled = cli.BoolFlag{
+		Name:  "p2p.acl.enabled",
+		Usage: "Allow peers to call only p2p channel topics permitted for their role (consumer, provider or monitoring agent)",
+		Value: true,
+	}
+	// FlagP2PACLMonitoringIdentities lists identities of monitoring agents.
+	FlagP2PACLMonitoringIdentities = cli.StringSliceFlag{
+		Name:  "p2p.acl.monitoring-identities",
+		Usage: "Identities of monitoring agents, which may only check services but not manage the node",
+		Value: cli.NewStringSlice(),
+	}
+	// FlagP2PACLProviderIdentities lists identities of other providers.
+	FlagP2PACLProviderIdentities = cli.StringSliceFlag{
+		Name:  "p2p.acl.provider-identities",
+		Usage: "Identities of other providers, which may only keep p2p channels alive",
+		Value: cli.NewStringSlice(),
+	}
 
 	// FlagConsumer sets to run as consumer only which allows to skip bootstrap for some of the dependencies.
 	FlagConsumer = cli.BoolFlag{
@@ -294,10 +432,28 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 
 	RegisterFlagsLocation(flags)
 	RegisterFlagsNetwork(flags)
+	RegisterFlagsBroker(flags)
 	RegisterFlagsTransactor(flags)
 	RegisterFlagsAffiliator(flags)
 	RegisterFlagsPayments(flags)
+	RegisterFlagsPricing(flags)
+	RegisterFlagsEarnings(flags)
 	RegisterFlagsPolicy(flags)
+	RegisterFlagsAbuse(flags)
+	RegisterFlagsHooks(flags)
+	RegisterFlagsWarmup(flags)
+	RegisterFlagsAutoConnect(flags)
+	RegisterFlagsPreflight(flags)
+	RegisterFlagsClock(flags)
+	RegisterFlagsPrivacy(flags)
+	RegisterFlagsDDNS(flags)
+	RegisterFlagsCamouflage(flags)
+	RegisterFlagsUpdater(flags)
+	RegisterFlagsServiceHealth(flags)
+	RegisterFlagsServiceRestore(flags)
+	RegisterFlagsServiceSchedule(flags)
+	RegisterFlagsFeatures(flags)
+	RegisterFlagsStorage(flags)
 	RegisterFlagsMMN(flags)
 	RegisterFlagsPilvytis(flags)
 	RegisterFlagsChains(flags)
@@ -306,6 +462,7 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 
 	*flags = append(*flags,
 		&FlagBindAddress,
+		&FlagBindServices,
 		&FlagDiscoveryType,
 		&FlagDiscoveryPingInterval,
 		&FlagDiscoveryFetchInterval,
@@ -315,12 +472,20 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 		&FlagDHTBootstrapPeers,
 		&FlagFeedbackURL,
 		&FlagFirewallKillSwitch,
+		&FlagFirewallAllowedApps,
+		&FlagFirewallDNSLeakProtection,
 		&FlagFirewallProtectedNetworks,
 		&FlagShaperEnabled,
 		&FlagShaperBandwidth,
+		&FlagStatsResolution,
+		&FlagServiceMaxSessions,
+		&FlagServiceBandwidthCapacity,
 		&FlagKeystoreLightweight,
+		&FlagNoopEnabled,
 		&FlagLogHTTP,
 		&FlagLogLevel,
+		&FlagLogDedupInterval,
+		&FlagLogDedupLevel,
 		&FlagVerbose,
 		&FlagOpenvpnBinary,
 		&FlagQualityType,
@@ -330,13 +495,28 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 		&FlagTequilapiPort,
 		&FlagTequilapiUsername,
 		&FlagTequilapiPassword,
+		&FlagTequilapiTLS,
+		&FlagTequilapiTLSDomain,
+		&FlagTequilapiTLSACMEHTTPAddress,
+		&FlagTequilapiTLSACMEEmail,
+		&FlagTequilapiSocket,
+		&FlagTequilapiSocketMode,
+		&FlagTequilapiSocketOnly,
 		&FlagPProfEnable,
+		&FlagPProfToken,
 		&FlagUserMode,
 		&FlagProxyMode,
 		&FlagUserspace,
 		&FlagVendorID,
 		&FlagLauncherVersion,
 		&FlagP2PListenPorts,
+		&FlagP2PExchangeRate,
+		&FlagP2PExchangePeerRate,
+		&FlagP2PExchangeRequireRegistered,
+		&FlagP2PExchangeNewPeerRate,
+		&FlagP2PACLEnabled,
+		&FlagP2PACLMonitoringIdentities,
+		&FlagP2PACLProviderIdentities,
 		&FlagConsumer,
 		&FlagDefaultCurrency,
 		&FlagDocsURL,
@@ -353,18 +533,39 @@ func ParseFlagsNode(ctx *cli.Context) {
 
 	ParseFlagsLocation(ctx)
 	ParseFlagsNetwork(ctx)
+	ParseFlagsBroker(ctx)
 	ParseFlagsTransactor(ctx)
 	ParseFlagsAffiliator(ctx)
 	ParseFlagsPayments(ctx)
+	ParseFlagsPricing(ctx)
+	ParseFlagsEarnings(ctx)
 	ParseFlagsPolicy(ctx)
+	ParseFlagsAbuse(ctx)
+	ParseFlagsHooks(ctx)
+	ParseFlagsWarmup(ctx)
+	ParseFlagsAutoConnect(ctx)
+	ParseFlagsPreflight(ctx)
+	ParseFlagsClock(ctx)
+	ParseFlagsPrivacy(ctx)
+	ParseFlagsDDNS(ctx)
+	ParseFlagsCamouflage(ctx)
+	ParseFlagsUpdater(ctx)
+	ParseFlagsServiceHealth(ctx)
+	ParseFlagsServiceRestore(ctx)
+	ParseFlagsServiceSchedule(ctx)
+	ParseFlagsFeatures(ctx)
+	ParseFlagsStorage(ctx)
 	ParseFlagsMMN(ctx)
 	ParseFlagPilvytis(ctx)
 	ParseFlagsChains(ctx)
 	ParseFlagsUI(ctx)
+	Current.ParseStringFlag(ctx, FlagQualityAddress)
+	Current.ParseStringFlag(ctx, FlagQualityType)
 	//it is important to have this one at the end so it overwrites defaults correctly
 	ParseFlagsBlockchainNetwork(ctx)
 
 	Current.ParseStringFlag(ctx, FlagBindAddress)
+	Current.ParseStringFlag(ctx, FlagBindServices)
 	Current.ParseStringSliceFlag(ctx, FlagDiscoveryType)
 	Current.ParseDurationFlag(ctx, FlagDiscoveryPingInterval)
 	Current.ParseDurationFlag(ctx, FlagDiscoveryFetchInterval)
@@ -374,28 +575,49 @@ func ParseFlagsNode(ctx *cli.Context) {
 	Current.ParseStringSliceFlag(ctx, FlagDHTBootstrapPeers)
 	Current.ParseStringFlag(ctx, FlagFeedbackURL)
 	Current.ParseBoolFlag(ctx, FlagFirewallKillSwitch)
+	Current.ParseStringSliceFlag(ctx, FlagFirewallAllowedApps)
+	Current.ParseStringFlag(ctx, FlagFirewallDNSLeakProtection)
 	Current.ParseStringFlag(ctx, FlagFirewallProtectedNetworks)
 	Current.ParseBoolFlag(ctx, FlagShaperEnabled)
 	Current.ParseUInt64Flag(ctx, FlagShaperBandwidth)
+	Current.ParseDurationFlag(ctx, FlagStatsResolution)
+	Current.ParseIntFlag(ctx, FlagServiceMaxSessions)
+	Current.ParseUInt64Flag(ctx, FlagServiceBandwidthCapacity)
+	Current.ParseBoolFlag(ctx, FlagNoopEnabled)
 	Current.ParseBoolFlag(ctx, FlagKeystoreLightweight)
 	Current.ParseBoolFlag(ctx, FlagLogHTTP)
 	Current.ParseBoolFlag(ctx, FlagVerbose)
 	Current.ParseStringFlag(ctx, FlagLogLevel)
+	Current.ParseDurationFlag(ctx, FlagLogDedupInterval)
+	Current.ParseStringFlag(ctx, FlagLogDedupLevel)
 	Current.ParseStringFlag(ctx, FlagOpenvpnBinary)
-	Current.ParseStringFlag(ctx, FlagQualityAddress)
-	Current.ParseStringFlag(ctx, FlagQualityType)
 	Current.ParseStringFlag(ctx, FlagTequilapiAddress)
 	Current.ParseStringFlag(ctx, FlagTequilapiAllowedHostnames)
 	Current.ParseIntFlag(ctx, FlagTequilapiPort)
 	Current.ParseStringFlag(ctx, FlagTequilapiUsername)
 	Current.ParseStringFlag(ctx, FlagTequilapiPassword)
+	Current.ParseBoolFlag(ctx, FlagTequilapiTLS)
+	Current.ParseStringFlag(ctx, FlagTequilapiTLSDomain)
+	Current.ParseStringFlag(ctx, FlagTequilapiTLSACMEHTTPAddress)
+	Current.ParseStringFlag(ctx, FlagTequilapiTLSACMEEmail)
+	Current.ParseStringFlag(ctx, FlagTequilapiSocket)
+	Current.ParseStringFlag(ctx, FlagTequilapiSocketMode)
+	Current.ParseBoolFlag(ctx, FlagTequilapiSocketOnly)
 	Current.ParseBoolFlag(ctx, FlagPProfEnable)
+	Current.ParseStringFlag(ctx, FlagPProfToken)
 	Current.ParseBoolFlag(ctx, FlagUserMode)
 	Current.ParseBoolFlag(ctx, FlagProxyMode)
 	Current.ParseBoolFlag(ctx, FlagUserspace)
 	Current.ParseStringFlag(ctx, FlagVendorID)
 	Current.ParseStringFlag(ctx, FlagLauncherVersion)
 	Current.ParseStringFlag(ctx, FlagP2PListenPorts)
+	Current.ParseFloat64Flag(ctx, FlagP2PExchangeRate)
+	Current.ParseFloat64Flag(ctx, FlagP2PExchangePeerRate)
+	Current.ParseBoolFlag(ctx, FlagP2PExchangeRequireRegistered)
+	Current.ParseFloat64Flag(ctx, FlagP2PExchangeNewPeerRate)
+	Current.ParseBoolFlag(ctx, FlagP2PACLEnabled)
+	Current.ParseStringSliceFlag(ctx, FlagP2PACLMonitoringIdentities)
+	Current.ParseStringSliceFlag(ctx, FlagP2PACLProviderIdentities)
 	Current.ParseBoolFlag(ctx, FlagConsumer)
 	Current.ParseStringFlag(ctx, FlagDefaultCurrency)
 	Current.ParseStringFlag(ctx, FlagDocsURL)
@@ -111,6 +111,30 @@ var (
 		Usage:  "The duration we'll wait before giving up on transactors registration status",
 		Hidden: true,
 	}
+	// FlagPaymentsGasPriceStrategy gas price strategy consulted before settlement and withdrawal transactions.
+	FlagPaymentsGasPriceStrategy = cli.StringFlag{
+		Name:  "payments.gas-price.strategy",
+		Usage: "Gas price strategy used to quote transaction costs: fast, standard or economy",
+		Value: "standard",
+	}
+	// FlagPaymentsGasPriceMax gas price cap in gwei.
+	FlagPaymentsGasPriceMax = cli.Float64Flag{
+		Name:  "payments.gas-price.max",
+		Usage: "Settlements and withdrawals are postponed while the gas price of the selected strategy is above this cap in gwei. No cap if 0",
+		Value: 0,
+	}
+	// FlagPaymentsGasPriceOracleURL gas station compatible oracle address.
+	FlagPaymentsGasPriceOracleURL = cli.StringFlag{
+		Name:  "payments.gas-price.oracle-url",
+		Usage: "Gas station compatible gas price oracle URL, {chain_id} is replaced with the chain ID. Node estimation is used if empty or unavailable",
+		Value: "",
+	}
+	// FlagPaymentsRegistryStatusCacheTTL how long an identity registration status observed on chain is trusted without rechecking.
+	FlagPaymentsRegistryStatusCacheTTL = cli.DurationFlag{
+		Name:  "payments.registry-status-cache-ttl",
+		Value: time.Minute * 10,
+		Usage: "How long an identity registration status observed on chain is used without rechecking it. The last stored status is used while the chain is unreachable",
+	}
 	// FlagPaymentsConsumerDataLeewayMegabytes sets the data amount the consumer agrees to pay before establishing a session
 	FlagPaymentsConsumerDataLeewayMegabytes = cli.Uint64Flag{
 		Name:  metadata.FlagNames.PaymentsDataLeewayMegabytes,
@@ -145,6 +169,42 @@ var (
 		Value:  5000000000000000000,
 		Hidden: true,
 	}
+	// FlagPaymentsDirectTopup enables top-ups by transferring MYST directly to the identity channel.
+	FlagPaymentsDirectTopup = cli.BoolFlag{
+		Name:  "payments.direct-topup",
+		Usage: "Offer top-ups by direct MYST transfer to the identity channel next to pilvytis payment gateways",
+		Value: false,
+	}
+	// FlagPaymentsAutoTopupThreshold sets the consumer balance below which a top-up order is created automatically.
+	FlagPaymentsAutoTopupThreshold = cli.Float64Flag{
+		Name:  "payments.auto-topup.threshold",
+		Usage: "Consumer balance in MYST below which a top-up order is created automatically, 0 disables auto top-up",
+		Value: 0,
+	}
+	// FlagPaymentsAutoTopupAmount sets the MYST amount of automatic top-up orders.
+	FlagPaymentsAutoTopupAmount = cli.Float64Flag{
+		Name:  "payments.auto-topup.amount",
+		Usage: "Amount of MYST to top up automatically",
+		Value: 0,
+	}
+	// FlagPaymentsAutoTopupGateway sets the payment gateway of automatic top-up orders.
+	FlagPaymentsAutoTopupGateway = cli.StringFlag{
+		Name:  "payments.auto-topup.gateway",
+		Usage: "Payment gateway used for automatic top-ups",
+		Value: "",
+	}
+	// FlagPaymentsAutoTopupCurrency sets the currency automatic top-up orders are paid in.
+	FlagPaymentsAutoTopupCurrency = cli.StringFlag{
+		Name:  "payments.auto-topup.currency",
+		Usage: "Currency automatic top-ups are paid in",
+		Value: "",
+	}
+	// FlagPaymentsAutoTopupInterval sets the minimum time between automatic top-up orders.
+	FlagPaymentsAutoTopupInterval = cli.DurationFlag{
+		Name:  "payments.auto-topup.interval",
+		Usage: "Minimum time between automatic top-up orders of the same identity",
+		Value: time.Hour,
+	}
 
 	// FlagObserverAddress address of Observer service.
 	FlagObserverAddress = cli.StringFlag{
@@ -182,6 +242,34 @@ var (
 		Value: time.Minute * 5,
 		Usage: "Determines how often the provider sends invoices.",
 	}
+
+	// FlagPaymentsProviderFreeTierMegabytes sets the daily traffic each consumer can use free of charge.
+	FlagPaymentsProviderFreeTierMegabytes = cli.Uint64Flag{
+		Name:  "payments.provider.free-tier-megabytes",
+		Value: 0,
+		Usage: "Traffic in megabytes each consumer can use free of charge per day before invoicing starts, 0 to not limit free traffic when free minutes are set",
+	}
+
+	// FlagPaymentsProviderFreeTierMinutes sets the daily session time each consumer can use free of charge.
+	FlagPaymentsProviderFreeTierMinutes = cli.Uint64Flag{
+		Name:  "payments.provider.free-tier-minutes",
+		Value: 0,
+		Usage: "Session minutes each consumer can use free of charge per day before invoicing starts, 0 to not limit free time when free megabytes are set",
+	}
+
+	// FlagPaymentsConsumerInvoicePeriod sets the invoice period the consumer asks the provider for.
+	FlagPaymentsConsumerInvoicePeriod = cli.DurationFlag{
+		Name:  "payments.consumer.invoice-period",
+		Value: 0,
+		Usage: "Invoice period to request from the provider when creating a session. The provider fits it into its own bounds, 0 leaves the choice to the provider",
+	}
+
+	// FlagPaymentsConsumerInvoiceDataMegabytes sets the traffic between invoices the consumer asks the provider for.
+	FlagPaymentsConsumerInvoiceDataMegabytes = cli.Uint64Flag{
+		Name:  "payments.consumer.invoice-data-megabytes",
+		Value: 0,
+		Usage: "Traffic in megabytes to use between invoices, requested from the provider when creating a session. Higher values reduce payment messages on high throughput sessions, 0 leaves the choice to the provider",
+	}
 )
 
 // RegisterFlagsPayments function register payments flags to flag list.
@@ -200,16 +288,30 @@ func RegisterFlagsPayments(flags *[]cli.Flag) {
 		&FlagPaymentsFastBalancePollTimeout,
 		&FlagPaymentsRegistryTransactorPollTimeout,
 		&FlagPaymentsRegistryTransactorPollInterval,
+		&FlagPaymentsRegistryStatusCacheTTL,
+		&FlagPaymentsGasPriceStrategy,
+		&FlagPaymentsGasPriceMax,
+		&FlagPaymentsGasPriceOracleURL,
 		&FlagPaymentsConsumerDataLeewayMegabytes,
 		&FlagPaymentsHermesStatusRecheckInterval,
 		&FlagOffchainBalanceExpiration,
 		&FlagPaymentsZeroStakeUnsettledAmount,
 		&FlagPaymentsDuringSessionDebug,
 		&FlagPaymentsAmountDuringSessionDebug,
+		&FlagPaymentsDirectTopup,
+		&FlagPaymentsAutoTopupThreshold,
+		&FlagPaymentsAutoTopupAmount,
+		&FlagPaymentsAutoTopupGateway,
+		&FlagPaymentsAutoTopupCurrency,
+		&FlagPaymentsAutoTopupInterval,
 		&FlagObserverAddress,
 
 		&FlagPaymentsProviderInvoiceFrequency,
 		&FlagPaymentsLimitProviderInvoiceFrequency,
+		&FlagPaymentsProviderFreeTierMegabytes,
+		&FlagPaymentsProviderFreeTierMinutes,
+		&FlagPaymentsConsumerInvoicePeriod,
+		&FlagPaymentsConsumerInvoiceDataMegabytes,
 
 		&FlagPaymentsUnpaidInvoiceValue,
 		&FlagPaymentsLimitUnpaidInvoiceValue,
@@ -231,16 +333,30 @@ func ParseFlagsPayments(ctx *cli.Context) {
 	Current.ParseDurationFlag(ctx, FlagPaymentsLongBalancePollInterval)
 	Current.ParseDurationFlag(ctx, FlagPaymentsRegistryTransactorPollInterval)
 	Current.ParseDurationFlag(ctx, FlagPaymentsRegistryTransactorPollTimeout)
+	Current.ParseDurationFlag(ctx, FlagPaymentsRegistryStatusCacheTTL)
+	Current.ParseStringFlag(ctx, FlagPaymentsGasPriceStrategy)
+	Current.ParseFloat64Flag(ctx, FlagPaymentsGasPriceMax)
+	Current.ParseStringFlag(ctx, FlagPaymentsGasPriceOracleURL)
 	Current.ParseUInt64Flag(ctx, FlagPaymentsConsumerDataLeewayMegabytes)
 	Current.ParseDurationFlag(ctx, FlagPaymentsHermesStatusRecheckInterval)
 	Current.ParseDurationFlag(ctx, FlagOffchainBalanceExpiration)
 	Current.ParseFloat64Flag(ctx, FlagPaymentsZeroStakeUnsettledAmount)
 	Current.ParseBoolFlag(ctx, FlagPaymentsDuringSessionDebug)
 	Current.ParseUInt64Flag(ctx, FlagPaymentsAmountDuringSessionDebug)
+	Current.ParseBoolFlag(ctx, FlagPaymentsDirectTopup)
+	Current.ParseFloat64Flag(ctx, FlagPaymentsAutoTopupThreshold)
+	Current.ParseFloat64Flag(ctx, FlagPaymentsAutoTopupAmount)
+	Current.ParseStringFlag(ctx, FlagPaymentsAutoTopupGateway)
+	Current.ParseStringFlag(ctx, FlagPaymentsAutoTopupCurrency)
+	Current.ParseDurationFlag(ctx, FlagPaymentsAutoTopupInterval)
 	Current.ParseStringFlag(ctx, FlagObserverAddress)
 
 	Current.ParseDurationFlag(ctx, FlagPaymentsProviderInvoiceFrequency)
 	Current.ParseDurationFlag(ctx, FlagPaymentsLimitProviderInvoiceFrequency)
+	Current.ParseUInt64Flag(ctx, FlagPaymentsProviderFreeTierMegabytes)
+	Current.ParseUInt64Flag(ctx, FlagPaymentsProviderFreeTierMinutes)
+	Current.ParseDurationFlag(ctx, FlagPaymentsConsumerInvoicePeriod)
+	Current.ParseUInt64Flag(ctx, FlagPaymentsConsumerInvoiceDataMegabytes)
 
 	Current.ParseStringFlag(ctx, FlagPaymentsLimitUnpaidInvoiceValue)
 	Current.ParseStringFlag(ctx, FlagPaymentsUnpaidInvoiceValue)
@@ -37,6 +37,36 @@ var (
 		Usage: `Proposal fetch interval { "30s", "3m", "1h20m30s" }`,
 		Value: 10 * time.Minute,
 	}
+	// FlagAccessPolicyBlocklistURL signed abuse blocklist feed URL.
+	FlagAccessPolicyBlocklistURL = cli.StringFlag{
+		Name:  "access-policy.blocklist-url",
+		Usage: "URL of signed abuse feed listing consumer identities and IP ranges to block, empty disables it",
+		Value: "",
+	}
+	// FlagAccessPolicyBlocklistSigner identity which signs the blocklist feed.
+	FlagAccessPolicyBlocklistSigner = cli.StringFlag{
+		Name:  "access-policy.blocklist-signer",
+		Usage: "Identity address expected to sign the abuse blocklist feed",
+		Value: "",
+	}
+	// FlagAccessPolicyBlocklistFetchInterval blocklist feed fetch interval.
+	FlagAccessPolicyBlocklistFetchInterval = cli.DurationFlag{
+		Name:  "access-policy.blocklist-fetch",
+		Usage: `Abuse blocklist feed fetch interval { "30s", "3m", "1h20m30s" }`,
+		Value: 10 * time.Minute,
+	}
+	// FlagAccessPolicyBlock locally blocked consumer identities, IP ranges, AS numbers and countries.
+	FlagAccessPolicyBlock = cli.StringSliceFlag{
+		Name:  "access-policy.block",
+		Usage: "Consumer identities, IPs, CIDR ranges, AS numbers or country codes always blocked, e.g. 0xd1faed693fec75389c3d1e59b863e4835ac6f5d1,10.0.0.0/8,AS64500,RU",
+		Value: cli.NewStringSlice(),
+	}
+	// FlagAccessPolicyBlockExempt consumer identities, IP ranges, AS numbers and countries never blocked by the feed.
+	FlagAccessPolicyBlockExempt = cli.StringSliceFlag{
+		Name:  "access-policy.block-exempt",
+		Usage: "Consumer identities, IPs, CIDR ranges, AS numbers or country codes never blocked, overriding the abuse blocklist feed",
+		Value: cli.NewStringSlice(),
+	}
 )
 
 // RegisterFlagsPolicy function registers Policy Oracle flags to flag list.
@@ -44,6 +74,11 @@ func RegisterFlagsPolicy(flags *[]cli.Flag) {
 	*flags = append(*flags,
 		&FlagAccessPolicyAddress,
 		&FlagAccessPolicyFetchInterval,
+		&FlagAccessPolicyBlocklistURL,
+		&FlagAccessPolicyBlocklistSigner,
+		&FlagAccessPolicyBlocklistFetchInterval,
+		&FlagAccessPolicyBlock,
+		&FlagAccessPolicyBlockExempt,
 	)
 }
 
@@ -51,4 +86,9 @@ func RegisterFlagsPolicy(flags *[]cli.Flag) {
 func ParseFlagsPolicy(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagAccessPolicyAddress)
 	Current.ParseDurationFlag(ctx, FlagAccessPolicyFetchInterval)
+	Current.ParseStringFlag(ctx, FlagAccessPolicyBlocklistURL)
+	Current.ParseStringFlag(ctx, FlagAccessPolicyBlocklistSigner)
+	Current.ParseDurationFlag(ctx, FlagAccessPolicyBlocklistFetchInterval)
+	Current.ParseStringSliceFlag(ctx, FlagAccessPolicyBlock)
+	Current.ParseStringSliceFlag(ctx, FlagAccessPolicyBlockExempt)
 }
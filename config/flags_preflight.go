@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagPreflightMode defines how provider startup reacts to failed dependency checks.
+	FlagPreflightMode = cli.StringFlag{
+		Name:  "preflight.mode",
+		Usage: "Provider startup dependency checks mode: 'enforce' waits for the checks and refuses to start when critical ones fail, 'warn' runs them in the background and reports failures, 'off' skips checks",
+		Value: "warn",
+	}
+	// FlagPreflightMinDiskSpace minimum free disk space required for the data directory.
+	FlagPreflightMinDiskSpace = cli.Uint64Flag{
+		Name:  "preflight.min-disk-space",
+		Usage: "Minimum free disk space of the data directory in MiB",
+		Value: 100,
+	}
+	// FlagPreflightNTPServer NTP server used to check system clock.
+	FlagPreflightNTPServer = cli.StringFlag{
+		Name:  "preflight.ntp-server",
+		Usage: "NTP server (host:port) used to check system clock",
+		Value: "pool.ntp.org:123",
+	}
+	// FlagPreflightMaxClockSkew maximum allowed system clock difference from NTP time.
+	FlagPreflightMaxClockSkew = cli.DurationFlag{
+		Name:  "preflight.max-clock-skew",
+		Usage: "Maximum allowed difference of system clock from NTP time",
+		Value: time.Minute,
+	}
+)
+
+// RegisterFlagsPreflight function registers startup dependency check flags to flag list.
+func RegisterFlagsPreflight(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagPreflightMode,
+		&FlagPreflightMinDiskSpace,
+		&FlagPreflightNTPServer,
+		&FlagPreflightMaxClockSkew,
+	)
+}
+
+// ParseFlagsPreflight function fills in startup dependency check options from CLI context.
+func ParseFlagsPreflight(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagPreflightMode)
+	Current.ParseUInt64Flag(ctx, FlagPreflightMinDiskSpace)
+	Current.ParseStringFlag(ctx, FlagPreflightNTPServer)
+	Current.ParseDurationFlag(ctx, FlagPreflightMaxClockSkew)
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagPricingAuto enables automatic pricing of provided services.
+	FlagPricingAuto = cli.BoolFlag{
+		Name:  "pricing.auto",
+		Usage: "Advertise provider prices moved toward market prices of comparable proposals from discovery",
+		Value: false,
+	}
+	// FlagPricingAutoServices sets services priced automatically.
+	FlagPricingAutoServices = cli.StringSliceFlag{
+		Name:  "pricing.auto-services",
+		Usage: "Service types priced automatically",
+		Value: cli.NewStringSlice("wireguard"),
+	}
+	// FlagPricingAutoPercentile sets the market price percentile to move toward.
+	FlagPricingAutoPercentile = cli.Float64Flag{
+		Name:  "pricing.auto-percentile",
+		Usage: "Percentile of comparable proposal prices (same country, service and IP type) to move toward, 0-100",
+		Value: 50,
+	}
+	// FlagPricingAutoInterval sets how often prices are updated.
+	FlagPricingAutoInterval = cli.DurationFlag{
+		Name:  "pricing.auto-interval",
+		Usage: "Least time between automatic price updates",
+		Value: 6 * time.Hour,
+	}
+	// FlagPricingAutoMaxChange limits the price change of a single update.
+	FlagPricingAutoMaxChange = cli.Float64Flag{
+		Name:  "pricing.auto-max-change",
+		Usage: "Largest relative price change of a single update, e.g. 0.1 for 10%, 0 for unlimited",
+		Value: 0.1,
+	}
+	// FlagPricingAutoMinPerHour sets the lowest automatic price per hour.
+	FlagPricingAutoMinPerHour = cli.Float64Flag{
+		Name:  "pricing.auto-min-per-hour",
+		Usage: "Lowest automatic price per hour in MYST",
+		Value: 0,
+	}
+	// FlagPricingAutoMaxPerHour sets the highest automatic price per hour.
+	FlagPricingAutoMaxPerHour = cli.Float64Flag{
+		Name:  "pricing.auto-max-per-hour",
+		Usage: "Highest automatic price per hour in MYST, 0 for unbounded",
+		Value: 0,
+	}
+	// FlagPricingAutoMinPerGiB sets the lowest automatic price per GiB.
+	FlagPricingAutoMinPerGiB = cli.Float64Flag{
+		Name:  "pricing.auto-min-per-gib",
+		Usage: "Lowest automatic price per GiB in MYST",
+		Value: 0,
+	}
+	// FlagPricingAutoMaxPerGiB sets the highest automatic price per GiB.
+	FlagPricingAutoMaxPerGiB = cli.Float64Flag{
+		Name:  "pricing.auto-max-per-gib",
+		Usage: "Highest automatic price per GiB in MYST, 0 for unbounded",
+		Value: 0,
+	}
+)
+
+// RegisterFlagsPricing function registers pricing flags to flag list.
+func RegisterFlagsPricing(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagPricingAuto,
+		&FlagPricingAutoServices,
+		&FlagPricingAutoPercentile,
+		&FlagPricingAutoInterval,
+		&FlagPricingAutoMaxChange,
+		&FlagPricingAutoMinPerHour,
+		&FlagPricingAutoMaxPerHour,
+		&FlagPricingAutoMinPerGiB,
+		&FlagPricingAutoMaxPerGiB,
+	)
+}
+
+// ParseFlagsPricing function fills in pricing options from CLI context.
+func ParseFlagsPricing(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagPricingAuto)
+	Current.ParseStringSliceFlag(ctx, FlagPricingAutoServices)
+	Current.ParseFloat64Flag(ctx, FlagPricingAutoPercentile)
+	Current.ParseDurationFlag(ctx, FlagPricingAutoInterval)
+	Current.ParseFloat64Flag(ctx, FlagPricingAutoMaxChange)
+	Current.ParseFloat64Flag(ctx, FlagPricingAutoMinPerHour)
+	Current.ParseFloat64Flag(ctx, FlagPricingAutoMaxPerHour)
+	Current.ParseFloat64Flag(ctx, FlagPricingAutoMinPerGiB)
+	Current.ParseFloat64Flag(ctx, FlagPricingAutoMaxPerGiB)
+}
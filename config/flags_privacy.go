@@ -0,0 +1,43 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagPrivacyConsumerIP defines how provider records consumer IP addresses.
+	FlagPrivacyConsumerIP = cli.StringFlag{
+		Name:  "privacy.consumer-ip",
+		Usage: "How consumer IP addresses are recorded in the blocklist audit and logs. Options: { full, truncated, hashed, none }",
+		Value: "truncated",
+	}
+)
+
+// RegisterFlagsPrivacy function registers privacy flags to flag list.
+func RegisterFlagsPrivacy(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagPrivacyConsumerIP,
+	)
+}
+
+// ParseFlagsPrivacy function fills in privacy options from CLI context.
+func ParseFlagsPrivacy(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagPrivacyConsumerIP)
+}
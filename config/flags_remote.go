@@ -0,0 +1,41 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import "github.com/urfave/cli/v2"
+
+var (
+	// FlagRemoteHost UI address of a remote node managed by CLI commands.
+	FlagRemoteHost = cli.StringFlag{
+		Name:  "remote-host",
+		Usage: "Manage a node on another host via API proxy of its UI, e.g. 10.0.0.5 or https://node.example.com:4449",
+		Value: "",
+	}
+	// FlagRemoteToken API auth token of the remote node.
+	FlagRemoteToken = cli.StringFlag{
+		Name:  "remote-token",
+		Usage: "API auth token of the remote node, issued by its /tequilapi/auth/authenticate endpoint",
+		Value: "",
+	}
+	// FlagRemoteCACert certificate trusted for the remote node.
+	FlagRemoteCACert = cli.StringFlag{
+		Name:  "remote-ca-cert",
+		Usage: "PEM certificate to trust for the remote node, e.g. its self-signed tequilapi certificate. System roots are used if empty",
+		Value: "",
+	}
+)
@@ -0,0 +1,60 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagServiceHealthInterval how often running services are probed.
+	FlagServiceHealthInterval = cli.DurationFlag{
+		Name:  "service.health.interval",
+		Usage: "How often running services are probed for health, probes are disabled if 0",
+		Value: time.Minute,
+	}
+	// FlagServiceHealthFailures consecutive failed probes after which service is restarted.
+	FlagServiceHealthFailures = cli.IntFlag{
+		Name:  "service.health.failures",
+		Usage: "Number of consecutive failed health probes after which service is restarted, restarts are disabled if 0",
+		Value: 3,
+	}
+	// FlagServiceHealthMaxRestarts limits restarts of an unhealthy service.
+	FlagServiceHealthMaxRestarts = cli.IntFlag{
+		Name:  "service.health.max-restarts",
+		Usage: "Maximum number of times an unhealthy service is restarted, unlimited if 0",
+		Value: 5,
+	}
+)
+
+// RegisterFlagsServiceHealth function registers service health flags to flag list.
+func RegisterFlagsServiceHealth(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagServiceHealthInterval,
+		&FlagServiceHealthFailures,
+		&FlagServiceHealthMaxRestarts,
+	)
+}
+
+// ParseFlagsServiceHealth function fills in service health options from CLI context.
+func ParseFlagsServiceHealth(ctx *cli.Context) {
+	Current.ParseDurationFlag(ctx, FlagServiceHealthInterval)
+	Current.ParseIntFlag(ctx, FlagServiceHealthFailures)
+	Current.ParseIntFlag(ctx, FlagServiceHealthMaxRestarts)
+}
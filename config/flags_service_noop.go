@@ -22,6 +22,12 @@ import (
 )
 
 var (
+	// FlagNoopEnabled enables noop service and connection.
+	FlagNoopEnabled = cli.BoolFlag{
+		Name:   "noop.enabled",
+		Usage:  "Enable noop service and connection, which go through session, payment and p2p flows without creating tunnels. Meant for testing",
+		Hidden: true,
+	}
 	// FlagNoopAccessPolicies a comma-separated list of access policies that determines allowed identities to use the service.
 	FlagNoopAccessPolicies = cli.StringFlag{
 		Name:   "noop.access-policies",
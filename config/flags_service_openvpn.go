@@ -46,6 +46,36 @@ var (
 		Usage: "OpenVPN subnet netmask",
 		Value: "255.255.255.0",
 	}
+	// FlagOpenvpnDataCiphers data channel ciphers offered to clients.
+	FlagOpenvpnDataCiphers = cli.StringFlag{
+		Name:  "openvpn.data-ciphers",
+		Usage: "Colon separated list of OpenVPN data channel ciphers in order of preference. Options: { AES-256-GCM, AES-128-GCM, CHACHA20-POLY1305 }",
+		Value: "AES-256-GCM",
+	}
+	// FlagOpenvpnTLSCiphers control channel TLS cipher suites.
+	FlagOpenvpnTLSCiphers = cli.StringFlag{
+		Name:  "openvpn.tls-ciphers",
+		Usage: "Colon separated list of OpenVPN control channel TLS cipher suites",
+		Value: "TLS-ECDHE-ECDSA-WITH-AES-256-GCM-SHA384",
+	}
+	// FlagOpenvpnTLSVersionMin minimal TLS version of the control channel.
+	FlagOpenvpnTLSVersionMin = cli.StringFlag{
+		Name:  "openvpn.tls-version-min",
+		Usage: "Minimal TLS version of OpenVPN control channel. Options: { 1.2, 1.3 }",
+		Value: "1.2",
+	}
+	// FlagOpenvpnDuplicateCN allows clients to connect with the same certificate common name.
+	FlagOpenvpnDuplicateCN = cli.BoolFlag{
+		Name:  "openvpn.duplicate-cn",
+		Usage: "Allow multiple OpenVPN clients to connect with the same certificate common name",
+		Value: false,
+	}
+	// FlagOpenvpnNoCompression enforces compression to be off.
+	FlagOpenvpnNoCompression = cli.BoolFlag{
+		Name:  "openvpn.no-compression",
+		Usage: "Refuse OpenVPN clients pushing compression, requires OpenVPN 2.5 or newer",
+		Value: false,
+	}
 	// FlagOpenVPNAccessPolicies a comma-separated list of access policies that determines allowed identities to use the service.
 	FlagOpenVPNAccessPolicies = cli.StringFlag{
 		Name:  "openvpn.access-policies",
@@ -61,6 +91,11 @@ func RegisterFlagsServiceOpenvpn(flags *[]cli.Flag) {
 		&FlagOpenvpnSubnet,
 		&FlagOpenvpnNetmask,
 		&FlagOpenVPNAccessPolicies,
+		&FlagOpenvpnDataCiphers,
+		&FlagOpenvpnTLSCiphers,
+		&FlagOpenvpnTLSVersionMin,
+		&FlagOpenvpnDuplicateCN,
+		&FlagOpenvpnNoCompression,
 	)
 }
 
@@ -71,4 +106,9 @@ func ParseFlagsServiceOpenvpn(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagOpenvpnSubnet)
 	Current.ParseStringFlag(ctx, FlagOpenvpnNetmask)
 	Current.ParseStringFlag(ctx, FlagOpenVPNAccessPolicies)
+	Current.ParseStringFlag(ctx, FlagOpenvpnDataCiphers)
+	Current.ParseStringFlag(ctx, FlagOpenvpnTLSCiphers)
+	Current.ParseStringFlag(ctx, FlagOpenvpnTLSVersionMin)
+	Current.ParseBoolFlag(ctx, FlagOpenvpnDuplicateCN)
+	Current.ParseBoolFlag(ctx, FlagOpenvpnNoCompression)
 }
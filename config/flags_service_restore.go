@@ -0,0 +1,53 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagServiceRestoreSessions keeps tunnels of provider sessions running across node restarts.
+	FlagServiceRestoreSessions = cli.BoolFlag{
+		Name:  "service.restore-sessions",
+		Usage: "Leave tunnels of active sessions running on shutdown and restore them once the node starts again (kernel WireGuard only)",
+		Value: false,
+	}
+	// FlagServiceRestoreGrace limits how long restored session waits for its consumer to reconnect.
+	FlagServiceRestoreGrace = cli.DurationFlag{
+		Name:  "service.restore-grace",
+		Usage: "How long restored session tunnel is kept waiting for its consumer to reconnect",
+		Value: 2 * time.Minute,
+	}
+)
+
+// RegisterFlagsServiceRestore function registers session restore flags to flag list.
+func RegisterFlagsServiceRestore(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagServiceRestoreSessions,
+		&FlagServiceRestoreGrace,
+	)
+}
+
+// ParseFlagsServiceRestore function fills in session restore options from CLI context.
+func ParseFlagsServiceRestore(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagServiceRestoreSessions)
+	Current.ParseDurationFlag(ctx, FlagServiceRestoreGrace)
+}
@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagServiceSchedule weekly access windows of provider services.
+	FlagServiceSchedule = cli.StringSliceFlag{
+		Name: "service.schedule",
+		Usage: "Weekly access window during which service is shared, in the <service type>=[<days>/]<HH:MM>-<HH:MM> format " +
+			"(e.g. wireguard=mon-fri/00:00-07:00). Services with windows are drained at the window end and started again once it opens",
+		Value: cli.NewStringSlice(),
+	}
+)
+
+// RegisterFlagsServiceSchedule function registers service schedule flags to flag list.
+func RegisterFlagsServiceSchedule(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagServiceSchedule,
+	)
+}
+
+// ParseFlagsServiceSchedule function fills in service schedule options from CLI context.
+func ParseFlagsServiceSchedule(ctx *cli.Context) {
+	Current.ParseStringSliceFlag(ctx, FlagServiceSchedule)
+}
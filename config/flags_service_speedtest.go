@@ -0,0 +1,60 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagSpeedtestAccessPolicies a comma-separated list of access policies that determines allowed identities to use the service.
+	FlagSpeedtestAccessPolicies = cli.StringFlag{
+		Name:  "speedtest.access-policies",
+		Usage: "Comma separated list that determines the access policies of the speedtest service.",
+	}
+	// FlagSpeedtestDuration duration of each speedtest measurement direction.
+	FlagSpeedtestDuration = cli.DurationFlag{
+		Name:  "speedtest.duration",
+		Usage: "Duration of download and upload measurements of the speedtest service",
+		Value: 5 * time.Second,
+	}
+	// FlagSpeedtestMaxRate limits speedtest measurement traffic.
+	FlagSpeedtestMaxRate = cli.Uint64Flag{
+		Name:  "speedtest.max-rate",
+		Usage: "Speedtest measurement traffic limit in Mbit/s, 0 means unlimited",
+		Value: 0,
+	}
+)
+
+// RegisterFlagsServiceSpeedtest function register speedtest flags to flag list
+func RegisterFlagsServiceSpeedtest(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagSpeedtestAccessPolicies,
+		&FlagSpeedtestDuration,
+		&FlagSpeedtestMaxRate,
+	)
+}
+
+// ParseFlagsServiceSpeedtest parses CLI flags and registers value to configuration
+func ParseFlagsServiceSpeedtest(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagSpeedtestAccessPolicies)
+	Current.ParseDurationFlag(ctx, FlagSpeedtestDuration)
+	Current.ParseUInt64Flag(ctx, FlagSpeedtestMaxRate)
+}
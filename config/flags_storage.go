@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagStorageEncryption source of the local storage encryption key.
+	FlagStorageEncryption = cli.StringFlag{
+		Name:  "storage.encryption",
+		Usage: "Encrypt local database and configuration secrets with a key derived from the identity passphrase passed via CLI flag (passphrase) or kept in the OS keyring (keyring). Disabled if empty",
+		Value: "",
+	}
+	// FlagStorageRetentionSessions retention period of session history.
+	FlagStorageRetentionSessions = cli.DurationFlag{
+		Name:  "storage.retention.sessions",
+		Usage: "Remove session history older than this. Kept forever if 0",
+		Value: 180 * 24 * time.Hour,
+	}
+	// FlagStorageRetentionPayments retention period of session receipts and settlement history.
+	FlagStorageRetentionPayments = cli.DurationFlag{
+		Name:  "storage.retention.payments",
+		Usage: "Remove session receipts and settlement history older than this. Kept forever if 0",
+		Value: 365 * 24 * time.Hour,
+	}
+	// FlagStorageRetentionEvents retention period of recorded events.
+	FlagStorageRetentionEvents = cli.DurationFlag{
+		Name:  "storage.retention.events",
+		Usage: "Remove recorded events, e.g. blocked access attempts, older than this. Kept forever if 0",
+		Value: 30 * 24 * time.Hour,
+	}
+	// FlagStoragePruneInterval interval of storage maintenance.
+	FlagStoragePruneInterval = cli.DurationFlag{
+		Name:  "storage.prune-interval",
+		Usage: "How often to remove records past their retention and compact the database. Disabled if 0",
+		Value: 24 * time.Hour,
+	}
+)
+
+// RegisterFlagsStorage function registers local storage flags to flag list.
+func RegisterFlagsStorage(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagStorageEncryption,
+		&FlagStorageRetentionSessions,
+		&FlagStorageRetentionPayments,
+		&FlagStorageRetentionEvents,
+		&FlagStoragePruneInterval,
+	)
+}
+
+// ParseFlagsStorage function fills in local storage options from CLI context.
+func ParseFlagsStorage(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagStorageEncryption)
+	Current.ParseDurationFlag(ctx, FlagStorageRetentionSessions)
+	Current.ParseDurationFlag(ctx, FlagStorageRetentionPayments)
+	Current.ParseDurationFlag(ctx, FlagStorageRetentionEvents)
+	Current.ParseDurationFlag(ctx, FlagStoragePruneInterval)
+}
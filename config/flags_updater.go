@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/mysteriumnetwork/node/metadata"
+)
+
+var (
+	// FlagUpdaterURL location of node release channels.
+	FlagUpdaterURL = cli.StringFlag{
+		Name:  "updater.url",
+		Usage: "Location of node release channels. Updates are disabled if empty",
+		Value: "",
+	}
+	// FlagUpdaterChannel release channel to update from.
+	FlagUpdaterChannel = cli.StringFlag{
+		Name:  "updater.channel",
+		Usage: "Release channel to update from: stable or beta",
+		Value: "stable",
+	}
+	// FlagUpdaterPublicKey key verifying release artifact signatures.
+	FlagUpdaterPublicKey = cli.StringFlag{
+		Name:  "updater.public-key",
+		Usage: "Hex encoded ed25519 public key verifying release artifact signatures, defaults to the key embedded at build time",
+		Value: metadata.ReleasePublicKey,
+	}
+	// FlagUpdaterDrainTimeout how long to wait for active sessions to end before restart.
+	FlagUpdaterDrainTimeout = cli.DurationFlag{
+		Name:  "updater.drain-timeout",
+		Usage: "How long to wait for active sessions to end before restarting into the updated binary",
+		Value: 5 * time.Minute,
+	}
+)
+
+// RegisterFlagsUpdater function registers updater flags to flag list.
+func RegisterFlagsUpdater(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagUpdaterURL,
+		&FlagUpdaterChannel,
+		&FlagUpdaterPublicKey,
+		&FlagUpdaterDrainTimeout,
+	)
+}
+
+// ParseFlagsUpdater function fills in updater options from CLI context.
+func ParseFlagsUpdater(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagUpdaterURL)
+	Current.ParseStringFlag(ctx, FlagUpdaterChannel)
+	Current.ParseStringFlag(ctx, FlagUpdaterPublicKey)
+	Current.ParseDurationFlag(ctx, FlagUpdaterDrainTimeout)
+}
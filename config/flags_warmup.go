@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagWarmupEnabled enables pre-established p2p channels to the best providers.
+	FlagWarmupEnabled = cli.BoolFlag{
+		Name:  "warmup.enabled",
+		Usage: "Keep p2p channels to the best ranked providers of favorite filter presets established for faster connects",
+		Value: false,
+	}
+	// FlagWarmupPresets filter presets whose best providers are kept warm.
+	FlagWarmupPresets = cli.StringSliceFlag{
+		Name:  "warmup.presets",
+		Usage: "IDs of favorite proposal filter presets to warm up channels for",
+		Value: cli.NewStringSlice("2"),
+	}
+	// FlagWarmupServiceType service type of warmed up channels.
+	FlagWarmupServiceType = cli.StringFlag{
+		Name:  "warmup.service-type",
+		Usage: "Service type to warm up channels for",
+		Value: "wireguard",
+	}
+	// FlagWarmupSize number of providers to keep warm channels with.
+	FlagWarmupSize = cli.IntFlag{
+		Name:  "warmup.size",
+		Usage: "Number of top ranked providers to keep warm channels with",
+		Value: 3,
+	}
+	// FlagWarmupInterval interval of warm channels refresh.
+	FlagWarmupInterval = cli.DurationFlag{
+		Name:  "warmup.interval",
+		Usage: "How often warm channels are refreshed",
+		Value: 2 * time.Minute,
+	}
+	// FlagWarmupMaxAge maximum age of a warm channel.
+	FlagWarmupMaxAge = cli.DurationFlag{
+		Name:  "warmup.max-age",
+		Usage: "Maximum age of a warm channel after which it is re-established",
+		Value: 5 * time.Minute,
+	}
+	// FlagWarmupKeepAliveInterval interval of warm channel pings.
+	FlagWarmupKeepAliveInterval = cli.DurationFlag{
+		Name:  "warmup.keepalive-interval",
+		Usage: "How often warm channels are pinged to keep NAT mappings open",
+		Value: 20 * time.Second,
+	}
+)
+
+// RegisterFlagsWarmup function registers connection warm-up flags to flag list.
+func RegisterFlagsWarmup(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagWarmupEnabled,
+		&FlagWarmupPresets,
+		&FlagWarmupServiceType,
+		&FlagWarmupSize,
+		&FlagWarmupInterval,
+		&FlagWarmupMaxAge,
+		&FlagWarmupKeepAliveInterval,
+	)
+}
+
+// ParseFlagsWarmup function fills in connection warm-up options from CLI context.
+func ParseFlagsWarmup(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagWarmupEnabled)
+	Current.ParseStringSliceFlag(ctx, FlagWarmupPresets)
+	Current.ParseStringFlag(ctx, FlagWarmupServiceType)
+	Current.ParseIntFlag(ctx, FlagWarmupSize)
+	Current.ParseDurationFlag(ctx, FlagWarmupInterval)
+	Current.ParseDurationFlag(ctx, FlagWarmupMaxAge)
+	Current.ParseDurationFlag(ctx, FlagWarmupKeepAliveInterval)
+}
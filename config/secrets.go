@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// encryptedValuePrefix marks encrypted values in the user configuration file.
+const encryptedValuePrefix = "enc:"
+
+// SecretCipher encrypts secret values of the user configuration.
+type SecretCipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// EnableSecretEncryption decrypts values of the given keys loaded from the user configuration file
+// and keeps them encrypted whenever the file is saved. Plaintext secrets found in the file get encrypted.
+func (cfg *Config) EnableSecretEncryption(cipher SecretCipher, keys []string) error {
+	var plaintext bool
+	err := func() error {
+		cfg.mu.Lock()
+		defer cfg.mu.Unlock()
+
+		cfg.secretCipher = cipher
+		cfg.secretKeys = keys
+		for _, key := range keys {
+			segments := strings.Split(strings.ToLower(key), ".")
+			value, ok := SearchMap(cfg.user, segments).(string)
+			if !ok || value == "" {
+				continue
+			}
+			if !strings.HasPrefix(value, encryptedValuePrefix) {
+				plaintext = true
+				continue
+			}
+
+			decrypted, err := decryptValue(cipher, value)
+			if err != nil {
+				return errors.Wrapf(err, "could not decrypt configuration value %q", key)
+			}
+			deepSearch(cfg.user, segments[:len(segments)-1])[segments[len(segments)-1]] = decrypted
+		}
+		return nil
+	}()
+	if err != nil || !plaintext || !cfg.userConfigLoaded() {
+		return err
+	}
+
+	return cfg.SaveUserConfig()
+}
+
+// userConfigForFile returns a copy of the user configuration with values set to take
+// effect on restart applied and secret values encrypted.
+// Must be called with the read lock held.
+func (cfg *Config) userConfigForFile() (map[string]interface{}, error) {
+	user := deepCopyStrMap(cfg.user)
+	for key, value := range cfg.onRestart {
+		segments := strings.Split(key, ".")
+		deepSearch(user, segments[:len(segments)-1])[segments[len(segments)-1]] = value
+	}
+	if cfg.secretCipher == nil {
+		return user, nil
+	}
+
+	for _, key := range cfg.secretKeys {
+		segments := strings.Split(strings.ToLower(key), ".")
+		value, ok := SearchMap(user, segments).(string)
+		if !ok || value == "" || strings.HasPrefix(value, encryptedValuePrefix) {
+			continue
+		}
+
+		encrypted, err := cfg.secretCipher.Encrypt([]byte(value))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not encrypt configuration value %q", key)
+		}
+		deepSearch(user, segments[:len(segments)-1])[segments[len(segments)-1]] = encryptedValuePrefix + base64.StdEncoding.EncodeToString(encrypted)
+	}
+	return user, nil
+}
+
+func decryptValue(cipher SecretCipher, value string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedValuePrefix))
+	if err != nil {
+		return "", err
+	}
+	decrypted, err := cipher.Decrypt(data)
+	return string(decrypted), err
+}
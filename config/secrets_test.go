@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// reverseCipher is a reversible stand-in for the storage cipher.
+type reverseCipher struct{}
+
+func (reverseCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	return reverse(plaintext), nil
+}
+
+func (reverseCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	return reverse(ciphertext), nil
+}
+
+func reverse(data []byte) []byte {
+	out := make([]byte, len(data))
+	for i := range data {
+		out[len(data)-1-i] = data[i]
+	}
+	return out
+}
+
+func TestUserConfig_SecretEncryption(t *testing.T) {
+	configFileName := NewTempFileName(t)
+	defer os.Remove(configFileName)
+
+	err := ioutil.WriteFile(configFileName, []byte(`
+		[tequilapi.auth]
+		password = "secret"
+		[openvpn]
+		port = 31338
+	`), 0700)
+	assert.NoError(t, err)
+
+	cfg := NewConfig()
+	assert.NoError(t, cfg.LoadUserConfig(configFileName))
+	assert.NoError(t, cfg.EnableSecretEncryption(reverseCipher{}, []string{"tequilapi.auth.password", "mmn.api-key"}))
+
+	// plaintext secret is encrypted in the file, but stays readable
+	assert.Equal(t, "secret", cfg.GetString("tequilapi.auth.password"))
+	content, err := ioutil.ReadFile(configFileName)
+	assert.NoError(t, err)
+	assert.False(t, bytes.Contains(content, []byte(`"secret"`)))
+	assert.True(t, bytes.Contains(content, []byte(`password = "enc:`)))
+	assert.True(t, bytes.Contains(content, []byte(`port = 31338`)))
+
+	// encrypted secret is decrypted on load
+	cfg = NewConfig()
+	assert.NoError(t, cfg.LoadUserConfig(configFileName))
+	assert.NotEqual(t, "secret", cfg.GetString("tequilapi.auth.password"))
+	assert.NoError(t, cfg.EnableSecretEncryption(reverseCipher{}, []string{"tequilapi.auth.password"}))
+	assert.Equal(t, "secret", cfg.GetString("tequilapi.auth.password"))
+}
@@ -56,6 +56,12 @@ func LoadUserConfigQuietly(ctx *cli.Context) error {
 	return nil
 }
 
+// UserConfigLocation returns path of the user config file determined from the context.
+func UserConfigLocation(ctx *cli.Context) string {
+	_, configFilePath := resolveLocation(ctx)
+	return configFilePath
+}
+
 func resolveLocation(ctx *cli.Context) (configDir string, configFilePath string) {
 	configDir = ctx.String("config-dir")
 	configFilePath = path.Join(configDir, "config-mainnet.toml")
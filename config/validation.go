@@ -0,0 +1,324 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cast"
+	"github.com/urfave/cli/v2"
+)
+
+// ValidationSeverity tells whether a configuration issue must be fixed.
+type ValidationSeverity string
+
+const (
+	// ValidationError is an issue which prevents the node from working as configured.
+	ValidationError ValidationSeverity = "error"
+	// ValidationWarning is an issue which is worth fixing, but does not break the node.
+	ValidationWarning ValidationSeverity = "warning"
+)
+
+// ValidationIssue describes a single configuration problem.
+type ValidationIssue struct {
+	Key      string             `json:"key"`
+	Severity ValidationSeverity `json:"severity"`
+	Message  string             `json:"message"`
+}
+
+// ValidationReport holds results of the configuration validation.
+type ValidationReport struct {
+	File     string            `json:"file,omitempty"`
+	Valid    bool              `json:"valid"`
+	Errors   int               `json:"errors"`
+	Warnings int               `json:"warnings"`
+	Issues   []ValidationIssue `json:"issues"`
+}
+
+// valueRules checks values of particular options after their type was validated.
+var valueRules = map[string]func(value interface{}) error{
+	FlagTequilapiPort.Name: validatePort,
+	FlagUIPort.Name:        validatePort,
+	FlagUDPListenPorts.Name: func(value interface{}) error {
+		_, _, err := parsePortRange(cast.ToString(value))
+		return err
+	},
+	FlagLogLevel.Name: func(value interface{}) error {
+		if level, err := zerolog.ParseLevel(cast.ToString(value)); err != nil || level == zerolog.NoLevel {
+			return errors.New("must be a log level, e.g. debug, info or warn")
+		}
+		return nil
+	},
+	FlagFirewallDNSLeakProtection.Name: func(value interface{}) error {
+		switch cast.ToString(value) {
+		case "off", "standard", "strict":
+			return nil
+		}
+		return errors.New("must be one of: off, standard, strict")
+	},
+	FlagPrivacyConsumerIP.Name: func(value interface{}) error {
+		switch cast.ToString(value) {
+		case "full", "truncated", "hashed", "none":
+			return nil
+		}
+		return errors.New("must be one of: full, truncated, hashed, none")
+	},
+	FlagShaperBandwidth.Name: func(value interface{}) error {
+		if cast.ToUint64(value) == 0 {
+			return errors.New("must be greater than 0")
+		}
+		return nil
+	},
+}
+
+// conflictRule reports an issue when options are set to incompatible values.
+type conflictRule struct {
+	key      string
+	severity ValidationSeverity
+	message  string
+	check    func(value func(name string) interface{}) bool
+}
+
+var conflictRules = []conflictRule{
+	{
+		key:      FlagTequilapiSocketOnly.Name,
+		severity: ValidationError,
+		message:  "UI requires the API TCP listener, disable ui.enable or tequilapi.socket-only",
+		check: func(value func(string) interface{}) bool {
+			return cast.ToBool(value(FlagTequilapiSocketOnly.Name)) && cast.ToBool(value(FlagUIEnable.Name))
+		},
+	},
+	{
+		key:      FlagTequilapiSocketOnly.Name,
+		severity: ValidationError,
+		message:  "API would not be served at all, set tequilapi.socket",
+		check: func(value func(string) interface{}) bool {
+			return cast.ToBool(value(FlagTequilapiSocketOnly.Name)) && cast.ToString(value(FlagTequilapiSocket.Name)) == ""
+		},
+	},
+	{
+		key:      FlagTequilapiTLSACMEEmail.Name,
+		severity: ValidationWarning,
+		message:  "has no effect without tequilapi.tls.domain",
+		check: func(value func(string) interface{}) bool {
+			return cast.ToString(value(FlagTequilapiTLSACMEEmail.Name)) != "" && cast.ToString(value(FlagTequilapiTLSDomain.Name)) == ""
+		},
+	},
+}
+
+// ValidateUserConfigFile loads user configuration file and validates it against the given flags.
+// Internal keys are options stored by the node itself which are not backed by flags.
+func ValidateUserConfigFile(path string, flags []cli.Flag, internal ...string) (ValidationReport, error) {
+	user := make(map[string]interface{})
+	if _, err := toml.DecodeFile(path, &user); err != nil {
+		return ValidationReport{File: path}, errors.Wrap(err, "failed to decode configuration file")
+	}
+
+	report := ValidateUserConfig(user, flags, internal...)
+	report.File = path
+	return report, nil
+}
+
+// ValidateUserConfig checks user configuration for unknown and deprecated options,
+// values of wrong type or out of range and conflicting options.
+func ValidateUserConfig(user map[string]interface{}, flags []cli.Flag, internal ...string) ValidationReport {
+	internalKeys := make(map[string]bool, len(internal))
+	for _, key := range internal {
+		internalKeys[key] = true
+	}
+	known := make(map[string]cli.Flag)
+	for _, f := range flags {
+		for _, name := range f.Names() {
+			known[name] = f
+		}
+	}
+
+	values := make(map[string]interface{})
+	flattenConfig(user, "", values)
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	report := ValidationReport{Issues: []ValidationIssue{}}
+	for _, key := range keys {
+		flag, ok := known[key]
+		if !ok && internalKeys[key] {
+			continue
+		}
+		if !ok {
+			report.add(key, ValidationWarning, "unknown option")
+			continue
+		}
+
+		if usage := flagUsage(flag); strings.HasPrefix(strings.ToLower(usage), "deprecated") {
+			report.add(key, ValidationWarning, usage)
+		}
+		if err := validateFlagType(flag, values[key]); err != nil {
+			report.add(key, ValidationError, err.Error())
+			continue
+		}
+		if rule, ok := valueRules[key]; ok {
+			if err := rule(values[key]); err != nil {
+				report.add(key, ValidationError, err.Error())
+			}
+		}
+	}
+
+	value := func(name string) interface{} {
+		if v, ok := values[name]; ok {
+			return v
+		}
+		return flagDefault(known[name])
+	}
+	for _, rule := range conflictRules {
+		if rule.check(value) {
+			report.add(rule.key, rule.severity, rule.message)
+		}
+	}
+
+	report.Valid = report.Errors == 0
+	return report
+}
+
+func (r *ValidationReport) add(key string, severity ValidationSeverity, message string) {
+	r.Issues = append(r.Issues, ValidationIssue{Key: key, Severity: severity, Message: message})
+	if severity == ValidationError {
+		r.Errors++
+	} else {
+		r.Warnings++
+	}
+}
+
+func flattenConfig(src map[string]interface{}, prefix string, dst map[string]interface{}) {
+	for k, v := range src {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]interface{}); ok {
+			flattenConfig(nested, key, dst)
+			continue
+		}
+		dst[key] = v
+	}
+}
+
+func flagUsage(flag cli.Flag) string {
+	if f, ok := flag.(cli.DocGenerationFlag); ok {
+		return f.GetUsage()
+	}
+	return ""
+}
+
+func flagDefault(flag cli.Flag) interface{} {
+	switch f := flag.(type) {
+	case *cli.BoolFlag:
+		return f.Value
+	case *cli.StringFlag:
+		return f.Value
+	case *cli.IntFlag:
+		return f.Value
+	case *cli.Int64Flag:
+		return f.Value
+	case *cli.Uint64Flag:
+		return f.Value
+	case *cli.Float64Flag:
+		return f.Value
+	case *cli.DurationFlag:
+		return f.Value
+	}
+	return nil
+}
+
+func validateFlagType(flag cli.Flag, value interface{}) (err error) {
+	switch flag.(type) {
+	case *cli.BoolFlag:
+		_, err = cast.ToBoolE(value)
+		return typeError(err, "boolean")
+	case *cli.IntFlag, *cli.Int64Flag:
+		_, err = cast.ToInt64E(value)
+		return typeError(err, "integer")
+	case *cli.Uint64Flag:
+		if i, err := cast.ToInt64E(value); err != nil || i < 0 {
+			return errors.New("must be a non-negative integer")
+		}
+	case *cli.Float64Flag:
+		_, err = cast.ToFloat64E(value)
+		return typeError(err, "number")
+	case *cli.DurationFlag:
+		d, err := cast.ToDurationE(value)
+		if err != nil {
+			return typeError(err, "duration, e.g. 30s or 5m")
+		}
+		if d < 0 {
+			return errors.New("must not be negative")
+		}
+	case *cli.StringSliceFlag:
+		_, err = cast.ToStringSliceE(value)
+		return typeError(err, "list of strings")
+	case *cli.StringFlag:
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return typeError(errors.New("not a string"), "string")
+		}
+	}
+	return nil
+}
+
+func typeError(err error, expected string) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("must be a %s", expected)
+}
+
+func validatePort(value interface{}) error {
+	port, err := cast.ToIntE(value)
+	if err != nil || port < 1 || port > 65535 {
+		return errors.New("must be a port number between 1 and 65535")
+	}
+	return nil
+}
+
+func parsePortRange(value string) (int, int, error) {
+	bounds := strings.Split(value, ":")
+	if len(bounds) != 2 {
+		return 0, 0, errors.New("must be a port range in the form min:max")
+	}
+	min, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		return 0, 0, errors.New("must be a port range in the form min:max")
+	}
+	max, err := strconv.Atoi(bounds[1])
+	if err != nil {
+		return 0, 0, errors.New("must be a port range in the form min:max")
+	}
+	if min < 1 || max > 65535 || min > max {
+		return 0, 0, errors.New("port range must be within 1:65535 with min not greater than max")
+	}
+	return min, max, nil
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func validationFlags() []cli.Flag {
+	return []cli.Flag{
+		&FlagTequilapiPort,
+		&FlagTequilapiSocket,
+		&FlagTequilapiSocketOnly,
+		&FlagTequilapiTLSDomain,
+		&FlagTequilapiTLSACMEEmail,
+		&FlagUIEnable,
+		&FlagUDPListenPorts,
+		&FlagP2PListenPorts,
+		&FlagLogLevel,
+		&FlagShaperEnabled,
+		&FlagShaperBandwidth,
+		&FlagKeepConnectedOnFail,
+		&FlagNATHolePunching,
+	}
+}
+
+func TestValidateUserConfig_Valid(t *testing.T) {
+	report := ValidateUserConfig(map[string]interface{}{
+		"log-level": "debug",
+		"tequilapi": map[string]interface{}{"port": int64(4050)},
+		"udp":       map[string]interface{}{"ports": "10000:20000"},
+		"shaper":    map[string]interface{}{"enabled": "true", "bandwidth": int64(100)},
+		"terms":     map[string]interface{}{"version": "1"},
+	}, validationFlags(), "terms.version")
+
+	assert.True(t, report.Valid)
+	assert.Empty(t, report.Issues)
+}
+
+func TestValidateUserConfig_Issues(t *testing.T) {
+	report := ValidateUserConfig(map[string]interface{}{
+		"log-level":                "loud",
+		"tequilapi.port":           int64(70000),
+		"udp":                      map[string]interface{}{"ports": "20000:10000"},
+		"shaper":                   map[string]interface{}{"enabled": "maybe"},
+		"p2p.listen.ports":         "1:2",
+		"experiment-natpunching":   false,
+		"unknown":                  map[string]interface{}{"option": 1},
+		"tequilapi.tls.acme-email": "admin@example.com",
+	}, validationFlags())
+
+	assert.False(t, report.Valid)
+	assert.Equal(t, 4, report.Errors)
+	assert.Equal(t, 4, report.Warnings)
+	assert.Equal(t, []ValidationIssue{
+		{Key: "experiment-natpunching", Severity: ValidationWarning, Message: FlagNATHolePunching.Usage},
+		{Key: "log-level", Severity: ValidationError, Message: "must be a log level, e.g. debug, info or warn"},
+		{Key: "p2p.listen.ports", Severity: ValidationWarning, Message: FlagP2PListenPorts.Usage},
+		{Key: "shaper.enabled", Severity: ValidationError, Message: "must be a boolean"},
+		{Key: "tequilapi.port", Severity: ValidationError, Message: "must be a port number between 1 and 65535"},
+		{Key: "udp.ports", Severity: ValidationError, Message: "port range must be within 1:65535 with min not greater than max"},
+		{Key: "unknown.option", Severity: ValidationWarning, Message: "unknown option"},
+		{Key: "tequilapi.tls.acme-email", Severity: ValidationWarning, Message: "has no effect without tequilapi.tls.domain"},
+	}, report.Issues)
+}
+
+func TestValidateUserConfig_Conflicts(t *testing.T) {
+	report := ValidateUserConfig(map[string]interface{}{
+		"tequilapi": map[string]interface{}{"socket-only": true},
+	}, validationFlags())
+
+	assert.False(t, report.Valid)
+	assert.Equal(t, []ValidationIssue{
+		{Key: "tequilapi.socket-only", Severity: ValidationError, Message: "UI requires the API TCP listener, disable ui.enable or tequilapi.socket-only"},
+		{Key: "tequilapi.socket-only", Severity: ValidationError, Message: "API would not be served at all, set tequilapi.socket"},
+	}, report.Issues)
+
+	report = ValidateUserConfig(map[string]interface{}{
+		"tequilapi": map[string]interface{}{"socket-only": true, "socket": "/run/myst.sock"},
+		"ui":        map[string]interface{}{"enable": false},
+	}, validationFlags())
+	assert.True(t, report.Valid)
+}
+
+func TestValidateUserConfigFile(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte("[tequilapi]\nport = 0\n"), 0600))
+	report, err := ValidateUserConfigFile(path, validationFlags())
+	assert.NoError(t, err)
+	assert.Equal(t, path, report.File)
+	assert.Equal(t, 1, report.Errors)
+
+	broken := filepath.Join(dir, "broken.toml")
+	require.NoError(t, os.WriteFile(broken, []byte("port = ["), 0600))
+	_, err = ValidateUserConfigFile(broken, validationFlags())
+	assert.Error(t, err)
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"sort"
+	"time"
+)
+
+// latencyWindow is the number of the most recent samples the percentile is computed from.
+const latencyWindow = 1000
+
+// Latency is the p2p keepalive round trip time measured during a session.
+type Latency struct {
+	Samples int
+	Last    time.Duration
+	Min     time.Duration
+	Avg     time.Duration
+	P95     time.Duration
+}
+
+// latencyRecorder aggregates keepalive ping round trip times of a single session.
+type latencyRecorder struct {
+	window []time.Duration
+	next   int
+	count  int
+	sum    time.Duration
+	min    time.Duration
+	last   time.Duration
+}
+
+func (r *latencyRecorder) record(rtt time.Duration) {
+	if r.count == 0 || rtt < r.min {
+		r.min = rtt
+	}
+	r.count++
+	r.sum += rtt
+	r.last = rtt
+
+	if len(r.window) < latencyWindow {
+		r.window = append(r.window, rtt)
+		return
+	}
+	r.window[r.next] = rtt
+	r.next = (r.next + 1) % latencyWindow
+}
+
+func (r *latencyRecorder) latency() Latency {
+	if r.count == 0 {
+		return Latency{}
+	}
+
+	sorted := make([]time.Duration, len(r.window))
+	copy(sorted, r.window)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return Latency{
+		Samples: r.count,
+		Last:    r.last,
+		Min:     r.min,
+		Avg:     r.sum / time.Duration(r.count),
+		P95:     sorted[(len(sorted)*95+99)/100-1],
+	}
+}
@@ -48,6 +48,8 @@ type History struct {
 	ProviderID      identity.Identity
 	ServiceType     string
 	ConsumerCountry string
+	// ConsumerASN of provided sessions resolved from the local GeoIP database, 0 if unknown.
+	ConsumerASN     int
 	ProviderCountry string
 	DataSent        uint64
 	DataReceived    uint64
@@ -58,6 +60,11 @@ type History struct {
 	Status  string
 	Started time.Time
 	Updated time.Time
+
+	// Keepalive ping round trip times measured during the session.
+	LatencyMin time.Duration
+	LatencyAvg time.Duration
+	LatencyP95 time.Duration
 }
 
 // GetDuration returns delta in seconds (TimeUpdated - TimeStarted)
@@ -27,6 +27,7 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
@@ -37,6 +38,9 @@ import (
 
 const sessionStorageBucketName = "session-history"
 
+// ErrSessionNotFound is returned when session is neither active nor found in the history.
+var ErrSessionNotFound = errors.New("session not found")
+
 type timeGetter func() time.Time
 
 // Storage contains functions for storing, getting session objects.
@@ -46,6 +50,7 @@ type Storage struct {
 
 	mu             sync.RWMutex
 	sessionsActive map[session_node.ID]History
+	latencyActive  map[session_node.ID]*latencyRecorder
 }
 
 // NewSessionStorage creates session repository with given dependencies.
@@ -55,6 +60,7 @@ func NewSessionStorage(storage *boltdb.Bolt) *Storage {
 		timeGetter: time.Now,
 
 		sessionsActive: make(map[session_node.ID]History),
+		latencyActive:  make(map[session_node.ID]*latencyRecorder),
 	}
 }
 
@@ -75,6 +81,12 @@ func (repo *Storage) Subscribe(bus eventbus.Subscriber) error {
 	if err := bus.Subscribe(connectionstate.AppTopicConnectionStatistics, repo.consumeConnectionStatisticsEvent); err != nil {
 		return err
 	}
+	if err := bus.SubscribeAsync(quality.AppTopicConsumerPingP2P, repo.consumePingEvent); err != nil {
+		return err
+	}
+	if err := bus.SubscribeAsync(quality.AppTopicProviderPingP2P, repo.consumePingEvent); err != nil {
+		return err
+	}
 	return bus.Subscribe(pingpong_event.AppTopicInvoicePaid, repo.consumeConnectionSpendingEvent)
 }
 
@@ -101,6 +113,35 @@ func (repo *Storage) List(filter *Filter) (result []History, err error) {
 	return result, err
 }
 
+// Latency returns keepalive round trip times of the session, measured so far
+// for an active session or the final ones for a finished session.
+func (repo *Storage) Latency(sessionID session_node.ID) (Latency, error) {
+	repo.mu.RLock()
+	recorder, ok := repo.latencyActive[sessionID]
+	if ok {
+		latency := recorder.latency()
+		repo.mu.RUnlock()
+		return latency, nil
+	}
+	repo.mu.RUnlock()
+
+	var row History
+	err := repo.storage.GetOneByField(sessionStorageBucketName, "SessionID", sessionID, &row)
+	if errors.Is(err, storm.ErrNotFound) {
+		return Latency{}, ErrSessionNotFound
+	}
+	if err != nil {
+		return Latency{}, err
+	}
+
+	return Latency{Min: row.LatencyMin, Avg: row.LatencyAvg, P95: row.LatencyP95}, nil
+}
+
+// Prune removes sessions started before the given time and returns their count.
+func (repo *Storage) Prune(before time.Time) (int, error) {
+	return repo.storage.DeleteBefore(sessionStorageBucketName, "Started", before, &History{})
+}
+
 // Stats fetches aggregated statistics to Filter.Stats.
 func (repo *Storage) Stats(filter *Filter) (result Stats, err error) {
 	repo.storage.RLock()
@@ -172,6 +213,7 @@ func (repo *Storage) consumeServiceSessionEvent(e session_event.AppEventSession)
 			ProviderID:      identity.FromAddress(e.Session.Proposal.ProviderID),
 			ServiceType:     e.Session.Proposal.ServiceType,
 			ConsumerCountry: e.Session.ConsumerLocation.Country,
+			ConsumerASN:     e.Session.ConsumerLocation.ASN,
 			ProviderCountry: e.Session.Proposal.Location.Country,
 			Started:         e.Session.StartedAt.UTC(),
 			Tokens:          new(big.Int),
@@ -221,6 +263,17 @@ func (repo *Storage) consumeServiceSessionEarningsEvent(e session_event.AppEvent
 	repo.sessionsActive[sessionID] = row
 }
 
+func (repo *Storage) consumePingEvent(e quality.PingEvent) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	recorder, ok := repo.latencyActive[session_node.ID(e.SessionID)]
+	if !ok {
+		return
+	}
+	recorder.record(e.Duration)
+}
+
 func (repo *Storage) activeSession(sessionID session_node.ID) (History, bool) {
 	history, ok := repo.sessionsActive[sessionID]
 	if !ok {
@@ -305,6 +358,10 @@ func (repo *Storage) handleEndedEvent(sessionID session_node.ID) {
 	}
 	row.Updated = repo.timeGetter().UTC()
 	row.Status = StatusCompleted
+	if recorder, ok := repo.latencyActive[sessionID]; ok {
+		latency := recorder.latency()
+		row.LatencyMin, row.LatencyAvg, row.LatencyP95 = latency.Min, latency.Avg, latency.P95
+	}
 
 	err := repo.storage.Update(sessionStorageBucketName, &row)
 	if err != nil {
@@ -313,6 +370,7 @@ func (repo *Storage) handleEndedEvent(sessionID session_node.ID) {
 	}
 
 	delete(repo.sessionsActive, sessionID)
+	delete(repo.latencyActive, sessionID)
 	log.Debug().Msgf("Session %v updated with final data", sessionID)
 }
 
@@ -334,5 +392,6 @@ func (repo *Storage) handleCreatedEvent(sessionID session_node.ID) {
 	}
 
 	repo.sessionsActive[sessionID] = row
+	repo.latencyActive[sessionID] = &latencyRecorder{}
 	log.Debug().Msgf("Session %v saved", row.SessionID)
 }
@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
@@ -376,6 +377,71 @@ func TestSessionStorage_consumeEventEndedOK(t *testing.T) {
 	)
 }
 
+func TestSessionStorage_Latency(t *testing.T) {
+	// given
+	storage, storageCleanup := newStorage()
+	defer storageCleanup()
+
+	_, err := storage.Latency("sessionID")
+	assert.Equal(t, ErrSessionNotFound, err)
+
+	// when
+	storage.consumeConnectionSessionEvent(connectionstate.AppEventConnectionSession{
+		Status:      connectionstate.SessionCreatedStatus,
+		SessionInfo: connectionSessionMock,
+	})
+	for _, ms := range []int{40, 20, 30, 100} {
+		storage.consumePingEvent(quality.PingEvent{SessionID: "sessionID", Duration: time.Duration(ms) * time.Millisecond})
+	}
+	storage.consumePingEvent(quality.PingEvent{SessionID: "unknown", Duration: time.Second})
+
+	// then
+	latency, err := storage.Latency("sessionID")
+	assert.NoError(t, err)
+	assert.Equal(t, Latency{
+		Samples: 4,
+		Last:    100 * time.Millisecond,
+		Min:     20 * time.Millisecond,
+		Avg:     47500 * time.Microsecond,
+		P95:     100 * time.Millisecond,
+	}, latency)
+
+	// when
+	storage.consumeConnectionSessionEvent(connectionstate.AppEventConnectionSession{
+		Status:      connectionstate.SessionEndedStatus,
+		SessionInfo: connectionSessionMock,
+	})
+
+	// then
+	sessions, err := storage.GetAll()
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, 20*time.Millisecond, sessions[0].LatencyMin)
+	assert.Equal(t, 47500*time.Microsecond, sessions[0].LatencyAvg)
+	assert.Equal(t, 100*time.Millisecond, sessions[0].LatencyP95)
+
+	latency, err = storage.Latency("sessionID")
+	assert.NoError(t, err)
+	assert.Equal(t, Latency{Min: 20 * time.Millisecond, Avg: 47500 * time.Microsecond, P95: 100 * time.Millisecond}, latency)
+}
+
+func TestLatencyRecorder_PercentileOfRecentSamples(t *testing.T) {
+	var r latencyRecorder
+	for i := 1; i <= 100; i++ {
+		r.record(time.Duration(i) * time.Millisecond)
+	}
+	assert.Equal(t, 95*time.Millisecond, r.latency().P95)
+
+	// old samples leave the window, but still count towards min and average
+	for i := 0; i < latencyWindow; i++ {
+		r.record(time.Second)
+	}
+	latency := r.latency()
+	assert.Equal(t, time.Second, latency.P95)
+	assert.Equal(t, time.Millisecond, latency.Min)
+	assert.Equal(t, 100+latencyWindow, latency.Samples)
+}
+
 func TestSessionStorage_consumeEventConnectedOK(t *testing.T) {
 	// given
 	storage, storageCleanup := newStorage()
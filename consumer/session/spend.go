@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"math/big"
+	"time"
+)
+
+// NewSpendSummary initiates zero SpendSummary instance.
+func NewSpendSummary() SpendSummary {
+	return SpendSummary{
+		Total:             new(big.Int),
+		ByProviderCountry: make(map[string]*big.Int),
+		ByServiceType:     make(map[string]*big.Int),
+		ByDay:             make(map[time.Time]*big.Int),
+	}
+}
+
+// SpendSummary holds aggregated spendings of consumed sessions.
+type SpendSummary struct {
+	Total             *big.Int
+	ByProviderCountry map[string]*big.Int
+	ByServiceType     map[string]*big.Int
+	ByDay             map[time.Time]*big.Int
+}
+
+// Add accumulates spendings of the given session.
+func (s *SpendSummary) Add(session History) {
+	if session.Tokens == nil {
+		return
+	}
+
+	s.Total = new(big.Int).Add(s.Total, session.Tokens)
+	addTokens(s.ByProviderCountry, session.ProviderCountry, session.Tokens)
+	addTokens(s.ByServiceType, session.ServiceType, session.Tokens)
+
+	day := session.Started.Truncate(stepDay)
+	if sum, ok := s.ByDay[day]; ok {
+		s.ByDay[day] = new(big.Int).Add(sum, session.Tokens)
+	} else {
+		s.ByDay[day] = new(big.Int).Set(session.Tokens)
+	}
+}
+
+func addTokens(sums map[string]*big.Int, key string, tokens *big.Int) {
+	if sum, ok := sums[key]; ok {
+		sums[key] = new(big.Int).Add(sum, tokens)
+	} else {
+		sums[key] = new(big.Int).Set(tokens)
+	}
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpendSummary_Add(t *testing.T) {
+	summary := NewSpendSummary()
+	summary.Add(History{
+		ProviderCountry: "US",
+		ServiceType:     "wireguard",
+		Tokens:          big.NewInt(10),
+		Started:         time.Date(2020, 6, 17, 10, 0, 0, 0, time.UTC),
+	})
+	summary.Add(History{
+		ProviderCountry: "US",
+		ServiceType:     "openvpn",
+		Tokens:          big.NewInt(5),
+		Started:         time.Date(2020, 6, 17, 23, 0, 0, 0, time.UTC),
+	})
+	summary.Add(History{
+		ProviderCountry: "LT",
+		ServiceType:     "wireguard",
+		Tokens:          big.NewInt(1),
+		Started:         time.Date(2020, 6, 18, 1, 0, 0, 0, time.UTC),
+	})
+	summary.Add(History{ProviderCountry: "LT"})
+
+	assert.Equal(t, big.NewInt(16), summary.Total)
+	assert.Equal(t, map[string]*big.Int{"US": big.NewInt(15), "LT": big.NewInt(1)}, summary.ByProviderCountry)
+	assert.Equal(t, map[string]*big.Int{"wireguard": big.NewInt(11), "openvpn": big.NewInt(5)}, summary.ByServiceType)
+	assert.Equal(t, map[time.Time]*big.Int{
+		time.Date(2020, 6, 17, 0, 0, 0, 0, time.UTC): big.NewInt(15),
+		time.Date(2020, 6, 18, 0, 0, 0, 0, time.UTC): big.NewInt(1),
+	}, summary.ByDay)
+}
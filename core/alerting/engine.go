@@ -0,0 +1,433 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/gofrs/uuid"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+const (
+	bucketName  = "alerting"
+	rulesKey    = "rules"
+	stateKey    = "state"
+	historySize = 100
+)
+
+// Alert is produced when a rule condition is met and resolved once it's not met anymore.
+type Alert struct {
+	ID       string
+	RuleID   string
+	Kind     Kind
+	Severity string
+	Message  string
+	FiredAt  time.Time
+	// ResolvedAt is zero while the alert is active.
+	ResolvedAt time.Time
+}
+
+// Active tells if the alert condition is still met.
+func (a Alert) Active() bool {
+	return a.ResolvedAt.IsZero()
+}
+
+// engineState is the persisted state of the engine, so that active alerts don't fire again after the restart.
+type engineState struct {
+	Active       []Alert
+	History      []Alert
+	FailingSince time.Time
+}
+
+// Sink delivers alerts to the node operator, it's notified when alerts fire and resolve.
+type Sink interface {
+	Notify(alert Alert) error
+}
+
+type ruleStorage interface {
+	GetValue(bucket string, key interface{}, to interface{}) error
+	SetValue(bucket string, key interface{}, to interface{}) error
+}
+
+type identityProvider interface {
+	GetUnlockedIdentity() (identity.Identity, bool)
+}
+
+type balanceProvider interface {
+	GetBalance(chainID int64, id identity.Identity) *big.Int
+}
+
+type sessionStatsProvider interface {
+	Stats(*session.Filter) (session.Stats, error)
+}
+
+type monitoringStatusProvider interface {
+	Status() node.MonitoringStatus
+}
+
+// Deps holds providers of the state rules are evaluated against, rules depending
+// on providers which are not set are never triggered.
+type Deps struct {
+	Identities   identityProvider
+	Balances     balanceProvider
+	SessionStats sessionStatsProvider
+	Monitoring   monitoringStatusProvider
+}
+
+// Engine periodically evaluates alert rules and dispatches produced alerts to sinks.
+type Engine struct {
+	storage  ruleStorage
+	deps     Deps
+	interval time.Duration
+	now      func() time.Time
+
+	mu           sync.Mutex
+	rules        []Rule
+	active       map[string]*Alert
+	history      []*Alert
+	failingSince time.Time
+	sinks        map[string]Sink
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewEngine returns a new alerting engine, loading stored rules and alerts.
+func NewEngine(storage ruleStorage, deps Deps, interval time.Duration) *Engine {
+	e := &Engine{
+		storage:  storage,
+		deps:     deps,
+		interval: interval,
+		now:      time.Now,
+		active:   make(map[string]*Alert),
+		sinks:    make(map[string]Sink),
+		stop:     make(chan struct{}),
+	}
+	if err := storage.GetValue(bucketName, rulesKey, &e.rules); err != nil && !errors.Is(err, storm.ErrNotFound) {
+		log.Warn().Err(err).Msg("Could not load alert rules")
+	}
+
+	var state engineState
+	if err := storage.GetValue(bucketName, stateKey, &state); err != nil && !errors.Is(err, storm.ErrNotFound) {
+		log.Warn().Err(err).Msg("Could not load alerts")
+	}
+	e.failingSince = state.FailingSince
+	for i := range state.Active {
+		e.active[state.Active[i].RuleID] = &state.Active[i]
+	}
+	for i := range state.History {
+		alert := &state.History[i]
+		if active, ok := e.active[alert.RuleID]; ok && active.ID == alert.ID {
+			alert = active
+		}
+		e.history = append(e.history, alert)
+	}
+	return e
+}
+
+// Start starts evaluating rules periodically.
+func (e *Engine) Start() {
+	go func() {
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-e.stop:
+				return
+			case <-ticker.C:
+				e.Check()
+			}
+		}
+	}()
+}
+
+// Stop stops evaluating rules.
+func (e *Engine) Stop() {
+	e.stopOnce.Do(func() {
+		close(e.stop)
+	})
+}
+
+// AddSink registers a sink under the given name, replacing a sink registered with the same name.
+func (e *Engine) AddSink(name string, sink Sink) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.sinks[name] = sink
+}
+
+// RemoveSink unregisters the sink with the given name.
+func (e *Engine) RemoveSink(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.sinks, name)
+}
+
+// Rules returns configured alert rules.
+func (e *Engine) Rules() []Rule {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]Rule{}, e.rules...)
+}
+
+// AddRule validates and stores a new rule, returning it with the assigned ID.
+func (e *Engine) AddRule(rule Rule) (Rule, error) {
+	if err := rule.Validate(); err != nil {
+		return Rule{}, err
+	}
+	id, err := uuid.NewV4()
+	if err != nil {
+		return Rule{}, err
+	}
+	rule.ID = id.String()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	rules := append(append([]Rule{}, e.rules...), rule)
+	if err := e.storage.SetValue(bucketName, rulesKey, rules); err != nil {
+		return Rule{}, err
+	}
+	e.rules = rules
+	return rule, nil
+}
+
+// RemoveRule removes the rule with the given ID, resolving its active alert without notifying sinks.
+func (e *Engine) RemoveRule(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	rules := make([]Rule, 0, len(e.rules))
+	for _, r := range e.rules {
+		if r.ID != id {
+			rules = append(rules, r)
+		}
+	}
+	if len(rules) == len(e.rules) {
+		return ErrRuleNotFound
+	}
+	if err := e.storage.SetValue(bucketName, rulesKey, rules); err != nil {
+		return err
+	}
+	e.rules = rules
+
+	if alert, ok := e.active[id]; ok {
+		alert.ResolvedAt = e.now().UTC()
+		delete(e.active, id)
+		e.saveState()
+	}
+	return nil
+}
+
+// Active returns alerts which conditions are still met, most recent first.
+func (e *Engine) Active() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	res := make([]Alert, 0, len(e.active))
+	for _, a := range e.active {
+		res = append(res, *a)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].FiredAt.After(res[j].FiredAt)
+	})
+	return res
+}
+
+// History returns recently produced alerts including resolved ones, most recent first.
+func (e *Engine) History() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	res := make([]Alert, 0, len(e.history))
+	for i := len(e.history) - 1; i >= 0; i-- {
+		res = append(res, *e.history[i])
+	}
+	return res
+}
+
+// Check evaluates all rules, firing and resolving alerts.
+func (e *Engine) Check() {
+	now := e.now().UTC()
+
+	var status node.MonitoringStatus
+	if e.deps.Monitoring != nil {
+		status = e.deps.Monitoring.Status()
+	}
+
+	e.mu.Lock()
+	previous := e.failingSince
+	if status == node.Failed {
+		if e.failingSince.IsZero() {
+			e.failingSince = now
+		}
+	} else {
+		e.failingSince = time.Time{}
+	}
+	failingSince := e.failingSince
+	if !failingSince.Equal(previous) {
+		e.saveState()
+	}
+	rules := append([]Rule{}, e.rules...)
+	e.mu.Unlock()
+
+	var notify []Alert
+	for _, rule := range rules {
+		triggered, message, ok := e.evaluate(rule, now, failingSince)
+		if !ok {
+			continue
+		}
+		if alert, changed := e.transition(rule, triggered, message, now); changed {
+			notify = append(notify, alert)
+		}
+	}
+
+	for _, alert := range notify {
+		e.dispatch(alert)
+	}
+}
+
+func (e *Engine) evaluate(rule Rule, now, failingSince time.Time) (triggered bool, message string, ok bool) {
+	if rule.Kind == KindMonitoringFailed {
+		if e.deps.Monitoring == nil {
+			return false, "", false
+		}
+		triggered = !failingSince.IsZero() && now.Sub(failingSince) >= rule.Window
+		return triggered, fmt.Sprintf("Monitoring has been failing for more than %s", rule.Window), true
+	}
+
+	if e.deps.Identities == nil {
+		return false, "", false
+	}
+	id, unlocked := e.deps.Identities.GetUnlockedIdentity()
+	if !unlocked {
+		return false, "", false
+	}
+
+	switch rule.Kind {
+	case KindBalanceBelow:
+		if e.deps.Balances == nil {
+			return false, "", false
+		}
+		balance := crypto.BigMystToDecimal(e.deps.Balances.GetBalance(config.GetInt64(config.FlagChainID), id))
+		return balance.LessThan(rule.Threshold), fmt.Sprintf("Balance is %s MYST, below %s MYST", balance.StringFixed(4), rule.Threshold), true
+	case KindEarningsBelow:
+		if e.deps.SessionStats == nil {
+			return false, "", false
+		}
+		filter := session.NewFilter().
+			SetStartedFrom(now.Add(-rule.Window)).
+			SetDirection(session.DirectionProvided).
+			SetProviderID(id)
+		stats, err := e.deps.SessionStats.Stats(filter)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Could not evaluate alert rule %s", rule.ID)
+			return false, "", false
+		}
+		earned := crypto.BigMystToDecimal(stats.SumTokens)
+		return earned.LessThan(rule.Threshold), fmt.Sprintf("Earned %s MYST during the last %s, below %s MYST", earned.StringFixed(4), rule.Window, rule.Threshold), true
+	}
+	return false, "", false
+}
+
+func (e *Engine) transition(rule Rule, triggered bool, message string, now time.Time) (Alert, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	active, isActive := e.active[rule.ID]
+	switch {
+	case triggered && !isActive:
+		id, err := uuid.NewV4()
+		if err != nil {
+			log.Error().Err(err).Msg("Could not generate alert ID")
+			return Alert{}, false
+		}
+		alert := &Alert{
+			ID:       id.String(),
+			RuleID:   rule.ID,
+			Kind:     rule.Kind,
+			Severity: rule.Severity,
+			Message:  message,
+			FiredAt:  now,
+		}
+		e.active[rule.ID] = alert
+		e.history = append(e.history, alert)
+		if len(e.history) > historySize {
+			e.history = e.history[len(e.history)-historySize:]
+		}
+		e.saveState()
+		log.Info().Msgf("Alert %s fired: %s", rule.Kind, message)
+		return *alert, true
+	case !triggered && isActive:
+		active.ResolvedAt = now
+		delete(e.active, rule.ID)
+		e.saveState()
+		log.Info().Msgf("Alert %s resolved", rule.Kind)
+		return *active, true
+	}
+	return Alert{}, false
+}
+
+// saveState stores alerts and monitoring failure start, must be called with the lock held.
+func (e *Engine) saveState() {
+	state := engineState{
+		Active:       make([]Alert, 0, len(e.active)),
+		History:      make([]Alert, 0, len(e.history)),
+		FailingSince: e.failingSince,
+	}
+	for _, alert := range e.active {
+		state.Active = append(state.Active, *alert)
+	}
+	for _, alert := range e.history {
+		state.History = append(state.History, *alert)
+	}
+	if err := e.storage.SetValue(bucketName, stateKey, state); err != nil {
+		log.Warn().Err(err).Msg("Could not store alerts")
+	}
+}
+
+func (e *Engine) dispatch(alert Alert) {
+	e.mu.Lock()
+	sinks := make(map[string]Sink, len(e.sinks))
+	for name, sink := range e.sinks {
+		sinks[name] = sink
+	}
+	e.mu.Unlock()
+
+	for name, sink := range sinks {
+		if err := sink.Notify(alert); err != nil {
+			log.Warn().Err(err).Msgf("Could not dispatch alert to %s sink", name)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package alerting
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+type mockIdentities struct{}
+
+func (m *mockIdentities) GetUnlockedIdentity() (identity.Identity, bool) {
+	return identity.FromAddress("0x1"), true
+}
+
+type mockBalances struct {
+	balance *big.Int
+}
+
+func (m *mockBalances) GetBalance(int64, identity.Identity) *big.Int {
+	return m.balance
+}
+
+type mockStats struct {
+	earned *big.Int
+	filter *session.Filter
+}
+
+func (m *mockStats) Stats(filter *session.Filter) (session.Stats, error) {
+	m.filter = filter
+	stats := session.NewStats()
+	stats.SumTokens = m.earned
+	return stats, nil
+}
+
+type mockMonitoring struct {
+	status node.MonitoringStatus
+}
+
+func (m *mockMonitoring) Status() node.MonitoringStatus {
+	return m.status
+}
+
+type mockSink struct {
+	alerts []Alert
+}
+
+func (m *mockSink) Notify(alert Alert) error {
+	m.alerts = append(m.alerts, alert)
+	return nil
+}
+
+func newTestStorage(t *testing.T) *boltdb.Bolt {
+	bolt, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { bolt.Close() })
+	return bolt
+}
+
+func TestEngine_PersistsRules(t *testing.T) {
+	bolt := newTestStorage(t)
+	engine := NewEngine(bolt, Deps{}, time.Minute)
+
+	_, err := engine.AddRule(Rule{Kind: "unknown"})
+	assert.ErrorIs(t, err, ErrInvalidRule)
+
+	rule, err := engine.AddRule(Rule{Kind: KindBalanceBelow, Threshold: decimal.NewFromInt(1)})
+	require.NoError(t, err)
+	assert.NotEmpty(t, rule.ID)
+	assert.Equal(t, SeverityWarning, rule.Severity)
+
+	reloaded := NewEngine(bolt, Deps{}, time.Minute)
+	require.Len(t, reloaded.Rules(), 1)
+	assert.Equal(t, rule.ID, reloaded.Rules()[0].ID)
+
+	assert.ErrorIs(t, reloaded.RemoveRule("missing"), ErrRuleNotFound)
+	require.NoError(t, reloaded.RemoveRule(rule.ID))
+	assert.Empty(t, NewEngine(bolt, Deps{}, time.Minute).Rules())
+}
+
+func TestEngine_MonitoringFailed(t *testing.T) {
+	monitoring := &mockMonitoring{status: node.Failed}
+	engine := NewEngine(newTestStorage(t), Deps{Monitoring: monitoring}, time.Minute)
+	sink := &mockSink{}
+	engine.AddSink("test", sink)
+	now := time.Date(2022, 3, 4, 10, 0, 0, 0, time.UTC)
+	engine.now = func() time.Time { return now }
+	rule, err := engine.AddRule(Rule{Kind: KindMonitoringFailed, Severity: SeverityError})
+	require.NoError(t, err)
+
+	engine.Check()
+	now = now.Add(59 * time.Minute)
+	engine.Check()
+	assert.Empty(t, engine.Active())
+	assert.Empty(t, sink.alerts)
+
+	now = now.Add(time.Minute)
+	engine.Check()
+	require.Len(t, engine.Active(), 1)
+	fired := engine.Active()[0]
+	assert.Equal(t, rule.ID, fired.RuleID)
+	assert.Equal(t, SeverityError, fired.Severity)
+	assert.Equal(t, now, fired.FiredAt)
+	assert.Equal(t, []Alert{fired}, sink.alerts)
+
+	engine.Check()
+	assert.Len(t, sink.alerts, 1)
+
+	monitoring.status = node.Passed
+	now = now.Add(time.Minute)
+	engine.Check()
+	assert.Empty(t, engine.Active())
+	require.Len(t, sink.alerts, 2)
+	assert.False(t, sink.alerts[1].Active())
+	assert.Equal(t, now, sink.alerts[1].ResolvedAt)
+	assert.Equal(t, []Alert{sink.alerts[1]}, engine.History())
+}
+
+func TestEngine_PersistsAlertsAcrossRestart(t *testing.T) {
+	bolt := newTestStorage(t)
+	monitoring := &mockMonitoring{status: node.Failed}
+	now := time.Date(2022, 3, 4, 10, 0, 0, 0, time.UTC)
+
+	engine := NewEngine(bolt, Deps{Monitoring: monitoring}, time.Minute)
+	engine.now = func() time.Time { return now }
+	_, err := engine.AddRule(Rule{Kind: KindMonitoringFailed, Window: 2 * time.Minute})
+	require.NoError(t, err)
+
+	// monitoring failure start survives the restart
+	engine.Check()
+	now = now.Add(time.Minute)
+	restarted := NewEngine(bolt, Deps{Monitoring: monitoring}, time.Minute)
+	restarted.now = func() time.Time { return now }
+	restarted.Check()
+	assert.Empty(t, restarted.Active())
+
+	now = now.Add(time.Minute)
+	restarted.Check()
+	require.Len(t, restarted.Active(), 1)
+	fired := restarted.Active()[0]
+
+	// active alert is not fired again after the restart
+	sink := &mockSink{}
+	restarted = NewEngine(bolt, Deps{Monitoring: monitoring}, time.Minute)
+	restarted.now = func() time.Time { return now }
+	restarted.AddSink("test", sink)
+	restarted.Check()
+	assert.Empty(t, sink.alerts)
+	assert.Equal(t, []Alert{fired}, restarted.Active())
+
+	monitoring.status = node.Passed
+	restarted.Check()
+	require.Len(t, sink.alerts, 1)
+	assert.Equal(t, fired.ID, sink.alerts[0].ID)
+	assert.Equal(t, []Alert{sink.alerts[0]}, restarted.History())
+}
+
+func TestEngine_ThresholdRules(t *testing.T) {
+	balances := &mockBalances{balance: crypto.FloatToBigMyst(0.5)}
+	stats := &mockStats{earned: crypto.FloatToBigMyst(3)}
+	engine := NewEngine(newTestStorage(t), Deps{
+		Identities:   &mockIdentities{},
+		Balances:     balances,
+		SessionStats: stats,
+	}, time.Minute)
+	now := time.Date(2022, 3, 4, 10, 0, 0, 0, time.UTC)
+	engine.now = func() time.Time { return now }
+
+	balanceRule, err := engine.AddRule(Rule{Kind: KindBalanceBelow, Threshold: decimal.NewFromInt(1)})
+	require.NoError(t, err)
+	_, err = engine.AddRule(Rule{Kind: KindEarningsBelow, Threshold: decimal.NewFromInt(2)})
+	require.NoError(t, err)
+
+	engine.Check()
+
+	active := engine.Active()
+	require.Len(t, active, 1)
+	assert.Equal(t, balanceRule.ID, active[0].RuleID)
+	assert.Equal(t, "Balance is 0.5000 MYST, below 1 MYST", active[0].Message)
+	assert.Equal(t, now.Add(-7*24*time.Hour), *stats.filter.StartedFrom)
+
+	balances.balance = crypto.FloatToBigMyst(2)
+	stats.earned = crypto.FloatToBigMyst(1)
+	engine.Check()
+
+	active = engine.Active()
+	require.Len(t, active, 1)
+	assert.Equal(t, KindEarningsBelow, active[0].Kind)
+	assert.Len(t, engine.History(), 2)
+}
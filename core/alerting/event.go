@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package alerting
+
+import "github.com/mysteriumnetwork/node/eventbus"
+
+// AppTopicAlert is published when an alert fires or resolves.
+const AppTopicAlert = "alert"
+
+// AppEventAlert is the event payload for AppTopicAlert topic.
+type AppEventAlert struct {
+	Alert
+}
+
+// EventSink dispatches alerts to the event bus, making them available to SSE subscribers.
+type EventSink struct {
+	publisher eventbus.Publisher
+}
+
+// NewEventSink returns a new event bus alert sink.
+func NewEventSink(publisher eventbus.Publisher) *EventSink {
+	return &EventSink{publisher: publisher}
+}
+
+// Notify publishes the alert.
+func (s *EventSink) Notify(alert Alert) error {
+	s.publisher.Publish(AppTopicAlert, AppEventAlert{Alert: alert})
+	return nil
+}
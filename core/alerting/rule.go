@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Kind is the condition checked by an alert rule.
+type Kind string
+
+const (
+	// KindEarningsBelow fires when earnings during the rule window are below the threshold.
+	KindEarningsBelow Kind = "earnings_below"
+	// KindBalanceBelow fires when the balance of the unlocked identity is below the threshold.
+	KindBalanceBelow Kind = "balance_below"
+	// KindMonitoringFailed fires when monitoring status stays failed for longer than the rule window.
+	KindMonitoringFailed Kind = "monitoring_failed"
+)
+
+// Alert severities.
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+var defaultWindows = map[Kind]time.Duration{
+	KindEarningsBelow:    7 * 24 * time.Hour,
+	KindBalanceBelow:     0,
+	KindMonitoringFailed: time.Hour,
+}
+
+// ErrInvalidRule is returned for rules which can not be evaluated.
+var ErrInvalidRule = errors.New("invalid alert rule")
+
+// ErrRuleNotFound is returned when removing an unknown rule.
+var ErrRuleNotFound = errors.New("alert rule not found")
+
+// Rule is a user defined condition producing alerts.
+type Rule struct {
+	ID   string
+	Kind Kind
+	// Threshold in MYST, used by earnings and balance rules.
+	Threshold decimal.Decimal
+	// Window is the earnings period or the time monitoring has to stay failed.
+	Window   time.Duration
+	Severity string
+}
+
+// Validate checks the rule and fills in defaults of optional fields.
+func (r *Rule) Validate() error {
+	window, ok := defaultWindows[r.Kind]
+	if !ok {
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidRule, r.Kind)
+	}
+	if r.Window < 0 {
+		return fmt.Errorf("%w: window can not be negative", ErrInvalidRule)
+	}
+	if r.Window == 0 {
+		r.Window = window
+	}
+
+	switch r.Kind {
+	case KindEarningsBelow, KindBalanceBelow:
+		if !r.Threshold.IsPositive() {
+			return fmt.Errorf("%w: %s requires a positive threshold", ErrInvalidRule, r.Kind)
+		}
+	}
+
+	switch r.Severity {
+	case "":
+		r.Severity = SeverityWarning
+	case SeverityInfo, SeverityWarning, SeverityError:
+	default:
+		return fmt.Errorf("%w: unknown severity %q", ErrInvalidRule, r.Severity)
+	}
+	return nil
+}
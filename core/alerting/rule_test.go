@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRule_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		rule    Rule
+		want    Rule
+		wantErr bool
+	}{
+		{
+			rule: Rule{Kind: KindEarningsBelow, Threshold: decimal.NewFromInt(5)},
+			want: Rule{Kind: KindEarningsBelow, Threshold: decimal.NewFromInt(5), Window: 7 * 24 * time.Hour, Severity: SeverityWarning},
+		},
+		{
+			rule: Rule{Kind: KindMonitoringFailed, Window: 2 * time.Hour, Severity: SeverityError},
+			want: Rule{Kind: KindMonitoringFailed, Window: 2 * time.Hour, Severity: SeverityError},
+		},
+		{rule: Rule{Kind: KindBalanceBelow}, wantErr: true},
+		{rule: Rule{Kind: KindMonitoringFailed, Window: -time.Hour}, wantErr: true},
+		{rule: Rule{Kind: KindMonitoringFailed, Severity: "fatal"}, wantErr: true},
+		{rule: Rule{Kind: "disk_full"}, wantErr: true},
+	} {
+		err := tc.rule.Validate()
+		if tc.wantErr {
+			assert.ErrorIs(t, err, ErrInvalidRule)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, tc.want, tc.rule)
+	}
+}
@@ -18,6 +18,7 @@
 package auth
 
 import (
+	"sync"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
@@ -26,6 +27,7 @@ import (
 
 // JWTAuthenticator contains JWT handling methods
 type JWTAuthenticator struct {
+	mu            sync.RWMutex
 	encryptionKey []byte
 }
 
@@ -51,7 +53,7 @@ const expiresIn = 48 * time.Hour
 // NewJWTAuthenticator creates a new JWT authentication instance
 func NewJWTAuthenticator(encryptionKey JWTEncryptionKey) *JWTAuthenticator {
 	auth := &JWTAuthenticator{
-		encryptionKey,
+		encryptionKey: encryptionKey,
 	}
 
 	return auth
@@ -68,7 +70,7 @@ func (jwtAuth *JWTAuthenticator) CreateToken(username string) (JWT, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtAuth.encryptionKey)
+	tokenString, err := token.SignedString(jwtAuth.key())
 	if err != nil {
 		return JWT{}, err
 	}
@@ -81,7 +83,7 @@ func (jwtAuth *JWTAuthenticator) ValidateToken(token string) (bool, error) {
 	claims := &jwtClaims{}
 
 	tkn, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtAuth.encryptionKey, nil
+		return jwtAuth.key(), nil
 	})
 	if err != nil {
 		return false, err
@@ -94,6 +96,19 @@ func (jwtAuth *JWTAuthenticator) ValidateToken(token string) (bool, error) {
 	return true, nil
 }
 
+// SetEncryptionKey replaces the encryption key, invalidating all previously issued tokens.
+func (jwtAuth *JWTAuthenticator) SetEncryptionKey(encryptionKey JWTEncryptionKey) {
+	jwtAuth.mu.Lock()
+	defer jwtAuth.mu.Unlock()
+	jwtAuth.encryptionKey = encryptionKey
+}
+
+func (jwtAuth *JWTAuthenticator) key() []byte {
+	jwtAuth.mu.RLock()
+	defer jwtAuth.mu.RUnlock()
+	return jwtAuth.encryptionKey
+}
+
 func (jwtAuth *JWTAuthenticator) getExpirationTime() time.Time {
 	return time.Now().Add(expiresIn)
 }
@@ -50,6 +50,18 @@ func NewJWTEncryptionKey(storage Storage) (JWTEncryptionKey, error) {
 	return key, nil
 }
 
+// RotateJWTEncryptionKey generates and stores a new JWT encryption key replacing the existing one
+func RotateJWTEncryptionKey(storage Storage) (JWTEncryptionKey, error) {
+	key, err := generateRandomBytes(256)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate JWT encryption key")
+	}
+	if err := storage.SetValue(encryptionKeyBucket, encryptionKeyName, JWTEncryptionKey(key)); err != nil {
+		return nil, errors.Wrap(err, "failed to store JWT encryption key")
+	}
+	return key, nil
+}
+
 func generateRandomBytes(length int) ([]byte, error) {
 	key := make([]byte, length)
 	_, err := rand.Read(key)
@@ -0,0 +1,188 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chains
+
+import (
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/metadata"
+	"github.com/mysteriumnetwork/node/utils/errcode"
+)
+
+// AppTopicActiveChain is published when the active chain switch is scheduled.
+const AppTopicActiveChain = "active-chain"
+
+// AppEventActiveChain represents the active chain switch which takes effect after the node restart.
+type AppEventActiveChain struct {
+	Previous int64
+	Current  int64
+}
+
+// Error codes of chain switching.
+const (
+	ErrCodeUnknownChain = "err_unknown_chain"
+	ErrCodeChainPinned  = "err_chain_pinned"
+	ErrCodeChainInUse   = "err_chain_in_use"
+)
+
+var (
+	// ErrUnknownChain indicates that the requested chain is not configured.
+	ErrUnknownChain = errcode.New(ErrCodeUnknownChain, "chain is not configured",
+		"Choose one of the chains listed by GET /chains")
+	// ErrChainPinned indicates that the active chain is set with a CLI flag.
+	ErrChainPinned = errcode.New(ErrCodeChainPinned, "active chain is set with a CLI flag",
+		"Remove the --"+config.FlagChainID.Name+" flag to switch chains at runtime")
+	// ErrChainInUse indicates that the active chain has running connections or services.
+	ErrChainInUse = errcode.New(ErrCodeChainInUse, "active chain is in use",
+		"Disconnect and stop all services before switching chains")
+)
+
+// Chain describes a blockchain the node can work with.
+type Chain struct {
+	metadata.ChainDefinition
+	Name string
+}
+
+type userConfig interface {
+	GetInt64(key string) int64
+	IsSetByCLI(key string) bool
+	SetUserOnRestart(key string, value interface{})
+	RemoveUserOnRestart(key string)
+	SaveUserConfig() error
+}
+
+// Switcher lists configured chains and switches the active one.
+// The node keeps working with the chain it was started with, a switch takes effect after the restart.
+type Switcher struct {
+	chains    []Chain
+	config    userConfig
+	publisher eventbus.Publisher
+	inUse     func() error
+	active    int64
+
+	mu      sync.Mutex
+	pending int64
+}
+
+// NewSwitcher creates a chain switcher for the given chain definitions.
+// The inUse callback returns an error while the active chain must not be switched.
+func NewSwitcher(definitions []metadata.ChainDefinition, cfg userConfig, publisher eventbus.Publisher, inUse func() error) *Switcher {
+	names := registry.Chains()
+	chains := make([]Chain, 0, len(definitions))
+	for _, d := range definitions {
+		chains = append(chains, Chain{ChainDefinition: d, Name: names[d.ChainID]})
+	}
+
+	return &Switcher{
+		chains:    chains,
+		config:    cfg,
+		publisher: publisher,
+		inUse:     inUse,
+		active:    cfg.GetInt64(config.FlagChainID.Name),
+	}
+}
+
+// Chains returns all configured chains.
+func (s *Switcher) Chains() []Chain {
+	return append([]Chain(nil), s.chains...)
+}
+
+// Active returns the chain the node is running with.
+func (s *Switcher) Active() Chain {
+	if chain, ok := s.chain(s.active); ok {
+		return chain
+	}
+	return Chain{ChainDefinition: metadata.ChainDefinition{ChainID: s.active}, Name: registry.Chains()[s.active]}
+}
+
+// Pending returns the chain which becomes active after the node restart, if a switch was requested.
+func (s *Switcher) Pending() (Chain, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pending == 0 {
+		return Chain{}, false
+	}
+	return s.chain(s.pending)
+}
+
+// Switch persists the given chain in user config to be used after the node restart.
+// It returns true if the node must be restarted for the chain to become active.
+// Switching back to the running chain cancels the pending switch.
+func (s *Switcher) Switch(chainID int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.chain(chainID); !ok {
+		return false, ErrUnknownChain
+	}
+
+	if chainID == s.pending {
+		return true, nil
+	}
+	if chainID == s.active && s.pending == 0 {
+		return false, nil
+	}
+	if s.config.IsSetByCLI(config.FlagChainID.Name) {
+		return false, ErrChainPinned
+	}
+	if chainID != s.active {
+		if err := s.inUse(); err != nil {
+			return false, ErrChainInUse.Wrap(err)
+		}
+	}
+
+	s.setOnRestart(chainID)
+	if err := s.config.SaveUserConfig(); err != nil {
+		s.setOnRestart(s.pending)
+		return false, err
+	}
+	s.pending = chainID
+	if chainID == s.active {
+		s.pending = 0
+		log.Info().Msgf("Pending switch of active chain %d cancelled", s.active)
+		return false, nil
+	}
+
+	log.Info().Msgf("Active chain will be switched from %d to %d after the node restart", s.active, chainID)
+	s.publisher.Publish(AppTopicActiveChain, AppEventActiveChain{Previous: s.active, Current: chainID})
+	return true, nil
+}
+
+// setOnRestart sets the chain to be used after the restart, zero or the running chain clears it.
+func (s *Switcher) setOnRestart(chainID int64) {
+	if chainID == 0 || chainID == s.active {
+		s.config.RemoveUserOnRestart(config.FlagChainID.Name)
+		return
+	}
+	s.config.SetUserOnRestart(config.FlagChainID.Name, chainID)
+}
+
+func (s *Switcher) chain(id int64) (Chain, bool) {
+	for _, c := range s.chains {
+		if c.ChainID == id {
+			return c, true
+		}
+	}
+	return Chain{}, false
+}
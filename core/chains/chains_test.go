@@ -0,0 +1,150 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chains
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/metadata"
+	"github.com/mysteriumnetwork/node/mocks"
+)
+
+type mockConfig struct {
+	user      map[string]interface{}
+	onRestart map[string]interface{}
+	cli       bool
+	saveErr   error
+}
+
+func (m *mockConfig) GetInt64(key string) int64 {
+	v, _ := m.user[key].(int64)
+	return v
+}
+
+func (m *mockConfig) IsSetByCLI(key string) bool {
+	return m.cli
+}
+
+func (m *mockConfig) SetUserOnRestart(key string, value interface{}) {
+	if m.onRestart == nil {
+		m.onRestart = make(map[string]interface{})
+	}
+	m.onRestart[key] = value
+}
+
+func (m *mockConfig) RemoveUserOnRestart(key string) {
+	delete(m.onRestart, key)
+}
+
+func (m *mockConfig) SaveUserConfig() error {
+	return m.saveErr
+}
+
+func newTestSwitcher(cfg *mockConfig, bus eventbus.Publisher, inUse error) *Switcher {
+	return NewSwitcher([]metadata.ChainDefinition{{ChainID: 5}, {ChainID: 80001}}, cfg, bus, func() error { return inUse })
+}
+
+func TestSwitcher_Switch(t *testing.T) {
+	cfg := &mockConfig{user: map[string]interface{}{config.FlagChainID.Name: int64(80001)}}
+	bus := mocks.NewEventBus()
+	switcher := newTestSwitcher(cfg, bus, nil)
+
+	assert.Equal(t, "Polygon Testnet Mumbai", switcher.Active().Name)
+
+	switched, err := switcher.Switch(5)
+	assert.NoError(t, err)
+	assert.True(t, switched)
+	assert.Equal(t, AppEventActiveChain{Previous: 80001, Current: 5}, bus.Pop())
+
+	// running node keeps the active chain until restart
+	assert.Equal(t, int64(80001), switcher.Active().ChainID)
+	assert.Equal(t, int64(80001), cfg.GetInt64(config.FlagChainID.Name))
+	assert.Equal(t, int64(5), cfg.onRestart[config.FlagChainID.Name])
+	pending, ok := switcher.Pending()
+	assert.True(t, ok)
+	assert.Equal(t, int64(5), pending.ChainID)
+
+	switched, err = switcher.Switch(5)
+	assert.NoError(t, err)
+	assert.True(t, switched)
+
+	// switching back to the running chain cancels the pending switch
+	switched, err = switcher.Switch(80001)
+	assert.NoError(t, err)
+	assert.False(t, switched)
+	assert.NotContains(t, cfg.onRestart, config.FlagChainID.Name)
+	_, ok = switcher.Pending()
+	assert.False(t, ok)
+}
+
+func TestSwitcher_SwitchRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		chainID int64
+		cfg     *mockConfig
+		inUse   error
+		wantErr error
+	}{
+		{
+			name:    "unknown chain",
+			chainID: 1,
+			cfg:     &mockConfig{user: map[string]interface{}{config.FlagChainID.Name: int64(80001)}},
+			wantErr: ErrUnknownChain,
+		},
+		{
+			name:    "pinned by CLI",
+			chainID: 5,
+			cfg:     &mockConfig{user: map[string]interface{}{config.FlagChainID.Name: int64(80001)}, cli: true},
+			wantErr: ErrChainPinned,
+		},
+		{
+			name:    "chain in use",
+			chainID: 5,
+			cfg:     &mockConfig{user: map[string]interface{}{config.FlagChainID.Name: int64(80001)}},
+			inUse:   errors.New("connection is active"),
+			wantErr: ErrChainInUse,
+		},
+		{
+			name:    "save failed",
+			chainID: 5,
+			cfg:     &mockConfig{user: map[string]interface{}{config.FlagChainID.Name: int64(80001)}, saveErr: errors.New("boom")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			switcher := newTestSwitcher(tt.cfg, mocks.NewEventBus(), tt.inUse)
+
+			switched, err := switcher.Switch(tt.chainID)
+			assert.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.False(t, switched)
+			assert.Equal(t, int64(80001), switcher.Active().ChainID)
+			_, ok := switcher.Pending()
+			assert.False(t, ok)
+			assert.NotContains(t, tt.cfg.onRestart, config.FlagChainID.Name)
+		})
+	}
+}
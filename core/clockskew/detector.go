@@ -0,0 +1,178 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package clockskew
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/utils/ntputil"
+)
+
+// AppTopicClockSkew is the topic to which dangerous clock skew changes are published.
+const AppTopicClockSkew = "clock_skew"
+
+// Event describes the measured clock skew.
+type Event struct {
+	// Offset is the difference between NTP time and the local clock.
+	Offset time.Duration `json:"offset"`
+	// Compensation is the offset applied to protocol timestamps.
+	Compensation time.Duration `json:"compensation"`
+	// Dangerous is true when the offset exceeds the danger threshold.
+	Dangerous bool `json:"dangerous"`
+}
+
+// Config configures the clock skew detector.
+type Config struct {
+	// Server is the NTP server address (host:port).
+	Server string
+	// Interval between measurements.
+	Interval time.Duration
+	// Tolerance is the maximum offset compensated in protocol timestamps.
+	Tolerance time.Duration
+	// DangerThreshold is the offset above which the skew event is raised.
+	DangerThreshold time.Duration
+}
+
+// Detector periodically measures local clock offset against NTP time.
+type Detector struct {
+	config    Config
+	publisher eventbus.Publisher
+	measure   func(ctx context.Context, server string) (time.Duration, error)
+
+	// compensation is the offset applied by Now, in nanoseconds.
+	compensation int64
+
+	mu        sync.Mutex
+	offset    time.Duration
+	dangerous bool
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewDetector creates a new clock skew detector.
+func NewDetector(publisher eventbus.Publisher, config Config) *Detector {
+	return &Detector{
+		config:    config,
+		publisher: publisher,
+		measure:   ntputil.Offset,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start measures the offset right away and keeps measuring it periodically.
+func (d *Detector) Start() {
+	go func() {
+		d.check()
+		ticker := time.NewTicker(d.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+				d.check()
+			}
+		}
+	}()
+}
+
+// Stop stops periodic measurements and resets the compensation.
+func (d *Detector) Stop() {
+	d.once.Do(func() {
+		close(d.stop)
+		atomic.StoreInt64(&d.compensation, 0)
+	})
+}
+
+// Now returns the current time adjusted by the measured offset, or local time if the detector is nil.
+// It should be used for timestamps in messages verified by other parties.
+func (d *Detector) Now() time.Time {
+	if d == nil {
+		return time.Now()
+	}
+	return time.Now().Add(time.Duration(atomic.LoadInt64(&d.compensation)))
+}
+
+// Offset returns the last measured offset of NTP time from the local clock.
+func (d *Detector) Offset() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.offset
+}
+
+func (d *Detector) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	offset, err := d.measure(ctx, d.config.Server)
+	if err != nil {
+		log.Debug().Err(err).Msgf("Could not measure clock offset using %s", d.config.Server)
+		return
+	}
+	d.update(offset)
+}
+
+func (d *Detector) update(offset time.Duration) {
+	comp := clamp(offset, d.config.Tolerance)
+	atomic.StoreInt64(&d.compensation, int64(comp))
+
+	d.mu.Lock()
+	d.offset = offset
+	dangerous := abs(offset) > d.config.DangerThreshold
+	changed := dangerous != d.dangerous
+	d.dangerous = dangerous
+	d.mu.Unlock()
+
+	if dangerous {
+		log.Warn().Msgf("System clock is off by %s, compensating %s. Enable time synchronization", offset.Round(time.Millisecond), comp.Round(time.Millisecond))
+	} else if offset != comp {
+		log.Info().Msgf("System clock is off by %s, compensating %s", offset.Round(time.Millisecond), comp.Round(time.Millisecond))
+	}
+
+	if changed {
+		d.publisher.Publish(AppTopicClockSkew, Event{
+			Offset:       offset,
+			Compensation: comp,
+			Dangerous:    dangerous,
+		})
+	}
+}
+
+func clamp(offset, limit time.Duration) time.Duration {
+	if offset > limit {
+		return limit
+	}
+	if offset < -limit {
+		return -limit
+	}
+	return offset
+}
+
+func abs(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package clockskew
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPublisher struct {
+	events []Event
+}
+
+func (p *mockPublisher) Publish(topic string, data interface{}) {
+	if topic == AppTopicClockSkew {
+		p.events = append(p.events, data.(Event))
+	}
+}
+
+func TestDetector_CompensatesWithinTolerance(t *testing.T) {
+	bus := &mockPublisher{}
+	d := NewDetector(bus, Config{Tolerance: 30 * time.Second, DangerThreshold: time.Minute})
+	defer d.Stop()
+
+	d.update(10 * time.Second)
+	assert.Equal(t, 10*time.Second, d.Offset())
+	assert.InDelta(t, 10*time.Second, time.Until(d.Now()), float64(time.Second))
+	assert.Empty(t, bus.events)
+
+	d.update(-45 * time.Second)
+	assert.InDelta(t, -30*time.Second, time.Until(d.Now()), float64(time.Second))
+	assert.Empty(t, bus.events)
+}
+
+func TestDetector_PublishesDangerousSkew(t *testing.T) {
+	bus := &mockPublisher{}
+	d := NewDetector(bus, Config{Tolerance: 30 * time.Second, DangerThreshold: time.Minute})
+	defer d.Stop()
+
+	d.update(2 * time.Minute)
+	d.update(3 * time.Minute)
+	d.update(time.Second)
+
+	assert.Equal(t, []Event{
+		{Offset: 2 * time.Minute, Compensation: 30 * time.Second, Dangerous: true},
+		{Offset: time.Second, Compensation: time.Second, Dangerous: false},
+	}, bus.events)
+}
+
+func TestDetector_KeepsOffsetWhenMeasurementFails(t *testing.T) {
+	d := NewDetector(&mockPublisher{}, Config{Interval: time.Hour, Tolerance: 30 * time.Second, DangerThreshold: time.Minute})
+	d.measure = func(ctx context.Context, server string) (time.Duration, error) {
+		return 5 * time.Second, nil
+	}
+	d.check()
+	assert.Equal(t, 5*time.Second, d.Offset())
+
+	d.measure = func(ctx context.Context, server string) (time.Duration, error) {
+		return 0, errors.New("timeout")
+	}
+	d.check()
+	assert.Equal(t, 5*time.Second, d.Offset())
+
+	d.Stop()
+	assert.InDelta(t, 0, time.Until(d.Now()), float64(time.Second))
+
+	var disabled *Detector
+	assert.InDelta(t, 0, time.Until(disabled.Now()), float64(time.Second))
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autoconnect
+
+import "time"
+
+const (
+	// AppTopicProfileUsed represents the topic a successful connect request profile is published on.
+	AppTopicProfileUsed = "Connection profile used"
+	// AppTopicNetwork represents the topic uplink network changes are published on.
+	AppTopicNetwork = "Network"
+	// AppTopicAutoConnect represents the topic auto-connect attempts are published on.
+	AppTopicAutoConnect = "Auto-connect"
+)
+
+// AppEventProfileUsed is published once a connection is created from the profile.
+type AppEventProfileUsed struct {
+	Profile Profile
+}
+
+// AppEventNetwork is published when uplink network changes.
+type AppEventNetwork struct {
+	Previous Network
+	Current  Network
+}
+
+// Outcome of the auto-connect attempt.
+type Outcome string
+
+const (
+	// OutcomeConnected means that auto-connect created a connection.
+	OutcomeConnected Outcome = "connected"
+	// OutcomeFailed means that auto-connect failed to create a connection.
+	OutcomeFailed Outcome = "failed"
+	// OutcomeSkipped means that auto-connect was triggered, but not attempted.
+	OutcomeSkipped Outcome = "skipped"
+	// OutcomeDisconnected means that the connection was closed on a trusted network.
+	OutcomeDisconnected Outcome = "disconnected"
+)
+
+// AppEventAutoConnect is published for every triggered auto-connect and disconnect on a trusted network.
+type AppEventAutoConnect struct {
+	Trigger    Trigger
+	Outcome    Outcome
+	Reason     string
+	ConsumerID string
+	At         time.Time
+}
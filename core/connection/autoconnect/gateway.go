@@ -0,0 +1,150 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autoconnect
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// gatewayInfo describes the default route of the uplink network.
+type gatewayInfo struct {
+	IP        string
+	MAC       string
+	LocalIP   string
+	Interface string
+}
+
+var macPattern = regexp.MustCompile(`(?i)\b[0-9a-f]{1,2}([:-][0-9a-f]{1,2}){5}\b`)
+
+// parseProcRoute parses /proc/net/route, returning the interface and gateway of the default route.
+func parseProcRoute(content string) (iface, gateway string, ok bool) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 || fields[1] != "00000000" || fields[7] != "00000000" {
+			continue
+		}
+		raw, err := hex.DecodeString(fields[2])
+		if err != nil || len(raw) != 4 {
+			continue
+		}
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, binary.LittleEndian.Uint32(raw))
+		return fields[0], ip.String(), true
+	}
+	return "", "", false
+}
+
+// parseProcARP parses /proc/net/arp, returning hardware address of the given IP.
+func parseProcARP(content, ip string) string {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 4 && fields[0] == ip {
+			return normalizeMAC(fields[3])
+		}
+	}
+	return ""
+}
+
+// parseRouteGet parses `route -n get default` output.
+func parseRouteGet(output string) (iface, gateway string, ok bool) {
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := cutField(line)
+		if !found {
+			continue
+		}
+		switch key {
+		case "gateway":
+			gateway = value
+		case "interface":
+			iface = value
+		}
+	}
+	return iface, gateway, gateway != ""
+}
+
+// parseRoutePrint parses `route print -4 0.0.0.0` output, returning gateway and interface address of the default route
+// with the lowest metric.
+func parseRoutePrint(output string) (gateway, localIP string, ok bool) {
+	best := -1
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 5 || fields[0] != "0.0.0.0" || fields[1] != "0.0.0.0" || net.ParseIP(fields[2]) == nil {
+			continue
+		}
+		metric, err := strconv.Atoi(fields[4])
+		if err != nil || (best >= 0 && metric >= best) {
+			continue
+		}
+		best, gateway, localIP = metric, fields[2], fields[3]
+	}
+	return gateway, localIP, best >= 0
+}
+
+// parseARP finds hardware address in `arp` command output.
+func parseARP(output string) string {
+	return normalizeMAC(macPattern.FindString(output))
+}
+
+// normalizeMAC formats hardware address as lower case colon separated octets, empty if it is not valid.
+func normalizeMAC(mac string) string {
+	octets := strings.FieldsFunc(mac, func(r rune) bool { return r == ':' || r == '-' })
+	for i, octet := range octets {
+		if len(octet) == 1 {
+			octets[i] = "0" + octet
+		}
+	}
+	hw, err := net.ParseMAC(strings.Join(octets, ":"))
+	if err != nil || len(hw) != 6 {
+		return ""
+	}
+	return hw.String()
+}
+
+func interfaceIPv4(name string) string {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return ""
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return ""
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+			return ipNet.IP.String()
+		}
+	}
+	return ""
+}
+
+func commandOutput(args ...string) string {
+	out, err := exec.Command(args[0], args[1:]...).Output()
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
@@ -0,0 +1,37 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autoconnect
+
+import "os"
+
+func detectGateway() (gatewayInfo, bool) {
+	routes, err := os.ReadFile("/proc/net/route")
+	if err != nil {
+		return gatewayInfo{}, false
+	}
+	iface, gateway, ok := parseProcRoute(string(routes))
+	if !ok {
+		return gatewayInfo{}, false
+	}
+
+	info := gatewayInfo{IP: gateway, LocalIP: interfaceIPv4(iface), Interface: iface}
+	if arp, err := os.ReadFile("/proc/net/arp"); err == nil {
+		info.MAC = parseProcARP(string(arp), gateway)
+	}
+	return info, true
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autoconnect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProcRoute(t *testing.T) {
+	iface, gateway, ok := parseProcRoute(`Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+wg0	00000000	00000000	0001	0	0	0	00000080	0	0	0
+eth0	000200C0	00000000	0001	0	0	0	00FFFFFF	0	0	0
+eth0	00000000	010200C0	0003	0	0	0	00000000	0	0	0
+`)
+	assert.True(t, ok)
+	assert.Equal(t, "eth0", iface)
+	assert.Equal(t, "192.0.2.1", gateway)
+
+	_, _, ok = parseProcRoute("Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT\n")
+	assert.False(t, ok)
+}
+
+func TestParseProcARP(t *testing.T) {
+	content := `IP address       HW type     Flags       HW address            Mask     Device
+192.0.2.1        0x1         0x2         02:FC:00:00:00:05     *        eth0
+`
+	assert.Equal(t, "02:fc:00:00:00:05", parseProcARP(content, "192.0.2.1"))
+	assert.Equal(t, "", parseProcARP(content, "192.0.2.2"))
+}
+
+func TestParseRouteGet(t *testing.T) {
+	iface, gateway, ok := parseRouteGet(`   route to: default
+destination: default
+       mask: default
+    gateway: 192.168.1.1
+  interface: en0
+      flags: <UP,GATEWAY,DONE,STATIC,PRCLONING>
+`)
+	assert.True(t, ok)
+	assert.Equal(t, "en0", iface)
+	assert.Equal(t, "192.168.1.1", gateway)
+}
+
+func TestParseRoutePrint(t *testing.T) {
+	gateway, localIP, ok := parseRoutePrint(`IPv4 Route Table
+===========================================================================
+Active Routes:
+Network Destination        Netmask          Gateway       Interface  Metric
+          0.0.0.0          0.0.0.0      10.10.0.1      10.10.0.20     50
+          0.0.0.0          0.0.0.0    192.168.1.1   192.168.1.10     25
+===========================================================================
+Persistent Routes:
+  None
+`)
+	assert.True(t, ok)
+	assert.Equal(t, "192.168.1.1", gateway)
+	assert.Equal(t, "192.168.1.10", localIP)
+}
+
+func TestParseARP(t *testing.T) {
+	assert.Equal(t, "0a:bb:cc:0d:ee:ff", parseARP("? (192.168.1.1) at a:bb:cc:d:ee:ff on en0 ifscope [ethernet]\n"))
+	assert.Equal(t, "aa:bb:cc:dd:ee:ff", parseARP(`
+Interface: 192.168.1.10 --- 0x5
+  Internet Address      Physical Address      Type
+  192.168.1.1           aa-bb-cc-dd-ee-ff     dynamic
+`))
+	assert.Equal(t, "", parseARP("192.168.1.1 (192.168.1.1) -- no entry\n"))
+}
@@ -0,0 +1,322 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autoconnect
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
+// MeteredPolicy defines how the consumer behaves on a metered uplink.
+type MeteredPolicy string
+
+const (
+	// MeteredIgnore does not check if the uplink is metered.
+	MeteredIgnore MeteredPolicy = "ignore"
+	// MeteredWarn reports the connection on a metered uplink.
+	MeteredWarn MeteredPolicy = "warn"
+	// MeteredCap limits uplink throughput while connected on a metered uplink.
+	// The limit is applied to the physical uplink interface, so it throttles all host traffic, not only the tunnel.
+	MeteredCap MeteredPolicy = "cap"
+	// MeteredRefuse never auto-connects on a metered uplink.
+	MeteredRefuse MeteredPolicy = "refuse"
+)
+
+// ParseMeteredPolicy parses metered uplink policy name.
+func ParseMeteredPolicy(name string) (MeteredPolicy, error) {
+	switch policy := MeteredPolicy(strings.ToLower(strings.TrimSpace(name))); policy {
+	case MeteredIgnore, MeteredWarn, MeteredCap, MeteredRefuse:
+		return policy, nil
+	case "":
+		return MeteredIgnore, nil
+	default:
+		return "", fmt.Errorf("unknown metered uplink policy %q", name)
+	}
+}
+
+func (p MeteredPolicy) checked() bool {
+	return p != "" && p != MeteredIgnore
+}
+
+// parseNmcliMetered parses `nmcli -t -f GENERAL.METERED device show <iface>` output.
+// NetworkManager reports "yes" or "no", optionally followed by " (guessed)".
+func parseNmcliMetered(output string) bool {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := splitNmcli(scanner.Text())
+		if len(fields) == 2 && fields[0] == "GENERAL.METERED" {
+			return strings.HasPrefix(strings.TrimSpace(fields[1]), "yes")
+		}
+	}
+	return false
+}
+
+// parseNetshCost parses `netsh wlan show profile name=<ssid>` output.
+// Fixed and Variable cost profiles are metered, Unrestricted and Unknown are not.
+func parseNetshCost(output string) bool {
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := cutField(line)
+		if found && key == "Cost" {
+			return value == "Fixed" || value == "Variable"
+		}
+	}
+	return false
+}
+
+// parseQdiscs parses `tc qdisc show dev <iface>` output and returns the root queueing discipline
+// configured by the user, in the form accepted by `tc qdisc replace dev <iface> root`.
+// Kernel default root disciplines have the 0: handle and need not be restored.
+// Ingress disciplines can not be saved together with their filters, so interfaces having them are not capped.
+func parseQdiscs(output string) (string, error) {
+	var root string
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[0] != "qdisc" {
+			continue
+		}
+		kind, handle := fields[1], fields[2]
+		if kind == "ingress" || kind == "clsact" {
+			return "", fmt.Errorf("%s queueing discipline is already configured", kind)
+		}
+		if fields[3] != "root" || handle == "0:" {
+			continue
+		}
+
+		spec := []string{"handle", handle, kind}
+		params := fields[4:]
+		for i := 0; i < len(params); i++ {
+			if params[i] == "refcnt" {
+				i++
+				continue
+			}
+			spec = append(spec, params[i])
+		}
+		root = strings.Join(spec, " ")
+	}
+	return root, nil
+}
+
+// throttler limits throughput of a network interface in kilobytes per second.
+type throttler interface {
+	LimitDownlink(interfaceName string, limitKbps int) error
+	LimitUplink(interfaceName string, limitKbps int) error
+	Clear(interfaceName string)
+	// Save returns the root queueing discipline configured on the interface, empty if it is the kernel default.
+	Save(interfaceName string) (qdisc string, err error)
+	// Restore puts the saved root queueing discipline back once the limit is cleared.
+	Restore(interfaceName, qdisc string) error
+}
+
+var errThrottleUnsupported = errors.New("throughput cap is not supported on this platform")
+
+type noopThrottler struct{}
+
+func (noopThrottler) LimitDownlink(string, int) error { return errThrottleUnsupported }
+
+func (noopThrottler) LimitUplink(string, int) error { return errThrottleUnsupported }
+
+func (noopThrottler) Clear(string) {}
+
+func (noopThrottler) Save(string) (string, error) { return "", errThrottleUnsupported }
+
+func (noopThrottler) Restore(string, string) error { return nil }
+
+const meteredCapKey = "metered_cap"
+
+// meteredCap is the throughput cap persisted while applied, so that it is removed after a crash.
+type meteredCap struct {
+	Interface string
+	Qdisc     string
+}
+
+// MeteredStatus describes the metered uplink state of the consumer.
+type MeteredStatus struct {
+	Metered bool
+	Policy  MeteredPolicy
+	// Capped is true while uplink throughput is limited.
+	Capped bool
+}
+
+// MeteredGuard applies metered uplink policy to the consumer connection.
+// With the cap policy the default route interface is limited while connected. The tunnel traffic is
+// limited with it, but so is every other connection of the host, which is why the policy needs an explicit opt-in.
+type MeteredGuard struct {
+	networks  networkSource
+	storage   profileStorage
+	throttler throttler
+	policy    MeteredPolicy
+	capKbps   int
+
+	mu        sync.Mutex
+	connected bool
+	capped    meteredCap
+}
+
+// NewMeteredGuard creates metered uplink guard, capKbps is used with the cap policy only.
+func NewMeteredGuard(networks networkSource, storage profileStorage, policy MeteredPolicy, capKbps int) *MeteredGuard {
+	return &MeteredGuard{
+		networks:  networks,
+		storage:   storage,
+		throttler: newThrottler(),
+		policy:    policy,
+		capKbps:   capKbps,
+	}
+}
+
+// Subscribe removes the cap left by a previous run and subscribes to connection state and network events.
+func (g *MeteredGuard) Subscribe(bus eventbus.Subscriber) error {
+	var stale meteredCap
+	if err := g.storage.GetValue(bucketName, meteredCapKey, &stale); err == nil && stale.Interface != "" {
+		log.Warn().Msgf("Removing metered uplink %s throughput cap left by previous run", stale.Interface)
+		g.clear(stale)
+	}
+
+	if !g.policy.checked() {
+		return nil
+	}
+	if err := bus.SubscribeAsync(connectionstate.AppTopicConnectionState, g.handleConnectionState); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(AppTopicNetwork, g.handleNetworkEvent)
+}
+
+// Status returns the metered uplink state.
+func (g *MeteredGuard) Status() MeteredStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	status := MeteredStatus{Policy: g.policy, Capped: g.capped.Interface != ""}
+	if g.policy.checked() {
+		status.Metered = g.networks.Current().Metered
+	}
+	return status
+}
+
+// Stop removes the throughput cap.
+func (g *MeteredGuard) Stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.connected = false
+	g.release()
+}
+
+func (g *MeteredGuard) handleConnectionState(e connectionstate.AppEventConnectionState) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch e.State {
+	case connectionstate.Connected:
+		g.connected = true
+		g.apply(g.networks.Current())
+	case connectionstate.NotConnected:
+		g.connected = false
+		g.release()
+	}
+}
+
+func (g *MeteredGuard) handleNetworkEvent(e AppEventNetwork) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.connected || (e.Current.Metered == e.Previous.Metered && e.Current.Interface == e.Previous.Interface) {
+		return
+	}
+	g.release()
+	g.apply(e.Current)
+}
+
+func (g *MeteredGuard) apply(network Network) {
+	if !network.Metered {
+		return
+	}
+
+	switch g.policy {
+	case MeteredWarn, MeteredRefuse:
+		log.Warn().Msg("Consumer is connected on a metered uplink")
+	case MeteredCap:
+		if g.capped.Interface != "" {
+			return
+		}
+		if network.Interface == "" {
+			log.Warn().Msg("Could not cap metered uplink throughput: default route interface is unknown")
+			return
+		}
+		qdisc, err := g.throttler.Save(network.Interface)
+		if err != nil {
+			log.Warn().Err(err).Msg("Could not cap metered uplink throughput")
+			return
+		}
+
+		capped := meteredCap{Interface: network.Interface, Qdisc: qdisc}
+		// Cap is persisted before it is applied, so that a crash never leaves the uplink throttled.
+		if err := g.storage.SetValue(bucketName, meteredCapKey, capped); err != nil {
+			log.Warn().Err(err).Msg("Could not cap metered uplink throughput")
+			return
+		}
+		if err := g.limit(capped); err != nil {
+			log.Warn().Err(err).Msg("Could not cap metered uplink throughput")
+			g.clear(capped)
+			return
+		}
+		log.Info().Msgf("Capped metered uplink %s throughput to %d Kbytes/s", network.Interface, g.capKbps)
+		g.capped = capped
+	}
+}
+
+func (g *MeteredGuard) limit(capped meteredCap) error {
+	// Root discipline of the user is replaced, it is restored once the cap is cleared.
+	if capped.Qdisc != "" {
+		g.throttler.Clear(capped.Interface)
+	}
+	if err := g.throttler.LimitDownlink(capped.Interface, g.capKbps); err != nil {
+		return err
+	}
+	return g.throttler.LimitUplink(capped.Interface, g.capKbps)
+}
+
+func (g *MeteredGuard) release() {
+	if g.capped.Interface == "" {
+		return
+	}
+	g.clear(g.capped)
+	log.Info().Msgf("Removed metered uplink %s throughput cap", g.capped.Interface)
+	g.capped = meteredCap{}
+}
+
+// clear removes the cap restoring the saved root queueing discipline, and forgets the persisted cap.
+func (g *MeteredGuard) clear(capped meteredCap) {
+	g.throttler.Clear(capped.Interface)
+	if capped.Qdisc != "" {
+		if err := g.throttler.Restore(capped.Interface, capped.Qdisc); err != nil {
+			log.Warn().Err(err).Msgf("Could not restore queueing discipline %q of %s", capped.Qdisc, capped.Interface)
+		}
+	}
+	if err := g.storage.SetValue(bucketName, meteredCapKey, meteredCap{}); err != nil {
+		log.Warn().Err(err).Msg("Could not forget metered uplink throughput cap")
+	}
+}
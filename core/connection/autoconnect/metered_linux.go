@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autoconnect
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/mysteriumnetwork/go-wondershaper/wondershaper"
+	"github.com/rs/zerolog/log"
+)
+
+func detectMetered(network Network) bool {
+	if network.Interface == "" {
+		return false
+	}
+	return parseNmcliMetered(commandOutput("nmcli", "-t", "-f", "GENERAL.METERED", "device", "show", network.Interface))
+}
+
+// tcThrottler limits throughput with wondershaper, saving the root queueing discipline it replaces.
+type tcThrottler struct {
+	*wondershaper.Shaper
+}
+
+func newThrottler() throttler {
+	ws := wondershaper.New()
+	ws.Stdout = log.Logger
+	ws.Stderr = log.Logger
+	return tcThrottler{Shaper: ws}
+}
+
+func (t tcThrottler) Save(interfaceName string) (string, error) {
+	out, err := exec.Command("tc", "qdisc", "show", "dev", interfaceName).Output()
+	if err != nil {
+		return "", fmt.Errorf("could not list queueing disciplines of %s: %w", interfaceName, err)
+	}
+	return parseQdiscs(string(out))
+}
+
+func (t tcThrottler) Restore(interfaceName, qdisc string) error {
+	args := append([]string{"tc", "qdisc", "replace", "dev", interfaceName, "root"}, strings.Fields(qdisc)...)
+	cmd := exec.Command("sudo", args...)
+	cmd.Stdout = t.Stdout
+	cmd.Stderr = t.Stderr
+	return cmd.Run()
+}
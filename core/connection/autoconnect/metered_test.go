@@ -0,0 +1,235 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autoconnect
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
+func TestParseMeteredPolicy(t *testing.T) {
+	policy, err := ParseMeteredPolicy(" Cap ")
+	require.NoError(t, err)
+	assert.Equal(t, MeteredCap, policy)
+
+	policy, err = ParseMeteredPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, MeteredIgnore, policy)
+
+	_, err = ParseMeteredPolicy("block")
+	assert.Error(t, err)
+}
+
+func TestParseNmcliMetered(t *testing.T) {
+	assert.True(t, parseNmcliMetered("GENERAL.METERED:yes\n"))
+	assert.True(t, parseNmcliMetered("GENERAL.METERED:yes (guessed)\n"))
+	assert.False(t, parseNmcliMetered("GENERAL.METERED:no (guessed)\n"))
+	assert.False(t, parseNmcliMetered("GENERAL.METERED:unknown\n"))
+	assert.False(t, parseNmcliMetered(""))
+}
+
+func TestParseNetshCost(t *testing.T) {
+	assert.True(t, parseNetshCost(`
+Cost settings
+-------------
+    Cost                   : Fixed
+    Congested              : No
+`))
+	assert.True(t, parseNetshCost("    Cost                   : Variable\n"))
+	assert.False(t, parseNetshCost("    Cost                   : Unrestricted\n"))
+	assert.False(t, parseNetshCost("Profile Home is not found on the system.\n"))
+}
+
+func TestPolicy_TriggerSkipsMeteredNetwork(t *testing.T) {
+	manager := &mockManager{state: connectionstate.NotConnected}
+	policy, publisher := newTestPolicy(t, manager, Config{OnStart: true, Metered: MeteredRefuse})
+	policy.networks = &mockNetworks{network: Network{Online: true, Interface: "wwan0", Metered: true}}
+	policy.handleProfileUsed(AppEventProfileUsed{Profile: testProfile})
+
+	policy.Trigger(TriggerNodeStart)
+
+	assert.Zero(t, manager.connects)
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, OutcomeSkipped, publisher.events[0].Outcome)
+	assert.Equal(t, "metered network", publisher.events[0].Reason)
+
+	policy.config.Metered = MeteredWarn
+	policy.Trigger(TriggerNodeStart)
+	assert.Equal(t, 1, manager.connects)
+}
+
+func TestParseQdiscs(t *testing.T) {
+	qdisc, err := parseQdiscs("qdisc fq_codel 0: root refcnt 2 limit 10240p flows 1024 quantum 1514 target 5ms interval 100ms memory_limit 32Mb ecn drop_batch 64\n")
+	assert.NoError(t, err)
+	assert.Empty(t, qdisc, "kernel default discipline need not be restored")
+
+	qdisc, err = parseQdiscs("qdisc tbf 8001: root refcnt 2 rate 1Mbit burst 32Kb lat 400ms\nqdisc pfifo 10: parent 8001:1 limit 1000p\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "handle 8001: tbf rate 1Mbit burst 32Kb lat 400ms", qdisc)
+
+	_, err = parseQdiscs("qdisc noqueue 0: root refcnt 2\nqdisc ingress ffff: parent ffff:fff1 ----------------\n")
+	assert.Error(t, err)
+}
+
+type mockThrottler struct {
+	err      error
+	saveErr  error
+	qdisc    string
+	limited  map[string]int
+	cleared  []string
+	restored map[string]string
+}
+
+func (m *mockThrottler) LimitDownlink(iface string, kbps int) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.limited[iface] = kbps
+	return nil
+}
+
+func (m *mockThrottler) LimitUplink(iface string, kbps int) error {
+	return m.err
+}
+
+func (m *mockThrottler) Clear(iface string) {
+	delete(m.limited, iface)
+	m.cleared = append(m.cleared, iface)
+}
+
+func (m *mockThrottler) Save(iface string) (string, error) {
+	return m.qdisc, m.saveErr
+}
+
+func (m *mockThrottler) Restore(iface, qdisc string) error {
+	m.restored[iface] = qdisc
+	return nil
+}
+
+func newTestGuard(t *testing.T, network Network, policy MeteredPolicy) (*MeteredGuard, *mockNetworks, *mockThrottler) {
+	bolt, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { bolt.Close() })
+
+	networks := &mockNetworks{network: network}
+	throttler := &mockThrottler{limited: map[string]int{}, restored: map[string]string{}}
+	guard := NewMeteredGuard(networks, bolt, policy, 500)
+	guard.throttler = throttler
+	return guard, networks, throttler
+}
+
+func TestMeteredGuard_CapsWhileConnected(t *testing.T) {
+	mobile := Network{Online: true, Interface: "wwan0", Metered: true}
+	guard, _, throttler := newTestGuard(t, mobile, MeteredCap)
+
+	guard.handleConnectionState(connectionstate.AppEventConnectionState{State: connectionstate.Connected})
+	assert.Equal(t, map[string]int{"wwan0": 500}, throttler.limited)
+	assert.Equal(t, MeteredStatus{Metered: true, Policy: MeteredCap, Capped: true}, guard.Status())
+
+	guard.handleConnectionState(connectionstate.AppEventConnectionState{State: connectionstate.NotConnected})
+	assert.Empty(t, throttler.limited)
+	assert.False(t, guard.Status().Capped)
+}
+
+func TestMeteredGuard_FollowsNetworkChanges(t *testing.T) {
+	home := Network{Online: true, Interface: "wlan0"}
+	mobile := Network{Online: true, Interface: "wwan0", Metered: true}
+	guard, networks, throttler := newTestGuard(t, home, MeteredCap)
+
+	guard.handleConnectionState(connectionstate.AppEventConnectionState{State: connectionstate.Connected})
+	assert.Empty(t, throttler.limited)
+
+	networks.network = mobile
+	guard.handleNetworkEvent(AppEventNetwork{Previous: home, Current: mobile})
+	assert.Equal(t, map[string]int{"wwan0": 500}, throttler.limited)
+
+	networks.network = home
+	guard.handleNetworkEvent(AppEventNetwork{Previous: mobile, Current: home})
+	assert.Empty(t, throttler.limited)
+	assert.Equal(t, MeteredStatus{Policy: MeteredCap}, guard.Status())
+}
+
+func TestMeteredGuard_ReportsFailedCap(t *testing.T) {
+	guard, _, throttler := newTestGuard(t, Network{Online: true, Interface: "wwan0", Metered: true}, MeteredCap)
+	throttler.err = errors.New("tc failed")
+
+	guard.handleConnectionState(connectionstate.AppEventConnectionState{State: connectionstate.Connected})
+
+	assert.Empty(t, throttler.limited)
+	assert.Equal(t, MeteredStatus{Metered: true, Policy: MeteredCap}, guard.Status())
+}
+
+func TestMeteredGuard_IgnoreDoesNotReportMetered(t *testing.T) {
+	guard, _, throttler := newTestGuard(t, Network{Online: true, Interface: "wwan0", Metered: true}, MeteredIgnore)
+
+	guard.handleConnectionState(connectionstate.AppEventConnectionState{State: connectionstate.Connected})
+
+	assert.Empty(t, throttler.limited)
+	assert.Equal(t, MeteredStatus{Policy: MeteredIgnore}, guard.Status())
+}
+
+func TestMeteredGuard_RestoresSavedQdisc(t *testing.T) {
+	guard, _, throttler := newTestGuard(t, Network{Online: true, Interface: "wwan0", Metered: true}, MeteredCap)
+	throttler.qdisc = "handle 8001: tbf rate 1Mbit burst 32Kb lat 400ms"
+
+	guard.handleConnectionState(connectionstate.AppEventConnectionState{State: connectionstate.Connected})
+	assert.Equal(t, map[string]int{"wwan0": 500}, throttler.limited)
+
+	guard.handleConnectionState(connectionstate.AppEventConnectionState{State: connectionstate.NotConnected})
+	assert.Equal(t, map[string]string{"wwan0": throttler.qdisc}, throttler.restored)
+}
+
+func TestMeteredGuard_DoesNotClearUnsavedQdisc(t *testing.T) {
+	guard, _, throttler := newTestGuard(t, Network{Online: true, Interface: "wwan0", Metered: true}, MeteredCap)
+	throttler.saveErr = errors.New("ingress queueing discipline is already configured")
+
+	guard.handleConnectionState(connectionstate.AppEventConnectionState{State: connectionstate.Connected})
+
+	assert.Empty(t, throttler.limited)
+	assert.Empty(t, throttler.cleared)
+	assert.False(t, guard.Status().Capped)
+}
+
+func TestMeteredGuard_ClearsCapLeftByCrash(t *testing.T) {
+	guard, _, throttler := newTestGuard(t, Network{Online: true, Interface: "wwan0", Metered: true}, MeteredCap)
+	throttler.qdisc = "handle 8001: tbf rate 1Mbit burst 32Kb lat 400ms"
+	guard.handleConnectionState(connectionstate.AppEventConnectionState{State: connectionstate.Connected})
+	require.True(t, guard.Status().Capped)
+
+	// Node restarts without removing the cap, with the metered policy turned off.
+	restarted := NewMeteredGuard(guard.networks, guard.storage, MeteredIgnore, 500)
+	restartedThrottler := &mockThrottler{limited: map[string]int{}, restored: map[string]string{}}
+	restarted.throttler = restartedThrottler
+	require.NoError(t, restarted.Subscribe(eventbus.New()))
+
+	assert.Equal(t, []string{"wwan0"}, restartedThrottler.cleared)
+	assert.Equal(t, map[string]string{"wwan0": throttler.qdisc}, restartedThrottler.restored)
+
+	again := NewMeteredGuard(guard.networks, guard.storage, MeteredIgnore, 500)
+	againThrottler := &mockThrottler{limited: map[string]int{}, restored: map[string]string{}}
+	again.throttler = againThrottler
+	require.NoError(t, again.Subscribe(eventbus.New()))
+	assert.Empty(t, againThrottler.cleared, "cap must be cleared once")
+}
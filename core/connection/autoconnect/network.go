@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autoconnect
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
+// onlineProbeAddress is dialed over UDP to check for a route to the internet, no packets are sent.
+const onlineProbeAddress = "1.1.1.1:53"
+
+// Network describes the uplink network the node is on.
+type Network struct {
+	Online bool
+	// SSID of the Wi-Fi network, empty if not on Wi-Fi or it is not detectable.
+	SSID string
+	// Secured is true if the Wi-Fi network requires authentication.
+	Secured bool
+	// Gateway of the default route, empty if it is not detectable.
+	Gateway    string
+	GatewayMAC string
+	// LocalIP is the node address on the interface of the default route.
+	LocalIP string
+	// Interface of the default route, empty if it is not detectable.
+	Interface string
+	// Metered is true if the OS reports the uplink as metered, false if it is not or can not tell.
+	Metered bool
+}
+
+// Untrusted reports whether the node is on an open Wi-Fi network.
+func (n Network) Untrusted() bool {
+	return n.Online && n.SSID != "" && !n.Secured
+}
+
+// Watcher periodically probes the uplink network and publishes its changes.
+type Watcher struct {
+	publisher eventbus.Publisher
+	interval  time.Duration
+	probe     func() Network
+
+	mu      sync.Mutex
+	current Network
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewWatcher creates uplink network watcher.
+func NewWatcher(publisher eventbus.Publisher, interval time.Duration) *Watcher {
+	return &Watcher{
+		publisher: publisher,
+		interval:  interval,
+		probe:     probeNetwork,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Current returns the last probed network.
+func (w *Watcher) Current() Network {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.current
+}
+
+// Start probes the network until stopped. The first probe only sets the current network.
+func (w *Watcher) Start() {
+	w.mu.Lock()
+	w.current = w.probe()
+	w.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				w.Check()
+			}
+		}
+	}()
+}
+
+// Stop stops probing the network.
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+}
+
+// Check probes the network and publishes a change if there is one.
+func (w *Watcher) Check() {
+	network := w.probe()
+
+	w.mu.Lock()
+	previous := w.current
+	w.current = network
+	w.mu.Unlock()
+
+	if network != previous {
+		w.publisher.Publish(AppTopicNetwork, AppEventNetwork{Previous: previous, Current: network})
+	}
+}
+
+func probeNetwork() Network {
+	conn, err := net.Dial("udp", onlineProbeAddress)
+	if err != nil {
+		return Network{}
+	}
+	conn.Close()
+
+	network := Network{Online: true}
+	if ssid, secured, ok := newWiFiDetector().detect(); ok {
+		network.SSID, network.Secured = ssid, secured
+	}
+	if gateway, ok := detectGateway(); ok {
+		network.Gateway, network.GatewayMAC, network.LocalIP = gateway.IP, gateway.MAC, gateway.LocalIP
+		network.Interface = gateway.Interface
+	}
+	network.Metered = detectMetered(network)
+	return network
+}
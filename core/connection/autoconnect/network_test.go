@@ -0,0 +1,57 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autoconnect
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
+func TestNetwork_Untrusted(t *testing.T) {
+	assert.True(t, Network{Online: true, SSID: "Cafe"}.Untrusted())
+	assert.False(t, Network{Online: true, SSID: "Home", Secured: true}.Untrusted())
+	assert.False(t, Network{Online: true}.Untrusted())
+	assert.False(t, Network{SSID: "Cafe"}.Untrusted())
+}
+
+func TestWatcher_PublishesChanges(t *testing.T) {
+	bus := eventbus.New()
+	var events []AppEventNetwork
+	assert.NoError(t, bus.Subscribe(AppTopicNetwork, func(e AppEventNetwork) {
+		events = append(events, e)
+	}))
+
+	network := Network{}
+	w := NewWatcher(bus, time.Hour)
+	w.probe = func() Network { return network }
+	w.Start()
+	defer w.Stop()
+
+	w.Check()
+	assert.Empty(t, events)
+
+	network = Network{Online: true, SSID: "Cafe"}
+	w.Check()
+	w.Check()
+	assert.Equal(t, []AppEventNetwork{{Previous: Network{}, Current: network}}, events)
+	assert.Equal(t, network, w.Current())
+}
@@ -0,0 +1,250 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autoconnect
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	nodevent "github.com/mysteriumnetwork/node/core/node/event"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+const (
+	bucketName = "autoconnect"
+	profileKey = "profile"
+)
+
+// Trigger is an event auto-connect reacts to.
+type Trigger string
+
+const (
+	// TriggerNodeStart is triggered once the node is started.
+	TriggerNodeStart Trigger = "node_start"
+	// TriggerNetworkRestored is triggered when internet connectivity returns.
+	TriggerNetworkRestored Trigger = "network_restored"
+	// TriggerUntrustedWiFi is triggered when the node joins an open Wi-Fi network.
+	TriggerUntrustedWiFi Trigger = "untrusted_wifi"
+	// TriggerTrustedNetwork is triggered when the node joins a trusted network.
+	TriggerTrustedNetwork Trigger = "trusted_network"
+)
+
+// Config enables auto-connect triggers.
+type Config struct {
+	OnStart          bool
+	OnNetworkRestore bool
+	OnUntrustedWiFi  bool
+	// Trusted networks are never auto-connected on.
+	Trusted TrustedNetworks
+	// DisconnectOnTrusted disconnects the consumer when it joins a trusted network.
+	DisconnectOnTrusted bool
+	// Metered uplink policy, auto-connect is skipped on metered uplinks with MeteredRefuse.
+	Metered MeteredPolicy
+}
+
+// WatchesNetwork tells if any of the enabled triggers depend on uplink network changes.
+func (c Config) WatchesNetwork() bool {
+	return c.OnNetworkRestore || c.OnUntrustedWiFi || !c.Trusted.Empty() || c.Metered.checked()
+}
+
+type connectionManager interface {
+	Connect(consumerID identity.Identity, hermesID common.Address, proposalLookup connection.ProposalLookup, params connection.ConnectParams) error
+	Status(n int) connectionstate.Status
+	Disconnect(n int) error
+}
+
+type networkSource interface {
+	Current() Network
+}
+
+type proposalRepository interface {
+	Proposals(filter *proposal.Filter) ([]proposal.PricedServiceProposal, error)
+}
+
+type profileStorage interface {
+	GetValue(bucket string, key interface{}, to interface{}) error
+	SetValue(bucket string, key interface{}, to interface{}) error
+}
+
+// Policy connects the consumer using the last used connection profile when one of the enabled triggers fires.
+// It never interrupts an existing connection and never connects on trusted networks.
+type Policy struct {
+	manager   connectionManager
+	proposals proposalRepository
+	networks  networkSource
+	storage   profileStorage
+	publisher eventbus.Publisher
+	config    Config
+	now       func() time.Time
+
+	mu         sync.Mutex
+	connecting bool
+}
+
+// NewPolicy creates consumer auto-connect policy.
+func NewPolicy(manager connectionManager, proposals proposalRepository, networks networkSource, storage profileStorage, publisher eventbus.Publisher, config Config) *Policy {
+	return &Policy{
+		manager:   manager,
+		proposals: proposals,
+		networks:  networks,
+		storage:   storage,
+		publisher: publisher,
+		config:    config,
+		now:       time.Now,
+	}
+}
+
+// Subscribe subscribes to profile, node and network events.
+func (p *Policy) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.SubscribeAsync(AppTopicProfileUsed, p.handleProfileUsed); err != nil {
+		return err
+	}
+	if err := bus.SubscribeAsync(nodevent.AppTopicNode, p.handleNodeEvent); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(AppTopicNetwork, p.handleNetworkEvent)
+}
+
+// Profile returns the last used connection profile.
+func (p *Policy) Profile() (Profile, bool) {
+	var profile Profile
+	if err := p.storage.GetValue(bucketName, profileKey, &profile); err != nil {
+		if !errors.Is(err, storm.ErrNotFound) {
+			log.Warn().Err(err).Msg("Could not load auto-connect profile")
+		}
+		return Profile{}, false
+	}
+	return profile, true
+}
+
+func (p *Policy) handleProfileUsed(e AppEventProfileUsed) {
+	profile := e.Profile
+	if profile.UsedAt.IsZero() {
+		profile.UsedAt = p.now()
+	}
+	if err := p.storage.SetValue(bucketName, profileKey, profile); err != nil {
+		log.Warn().Err(err).Msg("Could not store auto-connect profile")
+	}
+}
+
+func (p *Policy) handleNodeEvent(e nodevent.Payload) {
+	if e.Status == nodevent.StatusStarted && p.config.OnStart {
+		p.Trigger(TriggerNodeStart)
+	}
+}
+
+func (p *Policy) handleNetworkEvent(e AppEventNetwork) {
+	_, wasTrusted := p.config.Trusted.Match(e.Previous)
+	if reason, trusted := p.config.Trusted.Match(e.Current); trusted && !wasTrusted && p.config.DisconnectOnTrusted {
+		p.disconnect(reason)
+		return
+	}
+
+	switch {
+	case !e.Previous.Online && e.Current.Online && p.config.OnNetworkRestore:
+		p.Trigger(TriggerNetworkRestored)
+	case e.Current.Untrusted() && e.Current.SSID != e.Previous.SSID && p.config.OnUntrustedWiFi:
+		p.Trigger(TriggerUntrustedWiFi)
+	}
+}
+
+// Trigger connects using the last used profile unless already connected, connecting, on a trusted network or
+// on a metered uplink with MeteredRefuse policy.
+// The outcome is published on the AppTopicAutoConnect topic.
+func (p *Policy) Trigger(trigger Trigger) {
+	profile, ok := p.Profile()
+	if !ok {
+		p.publish(trigger, OutcomeSkipped, "no connection profile", "")
+		return
+	}
+
+	network := p.networks.Current()
+	if reason, trusted := p.config.Trusted.Match(network); trusted {
+		p.publish(trigger, OutcomeSkipped, "trusted network "+reason, profile.ConsumerID)
+		return
+	}
+
+	if network.Metered && p.config.Metered == MeteredRefuse {
+		p.publish(trigger, OutcomeSkipped, "metered network", profile.ConsumerID)
+		return
+	}
+
+	if state := p.manager.Status(profile.Params.ProxyPort).State; state != connectionstate.NotConnected {
+		p.publish(trigger, OutcomeSkipped, "connection state is "+string(state), profile.ConsumerID)
+		return
+	}
+
+	p.mu.Lock()
+	if p.connecting {
+		p.mu.Unlock()
+		p.publish(trigger, OutcomeSkipped, "auto-connect is in progress", profile.ConsumerID)
+		return
+	}
+	p.connecting = true
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.connecting = false
+		p.mu.Unlock()
+	}()
+
+	log.Info().Msgf("Auto-connecting consumer %s on %s", profile.ConsumerID, trigger)
+	lookup := connection.FilteredProposals(profile.Filter(), profile.SortBy, p.proposals)
+	err := p.manager.Connect(identity.FromAddress(profile.ConsumerID), common.HexToAddress(profile.HermesID), lookup, profile.Params)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Auto-connect on %s failed", trigger)
+		p.publish(trigger, OutcomeFailed, err.Error(), profile.ConsumerID)
+		return
+	}
+	p.publish(trigger, OutcomeConnected, "", profile.ConsumerID)
+}
+
+func (p *Policy) disconnect(reason string) {
+	profile, _ := p.Profile()
+	if p.manager.Status(profile.Params.ProxyPort).State == connectionstate.NotConnected {
+		return
+	}
+
+	log.Info().Msgf("Disconnecting on trusted network %s", reason)
+	if err := p.manager.Disconnect(profile.Params.ProxyPort); err != nil {
+		log.Warn().Err(err).Msg("Disconnect on trusted network failed")
+		p.publish(TriggerTrustedNetwork, OutcomeFailed, err.Error(), profile.ConsumerID)
+		return
+	}
+	p.publish(TriggerTrustedNetwork, OutcomeDisconnected, "trusted network "+reason, profile.ConsumerID)
+}
+
+func (p *Policy) publish(trigger Trigger, outcome Outcome, reason, consumerID string) {
+	p.publisher.Publish(AppTopicAutoConnect, AppEventAutoConnect{
+		Trigger:    trigger,
+		Outcome:    outcome,
+		Reason:     reason,
+		ConsumerID: consumerID,
+		At:         p.now(),
+	})
+}
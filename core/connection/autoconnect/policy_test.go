@@ -0,0 +1,260 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autoconnect
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	nodevent "github.com/mysteriumnetwork/node/core/node/event"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+type mockManager struct {
+	state connectionstate.State
+	err   error
+
+	consumerID  identity.Identity
+	hermesID    common.Address
+	params      connection.ConnectParams
+	connects    int
+	disconnects int
+}
+
+func (m *mockManager) Connect(consumerID identity.Identity, hermesID common.Address, _ connection.ProposalLookup, params connection.ConnectParams) error {
+	m.connects++
+	m.consumerID, m.hermesID, m.params = consumerID, hermesID, params
+	return m.err
+}
+
+func (m *mockManager) Status(int) connectionstate.Status {
+	return connectionstate.Status{State: m.state}
+}
+
+func (m *mockManager) Disconnect(int) error {
+	m.disconnects++
+	m.state = connectionstate.NotConnected
+	return nil
+}
+
+type mockNetworks struct {
+	network Network
+}
+
+func (m *mockNetworks) Current() Network {
+	return m.network
+}
+
+type mockProposals struct{}
+
+func (mockProposals) Proposals(*proposal.Filter) ([]proposal.PricedServiceProposal, error) {
+	return nil, nil
+}
+
+type mockPublisher struct {
+	mu     sync.Mutex
+	events []AppEventAutoConnect
+}
+
+func (p *mockPublisher) Publish(topic string, data interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e, ok := data.(AppEventAutoConnect); ok && topic == AppTopicAutoConnect {
+		p.events = append(p.events, e)
+	}
+}
+
+var testProfile = Profile{
+	ConsumerID:  "0x0000000000000000000000000000000000000001",
+	HermesID:    "0x0000000000000000000000000000000000000003",
+	ServiceType: "wireguard",
+	CountryCode: "DE",
+	Params:      connection.ConnectParams{DNS: connection.DNSOptionAuto},
+}
+
+func newTestPolicy(t *testing.T, manager *mockManager, config Config) (*Policy, *mockPublisher) {
+	bolt, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { bolt.Close() })
+
+	publisher := &mockPublisher{}
+	return NewPolicy(manager, mockProposals{}, &mockNetworks{}, bolt, publisher, config), publisher
+}
+
+func TestPolicy_TriggerWithoutProfile(t *testing.T) {
+	manager := &mockManager{state: connectionstate.NotConnected}
+	policy, publisher := newTestPolicy(t, manager, Config{OnStart: true})
+
+	policy.Trigger(TriggerNodeStart)
+
+	assert.Zero(t, manager.connects)
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, OutcomeSkipped, publisher.events[0].Outcome)
+	assert.Equal(t, "no connection profile", publisher.events[0].Reason)
+}
+
+func TestPolicy_TriggerConnectsLastProfile(t *testing.T) {
+	manager := &mockManager{state: connectionstate.NotConnected}
+	policy, publisher := newTestPolicy(t, manager, Config{OnStart: true})
+
+	policy.handleProfileUsed(AppEventProfileUsed{Profile: testProfile})
+	profile, ok := policy.Profile()
+	require.True(t, ok)
+	assert.False(t, profile.UsedAt.IsZero())
+
+	policy.handleNodeEvent(nodevent.Payload{Status: nodevent.StatusStarted})
+
+	assert.Equal(t, 1, manager.connects)
+	assert.Equal(t, identity.FromAddress(testProfile.ConsumerID), manager.consumerID)
+	assert.Equal(t, common.HexToAddress(testProfile.HermesID), manager.hermesID)
+	assert.Equal(t, testProfile.Params, manager.params)
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, TriggerNodeStart, publisher.events[0].Trigger)
+	assert.Equal(t, OutcomeConnected, publisher.events[0].Outcome)
+	assert.Equal(t, testProfile.ConsumerID, publisher.events[0].ConsumerID)
+}
+
+func TestPolicy_TriggerSkipsExistingConnection(t *testing.T) {
+	manager := &mockManager{state: connectionstate.Connected}
+	policy, publisher := newTestPolicy(t, manager, Config{OnStart: true})
+	policy.handleProfileUsed(AppEventProfileUsed{Profile: testProfile})
+
+	policy.Trigger(TriggerNodeStart)
+
+	assert.Zero(t, manager.connects)
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, OutcomeSkipped, publisher.events[0].Outcome)
+}
+
+func TestPolicy_TriggerReportsFailure(t *testing.T) {
+	manager := &mockManager{state: connectionstate.NotConnected, err: errors.New("no providers")}
+	policy, publisher := newTestPolicy(t, manager, Config{OnStart: true})
+	policy.handleProfileUsed(AppEventProfileUsed{Profile: testProfile})
+
+	policy.Trigger(TriggerNodeStart)
+
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, OutcomeFailed, publisher.events[0].Outcome)
+	assert.Equal(t, "no providers", publisher.events[0].Reason)
+}
+
+func TestPolicy_NetworkTriggers(t *testing.T) {
+	offline := Network{}
+	home := Network{Online: true, SSID: "Home", Secured: true}
+	cafe := Network{Online: true, SSID: "Cafe"}
+
+	for name, tc := range map[string]struct {
+		config   Config
+		event    AppEventNetwork
+		expected []Trigger
+	}{
+		"network restored": {
+			config:   Config{OnNetworkRestore: true},
+			event:    AppEventNetwork{Previous: offline, Current: home},
+			expected: []Trigger{TriggerNetworkRestored},
+		},
+		"network restore disabled": {
+			config: Config{OnUntrustedWiFi: true},
+			event:  AppEventNetwork{Previous: offline, Current: home},
+		},
+		"joined open wifi": {
+			config:   Config{OnUntrustedWiFi: true},
+			event:    AppEventNetwork{Previous: home, Current: cafe},
+			expected: []Trigger{TriggerUntrustedWiFi},
+		},
+		"joined secured wifi": {
+			config: Config{OnNetworkRestore: true, OnUntrustedWiFi: true},
+			event:  AppEventNetwork{Previous: cafe, Current: home},
+		},
+		"went offline": {
+			config: Config{OnNetworkRestore: true, OnUntrustedWiFi: true},
+			event:  AppEventNetwork{Previous: home, Current: offline},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			manager := &mockManager{state: connectionstate.NotConnected}
+			policy, publisher := newTestPolicy(t, manager, tc.config)
+			policy.handleProfileUsed(AppEventProfileUsed{Profile: testProfile})
+
+			policy.handleNetworkEvent(tc.event)
+
+			var triggers []Trigger
+			for _, e := range publisher.events {
+				triggers = append(triggers, e.Trigger)
+			}
+			assert.Equal(t, tc.expected, triggers)
+		})
+	}
+}
+
+func TestPolicy_TriggerSkipsTrustedNetwork(t *testing.T) {
+	trusted, err := ParseTrustedNetworks([]string{"Home"}, nil, nil)
+	require.NoError(t, err)
+	manager := &mockManager{state: connectionstate.NotConnected}
+	policy, publisher := newTestPolicy(t, manager, Config{OnStart: true, Trusted: trusted})
+	policy.networks = &mockNetworks{network: Network{Online: true, SSID: "Home", Secured: true}}
+	policy.handleProfileUsed(AppEventProfileUsed{Profile: testProfile})
+
+	policy.Trigger(TriggerNodeStart)
+
+	assert.Zero(t, manager.connects)
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, OutcomeSkipped, publisher.events[0].Outcome)
+	assert.Equal(t, `trusted network SSID "Home"`, publisher.events[0].Reason)
+}
+
+func TestPolicy_DisconnectsOnTrustedNetwork(t *testing.T) {
+	trusted, err := ParseTrustedNetworks(nil, []string{"AA-BB-CC-DD-EE-FF"}, nil)
+	require.NoError(t, err)
+	office := Network{Online: true, GatewayMAC: "aa:bb:cc:dd:ee:ff"}
+	cafe := Network{Online: true, SSID: "Cafe"}
+
+	manager := &mockManager{state: connectionstate.Connected}
+	policy, publisher := newTestPolicy(t, manager, Config{OnUntrustedWiFi: true, Trusted: trusted, DisconnectOnTrusted: true})
+
+	policy.handleNetworkEvent(AppEventNetwork{Previous: cafe, Current: office})
+	assert.Equal(t, 1, manager.disconnects)
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, TriggerTrustedNetwork, publisher.events[0].Trigger)
+	assert.Equal(t, OutcomeDisconnected, publisher.events[0].Outcome)
+
+	policy.handleNetworkEvent(AppEventNetwork{Previous: office, Current: office})
+	assert.Equal(t, 1, manager.disconnects)
+}
+
+func TestPolicy_KeepsConnectionOnTrustedNetworkByDefault(t *testing.T) {
+	trusted, err := ParseTrustedNetworks(nil, nil, []string{"192.168.1.0/24"})
+	require.NoError(t, err)
+
+	manager := &mockManager{state: connectionstate.Connected}
+	policy, publisher := newTestPolicy(t, manager, Config{Trusted: trusted})
+
+	policy.handleNetworkEvent(AppEventNetwork{Previous: Network{}, Current: Network{Online: true, LocalIP: "192.168.1.10"}})
+	assert.Zero(t, manager.disconnects)
+	assert.Empty(t, publisher.events)
+}
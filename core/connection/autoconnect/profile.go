@@ -0,0 +1,51 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autoconnect
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+)
+
+// Profile is a connect request remembered to be repeated by auto-connect.
+type Profile struct {
+	ConsumerID              string
+	HermesID                string
+	ServiceType             string
+	ProviderIDs             []string
+	CountryCode             string
+	IPType                  string
+	IncludeMonitoringFailed bool
+	SortBy                  string
+	Params                  connection.ConnectParams
+	UsedAt                  time.Time
+}
+
+// Filter returns proposal filter of the profile.
+func (p Profile) Filter() *proposal.Filter {
+	return &proposal.Filter{
+		ServiceType:             p.ServiceType,
+		LocationCountry:         p.CountryCode,
+		ProviderIDs:             p.ProviderIDs,
+		IPType:                  p.IPType,
+		IncludeMonitoringFailed: p.IncludeMonitoringFailed,
+		AccessPolicy:            "all",
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autoconnect
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// TrustedNetworks lists networks on which the consumer does not need a VPN connection.
+type TrustedNetworks struct {
+	SSIDs       []string
+	GatewayMACs []string
+	CIDRs       []*net.IPNet
+}
+
+// ParseTrustedNetworks parses trusted network SSIDs, gateway hardware addresses and local address ranges.
+func ParseTrustedNetworks(ssids, gatewayMACs, cidrs []string) (TrustedNetworks, error) {
+	trusted := TrustedNetworks{SSIDs: ssids}
+	for _, mac := range gatewayMACs {
+		normalized := normalizeMAC(mac)
+		if normalized == "" {
+			return TrustedNetworks{}, fmt.Errorf("invalid gateway hardware address %q", mac)
+		}
+		trusted.GatewayMACs = append(trusted.GatewayMACs, normalized)
+	}
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return TrustedNetworks{}, fmt.Errorf("invalid trusted network range %q: %w", cidr, err)
+		}
+		trusted.CIDRs = append(trusted.CIDRs, ipNet)
+	}
+	return trusted, nil
+}
+
+// Empty tells if there are no trusted networks.
+func (t TrustedNetworks) Empty() bool {
+	return len(t.SSIDs) == 0 && len(t.GatewayMACs) == 0 && len(t.CIDRs) == 0
+}
+
+// Match checks if the network is trusted, returning the matched criteria.
+func (t TrustedNetworks) Match(n Network) (string, bool) {
+	if !n.Online {
+		return "", false
+	}
+	if n.SSID != "" {
+		for _, ssid := range t.SSIDs {
+			if ssid == n.SSID {
+				return fmt.Sprintf("SSID %q", ssid), true
+			}
+		}
+	}
+	if n.GatewayMAC != "" {
+		for _, mac := range t.GatewayMACs {
+			if mac == n.GatewayMAC {
+				return "gateway " + mac, true
+			}
+		}
+	}
+	if ip := net.ParseIP(n.LocalIP); ip != nil {
+		for _, ipNet := range t.CIDRs {
+			if ipNet.Contains(ip) {
+				return "range " + ipNet.String(), true
+			}
+		}
+	}
+	return "", false
+}
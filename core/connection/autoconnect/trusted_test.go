@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autoconnect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrustedNetworks(t *testing.T) {
+	trusted, err := ParseTrustedNetworks([]string{"Home"}, []string{"A:B:C:D:E:F"}, []string{" 10.0.0.0/8"})
+	require.NoError(t, err)
+	assert.False(t, trusted.Empty())
+	assert.Equal(t, []string{"0a:0b:0c:0d:0e:0f"}, trusted.GatewayMACs)
+	assert.Equal(t, "10.0.0.0/8", trusted.CIDRs[0].String())
+
+	_, err = ParseTrustedNetworks(nil, []string{"router"}, nil)
+	assert.Error(t, err)
+	_, err = ParseTrustedNetworks(nil, nil, []string{"10.0.0.1"})
+	assert.Error(t, err)
+
+	empty, err := ParseTrustedNetworks(nil, nil, nil)
+	require.NoError(t, err)
+	assert.True(t, empty.Empty())
+}
+
+func TestTrustedNetworks_Match(t *testing.T) {
+	trusted, err := ParseTrustedNetworks([]string{"Home"}, []string{"aa:bb:cc:dd:ee:ff"}, []string{"192.168.1.0/24"})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		network Network
+		reason  string
+		trusted bool
+	}{
+		"ssid":         {network: Network{Online: true, SSID: "Home"}, reason: `SSID "Home"`, trusted: true},
+		"gateway":      {network: Network{Online: true, GatewayMAC: "aa:bb:cc:dd:ee:ff"}, reason: "gateway aa:bb:cc:dd:ee:ff", trusted: true},
+		"range":        {network: Network{Online: true, LocalIP: "192.168.1.10"}, reason: "range 192.168.1.0/24", trusted: true},
+		"other":        {network: Network{Online: true, SSID: "Cafe", LocalIP: "10.1.1.10"}},
+		"offline":      {network: Network{SSID: "Home"}},
+		"undetectable": {network: Network{Online: true}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			reason, ok := trusted.Match(tc.network)
+			assert.Equal(t, tc.trusted, ok)
+			assert.Equal(t, tc.reason, reason)
+		})
+	}
+}
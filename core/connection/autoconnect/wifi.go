@@ -0,0 +1,129 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autoconnect
+
+import (
+	"bufio"
+	"strings"
+)
+
+// wifiDetector detects the current Wi-Fi network by running a platform tool and parsing its output.
+type wifiDetector struct {
+	command []string
+	parse   func(output string) (ssid string, secured bool, ok bool)
+}
+
+func (d wifiDetector) detect() (ssid string, secured bool, ok bool) {
+	if len(d.command) == 0 {
+		return "", false, false
+	}
+
+	return d.parse(commandOutput(d.command...))
+}
+
+// parseNmcli parses `nmcli -t -f ACTIVE,SSID,SECURITY device wifi` output.
+func parseNmcli(output string) (ssid string, secured bool, ok bool) {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := splitNmcli(scanner.Text())
+		if len(fields) != 3 || fields[0] != "yes" {
+			continue
+		}
+		security := strings.TrimSpace(fields[2])
+		return fields[1], security != "" && security != "--", fields[1] != ""
+	}
+	return "", false, false
+}
+
+// splitNmcli splits terse nmcli output line, which escapes colons in values with a backslash.
+func splitNmcli(line string) []string {
+	var fields []string
+	var field strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line):
+			i++
+			field.WriteByte(line[i])
+		case line[i] == ':':
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteByte(line[i])
+		}
+	}
+	return append(fields, field.String())
+}
+
+// parseAirport parses `airport -I` output.
+func parseAirport(output string) (ssid string, secured bool, ok bool) {
+	auth := ""
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := cutField(line)
+		if !found {
+			continue
+		}
+		switch key {
+		case "AirPort":
+			if value == "Off" {
+				return "", false, false
+			}
+		case "SSID":
+			ssid = value
+		case "link auth":
+			auth = value
+		}
+	}
+	return ssid, auth != "open" && auth != "none", ssid != ""
+}
+
+// parseNetsh parses `netsh wlan show interfaces` output, taking the first connected interface.
+func parseNetsh(output string) (ssid string, secured bool, ok bool) {
+	var connected bool
+	var auth string
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := cutField(line)
+		if !found {
+			continue
+		}
+		switch key {
+		case "Name":
+			if connected && ssid != "" {
+				return ssid, auth != "Open", true
+			}
+			connected, ssid, auth = false, "", ""
+		case "State":
+			connected = value == "connected"
+		case "SSID":
+			ssid = value
+		case "Authentication":
+			auth = value
+		}
+	}
+	if !connected || ssid == "" {
+		return "", false, false
+	}
+	return ssid, auth != "Open", true
+}
+
+func cutField(line string) (key, value string, found bool) {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]), true
+}
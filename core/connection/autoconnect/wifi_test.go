@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package autoconnect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNmcli(t *testing.T) {
+	for name, tc := range map[string]struct {
+		output  string
+		ssid    string
+		secured bool
+		ok      bool
+	}{
+		"secured":        {output: "no:Neighbour:WPA2\nyes:Home:WPA2 WPA3\n", ssid: "Home", secured: true, ok: true},
+		"open":           {output: "yes:Cafe:\n", ssid: "Cafe", ok: true},
+		"escaped colon":  {output: "yes:Cafe\\:Free:--\n", ssid: "Cafe:Free", ok: true},
+		"not associated": {output: "no:Neighbour:WPA2\n", ok: false},
+		"empty":          {output: "", ok: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ssid, secured, ok := parseNmcli(tc.output)
+			assert.Equal(t, tc.ssid, ssid)
+			assert.Equal(t, tc.secured, secured)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestParseAirport(t *testing.T) {
+	ssid, secured, ok := parseAirport(`     agrCtlRSSI: -55
+          state: running
+      link auth: wpa2-psk
+          BSSID: aa:bb:cc:dd:ee:ff
+           SSID: Home
+`)
+	assert.Equal(t, "Home", ssid)
+	assert.True(t, secured)
+	assert.True(t, ok)
+
+	ssid, secured, ok = parseAirport("      link auth: open\n           SSID: Cafe\n")
+	assert.Equal(t, "Cafe", ssid)
+	assert.False(t, secured)
+	assert.True(t, ok)
+
+	_, _, ok = parseAirport("AirPort: Off\n")
+	assert.False(t, ok)
+}
+
+func TestParseNetsh(t *testing.T) {
+	ssid, secured, ok := parseNetsh(`
+There are 2 interfaces on the system:
+
+    Name                   : Wi-Fi 2
+    State                  : disconnected
+
+    Name                   : Wi-Fi
+    State                  : connected
+    SSID                   : Cafe
+    BSSID                  : aa:bb:cc:dd:ee:ff
+    Authentication         : Open
+`)
+	assert.Equal(t, "Cafe", ssid)
+	assert.False(t, secured)
+	assert.True(t, ok)
+
+	ssid, secured, ok = parseNetsh(`
+    Name                   : Wi-Fi
+    State                  : connected
+    SSID                   : Home
+    Authentication         : WPA2-Personal
+
+    Name                   : Wi-Fi 2
+    State                  : disconnected
+`)
+	assert.Equal(t, "Home", ssid)
+	assert.True(t, secured)
+	assert.True(t, ok)
+
+	_, _, ok = parseNetsh("    Name : Wi-Fi\n    State : disconnected\n")
+	assert.False(t, ok)
+}
@@ -18,6 +18,7 @@
 package connection
 
 import (
+	"errors"
 	"net"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -35,6 +36,26 @@ type ConnectParams struct {
 	DNS DNSOption
 
 	ProxyPort int
+
+	// Apps restricts the tunnel to or excludes given applications, on platforms supporting per-app tunneling
+	Apps AppFilter
+}
+
+// AppFilter lists applications, identified by their package names, which traffic is routed through the tunnel.
+// Either allowed or disallowed applications can be set, but not both.
+type AppFilter struct {
+	// Allowed applications are the only ones routed through the tunnel
+	Allowed []string
+	// Disallowed applications are routed outside the tunnel
+	Disallowed []string
+}
+
+// Validate checks that the filter can be applied.
+func (f AppFilter) Validate() error {
+	if len(f.Allowed) > 0 && len(f.Disallowed) > 0 {
+		return errors.New("allowed and disallowed applications can not be set at the same time")
+	}
+	return nil
 }
 
 // ConnectOptions represents the params we need to ensure a successful connection
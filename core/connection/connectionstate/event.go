@@ -36,6 +36,12 @@ const (
 	AppTopicConnectionStatistics = "Statistics"
 	// AppTopicConnectionSession represents the session lifetime changes
 	AppTopicConnectionSession = "Session"
+	// AppTopicConnectionQoS represents the session quality of service reported by provider
+	AppTopicConnectionQoS = "QoS"
+	// AppTopicConnectionTransition represents the connection state machine transitions
+	AppTopicConnectionTransition = "Transition"
+	// AppTopicConnectionProviderIP represents the provider public IP change notification
+	AppTopicConnectionProviderIP = "ProviderIP"
 )
 
 // AppEventConnectionState is the struct we'll emit on a AppEventConnectionState topic event
@@ -44,6 +50,15 @@ type AppEventConnectionState struct {
 	SessionInfo Status
 }
 
+// AppEventConnectionTransition represents a connection state machine transition
+type AppEventConnectionTransition struct {
+	From      State
+	To        State
+	Cause     string
+	At        time.Time
+	SessionID session.ID
+}
+
 // State represents list of possible connection states
 type State string
 
@@ -108,3 +123,27 @@ type AppEventConnectionStatistics struct {
 	Stats       Statistics
 	SessionInfo Status
 }
+
+// QoS represents session quality of service measured by provider.
+type QoS struct {
+	// PacketLoss is the share of lost provider pings, 0..1.
+	PacketLoss float64
+	RTT        time.Duration
+	// ThroughputSent is the consumer upload speed in bytes per second.
+	ThroughputSent uint64
+	// ThroughputReceived is the consumer download speed in bytes per second.
+	ThroughputReceived uint64
+	ReportedAt         time.Time
+}
+
+// AppEventConnectionQoS represents a session quality of service event
+type AppEventConnectionQoS struct {
+	QoS         QoS
+	SessionInfo Status
+}
+
+// AppEventConnectionProviderIP represents a provider public IP change event
+type AppEventConnectionProviderIP struct {
+	PublicIP    string
+	SessionInfo Status
+}
@@ -19,6 +19,7 @@ package connection
 
 import (
 	"context"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
@@ -39,6 +40,16 @@ type Connection interface {
 	Statistics() (connectionstate.Statistics, error)
 }
 
+// Rekeyer is implemented by connections able to rotate keys of the running session.
+type Rekeyer interface {
+	// RekeyInterval returns how often keys should be rotated, 0 disables rotation.
+	RekeyInterval() time.Duration
+	// RekeyConfig prepares new keys and returns the config sent to the provider.
+	RekeyConfig() (ConsumerConfig, error)
+	// Rekey applies prepared keys using the config answered by the provider.
+	Rekey(sessionConfig []byte) error
+}
+
 // StateChannel is the channel we receive state change events on
 type StateChannel chan connectionstate.State
 
@@ -35,6 +35,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
@@ -43,28 +44,53 @@ import (
 	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/connectivity"
+	"github.com/mysteriumnetwork/node/session/receipt"
 	"github.com/mysteriumnetwork/node/trace"
+	"github.com/mysteriumnetwork/node/utils/clock"
+	"github.com/mysteriumnetwork/node/utils/errcode"
 )
 
 const (
 	p2pDialTimeout = 60 * time.Second
 )
 
+// Error codes of connection manager errors, they are exposed to API clients.
+const (
+	ErrCodeNoConnection           = "err_no_connection_exists"
+	ErrCodeAlreadyExists          = "err_connection_already_exists"
+	ErrCodeConnectionCancelled    = "err_connection_cancelled"
+	ErrCodeConnectionFailed       = "err_connection_failed"
+	ErrCodeUnsupportedServiceType = "err_unsupported_service_type"
+	ErrCodeInsufficientBalance    = "err_insufficient_balance"
+	ErrCodeUnlockRequired         = "err_unlock_required"
+	ErrCodeConnectionVetoed       = "err_connection_vetoed"
+)
+
 var (
 	// ErrNoConnection error indicates that action applied to manager expects active connection (i.e. disconnect)
-	ErrNoConnection = errors.New("no connection exists")
+	ErrNoConnection = errcode.New(ErrCodeNoConnection, "no connection exists",
+		"Connect to a provider first")
 	// ErrAlreadyExists error indicates that action applied to manager expects no active connection (i.e. connect)
-	ErrAlreadyExists = errors.New("connection already exists")
+	ErrAlreadyExists = errcode.New(ErrCodeAlreadyExists, "connection already exists",
+		"Disconnect the current connection before starting a new one")
 	// ErrConnectionCancelled indicates that connection in progress was cancelled by request of api user
-	ErrConnectionCancelled = errors.New("connection was cancelled")
+	ErrConnectionCancelled = errcode.New(ErrCodeConnectionCancelled, "connection was cancelled",
+		"Connect again if the connection was cancelled by mistake")
 	// ErrConnectionFailed indicates that Connect method didn't reach "Connected" phase due to connection error
-	ErrConnectionFailed = errors.New("connection has failed")
+	ErrConnectionFailed = errcode.New(ErrCodeConnectionFailed, "connection has failed",
+		"Try connecting to a different provider")
 	// ErrUnsupportedServiceType indicates that target proposal contains unsupported service type
-	ErrUnsupportedServiceType = errors.New("unsupported service type in proposal")
+	ErrUnsupportedServiceType = errcode.New(ErrCodeUnsupportedServiceType, "unsupported service type in proposal",
+		"Choose a provider offering a service type supported by this node")
 	// ErrInsufficientBalance indicates consumer has insufficient balance to connect to selected proposal
-	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrInsufficientBalance = errcode.New(ErrCodeInsufficientBalance, "insufficient balance",
+		"Top up the balance or choose a cheaper provider")
 	// ErrUnlockRequired indicates that the consumer identity has not been unlocked yet
-	ErrUnlockRequired = errors.New("unlock required")
+	ErrUnlockRequired = errcode.New(ErrCodeUnlockRequired, "unlock required",
+		"Unlock the identity before connecting")
+	// ErrConnectionVetoed indicates that connection was rejected by one of pre-connect hooks
+	ErrConnectionVetoed = errcode.New(ErrCodeConnectionVetoed, "connection was vetoed",
+		"Choose a different provider or ask the node administrator to review the connection policy")
 )
 
 // IPCheckConfig contains common params for connection ip check.
@@ -123,6 +149,15 @@ type validator interface {
 	Validate(chainID int64, consumerID identity.Identity, p market.Price) error
 }
 
+type preConnectChecker interface {
+	Check(req PreConnectRequest) error
+}
+
+// ReceiptExchanger exchanges mutually signed session receipts with providers.
+type ReceiptExchanger interface {
+	Exchange(ctx context.Context, ch p2p.ChannelSender, r receipt.Receipt) (receipt.Receipt, error)
+}
+
 // TimeGetter function returns current time
 type TimeGetter func() time.Time
 
@@ -142,8 +177,10 @@ type connectionManager struct {
 	config               Config
 	statsReportInterval  time.Duration
 	validator            validator
+	preConnect           preConnectChecker
 	p2pDialer            p2p.Dialer
-	timeGetter           TimeGetter
+	receipts             ReceiptExchanger
+	clock                clock.Clock
 
 	// These are populated by Connect at runtime.
 	ctx                    context.Context
@@ -179,7 +216,9 @@ func NewManager(
 	config Config,
 	statsReportInterval time.Duration,
 	validator validator,
+	preConnect preConnectChecker,
 	p2pDialer p2p.Dialer,
+	receipts ReceiptExchanger,
 	preReconnect, postReconnect func(),
 ) *connectionManager {
 	m := &connectionManager{
@@ -194,8 +233,10 @@ func NewManager(
 		config:               config,
 		statsReportInterval:  statsReportInterval,
 		validator:            validator,
+		preConnect:           preConnect,
 		p2pDialer:            p2pDialer,
-		timeGetter:           time.Now,
+		receipts:             receipts,
+		clock:                clock.System,
 		preReconnect:         preReconnect,
 		postReconnect:        postReconnect,
 	}
@@ -241,6 +282,18 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 		return err
 	}
 
+	if m.preConnect != nil {
+		err = m.preConnect.Check(PreConnectRequest{
+			ConsumerID: consumerID,
+			HermesID:   hermesID,
+			Proposal:   *proposal,
+			Params:     params,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	m.ctxLock.Lock()
 	m.ctx, m.cancel = context.WithCancel(context.Background())
 	m.ctxLock.Unlock()
@@ -249,7 +302,7 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 	defer func() {
 		if err != nil {
 			log.Err(err).Msg("Connect failed, disconnecting")
-			m.disconnect()
+			m.disconnect("connect failed: " + err.Error())
 		}
 	}()
 
@@ -283,7 +336,7 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 		return m.handleStartError(sessionID, err)
 	}
 
-	m.statsTracker = newStatsTracker(m.eventBus, m.statsReportInterval)
+	m.statsTracker = newStatsTracker(m.eventBus, m.statsReportInterval, m.clock)
 	go m.statsTracker.start(m, m.activeConnection)
 	m.addCleanup(func() error {
 		log.Trace().Msg("Cleaning: stopping statistics publisher")
@@ -292,6 +345,18 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 		return nil
 	})
 
+	if m.receipts != nil {
+		m.addCleanup(func() error {
+			log.Trace().Msg("Cleaning: exchanging session receipt")
+			defer log.Trace().Msg("Cleaning: exchanging session receipt DONE")
+			return m.exchangeReceipt()
+		})
+	}
+
+	if r, ok := m.activeConnection.(Rekeyer); ok && r.RekeyInterval() > 0 {
+		go m.rekeyLoop(m.currentCtx(), r)
+	}
+
 	go m.consumeConnectionStates(m.activeConnection.State())
 	go m.checkSessionIP(m.channel, m.connectOptions.ConsumerID, m.connectOptions.SessionID, originalPublicIP)
 
@@ -372,7 +437,9 @@ func (m *connectionManager) initSession(tracer *trace.Tracer, prc market.Price)
 
 	traceStart := tracer.StartStage("Consumer session creation (start)")
 	go m.keepAliveLoop(m.channel, sessionID)
-	m.setStatus(func(status *connectionstate.Status) {
+	m.handleQoSReports(m.channel, sessionID)
+	m.handleProviderIPChanges(m.channel)
+	m.setStatus("session created", func(status *connectionstate.Status) {
 		status.SessionID = sessionID
 	})
 	m.publishSessionCreate(sessionID)
@@ -395,7 +462,7 @@ func (m *connectionManager) handleStartError(sessionID session.ID, err error) er
 	m.publishStateEvent(connectionstate.StateConnectionFailed)
 
 	log.Info().Err(err).Msg("Cancelling connection initiation: ")
-	m.Cancel()
+	m.cancelWithCause("connection start failed: " + err.Error())
 	return err
 }
 
@@ -404,7 +471,7 @@ func (m *connectionManager) clearIPCache() {
 		return
 	}
 
-	if cr, ok := m.ipResolver.(*ip.CachedResolver); ok {
+	if cr, ok := m.ipResolver.(interface{ ClearCache() }); ok {
 		cr.ClearCache()
 	}
 }
@@ -491,10 +558,11 @@ func (m *connectionManager) paymentLoop(opts ConnectOptions, price market.Price)
 		if err != nil {
 			log.Error().Err(err).Msg("Payment error")
 
+			cause := "payment failed: " + err.Error()
 			if config.GetBool(config.FlagKeepConnectedOnFail) {
-				m.statusOnHold()
+				m.statusOnHold(cause)
 			} else {
-				err = m.Disconnect()
+				err = m.disconnectWithCause(cause)
 				if err != nil {
 					log.Error().Err(err).Msg("Could not disconnect gracefully")
 				}
@@ -599,8 +667,9 @@ func (m *connectionManager) createP2PSession(c Connection, opts ConnectOptions,
 				PerHour: requestedPrice.PricePerHour.Bytes(),
 			},
 		},
-		ProposalID: opts.Proposal.ID,
-		Config:     config,
+		ProposalID:   opts.Proposal.ID,
+		Config:       config,
+		InvoiceTerms: requestedInvoiceTerms().Proto(),
 	}
 	log.Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicSessionCreate, sessionRequest.String())
 	ctx, cancel := context.WithTimeout(m.currentCtx(), 20*time.Second)
@@ -616,6 +685,9 @@ func (m *connectionManager) createP2PSession(c Connection, opts ConnectOptions,
 		return nil, fmt.Errorf("could not unmarshal session reply to proto: %w", err)
 	}
 	log.Info().Msgf("Provider's session config: %s", string(sessionResponse.Config))
+	if sessionResponse.InvoiceTerms != nil {
+		log.Info().Msgf("Provider agreed to invoice %s", market.NewInvoiceTerms(sessionResponse.InvoiceTerms))
+	}
 
 	channel := m.channel
 	m.acknowledge = func() {
@@ -654,6 +726,41 @@ func (m *connectionManager) createP2PSession(c Connection, opts ConnectOptions,
 	return &sessionResponse, nil
 }
 
+func requestedInvoiceTerms() market.InvoiceTerms {
+	return market.InvoiceTerms{
+		Period:    config.GetDuration(config.FlagPaymentsConsumerInvoicePeriod),
+		DataBytes: (datasize.MiB * datasize.BitSize(config.GetUInt64(config.FlagPaymentsConsumerInvoiceDataMegabytes))).Bytes(),
+	}
+}
+
+func (m *connectionManager) exchangeReceipt() error {
+	status := m.Status()
+	stats, err := m.activeConnection.Statistics()
+	if err != nil {
+		stats = m.statsTracker.stats()
+	}
+
+	r := receipt.Receipt{
+		SessionID:     status.SessionID,
+		ConsumerID:    status.ConsumerID,
+		ProviderID:    identity.FromAddress(status.Proposal.ProviderID),
+		ServiceType:   status.Proposal.ServiceType,
+		StartedAt:     status.StartedAt,
+		EndedAt:       m.clock.Now(),
+		BytesSent:     stats.BytesSent,
+		BytesReceived: stats.BytesReceived,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := m.receipts.Exchange(ctx, m.channel, r); err != nil {
+		return fmt.Errorf("could not exchange session receipt: %w", err)
+	}
+
+	log.Info().Msgf("Session %s receipt signed by both parties", status.SessionID)
+	return nil
+}
+
 func (m *connectionManager) publishSessionCreate(sessionID session.ID) {
 	m.eventBus.Publish(connectionstate.AppTopicConnectionSession, connectionstate.AppEventConnectionSession{
 		Status:      connectionstate.SessionCreatedStatus,
@@ -707,25 +814,33 @@ func (m *connectionManager) Stats() connectionstate.Statistics {
 	return m.statsTracker.stats()
 }
 
-func (m *connectionManager) setStatus(delta func(status *connectionstate.Status)) {
+func (m *connectionManager) setStatus(cause string, delta func(status *connectionstate.Status)) {
 	m.statusLock.Lock()
 	stateWas := m.status.State
 
 	delta(&m.status)
 
 	state := m.status.State
+	sessionID := m.status.SessionID
 	m.statusLock.Unlock()
 
 	if state != stateWas {
-		log.Info().Msgf("Connection state: %v -> %v", stateWas, state)
+		log.Info().Msgf("Connection state: %v -> %v (%s)", stateWas, state, cause)
 		m.publishStateEvent(state)
+		m.eventBus.Publish(connectionstate.AppTopicConnectionTransition, connectionstate.AppEventConnectionTransition{
+			From:      stateWas,
+			To:        state,
+			Cause:     cause,
+			At:        m.clock.Now(),
+			SessionID: sessionID,
+		})
 	}
 }
 
 func (m *connectionManager) statusConnecting(consumerID identity.Identity, accountantID common.Address, proposal proposal.PricedServiceProposal) {
-	m.setStatus(func(status *connectionstate.Status) {
+	m.setStatus("connect requested", func(status *connectionstate.Status) {
 		*status = connectionstate.Status{
-			StartedAt:        m.timeGetter(),
+			StartedAt:        m.clock.Now(),
 			ConsumerID:       consumerID,
 			ConsumerLocation: m.locationResolver.GetOrigin(),
 			HermesID:         accountantID,
@@ -735,54 +850,62 @@ func (m *connectionManager) statusConnecting(consumerID identity.Identity, accou
 	})
 }
 
-func (m *connectionManager) statusConnected() {
-	m.setStatus(func(status *connectionstate.Status) {
+func (m *connectionManager) statusConnected(cause string) {
+	m.setStatus(cause, func(status *connectionstate.Status) {
 		status.State = connectionstate.Connected
 	})
 }
 
-func (m *connectionManager) statusReconnecting() {
-	m.setStatus(func(status *connectionstate.Status) {
+func (m *connectionManager) statusReconnecting(cause string) {
+	m.setStatus(cause, func(status *connectionstate.Status) {
 		status.State = connectionstate.Reconnecting
 	})
 }
 
-func (m *connectionManager) statusNotConnected() {
-	m.setStatus(func(status *connectionstate.Status) {
+func (m *connectionManager) statusNotConnected(cause string) {
+	m.setStatus(cause, func(status *connectionstate.Status) {
 		status.State = connectionstate.NotConnected
 	})
 }
 
-func (m *connectionManager) statusDisconnecting() {
-	m.setStatus(func(status *connectionstate.Status) {
+func (m *connectionManager) statusDisconnecting(cause string) {
+	m.setStatus(cause, func(status *connectionstate.Status) {
 		status.State = connectionstate.Disconnecting
 	})
 }
 
-func (m *connectionManager) statusCanceled() {
-	m.setStatus(func(status *connectionstate.Status) {
+func (m *connectionManager) statusCanceled(cause string) {
+	m.setStatus(cause, func(status *connectionstate.Status) {
 		status.State = connectionstate.Canceled
 	})
 }
 
-func (m *connectionManager) statusOnHold() {
-	m.setStatus(func(status *connectionstate.Status) {
+func (m *connectionManager) statusOnHold(cause string) {
+	m.setStatus(cause, func(status *connectionstate.Status) {
 		status.State = connectionstate.StateOnHold
 	})
 }
 
 func (m *connectionManager) Cancel() {
-	m.statusCanceled()
-	logDisconnectError(m.Disconnect())
+	m.cancelWithCause("cancel requested")
+}
+
+func (m *connectionManager) cancelWithCause(cause string) {
+	m.statusCanceled(cause)
+	logDisconnectError(m.disconnectWithCause(cause))
 }
 
 func (m *connectionManager) Disconnect() error {
+	return m.disconnectWithCause("disconnect requested")
+}
+
+func (m *connectionManager) disconnectWithCause(cause string) error {
 	if m.Status().State == connectionstate.NotConnected {
 		return ErrNoConnection
 	}
 
-	m.statusDisconnecting()
-	m.disconnect()
+	m.statusDisconnecting(cause)
+	m.disconnect(cause)
 
 	return nil
 }
@@ -794,7 +917,7 @@ func (m *connectionManager) CheckChannel(ctx context.Context) error {
 	return nil
 }
 
-func (m *connectionManager) disconnect() {
+func (m *connectionManager) disconnect(cause string) {
 	m.discoLock.Lock()
 	defer m.discoLock.Unlock()
 
@@ -808,7 +931,7 @@ func (m *connectionManager) disconnect() {
 	m.ctxLock.Unlock()
 
 	m.cleanConnection()
-	m.statusNotConnected()
+	m.statusNotConnected(cause)
 
 	m.cleanAfterDisconnect()
 }
@@ -851,9 +974,9 @@ func (m *connectionManager) onStateChanged(state connectionstate.State) {
 	// React just to certain stains from connection. Because disconnect happens in connectionWaiter
 	switch state {
 	case connectionstate.Connected:
-		m.statusConnected()
+		m.statusConnected("tunnel connected")
 	case connectionstate.Reconnecting:
-		m.statusReconnecting()
+		m.statusReconnecting("tunnel reconnecting")
 	}
 }
 
@@ -932,17 +1055,18 @@ func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session
 		case <-m.currentCtx().Done():
 			log.Debug().Msgf("Stopping p2p keepalive: %v", m.currentCtx().Err())
 			return
-		case <-time.After(m.config.KeepAlive.SendInterval):
+		case <-m.clock.After(m.config.KeepAlive.SendInterval):
 			ctx, cancel := context.WithTimeout(context.Background(), m.config.KeepAlive.SendTimeout)
 			if err := m.sendKeepAlivePing(ctx, channel, sessionID); err != nil {
 				log.Err(err).Msgf("Failed to send p2p keepalive ping. SessionID=%s", sessionID)
 				errCount++
 				if errCount == m.config.KeepAlive.MaxSendErrCount {
 					log.Error().Msgf("Max p2p keepalive err count reached, disconnecting. SessionID=%s", sessionID)
+					cause := "keep-alive failed: " + err.Error()
 					if config.GetBool(config.FlagKeepConnectedOnFail) {
-						m.statusOnHold()
+						m.statusOnHold(cause)
 					} else {
-						m.Disconnect()
+						m.disconnectWithCause(cause)
 					}
 					cancel()
 					return
@@ -955,12 +1079,109 @@ func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session
 	}
 }
 
+func (m *connectionManager) rekeyLoop(ctx context.Context, conn Rekeyer) {
+	ticker := m.clock.NewTicker(conn.RekeyInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debug().Msgf("Stopping session rekeying: %v", ctx.Err())
+			return
+		case <-ticker.C():
+			if m.Status().State != connectionstate.Connected {
+				continue
+			}
+			if err := m.rekey(ctx, conn); err != nil {
+				log.Warn().Err(err).Msgf("Failed to rotate keys of session %s", m.Status().SessionID)
+			}
+		}
+	}
+}
+
+func (m *connectionManager) rekey(ctx context.Context, conn Rekeyer) error {
+	consumerConfig, err := conn.RekeyConfig()
+	if err != nil {
+		return err
+	}
+
+	config, err := json.Marshal(consumerConfig)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(session.RekeyRequest{
+		SessionID: m.Status().SessionID,
+		Config:    config,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+	res, err := m.channel.Send(ctx, p2p.TopicSessionRekey, &p2p.Message{Data: data})
+	if err != nil {
+		return fmt.Errorf("could not send rekey request: %w", err)
+	}
+
+	return conn.Rekey(res.Data)
+}
+
+func (m *connectionManager) handleQoSReports(channel p2p.Channel, sessionID session.ID) {
+	channel.Handle(p2p.TopicSessionQoS, func(c p2p.Context) error {
+		var report pb.SessionQoS
+		if err := c.Request().UnmarshalProto(&report); err != nil {
+			return err
+		}
+		if report.SessionID != string(sessionID) {
+			return c.OK()
+		}
+
+		// Provider reports from its perspective, what it sent consumer has received.
+		m.eventBus.Publish(connectionstate.AppTopicConnectionQoS, connectionstate.AppEventConnectionQoS{
+			QoS: connectionstate.QoS{
+				PacketLoss:         report.PacketLoss,
+				RTT:                time.Duration(report.RttMillis) * time.Millisecond,
+				ThroughputSent:     report.BytesPerSecondReceived,
+				ThroughputReceived: report.BytesPerSecondSent,
+				ReportedAt:         m.clock.Now().UTC(),
+			},
+			SessionInfo: m.Status(),
+		})
+		return c.OK()
+	})
+}
+
+func (m *connectionManager) handleProviderIPChanges(channel p2p.Channel) {
+	channel.Handle(p2p.TopicSessionProviderIP, func(c p2p.Context) error {
+		var config pb.P2PConnectConfig
+		if err := c.Request().UnmarshalProto(&config); err != nil {
+			return err
+		}
+
+		log.Info().Msgf("Provider public IP changed to %s, reconnecting", config.PublicIP)
+		status := m.Status()
+		m.eventBus.Publish(connectionstate.AppTopicConnectionProviderIP, connectionstate.AppEventConnectionProviderIP{
+			PublicIP:    config.PublicIP,
+			SessionInfo: status,
+		})
+
+		// Tunnel endpoint is the old provider IP, so the connection has to be dialed again.
+		// Reconnect closes this channel, it can't run in the handler.
+		if status.State == connectionstate.Connected {
+			go m.Reconnect()
+		}
+		return c.OK()
+	})
+}
+
 func (m *connectionManager) sendKeepAlivePing(ctx context.Context, channel p2p.Channel, sessionID session.ID) error {
 	msg := &pb.P2PKeepAlivePing{
 		SessionID: string(sessionID),
 	}
 
-	start := time.Now()
+	start := m.clock.Now()
 	_, err := channel.Send(ctx, p2p.TopicKeepAlive, p2p.ProtoMessage(msg))
 	if err != nil {
 		return err
@@ -968,7 +1189,7 @@ func (m *connectionManager) sendKeepAlivePing(ctx context.Context, channel p2p.C
 
 	m.eventBus.Publish(quality.AppTopicConsumerPingP2P, quality.PingEvent{
 		SessionID: string(sessionID),
-		Duration:  time.Since(start),
+		Duration:  m.clock.Since(start),
 	})
 
 	return nil
@@ -982,7 +1203,7 @@ func (m *connectionManager) currentCtx() context.Context {
 }
 
 func (m *connectionManager) Reconnect() {
-	err := m.Disconnect()
+	err := m.disconnectWithCause("reconnect requested")
 	if err != nil {
 		log.Error().Err(err).Msgf("Failed to disconnect stale session")
 	}
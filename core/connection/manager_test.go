@@ -46,6 +46,7 @@ import (
 	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/connectivity"
 	"github.com/mysteriumnetwork/node/trace"
+	"github.com/mysteriumnetwork/node/utils/clock"
 )
 
 type testContext struct {
@@ -60,7 +61,9 @@ type testContext struct {
 	config                Config
 	statsReportInterval   time.Duration
 	mockP2P               *mockP2PDialer
+	preConnectHooks       *PreConnectHooks
 	mockTime              time.Time
+	clock                 *clock.Mock
 	sync.RWMutex
 }
 
@@ -140,6 +143,7 @@ func (tc *testContext) SetupTest() {
 
 	tc.mockP2P = &mockP2PDialer{&mockP2PChannel{}}
 	tc.mockTime = time.Date(2000, time.January, 0, 10, 12, 3, 0, time.UTC)
+	tc.preConnectHooks = NewPreConnectHooks()
 
 	tc.connManager = NewManager(
 		func(channel p2p.Channel,
@@ -156,12 +160,13 @@ func (tc *testContext) SetupTest() {
 		tc.config,
 		tc.statsReportInterval,
 		&mockValidator{},
+		tc.preConnectHooks,
 		tc.mockP2P,
+		nil,
 		func() {}, func() {},
 	)
-	tc.connManager.timeGetter = func() time.Time {
-		return tc.mockTime
-	}
+	tc.clock = clock.NewMock(tc.mockTime)
+	tc.connManager.clock = tc.clock
 }
 
 func (tc *testContext) TestWhenNoConnectionIsMadeStatusIsNotConnected() {
@@ -336,6 +341,24 @@ func (tc *testContext) TestConnectFailsIfConnectionFactoryReturnsError() {
 	assert.Error(tc.T(), tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, ConnectParams{}))
 }
 
+func (tc *testContext) TestConnectFailsIfPreConnectHookVetoes() {
+	var checked PreConnectRequest
+	tc.preConnectHooks.Register("policy", PreConnectHookFunc(func(req PreConnectRequest) error {
+		checked = req
+		return NewVetoError("country_blocked", "country is blocked by policy")
+	}))
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, ConnectParams{})
+	assert.True(tc.T(), errors.Is(err, ErrConnectionVetoed))
+
+	var veto *VetoError
+	assert.True(tc.T(), errors.As(err, &veto))
+	assert.Equal(tc.T(), VetoError{Hook: "policy", Reason: "country_blocked", Message: "country is blocked by policy"}, *veto)
+	assert.Equal(tc.T(), consumerID, checked.ConsumerID)
+	assert.Equal(tc.T(), activeProposal, checked.Proposal)
+	assert.Equal(tc.T(), connectionstate.NotConnected, tc.connManager.Status().State)
+}
+
 func (tc *testContext) TestStatusIsConnectedWhenConnectCommandReturnsWithoutError() {
 	tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, ConnectParams{})
 	assert.Equal(
@@ -444,6 +467,7 @@ func (tc *testContext) Test_ManagerPublishesEvents() {
 	err := tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, ConnectParams{})
 	assert.NoError(tc.T(), err)
 
+	tc.advanceTimers(tc.statsReportInterval)
 	waitABit()
 
 	history := tc.stubPublisher.GetEventHistory()
@@ -495,6 +519,33 @@ func (tc *testContext) Test_ManagerPublishesEvents() {
 	}
 }
 
+func (tc *testContext) Test_ManagerPublishesTransitions() {
+	tc.stubPublisher.Clear()
+
+	tc.fakeConnectionFactory.mockConnection.onStartReportStates = []fakeState{
+		connectedState,
+	}
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, ConnectParams{})
+	assert.NoError(tc.T(), err)
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+
+	waitABit()
+
+	var transitions []connectionstate.AppEventConnectionTransition
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.Topic == connectionstate.AppTopicConnectionTransition {
+			transitions = append(transitions, v.Event.(connectionstate.AppEventConnectionTransition))
+		}
+	}
+	assert.Equal(tc.T(), []connectionstate.AppEventConnectionTransition{
+		{From: connectionstate.NotConnected, To: connectionstate.Connecting, Cause: "connect requested", At: tc.mockTime},
+		{From: connectionstate.Connecting, To: connectionstate.Connected, Cause: "tunnel connected", At: tc.mockTime, SessionID: establishedSessionID},
+		{From: connectionstate.Connected, To: connectionstate.Disconnecting, Cause: "disconnect requested", At: tc.mockTime, SessionID: establishedSessionID},
+		{From: connectionstate.Disconnecting, To: connectionstate.NotConnected, Cause: "disconnect requested", At: tc.mockTime, SessionID: establishedSessionID},
+	}, transitions)
+}
+
 func (tc *testContext) Test_ManagerNotifiesAboutSessionIPNotChanged() {
 	tc.stubPublisher.Clear()
 
@@ -569,6 +620,74 @@ func (tc *testContext) Test_ManagerNotifiesAboutSuccessfulConnection() {
 	)
 }
 
+func (tc *testContext) Test_KeepAliveDisconnectsAfterMaxSendErrors() {
+	tc.fakeConnectionFactory.mockConnection.onStartReportStates = []fakeState{
+		connectedState,
+	}
+	tc.mockP2P.ch.setKeepAliveErr(errors.New("ping lost"))
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	for i := 1; i < tc.config.KeepAlive.MaxSendErrCount; i++ {
+		tc.advanceTimers(tc.config.KeepAlive.SendInterval)
+	}
+	tc.awaitTimers()
+	assert.Equal(tc.T(), connectionstate.Connected, tc.connManager.Status().State)
+
+	tc.advanceTimers(tc.config.KeepAlive.SendInterval)
+	assert.Eventually(tc.T(), func() bool {
+		return tc.connManager.Status().State == connectionstate.NotConnected
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func (tc *testContext) Test_KeepAliveResetsErrorCountOnSuccess() {
+	tc.fakeConnectionFactory.mockConnection.onStartReportStates = []fakeState{
+		connectedState,
+	}
+	tc.mockP2P.ch.setKeepAliveErr(errors.New("ping lost"))
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	for i := 1; i < tc.config.KeepAlive.MaxSendErrCount; i++ {
+		tc.advanceTimers(tc.config.KeepAlive.SendInterval)
+	}
+	tc.awaitTimers()
+	tc.mockP2P.ch.setKeepAliveErr(nil)
+	tc.advanceTimers(tc.config.KeepAlive.SendInterval)
+	tc.awaitTimers()
+	tc.mockP2P.ch.setKeepAliveErr(errors.New("ping lost"))
+	for i := 1; i < tc.config.KeepAlive.MaxSendErrCount; i++ {
+		tc.advanceTimers(tc.config.KeepAlive.SendInterval)
+	}
+	tc.awaitTimers()
+
+	assert.Equal(tc.T(), connectionstate.Connected, tc.connManager.Status().State)
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+}
+
+// awaitTimers waits for keepalive and statistics loops of the connection to wait for the clock.
+func (tc *testContext) awaitTimers() {
+	waiting := make(chan struct{})
+	go func() {
+		tc.clock.BlockUntil(2)
+		close(waiting)
+	}()
+
+	select {
+	case <-waiting:
+	case <-time.After(2 * time.Second):
+		tc.FailNow("connection loops stopped waiting for the clock")
+	}
+}
+
+// advanceTimers moves the clock once keepalive and statistics loops are waiting for it.
+func (tc *testContext) advanceTimers(d time.Duration) {
+	tc.awaitTimers()
+	tc.clock.Add(d)
+}
+
 func TestConnectionManagerSuite(t *testing.T) {
 	suite.Run(t, new(testContext))
 }
@@ -626,8 +745,15 @@ func (m mockP2PDialer) Dial(ctx context.Context, consumerID identity.Identity, p
 }
 
 type mockP2PChannel struct {
-	status proto.Message
-	lock   sync.Mutex
+	status       proto.Message
+	keepAliveErr error
+	lock         sync.Mutex
+}
+
+func (m *mockP2PChannel) setKeepAliveErr(err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.keepAliveErr = err
 }
 
 func (m *mockP2PChannel) Conn() *net.UDPConn {
@@ -656,6 +782,10 @@ func (m *mockP2PChannel) Send(_ context.Context, topic string, msg *p2p.Message)
 		return nil, nil
 	case p2p.TopicSessionAcknowledge:
 		return nil, nil
+	case p2p.TopicKeepAlive:
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		return nil, m.keepAliveErr
 	}
 
 	return nil, errors.New("unexpected error")
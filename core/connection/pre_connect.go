@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// PreConnectRequest describes connection which is about to be established.
+type PreConnectRequest struct {
+	ConsumerID identity.Identity
+	HermesID   common.Address
+	Proposal   proposal.PricedServiceProposal
+	Params     ConnectParams
+}
+
+// PreConnectHook validates connection before it is established, e.g. against corporate policy or parental controls.
+// Returned error vetoes the connection, hooks should return VetoError to give a structured reason.
+type PreConnectHook interface {
+	Check(req PreConnectRequest) error
+}
+
+// PreConnectHookFunc is a function implementing PreConnectHook.
+type PreConnectHookFunc func(req PreConnectRequest) error
+
+// Check calls the function.
+func (f PreConnectHookFunc) Check(req PreConnectRequest) error {
+	return f(req)
+}
+
+// VetoError is a structured reason of connection vetoed by pre-connect hook.
+type VetoError struct {
+	// Hook is a name of the hook which vetoed the connection.
+	Hook string
+	// Reason is a machine-readable reason, e.g. "country_blocked".
+	Reason string
+	// Message is a human readable explanation.
+	Message string
+}
+
+// NewVetoError creates veto with the given reason, hook name is filled in on registration.
+func NewVetoError(reason, message string) *VetoError {
+	return &VetoError{Reason: reason, Message: message}
+}
+
+func (e *VetoError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Hook, e.Message)
+}
+
+type namedHook struct {
+	name string
+	hook PreConnectHook
+}
+
+// PreConnectHooks holds pre-connect hooks registered by integrators.
+type PreConnectHooks struct {
+	mu    sync.RWMutex
+	hooks []namedHook
+}
+
+// NewPreConnectHooks creates empty set of pre-connect hooks.
+func NewPreConnectHooks() *PreConnectHooks {
+	return &PreConnectHooks{}
+}
+
+// Register adds hook under the given name, hook already registered with the name is replaced.
+// Hooks are checked in the order they were registered.
+func (h *PreConnectHooks) Register(name string, hook PreConnectHook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i := range h.hooks {
+		if h.hooks[i].name == name {
+			h.hooks[i].hook = hook
+			return
+		}
+	}
+	h.hooks = append(h.hooks, namedHook{name: name, hook: hook})
+}
+
+// Unregister removes hook with the given name.
+func (h *PreConnectHooks) Unregister(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i := range h.hooks {
+		if h.hooks[i].name == name {
+			h.hooks = append(h.hooks[:i], h.hooks[i+1:]...)
+			return
+		}
+	}
+}
+
+// Check runs all registered hooks and returns ErrConnectionVetoed caused by VetoError of the first vetoing hook.
+func (h *PreConnectHooks) Check(req PreConnectRequest) error {
+	h.mu.RLock()
+	hooks := make([]namedHook, len(h.hooks))
+	copy(hooks, h.hooks)
+	h.mu.RUnlock()
+
+	for _, named := range hooks {
+		err := named.hook.Check(req)
+		if err == nil {
+			continue
+		}
+
+		veto := &VetoError{Hook: named.name, Message: err.Error()}
+		var vetoErr *VetoError
+		if errors.As(err, &vetoErr) {
+			veto.Reason = vetoErr.Reason
+			veto.Message = vetoErr.Message
+		}
+		return ErrConnectionVetoed.Wrap(veto)
+	}
+	return nil
+}
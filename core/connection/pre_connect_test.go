@@ -0,0 +1,58 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreConnectHooks_Check(t *testing.T) {
+	hooks := NewPreConnectHooks()
+	assert.NoError(t, hooks.Check(PreConnectRequest{}))
+
+	var order []string
+	hook := func(name string, err error) PreConnectHook {
+		return PreConnectHookFunc(func(req PreConnectRequest) error {
+			order = append(order, name)
+			return err
+		})
+	}
+
+	hooks.Register("allow", hook("allow", nil))
+	hooks.Register("parental", hook("parental", errors.New("adult content")))
+	hooks.Register("policy", hook("policy", NewVetoError("blocked", "blocked by policy")))
+
+	err := hooks.Check(PreConnectRequest{})
+	assert.True(t, errors.Is(err, ErrConnectionVetoed))
+	var veto *VetoError
+	require.True(t, errors.As(err, &veto))
+	assert.Equal(t, VetoError{Hook: "parental", Message: "adult content"}, *veto)
+	assert.Equal(t, []string{"allow", "parental"}, order)
+
+	hooks.Unregister("parental")
+	err = hooks.Check(PreConnectRequest{})
+	require.True(t, errors.As(err, &veto))
+	assert.Equal(t, VetoError{Hook: "policy", Reason: "blocked", Message: "blocked by policy"}, *veto)
+
+	hooks.Register("policy", hook("policy", nil))
+	assert.NoError(t, hooks.Check(PreConnectRequest{}))
+}
@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/rs/zerolog/log"
+
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 )
 
@@ -58,3 +60,16 @@ func FilteredProposals(f *proposal.Filter, sortBy string, repo proposalRepositor
 		return nil, fmt.Errorf("no providers available for the filter")
 	}
 }
+
+// PrefetchedProposals works like FilteredProposals, but starts fetching proposals from the discovery right away,
+// so that it runs concurrently with other connect preparations. The lookups pick up the prefetched proposals
+// from the repository cache shared by all discovery users.
+func PrefetchedProposals(f *proposal.Filter, sortBy string, repo proposalRepository) func() (*proposal.PricedServiceProposal, error) {
+	go func() {
+		if _, err := repo.Proposals(f); err != nil {
+			log.Debug().Err(err).Msg("Failed to prefetch proposals")
+		}
+	}()
+
+	return FilteredProposals(f, sortBy, repo)
+}
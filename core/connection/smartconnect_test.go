@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+type countingRepository struct {
+	mu        sync.Mutex
+	calls     int
+	failFirst bool
+}
+
+func (r *countingRepository) Proposals(_ *proposal.Filter) ([]proposal.PricedServiceProposal, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls++
+	if r.failFirst && r.calls == 1 {
+		return nil, errors.New("discovery unavailable")
+	}
+	return []proposal.PricedServiceProposal{
+		{ServiceProposal: market.ServiceProposal{ProviderID: "0x1"}},
+	}, nil
+}
+
+func (r *countingRepository) callCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls
+}
+
+func TestPrefetchedProposals(t *testing.T) {
+	repo := &countingRepository{}
+	lookup := PrefetchedProposals(&proposal.Filter{}, "", repo)
+
+	assert.Eventually(t, func() bool { return repo.callCount() == 1 }, time.Second, time.Millisecond, "proposals must be fetched before the first lookup")
+
+	p, err := lookup()
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", p.ProviderID)
+}
+
+func TestPrefetchedProposals_LookupAfterFailedPrefetch(t *testing.T) {
+	repo := &countingRepository{failFirst: true}
+	lookup := PrefetchedProposals(&proposal.Filter{}, "", repo)
+	assert.Eventually(t, func() bool { return repo.callCount() == 1 }, time.Second, time.Millisecond)
+
+	p, err := lookup()
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", p.ProviderID)
+	assert.Equal(t, 2, repo.callCount())
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/utils/clock"
 )
 
 // DefaultStatsReportInterval is interval for consumer connection statistics reporting.
@@ -38,23 +39,25 @@ type statsTracker struct {
 	done     chan struct{}
 	bus      eventbus.Publisher
 	interval time.Duration
+	clock    clock.Clock
 
 	mu        sync.RWMutex
 	lastStats connectionstate.Statistics
 }
 
-func newStatsTracker(bus eventbus.Publisher, interval time.Duration) statsTracker {
+func newStatsTracker(bus eventbus.Publisher, interval time.Duration, clock clock.Clock) statsTracker {
 	return statsTracker{
 		done:     make(chan struct{}),
 		bus:      bus,
 		interval: interval,
+		clock:    clock,
 	}
 }
 
 func (s *statsTracker) start(sessionSupplier *connectionManager, statsSupplier statsSupplier) {
 	for {
 		select {
-		case <-time.After(s.interval):
+		case <-s.clock.After(s.interval):
 			stats, err := statsSupplier.Statistics()
 			if err != nil {
 				log.Warn().Err(err).Msg("Could not get connection statistics")
@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"sync"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+)
+
+// DefaultTransitionLogSize is the number of retained connection state transitions.
+const DefaultTransitionLogSize = 100
+
+type transitionSubscriber interface {
+	SubscribeAsync(topic string, fn interface{}) error
+}
+
+// TransitionLog retains the last connection state machine transitions for debugging.
+type TransitionLog struct {
+	size int
+
+	mu          sync.Mutex
+	transitions []connectionstate.AppEventConnectionTransition
+}
+
+// NewTransitionLog returns a transition log retaining given number of transitions.
+func NewTransitionLog(size int) *TransitionLog {
+	return &TransitionLog{size: size}
+}
+
+// Subscribe subscribes to connection state transitions.
+func (l *TransitionLog) Subscribe(bus transitionSubscriber) error {
+	return bus.SubscribeAsync(connectionstate.AppTopicConnectionTransition, l.add)
+}
+
+// Transitions returns retained transitions, oldest first.
+func (l *TransitionLog) Transitions() []connectionstate.AppEventConnectionTransition {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result := make([]connectionstate.AppEventConnectionTransition, len(l.transitions))
+	copy(result, l.transitions)
+	return result
+}
+
+func (l *TransitionLog) add(e connectionstate.AppEventConnectionTransition) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.transitions = append(l.transitions, e)
+	if over := len(l.transitions) - l.size; over > 0 {
+		l.transitions = append(l.transitions[:0], l.transitions[over:]...)
+	}
+}
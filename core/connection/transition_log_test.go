@@ -0,0 +1,39 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+)
+
+func TestTransitionLog_RetainsLastTransitions(t *testing.T) {
+	log := NewTransitionLog(2)
+
+	log.add(connectionstate.AppEventConnectionTransition{From: connectionstate.NotConnected, To: connectionstate.Connecting})
+	log.add(connectionstate.AppEventConnectionTransition{From: connectionstate.Connecting, To: connectionstate.Connected})
+	log.add(connectionstate.AppEventConnectionTransition{From: connectionstate.Connected, To: connectionstate.Disconnecting})
+
+	assert.Equal(t, []connectionstate.AppEventConnectionTransition{
+		{From: connectionstate.Connecting, To: connectionstate.Connected},
+		{From: connectionstate.Connected, To: connectionstate.Disconnecting},
+	}, log.Transitions())
+}
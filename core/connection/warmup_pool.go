@@ -0,0 +1,328 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/trace"
+)
+
+// WarmupConfig configures pre-established p2p channels.
+type WarmupConfig struct {
+	// PresetIDs are filter presets whose best proposals are kept warm.
+	PresetIDs []int
+	// ServiceType of the proposals to keep warm.
+	ServiceType string
+	// Size is the maximum number of providers to keep warm channels with.
+	Size int
+	// Interval between pool refreshes.
+	Interval time.Duration
+	// MaxAge of a warm channel after which it is re-established.
+	MaxAge time.Duration
+	// KeepAliveInterval between pings of warm channels. It must stay below
+	// typical NAT mapping timeouts, otherwise warm channels silently die.
+	KeepAliveInterval time.Duration
+}
+
+type warmupProposalRepository interface {
+	Proposals(filter *proposal.Filter) ([]proposal.PricedServiceProposal, error)
+}
+
+type warmupIdentities interface {
+	GetUnlockedIdentity() (identity.Identity, bool)
+}
+
+type warmupKey struct {
+	consumerID  string
+	providerID  string
+	serviceType string
+}
+
+type warmChannel struct {
+	channel  p2p.Channel
+	dialedAt time.Time
+}
+
+// WarmupPool pre-establishes p2p channels to the top ranked providers of favorite filter presets,
+// so that connecting to them skips the p2p exchange and NAT traversal. No sessions are created
+// until the consumer actually connects. WarmupPool is a p2p.Dialer which hands out warm channels
+// and falls back to dialing.
+type WarmupPool struct {
+	dialer     p2p.Dialer
+	proposals  warmupProposalRepository
+	identities warmupIdentities
+	config     WarmupConfig
+	now        func() time.Time
+
+	mu       sync.Mutex
+	channels map[warmupKey]warmChannel
+
+	refreshMu sync.Mutex
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+// NewWarmupPool creates warm-up pool of p2p channels.
+func NewWarmupPool(dialer p2p.Dialer, proposals warmupProposalRepository, identities warmupIdentities, config WarmupConfig) *WarmupPool {
+	return &WarmupPool{
+		dialer:     dialer,
+		proposals:  proposals,
+		identities: identities,
+		config:     config,
+		now:        time.Now,
+		channels:   make(map[warmupKey]warmChannel),
+		stop:       make(chan struct{}),
+	}
+}
+
+// Dial returns a warm channel to the provider if there is one, otherwise it dials a new channel.
+func (p *WarmupPool) Dial(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, contactDef p2p.ContactDefinition, tracer *trace.Tracer) (p2p.Channel, error) {
+	if ch, ok := p.take(warmupKey{consumerID: consumerID.Address, providerID: providerID.Address, serviceType: serviceType}); ok {
+		log.Info().Msgf("Using warm p2p channel to provider %s", providerID.Address)
+		return ch, nil
+	}
+
+	return p.dialer.Dial(ctx, consumerID, providerID, serviceType, contactDef, tracer)
+}
+
+// Start periodically refreshes warm channels.
+func (p *WarmupPool) Start() {
+	go func() {
+		p.refresh()
+
+		ticker := time.NewTicker(p.config.Interval)
+		defer ticker.Stop()
+
+		keepAlive := time.NewTicker(p.config.KeepAliveInterval)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				p.refresh()
+			case <-keepAlive.C:
+				p.keepAlive()
+			}
+		}
+	}()
+}
+
+// Stop stops refreshing and closes all warm channels.
+func (p *WarmupPool) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		for key, warm := range p.channels {
+			p.closeChannel(key, warm)
+		}
+	})
+}
+
+func (p *WarmupPool) take(key warmupKey) (p2p.Channel, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	warm, ok := p.channels[key]
+	if !ok {
+		return nil, false
+	}
+	delete(p.channels, key)
+
+	if p.now().Sub(warm.dialedAt) > p.config.MaxAge {
+		p.closeChannel(key, warm)
+		return nil, false
+	}
+	return warm.channel, true
+}
+
+func (p *WarmupPool) refresh() {
+	p.refreshMu.Lock()
+	defer p.refreshMu.Unlock()
+
+	consumerID, ok := p.identities.GetUnlockedIdentity()
+	if !ok {
+		p.retain(nil)
+		return
+	}
+
+	targets := p.targets(consumerID)
+	p.retain(targets)
+
+	var wg sync.WaitGroup
+	for key, contactDef := range targets {
+		if p.warm(key) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(key warmupKey, contactDef p2p.ContactDefinition) {
+			defer wg.Done()
+			p.dial(key, contactDef)
+		}(key, contactDef)
+	}
+	wg.Wait()
+}
+
+// targets returns providers which should be kept warm.
+func (p *WarmupPool) targets(consumerID identity.Identity) map[warmupKey]p2p.ContactDefinition {
+	targets := make(map[warmupKey]p2p.ContactDefinition)
+	for _, presetID := range p.config.PresetIDs {
+		proposals, err := p.proposals.Proposals(&proposal.Filter{
+			PresetID:           presetID,
+			ServiceType:        p.config.ServiceType,
+			ExcludeUnsupported: true,
+		})
+		if err != nil {
+			log.Warn().Err(err).Msgf("Could not get proposals of filter preset %d for warm-up", presetID)
+			continue
+		}
+
+		for _, prop := range proposals {
+			if len(targets) >= p.config.Size {
+				return targets
+			}
+
+			contactDef, err := p2p.ParseContact(prop.Contacts)
+			if err != nil {
+				continue
+			}
+			key := warmupKey{consumerID: consumerID.Address, providerID: prop.ProviderID, serviceType: prop.ServiceType}
+			targets[key] = contactDef
+		}
+	}
+	return targets
+}
+
+// retain closes warm channels which are no longer targeted or are too old.
+func (p *WarmupPool) retain(targets map[warmupKey]p2p.ContactDefinition) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, warm := range p.channels {
+		if _, ok := targets[key]; ok && p.now().Sub(warm.dialedAt) <= p.config.MaxAge {
+			continue
+		}
+		delete(p.channels, key)
+		p.closeChannel(key, warm)
+	}
+}
+
+// keepAlive pings warm channels to keep their NAT mappings open and drops the ones
+// which don't answer.
+func (p *WarmupPool) keepAlive() {
+	p.mu.Lock()
+	channels := make(map[warmupKey]p2p.Channel, len(p.channels))
+	for key, warm := range p.channels {
+		channels[key] = warm.channel
+	}
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for key, ch := range channels {
+		wg.Add(1)
+		go func(key warmupKey, ch p2p.Channel) {
+			defer wg.Done()
+
+			if err := p.ping(ch); err != nil {
+				log.Debug().Err(err).Msgf("Warm p2p channel to provider %s is dead", key.providerID)
+				p.drop(key, ch)
+			}
+		}(key, ch)
+	}
+	wg.Wait()
+}
+
+func (p *WarmupPool) ping(ch p2p.Channel) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.KeepAliveInterval)
+	defer cancel()
+
+	_, err := ch.Send(ctx, p2p.TopicKeepAlive, p2p.ProtoMessage(&pb.P2PKeepAlivePing{}))
+	// Provider registers keep alive handler only for sessions, a reply without it
+	// still proves that the channel is alive.
+	if errors.Is(err, p2p.ErrHandlerNotFound) {
+		return nil
+	}
+	return err
+}
+
+// drop closes the warm channel unless it was already taken or replaced.
+func (p *WarmupPool) drop(key warmupKey, ch p2p.Channel) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	warm, ok := p.channels[key]
+	if !ok || warm.channel != ch {
+		return
+	}
+	delete(p.channels, key)
+	p.closeChannel(key, warm)
+}
+
+func (p *WarmupPool) warm(key warmupKey) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, ok := p.channels[key]
+	return ok
+}
+
+func (p *WarmupPool) dial(key warmupKey, contactDef p2p.ContactDefinition) {
+	ctx, cancel := context.WithTimeout(context.Background(), p2pDialTimeout)
+	defer cancel()
+
+	tracer := trace.NewTracer("Consumer P2P warm-up")
+	ch, err := p.dialer.Dial(ctx, identity.FromAddress(key.consumerID), identity.FromAddress(key.providerID), key.serviceType, contactDef, tracer)
+	if err != nil {
+		log.Debug().Err(err).Msgf("Could not warm up p2p channel to provider %s", key.providerID)
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	select {
+	case <-p.stop:
+		p.closeChannel(key, warmChannel{channel: ch})
+		return
+	default:
+	}
+
+	p.channels[key] = warmChannel{channel: ch, dialedAt: p.now()}
+	log.Debug().Msgf("Warmed up p2p channel to provider %s", key.providerID)
+}
+
+func (p *WarmupPool) closeChannel(key warmupKey, warm warmChannel) {
+	if err := warm.channel.Close(); err != nil {
+		log.Debug().Err(err).Msgf("Could not close warm p2p channel to provider %s", key.providerID)
+	}
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/trace"
+)
+
+type warmupChannel struct {
+	mockP2PChannel
+	providerID string
+	closed     bool
+}
+
+func (c *warmupChannel) Close() error {
+	c.closed = true
+	return nil
+}
+
+type warmupDialer struct {
+	mu     sync.Mutex
+	dialed []*warmupChannel
+}
+
+func (d *warmupDialer) Dial(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, contactDef p2p.ContactDefinition, tracer *trace.Tracer) (p2p.Channel, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ch := &warmupChannel{providerID: providerID.Address}
+	d.dialed = append(d.dialed, ch)
+	return ch, nil
+}
+
+type warmupProposals struct {
+	byPreset map[int][]string
+}
+
+func (r *warmupProposals) Proposals(filter *proposal.Filter) ([]proposal.PricedServiceProposal, error) {
+	var res []proposal.PricedServiceProposal
+	for _, providerID := range r.byPreset[filter.PresetID] {
+		res = append(res, proposal.PricedServiceProposal{ServiceProposal: market.ServiceProposal{
+			ProviderID:  providerID,
+			ServiceType: filter.ServiceType,
+			Contacts:    market.ContactList{{Type: p2p.ContactTypeV1, Definition: p2p.ContactDefinition{}}},
+		}})
+	}
+	return res, nil
+}
+
+type warmupIdentity struct {
+	id       identity.Identity
+	unlocked bool
+}
+
+func (i *warmupIdentity) GetUnlockedIdentity() (identity.Identity, bool) {
+	return i.id, i.unlocked
+}
+
+func TestWarmupPool(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	dialer := &warmupDialer{}
+	proposals := &warmupProposals{byPreset: map[int][]string{
+		1: {"0x1", "0x2"},
+		2: {"0x2", "0x3", "0x4"},
+	}}
+	consumer := &warmupIdentity{id: identity.FromAddress("0xc"), unlocked: true}
+	pool := NewWarmupPool(dialer, proposals, consumer, WarmupConfig{
+		PresetIDs:   []int{1, 2},
+		ServiceType: "wireguard",
+		Size:        3,
+		MaxAge:      time.Minute,
+	})
+	pool.now = func() time.Time { return now }
+
+	pool.refresh()
+	assert.Len(t, dialer.dialed, 3)
+
+	// Warm channel is handed out once, the next dial goes to the dialer.
+	taken, err := pool.Dial(context.Background(), consumer.id, identity.FromAddress("0x2"), "wireguard", p2p.ContactDefinition{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x2", taken.(*warmupChannel).providerID)
+	assert.Len(t, dialer.dialed, 3)
+
+	dialed, err := pool.Dial(context.Background(), consumer.id, identity.FromAddress("0x2"), "wireguard", p2p.ContactDefinition{}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, dialer.dialed, 4)
+
+	// Only the taken channel is re-established.
+	pool.refresh()
+	assert.Len(t, dialer.dialed, 5)
+
+	// Stale and no longer ranked channels are replaced.
+	proposals.byPreset[1] = []string{"0x1", "0x5"}
+	now = now.Add(2 * time.Minute)
+	pool.refresh()
+	assert.Len(t, dialer.dialed, 8)
+	for _, ch := range dialer.dialed[:5] {
+		if ch == taken || ch == dialed {
+			assert.False(t, ch.closed)
+			continue
+		}
+		assert.True(t, ch.closed)
+	}
+
+	// Locking identity drops the pool.
+	consumer.unlocked = false
+	pool.refresh()
+	for _, ch := range dialer.dialed[5:] {
+		assert.True(t, ch.closed)
+	}
+}
+
+func TestWarmupPool_KeepAlive(t *testing.T) {
+	dialer := &warmupDialer{}
+	proposals := &warmupProposals{byPreset: map[int][]string{1: {"0x1", "0x2"}}}
+	consumer := &warmupIdentity{id: identity.FromAddress("0xc"), unlocked: true}
+	pool := NewWarmupPool(dialer, proposals, consumer, WarmupConfig{
+		PresetIDs:         []int{1},
+		ServiceType:       "wireguard",
+		Size:              2,
+		MaxAge:            time.Minute,
+		KeepAliveInterval: time.Second,
+	})
+
+	pool.refresh()
+	assert.Len(t, dialer.dialed, 2)
+
+	// Missing keep alive handler on provider side still proves the channel is alive.
+	dialer.dialed[0].setKeepAliveErr(p2p.ErrHandlerNotFound)
+	dialer.dialed[1].setKeepAliveErr(context.DeadlineExceeded)
+	pool.keepAlive()
+
+	assert.False(t, dialer.dialed[0].closed)
+	assert.True(t, dialer.dialed[1].closed)
+	assert.True(t, pool.warm(warmupKey{consumerID: "0xc", providerID: dialer.dialed[0].providerID, serviceType: "wireguard"}))
+	assert.False(t, pool.warm(warmupKey{consumerID: "0xc", providerID: dialer.dialed[1].providerID, serviceType: "wireguard"}))
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ddns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/requests"
+)
+
+func newHTTPClient() *requests.HTTPClient {
+	return requests.NewHTTPClient("0.0.0.0", time.Second)
+}
+
+func TestDuckDNS_Update(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "node", r.URL.Query().Get("domains"))
+		assert.Equal(t, "1.2.3.4", r.URL.Query().Get("ip"))
+		if r.URL.Query().Get("token") != "secret" {
+			w.Write([]byte("KO"))
+			return
+		}
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	provider := NewDuckDNS(newHTTPClient(), "secret")
+	provider.apiURL = server.URL
+	assert.NoError(t, provider.Update(context.Background(), "node.duckdns.org", "1.2.3.4"))
+
+	provider.token = "wrong"
+	assert.Error(t, provider.Update(context.Background(), "node", "1.2.3.4"))
+}
+
+func TestCloudflare_Update(t *testing.T) {
+	var updated cloudflareRecord
+	var method string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		if r.Method == http.MethodGet {
+			assert.Equal(t, "/zones/zone/dns_records", r.URL.Path)
+			w.Write([]byte(`{"success":true,"result":[{"id":"rec","type":"A","name":"node.example.com","content":"1.1.1.1"}]}`))
+			return
+		}
+
+		method = r.Method
+		assert.Equal(t, "/zones/zone/dns_records/rec", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&updated))
+		w.Write([]byte(`{"success":true,"result":{}}`))
+	}))
+	defer server.Close()
+
+	provider := NewCloudflare(newHTTPClient(), "token", "zone")
+	provider.apiURL = server.URL
+	require.NoError(t, provider.Update(context.Background(), "node.example.com", "2.2.2.2"))
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, cloudflareRecord{Type: "A", Name: "node.example.com", Content: "2.2.2.2", TTL: 60}, updated)
+}
+
+func TestURLTemplate_Update(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		assert.Equal(t, "user", user)
+		assert.Equal(t, "pass", password)
+		assert.Equal(t, "node.example.com", r.URL.Query().Get("hostname"))
+		assert.Equal(t, "1.2.3.4", r.URL.Query().Get("myip"))
+	}))
+	defer server.Close()
+
+	template := "http://user:pass@" + server.Listener.Addr().String() + "/nic/update?hostname={hostname}&myip={ip}"
+	provider := NewURLTemplate(newHTTPClient(), template)
+	assert.NoError(t, provider.Update(context.Background(), "node.example.com", "1.2.3.4"))
+}
+
+type mockProvider struct {
+	updates []string
+	err     error
+}
+
+func (m *mockProvider) Update(ctx context.Context, hostname, ip string) error {
+	m.updates = append(m.updates, ip)
+	return m.err
+}
+
+func TestUpdater_Update(t *testing.T) {
+	provider := &mockProvider{}
+	updater := NewUpdater(provider, "node.example.com", nil)
+
+	updater.update("1.1.1.1")
+	updater.update("1.1.1.1")
+	assert.Equal(t, []string{"1.1.1.1"}, provider.updates)
+	assert.Equal(t, "1.1.1.1", updater.Status().IP)
+
+	provider.err = errors.New("unauthorized")
+	updater.update("2.2.2.2")
+	assert.Equal(t, "1.1.1.1", updater.Status().IP)
+	assert.Equal(t, "unauthorized", updater.Status().Error)
+
+	provider.err = nil
+	updater.update("2.2.2.2")
+	assert.Equal(t, []string{"1.1.1.1", "2.2.2.2", "2.2.2.2"}, provider.updates)
+	assert.Equal(t, Status{Hostname: "node.example.com", IP: "2.2.2.2", UpdatedAt: updater.Status().UpdatedAt}, updater.Status())
+}
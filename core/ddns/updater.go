@@ -0,0 +1,110 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ddns
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
+// Status is the state of the hostname DNS record.
+type Status struct {
+	Hostname  string
+	IP        string
+	UpdatedAt time.Time
+	Error     string
+}
+
+type publicIPResolver interface {
+	GetPublicIP() (string, error)
+}
+
+// Updater keeps the hostname pointed at the node public IP.
+type Updater struct {
+	provider Provider
+	hostname string
+	resolver publicIPResolver
+	timeout  time.Duration
+
+	mu     sync.Mutex
+	status Status
+}
+
+// NewUpdater creates a new dynamic DNS updater.
+func NewUpdater(provider Provider, hostname string, resolver publicIPResolver) *Updater {
+	return &Updater{
+		provider: provider,
+		hostname: hostname,
+		resolver: resolver,
+		timeout:  30 * time.Second,
+		status:   Status{Hostname: hostname},
+	}
+}
+
+// Subscribe updates the hostname on public IP changes.
+func (u *Updater) Subscribe(bus eventbus.Subscriber) error {
+	return bus.SubscribeAsync(ip.AppTopicPublicIPChanged, func(e ip.PublicIPChanged) {
+		u.update(e.Current)
+	})
+}
+
+// Start points the hostname at the current public IP.
+func (u *Updater) Start() {
+	go func() {
+		publicIP, err := u.resolver.GetPublicIP()
+		if err != nil {
+			log.Warn().Err(err).Msgf("Could not resolve public IP for %s DNS record", u.hostname)
+			return
+		}
+		u.update(publicIP)
+	}()
+}
+
+// Status returns the last update status.
+func (u *Updater) Status() Status {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.status
+}
+
+func (u *Updater) update(publicIP string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.status.IP == publicIP && u.status.Error == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), u.timeout)
+	defer cancel()
+
+	if err := u.provider.Update(ctx, u.hostname, publicIP); err != nil {
+		log.Error().Err(err).Msgf("Failed to point %s to %s", u.hostname, publicIP)
+		u.status.Error = err.Error()
+		return
+	}
+
+	log.Info().Msgf("Pointed %s to %s", u.hostname, publicIP)
+	u.status = Status{Hostname: u.hostname, IP: publicIP, UpdatedAt: time.Now().UTC()}
+}
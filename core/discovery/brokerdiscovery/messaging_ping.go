@@ -18,6 +18,8 @@
 package brokerdiscovery
 
 import (
+	"time"
+
 	"github.com/mysteriumnetwork/node/communication"
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/identity"
@@ -35,11 +37,12 @@ const pingEndpoint = communication.MessageEndpoint("*.proposal-ping.v3")
 type pingProducer struct {
 	message *pingMessage
 	signer  identity.Signer
+	timeNow func() time.Time
 }
 
 // GetMessageEndpoint returns endpoint where to send messages
 func (p *pingProducer) GetMessageEndpoint() (communication.MessageEndpoint, error) {
-	subj, err := nats.SignedSubject(p.signer, string(pingEndpoint))
+	subj, err := nats.SignedSubject(p.signer, string(pingEndpoint), p.timeNow())
 	return communication.MessageEndpoint(subj), err
 }
 
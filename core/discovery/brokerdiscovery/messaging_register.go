@@ -18,6 +18,8 @@
 package brokerdiscovery
 
 import (
+	"time"
+
 	"github.com/mysteriumnetwork/node/communication"
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/identity"
@@ -35,11 +37,12 @@ const registerEndpoint = communication.MessageEndpoint("*.proposal-register.v3")
 type registerProducer struct {
 	message *registerMessage
 	signer  identity.Signer
+	timeNow func() time.Time
 }
 
 // GetMessageEndpoint returns endpoint where to send messages
 func (p *registerProducer) GetMessageEndpoint() (communication.MessageEndpoint, error) {
-	subj, err := nats.SignedSubject(p.signer, string(registerEndpoint))
+	subj, err := nats.SignedSubject(p.signer, string(registerEndpoint), p.timeNow())
 	return communication.MessageEndpoint(subj), err
 }
 
@@ -18,6 +18,8 @@
 package brokerdiscovery
 
 import (
+	"time"
+
 	"github.com/mysteriumnetwork/node/communication"
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/identity"
@@ -35,11 +37,12 @@ const unregisterEndpoint = communication.MessageEndpoint("proposal-unregister.v3
 type unregisterProducer struct {
 	message *unregisterMessage
 	signer  identity.Signer
+	timeNow func() time.Time
 }
 
 // GetMessageEndpoint returns endpoint where to send messages
 func (p *unregisterProducer) GetMessageEndpoint() (communication.MessageEndpoint, error) {
-	subj, err := nats.SignedSubject(p.signer, string(unregisterEndpoint))
+	subj, err := nats.SignedSubject(p.signer, string(unregisterEndpoint), p.timeNow())
 	return communication.MessageEndpoint(subj), err
 }
 
@@ -18,37 +18,42 @@
 package brokerdiscovery
 
 import (
+	"time"
+
 	"github.com/mysteriumnetwork/node/communication"
+	"github.com/mysteriumnetwork/node/communication/broker"
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 )
 
 type registryBroker struct {
-	sender communication.Sender
+	sender  communication.Sender
+	timeNow func() time.Time
 }
 
-// NewRegistry create an instance of Broker registryBroker
-func NewRegistry(connection nats.Connection) *registryBroker {
+// NewRegistry create an instance of Broker registryBroker, timeNow gives timestamps of signed subjects
+func NewRegistry(connection broker.Connection, timeNow func() time.Time) *registryBroker {
 	return &registryBroker{
-		sender: nats.NewSender(connection, communication.NewCodecJSON()),
+		sender:  nats.NewSender(connection, communication.NewCodecJSON()),
+		timeNow: timeNow,
 	}
 }
 
 // RegisterProposal registers service proposal to discovery service
 func (rb *registryBroker) RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
 	message := &registerMessage{Proposal: proposal}
-	return rb.sender.Send(&registerProducer{message: message, signer: signer})
+	return rb.sender.Send(&registerProducer{message: message, signer: signer, timeNow: rb.timeNow})
 }
 
 // UnregisterProposal unregisters a service proposal when client disconnects
 func (rb *registryBroker) UnregisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
 	message := &unregisterMessage{Proposal: proposal}
-	return rb.sender.Send(&unregisterProducer{message: message, signer: signer})
+	return rb.sender.Send(&unregisterProducer{message: message, signer: signer, timeNow: rb.timeNow})
 }
 
 // PingProposal pings service proposal as being alive
 func (rb *registryBroker) PingProposal(proposal market.ServiceProposal, signer identity.Signer) error {
 	message := &pingMessage{Proposal: proposal}
-	return rb.sender.Send(&pingProducer{message: message, signer: signer})
+	return rb.sender.Send(&pingProducer{message: message, signer: signer, timeNow: rb.timeNow})
 }
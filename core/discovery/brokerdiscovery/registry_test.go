@@ -20,6 +20,7 @@ package brokerdiscovery
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/mysteriumnetwork/node/communication"
 	"github.com/mysteriumnetwork/node/communication/nats"
@@ -36,20 +37,16 @@ var (
 func Test_NewRegistry(t *testing.T) {
 	connection := nats.NewConnectionMock()
 
-	assert.Equal(
-		t,
-		&registryBroker{
-			sender: nats.NewSender(connection, communication.NewCodecJSON()),
-		},
-		NewRegistry(connection),
-	)
+	registry := NewRegistry(connection, time.Now)
+	assert.Equal(t, nats.NewSender(connection, communication.NewCodecJSON()), registry.sender)
+	assert.NotNil(t, registry.timeNow)
 }
 
 func Test_Registry_RegisterProposal(t *testing.T) {
 	connection := nats.StartConnectionMock()
 	defer connection.Close()
 
-	registry := NewRegistry(connection)
+	registry := NewRegistry(connection, time.Now)
 	err := registry.RegisterProposal(newProposal, &identity.SignerFake{})
 	assert.NoError(t, err)
 
@@ -66,7 +63,7 @@ func Test_Registry_UnregisterProposal(t *testing.T) {
 	connection := nats.StartConnectionMock()
 	defer connection.Close()
 
-	registry := NewRegistry(connection)
+	registry := NewRegistry(connection, time.Now)
 	err := registry.UnregisterProposal(newProposal, &identity.SignerFake{})
 	assert.NoError(t, err)
 
@@ -83,7 +80,7 @@ func Test_Registry_PingProposal(t *testing.T) {
 	connection := nats.StartConnectionMock()
 	defer connection.Close()
 
-	registry := NewRegistry(connection)
+	registry := NewRegistry(connection, time.Now)
 	err := registry.PingProposal(newProposal, &identity.SignerFake{})
 	assert.NoError(t, err)
 
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/mysteriumnetwork/node/communication"
+	"github.com/mysteriumnetwork/node/communication/broker"
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/market"
@@ -43,7 +44,7 @@ type Repository struct {
 
 // NewRepository constructs a new proposal repository (backed by the broker).
 func NewRepository(
-	connection nats.Connection,
+	connection broker.Connection,
 	storage *ProposalStorage,
 	proposalTimeoutInterval time.Duration,
 	proposalCheckInterval time.Duration,
@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mysteriumnetwork/node/communication/broker"
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/market"
@@ -63,7 +64,7 @@ func Test_Subscriber_StartSyncsNewProposals(t *testing.T) {
 		{
 		  "proposal": {
 			"format": "service-proposal/v3",
-			"compatibility": 2,
+			"compatibility": 3,
 			"provider_id": "0x1",
 			"service_type": "mock_service",
 			"contacts": [
@@ -133,7 +134,7 @@ func Test_Subscriber_StartSyncsHealthyProposals(t *testing.T) {
 	proposalRegister(connection, `{
 	  "proposal": {
 		"format": "service-proposal/v3",
-		"compatibility": 2,
+		"compatibility": 3,
 		"provider_id": "0x1",
 		"service_type": "mock_service",
 		"contacts": [
@@ -147,7 +148,7 @@ func Test_Subscriber_StartSyncsHealthyProposals(t *testing.T) {
 	proposalPing(connection, `{
 	  "proposal": {
         "format": "service-proposal/v3",
-		"compatibility": 2,
+		"compatibility": 3,
 		"provider_id": "0x1",
 		"service_type": "mock_service",
 		"contacts": [
@@ -188,21 +189,21 @@ func Test_Subscriber_StartSyncsStoppedProposals(t *testing.T) {
 }`)
 }
 
-func proposalRegister(connection nats.Connection, payload string) {
+func proposalRegister(connection broker.Connection, payload string) {
 	err := connection.Publish("*.proposal-register.v3", []byte(payload))
 	if err != nil {
 		panic(err)
 	}
 }
 
-func proposalUnregister(connection nats.Connection, payload string) {
+func proposalUnregister(connection broker.Connection, payload string) {
 	err := connection.Publish("*.proposal-unregister.v3", []byte(payload))
 	if err != nil {
 		panic(err)
 	}
 }
 
-func proposalPing(connection nats.Connection, payload string) {
+func proposalPing(connection broker.Connection, payload string) {
 	err := connection.Publish("*.proposal-ping.v3", []byte(payload))
 	if err != nil {
 		panic(err)
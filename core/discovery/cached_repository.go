@@ -0,0 +1,116 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// cachedRepository reuses proposals, along with their quality, fetched for the same filter for a short time.
+// Concurrent lookups of the same filter wait for a single fetch, so connect preparations started
+// in parallel and the following smart connect lookups query the discovery once.
+type cachedRepository struct {
+	delegate proposal.Repository
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*cachedProposals
+}
+
+type cachedProposals struct {
+	ready     chan struct{}
+	proposals []market.ServiceProposal
+	err       error
+	expiresAt time.Time
+}
+
+// NewCachedRepository wraps the given repository to reuse proposals fetched during the given ttl.
+func NewCachedRepository(delegate proposal.Repository, ttl time.Duration) *cachedRepository {
+	return &cachedRepository{
+		delegate: delegate,
+		ttl:      ttl,
+		now:      time.Now,
+		entries:  make(map[string]*cachedProposals),
+	}
+}
+
+// Proposal returns a single proposal by its ID.
+func (r *cachedRepository) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
+	return r.delegate.Proposal(id)
+}
+
+// Proposals returns proposals matching the filter, fetching them only if none are cached.
+func (r *cachedRepository) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
+	key, err := json.Marshal(filter)
+	if err != nil {
+		return r.delegate.Proposals(filter)
+	}
+
+	r.mu.Lock()
+	entry, ok := r.entries[string(key)]
+	if !ok || r.expired(entry) {
+		entry = r.fetch(string(key), filter)
+	} else {
+		r.mu.Unlock()
+	}
+
+	<-entry.ready
+	return append([]market.ServiceProposal(nil), entry.proposals...), entry.err
+}
+
+// Countries returns number of proposals matching the filter per country.
+func (r *cachedRepository) Countries(filter *proposal.Filter) (map[string]int, error) {
+	return r.delegate.Countries(filter)
+}
+
+// fetch must be called with r.mu held, it releases the lock while querying the delegate.
+func (r *cachedRepository) fetch(key string, filter *proposal.Filter) *cachedProposals {
+	for k, e := range r.entries {
+		if r.expired(e) {
+			delete(r.entries, k)
+		}
+	}
+	entry := &cachedProposals{ready: make(chan struct{})}
+	r.entries[key] = entry
+	r.mu.Unlock()
+
+	proposals, err := r.delegate.Proposals(filter)
+
+	r.mu.Lock()
+	entry.proposals, entry.err = proposals, err
+	entry.expiresAt = r.now().Add(r.ttl)
+	if err != nil && r.entries[key] == entry {
+		// Failures are returned to the waiting lookups only, the next one tries again.
+		delete(r.entries, key)
+	}
+	r.mu.Unlock()
+	close(entry.ready)
+
+	return entry
+}
+
+// expired must be called with r.mu held. Entries which are still being fetched never expire.
+func (r *cachedRepository) expired(entry *cachedProposals) bool {
+	return !entry.expiresAt.IsZero() && !r.now().Before(entry.expiresAt)
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+type countingRepository struct {
+	mockRepository
+	mu      sync.Mutex
+	calls   int
+	release chan struct{}
+}
+
+func (r *countingRepository) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
+	r.mu.Lock()
+	r.calls++
+	r.mu.Unlock()
+
+	if r.release != nil {
+		<-r.release
+	}
+	return r.mockRepository.Proposals(filter)
+}
+
+func (r *countingRepository) callCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls
+}
+
+func TestCachedRepository_ReusesProposalsWithinTTL(t *testing.T) {
+	delegate := &countingRepository{mockRepository: mockRepository{proposalsToReturn: []market.ServiceProposal{mockProposal}}}
+	repo := NewCachedRepository(delegate, time.Minute)
+	now := time.Now()
+	repo.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		proposals, err := repo.Proposals(&proposal.Filter{ServiceType: "wireguard"})
+		require.NoError(t, err)
+		assert.Equal(t, []market.ServiceProposal{mockProposal}, proposals)
+	}
+	assert.Equal(t, 1, delegate.callCount())
+
+	_, err := repo.Proposals(&proposal.Filter{ServiceType: "scraping"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, delegate.callCount(), "other filter must not reuse cached proposals")
+
+	now = now.Add(time.Minute)
+	_, err = repo.Proposals(&proposal.Filter{ServiceType: "wireguard"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, delegate.callCount(), "expired proposals must be fetched again")
+}
+
+func TestCachedRepository_SharesConcurrentFetch(t *testing.T) {
+	delegate := &countingRepository{
+		mockRepository: mockRepository{proposalsToReturn: []market.ServiceProposal{mockProposal}},
+		release:        make(chan struct{}),
+	}
+	repo := NewCachedRepository(delegate, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			proposals, err := repo.Proposals(&proposal.Filter{})
+			assert.NoError(t, err)
+			assert.Len(t, proposals, 1)
+		}()
+	}
+	assert.Eventually(t, func() bool { return delegate.callCount() == 1 }, time.Second, time.Millisecond)
+	close(delegate.release)
+	wg.Wait()
+
+	assert.Equal(t, 1, delegate.callCount())
+}
+
+func TestCachedRepository_DoesNotCacheFailures(t *testing.T) {
+	delegate := &countingRepository{mockRepository: mockRepository{errToReturn: errors.New("discovery unavailable")}}
+	repo := NewCachedRepository(delegate, time.Minute)
+
+	_, err := repo.Proposals(&proposal.Filter{})
+	assert.Error(t, err)
+
+	delegate.errToReturn = nil
+	delegate.proposalsToReturn = []market.ServiceProposal{mockProposal}
+	proposals, err := repo.Proposals(&proposal.Filter{})
+	require.NoError(t, err)
+	assert.Len(t, proposals, 1)
+	assert.Equal(t, 2, delegate.callCount())
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
@@ -52,12 +53,14 @@ type Discovery struct {
 	ownIdentity      identity.Identity
 	proposalRegistry ProposalRegistry
 	proposalPingTTL  time.Duration
+	pingSchedule     *pingSchedule
 	signerCreate     identity.SignerFactory
 	signer           identity.Signer
 	proposal         func() market.ServiceProposal
 	eventBus         eventbus.EventBus
 
 	statusChan                  chan Status
+	brokerReconnected           chan struct{}
 	status                      Status
 	proposalAnnouncementStopped *sync.WaitGroup
 	stop                        chan struct{}
@@ -78,9 +81,11 @@ func NewService(
 		identityRegistry:            identityRegistry,
 		proposalRegistry:            proposalRegistry,
 		proposalPingTTL:             proposalPingTTL,
+		pingSchedule:                newPingSchedule(proposalPingTTL),
 		eventBus:                    eventBus,
 		signerCreate:                signerCreate,
 		statusChan:                  make(chan Status),
+		brokerReconnected:           make(chan struct{}, 1),
 		status:                      StatusUndefined,
 		proposalAnnouncementStopped: &sync.WaitGroup{},
 		stop:                        make(chan struct{}),
@@ -99,6 +104,10 @@ func (d *Discovery) Start(ownIdentity identity.Identity, proposal func() market.
 
 	d.proposalAnnouncementStopped.Add(1)
 
+	if err := d.eventBus.SubscribeAsync(nats.AppTopicConnectionState, d.handleBrokerState); err != nil {
+		log.Warn().Err(err).Msg("Failed to subscribe to broker state, proposal won't be re-announced on reconnect")
+	}
+
 	go d.checkRegistration()
 
 	go d.mainDiscoveryLoop()
@@ -109,6 +118,11 @@ func (d *Discovery) Wait() {
 	d.proposalAnnouncementStopped.Wait()
 }
 
+// PingStats returns proposal ping outcomes.
+func (d *Discovery) PingStats() PingStats {
+	return d.pingSchedule.snapshot()
+}
+
 // Stop stops discovery loop
 func (d *Discovery) Stop() {
 	d.once.Do(func() {
@@ -203,14 +217,46 @@ func (d *Discovery) pingProposal() {
 	select {
 	case <-d.stop:
 		return
-	case <-time.After(d.proposalPingTTL):
-		proposal := d.proposal()
-		err := d.proposalRegistry.PingProposal(proposal, d.signer)
-		if err != nil {
-			log.Error().Err(err).Msg("Failed to ping proposal")
+	case <-d.brokerReconnected:
+		delay := d.pingSchedule.reconnectDelay()
+		log.Info().Msgf("Broker reconnected, re-announcing proposal in %s", delay)
+		select {
+		case <-d.stop:
+			return
+		case <-time.After(delay):
 		}
-		d.eventBus.Publish(AppTopicProposalAnnounce, proposal)
-		d.changeStatus(PingProposal)
+	case <-time.After(d.pingSchedule.next()):
+	}
+
+	proposal := d.proposal()
+	err := d.proposalRegistry.PingProposal(proposal, d.signer)
+
+	var stats PingStats
+	if err != nil {
+		stats = d.pingSchedule.failure()
+		log.Error().Err(err).Msgf("Failed to ping proposal, retrying in %s", stats.Interval)
+	} else {
+		stats = d.pingSchedule.success()
+	}
+	log.Debug().Msgf("Proposal ping success rate %.2f (%d/%d)", stats.SuccessRate(), stats.Succeeded, stats.Succeeded+stats.Failed)
+
+	d.eventBus.Publish(AppTopicProposalPing, AppEventProposalPing{
+		ProviderID:  proposal.ProviderID,
+		ServiceType: proposal.ServiceType,
+		Success:     err == nil,
+		Stats:       stats,
+	})
+	d.eventBus.Publish(AppTopicProposalAnnounce, proposal)
+	d.changeStatus(PingProposal)
+}
+
+func (d *Discovery) handleBrokerState(e nats.ConnectionStateEvent) {
+	if e.State != nats.StateReconnected {
+		return
+	}
+	select {
+	case d.brokerReconnected <- struct{}{}:
+	default:
 	}
 }
 
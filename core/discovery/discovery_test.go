@@ -24,6 +24,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	identityregistry "github.com/mysteriumnetwork/node/identity/registry"
@@ -40,12 +41,14 @@ var (
 func discoveryWithMockedDependencies() *Discovery {
 	return &Discovery{
 		statusChan:                  make(chan Status),
+		brokerReconnected:           make(chan struct{}, 1),
 		proposalAnnouncementStopped: &sync.WaitGroup{},
 		signerCreate: func(id identity.Identity) identity.Signer {
 			return &identity.SignerFake{}
 		},
 		proposalRegistry: &mockedProposalRegistry{},
 		proposalPingTTL:  1 * time.Minute,
+		pingSchedule:     newPingSchedule(1 * time.Minute),
 		eventBus:         eventbus.New(),
 		stop:             make(chan struct{}),
 	}
@@ -114,6 +117,34 @@ func TestStartStopUnregisterProposal(t *testing.T) {
 	assert.Equal(t, ProposalUnregistered, actualStatus)
 }
 
+func TestBrokerReconnectReannouncesProposal(t *testing.T) {
+	d := discoveryWithMockedDependencies()
+	d.identityRegistry = &identityregistry.FakeRegistry{RegistrationStatus: identityregistry.Registered}
+	d.pingSchedule.random = func() float64 { return 0 }
+
+	pings := make(chan AppEventProposalPing, 1)
+	err := d.eventBus.Subscribe(AppTopicProposalPing, func(e AppEventProposalPing) {
+		pings <- e
+	})
+	assert.NoError(t, err)
+
+	d.Start(providerID, func() market.ServiceProposal { return serviceProposal })
+	defer d.Stop()
+	observeStatus(d, PingProposal)
+
+	d.eventBus.Publish(nats.AppTopicConnectionState, nats.ConnectionStateEvent{State: nats.StateReconnected})
+
+	select {
+	case e := <-pings:
+		assert.True(t, e.Success)
+		assert.Equal(t, providerID.Address, e.ProviderID)
+		assert.Equal(t, uint64(1), e.Stats.Succeeded)
+		assert.Equal(t, 1.0, d.PingStats().SuccessRate())
+	case <-time.After(2 * time.Second):
+		t.Fatal("proposal was not re-announced after broker reconnect")
+	}
+}
+
 func observeStatus(d *Discovery, status Status) Status {
 	for {
 		d.mu.RLock()
@@ -27,4 +27,14 @@ const (
 	AppTopicProposalRemoved = "ProposalRemoved"
 	// AppTopicProposalAnnounce represent proposal events topic.
 	AppTopicProposalAnnounce = "proposalEvent"
+	// AppTopicProposalPing represents proposal ping outcome.
+	AppTopicProposalPing = "ProposalPing"
 )
+
+// AppEventProposalPing is published after each proposal ping.
+type AppEventProposalPing struct {
+	ProviderID  string
+	ServiceType string
+	Success     bool
+	Stats       PingStats
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// pingJitter is the share of the ping interval which is randomized,
+	// so that providers don't ping in lockstep after broker restarts.
+	pingJitter = 0.2
+	// pingMinIntervalDivisor bounds how far the interval shrinks on failures.
+	pingMinIntervalDivisor = 8
+)
+
+// PingStats describes proposal ping outcomes of a single discovery service.
+type PingStats struct {
+	Succeeded uint64
+	Failed    uint64
+	// Interval is the current delay between pings before jitter.
+	Interval time.Duration
+}
+
+// SuccessRate returns the share of successful pings, 1 when nothing was sent yet.
+func (s PingStats) SuccessRate() float64 {
+	total := s.Succeeded + s.Failed
+	if total == 0 {
+		return 1
+	}
+	return float64(s.Succeeded) / float64(total)
+}
+
+// pingSchedule adapts proposal ping interval to registry feedback. Failed pings
+// halve the interval so the proposal is refreshed before discovery expires it,
+// successful ones double it back up to the configured TTL, which it never exceeds.
+type pingSchedule struct {
+	max, min time.Duration
+	random   func() float64
+
+	mu    sync.Mutex
+	stats PingStats
+}
+
+func newPingSchedule(ttl time.Duration) *pingSchedule {
+	return &pingSchedule{
+		max:    ttl,
+		min:    ttl / pingMinIntervalDivisor,
+		random: rand.Float64,
+		stats:  PingStats{Interval: ttl},
+	}
+}
+
+// next returns the delay before the next ping. Jitter only shortens it,
+// a late ping could let discovery expire the proposal.
+func (s *pingSchedule) next() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stats.Interval - time.Duration(s.random()*pingJitter*float64(s.stats.Interval))
+}
+
+// reconnectDelay returns a random delay before re-announcing the proposal after
+// broker reconnects, spreading the load of all providers coming back at once.
+func (s *pingSchedule) reconnectDelay() time.Duration {
+	return time.Duration(s.random() * float64(s.min))
+}
+
+func (s *pingSchedule) success() PingStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.Succeeded++
+	s.stats.Interval *= 2
+	if s.stats.Interval > s.max {
+		s.stats.Interval = s.max
+	}
+	return s.stats
+}
+
+func (s *pingSchedule) failure() PingStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.Failed++
+	s.stats.Interval /= 2
+	if s.stats.Interval < s.min {
+		s.stats.Interval = s.min
+	}
+	return s.stats
+}
+
+func (s *pingSchedule) snapshot() PingStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stats
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_pingSchedule_AdaptsToFeedback(t *testing.T) {
+	s := newPingSchedule(80 * time.Second)
+	s.random = func() float64 { return 0 }
+	assert.Equal(t, 80*time.Second, s.next())
+
+	assert.Equal(t, 40*time.Second, s.failure().Interval)
+	assert.Equal(t, 20*time.Second, s.failure().Interval)
+	assert.Equal(t, 10*time.Second, s.failure().Interval)
+	assert.Equal(t, 10*time.Second, s.failure().Interval, "interval is bounded from below")
+
+	assert.Equal(t, 20*time.Second, s.success().Interval)
+	assert.Equal(t, 40*time.Second, s.success().Interval)
+	assert.Equal(t, 80*time.Second, s.success().Interval)
+	assert.Equal(t, 80*time.Second, s.success().Interval, "interval never exceeds ttl")
+
+	stats := s.snapshot()
+	assert.Equal(t, uint64(4), stats.Succeeded)
+	assert.Equal(t, uint64(4), stats.Failed)
+	assert.Equal(t, 0.5, stats.SuccessRate())
+}
+
+func Test_pingSchedule_JitterOnlyShortensInterval(t *testing.T) {
+	s := newPingSchedule(time.Minute)
+
+	s.random = func() float64 { return 1 }
+	assert.Equal(t, 48*time.Second, s.next())
+	assert.Equal(t, 7500*time.Millisecond, s.reconnectDelay())
+
+	s.random = func() float64 { return 0 }
+	assert.Equal(t, time.Minute, s.next())
+	assert.Equal(t, time.Duration(0), s.reconnectDelay())
+}
+
+func Test_PingStats_SuccessRateWithoutPings(t *testing.T) {
+	assert.Equal(t, 1.0, PingStats{}.SuccessRate())
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
 	"github.com/mysteriumnetwork/node/market"
 )
 
@@ -29,6 +30,11 @@ type PricedServiceProposalRepository struct {
 	baseRepo      proposal.Repository
 	pip           PriceInfoProvider
 	filterPresets proposal.FilterPresetRepository
+	origin        originProvider
+}
+
+type originProvider interface {
+	GetOrigin() locationstate.Location
 }
 
 // PriceInfoProvider allows to fetch the current pricing for services.
@@ -37,11 +43,12 @@ type PriceInfoProvider interface {
 }
 
 // NewPricedServiceProposalRepository returns a new instance of PricedServiceProposalRepository.
-func NewPricedServiceProposalRepository(baseRepo proposal.Repository, pip PriceInfoProvider, filterPresets proposal.FilterPresetRepository) *PricedServiceProposalRepository {
+func NewPricedServiceProposalRepository(baseRepo proposal.Repository, pip PriceInfoProvider, filterPresets proposal.FilterPresetRepository, origin originProvider) *PricedServiceProposalRepository {
 	return &PricedServiceProposalRepository{
 		baseRepo:      baseRepo,
 		pip:           pip,
 		filterPresets: filterPresets,
+		origin:        origin,
 	}
 }
 
@@ -105,6 +112,24 @@ func (pspr *PricedServiceProposalRepository) toPricedProposals(in []market.Servi
 }
 
 func (pspr *PricedServiceProposalRepository) toPricedProposal(in market.ServiceProposal) (proposal.PricedServiceProposal, error) {
+	if in.PriceBook != nil && pspr.origin != nil {
+		// Provider price book overrides network prices for consumers it lists.
+		if price, ok := in.PriceBook.Lookup(in.ServiceType, pspr.origin.GetOrigin().Country); ok {
+			return proposal.PricedServiceProposal{
+				ServiceProposal: in,
+				Price:           price,
+			}, nil
+		}
+	}
+
+	if in.Price != nil && in.Price.PricePerHour != nil && in.Price.PricePerGiB != nil {
+		// Provider set price overrides network prices.
+		return proposal.PricedServiceProposal{
+			ServiceProposal: in,
+			Price:           *in.Price,
+		}, nil
+	}
+
 	price, err := pspr.pip.GetCurrentPrice(in.Location.IPType, in.Location.Country, in.ServiceType)
 	if err != nil {
 		return proposal.PricedServiceProposal{}, err
@@ -25,13 +25,14 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
 	"github.com/mysteriumnetwork/node/market"
 )
 
 var mockProposal = market.ServiceProposal{
 	ID:            1,
 	Format:        "good format, i like it",
-	Compatibility: 2,
+	Compatibility: 3,
 	ProviderID:    "0x0",
 	ServiceType:   "much service",
 	Location: market.Location{
@@ -68,7 +69,7 @@ func TestGetProposal(t *testing.T) {
 			errToReturn:      nil,
 		}
 
-		repo := NewPricedServiceProposalRepository(mr, mp, presetRepository)
+		repo := NewPricedServiceProposalRepository(mr, mp, presetRepository, nil)
 
 		result, err := repo.Proposal(market.ProposalID{})
 		assert.NoError(t, err)
@@ -82,7 +83,7 @@ func TestGetProposal(t *testing.T) {
 			errToReturn: mockError,
 		}
 
-		repo := NewPricedServiceProposalRepository(mr, &mockPriceInfoProvider{}, presetRepository)
+		repo := NewPricedServiceProposalRepository(mr, &mockPriceInfoProvider{}, presetRepository, nil)
 		_, err := repo.Proposal(market.ProposalID{})
 		assert.Error(t, err)
 		assert.Equal(t, mockError, err)
@@ -94,12 +95,23 @@ func TestGetProposal(t *testing.T) {
 		}
 		repo := NewPricedServiceProposalRepository(&mockRepository{
 			proposalToReturn: &mockProposal,
-		}, mp, nil)
+		}, mp, nil, nil)
 
 		_, err := repo.Proposal(market.ProposalID{})
 		assert.Error(t, err)
 		assert.Equal(t, mockError, err)
 	})
+	t.Run("prefers provider price", func(t *testing.T) {
+		priced := mockProposal
+		priced.Price = market.NewPrice(3, 4)
+		repo := NewPricedServiceProposalRepository(&mockRepository{
+			proposalToReturn: &priced,
+		}, &mockPriceInfoProvider{errorToReturn: errors.New("boom")}, presetRepository, nil)
+
+		result, err := repo.Proposal(market.ProposalID{})
+		assert.NoError(t, err)
+		assert.EqualValues(t, *market.NewPrice(3, 4), result.Price)
+	})
 }
 
 func TestGetProposals(t *testing.T) {
@@ -117,7 +129,7 @@ func TestGetProposals(t *testing.T) {
 			errToReturn:       nil,
 		}
 
-		repo := NewPricedServiceProposalRepository(mr, mp, presetRepository)
+		repo := NewPricedServiceProposalRepository(mr, mp, presetRepository, nil)
 
 		result, err := repo.Proposals(nil)
 		assert.NoError(t, err)
@@ -131,11 +143,33 @@ func TestGetProposals(t *testing.T) {
 			errToReturn: mockError,
 		}
 
-		repo := NewPricedServiceProposalRepository(mr, &mockPriceInfoProvider{}, presetRepository)
+		repo := NewPricedServiceProposalRepository(mr, &mockPriceInfoProvider{}, presetRepository, nil)
 		_, err := repo.Proposals(nil)
 		assert.Error(t, err)
 		assert.Equal(t, mockError, err)
 	})
+	t.Run("uses provider price book for consumer origin", func(t *testing.T) {
+		booked := mockProposal
+		booked.PriceBook = &market.PriceBook{Entries: []market.PriceBookEntry{
+			{Country: "LT", Price: *market.NewPrice(3, 4)},
+		}}
+		mp := &mockPriceInfoProvider{
+			priceToReturn: *market.NewPrice(1, 2),
+		}
+		mr := &mockRepository{
+			proposalsToReturn: []market.ServiceProposal{booked},
+		}
+
+		repo := NewPricedServiceProposalRepository(mr, mp, presetRepository, mockOrigin{country: "LT"})
+		result, err := repo.Proposals(nil)
+		assert.NoError(t, err)
+		assert.EqualValues(t, *market.NewPrice(3, 4), result[0].Price)
+
+		repo = NewPricedServiceProposalRepository(mr, mp, presetRepository, mockOrigin{country: "DE"})
+		result, err = repo.Proposals(nil)
+		assert.NoError(t, err)
+		assert.EqualValues(t, *market.NewPrice(1, 2), result[0].Price)
+	})
 	t.Run("skips if price errors", func(t *testing.T) {
 		mockError := errors.New("boom")
 		mp := &mockPriceInfoProvider{
@@ -143,7 +177,7 @@ func TestGetProposals(t *testing.T) {
 		}
 		repo := NewPricedServiceProposalRepository(&mockRepository{
 			proposalsToReturn: []market.ServiceProposal{mockProposal},
-		}, mp, presetRepository)
+		}, mp, presetRepository, nil)
 
 		res, err := repo.Proposals(nil)
 		assert.NoError(t, err)
@@ -194,3 +228,11 @@ func (m *mockFilterPresetRepository) Get(id int) (*proposal.FilterPreset, error)
 	}
 	return nil, errors.New("preset not found")
 }
+
+type mockOrigin struct {
+	country string
+}
+
+func (mo mockOrigin) GetOrigin() locationstate.Location {
+	return locationstate.Location{Country: mo.country}
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"fmt"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/metadata"
+)
+
+// privateNetworkRepository limits proposals to providers allowed in a private network.
+type privateNetworkRepository struct {
+	delegate proposal.Repository
+	network  *metadata.PrivateNetwork
+}
+
+// NewPrivateNetworkRepository wraps the given repository to return proposals of private network providers only.
+func NewPrivateNetworkRepository(delegate proposal.Repository, network *metadata.PrivateNetwork) *privateNetworkRepository {
+	return &privateNetworkRepository{
+		delegate: delegate,
+		network:  network,
+	}
+}
+
+// Proposal returns a single proposal by its ID.
+func (r *privateNetworkRepository) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
+	if !r.network.IsIdentityAllowed(id.ProviderID) {
+		return nil, fmt.Errorf("provider %s is not allowed in private network %s", id.ProviderID, r.network.Name)
+	}
+	return r.delegate.Proposal(id)
+}
+
+// Proposals returns proposals of allowed providers matching the filter.
+func (r *privateNetworkRepository) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
+	proposals, err := r.delegate.Proposals(filter)
+
+	allowed := make([]market.ServiceProposal, 0, len(proposals))
+	for _, p := range proposals {
+		if r.network.IsIdentityAllowed(p.ProviderID) {
+			allowed = append(allowed, p)
+		}
+	}
+	return allowed, err
+}
+
+// Countries returns proposals of allowed providers per country matching the filter.
+func (r *privateNetworkRepository) Countries(filter *proposal.Filter) (map[string]int, error) {
+	proposals, err := r.Proposals(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	countries := make(map[string]int)
+	for _, p := range proposals {
+		countries[p.Location.Country]++
+	}
+	return countries, nil
+}
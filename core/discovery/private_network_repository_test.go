@@ -0,0 +1,57 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/metadata"
+)
+
+func TestPrivateNetworkRepository(t *testing.T) {
+	allowedProvider := "0x000000000000000000000000000000000000000a"
+	otherProvider := "0x000000000000000000000000000000000000000b"
+
+	allowedProposal := mockProposal
+	allowedProposal.ProviderID = allowedProvider
+	otherProposal := mockProposal
+	otherProposal.ProviderID = otherProvider
+
+	repo := NewPrivateNetworkRepository(&mockRepository{
+		proposalsToReturn: []market.ServiceProposal{allowedProposal, otherProposal},
+		proposalToReturn:  &otherProposal,
+	}, &metadata.PrivateNetwork{
+		Name:              "corp",
+		AllowedIdentities: []string{allowedProvider},
+	})
+
+	proposals, err := repo.Proposals(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []market.ServiceProposal{allowedProposal}, proposals)
+
+	countries, err := repo.Countries(nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"yes": 1}, countries)
+
+	_, err = repo.Proposal(market.ProposalID{ProviderID: otherProvider, ServiceType: "much service"})
+	assert.Error(t, err)
+}
@@ -29,6 +29,7 @@ const (
 	SortTypeLatency   = "latency"
 	SortTypePrice     = "price"
 	SortTypeQuality   = "quality"
+	SortTypeLoad      = "load"
 )
 
 // ErrUnsupportedSortType indicates unsupported proposals sorting type error.
@@ -48,6 +49,8 @@ func Sort(proposals []PricedServiceProposal, sortType string) ([]PricedServicePr
 		return SortByPrice(proposals), nil
 	case SortTypeQuality:
 		return SortByQuality(proposals), nil
+	case SortTypeLoad:
+		return SortByLoad(proposals), nil
 	case "": // Assuming zero value to be no sorting.
 		return proposals, nil
 	default:
@@ -114,3 +117,22 @@ func SortByPrice(proposals []PricedServiceProposal) []PricedServiceProposal {
 
 	return tmp
 }
+
+// SortByLoad sorts proposals list based on advertised provider utilization, least loaded first.
+// Proposals without advertised load are considered idle.
+func SortByLoad(proposals []PricedServiceProposal) []PricedServiceProposal {
+	tmp := make([]PricedServiceProposal, len(proposals))
+	copy(tmp, proposals)
+
+	utilization := func(p PricedServiceProposal) float64 {
+		if p.Load == nil {
+			return 0
+		}
+		return p.Load.Utilization()
+	}
+	sort.SliceStable(tmp, func(i, j int) bool {
+		return utilization(tmp[i]) < utilization(tmp[j])
+	})
+
+	return tmp
+}
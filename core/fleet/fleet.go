@@ -0,0 +1,353 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package fleet
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+)
+
+// Operations supported in bulk requests.
+const (
+	OpApplyConfig     = "apply_config"
+	OpRotateToken     = "rotate_token"
+	OpRestartServices = "restart_services"
+	OpHealth          = "health"
+)
+
+// Operation statuses.
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// allowedConfigKeys are user config keys bulk requests may set. Keys able to run commands,
+// replace binaries or change trust anchors (hooks, updater, openvpn.binary, blocklist signer)
+// are deliberately left out, so a leaked token can't be turned into code execution.
+var allowedConfigKeys = map[string]struct{}{
+	"wireguard.listen.ports":                {},
+	"wireguard.allowed.subnet":              {},
+	"wireguard.access-policies":             {},
+	"wireguard.rekey-interval":              {},
+	"openvpn.port":                          {},
+	"openvpn.proto":                         {},
+	"openvpn.subnet":                        {},
+	"openvpn.netmask":                       {},
+	"openvpn.access-policies":               {},
+	"noop.access-policies":                  {},
+	"speedtest.access-policies":             {},
+	"service.max-sessions":                  {},
+	"service.bandwidth-capacity":            {},
+	"service.schedule":                      {},
+	"service.health.interval":               {},
+	"service.health.failures":               {},
+	"service.health.max-restarts":           {},
+	"shaper.enabled":                        {},
+	"shaper.bandwidth":                      {},
+	"access-policy.list":                    {},
+	"access-policy.block":                   {},
+	"payment.price-gib":                     {},
+	"payment.price-hour":                    {},
+	"pricing.auto":                          {},
+	"pricing.auto-services":                 {},
+	"pricing.auto-interval":                 {},
+	"pricing.auto-percentile":               {},
+	"pricing.auto-max-change":               {},
+	"pricing.auto-min-per-gib":              {},
+	"pricing.auto-max-per-gib":              {},
+	"pricing.auto-min-per-hour":             {},
+	"pricing.auto-max-per-hour":             {},
+	"payments.provider.free-tier-megabytes": {},
+	"payments.provider.free-tier-minutes":   {},
+}
+
+// Operation is a single step of a bulk request.
+type Operation struct {
+	Op string
+	// Config is a bundle of user config values for OpApplyConfig, nil values remove the key.
+	Config map[string]interface{}
+	// ServiceTypes limits OpRestartServices to given types, all running services are restarted if empty.
+	ServiceTypes []string
+}
+
+// Result is an outcome of a single operation.
+type Result struct {
+	Op     string
+	Status string
+	Error  string
+	// Token is set by OpRotateToken.
+	Token *auth.JWT
+	// Restarted is set by OpRestartServices.
+	Restarted []RestartedService
+	// Health is set by OpHealth.
+	Health *Health
+}
+
+// RestartedService describes a service replaced by a restart.
+type RestartedService struct {
+	Type  string
+	OldID service.ID
+	NewID service.ID
+}
+
+// Health is a summary of the node health.
+type Health struct {
+	Uptime         time.Duration
+	Monitoring     node.MonitoringStatus
+	Services       []ServiceHealth
+	ActiveSessions int
+}
+
+// ServiceHealth describes state of a single service.
+type ServiceHealth struct {
+	ID     service.ID
+	Type   string
+	Status servicestate.State
+}
+
+// OptionsResolver returns access policies and options to restart service of given type with.
+type OptionsResolver func(serviceType string) (policies []string, options service.Options, err error)
+
+type configStore interface {
+	SetUser(key string, value interface{})
+	RemoveUser(key string)
+	SaveUserConfig() error
+}
+
+type serviceManager interface {
+	List(includeAll bool) []*service.Instance
+	Restart(id service.ID, policies []string, options service.Options) (service.ID, error)
+}
+
+type tokenIssuer interface {
+	SetEncryptionKey(key auth.JWTEncryptionKey)
+	CreateToken(username string) (auth.JWT, error)
+}
+
+type sessionPool interface {
+	GetAll() []*service.Session
+}
+
+type monitoringStatusProvider interface {
+	Status() node.MonitoringStatus
+}
+
+// Operator executes bulk operations issued by an external fleet orchestrator.
+type Operator struct {
+	config     configStore
+	services   serviceManager
+	options    OptionsResolver
+	storage    auth.Storage
+	tokens     tokenIssuer
+	username   string
+	sessions   sessionPool
+	monitoring monitoringStatusProvider
+	startedAt  time.Time
+}
+
+// NewOperator creates fleet operator, tokens are issued for the given API username.
+func NewOperator(
+	config configStore,
+	services serviceManager,
+	options OptionsResolver,
+	storage auth.Storage,
+	tokens tokenIssuer,
+	username string,
+	sessions sessionPool,
+	monitoring monitoringStatusProvider,
+) *Operator {
+	return &Operator{
+		config:     config,
+		services:   services,
+		options:    options,
+		storage:    storage,
+		tokens:     tokens,
+		username:   username,
+		sessions:   sessions,
+		monitoring: monitoring,
+		startedAt:  time.Now(),
+	}
+}
+
+// Run executes operations in order. Once an operation fails the remaining ones
+// are skipped, unless continueOnError is set.
+func (o *Operator) Run(ops []Operation, continueOnError bool) []Result {
+	results := make([]Result, 0, len(ops))
+	failed := false
+	for _, op := range ops {
+		if failed && !continueOnError {
+			results = append(results, Result{Op: op.Op, Status: StatusSkipped})
+			continue
+		}
+
+		res := o.run(op)
+		if res.Status == StatusFailed {
+			log.Warn().Msgf("Fleet operation %s failed: %s", op.Op, res.Error)
+			failed = true
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+func (o *Operator) run(op Operation) Result {
+	res := Result{Op: op.Op, Status: StatusOK}
+
+	var err error
+	switch op.Op {
+	case OpApplyConfig:
+		err = o.ApplyConfig(op.Config)
+	case OpRotateToken:
+		var token auth.JWT
+		token, err = o.RotateToken()
+		if err == nil {
+			res.Token = &token
+		}
+	case OpRestartServices:
+		res.Restarted, err = o.RestartServices(op.ServiceTypes)
+	case OpHealth:
+		health := o.Health()
+		res.Health = &health
+	default:
+		err = fmt.Errorf("unknown operation: %q", op.Op)
+	}
+
+	if err != nil {
+		res.Status = StatusFailed
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// ApplyConfig sets user config values from the bundle and persists them.
+// Nothing is applied if the bundle contains a key which is not allowed.
+func (o *Operator) ApplyConfig(bundle map[string]interface{}) error {
+	if len(bundle) == 0 {
+		return errors.New("config bundle is empty")
+	}
+
+	var denied []string
+	for key := range bundle {
+		if _, ok := allowedConfigKeys[key]; !ok {
+			denied = append(denied, key)
+		}
+	}
+	if len(denied) > 0 {
+		sort.Strings(denied)
+		return fmt.Errorf("config keys are not allowed: %v", denied)
+	}
+
+	for key, value := range bundle {
+		if value == nil {
+			o.config.RemoveUser(key)
+		} else {
+			o.config.SetUser(key, value)
+		}
+	}
+	return errors.Wrap(o.config.SaveUserConfig(), "failed to save config")
+}
+
+// RotateToken replaces the API token encryption key and issues a new token.
+// All previously issued tokens become invalid.
+func (o *Operator) RotateToken() (auth.JWT, error) {
+	key, err := auth.RotateJWTEncryptionKey(o.storage)
+	if err != nil {
+		return auth.JWT{}, err
+	}
+	o.tokens.SetEncryptionKey(key)
+
+	token, err := o.tokens.CreateToken(o.username)
+	return token, errors.Wrap(err, "failed to issue token")
+}
+
+// RestartServices gracefully restarts running services of given types with
+// options resolved from current config, so applied config bundles take effect.
+func (o *Operator) RestartServices(types []string) ([]RestartedService, error) {
+	restarted := make([]RestartedService, 0)
+	var failures []string
+	for _, instance := range o.services.List(false) {
+		if !matchesType(instance.Type, types) || instance.State() != servicestate.Running {
+			continue
+		}
+
+		policies, options, err := o.options(instance.Type)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", instance.ID, err))
+			continue
+		}
+
+		newID, err := o.services.Restart(instance.ID, policies, options)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", instance.ID, err))
+			continue
+		}
+		restarted = append(restarted, RestartedService{Type: instance.Type, OldID: instance.ID, NewID: newID})
+	}
+
+	if len(failures) > 0 {
+		return restarted, fmt.Errorf("failed to restart services: %v", failures)
+	}
+	return restarted, nil
+}
+
+// Health returns summary of the node health.
+func (o *Operator) Health() Health {
+	health := Health{
+		Uptime:     time.Since(o.startedAt).Truncate(time.Second),
+		Monitoring: o.monitoring.Status(),
+		Services:   make([]ServiceHealth, 0),
+	}
+
+	for _, instance := range o.services.List(false) {
+		health.Services = append(health.Services, ServiceHealth{
+			ID:     instance.ID,
+			Type:   instance.Type,
+			Status: instance.State(),
+		})
+	}
+	sort.Slice(health.Services, func(i, j int) bool {
+		if health.Services[i].Type != health.Services[j].Type {
+			return health.Services[i].Type < health.Services[j].Type
+		}
+		return health.Services[i].ID < health.Services[j].ID
+	})
+
+	health.ActiveSessions = len(o.sessions.GetAll())
+	return health
+}
+
+func matchesType(serviceType string, types []string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if t == serviceType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package fleet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+func TestOperator_Run(t *testing.T) {
+	cfg := &mockConfig{values: map[string]interface{}{"shaper.enabled": true}}
+	services := &mockServices{instances: []*service.Instance{
+		newInstance("1", "wireguard", servicestate.Running),
+		newInstance("2", "scraping", servicestate.Running),
+		newInstance("3", "noop", servicestate.Starting),
+		newInstance("0", "wireguard", servicestate.NotRunning),
+	}}
+	jwt := auth.NewJWTAuthenticator([]byte("old-key"))
+	oldToken, err := jwt.CreateToken("myst")
+	require.NoError(t, err)
+	storage := &mockStorage{}
+
+	operator := NewOperator(cfg, services, resolveOptions, storage, jwt, "myst",
+		&mockSessions{sessions: make([]*service.Session, 2)},
+		&mockMonitoring{status: node.Passed},
+	)
+
+	results := operator.Run([]Operation{
+		{Op: OpApplyConfig, Config: map[string]interface{}{"shaper.enabled": nil, "wireguard.listen.ports": "51820:51830"}},
+		{Op: OpRotateToken},
+		{Op: OpRestartServices, ServiceTypes: []string{"wireguard", "noop"}},
+		{Op: OpHealth},
+	}, false)
+
+	require.Len(t, results, 4)
+	for _, res := range results {
+		assert.Equal(t, StatusOK, res.Status, res.Op)
+	}
+
+	assert.Equal(t, map[string]interface{}{"wireguard.listen.ports": "51820:51830"}, cfg.values)
+	assert.True(t, cfg.saved)
+
+	require.NotNil(t, results[1].Token)
+	valid, _ := jwt.ValidateToken(results[1].Token.Token)
+	assert.True(t, valid)
+	valid, _ = jwt.ValidateToken(oldToken.Token)
+	assert.False(t, valid)
+	assert.NotNil(t, storage.key)
+
+	assert.Equal(t, []RestartedService{{Type: "wireguard", OldID: "1", NewID: "1-restarted"}}, results[2].Restarted)
+	assert.Equal(t, []string{"wireguard-policy"}, services.restartPolicies)
+
+	health := results[3].Health
+	require.NotNil(t, health)
+	assert.Equal(t, node.Passed, health.Monitoring)
+	assert.Equal(t, 2, health.ActiveSessions)
+	assert.Equal(t, []ServiceHealth{
+		{ID: "3", Type: "noop", Status: servicestate.Starting},
+		{ID: "2", Type: "scraping", Status: servicestate.Running},
+		{ID: "0", Type: "wireguard", Status: servicestate.NotRunning},
+		{ID: "1", Type: "wireguard", Status: servicestate.Running},
+	}, health.Services)
+}
+
+func TestOperator_ApplyConfig_RejectsKeysOutsideAllowlist(t *testing.T) {
+	cfg := &mockConfig{values: map[string]interface{}{}}
+	operator := NewOperator(cfg, &mockServices{}, resolveOptions, &mockStorage{}, auth.NewJWTAuthenticator([]byte("key")), "myst",
+		&mockSessions{}, &mockMonitoring{status: node.Pending},
+	)
+
+	err := operator.ApplyConfig(map[string]interface{}{
+		"wireguard.listen.ports": "51820:51830",
+		"hooks.on-session-start": "/bin/sh -c evil",
+		"openvpn.binary":         "/tmp/evil",
+	})
+
+	assert.EqualError(t, err, "config keys are not allowed: [hooks.on-session-start openvpn.binary]")
+	assert.Empty(t, cfg.values)
+	assert.False(t, cfg.saved)
+}
+
+func TestOperator_Run_SkipsAfterFailure(t *testing.T) {
+	cfg := &mockConfig{values: map[string]interface{}{}, saveErr: errors.New("disk full")}
+	operator := NewOperator(cfg, &mockServices{}, resolveOptions, &mockStorage{}, auth.NewJWTAuthenticator([]byte("key")), "myst",
+		&mockSessions{}, &mockMonitoring{status: node.Pending},
+	)
+	ops := []Operation{
+		{Op: OpApplyConfig, Config: map[string]interface{}{"shaper.enabled": true}},
+		{Op: OpHealth},
+		{Op: "reboot"},
+	}
+
+	results := operator.Run(ops, false)
+	assert.Equal(t, StatusFailed, results[0].Status)
+	assert.Contains(t, results[0].Error, "disk full")
+	assert.Equal(t, StatusSkipped, results[1].Status)
+	assert.Equal(t, StatusSkipped, results[2].Status)
+
+	results = operator.Run(ops, true)
+	assert.Equal(t, StatusFailed, results[0].Status)
+	assert.Equal(t, StatusOK, results[1].Status)
+	assert.Equal(t, StatusFailed, results[2].Status)
+	assert.Equal(t, `unknown operation: "reboot"`, results[2].Error)
+}
+
+func newInstance(id service.ID, serviceType string, state servicestate.State) *service.Instance {
+	instance := service.NewInstance(identity.FromAddress("0x1"), serviceType, nil, market.ServiceProposal{}, state, nil, nil, nil)
+	instance.ID = id
+	return instance
+}
+
+func resolveOptions(serviceType string) ([]string, service.Options, error) {
+	return []string{serviceType + "-policy"}, nil, nil
+}
+
+type mockConfig struct {
+	values  map[string]interface{}
+	saved   bool
+	saveErr error
+}
+
+func (m *mockConfig) SetUser(key string, value interface{}) { m.values[key] = value }
+func (m *mockConfig) RemoveUser(key string)                 { delete(m.values, key) }
+func (m *mockConfig) SaveUserConfig() error {
+	m.saved = m.saveErr == nil
+	return m.saveErr
+}
+
+type mockServices struct {
+	instances       []*service.Instance
+	restartPolicies []string
+}
+
+func (m *mockServices) List(bool) []*service.Instance { return m.instances }
+func (m *mockServices) Restart(id service.ID, policies []string, _ service.Options) (service.ID, error) {
+	m.restartPolicies = append(m.restartPolicies, policies...)
+	return id + "-restarted", nil
+}
+
+type mockStorage struct {
+	key interface{}
+}
+
+func (m *mockStorage) GetValue(string, interface{}, interface{}) error {
+	return errors.New("not found")
+}
+func (m *mockStorage) SetValue(_ string, _ interface{}, value interface{}) error {
+	m.key = value
+	return nil
+}
+
+type mockSessions struct {
+	sessions []*service.Session
+}
+
+func (m *mockSessions) GetAll() []*service.Session { return m.sessions }
+
+type mockMonitoring struct {
+	status node.MonitoringStatus
+}
+
+func (m *mockMonitoring) Status() node.MonitoringStatus { return m.status }
@@ -0,0 +1,209 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gasprice
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/utils/errcode"
+)
+
+// Strategy selects how fast transactions are expected to be mined.
+type Strategy string
+
+const (
+	// StrategyFast expects transactions to be mined quickly at a higher price.
+	StrategyFast Strategy = "fast"
+	// StrategyStandard expects transactions to be mined at a regular price.
+	StrategyStandard Strategy = "standard"
+	// StrategyEconomy accepts slower mining at a lower price.
+	StrategyEconomy Strategy = "economy"
+)
+
+// ParseStrategy parses gas price strategy name.
+func ParseStrategy(s string) (Strategy, error) {
+	switch strategy := Strategy(s); strategy {
+	case StrategyFast, StrategyStandard, StrategyEconomy:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown gas price strategy %q", s)
+	}
+}
+
+// Sources of gas price quotes.
+const (
+	SourceOracle = "oracle"
+	SourceNode   = "node"
+)
+
+// ErrCodePriceAboveCap is the error code of ErrPriceAboveCap.
+const ErrCodePriceAboveCap = "err_gas_price_above_cap"
+
+// ErrPriceAboveCap indicates that the network gas price is above the configured cap.
+var ErrPriceAboveCap = errcode.New(ErrCodePriceAboveCap, "gas price is above the configured cap",
+	"Wait for lower network fees or raise the gas price cap")
+
+// Prices holds gas prices in wei for each strategy.
+type Prices struct {
+	Fast     *big.Int
+	Standard *big.Int
+	Economy  *big.Int
+}
+
+// For returns the price for the given strategy.
+func (p Prices) For(strategy Strategy) *big.Int {
+	switch strategy {
+	case StrategyFast:
+		return p.Fast
+	case StrategyEconomy:
+		return p.Economy
+	default:
+		return p.Standard
+	}
+}
+
+// Oracle provides gas prices of a chain.
+type Oracle interface {
+	GasPrices(chainID int64) (Prices, error)
+}
+
+type estimator interface {
+	SuggestGasPrice(chainID int64) (*big.Int, error)
+}
+
+// Config configures gas price strategy.
+type Config struct {
+	Strategy Strategy
+	// MaxPrice caps the gas price in wei, no cap if nil.
+	MaxPrice *big.Int
+	// CacheTTL is how long quotes are reused.
+	CacheTTL time.Duration
+}
+
+// Quote is the gas price the node expects to pay on a chain.
+type Quote struct {
+	Price    *big.Int
+	Strategy Strategy
+	Source   string
+	MaxPrice *big.Int
+}
+
+// AboveCap returns true if the quoted price exceeds the cap.
+func (q Quote) AboveCap() bool {
+	return q.MaxPrice != nil && q.Price.Cmp(q.MaxPrice) > 0
+}
+
+type cachedQuote struct {
+	quote     Quote
+	expiresAt time.Time
+}
+
+// Provider quotes gas prices according to the configured strategy.
+// Prices come from the oracle and fall back to the node estimation.
+type Provider struct {
+	config    Config
+	oracle    Oracle
+	estimator estimator
+	now       func() time.Time
+
+	mu    sync.Mutex
+	cache map[int64]cachedQuote
+}
+
+// NewProvider creates gas price provider. Oracle is optional.
+func NewProvider(config Config, oracle Oracle, estimator estimator) *Provider {
+	return &Provider{
+		config:    config,
+		oracle:    oracle,
+		estimator: estimator,
+		now:       time.Now,
+		cache:     make(map[int64]cachedQuote),
+	}
+}
+
+// Quote returns the gas price for the given chain.
+func (p *Provider) Quote(chainID int64) (Quote, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if cached, ok := p.cache[chainID]; ok && p.now().Before(cached.expiresAt) {
+		return cached.quote, nil
+	}
+
+	prices, source, err := p.prices(chainID)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	quote := Quote{
+		Price:    prices.For(p.config.Strategy),
+		Strategy: p.config.Strategy,
+		Source:   source,
+		MaxPrice: p.config.MaxPrice,
+	}
+	p.cache[chainID] = cachedQuote{quote: quote, expiresAt: p.now().Add(p.config.CacheTTL)}
+	return quote, nil
+}
+
+// Check returns ErrPriceAboveCap if transactions should not be sent on the chain now.
+func (p *Provider) Check(chainID int64) error {
+	quote, err := p.Quote(chainID)
+	if err != nil {
+		log.Warn().Err(err).Int64("chain", chainID).Msg("Could not get gas price, skipping gas price check")
+		return nil
+	}
+	if quote.AboveCap() {
+		return ErrPriceAboveCap.Wrap(fmt.Errorf("%s gas price %s wei exceeds cap %s wei", quote.Strategy, quote.Price, quote.MaxPrice))
+	}
+	return nil
+}
+
+func (p *Provider) prices(chainID int64) (Prices, string, error) {
+	if p.oracle != nil {
+		prices, err := p.oracle.GasPrices(chainID)
+		if err == nil {
+			return prices, SourceOracle, nil
+		}
+		log.Warn().Err(err).Int64("chain", chainID).Msg("Gas price oracle failed, falling back to node estimation")
+	}
+
+	suggested, err := p.estimator.SuggestGasPrice(chainID)
+	if err != nil {
+		return Prices{}, "", fmt.Errorf("could not estimate gas price: %w", err)
+	}
+	return estimatedPrices(suggested), SourceNode, nil
+}
+
+// estimatedPrices derives strategy prices from a single node suggestion.
+func estimatedPrices(suggested *big.Int) Prices {
+	return Prices{
+		Fast:     percentOf(suggested, 125),
+		Standard: new(big.Int).Set(suggested),
+		Economy:  percentOf(suggested, 80),
+	}
+}
+
+func percentOf(v *big.Int, percent int64) *big.Int {
+	res := new(big.Int).Mul(v, big.NewInt(percent))
+	return res.Div(res, big.NewInt(100))
+}
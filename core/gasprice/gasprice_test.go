@@ -0,0 +1,123 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gasprice
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/requests"
+)
+
+type mockOracle struct {
+	prices Prices
+	err    error
+	calls  int
+}
+
+func (m *mockOracle) GasPrices(_ int64) (Prices, error) {
+	m.calls++
+	return m.prices, m.err
+}
+
+type mockEstimator struct {
+	price *big.Int
+	err   error
+}
+
+func (m *mockEstimator) SuggestGasPrice(_ int64) (*big.Int, error) {
+	return m.price, m.err
+}
+
+func TestProvider_QuoteUsesOracleAndCaches(t *testing.T) {
+	oracle := &mockOracle{prices: Prices{Fast: big.NewInt(30), Standard: big.NewInt(20), Economy: big.NewInt(10)}}
+	p := NewProvider(Config{Strategy: StrategyFast, CacheTTL: time.Minute}, oracle, &mockEstimator{})
+	now := time.Now()
+	p.now = func() time.Time { return now }
+
+	q, err := p.Quote(137)
+	assert.NoError(t, err)
+	assert.Equal(t, Quote{Price: big.NewInt(30), Strategy: StrategyFast, Source: SourceOracle}, q)
+
+	_, err = p.Quote(137)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, oracle.calls)
+
+	now = now.Add(2 * time.Minute)
+	_, err = p.Quote(137)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, oracle.calls)
+}
+
+func TestProvider_QuoteFallsBackToNode(t *testing.T) {
+	oracle := &mockOracle{err: errors.New("oracle down")}
+	p := NewProvider(Config{Strategy: StrategyEconomy}, oracle, &mockEstimator{price: big.NewInt(100)})
+
+	q, err := p.Quote(137)
+	assert.NoError(t, err)
+	assert.Equal(t, SourceNode, q.Source)
+	assert.Equal(t, big.NewInt(80), q.Price)
+
+	p = NewProvider(Config{Strategy: StrategyFast}, nil, &mockEstimator{err: errors.New("node down")})
+	_, err = p.Quote(137)
+	assert.Error(t, err)
+}
+
+func TestProvider_Check(t *testing.T) {
+	estimator := &mockEstimator{price: big.NewInt(100)}
+	p := NewProvider(Config{Strategy: StrategyStandard, MaxPrice: big.NewInt(100)}, nil, estimator)
+	assert.NoError(t, p.Check(137))
+
+	p = NewProvider(Config{Strategy: StrategyFast, MaxPrice: big.NewInt(100)}, nil, estimator)
+	err := p.Check(137)
+	assert.True(t, errors.Is(err, ErrPriceAboveCap))
+
+	// without a cap nothing is postponed
+	p = NewProvider(Config{Strategy: StrategyFast}, nil, estimator)
+	assert.NoError(t, p.Check(137))
+
+	// unknown price does not block transactions
+	p = NewProvider(Config{Strategy: StrategyFast, MaxPrice: big.NewInt(1)}, nil, &mockEstimator{err: errors.New("node down")})
+	assert.NoError(t, p.Check(137))
+}
+
+func TestHTTPOracle_GasPrices(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		fmt.Fprint(w, `{"safeLow": 30, "standard": 35.5, "fast": 50}`)
+	}))
+	defer server.Close()
+
+	oracle := NewHTTPOracle(requests.NewHTTPClient("0.0.0.0", time.Second), server.URL+"/"+ChainIDPlaceholder+"/gas")
+	prices, err := oracle.GasPrices(137)
+	assert.NoError(t, err)
+	assert.Equal(t, "/137/gas", path)
+	assert.Equal(t, Prices{
+		Fast:     big.NewInt(50_000_000_000),
+		Standard: big.NewInt(35_500_000_000),
+		Economy:  big.NewInt(30_000_000_000),
+	}, prices)
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gasprice
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/mysteriumnetwork/node/requests"
+)
+
+// ChainIDPlaceholder is replaced with the chain ID in the oracle URL.
+const ChainIDPlaceholder = "{chain_id}"
+
+type httpClient interface {
+	DoRequestAndParseResponse(req *http.Request, resp interface{}) error
+}
+
+// HTTPOracle fetches gas prices from a gas station compatible API,
+// which reports prices in gwei.
+type HTTPOracle struct {
+	url    string
+	client httpClient
+}
+
+// NewHTTPOracle creates gas station oracle. The URL may contain ChainIDPlaceholder.
+func NewHTTPOracle(client httpClient, url string) *HTTPOracle {
+	return &HTTPOracle{url: url, client: client}
+}
+
+type gasStationResponse struct {
+	SafeLow  float64 `json:"safeLow"`
+	Standard float64 `json:"standard"`
+	Fast     float64 `json:"fast"`
+}
+
+// GasPrices returns gas prices of the chain.
+func (o *HTTPOracle) GasPrices(chainID int64) (Prices, error) {
+	url := strings.ReplaceAll(o.url, ChainIDPlaceholder, strconv.FormatInt(chainID, 10))
+	req, err := requests.NewGetRequest(url, "", nil)
+	if err != nil {
+		return Prices{}, fmt.Errorf("could not create gas price request: %w", err)
+	}
+
+	var res gasStationResponse
+	if err := o.client.DoRequestAndParseResponse(req, &res); err != nil {
+		return Prices{}, fmt.Errorf("could not fetch gas prices: %w", err)
+	}
+	if res.SafeLow <= 0 || res.Standard <= 0 || res.Fast <= 0 {
+		return Prices{}, fmt.Errorf("gas station returned invalid prices: %+v", res)
+	}
+
+	return Prices{
+		Fast:     GweiToWei(res.Fast),
+		Standard: GweiToWei(res.Standard),
+		Economy:  GweiToWei(res.SafeLow),
+	}, nil
+}
+
+// GweiToWei converts gas price in gwei to wei.
+func GweiToWei(gwei float64) *big.Int {
+	return decimal.NewFromFloat(gwei).Shift(9).BigInt()
+}
@@ -0,0 +1,333 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	sevent "github.com/mysteriumnetwork/node/session/event"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+// Supported hook points.
+const (
+	SessionStart = "on-session-start"
+	SessionEnd   = "on-session-end"
+	Settlement   = "on-settlement"
+)
+
+// Config holds hook targets, each of them is either a http(s) URL or a path to an executable.
+// Empty target disables the hook.
+type Config struct {
+	SessionStart string
+	SessionEnd   string
+	Settlement   string
+	Timeout      time.Duration
+}
+
+// Enabled returns true if at least one hook is configured.
+func (c Config) Enabled() bool {
+	return c.SessionStart != "" || c.SessionEnd != "" || c.Settlement != ""
+}
+
+// Payload is the context passed to hooks: JSON body for URLs, JSON stdin and
+// MYST_HOOK_* environment variables for scripts.
+type Payload struct {
+	Hook            string `json:"hook"`
+	SessionID       string `json:"session_id,omitempty"`
+	ServiceID       string `json:"service_id,omitempty"`
+	ServiceType     string `json:"service_type,omitempty"`
+	ProviderID      string `json:"provider_id,omitempty"`
+	ConsumerID      string `json:"consumer_id,omitempty"`
+	ConsumerCountry string `json:"consumer_country,omitempty"`
+	HermesID        string `json:"hermes_id,omitempty"`
+	ChainID         int64  `json:"chain_id,omitempty"`
+	StartedAt       string `json:"started_at,omitempty"`
+	Duration        int64  `json:"duration,omitempty"`
+	BytesSent       uint64 `json:"bytes_sent,omitempty"`
+	BytesReceived   uint64 `json:"bytes_received,omitempty"`
+	TokensEarned    string `json:"tokens_earned,omitempty"`
+}
+
+func (p Payload) env() []string {
+	vars := map[string]string{
+		"HOOK":             p.Hook,
+		"SESSION_ID":       p.SessionID,
+		"SERVICE_ID":       p.ServiceID,
+		"SERVICE_TYPE":     p.ServiceType,
+		"PROVIDER_ID":      p.ProviderID,
+		"CONSUMER_ID":      p.ConsumerID,
+		"CONSUMER_COUNTRY": p.ConsumerCountry,
+		"HERMES_ID":        p.HermesID,
+		"STARTED_AT":       p.StartedAt,
+		"TOKENS_EARNED":    p.TokensEarned,
+	}
+	if p.ChainID != 0 {
+		vars["CHAIN_ID"] = strconv.FormatInt(p.ChainID, 10)
+	}
+	if p.Hook == SessionEnd {
+		vars["DURATION"] = strconv.FormatInt(p.Duration, 10)
+		vars["BYTES_SENT"] = strconv.FormatUint(p.BytesSent, 10)
+		vars["BYTES_RECEIVED"] = strconv.FormatUint(p.BytesReceived, 10)
+	}
+
+	env := make([]string, 0, len(vars))
+	for k, v := range vars {
+		if v != "" {
+			env = append(env, "MYST_HOOK_"+k+"="+v)
+		}
+	}
+	return env
+}
+
+type httpDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type eventSubscriber interface {
+	Subscribe(topic string, fn interface{}) error
+}
+
+// queueSize limits hooks waiting for execution in a single queue, further hooks are dropped.
+const queueSize = 16
+
+// settlementQueue is the key of the queue executing settlement hooks.
+const settlementQueue = "settlement"
+
+type sessionState struct {
+	payload Payload
+	started time.Time
+}
+
+// Runner executes configured hooks on session and settlement events.
+// Hooks of a session are executed one by one in the order of events, so that
+// session end never runs before session start.
+type Runner struct {
+	config Config
+	http   httpDoer
+	now    func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*sessionState
+	queues   map[string]chan Payload
+}
+
+// NewRunner returns a new hook runner.
+func NewRunner(config Config, http httpDoer) *Runner {
+	return &Runner{
+		config:   config,
+		http:     http,
+		now:      time.Now,
+		sessions: make(map[string]*sessionState),
+		queues:   make(map[string]chan Payload),
+	}
+}
+
+// Subscribe subscribes the runner to events which trigger hooks.
+// Handlers only queue hooks, so they are subscribed synchronously to keep the order of events.
+func (r *Runner) Subscribe(bus eventSubscriber) error {
+	if err := bus.Subscribe(sevent.AppTopicSession, r.consumeSessionEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(sevent.AppTopicDataTransferred, r.consumeDataTransferredEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(sevent.AppTopicTokensEarned, r.consumeTokensEarnedEvent); err != nil {
+		return err
+	}
+	return bus.Subscribe(pingpongEvent.AppTopicSettlementComplete, r.consumeSettlementEvent)
+}
+
+func (r *Runner) consumeSessionEvent(e sevent.AppEventSession) {
+	switch e.Status {
+	case sevent.CreatedStatus:
+		p := Payload{
+			Hook:            SessionStart,
+			SessionID:       e.Session.ID,
+			ServiceID:       e.Service.ID,
+			ServiceType:     e.Session.Proposal.ServiceType,
+			ProviderID:      e.Session.Proposal.ProviderID,
+			ConsumerID:      e.Session.ConsumerID.Address,
+			ConsumerCountry: e.Session.ConsumerLocation.Country,
+			HermesID:        e.Session.HermesID.Hex(),
+			StartedAt:       e.Session.StartedAt.UTC().Format(time.RFC3339),
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.sessions[e.Session.ID] = &sessionState{payload: p, started: e.Session.StartedAt}
+		r.enqueue(e.Session.ID, p, false)
+	case sevent.RemovedStatus:
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		s, ok := r.sessions[e.Session.ID]
+		if !ok {
+			return
+		}
+		delete(r.sessions, e.Session.ID)
+
+		p := s.payload
+		p.Hook = SessionEnd
+		p.Duration = int64(r.now().Sub(s.started).Seconds())
+		r.enqueue(e.Session.ID, p, true)
+	}
+}
+
+func (r *Runner) consumeDataTransferredEvent(e sevent.AppEventDataTransferred) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s, ok := r.sessions[e.ID]; ok {
+		s.payload.BytesSent, s.payload.BytesReceived = e.Up, e.Down
+	}
+}
+
+func (r *Runner) consumeTokensEarnedEvent(e sevent.AppEventTokensEarned) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s, ok := r.sessions[e.SessionID]; ok && e.Total != nil {
+		s.payload.TokensEarned = e.Total.String()
+	}
+}
+
+func (r *Runner) consumeSettlementEvent(e pingpongEvent.AppEventSettlementComplete) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.enqueue(settlementQueue, Payload{
+		Hook:       Settlement,
+		ProviderID: e.ProviderID.Address,
+		HermesID:   e.HermesID.Hex(),
+		ChainID:    e.ChainID,
+	}, false)
+}
+
+// enqueue queues the hook for execution by the worker of the key, starting one if needed.
+// The last hook of the key stops its worker. Must be called with the lock held.
+func (r *Runner) enqueue(key string, p Payload, last bool) {
+	queue, ok := r.queues[key]
+	if !ok {
+		queue = make(chan Payload, queueSize)
+		r.queues[key] = queue
+		go r.work(queue)
+	}
+
+	select {
+	case queue <- p:
+	default:
+		log.Warn().Msgf("Too many pending hooks, skipping hook %s", p.Hook)
+	}
+
+	if last {
+		delete(r.queues, key)
+		close(queue)
+	}
+}
+
+func (r *Runner) work(queue <-chan Payload) {
+	for p := range queue {
+		r.run(p)
+	}
+}
+
+func (r *Runner) target(hook string) string {
+	switch hook {
+	case SessionStart:
+		return r.config.SessionStart
+	case SessionEnd:
+		return r.config.SessionEnd
+	case Settlement:
+		return r.config.Settlement
+	}
+	return ""
+}
+
+func (r *Runner) run(p Payload) {
+	target := r.target(p.Hook)
+	if target == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.config.Timeout)
+	defer cancel()
+
+	var err error
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		err = r.call(ctx, target, p)
+	} else {
+		err = r.exec(ctx, target, p)
+	}
+	if err != nil {
+		log.Warn().Err(err).Msgf("Hook %s failed", p.Hook)
+		return
+	}
+	log.Debug().Msgf("Hook %s executed", p.Hook)
+}
+
+func (r *Runner) call(ctx context.Context, url string, p Payload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("hook endpoint responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (r *Runner) exec(ctx context.Context, path string, p Payload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Env = append(os.Environ(), p.env()...)
+	cmd.Stdin = bytes.NewReader(body)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("hook script failed: %w, output: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
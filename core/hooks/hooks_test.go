@@ -0,0 +1,167 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package hooks
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	sevent "github.com/mysteriumnetwork/node/session/event"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+var (
+	startedAt = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	sessionEvent = sevent.AppEventSession{
+		Service: sevent.ServiceContext{ID: "service1"},
+		Session: sevent.SessionContext{
+			ID:               "session1",
+			StartedAt:        startedAt,
+			ConsumerID:       identity.FromAddress("0xconsumer"),
+			ConsumerLocation: market.Location{Country: "LT"},
+			HermesID:         common.HexToAddress("0x1"),
+			Proposal:         market.ServiceProposal{ProviderID: "0xprovider", ServiceType: "wireguard"},
+		},
+	}
+)
+
+func TestRunner_HTTPHooks(t *testing.T) {
+	received := make(chan Payload, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p Payload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+		received <- p
+	}))
+	defer server.Close()
+
+	runner := NewRunner(Config{
+		SessionStart: server.URL,
+		SessionEnd:   server.URL,
+		Settlement:   server.URL,
+		Timeout:      time.Second,
+	}, http.DefaultClient)
+	runner.now = func() time.Time { return startedAt.Add(time.Minute) }
+
+	start := sessionEvent
+	start.Status = sevent.CreatedStatus
+	runner.consumeSessionEvent(start)
+	assert.Equal(t, Payload{
+		Hook:            SessionStart,
+		SessionID:       "session1",
+		ServiceID:       "service1",
+		ServiceType:     "wireguard",
+		ProviderID:      "0xprovider",
+		ConsumerID:      "0xconsumer",
+		ConsumerCountry: "LT",
+		HermesID:        common.HexToAddress("0x1").Hex(),
+		StartedAt:       "2022-01-01T00:00:00Z",
+	}, <-received)
+
+	runner.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "session1", Up: 100, Down: 200})
+	runner.consumeTokensEarnedEvent(sevent.AppEventTokensEarned{SessionID: "session1", Total: big.NewInt(42)})
+
+	end := sessionEvent
+	end.Status = sevent.RemovedStatus
+	runner.consumeSessionEvent(end)
+	p := <-received
+	assert.Equal(t, SessionEnd, p.Hook)
+	assert.Equal(t, "session1", p.SessionID)
+	assert.Equal(t, int64(60), p.Duration)
+	assert.Equal(t, uint64(100), p.BytesSent)
+	assert.Equal(t, uint64(200), p.BytesReceived)
+	assert.Equal(t, "42", p.TokensEarned)
+
+	runner.consumeSettlementEvent(pingpongEvent.AppEventSettlementComplete{
+		ProviderID: identity.FromAddress("0xprovider"),
+		HermesID:   common.HexToAddress("0x1"),
+		ChainID:    137,
+	})
+	p = <-received
+	assert.Equal(t, Settlement, p.Hook)
+	assert.Equal(t, int64(137), p.ChainID)
+}
+
+func TestRunner_KeepsSessionHookOrder(t *testing.T) {
+	received := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p Payload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+		if p.Hook == SessionStart {
+			time.Sleep(50 * time.Millisecond)
+		}
+		received <- p.Hook
+	}))
+	defer server.Close()
+
+	runner := NewRunner(Config{SessionStart: server.URL, SessionEnd: server.URL, Timeout: time.Second}, http.DefaultClient)
+
+	start := sessionEvent
+	start.Status = sevent.CreatedStatus
+	runner.consumeSessionEvent(start)
+	end := sessionEvent
+	end.Status = sevent.RemovedStatus
+	runner.consumeSessionEvent(end)
+
+	assert.Equal(t, SessionStart, <-received)
+	assert.Equal(t, SessionEnd, <-received)
+}
+
+func TestRunner_ScriptHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not supported on windows")
+	}
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	script := filepath.Join(dir, "hook.sh")
+	err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$MYST_HOOK_HOOK $MYST_HOOK_SESSION_ID $MYST_HOOK_CONSUMER_ID\" > "+out+"\ncat >> "+out+"\n"), 0700)
+	require.NoError(t, err)
+
+	runner := NewRunner(Config{SessionStart: script, Timeout: 5 * time.Second}, http.DefaultClient)
+	start := sessionEvent
+	start.Status = sevent.CreatedStatus
+	runner.consumeSessionEvent(start)
+
+	var result []byte
+	require.Eventually(t, func() bool {
+		result, err = os.ReadFile(out)
+		lines := strings.SplitN(string(result), "\n", 2)
+		return err == nil && len(lines) == 2 && json.Valid([]byte(lines[1]))
+	}, 5*time.Second, 10*time.Millisecond)
+	lines := strings.SplitN(string(result), "\n", 2)
+	assert.Equal(t, "on-session-start session1 0xconsumer", lines[0])
+
+	var p Payload
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &p))
+	assert.Equal(t, "session1", p.SessionID)
+}
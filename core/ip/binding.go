@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ip
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/utils/netutil"
+)
+
+// Binding pins service traffic to a local network interface and source IP.
+type Binding struct {
+	// Interface is the network interface the source IP belongs to.
+	Interface string
+	// IP is the local source IP service connections are bound to.
+	IP string
+	// Resolver resolves outbound and public IPs as seen through the bound source IP.
+	Resolver Resolver
+}
+
+// Bindings maps service types to their bindings.
+type Bindings map[string]Binding
+
+// Get returns binding of the service type or false if service is not bound.
+func (b Bindings) Get(serviceType string) (Binding, bool) {
+	binding, ok := b[serviceType]
+	return binding, ok
+}
+
+// ParseBindings parses comma separated "service=interface-or-ip" pairs.
+func ParseBindings(value string) (map[string]string, error) {
+	res := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("invalid service binding %q, expected service=interface-or-ip", pair)
+		}
+
+		serviceType := strings.TrimSpace(parts[0])
+		if _, ok := res[serviceType]; ok {
+			return nil, fmt.Errorf("service %s is bound more than once", serviceType)
+		}
+		res[serviceType] = strings.TrimSpace(parts[1])
+	}
+	return res, nil
+}
+
+// NewBinding resolves the network interface name or local IP address into a binding.
+// newResolver creates a resolver which uses the resolved source IP.
+func NewBinding(target string, newResolver func(sourceIP string) Resolver) (Binding, error) {
+	var binding Binding
+	if net.ParseIP(target) != nil {
+		iface, err := netutil.InterfaceByIP(target)
+		if err != nil {
+			return binding, err
+		}
+		binding.Interface, binding.IP = iface, target
+	} else {
+		sourceIP, err := netutil.InterfaceIPv4(target)
+		if err != nil {
+			return binding, err
+		}
+		binding.Interface, binding.IP = target, sourceIP
+	}
+
+	binding.Resolver = newResolver(binding.IP)
+	return binding, nil
+}
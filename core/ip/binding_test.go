@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ip
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBindings(t *testing.T) {
+	bindings, err := ParseBindings(" wireguard=eth1, openvpn=192.168.1.10,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"wireguard": "eth1", "openvpn": "192.168.1.10"}, bindings)
+
+	bindings, err = ParseBindings("")
+	require.NoError(t, err)
+	assert.Empty(t, bindings)
+
+	for _, value := range []string{"wireguard", "wireguard=", "=eth1", "wireguard=eth1,wireguard=eth2"} {
+		_, err = ParseBindings(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestNewBinding(t *testing.T) {
+	var resolverIP string
+	newResolver := func(sourceIP string) Resolver {
+		resolverIP = sourceIP
+		return NewResolverMock(sourceIP)
+	}
+
+	binding, err := NewBinding("127.0.0.1", newResolver)
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", binding.IP)
+	assert.NotEmpty(t, binding.Interface)
+	assert.Equal(t, "127.0.0.1", resolverIP)
+
+	byName, err := NewBinding(binding.Interface, newResolver)
+	require.NoError(t, err)
+	assert.Equal(t, binding.Interface, byName.Interface)
+	assert.Equal(t, "127.0.0.1", byName.IP)
+
+	_, err = NewBinding("203.0.113.77", newResolver)
+	assert.Error(t, err)
+	_, err = NewBinding("no-such-iface0", newResolver)
+	assert.Error(t, err)
+}
@@ -0,0 +1,273 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ip
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
+// AppTopicPublicIPChanged is the topic to which public IP changes are published.
+const AppTopicPublicIPChanged = "public_ip_changed"
+
+// PublicIPChanged is published when the consensus public IP changes.
+type PublicIPChanged struct {
+	Previous   string
+	Current    string
+	Confidence float64
+}
+
+// Source is a single public IP detection source.
+type Source interface {
+	Name() string
+	PublicIP(ctx context.Context) (string, error)
+}
+
+// Consensus is the public IP agreed on by the detection sources.
+type Consensus struct {
+	IP string
+	// Confidence is the share of responding sources that reported the IP.
+	Confidence float64
+	// Results maps source name to the IP it reported.
+	Results    map[string]string
+	ResolvedAt time.Time
+}
+
+// ConsensusConfig configures the consensus resolver.
+type ConsensusConfig struct {
+	// Timeout for a single resolution round.
+	Timeout time.Duration
+	// CacheDuration is how long the resolved IP is reused.
+	CacheDuration time.Duration
+	// CheckInterval between background resolutions detecting IP changes, zero disables them.
+	CheckInterval time.Duration
+	// MinConfidence below which the fallback resolver is used.
+	MinConfidence float64
+}
+
+// ConsensusResolver queries multiple sources concurrently and returns
+// the public IP reported by most of them.
+type ConsensusResolver struct {
+	fallback  Resolver
+	sources   []Source
+	publisher eventbus.Publisher
+	config    ConsensusConfig
+
+	mu   sync.Mutex
+	last Consensus
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewConsensusResolver creates a new consensus resolver. Outbound and proxy IPs
+// and public IP when sources disagree are resolved using the fallback resolver.
+func NewConsensusResolver(fallback Resolver, publisher eventbus.Publisher, config ConsensusConfig, sources ...Source) *ConsensusResolver {
+	return &ConsensusResolver{
+		fallback:  fallback,
+		sources:   sources,
+		publisher: publisher,
+		config:    config,
+		stop:      make(chan struct{}),
+	}
+}
+
+// GetOutboundIP returns current outbound IP as string for current system.
+func (r *ConsensusResolver) GetOutboundIP() (string, error) {
+	return r.fallback.GetOutboundIP()
+}
+
+// GetProxyIP returns proxy public IP.
+func (r *ConsensusResolver) GetProxyIP(proxyPort int) (string, error) {
+	return r.fallback.GetProxyIP(proxyPort)
+}
+
+// GetPublicIP returns the cached consensus public IP or resolves a new one.
+func (r *ConsensusResolver) GetPublicIP() (string, error) {
+	r.mu.Lock()
+	last := r.last
+	r.mu.Unlock()
+
+	if last.IP != "" && time.Since(last.ResolvedAt) < r.config.CacheDuration {
+		return last.IP, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.config.Timeout)
+	defer cancel()
+
+	consensus, err := r.Resolve(ctx)
+	if err != nil {
+		return "", err
+	}
+	return consensus.IP, nil
+}
+
+// Consensus returns the last resolved consensus.
+func (r *ConsensusResolver) Consensus() Consensus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.last
+}
+
+// ClearCache clears resolved IP cache.
+func (r *ConsensusResolver) ClearCache() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.last.ResolvedAt = time.Time{}
+}
+
+// Resolve queries all sources and returns their consensus.
+func (r *ConsensusResolver) Resolve(ctx context.Context) (Consensus, error) {
+	consensus := r.query(ctx)
+	if consensus.IP == "" || consensus.Confidence < r.config.MinConfidence {
+		if len(r.sources) > 0 {
+			log.Warn().Msgf("No public IP consensus %v, using fallback resolver", consensus.Results)
+		}
+		ip, err := r.fallback.GetPublicIP()
+		if err != nil {
+			return Consensus{}, errors.Wrap(err, "failed to resolve public IP")
+		}
+		consensus.IP = ip
+		consensus.Confidence = confidence(consensus.Results, ip)
+	}
+
+	r.mu.Lock()
+	previous := r.last.IP
+	r.last = consensus
+	r.mu.Unlock()
+
+	log.Debug().Msgf("Public IP detected: %s (confidence %.2f)", consensus.IP, consensus.Confidence)
+	if previous != "" && previous != consensus.IP {
+		log.Info().Msgf("Public IP changed from %s to %s", previous, consensus.IP)
+		r.publisher.Publish(AppTopicPublicIPChanged, PublicIPChanged{
+			Previous:   previous,
+			Current:    consensus.IP,
+			Confidence: consensus.Confidence,
+		})
+	}
+	return consensus, nil
+}
+
+// Start periodically resolves public IP to detect its changes.
+func (r *ConsensusResolver) Start() {
+	if r.config.CheckInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(r.config.CheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), r.config.Timeout)
+				if _, err := r.Resolve(ctx); err != nil {
+					log.Warn().Err(err).Msg("Public IP change check failed")
+				}
+				cancel()
+			}
+		}
+	}()
+}
+
+// Stop stops background resolutions.
+func (r *ConsensusResolver) Stop() {
+	r.once.Do(func() {
+		close(r.stop)
+	})
+}
+
+func (r *ConsensusResolver) query(ctx context.Context) Consensus {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]string)
+	)
+	for _, source := range r.sources {
+		wg.Add(1)
+		go func(source Source) {
+			defer wg.Done()
+			ip, err := source.PublicIP(ctx)
+			if err != nil {
+				log.Debug().Err(err).Msgf("Public IP source %s failed", source.Name())
+				return
+			}
+			if parsed := net.ParseIP(ip); parsed == nil || !isPublic(parsed) {
+				log.Debug().Msgf("Public IP source %s returned non public IP %q", source.Name(), ip)
+				return
+			}
+
+			mu.Lock()
+			results[source.Name()] = ip
+			mu.Unlock()
+		}(source)
+	}
+	wg.Wait()
+
+	votes := make(map[string]int)
+	for _, ip := range results {
+		votes[ip]++
+	}
+	candidates := make([]string, 0, len(votes))
+	for ip := range votes {
+		candidates = append(candidates, ip)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if votes[candidates[i]] != votes[candidates[j]] {
+			return votes[candidates[i]] > votes[candidates[j]]
+		}
+		return candidates[i] < candidates[j]
+	})
+
+	consensus := Consensus{Results: results, ResolvedAt: time.Now()}
+	// A tie between the best candidates is not a consensus.
+	if len(candidates) > 0 && (len(candidates) == 1 || votes[candidates[0]] > votes[candidates[1]]) {
+		consensus.IP = candidates[0]
+		consensus.Confidence = confidence(results, consensus.IP)
+	}
+	return consensus
+}
+
+func confidence(results map[string]string, ip string) float64 {
+	if len(results) == 0 {
+		return 0
+	}
+	var votes int
+	for _, result := range results {
+		if result == ip {
+			votes++
+		}
+	}
+	return float64(votes) / float64(len(results))
+}
+
+func isPublic(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !cgnat.Contains(ip)
+}
+
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ip
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticSource struct {
+	name string
+	ip   string
+	err  error
+}
+
+func (s *staticSource) Name() string {
+	return s.name
+}
+
+func (s *staticSource) PublicIP(ctx context.Context) (string, error) {
+	return s.ip, s.err
+}
+
+type mockPublisher struct {
+	events []PublicIPChanged
+}
+
+func (p *mockPublisher) Publish(topic string, data interface{}) {
+	if topic == AppTopicPublicIPChanged {
+		p.events = append(p.events, data.(PublicIPChanged))
+	}
+}
+
+func TestConsensusResolver_Resolve(t *testing.T) {
+	r := NewConsensusResolver(NewResolverMock("9.9.9.9"), &mockPublisher{}, ConsensusConfig{Timeout: time.Second, MinConfidence: 0.5},
+		&staticSource{name: "a", ip: "1.1.1.1"},
+		&staticSource{name: "b", ip: "1.1.1.1"},
+		&staticSource{name: "c", ip: "2.2.2.2"},
+		&staticSource{name: "d", err: errors.New("timeout")},
+		&staticSource{name: "e", ip: "192.168.1.1"},
+	)
+
+	consensus, err := r.Resolve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.1.1", consensus.IP)
+	assert.InDelta(t, 2.0/3, consensus.Confidence, 0.001)
+	assert.Equal(t, map[string]string{"a": "1.1.1.1", "b": "1.1.1.1", "c": "2.2.2.2"}, consensus.Results)
+}
+
+func TestConsensusResolver_FallsBackWithoutConsensus(t *testing.T) {
+	r := NewConsensusResolver(NewResolverMock("2.2.2.2"), &mockPublisher{}, ConsensusConfig{Timeout: time.Second},
+		&staticSource{name: "a", ip: "1.1.1.1"},
+		&staticSource{name: "b", ip: "2.2.2.2"},
+	)
+
+	consensus, err := r.Resolve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "2.2.2.2", consensus.IP)
+	assert.Equal(t, 0.5, consensus.Confidence)
+
+	r = NewConsensusResolver(NewResolverMockFailing(errors.New("offline")), &mockPublisher{}, ConsensusConfig{Timeout: time.Second})
+	_, err = r.GetPublicIP()
+	assert.Error(t, err)
+}
+
+func TestConsensusResolver_CachesAndPublishesChanges(t *testing.T) {
+	source := &staticSource{name: "a", ip: "1.1.1.1"}
+	publisher := &mockPublisher{}
+	r := NewConsensusResolver(NewResolverMock("9.9.9.9"), publisher, ConsensusConfig{Timeout: time.Second, CacheDuration: time.Hour}, source)
+
+	ip, err := r.GetPublicIP()
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.1.1", ip)
+
+	source.ip = "3.3.3.3"
+	ip, err = r.GetPublicIP()
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.1.1", ip)
+	assert.Empty(t, publisher.events)
+
+	r.ClearCache()
+	ip, err = r.GetPublicIP()
+	require.NoError(t, err)
+	assert.Equal(t, "3.3.3.3", ip)
+	assert.Equal(t, []PublicIPChanged{{Previous: "1.1.1.1", Current: "3.3.3.3", Confidence: 1}}, publisher.events)
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ip
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	portmap "github.com/ethereum/go-ethereum/p2p/nat"
+	"github.com/pion/stun"
+
+	"github.com/mysteriumnetwork/node/requests"
+)
+
+type resolverSource struct {
+	name     string
+	resolver Resolver
+}
+
+// NewResolverSource creates a source which resolves public IP using the given resolver.
+func NewResolverSource(name string, resolver Resolver) Source {
+	return &resolverSource{name: name, resolver: resolver}
+}
+
+func (s *resolverSource) Name() string {
+	return s.name
+}
+
+func (s *resolverSource) PublicIP(ctx context.Context) (string, error) {
+	return withContext(ctx, s.resolver.GetPublicIP)
+}
+
+type httpSource struct {
+	url        string
+	httpClient *requests.HTTPClient
+}
+
+// NewHTTPSource creates a source which resolves public IP using an HTTPS echo service with plain text response.
+func NewHTTPSource(httpClient *requests.HTTPClient, url string) Source {
+	return &httpSource{url: url, httpClient: httpClient}
+}
+
+func (s *httpSource) Name() string {
+	return s.url
+}
+
+func (s *httpSource) PublicIP(ctx context.Context) (string, error) {
+	return withContext(ctx, func() (string, error) {
+		return RequestAndParsePlainIPResponse(s.httpClient, s.url)
+	})
+}
+
+type stunSource struct {
+	server      string
+	bindAddress string
+}
+
+// NewSTUNSource creates a source which resolves public IP using the STUN server binding request.
+func NewSTUNSource(server, bindAddress string) Source {
+	return &stunSource{server: server, bindAddress: bindAddress}
+}
+
+func (s *stunSource) Name() string {
+	return "stun://" + s.server
+}
+
+func (s *stunSource) PublicIP(ctx context.Context) (string, error) {
+	dialer := net.Dialer{LocalAddr: &net.UDPAddr{IP: net.ParseIP(s.bindAddress)}}
+	conn, err := dialer.DialContext(ctx, "udp4", s.server)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to STUN server: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return "", err
+		}
+	}
+
+	req := stun.MustBuild(stun.TransactionID, stun.BindingRequest)
+	if _, err := conn.Write(req.Raw); err != nil {
+		return "", fmt.Errorf("failed to send binding request to STUN server: %w", err)
+	}
+
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return "", fmt.Errorf("failed to read message from STUN server: %w", err)
+	}
+
+	res := &stun.Message{Raw: buf[:n]}
+	if err := res.Decode(); err != nil {
+		return "", fmt.Errorf("failed to decode STUN server message: %w", err)
+	}
+
+	var addr stun.XORMappedAddress
+	if err := addr.GetFrom(res); err != nil {
+		return "", fmt.Errorf("failed to decode STUN server message: %w", err)
+	}
+	return addr.IP.String(), nil
+}
+
+type upnpSource struct {
+	router portmap.Interface
+}
+
+// NewUPnPSource creates a source which resolves public IP as the external IP of the router.
+func NewUPnPSource(router portmap.Interface) Source {
+	return &upnpSource{router: router}
+}
+
+func (s *upnpSource) Name() string {
+	return "upnp"
+}
+
+func (s *upnpSource) PublicIP(ctx context.Context) (string, error) {
+	return withContext(ctx, func() (string, error) {
+		ip, err := s.router.ExternalIP()
+		if err != nil {
+			return "", err
+		}
+		return ip.String(), nil
+	})
+}
+
+// withContext runs blocking resolve function and gives up on it when the context is done.
+func withContext(ctx context.Context, resolve func() (string, error)) (string, error) {
+	type result struct {
+		ip  string
+		err error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		ip, err := resolve()
+		done <- result{ip: ip, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case res := <-done:
+		return res.ip, res.err
+	}
+}
+
+// NewFallbackSources creates HTTP sources of n randomly chosen fallback IP echo services.
+func NewFallbackSources(httpClient *requests.HTTPClient, n int) []Source {
+	urls := shuffleStringSlice(IPFallbackAddresses)
+	if n < len(urls) {
+		urls = urls[:n]
+	}
+
+	sources := make([]Source, 0, len(urls))
+	for _, url := range urls {
+		sources = append(sources, NewHTTPSource(httpClient, url))
+	}
+	return sources
+}
@@ -89,6 +89,14 @@ func (c *Cache) DetectLocation() (locationstate.Location, error) {
 	return c.fetchAndSave()
 }
 
+// Refresh fetches the location ignoring the cache.
+func (c *Cache) Refresh() (locationstate.Location, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.fetchAndSave()
+}
+
 // DetectProxyLocation returns the proxy location.
 func (c *Cache) DetectProxyLocation(proxyPort int) (locationstate.Location, error) {
 	return c.locationDetector.DetectProxyLocation(proxyPort)
@@ -19,6 +19,7 @@ package location
 
 import (
 	"net"
+	"sync"
 
 	"github.com/oschwald/geoip2-golang"
 	"github.com/pkg/errors"
@@ -31,7 +32,21 @@ import (
 // DBResolver struct represents ip -> country resolver which uses geoip2 data reader
 type DBResolver struct {
 	dbReader   *geoip2.Reader
+	asnReader  *geoip2.Reader
 	ipResolver ip.Resolver
+	lock       sync.RWMutex
+}
+
+// ASN describes the autonomous system an IP address belongs to.
+type ASN struct {
+	Number       uint
+	Organization string
+}
+
+// GeoIP looks up country and autonomous system of IP addresses in a local database.
+type GeoIP interface {
+	LookupCountry(ipAddress string) (string, error)
+	LookupASN(ipAddress string) (ASN, error)
 }
 
 // NewExternalDBResolver returns Resolver which uses external country database
@@ -67,12 +82,85 @@ func (r *DBResolver) DetectProxyLocation(proxyPort int) (loc locationstate.Locat
 	return r.detectLocation(ipAddress)
 }
 
+// SetCountryDB replaces the country database used for lookups.
+func (r *DBResolver) SetCountryDB(reader *geoip2.Reader) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	old := r.dbReader
+	r.dbReader = reader
+	if old != nil {
+		old.Close()
+	}
+}
+
+// SetASNDB replaces the ASN database used for lookups.
+func (r *DBResolver) SetASNDB(reader *geoip2.Reader) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	old := r.asnReader
+	r.asnReader = reader
+	if old != nil {
+		old.Close()
+	}
+}
+
+// CountryDBBuildEpoch returns the build time of the currently loaded country database.
+func (r *DBResolver) CountryDBBuildEpoch() uint {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.dbReader.Metadata().BuildEpoch
+}
+
+// ASNDBBuildEpoch returns the build time of the currently loaded ASN database, or 0 if none is loaded.
+func (r *DBResolver) ASNDBBuildEpoch() uint {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if r.asnReader == nil {
+		return 0
+	}
+	return r.asnReader.Metadata().BuildEpoch
+}
+
+// LookupCountry resolves the country code of the given IP address from the local database.
+func (r *DBResolver) LookupCountry(ipAddress string) (string, error) {
+	loc, err := r.detectLocation(ipAddress)
+	if err != nil {
+		return "", err
+	}
+	return loc.Country, nil
+}
+
+// LookupASN resolves the autonomous system of the given IP address from the local database.
+func (r *DBResolver) LookupASN(ipAddress string) (ASN, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if r.asnReader == nil {
+		return ASN{}, errors.New("ASN database is not loaded")
+	}
+
+	record, err := r.asnReader.ASN(net.ParseIP(ipAddress))
+	if err != nil {
+		return ASN{}, errors.Wrap(err, "failed to get an ASN")
+	}
+	return ASN{
+		Number:       record.AutonomousSystemNumber,
+		Organization: record.AutonomousSystemOrganization,
+	}, nil
+}
+
 func (r *DBResolver) detectLocation(ipAddress string) (loc locationstate.Location, err error) {
 	log.Debug().Msg("Detecting with DB resolver")
 
 	ip := net.ParseIP(ipAddress)
 
+	r.lock.RLock()
 	countryRecord, err := r.dbReader.Country(ip)
+	r.lock.RUnlock()
 	if err != nil {
 		return loc, errors.Wrap(err, "failed to get a country")
 	}
@@ -0,0 +1,180 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package location
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/oschwald/geoip2-golang"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// maxDBSize limits the size of downloaded database, City databases are well below it.
+const maxDBSize = 256 << 20
+
+// MinDBUpdateInterval prevents downloading the databases over and over again.
+const MinDBUpdateInterval = time.Hour
+
+type httpDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DBUpdater keeps a MaxMind-compatible database up to date by periodically downloading
+// it from the given URL, persisting it locally and handing it over to the consumer.
+type DBUpdater struct {
+	client    httpDoer
+	url       string
+	path      string
+	interval  time.Duration
+	epoch     func() uint
+	setReader func(reader *geoip2.Reader)
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewDBUpdater returns a new database updater.
+// Epoch returns the build epoch of the database currently in use, only newer databases are handed over to setReader.
+func NewDBUpdater(client httpDoer, url, path string, interval time.Duration, epoch func() uint, setReader func(reader *geoip2.Reader)) *DBUpdater {
+	return &DBUpdater{
+		client:    client,
+		url:       url,
+		path:      path,
+		interval:  interval,
+		epoch:     epoch,
+		setReader: setReader,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start loads the previously downloaded database and starts refreshing it in the background.
+func (u *DBUpdater) Start() {
+	if err := u.loadLocal(); err != nil {
+		log.Warn().Err(err).Msgf("Failed to load local GeoIP database %s", u.path)
+	}
+
+	go u.run()
+}
+
+// Stop stops the database refresh.
+func (u *DBUpdater) Stop() {
+	u.once.Do(func() {
+		close(u.stop)
+	})
+}
+
+func (u *DBUpdater) run() {
+	for {
+		if err := u.Update(); err != nil {
+			log.Warn().Err(err).Msgf("Failed to update GeoIP database from %s", u.url)
+		}
+
+		select {
+		case <-u.stop:
+			return
+		case <-time.After(u.interval):
+		}
+	}
+}
+
+// Update downloads the database and swaps it in if it is newer than the one currently in use.
+func (u *DBUpdater) Update() error {
+	req, err := http.NewRequest(http.MethodGet, u.url, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to download database")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxDBSize+1))
+	if err != nil {
+		return errors.Wrap(err, "failed to read database")
+	}
+	if len(data) > maxDBSize {
+		return fmt.Errorf("database exceeds %d bytes", maxDBSize)
+	}
+
+	reader, err := geoip2.FromBytes(data)
+	if err != nil {
+		return errors.Wrap(err, "downloaded database is invalid")
+	}
+
+	if !u.isNewer(reader) {
+		return nil
+	}
+
+	if err := u.save(data); err != nil {
+		log.Warn().Err(err).Msgf("Failed to persist GeoIP database %s", u.path)
+	}
+
+	u.setReader(reader)
+	log.Info().Msgf("GeoIP database updated to build %d", reader.Metadata().BuildEpoch)
+	return nil
+}
+
+func (u *DBUpdater) loadLocal() error {
+	data, err := ioutil.ReadFile(u.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	reader, err := geoip2.FromBytes(data)
+	if err != nil {
+		return err
+	}
+
+	if u.isNewer(reader) {
+		u.setReader(reader)
+	}
+	return nil
+}
+
+func (u *DBUpdater) isNewer(reader *geoip2.Reader) bool {
+	return reader.Metadata().BuildEpoch > u.epoch()
+}
+
+func (u *DBUpdater) save(data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(u.path), 0700); err != nil {
+		return err
+	}
+
+	tmp := u.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, u.path)
+}
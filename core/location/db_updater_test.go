@@ -0,0 +1,97 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package location
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/oschwald/geoip2-golang"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBUpdater_Update(t *testing.T) {
+	data, err := ioutil.ReadFile("db/GeoLite2-Country.mmdb")
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "geoip")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name        string
+		epoch       uint
+		wantUpdated bool
+	}{
+		{"updates older database", 0, true},
+		{"keeps newer database", ^uint(0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".mmdb")
+			var got *geoip2.Reader
+			updater := NewDBUpdater(http.DefaultClient, server.URL, path, time.Hour,
+				func() uint { return tt.epoch },
+				func(reader *geoip2.Reader) { got = reader },
+			)
+
+			assert.NoError(t, updater.Update())
+			assert.Equal(t, tt.wantUpdated, got != nil)
+
+			_, err := os.Stat(path)
+			assert.Equal(t, tt.wantUpdated, err == nil)
+		})
+	}
+}
+
+func TestDBUpdater_UpdateRejectsInvalidDatabase(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("not a database"))
+	}))
+	defer server.Close()
+
+	updater := NewDBUpdater(http.DefaultClient, server.URL, filepath.Join(os.TempDir(), "invalid.mmdb"), time.Hour,
+		func() uint { return 0 },
+		func(reader *geoip2.Reader) { t.Fatal("invalid database should not be loaded") },
+	)
+
+	assert.Error(t, updater.Update())
+}
+
+func TestDBResolver_LookupASNWithoutDatabase(t *testing.T) {
+	resolver, err := NewExternalDBResolver("db/GeoLite2-Country.mmdb", nil)
+	assert.NoError(t, err)
+
+	_, err = resolver.LookupASN("8.8.8.8")
+	assert.EqualError(t, err, "ASN database is not loaded")
+
+	country, err := resolver.LookupCountry("8.8.8.8")
+	assert.NoError(t, err)
+	assert.Equal(t, "US", country)
+}
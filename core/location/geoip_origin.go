@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package location
+
+import (
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
+)
+
+// GeoIPOriginResolver reports the origin country as resolved from the origin IP by the local GeoIP database,
+// the same way providers resolve the country of consumers connecting to them.
+type GeoIPOriginResolver struct {
+	origin OriginResolver
+	geoIP  GeoIP
+}
+
+// NewGeoIPOriginResolver creates origin resolver resolving the country with the given GeoIP database.
+func NewGeoIPOriginResolver(origin OriginResolver, geoIP GeoIP) *GeoIPOriginResolver {
+	return &GeoIPOriginResolver{
+		origin: origin,
+		geoIP:  geoIP,
+	}
+}
+
+// GetOrigin returns the origin location with the country resolved by the GeoIP database.
+func (r *GeoIPOriginResolver) GetOrigin() locationstate.Location {
+	loc := r.origin.GetOrigin()
+	if loc.IP == "" {
+		return loc
+	}
+
+	country, err := r.geoIP.LookupCountry(loc.IP)
+	if err != nil {
+		log.Debug().Err(err).Msg("Could not resolve origin country, using the detected one")
+		return loc
+	}
+	loc.Country = country
+	return loc
+}
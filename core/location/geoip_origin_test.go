@@ -0,0 +1,56 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package location
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
+)
+
+type mockGeoIP struct {
+	countries map[string]string
+}
+
+func (m *mockGeoIP) LookupCountry(ip string) (string, error) {
+	country, ok := m.countries[ip]
+	if !ok {
+		return "", errors.New("country not found")
+	}
+	return country, nil
+}
+
+func (m *mockGeoIP) LookupASN(string) (ASN, error) {
+	return ASN{}, errors.New("not used")
+}
+
+func TestGeoIPOriginResolver_GetOrigin(t *testing.T) {
+	geoIP := &mockGeoIP{countries: map[string]string{"1.2.3.4": "US"}}
+
+	resolver := NewGeoIPOriginResolver(&mockOriginResolver{location: locationstate.Location{IP: "1.2.3.4", Country: "DE", ISP: "Some ISP"}}, geoIP)
+	assert.Equal(t, locationstate.Location{IP: "1.2.3.4", Country: "US", ISP: "Some ISP"}, resolver.GetOrigin())
+
+	resolver = NewGeoIPOriginResolver(&mockOriginResolver{location: locationstate.Location{IP: "5.6.7.8", Country: "DE"}}, geoIP)
+	assert.Equal(t, locationstate.Location{IP: "5.6.7.8", Country: "DE"}, resolver.GetOrigin())
+
+	resolver = NewGeoIPOriginResolver(&mockOriginResolver{location: locationstate.Location{Country: "DE"}}, geoIP)
+	assert.Equal(t, locationstate.Location{Country: "DE"}, resolver.GetOrigin())
+}
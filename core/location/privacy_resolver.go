@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package location
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
+)
+
+// PrivacyMode defines how the consumer location is reported to others.
+type PrivacyMode string
+
+const (
+	// PrivacyOff reports the detected location.
+	PrivacyOff = PrivacyMode("off")
+	// PrivacyOverride reports only the configured country.
+	PrivacyOverride = PrivacyMode("override")
+	// PrivacyOmit reports no location at all.
+	PrivacyOmit = PrivacyMode("omit")
+)
+
+// PrivacyResolver hides the origin location from session metadata and quality reports.
+type PrivacyResolver struct {
+	origin  OriginResolver
+	mode    PrivacyMode
+	country string
+}
+
+// NewPrivacyResolver creates origin resolver applying the given privacy mode.
+func NewPrivacyResolver(origin OriginResolver, mode PrivacyMode, country string) (*PrivacyResolver, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+
+	switch mode {
+	case "", PrivacyOff:
+		mode = PrivacyOff
+	case PrivacyOverride:
+		if len(country) != 2 {
+			return nil, fmt.Errorf("location privacy mode %q requires a two letter country code, got %q", mode, country)
+		}
+	case PrivacyOmit:
+	default:
+		return nil, fmt.Errorf("unknown location privacy mode: %q", mode)
+	}
+
+	return &PrivacyResolver{
+		origin:  origin,
+		mode:    mode,
+		country: country,
+	}, nil
+}
+
+// GetOrigin returns the origin location as allowed by the privacy mode.
+func (r *PrivacyResolver) GetOrigin() locationstate.Location {
+	switch r.mode {
+	case PrivacyOverride:
+		return locationstate.Location{Country: r.country}
+	case PrivacyOmit:
+		return locationstate.Location{}
+	default:
+		return r.origin.GetOrigin()
+	}
+}
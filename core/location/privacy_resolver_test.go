@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package location
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
+)
+
+type mockOriginResolver struct {
+	location locationstate.Location
+}
+
+func (m *mockOriginResolver) GetOrigin() locationstate.Location {
+	return m.location
+}
+
+func TestPrivacyResolver_GetOrigin(t *testing.T) {
+	origin := &mockOriginResolver{location: locationstate.Location{
+		IP:      "1.2.3.4",
+		ASN:     123,
+		ISP:     "Some ISP",
+		Country: "LT",
+		City:    "Vilnius",
+		IPType:  "residential",
+	}}
+
+	tests := []struct {
+		mode     PrivacyMode
+		country  string
+		expected locationstate.Location
+	}{
+		{mode: "", expected: origin.location},
+		{mode: PrivacyOff, country: "DE", expected: origin.location},
+		{mode: PrivacyOverride, country: " de ", expected: locationstate.Location{Country: "DE"}},
+		{mode: PrivacyOmit, expected: locationstate.Location{}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			resolver, err := NewPrivacyResolver(origin, tt.mode, tt.country)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, resolver.GetOrigin())
+		})
+	}
+}
+
+func TestNewPrivacyResolver_Validation(t *testing.T) {
+	_, err := NewPrivacyResolver(&mockOriginResolver{}, PrivacyOverride, "")
+	assert.Error(t, err)
+
+	_, err = NewPrivacyResolver(&mockOriginResolver{}, PrivacyOverride, "Germany")
+	assert.Error(t, err)
+
+	_, err = NewPrivacyResolver(&mockOriginResolver{}, PrivacyMode("hide"), "")
+	assert.Error(t, err)
+}
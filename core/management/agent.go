@@ -0,0 +1,331 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package management
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p"
+)
+
+var (
+	// ErrInvalidCode is returned to peers presenting an unknown or expired pairing code.
+	ErrInvalidCode = errors.New("invalid or expired pairing code")
+	// ErrNotPaired is returned to peers sending requests without pairing first.
+	ErrNotPaired = errors.New("peer is not paired")
+)
+
+const (
+	codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	codeLength   = 10
+	// maxCodeAttempts invalidates a code after this many wrong guesses.
+	maxCodeAttempts = 5
+)
+
+type p2pListener interface {
+	Listen(providerID identity.Identity, serviceType string, channelHandlers func(ch p2p.Channel)) (func(), error)
+}
+
+// Forwarder executes management requests against the local API.
+type Forwarder interface {
+	Forward(req Request) (Response, error)
+}
+
+// PairingCode is a one-time code a remote node presents to pair.
+type PairingCode struct {
+	NodeID    identity.Identity
+	Code      string
+	ExpiresAt time.Time
+}
+
+type pendingCode struct {
+	PairingCode
+	failures int
+}
+
+// Agent lets paired remote nodes manage this node over p2p channels established via the broker.
+type Agent struct {
+	listener  p2pListener
+	storage   persistentStorage
+	forwarder Forwarder
+	codeTTL   time.Duration
+	now       func() time.Time
+
+	lock      sync.Mutex
+	codes     map[string]*pendingCode
+	listening map[identity.Identity]func()
+	channels  map[string]p2p.Channel
+}
+
+// NewAgent creates remote management agent.
+func NewAgent(listener p2pListener, storage persistentStorage, forwarder Forwarder, codeTTL time.Duration) *Agent {
+	return &Agent{
+		listener:  listener,
+		storage:   storage,
+		forwarder: forwarder,
+		codeTTL:   codeTTL,
+		now:       time.Now,
+		codes:     make(map[string]*pendingCode),
+		listening: make(map[identity.Identity]func()),
+		channels:  make(map[string]p2p.Channel),
+	}
+}
+
+// Subscribe resumes listening for paired peers when their node identity is unlocked.
+func (a *Agent) Subscribe(bus eventbus.Subscriber) error {
+	return bus.SubscribeAsync(identity.AppTopicIdentityUnlock, func(e identity.AppEventIdentityUnlock) {
+		peers, err := a.Peers()
+		if err != nil {
+			log.Err(err).Msg("Could not load management peers")
+			return
+		}
+		for _, p := range peers {
+			if p.NodeID == e.ID {
+				if err := a.listen(e.ID); err != nil {
+					log.Err(err).Msgf("Could not listen for management peers of %s", e.ID.Address)
+				}
+				return
+			}
+		}
+	})
+}
+
+// StartPairing generates a one-time pairing code for the node identity and starts accepting pairing requests.
+func (a *Agent) StartPairing(nodeID identity.Identity) (PairingCode, error) {
+	if err := a.listen(nodeID); err != nil {
+		return PairingCode{}, err
+	}
+
+	code, err := generateCode()
+	if err != nil {
+		return PairingCode{}, err
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.expireCodes()
+	pc := PairingCode{NodeID: nodeID, Code: code, ExpiresAt: a.now().Add(a.codeTTL)}
+	a.codes[code] = &pendingCode{PairingCode: pc}
+	return pc, nil
+}
+
+// Peers returns the paired peers.
+func (a *Agent) Peers() ([]Peer, error) {
+	var peers []Peer
+	if err := a.storage.GetAllFrom(peerBucket, &peers); err != nil && !isNotFound(err) {
+		return nil, fmt.Errorf("could not load management peers: %w", err)
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].PairedAt.Before(peers[j].PairedAt)
+	})
+	return peers, nil
+}
+
+// Unpair revokes management access of the peer.
+func (a *Agent) Unpair(nodeID, peerID identity.Identity) error {
+	key := peerKey(nodeID, peerID)
+	if err := a.storage.Delete(peerBucket, &Peer{Key: key}); err != nil {
+		if isNotFound(err) {
+			return ErrNotPaired
+		}
+		return fmt.Errorf("could not delete management peer: %w", err)
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if ch, ok := a.channels[key]; ok {
+		delete(a.channels, key)
+		go ch.Close()
+	}
+	return nil
+}
+
+// Stop stops listening for management channels.
+func (a *Agent) Stop() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	for id, stop := range a.listening {
+		stop()
+		delete(a.listening, id)
+	}
+	for key, ch := range a.channels {
+		ch.Close()
+		delete(a.channels, key)
+	}
+}
+
+func (a *Agent) listen(nodeID identity.Identity) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if _, ok := a.listening[nodeID]; ok {
+		return nil
+	}
+
+	stop, err := a.listener.Listen(nodeID, ServiceType, func(ch p2p.Channel) {
+		a.handleChannel(nodeID, ch)
+	})
+	if err != nil {
+		return fmt.Errorf("could not listen for management channels: %w", err)
+	}
+	a.listening[nodeID] = stop
+	log.Info().Msgf("Accepting remote management channels for %s", nodeID.Address)
+	return nil
+}
+
+func (a *Agent) handleChannel(nodeID identity.Identity, ch p2p.Channel) {
+	ch.Handle(p2p.TopicManagementPair, func(c p2p.Context) error {
+		var req pairRequest
+		if err := unmarshalMessage(c.Request(), &req); err != nil {
+			return c.Error(err)
+		}
+		if err := a.pair(nodeID, c.PeerID(), req.Code); err != nil {
+			log.Warn().Err(err).Msgf("Rejected management pairing from %s", c.PeerID().Address)
+			return c.Error(err)
+		}
+		a.trackChannel(nodeID, c.PeerID(), ch)
+		return c.OK()
+	})
+
+	ch.Handle(p2p.TopicManagementRequest, func(c p2p.Context) error {
+		if !a.isPaired(nodeID, c.PeerID()) {
+			return c.Error(ErrNotPaired)
+		}
+		a.trackChannel(nodeID, c.PeerID(), ch)
+
+		var req Request
+		if err := unmarshalMessage(c.Request(), &req); err != nil {
+			return c.Error(err)
+		}
+		res, err := a.forwarder.Forward(req)
+		if err != nil {
+			return c.Error(err)
+		}
+		msg, err := jsonMessage(res)
+		if err != nil {
+			return c.Error(err)
+		}
+		return c.OkWithReply(msg)
+	})
+}
+
+func (a *Agent) pair(nodeID, peerID identity.Identity, code string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.expireCodes()
+	code = normalizeCode(code)
+	var matched *pendingCode
+	for c, pending := range a.codes {
+		if pending.NodeID != nodeID {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(c), []byte(code)) == 1 {
+			matched = pending
+			continue
+		}
+		pending.failures++
+		if pending.failures >= maxCodeAttempts {
+			delete(a.codes, c)
+		}
+	}
+	if matched == nil {
+		return ErrInvalidCode
+	}
+	delete(a.codes, matched.Code)
+
+	peer := Peer{
+		Key:      peerKey(nodeID, peerID),
+		NodeID:   nodeID,
+		PeerID:   peerID,
+		PairedAt: a.now().UTC(),
+	}
+	if err := a.storage.Store(peerBucket, &peer); err != nil {
+		return fmt.Errorf("could not store management peer: %w", err)
+	}
+	log.Info().Msgf("Paired %s for remote management of %s", peerID.Address, nodeID.Address)
+	return nil
+}
+
+func (a *Agent) isPaired(nodeID, peerID identity.Identity) bool {
+	peers, err := a.Peers()
+	if err != nil {
+		log.Err(err).Msg("Could not load management peers")
+		return false
+	}
+	key := peerKey(nodeID, peerID)
+	for _, p := range peers {
+		if p.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// trackChannel keeps a single channel per peer, closing the previous one.
+func (a *Agent) trackChannel(nodeID, peerID identity.Identity, ch p2p.Channel) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	key := peerKey(nodeID, peerID)
+	if prev, ok := a.channels[key]; ok && prev != ch {
+		go prev.Close()
+	}
+	a.channels[key] = ch
+}
+
+func (a *Agent) expireCodes() {
+	now := a.now()
+	for c, pending := range a.codes {
+		if now.After(pending.ExpiresAt) {
+			delete(a.codes, c)
+		}
+	}
+}
+
+func generateCode() (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(codeAlphabet)))
+	for i := 0; i < codeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("could not generate pairing code: %w", err)
+		}
+		sb.WriteByte(codeAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package management
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPForwarder forwards management requests to the local tequilapi.
+type HTTPForwarder struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPForwarder creates forwarder for tequilapi listening on given address.
+func NewHTTPForwarder(address string, port int, useTLS bool) *HTTPForwarder {
+	if address == "" || address == "0.0.0.0" || address == "::" {
+		address = "127.0.0.1"
+	}
+	scheme := "http"
+	transport := &http.Transport{}
+	if useTLS {
+		scheme = "https"
+		// Requests never leave the host, the certificate may be self-signed.
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &HTTPForwarder{
+		baseURL: fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(address, fmt.Sprint(port))),
+		client:  &http.Client{Transport: transport, Timeout: 30 * time.Second},
+	}
+}
+
+// NewUnixHTTPForwarder creates forwarder for tequilapi listening on given unix domain socket.
+func NewUnixHTTPForwarder(socketPath string) *HTTPForwarder {
+	var dialer net.Dialer
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+	}
+	return &HTTPForwarder{
+		baseURL: "http://localhost",
+		client:  &http.Client{Transport: transport, Timeout: 30 * time.Second},
+	}
+}
+
+// Forward executes the request against tequilapi.
+func (f *HTTPForwarder) Forward(req Request) (Response, error) {
+	if !strings.HasPrefix(req.Path, "/") || strings.HasPrefix(req.Path, "/management") {
+		return Response{Status: http.StatusForbidden}, nil
+	}
+
+	url := f.baseURL + req.Path
+	if req.Query != "" {
+		url += "?" + req.Query
+	}
+	httpReq, err := http.NewRequest(req.Method, url, bytes.NewReader(req.Body))
+	if err != nil {
+		return Response{}, fmt.Errorf("could not create management request: %w", err)
+	}
+	if req.ContentType != "" {
+		httpReq.Header.Set("Content-Type", req.ContentType)
+	}
+
+	resp, err := f.client.Do(httpReq)
+	if err != nil {
+		return Response{}, fmt.Errorf("could not forward management request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxBodySize+1))
+	if err != nil {
+		return Response{}, fmt.Errorf("could not read management response: %w", err)
+	}
+	if len(body) > MaxBodySize {
+		return Response{Status: http.StatusRequestEntityTooLarge}, nil
+	}
+	return Response{
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        body,
+	}, nil
+}
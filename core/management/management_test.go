@@ -0,0 +1,271 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package management
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/trace"
+)
+
+var (
+	nodeID   = identity.FromAddress("0x1")
+	remoteID = identity.FromAddress("0x2")
+)
+
+// pipeChannel delivers sent messages directly to handlers registered on the listening side.
+type pipeChannel struct {
+	peerID   identity.Identity
+	lock     sync.Mutex
+	handlers map[string]p2p.HandlerFunc
+	closed   bool
+}
+
+func (p *pipeChannel) Send(_ context.Context, topic string, msg *p2p.Message) (*p2p.Message, error) {
+	p.lock.Lock()
+	handler, ok := p.handlers[topic]
+	closed := p.closed
+	p.lock.Unlock()
+	if closed {
+		return nil, errors.New("channel closed")
+	}
+	if !ok {
+		return nil, fmt.Errorf("no handler for %s", topic)
+	}
+
+	c := &pipeContext{req: msg, peerID: p.peerID}
+	if err := handler(c); err != nil {
+		return nil, err
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.res, nil
+}
+
+func (p *pipeChannel) Handle(topic string, handler p2p.HandlerFunc) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.handlers[topic] = handler
+}
+
+func (p *pipeChannel) Tracer() *trace.Tracer     { return nil }
+func (p *pipeChannel) ServiceConn() *net.UDPConn { return nil }
+func (p *pipeChannel) Conn() *net.UDPConn        { return nil }
+func (p *pipeChannel) ID() string                { return fmt.Sprintf("%p", p) }
+
+func (p *pipeChannel) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.closed = true
+	return nil
+}
+
+type pipeContext struct {
+	req    *p2p.Message
+	res    *p2p.Message
+	err    error
+	peerID identity.Identity
+}
+
+func (c *pipeContext) Request() *p2p.Message              { return c.req }
+func (c *pipeContext) Error(err error) error              { c.err = err; return nil }
+func (c *pipeContext) OkWithReply(msg *p2p.Message) error { c.res = msg; return nil }
+func (c *pipeContext) OK() error                          { return nil }
+func (c *pipeContext) PeerID() identity.Identity          { return c.peerID }
+
+// pipeNetwork connects the remote dialer to the agent listener.
+type pipeNetwork struct {
+	handler  func(ch p2p.Channel)
+	channels []*pipeChannel
+}
+
+func (n *pipeNetwork) Listen(_ identity.Identity, _ string, handler func(ch p2p.Channel)) (func(), error) {
+	n.handler = handler
+	return func() {}, nil
+}
+
+func (n *pipeNetwork) Dial(_ context.Context, consumerID, _ identity.Identity, _ string, _ p2p.ContactDefinition, _ *trace.Tracer) (p2p.Channel, error) {
+	if n.handler == nil {
+		return nil, errors.New("node is not listening")
+	}
+	ch := &pipeChannel{peerID: consumerID, handlers: make(map[string]p2p.HandlerFunc)}
+	n.handler(ch)
+	n.channels = append(n.channels, ch)
+	return ch, nil
+}
+
+type mockForwarder struct {
+	requests []Request
+}
+
+func (m *mockForwarder) Forward(req Request) (Response, error) {
+	m.requests = append(m.requests, req)
+	return Response{Status: http.StatusOK, ContentType: "application/json", Body: []byte(`{"ok":true}`)}, nil
+}
+
+func newTestStorage(t *testing.T) *boltdb.Bolt {
+	bolt, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { bolt.Close() })
+	return bolt
+}
+
+func newTestPair(t *testing.T) (*Agent, *Remote, *pipeNetwork, *mockForwarder) {
+	network := &pipeNetwork{}
+	forwarder := &mockForwarder{}
+	agent := NewAgent(network, newTestStorage(t), forwarder, time.Minute)
+	remote := NewRemote(network, newTestStorage(t), nil)
+	return agent, remote, network, forwarder
+}
+
+func TestManagement_PairAndForward(t *testing.T) {
+	agent, remote, _, forwarder := newTestPair(t)
+
+	code, err := agent.StartPairing(nodeID)
+	require.NoError(t, err)
+	assert.Len(t, code.Code, codeLength)
+
+	node, err := remote.Pair(context.Background(), remoteID, nodeID, code.Code)
+	require.NoError(t, err)
+	assert.Equal(t, nodeID, node.NodeID)
+
+	peers, err := agent.Peers()
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+	assert.Equal(t, remoteID, peers[0].PeerID)
+
+	res, err := remote.Do(context.Background(), nodeID, Request{Method: http.MethodGet, Path: "/node/status"})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Status)
+	assert.Equal(t, `{"ok":true}`, string(res.Body))
+	require.Len(t, forwarder.requests, 1)
+	assert.Equal(t, "/node/status", forwarder.requests[0].Path)
+}
+
+func TestManagement_CodeIsSingleUse(t *testing.T) {
+	agent, remote, _, _ := newTestPair(t)
+
+	code, err := agent.StartPairing(nodeID)
+	require.NoError(t, err)
+
+	_, err = remote.Pair(context.Background(), remoteID, nodeID, code.Code)
+	require.NoError(t, err)
+
+	_, err = remote.Pair(context.Background(), identity.FromAddress("0x3"), nodeID, code.Code)
+	assert.ErrorIs(t, err, ErrInvalidCode)
+}
+
+func TestManagement_CodeExpires(t *testing.T) {
+	agent, remote, _, _ := newTestPair(t)
+	now := time.Now()
+	agent.now = func() time.Time { return now }
+
+	code, err := agent.StartPairing(nodeID)
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	_, err = remote.Pair(context.Background(), remoteID, nodeID, code.Code)
+	assert.ErrorIs(t, err, ErrInvalidCode)
+}
+
+func TestManagement_CodeInvalidatedAfterWrongAttempts(t *testing.T) {
+	agent, remote, _, _ := newTestPair(t)
+
+	code, err := agent.StartPairing(nodeID)
+	require.NoError(t, err)
+
+	for i := 0; i < maxCodeAttempts; i++ {
+		_, err = remote.Pair(context.Background(), remoteID, nodeID, "WRONGCODE0")
+		assert.ErrorIs(t, err, ErrInvalidCode)
+	}
+
+	_, err = remote.Pair(context.Background(), remoteID, nodeID, code.Code)
+	assert.ErrorIs(t, err, ErrInvalidCode)
+}
+
+func TestManagement_RejectsUnpairedPeer(t *testing.T) {
+	agent, remote, network, forwarder := newTestPair(t)
+
+	code, err := agent.StartPairing(nodeID)
+	require.NoError(t, err)
+	_, err = remote.Pair(context.Background(), remoteID, nodeID, code.Code)
+	require.NoError(t, err)
+
+	ch, err := network.Dial(context.Background(), identity.FromAddress("0x3"), nodeID, ServiceType, p2p.ContactDefinition{}, nil)
+	require.NoError(t, err)
+	msg, err := jsonMessage(Request{Method: http.MethodGet, Path: "/node/status"})
+	require.NoError(t, err)
+
+	_, err = ch.Send(context.Background(), p2p.TopicManagementRequest, msg)
+	assert.ErrorIs(t, err, ErrNotPaired)
+	assert.Empty(t, forwarder.requests)
+}
+
+func TestManagement_UnpairRevokesAccess(t *testing.T) {
+	agent, remote, network, _ := newTestPair(t)
+
+	code, err := agent.StartPairing(nodeID)
+	require.NoError(t, err)
+	_, err = remote.Pair(context.Background(), remoteID, nodeID, code.Code)
+	require.NoError(t, err)
+
+	require.NoError(t, agent.Unpair(nodeID, remoteID))
+	assert.ErrorIs(t, agent.Unpair(nodeID, remoteID), ErrNotPaired)
+
+	_, err = remote.Do(context.Background(), nodeID, Request{Method: http.MethodGet, Path: "/node/status"})
+	assert.ErrorIs(t, err, ErrNotPaired)
+	assert.Len(t, network.channels, 2)
+}
+
+func TestRemote_RedialsStaleChannel(t *testing.T) {
+	agent, remote, network, _ := newTestPair(t)
+
+	code, err := agent.StartPairing(nodeID)
+	require.NoError(t, err)
+	_, err = remote.Pair(context.Background(), remoteID, nodeID, code.Code)
+	require.NoError(t, err)
+
+	network.channels[0].Close()
+
+	res, err := remote.Do(context.Background(), nodeID, Request{Method: http.MethodGet, Path: "/node/status"})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Status)
+	assert.Len(t, network.channels, 2)
+}
+
+func TestRemote_ForgetUnknownNode(t *testing.T) {
+	_, remote, _, _ := newTestPair(t)
+
+	assert.ErrorIs(t, remote.Forget(nodeID), ErrUnknownNode)
+	_, err := remote.Do(context.Background(), nodeID, Request{Method: http.MethodGet, Path: "/"})
+	assert.ErrorIs(t, err, ErrUnknownNode)
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package management
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mysteriumnetwork/node/p2p"
+)
+
+// ServiceType is the p2p service type remote management channels are established for.
+const ServiceType = "management"
+
+// MaxBodySize limits request and response bodies so that they fit into a single p2p message.
+const MaxBodySize = 64 * 1024
+
+// Request is a tequilapi request sent over the management channel.
+type Request struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Query       string `json:"query,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Response is a tequilapi response sent over the management channel.
+type Response struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+type pairRequest struct {
+	Code string `json:"code"`
+}
+
+func jsonMessage(v interface{}) (*p2p.Message, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode management message: %w", err)
+	}
+	return &p2p.Message{Data: data}, nil
+}
+
+func unmarshalMessage(msg *p2p.Message, v interface{}) error {
+	if err := json.Unmarshal(msg.Data, v); err != nil {
+		return fmt.Errorf("could not decode management message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package management
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/trace"
+)
+
+// ErrUnknownNode is returned when a remote node was not paired.
+var ErrUnknownNode = errors.New("remote node is not paired")
+
+type p2pDialer interface {
+	Dial(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, contactDef p2p.ContactDefinition, tracer *trace.Tracer) (p2p.Channel, error)
+}
+
+// Remote manages paired remote nodes over p2p channels established via the broker.
+type Remote struct {
+	dialer          p2pDialer
+	storage         persistentStorage
+	brokerAddresses []string
+	now             func() time.Time
+
+	lock     sync.Mutex
+	channels map[string]p2p.Channel
+}
+
+// NewRemote creates remote node manager.
+func NewRemote(dialer p2pDialer, storage persistentStorage, brokerAddresses []string) *Remote {
+	return &Remote{
+		dialer:          dialer,
+		storage:         storage,
+		brokerAddresses: brokerAddresses,
+		now:             time.Now,
+		channels:        make(map[string]p2p.Channel),
+	}
+}
+
+// Pair pairs the local identity with the remote node using the one-time code shown by the remote node.
+func (r *Remote) Pair(ctx context.Context, localID, nodeID identity.Identity, code string) (Node, error) {
+	ch, err := r.dial(ctx, localID, nodeID)
+	if err != nil {
+		return Node{}, err
+	}
+
+	msg, err := jsonMessage(pairRequest{Code: code})
+	if err != nil {
+		return Node{}, err
+	}
+	if _, err := ch.Send(ctx, p2p.TopicManagementPair, msg); err != nil {
+		ch.Close()
+		return Node{}, fmt.Errorf("could not pair with %s: %w", nodeID.Address, err)
+	}
+
+	node := Node{
+		Key:      nodeID.Address,
+		NodeID:   nodeID,
+		LocalID:  localID,
+		PairedAt: r.now().UTC(),
+	}
+	if err := r.storage.Store(nodeBucket, &node); err != nil {
+		ch.Close()
+		return Node{}, fmt.Errorf("could not store remote node: %w", err)
+	}
+
+	r.replaceChannel(nodeID.Address, ch)
+	return node, nil
+}
+
+// Nodes returns paired remote nodes.
+func (r *Remote) Nodes() ([]Node, error) {
+	var nodes []Node
+	if err := r.storage.GetAllFrom(nodeBucket, &nodes); err != nil && !isNotFound(err) {
+		return nil, fmt.Errorf("could not load remote nodes: %w", err)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].PairedAt.Before(nodes[j].PairedAt)
+	})
+	return nodes, nil
+}
+
+// Forget removes the remote node and closes its channel.
+func (r *Remote) Forget(nodeID identity.Identity) error {
+	if err := r.storage.Delete(nodeBucket, &Node{Key: nodeID.Address}); err != nil {
+		if isNotFound(err) {
+			return ErrUnknownNode
+		}
+		return fmt.Errorf("could not delete remote node: %w", err)
+	}
+	r.replaceChannel(nodeID.Address, nil)
+	return nil
+}
+
+// Do sends the request to the remote node, redialing the channel when needed.
+func (r *Remote) Do(ctx context.Context, nodeID identity.Identity, req Request) (Response, error) {
+	if len(req.Body) > MaxBodySize {
+		return Response{}, fmt.Errorf("request body exceeds %d bytes", MaxBodySize)
+	}
+
+	node, err := r.node(nodeID)
+	if err != nil {
+		return Response{}, err
+	}
+
+	msg, err := jsonMessage(req)
+	if err != nil {
+		return Response{}, err
+	}
+
+	ch, reused, err := r.channel(ctx, node)
+	if err != nil {
+		return Response{}, err
+	}
+	reply, err := ch.Send(ctx, p2p.TopicManagementRequest, msg)
+	if err != nil && reused {
+		// The cached channel may be stale, e.g. after the remote node restarted.
+		r.replaceChannel(node.Key, nil)
+		if ch, _, err = r.channel(ctx, node); err != nil {
+			return Response{}, err
+		}
+		reply, err = ch.Send(ctx, p2p.TopicManagementRequest, msg)
+	}
+	if err != nil {
+		return Response{}, fmt.Errorf("could not send management request: %w", err)
+	}
+
+	var res Response
+	if err := unmarshalMessage(reply, &res); err != nil {
+		return Response{}, err
+	}
+	return res, nil
+}
+
+// Stop closes all management channels.
+func (r *Remote) Stop() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for key, ch := range r.channels {
+		ch.Close()
+		delete(r.channels, key)
+	}
+}
+
+func (r *Remote) node(nodeID identity.Identity) (Node, error) {
+	nodes, err := r.Nodes()
+	if err != nil {
+		return Node{}, err
+	}
+	for _, n := range nodes {
+		if n.Key == nodeID.Address {
+			return n, nil
+		}
+	}
+	return Node{}, ErrUnknownNode
+}
+
+func (r *Remote) channel(ctx context.Context, node Node) (p2p.Channel, bool, error) {
+	r.lock.Lock()
+	ch, ok := r.channels[node.Key]
+	r.lock.Unlock()
+	if ok {
+		return ch, true, nil
+	}
+
+	ch, err := r.dial(ctx, node.LocalID, node.NodeID)
+	if err != nil {
+		return nil, false, err
+	}
+	r.replaceChannel(node.Key, ch)
+	return ch, false, nil
+}
+
+func (r *Remote) dial(ctx context.Context, localID, nodeID identity.Identity) (p2p.Channel, error) {
+	contact := p2p.ContactDefinition{BrokerAddresses: r.brokerAddresses}
+	ch, err := r.dialer.Dial(ctx, localID, nodeID, ServiceType, contact, trace.NewTracer("Remote management dial"))
+	if err != nil {
+		return nil, fmt.Errorf("could not establish management channel with %s: %w", nodeID.Address, err)
+	}
+	return ch, nil
+}
+
+func (r *Remote) replaceChannel(key string, ch p2p.Channel) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if prev, ok := r.channels[key]; ok && prev != ch {
+		prev.Close()
+	}
+	if ch == nil {
+		delete(r.channels, key)
+		return
+	}
+	r.channels[key] = ch
+}
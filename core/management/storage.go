@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package management
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/asdine/storm/v3"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+const (
+	peerBucket = "management_peers"
+	nodeBucket = "management_nodes"
+)
+
+type persistentStorage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+	Delete(bucket string, data interface{}) error
+}
+
+// Peer is a remote identity paired to manage a local node identity.
+type Peer struct {
+	Key      string `storm:"id"`
+	NodeID   identity.Identity
+	PeerID   identity.Identity
+	PairedAt time.Time
+}
+
+// Node is a remote node this node was paired to manage.
+type Node struct {
+	Key    string `storm:"id"`
+	NodeID identity.Identity
+	// LocalID is the local identity the node was paired with.
+	LocalID  identity.Identity
+	PairedAt time.Time
+}
+
+func peerKey(nodeID, peerID identity.Identity) string {
+	return fmt.Sprintf("%s|%s", nodeID.Address, peerID.Address)
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, storm.ErrNotFound)
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const day = 24 * time.Hour
+
+// sessionRanges lists the session history ranges in days, served by the monitoring API.
+var sessionRanges = []int{1, 7, 30}
+
+// maxSessionRange is the longest of sessionRanges.
+const maxSessionRange = 30
+
+// EarningsForecast represents projected provider earnings until the end of the current month.
+type EarningsForecast struct {
+	// Earned is the amount earned since the start of the month.
+	Earned decimal.Decimal
+	// Projected is the amount expected to be earned until the end of the month.
+	Projected decimal.Decimal
+	// Total is the expected end of month earnings.
+	Total decimal.Decimal
+	// DailyTrend is the daily change of earnings over the history window.
+	DailyTrend decimal.Decimal
+	// MonthEnd is the moment the forecast is made for.
+	MonthEnd time.Time
+}
+
+// EarningsForecast projects end of month earnings, the daily trend is fitted over the sessions history of the given range.
+func (m *StatsTracker) EarningsForecast(rangeTime string) (EarningsForecast, error) {
+	window, err := parseRangeDays(rangeTime)
+	if err != nil {
+		return EarningsForecast{}, err
+	}
+
+	now := m.clock.Now().UTC()
+	sessions, err := m.Sessions(sessionsRange(now, window))
+	if err != nil {
+		return EarningsForecast{}, err
+	}
+
+	// The served ranges cover 30 days at most, the beginning of longer months comes from the local session history.
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	servedFrom := now.Add(-time.Duration(maxSessionRange) * day)
+	if monthStart.Before(servedFrom) && m.localSessionsList != nil {
+		id, ok := m.currentIdentity.GetUnlockedIdentity()
+		if !ok {
+			return EarningsForecast{}, errIdentityNotFound
+		}
+		local, err := m.localSessionsList(id, monthStart, servedFrom.Add(-time.Nanosecond))
+		if err != nil {
+			return EarningsForecast{}, err
+		}
+		sessions = append(sessions, local...)
+	}
+
+	return forecastEarnings(sessions, now, window), nil
+}
+
+// sessionsRange returns the shortest served sessions history range covering both the trend window and the current month.
+func sessionsRange(now time.Time, windowDays int) string {
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	days := int(math.Ceil(now.Sub(monthStart).Hours() / 24))
+	if windowDays > days {
+		days = windowDays
+	}
+
+	for _, r := range sessionRanges {
+		if r >= days {
+			return fmt.Sprintf("%dd", r)
+		}
+	}
+	return fmt.Sprintf("%dd", maxSessionRange)
+}
+
+func parseRangeDays(rangeTime string) (int, error) {
+	var days int
+	if _, err := fmt.Sscanf(rangeTime, "%dd", &days); err != nil || days <= 0 {
+		return 0, fmt.Errorf("invalid time range: %q", rangeTime)
+	}
+	return days, nil
+}
+
+// forecastEarnings fits a linear trend through daily earnings of the last windowDays
+// and extrapolates it until the end of the month.
+func forecastEarnings(sessions []SessionItem, now time.Time, windowDays int) EarningsForecast {
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	windowStart := now.Add(-time.Duration(windowDays) * day)
+
+	daily := make([]float64, windowDays)
+	earned := decimal.Zero
+	for _, s := range sessions {
+		amount, err := decimal.NewFromString(s.Earning)
+		if err != nil {
+			continue
+		}
+
+		startedAt := time.Unix(s.StartedAt, 0)
+		if !startedAt.Before(monthStart) {
+			earned = earned.Add(amount)
+		}
+
+		if startedAt.Before(windowStart) || !startedAt.Before(now) {
+			continue
+		}
+		idx := int(startedAt.Sub(windowStart) / day)
+		f, _ := amount.Float64()
+		daily[idx] += f
+	}
+
+	intercept, slope := linearFit(daily)
+
+	remaining := monthEnd.Sub(now).Hours() / 24
+	projected := 0.0
+	for x := float64(windowDays); remaining > 0; x++ {
+		share := math.Min(remaining, 1)
+		projected += share * math.Max(0, intercept+slope*x)
+		remaining -= share
+	}
+
+	projectedDec := decimal.NewFromFloat(projected)
+	return EarningsForecast{
+		Earned:     earned,
+		Projected:  projectedDec,
+		Total:      earned.Add(projectedDec),
+		DailyTrend: decimal.NewFromFloat(slope),
+		MonthEnd:   monthEnd,
+	}
+}
+
+// linearFit returns the least squares line through the points (i, values[i]).
+func linearFit(values []float64) (intercept, slope float64) {
+	n := float64(len(values))
+	if n == 0 {
+		return 0, 0
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range values {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return sumY / n, 0
+	}
+
+	slope = (n*sumXY - sumX*sumY) / denominator
+	intercept = (sumY - slope*sumX) / n
+	return intercept, slope
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/utils/clock"
+)
+
+func dailySessions(now time.Time, earnings ...float64) []SessionItem {
+	sessions := make([]SessionItem, 0, len(earnings))
+	for i, earning := range earnings {
+		startedAt := now.Add(-time.Duration(len(earnings)-i) * day).Add(time.Hour)
+		sessions = append(sessions, SessionItem{
+			ID:        fmt.Sprint(i),
+			StartedAt: startedAt.Unix(),
+			Earning:   fmt.Sprint(earning),
+		})
+	}
+	return sessions
+}
+
+func Test_forecastEarnings(t *testing.T) {
+	now := time.Date(2022, time.June, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		sessions      []SessionItem
+		wantEarned    string
+		wantProjected string
+		wantTrend     string
+	}{
+		{
+			name:          "no history",
+			wantEarned:    "0",
+			wantProjected: "0",
+			wantTrend:     "0",
+		},
+		{
+			name:          "flat earnings",
+			sessions:      dailySessions(now, 1, 1, 1, 1, 1, 1, 1),
+			wantEarned:    "7",
+			wantProjected: "16",
+			wantTrend:     "0",
+		},
+		{
+			name:          "growing earnings",
+			sessions:      dailySessions(now, 1, 2, 3, 4, 5, 6, 7),
+			wantEarned:    "28",
+			wantProjected: "248",
+			wantTrend:     "1",
+		},
+		{
+			name:          "declining earnings never go below zero",
+			sessions:      dailySessions(now, 7, 6, 5, 4, 3, 2, 1),
+			wantEarned:    "28",
+			wantProjected: "0",
+			wantTrend:     "-1",
+		},
+		{
+			name: "earnings from the previous month are not counted as earned",
+			sessions: []SessionItem{
+				{StartedAt: time.Date(2022, time.May, 31, 12, 0, 0, 0, time.UTC).Unix(), Earning: "5"},
+				{StartedAt: time.Date(2022, time.June, 1, 12, 0, 0, 0, time.UTC).Unix(), Earning: "2"},
+			},
+			wantEarned:    "2",
+			wantProjected: "0",
+			wantTrend:     "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := forecastEarnings(tt.sessions, now, 7)
+
+			assert.Equal(t, tt.wantEarned, got.Earned.String())
+			assert.Equal(t, tt.wantProjected, got.Projected.Round(6).String())
+			assert.Equal(t, tt.wantTrend, got.DailyTrend.Round(6).String())
+			assert.Equal(t, got.Earned.Add(got.Projected).String(), got.Total.String())
+			assert.Equal(t, time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC), got.MonthEnd)
+		})
+	}
+}
+
+func Test_forecastEarningsRangeShorterThanMonth(t *testing.T) {
+	now := time.Date(2022, time.June, 25, 0, 0, 0, 0, time.UTC)
+	sessions := append([]SessionItem{
+		{StartedAt: time.Date(2022, time.June, 2, 12, 0, 0, 0, time.UTC).Unix(), Earning: "10"},
+		{StartedAt: time.Date(2022, time.June, 10, 12, 0, 0, 0, time.UTC).Unix(), Earning: "5"},
+	}, dailySessions(now, 1, 1, 1, 1, 1, 1, 1)...)
+
+	got := forecastEarnings(sessions, now, 7)
+
+	assert.Equal(t, "22", got.Earned.String())
+	assert.Equal(t, "6", got.Projected.Round(6).String())
+	assert.Equal(t, "28", got.Total.Round(6).String())
+	assert.Equal(t, "0", got.DailyTrend.Round(6).String())
+}
+
+func Test_sessionsRange(t *testing.T) {
+	assert.Equal(t, "7d", sessionsRange(time.Date(2022, time.June, 3, 12, 0, 0, 0, time.UTC), 7))
+	assert.Equal(t, "30d", sessionsRange(time.Date(2022, time.June, 25, 12, 0, 0, 0, time.UTC), 7))
+	assert.Equal(t, "30d", sessionsRange(time.Date(2022, time.June, 3, 12, 0, 0, 0, time.UTC), 30))
+	assert.Equal(t, "1d", sessionsRange(time.Date(2022, time.June, 1, 12, 0, 0, 0, time.UTC), 1))
+	assert.Equal(t, "30d", sessionsRange(time.Date(2022, time.July, 31, 0, 0, 0, 0, time.UTC), 7))
+	assert.Equal(t, "30d", sessionsRange(time.Date(2022, time.July, 31, 12, 0, 0, 0, time.UTC), 7))
+}
+
+func Test_forecastEarningsPartialDay(t *testing.T) {
+	now := time.Date(2022, time.June, 30, 12, 0, 0, 0, time.UTC)
+
+	got := forecastEarnings(dailySessions(now, 2, 2, 2), now, 3)
+
+	assert.Equal(t, "1", got.Projected.Round(6).String())
+}
+
+func Test_linearFit(t *testing.T) {
+	intercept, slope := linearFit([]float64{3, 5, 7, 9})
+	assert.InDelta(t, 3, intercept, 1e-9)
+	assert.InDelta(t, 2, slope, 1e-9)
+
+	intercept, slope = linearFit([]float64{4})
+	assert.InDelta(t, 4, intercept, 1e-9)
+	assert.InDelta(t, 0, slope, 1e-9)
+}
+
+func TestStatsTracker_EarningsForecast(t *testing.T) {
+	now := time.Date(2022, time.June, 3, 12, 0, 0, 0, time.UTC)
+	var requestedRange string
+	sessionsList := func(id identity.Identity, rangeTime string) ([]SessionItem, error) {
+		requestedRange = rangeTime
+		return dailySessions(now, 1, 1), nil
+	}
+	tracker := NewNodeStatsTracker(nil, sessionsList, nil, nil, nil, nil, nil, nil, nil, newMockCurrentIdentity("0x1", false), nil, nil)
+	tracker.clock = clock.NewMock(now)
+
+	forecast, err := tracker.EarningsForecast("7d")
+	assert.NoError(t, err)
+	assert.Equal(t, "7d", requestedRange)
+	assert.Equal(t, time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC), forecast.MonthEnd)
+
+	_, err = tracker.EarningsForecast("week")
+	assert.Error(t, err)
+
+	locked := NewNodeStatsTracker(nil, sessionsList, nil, nil, nil, nil, nil, nil, nil, newMockCurrentIdentity("0x1", true), nil, nil)
+	_, err = locked.EarningsForecast("7d")
+	assert.Equal(t, errIdentityNotFound, err)
+}
+
+func TestStatsTracker_EarningsForecastAddsMonthStartFromLocalSessions(t *testing.T) {
+	now := time.Date(2022, time.July, 31, 12, 0, 0, 0, time.UTC)
+	var requestedRange string
+	sessionsList := func(id identity.Identity, rangeTime string) ([]SessionItem, error) {
+		requestedRange = rangeTime
+		return []SessionItem{{StartedAt: time.Date(2022, time.July, 20, 12, 0, 0, 0, time.UTC).Unix(), Earning: "2"}}, nil
+	}
+	var localFrom, localTo time.Time
+	localSessionsList := func(id identity.Identity, from, to time.Time) ([]SessionItem, error) {
+		localFrom, localTo = from, to
+		return []SessionItem{{StartedAt: time.Date(2022, time.July, 1, 6, 0, 0, 0, time.UTC).Unix(), Earning: "3"}}, nil
+	}
+	tracker := NewNodeStatsTracker(nil, sessionsList, nil, nil, nil, nil, nil, nil, localSessionsList, newMockCurrentIdentity("0x1", false), nil, nil)
+	tracker.clock = clock.NewMock(now)
+
+	forecast, err := tracker.EarningsForecast("7d")
+	assert.NoError(t, err)
+	assert.Equal(t, "30d", requestedRange)
+	assert.Equal(t, time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC), localFrom)
+	assert.True(t, localTo.Before(time.Date(2022, time.July, 1, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "5", forecast.Earned.String())
+}
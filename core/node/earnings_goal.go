@@ -0,0 +1,221 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/shopspring/decimal"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/node/utils/clock"
+)
+
+// AppTopicEarningsGoalReached is published once per period when the earnings goal is reached.
+const AppTopicEarningsGoalReached = "earnings_goal_reached"
+
+// EarningsGoalPeriod is the period an earnings goal applies to.
+type EarningsGoalPeriod string
+
+const (
+	// EarningsGoalPeriodWeek goal applies to a calendar week starting on Monday.
+	EarningsGoalPeriodWeek EarningsGoalPeriod = "week"
+	// EarningsGoalPeriodMonth goal applies to a calendar month.
+	EarningsGoalPeriodMonth EarningsGoalPeriod = "month"
+)
+
+// EarningsGoalConfig describes the earnings goal of the provider.
+type EarningsGoalConfig struct {
+	// Amount of MYST to earn during the period, zero disables the goal.
+	Amount decimal.Decimal
+	Period EarningsGoalPeriod
+}
+
+// Enabled tells if the earnings goal is configured.
+func (c EarningsGoalConfig) Enabled() bool {
+	return c.Amount.IsPositive()
+}
+
+// EarningsGoalSource returns the current earnings goal, it's called on every
+// progress computation so goal changes apply immediately.
+type EarningsGoalSource func() EarningsGoalConfig
+
+// EarningsGoal represents provider progress towards the earnings goal.
+type EarningsGoal struct {
+	Goal      decimal.Decimal
+	Earned    decimal.Decimal
+	Remaining decimal.Decimal
+	// PercentComplete is the share of the goal earned, capped at 100.
+	PercentComplete float64
+	// RequiredDailyRate is the amount to earn per day for the rest of the period to reach the goal.
+	RequiredDailyRate decimal.Decimal
+	Reached           bool
+
+	Period      EarningsGoalPeriod
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+}
+
+// AppEventEarningsGoalReached is the event payload for AppTopicEarningsGoalReached topic.
+type AppEventEarningsGoalReached struct {
+	Identity string
+	Goal     EarningsGoal
+}
+
+// ErrEarningsGoalNotSet is returned when earnings goal is not configured.
+var ErrEarningsGoalNotSet = errors.New("earnings goal is not set")
+
+// EarningsGoal computes progress towards the configured earnings goal.
+func (m *StatsTracker) EarningsGoal() (EarningsGoal, error) {
+	if m.earningsGoal == nil {
+		return EarningsGoal{}, ErrEarningsGoalNotSet
+	}
+	goal := m.earningsGoal()
+	if !goal.Enabled() {
+		return EarningsGoal{}, ErrEarningsGoalNotSet
+	}
+
+	rangeTime := "30d"
+	if goal.Period == EarningsGoalPeriodWeek {
+		rangeTime = "7d"
+	}
+	sessions, err := m.Sessions(rangeTime)
+	if err != nil {
+		return EarningsGoal{}, err
+	}
+
+	return earningsGoalProgress(goal, sessions, m.clock.Now().UTC()), nil
+}
+
+func earningsGoalProgress(goal EarningsGoalConfig, sessions []SessionItem, now time.Time) EarningsGoal {
+	start, end := goalPeriod(goal.Period, now)
+
+	earned := decimal.Zero
+	for _, s := range sessions {
+		amount, err := decimal.NewFromString(s.Earning)
+		if err != nil {
+			continue
+		}
+		if startedAt := time.Unix(s.StartedAt, 0); !startedAt.Before(start) && startedAt.Before(end) {
+			earned = earned.Add(amount)
+		}
+	}
+
+	remaining := goal.Amount.Sub(earned)
+	if remaining.IsNegative() {
+		remaining = decimal.Zero
+	}
+	percent, _ := earned.Div(goal.Amount).Mul(decimal.NewFromInt(100)).Float64()
+	if percent > 100 {
+		percent = 100
+	}
+
+	// Never divide by less than an hour, so the rate stays meaningful at the very end of the period.
+	daysLeft := decimal.NewFromFloat(end.Sub(now).Hours() / 24)
+	if minDays := decimal.NewFromFloat(1.0 / 24); daysLeft.LessThan(minDays) {
+		daysLeft = minDays
+	}
+
+	return EarningsGoal{
+		Goal:              goal.Amount,
+		Earned:            earned,
+		Remaining:         remaining,
+		PercentComplete:   percent,
+		RequiredDailyRate: remaining.Div(daysLeft),
+		Reached:           remaining.IsZero(),
+		Period:            goal.Period,
+		PeriodStart:       start,
+		PeriodEnd:         end,
+	}
+}
+
+func goalPeriod(period EarningsGoalPeriod, now time.Time) (start, end time.Time) {
+	if period == EarningsGoalPeriodWeek {
+		daysSinceMonday := (int(now.Weekday()) + 6) % 7
+		start = time.Date(now.Year(), now.Month(), now.Day()-daysSinceMonday, 0, 0, 0, 0, now.Location())
+		return start, start.AddDate(0, 0, 7)
+	}
+
+	start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return start, start.AddDate(0, 1, 0)
+}
+
+type earningsGoalProvider interface {
+	EarningsGoal() (EarningsGoal, error)
+}
+
+// EarningsGoalWatcher publishes an event when the earnings goal is reached. Progress is
+// rechecked on earnings changes, but not more often than the configured interval,
+// as it requires fetching sessions history.
+type EarningsGoalWatcher struct {
+	provider  earningsGoalProvider
+	publisher eventbus.Publisher
+	interval  time.Duration
+	clock     clock.Clock
+
+	mu          sync.Mutex
+	lastCheck   time.Time
+	reachedFrom time.Time
+}
+
+// NewEarningsGoalWatcher returns a new earnings goal watcher.
+func NewEarningsGoalWatcher(provider earningsGoalProvider, publisher eventbus.Publisher, interval time.Duration) *EarningsGoalWatcher {
+	return &EarningsGoalWatcher{
+		provider:  provider,
+		publisher: publisher,
+		interval:  interval,
+		clock:     clock.System,
+	}
+}
+
+// Subscribe subscribes to earnings events.
+func (w *EarningsGoalWatcher) Subscribe(bus eventbus.Subscriber) error {
+	return bus.SubscribeAsync(pingpongEvent.AppTopicEarningsChanged, w.handleEarningsChanged)
+}
+
+func (w *EarningsGoalWatcher) handleEarningsChanged(e pingpongEvent.AppEventEarningsChanged) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.clock.Since(w.lastCheck) < w.interval {
+		return
+	}
+	w.lastCheck = w.clock.Now()
+
+	goal, err := w.provider.EarningsGoal()
+	if err != nil {
+		if !errors.Is(err, ErrEarningsGoalNotSet) {
+			log.Warn().Err(err).Msg("Failed to check earnings goal progress")
+		}
+		return
+	}
+	if !goal.Reached || w.reachedFrom.Equal(goal.PeriodStart) {
+		return
+	}
+	w.reachedFrom = goal.PeriodStart
+
+	log.Info().Msgf("Earnings goal of %s MYST per %s reached", goal.Goal, goal.Period)
+	w.publisher.Publish(AppTopicEarningsGoalReached, AppEventEarningsGoalReached{
+		Identity: e.Identity.Address,
+		Goal:     goal,
+	})
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/mocks"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/node/utils/clock"
+)
+
+func TestGoalPeriod(t *testing.T) {
+	// Wednesday
+	now := time.Date(2022, time.June, 15, 13, 0, 0, 0, time.UTC)
+
+	start, end := goalPeriod(EarningsGoalPeriodWeek, now)
+	assert.Equal(t, time.Date(2022, time.June, 13, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2022, time.June, 20, 0, 0, 0, 0, time.UTC), end)
+
+	// Sunday belongs to the week started on Monday before it
+	start, _ = goalPeriod(EarningsGoalPeriodWeek, time.Date(2022, time.June, 19, 23, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2022, time.June, 13, 0, 0, 0, 0, time.UTC), start)
+
+	start, end = goalPeriod(EarningsGoalPeriodMonth, now)
+	assert.Equal(t, time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC), end)
+}
+
+func TestEarningsGoalProgress(t *testing.T) {
+	now := time.Date(2022, time.June, 21, 0, 0, 0, 0, time.UTC)
+	goal := EarningsGoalConfig{Amount: decimal.NewFromInt(50), Period: EarningsGoalPeriodMonth}
+
+	t.Run("counts only current period earnings", func(t *testing.T) {
+		sessions := []SessionItem{
+			{StartedAt: time.Date(2022, time.May, 31, 12, 0, 0, 0, time.UTC).Unix(), Earning: "100"},
+			{StartedAt: time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC).Unix(), Earning: "10"},
+			{StartedAt: time.Date(2022, time.June, 20, 0, 0, 0, 0, time.UTC).Unix(), Earning: "10"},
+		}
+
+		progress := earningsGoalProgress(goal, sessions, now)
+		assert.True(t, progress.Earned.Equal(decimal.NewFromInt(20)), progress.Earned.String())
+		assert.True(t, progress.Remaining.Equal(decimal.NewFromInt(30)), progress.Remaining.String())
+		assert.Equal(t, 40.0, progress.PercentComplete)
+		// 30 MYST left for 10 days
+		assert.True(t, progress.RequiredDailyRate.Equal(decimal.NewFromInt(3)), progress.RequiredDailyRate.String())
+		assert.False(t, progress.Reached)
+	})
+
+	t.Run("caps progress when goal is exceeded", func(t *testing.T) {
+		sessions := []SessionItem{
+			{StartedAt: time.Date(2022, time.June, 2, 0, 0, 0, 0, time.UTC).Unix(), Earning: "60"},
+		}
+
+		progress := earningsGoalProgress(goal, sessions, now)
+		assert.True(t, progress.Remaining.IsZero())
+		assert.Equal(t, 100.0, progress.PercentComplete)
+		assert.True(t, progress.RequiredDailyRate.IsZero())
+		assert.True(t, progress.Reached)
+	})
+}
+
+type mockEarningsGoalProvider struct {
+	goal EarningsGoal
+	err  error
+}
+
+func (m *mockEarningsGoalProvider) EarningsGoal() (EarningsGoal, error) {
+	return m.goal, m.err
+}
+
+func TestEarningsGoalWatcher_PublishesOncePerPeriod(t *testing.T) {
+	clk := clock.NewMock(time.Date(2022, time.June, 21, 0, 0, 0, 0, time.UTC))
+	provider := &mockEarningsGoalProvider{goal: EarningsGoal{
+		Reached:     true,
+		PeriodStart: time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC),
+	}}
+	bus := mocks.NewEventBus()
+	watcher := NewEarningsGoalWatcher(provider, bus, time.Minute)
+	watcher.clock = clk
+
+	event := pingpongEvent.AppEventEarningsChanged{Identity: identity.FromAddress("0x1")}
+
+	watcher.handleEarningsChanged(event)
+	assert.Equal(t, AppEventEarningsGoalReached{Identity: "0x1", Goal: provider.goal}, bus.Pop())
+
+	clk.Add(time.Hour)
+	watcher.handleEarningsChanged(event)
+	assert.Nil(t, bus.Pop())
+
+	provider.goal.PeriodStart = time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC)
+	clk.Add(time.Hour)
+	watcher.handleEarningsChanged(event)
+	assert.NotNil(t, bus.Pop())
+}
+
+func TestEarningsGoalWatcher_ThrottlesChecks(t *testing.T) {
+	clk := clock.NewMock(time.Date(2022, time.June, 21, 0, 0, 0, 0, time.UTC))
+	provider := &mockEarningsGoalProvider{err: ErrEarningsGoalNotSet}
+	bus := mocks.NewEventBus()
+	watcher := NewEarningsGoalWatcher(provider, bus, time.Minute)
+	watcher.clock = clk
+
+	event := pingpongEvent.AppEventEarningsChanged{Identity: identity.FromAddress("0x1")}
+	watcher.handleEarningsChanged(event)
+
+	provider.err = nil
+	provider.goal = EarningsGoal{Reached: true, PeriodStart: clk.Now()}
+	watcher.handleEarningsChanged(event)
+	assert.Nil(t, bus.Pop())
+
+	clk.Add(time.Minute)
+	watcher.handleEarningsChanged(event)
+	assert.NotNil(t, bus.Pop())
+}
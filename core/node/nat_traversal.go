@@ -0,0 +1,152 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/p2p/nat"
+	"github.com/mysteriumnetwork/node/utils/clock"
+)
+
+// natAttemptsLimit caps the number of remembered NAT traversal attempts.
+const natAttemptsLimit = 10000
+
+// NATTraversalAttempt is the outcome of a single attempt to reach consumer.
+type NATTraversalAttempt struct {
+	At          time.Time
+	ServiceType string
+	Method      string
+	Duration    time.Duration
+	Success     bool
+}
+
+// NATTraversalMethodStats aggregates NAT traversal attempts of a single method.
+type NATTraversalMethodStats struct {
+	Method    string
+	Attempts  int
+	Succeeded int
+	// SuccessRate is the share of successful attempts, 0..1.
+	SuccessRate float64
+	AvgDuration time.Duration
+}
+
+// NATTraversalStats aggregates provider NAT traversal attempts during a period of time.
+type NATTraversalStats struct {
+	NATTraversalMethodStats
+	Methods []NATTraversalMethodStats
+}
+
+// NATTraversalTracker records provider NAT traversal attempts since the node start.
+type NATTraversalTracker struct {
+	clock clock.Clock
+
+	mu       sync.Mutex
+	attempts []NATTraversalAttempt
+}
+
+// NewNATTraversalTracker creates NAT traversal attempts tracker.
+func NewNATTraversalTracker() *NATTraversalTracker {
+	return &NATTraversalTracker{clock: clock.System}
+}
+
+// Subscribe subscribes to NAT traversal attempt events.
+func (t *NATTraversalTracker) Subscribe(bus eventbus.Subscriber) error {
+	return bus.SubscribeAsync(nat.AppTopicNATTraversalAttempt, t.handleAttempt)
+}
+
+func (t *NATTraversalTracker) handleAttempt(e nat.NATTraversalAttempt) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.attempts = append(t.attempts, NATTraversalAttempt{
+		At:          t.clock.Now(),
+		ServiceType: e.ServiceType,
+		Method:      e.Method,
+		Duration:    e.Duration,
+		Success:     e.Success,
+	})
+	if len(t.attempts) > natAttemptsLimit {
+		t.attempts = t.attempts[len(t.attempts)-natAttemptsLimit:]
+	}
+}
+
+// Stats returns aggregated NAT traversal attempts for the given range ("1d", "7d", "30d").
+func (t *NATTraversalTracker) Stats(rangeTime string) (NATTraversalStats, error) {
+	days, err := parseRangeDays(rangeTime)
+	if err != nil {
+		return NATTraversalStats{}, err
+	}
+	since := t.clock.Now().Add(-time.Duration(days) * day)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var total natMethodAggregate
+	byMethod := make(map[string]*natMethodAggregate)
+	for _, a := range t.attempts {
+		if a.At.Before(since) {
+			continue
+		}
+		total.add(a)
+		m, ok := byMethod[a.Method]
+		if !ok {
+			m = &natMethodAggregate{}
+			byMethod[a.Method] = m
+		}
+		m.add(a)
+	}
+
+	stats := NATTraversalStats{
+		NATTraversalMethodStats: total.stats(""),
+		Methods:                 make([]NATTraversalMethodStats, 0, len(byMethod)),
+	}
+	for method, m := range byMethod {
+		stats.Methods = append(stats.Methods, m.stats(method))
+	}
+	sort.Slice(stats.Methods, func(i, j int) bool {
+		return stats.Methods[i].Method < stats.Methods[j].Method
+	})
+	return stats, nil
+}
+
+type natMethodAggregate struct {
+	attempts  int
+	succeeded int
+	duration  time.Duration
+}
+
+func (a *natMethodAggregate) add(attempt NATTraversalAttempt) {
+	a.attempts++
+	a.duration += attempt.Duration
+	if attempt.Success {
+		a.succeeded++
+	}
+}
+
+func (a *natMethodAggregate) stats(method string) NATTraversalMethodStats {
+	s := NATTraversalMethodStats{Method: method, Attempts: a.attempts, Succeeded: a.succeeded}
+	if a.attempts > 0 {
+		s.SuccessRate = float64(a.succeeded) / float64(a.attempts)
+		s.AvgDuration = a.duration / time.Duration(a.attempts)
+	}
+	return s
+}
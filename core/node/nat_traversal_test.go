@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/p2p/nat"
+	"github.com/mysteriumnetwork/node/utils/clock"
+)
+
+func TestNATTraversalTracker_Stats(t *testing.T) {
+	// given
+	clk := clock.NewMock(time.Date(2022, time.July, 7, 12, 0, 0, 0, time.UTC))
+	tracker := NewNATTraversalTracker()
+	tracker.clock = clk
+	tracker.handleAttempt(nat.NATTraversalAttempt{Method: "port_mapping", Duration: 4 * time.Second, Success: false})
+
+	clk.Add(3*day - time.Hour)
+	tracker.handleAttempt(nat.NATTraversalAttempt{Method: "port_mapping", Duration: time.Second, Success: true})
+	tracker.handleAttempt(nat.NATTraversalAttempt{Method: "hole_punching", Duration: 2 * time.Second, Success: true})
+	tracker.handleAttempt(nat.NATTraversalAttempt{Method: "hole_punching", Duration: 4 * time.Second, Success: false})
+	clk.Add(time.Hour)
+
+	// when
+	stats, err := tracker.Stats("1d")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, NATTraversalStats{
+		NATTraversalMethodStats: NATTraversalMethodStats{Attempts: 3, Succeeded: 2, SuccessRate: 2.0 / 3, AvgDuration: 7 * time.Second / 3},
+		Methods: []NATTraversalMethodStats{
+			{Method: "hole_punching", Attempts: 2, Succeeded: 1, SuccessRate: 0.5, AvgDuration: 3 * time.Second},
+			{Method: "port_mapping", Attempts: 1, Succeeded: 1, SuccessRate: 1, AvgDuration: time.Second},
+		},
+	}, stats)
+
+	// when
+	stats, err = tracker.Stats("7d")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 4, stats.Attempts)
+	assert.Equal(t, 0.5, stats.SuccessRate)
+	assert.Equal(t, NATTraversalMethodStats{Method: "port_mapping", Attempts: 2, Succeeded: 1, SuccessRate: 0.5, AvgDuration: 2500 * time.Millisecond}, stats.Methods[1])
+}
+
+func TestNATTraversalTracker_StatsEmpty(t *testing.T) {
+	tracker := NewNATTraversalTracker()
+
+	stats, err := tracker.Stats("30d")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stats.Attempts)
+	assert.Zero(t, stats.SuccessRate)
+	assert.Empty(t, stats.Methods)
+
+	_, err = tracker.Stats("week")
+	assert.Error(t, err)
+}
+
+func TestNATTraversalTracker_LimitsAttempts(t *testing.T) {
+	tracker := NewNATTraversalTracker()
+	for i := 0; i < natAttemptsLimit+10; i++ {
+		tracker.handleAttempt(nat.NATTraversalAttempt{Method: "port_mapping", Success: i >= 10})
+	}
+
+	stats, err := tracker.Stats("1d")
+	assert.NoError(t, err)
+	assert.Equal(t, natAttemptsLimit, stats.Attempts)
+	assert.Equal(t, 1.0, stats.SuccessRate)
+}
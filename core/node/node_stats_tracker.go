@@ -18,9 +18,12 @@
 package node
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
 
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/utils/clock"
 )
 
 var errIdentityNotFound = errors.New("identity not found")
@@ -46,12 +49,19 @@ type ProviderConsumersCount func(id identity.Identity, rangeTime string) (Consum
 // ProviderEarningsSeries should return earnings data series metrics
 type ProviderEarningsSeries func(id identity.Identity, rangeTime string) (EarningsSeries, error)
 
+// LocalSessionsList should return provided sessions recorded by the node which started within the given period
+type LocalSessionsList func(id identity.Identity, from, to time.Time) ([]SessionItem, error)
+
 // ProviderSessionsSeries should return sessions data series metrics
 type ProviderSessionsSeries func(id identity.Identity, rangeTime string) (SessionsSeries, error)
 
 // ProviderTransferredDataSeries should return transferred bytes data series metrics
 type ProviderTransferredDataSeries func(id identity.Identity, rangeTime string) (TransferredDataSeries, error)
 
+type natTraversalStats interface {
+	Stats(rangeTime string) (NATTraversalStats, error)
+}
+
 // StatsTracker tracks metrics for service
 type StatsTracker struct {
 	providerStatuses              ProviderStatuses
@@ -62,7 +72,11 @@ type StatsTracker struct {
 	providerEarningsSeries        ProviderEarningsSeries
 	providerSessionsSeries        ProviderSessionsSeries
 	providerTransferredDataSeries ProviderTransferredDataSeries
+	localSessionsList             LocalSessionsList
 	currentIdentity               currentIdentity
+	earningsGoal                  EarningsGoalSource
+	natTraversal                  natTraversalStats
+	clock                         clock.Clock
 }
 
 // NewNodeStatsTracker constructor
@@ -75,7 +89,10 @@ func NewNodeStatsTracker(
 	providerEarningsSeries ProviderEarningsSeries,
 	providerSessionsSeries ProviderSessionsSeries,
 	providerTransferredDataSeries ProviderTransferredDataSeries,
+	localSessionsList LocalSessionsList,
 	currentIdentity currentIdentity,
+	earningsGoal EarningsGoalSource,
+	natTraversal natTraversalStats,
 ) *StatsTracker {
 	mat := &StatsTracker{
 		providerStatuses:              providerStatuses,
@@ -86,7 +103,11 @@ func NewNodeStatsTracker(
 		providerEarningsSeries:        providerEarningsSeries,
 		providerSessionsSeries:        providerSessionsSeries,
 		providerTransferredDataSeries: providerTransferredDataSeries,
+		localSessionsList:             localSessionsList,
 		currentIdentity:               currentIdentity,
+		earningsGoal:                  earningsGoal,
+		natTraversal:                  natTraversal,
+		clock:                         clock.System,
 	}
 
 	return mat
@@ -218,3 +239,11 @@ func (m *StatsTracker) TransferredDataSeries(rangeTime string) (TransferredDataS
 
 	return TransferredDataSeries{}, errIdentityNotFound
 }
+
+// NATTraversal retrieves success rate of provider attempts to reach consumers through NAT during a time range
+func (m *StatsTracker) NATTraversal(rangeTime string) (NATTraversalStats, error) {
+	if m.natTraversal == nil {
+		return NATTraversalStats{}, nil
+	}
+	return m.natTraversal.Stats(rangeTime)
+}
@@ -60,7 +60,10 @@ type Options struct {
 	TequilapiPort          int
 	FlagTequilapiDebugMode bool
 	TequilapiEnabled       bool
+	TequilapiTLS           OptionsTLS
+	TequilapiSocket        OptionsSocket
 	BindAddress            string
+	ServiceBindings        string
 	UI                     OptionsUI
 	FeedbackURL            string
 
@@ -85,6 +88,9 @@ type Options struct {
 	SwarmDialerDNSHeadstart time.Duration
 	PilvytisAddress         string
 	ObserverAddress         string
+
+	// ConsumerIPPrivacy defines how consumer IP addresses are recorded, see privacy.IPMode.
+	ConsumerIPPrivacy string
 }
 
 // GetOptions retrieves node options from the app configuration.
@@ -96,6 +102,8 @@ func GetOptions() *Options {
 		EtherClientRPCL1: config.GetStringSlice(config.FlagEtherRPCL1),
 		EtherClientRPCL2: config.GetStringSlice(config.FlagEtherRPCL2),
 		ChainID:          config.GetInt64(config.FlagChainID),
+
+		PrivateNetworkDefinition: config.GetString(config.FlagNetworkDefinition),
 		DNSMap: map[string][]string{
 			"location.mysterium.network": {"51.158.129.204"},
 			"quality.mysterium.network":  {"51.158.129.204"},
@@ -116,13 +124,26 @@ func GetOptions() *Options {
 		FlagTequilapiDebugMode: config.GetBool(config.FlagTequilapiDebugMode),
 		TequilapiEnabled:       true,
 		BindAddress:            config.GetString(config.FlagBindAddress),
+		ServiceBindings:        config.GetString(config.FlagBindServices),
 		UI: OptionsUI{
 			UIEnabled:     config.GetBool(config.FlagUIEnable),
 			UIBindAddress: config.GetString(config.FlagUIAddress),
 			UIPort:        config.GetInt(config.FlagUIPort),
 		},
+		TequilapiTLS: OptionsTLS{
+			Enabled:         config.GetBool(config.FlagTequilapiTLS),
+			Domain:          config.GetString(config.FlagTequilapiTLSDomain),
+			ACMEHTTPAddress: config.GetString(config.FlagTequilapiTLSACMEHTTPAddress),
+			ACMEEmail:       config.GetString(config.FlagTequilapiTLSACMEEmail),
+		},
+		TequilapiSocket: OptionsSocket{
+			Path: config.GetString(config.FlagTequilapiSocket),
+			Mode: parseSocketMode(config.GetString(config.FlagTequilapiSocketMode)),
+			Only: config.GetBool(config.FlagTequilapiSocketOnly),
+		},
 		SwarmDialerDNSHeadstart: config.GetDuration(config.FlagDNSResolutionHeadstart),
 		FeedbackURL:             config.GetString(config.FlagFeedbackURL),
+		ConsumerIPPrivacy:       config.GetString(config.FlagPrivacyConsumerIP),
 		Keystore: OptionsKeystore{
 			UseLightweight: config.GetBool(config.FlagKeystoreLightweight),
 		},
@@ -140,6 +161,14 @@ func GetOptions() *Options {
 			Country:       config.GetString(config.FlagLocationCountry),
 			City:          config.GetString(config.FlagLocationCity),
 			IPType:        config.GetString(config.FlagLocationIPType),
+
+			ConsumerPrivacy: config.GetString(config.FlagLocationConsumerPrivacy),
+			ConsumerCountry: config.GetString(config.FlagLocationConsumerCountry),
+			GeoIP: OptionsGeoIP{
+				CountryURL:     config.GetString(config.FlagLocationGeoIPCountryURL),
+				ASNURL:         config.GetString(config.FlagLocationGeoIPASNURL),
+				UpdateInterval: config.GetDuration(config.FlagLocationGeoIPUpdateInterval),
+			},
 		},
 		Transactor: OptionsTransactor{
 			TransactorEndpointAddress:       config.GetString(config.FlagTransactorAddress),
@@ -162,6 +191,7 @@ func GetOptions() *Options {
 			BalanceFastPollTimeout:         config.GetDuration(config.FlagPaymentsFastBalancePollTimeout),
 			RegistryTransactorPollInterval: config.GetDuration(config.FlagPaymentsRegistryTransactorPollInterval),
 			RegistryTransactorPollTimeout:  config.GetDuration(config.FlagPaymentsRegistryTransactorPollTimeout),
+			RegistryStatusCacheTTL:         config.GetDuration(config.FlagPaymentsRegistryStatusCacheTTL),
 			ConsumerDataLeewayMegabytes:    config.GetUInt64(config.FlagPaymentsConsumerDataLeewayMegabytes),
 			HermesStatusRecheckInterval:    config.GetDuration(config.FlagPaymentsHermesStatusRecheckInterval),
 			MinAutoSettleAmount:            config.GetFloat64(config.FlagPaymentsZeroStakeUnsettledAmount),
@@ -194,6 +224,7 @@ func GetOptions() *Options {
 		}},
 		Firewall: OptionsFirewall{
 			BlockAlways: config.GetBool(config.FlagFirewallKillSwitch),
+			AllowedApps: config.GetStringSlice(config.FlagFirewallAllowedApps),
 		},
 		Consumer:        config.GetBool(config.FlagConsumer),
 		PilvytisAddress: config.GetString(config.FlagPilvytisAddress),
@@ -212,10 +243,17 @@ func GetLogOptions() *logconfig.LogOptions {
 		log.Error().Err(err).Msg("Failed to parse logging level")
 		level = zerolog.DebugLevel
 	}
+	dedupLevel, err := zerolog.ParseLevel(config.GetString(config.FlagLogDedupLevel))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse log deduplication level")
+		dedupLevel = zerolog.WarnLevel
+	}
 	return &logconfig.LogOptions{
-		LogLevel: level,
-		LogHTTP:  config.GetBool(config.FlagLogHTTP),
-		Filepath: filepath,
+		LogLevel:      level,
+		LogHTTP:       config.GetBool(config.FlagLogHTTP),
+		Filepath:      filepath,
+		DedupInterval: config.GetDuration(config.FlagLogDedupInterval),
+		DedupLevel:    dedupLevel,
 	}
 }
 
@@ -24,3 +24,8 @@ type OptionsChains struct {
 	Chain1 metadata.ChainDefinition
 	Chain2 metadata.ChainDefinition
 }
+
+// All returns all configured chains.
+func (o OptionsChains) All() []metadata.ChainDefinition {
+	return []metadata.ChainDefinition{o.Chain1, o.Chain2}
+}
@@ -20,4 +20,5 @@ package node
 // OptionsFirewall represent firewall control options
 type OptionsFirewall struct {
 	BlockAlways bool
+	AllowedApps []string
 }
@@ -17,6 +17,8 @@
 
 package node
 
+import "time"
+
 // LocationType identifies location type
 type LocationType string
 
@@ -40,4 +42,18 @@ type OptionsLocation struct {
 	Country string
 	City    string
 	IPType  string
+
+	// ConsumerPrivacy controls how consumer location is reported to providers and quality oracle.
+	ConsumerPrivacy string
+	// ConsumerCountry is reported instead of the detected country in override privacy mode.
+	ConsumerCountry string
+
+	GeoIP OptionsGeoIP
+}
+
+// OptionsGeoIP describes GeoIP database update configuration
+type OptionsGeoIP struct {
+	CountryURL     string
+	ASNURL         string
+	UpdateInterval time.Duration
 }
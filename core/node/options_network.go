@@ -28,4 +28,7 @@ type OptionsNetwork struct {
 	EtherClientRPCL2 []string
 	ChainID          int64
 	DNSMap           map[string][]string
+
+	// PrivateNetworkDefinition is a path to private network definition file, empty when running in public network.
+	PrivateNetworkDefinition string
 }
@@ -37,6 +37,7 @@ type OptionsPayments struct {
 	BalanceLongPollInterval        time.Duration
 	RegistryTransactorPollInterval time.Duration
 	RegistryTransactorPollTimeout  time.Duration
+	RegistryStatusCacheTTL         time.Duration
 	MinAutoSettleAmount            float64
 	MaxUnSettledAmount             float64
 
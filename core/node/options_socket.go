@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+)
+
+const defaultSocketMode os.FileMode = 0600
+
+// OptionsSocket describes unix domain socket of tequilapi.
+type OptionsSocket struct {
+	// Path of the socket, socket is disabled if empty.
+	Path string
+	Mode os.FileMode
+	// Only disables the TCP listener when socket is enabled.
+	Only bool
+}
+
+// Enabled returns whether tequilapi should be served on the socket.
+func (o OptionsSocket) Enabled() bool {
+	return o.Path != ""
+}
+
+// TCPDisabled returns whether tequilapi is served on the socket exclusively.
+func (o OptionsSocket) TCPDisabled() bool {
+	return o.Enabled() && o.Only
+}
+
+func parseSocketMode(value string) os.FileMode {
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || mode > 0777 {
+		log.Warn().Msgf("Invalid socket mode %q, using %#o", value, defaultSocketMode)
+		return defaultSocketMode
+	}
+	return os.FileMode(mode)
+}
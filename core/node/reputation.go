@@ -0,0 +1,341 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/crash"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/utils/clock"
+)
+
+const reputationBucket = "provider_reputation"
+
+// Reputation trends.
+const (
+	TrendImproving = "improving"
+	TrendDeclining = "declining"
+	TrendStable    = "stable"
+)
+
+// trendThreshold is the quality change considered significant.
+const trendThreshold = 0.1
+
+// ProviderProposals should return proposals of all providers as seen by consumers.
+type ProviderProposals func() ([]market.ServiceProposal, error)
+
+type reputationStorage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+	Delete(bucket string, data interface{}) error
+}
+
+// ServiceReputation describes how consumers see a single provider service.
+type ServiceReputation struct {
+	ServiceType string
+	// Listed is false when the service is not offered to consumers by discovery.
+	Listed    bool
+	Quality   float64
+	Latency   float64
+	Bandwidth float64
+	Uptime    float64
+	// LatencyPercentile is the percentage of providers of the same service type with higher latency.
+	LatencyPercentile float64
+	MonitoringFailed  bool
+	// FailureStreak is the number of consecutive samples monitoring failed in.
+	FailureStreak int
+	// Trend describes quality change during the requested period.
+	Trend string
+}
+
+// ReputationSample is a periodically recorded snapshot of provider reputation.
+type ReputationSample struct {
+	Key        string `storm:"id"`
+	ProviderID string
+	At         time.Time
+	Services   []ServiceReputation
+}
+
+// Reputation is provider reputation with its history.
+type Reputation struct {
+	ProviderID string
+	Services   []ServiceReputation
+	History    []ReputationSample
+}
+
+// ReputationTracker samples provider quality oracle metrics to build reputation history.
+type ReputationTracker struct {
+	proposals       ProviderProposals
+	sessions        ProviderSessions
+	storage         reputationStorage
+	currentIdentity currentIdentity
+	interval        time.Duration
+	retention       time.Duration
+	clock           clock.Clock
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewReputationTracker creates provider reputation tracker.
+func NewReputationTracker(proposals ProviderProposals, sessions ProviderSessions, storage reputationStorage, currentIdentity currentIdentity, interval time.Duration) *ReputationTracker {
+	return &ReputationTracker{
+		proposals:       proposals,
+		sessions:        sessions,
+		storage:         storage,
+		currentIdentity: currentIdentity,
+		interval:        interval,
+		retention:       30 * day,
+		clock:           clock.System,
+		stop:            make(chan struct{}),
+	}
+}
+
+// Start periodically records reputation samples.
+func (rt *ReputationTracker) Start() {
+	go func() {
+		defer crash.Recover("scheduler/reputation")
+		ticker := rt.clock.NewTicker(rt.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-rt.stop:
+				return
+			case <-ticker.C():
+				if _, err := rt.record(); err != nil && err != errIdentityNotFound {
+					log.Warn().Err(err).Msg("Failed to record provider reputation")
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops recording reputation samples.
+func (rt *ReputationTracker) Stop() {
+	rt.stopOnce.Do(func() {
+		close(rt.stop)
+	})
+}
+
+// Reputation returns current provider reputation with history for the given range ("1d", "7d", "30d").
+func (rt *ReputationTracker) Reputation(rangeTime string) (Reputation, error) {
+	days, err := parseRangeDays(rangeTime)
+	if err != nil {
+		return Reputation{}, err
+	}
+
+	current, err := rt.record()
+	if err != nil {
+		return Reputation{}, err
+	}
+
+	history, err := rt.history(current.ProviderID, rt.clock.Now().Add(-time.Duration(days)*day))
+	if err != nil {
+		return Reputation{}, err
+	}
+
+	services := current.Services
+	if len(history) > 0 {
+		oldest := history[0]
+		for i := range services {
+			services[i].Trend = trend(oldest, services[i])
+		}
+	}
+
+	return Reputation{
+		ProviderID: current.ProviderID,
+		Services:   services,
+		History:    history,
+	}, nil
+}
+
+// record samples current reputation, storing it when the last stored sample is older than the interval.
+func (rt *ReputationTracker) record() (ReputationSample, error) {
+	id, ok := rt.currentIdentity.GetUnlockedIdentity()
+	if !ok {
+		return ReputationSample{}, errIdentityNotFound
+	}
+
+	proposals, err := rt.proposals()
+	if err != nil {
+		return ReputationSample{}, fmt.Errorf("could not get proposals: %w", err)
+	}
+
+	history, err := rt.history(id.Address, time.Time{})
+	if err != nil {
+		return ReputationSample{}, err
+	}
+
+	now := rt.clock.Now().UTC()
+	sample := ReputationSample{
+		Key:        fmt.Sprintf("%s|%d", id.Address, now.UnixNano()),
+		ProviderID: id.Address,
+		At:         now,
+		Services:   resolveServiceReputation(id.Address, proposals, rt.sessions(id.Address)),
+	}
+	for i := range sample.Services {
+		sample.Services[i].FailureStreak = failureStreak(history, sample.Services[i])
+	}
+
+	if len(history) > 0 && now.Sub(history[len(history)-1].At) < rt.interval {
+		return sample, nil
+	}
+	if err := rt.storage.Store(reputationBucket, &sample); err != nil {
+		return ReputationSample{}, fmt.Errorf("could not store reputation sample: %w", err)
+	}
+	rt.prune(history, now)
+	return sample, nil
+}
+
+func (rt *ReputationTracker) history(providerID string, since time.Time) ([]ReputationSample, error) {
+	var all []ReputationSample
+	if err := rt.storage.GetAllFrom(reputationBucket, &all); err != nil && !errors.Is(err, storm.ErrNotFound) {
+		return nil, fmt.Errorf("could not load reputation history: %w", err)
+	}
+
+	samples := make([]ReputationSample, 0, len(all))
+	for _, s := range all {
+		if s.ProviderID == providerID && !s.At.Before(since) {
+			samples = append(samples, s)
+		}
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].At.Before(samples[j].At)
+	})
+	return samples, nil
+}
+
+func (rt *ReputationTracker) prune(history []ReputationSample, now time.Time) {
+	for _, s := range history {
+		if now.Sub(s.At) <= rt.retention {
+			break
+		}
+		s := s
+		if err := rt.storage.Delete(reputationBucket, &s); err != nil {
+			log.Warn().Err(err).Msg("Failed to prune reputation sample")
+		}
+	}
+}
+
+func resolveServiceReputation(providerID string, proposals []market.ServiceProposal, sessions []Session) []ServiceReputation {
+	byType := make(map[string]*ServiceReputation)
+	get := func(serviceType string) *ServiceReputation {
+		if sr, ok := byType[serviceType]; ok {
+			return sr
+		}
+		sr := &ServiceReputation{ServiceType: serviceType, Trend: TrendStable}
+		byType[serviceType] = sr
+		return sr
+	}
+
+	for _, p := range proposals {
+		if p.ProviderID != providerID {
+			continue
+		}
+		sr := get(p.ServiceType)
+		sr.Listed = true
+		sr.Quality = p.Quality.Quality
+		sr.Latency = p.Quality.Latency
+		sr.Bandwidth = p.Quality.Bandwidth
+		sr.Uptime = p.Quality.Uptime
+		sr.LatencyPercentile = latencyPercentile(p, proposals)
+	}
+	for _, s := range sessions {
+		get(s.ServiceType).MonitoringFailed = s.MonitoringFailed
+	}
+
+	services := make([]ServiceReputation, 0, len(byType))
+	for _, sr := range byType {
+		services = append(services, *sr)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].ServiceType < services[j].ServiceType
+	})
+	return services
+}
+
+func latencyPercentile(own market.ServiceProposal, proposals []market.ServiceProposal) float64 {
+	if own.Quality.Latency <= 0 {
+		return 0
+	}
+
+	var measured, slower int
+	for _, p := range proposals {
+		if p.ServiceType != own.ServiceType || p.ProviderID == own.ProviderID || p.Quality.Latency <= 0 {
+			continue
+		}
+		measured++
+		if p.Quality.Latency > own.Quality.Latency {
+			slower++
+		}
+	}
+	if measured == 0 {
+		return 100
+	}
+	return float64(slower) / float64(measured) * 100
+}
+
+func failureStreak(history []ReputationSample, current ServiceReputation) int {
+	if !current.MonitoringFailed {
+		return 0
+	}
+
+	streak := 1
+	for i := len(history) - 1; i >= 0; i-- {
+		sr, ok := findService(history[i], current.ServiceType)
+		if !ok || !sr.MonitoringFailed {
+			break
+		}
+		streak++
+	}
+	return streak
+}
+
+func trend(oldest ReputationSample, current ServiceReputation) string {
+	prev, ok := findService(oldest, current.ServiceType)
+	if !ok {
+		return TrendStable
+	}
+
+	switch change := current.Quality - prev.Quality; {
+	case change >= trendThreshold:
+		return TrendImproving
+	case change <= -trendThreshold:
+		return TrendDeclining
+	default:
+		return TrendStable
+	}
+}
+
+func findService(sample ReputationSample, serviceType string) (ServiceReputation, bool) {
+	for _, sr := range sample.Services {
+		if sr.ServiceType == serviceType {
+			return sr, true
+		}
+	}
+	return ServiceReputation{}, false
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/utils/clock"
+)
+
+func newTestReputationTracker(t *testing.T, quality *float64, monitoringFailed *bool) (*ReputationTracker, *clock.Mock) {
+	bolt, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { bolt.Close() })
+
+	proposals := func() ([]market.ServiceProposal, error) {
+		return []market.ServiceProposal{
+			{ProviderID: "0x1", ServiceType: "wireguard", Quality: market.Quality{Quality: *quality, Latency: 50}},
+			{ProviderID: "0x2", ServiceType: "wireguard", Quality: market.Quality{Quality: 2, Latency: 100}},
+			{ProviderID: "0x3", ServiceType: "wireguard", Quality: market.Quality{Quality: 2, Latency: 150}},
+			{ProviderID: "0x4", ServiceType: "wireguard", Quality: market.Quality{Quality: 2, Latency: 20}},
+			{ProviderID: "0x5", ServiceType: "wireguard", Quality: market.Quality{Quality: 2, Latency: 0}},
+			{ProviderID: "0x2", ServiceType: "scraping", Quality: market.Quality{Quality: 2, Latency: 10}},
+		}, nil
+	}
+	sessions := func(providerID string) []Session {
+		return []Session{{ProviderID: providerID, ServiceType: "wireguard", MonitoringFailed: *monitoringFailed}}
+	}
+
+	clk := clock.NewMock(time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC))
+	rt := NewReputationTracker(proposals, sessions, bolt, newMockCurrentIdentity("0x1", false), time.Hour)
+	rt.clock = clk
+	return rt, clk
+}
+
+func TestReputationTracker_Reputation(t *testing.T) {
+	quality, failed := 2.5, false
+	rt, _ := newTestReputationTracker(t, &quality, &failed)
+
+	reputation, err := rt.Reputation("7d")
+	require.NoError(t, err)
+
+	assert.Equal(t, "0x1", reputation.ProviderID)
+	require.Len(t, reputation.Services, 1)
+	sr := reputation.Services[0]
+	assert.Equal(t, "wireguard", sr.ServiceType)
+	assert.True(t, sr.Listed)
+	assert.Equal(t, 2.5, sr.Quality)
+	// faster than 2 of 3 measured providers
+	assert.InDelta(t, 66.67, sr.LatencyPercentile, 0.01)
+	assert.Equal(t, TrendStable, sr.Trend)
+	assert.Len(t, reputation.History, 1)
+}
+
+func TestReputationTracker_TrendAndFailureStreak(t *testing.T) {
+	quality, failed := 2.5, true
+	rt, clk := newTestReputationTracker(t, &quality, &failed)
+
+	for i := 0; i < 3; i++ {
+		_, err := rt.record()
+		require.NoError(t, err)
+		clk.Add(time.Hour)
+	}
+	quality = 1.5
+
+	reputation, err := rt.Reputation("1d")
+	require.NoError(t, err)
+
+	require.Len(t, reputation.Services, 1)
+	assert.Equal(t, 4, reputation.Services[0].FailureStreak)
+	assert.Equal(t, TrendDeclining, reputation.Services[0].Trend)
+	assert.Len(t, reputation.History, 4)
+
+	failed = false
+	reputation, err = rt.Reputation("1d")
+	require.NoError(t, err)
+	assert.Equal(t, 0, reputation.Services[0].FailureStreak)
+}
+
+func TestReputationTracker_RecordsOncePerInterval(t *testing.T) {
+	quality, failed := 2.5, false
+	rt, clk := newTestReputationTracker(t, &quality, &failed)
+
+	_, err := rt.Reputation("1d")
+	require.NoError(t, err)
+	clk.Add(30 * time.Minute)
+	reputation, err := rt.Reputation("1d")
+	require.NoError(t, err)
+	assert.Len(t, reputation.History, 1)
+}
+
+func TestReputationTracker_PrunesOldSamples(t *testing.T) {
+	quality, failed := 2.5, false
+	rt, clk := newTestReputationTracker(t, &quality, &failed)
+
+	_, err := rt.record()
+	require.NoError(t, err)
+	clk.Add(31 * day)
+	_, err = rt.record()
+	require.NoError(t, err)
+
+	history, err := rt.history("0x1", time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+}
+
+func TestReputationTracker_RequiresUnlockedIdentity(t *testing.T) {
+	quality, failed := 2.5, false
+	rt, _ := newTestReputationTracker(t, &quality, &failed)
+	rt.currentIdentity = newMockCurrentIdentity("", true)
+
+	_, err := rt.Reputation("7d")
+	assert.Equal(t, errIdentityNotFound, err)
+}
+
+func TestReputationTracker_InvalidRange(t *testing.T) {
+	quality, failed := 2.5, false
+	rt, _ := newTestReputationTracker(t, &quality, &failed)
+
+	_, err := rt.Reputation("week")
+	assert.Error(t, err)
+}
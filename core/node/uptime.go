@@ -0,0 +1,335 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/crash"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/utils/clock"
+)
+
+const uptimeBucket = "node_uptime"
+
+const uptimeDateLayout = "2006-01-02"
+
+// UptimeGrouping is the period availability is reported for.
+type UptimeGrouping string
+
+const (
+	// UptimeGroupingDay reports availability per UTC day.
+	UptimeGroupingDay UptimeGrouping = "day"
+	// UptimeGroupingWeek reports availability per week starting on Monday.
+	UptimeGroupingWeek UptimeGrouping = "week"
+)
+
+type uptimeStorage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+	Delete(bucket string, data interface{}) error
+}
+
+type monitoringStatusProvider interface {
+	Status() MonitoringStatus
+}
+
+// UptimeDay is the node availability recorded during a single UTC day.
+type UptimeDay struct {
+	Date string `storm:"id"`
+	// Up is the time node process was running.
+	Up time.Duration
+	// ServiceAvailable is the time at least one service was running.
+	ServiceAvailable time.Duration
+	// Reachable is the time services were running and confirmed reachable by the monitoring agent.
+	Reachable time.Duration
+}
+
+// UptimePeriod is the node availability during a period of time.
+type UptimePeriod struct {
+	Start time.Time
+	End   time.Time
+
+	Up               time.Duration
+	ServiceAvailable time.Duration
+	Reachable        time.Duration
+
+	// Percentages of the elapsed part of the period.
+	Uptime              float64
+	ServiceAvailability float64
+	Reachability        float64
+}
+
+// UptimeReport describes node reliability during a period of time.
+type UptimeReport struct {
+	StartedAt time.Time
+	Uptime    time.Duration
+	Total     UptimePeriod
+	Periods   []UptimePeriod
+}
+
+// UptimeTracker records node process uptime, service availability and
+// monitoring agent confirmed reachability, aggregated per day.
+type UptimeTracker struct {
+	storage    uptimeStorage
+	monitoring monitoringStatusProvider
+	interval   time.Duration
+	retention  time.Duration
+	clock      clock.Clock
+
+	mu         sync.Mutex
+	startedAt  time.Time
+	lastSample time.Time
+	days       map[string]*UptimeDay
+	running    map[string]struct{}
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewUptimeTracker creates node uptime tracker.
+func NewUptimeTracker(storage uptimeStorage, monitoring monitoringStatusProvider, interval time.Duration) *UptimeTracker {
+	return &UptimeTracker{
+		storage:    storage,
+		monitoring: monitoring,
+		interval:   interval,
+		retention:  35 * day,
+		clock:      clock.System,
+		days:       make(map[string]*UptimeDay),
+		running:    make(map[string]struct{}),
+		stop:       make(chan struct{}),
+	}
+}
+
+// Subscribe subscribes to service status events.
+func (ut *UptimeTracker) Subscribe(bus eventbus.Subscriber) error {
+	return bus.SubscribeAsync(servicestate.AppTopicServiceStatus, ut.handleServiceStatus)
+}
+
+func (ut *UptimeTracker) handleServiceStatus(e servicestate.AppEventServiceStatus) {
+	ut.mu.Lock()
+	defer ut.mu.Unlock()
+
+	if e.Status == string(servicestate.Running) {
+		ut.running[e.ID] = struct{}{}
+	} else {
+		delete(ut.running, e.ID)
+	}
+}
+
+// Start loads recorded history and periodically records node availability.
+func (ut *UptimeTracker) Start() {
+	var days []UptimeDay
+	if err := ut.storage.GetAllFrom(uptimeBucket, &days); err != nil && !errors.Is(err, storm.ErrNotFound) {
+		log.Warn().Err(err).Msg("Failed to load node uptime history")
+	}
+
+	ut.mu.Lock()
+	for i := range days {
+		ut.days[days[i].Date] = &days[i]
+	}
+	ut.startedAt = ut.clock.Now().UTC()
+	ut.lastSample = ut.startedAt
+	ut.mu.Unlock()
+
+	go func() {
+		defer crash.Recover("scheduler/uptime")
+		ticker := ut.clock.NewTicker(ut.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ut.stop:
+				return
+			case <-ticker.C():
+				ut.sample()
+			}
+		}
+	}()
+}
+
+// Stop records availability until now and stops the tracker.
+func (ut *UptimeTracker) Stop() {
+	ut.stopOnce.Do(func() {
+		close(ut.stop)
+		ut.sample()
+	})
+}
+
+// sample attributes the time passed since the last sample to the current node state.
+func (ut *UptimeTracker) sample() {
+	status := ut.monitoring.Status()
+	now := ut.clock.Now().UTC()
+
+	ut.mu.Lock()
+	defer ut.mu.Unlock()
+
+	if ut.lastSample.IsZero() {
+		return
+	}
+	from := ut.lastSample
+	ut.lastSample = now
+	// Gaps longer than a couple of intervals mean the host was suspended rather than running.
+	if now.Sub(from) > 2*ut.interval {
+		from = now.Add(-ut.interval)
+	}
+
+	serviceAvailable := len(ut.running) > 0
+	reachable := serviceAvailable && status == Passed
+
+	for start := from; start.Before(now); {
+		dayStart := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+		end := dayStart.Add(day)
+		if end.After(now) {
+			end = now
+		}
+
+		key := dayStart.Format(uptimeDateLayout)
+		d, ok := ut.days[key]
+		if !ok {
+			d = &UptimeDay{Date: key}
+			ut.days[key] = d
+		}
+		elapsed := end.Sub(start)
+		d.Up += elapsed
+		if serviceAvailable {
+			d.ServiceAvailable += elapsed
+		}
+		if reachable {
+			d.Reachable += elapsed
+		}
+		if err := ut.storage.Store(uptimeBucket, d); err != nil {
+			log.Warn().Err(err).Msg("Failed to store node uptime")
+		}
+		start = end
+	}
+
+	ut.prune(now)
+}
+
+func (ut *UptimeTracker) prune(now time.Time) {
+	cutoff := now.Add(-ut.retention).Format(uptimeDateLayout)
+	for key, d := range ut.days {
+		if key >= cutoff {
+			continue
+		}
+		if err := ut.storage.Delete(uptimeBucket, d); err != nil {
+			log.Warn().Err(err).Msg("Failed to prune node uptime")
+			continue
+		}
+		delete(ut.days, key)
+	}
+}
+
+// UptimeReport returns node availability for the given range ("1d", "7d", "30d") grouped per day or week.
+func (ut *UptimeTracker) UptimeReport(rangeTime string, grouping UptimeGrouping) (UptimeReport, error) {
+	days, err := parseRangeDays(rangeTime)
+	if err != nil {
+		return UptimeReport{}, err
+	}
+	if grouping != UptimeGroupingDay && grouping != UptimeGroupingWeek {
+		return UptimeReport{}, fmt.Errorf("invalid grouping: %q", grouping)
+	}
+
+	ut.sample()
+
+	ut.mu.Lock()
+	defer ut.mu.Unlock()
+
+	now := ut.clock.Now().UTC()
+	history := make([]UptimeDay, 0, len(ut.days))
+	for _, d := range ut.days {
+		history = append(history, *d)
+	}
+
+	report := uptimeReport(history, now, days, grouping)
+	report.StartedAt = ut.startedAt
+	report.Uptime = now.Sub(ut.startedAt)
+	return report, nil
+}
+
+func uptimeReport(history []UptimeDay, now time.Time, days int, grouping UptimeGrouping) UptimeReport {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	rangeStart := today.AddDate(0, 0, -(days - 1))
+
+	var periods []UptimePeriod
+	for start := rangeStart; start.Before(now); {
+		end := start.AddDate(0, 0, 1)
+		if grouping == UptimeGroupingWeek {
+			_, end = goalPeriod(EarningsGoalPeriodWeek, start)
+		}
+		periods = append(periods, UptimePeriod{Start: start, End: end})
+		start = end
+	}
+
+	total := UptimePeriod{Start: rangeStart, End: today.AddDate(0, 0, 1)}
+	for _, d := range history {
+		date, err := time.Parse(uptimeDateLayout, d.Date)
+		if err != nil || date.Before(rangeStart) || date.After(today) {
+			continue
+		}
+		i := sort.Search(len(periods), func(i int) bool {
+			return periods[i].End.After(date)
+		})
+		periods[i].add(d)
+		total.add(d)
+	}
+
+	for i := range periods {
+		periods[i].computePercentages(now)
+	}
+	total.computePercentages(now)
+
+	return UptimeReport{Total: total, Periods: periods}
+}
+
+func (p *UptimePeriod) add(d UptimeDay) {
+	p.Up += d.Up
+	p.ServiceAvailable += d.ServiceAvailable
+	p.Reachable += d.Reachable
+}
+
+func (p *UptimePeriod) computePercentages(now time.Time) {
+	end := p.End
+	if end.After(now) {
+		end = now
+	}
+	elapsed := end.Sub(p.Start)
+	if elapsed <= 0 {
+		return
+	}
+
+	percent := func(d time.Duration) float64 {
+		v := float64(d) / float64(elapsed) * 100
+		if v > 100 {
+			return 100
+		}
+		return v
+	}
+	p.Uptime = percent(p.Up)
+	p.ServiceAvailability = percent(p.ServiceAvailable)
+	p.Reachability = percent(p.Reachable)
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/utils/clock"
+)
+
+type mockMonitoringStatusProvider struct {
+	status MonitoringStatus
+}
+
+func (m *mockMonitoringStatusProvider) Status() MonitoringStatus {
+	return m.status
+}
+
+func newTestUptimeTracker(storage uptimeStorage, monitoring monitoringStatusProvider, clk *clock.Mock) *UptimeTracker {
+	ut := NewUptimeTracker(storage, monitoring, time.Hour)
+	ut.clock = clk
+	ut.Start()
+	clk.BlockUntil(1)
+	return ut
+}
+
+// advance moves the clock and waits for the tracker to sample on its tick.
+func advance(t *testing.T, ut *UptimeTracker, clk *clock.Mock, d time.Duration) {
+	clk.Add(d)
+	assert.Eventually(t, func() bool {
+		ut.mu.Lock()
+		defer ut.mu.Unlock()
+		return ut.lastSample.Equal(clk.Now().UTC())
+	}, time.Second, time.Millisecond)
+}
+
+func TestUptimeTracker_UptimeReport(t *testing.T) {
+	bolt, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { bolt.Close() })
+
+	monitoring := &mockMonitoringStatusProvider{status: Passed}
+	clk := clock.NewMock(time.Date(2022, time.July, 1, 22, 0, 0, 0, time.UTC))
+	ut := newTestUptimeTracker(bolt, monitoring, clk)
+	defer ut.Stop()
+
+	ut.handleServiceStatus(servicestate.AppEventServiceStatus{ID: "1", Status: string(servicestate.Running)})
+	advance(t, ut, clk, time.Hour)
+
+	monitoring.status = Failed
+	advance(t, ut, clk, time.Hour)
+
+	ut.handleServiceStatus(servicestate.AppEventServiceStatus{ID: "1", Status: string(servicestate.NotRunning)})
+	advance(t, ut, clk, time.Hour)
+
+	report, err := ut.UptimeReport("7d", UptimeGroupingDay)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2022, time.July, 1, 22, 0, 0, 0, time.UTC), report.StartedAt)
+	assert.Equal(t, 3*time.Hour, report.Uptime)
+	require.Len(t, report.Periods, 7)
+	assert.Equal(t, time.Date(2022, time.June, 26, 0, 0, 0, 0, time.UTC), report.Periods[0].Start)
+
+	july1 := report.Periods[5]
+	assert.Equal(t, time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC), july1.Start)
+	assert.Equal(t, 2*time.Hour, july1.Up)
+	assert.Equal(t, 2*time.Hour, july1.ServiceAvailable)
+	assert.Equal(t, time.Hour, july1.Reachable)
+	assert.InDelta(t, 100.0/12, july1.Uptime, 0.001)
+	assert.InDelta(t, 100.0/24, july1.Reachability, 0.001)
+
+	// the current day is measured against its elapsed part only
+	july2 := report.Periods[6]
+	assert.Equal(t, time.Hour, july2.Up)
+	assert.Equal(t, time.Duration(0), july2.ServiceAvailable)
+	assert.Equal(t, 100.0, july2.Uptime)
+	assert.Equal(t, 0.0, july2.ServiceAvailability)
+
+	assert.Equal(t, 3*time.Hour, report.Total.Up)
+
+	report, err = ut.UptimeReport("7d", UptimeGroupingWeek)
+	require.NoError(t, err)
+	require.Len(t, report.Periods, 2)
+	assert.Equal(t, time.Date(2022, time.June, 27, 0, 0, 0, 0, time.UTC), report.Periods[0].End)
+	assert.Equal(t, time.Date(2022, time.July, 4, 0, 0, 0, 0, time.UTC), report.Periods[1].End)
+	assert.Equal(t, 3*time.Hour, report.Periods[1].Up)
+}
+
+func TestUptimeTracker_RestoresHistory(t *testing.T) {
+	bolt, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { bolt.Close() })
+
+	monitoring := &mockMonitoringStatusProvider{status: Passed}
+	clk := clock.NewMock(time.Date(2022, time.July, 1, 10, 0, 0, 0, time.UTC))
+	ut := newTestUptimeTracker(bolt, monitoring, clk)
+	advance(t, ut, clk, time.Hour)
+	ut.Stop()
+
+	// restarted two hours later
+	clk.Add(2 * time.Hour)
+	ut = newTestUptimeTracker(bolt, monitoring, clk)
+	defer ut.Stop()
+	clk.Add(time.Hour)
+
+	report, err := ut.UptimeReport("1d", UptimeGroupingDay)
+	require.NoError(t, err)
+	require.Len(t, report.Periods, 1)
+	assert.Equal(t, 2*time.Hour, report.Periods[0].Up)
+	assert.Equal(t, time.Hour, report.Uptime)
+}
+
+func TestUptimeTracker_IgnoresSuspendedTime(t *testing.T) {
+	bolt, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { bolt.Close() })
+
+	clk := clock.NewMock(time.Date(2022, time.July, 1, 10, 0, 0, 0, time.UTC))
+	ut := newTestUptimeTracker(bolt, &mockMonitoringStatusProvider{}, clk)
+	defer ut.Stop()
+
+	clk.Add(5 * time.Hour)
+	report, err := ut.UptimeReport("1d", UptimeGroupingDay)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, report.Total.Up)
+}
+
+func TestUptimeTracker_InvalidRequest(t *testing.T) {
+	ut := NewUptimeTracker(nil, &mockMonitoringStatusProvider{}, time.Hour)
+
+	_, err := ut.UptimeReport("1y", UptimeGroupingDay)
+	assert.Error(t, err)
+
+	_, err = ut.UptimeReport("7d", UptimeGrouping("year"))
+	assert.Error(t, err)
+}
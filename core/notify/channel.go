@@ -0,0 +1,133 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Message is a rendered notification.
+type Message struct {
+	Subject string
+	Body    string
+}
+
+// Channel delivers notifications to the node operator.
+type Channel interface {
+	Name() string
+	Send(msg Message) error
+}
+
+type sendMailFunc func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+// EmailChannel sends notifications using SMTP server.
+type EmailChannel struct {
+	config   EmailConfig
+	sendMail sendMailFunc
+}
+
+// NewEmailChannel returns a new SMTP email channel.
+func NewEmailChannel(config EmailConfig) *EmailChannel {
+	return &EmailChannel{config: config, sendMail: smtp.SendMail}
+}
+
+// Name returns channel name.
+func (c *EmailChannel) Name() string {
+	return "email"
+}
+
+// Send sends the message to all recipients.
+func (c *EmailChannel) Send(msg Message) error {
+	var auth smtp.Auth
+	if c.config.Username != "" {
+		auth = smtp.PlainAuth("", c.config.Username, c.config.Password, c.config.Host)
+	}
+
+	var body strings.Builder
+	body.WriteString("From: " + c.config.From + "\r\n")
+	body.WriteString("To: " + strings.Join(c.config.To, ", ") + "\r\n")
+	body.WriteString("Subject: " + msg.Subject + "\r\n")
+	body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	body.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	addr := net.JoinHostPort(c.config.Host, strconv.Itoa(c.config.Port))
+	return c.sendMail(addr, auth, c.config.From, c.config.To, []byte(body.String()))
+}
+
+type httpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// TelegramChannel sends notifications using Telegram bot.
+type TelegramChannel struct {
+	config  TelegramConfig
+	client  httpClient
+	baseURL string
+}
+
+// NewTelegramChannel returns a new Telegram bot channel.
+func NewTelegramChannel(config TelegramConfig, client httpClient) *TelegramChannel {
+	return &TelegramChannel{config: config, client: client, baseURL: "https://api.telegram.org"}
+}
+
+// Name returns channel name.
+func (c *TelegramChannel) Name() string {
+	return "telegram"
+}
+
+// Send sends the message to the configured chat.
+func (c *TelegramChannel) Send(msg Message) error {
+	payload, err := json.Marshal(map[string]string{
+		"chat_id": c.config.ChatID,
+		"text":    msg.Subject + "\n\n" + msg.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/bot%s/sendMessage", c.baseURL, c.config.BotToken), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// Request URL contains the bot token, keep it out of logs.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return fmt.Errorf("could not reach telegram: %w", urlErr.Err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("telegram responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
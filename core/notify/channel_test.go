@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailChannel_Send(t *testing.T) {
+	ch := NewEmailChannel(EmailConfig{Host: "smtp.example.com", Port: 587, Username: "user", Password: "pass", From: "node@example.com", To: []string{"a@example.com", "b@example.com"}})
+	var sent struct {
+		addr string
+		auth smtp.Auth
+		from string
+		to   []string
+		msg  string
+	}
+	ch.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		sent.addr, sent.auth, sent.from, sent.to, sent.msg = addr, a, from, to, string(msg)
+		return nil
+	}
+
+	require.NoError(t, ch.Send(Message{Subject: "Alert: balance_below", Body: "Balance is low\nTop up"}))
+
+	assert.Equal(t, "smtp.example.com:587", sent.addr)
+	assert.NotNil(t, sent.auth)
+	assert.Equal(t, "node@example.com", sent.from)
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, sent.to)
+	assert.Equal(t, "From: node@example.com\r\n"+
+		"To: a@example.com, b@example.com\r\n"+
+		"Subject: Alert: balance_below\r\n"+
+		"Content-Type: text/plain; charset=UTF-8\r\n\r\n"+
+		"Balance is low\r\nTop up", sent.msg)
+}
+
+func TestTelegramChannel_Send(t *testing.T) {
+	var path string
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		if payload["chat_id"] != "42" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	ch := NewTelegramChannel(TelegramConfig{BotToken: "token", ChatID: "42"}, http.DefaultClient)
+	ch.baseURL = server.URL
+
+	require.NoError(t, ch.Send(Message{Subject: "Settlement completed", Body: "Done"}))
+	assert.Equal(t, "/bottoken/sendMessage", path)
+	assert.Equal(t, map[string]string{"chat_id": "42", "text": "Settlement completed\n\nDone"}, payload)
+
+	ch.config.ChatID = "1"
+	assert.EqualError(t, ch.Send(Message{}), "telegram responded with status 400")
+}
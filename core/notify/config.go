@@ -0,0 +1,157 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"text/template"
+)
+
+// Event kinds notifications are sent for, also used as template names.
+const (
+	KindAlert               = "alert"
+	KindSettlementCompleted = "settlement_completed"
+	KindServiceDown         = "service_down"
+	KindTest                = "test"
+)
+
+// defaultMaxPerHour limits notifications sent to each channel unless configured otherwise.
+const defaultMaxPerHour = 20
+
+// DefaultSMTPPort is used for the email channel unless configured otherwise.
+const DefaultSMTPPort = 587
+
+// ErrInvalidConfig is returned for notification settings which can not be applied.
+var ErrInvalidConfig = errors.New("invalid notification config")
+
+// Config holds notification channels settings.
+type Config struct {
+	// Email channel is disabled if nil.
+	Email *EmailConfig
+	// Telegram channel is disabled if nil.
+	Telegram *TelegramConfig
+	// Templates override default message templates by event kind. The first line
+	// of a rendered message is used as the subject.
+	Templates map[string]string
+	// MaxPerHour limits notifications sent to each channel, defaults to 20.
+	MaxPerHour int
+}
+
+// EmailConfig holds SMTP server settings.
+type EmailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// TelegramConfig holds Telegram bot settings.
+type TelegramConfig struct {
+	BotToken string
+	ChatID   string
+}
+
+// mapSecrets returns a copy of the config with channel secrets replaced by the results of fn.
+func (c Config) mapSecrets(fn func(string) (string, error)) (Config, error) {
+	if c.Email != nil {
+		e := *c.Email
+		password, err := fn(e.Password)
+		if err != nil {
+			return Config{}, err
+		}
+		e.Password = password
+		c.Email = &e
+	}
+	if c.Telegram != nil {
+		t := *c.Telegram
+		token, err := fn(t.BotToken)
+		if err != nil {
+			return Config{}, err
+		}
+		t.BotToken = token
+		c.Telegram = &t
+	}
+	return c, nil
+}
+
+func (c Config) hasSecrets() bool {
+	return (c.Email != nil && c.Email.Password != "") || (c.Telegram != nil && c.Telegram.BotToken != "")
+}
+
+func (c Config) sameSecrets(other Config) bool {
+	secrets := func(c Config) (password, token string) {
+		if c.Email != nil {
+			password = c.Email.Password
+		}
+		if c.Telegram != nil {
+			token = c.Telegram.BotToken
+		}
+		return password, token
+	}
+	password, token := secrets(c)
+	otherPassword, otherToken := secrets(other)
+	return password == otherPassword && token == otherToken
+}
+
+// Validate checks the config and fills in defaults of optional fields.
+func (c *Config) Validate() error {
+	if c.MaxPerHour < 0 {
+		return fmt.Errorf("%w: max per hour can not be negative", ErrInvalidConfig)
+	}
+	if c.MaxPerHour == 0 {
+		c.MaxPerHour = defaultMaxPerHour
+	}
+
+	if e := c.Email; e != nil {
+		if e.Host == "" {
+			return fmt.Errorf("%w: email host is required", ErrInvalidConfig)
+		}
+		if e.Port == 0 {
+			e.Port = DefaultSMTPPort
+		}
+		if _, err := mail.ParseAddress(e.From); err != nil {
+			return fmt.Errorf("%w: invalid email sender %q", ErrInvalidConfig, e.From)
+		}
+		if len(e.To) == 0 {
+			return fmt.Errorf("%w: at least one email recipient is required", ErrInvalidConfig)
+		}
+		for _, to := range e.To {
+			if _, err := mail.ParseAddress(to); err != nil {
+				return fmt.Errorf("%w: invalid email recipient %q", ErrInvalidConfig, to)
+			}
+		}
+	}
+
+	if t := c.Telegram; t != nil && (t.BotToken == "" || t.ChatID == "") {
+		return fmt.Errorf("%w: telegram bot token and chat ID are required", ErrInvalidConfig)
+	}
+
+	for kind, text := range c.Templates {
+		if _, ok := defaultTemplates[kind]; !ok {
+			return fmt.Errorf("%w: unknown template %q", ErrInvalidConfig, kind)
+		}
+		if _, err := template.New(kind).Parse(text); err != nil {
+			return fmt.Errorf("%w: template %q: %v", ErrInvalidConfig, kind, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package notify
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/time/rate"
+
+	"github.com/mysteriumnetwork/node/core/alerting"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/core/storage/encryption"
+	"github.com/mysteriumnetwork/node/eventbus"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/node/utils"
+)
+
+const (
+	bucketName = "notifications"
+	configKey  = "config"
+)
+
+var defaultTemplates = map[string]string{
+	KindAlert: `{{if .Active}}Alert{{else}}Resolved{{end}}: {{.Kind}}
+{{.Message}}
+Severity: {{.Severity}}, fired at {{.FiredAt.Format "2006-01-02 15:04 MST"}}`,
+	KindSettlementCompleted: `Settlement completed
+Earnings of {{.ProviderID.Address}} were settled with hermes {{.HermesID.Hex}} on chain {{.ChainID}}.`,
+	KindServiceDown: `Service down: {{.Type}}
+{{.Type}} service {{.ID}} is unhealthy: {{.Error}}`,
+	KindTest: `Test notification
+Notifications from your node are configured correctly.`,
+}
+
+// ErrNoChannels is returned when sending a notification without any channel configured.
+var ErrNoChannels = errors.New("no notification channels configured")
+
+type configStorage interface {
+	GetValue(bucket string, key interface{}, to interface{}) error
+	SetValue(bucket string, key interface{}, to interface{}) error
+}
+
+type secretCipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// Notifier renders notifications about alerts and key node events and sends them to configured channels.
+// Channel secrets are stored encrypted if cipher is given.
+type Notifier struct {
+	storage     configStorage
+	cipher      secretCipher
+	newChannels func(Config) []Channel
+
+	mu        sync.Mutex
+	config    Config
+	channels  []Channel
+	limiters  map[string]*rate.Limiter
+	templates *template.Template
+}
+
+// NewNotifier returns a new notifier, loading stored configuration.
+func NewNotifier(storage configStorage, client httpClient, cipher secretCipher) *Notifier {
+	n := &Notifier{
+		storage: storage,
+		cipher:  cipher,
+		newChannels: func(config Config) []Channel {
+			var channels []Channel
+			if config.Email != nil {
+				channels = append(channels, NewEmailChannel(*config.Email))
+			}
+			if config.Telegram != nil {
+				channels = append(channels, NewTelegramChannel(*config.Telegram, client))
+			}
+			return channels
+		},
+	}
+
+	config, err := n.load()
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not load notification config")
+	}
+	if err := config.Validate(); err != nil {
+		log.Warn().Err(err).Msg("Ignoring stored notification config")
+		config = Config{MaxPerHour: defaultMaxPerHour}
+	}
+	n.apply(config)
+	return n
+}
+
+// Config returns current notification configuration.
+func (n *Notifier) Config() Config {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.config
+}
+
+// SetConfig validates and stores notification configuration, replacing configured channels.
+func (n *Notifier) SetConfig(config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if err := n.store(config); err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.apply(config)
+	return nil
+}
+
+func (n *Notifier) load() (Config, error) {
+	var config Config
+	err := n.storage.GetValue(bucketName, configKey, &config)
+	if errors.Is(err, storm.ErrNotFound) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, err
+	}
+
+	stored := config
+	config, err = config.mapSecrets(n.decrypt)
+	if err != nil {
+		return Config{}, err
+	}
+
+	// Secrets stored in plaintext before encryption are encrypted on the first load.
+	if n.cipher != nil && config.hasSecrets() && config.sameSecrets(stored) {
+		if err := n.store(config); err != nil {
+			log.Warn().Err(err).Msg("Could not encrypt stored notification secrets")
+		}
+	}
+	return config, nil
+}
+
+func (n *Notifier) store(config Config) error {
+	sealed, err := config.mapSecrets(n.encrypt)
+	if err != nil {
+		return err
+	}
+	return n.storage.SetValue(bucketName, configKey, sealed)
+}
+
+func (n *Notifier) encrypt(secret string) (string, error) {
+	if n.cipher == nil || secret == "" {
+		return secret, nil
+	}
+
+	data, err := n.cipher.Encrypt([]byte(secret))
+	if err != nil {
+		return "", fmt.Errorf("could not encrypt notification secret: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+func (n *Notifier) decrypt(secret string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(secret)
+	if n.cipher == nil || err != nil || !encryption.IsEncrypted(data) {
+		return secret, nil
+	}
+
+	data, err = n.cipher.Decrypt(data)
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt notification secret: %w", err)
+	}
+	return string(data), nil
+}
+
+func (n *Notifier) apply(config Config) {
+	templates := template.New("")
+	for kind, text := range defaultTemplates {
+		if custom, ok := config.Templates[kind]; ok {
+			text = custom
+		}
+		template.Must(templates.New(kind).Parse(text))
+	}
+
+	n.config = config
+	n.templates = templates
+	n.channels = n.newChannels(config)
+	n.limiters = make(map[string]*rate.Limiter, len(n.channels))
+	for _, ch := range n.channels {
+		n.limiters[ch.Name()] = rate.NewLimiter(rate.Every(time.Hour/time.Duration(config.MaxPerHour)), config.MaxPerHour)
+	}
+}
+
+// Subscribe subscribes to settlement and service health events.
+func (n *Notifier) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.SubscribeAsync(pingpongEvent.AppTopicSettlementComplete, n.handleSettlementComplete); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(servicestate.AppTopicServiceHealth, n.handleServiceHealth)
+}
+
+func (n *Notifier) handleSettlementComplete(e pingpongEvent.AppEventSettlementComplete) {
+	if err := n.send(KindSettlementCompleted, e, true); err != nil && !errors.Is(err, ErrNoChannels) {
+		log.Warn().Err(err).Msg("Could not send settlement notification")
+	}
+}
+
+func (n *Notifier) handleServiceHealth(e servicestate.AppEventServiceHealth) {
+	if e.Health != service.HealthUnhealthy {
+		return
+	}
+	if err := n.send(KindServiceDown, e, true); err != nil && !errors.Is(err, ErrNoChannels) {
+		log.Warn().Err(err).Msg("Could not send service down notification")
+	}
+}
+
+// Notify sends notification about the alert, it implements alerting.Sink.
+func (n *Notifier) Notify(alert alerting.Alert) error {
+	err := n.send(KindAlert, alert, true)
+	if errors.Is(err, ErrNoChannels) {
+		return nil
+	}
+	return err
+}
+
+// Test sends a test notification to all configured channels ignoring rate limits.
+func (n *Notifier) Test() error {
+	return n.send(KindTest, nil, false)
+}
+
+func (n *Notifier) send(kind string, data interface{}, limited bool) error {
+	n.mu.Lock()
+	channels := n.channels
+	limiters := n.limiters
+	msg, err := n.render(kind, data)
+	n.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if len(channels) == 0 {
+		return ErrNoChannels
+	}
+
+	errs := utils.ErrorCollection{}
+	for _, ch := range channels {
+		if limited && !limiters[ch.Name()].Allow() {
+			log.Warn().Msgf("Notification rate limit reached, dropping %s notification to %s", kind, ch.Name())
+			continue
+		}
+		if err := ch.Send(msg); err != nil {
+			errs.Add(fmt.Errorf("%s: %w", ch.Name(), err))
+		}
+	}
+	return errs.Errorf("could not send notification: %s", ", ")
+}
+
+func (n *Notifier) render(kind string, data interface{}) (Message, error) {
+	var text strings.Builder
+	if err := n.templates.ExecuteTemplate(&text, kind, data); err != nil {
+		return Message{}, fmt.Errorf("could not render %s notification: %w", kind, err)
+	}
+
+	subject, body, _ := strings.Cut(strings.TrimSpace(text.String()), "\n")
+	return Message{Subject: strings.TrimSpace(subject), Body: strings.TrimSpace(body)}, nil
+}
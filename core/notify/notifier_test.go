@@ -0,0 +1,150 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package notify
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/alerting"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage/encryption"
+)
+
+type mockChannel struct {
+	name     string
+	messages []Message
+	err      error
+}
+
+func (m *mockChannel) Name() string {
+	return m.name
+}
+
+func (m *mockChannel) Send(msg Message) error {
+	m.messages = append(m.messages, msg)
+	return m.err
+}
+
+func newTestNotifier(t *testing.T, ch *mockChannel) (*Notifier, *boltdb.Bolt) {
+	bolt, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { bolt.Close() })
+
+	n := NewNotifier(bolt, nil, nil)
+	n.newChannels = func(config Config) []Channel {
+		if config.Telegram == nil {
+			return nil
+		}
+		return []Channel{ch}
+	}
+	return n, bolt
+}
+
+func TestNotifier_PersistsConfig(t *testing.T) {
+	n, bolt := newTestNotifier(t, &mockChannel{})
+	assert.Equal(t, Config{MaxPerHour: defaultMaxPerHour}, n.Config())
+
+	err := n.SetConfig(Config{Email: &EmailConfig{Host: "smtp.example.com", From: "node", To: []string{"me@example.com"}}})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	err = n.SetConfig(Config{Templates: map[string]string{KindAlert: "{{.Kind"}})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	config := Config{
+		Email:    &EmailConfig{Host: "smtp.example.com", From: "node@example.com", To: []string{"me@example.com"}},
+		Telegram: &TelegramConfig{BotToken: "token", ChatID: "42"},
+	}
+	require.NoError(t, n.SetConfig(config))
+
+	assert.Equal(t, Config{
+		Email:      &EmailConfig{Host: "smtp.example.com", Port: 587, From: "node@example.com", To: []string{"me@example.com"}},
+		Telegram:   &TelegramConfig{BotToken: "token", ChatID: "42"},
+		MaxPerHour: defaultMaxPerHour,
+	}, NewNotifier(bolt, nil, nil).Config())
+}
+
+func TestNotifier_EncryptsSecrets(t *testing.T) {
+	bolt, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { bolt.Close() })
+	cipher, err := encryption.NewCipher(make([]byte, encryption.KeySize))
+	require.NoError(t, err)
+
+	// Secrets stored in plaintext are encrypted when loaded.
+	config := Config{Telegram: &TelegramConfig{BotToken: "token", ChatID: "42"}, MaxPerHour: defaultMaxPerHour}
+	require.NoError(t, bolt.SetValue(bucketName, configKey, config))
+	assert.Equal(t, config, NewNotifier(bolt, nil, cipher).Config())
+
+	var stored Config
+	require.NoError(t, bolt.GetValue(bucketName, configKey, &stored))
+	assert.NotEqual(t, "token", stored.Telegram.BotToken)
+
+	n := NewNotifier(bolt, nil, cipher)
+	assert.Equal(t, config, n.Config())
+
+	require.NoError(t, n.SetConfig(Config{Email: &EmailConfig{Host: "smtp.example.com", Password: "pass", From: "node@example.com", To: []string{"me@example.com"}}}))
+	require.NoError(t, bolt.GetValue(bucketName, configKey, &stored))
+	assert.NotEqual(t, "pass", stored.Email.Password)
+	assert.Equal(t, "pass", NewNotifier(bolt, nil, cipher).Config().Email.Password)
+}
+
+func TestNotifier_NotifiesAlerts(t *testing.T) {
+	ch := &mockChannel{name: "telegram"}
+	n, _ := newTestNotifier(t, ch)
+	assert.NoError(t, n.Notify(alerting.Alert{Kind: alerting.KindBalanceBelow}))
+	assert.ErrorIs(t, n.Test(), ErrNoChannels)
+
+	require.NoError(t, n.SetConfig(Config{
+		Telegram:  &TelegramConfig{BotToken: "token", ChatID: "42"},
+		Templates: map[string]string{KindServiceDown: "{{.Type}} is down\n{{.Error}}"},
+	}))
+
+	fired := time.Date(2022, 3, 4, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, n.Notify(alerting.Alert{Kind: alerting.KindBalanceBelow, Severity: alerting.SeverityWarning, Message: "Balance is low", FiredAt: fired}))
+	require.NoError(t, n.Notify(alerting.Alert{Kind: alerting.KindBalanceBelow, Severity: alerting.SeverityWarning, Message: "Balance is low", FiredAt: fired, ResolvedAt: fired.Add(time.Hour)}))
+	n.handleServiceHealth(servicestate.AppEventServiceHealth{ID: "1", Type: "wireguard", Health: "healthy"})
+	n.handleServiceHealth(servicestate.AppEventServiceHealth{ID: "1", Type: "wireguard", Health: "unhealthy", Error: "no handshake"})
+
+	assert.Equal(t, []Message{
+		{Subject: "Alert: balance_below", Body: "Balance is low\nSeverity: warning, fired at 2022-03-04 10:00 UTC"},
+		{Subject: "Resolved: balance_below", Body: "Balance is low\nSeverity: warning, fired at 2022-03-04 10:00 UTC"},
+		{Subject: "wireguard is down", Body: "no handshake"},
+	}, ch.messages)
+
+	ch.err = errors.New("chat not found")
+	assert.EqualError(t, n.Test(), "could not send notification: telegram: chat not found")
+}
+
+func TestNotifier_RateLimits(t *testing.T) {
+	ch := &mockChannel{name: "telegram"}
+	n, _ := newTestNotifier(t, ch)
+	require.NoError(t, n.SetConfig(Config{Telegram: &TelegramConfig{BotToken: "token", ChatID: "42"}, MaxPerHour: 2}))
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, n.Notify(alerting.Alert{Kind: alerting.KindMonitoringFailed}))
+	}
+	assert.Len(t, ch.messages, 2)
+
+	require.NoError(t, n.Test())
+	assert.Len(t, ch.messages, 3)
+}
@@ -0,0 +1,583 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/time/rate"
+
+	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/core/privacy"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/logconfig/httptrace"
+	"github.com/mysteriumnetwork/node/requests"
+)
+
+const (
+	blocklistAuditBucket = "blocklist-audit"
+	blocklistStateBucket = "blocklist-state"
+	feedKey              = "feed"
+)
+
+const (
+	// maxAuditEntries bounds the audit log, the oldest attempts are removed first.
+	maxAuditEntries = 10000
+	// auditTrimEvery is how often, in recorded attempts, the audit log is trimmed.
+	auditTrimEvery = 100
+	// auditRepeatInterval is how often the same consumer blocked by the same rule is recorded.
+	auditRepeatInterval = time.Minute
+	// auditRate and auditBurst bound recorded attempts of all consumers,
+	// so that a blocked consumer rotating identities can't flood the storage.
+	auditRate  = rate.Limit(1)
+	auditBurst = 60
+	// feedMaxAge is how old a signed feed may be, older ones are considered replayed.
+	feedMaxAge = 7 * 24 * time.Hour
+)
+
+const (
+	// BlockSourceLocal marks consumers blocked by the local block list.
+	BlockSourceLocal = "local"
+	// BlockSourceFeed marks consumers blocked by the abuse blocklist feed.
+	BlockSourceFeed = "feed"
+	// BlockSourceNetwork marks consumers not allowed in the private network.
+	BlockSourceNetwork = "network"
+)
+
+// ErrConsumerBlocked is returned when consumer identity or IP is blocklisted.
+var ErrConsumerBlocked = errors.New("consumer is blocklisted")
+
+// BlocklistFeed is the signed envelope served by abuse blocklist feed.
+type BlocklistFeed struct {
+	// Payload is JSON encoded BlocklistEntries, signature covers its exact bytes.
+	Payload json.RawMessage `json:"payload"`
+	// Signature is hex encoded signature of the feed signer identity.
+	Signature string `json:"signature"`
+}
+
+// storedFeed is the last applied feed, it is verified and applied again on startup.
+type storedFeed struct {
+	Feed      BlocklistFeed
+	UpdatedAt time.Time
+}
+
+// BlocklistEntries lists consumer identities and IP ranges to block.
+type BlocklistEntries struct {
+	// Sequence increases with every published feed, feeds not newer than the applied one are ignored.
+	Sequence uint64 `json:"sequence"`
+	// IssuedAt is the time the feed was signed.
+	IssuedAt   time.Time `json:"issued_at"`
+	Identities []string  `json:"identities"`
+	IPRanges   []string  `json:"ip_ranges"`
+}
+
+// BlocklistConfig configures blocklist sources.
+type BlocklistConfig struct {
+	// FeedURL of signed abuse feed, empty disables the feed.
+	FeedURL string
+	// FeedSigner is the identity expected to sign the feed.
+	FeedSigner identity.Identity
+	// FetchInterval of the feed.
+	FetchInterval time.Duration
+	// Block lists identities, IPs, CIDR ranges, AS numbers or country codes always blocked.
+	Block []string
+	// Exempt lists identities, IPs, CIDR ranges, AS numbers or country codes never blocked.
+	Exempt []string
+	// Allow lists the only identities, IPs or CIDR ranges not blocked, empty allows everyone.
+	Allow []string
+}
+
+// BlocklistStatus describes the current state of blocklist.
+type BlocklistStatus struct {
+	FeedURL        string
+	FeedUpdatedAt  time.Time
+	FeedIdentities int
+	FeedIPRanges   int
+	FeedError      string
+	Block          []string
+	Exempt         []string
+}
+
+// BlockedAttempt is an audit log entry of a consumer rejected by blocklist.
+type BlockedAttempt struct {
+	ID         int `storm:"id,increment"`
+	ConsumerID string
+	IP         string
+	Source     string
+	Rule       string
+	At         time.Time
+}
+
+// Blocklist blocks consumers listed locally or by signed abuse feed, it keeps an audit log of blocked attempts.
+type Blocklist struct {
+	client  *requests.HTTPClient
+	config  BlocklistConfig
+	storage *boltdb.Bolt
+	geoIP   location.GeoIP
+	now     func() time.Time
+
+	lock   sync.RWMutex
+	block  matcher
+	exempt matcher
+	allow  matcher
+	feed   matcher
+	eTag   string
+	status BlocklistStatus
+	// sequence of the applied feed.
+	sequence uint64
+
+	auditLock     sync.Mutex
+	auditLimiter  *rate.Limiter
+	auditRecorded map[blockedKey]time.Time
+
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+}
+
+// NewBlocklist creates blocklist from the given config.
+// AS number and country rules are matched using the local GeoIP database, they never match if geoIP is nil.
+func NewBlocklist(client *requests.HTTPClient, config BlocklistConfig, storage *boltdb.Bolt, geoIP location.GeoIP) (*Blocklist, error) {
+	block, err := newMatcher(config.Block)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid block list")
+	}
+	exempt, err := newMatcher(config.Exempt)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid block exempt list")
+	}
+	allow, err := newMatcher(config.Allow)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid allow list")
+	}
+	if config.FeedURL != "" && config.FeedSigner.Address == "" {
+		return nil, errors.New("blocklist feed signer is required")
+	}
+
+	b := &Blocklist{
+		client:  client,
+		config:  config,
+		storage: storage,
+		geoIP:   geoIP,
+		now:     time.Now,
+		block:   block,
+		exempt:  exempt,
+		allow:   allow,
+		status: BlocklistStatus{
+			FeedURL: config.FeedURL,
+			Block:   config.Block,
+			Exempt:  config.Exempt,
+		},
+		auditLimiter:  rate.NewLimiter(auditRate, auditBurst),
+		auditRecorded: make(map[blockedKey]time.Time),
+		shutdown:      make(chan struct{}),
+	}
+
+	if err := b.restoreFeed(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// restoreFeed applies the feed stored by the previous run, so that the blocklist is in effect before the next fetch.
+func (b *Blocklist) restoreFeed() error {
+	if b.config.FeedURL == "" {
+		return nil
+	}
+
+	var stored storedFeed
+	err := b.storage.GetValue(blocklistStateBucket, feedKey, &stored)
+	if errors.Is(err, storm.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to load blocklist feed")
+	}
+
+	entries, err := stored.Feed.Verify(b.config.FeedSigner)
+	if err != nil {
+		log.Warn().Err(err).Msg("Ignoring stored blocklist feed")
+		return nil
+	}
+	matcher, err := newMatcher(append(entries.Identities, entries.IPRanges...))
+	if err != nil {
+		log.Warn().Err(err).Msg("Ignoring stored blocklist feed")
+		return nil
+	}
+
+	b.applyFeed(matcher, entries.Sequence, "", stored.UpdatedAt)
+	return nil
+}
+
+// Start begins fetching the blocklist feed, it blocks until stopped.
+func (b *Blocklist) Start() {
+	if b.config.FeedURL == "" {
+		return
+	}
+
+	for {
+		if err := b.fetch(); err != nil {
+			log.Warn().Err(err).Msg("Blocklist feed fetch failed")
+		}
+
+		select {
+		case <-b.shutdown:
+			return
+		case <-time.After(b.config.FetchInterval):
+		}
+	}
+}
+
+// Stop ends fetching the blocklist feed.
+func (b *Blocklist) Stop() {
+	b.shutdownOnce.Do(func() {
+		close(b.shutdown)
+	})
+}
+
+// Check returns ErrConsumerBlocked if the consumer identity or IP is blocklisted, blocked attempts are audited.
+func (b *Blocklist) Check(consumerID identity.Identity, ip net.IP) error {
+	c := b.resolve(consumerID, ip)
+
+	b.lock.RLock()
+	exempt := b.exempt.match(c) != ""
+	source, rule := "", ""
+	if !exempt {
+		if !b.allow.empty() && b.allow.match(c) == "" {
+			source, rule = BlockSourceNetwork, "allowlist"
+		} else if rule = b.block.match(c); rule != "" {
+			source = BlockSourceLocal
+		} else if rule = b.feed.match(c); rule != "" {
+			source = BlockSourceFeed
+		}
+	}
+	b.lock.RUnlock()
+
+	if source == "" {
+		return nil
+	}
+
+	attempt := BlockedAttempt{
+		ConsumerID: consumerID.Address,
+		Source:     source,
+		Rule:       rule,
+		At:         b.now().UTC(),
+	}
+	attempt.IP = privacy.ConsumerIP(ip)
+	if b.shouldRecord(attempt) {
+		if err := b.record(attempt); err != nil {
+			log.Warn().Err(err).Msg("Failed to record blocked attempt")
+		}
+	}
+
+	return fmt.Errorf("%w by %s rule %s", ErrConsumerBlocked, source, rule)
+}
+
+// resolve looks up the consumer country and AS number in the local GeoIP database.
+func (b *Blocklist) resolve(consumerID identity.Identity, ip net.IP) consumer {
+	c := consumer{id: consumerID, ip: ip}
+	if b.geoIP == nil || ip == nil {
+		return c
+	}
+
+	if country, err := b.geoIP.LookupCountry(ip.String()); err == nil {
+		c.country = country
+	}
+	if asn, err := b.geoIP.LookupASN(ip.String()); err == nil {
+		c.asn = asn.Number
+	}
+	return c
+}
+
+// Status returns the current state of blocklist.
+func (b *Blocklist) Status() BlocklistStatus {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	return b.status
+}
+
+// Audit returns the latest blocked attempts, the newest first.
+func (b *Blocklist) Audit(limit int) (result []BlockedAttempt, err error) {
+	b.storage.RLock()
+	defer b.storage.RUnlock()
+
+	err = b.storage.DB().From(blocklistAuditBucket).All(&result, storm.Limit(limit), storm.Reverse())
+	if errors.Is(err, storm.ErrNotFound) {
+		return []BlockedAttempt{}, nil
+	}
+	return result, err
+}
+
+// PruneBlocklistAudit removes blocked attempts recorded before the given time and returns their count.
+func PruneBlocklistAudit(storage *boltdb.Bolt, before time.Time) (int, error) {
+	return storage.DeleteBefore(blocklistAuditBucket, "At", before, &BlockedAttempt{})
+}
+
+// blockedKey identifies repeated attempts of the same consumer.
+type blockedKey struct {
+	consumerID string
+	ip         string
+	rule       string
+}
+
+// shouldRecord tells whether the attempt is worth recording: repeated attempts
+// are recorded once per interval and all attempts are rate limited.
+func (b *Blocklist) shouldRecord(attempt BlockedAttempt) bool {
+	b.auditLock.Lock()
+	defer b.auditLock.Unlock()
+
+	key := blockedKey{consumerID: attempt.ConsumerID, ip: attempt.IP, rule: attempt.Rule}
+	if at, ok := b.auditRecorded[key]; ok && attempt.At.Sub(at) < auditRepeatInterval {
+		return false
+	}
+	if !b.auditLimiter.AllowN(attempt.At, 1) {
+		return false
+	}
+
+	for k, at := range b.auditRecorded {
+		if attempt.At.Sub(at) >= auditRepeatInterval {
+			delete(b.auditRecorded, k)
+		}
+	}
+	b.auditRecorded[key] = attempt.At
+	return true
+}
+
+func (b *Blocklist) record(attempt BlockedAttempt) error {
+	b.storage.Lock()
+	defer b.storage.Unlock()
+
+	audit := b.storage.DB().From(blocklistAuditBucket)
+	if err := audit.Save(&attempt); err != nil {
+		return err
+	}
+
+	if attempt.ID <= maxAuditEntries || attempt.ID%auditTrimEvery != 0 {
+		return nil
+	}
+	err := audit.Select(q.Lte("ID", attempt.ID-maxAuditEntries)).Delete(&BlockedAttempt{})
+	if errors.Is(err, storm.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (b *Blocklist) fetch() error {
+	err := b.fetchFeed()
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if err != nil {
+		b.status.FeedError = err.Error()
+	} else {
+		b.status.FeedError = ""
+	}
+	return err
+}
+
+func (b *Blocklist) fetchFeed() error {
+	req, err := requests.NewGetRequest(b.config.FeedURL, "", nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create blocklist request")
+	}
+	req.Header.Add("If-None-Match", b.eTag)
+
+	res, err := b.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch blocklist")
+	}
+	defer res.Body.Close()
+
+	httptrace.TraceRequestResponse(req, res)
+
+	if res.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if err := requests.ParseResponseError(res); err != nil {
+		return errors.Wrap(err, "failed to fetch blocklist")
+	}
+
+	var feed BlocklistFeed
+	if err := requests.ParseResponseJSON(res, &feed); err != nil {
+		return errors.Wrap(err, "failed to parse blocklist")
+	}
+
+	entries, err := feed.Verify(b.config.FeedSigner)
+	if err != nil {
+		return err
+	}
+
+	b.lock.RLock()
+	sequence := b.sequence
+	b.lock.RUnlock()
+	if entries.Sequence == sequence && sequence != 0 {
+		return nil
+	}
+	if entries.Sequence < sequence {
+		return fmt.Errorf("blocklist feed sequence %d is older than applied %d", entries.Sequence, sequence)
+	}
+	if b.now().Sub(entries.IssuedAt) > feedMaxAge {
+		return fmt.Errorf("blocklist feed issued at %s is too old", entries.IssuedAt)
+	}
+
+	matcher, err := newMatcher(append(entries.Identities, entries.IPRanges...))
+	if err != nil {
+		return errors.Wrap(err, "invalid blocklist entry")
+	}
+
+	updatedAt := b.now().UTC()
+	if err := b.storage.SetValue(blocklistStateBucket, feedKey, storedFeed{Feed: feed, UpdatedAt: updatedAt}); err != nil {
+		return errors.Wrap(err, "failed to save blocklist feed")
+	}
+
+	b.applyFeed(matcher, entries.Sequence, res.Header.Get("ETag"), updatedAt)
+
+	log.Info().Msgf("Blocklist feed updated: %d identities, %d IP ranges", len(matcher.identities), len(matcher.networks))
+	return nil
+}
+
+func (b *Blocklist) applyFeed(matcher matcher, sequence uint64, eTag string, updatedAt time.Time) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.feed = matcher
+	b.sequence = sequence
+	b.eTag = eTag
+	b.status.FeedUpdatedAt = updatedAt
+	b.status.FeedIdentities = len(matcher.identities)
+	b.status.FeedIPRanges = len(matcher.networks)
+}
+
+// Verify checks the feed signature and returns its entries.
+func (f BlocklistFeed) Verify(signer identity.Identity) (BlocklistEntries, error) {
+	verifier := identity.NewVerifierIdentity(signer)
+	if ok, _ := verifier.Verify(f.Payload, identity.SignatureHex(f.Signature)); !ok {
+		return BlocklistEntries{}, errors.New("invalid blocklist signature")
+	}
+
+	var entries BlocklistEntries
+	if err := json.Unmarshal(f.Payload, &entries); err != nil {
+		return BlocklistEntries{}, errors.Wrap(err, "failed to parse blocklist payload")
+	}
+	return entries, nil
+}
+
+// consumer is matched against blocklist rules.
+type consumer struct {
+	id      identity.Identity
+	ip      net.IP
+	country string
+	asn     uint
+}
+
+// matcher matches consumers by identity, IP range, AS number or country.
+type matcher struct {
+	identities map[string]struct{}
+	networks   []*net.IPNet
+	asns       map[uint]struct{}
+	countries  map[string]struct{}
+}
+
+var (
+	asnPattern     = regexp.MustCompile(`^(?i)AS([0-9]+)$`)
+	countryPattern = regexp.MustCompile(`^[A-Za-z]{2}$`)
+)
+
+func newMatcher(values []string) (matcher, error) {
+	m := matcher{
+		identities: make(map[string]struct{}),
+		asns:       make(map[uint]struct{}),
+		countries:  make(map[string]struct{}),
+	}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		switch {
+		case value == "":
+			continue
+		case common.IsHexAddress(value):
+			m.identities[strings.ToLower(value)] = struct{}{}
+		case asnPattern.MatchString(value):
+			asn, err := strconv.ParseUint(asnPattern.FindStringSubmatch(value)[1], 10, 32)
+			if err != nil {
+				return matcher{}, err
+			}
+			m.asns[uint(asn)] = struct{}{}
+		case countryPattern.MatchString(value):
+			m.countries[strings.ToUpper(value)] = struct{}{}
+		case strings.Contains(value, "/"):
+			_, network, err := net.ParseCIDR(value)
+			if err != nil {
+				return matcher{}, err
+			}
+			m.networks = append(m.networks, network)
+		default:
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return matcher{}, fmt.Errorf("%q is neither identity, IP range, AS number nor country code", value)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			m.networks = append(m.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		}
+	}
+	return m, nil
+}
+
+// empty tells whether the matcher has no rules.
+func (m matcher) empty() bool {
+	return len(m.identities) == 0 && len(m.networks) == 0 && len(m.asns) == 0 && len(m.countries) == 0
+}
+
+// match returns the rule matching the consumer, empty if none.
+func (m matcher) match(c consumer) string {
+	address := strings.ToLower(c.id.Address)
+	if _, ok := m.identities[address]; ok {
+		return address
+	}
+	if c.ip == nil {
+		return ""
+	}
+	for _, network := range m.networks {
+		if network.Contains(c.ip) {
+			return network.String()
+		}
+	}
+	if _, ok := m.asns[c.asn]; ok && c.asn != 0 {
+		return fmt.Sprintf("AS%d", c.asn)
+	}
+	if _, ok := m.countries[c.country]; ok && c.country != "" {
+		return c.country
+	}
+	return ""
+}
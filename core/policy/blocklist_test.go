@@ -0,0 +1,331 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policy
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/core/privacy"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/requests"
+)
+
+var (
+	blockedConsumer = identity.FromAddress("0x000000000000000000000000000000000000000a")
+	exemptConsumer  = identity.FromAddress("0x000000000000000000000000000000000000000b")
+	otherConsumer   = identity.FromAddress("0x000000000000000000000000000000000000000c")
+)
+
+func TestBlocklist_LocalLists(t *testing.T) {
+	blocklist, err := NewBlocklist(nil, BlocklistConfig{
+		Block:  []string{blockedConsumer.Address, "10.0.0.0/8", "192.168.1.1"},
+		Exempt: []string{exemptConsumer.Address},
+	}, newTestBolt(t), nil)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, blocklist.Check(blockedConsumer, nil), ErrConsumerBlocked)
+	assert.ErrorIs(t, blocklist.Check(otherConsumer, net.ParseIP("10.1.2.3")), ErrConsumerBlocked)
+	assert.ErrorIs(t, blocklist.Check(otherConsumer, net.ParseIP("192.168.1.1")), ErrConsumerBlocked)
+	assert.NoError(t, blocklist.Check(otherConsumer, net.ParseIP("192.168.1.2")))
+	assert.NoError(t, blocklist.Check(exemptConsumer, net.ParseIP("10.1.2.3")))
+
+	audit, err := blocklist.Audit(10)
+	require.NoError(t, err)
+	require.Len(t, audit, 3)
+	assert.Equal(t, "192.168.1.1/32", audit[0].Rule)
+	assert.Equal(t, BlockSourceLocal, audit[0].Source)
+	assert.Equal(t, "10.1.2.0/24", audit[1].IP)
+	assert.Equal(t, blockedConsumer.Address, audit[2].ConsumerID)
+
+	audit, err = blocklist.Audit(1)
+	require.NoError(t, err)
+	assert.Len(t, audit, 1)
+}
+
+func TestBlocklist_AllowList(t *testing.T) {
+	blocklist, err := NewBlocklist(nil, BlocklistConfig{
+		Allow:  []string{otherConsumer.Address},
+		Exempt: []string{exemptConsumer.Address},
+	}, newTestBolt(t), nil)
+	require.NoError(t, err)
+
+	assert.NoError(t, blocklist.Check(otherConsumer, nil))
+	assert.NoError(t, blocklist.Check(exemptConsumer, nil))
+	assert.ErrorIs(t, blocklist.Check(blockedConsumer, nil), ErrConsumerBlocked)
+
+	audit, err := blocklist.Audit(10)
+	require.NoError(t, err)
+	require.Len(t, audit, 1)
+	assert.Equal(t, BlockSourceNetwork, audit[0].Source)
+	assert.Equal(t, blockedConsumer.Address, audit[0].ConsumerID)
+}
+
+func TestBlocklist_AuditConsumerIPPrivacy(t *testing.T) {
+	privacy.SetConsumerIPMode(privacy.IPNone)
+	defer privacy.SetConsumerIPMode(privacy.IPTruncated)
+
+	blocklist, err := NewBlocklist(nil, BlocklistConfig{Block: []string{"10.0.0.0/8"}}, newTestBolt(t), nil)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, blocklist.Check(otherConsumer, net.ParseIP("10.1.2.3")), ErrConsumerBlocked)
+
+	audit, err := blocklist.Audit(10)
+	require.NoError(t, err)
+	require.Len(t, audit, 1)
+	assert.Equal(t, "", audit[0].IP)
+}
+
+func TestBlocklist_GeoIPRules(t *testing.T) {
+	geoIP := &mockGeoIP{
+		countries: map[string]string{"203.0.113.1": "RU", "198.51.100.1": "LT"},
+		asns:      map[string]uint{"203.0.113.1": 64500, "198.51.100.1": 64501, "192.0.2.1": 64502},
+	}
+	blocklist, err := NewBlocklist(nil, BlocklistConfig{
+		Block:  []string{"as64501", "ru"},
+		Exempt: []string{"AS64500"},
+	}, newTestBolt(t), geoIP)
+	require.NoError(t, err)
+
+	assert.NoError(t, blocklist.Check(otherConsumer, net.ParseIP("203.0.113.1")))
+	assert.ErrorIs(t, blocklist.Check(otherConsumer, net.ParseIP("198.51.100.1")), ErrConsumerBlocked)
+	assert.NoError(t, blocklist.Check(otherConsumer, net.ParseIP("192.0.2.1")))
+	assert.NoError(t, blocklist.Check(otherConsumer, nil))
+
+	audit, err := blocklist.Audit(10)
+	require.NoError(t, err)
+	require.Len(t, audit, 1)
+	assert.Equal(t, "AS64501", audit[0].Rule)
+
+	blocklist, err = NewBlocklist(nil, BlocklistConfig{Block: []string{"RU"}}, newTestBolt(t), geoIP)
+	require.NoError(t, err)
+	assert.ErrorIs(t, blocklist.Check(otherConsumer, net.ParseIP("203.0.113.1")), ErrConsumerBlocked)
+}
+
+func TestBlocklist_GeoIPRulesWithoutDatabase(t *testing.T) {
+	blocklist, err := NewBlocklist(nil, BlocklistConfig{Block: []string{"AS64500", "RU"}}, newTestBolt(t), nil)
+	require.NoError(t, err)
+
+	assert.NoError(t, blocklist.Check(otherConsumer, net.ParseIP("203.0.113.1")))
+}
+
+type mockGeoIP struct {
+	countries map[string]string
+	asns      map[string]uint
+}
+
+func (m *mockGeoIP) LookupCountry(ip string) (string, error) {
+	if country, ok := m.countries[ip]; ok {
+		return country, nil
+	}
+	return "", errors.New("country not found")
+}
+
+func (m *mockGeoIP) LookupASN(ip string) (location.ASN, error) {
+	if asn, ok := m.asns[ip]; ok {
+		return location.ASN{Number: asn}, nil
+	}
+	return location.ASN{}, errors.New("ASN not found")
+}
+
+func TestBlocklist_InvalidEntry(t *testing.T) {
+	_, err := NewBlocklist(nil, BlocklistConfig{Block: []string{"not-an-entry"}}, newTestBolt(t), nil)
+	assert.Error(t, err)
+}
+
+func TestBlocklist_SignedFeed(t *testing.T) {
+	ks := identity.NewMockKeystore()
+	acc, err := ks.NewAccount("")
+	require.NoError(t, err)
+	require.NoError(t, ks.Unlock(acc, ""))
+	signer := identity.FromAddress(acc.Address.Hex())
+	feedSigner := identity.NewSigner(ks, signer)
+
+	feed := signFeed(t, feedSigner, BlocklistEntries{
+		Sequence:   1,
+		IssuedAt:   time.Now(),
+		Identities: []string{blockedConsumer.Address},
+		IPRanges:   []string{"172.16.0.0/12"},
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(feed)
+	}))
+	defer server.Close()
+
+	blocklist, err := NewBlocklist(requests.NewHTTPClient("0.0.0.0", time.Second), BlocklistConfig{
+		FeedURL:    server.URL,
+		FeedSigner: signer,
+		Exempt:     []string{"172.16.0.1"},
+	}, newTestBolt(t), nil)
+	require.NoError(t, err)
+
+	require.NoError(t, blocklist.fetch())
+	assert.ErrorIs(t, blocklist.Check(blockedConsumer, nil), ErrConsumerBlocked)
+	assert.ErrorIs(t, blocklist.Check(otherConsumer, net.ParseIP("172.16.5.5")), ErrConsumerBlocked)
+	assert.NoError(t, blocklist.Check(otherConsumer, net.ParseIP("172.16.0.1")))
+
+	status := blocklist.Status()
+	assert.Equal(t, 1, status.FeedIdentities)
+	assert.Equal(t, 1, status.FeedIPRanges)
+	assert.Empty(t, status.FeedError)
+
+	audit, err := blocklist.Audit(10)
+	require.NoError(t, err)
+	require.Len(t, audit, 2)
+	assert.Equal(t, BlockSourceFeed, audit[0].Source)
+
+	// Feed signed by another identity is rejected and the previous one stays in effect.
+	blocklist.config.FeedSigner = otherConsumer
+	assert.Error(t, blocklist.fetch())
+	assert.NotEmpty(t, blocklist.Status().FeedError)
+	assert.ErrorIs(t, blocklist.Check(blockedConsumer, nil), ErrConsumerBlocked)
+}
+
+func TestBlocklist_RejectsReplayedFeed(t *testing.T) {
+	ks := identity.NewMockKeystore()
+	acc, err := ks.NewAccount("")
+	require.NoError(t, err)
+	require.NoError(t, ks.Unlock(acc, ""))
+	signer := identity.FromAddress(acc.Address.Hex())
+	feedSigner := identity.NewSigner(ks, signer)
+
+	feed := signFeed(t, feedSigner, BlocklistEntries{Sequence: 2, IssuedAt: time.Now(), Identities: []string{blockedConsumer.Address}})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(feed)
+	}))
+	defer server.Close()
+
+	storage := newTestBolt(t)
+	config := BlocklistConfig{FeedURL: server.URL, FeedSigner: signer}
+	blocklist, err := NewBlocklist(requests.NewHTTPClient("0.0.0.0", time.Second), config, storage, nil)
+	require.NoError(t, err)
+	require.NoError(t, blocklist.fetch())
+	// The same feed served again is not an error.
+	require.NoError(t, blocklist.fetch())
+
+	// Older feed is rejected even after restart.
+	feed = signFeed(t, feedSigner, BlocklistEntries{Sequence: 1, IssuedAt: time.Now()})
+	blocklist, err = NewBlocklist(requests.NewHTTPClient("0.0.0.0", time.Second), config, storage, nil)
+	require.NoError(t, err)
+	assert.Error(t, blocklist.fetch())
+
+	// Stale feed is rejected.
+	feed = signFeed(t, feedSigner, BlocklistEntries{Sequence: 3, IssuedAt: time.Now().Add(-feedMaxAge - time.Hour)})
+	assert.Error(t, blocklist.fetch())
+
+	feed = signFeed(t, feedSigner, BlocklistEntries{Sequence: 3, IssuedAt: time.Now()})
+	assert.NoError(t, blocklist.fetch())
+	assert.NoError(t, blocklist.Check(blockedConsumer, nil))
+}
+
+func TestBlocklist_RestoresFeedAfterRestart(t *testing.T) {
+	ks := identity.NewMockKeystore()
+	acc, err := ks.NewAccount("")
+	require.NoError(t, err)
+	require.NoError(t, ks.Unlock(acc, ""))
+	signer := identity.FromAddress(acc.Address.Hex())
+	feedSigner := identity.NewSigner(ks, signer)
+
+	feed := signFeed(t, feedSigner, BlocklistEntries{Sequence: 1, IssuedAt: time.Now(), Identities: []string{blockedConsumer.Address}})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(feed)
+	}))
+	defer server.Close()
+
+	storage := newTestBolt(t)
+	config := BlocklistConfig{FeedURL: server.URL, FeedSigner: signer}
+	blocklist, err := NewBlocklist(requests.NewHTTPClient("0.0.0.0", time.Second), config, storage, nil)
+	require.NoError(t, err)
+	require.NoError(t, blocklist.fetch())
+
+	// The stored feed is in effect right after restart and stays so when the same sequence is served again.
+	blocklist, err = NewBlocklist(requests.NewHTTPClient("0.0.0.0", time.Second), config, storage, nil)
+	require.NoError(t, err)
+	assert.ErrorIs(t, blocklist.Check(blockedConsumer, nil), ErrConsumerBlocked)
+	assert.Equal(t, 1, blocklist.Status().FeedIdentities)
+
+	require.NoError(t, blocklist.fetch())
+	assert.ErrorIs(t, blocklist.Check(blockedConsumer, nil), ErrConsumerBlocked)
+
+	// The stored feed is not trusted once the signer changes.
+	config.FeedSigner = otherConsumer
+	blocklist, err = NewBlocklist(requests.NewHTTPClient("0.0.0.0", time.Second), config, storage, nil)
+	require.NoError(t, err)
+	assert.NoError(t, blocklist.Check(blockedConsumer, nil))
+}
+
+func TestBlocklist_AuditIsRateLimited(t *testing.T) {
+	blocklist, err := NewBlocklist(nil, BlocklistConfig{Block: []string{"10.0.0.0/8"}}, newTestBolt(t), nil)
+	require.NoError(t, err)
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	blocklist.now = func() time.Time { return now }
+
+	for i := 0; i < 10; i++ {
+		assert.ErrorIs(t, blocklist.Check(otherConsumer, net.ParseIP("10.1.2.3")), ErrConsumerBlocked)
+	}
+	audit, err := blocklist.Audit(100)
+	require.NoError(t, err)
+	assert.Len(t, audit, 1)
+
+	now = now.Add(auditRepeatInterval)
+	assert.ErrorIs(t, blocklist.Check(otherConsumer, net.ParseIP("10.1.2.3")), ErrConsumerBlocked)
+
+	// Consumers rotating identities are bounded by the global limit.
+	for i := 0; i < 2*auditBurst; i++ {
+		consumerID := identity.FromAddress(fmt.Sprintf("0x%040x", i+100))
+		assert.ErrorIs(t, blocklist.Check(consumerID, net.ParseIP("10.1.2.3")), ErrConsumerBlocked)
+	}
+	audit, err = blocklist.Audit(1000)
+	require.NoError(t, err)
+	// The first attempt plus the burst refilled during the repeat interval.
+	assert.Len(t, audit, 1+auditBurst)
+}
+
+func signFeed(t *testing.T, signer identity.Signer, entries BlocklistEntries) BlocklistFeed {
+	payload, err := json.Marshal(entries)
+	require.NoError(t, err)
+	signature, err := signer.Sign(payload)
+	require.NoError(t, err)
+	return BlocklistFeed{Payload: payload, Signature: hex.EncodeToString(signature.Bytes())}
+}
+
+func newTestBolt(t *testing.T) *boltdb.Bolt {
+	dir, err := os.MkdirTemp("", "blocklistTest")
+	require.NoError(t, err)
+
+	db, err := boltdb.NewStorage(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+		os.RemoveAll(dir)
+	})
+	return db
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/utils/ntputil"
+)
+
+// TUN checks that TUN devices can be created.
+func TUN() Check {
+	return Check{
+		Name:     "tun",
+		Critical: true,
+		Remedy:   "Load the tun kernel module (modprobe tun) or pass /dev/net/tun to the container (--device /dev/net/tun)",
+		Run:      checkTUN,
+	}
+}
+
+// Capabilities checks that node is able to manage network interfaces, routes and firewall.
+func Capabilities() Check {
+	return Check{
+		Name:     "capabilities",
+		Critical: true,
+		Remedy:   "Run the node as root, grant it NET_ADMIN capability (--cap-add NET_ADMIN) or allow passwordless sudo",
+		Run:      checkCapabilities,
+	}
+}
+
+// WireGuard checks that kernel WireGuard is available, userspace implementation is used otherwise.
+func WireGuard(kernelSupported func() bool) Check {
+	return Check{
+		Name:   "wireguard",
+		Remedy: "Install WireGuard kernel module for better performance, userspace implementation will be used meanwhile",
+		Run: func(ctx context.Context) error {
+			if !kernelSupported() {
+				return errors.New("kernel WireGuard is not supported")
+			}
+			return nil
+		},
+	}
+}
+
+type portSupplier interface {
+	Acquire() (port.Port, error)
+}
+
+// UDPReachability checks that UDP port from the provider port range is reachable from the Internet.
+func UDPReachability(ports portSupplier, echoServers []string) Check {
+	return Check{
+		Name:   "udp-reachability",
+		Remedy: "Forward the UDP port range to this host or enable UPnP on the router, otherwise consumers behind strict NATs will not be able to connect",
+		Run: func(ctx context.Context) error {
+			if len(echoServers) == 0 {
+				return ErrSkipped
+			}
+
+			p, err := ports.Acquire()
+			if err != nil {
+				return fmt.Errorf("could not acquire UDP port: %w", err)
+			}
+
+			timeout := 5 * time.Second
+			if deadline, ok := ctx.Deadline(); ok {
+				timeout = time.Until(deadline)
+			}
+			reachable, err := port.GloballyReachable(ctx, p, echoServers, timeout)
+			if err != nil {
+				return err
+			}
+			if !reachable {
+				return fmt.Errorf("UDP port %d is not reachable from the Internet", p.Num())
+			}
+			return nil
+		},
+	}
+}
+
+// Clock checks that system clock does not differ from NTP server time by more than maxSkew.
+func Clock(ntpServer string, maxSkew time.Duration) Check {
+	return Check{
+		Name:     "clock",
+		Critical: true,
+		Remedy:   "Enable time synchronization (e.g. timedatectl set-ntp true), payments and signatures are rejected when clock is off",
+		Run: func(ctx context.Context) error {
+			offset, err := ntputil.Offset(ctx, ntpServer)
+			if err != nil {
+				return ErrSkipped
+			}
+			if offset < 0 {
+				offset = -offset
+			}
+			if offset > maxSkew {
+				return fmt.Errorf("system clock is off by %s", offset.Round(time.Second))
+			}
+			return nil
+		},
+	}
+}
+
+// DiskSpace checks that the directory has at least minFree bytes available.
+func DiskSpace(dir string, minFree uint64) Check {
+	return Check{
+		Name:     "disk-space",
+		Critical: true,
+		Remedy:   "Free up disk space of the node data directory",
+		Run: func(ctx context.Context) error {
+			free, err := freeSpace(dir)
+			if err != nil {
+				return fmt.Errorf("could not get free space of %s: %w", dir, err)
+			}
+			if free < minFree {
+				return fmt.Errorf("only %d MiB available in %s, at least %d MiB required", free>>20, dir, minFree>>20)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,74 @@
+//go:build linux && !android
+
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package preflight
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// capNetAdmin is the bit of CAP_NET_ADMIN in capability sets.
+const capNetAdmin = 12
+
+func checkTUN(ctx context.Context) error {
+	f, err := os.OpenFile("/dev/net/tun", os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("could not open /dev/net/tun: %w", err)
+	}
+	return f.Close()
+}
+
+func checkCapabilities(ctx context.Context) error {
+	if os.Geteuid() == 0 {
+		return nil
+	}
+
+	caps, err := effectiveCapabilities()
+	if err == nil && caps&(1<<capNetAdmin) != 0 {
+		return nil
+	}
+
+	if err := exec.CommandContext(ctx, "sudo", "-n", "true").Run(); err != nil {
+		return errors.New("node has no NET_ADMIN capability and can't use sudo without password")
+	}
+	return nil
+}
+
+func effectiveCapabilities() (uint64, error) {
+	f, err := os.Open("/proc/self/status")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if value := strings.TrimPrefix(scanner.Text(), "CapEff:"); value != scanner.Text() {
+			return strconv.ParseUint(strings.TrimSpace(value), 16, 64)
+		}
+	}
+	return 0, errors.New("effective capabilities not found")
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Check statuses.
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// ErrSkipped is returned by checks which are not applicable in the current environment.
+var ErrSkipped = errors.New("check is not applicable")
+
+// Check verifies a single startup dependency.
+type Check struct {
+	Name string
+	// Critical checks prevent provider from serving consumers when they fail.
+	Critical bool
+	// Remedy tells operator how to fix the failure.
+	Remedy string
+	Run    func(ctx context.Context) error
+}
+
+// Result is an outcome of a single check.
+type Result struct {
+	Name     string
+	Critical bool
+	Status   string
+	Error    string
+	Remedy   string
+	Duration time.Duration
+}
+
+// Report holds results of all checks.
+type Report struct {
+	StartedAt time.Time
+	Results   []Result
+}
+
+// Passed returns true if none of the critical checks failed.
+func (r Report) Passed() bool {
+	for _, res := range r.Results {
+		if res.Critical && res.Status == StatusFailed {
+			return false
+		}
+	}
+	return true
+}
+
+// Degraded returns true if any of the non critical checks failed.
+func (r Report) Degraded() bool {
+	for _, res := range r.Results {
+		if !res.Critical && res.Status == StatusFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// Failures returns results of the failed checks.
+func (r Report) Failures() []Result {
+	var failed []Result
+	for _, res := range r.Results {
+		if res.Status == StatusFailed {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Checker runs startup dependency checks and keeps the last report.
+type Checker struct {
+	checks  []Check
+	timeout time.Duration
+	now     func() time.Time
+
+	mu     sync.Mutex
+	report Report
+}
+
+// NewChecker creates checker of the given checks, each check is limited by the timeout.
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{
+		checks:  checks,
+		timeout: timeout,
+		now:     time.Now,
+	}
+}
+
+// Run runs all checks concurrently and returns the report.
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Report{
+		StartedAt: c.now(),
+		Results:   make([]Result, len(c.checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i := range c.checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			report.Results[i] = c.run(ctx, c.checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	c.report = report
+	c.mu.Unlock()
+
+	return report
+}
+
+// Report returns the last report.
+func (c *Checker) Report() Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	started := c.now()
+	err := check.Run(ctx)
+
+	res := Result{
+		Name:     check.Name,
+		Critical: check.Critical,
+		Status:   StatusOK,
+		Duration: c.now().Sub(started),
+	}
+	switch {
+	case errors.Is(err, ErrSkipped):
+		res.Status = StatusSkipped
+	case err != nil:
+		res.Status = StatusFailed
+		res.Error = err.Error()
+		res.Remedy = check.Remedy
+	}
+	return res
+}
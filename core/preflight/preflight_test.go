@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package preflight
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker_Run(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	fail := func(ctx context.Context) error { return errors.New("boom") }
+	skip := func(ctx context.Context) error { return ErrSkipped }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	checker := NewChecker(50*time.Millisecond,
+		Check{Name: "ok", Critical: true, Run: ok},
+		Check{Name: "optional", Remedy: "fix it", Run: fail},
+		Check{Name: "skipped", Critical: true, Run: skip},
+	)
+	report := checker.Run(context.Background())
+	assert.True(t, report.Passed())
+	assert.True(t, report.Degraded())
+	require.Len(t, report.Results, 3)
+	assert.Equal(t, StatusOK, report.Results[0].Status)
+	assert.Equal(t, Result{Name: "optional", Status: StatusFailed, Error: "boom", Remedy: "fix it", Duration: report.Results[1].Duration}, report.Results[1])
+	assert.Equal(t, StatusSkipped, report.Results[2].Status)
+	assert.Equal(t, report, checker.Report())
+
+	report = NewChecker(50*time.Millisecond, Check{Name: "slow", Critical: true, Run: slow}).Run(context.Background())
+	assert.False(t, report.Passed())
+	assert.False(t, report.Degraded())
+	assert.Len(t, report.Failures(), 1)
+}
+
+func TestDiskSpace(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, DiskSpace(dir, 1).Run(context.Background()))
+	assert.Error(t, DiskSpace(dir, 1<<62).Run(context.Background()))
+}
+
+func TestClock(t *testing.T) {
+	skewed := func(skew time.Duration) string {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+
+		go func() {
+			req := make([]byte, 48)
+			_, addr, err := conn.ReadFromUDP(req)
+			if err != nil {
+				return
+			}
+			now := time.Now().Add(skew)
+			seconds := uint32(now.Unix() + 2208988800)
+			fraction := uint32((int64(now.Nanosecond()) << 32) / int64(time.Second))
+			res := make([]byte, 48)
+			res[0] = 0x24 // LI = 0, version = 4, mode = server.
+			res[1] = 2
+			copy(res[24:32], req[40:48])
+			for _, offset := range []int{32, 40} {
+				binary.BigEndian.PutUint32(res[offset:], seconds)
+				binary.BigEndian.PutUint32(res[offset+4:], fraction)
+			}
+			conn.WriteToUDP(res, addr)
+		}()
+		return conn.LocalAddr().String()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.NoError(t, Clock(skewed(0), time.Minute).Run(ctx))
+	assert.Error(t, Clock(skewed(-time.Hour), time.Minute).Run(ctx))
+}
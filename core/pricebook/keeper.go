@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pricebook
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/asdine/storm/v3"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+const (
+	bucketName = "price-book"
+	bookKey    = "book"
+)
+
+// Keeper keeps the provider price book and persists it across restarts.
+type Keeper struct {
+	storage storage.Storage
+	lock    sync.RWMutex
+	book    *market.PriceBook
+}
+
+// NewKeeper returns a new instance of price book keeper, loading the stored price book if there is one.
+func NewKeeper(storage storage.Storage) *Keeper {
+	k := &Keeper{storage: storage}
+
+	var book market.PriceBook
+	if err := storage.GetValue(bucketName, bookKey, &book); err == nil && len(book.Entries) > 0 {
+		k.book = &book
+	}
+	return k
+}
+
+// Get returns the current price book, empty if none is set.
+func (k *Keeper) Get() market.PriceBook {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	if k.book == nil {
+		return market.PriceBook{Entries: []market.PriceBookEntry{}}
+	}
+	return *k.book
+}
+
+// Set validates and stores the given price book, an empty book falls back to network prices.
+func (k *Keeper) Set(book market.PriceBook) error {
+	if err := book.Validate(); err != nil {
+		return err
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if len(book.Entries) == 0 {
+		if err := k.storage.DeleteKey(bucketName, bookKey); err != nil && !errors.Is(err, storm.ErrNotFound) {
+			return err
+		}
+		k.book = nil
+		return nil
+	}
+
+	if err := k.storage.SetValue(bucketName, bookKey, book); err != nil {
+		return err
+	}
+	k.book = &book
+	return nil
+}
+
+// PriceBook returns the part of the price book advertised in proposals of the given service type.
+func (k *Keeper) PriceBook(serviceType string) *market.PriceBook {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	return k.book.ForService(serviceType)
+}
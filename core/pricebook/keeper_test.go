@@ -0,0 +1,72 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pricebook
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+func TestKeeper_PersistsPriceBook(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pricebookTest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	require.NoError(t, err)
+	defer bolt.Close()
+
+	keeper := NewKeeper(bolt)
+	assert.Empty(t, keeper.Get().Entries)
+	assert.Nil(t, keeper.PriceBook("wireguard"))
+
+	book := market.PriceBook{Entries: []market.PriceBookEntry{
+		{ServiceType: "wireguard", Country: "DE", Price: *market.NewPrice(1, 2)},
+	}}
+	require.NoError(t, keeper.Set(book))
+
+	reloaded := NewKeeper(bolt)
+	assert.Equal(t, book, reloaded.Get())
+	assert.NotNil(t, reloaded.PriceBook("wireguard"))
+	assert.Nil(t, reloaded.PriceBook("scraping"))
+
+	require.NoError(t, reloaded.Set(market.PriceBook{}))
+	assert.Empty(t, NewKeeper(bolt).Get().Entries)
+}
+
+func TestKeeper_RejectsInvalidPriceBook(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pricebookTest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	require.NoError(t, err)
+	defer bolt.Close()
+
+	keeper := NewKeeper(bolt)
+	err = keeper.Set(market.PriceBook{Entries: []market.PriceBookEntry{{Country: "DE"}}})
+	assert.Error(t, err)
+	assert.Empty(t, keeper.Get().Entries)
+}
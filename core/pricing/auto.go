@@ -0,0 +1,271 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package pricing
+
+import (
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+const (
+	bucketName = "auto-prices"
+	pricesKey  = "prices"
+
+	// minSamples is the least number of comparable proposals the price is derived from.
+	minSamples = 5
+)
+
+// AutoPricingConfig configures automatic pricing.
+type AutoPricingConfig struct {
+	// ServiceTypes are the services priced automatically.
+	ServiceTypes []string
+	// Percentile of comparable proposal prices the price moves toward, 0-100.
+	Percentile float64
+	// Interval is the least time between price updates.
+	Interval time.Duration
+	// MaxChange is the largest relative change of the price in a single update, 0 for unlimited.
+	MaxChange float64
+	// Min and Max bound the price, nil fields are unbounded.
+	Min, Max market.Price
+}
+
+type proposalSource interface {
+	Proposals(filter *proposal.Filter) ([]proposal.PricedServiceProposal, error)
+}
+
+type originProvider interface {
+	GetOrigin() locationstate.Location
+}
+
+type networkPrices interface {
+	GetCurrentPrice(nodeType string, country string, serviceType string) (market.Price, error)
+}
+
+type storage interface {
+	GetValue(bucket string, key interface{}, to interface{}) error
+	SetValue(bucket string, key interface{}, to interface{}) error
+}
+
+// AutoPricer periodically samples prices of comparable proposals from discovery
+// and moves the provider price toward the configured percentile of them.
+// Comparable proposals are of the same service type from the same country and IP type.
+type AutoPricer struct {
+	config    AutoPricingConfig
+	proposals proposalSource
+	origin    originProvider
+	network   networkPrices
+	storage   storage
+	now       func() time.Time
+
+	lock      sync.Mutex
+	prices    map[string]market.Price
+	updatedAt time.Time
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+// NewAutoPricer returns a new instance of automatic pricer.
+func NewAutoPricer(config AutoPricingConfig, proposals proposalSource, origin originProvider, network networkPrices, storage storage) *AutoPricer {
+	return &AutoPricer{
+		config:    config,
+		proposals: proposals,
+		origin:    origin,
+		network:   network,
+		storage:   storage,
+		now:       time.Now,
+		prices:    make(map[string]market.Price),
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start loads the prices set before restart and starts updating them periodically.
+func (a *AutoPricer) Start() {
+	a.lock.Lock()
+	var stored map[string]market.Price
+	if err := a.storage.GetValue(bucketName, pricesKey, &stored); err == nil {
+		for serviceType, price := range stored {
+			a.prices[serviceType] = price
+		}
+	}
+	a.lock.Unlock()
+
+	go func() {
+		a.Update()
+
+		ticker := time.NewTicker(a.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-a.stop:
+				return
+			case <-ticker.C:
+				a.Update()
+			}
+		}
+	}()
+}
+
+// Stop stops updating prices.
+func (a *AutoPricer) Stop() {
+	a.stopOnce.Do(func() {
+		close(a.stop)
+	})
+}
+
+// Price returns the automatic price of the service, nil if it is not priced automatically.
+func (a *AutoPricer) Price(serviceType string) *market.Price {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	price, ok := a.prices[serviceType]
+	if !ok {
+		return nil
+	}
+	return &price
+}
+
+// Update moves prices of the configured services toward the market, at most once per interval.
+func (a *AutoPricer) Update() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !a.updatedAt.IsZero() && a.now().Sub(a.updatedAt) < a.config.Interval {
+		return
+	}
+
+	origin := a.origin.GetOrigin()
+	if origin.Country == "" {
+		log.Debug().Msg("Location is not detected yet, skipping auto pricing")
+		return
+	}
+	a.updatedAt = a.now()
+
+	changed := false
+	for _, serviceType := range a.config.ServiceTypes {
+		current, ok := a.prices[serviceType]
+		if !ok {
+			network, err := a.network.GetCurrentPrice(origin.IPType, origin.Country, serviceType)
+			if err != nil {
+				log.Warn().Err(err).Msgf("Could not get network price of %s, skipping auto pricing", serviceType)
+				continue
+			}
+			current = network
+		}
+
+		price, ok := a.price(origin, serviceType, current)
+		if !ok {
+			continue
+		}
+
+		log.Info().Msgf("Auto pricing %s at %s", serviceType, price)
+		a.prices[serviceType] = price
+		changed = true
+	}
+
+	if !changed {
+		return
+	}
+	if err := a.storage.SetValue(bucketName, pricesKey, a.prices); err != nil {
+		log.Error().Err(err).Msg("Could not store auto prices")
+	}
+}
+
+func (a *AutoPricer) price(origin locationstate.Location, serviceType string, current market.Price) (market.Price, bool) {
+	proposals, err := a.proposals.Proposals(&proposal.Filter{
+		ServiceType:     serviceType,
+		LocationCountry: origin.Country,
+		IPType:          origin.IPType,
+	})
+	if err != nil {
+		log.Warn().Err(err).Msgf("Could not sample %s proposals for auto pricing", serviceType)
+		return market.Price{}, false
+	}
+	if len(proposals) < minSamples {
+		log.Debug().Msgf("Too few comparable %s proposals for auto pricing: %d", serviceType, len(proposals))
+		return market.Price{}, false
+	}
+
+	perHour := make([]*big.Int, 0, len(proposals))
+	perGiB := make([]*big.Int, 0, len(proposals))
+	for _, p := range proposals {
+		perHour = append(perHour, p.Price.PricePerHour)
+		perGiB = append(perGiB, p.Price.PricePerGiB)
+	}
+
+	return market.Price{
+		PricePerHour: a.adjust(current.PricePerHour, percentile(perHour, a.config.Percentile), a.config.Min.PricePerHour, a.config.Max.PricePerHour),
+		PricePerGiB:  a.adjust(current.PricePerGiB, percentile(perGiB, a.config.Percentile), a.config.Min.PricePerGiB, a.config.Max.PricePerGiB),
+	}, true
+}
+
+// adjust moves the current price toward the target limiting the change, then clamps it to the bounds.
+func (a *AutoPricer) adjust(current, target, min, max *big.Int) *big.Int {
+	res := new(big.Int).Set(target)
+
+	if a.config.MaxChange > 0 && current != nil && current.Sign() > 0 {
+		step, _ := new(big.Float).Mul(new(big.Float).SetInt(current), big.NewFloat(a.config.MaxChange)).Int(nil)
+		if low := new(big.Int).Sub(current, step); res.Cmp(low) < 0 {
+			res = low
+		}
+		if high := new(big.Int).Add(current, step); res.Cmp(high) > 0 {
+			res = high
+		}
+	}
+
+	if min != nil && res.Cmp(min) < 0 {
+		res.Set(min)
+	}
+	if max != nil && res.Cmp(max) > 0 {
+		res.Set(max)
+	}
+	return res
+}
+
+// percentile returns the nearest rank percentile of the values.
+func percentile(values []*big.Int, p float64) *big.Int {
+	sorted := make([]*big.Int, 0, len(values))
+	for _, v := range values {
+		if v != nil {
+			sorted = append(sorted, v)
+		}
+	}
+	if len(sorted) == 0 {
+		return new(big.Int)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Cmp(sorted[j]) < 0
+	})
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return new(big.Int).Set(sorted[rank-1])
+}
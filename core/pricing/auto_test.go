@@ -0,0 +1,175 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package pricing
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+func Test_percentile(t *testing.T) {
+	values := []*big.Int{big.NewInt(50), big.NewInt(10), big.NewInt(40), big.NewInt(20), big.NewInt(30)}
+
+	assert.Equal(t, big.NewInt(10), percentile(values, 0))
+	assert.Equal(t, big.NewInt(10), percentile(values, 20))
+	assert.Equal(t, big.NewInt(30), percentile(values, 50))
+	assert.Equal(t, big.NewInt(40), percentile(values, 75))
+	assert.Equal(t, big.NewInt(50), percentile(values, 100))
+	assert.Equal(t, big.NewInt(0), percentile(nil, 50))
+}
+
+func TestAutoPricer_MovesTowardPercentile(t *testing.T) {
+	pricer, storage, _ := newTestAutoPricer(AutoPricingConfig{Percentile: 50})
+
+	pricer.Update()
+
+	assert.Equal(t, market.NewPrice(300, 3000), pricer.Price("wireguard"))
+	assert.Nil(t, pricer.Price("scraping"))
+	assert.Equal(t, map[string]market.Price{"wireguard": *market.NewPrice(300, 3000)}, storage.prices)
+}
+
+func TestAutoPricer_ClampsToBounds(t *testing.T) {
+	pricer, _, _ := newTestAutoPricer(AutoPricingConfig{
+		Percentile: 100,
+		Min:        market.Price{PricePerGiB: big.NewInt(4000)},
+		Max:        market.Price{PricePerHour: big.NewInt(350)},
+	})
+
+	pricer.Update()
+	assert.Equal(t, market.NewPrice(350, 5000), pricer.Price("wireguard"))
+
+	pricer, _, _ = newTestAutoPricer(AutoPricingConfig{
+		Percentile: 0,
+		Min:        market.Price{PricePerHour: big.NewInt(150), PricePerGiB: big.NewInt(1500)},
+	})
+
+	pricer.Update()
+	assert.Equal(t, market.NewPrice(150, 1500), pricer.Price("wireguard"))
+}
+
+func TestAutoPricer_LimitsRate(t *testing.T) {
+	pricer, _, now := newTestAutoPricer(AutoPricingConfig{Percentile: 100, MaxChange: 0.1})
+
+	// starts from the network price of 200/h, 2000/GiB
+	pricer.Update()
+	assert.Equal(t, market.NewPrice(220, 2200), pricer.Price("wireguard"))
+
+	// no update before the interval passes
+	pricer.Update()
+	assert.Equal(t, market.NewPrice(220, 2200), pricer.Price("wireguard"))
+
+	*now = now.Add(time.Hour)
+	pricer.Update()
+	assert.Equal(t, market.NewPrice(242, 2420), pricer.Price("wireguard"))
+}
+
+func TestAutoPricer_SkipsThinMarket(t *testing.T) {
+	pricer, _, _ := newTestAutoPricer(AutoPricingConfig{Percentile: 50})
+	pricer.proposals = &mockProposals{proposals: pricedProposals(100, 200)}
+
+	pricer.Update()
+
+	assert.Nil(t, pricer.Price("wireguard"))
+}
+
+func TestAutoPricer_ContinuesFromStoredPrice(t *testing.T) {
+	pricer, storage, _ := newTestAutoPricer(AutoPricingConfig{Percentile: 100, MaxChange: 0.1})
+	storage.prices = map[string]market.Price{"wireguard": *market.NewPrice(400, 4000)}
+
+	pricer.Start()
+	defer pricer.Stop()
+
+	assert.Eventually(t, func() bool {
+		price := pricer.Price("wireguard")
+		return price != nil && price.PricePerHour.Cmp(big.NewInt(440)) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func newTestAutoPricer(config AutoPricingConfig) (*AutoPricer, *mockStorage, *time.Time) {
+	config.ServiceTypes = []string{"wireguard"}
+	config.Interval = time.Hour
+
+	storage := &mockStorage{}
+	proposals := &mockProposals{proposals: pricedProposals(500, 100, 400, 200, 300)}
+
+	pricer := NewAutoPricer(config, proposals, &mockOrigin{}, &mockNetworkPrices{price: *market.NewPrice(200, 2000)}, storage)
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	pricer.now = func() time.Time { return now }
+	return pricer, storage, &now
+}
+
+func pricedProposals(perHour ...int64) []proposal.PricedServiceProposal {
+	res := make([]proposal.PricedServiceProposal, 0, len(perHour))
+	for _, p := range perHour {
+		res = append(res, proposal.PricedServiceProposal{Price: *market.NewPrice(p, p*10)})
+	}
+	return res
+}
+
+type mockProposals struct {
+	proposals []proposal.PricedServiceProposal
+}
+
+func (m *mockProposals) Proposals(*proposal.Filter) ([]proposal.PricedServiceProposal, error) {
+	return m.proposals, nil
+}
+
+type mockOrigin struct{}
+
+func (m *mockOrigin) GetOrigin() locationstate.Location {
+	return locationstate.Location{Country: "DE", IPType: "residential"}
+}
+
+type mockNetworkPrices struct {
+	price market.Price
+}
+
+func (m *mockNetworkPrices) GetCurrentPrice(string, string, string) (market.Price, error) {
+	return m.price, nil
+}
+
+type mockStorage struct {
+	prices map[string]market.Price
+}
+
+func (m *mockStorage) GetValue(_ string, _ interface{}, to interface{}) error {
+	if m.prices == nil {
+		return errors.New("not found")
+	}
+	stored := to.(*map[string]market.Price)
+	*stored = make(map[string]market.Price)
+	for k, v := range m.prices {
+		(*stored)[k] = v
+	}
+	return nil
+}
+
+func (m *mockStorage) SetValue(_ string, _ interface{}, value interface{}) error {
+	m.prices = make(map[string]market.Price)
+	for k, v := range value.(map[string]market.Price) {
+		m.prices[k] = v
+	}
+	return nil
+}
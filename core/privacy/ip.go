@@ -0,0 +1,151 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package privacy
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sync/atomic"
+)
+
+// IPMode defines how provider records consumer IP addresses.
+type IPMode string
+
+const (
+	// IPFull records full consumer IP addresses.
+	IPFull = IPMode("full")
+	// IPTruncated records only the /24 (IPv4) or /48 (IPv6) network of consumer IP addresses.
+	IPTruncated = IPMode("truncated")
+	// IPHashed records keyed hashes of consumer IP addresses, the key changes on every node start.
+	IPHashed = IPMode("hashed")
+	// IPNone records no consumer IP addresses at all.
+	IPNone = IPMode("none")
+)
+
+const (
+	truncatedIPv4Bits = 24
+	truncatedIPv6Bits = 48
+	hashLength        = 16
+)
+
+// ParseIPMode parses IP privacy mode, empty string stands for IPTruncated.
+func ParseIPMode(mode string) (IPMode, error) {
+	switch IPMode(mode) {
+	case "":
+		return IPTruncated, nil
+	case IPFull, IPTruncated, IPHashed, IPNone:
+		return IPMode(mode), nil
+	default:
+		return "", fmt.Errorf("unknown consumer IP privacy mode: %q", mode)
+	}
+}
+
+// IPAnonymizer turns IP addresses into their representation allowed by the privacy mode.
+type IPAnonymizer struct {
+	mode IPMode
+	key  []byte
+}
+
+// NewIPAnonymizer creates IP anonymizer for the given mode.
+func NewIPAnonymizer(mode IPMode) *IPAnonymizer {
+	a := &IPAnonymizer{mode: mode}
+	if mode == IPHashed {
+		a.key = make([]byte, sha256.Size)
+		if _, err := rand.Read(a.key); err != nil {
+			// Falling back to recording nothing rather than a hash with a guessable key.
+			a.mode = IPNone
+		}
+	}
+	return a
+}
+
+// Mode returns the privacy mode applied.
+func (a *IPAnonymizer) Mode() IPMode {
+	return a.mode
+}
+
+// IP returns IP address as allowed by the privacy mode, empty string if nothing may be recorded.
+func (a *IPAnonymizer) IP(ip net.IP) string {
+	if ip == nil {
+		return ""
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	switch a.mode {
+	case IPFull:
+		return ip.String()
+	case IPTruncated:
+		bits := truncatedIPv6Bits
+		if len(ip) == net.IPv4len {
+			bits = truncatedIPv4Bits
+		}
+		network := net.IPNet{IP: ip.Mask(net.CIDRMask(bits, 8*len(ip))), Mask: net.CIDRMask(bits, 8*len(ip))}
+		return network.String()
+	case IPHashed:
+		mac := hmac.New(sha256.New, a.key)
+		mac.Write(ip)
+		return hex.EncodeToString(mac.Sum(nil))[:hashLength]
+	default:
+		return ""
+	}
+}
+
+// Addr returns IP or IP:port address as allowed by the privacy mode, the port is kept in full mode only.
+func (a *IPAnonymizer) Addr(addr string) string {
+	if a.mode == IPFull {
+		return addr
+	}
+
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+	return a.IP(net.ParseIP(host))
+}
+
+var consumerIPs atomic.Value
+
+func init() {
+	consumerIPs.Store(NewIPAnonymizer(IPTruncated))
+}
+
+// SetConsumerIPMode sets how consumer IP addresses are recorded in the blocklist audit and logs.
+func SetConsumerIPMode(mode IPMode) {
+	consumerIPs.Store(NewIPAnonymizer(mode))
+}
+
+// ConsumerIPMode returns the current consumer IP privacy mode.
+func ConsumerIPMode() IPMode {
+	return consumerIPs.Load().(*IPAnonymizer).Mode()
+}
+
+// ConsumerIP returns consumer IP address as allowed by the current privacy mode.
+func ConsumerIP(ip net.IP) string {
+	return consumerIPs.Load().(*IPAnonymizer).IP(ip)
+}
+
+// ConsumerAddr returns consumer IP or IP:port address as allowed by the current privacy mode.
+func ConsumerAddr(addr string) string {
+	return consumerIPs.Load().(*IPAnonymizer).Addr(addr)
+}
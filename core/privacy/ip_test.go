@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package privacy
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIPMode(t *testing.T) {
+	mode, err := ParseIPMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, IPTruncated, mode)
+
+	mode, err = ParseIPMode("hashed")
+	assert.NoError(t, err)
+	assert.Equal(t, IPHashed, mode)
+
+	_, err = ParseIPMode("masked")
+	assert.Error(t, err)
+}
+
+func TestIPAnonymizer(t *testing.T) {
+	ipv4 := net.ParseIP("203.0.113.57")
+	ipv6 := net.ParseIP("2001:db8:1:2::5")
+
+	full := NewIPAnonymizer(IPFull)
+	assert.Equal(t, "203.0.113.57", full.IP(ipv4))
+	assert.Equal(t, "203.0.113.57:4589", full.Addr("203.0.113.57:4589"))
+	assert.Equal(t, "", full.IP(nil))
+
+	truncated := NewIPAnonymizer(IPTruncated)
+	assert.Equal(t, "203.0.113.0/24", truncated.IP(ipv4))
+	assert.Equal(t, "2001:db8:1::/48", truncated.IP(ipv6))
+	assert.Equal(t, "203.0.113.0/24", truncated.Addr("203.0.113.57:4589"))
+
+	hashed := NewIPAnonymizer(IPHashed)
+	hash := hashed.IP(ipv4)
+	assert.Len(t, hash, hashLength)
+	assert.Equal(t, hash, hashed.Addr("203.0.113.57:4589"))
+	assert.NotEqual(t, hash, hashed.IP(net.ParseIP("203.0.113.58")))
+	assert.NotEqual(t, hash, NewIPAnonymizer(IPHashed).IP(ipv4), "hash key should differ between runs")
+
+	none := NewIPAnonymizer(IPNone)
+	assert.Equal(t, "", none.IP(ipv4))
+	assert.Equal(t, "", none.Addr("203.0.113.57:4589"))
+	assert.Equal(t, "", truncated.Addr("not-an-ip"))
+}
+
+func TestConsumerIP(t *testing.T) {
+	defer SetConsumerIPMode(IPTruncated)
+
+	assert.Equal(t, IPTruncated, ConsumerIPMode())
+	assert.Equal(t, "198.51.100.0/24", ConsumerIP(net.ParseIP("198.51.100.7")))
+	assert.Equal(t, "198.51.100.0/24", ConsumerAddr("198.51.100.7:1194"))
+
+	SetConsumerIPMode(IPFull)
+	assert.Equal(t, IPFull, ConsumerIPMode())
+	assert.Equal(t, "198.51.100.7", ConsumerIP(net.ParseIP("198.51.100.7")))
+}
@@ -36,6 +36,8 @@ const (
 	StageConnectionCanceled = "connection_canceled"
 	// StageConnectionAlreadyExists describes already exists connection event.
 	StageConnectionAlreadyExists = "connection_already_exists"
+	// StageConnectionVetoed describes connection vetoed by pre-connect hook event.
+	StageConnectionVetoed = "connection_vetoed"
 	// StageConnectionUnknownError describes unknown connection event.
 	StageConnectionUnknownError = "connection_unknown_error"
 
@@ -94,6 +96,14 @@ type PingEvent struct {
 	Duration  time.Duration `json:"duration"`
 }
 
+// SpeedtestEvent represents throughput measured by the speedtest service.
+type SpeedtestEvent struct {
+	SessionID string `json:"session_id"`
+	// Download and Upload throughput in bits per second.
+	Download uint64 `json:"download"`
+	Upload   uint64 `json:"upload"`
+}
+
 const (
 	// AppTopicConnectionEvents represents event bus topic for the connection events.
 	AppTopicConnectionEvents = "connection_events"
@@ -103,4 +113,7 @@ const (
 
 	// AppTopicProviderPingP2P represents event bus topic for provider p2p pings to consumer.
 	AppTopicProviderPingP2P = "provider_ping_p2p"
+
+	// AppTopicSpeedtestResult represents event bus topic for speedtest service measurements.
+	AppTopicSpeedtestResult = "speedtest_result"
 )
@@ -60,6 +60,7 @@ const (
 	stunDetectionEvent       = "stun_detection_event"
 	natTypeDetectionEvent    = "nat_type_detection_event"
 	natTraversalMethod       = "nat_traversal_method"
+	speedtestEventName       = "speedtest_result"
 )
 
 // Transport allows sending events
@@ -112,6 +113,12 @@ type pingEventContext struct {
 	sessionContext
 }
 
+type speedtestEventContext struct {
+	Download uint64
+	Upload   uint64
+	sessionContext
+}
+
 type natMappingContext struct {
 	ID           string              `json:"id"`
 	Stage        string              `json:"stage"`
@@ -191,6 +198,7 @@ func (s *Sender) Subscribe(bus eventbus.Subscriber) error {
 		sessionEvent.AppTopicDataTransferred:         s.sendServiceDataStatistics,
 		AppTopicConsumerPingP2P:                      s.sendConsumerPingDistance,
 		AppTopicProviderPingP2P:                      s.sendProviderPingDistance,
+		AppTopicSpeedtestResult:                      s.sendSpeedtestResult,
 		identity.AppTopicResidentCountry:             s.sendResidentCountry,
 		p2p.AppTopicSTUN:                             s.sendSTUNDetectionStatus,
 		behavior.AppTopicNATTypeDetected:             s.sendNATType,
@@ -262,6 +270,20 @@ func (s *Sender) sendPingDistance(isProvider bool, p PingEvent) {
 	})
 }
 
+func (s *Sender) sendSpeedtestResult(e SpeedtestEvent) {
+	session, err := s.recoverSessionContext(e.SessionID)
+	if err != nil {
+		log.Warn().Err(err).Msg("Can't recover session context")
+		return
+	}
+
+	s.sendEvent(speedtestEventName, speedtestEventContext{
+		Download:       e.Download,
+		Upload:         e.Upload,
+		sessionContext: session,
+	})
+}
+
 func (s *Sender) sendConnectionEvent(e ConnectionEvent) {
 	s.sendEvent(connectionEvent, e)
 }
@@ -0,0 +1,343 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package abuse
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	sevent "github.com/mysteriumnetwork/node/session/event"
+)
+
+const (
+	// FlagConnectionChurn is raised when a consumer creates too many sessions in a short time.
+	FlagConnectionChurn = "connection_churn"
+	// FlagZeroPaymentStreak is raised when a consumer ends too many sessions in a row without paying.
+	FlagZeroPaymentStreak = "zero_payment_streak"
+	// FlagPortScan is raised when a consumer reaches too many distinct destination ports in a short time.
+	FlagPortScan = "port_scan"
+)
+
+// Config holds thresholds of abuse heuristics.
+type Config struct {
+	// Window is the period connection churn and egress patterns are evaluated over.
+	Window time.Duration
+	// ChurnThreshold is the number of sessions per window which flags the consumer.
+	ChurnThreshold int
+	// ZeroPaymentThreshold is the number of consecutive unpaid sessions which flags the consumer.
+	ZeroPaymentThreshold int
+	// PortScanThreshold is the number of distinct destination ports per window which flags the consumer.
+	PortScanThreshold int
+	// BlockScore is the score at which the consumer gets blocked, zero disables blocking.
+	BlockScore float64
+	// BlockDuration is how long the consumer stays blocked.
+	BlockDuration time.Duration
+	// Retention is how long stats of a consumer without active sessions are kept.
+	Retention time.Duration
+}
+
+// DefaultConfig returns default abuse detection thresholds with auto-block disabled.
+func DefaultConfig() Config {
+	return Config{
+		Window:               10 * time.Minute,
+		ChurnThreshold:       30,
+		ZeroPaymentThreshold: 5,
+		PortScanThreshold:    200,
+		BlockDuration:        time.Hour,
+		Retention:            24 * time.Hour,
+	}
+}
+
+// Score describes abuse indicators of a single consumer.
+// Each heuristic contributes its observed value divided by its threshold,
+// so a single heuristic at its threshold results in a score of 1.
+type Score struct {
+	Value             float64
+	Sessions          int
+	ZeroPaymentStreak int
+	DistinctPorts     int
+	Flags             []string
+	BlockedUntil      time.Time
+}
+
+type consumerStats struct {
+	sessionStarts     []time.Time
+	zeroPaymentStreak int
+	ports             map[uint16]time.Time
+	flags             map[string]bool
+	blockedUntil      time.Time
+	activeSessions    int
+	seenAt            time.Time
+}
+
+type sessionState struct {
+	consumerID identity.Identity
+	paid       bool
+}
+
+// Detector scores consumers on provider side by analysing their sessions.
+type Detector struct {
+	config    Config
+	publisher eventbus.Publisher
+	now       func() time.Time
+
+	lock      sync.Mutex
+	consumers map[identity.Identity]*consumerStats
+	sessions  map[string]*sessionState
+	sweptAt   time.Time
+}
+
+// NewDetector returns a new abuse detector.
+func NewDetector(config Config, publisher eventbus.Publisher) *Detector {
+	return &Detector{
+		config:    config,
+		publisher: publisher,
+		now:       time.Now,
+		consumers: make(map[identity.Identity]*consumerStats),
+		sessions:  make(map[string]*sessionState),
+	}
+}
+
+// Subscribe subscribes the detector to session and session egress events.
+func (d *Detector) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.SubscribeAsync(sevent.AppTopicSession, d.handleSessionEvent); err != nil {
+		return err
+	}
+	if err := bus.SubscribeAsync(sevent.AppTopicSessionEgress, d.handleSessionEgress); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(sevent.AppTopicTokensEarned, d.handleTokensEarned)
+}
+
+// IsBlocked returns true if the consumer is currently blocked.
+func (d *Detector) IsBlocked(consumerID identity.Identity) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	stats, ok := d.consumers[consumerID]
+	return ok && d.now().Before(stats.blockedUntil)
+}
+
+// Score returns the current abuse score of the consumer.
+func (d *Detector) Score(consumerID identity.Identity) Score {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	stats, ok := d.consumers[consumerID]
+	if !ok {
+		return Score{}
+	}
+	return d.score(stats)
+}
+
+// Scores returns abuse scores of all known consumers.
+func (d *Detector) Scores() map[identity.Identity]Score {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	scores := make(map[identity.Identity]Score, len(d.consumers))
+	for id, stats := range d.consumers {
+		scores[id] = d.score(stats)
+	}
+	return scores
+}
+
+func (d *Detector) handleSessionEvent(e sevent.AppEventSession) {
+	d.lock.Lock()
+	var events []interface{}
+	switch e.Status {
+	case sevent.CreatedStatus:
+		d.sessions[e.Session.ID] = &sessionState{consumerID: e.Session.ConsumerID}
+		stats := d.consumerStats(e.Session.ConsumerID)
+		stats.sessionStarts = append(stats.sessionStarts, d.now())
+		stats.activeSessions++
+		events = d.evaluate(e.Session.ConsumerID, stats)
+	case sevent.RemovedStatus:
+		session, ok := d.sessions[e.Session.ID]
+		if !ok {
+			break
+		}
+		delete(d.sessions, e.Session.ID)
+
+		stats := d.consumerStats(session.consumerID)
+		stats.activeSessions--
+		if session.paid {
+			stats.zeroPaymentStreak = 0
+		} else {
+			stats.zeroPaymentStreak++
+		}
+		events = d.evaluate(session.consumerID, stats)
+		d.sweep()
+	}
+	d.lock.Unlock()
+
+	d.publish(events)
+}
+
+// handleSessionEgress registers destination ports reached by the consumer of the session.
+func (d *Detector) handleSessionEgress(e sevent.AppEventSessionEgress) {
+	d.lock.Lock()
+	session, ok := d.sessions[e.SessionID]
+	if !ok {
+		d.lock.Unlock()
+		return
+	}
+
+	stats := d.consumerStats(session.consumerID)
+	now := d.now()
+	for _, port := range e.Ports {
+		stats.ports[port] = now
+	}
+	events := d.evaluate(session.consumerID, stats)
+	d.lock.Unlock()
+
+	d.publish(events)
+}
+
+func (d *Detector) handleTokensEarned(e sevent.AppEventTokensEarned) {
+	if e.Total == nil || e.Total.Sign() <= 0 {
+		return
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	session, ok := d.sessions[e.SessionID]
+	if !ok {
+		return
+	}
+	session.paid = true
+	d.consumerStats(session.consumerID).zeroPaymentStreak = 0
+}
+
+func (d *Detector) consumerStats(consumerID identity.Identity) *consumerStats {
+	stats, ok := d.consumers[consumerID]
+	if !ok {
+		stats = &consumerStats{
+			ports: make(map[uint16]time.Time),
+			flags: make(map[string]bool),
+		}
+		d.consumers[consumerID] = stats
+	}
+	stats.seenAt = d.now()
+	return stats
+}
+
+// sweep forgets consumers without active sessions which were not seen for the retention period.
+// It must be called with the lock held, it runs at most once per window.
+func (d *Detector) sweep() {
+	now := d.now()
+	if d.config.Retention <= 0 || now.Sub(d.sweptAt) < d.config.Window {
+		return
+	}
+	d.sweptAt = now
+
+	for id, stats := range d.consumers {
+		if stats.activeSessions <= 0 && now.Sub(stats.seenAt) >= d.config.Retention && !now.Before(stats.blockedUntil) {
+			delete(d.consumers, id)
+		}
+	}
+}
+
+func (d *Detector) prune(stats *consumerStats) {
+	since := d.now().Add(-d.config.Window)
+
+	i := 0
+	for i < len(stats.sessionStarts) && stats.sessionStarts[i].Before(since) {
+		i++
+	}
+	stats.sessionStarts = stats.sessionStarts[i:]
+
+	for port, seen := range stats.ports {
+		if seen.Before(since) {
+			delete(stats.ports, port)
+		}
+	}
+}
+
+func (d *Detector) score(stats *consumerStats) Score {
+	score := Score{
+		Sessions:          len(stats.sessionStarts),
+		ZeroPaymentStreak: stats.zeroPaymentStreak,
+		DistinctPorts:     len(stats.ports),
+		BlockedUntil:      stats.blockedUntil,
+	}
+
+	check := func(flag string, observed, threshold int) {
+		if threshold <= 0 {
+			return
+		}
+		score.Value += float64(observed) / float64(threshold)
+		if observed >= threshold {
+			score.Flags = append(score.Flags, flag)
+		}
+	}
+	check(FlagConnectionChurn, score.Sessions, d.config.ChurnThreshold)
+	check(FlagZeroPaymentStreak, score.ZeroPaymentStreak, d.config.ZeroPaymentThreshold)
+	check(FlagPortScan, score.DistinctPorts, d.config.PortScanThreshold)
+	sort.Strings(score.Flags)
+
+	return score
+}
+
+// evaluate must be called with the lock held, it returns events to be published after the lock is released.
+func (d *Detector) evaluate(consumerID identity.Identity, stats *consumerStats) (events []interface{}) {
+	d.prune(stats)
+	score := d.score(stats)
+
+	raised := false
+	current := make(map[string]bool, len(score.Flags))
+	for _, flag := range score.Flags {
+		current[flag] = true
+		if !stats.flags[flag] {
+			raised = true
+		}
+	}
+	stats.flags = current
+
+	if raised {
+		log.Warn().Msgf("Consumer %s flagged for abuse: %v (score %.2f)", consumerID.Address, score.Flags, score.Value)
+		events = append(events, AppEventAbuse{ConsumerID: consumerID, Score: score})
+	}
+
+	now := d.now()
+	if d.config.BlockScore > 0 && score.Value >= d.config.BlockScore && !now.Before(stats.blockedUntil) {
+		stats.blockedUntil = now.Add(d.config.BlockDuration)
+		score.BlockedUntil = stats.blockedUntil
+		log.Warn().Msgf("Consumer %s blocked until %s", consumerID.Address, stats.blockedUntil)
+		events = append(events, AppEventConsumerBlocked{ConsumerID: consumerID, Score: score})
+	}
+
+	return events
+}
+
+func (d *Detector) publish(events []interface{}) {
+	for _, e := range events {
+		switch e := e.(type) {
+		case AppEventAbuse:
+			d.publisher.Publish(AppTopicAbuseDetected, e)
+		case AppEventConsumerBlocked:
+			d.publisher.Publish(AppTopicConsumerBlocked, e)
+		}
+	}
+}
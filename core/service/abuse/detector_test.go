@@ -0,0 +1,160 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package abuse
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/mocks"
+	sevent "github.com/mysteriumnetwork/node/session/event"
+)
+
+var consumer = identity.FromAddress("0x1")
+
+func newTestDetector(config Config) (*Detector, *mocks.EventBus, *time.Time) {
+	bus := mocks.NewEventBus()
+	now := time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)
+	d := NewDetector(config, bus)
+	d.now = func() time.Time { return now }
+	return d, bus, &now
+}
+
+func sessionEvent(status sevent.Status, id string) sevent.AppEventSession {
+	return sevent.AppEventSession{
+		Status:  status,
+		Session: sevent.SessionContext{ID: id, ConsumerID: consumer},
+	}
+}
+
+func TestDetector_ConnectionChurn(t *testing.T) {
+	config := DefaultConfig()
+	config.ChurnThreshold = 3
+	d, bus, now := newTestDetector(config)
+
+	for i := 0; i < 2; i++ {
+		d.handleSessionEvent(sessionEvent(sevent.CreatedStatus, fmt.Sprint(i)))
+	}
+	assert.Empty(t, d.Score(consumer).Flags)
+
+	d.handleSessionEvent(sessionEvent(sevent.CreatedStatus, "2"))
+	score := d.Score(consumer)
+	assert.Equal(t, []string{FlagConnectionChurn}, score.Flags)
+	assert.Equal(t, 1.0, score.Value)
+	assert.Equal(t, AppTopicAbuseDetected, bus.GetEventHistory()[0].Topic)
+
+	*now = now.Add(config.Window + time.Second)
+	d.handleSessionEvent(sessionEvent(sevent.CreatedStatus, "3"))
+	assert.Empty(t, d.Score(consumer).Flags)
+	assert.Equal(t, 1, d.Score(consumer).Sessions)
+}
+
+func TestDetector_ZeroPaymentStreak(t *testing.T) {
+	config := DefaultConfig()
+	config.ZeroPaymentThreshold = 2
+	d, _, _ := newTestDetector(config)
+
+	for _, id := range []string{"1", "2"} {
+		d.handleSessionEvent(sessionEvent(sevent.CreatedStatus, id))
+		d.handleSessionEvent(sessionEvent(sevent.RemovedStatus, id))
+	}
+	assert.Equal(t, 2, d.Score(consumer).ZeroPaymentStreak)
+	assert.Contains(t, d.Score(consumer).Flags, FlagZeroPaymentStreak)
+
+	d.handleSessionEvent(sessionEvent(sevent.CreatedStatus, "3"))
+	d.handleTokensEarned(sevent.AppEventTokensEarned{SessionID: "3", Total: big.NewInt(1)})
+	d.handleSessionEvent(sessionEvent(sevent.RemovedStatus, "3"))
+	assert.Equal(t, 0, d.Score(consumer).ZeroPaymentStreak)
+	assert.NotContains(t, d.Score(consumer).Flags, FlagZeroPaymentStreak)
+}
+
+func TestDetector_PortScan(t *testing.T) {
+	config := DefaultConfig()
+	config.PortScanThreshold = 10
+	d, _, _ := newTestDetector(config)
+
+	d.handleSessionEvent(sessionEvent(sevent.CreatedStatus, "1"))
+	for port := uint16(1); port <= 10; port++ {
+		d.handleSessionEgress(sevent.AppEventSessionEgress{SessionID: "1", Ports: []uint16{port, port}})
+	}
+	d.handleSessionEgress(sevent.AppEventSessionEgress{SessionID: "unknown", Ports: []uint16{11}})
+
+	score := d.Score(consumer)
+	assert.Equal(t, 10, score.DistinctPorts)
+	assert.Equal(t, []string{FlagPortScan}, score.Flags)
+}
+
+func TestDetector_ForgetsIdleConsumers(t *testing.T) {
+	config := DefaultConfig()
+	d, _, now := newTestDetector(config)
+	other := identity.FromAddress("0x2")
+
+	d.handleSessionEvent(sessionEvent(sevent.CreatedStatus, "1"))
+	d.handleSessionEvent(sevent.AppEventSession{Status: sevent.CreatedStatus, Session: sevent.SessionContext{ID: "2", ConsumerID: other}})
+	d.handleSessionEvent(sessionEvent(sevent.RemovedStatus, "1"))
+	assert.Len(t, d.Scores(), 2)
+
+	*now = now.Add(config.Retention)
+	d.handleSessionEvent(sessionEvent(sevent.CreatedStatus, "3"))
+	d.handleSessionEvent(sessionEvent(sevent.RemovedStatus, "3"))
+	assert.Len(t, d.Scores(), 2, "recently seen consumer is kept")
+
+	*now = now.Add(config.Retention)
+	d.handleSessionEvent(sevent.AppEventSession{Status: sevent.CreatedStatus, Session: sevent.SessionContext{ID: "4", ConsumerID: other}})
+	d.handleSessionEvent(sevent.AppEventSession{Status: sevent.RemovedStatus, Session: sevent.SessionContext{ID: "4", ConsumerID: other}})
+
+	scores := d.Scores()
+	assert.Len(t, scores, 1)
+	assert.Contains(t, scores, other, "consumer with an active session is kept")
+	assert.Empty(t, d.sessions["1"])
+}
+
+func TestDetector_AutoBlock(t *testing.T) {
+	config := DefaultConfig()
+	config.ChurnThreshold = 2
+	config.BlockScore = 1
+	d, bus, now := newTestDetector(config)
+
+	d.handleSessionEvent(sessionEvent(sevent.CreatedStatus, "1"))
+	assert.False(t, d.IsBlocked(consumer))
+
+	d.handleSessionEvent(sessionEvent(sevent.CreatedStatus, "2"))
+	assert.True(t, d.IsBlocked(consumer))
+
+	history := bus.GetEventHistory()
+	assert.Equal(t, AppTopicConsumerBlocked, history[len(history)-1].Topic)
+
+	*now = now.Add(config.BlockDuration)
+	assert.False(t, d.IsBlocked(consumer))
+}
+
+func TestDetector_AutoBlockDisabledByDefault(t *testing.T) {
+	config := DefaultConfig()
+	config.ChurnThreshold = 1
+	d, _, _ := newTestDetector(config)
+
+	d.handleSessionEvent(sessionEvent(sevent.CreatedStatus, "1"))
+
+	assert.Contains(t, d.Score(consumer).Flags, FlagConnectionChurn)
+	assert.False(t, d.IsBlocked(consumer))
+}
@@ -0,0 +1,39 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package abuse
+
+import "github.com/mysteriumnetwork/node/identity"
+
+const (
+	// AppTopicAbuseDetected represents the topic on which consumer abuse flags are published.
+	AppTopicAbuseDetected = "Consumer abuse detected"
+	// AppTopicConsumerBlocked represents the topic on which automatically blocked consumers are published.
+	AppTopicConsumerBlocked = "Consumer blocked"
+)
+
+// AppEventAbuse is published when one of the heuristics flags a consumer.
+type AppEventAbuse struct {
+	ConsumerID identity.Identity
+	Score      Score
+}
+
+// AppEventConsumerBlocked is published when a consumer reaches the auto-block threshold.
+type AppEventConsumerBlocked struct {
+	ConsumerID identity.Identity
+	Score      Score
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package egress
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	sevent "github.com/mysteriumnetwork/node/session/event"
+)
+
+const (
+	conntrackPath   = "/proc/net/nf_conntrack"
+	defaultInterval = 10 * time.Second
+)
+
+// Sampler periodically reads the kernel connection tracking table and publishes
+// destination ports reached by the consumers of watched sessions.
+type Sampler struct {
+	bus      eventbus.Publisher
+	path     string
+	interval time.Duration
+
+	lock     sync.Mutex
+	sessions map[string]net.IPNet
+	stop     chan struct{}
+	disabled bool
+}
+
+// NewSampler creates a new egress sampler.
+func NewSampler(bus eventbus.Publisher) *Sampler {
+	return &Sampler{
+		bus:      bus,
+		path:     conntrackPath,
+		interval: defaultInterval,
+		sessions: make(map[string]net.IPNet),
+	}
+}
+
+// Watch starts publishing egress ports of the session consumer addressed from the given network.
+// Returned function stops watching the session.
+func (s *Sampler) Watch(sessionID string, network net.IPNet) func() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.sessions[sessionID] = network
+	if s.stop == nil && !s.disabled {
+		s.stop = make(chan struct{})
+		go s.run(s.stop)
+	}
+
+	return func() {
+		s.unwatch(sessionID)
+	}
+}
+
+func (s *Sampler) unwatch(sessionID string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.sessions, sessionID)
+	if len(s.sessions) == 0 && s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+func (s *Sampler) run(stop chan struct{}) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := s.sample(); err != nil {
+				log.Warn().Err(err).Msg("Could not read connection tracking table, egress sampling disabled")
+				s.disable(stop)
+				return
+			}
+		}
+	}
+}
+
+func (s *Sampler) disable(stop chan struct{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.disabled = true
+	if s.stop == stop {
+		s.stop = nil
+	}
+}
+
+func (s *Sampler) sample() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	flows, err := parseConntrack(f)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	ports := make(map[string][]uint16)
+	for _, flow := range flows {
+		for sessionID, network := range s.sessions {
+			if network.Contains(flow.src) {
+				ports[sessionID] = append(ports[sessionID], flow.dport)
+				break
+			}
+		}
+	}
+	s.lock.Unlock()
+
+	for sessionID, sessionPorts := range ports {
+		s.bus.Publish(sevent.AppTopicSessionEgress, sevent.AppEventSessionEgress{
+			SessionID: sessionID,
+			Ports:     sessionPorts,
+		})
+	}
+	return nil
+}
+
+type flow struct {
+	src   net.IP
+	dport uint16
+}
+
+// parseConntrack extracts the original direction source address and destination port of tracked flows.
+func parseConntrack(r io.Reader) ([]flow, error) {
+	var flows []flow
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var f flow
+		var hasPort bool
+		for _, field := range strings.Fields(scanner.Text()) {
+			switch {
+			case f.src == nil && strings.HasPrefix(field, "src="):
+				f.src = net.ParseIP(strings.TrimPrefix(field, "src="))
+			case !hasPort && strings.HasPrefix(field, "dport="):
+				port, err := strconv.ParseUint(strings.TrimPrefix(field, "dport="), 10, 16)
+				if err != nil {
+					continue
+				}
+				f.dport = uint16(port)
+				hasPort = true
+			}
+		}
+		if f.src != nil && hasPort {
+			flows = append(flows, f)
+		}
+	}
+	return flows, scanner.Err()
+}
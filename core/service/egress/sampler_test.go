@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package egress
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/mocks"
+	sevent "github.com/mysteriumnetwork/node/session/event"
+)
+
+const conntrackSample = `ipv4     2 tcp      6 431999 ESTABLISHED src=10.182.0.2 dst=1.1.1.1 sport=50000 dport=443 src=1.1.1.1 dst=192.168.1.10 sport=443 dport=50000 [ASSURED] mark=0 zone=0 use=2
+ipv4     2 udp      17 29 src=10.182.0.2 dst=8.8.8.8 sport=50001 dport=53 src=8.8.8.8 dst=192.168.1.10 sport=53 dport=50001 mark=0 zone=0 use=2
+ipv4     2 icmp     1 29 src=10.182.0.2 dst=8.8.8.8 type=8 code=0 id=1 src=8.8.8.8 dst=192.168.1.10 type=0 code=0 id=1 mark=0 zone=0 use=2
+ipv4     2 tcp      6 100 SYN_SENT src=10.183.0.2 dst=1.1.1.1 sport=50002 dport=22 [UNREPLIED] src=1.1.1.1 dst=192.168.1.10 sport=22 dport=50002 mark=0 zone=0 use=2
+`
+
+func Test_parseConntrack(t *testing.T) {
+	flows, err := parseConntrack(strings.NewReader(conntrackSample))
+	require.NoError(t, err)
+
+	assert.Equal(t, []flow{
+		{src: net.ParseIP("10.182.0.2"), dport: 443},
+		{src: net.ParseIP("10.182.0.2"), dport: 53},
+		{src: net.ParseIP("10.183.0.2"), dport: 22},
+	}, flows)
+}
+
+func TestSampler_PublishesPortsOfWatchedSessions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nf_conntrack")
+	require.NoError(t, os.WriteFile(path, []byte(conntrackSample), 0600))
+
+	bus := mocks.NewEventBus()
+	s := NewSampler(bus)
+	s.path = path
+	s.sessions["1"] = net.IPNet{IP: net.ParseIP("10.182.0.0"), Mask: net.CIDRMask(24, 32)}
+
+	require.NoError(t, s.sample())
+	assert.Equal(t, sevent.AppEventSessionEgress{SessionID: "1", Ports: []uint16{443, 53}}, bus.Pop())
+	assert.Nil(t, bus.Pop())
+}
+
+func TestSampler_StopsWhenNothingIsWatched(t *testing.T) {
+	s := NewSampler(mocks.NewEventBus())
+	s.path = filepath.Join(t.TempDir(), "missing")
+
+	unwatch := s.Watch("1", net.IPNet{IP: net.ParseIP("10.182.0.0"), Mask: net.CIDRMask(24, 32)})
+	assert.NotNil(t, s.stop)
+
+	unwatch()
+	assert.Nil(t, s.stop)
+	assert.Empty(t, s.sessions)
+}
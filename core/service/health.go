@@ -0,0 +1,170 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+)
+
+// HealthProber is implemented by services able to self-test their data plane.
+type HealthProber interface {
+	Probe() error
+}
+
+// Health statuses of a service.
+const (
+	HealthUnknown   = "unknown"
+	HealthHealthy   = "healthy"
+	HealthUnhealthy = "unhealthy"
+)
+
+// Health describes results of the service health probes.
+type Health struct {
+	Status    string
+	CheckedAt time.Time
+	Error     string
+	// Failures counts consecutive failed probes.
+	Failures int
+	// Restarts counts restarts of the service caused by failed probes.
+	Restarts int
+}
+
+// RestartPolicy defines when unhealthy services are restarted.
+type RestartPolicy struct {
+	// Failures is a number of consecutive failed probes after which service is restarted, restarts are disabled if 0.
+	Failures int
+	// MaxRestarts limits restarts of the service, unlimited if 0.
+	MaxRestarts int
+}
+
+func (p RestartPolicy) shouldRestart(health Health) bool {
+	if p.Failures <= 0 || health.Failures < p.Failures {
+		return false
+	}
+	return p.MaxRestarts <= 0 || health.Restarts < p.MaxRestarts
+}
+
+type supervisedServices interface {
+	List(includeAll bool) []*Instance
+	Service(id ID) *Instance
+	Restart(id ID, policyIDs []string, options Options) (ID, error)
+}
+
+// Supervisor periodically probes health of running services and restarts unhealthy ones.
+type Supervisor struct {
+	services supervisedServices
+	interval time.Duration
+	policy   RestartPolicy
+	now      func() time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewSupervisor returns a new service health supervisor.
+func NewSupervisor(services supervisedServices, interval time.Duration, policy RestartPolicy) *Supervisor {
+	return &Supervisor{
+		services: services,
+		interval: interval,
+		policy:   policy,
+		now:      time.Now,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start starts probing services periodically, it is a noop if interval is not set.
+func (s *Supervisor) Start() {
+	if s.interval <= 0 {
+		log.Info().Msg("Service health probes disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.Check()
+			}
+		}
+	}()
+}
+
+// Stop stops probing services.
+func (s *Supervisor) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+}
+
+// Check probes all running services once and applies restart policy to unhealthy ones.
+func (s *Supervisor) Check() {
+	for _, instance := range s.services.List(false) {
+		if instance.State() != servicestate.Running {
+			continue
+		}
+		prober, ok := instance.Service().(HealthProber)
+		if !ok {
+			continue
+		}
+
+		health := instance.Health()
+		health.CheckedAt = s.now()
+		if err := prober.Probe(); err != nil {
+			log.Warn().Err(err).Msgf("Health probe of %s service %s failed", instance.Type, instance.ID)
+			health.Status = HealthUnhealthy
+			health.Error = err.Error()
+			health.Failures++
+		} else {
+			health.Status = HealthHealthy
+			health.Error = ""
+			health.Failures = 0
+		}
+		instance.setHealth(health)
+
+		if s.policy.shouldRestart(health) {
+			s.restart(instance, health)
+		}
+	}
+}
+
+func (s *Supervisor) restart(instance *Instance, health Health) {
+	var policyIDs []string
+	for _, policy := range instance.Policies().Policies() {
+		policyIDs = append(policyIDs, policy.ID)
+	}
+
+	log.Warn().Msgf("Restarting unhealthy %s service %s after %d failed probes", instance.Type, instance.ID, health.Failures)
+	newID, err := s.services.Restart(instance.ID, policyIDs, instance.Options)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to restart unhealthy service %s", instance.ID)
+		return
+	}
+
+	if replacement := s.services.Service(newID); replacement != nil {
+		replacement.setHealth(Health{Status: HealthUnknown, Restarts: health.Restarts + 1})
+	}
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/mocks"
+)
+
+type probedService struct {
+	mockService
+	probeErr error
+}
+
+func (s *probedService) Probe() error {
+	return s.probeErr
+}
+
+type mockSupervisedServices struct {
+	instances map[ID]*Instance
+	restarted []ID
+}
+
+func (m *mockSupervisedServices) List(_ bool) []*Instance {
+	var list []*Instance
+	for _, instance := range m.instances {
+		list = append(list, instance)
+	}
+	return list
+}
+
+func (m *mockSupervisedServices) Service(id ID) *Instance {
+	return m.instances[id]
+}
+
+func (m *mockSupervisedServices) Restart(id ID, _ []string, options Options) (ID, error) {
+	old := m.instances[id]
+	delete(m.instances, id)
+	m.restarted = append(m.restarted, id)
+
+	newID := id + "-restarted"
+	m.instances[newID] = newProbedInstance(newID, old.service)
+	return newID, nil
+}
+
+func newProbedInstance(id ID, svc Service) *Instance {
+	return &Instance{
+		ID:       id,
+		Type:     "wireguard",
+		state:    servicestate.Running,
+		service:  svc,
+		policies: policy.NewRepository(),
+	}
+}
+
+func TestSupervisor_RecordsHealth(t *testing.T) {
+	svc := &probedService{}
+	services := &mockSupervisedServices{instances: map[ID]*Instance{"1": newProbedInstance("1", svc)}}
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	supervisor := NewSupervisor(services, time.Minute, RestartPolicy{Failures: 3})
+	supervisor.now = func() time.Time { return now }
+
+	assert.Equal(t, HealthUnknown, services.Service("1").Health().Status)
+
+	supervisor.Check()
+	assert.Equal(t, Health{Status: HealthHealthy, CheckedAt: now}, services.Service("1").Health())
+
+	svc.probeErr = errors.New("no handshake")
+	supervisor.Check()
+	assert.Equal(t, Health{Status: HealthUnhealthy, CheckedAt: now, Error: "no handshake", Failures: 1}, services.Service("1").Health())
+
+	svc.probeErr = nil
+	supervisor.Check()
+	assert.Equal(t, Health{Status: HealthHealthy, CheckedAt: now}, services.Service("1").Health())
+	assert.Empty(t, services.restarted)
+}
+
+func TestSupervisor_RestartsUnhealthyServices(t *testing.T) {
+	svc := &probedService{probeErr: errors.New("management interface is not responding")}
+	services := &mockSupervisedServices{instances: map[ID]*Instance{"1": newProbedInstance("1", svc)}}
+	supervisor := NewSupervisor(services, time.Minute, RestartPolicy{Failures: 2, MaxRestarts: 1})
+
+	supervisor.Check()
+	assert.Empty(t, services.restarted)
+
+	supervisor.Check()
+	assert.Equal(t, []ID{"1"}, services.restarted)
+	assert.Equal(t, Health{Status: HealthUnknown, Restarts: 1}, services.Service("1-restarted").Health())
+
+	// Restart limit is reached, the service is kept unhealthy.
+	supervisor.Check()
+	supervisor.Check()
+	supervisor.Check()
+	assert.Equal(t, []ID{"1"}, services.restarted)
+	health := services.Service("1-restarted").Health()
+	assert.Equal(t, HealthUnhealthy, health.Status)
+	assert.Equal(t, 3, health.Failures)
+	assert.Equal(t, 1, health.Restarts)
+}
+
+func TestSupervisor_SkipsServicesWithoutProbes(t *testing.T) {
+	instance := newProbedInstance("1", &mockService{})
+	services := &mockSupervisedServices{instances: map[ID]*Instance{"1": instance}}
+	supervisor := NewSupervisor(services, time.Minute, RestartPolicy{Failures: 1})
+
+	supervisor.Check()
+	assert.Equal(t, HealthUnknown, instance.Health().Status)
+	assert.Empty(t, services.restarted)
+}
+
+func TestSupervisor_PublishesHealthChanges(t *testing.T) {
+	svc := &probedService{probeErr: errors.New("no handshake")}
+	instance := newProbedInstance("1", svc)
+	bus := mocks.NewEventBus()
+	instance.eventPublisher = bus
+	services := &mockSupervisedServices{instances: map[ID]*Instance{"1": instance}}
+	supervisor := NewSupervisor(services, time.Minute, RestartPolicy{})
+
+	supervisor.Check()
+	supervisor.Check()
+
+	assert.Equal(t, []mocks.EventBusEntry{{
+		Topic: servicestate.AppTopicServiceHealth,
+		Event: servicestate.AppEventServiceHealth{ID: "1", Type: "wireguard", Health: HealthUnhealthy, Error: "no handshake"},
+	}}, bus.GetEventHistory())
+}
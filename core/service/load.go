@@ -0,0 +1,114 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/market"
+	sevent "github.com/mysteriumnetwork/node/session/event"
+)
+
+type eventSubscriber interface {
+	SubscribeAsync(topic string, fn interface{}) error
+}
+
+type sessionLoad struct {
+	serviceID string
+	bytes     uint64
+	sampledAt time.Time
+	rate      uint64
+}
+
+// LoadTracker keeps track of provider utilization to be advertised in proposals.
+type LoadTracker struct {
+	maxSessions int
+	capacity    uint64
+	now         func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*sessionLoad
+}
+
+// NewLoadTracker returns a new load tracker.
+// Bandwidth capacity is given in bytes per second, 0 when unknown.
+func NewLoadTracker(maxSessions int, capacity uint64) *LoadTracker {
+	return &LoadTracker{
+		maxSessions: maxSessions,
+		capacity:    capacity,
+		now:         time.Now,
+		sessions:    make(map[string]*sessionLoad),
+	}
+}
+
+// Subscribe subscribes to session events.
+func (t *LoadTracker) Subscribe(bus eventSubscriber) error {
+	if err := bus.SubscribeAsync(sevent.AppTopicSession, t.consumeSessionEvent); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(sevent.AppTopicDataTransferred, t.consumeDataTransferredEvent)
+}
+
+// Load returns current load of the given service.
+func (t *LoadTracker) Load(serviceID string) market.Load {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	load := market.Load{
+		MaxSessions:       t.maxSessions,
+		BandwidthCapacity: t.capacity,
+	}
+	for _, s := range t.sessions {
+		if s.serviceID == serviceID {
+			load.Sessions++
+		}
+		// Bandwidth is shared by all services of the node.
+		load.Throughput += s.rate
+	}
+	return load
+}
+
+func (t *LoadTracker) consumeSessionEvent(e sevent.AppEventSession) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch e.Status {
+	case sevent.CreatedStatus:
+		t.sessions[e.Session.ID] = &sessionLoad{serviceID: e.Service.ID, sampledAt: t.now()}
+	case sevent.RemovedStatus:
+		delete(t.sessions, e.Session.ID)
+	}
+}
+
+func (t *LoadTracker) consumeDataTransferredEvent(e sevent.AppEventDataTransferred) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.sessions[e.ID]
+	if !ok {
+		return
+	}
+
+	now := t.now()
+	total := e.Up + e.Down
+	if elapsed := now.Sub(s.sampledAt).Seconds(); elapsed > 0 {
+		s.rate = uint64(float64(delta(total, s.bytes)) / elapsed)
+	}
+	s.bytes, s.sampledAt = total, now
+}
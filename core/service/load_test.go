@@ -0,0 +1,65 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/market"
+	sevent "github.com/mysteriumnetwork/node/session/event"
+)
+
+func TestLoadTracker_Load(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewLoadTracker(4, 1000)
+	tracker.now = func() time.Time { return now }
+
+	created := func(serviceID, sessionID string) sevent.AppEventSession {
+		return sevent.AppEventSession{
+			Status:  sevent.CreatedStatus,
+			Service: sevent.ServiceContext{ID: serviceID},
+			Session: sevent.SessionContext{ID: sessionID},
+		}
+	}
+	tracker.consumeSessionEvent(created("svc1", "s1"))
+	tracker.consumeSessionEvent(created("svc1", "s2"))
+	tracker.consumeSessionEvent(created("svc2", "s3"))
+
+	now = now.Add(10 * time.Second)
+	tracker.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "s1", Up: 2000, Down: 1000})
+	tracker.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "s3", Up: 1000, Down: 1000})
+	tracker.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "unknown", Up: 1000, Down: 1000})
+
+	load := tracker.Load("svc1")
+	assert.Equal(t, market.Load{Sessions: 2, MaxSessions: 4, Throughput: 500, BandwidthCapacity: 1000}, load)
+	assert.Equal(t, uint64(500), load.BandwidthHeadroom())
+	assert.Equal(t, 0.5, load.Utilization())
+
+	tracker.consumeSessionEvent(sevent.AppEventSession{
+		Status:  sevent.RemovedStatus,
+		Service: sevent.ServiceContext{ID: "svc1"},
+		Session: sevent.SessionContext{ID: "s1"},
+	})
+	load = tracker.Load("svc1")
+	assert.Equal(t, 1, load.Sessions)
+	assert.Equal(t, uint64(200), load.Throughput)
+	assert.Equal(t, 0.25, load.Utilization())
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/location/locationstate"
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/crash"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/p2p"
@@ -46,10 +47,16 @@ var (
 	ErrUnsupportedServiceType = errors.New("unsupported service type")
 	// ErrUnsupportedAccessPolicy indicates that manager tried to create service with unsupported access policy
 	ErrUnsupportedAccessPolicy = errors.New("unsupported access policy")
+	// ErrNotRunning indicates that manager tried to pause a service which is not running
+	ErrNotRunning = errors.New("service is not running")
+	// ErrNotPaused indicates that manager tried to resume a service which is not paused
+	ErrNotPaused = errors.New("service is not paused")
 )
 
 const (
 	channelIdleTimeout = 1 * time.Minute
+	drainTimeout       = 1 * time.Hour
+	drainCheckInterval = 10 * time.Second
 )
 
 // Service interface represents pluggable Mysterium service
@@ -59,6 +66,12 @@ type Service interface {
 	ConfigProvider
 }
 
+// ExclusiveService is implemented by services which can not run side by side with another instance,
+// e.g. because they hold a fixed port. Such services are restarted by stopping the old instance first.
+type ExclusiveService interface {
+	Exclusive() bool
+}
+
 // DiscoveryFactory initiates instance which is able announce service discoverability
 type DiscoveryFactory func() Discovery
 
@@ -74,6 +87,22 @@ type locationResolver interface {
 	DetectLocation() (locationstate.Location, error)
 }
 
+type priceProvider interface {
+	Price(serviceType string) *market.Price
+}
+
+type loadProvider interface {
+	Load(serviceID string) market.Load
+}
+
+type priceBookProvider interface {
+	PriceBook(serviceType string) *market.PriceBook
+}
+
+type termsChecker interface {
+	CheckProvider() error
+}
+
 // WaitForNATHole blocks until NAT hole is punched towards consumer through local NAT or until hole punching failed
 type WaitForNATHole func() error
 
@@ -87,6 +116,10 @@ func NewManager(
 	sessionManager func(service *Instance, channel p2p.Channel) *SessionManager,
 	statusStorage connectivity.StatusStorage,
 	location locationResolver,
+	prices priceProvider,
+	load loadProvider,
+	priceBooks priceBookProvider,
+	terms termsChecker,
 ) *Manager {
 	return &Manager{
 		serviceRegistry:  serviceRegistry,
@@ -98,6 +131,12 @@ func NewManager(
 		sessionManager:   sessionManager,
 		statusStorage:    statusStorage,
 		location:         location,
+		prices:           prices,
+		load:             load,
+		priceBooks:       priceBooks,
+		terms:            terms,
+		drainTimeout:     drainTimeout,
+		drainInterval:    drainCheckInterval,
 	}
 }
 
@@ -114,12 +153,23 @@ type Manager struct {
 	sessionManager func(service *Instance, channel p2p.Channel) *SessionManager
 	statusStorage  connectivity.StatusStorage
 	location       locationResolver
+	prices         priceProvider
+	load           loadProvider
+	priceBooks     priceBookProvider
+	terms          termsChecker
+	drainTimeout   time.Duration
+	drainInterval  time.Duration
 }
 
 // Start starts an instance of the given service type if knows one in service registry.
 // It passes the options to the start method of the service.
 // If an error occurs in the underlying service, the error is then returned.
+// Services are only started once provider terms of service are accepted.
 func (manager *Manager) Start(providerID identity.Identity, serviceType string, policyIDs []string, options Options) (id ID, err error) {
+	if err := manager.checkTerms(); err != nil {
+		return id, err
+	}
+
 	log.Debug().Fields(map[string]interface{}{
 		"providerID":  providerID.Address,
 		"serviceType": serviceType,
@@ -141,18 +191,13 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 		}
 	}
 
-	location, err := manager.location.DetectLocation()
+	proposal, err := manager.newProposal(providerID, serviceType, accessPolicies)
 	if err != nil {
 		return "", err
 	}
-
-	proposal := market.NewProposal(providerID.Address, serviceType, market.NewProposalOpts{
-		Location:       market.NewLocation(location),
-		AccessPolicies: accessPolicies,
-		Contacts:       []market.Contact{manager.p2pListener.GetContact()},
-	})
-
-	discovery := manager.discoveryFactory()
+	if c, ok := service.(capabilitiesProvider); ok {
+		proposal.Capabilities = c.Capabilities()
+	}
 
 	id, err = generateID()
 	if err != nil {
@@ -168,13 +213,91 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 		service:        service,
 		Proposal:       proposal,
 		policies:       policyRules,
-		discovery:      discovery,
 		eventPublisher: manager.eventPublisher,
 		location:       manager.location,
+		prices:         manager.prices,
+		load:           manager.load,
+		priceBooks:     manager.priceBooks,
+	}
+
+	if err := manager.announce(instance); err != nil {
+		return id, err
+	}
+
+	manager.servicePool.Add(instance)
+
+	go func() {
+		defer crash.Recover("service/" + serviceType)
+		instance.setState(servicestate.Running)
+
+		serveErr := service.Serve(instance)
+		if serveErr != nil {
+			log.Error().Err(serveErr).Msg("Service serve failed")
+		}
+
+		discovery := instance.stopAnnouncing()
+
+		stopErr := manager.servicePool.Stop(id)
+		if stopErr != nil {
+			log.Error().Err(stopErr).Msg("Service stop failed")
+		}
+
+		discovery.Wait()
+	}()
+
+	netutil.LogNetworkStats()
+
+	return id, nil
+}
+
+// Preview renders the proposal which would be published to discovery for the service
+// without starting it.
+func (manager *Manager) Preview(providerID identity.Identity, serviceType string, policyIDs []string) (market.ServiceProposal, error) {
+	if !manager.serviceRegistry.Supports(serviceType) {
+		return market.ServiceProposal{}, ErrUnsupportedServiceType
 	}
 
-	discovery.Start(providerID, instance.proposalWithCurrentLocation)
+	var accessPolicies []market.AccessPolicy
+	if len(policyIDs) > 0 {
+		accessPolicies = manager.policyOracle.Policies(policyIDs)
+	}
+
+	proposal, err := manager.newProposal(providerID, serviceType, accessPolicies)
+	if err != nil {
+		return market.ServiceProposal{}, err
+	}
+	if manager.load != nil {
+		// Not started service has no sessions, only the shared node throughput.
+		load := manager.load.Load("")
+		proposal.Load = &load
+	}
+	return proposal, nil
+}
+
+// capabilitiesProvider is implemented by services advertising optional transport features in proposal.
+type capabilitiesProvider interface {
+	Capabilities() []string
+}
+
+func (manager *Manager) newProposal(providerID identity.Identity, serviceType string, accessPolicies []market.AccessPolicy) (market.ServiceProposal, error) {
+	location, err := manager.location.DetectLocation()
+	if err != nil {
+		return market.ServiceProposal{}, err
+	}
+
+	proposal := market.NewProposal(providerID.Address, serviceType, market.NewProposalOpts{
+		Location:       market.NewLocation(location),
+		AccessPolicies: accessPolicies,
+		Contacts:       manager.p2pListener.GetContacts(),
+	})
+	if manager.priceBooks != nil {
+		proposal.PriceBook = manager.priceBooks.PriceBook(serviceType)
+	}
+	return proposal, nil
+}
 
+// announce publishes service proposal and starts accepting consumers of the instance.
+func (manager *Manager) announce(instance *Instance) error {
 	channelHandlers := func(ch p2p.Channel) {
 		chID := "channel:" + ch.ID()
 		log.Info().Msgf("tracking p2p.Channel: %q", chID)
@@ -188,36 +311,162 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 		subscribeSessionStatus(ch, manager.statusStorage)
 		subscribeSessionAcknowledge(mng, ch)
 		subscribeSessionDestroy(mng, ch)
+		subscribeSessionReceipt(mng, ch)
+		subscribeSessionRekey(mng, ch)
 		subscribeSessionPayments(mng, ch)
 	}
-	stopP2PListener, err := manager.p2pListener.Listen(providerID, serviceType, channelHandlers)
+	stopP2PListener, err := manager.p2pListener.Listen(instance.ProviderID, instance.Type, channelHandlers)
 	if err != nil {
-		return id, fmt.Errorf("could not subscribe to p2p channels: %w", err)
+		return fmt.Errorf("could not subscribe to p2p channels: %w", err)
 	}
 
-	manager.servicePool.Add(instance)
+	discovery := manager.discoveryFactory()
+	discovery.Start(instance.ProviderID, instance.proposalWithCurrentLocation)
 
-	go func() {
-		instance.setState(servicestate.Running)
+	instance.setAnnouncement(discovery, stopP2PListener)
+	return nil
+}
 
-		serveErr := service.Serve(instance)
-		if serveErr != nil {
-			log.Error().Err(serveErr).Msg("Service serve failed")
+// Reannounce restarts announcements of all running services, so that consumers
+// connect using the current public IP and location.
+func (manager *Manager) Reannounce() []ID {
+	var ids []ID
+	for _, instance := range manager.servicePool.List() {
+		if instance.State() != servicestate.Running {
+			continue
+		}
+
+		instance.stopAnnouncing().Wait()
+		if err := manager.announce(instance); err != nil {
+			log.Error().Err(err).Msgf("Failed to re-announce service %s", instance.ID)
+			continue
 		}
+		ids = append(ids, instance.ID)
+	}
+	return ids
+}
 
-		stopP2PListener()
+// Restart replaces the running service with a new instance of the given configuration.
+// Instead of killing active sessions, the old instance stops accepting consumers
+// and is stopped once its sessions end or drain timeout passes.
+// Exclusive services are stopped before the new instance starts.
+func (manager *Manager) Restart(id ID, policyIDs []string, options Options) (ID, error) {
+	old := manager.servicePool.Instance(id)
+	if old == nil {
+		return "", ErrNoSuchInstance
+	}
+	// Exclusive services are stopped before the replacement starts, so terms are checked beforehand.
+	if err := manager.checkTerms(); err != nil {
+		return "", err
+	}
 
-		stopErr := manager.servicePool.Stop(id)
-		if stopErr != nil {
-			log.Error().Err(stopErr).Msg("Service stop failed")
+	if exclusive, ok := old.service.(ExclusiveService); ok && exclusive.Exclusive() {
+		log.Info().Msgf("Service %s can not run side by side with its replacement, stopping it first", id)
+		if err := manager.Stop(id); err != nil {
+			return "", err
 		}
+		return manager.Start(old.ProviderID, old.Type, policyIDs, options)
+	}
 
-		discovery.Wait()
-	}()
+	// Both proposals share the same unique ID, so the old one has to be
+	// unregistered before the new one is announced.
+	old.stopAnnouncing().Wait()
+	old.setState(servicestate.Draining)
 
-	netutil.LogNetworkStats()
+	newID, err := manager.Start(old.ProviderID, old.Type, policyIDs, options)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to start replacement of service %s, keeping it running", id)
+		if announceErr := manager.announce(old); announceErr != nil {
+			log.Error().Err(announceErr).Msgf("Failed to re-announce service %s", id)
+		}
+		old.setState(servicestate.Running)
+		return "", err
+	}
 
-	return id, nil
+	go manager.drain(old)
+
+	return newID, nil
+}
+
+func (manager *Manager) checkTerms() error {
+	if manager.terms == nil {
+		return nil
+	}
+	if err := manager.terms.CheckProvider(); err != nil {
+		return fmt.Errorf("provider terms of service must be accepted: %w", err)
+	}
+	return nil
+}
+
+// Drain stops announcing the service and stops it once its sessions end or drain timeout passes.
+func (manager *Manager) Drain(id ID) error {
+	instance := manager.servicePool.Instance(id)
+	if instance == nil {
+		return ErrNoSuchInstance
+	}
+
+	instance.stopAnnouncing().Wait()
+	instance.setState(servicestate.Draining)
+	go manager.drain(instance)
+
+	return nil
+}
+
+// Pause stops announcing the running service and accepting new sessions, keeping its active sessions.
+func (manager *Manager) Pause(id ID) error {
+	instance := manager.servicePool.Instance(id)
+	if instance == nil {
+		return ErrNoSuchInstance
+	}
+	if instance.State() != servicestate.Running {
+		return ErrNotRunning
+	}
+
+	instance.stopAnnouncing().Wait()
+	instance.setState(servicestate.Paused)
+	return nil
+}
+
+// Resume announces the paused service again.
+func (manager *Manager) Resume(id ID) error {
+	instance := manager.servicePool.Instance(id)
+	if instance == nil {
+		return ErrNoSuchInstance
+	}
+	if instance.State() != servicestate.Paused {
+		return ErrNotPaused
+	}
+
+	if err := manager.announce(instance); err != nil {
+		return err
+	}
+	instance.setState(servicestate.Running)
+	return nil
+}
+
+func (manager *Manager) drain(instance *Instance) {
+	timeout := time.After(manager.drainTimeout)
+	ticker := time.NewTicker(manager.drainInterval)
+	defer ticker.Stop()
+
+	for instance.ActiveSessions() > 0 {
+		select {
+		case <-timeout:
+			log.Warn().Msgf("Service %s drain timed out, stopping remaining sessions", instance.ID)
+			manager.stopDrained(instance.ID)
+			return
+		case <-ticker.C:
+		}
+	}
+	manager.stopDrained(instance.ID)
+}
+
+func (manager *Manager) stopDrained(id ID) {
+	if err := manager.Stop(id); err != nil && !errors.Is(err, ErrNoSuchInstance) {
+		log.Error().Err(err).Msgf("Failed to stop drained service %s", id)
+		return
+	}
+	log.Info().Msgf("Drained service %s stopped", id)
 }
 
 func generateID() (ID, error) {
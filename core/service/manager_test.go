@@ -29,6 +29,7 @@ import (
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/p2p/camouflage"
 	"github.com/mysteriumnetwork/node/requests"
 	"github.com/mysteriumnetwork/node/utils/netutil"
 	"github.com/stretchr/testify/assert"
@@ -58,7 +59,7 @@ func TestManager_StartRemovesServiceFromPoolIfServiceCrashes(t *testing.T) {
 		discoveryFactory,
 		mocks.NewEventBus(),
 		mockPolicyOracle,
-		&mockP2PListener{}, nil, nil, mockLocationResolver{},
+		&mockP2PListener{}, nil, nil, mockLocationResolver{}, nil, nil, nil, nil,
 	)
 	_, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{})
 	assert.Nil(t, err)
@@ -67,6 +68,36 @@ func TestManager_StartRemovesServiceFromPoolIfServiceCrashes(t *testing.T) {
 	assert.Len(t, manager.servicePool.List(), 0)
 }
 
+type mockTerms struct {
+	err error
+}
+
+func (m mockTerms) CheckProvider() error {
+	return m.err
+}
+
+func TestManager_StartRequiresAcceptedTerms(t *testing.T) {
+	registry := NewRegistry()
+	mockCopy := *serviceMock
+	registry.Register(serviceType, func(options Options) (Service, error) {
+		return &mockCopy, nil
+	})
+
+	manager := NewManager(
+		registry,
+		MockDiscoveryFactoryFunc(&mockDiscovery{}),
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		&mockP2PListener{}, nil, nil,
+		mockLocationResolver{}, nil,
+		nil, nil,
+		mockTerms{err: errors.New("terms of service are not accepted")},
+	)
+	_, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{})
+	assert.EqualError(t, err, "provider terms of service must be accepted: terms of service are not accepted")
+	assert.Len(t, manager.servicePool.List(), 0)
+}
+
 func TestManager_StartDoesNotCrashIfStoppedByUser(t *testing.T) {
 	registry := NewRegistry()
 	mockCopy := *serviceMock
@@ -83,7 +114,8 @@ func TestManager_StartDoesNotCrashIfStoppedByUser(t *testing.T) {
 		mocks.NewEventBus(),
 		mockPolicyOracle,
 		&mockP2PListener{}, nil, nil,
-		mockLocationResolver{},
+		mockLocationResolver{}, nil,
+		nil, nil, nil,
 	)
 	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{})
 	assert.Nil(t, err)
@@ -110,7 +142,8 @@ func TestManager_StopSendsEvent_SucceedsAndPublishesEvent(t *testing.T) {
 		eventBus,
 		mockPolicyOracle,
 		&mockP2PListener{}, nil, nil,
-		mockLocationResolver{},
+		mockLocationResolver{}, nil,
+		nil, nil, nil,
 	)
 
 	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{})
@@ -148,11 +181,201 @@ func TestManager_StopSendsEvent_SucceedsAndPublishesEvent(t *testing.T) {
 	assert.True(t, matchFound)
 }
 
+func TestManager_RestartDrainsOldInstance(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(serviceType, func(options Options) (Service, error) {
+		return &serviceFake{mockProcess: make(chan struct{})}, nil
+	})
+
+	discoveryFactory := func() Discovery {
+		return &mockDiscovery{}
+	}
+	manager := NewManager(
+		registry,
+		discoveryFactory,
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		&mockP2PListener{}, nil, nil,
+		mockLocationResolver{}, nil,
+		nil, nil, nil,
+	)
+	manager.drainInterval = 10 * time.Millisecond
+
+	oldID, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{})
+	assert.NoError(t, err)
+	old := manager.Service(oldID)
+	old.addSession("session1")
+
+	newID, err := manager.Restart(oldID, nil, struct{}{})
+	assert.NoError(t, err)
+	assert.NotEqual(t, oldID, newID)
+	assert.Equal(t, servicestate.Draining, old.State())
+	assert.Len(t, manager.servicePool.List(), 2)
+
+	// old instance keeps running while its session is active
+	time.Sleep(50 * time.Millisecond)
+	assert.NotNil(t, manager.Service(oldID))
+
+	old.removeSession("session1")
+	assert.Eventually(t, func() bool {
+		return manager.Service(oldID) == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.NotNil(t, manager.Service(newID))
+}
+
+func TestManager_RestartStopsExclusiveServiceFirst(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(serviceType, func(options Options) (Service, error) {
+		return &exclusiveServiceFake{serviceFake{mockProcess: make(chan struct{})}}, nil
+	})
+
+	manager := NewManager(
+		registry,
+		MockDiscoveryFactoryFunc(&mockDiscovery{}),
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		&mockP2PListener{}, nil, nil,
+		mockLocationResolver{}, nil,
+		nil, nil, nil,
+	)
+
+	oldID, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{})
+	assert.NoError(t, err)
+	manager.Service(oldID).addSession("session1")
+
+	newID, err := manager.Restart(oldID, nil, struct{}{})
+	assert.NoError(t, err)
+	assert.Nil(t, manager.Service(oldID))
+	assert.NotNil(t, manager.Service(newID))
+	assert.Len(t, manager.servicePool.List(), 1)
+}
+
+func TestManager_DrainTimesOut(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(serviceType, func(options Options) (Service, error) {
+		return &serviceFake{mockProcess: make(chan struct{})}, nil
+	})
+
+	manager := NewManager(
+		registry,
+		MockDiscoveryFactoryFunc(&mockDiscovery{}),
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		&mockP2PListener{}, nil, nil,
+		mockLocationResolver{}, nil,
+		nil, nil, nil,
+	)
+	manager.drainTimeout = 50 * time.Millisecond
+	manager.drainInterval = 10 * time.Millisecond
+
+	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{})
+	assert.NoError(t, err)
+	manager.Service(id).addSession("session1")
+
+	assert.NoError(t, manager.Drain(id))
+	assert.Eventually(t, func() bool {
+		return manager.Service(id) == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestManager_PauseResume(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(serviceType, func(options Options) (Service, error) {
+		return &serviceFake{mockProcess: make(chan struct{})}, nil
+	})
+	listener := &mockP2PListener{}
+	manager := NewManager(
+		registry,
+		func() Discovery { return &mockDiscovery{} },
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		listener, nil, nil,
+		mockLocationResolver{}, nil,
+		nil, nil, nil,
+	)
+
+	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{})
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return manager.Service(id).State() == servicestate.Running
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, ErrNotPaused, manager.Resume(id))
+
+	assert.NoError(t, manager.Pause(id))
+	assert.Equal(t, servicestate.Paused, manager.Service(id).State())
+	assert.False(t, manager.Service(id).announcing)
+	assert.Equal(t, ErrNotRunning, manager.Pause(id))
+
+	assert.NoError(t, manager.Resume(id))
+	assert.Equal(t, servicestate.Running, manager.Service(id).State())
+	assert.True(t, manager.Service(id).announcing)
+
+	assert.Equal(t, ErrNoSuchInstance, manager.Pause("unknown"))
+}
+
+func TestManager_RestartUnknownService(t *testing.T) {
+	manager := NewManager(
+		NewRegistry(),
+		MockDiscoveryFactoryFunc(&mockDiscovery{}),
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		&mockP2PListener{}, nil, nil,
+		mockLocationResolver{}, nil,
+		nil, nil, nil,
+	)
+
+	_, err := manager.Restart("unknown", nil, struct{}{})
+	assert.Equal(t, ErrNoSuchInstance, err)
+}
+
+func TestManager_PreviewDoesNotStartService(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(serviceType, func(options Options) (Service, error) {
+		t.Fatal("service should not be created")
+		return nil, nil
+	})
+	discovery := mockDiscovery{}
+	manager := NewManager(
+		registry,
+		MockDiscoveryFactoryFunc(&discovery),
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		&mockP2PListener{}, nil, nil,
+		mockLocationResolver{}, nil,
+		&mockLoad{},
+		nil,
+		nil,
+	)
+
+	proposal, err := manager.Preview(identity.FromAddress("0x1"), serviceType, []string{"verified-traffic"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "0x1", proposal.ProviderID)
+	assert.Equal(t, serviceType, proposal.ServiceType)
+	assert.Equal(t, []market.AccessPolicy{{ID: "verified-traffic", Source: "http://policy.localhost/verified-traffic"}}, *proposal.AccessPolicies)
+	assert.NotNil(t, proposal.Load)
+	assert.Len(t, manager.servicePool.List(), 0)
+
+	_, err = manager.Preview(identity.FromAddress("0x1"), "unknown", nil)
+	assert.Equal(t, ErrUnsupportedServiceType, err)
+}
+
+type mockLoad struct {
+	sessions int
+}
+
+func (m *mockLoad) Load(_ string) market.Load {
+	return market.Load{Sessions: m.sessions}
+}
+
 type mockP2PListener struct {
 }
 
-func (m mockP2PListener) GetContact() market.Contact {
-	return market.Contact{}
+func (m mockP2PListener) GetContacts() market.ContactList {
+	return market.ContactList{{}}
+}
+
+func (m mockP2PListener) ServeCamouflage(_ *camouflage.Server) {
 }
 
 func (m mockP2PListener) Listen(_ identity.Identity, serviceType string, channelHandler func(ch p2p.Channel)) (func(), error) {
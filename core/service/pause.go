@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/utils"
+)
+
+// ErrAlreadyPaused is returned when pausing services which are already paused.
+var ErrAlreadyPaused = errors.New("services are already paused")
+
+// PauseStatus describes the provider pause.
+type PauseStatus struct {
+	Paused       bool
+	Since        time.Time
+	KeepSessions bool
+	Services     []ID
+}
+
+type pausableServices interface {
+	List(includeAll bool) []*Instance
+	Pause(id ID) error
+	Resume(id ID) error
+}
+
+type sessionLister interface {
+	GetAll() []*Session
+}
+
+// Pauser pauses and resumes all running services at once for quick maintenance,
+// without stopping the services themselves.
+type Pauser struct {
+	services pausableServices
+	sessions sessionLister
+	now      func() time.Time
+
+	mu     sync.Mutex
+	status PauseStatus
+}
+
+// NewPauser returns a new services pauser.
+func NewPauser(services pausableServices, sessions sessionLister) *Pauser {
+	return &Pauser{services: services, sessions: sessions, now: time.Now}
+}
+
+// Status returns current pause status.
+func (p *Pauser) Status() PauseStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.status
+}
+
+// Pause unpublishes proposals of all running services and rejects new sessions.
+// Active sessions are closed unless keepSessions is set.
+func (p *Pauser) Pause(keepSessions bool) (PauseStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.status.Paused {
+		return p.status, ErrAlreadyPaused
+	}
+
+	paused := make(map[string]bool)
+	errs := utils.ErrorCollection{}
+	for _, instance := range p.services.List(false) {
+		if instance.State() != servicestate.Running {
+			continue
+		}
+		if err := p.services.Pause(instance.ID); err != nil {
+			errs.Add(err)
+			continue
+		}
+		paused[string(instance.ID)] = true
+	}
+
+	if !keepSessions && p.sessions != nil {
+		for _, session := range p.sessions.GetAll() {
+			if paused[session.ServiceID] {
+				log.Info().Msgf("Closing session %s of paused service %s", session.ID, session.ServiceID)
+				go session.Close()
+			}
+		}
+	}
+
+	p.status = PauseStatus{Paused: true, Since: p.now().UTC(), KeepSessions: keepSessions, Services: make([]ID, 0, len(paused))}
+	for id := range paused {
+		p.status.Services = append(p.status.Services, ID(id))
+	}
+	sort.Slice(p.status.Services, func(i, j int) bool {
+		return p.status.Services[i] < p.status.Services[j]
+	})
+	log.Info().Msgf("Paused %d services", len(paused))
+
+	return p.status, errs.Errorf("could not pause some services: %s", ", ")
+}
+
+// Resume announces paused services again. Services stopped while paused are skipped.
+func (p *Pauser) Resume() (PauseStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	errs := utils.ErrorCollection{}
+	for _, id := range p.status.Services {
+		if err := p.services.Resume(id); err != nil && !errors.Is(err, ErrNoSuchInstance) {
+			errs.Add(err)
+		}
+	}
+	if p.status.Paused {
+		log.Info().Msgf("Resumed %d services", len(p.status.Services))
+	}
+
+	p.status = PauseStatus{}
+	return p.status, errs.Errorf("could not resume some services: %s", ", ")
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+)
+
+type mockPausableServices struct {
+	instances map[ID]*Instance
+}
+
+func (m *mockPausableServices) List(_ bool) []*Instance {
+	var list []*Instance
+	for _, instance := range m.instances {
+		list = append(list, instance)
+	}
+	return list
+}
+
+func (m *mockPausableServices) Pause(id ID) error {
+	m.instances[id].state = servicestate.Paused
+	return nil
+}
+
+func (m *mockPausableServices) Resume(id ID) error {
+	instance, ok := m.instances[id]
+	if !ok {
+		return ErrNoSuchInstance
+	}
+	instance.state = servicestate.Running
+	return nil
+}
+
+type mockSessionLister struct {
+	sessions []*Session
+}
+
+func (m *mockSessionLister) GetAll() []*Session {
+	return m.sessions
+}
+
+func newPausableServices() *mockPausableServices {
+	return &mockPausableServices{instances: map[ID]*Instance{
+		"1": {ID: "1", state: servicestate.Running},
+		"2": {ID: "2", state: servicestate.Running},
+		"3": {ID: "3", state: servicestate.Starting},
+	}}
+}
+
+func TestPauser_PauseResume(t *testing.T) {
+	services := newPausableServices()
+	session := &Session{ServiceID: "1", done: make(chan struct{})}
+	pauser := NewPauser(services, &mockSessionLister{sessions: []*Session{session}})
+	now := time.Date(2022, 3, 4, 10, 0, 0, 0, time.UTC)
+	pauser.now = func() time.Time { return now }
+
+	status, err := pauser.Pause(true)
+	assert.NoError(t, err)
+	assert.Equal(t, PauseStatus{Paused: true, Since: now, KeepSessions: true, Services: []ID{"1", "2"}}, status)
+	assert.Equal(t, servicestate.Paused, services.instances["1"].State())
+	assert.Equal(t, servicestate.Starting, services.instances["3"].State())
+	assert.Equal(t, status, pauser.Status())
+	select {
+	case <-session.Done():
+		t.Fatal("session of paused service should be kept")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	_, err = pauser.Pause(true)
+	assert.Equal(t, ErrAlreadyPaused, err)
+
+	delete(services.instances, "2")
+	status, err = pauser.Resume()
+	assert.NoError(t, err)
+	assert.Equal(t, PauseStatus{}, status)
+	assert.Equal(t, servicestate.Running, services.instances["1"].State())
+}
+
+func TestPauser_PauseClosesSessions(t *testing.T) {
+	services := newPausableServices()
+	paused := &Session{ServiceID: "1", done: make(chan struct{})}
+	other := &Session{ServiceID: "3", done: make(chan struct{})}
+	pauser := NewPauser(services, &mockSessionLister{sessions: []*Session{paused, other}})
+
+	_, err := pauser.Pause(false)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		select {
+		case <-paused.Done():
+			return true
+		default:
+			return false
+		}
+	}, time.Second, 10*time.Millisecond)
+	select {
+	case <-other.Done():
+		t.Fatal("session of not paused service should be kept")
+	default:
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/utils"
 )
 
@@ -158,11 +159,24 @@ type Instance struct {
 	service         Service
 	Proposal        market.ServiceProposal
 	policies        *policy.Repository
-	discovery       Discovery
 	eventPublisher  Publisher
 	p2pChannelsLock sync.Mutex
 	p2pChannels     []p2p.Channel
 	location        locationResolver
+	prices          priceProvider
+	load            loadProvider
+	priceBooks      priceBookProvider
+
+	announceLock sync.Mutex
+	announcing   bool
+	discovery    Discovery
+	stopListener func()
+
+	healthLock sync.RWMutex
+	health     Health
+
+	sessionsLock sync.Mutex
+	sessions     map[session.ID]struct{}
 }
 
 // Service returns the running service implementation.
@@ -182,14 +196,80 @@ func (i *Instance) State() servicestate.State {
 	return i.state
 }
 
+// ActiveSessions returns the number of sessions established with the service instance.
+func (i *Instance) ActiveSessions() int {
+	i.sessionsLock.Lock()
+	defer i.sessionsLock.Unlock()
+	return len(i.sessions)
+}
+
+func (i *Instance) addSession(id session.ID) {
+	i.sessionsLock.Lock()
+	defer i.sessionsLock.Unlock()
+
+	if i.sessions == nil {
+		i.sessions = make(map[session.ID]struct{})
+	}
+	i.sessions[id] = struct{}{}
+}
+
+func (i *Instance) removeSession(id session.ID) {
+	i.sessionsLock.Lock()
+	defer i.sessionsLock.Unlock()
+	delete(i.sessions, id)
+}
+
+// Health returns results of the service instance health probes.
+func (i *Instance) Health() Health {
+	i.healthLock.RLock()
+	defer i.healthLock.RUnlock()
+
+	if i.health.Status == "" {
+		health := i.health
+		health.Status = HealthUnknown
+		return health
+	}
+	return i.health
+}
+
+func (i *Instance) setHealth(health Health) {
+	i.healthLock.Lock()
+	defer i.healthLock.Unlock()
+
+	changed := i.health.Status != health.Status
+	i.health = health
+
+	if changed && i.eventPublisher != nil {
+		i.eventPublisher.Publish(servicestate.AppTopicServiceHealth, servicestate.AppEventServiceHealth{
+			ID:         string(i.ID),
+			ProviderID: i.ProviderID.Address,
+			Type:       i.Type,
+			Health:     health.Status,
+			Error:      health.Error,
+		})
+	}
+}
+
 func (i *Instance) proposalWithCurrentLocation() market.ServiceProposal {
 	location, err := i.location.DetectLocation()
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to get current location for proposal, using last known location")
-		return i.Proposal
+	} else {
+		i.Proposal.Location = *market.NewLocation(location)
 	}
 
-	i.Proposal.Location = *market.NewLocation(location)
+	if i.load != nil {
+		load := i.load.Load(string(i.ID))
+		i.Proposal.Load = &load
+	}
+
+	if i.prices != nil {
+		i.Proposal.Price = i.prices.Price(i.Type)
+	}
+
+	if i.priceBooks != nil {
+		i.Proposal.PriceBook = i.priceBooks.PriceBook(i.Type)
+	}
 
 	return i.Proposal
 }
@@ -209,11 +289,43 @@ func (i *Instance) addP2PChannel(ch p2p.Channel) {
 	i.p2pChannels = append(i.p2pChannels, ch)
 }
 
+// p2pChannelList returns p2p channels of the instance consumers.
+func (i *Instance) p2pChannelList() []p2p.Channel {
+	i.p2pChannelsLock.Lock()
+	defer i.p2pChannelsLock.Unlock()
+
+	channels := make([]p2p.Channel, len(i.p2pChannels))
+	copy(channels, i.p2pChannels)
+	return channels
+}
+
+func (i *Instance) setAnnouncement(discovery Discovery, stopListener func()) {
+	i.announceLock.Lock()
+	defer i.announceLock.Unlock()
+
+	i.announcing = true
+	i.discovery = discovery
+	i.stopListener = stopListener
+}
+
+// stopAnnouncing stops accepting new consumers and proposal announcements.
+// Returned discovery can be waited on for the proposal to be unregistered.
+func (i *Instance) stopAnnouncing() Discovery {
+	i.announceLock.Lock()
+	defer i.announceLock.Unlock()
+
+	if !i.announcing {
+		return i.discovery
+	}
+	i.announcing = false
+	i.stopListener()
+	i.discovery.Stop()
+	return i.discovery
+}
+
 func (i *Instance) stop() error {
 	errStop := utils.ErrorCollection{}
-	if i.discovery != nil {
-		i.discovery.Stop()
-	}
+	i.stopAnnouncing()
 	if i.service != nil {
 		errStop.Add(i.service.Stop())
 	}
@@ -0,0 +1,153 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/pb"
+)
+
+// AppTopicPublicIPHandled is the topic to which provider public IP change handling results are published.
+const AppTopicPublicIPHandled = "provider_public_ip_handled"
+
+// AppEventPublicIPHandled describes how services reacted to a public IP change.
+type AppEventPublicIPHandled struct {
+	Previous    string
+	Current     string
+	Reannounced []ID
+	Notified    int
+}
+
+type locationRefresher interface {
+	Refresh() (locationstate.Location, error)
+}
+
+// PublicIPHandler keeps running services reachable when provider public IP changes.
+type PublicIPHandler struct {
+	manager   *Manager
+	location  locationRefresher
+	remap     func()
+	publisher Publisher
+
+	mu sync.Mutex
+	// connected is true while the node itself is connected as a consumer,
+	// public IP then changes to the VPN exit IP which must not be announced.
+	connected bool
+	// announced is the public IP services were last announced with.
+	announced string
+}
+
+// NewPublicIPHandler creates a new public IP change handler. Remap renews NAT port mappings.
+func NewPublicIPHandler(manager *Manager, location locationRefresher, remap func(), publisher Publisher) *PublicIPHandler {
+	return &PublicIPHandler{
+		manager:   manager,
+		location:  location,
+		remap:     remap,
+		publisher: publisher,
+	}
+}
+
+// Subscribe subscribes to public IP change events.
+func (h *PublicIPHandler) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.SubscribeAsync(connectionstate.AppTopicConnectionState, h.handleConnectionState); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(ip.AppTopicPublicIPChanged, h.handle)
+}
+
+func (h *PublicIPHandler) handleConnectionState(e connectionstate.AppEventConnectionState) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch e.State {
+	case connectionstate.Connecting, connectionstate.Connected, connectionstate.Reconnecting:
+		h.connected = true
+	case connectionstate.NotConnected:
+		h.connected = false
+	}
+}
+
+func (h *PublicIPHandler) handle(e ip.PublicIPChanged) {
+	if !h.changed(e) {
+		return
+	}
+	if len(h.manager.servicePool.List()) == 0 {
+		return
+	}
+	log.Info().Msgf("Public IP changed from %s to %s, refreshing services", e.Previous, e.Current)
+
+	if _, err := h.location.Refresh(); err != nil {
+		log.Warn().Err(err).Msg("Failed to refresh location after public IP change")
+	}
+	h.remap()
+
+	result := AppEventPublicIPHandled{
+		Previous:    e.Previous,
+		Current:     e.Current,
+		Reannounced: h.manager.Reannounce(),
+		Notified:    h.notifySessions(e.Current),
+	}
+	h.publisher.Publish(AppTopicPublicIPHandled, result)
+}
+
+// changed checks whether the change affects announced services.
+func (h *PublicIPHandler) changed(e ip.PublicIPChanged) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.announced == "" {
+		h.announced = e.Previous
+	}
+	if h.connected {
+		log.Debug().Msgf("Ignoring public IP change to %s while connected as a consumer", e.Current)
+		return false
+	}
+	if e.Current == h.announced {
+		return false
+	}
+	h.announced = e.Current
+	return true
+}
+
+// notifySessions tells consumers of the running services about the new provider IP.
+func (h *PublicIPHandler) notifySessions(publicIP string) (notified int) {
+	msg := p2p.ProtoMessage(&pb.P2PConnectConfig{PublicIP: publicIP})
+	for _, instance := range h.manager.servicePool.List() {
+		for _, channel := range instance.p2pChannelList() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			_, err := channel.Send(ctx, p2p.TopicSessionProviderIP, msg)
+			cancel()
+			if err != nil {
+				log.Debug().Err(err).Msgf("Failed to notify consumer of service %s about public IP change", instance.ID)
+				continue
+			}
+			notified++
+		}
+	}
+	return notified
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/mocks"
+)
+
+type mockLocationRefresher struct {
+	refreshed int
+}
+
+func (m *mockLocationRefresher) Refresh() (locationstate.Location, error) {
+	m.refreshed++
+	return locationstate.Location{}, nil
+}
+
+func TestPublicIPHandler_ReannouncesRunningServices(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(serviceType, func(options Options) (Service, error) {
+		return &serviceFake{mockProcess: make(chan struct{})}, nil
+	})
+
+	var announced int
+	discoveryFactory := func() Discovery {
+		announced++
+		return &mockDiscovery{}
+	}
+	manager := NewManager(
+		registry,
+		discoveryFactory,
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		&mockP2PListener{}, nil, nil,
+		mockLocationResolver{}, nil,
+		nil, nil, nil,
+	)
+
+	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return manager.Service(id).State() == servicestate.Running
+	}, time.Second, 10*time.Millisecond)
+
+	location := &mockLocationRefresher{}
+	var remapped bool
+	publisher := mocks.NewEventBus()
+	handler := NewPublicIPHandler(manager, location, func() { remapped = true }, publisher)
+
+	handler.handle(ip.PublicIPChanged{Previous: "1.1.1.1", Current: "2.2.2.2"})
+
+	assert.Equal(t, 2, announced)
+	assert.Equal(t, 1, location.refreshed)
+	assert.True(t, remapped)
+	assert.Equal(t, AppEventPublicIPHandled{
+		Previous:    "1.1.1.1",
+		Current:     "2.2.2.2",
+		Reannounced: []ID{id},
+	}, publisher.Pop())
+}
+
+func TestPublicIPHandler_IgnoresChangesWhileConsumerIsConnected(t *testing.T) {
+	handler := NewPublicIPHandler(nil, &mockLocationRefresher{}, func() {}, mocks.NewEventBus())
+
+	handler.handleConnectionState(connectionstate.AppEventConnectionState{State: connectionstate.Connected})
+	assert.False(t, handler.changed(ip.PublicIPChanged{Previous: "1.1.1.1", Current: "9.9.9.9"}))
+
+	// Going back to the announced IP after disconnect is not a change.
+	handler.handleConnectionState(connectionstate.AppEventConnectionState{State: connectionstate.NotConnected})
+	assert.False(t, handler.changed(ip.PublicIPChanged{Previous: "9.9.9.9", Current: "1.1.1.1"}))
+
+	assert.True(t, handler.changed(ip.PublicIPChanged{Previous: "1.1.1.1", Current: "2.2.2.2"}))
+	assert.False(t, handler.changed(ip.PublicIPChanged{Previous: "1.1.1.1", Current: "2.2.2.2"}))
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/pb"
+	sevent "github.com/mysteriumnetwork/node/session/event"
+)
+
+// QoSConfig contains session quality of service reporting options.
+type QoSConfig struct {
+	// ProbeInterval is how often the consumer is pinged to measure RTT and loss.
+	ProbeInterval time.Duration
+	// ProbeTimeout is the time after which a probe is considered lost.
+	ProbeTimeout time.Duration
+	// ReportInterval is how often QoS reports are sent to the consumer.
+	ReportInterval time.Duration
+}
+
+// qosRecorder aggregates probe results and session traffic between reports.
+type qosRecorder struct {
+	mu       sync.Mutex
+	probes   int
+	lost     int
+	rtt      time.Duration
+	sent     uint64
+	received uint64
+
+	reportedAt       time.Time
+	reportedSent     uint64
+	reportedReceived uint64
+}
+
+func newQoSRecorder(now time.Time) *qosRecorder {
+	return &qosRecorder{reportedAt: now}
+}
+
+func (r *qosRecorder) recordProbe(rtt time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.probes++
+	if err != nil {
+		r.lost++
+		return
+	}
+	r.rtt += rtt
+}
+
+// recordTransfer records total bytes of the session from the provider perspective.
+func (r *qosRecorder) recordTransfer(sent, received uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sent, r.received = sent, received
+}
+
+// report returns QoS of the window since the previous report and starts a new one.
+func (r *qosRecorder) report(sessionID string, now time.Time) *pb.SessionQoS {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	qos := &pb.SessionQoS{SessionID: sessionID}
+	if r.probes > 0 {
+		qos.PacketLoss = float64(r.lost) / float64(r.probes)
+	}
+	if answered := r.probes - r.lost; answered > 0 {
+		qos.RttMillis = uint64((r.rtt / time.Duration(answered)).Milliseconds())
+	}
+	if elapsed := now.Sub(r.reportedAt).Seconds(); elapsed > 0 {
+		qos.BytesPerSecondSent = uint64(float64(delta(r.sent, r.reportedSent)) / elapsed)
+		qos.BytesPerSecondReceived = uint64(float64(delta(r.received, r.reportedReceived)) / elapsed)
+	}
+
+	r.probes, r.lost, r.rtt = 0, 0, 0
+	r.reportedAt, r.reportedSent, r.reportedReceived = now, r.sent, r.received
+	return qos
+}
+
+func delta(current, previous uint64) uint64 {
+	if current < previous {
+		return 0
+	}
+	return current - previous
+}
+
+func (manager *SessionManager) qosLoop(sess *Session, channel p2p.Channel) {
+	cfg := manager.config.QoS
+	recorder := newQoSRecorder(manager.clock.Now())
+
+	onTransfer := func(e sevent.AppEventDataTransferred) {
+		if e.ID == string(sess.ID) {
+			recorder.recordTransfer(e.Up, e.Down)
+		}
+	}
+	uid := "qos-" + string(sess.ID)
+	if err := manager.publisher.SubscribeWithUID(sevent.AppTopicDataTransferred, uid, onTransfer); err != nil {
+		log.Err(err).Msgf("Failed to subscribe to session traffic, QoS reports are disabled. SessionID=%s", sess.ID)
+		return
+	}
+	defer manager.publisher.UnsubscribeWithUID(sevent.AppTopicDataTransferred, uid, onTransfer)
+
+	probe := manager.clock.NewTicker(cfg.ProbeInterval)
+	defer probe.Stop()
+	report := manager.clock.NewTicker(cfg.ReportInterval)
+	defer report.Stop()
+
+	for {
+		select {
+		case <-sess.Done():
+			return
+		case <-probe.C():
+			start := manager.clock.Now()
+			err := manager.sendP2P(channel, p2p.TopicKeepAlive, &pb.P2PKeepAlivePing{SessionID: string(sess.ID)}, cfg.ProbeTimeout)
+			recorder.recordProbe(manager.clock.Since(start), err)
+		case <-report.C():
+			qos := recorder.report(string(sess.ID), manager.clock.Now())
+			if err := manager.sendP2P(channel, p2p.TopicSessionQoS, qos, manager.config.KeepAlive.SendTimeout); err != nil {
+				log.Warn().Err(err).Msgf("Failed to send session QoS report. SessionID=%s", sess.ID)
+			}
+		}
+	}
+}
+
+func (manager *SessionManager) sendP2P(channel p2p.Channel, topic string, msg proto.Message, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := channel.Send(ctx, topic, p2p.ProtoMessage(msg))
+	return err
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQoSRecorder_Report(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	recorder := newQoSRecorder(start)
+
+	recorder.recordProbe(10*time.Millisecond, nil)
+	recorder.recordProbe(30*time.Millisecond, nil)
+	recorder.recordProbe(0, errors.New("timeout"))
+	recorder.recordProbe(0, errors.New("timeout"))
+	recorder.recordTransfer(1000, 500)
+
+	qos := recorder.report("session1", start.Add(10*time.Second))
+	assert.Equal(t, "session1", qos.SessionID)
+	assert.Equal(t, 0.5, qos.PacketLoss)
+	assert.Equal(t, uint64(20), qos.RttMillis)
+	assert.Equal(t, uint64(100), qos.BytesPerSecondSent)
+	assert.Equal(t, uint64(50), qos.BytesPerSecondReceived)
+
+	// next window only accounts for traffic since previous report
+	recorder.recordTransfer(3000, 500)
+
+	qos = recorder.report("session1", start.Add(20*time.Second))
+	assert.Equal(t, 0.0, qos.PacketLoss)
+	assert.Equal(t, uint64(0), qos.RttMillis)
+	assert.Equal(t, uint64(200), qos.BytesPerSecondSent)
+	assert.Equal(t, uint64(0), qos.BytesPerSecondReceived)
+}
@@ -0,0 +1,490 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/identity"
+	sevent "github.com/mysteriumnetwork/node/session/event"
+	"github.com/mysteriumnetwork/node/utils/clock"
+)
+
+const windowUsageBucket = "service_window_usage"
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// AccessWindow is a weekly recurring time window during which the service is shared.
+type AccessWindow struct {
+	ServiceType string
+	// Days the window starts on, every day if empty.
+	Days []time.Weekday
+	// From and To are offsets from the local midnight, window ends on the next day if To is not after From.
+	From time.Duration
+	To   time.Duration
+}
+
+// ParseAccessWindow parses window in the "<service type>=[<days>/]<HH:MM>-<HH:MM>" format,
+// where days is a comma separated list of weekdays or their ranges, e.g. "wireguard=mon-fri,sun/00:00-07:00".
+func ParseAccessWindow(s string) (AccessWindow, error) {
+	serviceType, spec, ok := strings.Cut(s, "=")
+	if !ok || serviceType == "" {
+		return AccessWindow{}, fmt.Errorf("access window %q: service type is missing", s)
+	}
+
+	w := AccessWindow{ServiceType: serviceType}
+	if days, hours, ok := strings.Cut(spec, "/"); ok {
+		parsed, err := parseWeekdays(days)
+		if err != nil {
+			return AccessWindow{}, fmt.Errorf("access window %q: %w", s, err)
+		}
+		w.Days = parsed
+		spec = hours
+	}
+
+	from, to, ok := strings.Cut(spec, "-")
+	if !ok {
+		return AccessWindow{}, fmt.Errorf("access window %q: time range is missing", s)
+	}
+	var err error
+	if w.From, err = parseClock(from); err != nil {
+		return AccessWindow{}, fmt.Errorf("access window %q: %w", s, err)
+	}
+	if w.To, err = parseClock(to); err != nil {
+		return AccessWindow{}, fmt.Errorf("access window %q: %w", s, err)
+	}
+	return w, nil
+}
+
+func parseWeekdays(s string) ([]time.Weekday, error) {
+	var res []time.Weekday
+	for _, part := range strings.Split(s, ",") {
+		first, last, isRange := strings.Cut(strings.ToLower(strings.TrimSpace(part)), "-")
+		from, ok := weekdays[first]
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday %q", first)
+		}
+		to := from
+		if isRange {
+			if to, ok = weekdays[last]; !ok {
+				return nil, fmt.Errorf("unknown weekday %q", last)
+			}
+		}
+		for d := from; ; d = (d + 1) % 7 {
+			res = append(res, d)
+			if d == to {
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Occurrence returns bounds of the window occurrence containing the given time.
+func (w AccessWindow) Occurrence(t time.Time) (start, end time.Time, ok bool) {
+	length := w.To - w.From
+	if length <= 0 {
+		length += 24 * time.Hour
+	}
+
+	// Window containing the time may have started on the previous day.
+	for _, day := range []time.Time{t.AddDate(0, 0, -1), t} {
+		if !w.startsOn(day.Weekday()) {
+			continue
+		}
+		start = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, t.Location()).Add(w.From)
+		end = start.Add(length)
+		if !t.Before(start) && t.Before(end) {
+			return start, end, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+func (w AccessWindow) startsOn(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessSchedule is a weekly timetable of service access windows.
+type AccessSchedule []AccessWindow
+
+// ParseAccessSchedule parses schedule from the list of access windows.
+func ParseAccessSchedule(windows []string) (AccessSchedule, error) {
+	var res AccessSchedule
+	for _, s := range windows {
+		w, err := ParseAccessWindow(s)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, w)
+	}
+	return res, nil
+}
+
+// Scheduled checks if the service type is restricted to access windows.
+func (s AccessSchedule) Scheduled(serviceType string) bool {
+	for _, w := range s {
+		if w.ServiceType == serviceType {
+			return true
+		}
+	}
+	return false
+}
+
+// Open returns bounds of the service type window open at the given time.
+func (s AccessSchedule) Open(serviceType string, t time.Time) (start, end time.Time, ok bool) {
+	for _, w := range s {
+		if w.ServiceType != serviceType {
+			continue
+		}
+		if start, end, ok = w.Occurrence(t); ok {
+			return start, end, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// WindowUsage holds utilization of a single access window occurrence.
+type WindowUsage struct {
+	ID          string `storm:"id"`
+	ServiceType string
+	Start       time.Time
+	End         time.Time
+	Sessions    int
+	Up          uint64
+	Down        uint64
+}
+
+type windowUsageStorage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+}
+
+type scheduledServices interface {
+	List(includeAll bool) []*Instance
+	Start(providerID identity.Identity, serviceType string, policyIDs []string, options Options) (ID, error)
+	Drain(id ID) error
+}
+
+// pausedService remembers configuration of the service drained at the window end.
+type pausedService struct {
+	providerID  identity.Identity
+	serviceType string
+	policyIDs   []string
+	options     Options
+}
+
+type windowSession struct {
+	usageID  string
+	up, down uint64
+}
+
+// Scheduler enables and disables services according to the access schedule and records utilization of each window.
+type Scheduler struct {
+	services scheduledServices
+	schedule AccessSchedule
+	storage  windowUsageStorage
+	interval time.Duration
+	clock    clock.Clock
+
+	mu       sync.Mutex
+	paused   map[string]pausedService
+	sessions map[string]*windowSession
+	usage    map[string]*WindowUsage
+	dirty    map[string]bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewScheduler returns a new service scheduler with window usage restored from the storage.
+func NewScheduler(services scheduledServices, schedule AccessSchedule, storage windowUsageStorage, interval time.Duration) *Scheduler {
+	s := &Scheduler{
+		services: services,
+		schedule: schedule,
+		storage:  storage,
+		interval: interval,
+		clock:    clock.System,
+		paused:   make(map[string]pausedService),
+		sessions: make(map[string]*windowSession),
+		usage:    make(map[string]*WindowUsage),
+		dirty:    make(map[string]bool),
+		stop:     make(chan struct{}),
+	}
+
+	var stored []WindowUsage
+	if err := storage.GetAllFrom(windowUsageBucket, &stored); err != nil && !errors.Is(err, storm.ErrNotFound) {
+		log.Warn().Err(err).Msg("Could not restore service window usage")
+	}
+	for i := range stored {
+		s.usage[stored[i].ID] = &stored[i]
+	}
+	return s
+}
+
+// Subscribe subscribes to session events.
+func (s *Scheduler) Subscribe(bus eventSubscriber) error {
+	if err := bus.SubscribeAsync(sevent.AppTopicSession, s.consumeSessionEvent); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(sevent.AppTopicDataTransferred, s.consumeDataTransferredEvent)
+}
+
+// Start starts applying the schedule periodically, it is a noop if no windows are configured.
+func (s *Scheduler) Start() {
+	if len(s.schedule) == 0 {
+		return
+	}
+
+	go func() {
+		ticker := s.clock.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C():
+				s.Check()
+			}
+		}
+	}()
+}
+
+// Stop stops applying the schedule persisting the latest window usage.
+func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		s.flush()
+	})
+}
+
+// Schedule returns configured access windows.
+func (s *Scheduler) Schedule() AccessSchedule {
+	return s.schedule
+}
+
+// Paused returns service types drained until their next window opens.
+func (s *Scheduler) Paused() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := make([]string, 0, len(s.paused))
+	for _, p := range s.paused {
+		res = append(res, p.serviceType)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Usage returns utilization of access windows, latest first.
+func (s *Scheduler) Usage() []WindowUsage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := make([]WindowUsage, 0, len(s.usage))
+	for _, u := range s.usage {
+		res = append(res, *u)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Start.Equal(res[j].Start) {
+			return res[i].ServiceType < res[j].ServiceType
+		}
+		return res[i].Start.After(res[j].Start)
+	})
+	return res
+}
+
+// Check drains running services whose window has ended and starts paused ones whose window has opened.
+func (s *Scheduler) Check() {
+	now := s.clock.Now()
+
+	for _, instance := range s.services.List(false) {
+		if instance.State() != servicestate.Running || !s.schedule.Scheduled(instance.Type) {
+			continue
+		}
+		if start, end, ok := s.schedule.Open(instance.Type, now); ok {
+			s.windowUsage(instance.Type, start, end)
+			continue
+		}
+
+		var policyIDs []string
+		for _, policy := range instance.Policies().Policies() {
+			policyIDs = append(policyIDs, policy.ID)
+		}
+
+		log.Info().Msgf("Access window of %s service %s ended, draining it", instance.Type, instance.ID)
+		if err := s.services.Drain(instance.ID); err != nil {
+			log.Error().Err(err).Msgf("Failed to drain service %s", instance.ID)
+			continue
+		}
+
+		s.mu.Lock()
+		s.paused[pausedKey(instance.ProviderID, instance.Type)] = pausedService{
+			providerID:  instance.ProviderID,
+			serviceType: instance.Type,
+			policyIDs:   policyIDs,
+			options:     instance.Options,
+		}
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	paused := make(map[string]pausedService, len(s.paused))
+	for key, p := range s.paused {
+		paused[key] = p
+	}
+	s.mu.Unlock()
+
+	for key, p := range paused {
+		start, end, ok := s.schedule.Open(p.serviceType, now)
+		if !ok {
+			continue
+		}
+
+		log.Info().Msgf("Access window of %s service opened, starting it", p.serviceType)
+		if _, err := s.services.Start(p.providerID, p.serviceType, p.policyIDs, p.options); err != nil {
+			log.Error().Err(err).Msgf("Failed to start scheduled %s service", p.serviceType)
+			continue
+		}
+		s.windowUsage(p.serviceType, start, end)
+
+		s.mu.Lock()
+		delete(s.paused, key)
+		s.mu.Unlock()
+	}
+
+	s.flush()
+}
+
+func pausedKey(providerID identity.Identity, serviceType string) string {
+	return providerID.Address + "/" + serviceType
+}
+
+// windowUsage returns usage record of the window occurrence, creating it if needed.
+func (s *Scheduler) windowUsage(serviceType string, start, end time.Time) *WindowUsage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.usageOf(serviceType, start, end)
+}
+
+func (s *Scheduler) usageOf(serviceType string, start, end time.Time) *WindowUsage {
+	id := serviceType + "/" + start.UTC().Format(time.RFC3339)
+	u, ok := s.usage[id]
+	if !ok {
+		u = &WindowUsage{ID: id, ServiceType: serviceType, Start: start.UTC(), End: end.UTC()}
+		s.usage[id] = u
+		s.dirty[id] = true
+	}
+	return u
+}
+
+func (s *Scheduler) consumeSessionEvent(e sevent.AppEventSession) {
+	switch e.Status {
+	case sevent.CreatedStatus:
+		serviceType := e.Session.Proposal.ServiceType
+		start, end, ok := s.schedule.Open(serviceType, s.clock.Now())
+		if !ok {
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		u := s.usageOf(serviceType, start, end)
+		u.Sessions++
+		s.sessions[e.Session.ID] = &windowSession{usageID: u.ID}
+		s.dirty[u.ID] = true
+	case sevent.RemovedStatus:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		delete(s.sessions, e.Session.ID)
+	}
+}
+
+// consumeDataTransferredEvent attributes traffic to the window session was started in,
+// including traffic of sessions being drained after the window end.
+func (s *Scheduler) consumeDataTransferredEvent(e sevent.AppEventDataTransferred) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ws, ok := s.sessions[e.ID]
+	if !ok {
+		return
+	}
+	u, ok := s.usage[ws.usageID]
+	if !ok {
+		return
+	}
+
+	u.Up += delta(e.Up, ws.up)
+	u.Down += delta(e.Down, ws.down)
+	ws.up, ws.down = e.Up, e.Down
+	s.dirty[u.ID] = true
+}
+
+func (s *Scheduler) flush() {
+	s.mu.Lock()
+	var changed []WindowUsage
+	for id := range s.dirty {
+		changed = append(changed, *s.usage[id])
+	}
+	s.dirty = make(map[string]bool)
+	s.mu.Unlock()
+
+	for i := range changed {
+		if err := s.storage.Store(windowUsageBucket, &changed[i]); err != nil {
+			log.Warn().Err(err).Msgf("Could not store usage of window %s", changed[i].ID)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	sevent "github.com/mysteriumnetwork/node/session/event"
+	"github.com/mysteriumnetwork/node/utils/clock"
+)
+
+func TestParseAccessWindow(t *testing.T) {
+	w, err := ParseAccessWindow("wireguard=fri-mon,wed/22:30-07:00")
+	require.NoError(t, err)
+	assert.Equal(t, AccessWindow{
+		ServiceType: "wireguard",
+		Days:        []time.Weekday{time.Friday, time.Saturday, time.Sunday, time.Monday, time.Wednesday},
+		From:        22*time.Hour + 30*time.Minute,
+		To:          7 * time.Hour,
+	}, w)
+
+	w, err = ParseAccessWindow("scraping=00:00-07:00")
+	require.NoError(t, err)
+	assert.Equal(t, AccessWindow{ServiceType: "scraping", To: 7 * time.Hour}, w)
+
+	for _, invalid := range []string{"00:00-07:00", "wireguard=", "wireguard=xyz/00:00-07:00", "wireguard=00:00-25:00", "wireguard=mon/00:00"} {
+		_, err := ParseAccessWindow(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestAccessWindow_Occurrence(t *testing.T) {
+	// Window starting on Fridays only, ending on Saturday morning.
+	w := AccessWindow{ServiceType: "wireguard", Days: []time.Weekday{time.Friday}, From: 22 * time.Hour, To: 7 * time.Hour}
+	friday := time.Date(2022, 6, 3, 0, 0, 0, 0, time.UTC)
+
+	_, _, ok := w.Occurrence(friday.Add(21 * time.Hour))
+	assert.False(t, ok)
+
+	start, end, ok := w.Occurrence(friday.Add(23 * time.Hour))
+	assert.True(t, ok)
+	assert.Equal(t, friday.Add(22*time.Hour), start)
+	assert.Equal(t, friday.Add(31*time.Hour), end)
+
+	_, _, ok = w.Occurrence(friday.Add(30 * time.Hour))
+	assert.True(t, ok)
+	_, _, ok = w.Occurrence(friday.Add(31 * time.Hour))
+	assert.False(t, ok)
+	_, _, ok = w.Occurrence(friday.Add(-2 * time.Hour))
+	assert.False(t, ok)
+}
+
+type mockScheduledServices struct {
+	instances map[ID]*Instance
+	drained   []ID
+	started   []string
+}
+
+func (m *mockScheduledServices) List(_ bool) []*Instance {
+	var list []*Instance
+	for _, instance := range m.instances {
+		list = append(list, instance)
+	}
+	return list
+}
+
+func (m *mockScheduledServices) Start(providerID identity.Identity, serviceType string, _ []string, options Options) (ID, error) {
+	m.started = append(m.started, serviceType)
+	id := ID(serviceType + "-started")
+	m.instances[id] = &Instance{ID: id, ProviderID: providerID, Type: serviceType, Options: options, state: servicestate.Running, policies: policy.NewRepository()}
+	return id, nil
+}
+
+func (m *mockScheduledServices) Drain(id ID) error {
+	m.drained = append(m.drained, id)
+	m.instances[id].state = servicestate.Draining
+	return nil
+}
+
+func TestScheduler_DrainsAndStartsServices(t *testing.T) {
+	storage, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	defer storage.Close()
+
+	providerID := identity.FromAddress("0x1")
+	services := &mockScheduledServices{instances: map[ID]*Instance{
+		"wg":     {ID: "wg", ProviderID: providerID, Type: "wireguard", Options: "opts", state: servicestate.Running, policies: policy.NewRepository()},
+		"scrape": {ID: "scrape", ProviderID: providerID, Type: "scraping", state: servicestate.Running, policies: policy.NewRepository()},
+	}}
+	schedule := AccessSchedule{{ServiceType: "wireguard", From: 0, To: 7 * time.Hour}}
+	scheduler := NewScheduler(services, schedule, storage, time.Minute)
+
+	midnight := time.Date(2022, 6, 3, 0, 0, 0, 0, time.UTC)
+	mockClock := clock.NewMock(midnight.Add(time.Hour))
+	scheduler.clock = mockClock
+	scheduler.Check()
+	assert.Empty(t, services.drained)
+
+	session := sevent.SessionContext{ID: "s1", Proposal: market.ServiceProposal{ServiceType: "wireguard"}}
+	scheduler.consumeSessionEvent(sevent.AppEventSession{Status: sevent.CreatedStatus, Session: session})
+	scheduler.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "s1", Up: 100, Down: 10})
+
+	// Window ended, unscheduled services are kept running.
+	mockClock.Set(midnight.Add(8 * time.Hour))
+	scheduler.Check()
+	assert.Equal(t, []ID{"wg"}, services.drained)
+	assert.Equal(t, []string{"wireguard"}, scheduler.Paused())
+
+	// Traffic of draining sessions is attributed to the window they started in.
+	scheduler.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "s1", Up: 150, Down: 20})
+	scheduler.consumeSessionEvent(sevent.AppEventSession{Status: sevent.RemovedStatus, Session: session})
+
+	scheduler.Check()
+	assert.Empty(t, services.started)
+
+	mockClock.Set(midnight.Add(24 * time.Hour))
+	scheduler.Check()
+	assert.Equal(t, []string{"wireguard"}, services.started)
+	assert.Equal(t, Options("opts"), services.instances["wireguard-started"].Options)
+	assert.Empty(t, scheduler.Paused())
+
+	assertUsage := func(usage []WindowUsage) {
+		require.Len(t, usage, 2)
+		assert.Equal(t, midnight.Add(24*time.Hour), usage[0].Start)
+		assert.Equal(t, 0, usage[0].Sessions)
+		assert.Equal(t, midnight, usage[1].Start)
+		assert.Equal(t, midnight.Add(7*time.Hour), usage[1].End)
+		assert.Equal(t, 1, usage[1].Sessions)
+		assert.Equal(t, uint64(150), usage[1].Up)
+		assert.Equal(t, uint64(20), usage[1].Down)
+	}
+	assertUsage(scheduler.Usage())
+
+	scheduler.Stop()
+	restored := NewScheduler(services, schedule, storage, time.Minute)
+	assertUsage(restored.Usage())
+}
+
+func TestScheduler_ChecksOnEveryTick(t *testing.T) {
+	storage, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	defer storage.Close()
+
+	services := &mockScheduledServices{instances: map[ID]*Instance{
+		"wg": {ID: "wg", ProviderID: identity.FromAddress("0x1"), Type: "wireguard", state: servicestate.Running, policies: policy.NewRepository()},
+	}}
+	schedule := AccessSchedule{{ServiceType: "wireguard", From: 0, To: 7 * time.Hour}}
+	scheduler := NewScheduler(services, schedule, storage, time.Minute)
+	mockClock := clock.NewMock(time.Date(2022, 6, 3, 6, 59, 30, 0, time.UTC))
+	scheduler.clock = mockClock
+
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	mockClock.BlockUntil(1)
+	mockClock.Add(time.Minute)
+	assert.Eventually(t, func() bool {
+		return len(scheduler.Paused()) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+}
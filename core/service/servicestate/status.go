@@ -20,6 +20,8 @@ package servicestate
 const (
 	// AppTopicServiceStatus is used in event bus to announce the service status.
 	AppTopicServiceStatus = "Service status"
+	// AppTopicServiceHealth is used in event bus to announce changes of the service health status.
+	AppTopicServiceHealth = "Service health"
 )
 
 // AppEventServiceStatus represents the service event related information
@@ -30,6 +32,15 @@ type AppEventServiceStatus struct {
 	Status     string `json:"status"`
 }
 
+// AppEventServiceHealth represents the service health change
+type AppEventServiceHealth struct {
+	ID         string `json:"id"`
+	ProviderID string `json:"provider_id"`
+	Type       string `json:"type"`
+	Health     string `json:"health"`
+	Error      string `json:"error,omitempty"`
+}
+
 // State represents list of possible service states
 type State string
 
@@ -40,4 +51,8 @@ const (
 	Starting = State("Starting")
 	// Running means that fully established service exists
 	Running = State("Running")
+	// Draining means that service was replaced and waits for its active sessions to end
+	Draining = State("Draining")
+	// Paused means that service does not announce its proposal and does not accept new sessions
+	Paused = State("Paused")
 )
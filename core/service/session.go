@@ -48,6 +48,10 @@ type Session struct {
 	cleanup          []func() error
 	tracer           *trace.Tracer
 	once             sync.Once
+	rekeyLock        sync.Mutex
+	rekeyedAt        time.Time
+	// restored holds traffic accounted before node restart, nil unless session was restored.
+	restored *SessionAccounting
 }
 
 // Close ends session.
@@ -91,6 +95,17 @@ func (s *Session) addCleanup(fn func() error) {
 	}
 }
 
+// resume continues the session restored after node restart.
+func (s *Session) resume(restored RestoredSession) {
+	s.ID = restored.ID
+	s.CreatedAt = restored.CreatedAt
+	s.restored = &restored.Accounting
+	s.addCleanup(func() error {
+		restored.Destroy()
+		return nil
+	})
+}
+
 func (s *Session) toEvent(status event.Status) event.AppEventSession {
 	return event.AppEventSession{
 		Status: status,
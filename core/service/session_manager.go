@@ -30,14 +30,20 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/crash"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/metrics"
 	"github.com/mysteriumnetwork/node/nat/event"
 	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/node/session"
 	sevent "github.com/mysteriumnetwork/node/session/event"
+	"github.com/mysteriumnetwork/node/session/receipt"
+	"github.com/mysteriumnetwork/node/utils/clock"
 	"github.com/mysteriumnetwork/node/utils/reftracker"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
@@ -49,6 +55,25 @@ var (
 	ErrorSessionNotExists = errors.New("session does not exists")
 	// ErrorWrongSessionOwner returned when consumer tries to destroy session that does not belongs to him
 	ErrorWrongSessionOwner = errors.New("wrong session owner")
+	// ErrorServiceFull returned when service reached its concurrent session limit
+	ErrorServiceFull = errors.New("service reached session limit")
+	// ErrorServiceDraining returned when service is being replaced and does not accept new sessions
+	ErrorServiceDraining = errors.New("service is draining")
+	// ErrorServicePaused returned when service is paused and does not accept new sessions
+	ErrorServicePaused = errors.New("service is paused")
+	// ErrorReceiptsNotSupported returned when consumer sends a session receipt to a provider not keeping them
+	ErrorReceiptsNotSupported = errors.New("session receipts are not supported")
+	// ErrorInvalidReceipt returned when session receipt does not match the session
+	ErrorInvalidReceipt = errors.New("receipt does not match the session")
+	// ErrorRekeyNotSupported returned when consumer requests key rotation from a service not supporting it
+	ErrorRekeyNotSupported = errors.New("key rotation is not supported by the service")
+	// ErrorRekeyTooOften returned when consumer requests key rotation sooner than allowed
+	ErrorRekeyTooOften = errors.New("keys were rotated too recently")
+)
+
+var (
+	sessionStartDuration = metrics.NewHistogram("service_session_start_duration_ms", "Duration of provider session setup", time.Minute.Milliseconds(), 3)
+	sessionStartFailures = metrics.NewCounter("service_session_start_failures_total", "Provider sessions which failed to start")
 )
 
 // IDGenerator defines method for session id generation
@@ -68,6 +93,12 @@ type publisher interface {
 	Publish(topic string, data interface{})
 }
 
+type sessionBus interface {
+	publisher
+	SubscribeWithUID(topic, uid string, fn interface{}) error
+	UnsubscribeWithUID(topic, uid string, fn interface{}) error
+}
+
 // KeepAliveConfig contains keep alive options.
 type KeepAliveConfig struct {
 	SendInterval    time.Duration
@@ -78,6 +109,9 @@ type KeepAliveConfig struct {
 // Config contains common configuration options for session manager.
 type Config struct {
 	KeepAlive KeepAliveConfig
+	QoS       QoSConfig
+	// MaxSessions limits concurrent sessions of the service, 0 means unlimited.
+	MaxSessions int
 }
 
 // DefaultConfig returns default params.
@@ -88,6 +122,11 @@ func DefaultConfig() Config {
 			SendTimeout:     5 * time.Second,
 			MaxSendErrCount: 5,
 		},
+		QoS: QoSConfig{
+			ProbeInterval:  2 * time.Second,
+			ProbeTimeout:   time.Second,
+			ReportInterval: 30 * time.Second,
+		},
 	}
 }
 
@@ -108,7 +147,7 @@ type PromiseProcessor interface {
 }
 
 // PaymentEngineFactory creates a new instance of payment engine
-type PaymentEngineFactory func(providerID, consumerID identity.Identity, chainID int64, hermesID common.Address, sessionID string, exchangeChan chan crypto.ExchangeMessage, price market.Price) (PaymentEngine, error)
+type PaymentEngineFactory func(providerID, consumerID identity.Identity, chainID int64, hermesID common.Address, sessionID string, exchangeChan chan crypto.ExchangeMessage, price market.Price, terms market.InvoiceTerms) (PaymentEngine, error)
 
 // PriceValidator allows to validate prices against those in discovery.
 type PriceValidator interface {
@@ -119,9 +158,46 @@ type PriceValidator interface {
 type PaymentEngine interface {
 	Start() error
 	WaitFirstInvoice(time.Duration) error
+	InvoiceTerms() market.InvoiceTerms
 	Stop()
 }
 
+// ConsumerBlocker tells whether a consumer is temporarily not allowed to start sessions.
+type ConsumerBlocker interface {
+	IsBlocked(consumerID identity.Identity) bool
+}
+
+// ConsumerBlocklist tells whether a consumer connecting from the given IP is blocklisted.
+type ConsumerBlocklist interface {
+	Check(consumerID identity.Identity, ip net.IP) error
+}
+
+// ReceiptSigner countersigns session receipts signed by consumers.
+type ReceiptSigner interface {
+	Countersign(r receipt.Receipt) (receipt.Receipt, error)
+}
+
+// Rekeyer is implemented by services able to rotate keys of a running session.
+type Rekeyer interface {
+	Rekey(sessionID string, sessionConfig json.RawMessage) (ServiceConfiguration, error)
+}
+
+// RestoredSessions hands sessions restored after node restart over to reconnecting consumers.
+type RestoredSessions interface {
+	Take(service *Instance, consumerID identity.Identity) (RestoredSession, bool)
+}
+
+// dataTransferSkipper is implemented by payment engines able to leave out traffic accounted before node restart.
+type dataTransferSkipper interface {
+	SkipDataTransferred(up, down uint64)
+}
+
+// minRekeyInterval limits how often consumer may rotate keys of a session.
+const minRekeyInterval = time.Minute
+
+// receiptTimeTolerance is the allowed difference between session times observed by consumer and provider.
+const receiptTimeTolerance = 2 * time.Minute
+
 // NATEventGetter lets us access the last known traversal event
 type NATEventGetter interface {
 	LastEvent() *event.Event
@@ -132,10 +208,15 @@ func NewSessionManager(
 	service *Instance,
 	sessionStorage *SessionPool,
 	paymentEngineFactory PaymentEngineFactory,
-	publisher publisher,
+	publisher sessionBus,
 	channel p2p.Channel,
 	config Config,
 	priceValidator PriceValidator,
+	consumerBlocker ConsumerBlocker,
+	blocklist ConsumerBlocklist,
+	receipts ReceiptSigner,
+	restored RestoredSessions,
+	geoIP location.GeoIP,
 ) *SessionManager {
 	return &SessionManager{
 		service:              service,
@@ -146,6 +227,12 @@ func NewSessionManager(
 		channel:              channel,
 		config:               config,
 		priceValidator:       priceValidator,
+		consumerBlocker:      consumerBlocker,
+		blocklist:            blocklist,
+		receipts:             receipts,
+		restored:             restored,
+		geoIP:                geoIP,
+		clock:                clock.System,
 	}
 }
 
@@ -155,19 +242,50 @@ type SessionManager struct {
 	sessionStorage       *SessionPool
 	paymentEngineFactory PaymentEngineFactory
 	paymentEngineChan    chan crypto.ExchangeMessage
-	publisher            publisher
+	publisher            sessionBus
 	channel              p2p.Channel
 	config               Config
 	priceValidator       PriceValidator
+	consumerBlocker      ConsumerBlocker
+	blocklist            ConsumerBlocklist
+	receipts             ReceiptSigner
+	restored             RestoredSessions
+	geoIP                location.GeoIP
+	clock                clock.Clock
 }
 
 // Start starts a session on the provider side for the given consumer.
 // Multiple sessions per peerID is possible in case different services are used
 func (manager *SessionManager) Start(request *pb.SessionRequest) (_ pb.SessionResponse, err error) {
+	startedAt := manager.clock.Now()
+	defer func() {
+		sessionStartDuration.Record(manager.clock.Since(startedAt).Milliseconds())
+		if err != nil {
+			sessionStartFailures.Inc()
+		}
+	}()
+
+	switch manager.service.State() {
+	case servicestate.Draining:
+		return pb.SessionResponse{}, ErrorServiceDraining
+	case servicestate.Paused:
+		return pb.SessionResponse{}, ErrorServicePaused
+	}
+	if manager.serviceFull() {
+		return pb.SessionResponse{}, ErrorServiceFull
+	}
+
 	session, err := NewSession(manager.service, request, manager.channel.Tracer())
 	if err != nil {
 		return pb.SessionResponse{}, fmt.Errorf("cannot create new session: %w", err)
 	}
+	manager.lookupConsumerASN(session)
+	if manager.restored != nil {
+		if restored, ok := manager.restored.Take(manager.service, session.ConsumerID); ok {
+			log.Info().Msgf("Resuming session %s restored after restart", restored.ID)
+			session.resume(restored)
+		}
+	}
 
 	rt := reftracker.Singleton()
 	chID := "channel:" + manager.channel.ID()
@@ -201,11 +319,26 @@ func (manager *SessionManager) Start(request *pb.SessionRequest) (_ pb.SessionRe
 	if err = manager.startSession(session, prices); err != nil {
 		return pb.SessionResponse{}, err
 	}
-	if err = manager.paymentLoop(session, prices); err != nil {
+	terms, err := manager.paymentLoop(session, prices, market.NewInvoiceTerms(request.GetInvoiceTerms()))
+	if err != nil {
 		return pb.SessionResponse{}, err
 	}
 
-	return manager.providerService(session, manager.channel)
+	return manager.providerService(session, manager.channel, terms)
+}
+
+func (manager *SessionManager) serviceFull() bool {
+	if manager.config.MaxSessions <= 0 {
+		return false
+	}
+
+	var count int
+	for _, s := range manager.sessionStorage.GetAll() {
+		if s.ServiceID == string(manager.service.ID) {
+			count++
+		}
+	}
+	return count >= manager.config.MaxSessions
 }
 
 func (manager *SessionManager) validatePrice(in market.Price, nodeType, country, serviceType string) error {
@@ -216,6 +349,26 @@ func (manager *SessionManager) validatePrice(in market.Price, nodeType, country,
 	return nil
 }
 
+// validateProviderPrice checks that the consumer agreed to the price provider announced in its proposal.
+func validateProviderPrice(in, announced market.Price) error {
+	if in.PricePerHour == nil || in.PricePerGiB == nil ||
+		in.PricePerHour.Cmp(announced.PricePerHour) != 0 || in.PricePerGiB.Cmp(announced.PricePerGiB) != 0 {
+		return fmt.Errorf("consumer asking for price %v, provider sets %v", in, announced)
+	}
+
+	return nil
+}
+
+// validateBookPrice checks that the consumer agreed to the price provider announced in its price book.
+func validateBookPrice(in, book market.Price) error {
+	if in.PricePerHour == nil || in.PricePerGiB == nil ||
+		in.PricePerHour.Cmp(book.PricePerHour) != 0 || in.PricePerGiB.Cmp(book.PricePerGiB) != 0 {
+		return fmt.Errorf("consumer asking for price %v, price book sets %v", in, book)
+	}
+
+	return nil
+}
+
 func (manager *SessionManager) remapPricing(in *pb.Pricing) market.Price {
 	// This prevents panics in case of malicious consumers.
 	if in == nil || in.PerGib == nil || in.PerHour == nil {
@@ -256,12 +409,15 @@ func (manager *SessionManager) startSession(session *Session, prices market.Pric
 	manager.clearStaleSession(session.ConsumerID, manager.service.Type)
 
 	manager.sessionStorage.Add(session)
+	manager.service.addSession(session.ID)
 	session.addCleanup(func() error {
 		manager.sessionStorage.Remove(session.ID)
+		manager.service.removeSession(session.ID)
 		return nil
 	})
 
-	go manager.keepAliveLoop(session, manager.channel)
+	crash.Go("service/keepalive", func() { manager.keepAliveLoop(session, manager.channel) })
+	crash.Go("service/qos", func() { manager.qosLoop(session, manager.channel) })
 
 	return nil
 }
@@ -270,8 +426,68 @@ func (manager *SessionManager) validateSession(session *Session, prices market.P
 	if !manager.service.Policies().IsIdentityAllowed(session.ConsumerID) {
 		return fmt.Errorf("consumer identity is not allowed: %s", session.ConsumerID.Address)
 	}
+	if manager.consumerBlocker.IsBlocked(session.ConsumerID) {
+		return fmt.Errorf("consumer identity is blocked for abuse: %s", session.ConsumerID.Address)
+	}
+	if manager.blocklist != nil {
+		if err := manager.blocklist.Check(session.ConsumerID, manager.peerIP()); err != nil {
+			return err
+		}
+	}
+
+	proposal := manager.service.Proposal
+	if price, ok := proposal.PriceBook.Lookup(proposal.ServiceType, manager.consumerCountry()); ok {
+		return validateBookPrice(prices, price)
+	}
+	if proposal.Price != nil {
+		return validateProviderPrice(prices, *proposal.Price)
+	}
+	return manager.validatePrice(prices, proposal.Location.IPType, proposal.Location.Country, proposal.ServiceType)
+}
+
+// consumerCountry resolves consumer country from its IP with the local GeoIP database for pricing,
+// as the country reported by the consumer can be anything. Empty country matches only country-less price book entries.
+func (manager *SessionManager) consumerCountry() string {
+	ip := manager.peerIP()
+	if manager.geoIP == nil || ip == nil {
+		return ""
+	}
+
+	country, err := manager.geoIP.LookupCountry(ip.String())
+	if err != nil {
+		log.Debug().Err(err).Msg("Could not resolve consumer country")
+		return ""
+	}
+	return country
+}
+
+// lookupConsumerASN resolves consumer autonomous system from the local GeoIP database for session stats.
+// Consumer country is left as reported by the consumer, so its location privacy mode is respected.
+func (manager *SessionManager) lookupConsumerASN(session *Session) {
+	ip := manager.peerIP()
+	if manager.geoIP == nil || ip == nil {
+		return
+	}
+
+	asn, err := manager.geoIP.LookupASN(ip.String())
+	if err != nil {
+		log.Debug().Err(err).Msg("Could not resolve consumer ASN")
+		return
+	}
+	session.ConsumerLocation.ASN = int(asn.Number)
+	session.ConsumerLocation.ISP = asn.Organization
+}
 
-	return manager.validatePrice(prices, manager.service.Proposal.Location.IPType, manager.service.Proposal.Location.Country, manager.service.Proposal.ServiceType)
+// peerIP returns the consumer IP the p2p channel is established with.
+func (manager *SessionManager) peerIP() net.IP {
+	conn := manager.channel.Conn()
+	if conn == nil {
+		return nil
+	}
+	if addr, ok := conn.RemoteAddr().(*net.UDPAddr); ok {
+		return addr.IP
+	}
+	return nil
 }
 
 func (manager *SessionManager) clearStaleSession(consumerID identity.Identity, serviceType string) {
@@ -303,16 +519,85 @@ func (manager *SessionManager) Destroy(consumerID identity.Identity, sessionID s
 	return nil
 }
 
-func (manager *SessionManager) paymentLoop(session *Session, price market.Price) error {
+// Countersign verifies the consumer signed receipt of an active session and signs it as provider.
+func (manager *SessionManager) Countersign(r receipt.Receipt) (receipt.Receipt, error) {
+	if manager.receipts == nil {
+		return receipt.Receipt{}, ErrorReceiptsNotSupported
+	}
+
+	session, found := manager.sessionStorage.Find(r.SessionID)
+	if !found {
+		return receipt.Receipt{}, ErrorSessionNotExists
+	}
+	if session.ConsumerID != identity.FromAddress(r.ConsumerID.Address) {
+		return receipt.Receipt{}, ErrorWrongSessionOwner
+	}
+	if manager.service.ProviderID != identity.FromAddress(r.ProviderID.Address) || session.Proposal.ServiceType != r.ServiceType {
+		return receipt.Receipt{}, ErrorInvalidReceipt
+	}
+
+	now := manager.clock.Now()
+	if r.StartedAt.Before(session.CreatedAt.Add(-receiptTimeTolerance)) ||
+		r.EndedAt.Before(r.StartedAt) ||
+		r.EndedAt.After(now.Add(receiptTimeTolerance)) {
+		return receipt.Receipt{}, ErrorInvalidReceipt
+	}
+
+	return manager.receipts.Countersign(r)
+}
+
+// Rekey rotates keys of the active session on consumer request.
+func (manager *SessionManager) Rekey(consumerID identity.Identity, req session.RekeyRequest) (ServiceConfiguration, error) {
+	rekeyer, ok := manager.service.Service().(Rekeyer)
+	if !ok {
+		return nil, ErrorRekeyNotSupported
+	}
+
+	session, found := manager.sessionStorage.Find(req.SessionID)
+	if !found {
+		return nil, ErrorSessionNotExists
+	}
+	if session.ConsumerID != consumerID {
+		return nil, ErrorWrongSessionOwner
+	}
+
+	session.rekeyLock.Lock()
+	defer session.rekeyLock.Unlock()
+
+	last := session.CreatedAt
+	if session.rekeyedAt.After(last) {
+		last = session.rekeyedAt
+	}
+	if manager.clock.Since(last) < minRekeyInterval {
+		return nil, ErrorRekeyTooOften
+	}
+
+	config, err := rekeyer.Rekey(string(session.ID), req.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	session.rekeyedAt = manager.clock.Now()
+	return config, nil
+}
+
+func (manager *SessionManager) paymentLoop(session *Session, price market.Price, requestedTerms market.InvoiceTerms) (market.InvoiceTerms, error) {
 	trace := session.tracer.StartStage("Provider session create (payment)")
 	defer session.tracer.EndStage(trace)
 
 	log.Info().Msg("Using new payments")
 
 	chainID := config.GetInt64(config.FlagChainID)
-	engine, err := manager.paymentEngineFactory(manager.service.ProviderID, session.ConsumerID, chainID, session.HermesID, string(session.ID), manager.paymentEngineChan, price)
+	engine, err := manager.paymentEngineFactory(manager.service.ProviderID, session.ConsumerID, chainID, session.HermesID, string(session.ID), manager.paymentEngineChan, price, requestedTerms)
 	if err != nil {
-		return err
+		return market.InvoiceTerms{}, err
+	}
+	terms := engine.InvoiceTerms()
+	log.Info().Msgf("Invoicing session %s %s", session.ID, terms)
+
+	// Traffic before restart was invoiced within the previous agreement.
+	if skipper, ok := engine.(dataTransferSkipper); ok && session.restored != nil {
+		skipper.SkipDataTransferred(session.restored.Up, session.restored.Down)
 	}
 
 	// stop the balance tracker once the session is finished
@@ -322,6 +607,7 @@ func (manager *SessionManager) paymentLoop(session *Session, price market.Price)
 	})
 
 	go func() {
+		defer crash.Recover("service/payments")
 		err := engine.Start()
 		if err != nil {
 			log.Error().Err(err).Msg("Payment engine error")
@@ -331,19 +617,51 @@ func (manager *SessionManager) paymentLoop(session *Session, price market.Price)
 
 	log.Info().Msg("Waiting for a first invoice to be paid")
 	if err := engine.WaitFirstInvoice(30 * time.Second); err != nil {
-		return fmt.Errorf("first invoice was not paid: %w", err)
+		return market.InvoiceTerms{}, fmt.Errorf("first invoice was not paid: %w", err)
 	}
 
-	return nil
+	return terms, nil
 }
 
-func (manager *SessionManager) providerService(session *Session, channel p2p.Channel) (pb.SessionResponse, error) {
+func (manager *SessionManager) providerService(session *Session, channel p2p.Channel, terms market.InvoiceTerms) (pb.SessionResponse, error) {
 	trace := session.tracer.StartStage("Provider session create (configure)")
 	defer session.tracer.EndStage(trace)
 
+	config, err := manager.serviceConfig(session, channel)
+	if err != nil {
+		return pb.SessionResponse{}, err
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return pb.SessionResponse{}, fmt.Errorf("cannot pack session %s service config: %w", string(session.ID), err)
+	}
+
+	return pb.SessionResponse{
+		ID:           string(session.ID),
+		PaymentInfo:  "v3",
+		Config:       data,
+		InvoiceTerms: terms.Proto(),
+	}, nil
+}
+
+func (manager *SessionManager) serviceConfig(session *Session, channel p2p.Channel) (ServiceConfiguration, error) {
+	if session.restored != nil {
+		restorer, ok := manager.service.Service().(Restorer)
+		if !ok {
+			return nil, fmt.Errorf("service can not resume session %s", string(session.ID))
+		}
+
+		config, err := restorer.Resume(string(session.ID), session.request.GetConfig(), channel.ServiceConn())
+		if err != nil {
+			return nil, fmt.Errorf("cannot resume session %s: %w", string(session.ID), err)
+		}
+		return config, nil
+	}
+
 	config, err := manager.service.Service().ProvideConfig(string(session.ID), session.request.GetConfig(), channel.ServiceConn())
 	if err != nil {
-		return pb.SessionResponse{}, fmt.Errorf("cannot get provider config for session %s: %w", string(session.ID), err)
+		return nil, fmt.Errorf("cannot get provider config for session %s: %w", string(session.ID), err)
 	}
 
 	if config.SessionDestroyCallback != nil {
@@ -352,17 +670,7 @@ func (manager *SessionManager) providerService(session *Session, channel p2p.Cha
 			return nil
 		})
 	}
-
-	data, err := json.Marshal(config.SessionServiceConfig)
-	if err != nil {
-		return pb.SessionResponse{}, fmt.Errorf("cannot pack session %s service config: %w", string(session.ID), err)
-	}
-
-	return pb.SessionResponse{
-		ID:          string(session.ID),
-		PaymentInfo: "v3",
-		Config:      data,
-	}, nil
+	return config.SessionServiceConfig, nil
 }
 
 func (manager *SessionManager) keepAliveLoop(sess *Session, channel p2p.Channel) {
@@ -383,7 +691,7 @@ func (manager *SessionManager) keepAliveLoop(sess *Session, channel p2p.Channel)
 		select {
 		case <-sess.Done():
 			return
-		case <-time.After(manager.config.KeepAlive.SendInterval):
+		case <-manager.clock.After(manager.config.KeepAlive.SendInterval):
 			if err := manager.sendKeepAlivePing(channel, sess.ID); err != nil {
 				log.Err(err).Msgf("Failed to send p2p keepalive ping. SessionID=%s", sess.ID)
 				errCount++
@@ -406,12 +714,16 @@ func (manager *SessionManager) sendKeepAlivePing(channel p2p.Channel, sessionID
 		SessionID: string(sessionID),
 	}
 
-	start := time.Now()
+	start := manager.clock.Now()
 	_, err := channel.Send(ctx, p2p.TopicKeepAlive, p2p.ProtoMessage(msg))
+	if err != nil {
+		return err
+	}
+
 	manager.publisher.Publish(quality.AppTopicProviderPingP2P, quality.PingEvent{
 		SessionID: string(sessionID),
-		Duration:  time.Since(start),
+		Duration:  manager.clock.Since(start),
 	})
 
-	return err
+	return nil
 }
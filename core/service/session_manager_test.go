@@ -19,6 +19,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -28,7 +29,9 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
 	"github.com/mysteriumnetwork/node/identity"
@@ -36,8 +39,10 @@ import (
 	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/pb"
+	sessionpkg "github.com/mysteriumnetwork/node/session"
 	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/trace"
+	"github.com/mysteriumnetwork/node/utils/clock"
 	"github.com/mysteriumnetwork/node/utils/reftracker"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
@@ -75,12 +80,18 @@ func (m mockBalanceTracker) WaitFirstInvoice(time.Duration) error {
 	return m.firstPaymentError
 }
 
+func (m mockBalanceTracker) InvoiceTerms() market.InvoiceTerms {
+	return market.InvoiceTerms{}
+}
+
 type mockP2PChannel struct {
-	tracer *trace.Tracer
+	tracer  *trace.Tracer
+	sendErr error
+	conn    *net.UDPConn
 }
 
 func (m *mockP2PChannel) Send(_ context.Context, _ string, _ *p2p.Message) (*p2p.Message, error) {
-	return nil, nil
+	return nil, m.sendErr
 }
 
 func (m *mockP2PChannel) Handle(topic string, handler p2p.HandlerFunc) {
@@ -92,7 +103,7 @@ func (m *mockP2PChannel) Tracer() *trace.Tracer {
 
 func (m *mockP2PChannel) ServiceConn() *net.UDPConn { return nil }
 
-func (m *mockP2PChannel) Conn() *net.UDPConn { return nil }
+func (m *mockP2PChannel) Conn() *net.UDPConn { return m.conn }
 
 func (m *mockP2PChannel) Close() error { return nil }
 
@@ -306,12 +317,148 @@ func TestManager_AcknowledgeSession_PublishesEvent(t *testing.T) {
 	}, 2*time.Second, 10*time.Millisecond)
 }
 
-func newManager(service *Instance, sessions *SessionPool, publisher publisher, paymentEngine PaymentEngine, isPriceValid bool) *SessionManager {
+type mockRekeyService struct {
+	mockService
+	rekeyed []string
+}
+
+func (mr *mockRekeyService) Rekey(sessionID string, _ json.RawMessage) (ServiceConfiguration, error) {
+	mr.rekeyed = append(mr.rekeyed, sessionID)
+	return "rekeyed", nil
+}
+
+func TestManager_Rekey(t *testing.T) {
+	svc := &mockRekeyService{}
+	instance := NewInstance(
+		identity.FromAddress(currentProposal.ProviderID),
+		currentProposal.ServiceType,
+		struct{}{},
+		currentProposal,
+		servicestate.Running,
+		svc,
+		policy.NewRepository(),
+		&mockDiscovery{},
+	)
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	session, _ := NewSession(
+		instance,
+		&pb.SessionRequest{Consumer: &pb.ConsumerInfo{Id: consumerID.Address}},
+		trace.NewTracer(""),
+	)
+	session.CreatedAt = time.Now().Add(-2 * minRekeyInterval)
+	sessionStore.Add(session)
+	manager := newManager(instance, sessionStore, publisher, &mockBalanceTracker{}, true)
+
+	_, err := manager.Rekey(identity.FromAddress("some other id"), sessionpkg.RekeyRequest{SessionID: session.ID})
+	assert.Exactly(t, ErrorWrongSessionOwner, err)
+
+	config, err := manager.Rekey(consumerID, sessionpkg.RekeyRequest{SessionID: session.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, "rekeyed", config)
+	assert.Equal(t, []string{string(session.ID)}, svc.rekeyed)
+
+	_, err = manager.Rekey(consumerID, sessionpkg.RekeyRequest{SessionID: session.ID})
+	assert.Exactly(t, ErrorRekeyTooOften, err)
+}
+
+func TestManager_Rekey_AllowedAfterInterval(t *testing.T) {
+	svc := &mockRekeyService{}
+	instance := NewInstance(
+		identity.FromAddress(currentProposal.ProviderID),
+		currentProposal.ServiceType,
+		struct{}{},
+		currentProposal,
+		servicestate.Running,
+		svc,
+		policy.NewRepository(),
+		&mockDiscovery{},
+	)
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	session, _ := NewSession(
+		instance,
+		&pb.SessionRequest{Consumer: &pb.ConsumerInfo{Id: consumerID.Address}},
+		trace.NewTracer(""),
+	)
+	sessionStore.Add(session)
+	manager := newManager(instance, sessionStore, publisher, &mockBalanceTracker{}, true)
+	mockClock := clock.NewMock(session.CreatedAt)
+	manager.clock = mockClock
+
+	_, err := manager.Rekey(consumerID, sessionpkg.RekeyRequest{SessionID: session.ID})
+	assert.Exactly(t, ErrorRekeyTooOften, err)
+
+	mockClock.Add(minRekeyInterval)
+	_, err = manager.Rekey(consumerID, sessionpkg.RekeyRequest{SessionID: session.ID})
+	assert.NoError(t, err)
+
+	mockClock.Add(minRekeyInterval - time.Second)
+	_, err = manager.Rekey(consumerID, sessionpkg.RekeyRequest{SessionID: session.ID})
+	assert.Exactly(t, ErrorRekeyTooOften, err)
+
+	mockClock.Add(time.Second)
+	_, err = manager.Rekey(consumerID, sessionpkg.RekeyRequest{SessionID: session.ID})
+	assert.NoError(t, err)
+	assert.Len(t, svc.rekeyed, 2)
+}
+
+func TestManager_KeepAlive_ClosesSessionAfterMaxSendErrors(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	manager := newManager(currentService, sessionStore, publisher, &mockBalanceTracker{}, true)
+	mockClock := clock.NewMock(time.Now())
+	manager.clock = mockClock
+	session, _ := NewSession(
+		currentService,
+		&pb.SessionRequest{Consumer: &pb.ConsumerInfo{Id: consumerID.Address}},
+		trace.NewTracer(""),
+	)
+
+	done := make(chan struct{})
+	go func() {
+		manager.keepAliveLoop(session, &mockP2PChannel{sendErr: errors.New("ping lost")})
+		close(done)
+	}()
+
+	for i := 1; i < manager.config.KeepAlive.MaxSendErrCount; i++ {
+		mockClock.BlockUntil(1)
+		mockClock.Add(manager.config.KeepAlive.SendInterval)
+	}
+	mockClock.BlockUntil(1)
+	select {
+	case <-session.Done():
+		t.Fatal("session closed before max keepalive errors")
+	default:
+	}
+
+	mockClock.Add(manager.config.KeepAlive.SendInterval)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("keepalive loop did not stop")
+	}
+	select {
+	case <-session.Done():
+	default:
+		t.Fatal("session not closed after max keepalive errors")
+	}
+}
+
+func TestManager_Rekey_NotSupported(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	manager := newManager(currentService, NewSessionPool(publisher), publisher, &mockBalanceTracker{}, true)
+
+	_, err := manager.Rekey(consumerID, sessionpkg.RekeyRequest{SessionID: "unknown"})
+	assert.Exactly(t, ErrorRekeyNotSupported, err)
+}
+
+func newManager(service *Instance, sessions *SessionPool, publisher sessionBus, paymentEngine PaymentEngine, isPriceValid bool) *SessionManager {
 	ch := &mockP2PChannel{tracer: trace.NewTracer("Provider connect")}
 	m := NewSessionManager(
 		service,
 		sessions,
-		func(_, _ identity.Identity, _ int64, _ common.Address, _ string, _ chan crypto.ExchangeMessage, price market.Price) (PaymentEngine, error) {
+		func(_, _ identity.Identity, _ int64, _ common.Address, _ string, _ chan crypto.ExchangeMessage, price market.Price, _ market.InvoiceTerms) (PaymentEngine, error) {
 			return paymentEngine, nil
 		},
 		publisher,
@@ -320,6 +467,11 @@ func newManager(service *Instance, sessions *SessionPool, publisher publisher, p
 		&mockPriceValidator{
 			toReturn: isPriceValid,
 		},
+		&mockConsumerBlocker{},
+		nil,
+		nil,
+		nil,
+		nil,
 	)
 	reftracker.Singleton().Put("channel:"+ch.ID(), 10*time.Second, func() { ch.Close() })
 	return m
@@ -345,6 +497,173 @@ func TestManager_Start_RejectsInvalidPricing(t *testing.T) {
 	assert.Equal(t, "consumer asking for invalid price", err.Error())
 }
 
+func TestManager_Start_RejectsPriceOtherThanProviders(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	priced := currentProposal
+	priced.Price = market.NewPrice(2, 2)
+	service := NewInstance(identity.FromAddress(priced.ProviderID), priced.ServiceType, struct{}{}, priced, servicestate.Running, &mockService{}, policy.NewRepository(), &mockDiscovery{})
+	manager := newManager(service, sessionStore, publisher, &mockBalanceTracker{}, true)
+
+	_, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+			Pricing: &pb.Pricing{
+				PerGib:  big.NewInt(1).Bytes(),
+				PerHour: big.NewInt(1).Bytes(),
+			},
+		},
+		ProposalID: int64(currentProposalID),
+	})
+	assert.EqualError(t, err, "consumer asking for price 1/h, 1/GiB , provider sets 2/h, 2/GiB ")
+}
+
+func TestManager_Start_ValidatesPriceBookPrice(t *testing.T) {
+	proposal := market.NewProposal("0x1", "mockservice", market.NewProposalOpts{})
+	proposal.PriceBook = &market.PriceBook{Entries: []market.PriceBookEntry{
+		{Country: "DE", Price: *market.NewPrice(5, 10)},
+	}}
+	service := NewInstance(
+		identity.FromAddress(proposal.ProviderID),
+		proposal.ServiceType,
+		struct{}{},
+		proposal,
+		servicestate.Running,
+		&mockService{},
+		policy.NewRepository(),
+		&mockDiscovery{},
+	)
+	request := func(perHour, perGiB int64) *pb.SessionRequest {
+		return &pb.SessionRequest{
+			Consumer: &pb.ConsumerInfo{
+				Id:       consumerID.Address,
+				HermesID: hermesID.String(),
+				Pricing: &pb.Pricing{
+					PerGib:  big.NewInt(perGiB).Bytes(),
+					PerHour: big.NewInt(perHour).Bytes(),
+				},
+				Location: &pb.LocationInfo{Country: "DE"},
+			},
+			ProposalID: int64(proposal.ID),
+		}
+	}
+
+	conn, err := net.DialUDP("udp4", nil, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 9})
+	require.NoError(t, err)
+	defer conn.Close()
+	newBookManager := func(country string, priceValid bool) *SessionManager {
+		publisher := mocks.NewEventBus()
+		manager := newManager(service, NewSessionPool(publisher), publisher, &mockBalanceTracker{}, priceValid)
+		manager.channel.(*mockP2PChannel).conn = conn
+		manager.geoIP = &mockGeoIP{country: country}
+		return manager
+	}
+
+	// Network price validator would accept any price, book price takes precedence.
+	_, err = newBookManager("DE", true).Start(request(1, 1))
+	assert.Error(t, err)
+
+	_, err = newBookManager("DE", false).Start(request(5, 10))
+	assert.NoError(t, err)
+
+	// Consumer claiming to be in the listed country is priced by the country of its IP.
+	_, err = newBookManager("US", false).Start(request(5, 10))
+	assert.Error(t, err)
+}
+
+func TestManager_Start_RejectsBlockedConsumer(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	manager := newManager(currentService, sessionStore, publisher, &mockBalanceTracker{}, true)
+	manager.consumerBlocker = &mockConsumerBlocker{blocked: true}
+
+	_, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+			Pricing: &pb.Pricing{
+				PerGib:  big.NewInt(1).Bytes(),
+				PerHour: big.NewInt(1).Bytes(),
+			},
+		},
+		ProposalID: int64(currentProposalID),
+	})
+	assert.EqualError(t, err, "consumer identity is blocked for abuse: "+consumerID.Address)
+}
+
+func TestManager_Start_RejectsBlocklistedConsumer(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	manager := newManager(currentService, sessionStore, publisher, &mockBalanceTracker{}, true)
+	manager.blocklist = &mockConsumerBlocklist{err: errors.New("consumer is blocklisted")}
+
+	_, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+			Pricing: &pb.Pricing{
+				PerGib:  big.NewInt(1).Bytes(),
+				PerHour: big.NewInt(1).Bytes(),
+			},
+		},
+		ProposalID: int64(currentProposalID),
+	})
+	assert.EqualError(t, err, "consumer is blocklisted")
+}
+
+func TestManager_LookupConsumerASN(t *testing.T) {
+	conn, err := net.DialUDP("udp4", nil, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 9})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	publisher := mocks.NewEventBus()
+	manager := newManager(currentService, NewSessionPool(publisher), publisher, &mockBalanceTracker{}, true)
+	manager.channel.(*mockP2PChannel).conn = conn
+	geoIP := &mockGeoIP{asn: location.ASN{Number: 64500, Organization: "Example ISP"}}
+	manager.geoIP = geoIP
+
+	session := &Session{ConsumerLocation: market.Location{Country: "LT"}}
+	manager.lookupConsumerASN(session)
+
+	assert.Equal(t, "127.0.0.1", geoIP.ip)
+	assert.Equal(t, market.Location{Country: "LT", ASN: 64500, ISP: "Example ISP"}, session.ConsumerLocation)
+}
+
+type mockGeoIP struct {
+	country string
+	asn     location.ASN
+	ip      string
+}
+
+func (m *mockGeoIP) LookupCountry(string) (string, error) {
+	if m.country == "" {
+		return "", errors.New("country not found")
+	}
+	return m.country, nil
+}
+
+func (m *mockGeoIP) LookupASN(ip string) (location.ASN, error) {
+	m.ip = ip
+	return m.asn, nil
+}
+
+type mockConsumerBlocklist struct {
+	err error
+}
+
+func (mcb *mockConsumerBlocklist) Check(_ identity.Identity, _ net.IP) error {
+	return mcb.err
+}
+
+type mockConsumerBlocker struct {
+	blocked bool
+}
+
+func (mcb *mockConsumerBlocker) IsBlocked(_ identity.Identity) bool {
+	return mcb.blocked
+}
+
 type mockPriceValidator struct {
 	toReturn bool
 }
@@ -352,3 +671,50 @@ type mockPriceValidator struct {
 func (mpv *mockPriceValidator) IsPriceValid(in market.Price, nodeType, country, ServiceType string) bool {
 	return mpv.toReturn
 }
+
+func TestManager_Start_RejectsWhenServiceFull(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	session, _ := NewSession(
+		currentService,
+		&pb.SessionRequest{Consumer: &pb.ConsumerInfo{Id: consumerID.Address}},
+		trace.NewTracer(""),
+	)
+	sessionStore.Add(session)
+
+	manager := newManager(currentService, sessionStore, publisher, &mockBalanceTracker{}, true)
+	manager.config.MaxSessions = 1
+
+	_, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+		},
+		ProposalID: int64(currentProposalID),
+	})
+	assert.Equal(t, ErrorServiceFull, err)
+}
+
+func TestManager_Start_RejectsWhenServicePaused(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	service := NewInstance(
+		identity.FromAddress(currentProposal.ProviderID),
+		currentProposal.ServiceType,
+		struct{}{},
+		currentProposal,
+		servicestate.Paused,
+		&mockService{},
+		policy.NewRepository(),
+		&mockDiscovery{},
+	)
+	manager := newManager(service, NewSessionPool(publisher), publisher, &mockBalanceTracker{}, true)
+
+	_, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+		},
+		ProposalID: int64(currentProposalID),
+	})
+	assert.Equal(t, ErrorServicePaused, err)
+}
@@ -21,10 +21,13 @@ import (
 	"sync"
 
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/metrics"
 	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/event"
 )
 
+var activeSessions = metrics.NewGauge("service_sessions_active", "Provider sessions currently served")
+
 // NewSessionPool initiates new session storage
 func NewSessionPool(publisher publisher) *SessionPool {
 	sm := &SessionPool{
@@ -49,6 +52,7 @@ func (sp *SessionPool) Add(instance *Session) {
 	defer sp.lock.Unlock()
 
 	sp.sessions[instance.ID] = instance
+	activeSessions.Set(float64(len(sp.sessions)))
 	sp.publisher.Publish(event.AppTopicSession, instance.toEvent(event.CreatedStatus))
 }
 
@@ -108,6 +112,7 @@ func (sp *SessionPool) Remove(id session.ID) {
 
 	if instance, found := sp.sessions[id]; found {
 		delete(sp.sessions, id)
+		activeSessions.Set(float64(len(sp.sessions)))
 		go sp.publisher.Publish(event.AppTopicSession, instance.toEvent(event.RemovedStatus))
 	}
 }
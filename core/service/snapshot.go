@@ -0,0 +1,342 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session"
+	sevent "github.com/mysteriumnetwork/node/session/event"
+	"github.com/mysteriumnetwork/node/utils/clock"
+)
+
+const sessionSnapshotBucket = "session_snapshots"
+
+// maxRestoreAttempts limits how many node starts try to restore the snapshot before it is dropped.
+const maxRestoreAttempts = 3
+
+// Restorer is implemented by services able to keep session tunnels running while the node restarts.
+type Restorer interface {
+	// Detach ends the session leaving its tunnel running and returns the state required to restore it.
+	Detach(sessionID string) (json.RawMessage, error)
+	// Restore takes over the tunnel of the session detached before restart.
+	Restore(sessionID string, state json.RawMessage) (DestroyCallback, error)
+	// Resume hands the restored tunnel over to the reconnected consumer.
+	Resume(sessionID string, sessionConfig json.RawMessage, conn *net.UDPConn) (ServiceConfiguration, error)
+}
+
+// SessionAccounting holds session traffic as reported in data transferred events.
+type SessionAccounting struct {
+	Up   uint64
+	Down uint64
+}
+
+// SessionSnapshot is the provider session state persisted while the node restarts.
+type SessionSnapshot struct {
+	ID               session.ID `storm:"id"`
+	ServiceType      string
+	ProviderID       identity.Identity
+	ConsumerID       identity.Identity
+	ConsumerLocation market.Location
+	HermesID         common.Address
+	CreatedAt        time.Time
+	SavedAt          time.Time
+	Accounting       SessionAccounting
+	State            json.RawMessage
+	RestoreAttempts  int
+}
+
+// RestoredSession is the session restored after node restart, waiting for its consumer to reconnect.
+type RestoredSession struct {
+	SessionSnapshot
+	Destroy DestroyCallback
+}
+
+type snapshotStorage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+	Delete(bucket string, data interface{}) error
+}
+
+type instanceFinder interface {
+	Service(id ID) *Instance
+}
+
+type restoredSession struct {
+	RestoredSession
+	instance *Instance
+	claimed  chan struct{}
+}
+
+// SessionSnapshots keeps tunnels of restorable services running across node restarts.
+// Sessions are snapshotted on shutdown, their tunnels are restored once the service starts again
+// and handed over to reconnecting consumers. Tunnels not claimed within the grace period are destroyed.
+type SessionSnapshots struct {
+	storage  snapshotStorage
+	sessions *SessionPool
+	services instanceFinder
+	grace    time.Duration
+	clock    clock.Clock
+
+	mu         sync.Mutex
+	accounting map[session.ID]SessionAccounting
+	pending    []SessionSnapshot
+	restored   map[session.ID]*restoredSession
+}
+
+// NewSessionSnapshots returns session snapshots with the ones saved on the last shutdown pending restore.
+// Snapshots are removed from the storage once restored, failed restores are retried on the next start.
+func NewSessionSnapshots(storage snapshotStorage, sessions *SessionPool, services instanceFinder, grace time.Duration) *SessionSnapshots {
+	k := &SessionSnapshots{
+		storage:    storage,
+		sessions:   sessions,
+		services:   services,
+		grace:      grace,
+		clock:      clock.System,
+		accounting: make(map[session.ID]SessionAccounting),
+		restored:   make(map[session.ID]*restoredSession),
+	}
+
+	if err := storage.GetAllFrom(sessionSnapshotBucket, &k.pending); err != nil && !errors.Is(err, storm.ErrNotFound) {
+		log.Warn().Err(err).Msg("Could not load session snapshots")
+	}
+	return k
+}
+
+// Pending returns snapshots waiting for their services to start.
+func (k *SessionSnapshots) Pending() []SessionSnapshot {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return append([]SessionSnapshot(nil), k.pending...)
+}
+
+// Subscribe subscribes to session and service events.
+func (k *SessionSnapshots) Subscribe(bus eventSubscriber) error {
+	if err := bus.SubscribeAsync(sevent.AppTopicDataTransferred, k.consumeDataTransferredEvent); err != nil {
+		return err
+	}
+	if err := bus.SubscribeAsync(sevent.AppTopicSession, k.consumeSessionEvent); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(servicestate.AppTopicServiceStatus, k.consumeServiceStatusEvent)
+}
+
+func (k *SessionSnapshots) consumeDataTransferredEvent(e sevent.AppEventDataTransferred) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.accounting[session.ID(e.ID)] = SessionAccounting{Up: e.Up, Down: e.Down}
+}
+
+func (k *SessionSnapshots) consumeSessionEvent(e sevent.AppEventSession) {
+	if e.Status != sevent.RemovedStatus {
+		return
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	delete(k.accounting, session.ID(e.Session.ID))
+}
+
+func (k *SessionSnapshots) consumeServiceStatusEvent(e servicestate.AppEventServiceStatus) {
+	if e.Status != string(servicestate.Running) {
+		return
+	}
+
+	if instance := k.services.Service(ID(e.ID)); instance != nil {
+		k.restore(instance)
+	}
+}
+
+func (k *SessionSnapshots) restore(instance *Instance) {
+	restorer, ok := instance.Service().(Restorer)
+	if !ok {
+		return
+	}
+
+	k.mu.Lock()
+	var snapshots []SessionSnapshot
+	pending := k.pending[:0]
+	for _, snapshot := range k.pending {
+		if snapshot.ProviderID == instance.ProviderID && snapshot.ServiceType == instance.Type {
+			snapshots = append(snapshots, snapshot)
+		} else {
+			pending = append(pending, snapshot)
+		}
+	}
+	k.pending = pending
+	k.mu.Unlock()
+
+	for _, snapshot := range snapshots {
+		destroy, err := restorer.Restore(string(snapshot.ID), snapshot.State)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Could not restore session %s", snapshot.ID)
+			k.restoreFailed(snapshot)
+			continue
+		}
+		if err := k.storage.Delete(sessionSnapshotBucket, &snapshot); err != nil {
+			log.Warn().Err(err).Msgf("Could not delete snapshot of session %s", snapshot.ID)
+		}
+
+		r := &restoredSession{
+			RestoredSession: RestoredSession{SessionSnapshot: snapshot, Destroy: destroy},
+			instance:        instance,
+			claimed:         make(chan struct{}),
+		}
+		k.mu.Lock()
+		k.restored[snapshot.ID] = r
+		k.accounting[snapshot.ID] = snapshot.Accounting
+		k.mu.Unlock()
+
+		log.Info().Msgf("Session %s of %s consumer restored, waiting for it to reconnect", snapshot.ID, snapshot.ConsumerID.Address)
+		go k.expire(r)
+	}
+}
+
+// restoreFailed keeps the snapshot to be restored on the next start, unless it failed too many times already.
+func (k *SessionSnapshots) restoreFailed(snapshot SessionSnapshot) {
+	snapshot.RestoreAttempts++
+	if snapshot.RestoreAttempts >= maxRestoreAttempts {
+		log.Warn().Msgf("Session %s failed to restore %d times, dropping its snapshot", snapshot.ID, snapshot.RestoreAttempts)
+		if err := k.storage.Delete(sessionSnapshotBucket, &snapshot); err != nil {
+			log.Warn().Err(err).Msgf("Could not delete snapshot of session %s", snapshot.ID)
+		}
+		return
+	}
+
+	if err := k.storage.Store(sessionSnapshotBucket, &snapshot); err != nil {
+		log.Error().Err(err).Msgf("Could not save snapshot of session %s", snapshot.ID)
+	}
+}
+
+func (k *SessionSnapshots) expire(r *restoredSession) {
+	select {
+	case <-r.claimed:
+	case <-k.clock.After(k.grace):
+		if k.claim(r.ID) != nil {
+			log.Info().Msgf("Consumer of restored session %s did not reconnect, destroying it", r.ID)
+			r.Destroy()
+
+			k.mu.Lock()
+			delete(k.accounting, r.ID)
+			k.mu.Unlock()
+		}
+	}
+}
+
+func (k *SessionSnapshots) claim(id session.ID) *restoredSession {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	r, ok := k.restored[id]
+	if !ok {
+		return nil
+	}
+	delete(k.restored, id)
+	close(r.claimed)
+	return r
+}
+
+// Take hands the restored session of the service over to the reconnected consumer.
+func (k *SessionSnapshots) Take(service *Instance, consumerID identity.Identity) (RestoredSession, bool) {
+	k.mu.Lock()
+	var id session.ID
+	for _, r := range k.restored {
+		if r.instance == service && r.ConsumerID == consumerID {
+			id = r.ID
+			break
+		}
+	}
+	k.mu.Unlock()
+
+	if r := k.claim(id); r != nil {
+		return r.RestoredSession, true
+	}
+	return RestoredSession{}, false
+}
+
+// Save detaches sessions of restorable services and persists their snapshots, so session tunnels outlive the node shutdown.
+func (k *SessionSnapshots) Save() {
+	for _, s := range k.sessions.GetAll() {
+		if instance := k.services.Service(ID(s.ServiceID)); instance != nil {
+			k.save(instance, SessionSnapshot{
+				ID:               s.ID,
+				ConsumerID:       s.ConsumerID,
+				ConsumerLocation: s.ConsumerLocation,
+				HermesID:         s.HermesID,
+				CreatedAt:        s.CreatedAt,
+			})
+		}
+	}
+
+	// Restored sessions whose consumers did not reconnect yet are kept for the next start too.
+	k.mu.Lock()
+	ids := make([]session.ID, 0, len(k.restored))
+	for id := range k.restored {
+		ids = append(ids, id)
+	}
+	k.mu.Unlock()
+	for _, id := range ids {
+		if r := k.claim(id); r != nil {
+			k.save(r.instance, r.SessionSnapshot)
+		}
+	}
+}
+
+func (k *SessionSnapshots) save(instance *Instance, snapshot SessionSnapshot) {
+	restorer, ok := instance.Service().(Restorer)
+	if !ok {
+		return
+	}
+
+	k.mu.Lock()
+	if accounting, ok := k.accounting[snapshot.ID]; ok {
+		snapshot.Accounting = accounting
+	}
+	k.mu.Unlock()
+
+	state, err := restorer.Detach(string(snapshot.ID))
+	if err != nil {
+		log.Warn().Err(err).Msgf("Session %s will not be restored", snapshot.ID)
+		return
+	}
+
+	snapshot.ServiceType = instance.Type
+	snapshot.ProviderID = instance.ProviderID
+	snapshot.State = state
+	snapshot.SavedAt = k.clock.Now().UTC()
+	snapshot.RestoreAttempts = 0
+	if err := k.storage.Store(sessionSnapshotBucket, &snapshot); err != nil {
+		log.Error().Err(err).Msgf("Could not save snapshot of session %s", snapshot.ID)
+		return
+	}
+	log.Info().Msgf("Session %s saved to be restored after restart", snapshot.ID)
+}
@@ -0,0 +1,270 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/pb"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	"github.com/mysteriumnetwork/node/trace"
+	"github.com/mysteriumnetwork/node/utils/clock"
+)
+
+type mockRestorerService struct {
+	mockService
+	mu        sync.Mutex
+	detached  []string
+	restored  map[string]json.RawMessage
+	resumed   []string
+	destroyed []string
+	failing   bool
+}
+
+func (mr *mockRestorerService) Detach(sessionID string) (json.RawMessage, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	mr.detached = append(mr.detached, sessionID)
+	return json.RawMessage(`{"iface":"myst0"}`), nil
+}
+
+func (mr *mockRestorerService) Restore(sessionID string, state json.RawMessage) (DestroyCallback, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	if mr.restored == nil {
+		mr.restored = make(map[string]json.RawMessage)
+	}
+	mr.restored[sessionID] = state
+	if mr.failing {
+		return nil, errors.New("interface is gone")
+	}
+	return func() {
+		mr.mu.Lock()
+		defer mr.mu.Unlock()
+		mr.destroyed = append(mr.destroyed, sessionID)
+	}, nil
+}
+
+func (mr *mockRestorerService) Resume(sessionID string, _ json.RawMessage, _ *net.UDPConn) (ServiceConfiguration, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	mr.resumed = append(mr.resumed, sessionID)
+	return "resumed", nil
+}
+
+func (mr *mockRestorerService) destroyedSessions() []string {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	return append([]string(nil), mr.destroyed...)
+}
+
+type mockInstanceFinder map[ID]*Instance
+
+func (f mockInstanceFinder) Service(id ID) *Instance {
+	return f[id]
+}
+
+func newRestorableInstance(id ID, svc Service) *Instance {
+	instance := NewInstance(
+		identity.FromAddress(currentProposal.ProviderID),
+		currentProposal.ServiceType,
+		struct{}{},
+		currentProposal,
+		servicestate.Running,
+		svc,
+		policy.NewRepository(),
+		&mockDiscovery{},
+	)
+	instance.ID = id
+	return instance
+}
+
+func TestSessionSnapshots_RestoresSavedSessions(t *testing.T) {
+	storage, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	defer storage.Close()
+
+	// Node before restart.
+	svc := &mockRestorerService{}
+	instance := newRestorableInstance("service-1", svc)
+	sessions := NewSessionPool(mocks.NewEventBus())
+	session, err := NewSession(instance, &pb.SessionRequest{Consumer: &pb.ConsumerInfo{Id: consumerID.Address}}, trace.NewTracer(""))
+	require.NoError(t, err)
+	sessions.Add(session)
+
+	snapshots := NewSessionSnapshots(storage, sessions, mockInstanceFinder{instance.ID: instance}, time.Minute)
+	snapshots.consumeDataTransferredEvent(sessionEvent.AppEventDataTransferred{ID: string(session.ID), Up: 10, Down: 20})
+	snapshots.Save()
+	assert.Equal(t, []string{string(session.ID)}, svc.detached)
+
+	// Node after restart.
+	restartedSvc := &mockRestorerService{}
+	restarted := newRestorableInstance("service-2", restartedSvc)
+	snapshots = NewSessionSnapshots(storage, NewSessionPool(mocks.NewEventBus()), mockInstanceFinder{restarted.ID: restarted}, time.Minute)
+	snapshots.clock = clock.NewMock(time.Now())
+
+	snapshots.consumeServiceStatusEvent(servicestate.AppEventServiceStatus{ID: string(restarted.ID), Status: string(servicestate.Running)})
+	assert.JSONEq(t, `{"iface":"myst0"}`, string(restartedSvc.restored[string(session.ID)]))
+
+	_, ok := snapshots.Take(restarted, identity.FromAddress("0x2"))
+	assert.False(t, ok)
+
+	restored, ok := snapshots.Take(restarted, consumerID)
+	require.True(t, ok)
+	assert.Equal(t, session.ID, restored.ID)
+	assert.True(t, session.CreatedAt.Equal(restored.CreatedAt))
+	assert.Equal(t, SessionAccounting{Up: 10, Down: 20}, restored.Accounting)
+
+	_, ok = snapshots.Take(restarted, consumerID)
+	assert.False(t, ok)
+
+	snapshots = NewSessionSnapshots(storage, NewSessionPool(mocks.NewEventBus()), mockInstanceFinder{}, time.Minute)
+	assert.Empty(t, snapshots.pending, "snapshots must be restored at most once")
+}
+
+func TestSessionSnapshots_DestroysUnclaimedSessions(t *testing.T) {
+	storage, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	defer storage.Close()
+	require.NoError(t, storage.Store(sessionSnapshotBucket, &SessionSnapshot{
+		ID:          "session-1",
+		ServiceType: currentProposal.ServiceType,
+		ProviderID:  identity.FromAddress(currentProposal.ProviderID),
+		ConsumerID:  consumerID,
+		State:       json.RawMessage(`{}`),
+	}))
+
+	svc := &mockRestorerService{}
+	instance := newRestorableInstance("service-1", svc)
+	snapshots := NewSessionSnapshots(storage, NewSessionPool(mocks.NewEventBus()), mockInstanceFinder{instance.ID: instance}, time.Minute)
+	mockClock := clock.NewMock(time.Now())
+	snapshots.clock = mockClock
+
+	snapshots.consumeServiceStatusEvent(servicestate.AppEventServiceStatus{ID: string(instance.ID), Status: string(servicestate.Running)})
+	mockClock.BlockUntil(1)
+	assert.Empty(t, svc.destroyedSessions())
+
+	mockClock.Add(time.Minute)
+	assert.Eventually(t, func() bool {
+		return len(svc.destroyedSessions()) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	_, ok := snapshots.Take(instance, consumerID)
+	assert.False(t, ok)
+}
+
+func TestSessionSnapshots_RetriesFailedRestores(t *testing.T) {
+	storage, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	defer storage.Close()
+	require.NoError(t, storage.Store(sessionSnapshotBucket, &SessionSnapshot{
+		ID:          "session-1",
+		ServiceType: currentProposal.ServiceType,
+		ProviderID:  identity.FromAddress(currentProposal.ProviderID),
+		ConsumerID:  consumerID,
+		State:       json.RawMessage(`{}`),
+	}))
+
+	for attempt := 1; attempt <= maxRestoreAttempts; attempt++ {
+		instance := newRestorableInstance("service-1", &mockRestorerService{failing: true})
+		snapshots := NewSessionSnapshots(storage, NewSessionPool(mocks.NewEventBus()), mockInstanceFinder{instance.ID: instance}, time.Minute)
+		require.Len(t, snapshots.Pending(), 1, "failed snapshot must be kept for the next start")
+		assert.Equal(t, attempt-1, snapshots.Pending()[0].RestoreAttempts)
+
+		snapshots.consumeServiceStatusEvent(servicestate.AppEventServiceStatus{ID: string(instance.ID), Status: string(servicestate.Running)})
+		assert.Empty(t, snapshots.Pending())
+	}
+
+	snapshots := NewSessionSnapshots(storage, NewSessionPool(mocks.NewEventBus()), mockInstanceFinder{}, time.Minute)
+	assert.Empty(t, snapshots.Pending(), "snapshot must be dropped after too many failed restores")
+}
+
+type mockRestoredSessions struct {
+	restored RestoredSession
+}
+
+func (m *mockRestoredSessions) Take(_ *Instance, id identity.Identity) (RestoredSession, bool) {
+	return m.restored, id == m.restored.ConsumerID
+}
+
+type mockSkippingEngine struct {
+	mockBalanceTracker
+	skipped SessionAccounting
+}
+
+func (m *mockSkippingEngine) SkipDataTransferred(up, down uint64) {
+	m.skipped = SessionAccounting{Up: up, Down: down}
+}
+
+func TestManager_Start_ResumesRestoredSession(t *testing.T) {
+	svc := &mockRestorerService{}
+	instance := newRestorableInstance("service-1", svc)
+	createdAt := time.Now().Add(-time.Hour).UTC()
+	destroyed := false
+	restored := &mockRestoredSessions{restored: RestoredSession{
+		SessionSnapshot: SessionSnapshot{
+			ID:         "session-1",
+			ConsumerID: consumerID,
+			CreatedAt:  createdAt,
+			Accounting: SessionAccounting{Up: 10, Down: 20},
+		},
+		Destroy: func() { destroyed = true },
+	}}
+	engine := &mockSkippingEngine{}
+	publisher := mocks.NewEventBus()
+	sessions := NewSessionPool(publisher)
+	manager := newManager(instance, sessions, publisher, engine, true)
+	manager.restored = restored
+
+	res, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+			Pricing: &pb.Pricing{
+				PerGib:  big.NewInt(1).Bytes(),
+				PerHour: big.NewInt(1).Bytes(),
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "session-1", res.ID)
+	assert.Equal(t, `"resumed"`, string(res.Config))
+	assert.Equal(t, []string{"session-1"}, svc.resumed)
+	assert.Equal(t, SessionAccounting{Up: 10, Down: 20}, engine.skipped)
+
+	session, ok := sessions.Find("session-1")
+	require.True(t, ok)
+	assert.Equal(t, createdAt, session.CreatedAt)
+
+	session.Close()
+	assert.True(t, destroyed)
+}
@@ -78,3 +78,11 @@ func MockDiscoveryFactoryFunc(ds Discovery) DiscoveryFactory {
 		return ds
 	}
 }
+
+type exclusiveServiceFake struct {
+	serviceFake
+}
+
+func (service *exclusiveServiceFake) Exclusive() bool {
+	return true
+}
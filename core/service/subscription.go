@@ -18,6 +18,7 @@
 package service
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"time"
@@ -25,7 +26,9 @@ import (
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/connectivity"
+	"github.com/mysteriumnetwork/node/session/receipt"
 	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/rs/zerolog/log"
 )
@@ -111,6 +114,56 @@ func subscribeSessionDestroy(mng *SessionManager, ch p2p.ChannelHandler) {
 	})
 }
 
+func subscribeSessionRekey(mng *SessionManager, ch p2p.ChannelHandler) {
+	ch.Handle(p2p.TopicSessionRekey, func(c p2p.Context) error {
+		var req session.RekeyRequest
+		if err := json.Unmarshal(c.Request().Data, &req); err != nil {
+			return err
+		}
+
+		log.Debug().Msgf("Received P2P message for %q: %s", p2p.TopicSessionRekey, req.SessionID)
+
+		config, err := mng.Rekey(c.PeerID(), req)
+		if err != nil {
+			return fmt.Errorf("cannot rotate keys of session %s: %w", req.SessionID, err)
+		}
+
+		data, err := json.Marshal(config)
+		if err != nil {
+			return err
+		}
+		return c.OkWithReply(&p2p.Message{Data: data})
+	})
+}
+
+func subscribeSessionReceipt(mng *SessionManager, ch p2p.ChannelHandler) {
+	ch.Handle(p2p.TopicSessionReceipt, func(c p2p.Context) error {
+		r, err := receipt.DecodeMessage(c.Request())
+		if err != nil {
+			return err
+		}
+		if identity.FromAddress(r.ConsumerID.Address) != c.PeerID() {
+			return fmt.Errorf("wrong consumer identity in session receipt. Expected: %s, got: %s",
+				c.PeerID().ToCommonAddress(),
+				identity.FromAddress(r.ConsumerID.Address),
+			)
+		}
+
+		log.Debug().Msgf("Received P2P message for %q: %s", p2p.TopicSessionReceipt, r.SessionID)
+
+		signed, err := mng.Countersign(r)
+		if err != nil {
+			return fmt.Errorf("cannot countersign receipt of session %s: %w", r.SessionID, err)
+		}
+
+		msg, err := receipt.EncodeMessage(signed)
+		if err != nil {
+			return err
+		}
+		return c.OkWithReply(msg)
+	})
+}
+
 func subscribeSessionAcknowledge(mng *SessionManager, ch p2p.ChannelHandler) {
 	ch.Handle(p2p.TopicSessionAcknowledge, func(c p2p.Context) error {
 		var si pb.SessionInfo
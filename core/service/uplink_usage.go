@@ -0,0 +1,244 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/rs/zerolog/log"
+
+	sevent "github.com/mysteriumnetwork/node/session/event"
+)
+
+const uplinkUsageBucket = "uplink_usage"
+
+// UnknownUplink is used when session uplink interface could not be resolved.
+const UnknownUplink = "unknown"
+
+// uplinkResolveInterval is how often the uplink of a running session is resolved again,
+// so that traffic is attributed to the interface it goes through after routes change.
+const uplinkResolveInterval = 30 * time.Second
+
+// UplinkResolver returns name of the network interface consumer traffic of the service type is sent through.
+type UplinkResolver func(serviceType string) (string, error)
+
+type uplinkUsageStorage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+}
+
+// UplinkUsage holds bytes transferred through a network interface.
+type UplinkUsage struct {
+	Interface string `storm:"id"`
+	Up        uint64
+	Down      uint64
+	Sessions  int
+	UpdatedAt time.Time
+}
+
+type sessionUplink struct {
+	serviceType string
+	iface       string
+	resolvedAt  time.Time
+	up, down    uint64
+}
+
+// UplinkUsageTracker attributes bytes transferred by provider sessions to the uplink interface they are served through.
+// The uplink is resolved periodically while the session runs, so traffic after a failover goes to the new interface.
+type UplinkUsageTracker struct {
+	resolve       UplinkResolver
+	storage       uplinkUsageStorage
+	flushInterval time.Duration
+	now           func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*sessionUplink
+	usage    map[string]*UplinkUsage
+	dirty    map[string]bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewUplinkUsageTracker returns a new uplink usage tracker with totals restored from the storage.
+func NewUplinkUsageTracker(resolve UplinkResolver, storage uplinkUsageStorage, flushInterval time.Duration) *UplinkUsageTracker {
+	t := &UplinkUsageTracker{
+		resolve:       resolve,
+		storage:       storage,
+		flushInterval: flushInterval,
+		now:           time.Now,
+		sessions:      make(map[string]*sessionUplink),
+		usage:         make(map[string]*UplinkUsage),
+		dirty:         make(map[string]bool),
+		stop:          make(chan struct{}),
+	}
+
+	var stored []UplinkUsage
+	if err := storage.GetAllFrom(uplinkUsageBucket, &stored); err != nil && !errors.Is(err, storm.ErrNotFound) {
+		log.Warn().Err(err).Msg("Could not restore uplink usage")
+	}
+	for i := range stored {
+		t.usage[stored[i].Interface] = &stored[i]
+	}
+	return t
+}
+
+// Subscribe subscribes to session events.
+func (t *UplinkUsageTracker) Subscribe(bus eventSubscriber) error {
+	if err := bus.SubscribeAsync(sevent.AppTopicSession, t.consumeSessionEvent); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(sevent.AppTopicDataTransferred, t.consumeDataTransferredEvent)
+}
+
+// Start periodically persists usage totals.
+func (t *UplinkUsageTracker) Start() {
+	go func() {
+		ticker := time.NewTicker(t.flushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-t.stop:
+				return
+			case <-ticker.C:
+				t.flush()
+			}
+		}
+	}()
+}
+
+// Stop stops the tracker persisting the latest totals.
+func (t *UplinkUsageTracker) Stop() {
+	t.stopOnce.Do(func() {
+		close(t.stop)
+		t.flush()
+	})
+}
+
+// Usage returns totals per uplink interface.
+func (t *UplinkUsageTracker) Usage() []UplinkUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	res := make([]UplinkUsage, 0, len(t.usage))
+	for _, u := range t.usage {
+		res = append(res, *u)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Interface < res[j].Interface
+	})
+	return res
+}
+
+func (t *UplinkUsageTracker) consumeSessionEvent(e sevent.AppEventSession) {
+	switch e.Status {
+	case sevent.CreatedStatus:
+		iface := t.uplink(e.Session.ID, e.Session.Proposal.ServiceType)
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		t.sessions[e.Session.ID] = &sessionUplink{
+			serviceType: e.Session.Proposal.ServiceType,
+			iface:       iface,
+			resolvedAt:  t.now(),
+		}
+		t.usageOf(iface).Sessions++
+		t.dirty[iface] = true
+	case sevent.RemovedStatus:
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		delete(t.sessions, e.Session.ID)
+	}
+}
+
+func (t *UplinkUsageTracker) consumeDataTransferredEvent(e sevent.AppEventDataTransferred) {
+	t.mu.Lock()
+	s, ok := t.sessions[e.ID]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	serviceType, stale := s.serviceType, t.now().Sub(s.resolvedAt) >= uplinkResolveInterval
+	t.mu.Unlock()
+
+	var iface string
+	if stale {
+		iface = t.uplink(e.ID, serviceType)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if s, ok = t.sessions[e.ID]; !ok {
+		return
+	}
+	if stale {
+		if iface != s.iface {
+			log.Info().Msgf("Uplink of session %s changed from %s to %s", e.ID, s.iface, iface)
+		}
+		s.iface, s.resolvedAt = iface, t.now()
+	}
+
+	u := t.usageOf(s.iface)
+	u.Up += delta(e.Up, s.up)
+	u.Down += delta(e.Down, s.down)
+	u.UpdatedAt = t.now().UTC()
+	s.up, s.down = e.Up, e.Down
+	t.dirty[s.iface] = true
+}
+
+func (t *UplinkUsageTracker) uplink(sessionID, serviceType string) string {
+	iface, err := t.resolve(serviceType)
+	if err != nil || iface == "" {
+		log.Warn().Err(err).Msgf("Could not resolve uplink of session %s", sessionID)
+		return UnknownUplink
+	}
+	return iface
+}
+
+func (t *UplinkUsageTracker) usageOf(iface string) *UplinkUsage {
+	u, ok := t.usage[iface]
+	if !ok {
+		u = &UplinkUsage{Interface: iface}
+		t.usage[iface] = u
+	}
+	return u
+}
+
+func (t *UplinkUsageTracker) flush() {
+	t.mu.Lock()
+	var changed []UplinkUsage
+	for iface := range t.dirty {
+		changed = append(changed, *t.usage[iface])
+	}
+	t.dirty = make(map[string]bool)
+	t.mu.Unlock()
+
+	for i := range changed {
+		if err := t.storage.Store(uplinkUsageBucket, &changed[i]); err != nil {
+			log.Warn().Err(err).Msgf("Could not store uplink usage of %s", changed[i].Interface)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	sevent "github.com/mysteriumnetwork/node/session/event"
+)
+
+func TestUplinkUsageTracker_AttributesTrafficToUplink(t *testing.T) {
+	storage, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	defer storage.Close()
+
+	uplink, uplinkErr := "eth0", error(nil)
+	tracker := NewUplinkUsageTracker(func(string) (string, error) { return uplink, uplinkErr }, storage, time.Minute)
+
+	created := func(sessionID string) sevent.AppEventSession {
+		return sevent.AppEventSession{Status: sevent.CreatedStatus, Session: sevent.SessionContext{ID: sessionID}}
+	}
+	tracker.consumeSessionEvent(created("s1"))
+	uplink = "wwan0"
+	tracker.consumeSessionEvent(created("s2"))
+	uplinkErr = errors.New("no route")
+	tracker.consumeSessionEvent(created("s3"))
+
+	tracker.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "s1", Up: 100, Down: 10})
+	tracker.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "s1", Up: 150, Down: 30})
+	tracker.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "s2", Up: 40, Down: 4})
+	tracker.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "s3", Up: 7, Down: 1})
+	tracker.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "untracked", Up: 1000, Down: 1000})
+
+	tracker.consumeSessionEvent(sevent.AppEventSession{Status: sevent.RemovedStatus, Session: sevent.SessionContext{ID: "s1"}})
+	tracker.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "s1", Up: 500, Down: 500})
+
+	assertTotals := func(usage []UplinkUsage) {
+		require.Len(t, usage, 3)
+		assert.Equal(t, "eth0", usage[0].Interface)
+		assert.Equal(t, uint64(150), usage[0].Up)
+		assert.Equal(t, uint64(30), usage[0].Down)
+		assert.Equal(t, 1, usage[0].Sessions)
+		assert.Equal(t, UnknownUplink, usage[1].Interface)
+		assert.Equal(t, uint64(7), usage[1].Up)
+		assert.Equal(t, "wwan0", usage[2].Interface)
+		assert.Equal(t, uint64(40), usage[2].Up)
+		assert.Equal(t, uint64(4), usage[2].Down)
+	}
+	assertTotals(tracker.Usage())
+
+	tracker.Stop()
+	restored := NewUplinkUsageTracker(func(string) (string, error) { return "eth0", nil }, storage, time.Minute)
+	assertTotals(restored.Usage())
+}
+
+func TestUplinkUsageTracker_FollowsUplinkChanges(t *testing.T) {
+	storage, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	defer storage.Close()
+
+	uplink := "eth0"
+	tracker := NewUplinkUsageTracker(func(string) (string, error) { return uplink, nil }, storage, time.Minute)
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+
+	tracker.consumeSessionEvent(sevent.AppEventSession{Status: sevent.CreatedStatus, Session: sevent.SessionContext{ID: "s1"}})
+	tracker.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "s1", Up: 100, Down: 10})
+
+	// failover is picked up once the resolved uplink gets stale
+	uplink = "wwan0"
+	tracker.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "s1", Up: 150, Down: 20})
+	now = now.Add(uplinkResolveInterval)
+	tracker.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "s1", Up: 400, Down: 50})
+
+	usage := tracker.Usage()
+	require.Len(t, usage, 2)
+	assert.Equal(t, UplinkUsage{Interface: "eth0", Up: 150, Down: 20, Sessions: 1, UpdatedAt: now.Add(-uplinkResolveInterval).UTC()}, usage[0])
+	assert.Equal(t, UplinkUsage{Interface: "wwan0", Up: 250, Down: 30, UpdatedAt: now.UTC()}, usage[1])
+}
@@ -63,10 +63,11 @@ type Identity struct {
 
 // Connection represents consumer connection state.
 type Connection struct {
-	Session    connectionstate.Status
-	Statistics connectionstate.Statistics
-	Throughput bandwidth.Throughput
-	Invoice    crypto.Invoice
+	Session     connectionstate.Status
+	Statistics  connectionstate.Statistics
+	Throughput  bandwidth.Throughput
+	Invoice     crypto.Invoice
+	ProviderQoS connectionstate.QoS
 }
 
 func (c Connection) String() string {
@@ -195,6 +195,9 @@ func (k *Keeper) Subscribe(bus eventbus.Subscriber) error {
 	if err := bus.SubscribeAsync(bandwidth.AppTopicConnectionThroughput, k.consumeConnectionThroughputEvent); err != nil {
 		return err
 	}
+	if err := bus.SubscribeAsync(connectionstate.AppTopicConnectionQoS, k.updateConnectionQoS); err != nil {
+		return err
+	}
 	if err := bus.SubscribeAsync(pingpongEvent.AppTopicInvoicePaid, k.consumeConnectionSpendingEvent); err != nil {
 		return err
 	}
@@ -434,6 +437,20 @@ func (k *Keeper) updateConnectionThroughput(e interface{}) {
 	go k.announceStateChanges(nil)
 }
 
+func (k *Keeper) updateConnectionQoS(e connectionstate.AppEventConnectionQoS) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	conn, ok := k.state.Connections[string(e.SessionInfo.SessionID)]
+	if !ok {
+		return
+	}
+	conn.ProviderQoS = e.QoS
+	k.state.Connections[string(e.SessionInfo.SessionID)] = conn
+
+	go k.announceStateChanges(nil)
+}
+
 func (k *Keeper) updateConnectionSpending(e interface{}) {
 	k.lock.Lock()
 	defer k.lock.Unlock()
@@ -0,0 +1,219 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"os"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
+	"github.com/pkg/errors"
+	"go.etcd.io/bbolt"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+)
+
+// Verify checks the consistency of database pages.
+func (b *Bolt) Verify() error {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+
+	return b.db.Bolt.View(func(tx *bbolt.Tx) error {
+		var errs []string
+		for err := range tx.Check() {
+			errs = append(errs, err.Error())
+		}
+		if len(errs) > 0 {
+			return errors.Errorf("database is inconsistent: %v", errs)
+		}
+		return nil
+	})
+}
+
+// Compact rewrites the database into a new file without free pages and replaces the original.
+func (b *Bolt) Compact() error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	compacted := b.path + ".compact"
+	defer os.Remove(compacted)
+
+	dst, err := bbolt.Open(compacted, 0600, &bbolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return errors.Wrap(err, "failed to create compacted database")
+	}
+	err = b.db.Bolt.View(func(src *bbolt.Tx) error {
+		return dst.Update(func(tx *bbolt.Tx) error {
+			return src.ForEach(func(name []byte, bucket *bbolt.Bucket) error {
+				target, err := tx.CreateBucket(name)
+				if err != nil {
+					return err
+				}
+				return copyBucket(target, bucket)
+			})
+		})
+	})
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to compact database")
+	}
+
+	if err := b.db.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(compacted, b.path); err != nil {
+		// The original database is left intact, keep using it.
+		db, oerr := storm.Open(b.path, b.options...)
+		if oerr != nil {
+			return errors.Wrapf(oerr, "failed to reopen boltDB after failing to replace it: %v", err)
+		}
+		b.db = db
+		return errors.Wrap(err, "failed to replace database")
+	}
+
+	b.db, err = storm.Open(b.path, b.options...)
+	return errors.Wrap(err, "failed to reopen boltDB")
+}
+
+// stormMetadataBucket is the nested bucket where storm keeps the codec name and counters of a bucket.
+const stormMetadataBucket = "__storm_metadata"
+
+// Rewrite replaces raw records of all buckets, including storm indexes, with the ones returned by fn
+// and records the storage codec in storm bucket metadata. It returns the number of replaced records.
+func (b *Bolt) Rewrite(fn storage.RewriteFunc) (int, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	var count int
+	err := b.db.Bolt.Update(func(tx *bbolt.Tx) error {
+		return tx.ForEach(func(_ []byte, bucket *bbolt.Bucket) error {
+			n, err := b.rewriteBucket(bucket, fn)
+			count += n
+			return err
+		})
+	})
+	return count, errors.Wrap(err, "failed to rewrite boltDB records")
+}
+
+func (b *Bolt) rewriteBucket(bucket *bbolt.Bucket, fn storage.RewriteFunc) (int, error) {
+	type record struct{ oldKey, key, value []byte }
+	var records []record
+	var nested [][]byte
+
+	// Records are collected first, bbolt does not allow modifying a bucket while iterating it.
+	err := bucket.ForEach(func(k, v []byte) error {
+		if v == nil {
+			nested = append(nested, append([]byte{}, k...))
+			return nil
+		}
+
+		key, value, ok, err := fn(k, v)
+		if ok {
+			records = append(records, record{
+				oldKey: append([]byte{}, k...),
+				key:    append([]byte{}, key...),
+				value:  append([]byte{}, value...),
+			})
+		}
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, r := range records {
+		if err := bucket.Delete(r.oldKey); err != nil {
+			return 0, err
+		}
+		if err := bucket.Put(r.key, r.value); err != nil {
+			return 0, err
+		}
+	}
+
+	count := len(records)
+	for _, name := range nested {
+		if string(name) == stormMetadataBucket {
+			if err := bucket.Bucket(name).Put([]byte("codec"), []byte(b.db.Codec().Name())); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		n, err := b.rewriteBucket(bucket.Bucket(name), fn)
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+	return count, nil
+}
+
+// DeleteBefore removes records of the bucket with the time field value before the given time
+// and returns the number of removed records.
+func (b *Bolt) DeleteBefore(bucket, field string, before time.Time, kind interface{}) (int, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	matcher := q.Lt(field, before)
+	count, err := b.db.From(bucket).Select(matcher).Count(kind)
+	if errors.Is(err, storm.ErrNotFound) || count == 0 {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	err = b.db.From(bucket).Select(matcher).Delete(kind)
+	if errors.Is(err, storm.ErrNotFound) {
+		return 0, nil
+	}
+	return count, err
+}
+
+// Size returns the size of the database file in bytes.
+func (b *Bolt) Size() (int64, error) {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+
+	info, err := os.Stat(b.path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func copyBucket(dst, src *bbolt.Bucket) error {
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+
+	return src.ForEach(func(k, v []byte) error {
+		if v != nil {
+			return dst.Put(k, v)
+		}
+
+		nested, err := dst.CreateBucket(k)
+		if err != nil {
+			return err
+		}
+		return copyBucket(nested, src.Bucket(k))
+	})
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_StorageCompact(t *testing.T) {
+	storage, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	for i := int64(1); i <= 1000; i++ {
+		assert.NoError(t, storage.Store(bucket, &myTestType{ID: i}))
+	}
+	for i := int64(1); i < 1000; i++ {
+		assert.NoError(t, storage.Delete(bucket, &myTestType{ID: i}))
+	}
+	assert.NoError(t, storage.SetValue("values", "key", "value"))
+
+	before, err := os.Stat(storage.path)
+	assert.NoError(t, err)
+
+	assert.NoError(t, storage.Compact())
+	assert.NoError(t, storage.Verify())
+
+	after, err := os.Stat(storage.path)
+	assert.NoError(t, err)
+	assert.Less(t, after.Size(), before.Size())
+
+	var result []myTestType
+	assert.NoError(t, storage.GetAllFrom(bucket, &result))
+	assert.Equal(t, []myTestType{{ID: 1000}}, result)
+
+	var value string
+	assert.NoError(t, storage.GetValue("values", "key", &value))
+	assert.Equal(t, "value", value)
+}
+
+func Test_StorageDeleteBefore(t *testing.T) {
+	storage, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	type record struct {
+		ID int `storm:"id,increment"`
+		At time.Time
+	}
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, storage.Store("records", &record{At: now.Add(-time.Duration(i) * time.Hour)}))
+	}
+
+	count, err := storage.DeleteBefore("records", "At", now.Add(-150*time.Minute), &record{})
+	assert.NoError(t, err)
+	assert.Equal(t, 7, count)
+
+	var result []record
+	assert.NoError(t, storage.GetAllFrom("records", &result))
+	assert.Len(t, result, 3)
+
+	count, err = storage.DeleteBefore("missing", "At", now, &record{})
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	size, err := storage.Size()
+	assert.NoError(t, err)
+	assert.Positive(t, size)
+}
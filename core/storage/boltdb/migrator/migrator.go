@@ -86,6 +86,21 @@ func (m *Migrator) sortMigrations(sequence []migrations.Migration) []migrations.
 	return sequence
 }
 
+// Pending returns migrations of the given sequence which are not applied yet, in the order they would run.
+func (m *Migrator) Pending(sequence []migrations.Migration) ([]migrations.Migration, error) {
+	var pending []migrations.Migration
+	for _, migration := range m.sortMigrations(sequence) {
+		isRun, err := m.isApplied(migration)
+		if err != nil {
+			return nil, err
+		}
+		if !isRun {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
 // RunMigrations runs the given sequence of migrations
 func (m *Migrator) RunMigrations(sequence []migrations.Migration) error {
 	sorted := m.sortMigrations(sequence)
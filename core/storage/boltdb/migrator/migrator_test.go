@@ -205,3 +205,31 @@ func TestRunsMigrationsInOrder(t *testing.T) {
 
 	assert.True(t, firstMockApplier.calledAt.Before(secondMockApplier.calledAt))
 }
+
+func TestPendingMigrations(t *testing.T) {
+	dir := boltdbtest.CreateTempDir(t)
+	defer boltdbtest.RemoveTempDir(t, dir)
+
+	_, migrator := createDBAndMigrator(t, dir)
+
+	older := migrations.Migration{Name: "older", Migrate: mockMigration.Migrate, Date: mockMigration.Date.Add(-time.Hour)}
+	sequence := []migrations.Migration{mockMigration, older}
+
+	pending, err := migrator.Pending(sequence)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"older", "test"}, migrationNames(pending))
+
+	err = migrator.saveMigrationRun(older)
+	assert.NoError(t, err)
+
+	pending, err = migrator.Pending(sequence)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"test"}, migrationNames(pending))
+}
+
+func migrationNames(sequence []migrations.Migration) (names []string) {
+	for _, m := range sequence {
+		names = append(names, m.Name)
+	}
+	return names
+}
@@ -20,27 +20,56 @@ package boltdb
 import (
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/codec"
 	"github.com/pkg/errors"
+	"go.etcd.io/bbolt"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+)
+
+var (
+	_ storage.Storage    = (*Bolt)(nil)
+	_ storage.Maintainer = (*Bolt)(nil)
+	_ storage.Rewriter   = (*Bolt)(nil)
 )
 
+// openTimeout limits waiting for the database file lock held by another process.
+const openTimeout = 10 * time.Second
+
 // Bolt is a wrapper around boltdb
 type Bolt struct {
-	mux sync.RWMutex
-	db  *storm.DB
+	mux     sync.RWMutex
+	db      *storm.DB
+	path    string
+	options []func(*storm.Options) error
 }
 
 // NewStorage creates a new BoltDB storage for service promises
 func NewStorage(path string) (*Bolt, error) {
-	return openDB(filepath.Join(path, "myst.db"))
+	return openDB(Path(path))
+}
+
+// NewStorageWithCodec creates a new BoltDB storage encoding records with the given codec.
+func NewStorageWithCodec(path string, c codec.MarshalUnmarshaler) (*Bolt, error) {
+	return openDB(Path(path), storm.Codec(c))
+}
+
+// Path returns the database file location in the storage directory.
+func Path(dir string) string {
+	return filepath.Join(dir, "myst.db")
 }
 
 // openDB creates new or open existing BoltDB
-func openDB(name string) (*Bolt, error) {
-	db, err := storm.Open(name)
+func openDB(name string, options ...func(*storm.Options) error) (*Bolt, error) {
+	options = append(options, storm.BoltOptions(0600, &bbolt.Options{Timeout: openTimeout}))
+	db, err := storm.Open(name, options...)
 	return &Bolt{
-		db: db,
+		db:      db,
+		path:    name,
+		options: options,
 	}, errors.Wrap(err, "failed to open boltDB")
 }
 
@@ -0,0 +1,142 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// KeySize is the size of the storage encryption key.
+const KeySize = 32
+
+// magic prefixes encrypted data, so that plaintext records written before enabling encryption stay readable.
+var magic = []byte("MYE1")
+
+// CodecName is the name storm records in bucket metadata for the encrypted codec.
+const CodecName = "encrypted-json"
+
+// Cipher encrypts data with AES-GCM using a synthetic nonce derived from the plaintext.
+// Encryption is deterministic: equal plaintexts produce equal ciphertexts,
+// which is required for encrypted record keys and indexes to be looked up.
+type Cipher struct {
+	aead  cipher.AEAD
+	ivKey []byte
+}
+
+// NewCipher creates cipher with the given key.
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, errors.Errorf("invalid key size %d", len(key))
+	}
+
+	block, err := aes.NewCipher(subkey(key, "encryption"))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cipher{aead: aead, ivKey: subkey(key, "nonce")}, nil
+}
+
+// Encrypt encrypts the plaintext.
+func (c *Cipher) Encrypt(plaintext []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, c.ivKey)
+	mac.Write(plaintext)
+	nonce := mac.Sum(nil)[:c.aead.NonceSize()]
+
+	out := make([]byte, 0, len(magic)+len(nonce)+len(plaintext)+c.aead.Overhead())
+	out = append(out, magic...)
+	out = append(out, nonce...)
+	return c.aead.Seal(out, nonce, plaintext, magic), nil
+}
+
+// Decrypt decrypts the ciphertext produced by Encrypt.
+func (c *Cipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	if !IsEncrypted(ciphertext) {
+		return nil, errors.New("data is not encrypted")
+	}
+
+	data := ciphertext[len(magic):]
+	if len(data) < c.aead.NonceSize() {
+		return nil, errors.New("encrypted data is too short")
+	}
+
+	nonce := data[:c.aead.NonceSize()]
+	plaintext, err := c.aead.Open(nil, nonce, data[c.aead.NonceSize():], magic)
+	return plaintext, errors.Wrap(err, "failed to decrypt data, wrong key?")
+}
+
+// IsEncrypted returns true if the data was produced by Cipher.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, magic)
+}
+
+// Codec is a storm codec which encrypts JSON encoded records.
+// Records which are not encrypted are decoded as plain JSON.
+// Storm writes ids and indexed values of primitive types raw, those stay unencrypted.
+type Codec struct {
+	cipher *Cipher
+}
+
+// NewCodec creates encrypting storm codec.
+func NewCodec(cipher *Cipher) *Codec {
+	return &Codec{cipher: cipher}
+}
+
+// Marshal encodes and encrypts the value.
+func (c *Codec) Marshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return c.cipher.Encrypt(data)
+}
+
+// Unmarshal decrypts and decodes the value.
+func (c *Codec) Unmarshal(b []byte, v interface{}) error {
+	if !IsEncrypted(b) {
+		return json.Unmarshal(b, v)
+	}
+
+	data, err := c.cipher.Decrypt(b)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Name returns the codec name.
+func (c *Codec) Name() string {
+	return CodecName
+}
+
+func subkey(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
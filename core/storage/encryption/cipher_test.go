@@ -0,0 +1,130 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package encryption
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asdine/storm/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+)
+
+func newTestCipher(t *testing.T, passphrase string) *Cipher {
+	key, err := DeriveKey(passphrase, []byte("salt"))
+	require.NoError(t, err)
+	cipher, err := NewCipher(key)
+	require.NoError(t, err)
+	return cipher
+}
+
+func TestCipher(t *testing.T) {
+	cipher := newTestCipher(t, "passphrase")
+
+	encrypted, err := cipher.Encrypt([]byte("data"))
+	assert.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.False(t, bytes.Contains(encrypted, []byte("data")))
+
+	again, err := cipher.Encrypt([]byte("data"))
+	assert.NoError(t, err)
+	assert.Equal(t, encrypted, again, "encryption must be deterministic")
+
+	decrypted, err := cipher.Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), decrypted)
+
+	_, err = newTestCipher(t, "wrong").Decrypt(encrypted)
+	assert.Error(t, err)
+}
+
+type session struct {
+	ID    sessionID `storm:"id"`
+	Name  string
+	Price int
+}
+
+type sessionID string
+
+func TestEncryptStorage(t *testing.T) {
+	dir := t.TempDir()
+	db, err := storm.Open(boltdb.Path(dir))
+	require.NoError(t, err)
+	require.NoError(t, db.From("sessions").Save(&session{ID: "session-1", Name: "plaintext-name", Price: 10}))
+	require.NoError(t, db.Set("values", "key", "plaintext-value"))
+	require.NoError(t, db.Close())
+
+	cipher := newTestCipher(t, "passphrase")
+	bolt, err := boltdb.NewStorageWithCodec(dir, NewCodec(cipher))
+	require.NoError(t, err)
+	defer bolt.Close()
+
+	count, err := EncryptStorage(bolt, cipher)
+	assert.NoError(t, err)
+	assert.Greater(t, count, 0)
+
+	count, err = EncryptStorage(bolt, cipher)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	raw, err := os.ReadFile(boltdb.Path(dir))
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(raw, []byte("plaintext-name")), "plaintext must not be left in the file")
+
+	var s session
+	assert.NoError(t, bolt.GetOneByField("sessions", "ID", sessionID("session-1"), &s))
+	assert.Equal(t, "plaintext-name", s.Name)
+
+	var value string
+	assert.NoError(t, bolt.GetValue("values", "key", &value))
+	assert.Equal(t, "plaintext-value", value)
+
+	assert.NoError(t, bolt.Store("sessions", &session{ID: "session-2", Name: "other"}))
+	var all []session
+	assert.NoError(t, bolt.GetAllFrom("sessions", &all))
+	assert.Len(t, all, 2)
+}
+
+func TestLoadKey(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := LoadKey(KeySourcePassphrase, dir, "")
+	assert.Equal(t, ErrPassphraseRequired, err)
+
+	key, err := LoadKey(KeySourcePassphrase, dir, "passphrase")
+	assert.NoError(t, err)
+	assert.Len(t, key, KeySize)
+	assert.FileExists(t, filepath.Join(dir, saltFile))
+
+	again, err := LoadKey(KeySourcePassphrase, dir, "passphrase")
+	assert.NoError(t, err)
+	assert.Equal(t, key, again)
+
+	key, err = LoadKey(KeySourceNone, dir, "passphrase")
+	assert.NoError(t, err)
+	assert.Nil(t, key)
+
+	_, err = LoadKey("unknown", dir, "")
+	assert.Error(t, err)
+	assert.NoError(t, os.Remove(filepath.Join(dir, saltFile)))
+}
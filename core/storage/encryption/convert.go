@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package encryption
+
+import (
+	"encoding/json"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+)
+
+// encryptedStorage is a storage opened with the encrypted codec.
+type encryptedStorage interface {
+	storage.Rewriter
+	storage.Maintainer
+}
+
+// EncryptStorage encrypts plaintext records written with JSON codec before encryption was enabled
+// and returns the number of encrypted records. It is a no-op for already encrypted storages.
+// The storage must be opened with the codec of the same cipher, it is compacted to drop the plaintext
+// left in free pages.
+//
+// Storages write primitive keys (strings, numbers, bytes) raw and other keys with the codec.
+// The codec output is told apart by being a JSON document or string, other entries are left untouched.
+func EncryptStorage(s encryptedStorage, cipher *Cipher) (int, error) {
+	count, err := s.Rewrite(func(key, value []byte) ([]byte, []byte, bool, error) {
+		if !codecEncoded(key) && !codecEncoded(value) {
+			return nil, nil, false, nil
+		}
+
+		var err error
+		if codecEncoded(key) {
+			if key, err = cipher.Encrypt(key); err != nil {
+				return nil, nil, false, err
+			}
+		}
+		if codecEncoded(value) {
+			if value, err = cipher.Encrypt(value); err != nil {
+				return nil, nil, false, err
+			}
+		}
+		return key, value, true, nil
+	})
+	if err != nil || count == 0 {
+		return count, err
+	}
+	return count, s.Compact()
+}
+
+// codecEncoded returns true if the data looks like plaintext written by the JSON codec.
+func codecEncoded(data []byte) bool {
+	if len(data) == 0 || IsEncrypted(data) {
+		return false
+	}
+	switch data[0] {
+	case '{', '[', '"':
+		return json.Valid(data)
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+//go:build sqlite
+
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package encryption
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/storage/sqlite"
+)
+
+func TestEncryptStorage_SQLite(t *testing.T) {
+	dir := t.TempDir()
+	db, err := sqlite.NewStorage(dir)
+	require.NoError(t, err)
+	require.NoError(t, db.Store("sessions", &session{ID: "session-1", Name: "plaintext-name", Price: 10}))
+	require.NoError(t, db.Close())
+
+	cipher := newTestCipher(t, "passphrase")
+	db, err = sqlite.NewStorageWithCodec(dir, NewCodec(cipher))
+	require.NoError(t, err)
+	defer db.Close()
+
+	count, err := EncryptStorage(db, cipher)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	raw, err := os.ReadFile(filepath.Join(dir, sqlite.FileName))
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(raw, []byte("plaintext-name")), "plaintext must not be left in the file")
+
+	var s session
+	assert.NoError(t, db.GetOneByField("sessions", "ID", sessionID("session-1"), &s))
+	assert.Equal(t, "plaintext-name", s.Name)
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package encryption
+
+import (
+	"crypto/rand"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KeySource tells where the storage encryption key comes from.
+type KeySource string
+
+const (
+	// KeySourceNone disables encryption.
+	KeySourceNone KeySource = ""
+	// KeySourcePassphrase derives the key from the identity passphrase.
+	KeySourcePassphrase KeySource = "passphrase"
+	// KeySourceKeyring keeps a random key in the OS keyring.
+	KeySourceKeyring KeySource = "keyring"
+)
+
+const (
+	saltFile = "storage.salt"
+
+	keyringService = "mysterium-node"
+	keyringAccount = "storage"
+)
+
+// ErrPassphraseRequired is returned when the key is derived from the passphrase, but it is not provided.
+var ErrPassphraseRequired = errors.New("identity passphrase is required to decrypt local storage")
+
+// ErrPassphraseStored is returned when the key is derived from the passphrase kept in plaintext configuration.
+var ErrPassphraseStored = errors.New("identity passphrase encrypting local storage must be passed via CLI flag, remove it from the configuration file")
+
+// LoadKey returns the storage encryption key from the given source, or nil if encryption is disabled.
+// Salt of the passphrase derived key and the keyring fallback are kept in the data directory.
+func LoadKey(source KeySource, dataDir, passphrase string) ([]byte, error) {
+	switch source {
+	case KeySourceNone:
+		return nil, nil
+	case KeySourcePassphrase:
+		if passphrase == "" {
+			return nil, ErrPassphraseRequired
+		}
+		salt, err := loadOrCreate(filepath.Join(dataDir, saltFile), 16)
+		if err != nil {
+			return nil, err
+		}
+		return DeriveKey(passphrase, salt)
+	case KeySourceKeyring:
+		return keyringKey(dataDir)
+	default:
+		return nil, errors.Errorf("unknown storage encryption key source %q", source)
+	}
+}
+
+// DeriveKey derives the encryption key from the passphrase.
+func DeriveKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, KeySize)
+}
+
+// LoadFileKey returns the random key kept in the given file, creating it on first use.
+func LoadFileKey(path string) ([]byte, error) {
+	return loadOrCreate(path, KeySize)
+}
+
+func randomKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	_, err := rand.Read(key)
+	return key, err
+}
+
+func loadOrCreate(path string, size int) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return data, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	data = make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		return nil, err
+	}
+	return data, errors.Wrapf(os.WriteFile(path, data, 0600), "could not save %s", filepath.Base(path))
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package encryption
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// errSecItemNotFound is the exit code of security tool when the keychain item does not exist.
+const errSecItemNotFound = 44
+
+// keyringKey reads the key from the login keychain, creating it on first use.
+// The key is passed to the security tool on stdin, so that it does not show up in the process list.
+func keyringKey(_ string) ([]byte, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", keyringService, "-a", keyringAccount, "-w").Output()
+	if err == nil {
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(string(out)))
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != errSecItemNotFound {
+		return nil, errors.Wrap(err, "could not read key from keychain")
+	}
+
+	key, err := randomKey()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -s %s -a %s -w %s\n", keyringService, keyringAccount, base64.StdEncoding.EncodeToString(key)))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, errors.Wrapf(err, "could not save key to keychain: %s", out)
+	}
+	return key, nil
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package encryption
+
+import (
+	"encoding/base64"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// keyringKey reads the key from the Secret Service keyring, creating it on first use.
+// Secret Service is reached over the D-Bus session of a logged in user, system services have none.
+func keyringKey(_ string) ([]byte, error) {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return nil, errors.New("OS keyring requires a D-Bus user session, use the passphrase key source for system services")
+	}
+
+	out, err := exec.Command("secret-tool", "lookup", "service", keyringService, "account", keyringAccount).Output()
+	if err == nil && len(strings.TrimSpace(string(out))) > 0 {
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(string(out)))
+	}
+	// Missing item is reported with a failure exit code only, anything on stderr is an actual error.
+	var exitErr *exec.ExitError
+	if err != nil && (!errors.As(err, &exitErr) || len(exitErr.Stderr) > 0) {
+		if exitErr != nil {
+			return nil, errors.Wrapf(err, "could not read key from keyring: %s", exitErr.Stderr)
+		}
+		return nil, errors.Wrap(err, "could not read key from keyring")
+	}
+
+	key, err := randomKey()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command("secret-tool", "store", "--label", "Mysterium node storage", "service", keyringService, "account", keyringAccount)
+	cmd.Stdin = strings.NewReader(base64.StdEncoding.EncodeToString(key))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, errors.Wrapf(err, "could not save key to keyring: %s", out)
+	}
+	return key, nil
+}
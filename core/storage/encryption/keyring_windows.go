@@ -0,0 +1,73 @@
+//go:build windows
+
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package encryption
+
+import (
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/windows"
+)
+
+const keyFile = "storage.key"
+
+// keyringKey reads the key protected with DPAPI from the data directory, creating it on first use.
+func keyringKey(dataDir string) ([]byte, error) {
+	path := filepath.Join(dataDir, keyFile)
+	protected, err := os.ReadFile(path)
+	if err == nil {
+		return dpapi(protected, windows.CryptUnprotectData)
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	key, err := randomKey()
+	if err != nil {
+		return nil, err
+	}
+	protected, err = dpapi(key, func(in *windows.DataBlob, _ **uint16, entropy *windows.DataBlob, reserved uintptr, prompt *windows.CryptProtectPromptStruct, flags uint32, out *windows.DataBlob) error {
+		return windows.CryptProtectData(in, nil, entropy, reserved, prompt, flags, out)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return key, errors.Wrap(os.WriteFile(path, protected, 0600), "could not save key")
+}
+
+type dpapiFunc func(in *windows.DataBlob, name **uint16, entropy *windows.DataBlob, reserved uintptr, prompt *windows.CryptProtectPromptStruct, flags uint32, out *windows.DataBlob) error
+
+func dpapi(data []byte, fn dpapiFunc) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("DPAPI failed: no data")
+	}
+	in := windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+	var out windows.DataBlob
+	if err := fn(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, errors.Wrap(err, "DPAPI failed")
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+
+	result := make([]byte, out.Size)
+	copy(result, unsafe.Slice(out.Data, out.Size))
+	return result, nil
+}
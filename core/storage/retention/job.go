@@ -0,0 +1,232 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package retention
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrRunning is returned when maintenance is requested while it is already running.
+var ErrRunning = errors.New("storage maintenance is already running")
+
+// Target is a kind of records kept no longer than their retention period.
+type Target struct {
+	Name string
+	// Retention disables pruning of the target if zero.
+	Retention time.Duration
+	Prune     func(before time.Time) (int, error)
+}
+
+// Result is an outcome of pruning a single target.
+type Result struct {
+	Name   string
+	Pruned int
+	Error  string
+}
+
+// Report describes the current or the last maintenance run.
+type Report struct {
+	Running    bool
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Step counts finished steps out of Steps: every target and the compaction.
+	Step  int
+	Steps int
+	// Current is the name of the step in progress.
+	Current    string
+	Results    []Result
+	SizeBefore int64
+	SizeAfter  int64
+	Error      string
+}
+
+// Freed returns bytes of storage reclaimed by the compaction.
+func (r Report) Freed() int64 {
+	if r.Running || r.SizeAfter > r.SizeBefore {
+		return 0
+	}
+	return r.SizeBefore - r.SizeAfter
+}
+
+type compactor interface {
+	Compact() error
+	Size() (int64, error)
+}
+
+// Job prunes records past their retention periods and compacts the storage afterwards.
+type Job struct {
+	db       compactor
+	targets  []Target
+	interval time.Duration
+	now      func() time.Time
+
+	mu     sync.Mutex
+	report Report
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewJob creates storage maintenance job running every interval once started.
+func NewJob(db compactor, interval time.Duration, targets ...Target) *Job {
+	return &Job{
+		db:       db,
+		targets:  targets,
+		interval: interval,
+		now:      time.Now,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start runs maintenance periodically in the background, unless interval is zero.
+func (j *Job) Start() {
+	if j.interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-j.stop:
+				return
+			case <-ticker.C:
+				if _, err := j.Run(); err != nil && !errors.Is(err, ErrRunning) {
+					log.Error().Err(err).Msg("Storage maintenance failed")
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops periodic maintenance.
+func (j *Job) Stop() {
+	j.stopOnce.Do(func() {
+		close(j.stop)
+	})
+}
+
+// Trigger starts maintenance in the background.
+func (j *Job) Trigger() error {
+	if !j.begin() {
+		return ErrRunning
+	}
+	go func() {
+		if err := j.run(); err != nil {
+			log.Error().Err(err).Msg("Storage maintenance failed")
+		}
+	}()
+	return nil
+}
+
+// Run performs maintenance and returns its report.
+func (j *Job) Run() (Report, error) {
+	if !j.begin() {
+		return j.Report(), ErrRunning
+	}
+	err := j.run()
+	return j.Report(), err
+}
+
+// Report returns the progress of the running maintenance or the report of the last one.
+func (j *Job) Report() Report {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	report := j.report
+	report.Results = append([]Result(nil), j.report.Results...)
+	return report
+}
+
+func (j *Job) begin() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.report.Running {
+		return false
+	}
+	j.report = Report{
+		Running:   true,
+		StartedAt: j.now(),
+		Steps:     len(j.targets) + 1,
+	}
+	return true
+}
+
+func (j *Job) run() (err error) {
+	defer func() {
+		j.update(func(r *Report) {
+			r.Running = false
+			r.Current = ""
+			r.FinishedAt = j.now()
+			if err != nil {
+				r.Error = err.Error()
+			}
+		})
+	}()
+
+	sizeBefore, err := j.db.Size()
+	if err != nil {
+		return err
+	}
+	j.update(func(r *Report) { r.SizeBefore = sizeBefore })
+
+	for _, target := range j.targets {
+		j.update(func(r *Report) { r.Current = target.Name })
+
+		res := Result{Name: target.Name}
+		if target.Retention > 0 {
+			pruned, pruneErr := target.Prune(j.now().Add(-target.Retention))
+			if pruneErr != nil {
+				log.Warn().Err(pruneErr).Msgf("Failed to prune %s", target.Name)
+				res.Error = pruneErr.Error()
+			}
+			res.Pruned = pruned
+		}
+		j.update(func(r *Report) {
+			r.Results = append(r.Results, res)
+			r.Step++
+		})
+	}
+
+	j.update(func(r *Report) { r.Current = "compaction" })
+	if err := j.db.Compact(); err != nil {
+		return err
+	}
+	sizeAfter, err := j.db.Size()
+	if err != nil {
+		return err
+	}
+	j.update(func(r *Report) {
+		r.SizeAfter = sizeAfter
+		r.Step++
+	})
+
+	log.Info().Msgf("Storage maintenance finished, %d bytes freed", sizeBefore-sizeAfter)
+	return nil
+}
+
+func (j *Job) update(fn func(r *Report)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	fn(&j.report)
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package retention
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCompactor struct {
+	size      int64
+	compacted bool
+}
+
+func (m *mockCompactor) Compact() error {
+	m.compacted = true
+	m.size /= 2
+	return nil
+}
+
+func (m *mockCompactor) Size() (int64, error) {
+	return m.size, nil
+}
+
+func TestJob_Run(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	db := &mockCompactor{size: 1000}
+	var sessionsBefore time.Time
+
+	job := NewJob(db, 0,
+		Target{Name: "sessions", Retention: 24 * time.Hour, Prune: func(before time.Time) (int, error) {
+			sessionsBefore = before
+			return 5, nil
+		}},
+		Target{Name: "payments", Prune: func(time.Time) (int, error) {
+			t.Fatal("target without retention must not be pruned")
+			return 0, nil
+		}},
+		Target{Name: "events", Retention: time.Hour, Prune: func(time.Time) (int, error) {
+			return 0, errors.New("boom")
+		}},
+	)
+	job.now = func() time.Time { return now }
+
+	report, err := job.Run()
+	require.NoError(t, err)
+
+	assert.Equal(t, now.Add(-24*time.Hour), sessionsBefore)
+	assert.True(t, db.compacted)
+	assert.False(t, report.Running)
+	assert.Equal(t, 4, report.Step)
+	assert.Equal(t, 4, report.Steps)
+	assert.Equal(t, []Result{
+		{Name: "sessions", Pruned: 5},
+		{Name: "payments"},
+		{Name: "events", Error: "boom"},
+	}, report.Results)
+	assert.Equal(t, int64(1000), report.SizeBefore)
+	assert.Equal(t, int64(500), report.SizeAfter)
+	assert.Equal(t, int64(500), report.Freed())
+	assert.Equal(t, now, report.FinishedAt)
+}
+
+func TestJob_RejectsConcurrentRuns(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	job := NewJob(&mockCompactor{}, 0, Target{Name: "sessions", Retention: time.Hour, Prune: func(time.Time) (int, error) {
+		close(started)
+		<-release
+		return 1, nil
+	}})
+
+	require.NoError(t, job.Trigger())
+	<-started
+
+	report := job.Report()
+	assert.True(t, report.Running)
+	assert.Equal(t, "sessions", report.Current)
+	assert.Zero(t, report.Freed())
+	assert.ErrorIs(t, job.Trigger(), ErrRunning)
+	_, err := job.Run()
+	assert.ErrorIs(t, err, ErrRunning)
+
+	close(release)
+	assert.Eventually(t, func() bool { return !job.Report().Running }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []Result{{Name: "sessions", Pruned: 1}}, job.Report().Results)
+}
@@ -0,0 +1,28 @@
+//go:build sqlite
+
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+// The driver is linked only with the sqlite tag to keep cgo out of default builds.
+// Without it the storage can not be opened and `myst db` reports that SQLite is unavailable.
+
+import (
+	// Registers the SQLite database/sql driver.
+	_ "github.com/mattn/go-sqlite3"
+)
@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.etcd.io/bbolt"
+)
+
+// stormPrefix marks internal storm buckets holding indexes and metadata, which are not copied.
+const stormPrefix = "__storm"
+
+// ImportBolt copies all records of a BoltDB database created by storm and returns the number of copied records.
+// Indexes are not copied, they are not used by SQLite storage.
+func (s *SQLite) ImportBolt(db *bbolt.DB) (int, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	put := func(bucket string, key, value []byte) error {
+		_, err := tx.Exec("INSERT OR REPLACE INTO records (bucket, key, value) VALUES (?, ?, ?)", bucket, key, value)
+		return err
+	}
+
+	var count int
+	err = db.View(func(btx *bbolt.Tx) error {
+		return btx.ForEach(func(name []byte, bucket *bbolt.Bucket) error {
+			if strings.HasPrefix(string(name), stormPrefix) {
+				return nil
+			}
+
+			return bucket.ForEach(func(k, v []byte) error {
+				if v != nil {
+					count++
+					return put(string(name), k, v)
+				}
+				if strings.HasPrefix(string(k), stormPrefix) {
+					return nil
+				}
+
+				return bucket.Bucket(k).ForEach(func(id, data []byte) error {
+					if data == nil {
+						return nil
+					}
+					count++
+					return put(nested(string(name), string(k)), id, data)
+				})
+			})
+		})
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to import BoltDB records")
+	}
+	return count, tx.Commit()
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/codec/json"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/bbolt"
+)
+
+type namedID string
+
+type record struct {
+	Key   namedID `storm:"id"`
+	Value string
+}
+
+func Test_toBytesMatchesStorm(t *testing.T) {
+	dir := t.TempDir()
+	db, err := storm.Open(dir + "/storm.db")
+	assert.NoError(t, err)
+	defer db.Close()
+
+	for i, key := range []interface{}{"key", []byte("key"), 42, int64(-1), uint32(7), namedID("id")} {
+		bucket := fmt.Sprintf("bucket%d", i)
+		assert.NoError(t, db.Set(bucket, key, "value"))
+
+		expected := storedKey(t, db, bucket)
+		actual, err := toBytes(key, json.Codec)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, "key %v", key)
+	}
+}
+
+func Test_structID(t *testing.T) {
+	name, id, err := structID(&record{Key: "id"}, json.Codec)
+	assert.NoError(t, err)
+	assert.Equal(t, "record", name)
+	assert.Equal(t, []byte(`"id"`), id)
+
+	_, _, err = structID(&record{}, json.Codec)
+	assert.Equal(t, storm.ErrZeroID, err)
+
+	_, _, err = structID(record{Key: "id"}, json.Codec)
+	assert.Equal(t, storm.ErrStructPtrNeeded, err)
+
+	_, _, err = structID(&struct{ Value string }{Value: "v"}, json.Codec)
+	assert.Equal(t, storm.ErrNoID, err)
+}
+
+// storedKey returns the raw key storm wrote to the bucket.
+func storedKey(t *testing.T, db *storm.DB, bucket string) []byte {
+	var raw []byte
+	err := db.Bolt.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte(bucket)).ForEach(func(k, v []byte) error {
+			if v != nil {
+				raw = k
+			}
+			return nil
+		})
+	})
+	assert.NoError(t, err)
+	return raw
+}
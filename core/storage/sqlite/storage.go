@@ -0,0 +1,500 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/codec"
+	"github.com/asdine/storm/v3/codec/json"
+	"github.com/pkg/errors"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+)
+
+// DriverName is the database/sql driver used by the storage.
+// The driver is linked when the node is built with the sqlite tag.
+const DriverName = "sqlite3"
+
+// FileName is the name of the database file in the storage directory.
+const FileName = "myst.sqlite"
+
+var (
+	_ storage.Storage    = (*SQLite)(nil)
+	_ storage.Maintainer = (*SQLite)(nil)
+	_ storage.Rewriter   = (*SQLite)(nil)
+)
+
+const schema = `CREATE TABLE IF NOT EXISTS records (
+	bucket TEXT NOT NULL,
+	key BLOB NOT NULL,
+	value BLOB NOT NULL,
+	PRIMARY KEY (bucket, key)
+)`
+
+// SQLite is a storage backed by an SQLite database.
+// Records are laid out the same way as in BoltDB: key values are stored in the bucket,
+// structs are stored in the bucket nested by struct type name.
+//
+// The running node always uses BoltDB: several stores query storm directly and are tied to it.
+// SQLite is only reachable through the `myst db` command, which copies BoltDB records into it
+// and verifies or compacts the copy, so the backend can be evaluated before switching to it.
+type SQLite struct {
+	mux   sync.RWMutex
+	db    *sql.DB
+	codec codec.MarshalUnmarshaler
+}
+
+// Available returns true if the SQLite driver is linked into the binary.
+func Available() bool {
+	for _, driver := range sql.Drivers() {
+		if driver == DriverName {
+			return true
+		}
+	}
+	return false
+}
+
+// NewStorage opens SQLite storage in the given directory.
+func NewStorage(path string) (*SQLite, error) {
+	return openDB(filepath.Join(path, FileName), json.Codec)
+}
+
+// NewStorageWithCodec opens SQLite storage in the given directory encoding records with the given codec.
+func NewStorageWithCodec(path string, c codec.MarshalUnmarshaler) (*SQLite, error) {
+	return openDB(filepath.Join(path, FileName), c)
+}
+
+func openDB(name string, c codec.MarshalUnmarshaler) (*SQLite, error) {
+	db, err := sql.Open(DriverName, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open SQLite")
+	}
+	// SQLite allows a single writer, serialize access on the client side instead of handling busy errors.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to create SQLite schema")
+	}
+	return &SQLite{db: db, codec: c}, nil
+}
+
+// GetValue gets key value
+func (s *SQLite) GetValue(bucket string, key interface{}, to interface{}) error {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	k, err := toBytes(key, s.codec)
+	if err != nil {
+		return err
+	}
+	return s.get(bucket, k, to)
+}
+
+// SetValue sets key value
+func (s *SQLite) SetValue(bucket string, key interface{}, value interface{}) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	k, err := toBytes(key, s.codec)
+	if err != nil {
+		return err
+	}
+	return s.put(bucket, k, value)
+}
+
+// Store allows to keep struct grouped by the bucket
+func (s *SQLite) Store(bucket string, data interface{}) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	name, id, err := structID(data, s.codec)
+	if err != nil {
+		return err
+	}
+	return s.put(nested(bucket, name), id, data)
+}
+
+// GetAllFrom allows to get all structs from the bucket
+func (s *SQLite) GetAllFrom(bucket string, data interface{}) error {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	ref := reflect.ValueOf(data)
+	if ref.Kind() != reflect.Ptr || ref.Elem().Kind() != reflect.Slice {
+		return storm.ErrSlicePtrNeeded
+	}
+	slice := ref.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+
+	rows, err := s.db.Query("SELECT value FROM records WHERE bucket = ? ORDER BY key", nested(bucket, structType.Name()))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	results := reflect.MakeSlice(slice.Type(), 0, 0)
+	for rows.Next() {
+		var value []byte
+		if err := rows.Scan(&value); err != nil {
+			return err
+		}
+
+		item := reflect.New(structType)
+		if err := s.codec.Unmarshal(value, item.Interface()); err != nil {
+			return err
+		}
+		if elemType.Kind() != reflect.Ptr {
+			item = item.Elem()
+		}
+		results = reflect.Append(results, item)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	slice.Set(results)
+	return nil
+}
+
+// Delete removes the given struct from the given bucket
+func (s *SQLite) Delete(bucket string, data interface{}) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	name, id, err := structID(data, s.codec)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.db.Exec("DELETE FROM records WHERE bucket = ? AND key = ?", nested(bucket, name), id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteKey the given struct from the given bucket
+func (s *SQLite) DeleteKey(bucket string, key interface{}) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	k, err := toBytes(key, s.codec)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec("DELETE FROM records WHERE bucket = ? AND key = ?", bucket, k)
+	return err
+}
+
+// Update allows to update the struct in the given bucket
+func (s *SQLite) Update(bucket string, object interface{}) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	name, id, err := structID(object, s.codec)
+	if err != nil {
+		return err
+	}
+
+	ref := reflect.ValueOf(object).Elem()
+	current := reflect.New(ref.Type())
+	if err := s.get(nested(bucket, name), id, current.Interface()); err != nil {
+		return err
+	}
+
+	// Only non-zero fields are updated, as in storm.
+	for i := 0; i < ref.NumField(); i++ {
+		if ref.Type().Field(i).PkgPath != "" {
+			continue
+		}
+		if field := ref.Field(i); !field.IsZero() {
+			current.Elem().Field(i).Set(field)
+		}
+	}
+	return s.put(nested(bucket, name), id, current.Interface())
+}
+
+// GetOneByField returns an object from the given bucket by the given field
+func (s *SQLite) GetOneByField(bucket string, fieldName string, key interface{}, to interface{}) error {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	ref := reflect.ValueOf(to)
+	if ref.Kind() != reflect.Ptr || ref.Elem().Kind() != reflect.Struct {
+		return storm.ErrStructPtrNeeded
+	}
+	if _, ok := ref.Elem().Type().FieldByName(fieldName); !ok {
+		return storm.ErrNotFound
+	}
+
+	rows, err := s.db.Query("SELECT value FROM records WHERE bucket = ? ORDER BY key", nested(bucket, ref.Elem().Type().Name()))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var value []byte
+		if err := rows.Scan(&value); err != nil {
+			return err
+		}
+
+		item := reflect.New(ref.Elem().Type())
+		if err := s.codec.Unmarshal(value, item.Interface()); err != nil {
+			return err
+		}
+		if reflect.DeepEqual(item.Elem().FieldByName(fieldName).Interface(), key) {
+			ref.Elem().Set(item.Elem())
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return storage.ErrNotFound
+}
+
+// GetLast returns the last entry in the bucket
+func (s *SQLite) GetLast(bucket string, to interface{}) error {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	ref := reflect.ValueOf(to)
+	if ref.Kind() != reflect.Ptr || ref.Elem().Kind() != reflect.Struct {
+		return storm.ErrStructPtrNeeded
+	}
+
+	var value []byte
+	err := s.db.QueryRow("SELECT value FROM records WHERE bucket = ? ORDER BY key DESC LIMIT 1", nested(bucket, ref.Elem().Type().Name())).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.codec.Unmarshal(value, to)
+}
+
+// GetBuckets returns a list of buckets
+func (s *SQLite) GetBuckets() []string {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	rows, err := s.db.Query("SELECT DISTINCT bucket FROM records ORDER BY bucket")
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var buckets []string
+	for rows.Next() {
+		var bucket string
+		if err := rows.Scan(&bucket); err != nil {
+			return buckets
+		}
+		bucket = strings.SplitN(bucket, "/", 2)[0]
+		if len(buckets) == 0 || buckets[len(buckets)-1] != bucket {
+			buckets = append(buckets, bucket)
+		}
+	}
+	return buckets
+}
+
+// Verify checks the integrity of the database.
+func (s *SQLite) Verify() error {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	var result string
+	if err := s.db.QueryRow("PRAGMA integrity_check").Scan(&result); err != nil {
+		return err
+	}
+	if result != "ok" {
+		return errors.Errorf("database is inconsistent: %s", result)
+	}
+	return nil
+}
+
+// Compact rebuilds the database file reclaiming unused space.
+func (s *SQLite) Compact() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	_, err := s.db.Exec("VACUUM")
+	return err
+}
+
+// Rewrite replaces raw records with the ones returned by fn and returns the number of replaced records.
+func (s *SQLite) Rewrite(fn storage.RewriteFunc) (int, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	type record struct {
+		bucket             string
+		oldKey, key, value []byte
+	}
+	var records []record
+
+	rows, err := tx.Query("SELECT bucket, key, value FROM records")
+	if err != nil {
+		return 0, err
+	}
+	for rows.Next() {
+		var bucket string
+		var key, value []byte
+		if err := rows.Scan(&bucket, &key, &value); err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		newKey, newValue, ok, err := fn(key, value)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if ok {
+			records = append(records, record{bucket: bucket, oldKey: key, key: newKey, value: newValue})
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, r := range records {
+		if _, err := tx.Exec("DELETE FROM records WHERE bucket = ? AND key = ?", r.bucket, r.oldKey); err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec("INSERT OR REPLACE INTO records (bucket, key, value) VALUES (?, ?, ?)", r.bucket, r.key, r.value); err != nil {
+			return 0, err
+		}
+	}
+	return len(records), errors.Wrap(tx.Commit(), "failed to rewrite SQLite records")
+}
+
+// Close closes database
+func (s *SQLite) Close() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.db.Close()
+}
+
+func (s *SQLite) get(bucket string, key []byte, to interface{}) error {
+	var value []byte
+	err := s.db.QueryRow("SELECT value FROM records WHERE bucket = ? AND key = ?", bucket, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.codec.Unmarshal(value, to)
+}
+
+func (s *SQLite) put(bucket string, key []byte, value interface{}) error {
+	data, err := s.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec("INSERT OR REPLACE INTO records (bucket, key, value) VALUES (?, ?, ?)", bucket, key, data)
+	return err
+}
+
+func nested(bucket, name string) string {
+	return bucket + "/" + name
+}
+
+// structID returns the type name and encoded id of the struct pointer.
+func structID(data interface{}, c codec.MarshalUnmarshaler) (string, []byte, error) {
+	ref := reflect.ValueOf(data)
+	if ref.Kind() != reflect.Ptr || ref.Elem().Kind() != reflect.Struct {
+		return "", nil, storm.ErrStructPtrNeeded
+	}
+	ref = ref.Elem()
+
+	var id reflect.Value
+	for i := 0; i < ref.NumField(); i++ {
+		field := ref.Type().Field(i)
+		tag := field.Tag.Get("storm")
+		if tag == "id" || strings.HasPrefix(tag, "id,") {
+			id = ref.Field(i)
+			break
+		}
+		if field.Name == "ID" {
+			id = ref.Field(i)
+		}
+	}
+	if !id.IsValid() {
+		return "", nil, storm.ErrNoID
+	}
+	if id.IsZero() {
+		return "", nil, storm.ErrZeroID
+	}
+
+	key, err := toBytes(id.Interface(), c)
+	return ref.Type().Name(), key, err
+}
+
+// toBytes encodes the key the same way storm does, so records are interchangeable with BoltDB.
+func toBytes(key interface{}, c codec.MarshalUnmarshaler) ([]byte, error) {
+	switch t := key.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return t, nil
+	case string:
+		return []byte(t), nil
+	case int:
+		return numberToBytes(int64(t))
+	case uint:
+		return numberToBytes(uint64(t))
+	case int8, int16, int32, int64, uint8, uint16, uint32, uint64:
+		return numberToBytes(t)
+	default:
+		return c.Marshal(key)
+	}
+}
+
+func numberToBytes(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := binary.Write(&buf, binary.BigEndian, v)
+	return buf.Bytes(), err
+}
@@ -0,0 +1,132 @@
+//go:build sqlite
+
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+)
+
+type item struct {
+	ID    int64 `storm:"id"`
+	Name  string
+	Count int
+}
+
+func createStorage(t *testing.T) *SQLite {
+	s, err := NewStorage(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSQLite_Values(t *testing.T) {
+	s := createStorage(t)
+
+	var value string
+	assert.Equal(t, storage.ErrNotFound, s.GetValue("values", "key", &value))
+
+	assert.NoError(t, s.SetValue("values", "key", "value"))
+	assert.NoError(t, s.GetValue("values", "key", &value))
+	assert.Equal(t, "value", value)
+
+	assert.NoError(t, s.DeleteKey("values", "key"))
+	assert.Equal(t, storage.ErrNotFound, s.GetValue("values", "key", &value))
+}
+
+func TestSQLite_Structs(t *testing.T) {
+	s := createStorage(t)
+
+	assert.NoError(t, s.Store("items", &item{ID: 2, Name: "second", Count: 2}))
+	assert.NoError(t, s.Store("items", &item{ID: 1, Name: "first", Count: 1}))
+
+	var all []item
+	assert.NoError(t, s.GetAllFrom("items", &all))
+	assert.Equal(t, []item{{ID: 1, Name: "first", Count: 1}, {ID: 2, Name: "second", Count: 2}}, all)
+
+	var one item
+	assert.NoError(t, s.GetOneByField("items", "Name", "second", &one))
+	assert.Equal(t, int64(2), one.ID)
+	assert.Equal(t, storage.ErrNotFound, s.GetOneByField("items", "Name", "third", &one))
+
+	assert.NoError(t, s.Update("items", &item{ID: 1, Count: 10}))
+	assert.NoError(t, s.GetOneByField("items", "ID", int64(1), &one))
+	assert.Equal(t, item{ID: 1, Name: "first", Count: 10}, one)
+
+	var last item
+	assert.NoError(t, s.GetLast("items", &last))
+	assert.Equal(t, int64(2), last.ID)
+
+	assert.NoError(t, s.Delete("items", &item{ID: 2}))
+	assert.Equal(t, storage.ErrNotFound, s.Delete("items", &item{ID: 2}))
+	assert.Equal(t, []string{"items"}, s.GetBuckets())
+
+	assert.NoError(t, s.Verify())
+	assert.NoError(t, s.Compact())
+}
+
+func TestSQLite_ImportBolt(t *testing.T) {
+	bolt, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	defer bolt.Close()
+
+	require.NoError(t, bolt.Store("items", &item{ID: 1, Name: "first"}))
+	require.NoError(t, bolt.SetValue("values", "key", "value"))
+
+	s := createStorage(t)
+	count, err := s.ImportBolt(bolt.DB().Bolt)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	var one item
+	assert.NoError(t, s.GetOneByField("items", "ID", int64(1), &one))
+	assert.Equal(t, "first", one.Name)
+
+	var value string
+	assert.NoError(t, s.GetValue("values", "key", &value))
+	assert.Equal(t, "value", value)
+}
+
+func TestSQLite_Rewrite(t *testing.T) {
+	s := createStorage(t)
+	require.NoError(t, s.SetValue("values", "key", "value"))
+	require.NoError(t, s.SetValue("values", "other", "kept"))
+
+	count, err := s.Rewrite(func(key, value []byte) ([]byte, []byte, bool, error) {
+		if string(key) != "key" {
+			return nil, nil, false, nil
+		}
+		return []byte("renamed"), []byte(`"rewritten"`), true, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	var value string
+	assert.Equal(t, storage.ErrNotFound, s.GetValue("values", "key", &value))
+	assert.NoError(t, s.GetValue("values", "renamed", &value))
+	assert.Equal(t, "rewritten", value)
+	assert.NoError(t, s.GetValue("values", "other", &value))
+	assert.Equal(t, "kept", value)
+}
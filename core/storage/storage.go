@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package storage
+
+// Storage is a bucket based persistent storage of the node.
+// Structs stored with Store, Update and Delete are identified by the field tagged `storm:"id"`.
+type Storage interface {
+	// GetValue reads the value of the key in the bucket.
+	GetValue(bucket string, key interface{}, to interface{}) error
+	// SetValue writes the value of the key in the bucket.
+	SetValue(bucket string, key interface{}, value interface{}) error
+	// Store saves the struct in the bucket.
+	Store(bucket string, data interface{}) error
+	// GetAllFrom reads all structs of the bucket into the slice.
+	GetAllFrom(bucket string, data interface{}) error
+	// Delete removes the struct from the bucket.
+	Delete(bucket string, data interface{}) error
+	// DeleteKey removes the key from the bucket.
+	DeleteKey(bucket string, key interface{}) error
+	// Update sets non-zero fields of the struct on the stored one.
+	Update(bucket string, data interface{}) error
+	// GetOneByField reads the first struct of the bucket with the field equal to the key.
+	GetOneByField(bucket string, fieldName string, key interface{}, to interface{}) error
+	// GetLast reads the struct with the greatest id from the bucket.
+	GetLast(bucket string, to interface{}) error
+	// GetBuckets lists all buckets.
+	GetBuckets() []string
+	// Close closes the storage.
+	Close() error
+}
+
+// Maintainer is implemented by storages which support integrity checks and compaction.
+type Maintainer interface {
+	// Verify checks the consistency of stored data.
+	Verify() error
+	// Compact reclaims unused space.
+	Compact() error
+}
+
+// Rewriter is implemented by storages which can rewrite their raw records in place.
+type Rewriter interface {
+	// Rewrite replaces raw records with the ones returned by fn and marks the storage
+	// as encoded with its codec. It returns the number of replaced records.
+	Rewrite(fn RewriteFunc) (int, error)
+}
+
+// RewriteFunc returns the new raw key and value of the record, ok is false to keep the record as is.
+type RewriteFunc func(key, value []byte) (newKey, newValue []byte, ok bool, err error)
@@ -0,0 +1,268 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package summary
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/alerting"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+// Alert severities.
+const (
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// Alert codes derived from the node state.
+const (
+	AlertIdentityLocked       = "identity_locked"
+	AlertIdentityUnregistered = "identity_unregistered"
+	AlertMonitoringFailed     = "monitoring_failed"
+	AlertServiceUnhealthy     = "service_unhealthy"
+)
+
+// Summary aggregates node state needed to render the dashboard.
+type Summary struct {
+	// Identity is nil if there is no unlocked identity.
+	Identity         *Identity
+	Monitoring       node.MonitoringStatus
+	Services         []Service
+	ProviderSessions int
+	ConsumerState    connectionstate.State
+	Today            Traffic
+	Alerts           []Alert
+	GeneratedAt      time.Time
+}
+
+// Identity describes the unlocked node identity.
+type Identity struct {
+	Address            string
+	RegistrationStatus registry.RegistrationStatus
+	Balance            *big.Int
+	Earnings           *big.Int
+	EarningsTotal      *big.Int
+}
+
+// Service describes a running service.
+type Service struct {
+	ID     service.ID
+	Type   string
+	State  servicestate.State
+	Health string
+}
+
+// Traffic holds totals of provided sessions started during a period.
+type Traffic struct {
+	Sessions     int
+	DataSent     uint64
+	DataReceived uint64
+	Earnings     *big.Int
+}
+
+// Alert is a condition requiring attention of the node operator.
+type Alert struct {
+	Code     string
+	Severity string
+	Message  string
+}
+
+type identityProvider interface {
+	GetUnlockedIdentity() (identity.Identity, bool)
+}
+
+type registrationProvider interface {
+	GetRegistrationStatus(chainID int64, id identity.Identity) (registry.RegistrationStatus, error)
+}
+
+type balanceProvider interface {
+	GetBalance(chainID int64, id identity.Identity) *big.Int
+}
+
+type earningsProvider interface {
+	GetEarningsDetailed(chainID int64, id identity.Identity) *pingpong_event.EarningsDetailed
+}
+
+type serviceLister interface {
+	List(includeAll bool) []*service.Instance
+}
+
+type sessionPool interface {
+	GetAll() []*service.Session
+}
+
+type connectionStatusProvider interface {
+	Status(n int) connectionstate.Status
+}
+
+type sessionStatsProvider interface {
+	Stats(*session.Filter) (session.Stats, error)
+}
+
+type monitoringStatusProvider interface {
+	Status() node.MonitoringStatus
+}
+
+type alertProvider interface {
+	Active() []alerting.Alert
+}
+
+// Deps holds providers of the summarized state, providers which are not set are skipped.
+type Deps struct {
+	Identities   identityProvider
+	Registry     registrationProvider
+	Balances     balanceProvider
+	Earnings     earningsProvider
+	Services     serviceLister
+	Sessions     sessionPool
+	Connections  connectionStatusProvider
+	SessionStats sessionStatsProvider
+	Monitoring   monitoringStatusProvider
+	Alerts       alertProvider
+}
+
+// Builder builds node summary.
+type Builder struct {
+	deps Deps
+	now  func() time.Time
+}
+
+// NewBuilder creates node summary builder.
+func NewBuilder(deps Deps) *Builder {
+	return &Builder{deps: deps, now: time.Now}
+}
+
+// Summary collects current node state. Failures of single providers are logged
+// and leave the corresponding parts of the summary empty.
+func (b *Builder) Summary() Summary {
+	now := b.now()
+	s := Summary{
+		Monitoring:  node.Pending,
+		Services:    []Service{},
+		Today:       Traffic{Earnings: new(big.Int)},
+		Alerts:      []Alert{},
+		GeneratedAt: now.UTC(),
+	}
+
+	s.Identity = b.identity(config.GetInt64(config.FlagChainID))
+	switch {
+	case s.Identity == nil:
+		s.Alerts = append(s.Alerts, Alert{Code: AlertIdentityLocked, Severity: SeverityWarning, Message: "No identity is unlocked"})
+	case s.Identity.RegistrationStatus == registry.Unregistered || s.Identity.RegistrationStatus == registry.RegistrationError:
+		s.Alerts = append(s.Alerts, Alert{Code: AlertIdentityUnregistered, Severity: SeverityError, Message: "Identity is not registered"})
+	}
+
+	if b.deps.Monitoring != nil {
+		s.Monitoring = b.deps.Monitoring.Status()
+		if s.Monitoring == node.Failed {
+			s.Alerts = append(s.Alerts, Alert{Code: AlertMonitoringFailed, Severity: SeverityError, Message: "Monitoring agent failed to reach node services"})
+		}
+	}
+
+	if b.deps.Services != nil {
+		for _, instance := range b.deps.Services.List(false) {
+			health := instance.Health()
+			s.Services = append(s.Services, Service{ID: instance.ID, Type: instance.Type, State: instance.State(), Health: health.Status})
+			if health.Status == service.HealthUnhealthy {
+				s.Alerts = append(s.Alerts, Alert{Code: AlertServiceUnhealthy, Severity: SeverityWarning, Message: instance.Type + " service is unhealthy: " + health.Error})
+			}
+		}
+	}
+	if b.deps.Sessions != nil {
+		s.ProviderSessions = len(b.deps.Sessions.GetAll())
+	}
+
+	s.ConsumerState = connectionstate.NotConnected
+	if b.deps.Connections != nil {
+		s.ConsumerState = b.deps.Connections.Status(0).State
+	}
+
+	if b.deps.SessionStats != nil {
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		filter := session.NewFilter().SetStartedFrom(midnight).SetDirection(session.DirectionProvided)
+		if s.Identity != nil {
+			filter.SetProviderID(identity.FromAddress(s.Identity.Address))
+		}
+		stats, err := b.deps.SessionStats.Stats(filter)
+		if err != nil {
+			log.Warn().Err(err).Msg("Could not get today's session stats for node summary")
+		} else {
+			s.Today = Traffic{
+				Sessions:     stats.Count,
+				DataSent:     stats.SumDataSent,
+				DataReceived: stats.SumDataReceived,
+				Earnings:     stats.SumTokens,
+			}
+		}
+	}
+
+	if b.deps.Alerts != nil {
+		for _, a := range b.deps.Alerts.Active() {
+			s.Alerts = append(s.Alerts, Alert{Code: string(a.Kind), Severity: a.Severity, Message: a.Message})
+		}
+	}
+
+	return s
+}
+
+func (b *Builder) identity(chainID int64) *Identity {
+	if b.deps.Identities == nil {
+		return nil
+	}
+	id, ok := b.deps.Identities.GetUnlockedIdentity()
+	if !ok {
+		return nil
+	}
+
+	res := &Identity{
+		Address:            id.Address,
+		RegistrationStatus: registry.Unknown,
+		Balance:            new(big.Int),
+		Earnings:           new(big.Int),
+		EarningsTotal:      new(big.Int),
+	}
+	if b.deps.Registry != nil {
+		status, err := b.deps.Registry.GetRegistrationStatus(chainID, id)
+		if err != nil {
+			log.Warn().Err(err).Msg("Could not get registration status for node summary")
+		} else {
+			res.RegistrationStatus = status
+		}
+	}
+	if b.deps.Balances != nil {
+		res.Balance = b.deps.Balances.GetBalance(chainID, id)
+	}
+	if b.deps.Earnings != nil {
+		earnings := b.deps.Earnings.GetEarningsDetailed(chainID, id)
+		res.Earnings = earnings.Total.UnsettledBalance
+		res.EarningsTotal = earnings.Total.LifetimeBalance
+	}
+	return res
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package summary
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/alerting"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+var providerID = identity.FromAddress("0x1")
+
+type mockIdentities struct {
+	id       identity.Identity
+	unlocked bool
+}
+
+func (m *mockIdentities) GetUnlockedIdentity() (identity.Identity, bool) {
+	return m.id, m.unlocked
+}
+
+type mockRegistry struct {
+	status registry.RegistrationStatus
+	err    error
+}
+
+func (m *mockRegistry) GetRegistrationStatus(int64, identity.Identity) (registry.RegistrationStatus, error) {
+	return m.status, m.err
+}
+
+type mockBalances struct{}
+
+func (m *mockBalances) GetBalance(int64, identity.Identity) *big.Int {
+	return big.NewInt(100)
+}
+
+type mockEarnings struct{}
+
+func (m *mockEarnings) GetEarningsDetailed(int64, identity.Identity) *pingpong_event.EarningsDetailed {
+	return &pingpong_event.EarningsDetailed{
+		Total: pingpong_event.Earnings{LifetimeBalance: big.NewInt(50), UnsettledBalance: big.NewInt(20)},
+	}
+}
+
+type mockMonitoring struct {
+	status node.MonitoringStatus
+}
+
+func (m *mockMonitoring) Status() node.MonitoringStatus {
+	return m.status
+}
+
+type mockConnections struct{}
+
+func (m *mockConnections) Status(int) connectionstate.Status {
+	return connectionstate.Status{State: connectionstate.Connected}
+}
+
+type mockStats struct {
+	filter *session.Filter
+	err    error
+}
+
+func (m *mockStats) Stats(filter *session.Filter) (session.Stats, error) {
+	m.filter = filter
+	stats := session.NewStats()
+	stats.Count = 3
+	stats.SumDataSent = 10
+	stats.SumDataReceived = 20
+	stats.SumTokens = big.NewInt(7)
+	return stats, m.err
+}
+
+func TestBuilder_Summary(t *testing.T) {
+	stats := &mockStats{}
+	builder := NewBuilder(Deps{
+		Identities:   &mockIdentities{id: providerID, unlocked: true},
+		Registry:     &mockRegistry{status: registry.Registered},
+		Balances:     &mockBalances{},
+		Earnings:     &mockEarnings{},
+		Connections:  &mockConnections{},
+		SessionStats: stats,
+		Monitoring:   &mockMonitoring{status: node.Passed},
+	})
+	now := time.Date(2022, 3, 4, 15, 30, 0, 0, time.UTC)
+	builder.now = func() time.Time { return now }
+
+	s := builder.Summary()
+
+	assert.Equal(t, &Identity{
+		Address:            providerID.Address,
+		RegistrationStatus: registry.Registered,
+		Balance:            big.NewInt(100),
+		Earnings:           big.NewInt(20),
+		EarningsTotal:      big.NewInt(50),
+	}, s.Identity)
+	assert.Equal(t, node.Passed, s.Monitoring)
+	assert.Equal(t, connectionstate.Connected, s.ConsumerState)
+	assert.Equal(t, Traffic{Sessions: 3, DataSent: 10, DataReceived: 20, Earnings: big.NewInt(7)}, s.Today)
+	assert.Equal(t, time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC), *stats.filter.StartedFrom)
+	assert.Equal(t, providerID, *stats.filter.ProviderID)
+	assert.Empty(t, s.Alerts)
+	assert.Equal(t, now, s.GeneratedAt)
+}
+
+func TestBuilder_Summary_Alerts(t *testing.T) {
+	builder := NewBuilder(Deps{
+		Identities: &mockIdentities{},
+		Monitoring: &mockMonitoring{status: node.Failed},
+	})
+
+	s := builder.Summary()
+
+	assert.Nil(t, s.Identity)
+	assert.Equal(t, connectionstate.NotConnected, s.ConsumerState)
+	assert.Equal(t, []string{AlertIdentityLocked, AlertMonitoringFailed}, alertCodes(s.Alerts))
+
+	builder.deps.Identities = &mockIdentities{id: providerID, unlocked: true}
+	builder.deps.Registry = &mockRegistry{status: registry.Unregistered}
+	builder.deps.Monitoring = &mockMonitoring{status: node.Passed}
+
+	s = builder.Summary()
+
+	assert.Equal(t, []string{AlertIdentityUnregistered}, alertCodes(s.Alerts))
+}
+
+type mockAlerts struct{}
+
+func (m *mockAlerts) Active() []alerting.Alert {
+	return []alerting.Alert{{Kind: alerting.KindBalanceBelow, Severity: alerting.SeverityWarning, Message: "low balance"}}
+}
+
+func TestBuilder_Summary_IncludesRuleAlerts(t *testing.T) {
+	builder := NewBuilder(Deps{
+		Identities: &mockIdentities{id: providerID, unlocked: true},
+		Alerts:     &mockAlerts{},
+	})
+
+	s := builder.Summary()
+
+	assert.Equal(t, []Alert{{Code: "balance_below", Severity: SeverityWarning, Message: "low balance"}}, s.Alerts)
+}
+
+func TestBuilder_Summary_SkipsFailedProviders(t *testing.T) {
+	builder := NewBuilder(Deps{
+		Identities:   &mockIdentities{id: providerID, unlocked: true},
+		Registry:     &mockRegistry{err: errors.New("boom")},
+		SessionStats: &mockStats{err: errors.New("boom")},
+	})
+
+	s := builder.Summary()
+
+	assert.Equal(t, registry.Unknown, s.Identity.RegistrationStatus)
+	assert.Equal(t, Traffic{Earnings: new(big.Int)}, s.Today)
+	assert.Equal(t, node.Pending, s.Monitoring)
+}
+
+func alertCodes(alerts []Alert) []string {
+	codes := make([]string, 0, len(alerts))
+	for _, a := range alerts {
+		codes = append(codes, a.Code)
+	}
+	return codes
+}
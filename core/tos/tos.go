@@ -0,0 +1,201 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tos
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/terms/terms-go"
+)
+
+const (
+	// ConsumerAgreedKey stores terms agreement for consumer features.
+	ConsumerAgreedKey = "terms.consumer-agreed"
+	// ProviderAgreedKey stores terms agreement for provider features.
+	ProviderAgreedKey = "terms.provider-agreed"
+	// VersionKey stores agreed terms version for both provider and consumer.
+	// Deprecated: it is only read for acceptances stored before ProviderVersionKey and ConsumerVersionKey.
+	VersionKey = "terms.version"
+	// ConsumerVersionKey stores terms version accepted by consumer.
+	ConsumerVersionKey = "terms.consumer-version"
+	// ProviderVersionKey stores terms version accepted by provider.
+	ProviderVersionKey = "terms.provider-version"
+	// ConsumerAgreedAtKey stores the time consumer terms were accepted.
+	ConsumerAgreedAtKey = "terms.consumer-agreed-at"
+	// ProviderAgreedAtKey stores the time provider terms were accepted.
+	ProviderAgreedAtKey = "terms.provider-agreed-at"
+)
+
+// ErrNotAccepted is returned when the current terms are not accepted.
+var ErrNotAccepted = errors.New("terms of service are not accepted")
+
+// ErrVersionMismatch is returned when accepting terms other than the current ones.
+var ErrVersionMismatch = errors.New("only the current terms version can be accepted")
+
+// Documents lists terms documents of the current terms version by their name.
+var Documents = map[string][]byte{
+	"node_short": terms.TermsNodeShort,
+	"exit_node":  terms.TermsExitNode,
+	"end_user":   terms.TermsEndUser,
+}
+
+// Acceptance describes terms acceptance of a single party.
+type Acceptance struct {
+	Agreed     bool
+	Version    string
+	AcceptedAt time.Time
+}
+
+// Accepted tells whether the given terms version is accepted.
+func (a Acceptance) Accepted(version string) bool {
+	return a.Agreed && a.Version == version
+}
+
+// Status describes current terms and their acceptance.
+type Status struct {
+	CurrentVersion string
+	Provider       Acceptance
+	Consumer       Acceptance
+}
+
+// Update describes a change of terms acceptance, nil fields are left intact.
+type Update struct {
+	Provider *bool
+	Consumer *bool
+	Version  string
+}
+
+type configStore interface {
+	GetBool(key string) bool
+	GetString(key string) string
+	SetUser(key string, value interface{})
+	RemoveUser(key string)
+	SaveUserConfig() error
+}
+
+// Keeper persists terms acceptance in the user config.
+type Keeper struct {
+	lock    sync.Mutex
+	config  configStore
+	version string
+	now     func() time.Time
+}
+
+// NewKeeper creates terms keeper for the given current terms version.
+func NewKeeper(config configStore, version string) *Keeper {
+	return &Keeper{
+		config:  config,
+		version: version,
+		now:     time.Now,
+	}
+}
+
+// CurrentVersion returns the version of terms which must be accepted.
+func (k *Keeper) CurrentVersion() string {
+	return k.version
+}
+
+// Status returns current terms version and acceptance state.
+func (k *Keeper) Status() Status {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	return Status{
+		CurrentVersion: k.version,
+		Provider: Acceptance{
+			Agreed:     k.config.GetBool(ProviderAgreedKey),
+			Version:    AgreedVersion(k.config, ProviderVersionKey),
+			AcceptedAt: k.acceptedAt(ProviderAgreedAtKey),
+		},
+		Consumer: Acceptance{
+			Agreed:     k.config.GetBool(ConsumerAgreedKey),
+			Version:    AgreedVersion(k.config, ConsumerVersionKey),
+			AcceptedAt: k.acceptedAt(ConsumerAgreedAtKey),
+		},
+	}
+}
+
+// CheckProvider returns ErrNotAccepted unless the current provider terms are accepted.
+func (k *Keeper) CheckProvider() error {
+	provider := k.Status().Provider
+	if !provider.Agreed {
+		return ErrNotAccepted
+	}
+	if !provider.Accepted(k.version) {
+		return fmt.Errorf("%w: version %s is accepted, but version %s is required", ErrNotAccepted, provider.Version, k.version)
+	}
+	return nil
+}
+
+// Update records terms acceptance and persists it with acceptance time.
+func (k *Keeper) Update(update Update) error {
+	version := update.Version
+	if version == "" {
+		version = k.version
+	}
+	if version != k.version && (isTrue(update.Provider) || isTrue(update.Consumer)) {
+		return fmt.Errorf("%w: %s", ErrVersionMismatch, k.version)
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	now := k.now().UTC()
+	k.set(update.Provider, ProviderAgreedKey, ProviderAgreedAtKey, ProviderVersionKey, version, now)
+	k.set(update.Consumer, ConsumerAgreedKey, ConsumerAgreedAtKey, ConsumerVersionKey, version, now)
+
+	return k.config.SaveUserConfig()
+}
+
+func (k *Keeper) set(agreed *bool, agreedKey, agreedAtKey, versionKey, version string, now time.Time) {
+	if agreed == nil {
+		return
+	}
+
+	k.config.SetUser(agreedKey, *agreed)
+	k.config.SetUser(versionKey, version)
+	if *agreed {
+		k.config.SetUser(agreedAtKey, now.Format(time.RFC3339))
+	} else {
+		k.config.RemoveUser(agreedAtKey)
+	}
+}
+
+func (k *Keeper) acceptedAt(key string) time.Time {
+	acceptedAt, err := time.Parse(time.RFC3339, k.config.GetString(key))
+	if err != nil {
+		return time.Time{}
+	}
+	return acceptedAt
+}
+
+// AgreedVersion returns the terms version stored under the given party version key,
+// falling back to the version shared by both parties before they were stored separately.
+func AgreedVersion(config interface{ GetString(key string) string }, versionKey string) string {
+	if version := config.GetString(versionKey); version != "" {
+		return version
+	}
+	return config.GetString(VersionKey)
+}
+
+func isTrue(value *bool) bool {
+	return value != nil && *value
+}
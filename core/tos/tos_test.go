@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tos
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockConfig struct {
+	values map[string]interface{}
+	saves  int
+}
+
+func newMockConfig() *mockConfig {
+	return &mockConfig{values: make(map[string]interface{})}
+}
+
+func (c *mockConfig) GetBool(key string) bool {
+	v, _ := c.values[key].(bool)
+	return v
+}
+
+func (c *mockConfig) GetString(key string) string {
+	v, _ := c.values[key].(string)
+	return v
+}
+
+func (c *mockConfig) SetUser(key string, value interface{}) { c.values[key] = value }
+func (c *mockConfig) RemoveUser(key string)                 { delete(c.values, key) }
+func (c *mockConfig) SaveUserConfig() error                 { c.saves++; return nil }
+
+func TestKeeper_AcceptProvider(t *testing.T) {
+	cfg := newMockConfig()
+	keeper := NewKeeper(cfg, "0.0.40")
+	keeper.now = func() time.Time { return time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	assert.ErrorIs(t, keeper.CheckProvider(), ErrNotAccepted)
+
+	agreed := true
+	require.NoError(t, keeper.Update(Update{Provider: &agreed}))
+	assert.NoError(t, keeper.CheckProvider())
+	assert.Equal(t, 1, cfg.saves)
+
+	status := keeper.Status()
+	assert.Equal(t, "0.0.40", status.CurrentVersion)
+	assert.Equal(t, Acceptance{Agreed: true, Version: "0.0.40", AcceptedAt: keeper.now()}, status.Provider)
+	assert.False(t, status.Consumer.Accepted(status.CurrentVersion))
+
+	disagreed := false
+	require.NoError(t, keeper.Update(Update{Provider: &disagreed, Version: "0.0.40"}))
+	assert.ErrorIs(t, keeper.CheckProvider(), ErrNotAccepted)
+	assert.True(t, keeper.Status().Provider.AcceptedAt.IsZero())
+}
+
+func TestKeeper_OutdatedVersion(t *testing.T) {
+	cfg := newMockConfig()
+	cfg.values[ProviderAgreedKey] = true
+	cfg.values[VersionKey] = "0.0.39"
+	keeper := NewKeeper(cfg, "0.0.40")
+
+	err := keeper.CheckProvider()
+	assert.ErrorIs(t, err, ErrNotAccepted)
+	assert.Contains(t, err.Error(), "0.0.39")
+
+	agreed := true
+	err = keeper.Update(Update{Provider: &agreed, Version: "0.0.39"})
+	assert.True(t, errors.Is(err, ErrVersionMismatch))
+	assert.Equal(t, 0, cfg.saves)
+}
+
+func TestKeeper_VersionPerParty(t *testing.T) {
+	cfg := newMockConfig()
+	cfg.values[ProviderAgreedKey] = true
+	cfg.values[ProviderVersionKey] = "0.0.39"
+	keeper := NewKeeper(cfg, "0.0.40")
+
+	agreed := true
+	require.NoError(t, keeper.Update(Update{Consumer: &agreed}))
+
+	status := keeper.Status()
+	assert.Equal(t, "0.0.40", status.Consumer.Version)
+	assert.Equal(t, "0.0.39", status.Provider.Version)
+	assert.ErrorIs(t, keeper.CheckProvider(), ErrNotAccepted, "consumer acceptance must not accept provider terms")
+
+	require.NoError(t, keeper.Update(Update{Provider: &agreed}))
+	assert.NoError(t, keeper.CheckProvider())
+	assert.Equal(t, "0.0.40", cfg.values[ProviderVersionKey])
+}
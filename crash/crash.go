@@ -0,0 +1,248 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package crash recovers panics in goroutine entry points and persists crash reports.
+package crash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/metadata"
+	"github.com/mysteriumnetwork/node/metrics"
+)
+
+const (
+	reportPrefix = "crash-"
+	reportSuffix = ".json"
+	// reportsLimit is the number of crash reports kept on disk.
+	reportsLimit = 20
+	// eventsLimit is the number of recent log messages attached to crash reports.
+	eventsLimit = 50
+)
+
+var crashes = metrics.NewCounter("node_crashes_total", "Panics recovered in goroutine entry points")
+
+var defaultHandler = NewHandler("")
+
+// ReportsDir returns the directory crash reports are stored in under the node data directory.
+func ReportsDir(dataDir string) string {
+	return filepath.Join(dataDir, "crashes")
+}
+
+// Setup makes the default handler persist crash reports to the given directory.
+// The returned handler should be attached as a zerolog hook to collect recent events.
+func Setup(dir string) *Handler {
+	defaultHandler.setDir(dir)
+	return defaultHandler
+}
+
+// Recover recovers a panic using the default handler, it must be deferred directly.
+func Recover(name string) {
+	if r := recover(); r != nil {
+		defaultHandler.handle(name, r, debug.Stack())
+	}
+}
+
+// Go runs fn in a new goroutine recovering its panics with the default handler.
+func Go(name string, fn func()) {
+	go func() {
+		defer Recover(name)
+		fn()
+	}()
+}
+
+// GetStats returns crash statistics of the default handler.
+func GetStats() Stats {
+	return defaultHandler.Stats()
+}
+
+// Event is a log message logged shortly before the crash.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+}
+
+// Report describes a recovered panic.
+type Report struct {
+	Time         time.Time `json:"time"`
+	Name         string    `json:"name"`
+	Version      string    `json:"version"`
+	Panic        string    `json:"panic"`
+	Stack        string    `json:"stack"`
+	Goroutines   int       `json:"goroutines"`
+	RecentEvents []Event   `json:"recent_events"`
+}
+
+// Stats holds crash counters.
+type Stats struct {
+	// Crashes is the number of panics recovered since the node start.
+	Crashes uint64
+	// LastCrashAt is the time of the last recovered panic since the node start.
+	LastCrashAt time.Time
+}
+
+// Handler recovers panics and persists crash reports with recent log messages.
+type Handler struct {
+	now func() time.Time
+
+	mu     sync.Mutex
+	dir    string
+	events []Event
+	next   int
+	stats  Stats
+}
+
+// NewHandler creates crash handler persisting reports to dir, reports are only logged if dir is empty.
+func NewHandler(dir string) *Handler {
+	return &Handler{dir: dir, now: time.Now}
+}
+
+// Recover recovers a panic, it must be deferred directly.
+func (h *Handler) Recover(name string) {
+	if r := recover(); r != nil {
+		h.handle(name, r, debug.Stack())
+	}
+}
+
+// Run records recent log messages (zerolog hook).
+func (h *Handler) Run(_ *zerolog.Event, level zerolog.Level, message string) {
+	if level < zerolog.InfoLevel || level == zerolog.NoLevel {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	e := Event{Time: h.now().UTC(), Level: level.String(), Message: message}
+	if len(h.events) < eventsLimit {
+		h.events = append(h.events, e)
+		return
+	}
+	h.events[h.next] = e
+	h.next = (h.next + 1) % eventsLimit
+}
+
+// Stats returns crash statistics.
+func (h *Handler) Stats() Stats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.stats
+}
+
+func (h *Handler) setDir(dir string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.dir = dir
+}
+
+func (h *Handler) handle(name string, r interface{}, stack []byte) {
+	crashes.Inc()
+
+	h.mu.Lock()
+	now := h.now().UTC()
+	h.stats.Crashes++
+	h.stats.LastCrashAt = now
+	dir := h.dir
+	events := make([]Event, 0, len(h.events))
+	events = append(events, h.events[h.next:]...)
+	events = append(events, h.events[:h.next]...)
+	h.mu.Unlock()
+
+	report := Report{
+		Time:         now,
+		Name:         name,
+		Version:      metadata.VersionAsString(),
+		Panic:        fmt.Sprint(r),
+		Stack:        string(stack),
+		Goroutines:   runtime.NumGoroutine(),
+		RecentEvents: events,
+	}
+	log.Error().Str("name", name).Str("stack", report.Stack).Msgf("Recovered from panic: %s", report.Panic)
+
+	if dir == "" {
+		return
+	}
+	path, err := writeReport(dir, report)
+	if err != nil {
+		log.Err(err).Msg("Failed to persist crash report")
+		return
+	}
+	log.Info().Msgf("Crash report saved to %s", path)
+
+	if err := pruneReports(dir, reportsLimit); err != nil {
+		log.Warn().Err(err).Msg("Failed to prune crash reports")
+	}
+}
+
+func writeReport(dir string, report Report) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ' ' || r == ':' {
+			return '_'
+		}
+		return r
+	}, report.Name)
+	path := filepath.Join(dir, reportPrefix+report.Time.Format("20060102T150405.000000000Z")+"-"+name+reportSuffix)
+	return path, os.WriteFile(path, data, 0600)
+}
+
+// Reports returns paths of crash reports stored in dir, oldest first.
+func Reports(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, reportPrefix+"*"+reportSuffix))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func pruneReports(dir string, keep int) error {
+	paths, err := Reports(dir)
+	if err != nil {
+		return err
+	}
+	for len(paths) > keep {
+		if err := os.Remove(paths[0]); err != nil {
+			return err
+		}
+		paths = paths[1:]
+	}
+	return nil
+}
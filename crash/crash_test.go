@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package crash
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_RecoverPersistsReport(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	h := NewHandler(dir)
+	at := time.Date(2022, time.July, 1, 12, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return at }
+	h.Run(nil, zerolog.DebugLevel, "ignored")
+	h.Run(nil, zerolog.InfoLevel, "starting service")
+	h.Run(nil, zerolog.ErrorLevel, "something failed")
+
+	// when
+	func() {
+		defer h.Recover("p2p/handler")
+		panic("boom")
+	}()
+
+	// then
+	stats := h.Stats()
+	assert.Equal(t, uint64(1), stats.Crashes)
+	assert.Equal(t, at, stats.LastCrashAt)
+
+	paths, err := Reports(dir)
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	assert.Contains(t, paths[0], "p2p_handler")
+
+	data, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+	var report Report
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, "p2p/handler", report.Name)
+	assert.Equal(t, "boom", report.Panic)
+	assert.Contains(t, report.Stack, "TestHandler_RecoverPersistsReport")
+	assert.Equal(t, []Event{
+		{Time: at, Level: "info", Message: "starting service"},
+		{Time: at, Level: "error", Message: "something failed"},
+	}, report.RecentEvents)
+}
+
+func TestHandler_KeepsRecentEvents(t *testing.T) {
+	h := NewHandler("")
+	for i := 0; i < eventsLimit+5; i++ {
+		h.Run(nil, zerolog.InfoLevel, string(rune('a'+i%26)))
+	}
+
+	func() {
+		defer h.Recover("scheduler")
+		var m map[string]int
+		m["x"] = 1
+	}()
+
+	assert.Equal(t, uint64(1), h.Stats().Crashes)
+	assert.Len(t, h.events, eventsLimit)
+	assert.Equal(t, string(rune('a'+5%26)), h.events[h.next].Message)
+}
+
+func TestHandler_PrunesReports(t *testing.T) {
+	dir := t.TempDir()
+	h := NewHandler(dir)
+	at := time.Date(2022, time.July, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < reportsLimit+3; i++ {
+		h.now = func() time.Time { return at.Add(time.Duration(i) * time.Second) }
+		func() {
+			defer h.Recover("service")
+			panic(i)
+		}()
+	}
+
+	paths, err := Reports(dir)
+	require.NoError(t, err)
+	assert.Len(t, paths, reportsLimit)
+	assert.Contains(t, paths[0], "20220701T120003")
+}
+
+func TestGo_RecoversPanic(t *testing.T) {
+	before := GetStats().Crashes
+
+	done := make(chan struct{})
+	Go("test", func() {
+		defer close(done)
+		panic("boom")
+	})
+	<-done
+
+	assert.Eventually(t, func() bool { return GetStats().Crashes == before+1 }, time.Second, 10*time.Millisecond)
+}
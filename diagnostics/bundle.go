@@ -0,0 +1,230 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package diagnostics
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/crash"
+	"github.com/mysteriumnetwork/node/nat"
+	"github.com/mysteriumnetwork/node/utils/cmdutil"
+)
+
+const natProbeTimeout = 10 * time.Second
+
+type logCollector interface {
+	Filepaths() ([]string, error)
+}
+
+type natProber interface {
+	Probe(context.Context) (nat.NATType, error)
+}
+
+type stateProvider interface {
+	GetState() event.State
+}
+
+// Bundler gathers node state into a single archive for support.
+type Bundler struct {
+	outputDir    string
+	logCollector logCollector
+	config       func() map[string]interface{}
+	natProber    natProber
+	state        stateProvider
+	errorLog     *ErrorLog
+	now          func() time.Time
+}
+
+// NewBundler creates a diagnostics bundler which stores archives in the given directory.
+func NewBundler(outputDir string, logCollector logCollector, config func() map[string]interface{}, natProber natProber, state stateProvider, errorLog *ErrorLog) *Bundler {
+	return &Bundler{
+		outputDir:    outputDir,
+		logCollector: logCollector,
+		config:       config,
+		natProber:    natProber,
+		state:        state,
+		errorLog:     errorLog,
+		now:          time.Now,
+	}
+}
+
+// Generate creates the diagnostics archive and returns its path.
+// Failure to collect a single part is recorded in the archive instead of failing the whole bundle.
+// Secrets are redacted from configuration, logs and crash reports.
+func (b *Bundler) Generate(ctx context.Context) (_ string, err error) {
+	path := filepath.Join(b.outputDir, fmt.Sprintf("diagnostics-%s.zip", b.now().UTC().Format("20060102-150405")))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", errors.Wrap(err, "could not create diagnostics archive")
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = errors.Wrap(closeErr, "could not write diagnostics archive")
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	archive := zip.NewWriter(file)
+	bundle := &bundleWriter{archive: archive}
+
+	bundle.writeJSON("config.json", Redact(b.config()))
+	bundle.writeJSON("nat.json", b.natType(ctx))
+	bundle.writeJSON("state.json", b.state.GetState())
+	bundle.writeJSON("errors.json", redactEntries(b.errorLog.Entries()))
+	bundle.writeCommand("routes.txt", routeTableCommand())
+	bundle.writeCommand("firewall.txt", firewallRulesCommand())
+	bundle.writeLogs(b.logCollector)
+	bundle.writeCrashReports(crash.ReportsDir(b.outputDir))
+	bundle.writeJSON("failures.json", bundle.failures)
+
+	if err := archive.Close(); err != nil {
+		return "", errors.Wrap(err, "could not finalize diagnostics archive")
+	}
+
+	log.Info().Msgf("Diagnostics bundle created: %s", path)
+	return path, nil
+}
+
+func (b *Bundler) natType(ctx context.Context) map[string]string {
+	ctx, cancel := context.WithTimeout(ctx, natProbeTimeout)
+	defer cancel()
+
+	natType, err := b.natProber.Probe(ctx)
+	if err != nil {
+		return map[string]string{"error": err.Error()}
+	}
+	return map[string]string{"type": string(natType)}
+}
+
+type bundleWriter struct {
+	archive  *zip.Writer
+	failures map[string]string
+}
+
+func (w *bundleWriter) fail(name string, err error) {
+	if w.failures == nil {
+		w.failures = make(map[string]string)
+	}
+	w.failures[name] = err.Error()
+}
+
+func (w *bundleWriter) writeJSON(name string, value interface{}) {
+	f, err := w.archive.Create(name)
+	if err != nil {
+		w.fail(name, err)
+		return
+	}
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		w.fail(name, err)
+	}
+}
+
+func (w *bundleWriter) writeCommand(name string, args []string) {
+	if len(args) == 0 {
+		return
+	}
+
+	out, err := cmdutil.ExecOutput(args...)
+	if err != nil {
+		w.fail(name, err)
+	}
+
+	f, err := w.archive.Create(name)
+	if err != nil {
+		w.fail(name, err)
+		return
+	}
+	io.WriteString(f, out)
+}
+
+func (w *bundleWriter) writeLogs(collector logCollector) {
+	paths, err := collector.Filepaths()
+	if err != nil {
+		w.fail("logs", err)
+		return
+	}
+
+	for _, path := range paths {
+		name := "logs/" + filepath.Base(path)
+		if err := w.copyRedacted(name, path); err != nil {
+			w.fail(name, err)
+		}
+	}
+}
+
+func (w *bundleWriter) writeCrashReports(dir string) {
+	paths, err := crash.Reports(dir)
+	if err != nil {
+		w.fail("crashes", err)
+		return
+	}
+
+	for _, path := range paths {
+		name := "crashes/" + filepath.Base(path)
+		if err := w.copyRedacted(name, path); err != nil {
+			w.fail(name, err)
+		}
+	}
+}
+
+// copyRedacted copies the file line by line into the archive, hiding sensitive values.
+func (w *bundleWriter) copyRedacted(name, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := w.archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if _, err := io.WriteString(dst, RedactLine(scanner.Text())+"\n"); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func redactEntries(entries []ErrorEntry) []ErrorEntry {
+	for i := range entries {
+		entries[i].Message = RedactLine(entries[i].Message)
+	}
+	return entries
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package diagnostics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrorEntry is a single error logged by the node.
+type ErrorEntry struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+}
+
+// ErrorLog keeps the most recent error messages, it is a zerolog hook.
+type ErrorLog struct {
+	size int
+
+	lock    sync.Mutex
+	entries []ErrorEntry
+	next    int
+}
+
+// NewErrorLog creates an error log keeping at most size entries.
+func NewErrorLog(size int) *ErrorLog {
+	return &ErrorLog{size: size}
+}
+
+// Run records error level messages (zerolog hook).
+func (l *ErrorLog) Run(_ *zerolog.Event, level zerolog.Level, message string) {
+	if level < zerolog.ErrorLevel || level == zerolog.NoLevel {
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	entry := ErrorEntry{Time: time.Now().UTC(), Level: level.String(), Message: message}
+	if len(l.entries) < l.size {
+		l.entries = append(l.entries, entry)
+		return
+	}
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % l.size
+}
+
+// Entries returns recorded errors, oldest first.
+func (l *ErrorLog) Entries() []ErrorEntry {
+	if l == nil {
+		return nil
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	result := make([]ErrorEntry, 0, len(l.entries))
+	result = append(result, l.entries[l.next:]...)
+	return append(result, l.entries[:l.next]...)
+}
@@ -1,5 +1,5 @@
 /*
- * Copyright (C) 2017 The "MysteriumNetwork/node" Authors.
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
  *
  * This program is free software: you can redistribute it and/or modify
  * it under the terms of the GNU General Public License as published by
@@ -15,22 +15,27 @@
  * along with this program.  If not, see <http://www.gnu.org/licenses/>.
  */
 
-package nats
+package diagnostics
 
 import (
-	"context"
-	"time"
+	"fmt"
+	"testing"
 
-	"github.com/nats-io/nats.go"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
 )
 
-// Connection represents is publish-subscriber instance which can deliver messages
-type Connection interface {
-	Open() error
-	Close()
-	Servers() []string
-	Publish(subject string, payload []byte) error
-	Subscribe(subject string, handler nats.MsgHandler) (*nats.Subscription, error)
-	Request(subject string, payload []byte, timeout time.Duration) (*nats.Msg, error)
-	RequestWithContext(ctx context.Context, subj string, data []byte) (*nats.Msg, error)
+func TestErrorLog_KeepsMostRecentErrors(t *testing.T) {
+	errorLog := NewErrorLog(2)
+
+	errorLog.Run(nil, zerolog.InfoLevel, "info")
+	for i := 1; i <= 3; i++ {
+		errorLog.Run(nil, zerolog.ErrorLevel, fmt.Sprint("error ", i))
+	}
+
+	var messages []string
+	for _, e := range errorLog.Entries() {
+		messages = append(messages, e.Message)
+	}
+	assert.Equal(t, []string{"error 2", "error 3"}, messages)
 }
@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package diagnostics
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+const redacted = "<redacted>"
+
+var sensitiveKeys = []string{"password", "passphrase", "secret", "token", "private", "key", "email", "auth", "credential"}
+
+// sensitiveAssignment matches key=value and key: value pairs of sensitive keys in free form text.
+var sensitiveAssignment = regexp.MustCompile(`(?i)([\w.-]*(?:` + strings.Join(sensitiveKeys, "|") + `)[\w.-]*"?\s*[=:]\s*)("[^"]*"|[^\s,}&]+)`)
+
+// urlCredentials matches user info of URLs.
+var urlCredentials = regexp.MustCompile(`(\w+://)[^/@\s]+@`)
+
+// Redact returns a copy of the configuration with values of sensitive keys replaced.
+func Redact(config map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(config))
+	for k, v := range config {
+		switch {
+		case IsSensitiveKey(k):
+			result[k] = redacted
+		case isMap(v):
+			result[k] = Redact(v.(map[string]interface{}))
+		default:
+			result[k] = redactValue(v)
+		}
+	}
+	return result
+}
+
+// RedactLine hides sensitive values in a log line, JSON lines are redacted field by field.
+func RedactLine(line string) string {
+	var fields map[string]interface{}
+	if strings.HasPrefix(line, "{") && json.Unmarshal([]byte(line), &fields) == nil {
+		var out strings.Builder
+		encoder := json.NewEncoder(&out)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(Redact(fields)); err == nil {
+			return strings.TrimSuffix(out.String(), "\n")
+		}
+	}
+	return redactText(line)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case string:
+		return redactText(value)
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i := range value {
+			result[i] = redactValue(value[i])
+		}
+		return result
+	}
+	return v
+}
+
+// redactText hides sensitive assignments and credentials of URLs found in the text.
+func redactText(text string) string {
+	text = sensitiveAssignment.ReplaceAllString(text, "${1}"+redacted)
+	return urlCredentials.ReplaceAllString(text, "${1}"+redacted+"@")
+}
+
+// IsSensitiveKey returns true if the configuration key may hold a secret or personal value.
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func isMap(v interface{}) bool {
+	_, ok := v.(map[string]interface{})
+	return ok
+}
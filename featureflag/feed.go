@@ -0,0 +1,197 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package featureflag
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// signedFeed is the document served by the remote flag feed.
+// Payload is base64 encoded JSON of feedPayload, signature is the base64 encoded ed25519 signature of decoded payload.
+type signedFeed struct {
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+type feedPayload struct {
+	Flags map[Flag]bool `json:"flags"`
+	// IssuedAt prevents replaying an older feed.
+	IssuedAt time.Time `json:"issued_at"`
+}
+
+// Feed periodically fetches signed feature values and applies them as remote overrides.
+// The last applied feed is kept on disk, so that overrides and replay protection survive restarts.
+type Feed struct {
+	flags     *Flags
+	url       string
+	publicKey ed25519.PublicKey
+	interval  time.Duration
+	cachePath string
+	http      *http.Client
+
+	lock     sync.Mutex
+	issuedAt time.Time
+	stop     chan struct{}
+	once     sync.Once
+}
+
+// NewFeed creates remote flag feed, the last applied feed is cached at cachePath.
+func NewFeed(flags *Flags, url string, publicKey ed25519.PublicKey, interval time.Duration, cachePath string) *Feed {
+	return &Feed{
+		flags:     flags,
+		url:       url,
+		publicKey: publicKey,
+		interval:  interval,
+		cachePath: cachePath,
+		http:      &http.Client{Timeout: 20 * time.Second},
+		stop:      make(chan struct{}),
+	}
+}
+
+// Load applies the cached feed, it should be called before features are read at startup.
+func (f *Feed) Load() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	data, err := os.ReadFile(f.cachePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not read cached feature flag feed")
+	}
+
+	var feed signedFeed
+	if err := json.Unmarshal(data, &feed); err != nil {
+		return errors.Wrap(err, "could not parse cached feature flag feed")
+	}
+	return f.apply(feed)
+}
+
+// Start fetches the feed and keeps refreshing it in background.
+func (f *Feed) Start() {
+	go func() {
+		if err := f.Refresh(); err != nil {
+			log.Warn().Err(err).Msg("Failed to fetch remote feature flags")
+		}
+
+		ticker := time.NewTicker(f.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-f.stop:
+				return
+			case <-ticker.C:
+				if err := f.Refresh(); err != nil {
+					log.Warn().Err(err).Msg("Failed to refresh remote feature flags")
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops refreshing the feed.
+func (f *Feed) Stop() {
+	f.once.Do(func() {
+		close(f.stop)
+	})
+}
+
+// Refresh fetches the feed and applies it if its signature is valid.
+// Previously applied values stay in effect if the feed can't be fetched.
+func (f *Feed) Refresh() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	res, err := f.http.Get(f.url)
+	if err != nil {
+		return errors.Wrap(err, "could not fetch feature flag feed")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("feature flag feed request failed with status %d", res.StatusCode)
+	}
+
+	var feed signedFeed
+	if err := json.NewDecoder(res.Body).Decode(&feed); err != nil {
+		return errors.Wrap(err, "could not parse feature flag feed")
+	}
+	if err := f.apply(feed); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(feed)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(os.WriteFile(f.cachePath, data, 0600), "could not cache feature flag feed")
+}
+
+func (f *Feed) apply(feed signedFeed) error {
+	payload, err := verifyFeed(feed, f.publicKey)
+	if err != nil {
+		return err
+	}
+	if payload.IssuedAt.Before(f.issuedAt) {
+		return errors.New("feature flag feed is older than the applied one")
+	}
+	f.issuedAt = payload.IssuedAt
+
+	for _, state := range f.flags.setRemote(payload.Flags) {
+		log.Info().Msgf("Feature %q remotely set to enabled=%t", state.Name, state.Enabled)
+	}
+	return nil
+}
+
+func verifyFeed(feed signedFeed, publicKey ed25519.PublicKey) (feedPayload, error) {
+	var payload feedPayload
+	if len(publicKey) != ed25519.PublicKeySize {
+		return payload, errors.New("feature flag feed key is not configured")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(feed.Payload)
+	if err != nil {
+		return payload, errors.Wrap(err, "could not decode feature flag feed payload")
+	}
+	signature, err := base64.StdEncoding.DecodeString(feed.Signature)
+	if err != nil {
+		return payload, errors.Wrap(err, "could not decode feature flag feed signature")
+	}
+	if !ed25519.Verify(publicKey, data, signature) {
+		return payload, errors.New("invalid feature flag feed signature")
+	}
+
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return payload, errors.Wrap(err, "could not parse feature flag feed payload")
+	}
+	if payload.Flags == nil {
+		payload.Flags = map[Flag]bool{}
+	}
+	return payload, nil
+}
@@ -0,0 +1,169 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package featureflag
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Flag is the name of a feature gating a node subsystem.
+type Flag string
+
+const (
+	// MTUDiscovery gates path MTU discovery of tunnels.
+	MTUDiscovery Flag = "mtu-discovery"
+	// SelfUpdate gates node self-update.
+	SelfUpdate Flag = "self-update"
+	// Camouflage gates the TLS camouflage transport of providers.
+	Camouflage Flag = "camouflage"
+	// DirectTopup gates top-ups paid directly on chain.
+	DirectTopup Flag = "direct-topup"
+	// FreeTier gates free of charge provider sessions.
+	FreeTier Flag = "free-tier"
+)
+
+// Definition describes a known feature.
+type Definition struct {
+	Name        Flag
+	Description string
+	Default     bool
+}
+
+// Definitions lists all known features.
+var Definitions = []Definition{
+	{Name: MTUDiscovery, Description: "Discover path MTU of tunnels and clamp TCP MSS", Default: true},
+	{Name: SelfUpdate, Description: "Update node binary from release channels", Default: true},
+	{Name: Camouflage, Description: "Carry WireGuard traffic over TLS on the camouflage listener", Default: true},
+	{Name: DirectTopup, Description: "Offer top-ups paid directly on chain", Default: true},
+	{Name: FreeTier, Description: "Serve free tier allowance to consumers", Default: true},
+}
+
+// Source tells where the effective value of a feature comes from.
+type Source string
+
+const (
+	// SourceDefault value is the built-in default.
+	SourceDefault Source = "default"
+	// SourceLocal value is set in node configuration.
+	SourceLocal Source = "local"
+	// SourceRemote value is set by the remote flag feed.
+	SourceRemote Source = "remote"
+)
+
+// State is the effective state of a feature.
+type State struct {
+	Name        Flag   `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	Source      Source `json:"source"`
+}
+
+// Flags resolves feature states. Remote values override local configuration
+// so that a feature can be disabled without a new release.
+type Flags struct {
+	lock   sync.RWMutex
+	local  map[Flag]bool
+	remote map[Flag]bool
+}
+
+// NewFlags creates feature flags with the local configuration.
+func NewFlags(local map[Flag]bool) *Flags {
+	return &Flags{local: local, remote: map[Flag]bool{}}
+}
+
+// Enabled returns true if the feature is enabled.
+// Unknown features are disabled unless configured.
+func (f *Flags) Enabled(flag Flag) bool {
+	return f.state(flag).Enabled
+}
+
+// All returns states of all known features sorted by name.
+func (f *Flags) All() []State {
+	states := make([]State, 0, len(Definitions))
+	for _, def := range Definitions {
+		states = append(states, f.state(def.Name))
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+// setRemote replaces remote feature values and returns the features which changed state.
+func (f *Flags) setRemote(remote map[Flag]bool) []State {
+	before := f.All()
+
+	f.lock.Lock()
+	f.remote = remote
+	f.lock.Unlock()
+
+	var changed []State
+	for i, state := range f.All() {
+		if state.Enabled != before[i].Enabled {
+			changed = append(changed, state)
+		}
+	}
+	return changed
+}
+
+func (f *Flags) state(flag Flag) State {
+	state := State{Name: flag, Source: SourceDefault}
+	for _, def := range Definitions {
+		if def.Name == flag {
+			state.Description = def.Description
+			state.Enabled = def.Default
+		}
+	}
+
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	if enabled, ok := f.local[flag]; ok {
+		state.Enabled, state.Source = enabled, SourceLocal
+	}
+	if enabled, ok := f.remote[flag]; ok {
+		state.Enabled, state.Source = enabled, SourceRemote
+	}
+	return state
+}
+
+// ParseLocal parses comma separated feature values, e.g. "mtu-discovery=false,self-update".
+// A feature without value is enabled.
+func ParseLocal(value string) (map[Flag]bool, error) {
+	local := map[Flag]bool{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, raw, hasValue := strings.Cut(item, "=")
+		enabled := true
+		if hasValue {
+			var err error
+			if enabled, err = strconv.ParseBool(raw); err != nil {
+				return nil, errors.Wrapf(err, "invalid value of feature %q", name)
+			}
+		}
+		local[Flag(strings.TrimSpace(name))] = enabled
+	}
+	return local, nil
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package featureflag
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLocal(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected map[Flag]bool
+		err      bool
+	}{
+		{value: "", expected: map[Flag]bool{}},
+		{value: "mtu-discovery=false", expected: map[Flag]bool{MTUDiscovery: false}},
+		{value: "mtu-discovery=0, self-update", expected: map[Flag]bool{MTUDiscovery: false, SelfUpdate: true}},
+		{value: "self-update=maybe", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			local, err := ParseLocal(tt.value)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, local)
+		})
+	}
+}
+
+func TestFlags_Enabled(t *testing.T) {
+	flags := NewFlags(map[Flag]bool{SelfUpdate: false, "experimental": true})
+
+	assert.True(t, flags.Enabled(MTUDiscovery))
+	assert.False(t, flags.Enabled(SelfUpdate))
+	assert.True(t, flags.Enabled("experimental"))
+	assert.False(t, flags.Enabled("unknown"))
+
+	changed := flags.setRemote(map[Flag]bool{MTUDiscovery: false, SelfUpdate: false})
+	assert.Equal(t, []State{{Name: MTUDiscovery, Description: Definitions[0].Description, Enabled: false, Source: SourceRemote}}, changed)
+	assert.False(t, flags.Enabled(MTUDiscovery))
+}
+
+func TestFeed_Refresh(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	feed := signFeed(t, priv, feedPayload{Flags: map[Flag]bool{SelfUpdate: false}, IssuedAt: time.Now()})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(feed)
+	}))
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "features.json")
+	flags := NewFlags(nil)
+	f := NewFeed(flags, server.URL, pub, time.Minute, cachePath)
+	require.NoError(t, f.Load())
+	require.NoError(t, f.Refresh())
+	assert.False(t, flags.Enabled(SelfUpdate))
+
+	// Replayed older feed is rejected.
+	feed = signFeed(t, priv, feedPayload{Flags: map[Flag]bool{}, IssuedAt: time.Now().Add(-time.Hour)})
+	assert.Error(t, f.Refresh())
+	assert.False(t, flags.Enabled(SelfUpdate))
+
+	// Cached feed is applied and replay protected after restart.
+	restartedFlags := NewFlags(nil)
+	restarted := NewFeed(restartedFlags, server.URL, pub, time.Minute, cachePath)
+	require.NoError(t, restarted.Load())
+	assert.False(t, restartedFlags.Enabled(SelfUpdate))
+	assert.Error(t, restarted.Refresh())
+	assert.False(t, restartedFlags.Enabled(SelfUpdate))
+
+	// Feed signed by another key is rejected.
+	_, otherPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	feed = signFeed(t, otherPriv, feedPayload{Flags: map[Flag]bool{}, IssuedAt: time.Now()})
+	assert.EqualError(t, f.Refresh(), "invalid feature flag feed signature")
+	assert.False(t, flags.Enabled(SelfUpdate))
+}
+
+func signFeed(t *testing.T, key ed25519.PrivateKey, payload feedPayload) signedFeed {
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+	return signedFeed{
+		Payload:   base64.StdEncoding.EncodeToString(data),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)),
+	}
+}
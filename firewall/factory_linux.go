@@ -19,23 +19,36 @@
 
 package firewall
 
+import "github.com/mysteriumnetwork/node/firewall/nftables"
+
 // NewOutgoingTrafficFirewall creates firewall instance for outgoing traffic.
 func NewOutgoingTrafficFirewall(enabled bool) OutgoingTrafficFirewall {
-	if enabled {
-		return &outgoingFirewallIptables{
+	if !enabled {
+		return &outgoingFirewallNoop{}
+	}
+
+	if nftables.Preferred() {
+		return &outgoingFirewallNftables{
 			referenceTracker: make(map[string]refCount),
 			trafficLockScope: none,
 		}
 	}
 
-	return &outgoingFirewallNoop{}
+	return &outgoingFirewallIptables{
+		referenceTracker: make(map[string]refCount),
+		trafficLockScope: none,
+	}
 }
 
 // NewIncomingTrafficFirewall creates firewall instance for incoming traffic.
 func NewIncomingTrafficFirewall(enabled bool) IncomingTrafficFirewall {
-	if enabled {
-		return &incomingFirewallIptables{}
+	if !enabled {
+		return &incomingFirewallNoop{}
+	}
+
+	if nftables.Preferred() {
+		return &incomingFirewallNftables{}
 	}
 
-	return &incomingFirewallNoop{}
+	return &incomingFirewallIptables{}
 }
@@ -0,0 +1,35 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+// NewOutgoingTrafficFirewall creates firewall instance for outgoing traffic.
+func NewOutgoingTrafficFirewall(enabled bool) OutgoingTrafficFirewall {
+	if enabled {
+		return &outgoingFirewallWFP{
+			referenceTracker: make(map[string]refCount),
+			trafficLockScope: none,
+		}
+	}
+
+	return &outgoingFirewallNoop{}
+}
+
+// NewIncomingTrafficFirewall creates firewall instance for incoming traffic.
+func NewIncomingTrafficFirewall(enabled bool) IncomingTrafficFirewall {
+	return &incomingFirewallNoop{}
+}
@@ -19,6 +19,11 @@
 
 package firewall
 
+import "errors"
+
+// ErrAppAccessUnsupported is returned when the platform firewall cannot exempt applications from the kill switch.
+var ErrAppAccessUnsupported = errors.New("per application kill switch exceptions are only supported on Windows")
+
 // AddInboundRule adds new inbound rule to the platform specific firewall.
 func AddInboundRule(proto string, port int) error {
 	// TODO adding firewall rules should be implemented for every platform.
@@ -30,3 +35,8 @@ func RemoveInboundRule(proto string, port int) error {
 	// TODO adding firewall rules should be implemented for every platform.
 	return nil
 }
+
+// AllowApplicationAccess lets given application bypass the kill switch.
+func AllowApplicationAccess(path string) (OutgoingRuleRemove, error) {
+	return nil, ErrAppAccessUnsupported
+}
@@ -19,55 +19,114 @@ package firewall
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 
-	"github.com/mysteriumnetwork/node/utils/cmdutil"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
+	"golang.org/x/sys/windows"
+
+	"github.com/mysteriumnetwork/node/firewall/wfp"
 )
 
+var (
+	engineOnce sync.Once
+	engine     *wfp.Engine
+	engineErr  error
+
+	inboundLock  sync.Mutex
+	inboundRules = make(map[string]uint64)
+)
+
+// filteringEngine opens the shared WFP session, removing filters left behind by a previous run.
+func filteringEngine() (*wfp.Engine, error) {
+	engineOnce.Do(func() {
+		engine, engineErr = wfp.Open()
+		if engineErr == nil {
+			engineErr = engine.ResetSublayer()
+		}
+	})
+	return engine, engineErr
+}
+
 // AddInboundRule adds new inbound rule to the platform specific firewall.
 func AddInboundRule(proto string, port int) error {
 	name := fmt.Sprintf("myst-%d:%s", port, proto)
-	cmd := fmt.Sprintf(`netsh advfirewall firewall add rule name="%s" dir=in action=allow protocol=%s localport=%d`, name, proto, port)
 
-	if inboundRuleExists(name) {
+	inboundLock.Lock()
+	defer inboundLock.Unlock()
+
+	if _, ok := inboundRules[name]; ok {
 		return nil
 	}
 
-	_, err := cmdutil.PowerShell(cmd)
+	protocol, err := ipProtocol(proto)
+	if err != nil {
+		return err
+	}
+	engine, err := filteringEngine()
+	if err != nil {
+		return err
+	}
+
+	id, err := engine.AddFilter(wfp.Filter{
+		Name:       name,
+		Layer:      wfp.LayerRecvAcceptV4,
+		Action:     wfp.ActionPermit,
+		Weight:     15,
+		Conditions: []wfp.Condition{wfp.Protocol(protocol), wfp.LocalPort(uint16(port))},
+		Hard:       true,
+	})
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to add firewall rule")
 		return err
 	}
 
+	inboundRules[name] = id
 	return nil
 }
 
 // RemoveInboundRule removes inbound rule from the platform specific firewall.
 func RemoveInboundRule(proto string, port int) error {
 	name := fmt.Sprintf("myst-%d:%s", port, proto)
-	cmd := fmt.Sprintf(`netsh advfirewall firewall delete rule name="%s" dir=in`, name)
 
-	if !inboundRuleExists(name) {
+	inboundLock.Lock()
+	defer inboundLock.Unlock()
+
+	id, ok := inboundRules[name]
+	if !ok {
 		return errors.New("firewall rule not found")
 	}
 
-	_, err := cmdutil.PowerShell(cmd)
+	engine, err := filteringEngine()
 	if err != nil {
+		return err
+	}
+	if err := engine.DeleteFilter(id); err != nil {
 		log.Warn().Err(err).Msg("Failed to remove firewall rule")
 		return err
 	}
 
+	delete(inboundRules, name)
 	return nil
 }
 
-func inboundRuleExists(name string) bool {
-	cmd := fmt.Sprintf(`netsh advfirewall firewall show rule name="%s" dir=in`, name)
-
-	if _, err := cmdutil.PowerShell(cmd); err != nil {
-		log.Warn().Err(err).Msg("Failed to get firewall rule")
-		return false
+// AllowApplicationAccess lets given application bypass the kill switch.
+func AllowApplicationAccess(path string) (OutgoingRuleRemove, error) {
+	fw, ok := DefaultOutgoingFirewall.(*outgoingFirewallWFP)
+	if !ok {
+		return func() {}, nil
 	}
+	return fw.AllowApplicationAccess(path)
+}
 
-	return true
+func ipProtocol(proto string) (uint8, error) {
+	switch strings.ToLower(proto) {
+	case "tcp":
+		return windows.IPPROTO_TCP, nil
+	case "udp":
+		return windows.IPPROTO_UDP, nil
+	default:
+		return 0, errors.Errorf("unsupported protocol: %s", proto)
+	}
 }
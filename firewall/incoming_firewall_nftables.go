@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import (
+	"net"
+	"net/url"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+)
+
+const (
+	incomingFirewallTable        = "myst_provider_firewall"
+	incomingFirewallForwardChain = "forward"
+	incomingFirewallNftChain     = "firewall"
+	incomingFirewallSet          = "dst_whitelist"
+)
+
+// incomingFirewallNftables allows incoming traffic blocking in IP granularity.
+type incomingFirewallNftables struct{}
+
+func (ibn *incomingFirewallNftables) Setup() error {
+	if err := nftables.CreateTable("inet", incomingFirewallTable); err != nil {
+		return err
+	}
+	if _, err := nftables.Exec("add", "set", "inet", incomingFirewallTable, incomingFirewallSet, "{ type ipv4_addr; flags timeout; timeout 24h; }"); err != nil {
+		return err
+	}
+	if err := nftables.CreateChain("inet", incomingFirewallTable, incomingFirewallForwardChain, "type filter hook forward priority 0; policy accept;"); err != nil {
+		return err
+	}
+	if err := nftables.CreateChain("inet", incomingFirewallTable, incomingFirewallNftChain, ""); err != nil {
+		return err
+	}
+
+	// Packets going to firewall with whitelisted destination IPs are accepted, all the others are rejected
+	if _, err := nftables.Apply(nftables.AppendTo("inet", incomingFirewallTable, incomingFirewallNftChain).RuleSpec("ip", "daddr", "@"+incomingFirewallSet, "accept")); err != nil {
+		return err
+	}
+	_, err := nftables.Apply(nftables.AppendTo("inet", incomingFirewallTable, incomingFirewallNftChain).RuleSpec("reject"))
+	return err
+}
+
+func (ibn *incomingFirewallNftables) Teardown() {
+	if err := nftables.DeleteTable("inet", incomingFirewallTable); err != nil {
+		log.Warn().Err(err).Msg("Error cleaning up nftables rules, you might want to do it yourself")
+	}
+}
+
+func (ibn *incomingFirewallNftables) BlockIncomingTraffic(network net.IPNet) (IncomingRuleRemove, error) {
+	remover, err := nftables.AddRuleWithRemoval(
+		nftables.AppendTo("inet", incomingFirewallTable, incomingFirewallForwardChain).RuleSpec("ip", "saddr", network.String(), "jump", incomingFirewallNftChain),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		remover()
+		return nil
+	}, nil
+}
+
+// AllowURLAccess adds URL based exception.
+func (ibn *incomingFirewallNftables) AllowURLAccess(rawURLs ...string) (IncomingRuleRemove, error) {
+	var ruleRemovers []func()
+	removeAll := func() error {
+		for _, ruleRemover := range ruleRemovers {
+			ruleRemover()
+		}
+		return nil
+	}
+
+	for _, rawURL := range rawURLs {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+
+		match, err := nftables.DestinationMatch(parsed.Hostname())
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+
+		remover, err := nftables.AddRuleWithRemoval(
+			nftables.InsertInto("inet", incomingFirewallTable, incomingFirewallNftChain).RuleSpec(append(match, "accept")...),
+		)
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+		ruleRemovers = append(ruleRemovers, remover)
+	}
+	return removeAll, nil
+}
+
+func (ibn *incomingFirewallNftables) AllowIPAccess(ip net.IP) (IncomingRuleRemove, error) {
+	element := "{ " + ip.String() + " }"
+	if _, err := nftables.Exec("add", "element", "inet", incomingFirewallTable, incomingFirewallSet, element); err != nil {
+		return nil, err
+	}
+	return func() error {
+		_, err := nftables.Exec("delete", "element", "inet", incomingFirewallTable, incomingFirewallSet, element)
+		return err
+	}, nil
+}
+
+var _ IncomingTrafficFirewall = &incomingFirewallNftables{}
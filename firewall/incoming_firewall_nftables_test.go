@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+)
+
+func Test_incomingFirewallNftables_Setup(t *testing.T) {
+	mockedExec := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{
+			"--echo --handle add rule inet myst_provider_firewall firewall ip daddr @dst_whitelist accept": {
+				output: []string{"# handle 4"},
+			},
+			"--echo --handle add rule inet myst_provider_firewall firewall reject": {
+				output: []string{"# handle 5"},
+			},
+		},
+	}
+	nftables.Exec = mockedExec.Exec
+
+	fw := &incomingFirewallNftables{}
+	assert.NoError(t, fw.Setup())
+	assert.True(t, mockedExec.VerifyCalledWithArgs("delete", "table", "inet", incomingFirewallTable))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("add", "table", "inet", incomingFirewallTable))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("add", "set", "inet", incomingFirewallTable, incomingFirewallSet, "{ type ipv4_addr; flags timeout; timeout 24h; }"))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("add", "chain", "inet", incomingFirewallTable, incomingFirewallForwardChain, "{ type filter hook forward priority 0; policy accept; }"))
+
+	fw.Teardown()
+	assert.True(t, mockedExec.VerifyCalledWithArgs("delete", "table", "inet", incomingFirewallTable))
+}
+
+func Test_incomingFirewallNftables_AllowIPAccess(t *testing.T) {
+	mockedExec := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	nftables.Exec = mockedExec.Exec
+
+	fw := &incomingFirewallNftables{}
+	remove, err := fw.AllowIPAccess(net.ParseIP("8.8.8.8"))
+	assert.NoError(t, err)
+	assert.True(t, mockedExec.VerifyCalledWithArgs("add", "element", "inet", incomingFirewallTable, incomingFirewallSet, "{ 8.8.8.8 }"))
+
+	assert.NoError(t, remove())
+	assert.True(t, mockedExec.VerifyCalledWithArgs("delete", "element", "inet", incomingFirewallTable, incomingFirewallSet, "{ 8.8.8.8 }"))
+}
@@ -17,10 +17,14 @@
 
 package iptables
 
-import "strconv"
+import (
+	"encoding/json"
+	"strconv"
+)
 
 // Rule is a packet filter rule for IPTables.
 type Rule struct {
+	table     string
 	chainName string
 	action    []string
 	ruleSpec  []string
@@ -42,6 +46,12 @@ func InsertAt(chainName string, line int) Rule {
 	}
 }
 
+// InTable sets the table of the rule, filter table is used if not set.
+func (r Rule) InTable(table string) Rule {
+	r.table = table
+	return r
+}
+
 // RuleSpec sets the rule specification (see `man iptables`).
 func (r Rule) RuleSpec(spec ...string) Rule {
 	r.ruleSpec = spec
@@ -50,17 +60,30 @@ func (r Rule) RuleSpec(spec ...string) Rule {
 
 // ApplyArgs returns an argument list to be passed to the iptables executable to APPLY the rule.
 func (r Rule) ApplyArgs() []string {
-	return append(r.action, r.ruleSpec...)
+	return append(r.tableArgs(r.action...), r.ruleSpec...)
 }
 
 // RemoveArgs returns an argument list to be passed to the iptables executable to REMOVE the rule.
 func (r Rule) RemoveArgs() []string {
-	return append([]string{"-D", r.chainName}, r.ruleSpec...)
+	return append(r.tableArgs("-D", r.chainName), r.ruleSpec...)
+}
+
+// CheckArgs returns an argument list to be passed to the iptables executable to CHECK whether the rule exists.
+func (r Rule) CheckArgs() []string {
+	return append(r.tableArgs("-C", r.chainName), r.ruleSpec...)
+}
+
+func (r Rule) tableArgs(args ...string) []string {
+	if r.table == "" {
+		return append([]string{}, args...)
+	}
+	return append([]string{"--table", r.table}, args...)
 }
 
 // Equals checks if two Rules are equal.
 func (r Rule) Equals(another Rule) bool {
-	return r.chainName == another.chainName &&
+	return r.table == another.table &&
+		r.chainName == another.chainName &&
 		equalStringSlice(r.ruleSpec, another.ruleSpec)
 }
 
@@ -75,3 +98,25 @@ func equalStringSlice(a, b []string) bool {
 	}
 	return true
 }
+
+type ruleJSON struct {
+	Table    string   `json:"table,omitempty"`
+	Chain    string   `json:"chain"`
+	Action   []string `json:"action"`
+	RuleSpec []string `json:"rule_spec"`
+}
+
+// MarshalJSON serializes the rule, so that it can be stored and removed by a later process.
+func (r Rule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ruleJSON{Table: r.table, Chain: r.chainName, Action: r.action, RuleSpec: r.ruleSpec})
+}
+
+// UnmarshalJSON restores the rule serialized by MarshalJSON.
+func (r *Rule) UnmarshalJSON(data []byte) error {
+	var rj ruleJSON
+	if err := json.Unmarshal(data, &rj); err != nil {
+		return err
+	}
+	*r = Rule{table: rj.Table, chainName: rj.Chain, action: rj.Action, ruleSpec: rj.RuleSpec}
+	return nil
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package iptables
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRule_TableArgs(t *testing.T) {
+	rule := AppendTo("FORWARD").InTable("mangle").RuleSpec("--jump", "TCPMSS", "--clamp-mss-to-pmtu")
+
+	assert.Equal(t, []string{"--table", "mangle", "-A", "FORWARD", "--jump", "TCPMSS", "--clamp-mss-to-pmtu"}, rule.ApplyArgs())
+	assert.Equal(t, []string{"--table", "mangle", "-D", "FORWARD", "--jump", "TCPMSS", "--clamp-mss-to-pmtu"}, rule.RemoveArgs())
+	assert.False(t, rule.Equals(AppendTo("FORWARD").RuleSpec("--jump", "TCPMSS", "--clamp-mss-to-pmtu")))
+
+	rule = InsertAt("INPUT", 1).RuleSpec("--jump", "ACCEPT")
+	assert.Equal(t, []string{"-I", "INPUT", "1", "--jump", "ACCEPT"}, rule.ApplyArgs())
+	assert.Equal(t, []string{"-D", "INPUT", "--jump", "ACCEPT"}, rule.RemoveArgs())
+}
+
+func TestRule_JSON(t *testing.T) {
+	rule := InsertAt("PREROUTING", 1).InTable("nat").RuleSpec("--source", "10.182.0.0/24", "--jump", "MYST")
+
+	data, err := json.Marshal(rule)
+	require.NoError(t, err)
+
+	var restored Rule
+	require.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, rule, restored)
+	assert.Equal(t, []string{"--table", "nat", "-C", "PREROUTING", "--source", "10.182.0.0/24", "--jump", "MYST"}, restored.CheckArgs())
+}
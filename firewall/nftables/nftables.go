@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nftables
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/firewall/iptables"
+	"github.com/mysteriumnetwork/node/utils/cmdutil"
+)
+
+// Exec executes given args
+var Exec = defaultExec
+
+func defaultExec(args ...string) ([]string, error) {
+	args = append([]string{"sudo", "/usr/sbin/nft"}, args...)
+	output, err := cmdutil.ExecOutput(args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "nft cmd error")
+	}
+
+	outputScanner := bufio.NewScanner(bytes.NewBufferString(output))
+	var lines []string
+	for outputScanner.Scan() {
+		lines = append(lines, outputScanner.Text())
+	}
+	return lines, outputScanner.Err()
+}
+
+// Preferred detects whether nftables should be used instead of iptables.
+// It is the case on systems which ship nft without the iptables layer.
+func Preferred() bool {
+	if _, err := iptables.Exec("--version"); err == nil {
+		return false
+	}
+	if _, err := Exec("--version"); err != nil {
+		return false
+	}
+	log.Info().Msg("iptables is not available, using nftables firewall backend")
+	return true
+}
+
+// CreateTable creates a table, recreating it from scratch if it already exists.
+func CreateTable(family, table string) error {
+	DeleteTable(family, table)
+	_, err := Exec("add", "table", family, table)
+	return err
+}
+
+// DeleteTable removes a table together with all of its chains, sets and rules.
+func DeleteTable(family, table string) error {
+	_, err := Exec("delete", "table", family, table)
+	return err
+}
+
+// CreateChain creates a chain. A chain with a spec like
+// "type filter hook output priority 0; policy accept;" is a base chain attached to a netfilter hook.
+func CreateChain(family, table, chain, spec string) error {
+	args := []string{"add", "chain", family, table, chain}
+	if spec != "" {
+		args = append(args, "{ "+spec+" }")
+	}
+	_, err := Exec(args...)
+	return err
+}
+
+var handleRegexp = regexp.MustCompile(`# handle (\d+)$`)
+
+// Apply activates given rule and returns it with the handle assigned by the kernel.
+func Apply(rule Rule) (Rule, error) {
+	output, err := Exec(rule.ApplyArgs()...)
+	if err != nil {
+		return rule, err
+	}
+	for _, line := range output {
+		if match := handleRegexp.FindStringSubmatch(line); match != nil {
+			rule.handle = match[1]
+			return rule, nil
+		}
+	}
+	return rule, errors.Errorf("no rule handle found in nft output: %v", output)
+}
+
+// Remove removes previously applied rule.
+func Remove(rule Rule) error {
+	if rule.handle == "" {
+		return errors.New("rule was not applied")
+	}
+	_, err := Exec(rule.RemoveArgs()...)
+	return err
+}
+
+// AddRuleWithRemoval activates given rule
+func AddRuleWithRemoval(rule Rule) (func(), error) {
+	applied, err := Apply(rule)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		if err := Remove(applied); err != nil {
+			log.Warn().Err(err).Msgf("Error executing rule: %v you might wanna do it yourself", applied.RemoveArgs())
+		}
+	}, nil
+}
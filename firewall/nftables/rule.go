@@ -0,0 +1,131 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nftables
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Rule is a packet filter rule for nftables.
+type Rule struct {
+	family   string
+	table    string
+	chain    string
+	action   string
+	ruleSpec []string
+	handle   string
+}
+
+// AppendTo creates a new rule to be appended to the specified chain.
+func AppendTo(family, table, chain string) Rule {
+	return Rule{family: family, table: table, chain: chain, action: "add"}
+}
+
+// InsertInto creates a new rule to be inserted at the beginning of the specified chain.
+func InsertInto(family, table, chain string) Rule {
+	return Rule{family: family, table: table, chain: chain, action: "insert"}
+}
+
+// RuleSpec sets the rule specification (see `man nft`).
+func (r Rule) RuleSpec(spec ...string) Rule {
+	r.ruleSpec = spec
+	return r
+}
+
+// Handle returns the handle assigned to the applied rule.
+func (r Rule) Handle() string {
+	return r.handle
+}
+
+// ApplyArgs returns an argument list to be passed to the nft executable to APPLY the rule.
+// The rule is echoed back together with its handle, which is needed to remove it later.
+func (r Rule) ApplyArgs() []string {
+	return append([]string{"--echo", "--handle", r.action, "rule", r.family, r.table, r.chain}, r.ruleSpec...)
+}
+
+// RemoveArgs returns an argument list to be passed to the nft executable to REMOVE the rule.
+func (r Rule) RemoveArgs() []string {
+	return []string{"delete", "rule", r.family, r.table, r.chain, "handle", r.handle}
+}
+
+// Equals checks if two Rules are equal.
+func (r Rule) Equals(another Rule) bool {
+	if r.family != another.family || r.table != another.table || r.chain != another.chain {
+		return false
+	}
+	if len(r.ruleSpec) != len(another.ruleSpec) {
+		return false
+	}
+	for i := range r.ruleSpec {
+		if r.ruleSpec[i] != another.ruleSpec[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type ruleJSON struct {
+	Family   string   `json:"family"`
+	Table    string   `json:"table"`
+	Chain    string   `json:"chain"`
+	Action   string   `json:"action"`
+	RuleSpec []string `json:"rule_spec"`
+	Handle   string   `json:"handle,omitempty"`
+}
+
+// MarshalJSON serializes the rule, so that it can be stored and removed by a later process.
+func (r Rule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ruleJSON{Family: r.family, Table: r.table, Chain: r.chain, Action: r.action, RuleSpec: r.ruleSpec, Handle: r.handle})
+}
+
+// UnmarshalJSON restores the rule serialized by MarshalJSON.
+func (r *Rule) UnmarshalJSON(data []byte) error {
+	var rj ruleJSON
+	if err := json.Unmarshal(data, &rj); err != nil {
+		return err
+	}
+	*r = Rule{family: rj.Family, table: rj.Table, chain: rj.Chain, action: rj.Action, ruleSpec: rj.RuleSpec, handle: rj.Handle}
+	return nil
+}
+
+// DestinationMatch returns a rule expression matching IPv4 destination of the given host.
+// Host names are resolved, since nft does not accept names resolving to multiple addresses.
+func DestinationMatch(host string) ([]string, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []string{"ip", "daddr", ip.String()}, nil
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not resolve %s", host)
+	}
+	var addrs []string
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			addrs = append(addrs, ip.String())
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, errors.Errorf("no IPv4 addresses found for %s", host)
+	}
+	return []string{"ip", "daddr", "{ " + strings.Join(addrs, ", ") + " }"}, nil
+}
@@ -17,6 +17,8 @@
 
 package firewall
 
+import "fmt"
+
 const (
 	// Global scope overrides session scope and is not affected by session scope calls.
 	Global Scope = "global"
@@ -26,6 +28,21 @@ const (
 	none Scope = ""
 )
 
+// DNSLeakProtection defines how strictly DNS traffic not destined to the tunnel resolvers is blocked.
+type DNSLeakProtection string
+
+const (
+	// DNSLeakProtectionOff does not restrict DNS traffic.
+	DNSLeakProtectionOff DNSLeakProtection = "off"
+	// DNSLeakProtectionStandard blocks DNS traffic leaving outside of the tunnel, unless it is sent to the tunnel resolvers.
+	DNSLeakProtectionStandard DNSLeakProtection = "standard"
+	// DNSLeakProtectionStrict blocks DNS traffic to any resolver but the tunnel and local ones, also inside of the tunnel.
+	DNSLeakProtectionStrict DNSLeakProtection = "strict"
+)
+
+// dnsPorts are plain DNS and DNS over TLS ports.
+var dnsPorts = []int{53, 853}
+
 // DefaultOutgoingFirewall outgoing traffic firewall bootstrapped for global calls.
 var DefaultOutgoingFirewall OutgoingTrafficFirewall = &outgoingFirewallNoop{}
 
@@ -38,6 +55,7 @@ type OutgoingTrafficFirewall interface {
 	BlockOutgoingTraffic(scope Scope, outboundIP string) (OutgoingRuleRemove, error)
 	AllowIPAccess(ip string) (OutgoingRuleRemove, error)
 	AllowURLAccess(rawURLs ...string) (OutgoingRuleRemove, error)
+	BlockDNSLeaks(protection DNSLeakProtection, outboundIP string, resolvers []string) (OutgoingRuleRemove, error)
 }
 
 // Scope type represents scope of blocking consumer traffic.
@@ -61,6 +79,18 @@ func AllowIPAccess(ip string) (OutgoingRuleRemove, error) {
 	return DefaultOutgoingFirewall.AllowIPAccess(ip)
 }
 
+// BlockDNSLeaks blocks outgoing DNS traffic not destined to the given tunnel resolvers.
+func BlockDNSLeaks(protection DNSLeakProtection, outboundIP string, resolvers []string) (OutgoingRuleRemove, error) {
+	switch protection {
+	case DNSLeakProtectionOff, "":
+		return func() {}, nil
+	case DNSLeakProtectionStandard, DNSLeakProtectionStrict:
+		return DefaultOutgoingFirewall.BlockDNSLeaks(protection, outboundIP, resolvers)
+	default:
+		return nil, fmt.Errorf("unknown DNS leak protection: %q", protection)
+	}
+}
+
 // Reset firewall state - usually called when cleanup is needed (during shutdown).
 func Reset() {
 	DefaultOutgoingFirewall.Teardown()
@@ -18,7 +18,9 @@
 package firewall
 
 import (
+	"net"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -26,7 +28,10 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-const killswitchChain = "MYST_CONSUMER_KILL_SWITCH"
+const (
+	killswitchChain = "MYST_CONSUMER_KILL_SWITCH"
+	dnsLeakChain    = "MYST_CONSUMER_DNS_LEAK"
+)
 
 type refCount struct {
 	count int
@@ -106,6 +111,87 @@ func (obi *outgoingFirewallIptables) AllowURLAccess(rawURLs ...string) (Outgoing
 	return removeAll, nil
 }
 
+// BlockDNSLeaks rejects outgoing DNS traffic not destined to the given resolvers.
+func (obi *outgoingFirewallIptables) BlockDNSLeaks(protection DNSLeakProtection, outboundIP string, resolvers []string) (OutgoingRuleRemove, error) {
+	return obi.trackingReferenceCall("dns-leak", func() (OutgoingRuleRemove, error) {
+		var source string
+		if protection != DNSLeakProtectionStrict {
+			source = outboundIP
+		}
+		removeIPv4, err := blockDNSLeaks(iptables.Exec, iptables.AddRuleWithRemoval, "127.0.0.0/8", source, resolversOfFamily(resolvers, false))
+		if err != nil {
+			return nil, err
+		}
+
+		// The outbound IPv6 address is not known, so IPv6 DNS traffic is matched regardless of its source.
+		removeIPv6, err := blockDNSLeaks(iptables.Exec6, iptables.AddRule6WithRemoval, "::1/128", "", resolversOfFamily(resolvers, true))
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to block IPv6 DNS leaks, IPv6 DNS traffic is not protected")
+			return removeIPv4, nil
+		}
+		return func() {
+			removeIPv6()
+			removeIPv4()
+		}, nil
+	})
+}
+
+// blockDNSLeaks creates the DNS leak chain using the given iptables binary and jumps to it from OUTPUT.
+func blockDNSLeaks(
+	exec func(args ...string) ([]string, error),
+	addRule func(rule iptables.Rule) (func(), error),
+	loopback, source string,
+	resolvers []string,
+) (func(), error) {
+	if _, err := exec("-N", dnsLeakChain); err != nil {
+		return nil, err
+	}
+	removeChain := func() {
+		if _, err := exec("-F", dnsLeakChain); err != nil {
+			log.Warn().Err(err).Msg("Error flushing DNS leak chain, you might want to do it yourself")
+		}
+		if _, err := exec("-X", dnsLeakChain); err != nil {
+			log.Warn().Err(err).Msg("Error removing DNS leak chain, you might want to do it yourself")
+		}
+	}
+
+	// Local resolvers (e.g. systemd-resolved) forward queries to the tunnel resolvers.
+	destinations := append([]string{loopback}, resolvers...)
+	for _, destination := range destinations {
+		if _, err := exec("-A", dnsLeakChain, "-d", destination, "-j", "RETURN"); err != nil {
+			removeChain()
+			return nil, err
+		}
+	}
+	if _, err := exec("-A", dnsLeakChain, "-j", "REJECT"); err != nil {
+		removeChain()
+		return nil, err
+	}
+
+	var ruleRemovers []func()
+	removeAll := func() {
+		for _, ruleRemover := range ruleRemovers {
+			ruleRemover()
+		}
+		removeChain()
+	}
+	for _, proto := range []string{"udp", "tcp"} {
+		for _, port := range dnsPorts {
+			spec := []string{"-p", proto, "--dport", strconv.Itoa(port), "-j", dnsLeakChain}
+			if source != "" {
+				spec = append([]string{"-s", source}, spec...)
+			}
+			remover, err := addRule(iptables.InsertAt("OUTPUT", 1).RuleSpec(spec...))
+			if err != nil {
+				removeAll()
+				return nil, err
+			}
+			ruleRemovers = append(ruleRemovers, remover)
+		}
+	}
+	return removeAll, nil
+}
+
 func (obi *outgoingFirewallIptables) checkIptablesVersion() error {
 	output, err := iptables.Exec("--version")
 	if err != nil {
@@ -140,44 +226,79 @@ func (obi *outgoingFirewallIptables) setupKillSwitchChain() error {
 }
 
 func (obi *outgoingFirewallIptables) cleanupStaleRules() error {
+	if err := cleanupStaleRules(iptables.Exec, killswitchChain, dnsLeakChain); err != nil {
+		return err
+	}
+	// ip6tables might be missing on hosts without IPv6, only the DNS leak chain is created there.
+	if err := cleanupStaleRules(iptables.Exec6, dnsLeakChain); err != nil {
+		log.Warn().Err(err).Msg("Error cleaning up ip6tables rules, you might want to do it yourself")
+	}
+	return nil
+}
+
+func cleanupStaleRules(exec func(args ...string) ([]string, error), chains ...string) error {
 	// List rules
-	rules, err := iptables.Exec("-S", "OUTPUT")
+	rules, err := exec("-S", "OUTPUT")
 	if err != nil {
 		return err
 	}
 	for _, rule := range rules {
-		// detect if any references exist in OUTPUT chain like -j MYST_CONSUMER_KILL_SWITCH
-		if strings.HasSuffix(rule, killswitchChain) {
-			deleteRule := strings.Replace(rule, "-A", "-D", 1)
-			deleteRuleArgs := strings.Split(deleteRule, " ")
-			if _, err := iptables.Exec(deleteRuleArgs...); err != nil {
-				return err
+		for _, chain := range chains {
+			// detect if any references exist in OUTPUT chain like -j MYST_CONSUMER_KILL_SWITCH
+			if strings.HasSuffix(rule, chain) {
+				deleteRule := strings.Replace(rule, "-A", "-D", 1)
+				deleteRuleArgs := strings.Split(deleteRule, " ")
+				if _, err := exec(deleteRuleArgs...); err != nil {
+					return err
+				}
 			}
 		}
 	}
 
-	// List chain rules
-	if _, err := iptables.Exec("-L", killswitchChain); err != nil {
-		// error means no such chain - log error just in case and bail out
-		log.Info().Err(err).Msg("[setup] Got error while listing kill switch chain rules. Probably nothing to worry about")
-		return nil
-	}
+	for _, chain := range chains {
+		// List chain rules
+		if _, err := exec("-L", chain); err != nil {
+			// error means no such chain - log error just in case and move on
+			log.Info().Err(err).Msgf("[setup] Got error while listing %s chain rules. Probably nothing to worry about", chain)
+			continue
+		}
 
-	// Remove chain rules
-	if _, err := iptables.Exec("-F", killswitchChain); err != nil {
-		return err
+		// Remove chain rules
+		if _, err := exec("-F", chain); err != nil {
+			return err
+		}
+
+		// Remove chain
+		if _, err := exec("-X", chain); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	// Remove chain
-	_, err = iptables.Exec("-X", killswitchChain)
-	return err
+// resolversOfFamily filters resolvers by IP family, as rules of each family match its own traffic only.
+func resolversOfFamily(resolvers []string, ipv6 bool) []string {
+	var ips []string
+	for _, resolver := range resolvers {
+		ip := net.ParseIP(resolver)
+		if ip == nil || (ip.To4() == nil) != ipv6 {
+			continue
+		}
+		ips = append(ips, ip.String())
+	}
+	return ips
 }
 
 func (obi *outgoingFirewallIptables) trackingReferenceCall(ref string, actualCall func() (OutgoingRuleRemove, error)) (OutgoingRuleRemove, error) {
-	obi.lock.Lock()
-	defer obi.lock.Unlock()
+	return trackingReferenceCall(&obi.lock, obi.referenceTracker, ref, actualCall)
+}
+
+// trackingReferenceCall applies the rule on first reference only and removes it once the last reference is released.
+func trackingReferenceCall(lock *sync.Mutex, referenceTracker map[string]refCount, ref string, actualCall func() (OutgoingRuleRemove, error)) (OutgoingRuleRemove, error) {
+	lock.Lock()
+	defer lock.Unlock()
 
-	refCount := obi.referenceTracker[ref]
+	refCount := referenceTracker[ref]
 	if refCount.count == 0 {
 		removeRule, err := actualCall()
 		if err != nil {
@@ -186,23 +307,23 @@ func (obi *outgoingFirewallIptables) trackingReferenceCall(ref string, actualCal
 		refCount.f = removeRule
 
 		refCount.count++
-		obi.referenceTracker[ref] = refCount
+		referenceTracker[ref] = refCount
 	}
 
-	return obi.decreaseRefCall(ref), nil
+	return decreaseRefCall(lock, referenceTracker, ref), nil
 }
 
-func (obi *outgoingFirewallIptables) decreaseRefCall(ref string) OutgoingRuleRemove {
+func decreaseRefCall(lock *sync.Mutex, referenceTracker map[string]refCount, ref string) OutgoingRuleRemove {
 	return func() {
-		obi.lock.Lock()
-		defer obi.lock.Unlock()
+		lock.Lock()
+		defer lock.Unlock()
 
-		refCount := obi.referenceTracker[ref]
+		refCount := referenceTracker[ref]
 		if refCount.count == 1 {
 			refCount.f()
 
 			refCount.count--
-			obi.referenceTracker[ref] = refCount
+			referenceTracker[ref] = refCount
 		}
 	}
 }
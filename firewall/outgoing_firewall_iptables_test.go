@@ -123,6 +123,7 @@ func Test_outgoingFirewallIptables_SetupIsSuccessful(t *testing.T) {
 		},
 	}
 	iptables.Exec = mockedExec.Exec
+	iptables.Exec6 = (&iptablesExecMock{mocks: map[string]iptablesExecResult{}}).Exec
 
 	fw := &outgoingFirewallIptables{
 		referenceTracker: make(map[string]refCount),
@@ -156,6 +157,7 @@ func Test_outgoingFirewallIptables_SetupIsSucessfulIfPreviousCleanupFailed(t *te
 		},
 	}
 	iptables.Exec = mockedExec.Exec
+	iptables.Exec6 = (&iptablesExecMock{mocks: map[string]iptablesExecResult{}}).Exec
 
 	fw := &outgoingFirewallIptables{
 		referenceTracker: make(map[string]refCount),
@@ -192,6 +194,7 @@ func Test_outgoingFirewallIptables_ResetIsSuccessful(t *testing.T) {
 		},
 	}
 	iptables.Exec = mockedExec.Exec
+	iptables.Exec6 = (&iptablesExecMock{mocks: map[string]iptablesExecResult{}}).Exec
 
 	fw := &outgoingFirewallIptables{
 		referenceTracker: make(map[string]refCount),
@@ -220,3 +223,60 @@ func Test_outgoingFirewallIptables_AddsAllowedIP(t *testing.T) {
 	assert.True(t, mockedExec.VerifyCalledWithArgs("-D", killswitchChain, "-d", "2.2.2.2", "-j", "ACCEPT"))
 
 }
+
+func Test_outgoingFirewallIptables_BlocksDNSLeaks(t *testing.T) {
+	mockedExec := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	mockedExec6 := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	iptables.Exec = mockedExec.Exec
+	iptables.Exec6 = mockedExec6.Exec
+
+	fw := &outgoingFirewallIptables{
+		referenceTracker: make(map[string]refCount),
+	}
+
+	removeRule, err := fw.BlockDNSLeaks(DNSLeakProtectionStandard, "1.1.1.1", []string{"10.8.0.1", "fd00::1"})
+	assert.NoError(t, err)
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-N", dnsLeakChain))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-A", dnsLeakChain, "-d", "127.0.0.0/8", "-j", "RETURN"))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-A", dnsLeakChain, "-d", "10.8.0.1", "-j", "RETURN"))
+	assert.False(t, mockedExec.VerifyCalledWithArgs("-A", dnsLeakChain, "-d", "fd00::1", "-j", "RETURN"))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-A", dnsLeakChain, "-j", "REJECT"))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-I", "OUTPUT", "1", "-s", "1.1.1.1", "-p", "udp", "--dport", "53", "-j", dnsLeakChain))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-I", "OUTPUT", "1", "-s", "1.1.1.1", "-p", "tcp", "--dport", "853", "-j", dnsLeakChain))
+
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-N", dnsLeakChain))
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-A", dnsLeakChain, "-d", "::1/128", "-j", "RETURN"))
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-A", dnsLeakChain, "-d", "fd00::1", "-j", "RETURN"))
+	assert.False(t, mockedExec6.VerifyCalledWithArgs("-A", dnsLeakChain, "-d", "10.8.0.1", "-j", "RETURN"))
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-A", dnsLeakChain, "-j", "REJECT"))
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-I", "OUTPUT", "1", "-p", "udp", "--dport", "53", "-j", dnsLeakChain))
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-I", "OUTPUT", "1", "-p", "tcp", "--dport", "853", "-j", dnsLeakChain))
+
+	removeRule()
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-D", "OUTPUT", "-s", "1.1.1.1", "-p", "udp", "--dport", "53", "-j", dnsLeakChain))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-X", dnsLeakChain))
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-D", "OUTPUT", "-p", "udp", "--dport", "53", "-j", dnsLeakChain))
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-X", dnsLeakChain))
+	assert.Equal(t, 0, fw.referenceTracker["dns-leak"].count)
+}
+
+func Test_outgoingFirewallIptables_StrictDNSLeakProtectionMatchesAllInterfaces(t *testing.T) {
+	mockedExec := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	iptables.Exec = mockedExec.Exec
+	iptables.Exec6 = (&iptablesExecMock{mocks: map[string]iptablesExecResult{}}).Exec
+
+	fw := &outgoingFirewallIptables{
+		referenceTracker: make(map[string]refCount),
+	}
+
+	_, err := fw.BlockDNSLeaks(DNSLeakProtectionStrict, "1.1.1.1", []string{"10.8.0.1"})
+	assert.NoError(t, err)
+	assert.True(t, mockedExec.VerifyCalledWithArgs("-I", "OUTPUT", "1", "-p", "udp", "--dport", "53", "-j", dnsLeakChain))
+	assert.False(t, mockedExec.VerifyCalledWithArgs("-I", "OUTPUT", "1", "-s", "1.1.1.1", "-p", "udp", "--dport", "53", "-j", dnsLeakChain))
+}
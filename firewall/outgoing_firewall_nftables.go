@@ -0,0 +1,187 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+)
+
+const (
+	killswitchTable       = "myst_consumer_kill_switch"
+	killswitchOutputChain = "output"
+	killswitchNftChain    = "kill_switch"
+	dnsLeakNftChain       = "dns_leak"
+)
+
+type outgoingFirewallNftables struct {
+	lock             sync.Mutex
+	trafficLockScope Scope
+	referenceTracker map[string]refCount
+}
+
+// Setup prepares kill switch table, cleaning up any leftovers from previous runs.
+func (obn *outgoingFirewallNftables) Setup() error {
+	if err := nftables.CreateTable("inet", killswitchTable); err != nil {
+		return err
+	}
+	if err := nftables.CreateChain("inet", killswitchTable, killswitchOutputChain, "type filter hook output priority 0; policy accept;"); err != nil {
+		return err
+	}
+	if err := nftables.CreateChain("inet", killswitchTable, killswitchNftChain, ""); err != nil {
+		return err
+	}
+
+	// By default all new connections going to kill switch chain are rejected,
+	// except DNS traffic which is always allowed for now, same as with iptables.
+	for _, spec := range [][]string{
+		{"udp", "dport", "53", "accept"},
+		{"tcp", "dport", "53", "accept"},
+		{"ct", "state", "new", "reject"},
+	} {
+		if _, err := nftables.Apply(nftables.AppendTo("inet", killswitchTable, killswitchNftChain).RuleSpec(spec...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Teardown removes kill switch table with all of its rules.
+func (obn *outgoingFirewallNftables) Teardown() {
+	if err := nftables.DeleteTable("inet", killswitchTable); err != nil {
+		log.Warn().Err(err).Msg("Error cleaning up nftables rules, you might want to do it yourself")
+	}
+}
+
+// BlockOutgoingTraffic effectively disallows any outgoing traffic from consumer node with specified scope.
+func (obn *outgoingFirewallNftables) BlockOutgoingTraffic(scope Scope, outboundIP string) (OutgoingRuleRemove, error) {
+	if obn.trafficLockScope == Global {
+		// nothing can override global lock
+		return func() {}, nil
+	}
+	obn.trafficLockScope = scope
+	return trackingReferenceCall(&obn.lock, obn.referenceTracker, "block-traffic", func() (OutgoingRuleRemove, error) {
+		return nftables.AddRuleWithRemoval(
+			nftables.AppendTo("inet", killswitchTable, killswitchOutputChain).RuleSpec("ip", "saddr", outboundIP, "jump", killswitchNftChain),
+		)
+	})
+}
+
+// AllowIPAccess adds exception to blocked traffic for specified IP or host name.
+func (obn *outgoingFirewallNftables) AllowIPAccess(ip string) (OutgoingRuleRemove, error) {
+	return trackingReferenceCall(&obn.lock, obn.referenceTracker, "allow:"+ip, func() (OutgoingRuleRemove, error) {
+		match, err := nftables.DestinationMatch(ip)
+		if err != nil {
+			return nil, err
+		}
+		return nftables.AddRuleWithRemoval(
+			nftables.InsertInto("inet", killswitchTable, killswitchNftChain).RuleSpec(append(match, "accept")...),
+		)
+	})
+}
+
+// AllowURLAccess adds URL based exception.
+func (obn *outgoingFirewallNftables) AllowURLAccess(rawURLs ...string) (OutgoingRuleRemove, error) {
+	var ruleRemovers []func()
+	removeAll := func() {
+		for _, ruleRemover := range ruleRemovers {
+			ruleRemover()
+		}
+	}
+	for _, rawURL := range rawURLs {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+
+		remover, err := obn.AllowIPAccess(parsed.Hostname())
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+		ruleRemovers = append(ruleRemovers, remover)
+	}
+	return removeAll, nil
+}
+
+// BlockDNSLeaks rejects outgoing DNS traffic not destined to the given resolvers.
+func (obn *outgoingFirewallNftables) BlockDNSLeaks(protection DNSLeakProtection, outboundIP string, resolvers []string) (OutgoingRuleRemove, error) {
+	return trackingReferenceCall(&obn.lock, obn.referenceTracker, "dns-leak", func() (OutgoingRuleRemove, error) {
+		if err := nftables.CreateChain("inet", killswitchTable, dnsLeakNftChain, ""); err != nil {
+			return nil, err
+		}
+
+		var ruleRemovers []func()
+		removeAll := func() {
+			for i := len(ruleRemovers) - 1; i >= 0; i-- {
+				ruleRemovers[i]()
+			}
+		}
+		addRule := func(rule nftables.Rule) error {
+			remover, err := nftables.AddRuleWithRemoval(rule)
+			if err != nil {
+				removeAll()
+				return err
+			}
+			ruleRemovers = append(ruleRemovers, remover)
+			return nil
+		}
+
+		// Local resolvers (e.g. systemd-resolved) forward queries to the tunnel resolvers.
+		destinations := append([]string{"127.0.0.0/8"}, resolversOfFamily(resolvers, false)...)
+		destinations6 := append([]string{"::1"}, resolversOfFamily(resolvers, true)...)
+		for _, spec := range [][]string{
+			{"ip", "daddr", "{ " + strings.Join(destinations, ", ") + " }", "return"},
+			{"ip6", "daddr", "{ " + strings.Join(destinations6, ", ") + " }", "return"},
+			{"reject"},
+		} {
+			if err := addRule(nftables.AppendTo("inet", killswitchTable, dnsLeakNftChain).RuleSpec(spec...)); err != nil {
+				return nil, err
+			}
+		}
+
+		ports := make([]string, len(dnsPorts))
+		for i, port := range dnsPorts {
+			ports[i] = strconv.Itoa(port)
+		}
+		spec := []string{"meta", "l4proto", "{ tcp, udp }", "th", "dport", "{ " + strings.Join(ports, ", ") + " }", "jump", dnsLeakNftChain}
+		specs := [][]string{spec}
+		if protection != DNSLeakProtectionStrict {
+			// The outbound IPv6 address is not known, so IPv6 DNS traffic is matched regardless of its source.
+			specs = [][]string{
+				append([]string{"ip", "saddr", outboundIP}, spec...),
+				append([]string{"meta", "nfproto", "ipv6"}, spec...),
+			}
+		}
+		for _, spec := range specs {
+			if err := addRule(nftables.InsertInto("inet", killswitchTable, killswitchOutputChain).RuleSpec(spec...)); err != nil {
+				return nil, err
+			}
+		}
+		return removeAll, nil
+	})
+}
+
+var _ OutgoingTrafficFirewall = &outgoingFirewallNftables{}
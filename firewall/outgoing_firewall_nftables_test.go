@@ -0,0 +1,122 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+)
+
+func Test_outgoingFirewallNftables_BlocksAllOutgoingTraffic(t *testing.T) {
+	mockedExec := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{
+			"--echo --handle add rule inet myst_consumer_kill_switch output ip saddr 1.1.1.1 jump kill_switch": {
+				output: []string{"add rule inet myst_consumer_kill_switch output ip saddr 1.1.1.1 jump kill_switch # handle 7"},
+			},
+		},
+	}
+	nftables.Exec = mockedExec.Exec
+
+	fw := &outgoingFirewallNftables{
+		referenceTracker: make(map[string]refCount),
+	}
+
+	removeRuleFunc, err := fw.BlockOutgoingTraffic("test-scope", "1.1.1.1")
+	assert.NoError(t, err)
+
+	removeRuleFunc()
+	assert.True(t, mockedExec.VerifyCalledWithArgs("delete", "rule", "inet", killswitchTable, killswitchOutputChain, "handle", "7"))
+}
+
+func Test_outgoingFirewallNftables_AllowIPAccessIsAddedAndRemoved(t *testing.T) {
+	mockedExec := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{
+			"--echo --handle insert rule inet myst_consumer_kill_switch kill_switch ip daddr 2.2.2.2 accept": {
+				output: []string{"insert rule inet myst_consumer_kill_switch kill_switch ip daddr 2.2.2.2 accept # handle 3"},
+			},
+		},
+	}
+	nftables.Exec = mockedExec.Exec
+
+	fw := &outgoingFirewallNftables{
+		referenceTracker: make(map[string]refCount),
+	}
+
+	removeRule, err := fw.AllowIPAccess("2.2.2.2")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fw.referenceTracker["allow:2.2.2.2"].count)
+
+	removeRule()
+	assert.Equal(t, 0, fw.referenceTracker["allow:2.2.2.2"].count)
+	assert.True(t, mockedExec.VerifyCalledWithArgs("delete", "rule", "inet", killswitchTable, killswitchNftChain, "handle", "3"))
+}
+
+func Test_outgoingFirewallNftables_FailsWhenRuleHandleIsMissing(t *testing.T) {
+	mockedExec := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	nftables.Exec = mockedExec.Exec
+
+	fw := &outgoingFirewallNftables{
+		referenceTracker: make(map[string]refCount),
+	}
+
+	_, err := fw.AllowIPAccess("2.2.2.2")
+	assert.Error(t, err)
+	assert.Equal(t, 0, fw.referenceTracker["allow:2.2.2.2"].count)
+}
+
+func Test_outgoingFirewallNftables_BlocksDNSLeaks(t *testing.T) {
+	mockedExec := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{
+			"--echo --handle add rule inet myst_consumer_kill_switch dns_leak ip daddr { 127.0.0.0/8, 10.8.0.1 } return": {
+				output: []string{"add rule inet myst_consumer_kill_switch dns_leak ip daddr { 127.0.0.0/8, 10.8.0.1 } return # handle 4"},
+			},
+			"--echo --handle add rule inet myst_consumer_kill_switch dns_leak ip6 daddr { ::1, fd00::1 } return": {
+				output: []string{"add rule inet myst_consumer_kill_switch dns_leak ip6 daddr { ::1, fd00::1 } return # handle 5"},
+			},
+			"--echo --handle add rule inet myst_consumer_kill_switch dns_leak reject": {
+				output: []string{"add rule inet myst_consumer_kill_switch dns_leak reject # handle 6"},
+			},
+			"--echo --handle insert rule inet myst_consumer_kill_switch output ip saddr 1.1.1.1 meta l4proto { tcp, udp } th dport { 53, 853 } jump dns_leak": {
+				output: []string{"insert rule inet myst_consumer_kill_switch output ip saddr 1.1.1.1 meta l4proto { tcp, udp } th dport { 53, 853 } jump dns_leak # handle 7"},
+			},
+			"--echo --handle insert rule inet myst_consumer_kill_switch output meta nfproto ipv6 meta l4proto { tcp, udp } th dport { 53, 853 } jump dns_leak": {
+				output: []string{"insert rule inet myst_consumer_kill_switch output meta nfproto ipv6 meta l4proto { tcp, udp } th dport { 53, 853 } jump dns_leak # handle 8"},
+			},
+		},
+	}
+	nftables.Exec = mockedExec.Exec
+
+	fw := &outgoingFirewallNftables{
+		referenceTracker: make(map[string]refCount),
+	}
+
+	removeRule, err := fw.BlockDNSLeaks(DNSLeakProtectionStandard, "1.1.1.1", []string{"10.8.0.1", "fd00::1"})
+	assert.NoError(t, err)
+
+	removeRule()
+	assert.True(t, mockedExec.VerifyCalledWithArgs("delete", "rule", "inet", killswitchTable, killswitchOutputChain, "handle", "8"))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("delete", "rule", "inet", killswitchTable, killswitchOutputChain, "handle", "7"))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("delete", "rule", "inet", killswitchTable, dnsLeakNftChain, "handle", "6"))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("delete", "rule", "inet", killswitchTable, dnsLeakNftChain, "handle", "5"))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("delete", "rule", "inet", killswitchTable, dnsLeakNftChain, "handle", "4"))
+}
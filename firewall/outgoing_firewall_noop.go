@@ -63,4 +63,12 @@ func (ofn *outgoingFirewallNoop) AllowURLAccess(rawURLs ...string) (OutgoingRule
 	}, nil
 }
 
+// BlockDNSLeaks logs resolvers DNS traffic was requested to be restricted to.
+func (ofn *outgoingFirewallNoop) BlockDNSLeaks(protection DNSLeakProtection, outboundIP string, resolvers []string) (OutgoingRuleRemove, error) {
+	log.Info().Msgf("DNS leak protection (%s) requested, resolvers: %v", protection, resolvers)
+	return func() {
+		log.Info().Msg("DNS leak protection removed")
+	}, nil
+}
+
 var _ OutgoingTrafficFirewall = &outgoingFirewallNoop{}
@@ -0,0 +1,245 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import (
+	"net"
+	"net/url"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/firewall/wfp"
+)
+
+// Filter weights within the node sublayer, the highest matching filter decides.
+const (
+	weightBlock         = 0
+	weightDNS           = 10
+	weightDNSLeakBlock  = 11
+	weightAllowIP       = 12
+	weightAllowResolver = 13
+	weightAllowAppID    = 14
+)
+
+const killswitchFilters = "Mysterium kill switch"
+
+// outgoingFirewallWFP implements kill switch with Windows Filtering Platform filters.
+type outgoingFirewallWFP struct {
+	lock             sync.Mutex
+	trafficLockScope Scope
+	referenceTracker map[string]refCount
+	engine           *wfp.Engine
+}
+
+// Setup opens filtering engine and allows DNS traffic, same as the other kill switch backends.
+func (obw *outgoingFirewallWFP) Setup() error {
+	engine, err := filteringEngine()
+	if err != nil {
+		return err
+	}
+	obw.engine = engine
+
+	_, err = engine.AddFilter(wfp.Filter{
+		Name:       killswitchFilters + ": allow DNS",
+		Layer:      wfp.LayerConnectV4,
+		Action:     wfp.ActionPermit,
+		Weight:     weightDNS,
+		Conditions: []wfp.Condition{wfp.RemotePort(53)},
+	})
+	return err
+}
+
+// Teardown removes all filters of the node, leaving the system in the state before setup.
+func (obw *outgoingFirewallWFP) Teardown() {
+	if obw.engine == nil {
+		return
+	}
+	if err := obw.engine.DeleteSublayer(); err != nil {
+		log.Warn().Err(err).Msg("Error cleaning up WFP filters, you might want to do it yourself")
+	}
+}
+
+// BlockOutgoingTraffic effectively disallows any outgoing traffic from consumer node with specified scope.
+func (obw *outgoingFirewallWFP) BlockOutgoingTraffic(scope Scope, outboundIP string) (OutgoingRuleRemove, error) {
+	if obw.trafficLockScope == Global {
+		// nothing can override global lock
+		return func() {}, nil
+	}
+	obw.trafficLockScope = scope
+	return trackingReferenceCall(&obw.lock, obw.referenceTracker, "block-traffic", func() (OutgoingRuleRemove, error) {
+		ip := net.ParseIP(outboundIP)
+		if ip.To4() == nil {
+			return nil, errors.Errorf("invalid outbound IPv4 address: %s", outboundIP)
+		}
+		return obw.addFilters(wfp.Filter{
+			Name:       killswitchFilters + ": block " + outboundIP,
+			Layer:      wfp.LayerConnectV4,
+			Action:     wfp.ActionBlock,
+			Weight:     weightBlock,
+			Conditions: []wfp.Condition{wfp.LocalAddress(ip)},
+		})
+	})
+}
+
+// AllowIPAccess adds exception to blocked traffic for specified IP or host name.
+func (obw *outgoingFirewallWFP) AllowIPAccess(ip string) (OutgoingRuleRemove, error) {
+	return trackingReferenceCall(&obw.lock, obw.referenceTracker, "allow:"+ip, func() (OutgoingRuleRemove, error) {
+		ips, err := net.LookupIP(ip)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not resolve %s", ip)
+		}
+
+		var filters []wfp.Filter
+		for _, addr := range ips {
+			if addr.To4() == nil {
+				continue
+			}
+			filters = append(filters, wfp.Filter{
+				Name:       killswitchFilters + ": allow " + addr.String(),
+				Layer:      wfp.LayerConnectV4,
+				Action:     wfp.ActionPermit,
+				Weight:     weightAllowIP,
+				Conditions: []wfp.Condition{wfp.RemoteAddress(addr)},
+			})
+		}
+		return obw.addFilters(filters...)
+	})
+}
+
+// AllowURLAccess adds URL based exception.
+func (obw *outgoingFirewallWFP) AllowURLAccess(rawURLs ...string) (OutgoingRuleRemove, error) {
+	var ruleRemovers []func()
+	removeAll := func() {
+		for _, ruleRemover := range ruleRemovers {
+			ruleRemover()
+		}
+	}
+	for _, rawURL := range rawURLs {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+
+		remover, err := obw.AllowIPAccess(parsed.Hostname())
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+		ruleRemovers = append(ruleRemovers, remover)
+	}
+	return removeAll, nil
+}
+
+// AllowApplicationAccess lets traffic of given application bypass the kill switch.
+func (obw *outgoingFirewallWFP) AllowApplicationAccess(path string) (OutgoingRuleRemove, error) {
+	return trackingReferenceCall(&obw.lock, obw.referenceTracker, "app:"+path, func() (OutgoingRuleRemove, error) {
+		return obw.addFilters(wfp.Filter{
+			Name:       killswitchFilters + ": allow " + path,
+			Layer:      wfp.LayerConnectV4,
+			Action:     wfp.ActionPermit,
+			Weight:     weightAllowAppID,
+			Conditions: []wfp.Condition{wfp.Application(path)},
+		})
+	})
+}
+
+// BlockDNSLeaks blocks outgoing DNS traffic not destined to the given resolvers.
+func (obw *outgoingFirewallWFP) BlockDNSLeaks(protection DNSLeakProtection, outboundIP string, resolvers []string) (OutgoingRuleRemove, error) {
+	return trackingReferenceCall(&obw.lock, obw.referenceTracker, "dns-leak", func() (OutgoingRuleRemove, error) {
+		var scope []wfp.Condition
+		if protection != DNSLeakProtectionStrict {
+			ip := net.ParseIP(outboundIP)
+			if ip.To4() == nil {
+				return nil, errors.Errorf("invalid outbound IPv4 address: %s", outboundIP)
+			}
+			scope = append(scope, wfp.LocalAddress(ip))
+		}
+
+		// Local resolvers forward queries to the tunnel resolvers.
+		allowed := append([]string{"127.0.0.1"}, resolversOfFamily(resolvers, false)...)
+		allowed6 := append([]string{"::1"}, resolversOfFamily(resolvers, true)...)
+
+		var filters []wfp.Filter
+		for _, port := range dnsPorts {
+			filters = append(filters, wfp.Filter{
+				Name:       killswitchFilters + ": block DNS leaks",
+				Layer:      wfp.LayerConnectV4,
+				Action:     wfp.ActionBlock,
+				Weight:     weightDNSLeakBlock,
+				Conditions: append([]wfp.Condition{wfp.RemotePort(uint16(port))}, scope...),
+			})
+			// The outbound IPv6 address is not known, so IPv6 DNS traffic is matched regardless of its source.
+			filters = append(filters, wfp.Filter{
+				Name:       killswitchFilters + ": block IPv6 DNS leaks",
+				Layer:      wfp.LayerConnectV6,
+				Action:     wfp.ActionBlock,
+				Weight:     weightDNSLeakBlock,
+				Conditions: []wfp.Condition{wfp.RemotePort(uint16(port))},
+			})
+			for _, resolver := range allowed {
+				filters = append(filters, wfp.Filter{
+					Name:       killswitchFilters + ": allow DNS resolver " + resolver,
+					Layer:      wfp.LayerConnectV4,
+					Action:     wfp.ActionPermit,
+					Weight:     weightAllowResolver,
+					Conditions: []wfp.Condition{wfp.RemotePort(uint16(port)), wfp.RemoteAddress(net.ParseIP(resolver))},
+				})
+			}
+			for _, resolver := range allowed6 {
+				filters = append(filters, wfp.Filter{
+					Name:       killswitchFilters + ": allow DNS resolver " + resolver,
+					Layer:      wfp.LayerConnectV6,
+					Action:     wfp.ActionPermit,
+					Weight:     weightAllowResolver,
+					Conditions: []wfp.Condition{wfp.RemotePort(uint16(port)), wfp.RemoteAddress6(net.ParseIP(resolver))},
+				})
+			}
+		}
+		return obw.addFilters(filters...)
+	})
+}
+
+func (obw *outgoingFirewallWFP) addFilters(filters ...wfp.Filter) (OutgoingRuleRemove, error) {
+	if obw.engine == nil {
+		return nil, errors.New("firewall is not set up")
+	}
+
+	var ids []uint64
+	removeAll := func() {
+		for _, id := range ids {
+			if err := obw.engine.DeleteFilter(id); err != nil {
+				log.Warn().Err(err).Msgf("Error deleting WFP filter %d, you might want to do it yourself", id)
+			}
+		}
+	}
+
+	for _, filter := range filters {
+		id, err := obw.engine.AddFilter(filter)
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return removeAll, nil
+}
+
+var _ OutgoingTrafficFirewall = &outgoingFirewallWFP{}
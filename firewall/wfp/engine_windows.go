@@ -0,0 +1,291 @@
+//go:build windows
+
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package wfp
+
+import (
+	"encoding/binary"
+	"net"
+	"runtime"
+	"sync"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/windows"
+)
+
+// Action is the decision made by a filter.
+type Action uint32
+
+const (
+	// ActionBlock blocks matching traffic.
+	ActionBlock Action = fwpActionBlock
+	// ActionPermit permits matching traffic.
+	ActionPermit Action = fwpActionPermit
+)
+
+// Filter describes a single WFP filter of the node sublayer.
+type Filter struct {
+	Name       string
+	Layer      windows.GUID
+	Action     Action
+	Weight     uint8
+	Conditions []Condition
+	// Hard makes permit filters override blocks of lower priority sublayers, e.g. of Windows Defender Firewall.
+	Hard bool
+}
+
+// Condition is a single condition of a filter, all conditions must match for filter to apply.
+type Condition struct {
+	field   windows.GUID
+	kind    uint32
+	value   uintptr
+	addr6   *[16]byte
+	appPath string
+}
+
+// LocalAddress matches traffic with given local IPv4 address.
+func LocalAddress(ip net.IP) Condition {
+	return Condition{field: conditionIPLocalAddress, kind: fwpUint32, value: uintptr(ipv4ToUint32(ip))}
+}
+
+// RemoteAddress matches traffic with given remote IPv4 address.
+func RemoteAddress(ip net.IP) Condition {
+	return Condition{field: conditionIPRemoteAddress, kind: fwpUint32, value: uintptr(ipv4ToUint32(ip))}
+}
+
+// RemoteAddress6 matches traffic with given remote IPv6 address.
+func RemoteAddress6(ip net.IP) Condition {
+	var addr [16]byte
+	copy(addr[:], ip.To16())
+	return Condition{field: conditionIPRemoteAddress, kind: fwpByteArray16Type, addr6: &addr}
+}
+
+// LocalPort matches traffic with given local port.
+func LocalPort(port uint16) Condition {
+	return Condition{field: conditionIPLocalPort, kind: fwpUint16, value: uintptr(port)}
+}
+
+// RemotePort matches traffic with given remote port.
+func RemotePort(port uint16) Condition {
+	return Condition{field: conditionIPRemotePort, kind: fwpUint16, value: uintptr(port)}
+}
+
+// Protocol matches traffic of given IP protocol, e.g. windows.IPPROTO_UDP.
+func Protocol(proto uint8) Condition {
+	return Condition{field: conditionIPProtocol, kind: fwpUint8, value: uintptr(proto)}
+}
+
+// Application matches traffic of the application with given executable path.
+func Application(path string) Condition {
+	return Condition{field: conditionALEAppID, kind: fwpByteBlobType, appPath: path}
+}
+
+func ipv4ToUint32(ip net.IP) uint32 {
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(ip4)
+}
+
+// Engine is a session to the Windows Filtering Platform, all filters are kept in the node sublayer.
+type Engine struct {
+	lock   sync.Mutex
+	handle uintptr
+}
+
+// Open opens a session to the filtering engine.
+func Open() (*Engine, error) {
+	name, err := windows.UTF16PtrFromString("Mysterium node")
+	if err != nil {
+		return nil, err
+	}
+	session := fwpmSession0{
+		displayData:          fwpmDisplayData0{name: name},
+		txnWaitTimeoutInMSec: windows.INFINITE,
+	}
+
+	var handle uintptr
+	if err := fwpmEngineOpen0(&session, &handle); err != nil {
+		return nil, errors.Wrap(err, "could not open filtering engine")
+	}
+	return &Engine{handle: handle}, nil
+}
+
+// Close closes the session. Filters stay in place until they are deleted or the sublayer is reset.
+func (e *Engine) Close() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return fwpmEngineClose0(e.handle)
+}
+
+// ResetSublayer removes filters left behind by a previous run and makes sure the persistent node sublayer exists.
+func (e *Engine) ResetSublayer() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if err := fwpmTransactionBegin0(e.handle); err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+
+	if err := e.deleteSublayerFilters(); err != nil {
+		fwpmTransactionAbort0(e.handle)
+		return err
+	}
+
+	name, err := windows.UTF16PtrFromString("Mysterium node filters")
+	if err != nil {
+		fwpmTransactionAbort0(e.handle)
+		return err
+	}
+	sublayer := fwpmSublayer0{
+		subLayerKey: sublayerKey,
+		displayData: fwpmDisplayData0{name: name},
+		flags:       fwpmSublayerFlagPersistent,
+		weight:      0xffff,
+	}
+	if err := fwpmSubLayerAdd0(e.handle, &sublayer); err != nil && err != fwpErrAlreadyExists {
+		fwpmTransactionAbort0(e.handle)
+		return errors.Wrap(err, "could not add sublayer")
+	}
+
+	return fwpmTransactionCommit0(e.handle)
+}
+
+// DeleteSublayer removes all filters of the node together with its sublayer.
+func (e *Engine) DeleteSublayer() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if err := e.deleteSublayerFilters(); err != nil {
+		return err
+	}
+	if err := fwpmSubLayerDeleteByKey0(e.handle, &sublayerKey); err != nil && err != fwpErrSublayerNotFound {
+		return errors.Wrap(err, "could not delete sublayer")
+	}
+	return nil
+}
+
+func (e *Engine) deleteSublayerFilters() error {
+	var enum uintptr
+	if err := fwpmFilterCreateEnumHandle0(e.handle, &enum); err != nil {
+		return errors.Wrap(err, "could not enumerate filters")
+	}
+	defer fwpmFilterDestroyEnumHandle0(e.handle, enum)
+
+	var stale []uint64
+	for {
+		var entries **fwpmFilter0
+		var returned uint32
+		if err := fwpmFilterEnum0(e.handle, enum, filterEnumBatchSize, &entries, &returned); err != nil {
+			return errors.Wrap(err, "could not enumerate filters")
+		}
+		if returned == 0 {
+			break
+		}
+		for _, filter := range unsafe.Slice(entries, returned) {
+			if filter.subLayerKey == sublayerKey {
+				stale = append(stale, filter.filterID)
+			}
+		}
+		fwpmFreeMemory0(unsafe.Pointer(entries))
+		if returned < filterEnumBatchSize {
+			break
+		}
+	}
+
+	for _, id := range stale {
+		if err := fwpmFilterDeleteByID0(e.handle, id); err != nil {
+			return errors.Wrapf(err, "could not delete filter %d", id)
+		}
+	}
+	return nil
+}
+
+// AddFilter adds a filter to the node sublayer and returns its ID.
+func (e *Engine) AddFilter(f Filter) (uint64, error) {
+	name, err := windows.UTF16PtrFromString(f.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	conditions := make([]fwpmFilterCondition0, len(f.Conditions))
+	for i, c := range f.Conditions {
+		conditions[i] = fwpmFilterCondition0{
+			fieldKey:       c.field,
+			matchType:      fwpMatchEqual,
+			conditionValue: fwpValue0{dataType: c.kind, value: c.value},
+		}
+		if c.addr6 != nil {
+			conditions[i].conditionValue.value = uintptr(unsafe.Pointer(c.addr6))
+		}
+		if c.appPath == "" {
+			continue
+		}
+
+		path, err := windows.UTF16PtrFromString(c.appPath)
+		if err != nil {
+			return 0, err
+		}
+		var appID *fwpByteBlob
+		if err := fwpmGetAppIDFromFileName0(path, &appID); err != nil {
+			return 0, errors.Wrapf(err, "could not get application ID of %s", c.appPath)
+		}
+		defer fwpmFreeMemory0(unsafe.Pointer(appID))
+		conditions[i].conditionValue.value = uintptr(unsafe.Pointer(appID))
+	}
+
+	filter := fwpmFilter0{
+		displayData:         fwpmDisplayData0{name: name},
+		layerKey:            f.Layer,
+		subLayerKey:         sublayerKey,
+		weight:              fwpValue0{dataType: fwpUint8, value: uintptr(f.Weight)},
+		numFilterConditions: uint32(len(conditions)),
+		action:              fwpmAction0{actionType: uint32(f.Action)},
+	}
+	if len(conditions) > 0 {
+		filter.filterCondition = &conditions[0]
+	}
+	if f.Hard {
+		filter.flags = fwpmFilterFlagClearActionRight
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	var id uint64
+	err = fwpmFilterAdd0(e.handle, &filter, &id)
+	runtime.KeepAlive(conditions)
+	runtime.KeepAlive(f.Conditions)
+	runtime.KeepAlive(name)
+	if err != nil {
+		return 0, errors.Wrapf(err, "could not add filter %q", f.Name)
+	}
+	return id, nil
+}
+
+// DeleteFilter removes the filter with given ID.
+func (e *Engine) DeleteFilter(id uint64) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return fwpmFilterDeleteByID0(e.handle, id)
+}
@@ -0,0 +1,121 @@
+//go:build windows
+
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package wfp
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modfwpuclnt = windows.NewLazySystemDLL("fwpuclnt.dll")
+
+	procFwpmEngineOpen0              = modfwpuclnt.NewProc("FwpmEngineOpen0")
+	procFwpmEngineClose0             = modfwpuclnt.NewProc("FwpmEngineClose0")
+	procFwpmSubLayerAdd0             = modfwpuclnt.NewProc("FwpmSubLayerAdd0")
+	procFwpmSubLayerDeleteByKey0     = modfwpuclnt.NewProc("FwpmSubLayerDeleteByKey0")
+	procFwpmFilterAdd0               = modfwpuclnt.NewProc("FwpmFilterAdd0")
+	procFwpmFilterDeleteByID0        = modfwpuclnt.NewProc("FwpmFilterDeleteById0")
+	procFwpmFilterCreateEnumHandle0  = modfwpuclnt.NewProc("FwpmFilterCreateEnumHandle0")
+	procFwpmFilterEnum0              = modfwpuclnt.NewProc("FwpmFilterEnum0")
+	procFwpmFilterDestroyEnumHandle0 = modfwpuclnt.NewProc("FwpmFilterDestroyEnumHandle0")
+	procFwpmGetAppIDFromFileName0    = modfwpuclnt.NewProc("FwpmGetAppIdFromFileName0")
+	procFwpmFreeMemory0              = modfwpuclnt.NewProc("FwpmFreeMemory0")
+	procFwpmTransactionBegin0        = modfwpuclnt.NewProc("FwpmTransactionBegin0")
+	procFwpmTransactionCommit0       = modfwpuclnt.NewProc("FwpmTransactionCommit0")
+	procFwpmTransactionAbort0        = modfwpuclnt.NewProc("FwpmTransactionAbort0")
+)
+
+func result(r1 uintptr) error {
+	if r1 != 0 {
+		return windows.Errno(r1)
+	}
+	return nil
+}
+
+func fwpmEngineOpen0(session *fwpmSession0, engine *uintptr) error {
+	r1, _, _ := procFwpmEngineOpen0.Call(0, rpcCAuthnWinNT, 0, uintptr(unsafe.Pointer(session)), uintptr(unsafe.Pointer(engine)))
+	return result(r1)
+}
+
+func fwpmEngineClose0(engine uintptr) error {
+	r1, _, _ := procFwpmEngineClose0.Call(engine)
+	return result(r1)
+}
+
+func fwpmSubLayerAdd0(engine uintptr, sublayer *fwpmSublayer0) error {
+	r1, _, _ := procFwpmSubLayerAdd0.Call(engine, uintptr(unsafe.Pointer(sublayer)), 0)
+	return result(r1)
+}
+
+func fwpmSubLayerDeleteByKey0(engine uintptr, key *windows.GUID) error {
+	r1, _, _ := procFwpmSubLayerDeleteByKey0.Call(engine, uintptr(unsafe.Pointer(key)))
+	return result(r1)
+}
+
+func fwpmFilterAdd0(engine uintptr, filter *fwpmFilter0, id *uint64) error {
+	r1, _, _ := procFwpmFilterAdd0.Call(engine, uintptr(unsafe.Pointer(filter)), 0, uintptr(unsafe.Pointer(id)))
+	return result(r1)
+}
+
+func fwpmFilterDeleteByID0(engine uintptr, id uint64) error {
+	r1, _, _ := procFwpmFilterDeleteByID0.Call(engine, uintptr(id))
+	return result(r1)
+}
+
+func fwpmFilterCreateEnumHandle0(engine uintptr, enum *uintptr) error {
+	r1, _, _ := procFwpmFilterCreateEnumHandle0.Call(engine, 0, uintptr(unsafe.Pointer(enum)))
+	return result(r1)
+}
+
+func fwpmFilterEnum0(engine, enum uintptr, requested uint32, entries ***fwpmFilter0, returned *uint32) error {
+	r1, _, _ := procFwpmFilterEnum0.Call(engine, enum, uintptr(requested), uintptr(unsafe.Pointer(entries)), uintptr(unsafe.Pointer(returned)))
+	return result(r1)
+}
+
+func fwpmFilterDestroyEnumHandle0(engine, enum uintptr) error {
+	r1, _, _ := procFwpmFilterDestroyEnumHandle0.Call(engine, enum)
+	return result(r1)
+}
+
+func fwpmGetAppIDFromFileName0(fileName *uint16, appID **fwpByteBlob) error {
+	r1, _, _ := procFwpmGetAppIDFromFileName0.Call(uintptr(unsafe.Pointer(fileName)), uintptr(unsafe.Pointer(appID)))
+	return result(r1)
+}
+
+func fwpmFreeMemory0(p unsafe.Pointer) {
+	procFwpmFreeMemory0.Call(uintptr(unsafe.Pointer(&p)))
+}
+
+func fwpmTransactionBegin0(engine uintptr) error {
+	r1, _, _ := procFwpmTransactionBegin0.Call(engine, 0)
+	return result(r1)
+}
+
+func fwpmTransactionCommit0(engine uintptr) error {
+	r1, _, _ := procFwpmTransactionCommit0.Call(engine)
+	return result(r1)
+}
+
+func fwpmTransactionAbort0(engine uintptr) error {
+	r1, _, _ := procFwpmTransactionAbort0.Call(engine)
+	return result(r1)
+}
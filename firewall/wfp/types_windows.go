@@ -0,0 +1,131 @@
+//go:build windows
+
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package wfp
+
+import "golang.org/x/sys/windows"
+
+// Struct layouts below mirror fwpmtypes.h for 64-bit Windows.
+
+type fwpByteBlob struct {
+	size uint32
+	data *uint8
+}
+
+type fwpmDisplayData0 struct {
+	name        *uint16
+	description *uint16
+}
+
+type fwpmSession0 struct {
+	sessionKey           windows.GUID
+	displayData          fwpmDisplayData0
+	flags                uint32
+	txnWaitTimeoutInMSec uint32
+	processID            uint32
+	sid                  *windows.SID
+	username             *uint16
+	kernelMode           int32
+}
+
+type fwpmSublayer0 struct {
+	subLayerKey  windows.GUID
+	displayData  fwpmDisplayData0
+	flags        uint32
+	providerKey  *windows.GUID
+	providerData fwpByteBlob
+	weight       uint16
+}
+
+// fwpValue0 is used for both FWP_VALUE0 and FWP_CONDITION_VALUE0, values wider
+// than a pointer are passed by reference.
+type fwpValue0 struct {
+	dataType uint32
+	value    uintptr
+}
+
+type fwpmFilterCondition0 struct {
+	fieldKey       windows.GUID
+	matchType      uint32
+	conditionValue fwpValue0
+}
+
+type fwpmAction0 struct {
+	actionType uint32
+	filterType windows.GUID
+}
+
+type fwpmFilter0 struct {
+	filterKey           windows.GUID
+	displayData         fwpmDisplayData0
+	flags               uint32
+	providerKey         *windows.GUID
+	providerData        fwpByteBlob
+	layerKey            windows.GUID
+	subLayerKey         windows.GUID
+	weight              fwpValue0
+	numFilterConditions uint32
+	filterCondition     *fwpmFilterCondition0
+	action              fwpmAction0
+	providerContextKey  [2]uint64
+	reserved            *windows.GUID
+	filterID            uint64
+	effectiveWeight     fwpValue0
+}
+
+const (
+	fwpUint8           = 1
+	fwpUint16          = 2
+	fwpUint32          = 3
+	fwpByteArray16Type = 11
+	fwpByteBlobType    = 12
+
+	fwpMatchEqual = 0
+
+	fwpActionFlagTerminating = 0x1000
+	fwpActionBlock           = 0x1 | fwpActionFlagTerminating
+	fwpActionPermit          = 0x2 | fwpActionFlagTerminating
+
+	fwpmSublayerFlagPersistent            = 0x1
+	fwpmFilterFlagClearActionRight        = 0x8
+	rpcCAuthnWinNT                        = 10
+	fwpErrSublayerNotFound                = windows.Errno(0x80320007)
+	fwpErrAlreadyExists                   = windows.Errno(0x80320009)
+	filterEnumBatchSize            uint32 = 100
+)
+
+var (
+	// LayerConnectV4 authorizes outgoing IPv4 connections.
+	LayerConnectV4 = windows.GUID{Data1: 0xc38d57d1, Data2: 0x05a7, Data3: 0x4c33, Data4: [8]byte{0x90, 0x4f, 0x7f, 0xbc, 0xee, 0xe6, 0x0e, 0x82}}
+	// LayerConnectV6 authorizes outgoing IPv6 connections.
+	LayerConnectV6 = windows.GUID{Data1: 0x4a72393b, Data2: 0x319f, Data3: 0x44bc, Data4: [8]byte{0x84, 0xc3, 0xba, 0x54, 0xdc, 0xb3, 0xb6, 0xb4}}
+	// LayerRecvAcceptV4 authorizes incoming IPv4 connections.
+	LayerRecvAcceptV4 = windows.GUID{Data1: 0xe1cd9fe7, Data2: 0xf4b5, Data3: 0x4273, Data4: [8]byte{0x96, 0xc0, 0x59, 0x2e, 0x48, 0x7b, 0x86, 0x50}}
+
+	conditionIPLocalAddress  = windows.GUID{Data1: 0xd9ee00de, Data2: 0xc1ef, Data3: 0x4617, Data4: [8]byte{0xbf, 0xe3, 0xff, 0xd8, 0xf5, 0xa0, 0x89, 0x57}}
+	conditionIPRemoteAddress = windows.GUID{Data1: 0xb235ae9a, Data2: 0x1d64, Data3: 0x49b8, Data4: [8]byte{0xa4, 0x4c, 0x5f, 0xf3, 0xd9, 0x09, 0x50, 0x45}}
+	conditionIPLocalPort     = windows.GUID{Data1: 0x0c1ba1af, Data2: 0x5765, Data3: 0x453f, Data4: [8]byte{0xaf, 0x22, 0xa8, 0xf7, 0x91, 0xac, 0x77, 0x5b}}
+	conditionIPRemotePort    = windows.GUID{Data1: 0xc35a604d, Data2: 0xd22b, Data3: 0x4e1a, Data4: [8]byte{0x91, 0xb4, 0x68, 0xf6, 0x74, 0xee, 0x67, 0x4b}}
+	conditionIPProtocol      = windows.GUID{Data1: 0x3971ef2b, Data2: 0x623e, Data3: 0x4f9a, Data4: [8]byte{0x8c, 0xb1, 0x6e, 0x79, 0xb8, 0x06, 0xb9, 0xa7}}
+	conditionALEAppID        = windows.GUID{Data1: 0xd78e1e87, Data2: 0x8644, Data3: 0x4ea5, Data4: [8]byte{0x94, 0x37, 0xd8, 0x09, 0xec, 0xef, 0xc9, 0x71}}
+
+	// sublayerKey identifies the sublayer holding all node filters, it is persistent
+	// so that filters left behind by a crashed node can be found and removed on the next start.
+	sublayerKey = windows.GUID{Data1: 0x6d79737a, Data2: 0x7466, Data3: 0x7770, Data4: [8]byte{0x6d, 0x79, 0x73, 0x74, 0x6e, 0x6f, 0x64, 0x65}}
+)
@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/pkg/errors"
+)
+
+// DecrypterFactory callback returning Decrypter
+type DecrypterFactory func(id Identity) Decrypter
+
+// Decrypter is able to open payloads sealed to the identity public key
+type Decrypter interface {
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+type eciesKeystore interface {
+	DecryptECIES(addr common.Address, ciphertext []byte) ([]byte, error)
+}
+
+type keystoreDecrypter struct {
+	keystore eciesKeystore
+	address  common.Address
+}
+
+// NewDecrypter returns new instance of Decrypter
+func NewDecrypter(keystore eciesKeystore, id Identity) Decrypter {
+	return &keystoreDecrypter{
+		keystore: keystore,
+		address:  id.ToCommonAddress(),
+	}
+}
+
+// Decrypt opens payload sealed to the identity public key
+func (d *keystoreDecrypter) Decrypt(ciphertext []byte) ([]byte, error) {
+	return d.keystore.DecryptECIES(d.address, ciphertext)
+}
+
+// Seal encrypts plaintext with ECIES so that only the owner of the public key can read it
+func Seal(publicKey *ecdsa.PublicKey, plaintext []byte) ([]byte, error) {
+	return ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(publicKey), plaintext, nil, nil)
+}
+
+// RecoverPublicKey returns public key of the identity which signed given message
+func RecoverPublicKey(message []byte, signature Signature) (*ecdsa.PublicKey, error) {
+	signatureBytes := signature.Bytes()
+	if len(signatureBytes) == 0 {
+		return nil, errors.New("empty signature")
+	}
+
+	recoveredKey, err := crypto.Ecrecover(messageHash(message), signatureBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return crypto.UnmarshalPubkey(recoveredKey)
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethKs "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecrypter_OpensSealedMessage(t *testing.T) {
+	ks := NewMockKeystoreWith(MockKeys)
+	id := FromAddress(signerAddress)
+	signature, err := NewSigner(ks, id).Sign([]byte("hello"))
+	assert.Error(t, err, "locked keystore should not sign")
+
+	require.NoError(t, ks.Unlock(signerAccount, ""))
+	signature, err = NewSigner(ks, id).Sign([]byte("hello"))
+	require.NoError(t, err)
+
+	publicKey, err := RecoverPublicKey([]byte("hello"), signature)
+	require.NoError(t, err)
+	assert.Equal(t, signerKey.PublicKey, *publicKey)
+
+	sealed, err := Seal(publicKey, []byte("secret"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "secret")
+
+	plaintext, err := NewDecrypter(ks, id).Decrypt(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), plaintext)
+
+	other, err := ks.NewAccount("")
+	require.NoError(t, err)
+	require.NoError(t, ks.Unlock(other, ""))
+	_, err = NewDecrypter(ks, FromAddress(other.Address.Hex())).Decrypt(sealed)
+	assert.Error(t, err)
+
+	_, err = NewDecrypter(ks, FromAddress(accounts.Account{}.Address.Hex())).Decrypt(sealed)
+	assert.Error(t, err)
+}
+
+func TestKeystore_DecryptECIES(t *testing.T) {
+	ks := NewKeystoreFilesystem("dir", &ethKeystoreMock{account: signerAccount})
+	ks.loadKey = func(addr common.Address, filename, auth string) (*ethKs.Key, error) {
+		return &ethKs.Key{Address: addr, PrivateKey: signerKey}, nil
+	}
+	sealed, err := Seal(&signerKey.PublicKey, []byte("secret"))
+	require.NoError(t, err)
+
+	_, err = ks.DecryptECIES(signerAccount.Address, sealed)
+	assert.Error(t, err, "locked account should not decrypt")
+
+	require.NoError(t, ks.Unlock(signerAccount, ""))
+	plaintext, err := ks.DecryptECIES(signerAccount.Address, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), plaintext)
+}
@@ -19,7 +19,6 @@ package identity
 
 import (
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/pkg/errors"
 )
 
 // Extractor is able to message signer's identity
@@ -36,17 +35,7 @@ type extractor struct{}
 
 // Extractor extracts identity which was used to sign given message
 func (extractor *extractor) Extract(message []byte, signature Signature) (Identity, error) {
-	signatureBytes := signature.Bytes()
-	if len(signatureBytes) == 0 {
-		return Identity{}, errors.New("empty signature")
-	}
-
-	recoveredKey, err := crypto.Ecrecover(messageHash(message), signatureBytes)
-	if err != nil {
-		return Identity{}, err
-	}
-
-	key, err := crypto.UnmarshalPubkey(recoveredKey)
+	key, err := RecoverPublicKey(message, signature)
 	if err != nil {
 		return Identity{}, err
 	}
@@ -34,6 +34,7 @@ import (
 	ethKs "github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
 	"golang.org/x/crypto/hkdf"
 )
 
@@ -213,6 +214,19 @@ func (ks *Keystore) Decrypt(addr common.Address, encrypted []byte) ([]byte, erro
 	return gcm.Open(nil, nonce, encrypted, nil)
 }
 
+// DecryptECIES decrypts the ciphertext sealed to the public key of the given address.
+func (ks *Keystore) DecryptECIES(addr common.Address, ciphertext []byte) ([]byte, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, found := ks.unlocked[addr]
+	if !found {
+		return nil, ethKs.ErrLocked
+	}
+
+	return ecies.ImportECDSA(key.PrivateKey).Decrypt(ciphertext, nil, nil)
+}
+
 // SignHash calculates a ECDSA signature for the given hash. The produced
 // signature is in the [R || S || V] format where V is 0 or 1.
 func (ks *Keystore) SignHash(a accounts.Account, hash []byte) ([]byte, error) {
@@ -27,6 +27,7 @@ import (
 	ethKs "github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
 )
 
 type mockKeystore struct {
@@ -91,6 +92,19 @@ func (mk *mockKeystore) SignHash(a accounts.Account, hash []byte) ([]byte, error
 	return nil, ethKs.ErrNoMatch
 }
 
+func (mk *mockKeystore) DecryptECIES(addr common.Address, ciphertext []byte) ([]byte, error) {
+	mk.lock.Lock()
+	defer mk.lock.Unlock()
+
+	if v, ok := mk.keys[addr]; ok {
+		if !v.isUnlocked {
+			return nil, ethKs.ErrLocked
+		}
+		return ecies.ImportECDSA(v.pk).Decrypt(ciphertext, nil, nil)
+	}
+	return nil, ethKs.ErrNoMatch
+}
+
 func (mk *mockKeystore) Export(a accounts.Account, passphrase, newPassphrase string) (keyJSON []byte, err error) {
 	mk.lock.Lock()
 	defer mk.lock.Unlock()
@@ -59,17 +59,21 @@ type contractRegistry struct {
 	hermes     hermesCaller
 	transactor transactor
 	cfg        IdentityRegistryConfig
+	now        func() time.Time
+	bcStatus   func(chainID int64, id identity.Identity) (RegistrationStatus, error)
 }
 
 // IdentityRegistryConfig contains the configuration for registry contract.
 type IdentityRegistryConfig struct {
 	TransactorPollInterval time.Duration
 	TransactorPollTimeout  time.Duration
+	// StatusCacheTTL is how long a status observed on chain is used without rechecking it.
+	StatusCacheTTL time.Duration
 }
 
 // NewIdentityRegistryContract creates identity registry service which uses blockchain for information
 func NewIdentityRegistryContract(ethClient paymentClient.EtherClient, ap AddressProvider, registryStorage registryStorage, publisher eventbus.Publisher, caller hermesCaller, transactor transactor, cfg IdentityRegistryConfig) (*contractRegistry, error) {
-	return &contractRegistry{
+	registry := &contractRegistry{
 		storage:    registryStorage,
 		stop:       make(chan struct{}),
 		publisher:  publisher,
@@ -78,7 +82,10 @@ func NewIdentityRegistryContract(ethClient paymentClient.EtherClient, ap Address
 		hermes:     caller,
 		transactor: transactor,
 		cfg:        cfg,
-	}, nil
+		now:        time.Now,
+	}
+	registry.bcStatus = registry.bcRegistrationStatus
+	return registry, nil
 }
 
 // Subscribe subscribes the contract registry to relevant events
@@ -94,10 +101,22 @@ func (registry *contractRegistry) Subscribe(eb eventbus.Subscriber) error {
 	return eb.Subscribe(AppTopicTransactorRegistration, registry.handleRegistrationEvent)
 }
 
-// GetRegistrationStatus returns the registration status of the provided identity
+// GetRegistrationStatus returns the registration status of the provided identity.
+// Statuses observed on chain are cached for the configured TTL and the last stored
+// status is used as a fallback while the chain can't be reached.
 func (registry *contractRegistry) GetRegistrationStatus(chainID int64, id identity.Identity) (RegistrationStatus, error) {
+	return registry.registrationStatus(chainID, id, false)
+}
+
+// recheckRegistrationStatus checks the registration status on chain ignoring the cached status.
+func (registry *contractRegistry) recheckRegistrationStatus(chainID int64, id identity.Identity) (RegistrationStatus, error) {
+	return registry.registrationStatus(chainID, id, true)
+}
+
+func (registry *contractRegistry) registrationStatus(chainID int64, id identity.Identity, force bool) (RegistrationStatus, error) {
 	var currentStatus RegistrationStatus
 	ss, err := registry.storage.Get(chainID, id)
+	stored := err == nil
 	switch err {
 	case nil:
 		currentStatus = ss.RegistrationStatus
@@ -111,9 +130,20 @@ func (registry *contractRegistry) GetRegistrationStatus(chainID int64, id identi
 		return currentStatus, nil
 	}
 
-	var newStatus RegistrationStatus
-	newStatus, err = registry.bcRegistrationStatus(chainID, id)
+	if !force && !ss.ObservedAt.IsZero() && registry.now().Sub(ss.ObservedAt) < registry.cfg.StatusCacheTTL {
+		return currentStatus, nil
+	}
+
+	newStatus, err := registry.bcStatus(chainID, id)
 	if err != nil {
+		if stored {
+			log.Warn().Err(err).
+				Str("identity", id.Address).
+				Str("status", currentStatus.String()).
+				Time("observed_at", ss.ObservedAt).
+				Msg("Could not check registration status on blockchain, using stored status")
+			return currentStatus, nil
+		}
 		return Unregistered, errors.Wrap(err, "could not check identity registration status on blockchain")
 	}
 
@@ -137,6 +167,7 @@ func (registry *contractRegistry) GetRegistrationStatus(chainID int64, id identi
 		Identity:           id,
 		RegistrationStatus: newStatus,
 		ChainID:            chainID,
+		ObservedAt:         registry.now(),
 	})
 	if err != nil {
 		return newStatus, errors.Wrap(err, "could not store registration status")
@@ -194,7 +225,7 @@ func (registry *contractRegistry) handleRegistrationEvent(ev IdentityRegistratio
 
 	// In case we have a previous registration, force re-check the BC status
 	if status.RegistrationStatus == InProgress || status.RegistrationStatus == RegistrationError {
-		status, err := registry.GetRegistrationStatus(ev.ChainID, identity.FromAddress(ev.Identity))
+		status, err := registry.recheckRegistrationStatus(ev.ChainID, identity.FromAddress(ev.Identity))
 		if err != nil {
 			log.Info().Err(err).Msg("could not recheck status with bc")
 		} else if status.Registered() {
@@ -361,7 +392,7 @@ func (registry *contractRegistry) getProviderChannelAddressBytes(hermesAddress c
 }
 
 func (registry *contractRegistry) handleUnregisteredIdentityInitialLoad(chainID int64, id identity.Identity) error {
-	status, err := registry.GetRegistrationStatus(chainID, id)
+	status, err := registry.recheckRegistrationStatus(chainID, id)
 	if err != nil {
 		return errors.Wrap(err, "could not check status on blockchain")
 	}
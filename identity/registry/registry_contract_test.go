@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+type mockHermesCaller struct{}
+
+func (m *mockHermesCaller) IsIdentityOffchain(chainID int64, id string) (bool, error) {
+	return false, nil
+}
+
+func TestContractRegistry_GetRegistrationStatusCachesObservedStatus(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registryStatusCacheTest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	defer bolt.Close()
+
+	now := time.Date(2022, time.June, 15, 12, 0, 0, 0, time.UTC)
+	registry, err := NewIdentityRegistryContract(nil, nil, NewRegistrationStatusStorage(bolt), eventbus.New(), &mockHermesCaller{}, nil, IdentityRegistryConfig{
+		StatusCacheTTL: time.Minute,
+	})
+	assert.NoError(t, err)
+	registry.now = func() time.Time { return now }
+
+	calls := 0
+	var bcErr error
+	registry.bcStatus = func(chainID int64, id identity.Identity) (RegistrationStatus, error) {
+		calls++
+		return Unregistered, bcErr
+	}
+
+	id := identity.FromAddress("0x001")
+
+	// Unknown status is checked on chain and cached.
+	status, err := registry.GetRegistrationStatus(1, id)
+	assert.NoError(t, err)
+	assert.Equal(t, Unregistered, status)
+	assert.Equal(t, 1, calls)
+
+	stored, err := registry.storage.Get(1, id)
+	assert.NoError(t, err)
+	assert.True(t, now.Equal(stored.ObservedAt))
+
+	// Cached status is used within TTL.
+	status, err = registry.GetRegistrationStatus(1, id)
+	assert.NoError(t, err)
+	assert.Equal(t, Unregistered, status)
+	assert.Equal(t, 1, calls)
+
+	// Forced recheck ignores the cached status.
+	status, err = registry.recheckRegistrationStatus(1, id)
+	assert.NoError(t, err)
+	assert.Equal(t, Unregistered, status)
+	assert.Equal(t, 2, calls)
+
+	// Status stored without observing the chain is not cached.
+	assert.NoError(t, registry.storage.Store(StoredRegistrationStatus{Identity: id, ChainID: 1, RegistrationStatus: InProgress}))
+	status, err = registry.GetRegistrationStatus(1, id)
+	assert.NoError(t, err)
+	assert.Equal(t, InProgress, status)
+	assert.Equal(t, 3, calls)
+
+	// Expired status is used as a fallback while chain is unreachable.
+	now = now.Add(time.Hour)
+	bcErr = errors.New("chain unreachable")
+	status, err = registry.GetRegistrationStatus(1, id)
+	assert.NoError(t, err)
+	assert.Equal(t, InProgress, status)
+	assert.Equal(t, 4, calls)
+
+	// Status which was never stored can't be served offline.
+	_, err = registry.GetRegistrationStatus(1, identity.FromAddress("0x002"))
+	assert.Error(t, err)
+}
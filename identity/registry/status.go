@@ -68,6 +68,8 @@ type StoredRegistrationStatus struct {
 	ChainID             int64
 	RegistrationRequest IdentityRegistrationRequest
 	UpdatedAt           time.Time
+	// ObservedAt is the time the status was last confirmed on chain.
+	ObservedAt time.Time
 }
 
 // FromEvent constructs a stored registration status from transactor.IdentityRegistrationRequest
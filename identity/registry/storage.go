@@ -75,6 +75,7 @@ func (rss *RegistrationStatusStorage) Store(status StoredRegistrationStatus) err
 		return nil
 	default:
 		s.RegistrationStatus = status.RegistrationStatus
+		s.ObservedAt = status.ObservedAt
 	}
 
 	return rss.store(s)
@@ -19,6 +19,7 @@ package registry
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"strings"
@@ -115,10 +116,11 @@ type Transactor struct {
 	bc              channelProvider
 	addresser       AddressProvider
 	feeCache        *feeCacher
+	queue           *TransactorQueue
 }
 
 // NewTransactor creates and returns new Transactor instance
-func NewTransactor(httpClient *requests.HTTPClient, endpointAddress string, addresser AddressProvider, signerFactory identity.SignerFactory, publisher eventbus.Publisher, bc channelProvider, feesValidTime time.Duration) *Transactor {
+func NewTransactor(httpClient *requests.HTTPClient, endpointAddress string, addresser AddressProvider, signerFactory identity.SignerFactory, publisher eventbus.Publisher, bc channelProvider, feesValidTime time.Duration, queue *TransactorQueue) *Transactor {
 	return &Transactor{
 		httpClient:      httpClient,
 		endpointAddress: endpointAddress,
@@ -127,18 +129,84 @@ func NewTransactor(httpClient *requests.HTTPClient, endpointAddress string, addr
 		publisher:       publisher,
 		bc:              bc,
 		feeCache:        newFeeCacher(feesValidTime),
+		queue:           queue,
 	}
 }
 
+// QueuedJobs returns registration and settlement requests sent through the queue.
+func (t *Transactor) QueuedJobs() ([]TransactorJob, error) {
+	if t.queue == nil {
+		return nil, nil
+	}
+	return t.queue.Jobs()
+}
+
+// HandleJob sends the job to transactor. Used by the queue to retry jobs.
+func (t *Transactor) HandleJob(job TransactorJob) (string, error) {
+	req, err := requests.NewPostRequest(t.endpointAddress, job.Endpoint, job.Payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s request: %w", job.Kind, err)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if err := requests.ParseResponseError(resp); err != nil {
+		return "", &transactorResponseError{status: resp.StatusCode, err: err}
+	}
+
+	if job.Kind != TransactorJobRegistration {
+		res := SettleResponse{}
+		return res.ID, requests.ParseResponseJSON(resp, &res)
+	}
+
+	var regReq IdentityRegistrationRequest
+	if err := json.Unmarshal(job.Payload, &regReq); err != nil {
+		log.Err(err).Msgf("Could not decode registration request of job %s", job.Key)
+		return "", nil
+	}
+	// This is left as a synchronous call on purpose.
+	// We need to notify registry before returning.
+	t.publisher.Publish(AppTopicTransactorRegistration, regReq)
+	return "", nil
+}
+
+// send sends the request through the queue, registrations failing transiently are retried in the background.
+func (t *Transactor) send(kind TransactorJobKind, key, endpoint string, payload interface{}) (string, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s request: %w", kind, err)
+	}
+
+	job := TransactorJob{Key: key, Kind: kind, Endpoint: endpoint, Payload: raw, Background: kind == TransactorJobRegistration}
+	if t.queue == nil {
+		return t.HandleJob(job)
+	}
+
+	job, err = t.queue.Do(job, t.HandleJob)
+	return job.Result, err
+}
+
+func settlementJobKey(endpoint string, promise pc.Promise) string {
+	return fmt.Sprintf("%s|%d|%x|%s", endpoint, promise.ChainID, promise.ChannelID, promise.Amount)
+}
+
+func registrationJobKey(id string, chainID int64) string {
+	return fmt.Sprintf("registration|%d|%s", chainID, strings.ToLower(id))
+}
+
 // FeesResponse represents fees applied by Transactor
 type FeesResponse struct {
 	Fee        *big.Int  `json:"fee"`
 	ValidUntil time.Time `json:"valid_until"`
 }
 
-// IsValid returns false if the fee has already expired and should be re-requested
-func (fr FeesResponse) IsValid() bool {
-	return time.Now().UTC().Before(fr.ValidUntil.UTC())
+// IsValid returns false if the fee has already expired at the given time and should be re-requested
+func (fr FeesResponse) IsValid(now time.Time) bool {
+	return now.UTC().Before(fr.ValidUntil.UTC())
 }
 
 // IdentityRegistrationRequest represents the identity registration request body
@@ -271,12 +339,8 @@ func (t *Transactor) SettleAndRebalance(hermesID, providerID string, promise pc.
 		ChainID:       promise.ChainID,
 	}
 
-	req, err := requests.NewPostRequest(t.endpointAddress, "identity/settle_and_rebalance", payload)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to create settle and rebalance request")
-	}
-	res := SettleResponse{}
-	return res.ID, t.httpClient.DoRequestAndParseResponse(req, &res)
+	endpoint := "identity/settle_and_rebalance"
+	return t.send(TransactorJobSettlement, settlementJobKey(endpoint, promise), endpoint, payload)
 }
 
 func (t *Transactor) registerIdentity(endpoint string, id string, stake, fee *big.Int, beneficiary string, chainID int64) error {
@@ -290,21 +354,8 @@ func (t *Transactor) registerIdentity(endpoint string, id string, stake, fee *bi
 		return errors.Wrap(err, "identity request validation failed")
 	}
 
-	req, err := requests.NewPostRequest(t.endpointAddress, endpoint, regReq)
-	if err != nil {
-		return errors.Wrap(err, "failed to create RegisterIdentity request")
-	}
-
-	err = t.httpClient.DoRequest(req)
-	if err != nil {
-		return err
-	}
-
-	// This is left as a synchronous call on purpose.
-	// We need to notify registry before returning.
-	t.publisher.Publish(AppTopicTransactorRegistration, regReq)
-
-	return nil
+	_, err = t.send(TransactorJobRegistration, registrationJobKey(id, chainID), endpoint, regReq)
+	return err
 }
 
 type identityRegistrationRequestWithToken struct {
@@ -328,21 +379,8 @@ func (t *Transactor) registerIdentityWithReferralToken(id string, stake *big.Int
 		Token:                       token,
 	}
 
-	req, err := requests.NewPostRequest(t.endpointAddress, "identity/register/referer", r)
-	if err != nil {
-		return errors.Wrap(err, "failed to create RegisterIdentity request")
-	}
-
-	err = t.httpClient.DoRequest(req)
-	if err != nil {
-		return err
-	}
-
-	// This is left as a synchronous call on purpose.
-	// We need to notify registry before returning.
-	t.publisher.Publish(AppTopicTransactorRegistration, regReq)
-
-	return nil
+	_, err = t.send(TransactorJobRegistration, registrationJobKey(id, chainID), "identity/register/referer", r)
+	return err
 }
 
 // TokenRewardResponse represents the token reward response.
@@ -647,13 +685,8 @@ func (t *Transactor) SettleWithBeneficiary(id, beneficiary, hermesID string, pro
 		Registry:    registry.Hex(),
 	}
 
-	req, err := requests.NewPostRequest(t.endpointAddress, "identity/settle_with_beneficiary", payload)
-	if err != nil {
-		return "", fmt.Errorf("failed to create RegisterIdentity request %w", err)
-	}
-
-	res := SettleResponse{}
-	return res.ID, t.httpClient.DoRequestAndParseResponse(req, &res)
+	endpoint := "identity/settle_with_beneficiary"
+	return t.send(TransactorJobSettlement, settlementJobKey(endpoint, promise), endpoint, payload)
 }
 
 func (t *Transactor) fillSetBeneficiaryRequest(chainID int64, id, beneficiary, registry string) (pc.SetBeneficiaryRequest, error) {
@@ -748,12 +781,8 @@ func (t *Transactor) SettleIntoStake(hermesID, providerID string, promise pc.Pro
 		ChainID:       promise.ChainID,
 	}
 
-	req, err := requests.NewPostRequest(t.endpointAddress, "identity/settle/into_stake", payload)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to create settle into stake request")
-	}
-	res := SettleResponse{}
-	return res.ID, t.httpClient.DoRequestAndParseResponse(req, &res)
+	endpoint := "identity/settle/into_stake"
+	return t.send(TransactorJobSettlement, settlementJobKey(endpoint, promise), endpoint, payload)
 }
 
 // EligibilityResponse shows if one is eligible for free registration.
@@ -811,12 +840,8 @@ func (t *Transactor) PayAndSettle(hermesID, providerID string, promise pc.Promis
 		BeneficiarySignature: beneficiarySignature,
 	}
 
-	req, err := requests.NewPostRequest(t.endpointAddress, "identity/pay_and_settle", payload)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to create pay and settle request")
-	}
-	res := SettleResponse{}
-	return res.ID, t.httpClient.DoRequestAndParseResponse(req, &res)
+	endpoint := "identity/pay_and_settle"
+	return t.send(TransactorJobSettlement, settlementJobKey(endpoint, promise), endpoint, payload)
 }
 
 // DecreaseProviderStakeRequest represents all the parameters required for decreasing provider stake.
@@ -0,0 +1,446 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/rs/zerolog/log"
+)
+
+const transactorJobBucket = "transactor_jobs"
+
+// TransactorJobKind identifies what a transactor job does.
+type TransactorJobKind string
+
+const (
+	// TransactorJobRegistration is an identity registration request.
+	TransactorJobRegistration TransactorJobKind = "registration"
+	// TransactorJobSettlement is a promise settlement request.
+	TransactorJobSettlement TransactorJobKind = "settlement"
+)
+
+// TransactorJobState is the state of a transactor job.
+type TransactorJobState string
+
+const (
+	// TransactorJobPending job is waiting to be (re)sent.
+	TransactorJobPending TransactorJobState = "pending"
+	// TransactorJobDone job was accepted by transactor.
+	TransactorJobDone TransactorJobState = "done"
+	// TransactorJobFailed job was rejected by transactor or expired.
+	TransactorJobFailed TransactorJobState = "failed"
+)
+
+// ErrTransactorJobInProgress is returned when a job with the same key is still pending.
+var ErrTransactorJobInProgress = errors.New("transactor request with the same key is already in progress")
+
+// errTransactorJobLost is recorded for pending jobs whose payload was lost with the node restart,
+// which happens if the queue has no cipher to persist payloads with.
+var errTransactorJobLost = errors.New("request was not sent before the node restart")
+
+// TransactorJob is a persisted transactor request.
+type TransactorJob struct {
+	// Key deduplicates requests, only one job per key may be pending.
+	Key      string `storm:"id"`
+	Kind     TransactorJobKind
+	Endpoint string
+	// Payload is not stored as is, since requests may contain secrets such as referral tokens.
+	Payload json.RawMessage `json:"-"`
+	// EncryptedPayload keeps the payload of pending jobs, so that they are retried after the node restart.
+	EncryptedPayload []byte `json:",omitempty"`
+	// Background jobs are accepted when they fail transiently and are retried in the background,
+	// other jobs return transient failures to the caller and are not sent again.
+	Background    bool
+	State         TransactorJobState
+	Attempts      int
+	LastError     string
+	Result        string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	NextAttemptAt time.Time
+}
+
+// TransactorQueueConfig configures transactor queue retries.
+type TransactorQueueConfig struct {
+	// Backoff is the delay before the first background attempt, doubled after each attempt.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// RetryInterval is how often pending jobs are checked in the background.
+	RetryInterval time.Duration
+	// DedupWindow is for how long a finished job is returned instead of sending the same request again.
+	DedupWindow time.Duration
+	// Expiry is for how long background jobs are retried.
+	Expiry time.Duration
+	// Retention is for how long finished jobs are kept for inspection.
+	Retention time.Duration
+}
+
+// DefaultTransactorQueueConfig returns default transactor queue config.
+func DefaultTransactorQueueConfig() TransactorQueueConfig {
+	return TransactorQueueConfig{
+		Backoff:       15 * time.Second,
+		MaxBackoff:    10 * time.Minute,
+		RetryInterval: 15 * time.Second,
+		DedupWindow:   10 * time.Minute,
+		Expiry:        time.Hour,
+		Retention:     72 * time.Hour,
+	}
+}
+
+type transactorJobStorage interface {
+	Store(bucket string, data interface{}) error
+	GetOneByField(bucket string, fieldName string, key interface{}, to interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+	Delete(bucket string, data interface{}) error
+}
+
+type payloadCipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// TransactorJobHandler sends the job and returns the transactor result.
+type TransactorJobHandler func(job TransactorJob) (string, error)
+
+// TransactorQueue persists transactor requests and retries them on transient failures.
+// Payloads of pending jobs are stored encrypted if cipher is given, otherwise they are kept in memory only.
+type TransactorQueue struct {
+	storage transactorJobStorage
+	cipher  payloadCipher
+	config  TransactorQueueConfig
+	now     func() time.Time
+
+	lock     sync.Mutex
+	running  map[string]struct{}
+	payloads map[string]json.RawMessage
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewTransactorQueue creates a new transactor queue.
+func NewTransactorQueue(storage transactorJobStorage, cipher payloadCipher, config TransactorQueueConfig) *TransactorQueue {
+	return &TransactorQueue{
+		storage:  storage,
+		cipher:   cipher,
+		config:   config,
+		now:      time.Now,
+		running:  make(map[string]struct{}),
+		payloads: make(map[string]json.RawMessage),
+		stop:     make(chan struct{}),
+	}
+}
+
+// Do persists the job and sends it using the handler once.
+// A background job that fails transiently is accepted, it stays pending and is retried in the background.
+// If a job with the same key finished recently, its result is returned without sending it again.
+func (q *TransactorQueue) Do(job TransactorJob, handler TransactorJobHandler) (TransactorJob, error) {
+	q.lock.Lock()
+	existing, err := q.get(job.Key)
+	if err == nil {
+		// pending job without payload was lost with the node restart and may be submitted again
+		if _, ok := q.payload(existing); ok && existing.State == TransactorJobPending {
+			q.lock.Unlock()
+			return existing, ErrTransactorJobInProgress
+		}
+		if existing.State == TransactorJobDone && q.now().Sub(existing.UpdatedAt) < q.config.DedupWindow {
+			q.lock.Unlock()
+			return existing, nil
+		}
+	} else if !errors.Is(err, ErrNotFound) {
+		q.lock.Unlock()
+		return job, err
+	}
+
+	now := q.now().UTC()
+	job.State = TransactorJobPending
+	job.Attempts = 0
+	job.LastError = ""
+	job.Result = ""
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	job.NextAttemptAt = now
+	if job.EncryptedPayload, err = q.encrypt(job.Payload); err != nil {
+		q.lock.Unlock()
+		return job, err
+	}
+	if err := q.store(job); err != nil {
+		q.lock.Unlock()
+		return job, err
+	}
+	q.running[job.Key] = struct{}{}
+	q.payloads[job.Key] = job.Payload
+	q.lock.Unlock()
+
+	defer q.release(job.Key)
+
+	job, err = q.attempt(job, handler)
+	if job.State == TransactorJobPending {
+		log.Warn().Err(err).Msgf("Transactor %s job %s failed, will retry in background", job.Kind, job.Key)
+		return job, nil
+	}
+	return job, err
+}
+
+// Jobs returns all the jobs, newest first.
+func (q *TransactorQueue) Jobs() ([]TransactorJob, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.all()
+}
+
+// Start retries pending jobs in the background until stopped.
+func (q *TransactorQueue) Start(handler TransactorJobHandler) {
+	go func() {
+		ticker := time.NewTicker(q.config.RetryInterval)
+		defer ticker.Stop()
+
+		for {
+			q.retryPending(handler)
+
+			select {
+			case <-q.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops background retries.
+func (q *TransactorQueue) Stop() {
+	q.once.Do(func() {
+		close(q.stop)
+	})
+}
+
+func (q *TransactorQueue) retryPending(handler TransactorJobHandler) {
+	q.lock.Lock()
+	jobs, err := q.all()
+	if err != nil {
+		q.lock.Unlock()
+		log.Error().Err(err).Msg("Could not load transactor jobs")
+		return
+	}
+
+	now := q.now()
+	var due []TransactorJob
+	for _, job := range jobs {
+		if _, ok := q.running[job.Key]; ok {
+			continue
+		}
+
+		switch job.State {
+		case TransactorJobPending:
+			payload, ok := q.payload(job)
+			if !ok || now.Sub(job.CreatedAt) > q.config.Expiry {
+				job.State = TransactorJobFailed
+				job.LastError = "expired: " + job.LastError
+				if !ok {
+					job.LastError = errTransactorJobLost.Error()
+				}
+				job.UpdatedAt = now.UTC()
+				job.EncryptedPayload = nil
+				delete(q.payloads, job.Key)
+				if err := q.store(job); err != nil {
+					log.Error().Err(err).Msgf("Could not expire transactor job %s", job.Key)
+				}
+				continue
+			}
+			if !now.Before(job.NextAttemptAt) {
+				job.Payload = payload
+				q.running[job.Key] = struct{}{}
+				due = append(due, job)
+			}
+		default:
+			if now.Sub(job.UpdatedAt) > q.config.Retention {
+				if err := q.storage.Delete(transactorJobBucket, &job); err != nil {
+					log.Error().Err(err).Msgf("Could not delete transactor job %s", job.Key)
+				}
+			}
+		}
+	}
+	q.lock.Unlock()
+
+	for _, job := range due {
+		log.Info().Msgf("Retrying transactor %s job %s, attempt %d", job.Kind, job.Key, job.Attempts+1)
+		if _, err := q.attempt(job, handler); err != nil {
+			log.Warn().Err(err).Msgf("Transactor %s job %s failed", job.Kind, job.Key)
+		}
+		q.release(job.Key)
+	}
+}
+
+func (q *TransactorQueue) attempt(job TransactorJob, handler TransactorJobHandler) (TransactorJob, error) {
+	result, err := handler(job)
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	now := q.now().UTC()
+	job.Attempts++
+	job.UpdatedAt = now
+	switch {
+	case err == nil:
+		job.State = TransactorJobDone
+		job.Result = result
+		job.LastError = ""
+	case job.Background && isTransientTransactorError(err):
+		job.LastError = err.Error()
+		job.NextAttemptAt = now.Add(q.backoff(job.Attempts))
+	default:
+		job.State = TransactorJobFailed
+		job.LastError = err.Error()
+	}
+	if job.State != TransactorJobPending {
+		job.EncryptedPayload = nil
+		delete(q.payloads, job.Key)
+	}
+
+	if storeErr := q.store(job); storeErr != nil {
+		log.Error().Err(storeErr).Msgf("Could not update transactor job %s", job.Key)
+	}
+	return job, err
+}
+
+func (q *TransactorQueue) backoff(attempts int) time.Duration {
+	backoff := q.config.Backoff
+	for i := 1; i < attempts && backoff < q.config.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > q.config.MaxBackoff {
+		return q.config.MaxBackoff
+	}
+	return backoff
+}
+
+func (q *TransactorQueue) encrypt(payload json.RawMessage) ([]byte, error) {
+	if q.cipher == nil || len(payload) == 0 {
+		return nil, nil
+	}
+
+	data, err := q.cipher.Encrypt(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not encrypt transactor job payload: %w", err)
+	}
+	return data, nil
+}
+
+// payload must be called with q.lock held, it returns the payload kept in memory or the stored one.
+func (q *TransactorQueue) payload(job TransactorJob) (json.RawMessage, bool) {
+	if payload, ok := q.payloads[job.Key]; ok {
+		return payload, true
+	}
+	if q.cipher == nil || len(job.EncryptedPayload) == 0 {
+		return nil, false
+	}
+
+	payload, err := q.cipher.Decrypt(job.EncryptedPayload)
+	if err != nil {
+		log.Error().Err(err).Msgf("Could not decrypt transactor job %s payload", job.Key)
+		return nil, false
+	}
+	q.payloads[job.Key] = payload
+	return payload, true
+}
+
+func (q *TransactorQueue) release(key string) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	delete(q.running, key)
+}
+
+func (q *TransactorQueue) get(key string) (TransactorJob, error) {
+	var job TransactorJob
+	err := q.storage.GetOneByField(transactorJobBucket, "Key", key, &job)
+	if err != nil {
+		if errors.Is(err, storm.ErrNotFound) {
+			return job, ErrNotFound
+		}
+		return job, fmt.Errorf("could not get transactor job: %w", err)
+	}
+	return job, nil
+}
+
+func (q *TransactorQueue) all() ([]TransactorJob, error) {
+	var jobs []TransactorJob
+	if err := q.storage.GetAllFrom(transactorJobBucket, &jobs); err != nil {
+		if errors.Is(err, storm.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not get transactor jobs: %w", err)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+	return jobs, nil
+}
+
+func (q *TransactorQueue) store(job TransactorJob) error {
+	if err := q.storage.Store(transactorJobBucket, &job); err != nil {
+		return fmt.Errorf("could not store transactor job: %w", err)
+	}
+	return nil
+}
+
+// transactorResponseError keeps the status of a failed transactor response.
+type transactorResponseError struct {
+	status int
+	err    error
+}
+
+func (e *transactorResponseError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transactorResponseError) Unwrap() error {
+	return e.err
+}
+
+// isTransientTransactorError returns true for network errors and 5xx or 429 responses.
+func isTransientTransactorError(err error) bool {
+	var respErr *transactorResponseError
+	if errors.As(err, &respErr) {
+		return isTransientStatus(respErr.status)
+	}
+
+	var apiErr *apierror.APIError
+	if errors.As(err, &apiErr) {
+		return isTransientStatus(apiErr.Status)
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func isTransientStatus(status int) bool {
+	return status >= http.StatusInternalServerError || status == http.StatusTooManyRequests
+}
@@ -0,0 +1,254 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage/encryption"
+)
+
+type mockJobHandler struct {
+	errs  []error
+	calls int
+}
+
+func (m *mockJobHandler) handle(_ TransactorJob) (string, error) {
+	m.calls++
+	if len(m.errs) > 0 {
+		err := m.errs[0]
+		m.errs = m.errs[1:]
+		if err != nil {
+			return "", err
+		}
+	}
+	return "queue-id", nil
+}
+
+func newTestTransactorQueue(t *testing.T) (*TransactorQueue, *time.Time) {
+	bolt, err := boltdb.NewStorage(t.TempDir())
+	assert.NoError(t, err)
+	t.Cleanup(func() { bolt.Close() })
+
+	return newTestTransactorQueueWithStorage(bolt, nil)
+}
+
+func newTestTransactorQueueWithStorage(storage transactorJobStorage, cipher payloadCipher) (*TransactorQueue, *time.Time) {
+	q := NewTransactorQueue(storage, cipher, TransactorQueueConfig{
+		Backoff:       time.Second,
+		MaxBackoff:    time.Minute,
+		RetryInterval: time.Second,
+		DedupWindow:   time.Minute,
+		Expiry:        time.Hour,
+		Retention:     2 * time.Hour,
+	})
+	now := time.Now()
+	q.now = func() time.Time { return now }
+	return q, &now
+}
+
+func TestTransactorQueue_ReturnsTransientErrorsOfForegroundJobs(t *testing.T) {
+	q, now := newTestTransactorQueue(t)
+	unavailable := &apierror.APIError{Status: http.StatusBadGateway}
+	h := &mockJobHandler{errs: []error{unavailable}}
+
+	job, err := q.Do(TransactorJob{Key: "settle", Kind: TransactorJobSettlement}, h.handle)
+	assert.ErrorIs(t, err, unavailable)
+	assert.Equal(t, 1, h.calls)
+	assert.Equal(t, TransactorJobFailed, job.State)
+
+	// failed request is not sent again in the background
+	*now = now.Add(time.Minute)
+	q.retryPending(h.handle)
+	assert.Equal(t, 1, h.calls)
+
+	job, err = q.Do(TransactorJob{Key: "settle", Kind: TransactorJobSettlement}, h.handle)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, h.calls)
+	assert.Equal(t, TransactorJobDone, job.State)
+	assert.Equal(t, "queue-id", job.Result)
+
+	// same request is not sent again
+	job, err = q.Do(TransactorJob{Key: "settle", Kind: TransactorJobSettlement}, h.handle)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, h.calls)
+	assert.Equal(t, "queue-id", job.Result)
+}
+
+func TestTransactorQueue_RetriesBackgroundJobs(t *testing.T) {
+	q, now := newTestTransactorQueue(t)
+	unavailable := &apierror.APIError{Status: http.StatusServiceUnavailable}
+	h := &mockJobHandler{errs: []error{unavailable, unavailable}}
+
+	job, err := q.Do(TransactorJob{Key: "register", Kind: TransactorJobRegistration, Background: true}, h.handle)
+	assert.NoError(t, err)
+	assert.Equal(t, TransactorJobPending, job.State)
+	assert.Equal(t, 1, job.Attempts)
+
+	_, err = q.Do(TransactorJob{Key: "register", Kind: TransactorJobRegistration, Background: true}, h.handle)
+	assert.ErrorIs(t, err, ErrTransactorJobInProgress)
+	assert.Equal(t, 1, h.calls)
+
+	// not due yet
+	q.retryPending(h.handle)
+	assert.Equal(t, 1, h.calls)
+
+	*now = now.Add(time.Minute)
+	q.retryPending(h.handle)
+	assert.Equal(t, 2, h.calls)
+
+	*now = now.Add(time.Minute)
+	q.retryPending(h.handle)
+	assert.Equal(t, 3, h.calls)
+
+	jobs, err := q.Jobs()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, TransactorJobDone, jobs[0].State)
+	assert.Equal(t, 3, jobs[0].Attempts)
+	assert.Empty(t, jobs[0].LastError)
+}
+
+func TestTransactorQueue_DoesNotPersistPayloadsWithoutCipher(t *testing.T) {
+	bolt, err := boltdb.NewStorage(t.TempDir())
+	assert.NoError(t, err)
+	defer bolt.Close()
+
+	q, now := newTestTransactorQueueWithStorage(bolt, nil)
+	unavailable := &apierror.APIError{Status: http.StatusServiceUnavailable}
+	h := &mockJobHandler{errs: []error{unavailable}}
+
+	_, err = q.Do(TransactorJob{Key: "register", Kind: TransactorJobRegistration, Background: true, Payload: []byte(`{"token":"secret"}`)}, h.handle)
+	assert.NoError(t, err)
+
+	var stored TransactorJob
+	assert.NoError(t, bolt.GetOneByField(transactorJobBucket, "Key", "register", &stored))
+	assert.Empty(t, stored.Payload)
+	assert.Empty(t, stored.EncryptedPayload)
+
+	// payload is lost with the restart, so the job fails and may be submitted again
+	restarted, _ := newTestTransactorQueueWithStorage(bolt, nil)
+	restarted.now = func() time.Time { return now.Add(time.Minute) }
+	restarted.retryPending(h.handle)
+	assert.Equal(t, 1, h.calls)
+
+	jobs, err := restarted.Jobs()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, TransactorJobFailed, jobs[0].State)
+
+	job, err := restarted.Do(TransactorJob{Key: "register", Kind: TransactorJobRegistration, Background: true}, h.handle)
+	assert.NoError(t, err)
+	assert.Equal(t, TransactorJobDone, job.State)
+}
+
+func TestTransactorQueue_RetriesEncryptedPayloadsAfterRestart(t *testing.T) {
+	bolt, err := boltdb.NewStorage(t.TempDir())
+	assert.NoError(t, err)
+	defer bolt.Close()
+	cipher, err := encryption.NewCipher(bytes.Repeat([]byte{1}, 32))
+	assert.NoError(t, err)
+
+	q, now := newTestTransactorQueueWithStorage(bolt, cipher)
+	unavailable := &apierror.APIError{Status: http.StatusServiceUnavailable}
+	var sent []string
+	handler := func(job TransactorJob) (string, error) {
+		sent = append(sent, string(job.Payload))
+		if len(sent) == 1 {
+			return "", unavailable
+		}
+		return "queue-id", nil
+	}
+
+	payload := `{"token":"secret"}`
+	_, err = q.Do(TransactorJob{Key: "register", Kind: TransactorJobRegistration, Background: true, Payload: []byte(payload)}, handler)
+	assert.NoError(t, err)
+
+	var stored TransactorJob
+	assert.NoError(t, bolt.GetOneByField(transactorJobBucket, "Key", "register", &stored))
+	assert.NotEmpty(t, stored.EncryptedPayload)
+	assert.NotContains(t, string(stored.EncryptedPayload), "secret")
+
+	// the same request is retried after the restart
+	restarted, _ := newTestTransactorQueueWithStorage(bolt, cipher)
+	_, err = restarted.Do(TransactorJob{Key: "register", Kind: TransactorJobRegistration, Background: true}, handler)
+	assert.ErrorIs(t, err, ErrTransactorJobInProgress)
+
+	restarted.now = func() time.Time { return now.Add(time.Minute) }
+	restarted.retryPending(handler)
+	assert.Equal(t, []string{payload, payload}, sent)
+
+	jobs, err := restarted.Jobs()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, TransactorJobDone, jobs[0].State)
+	assert.Empty(t, jobs[0].EncryptedPayload)
+}
+
+func TestTransactorQueue_PermanentErrorsFailJob(t *testing.T) {
+	q, _ := newTestTransactorQueue(t)
+	h := &mockJobHandler{errs: []error{&apierror.APIError{Status: http.StatusBadRequest}}}
+
+	job, err := q.Do(TransactorJob{Key: "register", Kind: TransactorJobRegistration, Background: true}, h.handle)
+	assert.Error(t, err)
+	assert.Equal(t, TransactorJobFailed, job.State)
+	assert.Equal(t, 1, h.calls)
+
+	// failed jobs may be submitted again
+	job, err = q.Do(TransactorJob{Key: "register", Kind: TransactorJobRegistration, Background: true}, h.handle)
+	assert.NoError(t, err)
+	assert.Equal(t, TransactorJobDone, job.State)
+	assert.Equal(t, 1, job.Attempts)
+}
+
+func TestTransactorQueue_ExpiresAndPrunesJobs(t *testing.T) {
+	q, now := newTestTransactorQueue(t)
+	h := &mockJobHandler{errs: []error{errors.New("boom")}}
+	_, err := q.Do(TransactorJob{Key: "failed", Kind: TransactorJobSettlement}, h.handle)
+	assert.Error(t, err)
+
+	unavailable := &apierror.APIError{Status: http.StatusInternalServerError}
+	h.errs = []error{unavailable}
+	_, err = q.Do(TransactorJob{Key: "pending", Kind: TransactorJobRegistration, Background: true}, h.handle)
+	assert.NoError(t, err)
+
+	*now = now.Add(90 * time.Minute)
+	q.retryPending(h.handle)
+
+	jobs, err := q.Jobs()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 2)
+	for _, job := range jobs {
+		assert.Equal(t, TransactorJobFailed, job.State)
+	}
+
+	*now = now.Add(3 * time.Hour)
+	q.retryPending(h.handle)
+
+	jobs, err = q.Jobs()
+	assert.NoError(t, err)
+	assert.Empty(t, jobs)
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package selector
+
+import (
+	"fmt"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// allowlistHandler refuses identities which may not be used, e.g. outside of a private network allowlist.
+type allowlistHandler struct {
+	Handler
+	allowed func(address string) bool
+}
+
+// NewAllowlistHandler wraps the handler so that only identities passing the allowed check can be used.
+func NewAllowlistHandler(handler Handler, allowed func(address string) bool) Handler {
+	return &allowlistHandler{Handler: handler, allowed: allowed}
+}
+
+func (h *allowlistHandler) UseOrCreate(address, passphrase string, chainID int64) (identity.Identity, error) {
+	if address != "" && !h.allowed(address) {
+		return identity.Identity{}, fmt.Errorf("identity %s is not allowed", address)
+	}
+
+	id, err := h.Handler.UseOrCreate(address, passphrase, chainID)
+	if err != nil {
+		return id, err
+	}
+	if !h.allowed(id.Address) {
+		return identity.Identity{}, fmt.Errorf("identity %s is not allowed", id.Address)
+	}
+	return id, nil
+}
+
+func (h *allowlistHandler) SetDefault(address string) error {
+	if !h.allowed(address) {
+		return fmt.Errorf("identity %s is not allowed", address)
+	}
+	return h.Handler.SetDefault(address)
+}
@@ -0,0 +1,42 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package selector
+
+import (
+	"testing"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowlistHandler(t *testing.T) {
+	identityManager := identity.NewIdentityManagerFake([]identity.Identity{existingIdentity}, newIdentity)
+	cache := identity.NewIdentityCacheFake()
+	handler := NewAllowlistHandler(NewHandler(identityManager, cache, fakeSignerFactory), func(address string) bool {
+		return address == existingIdentity.Address
+	})
+
+	id, err := handler.UseOrCreate(existingIdentity.Address, "", chainID)
+	assert.NoError(t, err)
+	assert.Equal(t, existingIdentity, id)
+
+	_, err = handler.UseOrCreate(newIdentity.Address, "", chainID)
+	assert.EqualError(t, err, "identity new is not allowed")
+
+	assert.EqualError(t, handler.SetDefault(newIdentity.Address), "identity new is not allowed")
+}
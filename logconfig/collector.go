@@ -59,6 +59,14 @@ func (c *Collector) Archive() (outputFilepath string, err error) {
 	return zipFilepath, nil
 }
 
+// Filepaths returns paths of the current and the most recent rotated log files.
+func (c *Collector) Filepaths() ([]string, error) {
+	if c.options.Filepath == "" {
+		return nil, errors.New("file logging is disabled, can't retrieve logs")
+	}
+	return c.logFilepaths()
+}
+
 func (c *Collector) logFilepaths() (result []string, err error) {
 	filename := path.Base(c.options.Filepath)
 	dir := path.Dir(c.options.Filepath)
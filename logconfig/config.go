@@ -72,20 +72,26 @@ func SetLogLevel(level zerolog.Level) {
 func Configure(opts *LogOptions) {
 	CurrentLogOptions = *opts
 	log.Info().Msgf("Log level: %s", opts.LogLevel)
+	w := consoleWriter()
 	if opts.Filepath != "" {
 		log.Info().Msgf("Log file path: %s", opts.Filepath)
 		rollingWriter, err := rollingwriter.NewRollingWriter(opts.Filepath)
 		if err != nil {
 			log.Err(err).Msg("Failed to configure file logger")
 		} else {
-			multiWriter := io.MultiWriter(consoleWriter(), zeroLogger(rollingWriter.Writer))
-			logger := makeLogger(multiWriter)
-			setGlobalLogger(&logger)
+			w = io.MultiWriter(consoleWriter(), zeroLogger(rollingWriter.Writer))
 		}
 		if err := rollingWriter.CleanObsoleteLogs(); err != nil {
 			log.Err(err).Msg("Failed to cleanup obsolete logs")
 		}
 	}
+	if opts.DedupInterval > 0 {
+		dedup := newDedupWriter(w, opts.DedupInterval, opts.DedupLevel)
+		dedup.start()
+		w = dedup
+	}
+	logger := makeLogger(w)
+	setGlobalLogger(&logger)
 	log.Logger = log.Logger.Level(opts.LogLevel)
 }
 
@@ -0,0 +1,160 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package logconfig
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// dedupMaxEntries bounds memory used for tracking distinct messages.
+const dedupMaxEntries = 1000
+
+type dedupEntry struct {
+	level      zerolog.Level
+	msg        string
+	emittedAt  time.Time
+	suppressed int
+}
+
+// dedupWriter suppresses repeated identical messages, e.g. errors logged from packet loops.
+// Messages are identical when their level, message and fields other than the timestamp match.
+// The first message is written and its repetitions are only counted, the count is reported
+// with the next message written after the interval, or on its own once repetitions stop.
+type dedupWriter struct {
+	out      io.Writer
+	interval time.Duration
+	level    zerolog.Level
+	now      func() time.Time
+	report   func(level zerolog.Level, msg string, suppressed int)
+
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+}
+
+func newDedupWriter(out io.Writer, interval time.Duration, level zerolog.Level) *dedupWriter {
+	return &dedupWriter{
+		out:      out,
+		interval: interval,
+		level:    level,
+		now:      time.Now,
+		report: func(level zerolog.Level, msg string, suppressed int) {
+			log.WithLevel(level).Int("suppressed", suppressed).Msgf("Repeated message suppressed: %s", msg)
+		},
+		entries: make(map[string]*dedupEntry),
+	}
+}
+
+// start periodically reports counts of repetitions which stopped.
+func (w *dedupWriter) start() {
+	go func() {
+		for range time.Tick(w.interval) {
+			w.flush()
+		}
+	}()
+}
+
+// Write implements io.Writer.
+func (w *dedupWriter) Write(p []byte) (int, error) {
+	return w.out.Write(p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *dedupWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level < w.level || level == zerolog.NoLevel {
+		return w.out.Write(p)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(p, &fields); err != nil {
+		return w.out.Write(p)
+	}
+	var msg string
+	if err := json.Unmarshal(fields[zerolog.MessageFieldName], &msg); err != nil || msg == "" {
+		return w.out.Write(p)
+	}
+	delete(fields, zerolog.TimestampFieldName)
+	key, err := json.Marshal(fields)
+	if err != nil {
+		return w.out.Write(p)
+	}
+
+	emit, suppressed := w.record(string(key), level, msg)
+	if !emit {
+		return len(p), nil
+	}
+	if suppressed > 0 && len(p) > 1 {
+		line := append([]byte(`{"suppressed":`+strconv.Itoa(suppressed)+`,`), p[1:]...)
+		if _, err := w.out.Write(line); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	return w.out.Write(p)
+}
+
+// record decides whether the message is written, returning the count of its suppressed repetitions.
+func (w *dedupWriter) record(key string, level zerolog.Level, msg string) (bool, int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.now()
+	entry, ok := w.entries[key]
+	switch {
+	case !ok:
+		if len(w.entries) >= dedupMaxEntries {
+			w.entries = make(map[string]*dedupEntry)
+		}
+		w.entries[key] = &dedupEntry{level: level, msg: msg, emittedAt: now}
+		return true, 0
+	case now.Sub(entry.emittedAt) < w.interval:
+		entry.suppressed++
+		return false, 0
+	default:
+		suppressed := entry.suppressed
+		entry.emittedAt, entry.suppressed = now, 0
+		return true, suppressed
+	}
+}
+
+// flush forgets messages not written for the interval, reporting their suppressed repetitions.
+func (w *dedupWriter) flush() {
+	w.mu.Lock()
+	now := w.now()
+	var expired []*dedupEntry
+	for k, entry := range w.entries {
+		if now.Sub(entry.emittedAt) < w.interval {
+			continue
+		}
+		delete(w.entries, k)
+		if entry.suppressed > 0 {
+			expired = append(expired, entry)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, entry := range expired {
+		w.report(entry.level, entry.msg, entry.suppressed)
+	}
+}
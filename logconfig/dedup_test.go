@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package logconfig
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupWriter(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	var out bytes.Buffer
+	w := newDedupWriter(&out, 10*time.Second, zerolog.WarnLevel)
+	w.now = func() time.Time { return now }
+	var reported []string
+	w.report = func(level zerolog.Level, msg string, suppressed int) {
+		reported = append(reported, level.String()+" "+msg)
+		assert.Equal(t, 2, suppressed)
+	}
+
+	logger := zerolog.New(w).With().Timestamp().Logger()
+	zerolog.TimestampFunc = func() time.Time { return now }
+	defer func() { zerolog.TimestampFunc = time.Now }()
+	lines := func() []string {
+		defer out.Reset()
+		return strings.Split(strings.TrimSpace(out.String()), "\n")
+	}
+
+	for i := 0; i < 5; i++ {
+		logger.Error().Err(errors.New("timeout")).Msg("packet write failed")
+	}
+	logger.Error().Err(errors.New("refused")).Msg("packet write failed")
+	logger.Error().Msg("other")
+	assert.Equal(t, []string{
+		`{"level":"error","error":"timeout","time":"2022-01-01T00:00:00Z","message":"packet write failed"}`,
+		`{"level":"error","error":"refused","time":"2022-01-01T00:00:00Z","message":"packet write failed"}`,
+		`{"level":"error","time":"2022-01-01T00:00:00Z","message":"other"}`,
+	}, lines())
+
+	// messages below the dedup level are not suppressed
+	logger.Info().Msg("tick")
+	logger.Info().Msg("tick")
+	assert.Len(t, lines(), 2)
+
+	// repetitions are counted on the next message after the interval
+	now = now.Add(10 * time.Second)
+	logger.Error().Err(errors.New("timeout")).Msg("packet write failed")
+	assert.Equal(t, []string{`{"suppressed":4,"level":"error","error":"timeout","time":"2022-01-01T00:00:10Z","message":"packet write failed"}`}, lines())
+
+	logger.Error().Err(errors.New("timeout")).Msg("packet write failed")
+	logger.Error().Err(errors.New("timeout")).Msg("packet write failed")
+	assert.Empty(t, out.String())
+
+	// stopped repetitions are reported by the periodic flush
+	now = now.Add(10 * time.Second)
+	w.flush()
+	assert.Equal(t, []string{"error packet write failed"}, reported)
+	assert.Empty(t, w.entries)
+}
@@ -18,6 +18,8 @@
 package logconfig
 
 import (
+	"time"
+
 	"github.com/rs/zerolog"
 )
 
@@ -26,6 +28,10 @@ type LogOptions struct {
 	LogLevel zerolog.Level
 	LogHTTP  bool
 	Filepath string
+	// DedupInterval is the period during which repeated identical messages are suppressed, 0 disables it.
+	DedupInterval time.Duration
+	// DedupLevel is the lowest level of messages which are deduplicated.
+	DedupLevel zerolog.Level
 }
 
 // CurrentLogOptions stores global LogOptions.
@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package market
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mysteriumnetwork/node/pb"
+)
+
+// InvoiceTerms define how often the provider invoices the consumer during a session.
+// Zero values leave the choice to the provider.
+type InvoiceTerms struct {
+	// Period is the time between regular invoices.
+	Period time.Duration
+	// DataBytes is the traffic consumer may use before being invoiced.
+	DataBytes uint64
+}
+
+// NewInvoiceTerms creates invoice terms from the session message.
+func NewInvoiceTerms(in *pb.InvoiceTerms) InvoiceTerms {
+	return InvoiceTerms{
+		Period:    time.Duration(in.GetPeriodMillis()) * time.Millisecond,
+		DataBytes: in.GetDataBytes(),
+	}
+}
+
+// Proto returns invoice terms as a session message.
+func (t InvoiceTerms) Proto() *pb.InvoiceTerms {
+	return &pb.InvoiceTerms{
+		PeriodMillis: uint64(t.Period.Milliseconds()),
+		DataBytes:    t.DataBytes,
+	}
+}
+
+// String returns human readable invoice terms.
+func (t InvoiceTerms) String() string {
+	return fmt.Sprintf("every %v or %v bytes", t.Period, t.DataBytes)
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package market
+
+// Load represents provider utilization at the time of proposal announcement.
+type Load struct {
+	// Sessions is the number of active sessions of the service.
+	Sessions int `json:"sessions"`
+	// MaxSessions is the limit of concurrent service sessions, 0 when unlimited.
+	MaxSessions int `json:"max_sessions,omitempty"`
+	// Throughput is the current provider traffic in bytes per second.
+	Throughput uint64 `json:"throughput"`
+	// BandwidthCapacity is the advertised provider bandwidth in bytes per second, 0 when unknown.
+	BandwidthCapacity uint64 `json:"bandwidth_capacity,omitempty"`
+}
+
+// BandwidthHeadroom returns unused bandwidth in bytes per second, 0 when capacity is unknown.
+func (l Load) BandwidthHeadroom() uint64 {
+	if l.Throughput >= l.BandwidthCapacity {
+		return 0
+	}
+	return l.BandwidthCapacity - l.Throughput
+}
+
+// Utilization returns the most saturated of the advertised limits, 0..1.
+// Zero is returned when provider does not advertise any limits.
+func (l Load) Utilization() float64 {
+	var u float64
+	if l.MaxSessions > 0 {
+		u = float64(l.Sessions) / float64(l.MaxSessions)
+	}
+	if l.BandwidthCapacity > 0 {
+		if bu := float64(l.Throughput) / float64(l.BandwidthCapacity); bu > u {
+			u = bu
+		}
+	}
+	if u > 1 {
+		return 1
+	}
+	return u
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package market
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PriceBook holds provider set prices keyed by service type and consumer country.
+// The most specific entry matching the session is used, network prices apply when none matches.
+type PriceBook struct {
+	// Regions group country codes under a name which entries can use instead of a single country.
+	Regions map[string][]string `json:"regions,omitempty"`
+	Entries []PriceBookEntry    `json:"entries"`
+}
+
+// PriceBookEntry is a price applied to consumers of the given service type from the given country or region.
+type PriceBookEntry struct {
+	// ServiceType the price applies to, empty for all services.
+	ServiceType string `json:"service_type,omitempty"`
+	// Country code or region name the price applies to, empty for all consumers.
+	Country string `json:"country,omitempty"`
+	Price   Price  `json:"price"`
+}
+
+// Validate checks whether the price book is well formed.
+func (b PriceBook) Validate() error {
+	for name, countries := range b.Regions {
+		if name == "" {
+			return errors.New("region name is required")
+		}
+		if len(countries) == 0 {
+			return fmt.Errorf("region %q has no countries", name)
+		}
+	}
+
+	for i, e := range b.Entries {
+		if e.Price.PricePerHour == nil || e.Price.PricePerGiB == nil {
+			return fmt.Errorf("entry %d: both per hour and per GiB prices are required", i)
+		}
+		if e.Price.PricePerHour.Sign() < 0 || e.Price.PricePerGiB.Sign() < 0 {
+			return fmt.Errorf("entry %d: prices can not be negative", i)
+		}
+	}
+	return nil
+}
+
+// ForService returns the part of the price book which applies to the given service type, nil if none does.
+func (b *PriceBook) ForService(serviceType string) *PriceBook {
+	if b == nil {
+		return nil
+	}
+
+	var entries []PriceBookEntry
+	for _, e := range b.Entries {
+		if e.ServiceType == "" || strings.EqualFold(e.ServiceType, serviceType) {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return &PriceBook{Regions: b.Regions, Entries: entries}
+}
+
+// Lookup returns the price for a consumer from the given country using the given service type.
+func (b *PriceBook) Lookup(serviceType, country string) (Price, bool) {
+	if b == nil {
+		return Price{}, false
+	}
+
+	best, bestScore := -1, -1
+	for i, e := range b.Entries {
+		score := 0
+		switch {
+		case e.ServiceType == "":
+		case strings.EqualFold(e.ServiceType, serviceType):
+			score += 4
+		default:
+			continue
+		}
+
+		switch {
+		case e.Country == "":
+		case strings.EqualFold(e.Country, country):
+			score += 2
+		case b.inRegion(e.Country, country):
+			score++
+		default:
+			continue
+		}
+
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+
+	if best < 0 {
+		return Price{}, false
+	}
+	return b.Entries[best].Price, true
+}
+
+func (b *PriceBook) inRegion(region, country string) bool {
+	if country == "" {
+		return false
+	}
+	for _, c := range b.Regions[region] {
+		if strings.EqualFold(c, country) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package market
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceBook_Lookup(t *testing.T) {
+	book := &PriceBook{
+		Regions: map[string][]string{"EU": {"DE", "FR", "LT"}},
+		Entries: []PriceBookEntry{
+			{Price: *NewPrice(1, 1)},
+			{Country: "EU", Price: *NewPrice(2, 2)},
+			{Country: "DE", Price: *NewPrice(3, 3)},
+			{ServiceType: "wireguard", Price: *NewPrice(4, 4)},
+			{ServiceType: "wireguard", Country: "EU", Price: *NewPrice(5, 5)},
+		},
+	}
+
+	for _, tc := range []struct {
+		serviceType, country string
+		expected             *Price
+	}{
+		{"scraping", "US", NewPrice(1, 1)},
+		{"scraping", "fr", NewPrice(2, 2)},
+		{"scraping", "DE", NewPrice(3, 3)},
+		{"wireguard", "US", NewPrice(4, 4)},
+		{"wireguard", "LT", NewPrice(5, 5)},
+		{"wireguard", "DE", NewPrice(5, 5)},
+		{"scraping", "", NewPrice(1, 1)},
+	} {
+		price, ok := book.Lookup(tc.serviceType, tc.country)
+		assert.True(t, ok)
+		assert.Equal(t, *tc.expected, price, "%s from %s", tc.serviceType, tc.country)
+	}
+}
+
+func TestPriceBook_LookupNoMatch(t *testing.T) {
+	var nilBook *PriceBook
+	_, ok := nilBook.Lookup("wireguard", "DE")
+	assert.False(t, ok)
+
+	book := &PriceBook{Entries: []PriceBookEntry{{ServiceType: "scraping", Country: "DE", Price: *NewPrice(1, 1)}}}
+	_, ok = book.Lookup("wireguard", "DE")
+	assert.False(t, ok)
+	_, ok = book.Lookup("scraping", "FR")
+	assert.False(t, ok)
+}
+
+func TestPriceBook_ForService(t *testing.T) {
+	book := &PriceBook{Entries: []PriceBookEntry{
+		{ServiceType: "scraping", Price: *NewPrice(1, 1)},
+		{Country: "DE", Price: *NewPrice(2, 2)},
+	}}
+
+	assert.Len(t, book.ForService("scraping").Entries, 2)
+	assert.Len(t, book.ForService("wireguard").Entries, 1)
+	assert.Nil(t, (&PriceBook{Entries: book.Entries[:1]}).ForService("wireguard"))
+}
+
+func TestPriceBook_Validate(t *testing.T) {
+	assert.NoError(t, PriceBook{Entries: []PriceBookEntry{{Price: *NewPrice(0, 1)}}}.Validate())
+	assert.Error(t, PriceBook{Entries: []PriceBookEntry{{Price: Price{}}}}.Validate())
+	assert.Error(t, PriceBook{Entries: []PriceBookEntry{{Price: *NewPrice(-1, 1)}}}.Validate())
+	assert.Error(t, PriceBook{Regions: map[string][]string{"EU": nil}}.Validate())
+}
@@ -48,6 +48,9 @@ type ServiceProposal struct {
 	// Service location
 	Location Location `json:"location"`
 
+	// Price set by the provider overriding network prices, nil when not advertised.
+	Price *Price `json:"price,omitempty"`
+
 	// Communication methods possible
 	Contacts ContactList `json:"contacts"`
 
@@ -56,6 +59,15 @@ type ServiceProposal struct {
 
 	// Quality represents the service quality.
 	Quality Quality `json:"quality"`
+
+	// Load represents provider utilization, nil when not advertised.
+	Load *Load `json:"load,omitempty"`
+
+	// Capabilities lists optional transport features supported by the service, e.g. obfuscation methods.
+	Capabilities []string `json:"capabilities,omitempty"`
+
+	// PriceBook lists provider set prices overriding network prices, nil when not advertised.
+	PriceBook *PriceBook `json:"price_book,omitempty"`
 }
 
 // NewProposalOpts optional params for the new proposal creation.
@@ -120,9 +132,13 @@ func (proposal *ServiceProposal) UnmarshalJSON(data []byte) error {
 		ServiceType    string           `json:"service_type"`
 		Compatibility  int              `json:"compatibility"`
 		Location       Location         `json:"location"`
+		Price          *Price           `json:"price,omitempty"`
 		Contacts       *json.RawMessage `json:"contacts"`
 		AccessPolicies *[]AccessPolicy  `json:"access_policies,omitempty"`
 		Quality        Quality          `json:"quality"`
+		Load           *Load            `json:"load,omitempty"`
+		Capabilities   []string         `json:"capabilities,omitempty"`
+		PriceBook      *PriceBook       `json:"price_book,omitempty"`
 	}
 	if err := json.Unmarshal(data, &jsonData); err != nil {
 		return err
@@ -134,15 +150,29 @@ func (proposal *ServiceProposal) UnmarshalJSON(data []byte) error {
 	proposal.ServiceType = jsonData.ServiceType
 	proposal.Compatibility = jsonData.Compatibility
 	proposal.Location = jsonData.Location
+	proposal.Price = jsonData.Price
 
 	// run contact unserializer
 	proposal.Contacts = unserializeContacts(jsonData.Contacts)
 	proposal.AccessPolicies = jsonData.AccessPolicies
 	proposal.Quality = jsonData.Quality
+	proposal.Load = jsonData.Load
+	proposal.Capabilities = jsonData.Capabilities
+	proposal.PriceBook = jsonData.PriceBook
 
 	return nil
 }
 
+// HasCapability reports whether the service advertises given capability.
+func (proposal *ServiceProposal) HasCapability(capability string) bool {
+	for _, c := range proposal.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
 // IsSupported returns true if this service proposal can be used for connections by service consumer
 // can be used as a filter to filter out all proposals which are unsupported for any reason
 func (proposal *ServiceProposal) IsSupported() bool {
@@ -51,7 +51,7 @@ func Test_ServiceProposal_Serialize(t *testing.T) {
 	assert.Nil(t, err)
 
 	expectedJSON := `{
-      "compatibility": 2,
+      "compatibility": 3,
 	  "format": "service-proposal/v3",
 	  "service_type": "mock_service",
 	  "provider_id": "node",
@@ -100,6 +100,23 @@ func Test_ServiceProposal_Unserialize(t *testing.T) {
 	assert.True(t, actual.IsSupported())
 }
 
+func Test_ServiceProposal_UnserializeCapabilities(t *testing.T) {
+	jsonData := []byte(`{
+		"format": "service-proposal/v3",
+		"provider_id": "node",
+		"service_type": "mock_service",
+		"capabilities": ["obfs.xchacha"]
+	}`)
+
+	var actual ServiceProposal
+	err := json.Unmarshal(jsonData, &actual)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"obfs.xchacha"}, actual.Capabilities)
+	assert.True(t, actual.HasCapability("obfs.xchacha"))
+	assert.False(t, actual.HasCapability("obfs.other"))
+}
+
 func Test_ServiceProposal_UnserializeAccessPolicy(t *testing.T) {
 	RegisterServiceType("mock_service")
 	jsonData := []byte(`{
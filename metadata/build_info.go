@@ -28,6 +28,8 @@ var (
 	BuildBranch = "<unknown>"
 	// BuildNumber comes from BUILD_NUMBER env variable (set via linker flags)
 	BuildNumber = "dev-build"
+	// ReleasePublicKey comes from RELEASE_PUBLIC_KEY env variable (set via linker flags), it verifies signatures of node releases
+	ReleasePublicKey = ""
 )
 
 // BuildAsString returns all defined build constants as single string
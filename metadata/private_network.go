@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var identityAddressPattern = regexp.MustCompile("^0x[0-9a-fA-F]{40}$")
+
+// PrivateNetwork describes a private network deployment loaded from a network definition file.
+// Discovery, broker, hermes and oracle addresses are all taken from its definition,
+// addresses left out keep their defaults and only allowed identities may take part in the network.
+type PrivateNetwork struct {
+	// Name of the private network.
+	Name string `json:"name"`
+	// AllowedIdentities lists identities allowed in the network, empty allows any identity.
+	AllowedIdentities []string `json:"allowed_identities"`
+	// Definition holds network service addresses and chain parameters.
+	Definition NetworkDefinition `json:"definition"`
+	// QualityOracleAddress of the network quality oracle, empty opts out from sending quality metrics.
+	QualityOracleAddress string `json:"quality_oracle_address"`
+}
+
+// LoadPrivateNetwork reads and validates a JSON network definition file.
+func LoadPrivateNetwork(path string) (*PrivateNetwork, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read network definition: %w", err)
+	}
+
+	var network PrivateNetwork
+	if err := json.Unmarshal(data, &network); err != nil {
+		return nil, fmt.Errorf("could not parse network definition %s: %w", path, err)
+	}
+	if err := network.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid network definition %s: %w", path, err)
+	}
+
+	for i, address := range network.AllowedIdentities {
+		network.AllowedIdentities[i] = strings.ToLower(address)
+	}
+	if network.Definition.MysteriumAPIAddress == "" {
+		network.Definition.MysteriumAPIAddress = network.Definition.DiscoveryAddress
+	}
+
+	return &network, nil
+}
+
+// Validate checks whether the private network is complete enough to run a node in it.
+func (p *PrivateNetwork) Validate() error {
+	if p.Name == "" {
+		return errors.New("name is required")
+	}
+	if p.Definition.DiscoveryAddress == "" {
+		return errors.New("discovery address is required")
+	}
+	if len(p.Definition.BrokerAddresses) == 0 {
+		return errors.New("at least one broker address is required")
+	}
+	if p.Definition.DefaultChainID == 0 {
+		return errors.New("default chain ID is required")
+	}
+	for _, address := range p.AllowedIdentities {
+		if !identityAddressPattern.MatchString(address) {
+			return fmt.Errorf("allowed identity %q is not an identity address", address)
+		}
+	}
+	return nil
+}
+
+// IsIdentityAllowed tells whether the given identity address may take part in the network.
+func (p *PrivateNetwork) IsIdentityAllowed(address string) bool {
+	if len(p.AllowedIdentities) == 0 {
+		return true
+	}
+
+	address = strings.ToLower(address)
+	for _, allowed := range p.AllowedIdentities {
+		if allowed == address {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package metadata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPrivateNetwork(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "network.json")
+	err := os.WriteFile(path, []byte(`{
+		"name": "corp",
+		"allowed_identities": ["0x000000000000000000000000000000000000000A"],
+		"quality_oracle_address": "https://quality.corp.example/api/v3",
+		"definition": {
+			"DiscoveryAddress": "https://discovery.corp.example/api/v4",
+			"BrokerAddresses": ["nats://broker.corp.example"],
+			"AccessPolicyOracleAddress": "https://trust.corp.example/api/v1/access-policies/",
+			"DefaultChainID": 80001,
+			"Chain2": {"ChainID": 80001, "HermesID": "0x000000000000000000000000000000000000000f"}
+		}
+	}`), 0600)
+	require.NoError(t, err)
+
+	network, err := LoadPrivateNetwork(path)
+	require.NoError(t, err)
+	assert.Equal(t, "corp", network.Name)
+	assert.Equal(t, "https://quality.corp.example/api/v3", network.QualityOracleAddress)
+	assert.Equal(t, "https://discovery.corp.example/api/v4", network.Definition.MysteriumAPIAddress)
+	assert.True(t, network.IsIdentityAllowed("0x000000000000000000000000000000000000000a"))
+	assert.False(t, network.IsIdentityAllowed("0x000000000000000000000000000000000000000b"))
+
+	flags := network.Definition.GetDefaultFlagValues()
+	assert.Equal(t, []string{"nats://broker.corp.example"}, flags[FlagNames.BrokerAddressesFlag])
+	assert.Equal(t, "0x000000000000000000000000000000000000000f", flags[FlagNames.Chain2Flag.HermesID])
+}
+
+func TestPrivateNetwork_Validate(t *testing.T) {
+	valid := PrivateNetwork{
+		Name: "corp",
+		Definition: NetworkDefinition{
+			DiscoveryAddress: "https://discovery.corp.example/api/v4",
+			BrokerAddresses:  []string{"nats://broker.corp.example"},
+			DefaultChainID:   80001,
+		},
+	}
+	assert.NoError(t, valid.Validate())
+	assert.True(t, valid.IsIdentityAllowed("0x000000000000000000000000000000000000000b"))
+
+	noBroker := valid
+	noBroker.Definition.BrokerAddresses = nil
+	assert.Error(t, noBroker.Validate())
+
+	badIdentity := valid
+	badIdentity.AllowedIdentities = []string{"corp-user"}
+	assert.Error(t, badIdentity.Validate())
+}
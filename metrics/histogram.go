@@ -0,0 +1,214 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package metrics
+
+import (
+	"math"
+	"math/bits"
+	"sync"
+)
+
+// SnapshotQuantiles are the quantiles reported by histogram snapshots.
+var SnapshotQuantiles = []float64{0.5, 0.9, 0.95, 0.99, 0.999}
+
+// Histogram is a High Dynamic Range histogram of non-negative integer values.
+// It keeps the relative error of recorded values within the configured number
+// of significant figures using a fixed amount of memory, so it can be updated
+// on hot paths without keeping the samples.
+type Histogram struct {
+	name, help string
+
+	highestValue                int64
+	subBucketHalfCountMagnitude uint
+	subBucketHalfCount          int
+	subBucketMask               int64
+
+	mu     sync.Mutex
+	counts []uint64
+	count  uint64
+	sum    int64
+	min    int64
+	max    int64
+}
+
+func newHistogram(name, help string, highestValue int64, significantFigures int) *Histogram {
+	if significantFigures < 1 {
+		significantFigures = 1
+	} else if significantFigures > 5 {
+		significantFigures = 5
+	}
+	if highestValue < 2 {
+		highestValue = 2
+	}
+
+	largestValueWithSingleUnitResolution := 2 * math.Pow10(significantFigures)
+	subBucketCountMagnitude := uint(math.Ceil(math.Log2(largestValueWithSingleUnitResolution)))
+	subBucketHalfCountMagnitude := subBucketCountMagnitude - 1
+	subBucketCount := 1 << subBucketCountMagnitude
+
+	bucketCount := 1
+	for smallestUntrackableValue := int64(subBucketCount); smallestUntrackableValue <= highestValue; smallestUntrackableValue <<= 1 {
+		bucketCount++
+		if smallestUntrackableValue > math.MaxInt64/2 {
+			break
+		}
+	}
+
+	return &Histogram{
+		name:                        name,
+		help:                        help,
+		highestValue:                highestValue,
+		subBucketHalfCountMagnitude: subBucketHalfCountMagnitude,
+		subBucketHalfCount:          subBucketCount / 2,
+		subBucketMask:               int64(subBucketCount - 1),
+		counts:                      make([]uint64, (bucketCount+1)*(subBucketCount/2)),
+	}
+}
+
+// Record adds the value to the histogram. Negative values are recorded as zero,
+// values above the highest trackable value are recorded as the highest value.
+func (h *Histogram) Record(v int64) {
+	if v < 0 {
+		v = 0
+	} else if v > h.highestValue {
+		v = h.highestValue
+	}
+	idx := h.countsIndex(v)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.count == 0 || v < h.min {
+		h.min = v
+	}
+	if v > h.max {
+		h.max = v
+	}
+	h.counts[idx]++
+	h.count++
+	h.sum += v
+}
+
+// Count returns the number of recorded values.
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.count
+}
+
+// ValueAtQuantile returns the value below which the given share (0..1) of recorded values fall.
+func (h *Histogram) ValueAtQuantile(q float64) int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.valueAtQuantile(q)
+}
+
+// Snapshot returns the histogram state at a point in time.
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := HistogramSnapshot{
+		Name:      h.name,
+		Help:      h.help,
+		Count:     h.count,
+		Sum:       h.sum,
+		Min:       h.min,
+		Max:       h.max,
+		Quantiles: make([]QuantileSnapshot, 0, len(SnapshotQuantiles)),
+	}
+	if h.count > 0 {
+		s.Mean = float64(h.sum) / float64(h.count)
+	}
+	for _, q := range SnapshotQuantiles {
+		s.Quantiles = append(s.Quantiles, QuantileSnapshot{Quantile: q, Value: h.valueAtQuantile(q)})
+	}
+	return s
+}
+
+// Reset clears all recorded values.
+func (h *Histogram) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i := range h.counts {
+		h.counts[i] = 0
+	}
+	h.count, h.sum, h.min, h.max = 0, 0, 0, 0
+}
+
+func (h *Histogram) valueAtQuantile(q float64) int64 {
+	if h.count == 0 {
+		return 0
+	}
+	if q > 1 {
+		q = 1
+	}
+	rank := uint64(math.Ceil(q * float64(h.count)))
+	if rank == 0 {
+		rank = 1
+	}
+
+	var total uint64
+	for i, c := range h.counts {
+		total += c
+		if total >= rank {
+			v := h.highestEquivalentValue(i)
+			if v > h.max {
+				return h.max
+			}
+			return v
+		}
+	}
+	return h.max
+}
+
+func (h *Histogram) countsIndex(v int64) int {
+	bucketIdx := bits.Len64(uint64(v|h.subBucketMask)) - int(h.subBucketHalfCountMagnitude) - 1
+	subBucketIdx := int(v >> uint(bucketIdx))
+	return (bucketIdx+1)<<h.subBucketHalfCountMagnitude + subBucketIdx - h.subBucketHalfCount
+}
+
+func (h *Histogram) highestEquivalentValue(idx int) int64 {
+	bucketIdx := idx>>h.subBucketHalfCountMagnitude - 1
+	subBucketIdx := idx&(h.subBucketHalfCount-1) + h.subBucketHalfCount
+	if bucketIdx < 0 {
+		subBucketIdx -= h.subBucketHalfCount
+		bucketIdx = 0
+	}
+	lowest := int64(subBucketIdx) << uint(bucketIdx)
+	return lowest + int64(1)<<uint(bucketIdx) - 1
+}
+
+// HistogramSnapshot is a histogram state at a point in time.
+type HistogramSnapshot struct {
+	Name, Help string
+	Count      uint64
+	Sum        int64
+	Min, Max   int64
+	Mean       float64
+	Quantiles  []QuantileSnapshot
+}
+
+// QuantileSnapshot is a value at the given quantile.
+type QuantileSnapshot struct {
+	Quantile float64
+	Value    int64
+}
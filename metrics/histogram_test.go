@@ -0,0 +1,73 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram_ValueAtQuantile(t *testing.T) {
+	h := newHistogram("test", "", 3600000, 3)
+	for v := int64(1); v <= 10000; v++ {
+		h.Record(v)
+	}
+
+	assert.Equal(t, uint64(10000), h.Count())
+	assert.InEpsilon(t, 5000, h.ValueAtQuantile(0.5), 0.001)
+	assert.InEpsilon(t, 9900, h.ValueAtQuantile(0.99), 0.001)
+	assert.Equal(t, int64(10000), h.ValueAtQuantile(1))
+	assert.Equal(t, int64(1), h.ValueAtQuantile(0))
+}
+
+func TestHistogram_KeepsRelativeError(t *testing.T) {
+	h := newHistogram("test", "", 1<<40, 2)
+	for _, v := range []int64{0, 1, 99, 1000, 123456, 987654321, 1 << 39} {
+		h.Reset()
+		h.Record(v)
+
+		got := h.ValueAtQuantile(0.5)
+		assert.GreaterOrEqual(t, got, v)
+		assert.LessOrEqual(t, float64(got-v), float64(v)/100)
+	}
+}
+
+func TestHistogram_ClampsValues(t *testing.T) {
+	h := newHistogram("test", "", 1000, 2)
+	h.Record(-5)
+	h.Record(5000)
+
+	s := h.Snapshot()
+	assert.Equal(t, uint64(2), s.Count)
+	assert.Equal(t, int64(0), s.Min)
+	assert.Equal(t, int64(1000), s.Max)
+	assert.Equal(t, int64(1000), s.Sum)
+	assert.Equal(t, 500.0, s.Mean)
+}
+
+func TestHistogram_SnapshotEmpty(t *testing.T) {
+	s := newHistogram("latency_ms", "help", 1000, 2).Snapshot()
+
+	assert.Equal(t, "latency_ms", s.Name)
+	assert.Equal(t, uint64(0), s.Count)
+	assert.Len(t, s.Quantiles, len(SnapshotQuantiles))
+	for _, q := range s.Quantiles {
+		assert.Equal(t, int64(0), q.Value)
+	}
+}
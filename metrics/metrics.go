@@ -0,0 +1,195 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package metrics provides in-memory counters, gauges and HDR histograms
+// shared by node components and exported through tequilapi.
+package metrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultRegistry is the registry used by the package level constructors.
+var DefaultRegistry = NewRegistry()
+
+// NewCounter returns counter registered in the DefaultRegistry.
+func NewCounter(name, help string) *Counter {
+	return DefaultRegistry.Counter(name, help)
+}
+
+// NewGauge returns gauge registered in the DefaultRegistry.
+func NewGauge(name, help string) *Gauge {
+	return DefaultRegistry.Gauge(name, help)
+}
+
+// NewHistogram returns histogram registered in the DefaultRegistry.
+func NewHistogram(name, help string, highestValue int64, significantFigures int) *Histogram {
+	return DefaultRegistry.Histogram(name, help, highestValue, significantFigures)
+}
+
+// Registry keeps named metrics.
+type Registry struct {
+	mu         sync.Mutex
+	counters   map[string]*Counter
+	gauges     map[string]*Gauge
+	histograms map[string]*Histogram
+}
+
+// NewRegistry creates an empty metrics registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		counters:   make(map[string]*Counter),
+		gauges:     make(map[string]*Gauge),
+		histograms: make(map[string]*Histogram),
+	}
+}
+
+// Counter returns counter with the given name, registering it on first use.
+func (r *Registry) Counter(name, help string) *Counter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.counters[name]
+	if !ok {
+		c = &Counter{name: name, help: help}
+		r.counters[name] = c
+	}
+	return c
+}
+
+// Gauge returns gauge with the given name, registering it on first use.
+func (r *Registry) Gauge(name, help string) *Gauge {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	g, ok := r.gauges[name]
+	if !ok {
+		g = &Gauge{name: name, help: help}
+		r.gauges[name] = g
+	}
+	return g
+}
+
+// Histogram returns histogram with the given name, registering it on first use.
+// Values up to highestValue are tracked with the given number of significant figures (1..5).
+func (r *Registry) Histogram(name, help string, highestValue int64, significantFigures int) *Histogram {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	h, ok := r.histograms[name]
+	if !ok {
+		h = newHistogram(name, help, highestValue, significantFigures)
+		r.histograms[name] = h
+	}
+	return h
+}
+
+// Snapshot returns current values of all registered metrics sorted by name.
+func (r *Registry) Snapshot() Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := Snapshot{
+		Counters:   make([]CounterSnapshot, 0, len(r.counters)),
+		Gauges:     make([]GaugeSnapshot, 0, len(r.gauges)),
+		Histograms: make([]HistogramSnapshot, 0, len(r.histograms)),
+	}
+	for _, c := range r.counters {
+		s.Counters = append(s.Counters, CounterSnapshot{Name: c.name, Help: c.help, Value: c.Value()})
+	}
+	for _, g := range r.gauges {
+		s.Gauges = append(s.Gauges, GaugeSnapshot{Name: g.name, Help: g.help, Value: g.Value()})
+	}
+	for _, h := range r.histograms {
+		s.Histograms = append(s.Histograms, h.Snapshot())
+	}
+
+	sort.Slice(s.Counters, func(i, j int) bool { return s.Counters[i].Name < s.Counters[j].Name })
+	sort.Slice(s.Gauges, func(i, j int) bool { return s.Gauges[i].Name < s.Gauges[j].Name })
+	sort.Slice(s.Histograms, func(i, j int) bool { return s.Histograms[i].Name < s.Histograms[j].Name })
+	return s
+}
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	name, help string
+	value      uint64
+}
+
+// Inc increments the counter by one.
+func (c *Counter) Inc() {
+	atomic.AddUint64(&c.value, 1)
+}
+
+// Add increments the counter by the given delta.
+func (c *Counter) Add(delta uint64) {
+	atomic.AddUint64(&c.value, delta)
+}
+
+// Value returns current counter value.
+func (c *Counter) Value() uint64 {
+	return atomic.LoadUint64(&c.value)
+}
+
+// Gauge is a value which can go up and down.
+type Gauge struct {
+	name, help string
+	bits       uint64
+}
+
+// Set sets the gauge to the given value.
+func (g *Gauge) Set(v float64) {
+	atomic.StoreUint64(&g.bits, math.Float64bits(v))
+}
+
+// Add adds the given delta to the gauge.
+func (g *Gauge) Add(delta float64) {
+	for {
+		old := atomic.LoadUint64(&g.bits)
+		updated := math.Float64bits(math.Float64frombits(old) + delta)
+		if atomic.CompareAndSwapUint64(&g.bits, old, updated) {
+			return
+		}
+	}
+}
+
+// Value returns current gauge value.
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&g.bits))
+}
+
+// Snapshot holds values of registered metrics at a point in time.
+type Snapshot struct {
+	Counters   []CounterSnapshot
+	Gauges     []GaugeSnapshot
+	Histograms []HistogramSnapshot
+}
+
+// CounterSnapshot is a counter value at a point in time.
+type CounterSnapshot struct {
+	Name, Help string
+	Value      uint64
+}
+
+// GaugeSnapshot is a gauge value at a point in time.
+type GaugeSnapshot struct {
+	Name, Help string
+	Value      float64
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_ReturnsRegisteredMetrics(t *testing.T) {
+	r := NewRegistry()
+
+	r.Counter("requests_total", "").Inc()
+	r.Counter("requests_total", "").Add(2)
+	r.Gauge("sessions", "").Set(3)
+	r.Gauge("sessions", "").Add(-1.5)
+
+	assert.Equal(t, uint64(3), r.Counter("requests_total", "").Value())
+	assert.Equal(t, 1.5, r.Gauge("sessions", "").Value())
+	assert.Same(t, r.Histogram("dial_ms", "", 1000, 2), r.Histogram("dial_ms", "", 1000, 2))
+}
+
+func TestRegistry_Snapshot(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("b_total", "B").Inc()
+	r.Counter("a_total", "A").Add(5)
+	r.Gauge("sessions", "Active sessions").Set(2)
+	r.Histogram("dial_ms", "Dial duration", 1000, 2).Record(10)
+
+	s := r.Snapshot()
+
+	assert.Equal(t, []CounterSnapshot{
+		{Name: "a_total", Help: "A", Value: 5},
+		{Name: "b_total", Help: "B", Value: 1},
+	}, s.Counters)
+	assert.Equal(t, []GaugeSnapshot{{Name: "sessions", Help: "Active sessions", Value: 2}}, s.Gauges)
+	assert.Len(t, s.Histograms, 1)
+	assert.Equal(t, uint64(1), s.Histograms[0].Count)
+}
+
+func TestWritePrometheus(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("invoices_total", "Sent invoices").Add(7)
+	r.Gauge("sessions", "Active\nsessions").Set(1.5)
+	h := r.Histogram("dial_ms", "", 1000, 2)
+	h.Record(10)
+	h.Record(20)
+
+	var buf bytes.Buffer
+	err := WritePrometheus(&buf, r.Snapshot())
+
+	assert.NoError(t, err)
+	assert.Equal(t, `# HELP invoices_total Sent invoices
+# TYPE invoices_total counter
+invoices_total 7
+# HELP sessions Active\nsessions
+# TYPE sessions gauge
+sessions 1.5
+# TYPE dial_ms summary
+dial_ms{quantile="0.5"} 10
+dial_ms{quantile="0.9"} 20
+dial_ms{quantile="0.95"} 20
+dial_ms{quantile="0.99"} 20
+dial_ms{quantile="0.999"} 20
+dial_ms_sum 30
+dial_ms_count 2
+`, buf.String())
+}
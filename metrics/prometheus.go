@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// PrometheusContentType is the content type of the Prometheus text exposition format.
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WritePrometheus writes the snapshot in the Prometheus text exposition format.
+// Histograms are exported as summaries with precomputed quantiles.
+func WritePrometheus(w io.Writer, s Snapshot) error {
+	bw := bufio.NewWriter(w)
+
+	for _, c := range s.Counters {
+		writeHeader(bw, c.Name, c.Help, "counter")
+		fmt.Fprintf(bw, "%s %d\n", c.Name, c.Value)
+	}
+	for _, g := range s.Gauges {
+		writeHeader(bw, g.Name, g.Help, "gauge")
+		fmt.Fprintf(bw, "%s %s\n", g.Name, strconv.FormatFloat(g.Value, 'g', -1, 64))
+	}
+	for _, h := range s.Histograms {
+		writeHeader(bw, h.Name, h.Help, "summary")
+		for _, q := range h.Quantiles {
+			fmt.Fprintf(bw, "%s{quantile=\"%s\"} %d\n", h.Name, strconv.FormatFloat(q.Quantile, 'g', -1, 64), q.Value)
+		}
+		fmt.Fprintf(bw, "%s_sum %d\n", h.Name, h.Sum)
+		fmt.Fprintf(bw, "%s_count %d\n", h.Name, h.Count)
+	}
+
+	return bw.Flush()
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func writeHeader(w io.Writer, name, help, kind string) {
+	if help != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", name, helpEscaper.Replace(help))
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
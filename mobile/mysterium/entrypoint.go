@@ -82,7 +82,7 @@ type MobileNode struct {
 	identityRegistry          registry.IdentityRegistry
 	identityChannelCalculator *paymentClient.MultiChainAddressProvider
 	consumerBalanceTracker    *pingpong.ConsumerBalanceTracker
-	pilvytis                  *pilvytis.API
+	pilvytis                  *pilvytis.Gateways
 	pilvytisOrderIssuer       *pilvytis.OrderIssuer
 	chainID                   int64
 	startTime                 time.Time
@@ -118,6 +118,8 @@ type MobileNodeOptions struct {
 	ChannelImplementationSCAddress string
 	CacheTTLSeconds                int
 	ObserverAddress                string
+	// ReferralToken is attached to identity registration and top-ups so that distribution partners can track signups.
+	ReferralToken string
 }
 
 // ConsumerPaymentConfig defines consumer side payment configuration
@@ -170,6 +172,7 @@ func NewNode(appPath string, options *MobileNodeOptions) (*MobileNode, error) {
 
 	config.Current.SetDefault(config.FlagChainID.Name, options.ActiveChainID)
 	config.Current.SetDefault(config.FlagKeepConnectedOnFail.Name, options.KeepConnectedOnFail)
+	config.Current.SetDefault(config.FlagReferralToken.Name, options.ReferralToken)
 	config.Current.SetDefault(config.FlagAutoReconnect.Name, "true")
 	config.Current.SetDefault(config.FlagDefaultCurrency.Name, metadata.DefaultNetwork.DefaultCurrency)
 	config.Current.SetDefault(config.FlagSTUNservers.Name, []string{"stun.l.google.com:19302", "stun1.l.google.com:19302", "stun2.l.google.com:19302"})
@@ -195,9 +198,11 @@ func NewNode(appPath string, options *MobileNodeOptions) (*MobileNode, error) {
 		},
 	}
 	logOptions := logconfig.LogOptions{
-		LogLevel: zerolog.DebugLevel,
-		LogHTTP:  false,
-		Filepath: filepath.Join(dataDir, "mysterium-node"),
+		LogLevel:      zerolog.DebugLevel,
+		LogHTTP:       false,
+		Filepath:      filepath.Join(dataDir, "mysterium-node"),
+		DedupInterval: 10 * time.Second,
+		DedupLevel:    zerolog.WarnLevel,
 	}
 
 	nodeOptions := node.Options{
@@ -255,6 +260,7 @@ func NewNode(appPath string, options *MobileNodeOptions) (*MobileNode, error) {
 			BalanceLongPollInterval:        time.Hour * 1,
 			RegistryTransactorPollInterval: time.Second * 20,
 			RegistryTransactorPollTimeout:  time.Minute * 20,
+			RegistryStatusCacheTTL:         time.Minute * 10,
 		},
 		Chains: node.OptionsChains{
 			Chain1: metadata.ChainDefinition{
@@ -306,7 +312,7 @@ func NewNode(appPath string, options *MobileNodeOptions) (*MobileNode, error) {
 			di.NATProber,
 			time.Duration(options.CacheTTLSeconds)*time.Second,
 		),
-		pilvytis:            di.PilvytisAPI,
+		pilvytis:            di.PaymentGateways,
 		pilvytisOrderIssuer: di.PilvytisOrderIssuer,
 		startTime:           time.Now(),
 		chainID:             nodeOptions.OptionsNetwork.ChainID,
@@ -456,6 +462,10 @@ func (mb *MobileNode) RegisterBalanceChangeCallback(cb BalanceChangeCallback) {
  *	- "auto" (default) tries the following with fallbacks: provider's DNS -> client's system DNS -> public DNS
  *  - "provider" uses DNS servers from provider's system configuration
  *  - "system" uses DNS servers from client's system configuration
+ *
+ * AllowedApps and DisallowedApps enable per-app tunneling, only one of them can be set:
+ *  - AllowedApps routes only the listed applications through the tunnel
+ *  - DisallowedApps routes the listed applications outside the tunnel
  */
 type ConnectRequest struct {
 	Providers               string // comma separated list of providers that will be used for the connection.
@@ -466,6 +476,8 @@ type ConnectRequest struct {
 	SortBy                  string
 	DNSOption               string
 	IncludeMonitoringFailed bool
+	AllowedApps             string // comma separated list of application package names routed through the tunnel.
+	DisallowedApps          string // comma separated list of application package names routed outside the tunnel.
 }
 
 func (cr *ConnectRequest) dnsOption() (connection.DNSOption, error) {
@@ -476,6 +488,24 @@ func (cr *ConnectRequest) dnsOption() (connection.DNSOption, error) {
 	return connection.DNSOptionAuto, nil
 }
 
+func (cr *ConnectRequest) appFilter() (connection.AppFilter, error) {
+	apps := connection.AppFilter{
+		Allowed:    splitPackageNames(cr.AllowedApps),
+		Disallowed: splitPackageNames(cr.DisallowedApps),
+	}
+	return apps, apps.Validate()
+}
+
+func splitPackageNames(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 // ConnectResponse represents connect response with optional error code and message.
 type ConnectResponse struct {
 	ErrorCode    string
@@ -522,8 +552,16 @@ func (mb *MobileNode) Connect(req *ConnectRequest) *ConnectResponse {
 			ErrorMessage: err.Error(),
 		}
 	}
+	appFilter, err := req.appFilter()
+	if err != nil {
+		return &ConnectResponse{
+			ErrorCode:    connectErrUnknown,
+			ErrorMessage: err.Error(),
+		}
+	}
 	connectOptions := connection.ConnectParams{
-		DNS: dnsOption,
+		DNS:  dnsOption,
+		Apps: appFilter,
 	}
 
 	hermes, err := mb.identityChannelCalculator.GetActiveHermes(mb.chainID)
@@ -654,6 +692,27 @@ func (mb *MobileNode) OverrideWireguardConnection(wgTunnelSetup WireguardTunnelS
 	router.SetProtectFunc(wgTunnelSetup.Protect)
 }
 
+// OverrideWireguardPacketConnection overrides default wireguard connection implementation with one exchanging
+// IP packets through the packet tunnel setup, for platforms not exposing the TUN device such as iOS.
+func (mb *MobileNode) OverrideWireguardPacketConnection(packetTunnelSetup PacketTunnelSetup) {
+	wireguard.Bootstrap()
+
+	factory := func() (connection.Connection, error) {
+		opts := wireGuardOptions{
+			statsUpdateInterval: 1 * time.Second,
+			handshakeTimeout:    1 * time.Minute,
+		}
+
+		return NewWireGuardConnection(
+			opts,
+			newPacketDevice(packetTunnelSetup),
+			mb.ipResolver,
+			wireguard_connection.NewHandshakeWaiter(),
+		)
+	}
+	mb.connectionRegistry.Register(wireguard.ServiceType, factory)
+}
+
 // HealthCheckData represents node health check info.
 type HealthCheckData struct {
 	Uptime    string     `json:"uptime"`
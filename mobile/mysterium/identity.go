@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/payments/crypto"
@@ -101,8 +102,8 @@ func (mb *MobileNode) RegisterIdentity(req *RegisterIdentityRequest) error {
 	}
 
 	var token *string
-	if req.Token != "" {
-		token = &req.Token
+	if t := config.ReferralToken(req.Token); t != "" {
+		token = &t
 	}
 
 	err = mb.transactor.RegisterIdentity(req.IdentityAddress, big.NewInt(0), fees.Fee, "", mb.chainID, token)
@@ -0,0 +1,202 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package mysterium
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"golang.zx2c4.com/wireguard/conn"
+	"golang.zx2c4.com/wireguard/device"
+	"golang.zx2c4.com/wireguard/tun"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
+)
+
+// PacketTunnelSetup exposes api for caller to implement tunnel setup on platforms which
+// don't expose the TUN device, e.g. iOS NEPacketTunnelProvider. Instead of a file descriptor
+// IP packets are exchanged with the system through the PacketBridge.
+type PacketTunnelSetup interface {
+	NewTunnel()
+	AddTunnelAddress(ip string, prefixLen int)
+	AddRoute(route string, prefixLen int)
+	AddDNS(ip string)
+	SetMTU(mtu int)
+	// Establish applies tunnel settings and starts reading packets from the system,
+	// every packet read should be passed to bridge.WritePacket.
+	Establish(bridge *PacketBridge) error
+	// WritePacket delivers IP packet received from the tunnel to the system.
+	WritePacket(packet []byte) error
+}
+
+// PacketBridge passes IP packets read from the system into the tunnel.
+type PacketBridge struct {
+	tun *packetTun
+}
+
+// WritePacket passes IP packet read from the system into the tunnel.
+// It blocks until the tunnel accepts the packet.
+func (b *PacketBridge) WritePacket(packet []byte) error {
+	return b.tun.inject(packet)
+}
+
+// packetTun is a TUN device exchanging packets with PacketTunnelSetup.
+type packetTun struct {
+	setup   PacketTunnelSetup
+	mtu     int
+	packets chan []byte
+	events  chan tun.Event
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+var _ tun.Device = &packetTun{}
+
+func newPacketTun(setup PacketTunnelSetup, mtu int) *packetTun {
+	events := make(chan tun.Event, 1)
+	events <- tun.EventUp
+
+	return &packetTun{
+		setup:   setup,
+		mtu:     mtu,
+		packets: make(chan []byte),
+		events:  events,
+		closed:  make(chan struct{}),
+	}
+}
+
+func (t *packetTun) inject(packet []byte) error {
+	// Caller owned memory is valid only for the duration of the call.
+	packet = append([]byte(nil), packet...)
+
+	select {
+	case t.packets <- packet:
+		return nil
+	case <-t.closed:
+		return os.ErrClosed
+	}
+}
+
+func (t *packetTun) File() *os.File {
+	return nil
+}
+
+func (t *packetTun) Read(buf []byte, offset int) (int, error) {
+	select {
+	case packet := <-t.packets:
+		return copy(buf[offset:], packet), nil
+	case <-t.closed:
+		return 0, os.ErrClosed
+	}
+}
+
+func (t *packetTun) Write(buf []byte, offset int) (int, error) {
+	select {
+	case <-t.closed:
+		return 0, os.ErrClosed
+	default:
+	}
+
+	packet := append([]byte(nil), buf[offset:]...)
+	if err := t.setup.WritePacket(packet); err != nil {
+		return 0, err
+	}
+	return len(packet), nil
+}
+
+func (t *packetTun) Flush() error {
+	return nil
+}
+
+func (t *packetTun) MTU() (int, error) {
+	return t.mtu, nil
+}
+
+func (t *packetTun) Name() (string, error) {
+	return "packet-bridge", nil
+}
+
+func (t *packetTun) Events() chan tun.Event {
+	return t.events
+}
+
+func (t *packetTun) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.closed)
+		close(t.events)
+	})
+	return nil
+}
+
+func newPacketDevice(tunnelSetup PacketTunnelSetup) wireguardDevice {
+	return &packetDevice{tunnelSetup: tunnelSetup}
+}
+
+// packetDevice runs userspace wireguard on top of the packet bridge.
+type packetDevice struct {
+	tunnelSetup PacketTunnelSetup
+
+	device *device.Device
+}
+
+func (p *packetDevice) Start(privateKey string, config wireguard.ServiceConfig, _ *net.UDPConn, dns connection.DNSOption, apps connection.AppFilter) error {
+	if len(apps.Allowed) > 0 || len(apps.Disallowed) > 0 {
+		log.Warn().Msg("Per-app tunneling is not supported by packet tunnel, ignoring application filter")
+	}
+
+	log.Debug().Msg("Creating packet tunnel")
+	p.tunnelSetup.NewTunnel()
+	if err := setupTunnelSettings(p.tunnelSetup, config, dns); err != nil {
+		return fmt.Errorf("could not setup packet tunnel: %w", err)
+	}
+
+	tunDevice := newPacketTun(p.tunnelSetup, tunnelMTU)
+	if err := p.tunnelSetup.Establish(&PacketBridge{tun: tunDevice}); err != nil {
+		tunDevice.Close()
+		return fmt.Errorf("could not establish packet tunnel: %w", err)
+	}
+
+	oldDevice := p.device
+	defer func() {
+		if oldDevice != nil {
+			oldDevice.Close()
+		}
+	}()
+
+	// Sockets opened by the packet tunnel provider bypass the tunnel, so unlike on Android there is nothing to protect.
+	p.device = device.NewDevice(tunDevice, conn.NewStdNetBind(), device.NewLogger(device.LogLevelVerbose, "[userspace-wg]"))
+	if err := applyDeviceConfig(p.device, privateKey, config); err != nil {
+		return fmt.Errorf("could not setup device configuration: %w", err)
+	}
+	return p.device.Up()
+}
+
+func (p *packetDevice) Stop() {
+	if p.device != nil {
+		p.device.Close()
+	}
+}
+
+func (p *packetDevice) Stats() (wgcfg.Stats, error) {
+	return deviceStats(p.device)
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package mysterium
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.zx2c4.com/wireguard/tun"
+)
+
+func TestPacketTunExchangesPackets(t *testing.T) {
+	setup := &mockPacketTunnelSetup{}
+	device := newPacketTun(setup, 1280)
+	bridge := &PacketBridge{tun: device}
+
+	assert.EqualValues(t, tun.EventUp, <-device.Events())
+
+	packet := []byte{0x45, 0x00, 0x01}
+	go func() {
+		assert.NoError(t, bridge.WritePacket(packet))
+	}()
+	buf := make([]byte, 16)
+	n, err := device.Read(buf, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, packet, buf[4:4+n])
+
+	n, err = device.Write([]byte{0, 0, 0x60, 0x01}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, [][]byte{{0x60, 0x01}}, setup.written)
+}
+
+func TestPacketTunClose(t *testing.T) {
+	device := newPacketTun(&mockPacketTunnelSetup{}, 1280)
+	bridge := &PacketBridge{tun: device}
+
+	assert.NoError(t, device.Close())
+	assert.NoError(t, device.Close())
+
+	_, err := device.Read(make([]byte, 16), 0)
+	assert.Equal(t, os.ErrClosed, err)
+	_, err = device.Write([]byte{0x45}, 0)
+	assert.Equal(t, os.ErrClosed, err)
+	assert.Equal(t, os.ErrClosed, bridge.WritePacket([]byte{0x45}))
+}
+
+type mockPacketTunnelSetup struct {
+	written [][]byte
+}
+
+func (m *mockPacketTunnelSetup) NewTunnel()                                {}
+func (m *mockPacketTunnelSetup) AddTunnelAddress(ip string, prefixLen int) {}
+func (m *mockPacketTunnelSetup) AddRoute(route string, prefixLen int)      {}
+func (m *mockPacketTunnelSetup) AddDNS(ip string)                          {}
+func (m *mockPacketTunnelSetup) SetMTU(mtu int)                            {}
+func (m *mockPacketTunnelSetup) Establish(bridge *PacketBridge) error      { return nil }
+
+func (m *mockPacketTunnelSetup) WritePacket(packet []byte) error {
+	m.written = append(m.written, packet)
+	return nil
+}
@@ -141,6 +141,8 @@ type CreatePaymentGatewayOrderReq struct {
 	PayCurrency     string
 	Country         string
 	State           string
+	// ReferralToken attributes the top-up to a distribution partner, MobileNodeOptions.ReferralToken is used if empty.
+	ReferralToken string
 	// GatewayCallerData is marshaled json that is accepting by the payment gateway.
 	GatewayCallerData []byte
 }
@@ -154,13 +156,14 @@ func (mb *MobileNode) CreatePaymentGatewayOrder(req *CreatePaymentGatewayOrderRe
 
 	order, err := mb.pilvytisOrderIssuer.CreatePaymentGatewayOrder(
 		pilvytis.GatewayOrderRequest{Identity: identity.FromAddress(req.IdentityAddress),
-			Gateway:     req.Gateway,
-			MystAmount:  req.MystAmount,
-			AmountUSD:   req.AmountUSD,
-			PayCurrency: req.PayCurrency,
-			Country:     req.Country,
-			State:       req.State,
-			CallerData:  req.GatewayCallerData,
+			Gateway:       req.Gateway,
+			MystAmount:    req.MystAmount,
+			AmountUSD:     req.AmountUSD,
+			PayCurrency:   req.PayCurrency,
+			Country:       req.Country,
+			State:         req.State,
+			ReferralToken: req.ReferralToken,
+			CallerData:    req.GatewayCallerData,
 		},
 	)
 	if err != nil {
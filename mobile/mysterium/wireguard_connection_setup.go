@@ -44,8 +44,8 @@ import (
 )
 
 const (
-	// Taken from android-wireguard project
-	androidTunMtu = 1280
+	// Taken from android-wireguard project, wireguard-apple uses the same default
+	tunnelMTU = 1280
 )
 
 // WireguardTunnelSetup exposes api for caller to implement external tunnel setup
@@ -54,6 +54,8 @@ type WireguardTunnelSetup interface {
 	AddTunnelAddress(ip string, prefixLen int)
 	AddRoute(route string, prefixLen int)
 	AddDNS(ip string)
+	AddAllowedApplication(packageName string) error
+	AddDisallowedApplication(packageName string) error
 	SetBlocking(blocking bool)
 	Establish() (int, error)
 	SetMTU(mtu int)
@@ -139,7 +141,7 @@ func (c *wireguardConnection) Start(ctx context.Context, options connection.Conn
 		config.Provider.Endpoint.Port = options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).Port
 	}
 
-	if err = c.device.Start(c.privateKey, config, options.ChannelConn, options.Params.DNS); err != nil {
+	if err = c.device.Start(c.privateKey, config, options.ChannelConn, options.Params.DNS, options.Params.Apps); err != nil {
 		return errors.Wrap(err, "could not start device")
 	}
 
@@ -179,7 +181,7 @@ func (c *wireguardConnection) GetConfig() (connection.ConsumerConfig, error) {
 }
 
 type wireguardDevice interface {
-	Start(privateKey string, config wireguard.ServiceConfig, channelConn *net.UDPConn, dns connection.DNSOption) error
+	Start(privateKey string, config wireguard.ServiceConfig, channelConn *net.UDPConn, dns connection.DNSOption, apps connection.AppFilter) error
 	Stop()
 	Stats() (wgcfg.Stats, error)
 }
@@ -194,9 +196,9 @@ type wireguardDeviceImpl struct {
 	device *device.Device
 }
 
-func (w *wireguardDeviceImpl) Start(privateKey string, config wireguard.ServiceConfig, channelConn *net.UDPConn, dns connection.DNSOption) error {
+func (w *wireguardDeviceImpl) Start(privateKey string, config wireguard.ServiceConfig, channelConn *net.UDPConn, dns connection.DNSOption, apps connection.AppFilter) error {
 	log.Debug().Msg("Creating tunnel device")
-	tunDevice, err := w.newTunnDevice(w.tunnelSetup, config, dns)
+	tunDevice, err := w.newTunnDevice(w.tunnelSetup, config, dns, apps)
 	if err != nil {
 		return errors.Wrap(err, "could not create tunnel device")
 	}
@@ -210,7 +212,7 @@ func (w *wireguardDeviceImpl) Start(privateKey string, config wireguard.ServiceC
 
 	w.device = device.NewDevice(tunDevice, conn.NewStdNetBind(), device.NewLogger(device.LogLevelVerbose, "[userspace-wg]"))
 
-	err = w.applyConfig(w.device, privateKey, config)
+	err = applyDeviceConfig(w.device, privateKey, config)
 	if err != nil {
 		return errors.Wrap(err, "could not setup device configuration")
 	}
@@ -246,10 +248,14 @@ func (w *wireguardDeviceImpl) Stop() {
 }
 
 func (w *wireguardDeviceImpl) Stats() (wgcfg.Stats, error) {
-	if w.device == nil {
+	return deviceStats(w.device)
+}
+
+func deviceStats(d *device.Device) (wgcfg.Stats, error) {
+	if d == nil {
 		return wgcfg.Stats{}, errors.New("device is not started")
 	}
-	deviceState, err := userspace.ParseUserspaceDevice(w.device.IpcGetOperation)
+	deviceState, err := userspace.ParseUserspaceDevice(d.IpcGetOperation)
 	if err != nil {
 		return wgcfg.Stats{}, errors.Wrap(err, "could not parse userspace wg device state")
 	}
@@ -260,7 +266,7 @@ func (w *wireguardDeviceImpl) Stats() (wgcfg.Stats, error) {
 	return stats, nil
 }
 
-func (w *wireguardDeviceImpl) applyConfig(devApi *device.Device, privateKey string, config wireguard.ServiceConfig) error {
+func applyDeviceConfig(devApi *device.Device, privateKey string, config wireguard.ServiceConfig) error {
 	deviceConfig := wgcfg.DeviceConfig{
 		PrivateKey: privateKey,
 		ListenPort: config.LocalPort,
@@ -280,28 +286,17 @@ func (w *wireguardDeviceImpl) applyConfig(devApi *device.Device, privateKey stri
 	return nil
 }
 
-func (w *wireguardDeviceImpl) newTunnDevice(wgTunnSetup WireguardTunnelSetup, config wireguard.ServiceConfig, dns connection.DNSOption) (tun.Device, error) {
-	consumerIP := config.Consumer.IPAddress
-	prefixLen, _ := consumerIP.Mask.Size()
+func (w *wireguardDeviceImpl) newTunnDevice(wgTunnSetup WireguardTunnelSetup, config wireguard.ServiceConfig, dns connection.DNSOption, apps connection.AppFilter) (tun.Device, error) {
 	wgTunnSetup.NewTunnel()
 	wgTunnSetup.SetSessionName("wg-tun-session")
-	wgTunnSetup.AddTunnelAddress(consumerIP.IP.String(), prefixLen)
-	wgTunnSetup.SetMTU(androidTunMtu)
 	wgTunnSetup.SetBlocking(true)
-
-	dnsIPs, err := dns.ResolveIPs(config.Consumer.DNSIPs)
-	if err != nil {
+	if err := setupTunnelSettings(wgTunnSetup, config, dns); err != nil {
 		return nil, err
 	}
-	for _, dnsIP := range dnsIPs {
-		wgTunnSetup.AddDNS(dnsIP)
-	}
 
-	// Route all traffic through tunnel
-	wgTunnSetup.AddRoute("0.0.0.0", 1)
-	wgTunnSetup.AddRoute("128.0.0.0", 1)
-	wgTunnSetup.AddRoute("::", 1)
-	wgTunnSetup.AddRoute("8000::", 1)
+	if err := setupAppFilter(wgTunnSetup, apps); err != nil {
+		return nil, err
+	}
 
 	fd, err := wgTunnSetup.Establish()
 	if err != nil {
@@ -317,3 +312,52 @@ func (w *wireguardDeviceImpl) newTunnDevice(wgTunnSetup WireguardTunnelSetup, co
 
 	return tunDevice, err
 }
+
+// tunnelSettings is the part of tunnel setup shared by platform specific tunnel setups.
+type tunnelSettings interface {
+	AddTunnelAddress(ip string, prefixLen int)
+	AddRoute(route string, prefixLen int)
+	AddDNS(ip string)
+	SetMTU(mtu int)
+}
+
+func setupTunnelSettings(settings tunnelSettings, config wireguard.ServiceConfig, dns connection.DNSOption) error {
+	consumerIP := config.Consumer.IPAddress
+	prefixLen, _ := consumerIP.Mask.Size()
+	settings.AddTunnelAddress(consumerIP.IP.String(), prefixLen)
+	settings.SetMTU(tunnelMTU)
+
+	dnsIPs, err := dns.ResolveIPs(config.Consumer.DNSIPs)
+	if err != nil {
+		return err
+	}
+	for _, dnsIP := range dnsIPs {
+		settings.AddDNS(dnsIP)
+	}
+
+	// Route all traffic through tunnel
+	settings.AddRoute("0.0.0.0", 1)
+	settings.AddRoute("128.0.0.0", 1)
+	settings.AddRoute("::", 1)
+	settings.AddRoute("8000::", 1)
+	return nil
+}
+
+// setupAppFilter limits the tunnel to allowed applications or excludes disallowed ones.
+// Android rejects mixing both lists within a single tunnel, so the filter is validated first.
+func setupAppFilter(wgTunnSetup WireguardTunnelSetup, apps connection.AppFilter) error {
+	if err := apps.Validate(); err != nil {
+		return err
+	}
+	for _, packageName := range apps.Allowed {
+		if err := wgTunnSetup.AddAllowedApplication(packageName); err != nil {
+			return fmt.Errorf("could not allow application %s: %w", packageName, err)
+		}
+	}
+	for _, packageName := range apps.Disallowed {
+		if err := wgTunnSetup.AddDisallowedApplication(packageName); err != nil {
+			return fmt.Errorf("could not disallow application %s: %w", packageName, err)
+		}
+	}
+	return nil
+}
@@ -93,6 +93,45 @@ func TestConnectionStopOnceAfterHandshakeErrorAndStopCall(t *testing.T) {
 	assert.Equal(t, connectionstate.NotConnected, <-conn.State())
 }
 
+func TestSetupAppFilter(t *testing.T) {
+	setup := &mockTunnelSetup{}
+	err := setupAppFilter(setup, connection.AppFilter{Allowed: []string{"org.mozilla.firefox", "com.android.chrome"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"org.mozilla.firefox", "com.android.chrome"}, setup.allowed)
+	assert.Empty(t, setup.disallowed)
+
+	setup = &mockTunnelSetup{}
+	err = setupAppFilter(setup, connection.AppFilter{Disallowed: []string{"com.netflix.mediaclient"}})
+	assert.NoError(t, err)
+	assert.Empty(t, setup.allowed)
+	assert.Equal(t, []string{"com.netflix.mediaclient"}, setup.disallowed)
+
+	setup = &mockTunnelSetup{}
+	err = setupAppFilter(setup, connection.AppFilter{Allowed: []string{"a"}, Disallowed: []string{"b"}})
+	assert.Error(t, err)
+	assert.Empty(t, setup.allowed)
+
+	setup = &mockTunnelSetup{err: errors.New("package not found")}
+	err = setupAppFilter(setup, connection.AppFilter{Allowed: []string{"missing.app"}})
+	assert.EqualError(t, err, "could not allow application missing.app: package not found")
+}
+
+func TestConnectRequestAppFilter(t *testing.T) {
+	req := &ConnectRequest{AllowedApps: " org.mozilla.firefox, ,com.android.chrome"}
+	apps, err := req.appFilter()
+	assert.NoError(t, err)
+	assert.Equal(t, connection.AppFilter{Allowed: []string{"org.mozilla.firefox", "com.android.chrome"}}, apps)
+
+	req = &ConnectRequest{}
+	apps, err = req.appFilter()
+	assert.NoError(t, err)
+	assert.Equal(t, connection.AppFilter{}, apps)
+
+	req = &ConnectRequest{AllowedApps: "a", DisallowedApps: "b"}
+	_, err = req.appFilter()
+	assert.Error(t, err)
+}
+
 func newConn(t *testing.T) *wireguardConnection {
 	opts := wireGuardOptions{
 		statsUpdateInterval: 1 * time.Millisecond,
@@ -129,7 +168,7 @@ func newServiceConfig() wg.ServiceConfig {
 
 type mockWireGuardDevice struct{}
 
-func (m mockWireGuardDevice) Start(_ string, _ wg.ServiceConfig, _ *net.UDPConn, _ connection.DNSOption) error {
+func (m mockWireGuardDevice) Start(_ string, _ wg.ServiceConfig, _ *net.UDPConn, _ connection.DNSOption, _ connection.AppFilter) error {
 	return nil
 }
 
@@ -147,3 +186,27 @@ type mockHandshakeWaiter struct {
 func (m *mockHandshakeWaiter) Wait(ctx context.Context, statsFetch func() (wgcfg.Stats, error), timeout time.Duration, stop <-chan struct{}) error {
 	return m.err
 }
+
+type mockTunnelSetup struct {
+	WireguardTunnelSetup
+
+	err        error
+	allowed    []string
+	disallowed []string
+}
+
+func (m *mockTunnelSetup) AddAllowedApplication(packageName string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.allowed = append(m.allowed, packageName)
+	return nil
+}
+
+func (m *mockTunnelSetup) AddDisallowedApplication(packageName string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.disallowed = append(m.disallowed, packageName)
+	return nil
+}
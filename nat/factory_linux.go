@@ -21,21 +21,26 @@ import (
 	"os/exec"
 
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/firewall/nftables"
 )
 
-// NewService returns linux os specific nat service based on ip tables
+// NewService returns linux os specific nat service based on ip tables,
+// or nftables when iptables is not available in the system.
 func NewService() NATService {
 	if config.GetBool(config.FlagUserspace) {
 		return &serviceNoop{}
 	}
-	return &serviceIPTables{
-		ipForward: serviceIPForward{
-			CommandFactory: func(name string, arg ...string) Command {
-				return exec.Command(name, arg...)
-			},
-			CommandEnable:  []string{"sudo", "/sbin/sysctl", "-w", "net.ipv4.ip_forward=1"},
-			CommandDisable: []string{"sudo", "/sbin/sysctl", "-w", "net.ipv4.ip_forward=0"},
-			CommandRead:    []string{"/sbin/sysctl", "-n", "net.ipv4.ip_forward"},
+
+	ipForward := serviceIPForward{
+		CommandFactory: func(name string, arg ...string) Command {
+			return exec.Command(name, arg...)
 		},
+		CommandEnable:  []string{"sudo", "/sbin/sysctl", "-w", "net.ipv4.ip_forward=1"},
+		CommandDisable: []string{"sudo", "/sbin/sysctl", "-w", "net.ipv4.ip_forward=0"},
+		CommandRead:    []string{"/sbin/sysctl", "-n", "net.ipv4.ip_forward"},
+	}
+	if nftables.Preferred() {
+		return &serviceNftables{ipForward: ipForward}
 	}
+	return &serviceIPTables{ipForward: ipForward}
 }
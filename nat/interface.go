@@ -17,7 +17,10 @@
 
 package nat
 
-import "net"
+import (
+	"encoding/json"
+	"net"
+)
 
 // NATService routes internet traffic through provider and
 // sets up firewall rules for security
@@ -28,6 +31,16 @@ type NATService interface {
 	Disable() error
 }
 
+// RuleKeeper is implemented by NAT services able to keep rules of sessions
+// which outlive the node process, so that a later process can take them over.
+type RuleKeeper interface {
+	// Keep stops tracking given rules, so that they stay in place when the service is disabled,
+	// and returns their state.
+	Keep(rules []interface{}) (json.RawMessage, error)
+	// Adopt ensures that kept rules are in place and tracks them again.
+	Adopt(state json.RawMessage) (rules []interface{}, err error)
+}
+
 // Options params to setup firewall/NAT rules.
 type Options struct {
 	VPNNetwork        net.IPNet
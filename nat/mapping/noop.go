@@ -42,3 +42,5 @@ func (p *noopPortMapper) Map(id, protocol string, port int, name string) (releas
 		log.Debug().Msgf("Noop port mapping released: %d", port)
 	}, false
 }
+
+func (p *noopPortMapper) Remap() {}
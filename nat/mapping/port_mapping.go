@@ -19,7 +19,9 @@ package mapping
 
 import (
 	"errors"
+	"fmt"
 	"net"
+	"sync"
 	"time"
 
 	portmap "github.com/ethereum/go-ethereum/p2p/nat"
@@ -53,6 +55,8 @@ type PortMapper interface {
 	// must be called when port no longer needed and ok which is true if
 	// port mapping was successful.
 	Map(id, protocol string, port int, name string) (release func(), ok bool)
+	// Remap re-adds all active port mappings, e.g. after router reconnection.
+	Remap()
 }
 
 // NewPortMapper returns port mapper instance.
@@ -60,12 +64,27 @@ func NewPortMapper(config *Config, publisher eventbus.Publisher) PortMapper {
 	return &portMapper{
 		config:    config,
 		publisher: publisher,
+		active:    make(map[string]activeMapping),
 	}
 }
 
 type portMapper struct {
 	config    *Config
 	publisher eventbus.Publisher
+
+	mu     sync.Mutex
+	active map[string]activeMapping
+}
+
+type activeMapping struct {
+	id       string
+	protocol string
+	port     int
+	name     string
+}
+
+func (m activeMapping) key() string {
+	return fmt.Sprintf("%s/%d", m.protocol, m.port)
 }
 
 func (p *portMapper) Map(id, protocol string, port int, name string) (release func(), ok bool) {
@@ -85,9 +104,14 @@ func (p *portMapper) Map(id, protocol string, port int, name string) (release fu
 		return nil, false
 	}
 
+	active := activeMapping{id: id, protocol: protocol, port: port, name: name}
+	p.mu.Lock()
+	p.active[active.key()] = active
+	p.mu.Unlock()
+
 	// If only permanent lease is supported we don't need to update it in intervals.
 	if permanent {
-		return func() { p.deleteMapping(protocol, port, port) }, true
+		return func() { p.release(active) }, true
 	}
 
 	stopUpdate := make(chan struct{})
@@ -104,11 +128,34 @@ func (p *portMapper) Map(id, protocol string, port int, name string) (release fu
 	}()
 
 	return func() {
-		p.deleteMapping(protocol, port, port)
+		p.release(active)
 		close(stopUpdate)
 	}, true
 }
 
+// Remap re-adds all active port mappings.
+func (p *portMapper) Remap() {
+	p.mu.Lock()
+	mappings := make([]activeMapping, 0, len(p.active))
+	for _, active := range p.active {
+		mappings = append(mappings, active)
+	}
+	p.mu.Unlock()
+
+	for _, active := range mappings {
+		_, err := p.addMapping(active.protocol, active.port, active.port, active.name)
+		p.notify(active.id, err)
+	}
+}
+
+func (p *portMapper) release(active activeMapping) {
+	p.mu.Lock()
+	delete(p.active, active.key())
+	p.mu.Unlock()
+
+	p.deleteMapping(active.protocol, active.port, active.port)
+}
+
 func (p *portMapper) routerIPPublic() bool {
 	ip, err := p.config.MapInterface.ExternalIP()
 	if err != nil {
@@ -74,6 +74,23 @@ func TestMap_uPnP_Enabled_With_Permanent_Lease(t *testing.T) {
 	}, router.addedMapping())
 }
 
+func TestRemap(t *testing.T) {
+	router := &mockRouter{uPnPEnabled: true, permanentLease: true}
+	portMapper := NewPortMapper(&Config{MapInterface: router}, mocks.NewEventBus())
+
+	release, ok := portMapper.Map("id", "UDP", 51334, "Test")
+	assert.True(t, ok)
+
+	router.reset()
+	portMapper.Remap()
+	assert.Equal(t, mapping{protocol: "UDP", extport: 51334, intport: 51334, name: "Test"}, router.addedMapping())
+
+	release()
+	router.reset()
+	portMapper.Remap()
+	assert.Equal(t, mapping{}, router.addedMapping())
+}
+
 func TestMap_uPnP_Disabled(t *testing.T) {
 	router := &mockRouter{uPnPEnabled: false}
 	config := &Config{
@@ -168,6 +185,13 @@ func (m *mockRouter) addedMapping() mapping {
 	return m.mapping
 }
 
+func (m *mockRouter) reset() {
+	m.Lock()
+	defer m.Unlock()
+
+	m.mapping = mapping{}
+}
+
 func (m *mockRouter) DeleteMapping(protocol string, extport, intport int) error {
 	return nil
 }
@@ -18,6 +18,7 @@
 package nat
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"sync"
@@ -34,6 +35,7 @@ import (
 type serviceIPTables struct {
 	mu        sync.Mutex
 	rules     []iptables.Rule
+	kept      bool
 	ipForward serviceIPForward
 }
 
@@ -93,6 +95,37 @@ func (svc *serviceIPTables) Del(rules []interface{}) (err error) {
 	return err
 }
 
+// Keep stops tracking given rules, so that they stay in place when the service is disabled.
+func (svc *serviceIPTables) Keep(rules []interface{}) (json.RawMessage, error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	typed := typedIptRules(rules)
+	for _, rule := range typed {
+		svc.forgetRule(rule)
+	}
+	svc.kept = true
+	return json.Marshal(typed)
+}
+
+// Adopt ensures that rules kept by a previous process are in place and tracks them again.
+func (svc *serviceIPTables) Adopt(state json.RawMessage) ([]interface{}, error) {
+	var rules []iptables.Rule
+	if err := json.Unmarshal(state, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse kept iptables rules: %w", err)
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	for _, rule := range rules {
+		if err := svc.adoptRule(rule); err != nil {
+			return nil, err
+		}
+	}
+	return untypedIptRules(rules), nil
+}
+
 // Enable enables NAT service.
 func (svc *serviceIPTables) Enable() error {
 	if config.GetBool(config.FlagUserMode) || config.GetBool(config.FlagUserspace) {
@@ -119,6 +152,15 @@ func (svc *serviceIPTables) Disable() error {
 		return nil
 	}
 
+	svc.mu.Lock()
+	kept := svc.kept
+	svc.mu.Unlock()
+
+	if kept {
+		log.Info().Msg("Keeping NAT/Firewall rules of detached sessions")
+		return nil
+	}
+
 	svc.ipForward.Disable()
 	err := svc.Del(untypedIptRules(svc.rules))
 	if err != nil {
@@ -141,28 +183,46 @@ func (svc *serviceIPTables) applyRule(rule iptables.Rule) error {
 	return nil
 }
 
+// adoptRule applies the rule unless it is already in place, and tracks it.
+func (svc *serviceIPTables) adoptRule(rule iptables.Rule) error {
+	if err := iptablesExec(rule.CheckArgs()...); err == nil {
+		svc.rules = append(svc.rules, rule)
+		return nil
+	}
+	return svc.applyRule(rule)
+}
+
 func (svc *serviceIPTables) removeRule(rule iptables.Rule) error {
 	if err := iptablesExec(rule.RemoveArgs()...); err != nil {
 		return err
 	}
+	svc.forgetRule(rule)
+	return nil
+}
+
+func (svc *serviceIPTables) forgetRule(rule iptables.Rule) {
 	for i := range svc.rules {
 		if svc.rules[i].Equals(rule) {
 			svc.rules = append(svc.rules[:i], svc.rules[i+1:]...)
 			break
 		}
 	}
-	return nil
 }
 
 func (svc *serviceIPTables) prepare() error {
-	err := iptablesExec("--new", chainMyst, "--table", "nat")
-	if err != nil {
-		return fmt.Errorf("failed to create MYST iptables chain: %w", err)
+	// The chain outlives the process when rules of detached sessions were kept.
+	if err := iptablesExec("--list", chainMyst, "--table", "nat"); err != nil {
+		if err := iptablesExec("--new", chainMyst, "--table", "nat"); err != nil {
+			return fmt.Errorf("failed to create MYST iptables chain: %w", err)
+		}
 	}
 
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
 	for _, ipNet := range protectedNetworks() {
 		// Protect private networks rule
-		err = svc.applyRule(iptables.AppendTo(chainMyst).RuleSpec(
+		err := svc.adoptRule(iptables.AppendTo(chainMyst).RuleSpec(
 			"--destination", ipNet.String(), "--jump", "DNAT", "--to-destination", "240.0.0.1", "--table", "nat"))
 		if err != nil {
 			return fmt.Errorf("failed to create blackhole rule in the MYST iptables chain: %w", err)
@@ -219,6 +279,13 @@ func makeIPTablesRules(opts Options) (rules []iptables.Rule) {
 		"--table", "nat")
 	rules = append(rules, rule)
 
+	// Clamp TCP MSS to path MTU, so that tunneled connections are not blackholed on low MTU links
+	for _, direction := range []string{"--source", "--destination"} {
+		rules = append(rules, iptables.AppendTo(chainForward).InTable("mangle").RuleSpec(direction, vpnNetwork,
+			"--protocol", "tcp", "--tcp-flags", "SYN,RST", "SYN",
+			"--jump", "TCPMSS", "--clamp-mss-to-pmtu"))
+	}
+
 	// ACCEPT forwarding rules
 	rules = append(rules, iptables.AppendTo(chainForward).RuleSpec("--source", vpnNetwork, "--jump", "ACCEPT"))
 	rules = append(rules, iptables.AppendTo(chainForward).RuleSpec("--destination", vpnNetwork, "--jump", "ACCEPT"))
@@ -0,0 +1,290 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+	"github.com/mysteriumnetwork/node/utils"
+)
+
+const (
+	nftTableNAT        = "myst_nat"
+	nftTableFilter     = "myst_filter"
+	nftChainMyst       = "myst"
+	nftChainPreRouting = "prerouting"
+	nftChainPostRoute  = "postrouting"
+	nftChainForward    = "forward"
+)
+
+// serviceNftables is the NAT service for systems without iptables, it keeps all of its rules in dedicated tables.
+type serviceNftables struct {
+	mu        sync.Mutex
+	rules     []nftables.Rule
+	kept      bool
+	ipForward serviceIPForward
+}
+
+// Setup sets NAT/Firewall rules for the given NATOptions.
+func (svc *serviceNftables) Setup(opts Options) (appliedRules []interface{}, err error) {
+	log.Info().Msg("Setting up NAT/Firewall rules")
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	// Store applied rules so we can remove if setup exits prematurely (one of the latter rules fails to apply)
+	var applied []nftables.Rule
+	defer func() {
+		if err == nil {
+			return
+		}
+		log.Warn().Msg("Error detected, clearing up rules that were already setup")
+		for _, rule := range applied {
+			if err := svc.removeRule(rule); err != nil {
+				log.Error().Err(err).Msg("Could not remove rule")
+			}
+		}
+	}()
+
+	for _, rule := range makeNftablesRules(opts) {
+		rule, err := svc.applyRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		applied = append(applied, rule)
+	}
+	log.Info().Msg("Setting up NAT/Firewall rules... done")
+	return untypedNftRules(applied), nil
+}
+
+// Del removes given NAT/Firewall rules that were previously set up.
+func (svc *serviceNftables) Del(rules []interface{}) (err error) {
+	log.Info().Msg("Deleting NAT/Firewall rules")
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	errs := utils.ErrorCollection{}
+	for _, rule := range typedNftRules(rules) {
+		log.Trace().Msgf("Deleting rule: %v", rule)
+		if err := svc.removeRule(rule); err != nil {
+			errs.Add(err)
+		}
+	}
+	err = errs.Error()
+	log.Info().Err(err).Msg("Deleting NAT/Firewall rules... done")
+	return err
+}
+
+// Keep stops tracking given rules, so that they stay in place when the service is disabled.
+func (svc *serviceNftables) Keep(rules []interface{}) (json.RawMessage, error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	typed := typedNftRules(rules)
+	for _, rule := range typed {
+		svc.forgetRule(rule)
+	}
+	svc.kept = true
+	return json.Marshal(typed)
+}
+
+// Adopt applies rules kept by a previous process and tracks them again.
+// The tables are recreated when the service is enabled, so kept rules are always applied anew.
+func (svc *serviceNftables) Adopt(state json.RawMessage) ([]interface{}, error) {
+	var rules []nftables.Rule
+	if err := json.Unmarshal(state, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse kept nftables rules: %w", err)
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	applied := make([]nftables.Rule, 0, len(rules))
+	for _, rule := range rules {
+		rule, err := svc.applyRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		applied = append(applied, rule)
+	}
+	return untypedNftRules(applied), nil
+}
+
+// Enable enables NAT service.
+func (svc *serviceNftables) Enable() error {
+	if config.GetBool(config.FlagUserMode) || config.GetBool(config.FlagUserspace) {
+		log.Info().Msg("Usermode active, nothing to do with nftables")
+		return nil
+	}
+
+	err := svc.prepare()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to prepare nftables setup")
+	}
+
+	err = svc.ipForward.Enable()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to enable IP forwarding")
+	}
+	return err
+}
+
+// Disable disables NAT service and deletes all rules.
+func (svc *serviceNftables) Disable() error {
+	if config.GetBool(config.FlagUserMode) || config.GetBool(config.FlagUserspace) {
+		log.Info().Msg("Usermode active, nothing to do with nftables")
+		return nil
+	}
+
+	svc.mu.Lock()
+	kept := svc.kept
+	svc.rules = nil
+	svc.mu.Unlock()
+
+	if kept {
+		log.Info().Msg("Keeping NAT/Firewall rules of detached sessions")
+		return nil
+	}
+
+	svc.ipForward.Disable()
+
+	errs := utils.ErrorCollection{}
+	errs.Add(nftables.DeleteTable("ip", nftTableNAT), nftables.DeleteTable("inet", nftTableFilter))
+	if err := errs.Error(); err != nil {
+		return fmt.Errorf("failed to cleanup nftables tables: %w", err)
+	}
+	return nil
+}
+
+func (svc *serviceNftables) applyRule(rule nftables.Rule) (nftables.Rule, error) {
+	rule, err := nftables.Apply(rule)
+	if err != nil {
+		return rule, err
+	}
+	svc.rules = append(svc.rules, rule)
+	return rule, nil
+}
+
+func (svc *serviceNftables) removeRule(rule nftables.Rule) error {
+	if err := nftables.Remove(rule); err != nil {
+		return err
+	}
+	svc.forgetRule(rule)
+	return nil
+}
+
+func (svc *serviceNftables) forgetRule(rule nftables.Rule) {
+	for i := range svc.rules {
+		if svc.rules[i].Handle() == rule.Handle() && svc.rules[i].Equals(rule) {
+			svc.rules = append(svc.rules[:i], svc.rules[i+1:]...)
+			break
+		}
+	}
+}
+
+func (svc *serviceNftables) prepare() error {
+	if err := nftables.CreateTable("ip", nftTableNAT); err != nil {
+		return fmt.Errorf("failed to create nftables NAT table: %w", err)
+	}
+	chains := []struct{ name, spec string }{
+		{nftChainPreRouting, "type nat hook prerouting priority -100;"},
+		{nftChainPostRoute, "type nat hook postrouting priority 100;"},
+		{nftChainMyst, ""},
+	}
+	for _, chain := range chains {
+		if err := nftables.CreateChain("ip", nftTableNAT, chain.name, chain.spec); err != nil {
+			return fmt.Errorf("failed to create nftables %s chain: %w", chain.name, err)
+		}
+	}
+
+	if err := nftables.CreateTable("inet", nftTableFilter); err != nil {
+		return fmt.Errorf("failed to create nftables filter table: %w", err)
+	}
+	if err := nftables.CreateChain("inet", nftTableFilter, nftChainForward, "type filter hook forward priority 0; policy accept;"); err != nil {
+		return fmt.Errorf("failed to create nftables forward chain: %w", err)
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	for _, ipNet := range protectedNetworks() {
+		// Protect private networks rule
+		_, err := svc.applyRule(nftables.AppendTo("ip", nftTableNAT, nftChainMyst).RuleSpec(
+			"ip", "daddr", ipNet.String(), "dnat", "to", "240.0.0.1"))
+		if err != nil {
+			return fmt.Errorf("failed to create blackhole rule in the myst nftables chain: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func makeNftablesRules(opts Options) (rules []nftables.Rule) {
+	vpnNetwork := opts.VPNNetwork.String()
+
+	rules = append(rules, nftables.InsertInto("ip", nftTableNAT, nftChainPreRouting).RuleSpec(
+		"ip", "saddr", vpnNetwork, "jump", nftChainMyst))
+
+	if opts.EnableDNSRedirect {
+		// DNS port redirect rules
+		for _, proto := range []string{"udp", "tcp"} {
+			rules = append(rules, nftables.InsertInto("ip", nftTableNAT, nftChainMyst).RuleSpec(
+				"ip", "daddr", opts.DNSIP.String(), proto, "dport", "53",
+				"redirect", "to", ":"+strconv.Itoa(opts.DNSPort),
+			))
+		}
+	}
+
+	// NAT forwarding rule
+	rules = append(rules, nftables.AppendTo("ip", nftTableNAT, nftChainPostRoute).RuleSpec(
+		"ip", "saddr", vpnNetwork, "ip", "daddr", "!=", vpnNetwork, "snat", "to", opts.ProviderExtIP.String()))
+
+	// Clamp TCP MSS to path MTU, so that tunneled connections are not blackholed on low MTU links
+	for _, direction := range []string{"saddr", "daddr"} {
+		rules = append(rules, nftables.AppendTo("inet", nftTableFilter, nftChainForward).RuleSpec(
+			"ip", direction, vpnNetwork, "tcp", "flags", "syn", "tcp", "option", "maxseg", "size", "set", "rt", "mtu"))
+	}
+
+	// ACCEPT forwarding rules
+	rules = append(rules, nftables.AppendTo("inet", nftTableFilter, nftChainForward).RuleSpec("ip", "saddr", vpnNetwork, "accept"))
+	rules = append(rules, nftables.AppendTo("inet", nftTableFilter, nftChainForward).RuleSpec("ip", "daddr", vpnNetwork, "accept"))
+
+	return rules
+}
+
+func untypedNftRules(rules []nftables.Rule) []interface{} {
+	res := make([]interface{}, len(rules))
+	for i := range rules {
+		res[i] = rules[i]
+	}
+	return res
+}
+
+func typedNftRules(rules []interface{}) []nftables.Rule {
+	res := make([]nftables.Rule, len(rules))
+	for i := range rules {
+		res[i] = rules[i].(nftables.Rule)
+	}
+	return res
+}
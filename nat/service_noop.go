@@ -17,6 +17,8 @@
 
 package nat
 
+import "encoding/json"
+
 type serviceNoop struct{}
 
 // Setup sets NAT/Firewall rules for the given NATOptions.
@@ -38,3 +40,13 @@ func (svc *serviceNoop) Enable() error {
 func (svc *serviceNoop) Disable() error {
 	return nil
 }
+
+// Keep stops tracking given rules, so that they stay in place when the service is disabled.
+func (svc *serviceNoop) Keep(rules []interface{}) (json.RawMessage, error) {
+	return json.RawMessage("null"), nil
+}
+
+// Adopt ensures that rules kept by a previous process are in place and tracks them again.
+func (svc *serviceNoop) Adopt(state json.RawMessage) ([]interface{}, error) {
+	return nil, nil
+}
@@ -0,0 +1,151 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/utils/netutil"
+)
+
+// declared as vars for override in test
+var (
+	addSourceRoute    = netutil.AddSourceRoute
+	deleteSourceRoute = netutil.DeleteSourceRoute
+)
+
+// sourceRoute is a rule routing consumer network through the bound interface.
+type sourceRoute struct {
+	network net.IPNet
+}
+
+// keptSourceRoutes is the state of rules and routes kept for a later process.
+type keptSourceRoutes struct {
+	NAT    json.RawMessage `json:"nat"`
+	Routes []string        `json:"routes"`
+}
+
+type serviceSourceRoute struct {
+	NATService
+	iface string
+}
+
+// NewSourceRouteService wraps NAT service so that traffic of consumer networks
+// leaves the provider through the given network interface instead of the default route.
+func NewSourceRouteService(natService NATService, iface string) NATService {
+	return &serviceSourceRoute{
+		NATService: natService,
+		iface:      iface,
+	}
+}
+
+// Setup sets NAT/Firewall rules for the given NATOptions and routes VPN network through the interface.
+func (svc *serviceSourceRoute) Setup(opts Options) ([]interface{}, error) {
+	rules, err := svc.NATService.Setup(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := addSourceRoute(opts.VPNNetwork, svc.iface); err != nil {
+		if err := svc.NATService.Del(rules); err != nil {
+			log.Error().Err(err).Msg("Failed to delete NAT rules")
+		}
+		return nil, err
+	}
+
+	return append(rules, sourceRoute{network: opts.VPNNetwork}), nil
+}
+
+// Del removes given NAT/Firewall rules and routes that were previously set up.
+func (svc *serviceSourceRoute) Del(rules []interface{}) error {
+	var natRules []interface{}
+	for _, rule := range rules {
+		route, ok := rule.(sourceRoute)
+		if !ok {
+			natRules = append(natRules, rule)
+			continue
+		}
+
+		if err := deleteSourceRoute(route.network, svc.iface); err != nil {
+			log.Error().Err(err).Msgf("Failed to delete source route of %s", route.network.String())
+		}
+	}
+
+	return svc.NATService.Del(natRules)
+}
+
+// Keep stops tracking given rules and routes, so that they stay in place when the service is disabled.
+func (svc *serviceSourceRoute) Keep(rules []interface{}) (json.RawMessage, error) {
+	keeper, ok := svc.NATService.(RuleKeeper)
+	if !ok {
+		return nil, errors.New("NAT service cannot keep rules")
+	}
+
+	var kept keptSourceRoutes
+	var natRules []interface{}
+	for _, rule := range rules {
+		if route, ok := rule.(sourceRoute); ok {
+			kept.Routes = append(kept.Routes, route.network.String())
+			continue
+		}
+		natRules = append(natRules, rule)
+	}
+
+	natState, err := keeper.Keep(natRules)
+	if err != nil {
+		return nil, err
+	}
+	kept.NAT = natState
+	return json.Marshal(kept)
+}
+
+// Adopt ensures that rules and routes kept by a previous process are in place and tracks them again.
+func (svc *serviceSourceRoute) Adopt(state json.RawMessage) ([]interface{}, error) {
+	keeper, ok := svc.NATService.(RuleKeeper)
+	if !ok {
+		return nil, errors.New("NAT service cannot keep rules")
+	}
+
+	var kept keptSourceRoutes
+	if err := json.Unmarshal(state, &kept); err != nil {
+		return nil, fmt.Errorf("failed to parse kept source routes: %w", err)
+	}
+
+	rules, err := keeper.Adopt(kept.NAT)
+	if err != nil {
+		return nil, err
+	}
+	for _, route := range kept.Routes {
+		_, network, err := net.ParseCIDR(route)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse kept source route: %w", err)
+		}
+		// The route may be left in place, re-add it so that it is known to match the bound interface.
+		deleteSourceRoute(*network, svc.iface)
+		if err := addSourceRoute(*network, svc.iface); err != nil {
+			return nil, err
+		}
+		rules = append(rules, sourceRoute{network: *network})
+	}
+	return rules, nil
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockNATService struct {
+	serviceNoop
+	deleted []interface{}
+}
+
+func (svc *mockNATService) Setup(opts Options) ([]interface{}, error) {
+	return []interface{}{"rule"}, nil
+}
+
+func (svc *mockNATService) Del(rules []interface{}) error {
+	svc.deleted = append(svc.deleted, rules...)
+	return nil
+}
+
+func TestSourceRouteService(t *testing.T) {
+	routes := map[string]string{}
+	defer func(add, del func(net.IPNet, string) error) {
+		addSourceRoute, deleteSourceRoute = add, del
+	}(addSourceRoute, deleteSourceRoute)
+	addSourceRoute = func(src net.IPNet, iface string) error {
+		routes[src.String()] = iface
+		return nil
+	}
+	deleteSourceRoute = func(src net.IPNet, iface string) error {
+		delete(routes, src.String())
+		return nil
+	}
+
+	inner := &mockNATService{}
+	svc := NewSourceRouteService(inner, "eth1")
+	_, network, _ := net.ParseCIDR("10.182.0.0/24")
+
+	rules, err := svc.Setup(Options{VPNNetwork: *network})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"10.182.0.0/24": "eth1"}, routes)
+
+	require.NoError(t, svc.Del(rules))
+	assert.Empty(t, routes)
+	assert.Equal(t, []interface{}{"rule"}, inner.deleted)
+}
+
+func TestSourceRouteService_SetupFailure(t *testing.T) {
+	defer func(add func(net.IPNet, string) error) { addSourceRoute = add }(addSourceRoute)
+	addSourceRoute = func(src net.IPNet, iface string) error {
+		return errors.New("no route")
+	}
+
+	inner := &mockNATService{}
+	_, err := NewSourceRouteService(inner, "eth1").Setup(Options{})
+	assert.Error(t, err)
+	assert.Equal(t, []interface{}{"rule"}, inner.deleted)
+}
+
+func TestSourceRouteService_KeepAndAdopt(t *testing.T) {
+	routes := map[string]string{}
+	defer func(add, del func(net.IPNet, string) error) {
+		addSourceRoute, deleteSourceRoute = add, del
+	}(addSourceRoute, deleteSourceRoute)
+	addSourceRoute = func(src net.IPNet, iface string) error {
+		routes[src.String()] = iface
+		return nil
+	}
+	deleteSourceRoute = func(src net.IPNet, iface string) error {
+		delete(routes, src.String())
+		return nil
+	}
+
+	svc := NewSourceRouteService(&mockNATService{}, "eth1")
+	_, network, _ := net.ParseCIDR("10.182.0.0/24")
+	rules, err := svc.Setup(Options{VPNNetwork: *network})
+	require.NoError(t, err)
+
+	state, err := svc.(RuleKeeper).Keep(rules)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"10.182.0.0/24": "eth1"}, routes)
+
+	adopted, err := NewSourceRouteService(&mockNATService{}, "eth1").(RuleKeeper).Adopt(state)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{sourceRoute{network: *network}}, adopted)
+	assert.Equal(t, map[string]string{"10.182.0.0/24": "eth1"}, routes)
+}
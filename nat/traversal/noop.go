@@ -43,7 +43,7 @@ func (np *NoopPinger) PingProviderPeer(ctx context.Context, localIP, remoteIP st
 }
 
 // PingConsumerPeer does nothing.
-func (np *NoopPinger) PingConsumerPeer(ctx context.Context, id, ip string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error) {
+func (np *NoopPinger) PingConsumerPeer(ctx context.Context, id, localIP, ip string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error) {
 	np.eventPublisher.Publish(event.AppTopicTraversal, event.BuildSuccessfulEvent(id, "noop_pinger"))
 	return []*net.UDPConn{}, nil
 }
@@ -31,6 +31,7 @@ import (
 	"golang.org/x/net/ipv4"
 
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/core/privacy"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/nat/event"
 	"github.com/mysteriumnetwork/node/router"
@@ -57,7 +58,7 @@ var ErrTooFew = errors.New("too few connections were built")
 // NATPinger is responsible for pinging nat holes
 type NATPinger interface {
 	PingProviderPeer(ctx context.Context, localIP, remoteIP string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error)
-	PingConsumerPeer(ctx context.Context, id string, remoteIP string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error)
+	PingConsumerPeer(ctx context.Context, id string, localIP, remoteIP string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error)
 }
 
 // PingConfig represents NAT pinger config.
@@ -112,9 +113,9 @@ func cleanupConnections(responses []pingResponse) {
 }
 
 // PingConsumerPeer pings remote peer with a defined configuration
-// and notifies peer which connections will be used.
-// It returns n connections if possible or error.
-func (p *Pinger) PingConsumerPeer(ctx context.Context, id string, remoteIP string, localPorts, remotePorts []int, initialTTL int, n int) ([]*net.UDPConn, error) {
+// and notifies peer which connections will be used. Connections are bound
+// to localIP unless it is empty. It returns n connections if possible or error.
+func (p *Pinger) PingConsumerPeer(ctx context.Context, id string, localIP, remoteIP string, localPorts, remotePorts []int, initialTTL int, n int) ([]*net.UDPConn, error) {
 	ctx, cancel := context.WithTimeout(ctx, p.pingConfig.Timeout)
 	defer cancel()
 
@@ -123,7 +124,7 @@ func (p *Pinger) PingConsumerPeer(ctx context.Context, id string, remoteIP strin
 	stop := make(chan struct{})
 	defer close(stop)
 
-	ch, err := p.multiPingN(ctx, "", remoteIP, localPorts, remotePorts, initialTTL, n)
+	ch, err := p.multiPingN(ctx, localIP, remoteIP, localPorts, remotePorts, initialTTL, n)
 	if err != nil {
 		log.Err(err).Msg("Failed to ping remote peer")
 		return nil, err
@@ -329,7 +330,7 @@ func (p *Pinger) ping(ctx context.Context, conn *net.UDPConn, remoteAddr *net.UD
 		case <-ctx.Done():
 			return nil
 		case <-time.After(p.pingConfig.Interval):
-			log.Trace().Msgf("Pinging %s from %s... with ttl %d", remoteAddr, conn.LocalAddr(), ttl)
+			log.Trace().Msgf("Pinging %s from %s... with ttl %d", privacy.ConsumerAddr(remoteAddr.String()), conn.LocalAddr(), ttl)
 
 			_, err := conn.WriteToUDP([]byte(msgPing+remoteAddr.String()), remoteAddr)
 			if ctx.Err() != nil {
@@ -388,12 +389,12 @@ func (p *Pinger) pingReceiver(ctx context.Context, conn *net.UDPConn) (*net.UDPA
 		}
 
 		if err != nil || n == 0 {
-			log.Debug().Err(err).Msgf("Failed to read remote peer: %s - attempting to continue", raddr)
+			log.Debug().Err(err).Msgf("Failed to read remote peer: %s - attempting to continue", privacy.ConsumerAddr(raddr.String()))
 			continue
 		}
 
 		msg := string(buf[:n])
-		log.Debug().Msgf("Remote peer data received: %s, len: %d, from: %s", msg, n, raddr)
+		log.Debug().Msgf("Remote peer data received, len: %d, from: %s", n, privacy.ConsumerAddr(raddr.String()))
 
 		if msg == msgOK || strings.HasPrefix(msg, msgPing) {
 			return raddr, nil
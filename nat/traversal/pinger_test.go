@@ -55,7 +55,7 @@ func TestPinger_PingPeer_N_Connections(t *testing.T) {
 		peerConns <- conns[0]
 		peerConns <- conns[1]
 	}()
-	conns, err := provider.PingConsumerPeer(context.Background(), "id", "127.0.0.1", pPorts, cPorts, 2, 2)
+	conns, err := provider.PingConsumerPeer(context.Background(), "id", "", "127.0.0.1", pPorts, cPorts, 2, 2)
 	if err != nil {
 		t.Errorf("PingConsumerPeer error: %v", err)
 		return
@@ -100,7 +100,7 @@ func TestPinger_PingPeer_Not_Enough_Connections_Timeout(t *testing.T) {
 		_, err := consumer.PingProviderPeer(context.Background(), "", "127.0.0.1", cPorts, pPorts, 2, 30)
 		consumerPingErr <- err
 	}()
-	conns, err := provider.PingConsumerPeer(context.Background(), "id", "127.0.0.1", pPorts, cPorts, 2, 30)
+	conns, err := provider.PingConsumerPeer(context.Background(), "id", "", "127.0.0.1", pPorts, cPorts, 2, 30)
 	assert.Equal(t, ErrTooFew, err)
 	assert.Len(t, conns, 0)
 
@@ -128,7 +128,7 @@ func TestPinger_PingConsumerPeer_Timeout(t *testing.T) {
 		select {}
 	}()
 
-	_, err = pinger.PingConsumerPeer(context.Background(), "id", "127.0.0.1", []int{consumerPort}, []int{providerPort}, 2, 2)
+	_, err = pinger.PingConsumerPeer(context.Background(), "id", "", "127.0.0.1", []int{consumerPort}, []int{providerPort}, 2, 2)
 
 	assert.Equal(t, ErrTooFew, err)
 }
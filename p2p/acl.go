@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// ErrTopicForbidden indicates that peer is not allowed to call the topic.
+var ErrTopicForbidden = errors.New("p2p topic forbidden")
+
+// PeerRole is a role of the authenticated peer on the other side of the channel.
+type PeerRole string
+
+const (
+	// PeerRoleConsumer is a regular consumer using provider services.
+	PeerRoleConsumer PeerRole = "consumer"
+	// PeerRoleProvider is another provider talking to this provider.
+	PeerRoleProvider PeerRole = "provider"
+	// PeerRoleMonitoring is a monitoring agent checking provider services.
+	PeerRoleMonitoring PeerRole = "monitoring"
+)
+
+// TopicForbiddenError is returned by the channel when peer calls a topic which is not allowed for its role.
+type TopicForbiddenError struct {
+	Topic string
+	Role  PeerRole
+}
+
+func (e *TopicForbiddenError) Error() string {
+	return fmt.Sprintf("topic %q is not allowed for %s peer", e.Topic, e.Role)
+}
+
+// Unwrap allows to match the error with ErrTopicForbidden.
+func (e *TopicForbiddenError) Unwrap() error {
+	return ErrTopicForbidden
+}
+
+// TopicACL declares which topics peers may call on channels established by the listener.
+type TopicACL struct {
+	// Role resolves the role of authenticated peer. Peers are treated as consumers if not set.
+	Role func(peerID identity.Identity) PeerRole
+
+	// Topics lists topics each role may call. Roles which are not listed can't call any topic.
+	// All registered topics are callable by any peer if not set.
+	Topics map[PeerRole][]string
+}
+
+// DefaultTopicACL returns topic ACL which allows consumers to use and pay for services,
+// monitoring agents to check them and other providers only to keep channels alive.
+func DefaultTopicACL() TopicACL {
+	session := []string{
+		TopicKeepAlive,
+		TopicSessionCreate,
+		TopicSessionAcknowledge,
+		TopicSessionStatus,
+		TopicSessionDestroy,
+		TopicPaymentMessage,
+	}
+
+	return TopicACL{
+		Topics: map[PeerRole][]string{
+			PeerRoleConsumer: append([]string{
+				TopicSessionReceipt,
+				TopicSessionRekey,
+				// Management requests are additionally allowed only for paired peers.
+				TopicManagementPair,
+				TopicManagementRequest,
+			}, session...),
+			PeerRoleMonitoring: session,
+			PeerRoleProvider:   {TopicKeepAlive},
+		},
+	}
+}
+
+// forPeer resolves topics allowed for the given peer. Nil is returned if topics are not restricted.
+func (acl TopicACL) forPeer(peerID identity.Identity) *peerACL {
+	if acl.Topics == nil {
+		return nil
+	}
+
+	role := PeerRoleConsumer
+	if acl.Role != nil {
+		role = acl.Role(peerID)
+	}
+
+	allowed := make(map[string]struct{}, len(acl.Topics[role]))
+	for _, topic := range acl.Topics[role] {
+		allowed[topic] = struct{}{}
+	}
+	return &peerACL{role: role, topics: allowed}
+}
+
+// peerACL holds topics allowed for a single channel peer.
+type peerACL struct {
+	role   PeerRole
+	topics map[string]struct{}
+}
+
+func (a *peerACL) check(topic string) error {
+	if a == nil {
+		return nil
+	}
+	if _, ok := a.topics[topic]; !ok {
+		return &TopicForbiddenError{Topic: topic, Role: a.role}
+	}
+	return nil
+}
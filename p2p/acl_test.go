@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+func TestTopicACL_ForPeer(t *testing.T) {
+	monitoring := identity.FromAddress("0x1")
+	provider := identity.FromAddress("0x2")
+	consumer := identity.FromAddress("0x3")
+
+	acl := DefaultTopicACL()
+	acl.Role = func(peerID identity.Identity) PeerRole {
+		switch peerID {
+		case monitoring:
+			return PeerRoleMonitoring
+		case provider:
+			return PeerRoleProvider
+		}
+		return PeerRoleConsumer
+	}
+
+	assert.NoError(t, acl.forPeer(consumer).check(TopicManagementRequest))
+	assert.NoError(t, acl.forPeer(monitoring).check(TopicSessionCreate))
+	assert.NoError(t, acl.forPeer(provider).check(TopicKeepAlive))
+
+	err := acl.forPeer(monitoring).check(TopicManagementRequest)
+	assert.True(t, errors.Is(err, ErrTopicForbidden))
+	var forbidden *TopicForbiddenError
+	require.True(t, errors.As(err, &forbidden))
+	assert.Equal(t, TopicForbiddenError{Topic: TopicManagementRequest, Role: PeerRoleMonitoring}, *forbidden)
+
+	assert.True(t, errors.Is(acl.forPeer(provider).check(TopicSessionCreate), ErrTopicForbidden))
+	assert.True(t, errors.Is(acl.forPeer(consumer).check("unknown"), ErrTopicForbidden))
+}
+
+func TestTopicACL_ForPeer_Unrestricted(t *testing.T) {
+	acl := TopicACL{}
+	assert.Nil(t, acl.forPeer(identity.FromAddress("0x1")))
+	assert.NoError(t, acl.forPeer(identity.FromAddress("0x1")).check("anything"))
+}
+
+func TestChannel_RejectsForbiddenTopics(t *testing.T) {
+	provider, consumer, err := createTestChannels()
+	require.NoError(t, err)
+	defer provider.Close()
+	defer consumer.Close()
+
+	provider.(*channel).setACL(TopicACL{
+		Topics: map[PeerRole][]string{PeerRoleConsumer: {"allowed"}},
+	}.forPeer(identity.FromAddress("0x1")))
+
+	handled := make(chan string, 2)
+	for _, topic := range []string{"allowed", "forbidden"} {
+		topic := topic
+		provider.Handle(topic, func(c Context) error {
+			handled <- topic
+			return c.OK()
+		})
+	}
+
+	_, err = consumer.Send(context.Background(), "allowed", &Message{})
+	assert.NoError(t, err)
+
+	_, err = consumer.Send(context.Background(), "forbidden", &Message{})
+	assert.True(t, errors.Is(err, ErrTopicForbidden))
+
+	assert.Len(t, handled, 1)
+	assert.Equal(t, "allowed", <-handled)
+}
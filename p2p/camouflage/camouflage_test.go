@@ -0,0 +1,146 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package camouflage
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTunnel_RelaysThroughServer(t *testing.T) {
+	server := startServer(t)
+
+	target, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	defer target.Close()
+
+	token, err := GenerateToken()
+	require.NoError(t, err)
+	release := server.Register(token, target.LocalAddr().(*net.UDPAddr))
+	defer release()
+
+	tunnel, err := Dial(context.Background(), serverAddr(server), ClientConfig{
+		ServerName:  server.ServerName(),
+		Fingerprint: server.Fingerprint(),
+		Token:       token,
+	})
+	require.NoError(t, err)
+	defer tunnel.Stop()
+	tunnel.Start()
+
+	app, err := net.DialUDP("udp4", nil, tunnel.LocalAddr())
+	require.NoError(t, err)
+	defer app.Close()
+
+	_, err = app.Write([]byte("handshake"))
+	require.NoError(t, err)
+
+	buf := make([]byte, 100)
+	target.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, from, err := target.ReadFromUDP(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "handshake", string(buf[:n]))
+
+	_, err = target.WriteToUDP([]byte("response"), from)
+	require.NoError(t, err)
+
+	app.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err = app.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "response", string(buf[:n]))
+}
+
+func TestDial_RejectsWrongFingerprint(t *testing.T) {
+	server := startServer(t)
+
+	token, _ := GenerateToken()
+	_, err := Dial(context.Background(), serverAddr(server), ClientConfig{
+		Fingerprint: make([]byte, 32),
+		Token:       token,
+	})
+	assert.Error(t, err)
+}
+
+func TestServer_HandsBootstrapConnections(t *testing.T) {
+	server := startServer(t)
+
+	received := make(chan []byte, 1)
+	server.HandleBootstrap(func(conn net.Conn) {
+		defer conn.Close()
+
+		packet, err := ReadFrame(conn, make([]byte, MaxPacketSize))
+		if err != nil {
+			return
+		}
+		received <- packet
+	})
+
+	conn, err := DialConn(context.Background(), serverAddr(server), ClientConfig{
+		ServerName:  server.ServerName(),
+		Fingerprint: server.Fingerprint(),
+		Token:       BootstrapToken,
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, WriteFrame(conn, []byte("exchange")))
+
+	select {
+	case packet := <-received:
+		assert.Equal(t, "exchange", string(packet))
+	case <-time.After(2 * time.Second):
+		t.Fatal("bootstrap connection was not handed over")
+	}
+}
+
+func TestServer_ServesFallbackToOtherClients(t *testing.T) {
+	server := startServer(t)
+
+	client := http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		Timeout:   5 * time.Second,
+	}
+	resp, err := client.Get("https://" + serverAddr(server) + "/")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "nginx", resp.Header.Get("Server"))
+}
+
+func startServer(t *testing.T) *Server {
+	server, err := NewServer(ServerConfig{Address: "127.0.0.1:0", ServerName: "example.com"})
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(server.Stop)
+	return server
+}
+
+func serverAddr(server *Server) string {
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(server.Port()))
+}
@@ -0,0 +1,186 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package camouflage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ClientConfig describes how to reach session relay on the provider camouflage server.
+type ClientConfig struct {
+	ServerName  string
+	Fingerprint []byte
+	Token       []byte
+}
+
+// Tunnel carries UDP packets sent to its loopback socket inside TLS connection
+// to the provider camouflage server.
+type Tunnel struct {
+	conn  net.Conn
+	local *net.UDPConn
+
+	mu   sync.Mutex
+	peer *net.UDPAddr
+
+	once sync.Once
+}
+
+// Dial connects to the camouflage server and authenticates the session.
+func Dial(ctx context.Context, address string, config ClientConfig) (*Tunnel, error) {
+	conn, err := DialConn(ctx, address, config)
+	if err != nil {
+		return nil, err
+	}
+
+	tunnel, err := NewTunnel(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tunnel, nil
+}
+
+// DialConn connects to the camouflage server and sends the token.
+// Server certificate is verified against the fingerprint only, as camouflage
+// certificates are usually self-signed or issued for an unrelated domain.
+func DialConn(ctx context.Context, address string, config ClientConfig) (net.Conn, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         config.ServerName,
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS12,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errors.New("no camouflage server certificate")
+			}
+			fingerprint := sha256.Sum256(rawCerts[0])
+			if !bytes.Equal(fingerprint[:], config.Fingerprint) {
+				return errors.New("camouflage server certificate fingerprint mismatch")
+			}
+			return nil
+		},
+	}
+
+	dialer := &tls.Dialer{Config: tlsConfig}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("could not dial camouflage server: %w", err)
+	}
+
+	if _, err := conn.Write(config.Token); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("could not send camouflage token: %w", err)
+	}
+	return conn, nil
+}
+
+// NewTunnel relays packets between a loopback socket and the framed connection.
+// Packets from the connection are delivered to the peer, which is learned from
+// the first local packet unless set in advance.
+func NewTunnel(conn net.Conn) (*Tunnel, error) {
+	local, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tunnel{conn: conn, local: local}, nil
+}
+
+// SetPeer sets the local socket packets from the connection are delivered to.
+func (t *Tunnel) SetPeer(addr *net.UDPAddr) {
+	t.mu.Lock()
+	t.peer = addr
+	t.mu.Unlock()
+}
+
+// LocalAddr returns loopback address packets should be sent to.
+func (t *Tunnel) LocalAddr() *net.UDPAddr {
+	return t.local.LocalAddr().(*net.UDPAddr)
+}
+
+// Start starts relaying packets in both directions.
+func (t *Tunnel) Start() {
+	go t.localReadLoop()
+	go t.remoteReadLoop()
+}
+
+// Stop closes the tunnel.
+func (t *Tunnel) Stop() {
+	t.once.Do(func() {
+		t.conn.Close()
+		t.local.Close()
+	})
+}
+
+func (t *Tunnel) localReadLoop() {
+	defer t.Stop()
+
+	buf := make([]byte, MaxPacketSize)
+	for {
+		n, addr, err := t.local.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+
+		t.mu.Lock()
+		t.peer = addr
+		t.mu.Unlock()
+
+		if err := WriteFrame(t.conn, buf[:n]); err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Error().Err(err).Msg("Write to camouflage tunnel failed")
+			}
+			return
+		}
+	}
+}
+
+func (t *Tunnel) remoteReadLoop() {
+	defer t.Stop()
+
+	buf := make([]byte, MaxPacketSize)
+	for {
+		packet, err := ReadFrame(t.conn, buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Error().Err(err).Msg("Read from camouflage tunnel failed")
+			}
+			return
+		}
+
+		t.mu.Lock()
+		peer := t.peer
+		t.mu.Unlock()
+		if peer == nil {
+			continue
+		}
+
+		if _, err := t.local.WriteToUDP(packet, peer); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package camouflage
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Capability advertises TLS camouflage support in service proposal.
+const Capability = "tls-camouflage"
+
+// TokenSize is the size of session token sent by client right after TLS handshake.
+const TokenSize = 16
+
+// Overhead is the approximate number of bytes added to each packet by framing and TLS records.
+const Overhead = 2 + 29
+
+// MaxPacketSize is the biggest packet which fits into a frame.
+const MaxPacketSize = 0xffff
+
+// BootstrapToken is sent instead of a session token by consumers which can not reach
+// the provider over the broker and UDP, such connections carry the p2p channel setup.
+var BootstrapToken = []byte("mysterium/p2p/v1")
+
+// GenerateToken generates random session token.
+func GenerateToken() ([]byte, error) {
+	token := make([]byte, TokenSize)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("could not generate camouflage token: %w", err)
+	}
+	return token, nil
+}
+
+// WriteFrame writes length prefixed packet.
+func WriteFrame(w io.Writer, packet []byte) error {
+	if len(packet) > MaxPacketSize {
+		return fmt.Errorf("packet is too big: %d", len(packet))
+	}
+
+	frame := make([]byte, 2+len(packet))
+	binary.BigEndian.PutUint16(frame, uint16(len(packet)))
+	copy(frame[2:], packet)
+	_, err := w.Write(frame)
+	return err
+}
+
+// ReadFrame reads length prefixed packet into the buffer.
+func ReadFrame(r io.Reader, buf []byte) ([]byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+
+	size := int(binary.BigEndian.Uint16(header[:]))
+	if size > len(buf) {
+		return nil, fmt.Errorf("frame is too big: %d", size)
+	}
+	if _, err := io.ReadFull(r, buf[:size]); err != nil {
+		return nil, err
+	}
+	return buf[:size], nil
+}
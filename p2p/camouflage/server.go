@@ -0,0 +1,389 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package camouflage
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/privacy"
+)
+
+const tokenReadTimeout = 10 * time.Second
+
+// ServerConfig describes TLS camouflage listener.
+type ServerConfig struct {
+	// Address to listen on, ports below 1024 require root.
+	Address string
+	// PublicPort is advertised to consumers when it differs from the listen port, e.g. 443 forwarded by a router.
+	PublicPort int
+	// CertFile and KeyFile point to camouflage certificate, self-signed one is generated when empty.
+	CertFile string
+	KeyFile  string
+	// ServerName is the SNI consumers send and the name in the self-signed certificate.
+	ServerName string
+	// FallbackURL is the site non-VPN clients are proxied to, empty serves a plain 404 page.
+	FallbackURL string
+}
+
+// Server accepts TLS connections on a HTTPS port and multiplexes VPN traffic inside:
+// connections starting with a registered session token are relayed to the local
+// UDP target of the session, everything else is served as ordinary HTTPS.
+type Server struct {
+	config      ServerConfig
+	certificate tls.Certificate
+	fingerprint []byte
+
+	mu        sync.Mutex
+	targets   map[string]*net.UDPAddr
+	bootstrap func(conn net.Conn)
+	listener  net.Listener
+	fallback  *fallbackListener
+	http      *http.Server
+}
+
+// NewServer creates TLS camouflage server.
+func NewServer(config ServerConfig) (*Server, error) {
+	var certificate tls.Certificate
+	var err error
+	if config.CertFile != "" || config.KeyFile != "" {
+		certificate, err = tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+	} else {
+		certificate, err = selfSignedCertificate(config.ServerName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not load camouflage certificate: %w", err)
+	}
+
+	handler, err := fallbackHandler(config.FallbackURL)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint := sha256.Sum256(certificate.Certificate[0])
+	return &Server{
+		config:      config,
+		certificate: certificate,
+		fingerprint: fingerprint[:],
+		targets:     make(map[string]*net.UDPAddr),
+		http: &http.Server{
+			Handler:           handler,
+			ReadHeaderTimeout: 30 * time.Second,
+		},
+	}, nil
+}
+
+// Start starts accepting connections.
+func (s *Server) Start() error {
+	listener, err := tls.Listen("tcp", s.config.Address, &tls.Config{
+		Certificates: []tls.Certificate{s.certificate},
+		MinVersion:   tls.VersionTLS12,
+	})
+	if err != nil {
+		return fmt.Errorf("could not listen for TLS camouflage on %s: %w", s.config.Address, err)
+	}
+
+	fallback := newFallbackListener(listener.Addr())
+	s.mu.Lock()
+	s.listener = listener
+	s.fallback = fallback
+	s.mu.Unlock()
+
+	go s.http.Serve(fallback)
+	go s.acceptLoop(listener, fallback)
+
+	log.Info().Msgf("TLS camouflage listening on %s", listener.Addr())
+	return nil
+}
+
+// Stop stops accepting connections.
+func (s *Server) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener != nil {
+		s.listener.Close()
+		s.http.Close()
+		s.listener = nil
+	}
+}
+
+// Port returns port the server listens on.
+func (s *Server) Port() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return 0
+	}
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// PublicPort returns port consumers should connect to.
+func (s *Server) PublicPort() int {
+	if s.config.PublicPort != 0 {
+		return s.config.PublicPort
+	}
+	return s.Port()
+}
+
+// ServerName returns SNI consumers should use.
+func (s *Server) ServerName() string {
+	return s.config.ServerName
+}
+
+// Fingerprint returns SHA-256 fingerprint of the server certificate for consumers to pin.
+func (s *Server) Fingerprint() []byte {
+	return s.fingerprint
+}
+
+// Register routes connections presenting the token to the UDP target.
+// Returned function removes the registration.
+func (s *Server) Register(token []byte, target *net.UDPAddr) func() {
+	s.mu.Lock()
+	s.targets[string(token)] = target
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		delete(s.targets, string(token))
+		s.mu.Unlock()
+	}
+}
+
+// HandleBootstrap routes connections presenting BootstrapToken to the handler.
+func (s *Server) HandleBootstrap(handler func(conn net.Conn)) {
+	s.mu.Lock()
+	s.bootstrap = handler
+	s.mu.Unlock()
+}
+
+func (s *Server) bootstrapHandler() func(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.bootstrap
+}
+
+func (s *Server) target(token []byte) (*net.UDPAddr, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target, ok := s.targets[string(token)]
+	return target, ok
+}
+
+func (s *Server) acceptLoop(listener net.Listener, fallback *fallbackListener) {
+	defer fallback.Close()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Error().Err(err).Msg("TLS camouflage accept failed")
+			}
+			return
+		}
+
+		go s.handle(conn, fallback)
+	}
+}
+
+func (s *Server) handle(conn net.Conn, fallback *fallbackListener) {
+	token := make([]byte, TokenSize)
+	conn.SetReadDeadline(time.Now().Add(tokenReadTimeout))
+	n, _ := io.ReadFull(conn, token)
+	conn.SetReadDeadline(time.Time{})
+
+	if bootstrap := s.bootstrapHandler(); bootstrap != nil && bytes.Equal(token[:n], BootstrapToken) {
+		log.Debug().Msgf("Bootstrapping p2p channel over TLS camouflage connection from %s", privacy.ConsumerAddr(conn.RemoteAddr().String()))
+		bootstrap(conn)
+		return
+	}
+
+	target, ok := s.target(token[:n])
+	if !ok || n != TokenSize {
+		fallback.push(&prefixConn{Conn: conn, prefix: token[:n]})
+		return
+	}
+
+	udp, err := net.DialUDP("udp4", nil, target)
+	if err != nil {
+		log.Error().Err(err).Msg("Could not connect TLS camouflage session to its target")
+		conn.Close()
+		return
+	}
+
+	log.Debug().Msgf("Relaying TLS camouflage connection from %s", privacy.ConsumerAddr(conn.RemoteAddr().String()))
+	go func() {
+		defer conn.Close()
+		defer udp.Close()
+
+		buf := make([]byte, MaxPacketSize)
+		for {
+			packet, err := ReadFrame(conn, buf)
+			if err != nil {
+				return
+			}
+			if _, err := udp.Write(packet); err != nil {
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer conn.Close()
+		defer udp.Close()
+
+		buf := make([]byte, MaxPacketSize)
+		for {
+			n, err := udp.Read(buf)
+			if err != nil {
+				return
+			}
+			if err := WriteFrame(conn, buf[:n]); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+func fallbackHandler(fallbackURL string) (http.Handler, error) {
+	if fallbackURL == "" {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Server", "nginx")
+			http.NotFound(w, r)
+		}), nil
+	}
+
+	target, err := url.Parse(fallbackURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid camouflage fallback URL: %w", err)
+	}
+	return httputil.NewSingleHostReverseProxy(target), nil
+}
+
+func selfSignedCertificate(serverName string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: serverName},
+		NotBefore:             time.Now().Add(-24 * time.Hour),
+		NotAfter:              time.Now().AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	if serverName != "" {
+		template.DNSNames = []string{serverName}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+// prefixConn replays bytes already consumed while looking for a session token.
+type prefixConn struct {
+	net.Conn
+	prefix []byte
+}
+
+func (c *prefixConn) Read(b []byte) (int, error) {
+	if len(c.prefix) > 0 {
+		n := copy(b, c.prefix)
+		c.prefix = c.prefix[n:]
+		return n, nil
+	}
+	return c.Conn.Read(b)
+}
+
+// fallbackListener hands non-VPN connections over to the HTTP server.
+type fallbackListener struct {
+	addr  net.Addr
+	conns chan net.Conn
+	once  sync.Once
+	done  chan struct{}
+}
+
+func newFallbackListener(addr net.Addr) *fallbackListener {
+	return &fallbackListener{
+		addr:  addr,
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+func (l *fallbackListener) push(conn net.Conn) {
+	select {
+	case l.conns <- conn:
+	case <-l.done:
+		conn.Close()
+	}
+}
+
+// Accept waits for and returns the next connection to the listener.
+func (l *fallbackListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close closes the listener.
+func (l *fallbackListener) Close() error {
+	l.once.Do(func() { close(l.done) })
+	return nil
+}
+
+// Addr returns the listener's network address.
+func (l *fallbackListener) Addr() net.Addr {
+	return l.addr
+}
@@ -19,6 +19,7 @@ package p2p
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"net"
@@ -31,7 +32,10 @@ import (
 	kcp "github.com/xtaci/kcp-go/v5"
 	"golang.org/x/crypto/nacl/box"
 
+	"github.com/mysteriumnetwork/node/crash"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p/compat"
+	"github.com/mysteriumnetwork/node/p2p/obfs"
 	"github.com/mysteriumnetwork/node/router"
 	"github.com/mysteriumnetwork/node/trace"
 )
@@ -135,6 +139,9 @@ type transport struct {
 	// this is needed to detect remote peer address changes as we can simply use conn.ReadFromUDP and
 	// get updated peer address.
 	proxyConn *net.UDPConn
+
+	// obfuscator wraps packets exchanged with remote peer, nil if peer doesn't support obfuscation.
+	obfuscator obfs.Obfuscator
 }
 
 // channel implements Channel interface.
@@ -158,6 +165,9 @@ type channel struct {
 	// topicHandlers is similar to HTTP Server handlers and is responsible for handling peer requests.
 	topicHandlers map[string]HandlerFunc
 
+	// acl restricts topics the peer may call, nil if all registered topics are allowed.
+	acl *peerACL
+
 	// streams is temp map to create request/response pipelines. Each stream is created on send and contains
 	// channel to which receive loop should eventually send peer reply.
 	streams      map[uint64]*stream
@@ -179,13 +189,16 @@ type channel struct {
 	// upnpPortsRelease should be called to close mapped upnp ports when channel is closed.
 	upnpPortsRelease func()
 
+	// closeStream should be called to close the camouflage stream carrying the channel.
+	closeStream func()
+
 	// stop is used to stop all running goroutines.
 	stop chan struct{}
 }
 
 // newChannel creates new p2p channel with initialized crypto primitives for data encryption
-// and starts listening for connections.
-func newChannel(remoteConn *net.UDPConn, privateKey PrivateKey, peerPubKey PublicKey, peerCompatibility int) (*channel, error) {
+// and starts listening for connections. Capabilities are the ones negotiated with the peer.
+func newChannel(remoteConn *net.UDPConn, privateKey PrivateKey, peerPubKey PublicKey, capabilities compat.Capabilities) (*channel, error) {
 	peerAddr := remoteConn.RemoteAddr().(*net.UDPAddr)
 	localAddr := remoteConn.LocalAddr().(*net.UDPAddr)
 	remoteConn, err := reopenConn(remoteConn)
@@ -211,9 +224,18 @@ func newChannel(remoteConn *net.UDPConn, privateKey PrivateKey, peerPubKey Publi
 
 	log.Debug().Msgf("Creating p2p channel with local addr: %s, UDP session addr: %s, proxy addr: %s, remote peer addr: x.x.x.x:%d", localAddr.String(), udpSession.LocalAddr().String(), proxyConn.LocalAddr().String(), peerAddr.Port)
 
+	var obfuscator obfs.Obfuscator
+	if capabilities.Has(compat.CapabilityObfs) {
+		obfuscator, err = newObfuscator(privateKey, peerPubKey)
+		if err != nil {
+			return nil, fmt.Errorf("could not create obfuscator: %w", err)
+		}
+	}
+
 	tr := transport{
-		wireReader: newCompatibleWireReader(udpSession, peerCompatibility),
-		wireWriter: newCompatibleWireWriter(udpSession, peerCompatibility),
+		obfuscator: obfuscator,
+		wireReader: newCompatibleWireReader(udpSession, capabilities),
+		wireWriter: newCompatibleWireWriter(udpSession, capabilities),
 		session:    udpSession,
 		remoteConn: remoteConn,
 		localConn:  localConn,
@@ -276,6 +298,15 @@ func (c *channel) remoteReadLoop(tr *transport) {
 			return
 		}
 
+		packet := buf[:n]
+		if tr.obfuscator != nil {
+			packet, err = tr.obfuscator.Deobfuscate(packet)
+			if err != nil {
+				log.Trace().Err(err).Msg("Dropping packet which could not be deobfuscated")
+				continue
+			}
+		}
+
 		// Check if peer port changed.
 		if addr, ok := addr.(*net.UDPAddr); ok {
 			if addr.IP.Equal(latestPeerAddr.IP) && addr.Port != latestPeerAddr.Port {
@@ -285,7 +316,7 @@ func (c *channel) remoteReadLoop(tr *transport) {
 			}
 		}
 
-		_, err = tr.proxyConn.WriteToUDP(buf[:n], c.localSessionAddr)
+		_, err = tr.proxyConn.WriteToUDP(packet, c.localSessionAddr)
 		if err != nil {
 			if !errNetClose(err) {
 				log.Error().Err(err).Msg("Write to local udp session failed")
@@ -300,6 +331,10 @@ func (c *channel) remoteReadLoop(tr *transport) {
 // Packets to proxy conn are written by local KCP UDP session from localSendLoop.
 func (c *channel) remoteSendLoop(tr *transport) {
 	buf := make([]byte, mtuLimit)
+	var wire []byte
+	if tr.obfuscator != nil {
+		wire = make([]byte, 0, mtuLimit+tr.obfuscator.Overhead())
+	}
 
 	for {
 		select {
@@ -317,7 +352,16 @@ func (c *channel) remoteSendLoop(tr *transport) {
 			return
 		}
 
-		_, err = tr.remoteConn.WriteToUDP(buf[:n], c.peer.addr())
+		packet := buf[:n]
+		if tr.obfuscator != nil {
+			packet, err = tr.obfuscator.Obfuscate(wire[:0], packet)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to obfuscate packet")
+				continue
+			}
+		}
+
+		_, err = tr.remoteConn.WriteToUDP(packet, c.peer.addr())
 		if err != nil {
 			if !errNetClose(err) {
 				log.Error().Err(err).Msgf("Write to remote peer conn failed")
@@ -352,10 +396,10 @@ func (c *channel) localReadLoop(tr *transport) {
 		// If message contains topic it means that peer is making a request
 		// and waits for response.
 		if msg.topic != "" {
-			go c.handleRequest(&msg)
+			crash.Go("p2p/request "+msg.topic, func() { c.handleRequest(&msg) })
 		} else {
 			// In other case we treat it as a reply for peer to our request.
-			go c.handleReply(&msg)
+			crash.Go("p2p/reply", func() { c.handleReply(&msg) })
 		}
 	}
 }
@@ -400,11 +444,21 @@ func (c *channel) handleReply(msg *transportMsg) {
 func (c *channel) handleRequest(msg *transportMsg) {
 	c.mu.RLock()
 	handler, ok := c.topicHandlers[msg.topic]
+	acl := c.acl
 	c.mu.RUnlock()
 
 	var resMsg transportMsg
 	resMsg.id = msg.id
 
+	if err := acl.check(msg.topic); err != nil {
+		log.Warn().Err(err).Msgf("Rejected request from %s", c.peerID.Address)
+		resMsg.statusCode = statusCodeTopicForbiddenErr
+		resMsg.msg = err.Error()
+		resMsg.data = []byte(err.Error())
+		c.sendQueue <- &resMsg
+		return
+	}
+
 	if !ok {
 		resMsg.statusCode = statusCodeHandlerNotFoundErr
 		errMsg := fmt.Sprintf("handler %q not found", msg.topic)
@@ -461,6 +515,10 @@ func (c *channel) Close() error {
 			c.upnpPortsRelease()
 		}
 
+		if c.closeStream != nil {
+			c.closeStream()
+		}
+
 		if err := c.tr.localConn.Close(); err != nil {
 			closeErr = fmt.Errorf("could not close remote conn: %w", err)
 		}
@@ -537,6 +595,9 @@ func (c *channel) sendRequest(ctx context.Context, topic string, m *Message) (*M
 			if res.statusCode == statusCodeHandlerNotFoundErr {
 				return nil, fmt.Errorf("%s: %w", string(res.data), ErrHandlerNotFound)
 			}
+			if res.statusCode == statusCodeTopicForbiddenErr {
+				return nil, fmt.Errorf("%s: %w", string(res.data), ErrTopicForbidden)
+			}
 			return nil, fmt.Errorf("peer error: %w", errors.New(res.msg))
 		}
 		return &Message{Data: res.data}, nil
@@ -582,6 +643,13 @@ func (c *channel) setPeerID(id identity.Identity) {
 	c.peerID = id
 }
 
+func (c *channel) setACL(acl *peerACL) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.acl = acl
+}
+
 func (c *channel) setUpnpPortsRelease(release func()) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -589,6 +657,13 @@ func (c *channel) setUpnpPortsRelease(release func()) {
 	c.upnpPortsRelease = release
 }
 
+func (c *channel) setCloseStream(closeStream func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closeStream = closeStream
+}
+
 func reopenConn(conn *net.UDPConn) (*net.UDPConn, error) {
 	// conn first must be closed to prevent use of WriteTo with pre-connected connection error.
 	conn.Close()
@@ -626,6 +701,15 @@ func listenUDPSession(proxyAddr net.Addr, privateKey PrivateKey, peerPubKey Publ
 	return sess, localConn, nil
 }
 
+// newObfuscator creates packet obfuscator keyed by the hash of the peers shared key,
+// so that obfuscation layer doesn't reuse the KCP encryption key.
+func newObfuscator(privateKey PrivateKey, peerPublicKey PublicKey) (obfs.Obfuscator, error) {
+	var sharedKey [32]byte
+	box.Precompute(&sharedKey, (*[32]byte)(&peerPublicKey), (*[32]byte)(&privateKey))
+	key := sha256.Sum256(append([]byte("p2p-obfs"), sharedKey[:]...))
+	return obfs.New(obfs.MethodXChaCha, key[:])
+}
+
 func newBlockCrypt(privateKey PrivateKey, peerPublicKey PublicKey) (kcp.BlockCrypt, error) {
 	// Compute shared key. Nonce for each message will be added inside kcp salsa block crypt.
 	var sharedKey [32]byte
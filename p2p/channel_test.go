@@ -29,6 +29,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/p2p/compat"
 	"github.com/mysteriumnetwork/node/pb"
 )
 
@@ -178,6 +179,24 @@ func TestChannelFullCommunicationFlow(t *testing.T) {
 	})
 }
 
+func TestChannel_Obfuscated(t *testing.T) {
+	provider, consumer, err := createTestChannelsWithCompatibility(compat.Compatibility)
+	require.NoError(t, err)
+	defer provider.Close()
+	defer consumer.Close()
+	assert.NotNil(t, provider.(*channel).tr.obfuscator)
+
+	provider.Handle("ping", func(c Context) error {
+		return c.OkWithReply(&Message{Data: []byte("pong")})
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := consumer.Send(ctx, "ping", &Message{Data: []byte("ping")})
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(res.Data))
+}
+
 func TestChannel_Send_Timeout(t *testing.T) {
 	provider, consumer, err := createTestChannels()
 	require.NoError(t, err)
@@ -306,6 +325,10 @@ func reopenChannelWithNewLocalAddr(c *channel) (*channel, error) {
 }
 
 func createTestChannels() (Channel, Channel, error) {
+	return createTestChannelsWithCompatibility(1)
+}
+
+func createTestChannelsWithCompatibility(compatibility int) (Channel, Channel, error) {
 	ports, err := acquirePorts(2)
 	if err != nil {
 		return nil, nil, err
@@ -332,13 +355,13 @@ func createTestChannels() (Channel, Channel, error) {
 		return nil, nil, err
 	}
 
-	provider, err := newChannel(providerConn, providerPrivateKey, consumerPublicKey, 1)
+	provider, err := newChannel(providerConn, providerPrivateKey, consumerPublicKey, compat.LegacyCapabilities(compatibility))
 	if err != nil {
 		return nil, nil, err
 	}
 	provider.launchReadSendLoops()
 
-	consumer, err := newChannel(consumerConn, consumerPrivateKey, providerPublicKey, 1)
+	consumer, err := newChannel(consumerConn, consumerPrivateKey, providerPublicKey, compat.LegacyCapabilities(compatibility))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -19,16 +19,16 @@ package p2p
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"net"
-	"net/url"
 
 	"google.golang.org/protobuf/proto"
 
-	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p/compat"
 	"github.com/mysteriumnetwork/node/pb"
 )
 
@@ -37,16 +37,12 @@ const (
 	consumerInitialTTL = 128
 )
 
-type brokerConnector interface {
-	Connect(serverURIs ...*url.URL) (nats.Connection, error)
-}
-
 type natConsumerPinger interface {
 	PingProviderPeer(ctx context.Context, localIP, remoteIP string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error)
 }
 
 type natProviderPinger interface {
-	PingConsumerPeer(ctx context.Context, id string, ip string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error)
+	PingConsumerPeer(ctx context.Context, id string, localIP, ip string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error)
 }
 
 func configExchangeSubject(providerID identity.Identity, serviceType string) string {
@@ -73,17 +69,25 @@ func acquireLocalPorts(portPool port.ServicePortSupplier, n int) ([]int, error)
 	return res, nil
 }
 
-// packSignedMsg marshals, signs and returns ready to send bytes.
-func packSignedMsg(signer identity.SignerFactory, signerID identity.Identity, msg *pb.P2PConfigExchangeMsg) ([]byte, error) {
+// packSignedMsg marshals, signs and returns ready to send bytes. If recipient key is given,
+// message is sealed to it before signing, so that brokers can't read exchanged configs.
+func packSignedMsg(signer identity.SignerFactory, signerID identity.Identity, msg *pb.P2PConfigExchangeMsg, recipientKey *ecdsa.PublicKey) ([]byte, error) {
 	protoBytes, err := proto.Marshal(msg)
 	if err != nil {
 		return nil, err
 	}
+	sealed := recipientKey != nil
+	if sealed {
+		protoBytes, err = identity.Seal(recipientKey, protoBytes)
+		if err != nil {
+			return nil, fmt.Errorf("could not seal message: %w", err)
+		}
+	}
 	signature, err := signer(signerID).Sign(protoBytes)
 	if err != nil {
 		return nil, err
 	}
-	signedMsg := &pb.P2PSignedMsg{Data: protoBytes, Signature: signature.Bytes()}
+	signedMsg := &pb.P2PSignedMsg{Data: protoBytes, Signature: signature.Bytes(), Sealed: sealed}
 	signedMsgProtoBytes, err := proto.Marshal(signedMsg)
 	if err != nil {
 		return nil, err
@@ -104,6 +108,31 @@ func unpackSignedMsg(verifier identity.Verifier, b []byte) (*pb.P2PSignedMsg, id
 	return &signedMsg, id, nil
 }
 
+// openExchangeMsg unmarshals exchange message from signed message, unsealing it first if needed.
+func openExchangeMsg(decrypter identity.Decrypter, signedMsg *pb.P2PSignedMsg) (*pb.P2PConfigExchangeMsg, error) {
+	data := signedMsg.Data
+	if signedMsg.Sealed {
+		var err error
+		data, err = decrypter.Decrypt(data)
+		if err != nil {
+			return nil, fmt.Errorf("could not unseal message: %w", err)
+		}
+	}
+	var msg pb.P2PConfigExchangeMsg
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// sealingKey returns identity key of the signed message sender if peer accepts sealed messages.
+func sealingKey(signedMsg *pb.P2PSignedMsg, peerCapabilities compat.Capabilities) (*ecdsa.PublicKey, error) {
+	if !peerCapabilities.Has(compat.CapabilitySealedExchange) {
+		return nil, nil
+	}
+	return identity.RecoverPublicKey(signedMsg.Data, identity.SignatureBytes(signedMsg.Signature))
+}
+
 // encryptConnConfigMsg encrypts proto message and returns bytes.
 func encryptConnConfigMsg(msg *pb.P2PConnectConfig, privateKey PrivateKey, peerPubKey PublicKey) ([]byte, error) {
 	protoBytes, err := proto.Marshal(msg)
@@ -130,6 +159,15 @@ func decryptConnConfigMsg(ciphertext []byte, privateKey PrivateKey, peerPubKey P
 	return &peerProtoConnectConfig, nil
 }
 
+// negotiateCapabilities returns capabilities shared with the peer. Legacy peers
+// don't announce capabilities, so they are derived from the compatibility level.
+func negotiateCapabilities(version uint32, peerCapabilities compat.Capabilities, peerCompatibility int) compat.Capabilities {
+	if version == 0 {
+		peerCapabilities = compat.LegacyCapabilities(peerCompatibility)
+	}
+	return compat.Supported.Negotiate(peerCapabilities)
+}
+
 func int32ToIntSlice(arr []int32) []int {
 	var res []int
 	for _, v := range arr {
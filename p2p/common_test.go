@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p/compat"
+	"github.com/mysteriumnetwork/node/pb"
+)
+
+func TestSignedMsg_SealedRoundTrip(t *testing.T) {
+	ks := identity.NewMockKeystore()
+	consumerAcc, err := ks.NewAccount("")
+	require.NoError(t, err)
+	require.NoError(t, ks.Unlock(consumerAcc, ""))
+	providerAcc, err := ks.NewAccount("")
+	require.NoError(t, err)
+	require.NoError(t, ks.Unlock(providerAcc, ""))
+
+	consumerID := identity.FromAddress(consumerAcc.Address.Hex())
+	providerID := identity.FromAddress(providerAcc.Address.Hex())
+	signer := func(id identity.Identity) identity.Signer { return identity.NewSigner(ks, id) }
+	decrypter := func(id identity.Identity) identity.Decrypter { return identity.NewDecrypter(ks, id) }
+
+	// Consumer doesn't know provider key yet, so initial exchange goes signed only.
+	packed, err := packSignedMsg(signer, consumerID, &pb.P2PConfigExchangeMsg{
+		PublicKey:    "consumer-key",
+		Capabilities: uint64(compat.Supported),
+	}, nil)
+	require.NoError(t, err)
+	signedMsg, peerID, err := unpackSignedMsg(identity.NewVerifierSigned(), packed)
+	require.NoError(t, err)
+	assert.Equal(t, consumerID, peerID)
+	assert.False(t, signedMsg.Sealed)
+	msg, err := openExchangeMsg(decrypter(providerID), signedMsg)
+	require.NoError(t, err)
+
+	// Provider seals reply to the consumer identity key recovered from the signature.
+	consumerKey, err := sealingKey(signedMsg, compat.Capabilities(msg.Capabilities))
+	require.NoError(t, err)
+	require.NotNil(t, consumerKey)
+	packed, err = packSignedMsg(signer, providerID, &pb.P2PConfigExchangeMsg{PublicKey: "provider-key"}, consumerKey)
+	require.NoError(t, err)
+	assert.NotContains(t, string(packed), "provider-key")
+
+	signedMsg, peerID, err = unpackSignedMsg(identity.NewVerifierIdentity(providerID), packed)
+	require.NoError(t, err)
+	assert.Equal(t, providerID, peerID)
+	assert.True(t, signedMsg.Sealed)
+
+	_, err = openExchangeMsg(decrypter(providerID), signedMsg)
+	assert.Error(t, err, "only recipient should be able to unseal")
+	msg, err = openExchangeMsg(decrypter(consumerID), signedMsg)
+	require.NoError(t, err)
+	assert.Equal(t, "provider-key", msg.PublicKey)
+}
+
+func TestSealingKey_LegacyPeer(t *testing.T) {
+	key, err := sealingKey(&pb.P2PSignedMsg{}, compat.LegacyCapabilities(compat.Compatibility))
+	assert.NoError(t, err)
+	assert.Nil(t, key)
+}
@@ -18,10 +18,15 @@
 package compat
 
 // Compatibility level of P2P protocol
-const Compatibility = 2
+const Compatibility = 3
 
 // FeaturePBP2P reports whether peer supports new wire format
 // for transportMsg envelopes
 func FeaturePBP2P(peerCompatibility int) bool {
 	return peerCompatibility >= 1
 }
+
+// FeatureObfs reports whether peer obfuscates p2p channel packets
+func FeatureObfs(peerCompatibility int) bool {
+	return peerCompatibility >= 3
+}
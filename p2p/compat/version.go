@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package compat
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Version of the p2p broker exchange protocol.
+// Peers which do not announce a version are treated as version 0 (legacy).
+const Version uint32 = 1
+
+// MinVersion is the oldest broker exchange protocol version this node still speaks.
+const MinVersion uint32 = 0
+
+// Capabilities is a bitmap of optional p2p features supported by a peer.
+type Capabilities uint64
+
+const (
+	// CapabilityPBWire means peer uses protobuf wire format for transportMsg envelopes.
+	CapabilityPBWire Capabilities = 1 << iota
+	// CapabilityObfs means peer obfuscates p2p channel packets.
+	CapabilityObfs
+	// CapabilitySealedExchange means peer accepts broker exchange messages sealed to its identity key.
+	CapabilitySealedExchange
+)
+
+// Supported is the set of capabilities this node announces.
+const Supported = CapabilityPBWire | CapabilityObfs | CapabilitySealedExchange
+
+// Has reports whether all of the given capabilities are set.
+func (c Capabilities) Has(capabilities Capabilities) bool {
+	return c&capabilities == capabilities
+}
+
+// Negotiate returns capabilities supported by both this node and the peer.
+func (c Capabilities) Negotiate(peer Capabilities) Capabilities {
+	return c & peer
+}
+
+// LegacyCapabilities derives capabilities of a legacy peer from its compatibility level.
+func LegacyCapabilities(peerCompatibility int) Capabilities {
+	var c Capabilities
+	if FeaturePBP2P(peerCompatibility) {
+		c |= CapabilityPBWire
+	}
+	if FeatureObfs(peerCompatibility) {
+		c |= CapabilityObfs
+	}
+	return c
+}
+
+// ErrUnsupportedVersion is matched by all UnsupportedVersionError values with errors.Is.
+var ErrUnsupportedVersion = errors.New("unsupported p2p protocol version")
+
+// UnsupportedVersionError is returned when peers have no exchange protocol version in common.
+type UnsupportedVersionError struct {
+	PeerVersion    uint32
+	PeerMinVersion uint32
+}
+
+// Error returns error message.
+func (e *UnsupportedVersionError) Error() string {
+	return fmt.Sprintf("%s: peer speaks %d-%d, we speak %d-%d",
+		ErrUnsupportedVersion, e.PeerMinVersion, e.PeerVersion, MinVersion, Version)
+}
+
+// Is makes UnsupportedVersionError match ErrUnsupportedVersion.
+func (e *UnsupportedVersionError) Is(target error) bool {
+	return target == ErrUnsupportedVersion
+}
+
+// NegotiateVersion returns the highest exchange protocol version both this node
+// and the peer speak. A peer announcing version 0 is legacy and only talks version 0.
+func NegotiateVersion(peerVersion, peerMinVersion uint32) (uint32, error) {
+	if peerVersion == 0 {
+		peerMinVersion = 0
+	}
+
+	version := Version
+	if peerVersion < version {
+		version = peerVersion
+	}
+	if version < MinVersion || version < peerMinVersion || peerMinVersion > peerVersion {
+		return 0, &UnsupportedVersionError{PeerVersion: peerVersion, PeerMinVersion: peerMinVersion}
+	}
+	return version, nil
+}
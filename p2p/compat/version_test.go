@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package compat
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateVersion(t *testing.T) {
+	tests := []struct {
+		name                        string
+		peerVersion, peerMinVersion uint32
+		want                        uint32
+		wantErr                     bool
+	}{
+		{name: "legacy peer", peerVersion: 0, peerMinVersion: 0, want: 0},
+		{name: "same version", peerVersion: Version, peerMinVersion: MinVersion, want: Version},
+		{name: "newer peer still speaking ours", peerVersion: Version + 1, peerMinVersion: Version, want: Version},
+		{name: "newer peer dropped ours", peerVersion: Version + 2, peerMinVersion: Version + 1, wantErr: true},
+		{name: "invalid range", peerVersion: 1, peerMinVersion: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NegotiateVersion(tt.peerVersion, tt.peerMinVersion)
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrUnsupportedVersion))
+				var versionErr *UnsupportedVersionError
+				assert.True(t, errors.As(err, &versionErr))
+				assert.Equal(t, tt.peerVersion, versionErr.PeerVersion)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	assert.Equal(t, Capabilities(0), LegacyCapabilities(0))
+	assert.Equal(t, CapabilityPBWire, LegacyCapabilities(2))
+	assert.Equal(t, CapabilityPBWire|CapabilityObfs, LegacyCapabilities(Compatibility))
+
+	negotiated := Supported.Negotiate(CapabilityObfs | 1<<10)
+	assert.True(t, negotiated.Has(CapabilityObfs))
+	assert.False(t, negotiated.Has(CapabilityPBWire))
+	assert.False(t, negotiated.Has(CapabilityObfs|CapabilityPBWire))
+}
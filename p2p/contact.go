@@ -31,21 +31,45 @@ var ErrContactNotFound = errors.New("p2p contact not found")
 const (
 	// ContactTypeV1 is p2p contact type.
 	ContactTypeV1 = "nats/p2p/v1"
+	// ContactTypeCamouflageV1 is p2p contact type for channel setup over TLS camouflage.
+	ContactTypeCamouflageV1 = "tls/p2p/v1"
 )
 
 // ContactDefinition represents p2p contact which contains NATS broker addresses for connection.
 type ContactDefinition struct {
 	BrokerAddresses []string `json:"broker_addresses"`
+
+	// Camouflage is filled from the TLS camouflage contact when provider advertises one.
+	Camouflage *CamouflageContact `json:"-"`
+}
+
+// CamouflageContact represents provider TLS camouflage server, which accepts p2p
+// channel setup on networks where only HTTPS gets through.
+type CamouflageContact struct {
+	Host        string `json:"host"`
+	Port        int    `json:"port"`
+	ServerName  string `json:"server_name"`
+	Fingerprint []byte `json:"fingerprint"`
 }
 
 // ParseContact tries to parse p2p contact from given contacts list.
 func ParseContact(contacts market.ContactList) (ContactDefinition, error) {
+	var camouflage *CamouflageContact
+	for _, c := range contacts {
+		if c.Type == ContactTypeCamouflageV1 {
+			if def, ok := c.Definition.(CamouflageContact); ok {
+				camouflage = &def
+			}
+		}
+	}
+
 	for _, c := range contacts {
 		if c.Type == ContactTypeV1 {
 			def, ok := c.Definition.(ContactDefinition)
 			if !ok {
 				return ContactDefinition{}, fmt.Errorf("invalid p2p contact definition: %#v", c.Definition)
 			}
+			def.Camouflage = camouflage
 			return def, nil
 		}
 	}
@@ -62,4 +86,12 @@ func RegisterContactUnserializer() {
 			return contact, err
 		},
 	)
+	market.RegisterContactUnserializer(
+		ContactTypeCamouflageV1,
+		func(rawDefinition *json.RawMessage) (market.ContactDefinition, error) {
+			var contact CamouflageContact
+			err := json.Unmarshal(*rawDefinition, &contact)
+			return contact, err
+		},
+	)
 }
@@ -25,10 +25,10 @@ import (
 	"sync"
 	"time"
 
-	nats_lib "github.com/nats-io/nats.go"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/mysteriumnetwork/node/communication/broker"
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/port"
@@ -44,6 +44,13 @@ import (
 
 const maxBrokerConnectAttempts = 25
 
+// brokerConnectBackoff spaces broker connect attempts while network routes are being reconfigured.
+var brokerConnectBackoff = nats.Backoff{
+	Initial: 250 * time.Millisecond,
+	Max:     2 * time.Second,
+	Jitter:  0.5,
+}
+
 // Dialer knows how to exchange p2p keys and encrypted configuration and creates ready to use p2p channels.
 type Dialer interface {
 	// Dial exchanges p2p configuration via broker, performs NAT pinging if needed
@@ -52,11 +59,12 @@ type Dialer interface {
 }
 
 // NewDialer creates new p2p communication dialer which is used on consumer side.
-func NewDialer(broker brokerConnector, signer identity.SignerFactory, verifierFactory identity.VerifierFactory, ipResolver ip.Resolver, portPool port.ServicePortSupplier, eventBus eventbus.EventBus) Dialer {
+func NewDialer(brokerConnector broker.Connector, signer identity.SignerFactory, decrypter identity.DecrypterFactory, verifierFactory identity.VerifierFactory, ipResolver ip.Resolver, portPool port.ServicePortSupplier, eventBus eventbus.EventBus) Dialer {
 	return &dialer{
-		broker:          broker,
+		broker:          brokerConnector,
 		ipResolver:      ipResolver,
 		signer:          signer,
+		decrypter:       decrypter,
 		verifierFactory: verifierFactory,
 		portPool:        portPool,
 		consumerPinger:  traversal.NewPinger(traversal.DefaultPingConfig(), eventbus.New()),
@@ -64,12 +72,16 @@ func NewDialer(broker brokerConnector, signer identity.SignerFactory, verifierFa
 	}
 }
 
+// exchangeRequest sends signed exchange message to the subject and returns the reply.
+type exchangeRequest func(ctx context.Context, subject string, msg []byte) ([]byte, error)
+
 // dialer implements Dialer interface.
 type dialer struct {
 	portPool        port.ServicePortSupplier
-	broker          brokerConnector
+	broker          broker.Connector
 	consumerPinger  natConsumerPinger
 	signer          identity.SignerFactory
+	decrypter       identity.DecrypterFactory
 	verifierFactory identity.VerifierFactory
 	ipResolver      ip.Resolver
 	eventBus        eventbus.EventBus
@@ -78,6 +90,10 @@ type dialer struct {
 // Dial exchanges p2p configuration via broker, performs NAT pinging if needed
 // and create p2p channel which is ready for communication.
 func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, contactDef ContactDefinition, tracer *trace.Tracer) (Channel, error) {
+	if useCamouflage(contactDef) {
+		return m.dialStream(ctx, consumerID, providerID, serviceType, *contactDef.Camouflage, tracer)
+	}
+
 	config := &p2pConnectConfig{tracer: tracer}
 
 	// Send initial exchange with signed consumer public key.
@@ -89,9 +105,9 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 
 	peerReady := make(chan struct{})
 	var once sync.Once
-	_, err = brokerConn.Subscribe(channelHandlersReadySubject(providerID, serviceType), func(msg *nats_lib.Msg) {
+	_, err = brokerConn.Subscribe(channelHandlersReadySubject(providerID, serviceType), func(msg *broker.Msg) {
 		defer once.Do(func() { close(peerReady) })
-		if err := m.channelHandlersReady(msg); err != nil {
+		if err := m.channelHandlersReady(msg.Data); err != nil {
 			log.Err(err).Msg("Channel handlers ready handler setup failed")
 			return
 		}
@@ -100,7 +116,10 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 		return nil, fmt.Errorf("could not subscribe to ready subject: %w", err)
 	}
 
-	config, err = m.startConfigExchange(config, ctx, brokerConn, providerID, serviceType, consumerID)
+	request := func(ctx context.Context, subject string, msg []byte) ([]byte, error) {
+		return m.sendSignedMsg(ctx, subject, msg, brokerConn)
+	}
+	config, err = m.startConfigExchange(config, ctx, request, providerID, serviceType, consumerID)
 	if err != nil {
 		return nil, fmt.Errorf("could not exchange config: %w", err)
 	}
@@ -127,7 +146,7 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 	config.publicPorts = stunPorts(consumerID, m.eventBus, config.localPorts...)
 
 	// Finally send consumer encrypted and signed connect config in ack message.
-	err = m.ackConfigExchange(config, ctx, brokerConn, providerID, serviceType, consumerID)
+	err = m.ackConfigExchange(config, ctx, request, providerID, serviceType, consumerID)
 	if err != nil {
 		return nil, fmt.Errorf("could not ack config: %w", err)
 	}
@@ -150,7 +169,7 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 		return nil, errors.New("timeout while performing configuration exchange")
 	}
 
-	channel, err := newChannel(conn1, config.privateKey, config.peerPubKey, config.compatibility)
+	channel, err := newChannel(conn1, config.privateKey, config.peerPubKey, config.capabilities)
 	if err != nil {
 		return nil, fmt.Errorf("could not create p2p channel during dial: %w", err)
 	}
@@ -163,7 +182,7 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 	return channel, nil
 }
 
-func (m *dialer) connect(contactDef ContactDefinition, tracer *trace.Tracer) (conn nats.Connection, err error) {
+func (m *dialer) connect(contactDef ContactDefinition, tracer *trace.Tracer) (conn broker.Connection, err error) {
 	trace := tracer.StartStage("Consumer P2P connect")
 	defer tracer.EndStage(trace)
 
@@ -176,8 +195,9 @@ func (m *dialer) connect(contactDef ContactDefinition, tracer *trace.Tracer) (co
 
 		conn, err = m.broker.Connect(serverURLs...)
 		if err != nil {
-			log.Warn().Msgf("broker connect failed - attempting again in 1sec: %s", err)
-			time.Sleep(time.Second)
+			delay := brokerConnectBackoff.Delay(i + 1)
+			log.Warn().Msgf("broker connect failed - attempting again in %s: %s", delay, err)
+			time.Sleep(delay)
 			continue
 		}
 		break
@@ -185,7 +205,7 @@ func (m *dialer) connect(contactDef ContactDefinition, tracer *trace.Tracer) (co
 	return conn, err
 }
 
-func (m *dialer) startConfigExchange(config *p2pConnectConfig, ctx context.Context, brokerConn nats.Connection, providerID identity.Identity, serviceType string, consumerID identity.Identity) (*p2pConnectConfig, error) {
+func (m *dialer) startConfigExchange(config *p2pConnectConfig, ctx context.Context, request exchangeRequest, providerID identity.Identity, serviceType string, consumerID identity.Identity) (*p2pConnectConfig, error) {
 	trace := config.tracer.StartStage("Consumer P2P exchange")
 	defer config.tracer.EndStage(trace)
 
@@ -195,14 +215,17 @@ func (m *dialer) startConfigExchange(config *p2pConnectConfig, ctx context.Conte
 	}
 
 	beginExchangeMsg := &pb.P2PConfigExchangeMsg{
-		PublicKey: pubKey.Hex(),
+		PublicKey:    pubKey.Hex(),
+		Version:      compat.Version,
+		MinVersion:   compat.MinVersion,
+		Capabilities: uint64(compat.Supported),
 	}
 	log.Debug().Msgf("Consumer %s sending public key %s to provider %s", consumerID.Address, beginExchangeMsg.PublicKey, providerID.Address)
-	packedMsg, err := packSignedMsg(m.signer, consumerID, beginExchangeMsg)
+	packedMsg, err := packSignedMsg(m.signer, consumerID, beginExchangeMsg, nil)
 	if err != nil {
 		return nil, fmt.Errorf("could not pack signed message: %v", err)
 	}
-	exchangeMsgBrokerReply, err := m.sendSignedMsg(ctx, configExchangeSubject(providerID, serviceType), packedMsg, brokerConn)
+	exchangeMsgBrokerReply, err := request(ctx, configExchangeSubject(providerID, serviceType), packedMsg)
 	if err != nil {
 		return nil, fmt.Errorf("could not send signed message: %w", err)
 	}
@@ -212,10 +235,14 @@ func (m *dialer) startConfigExchange(config *p2pConnectConfig, ctx context.Conte
 	if err != nil {
 		return nil, fmt.Errorf("could not unpack peer signed message: %w", err)
 	}
-	var exchangeMsgReply pb.P2PConfigExchangeMsg
-	if err := proto.Unmarshal(exchangeMsgReplySignedMsg.Data, &exchangeMsgReply); err != nil {
+	exchangeMsgReply, err := openExchangeMsg(m.decrypter(consumerID), exchangeMsgReplySignedMsg)
+	if err != nil {
 		return nil, fmt.Errorf("could not unmarshal peer signed message payload: %w", err)
 	}
+	version, err := compat.NegotiateVersion(exchangeMsgReply.Version, exchangeMsgReply.MinVersion)
+	if err != nil {
+		return nil, err
+	}
 	peerPubKey, err := DecodePublicKey(exchangeMsgReply.PublicKey)
 	if err != nil {
 		return nil, err
@@ -228,6 +255,12 @@ func (m *dialer) startConfigExchange(config *p2pConnectConfig, ctx context.Conte
 
 	config.publicKey = pubKey
 	config.compatibility = int(peerConnConfig.Compatibility)
+	config.version = version
+	config.capabilities = negotiateCapabilities(version, compat.Capabilities(exchangeMsgReply.Capabilities), config.compatibility)
+	config.peerIdentityKey, err = sealingKey(exchangeMsgReplySignedMsg, config.capabilities)
+	if err != nil {
+		return nil, fmt.Errorf("could not recover provider identity key: %w", err)
+	}
 	config.privateKey = privateKey
 	config.peerPubKey = peerPubKey
 	config.peerPublicIP = peerConnConfig.PublicIP
@@ -235,7 +268,7 @@ func (m *dialer) startConfigExchange(config *p2pConnectConfig, ctx context.Conte
 	return config, nil
 }
 
-func (m *dialer) ackConfigExchange(config *p2pConnectConfig, ctx context.Context, brokerConn nats.Connection, providerID identity.Identity, serviceType string, consumerID identity.Identity) error {
+func (m *dialer) ackConfigExchange(config *p2pConnectConfig, ctx context.Context, request exchangeRequest, providerID identity.Identity, serviceType string, consumerID identity.Identity) error {
 	trace := config.tracer.StartStage("Consumer P2P exchange ack")
 	defer config.tracer.EndStage(trace)
 
@@ -251,9 +284,12 @@ func (m *dialer) ackConfigExchange(config *p2pConnectConfig, ctx context.Context
 	endExchangeMsg := &pb.P2PConfigExchangeMsg{
 		PublicKey:        config.publicKey.Hex(),
 		ConfigCiphertext: connConfigCiphertext,
+		Version:          compat.Version,
+		MinVersion:       compat.MinVersion,
+		Capabilities:     uint64(compat.Supported),
 	}
 	log.Debug().Msgf("Consumer %s sending ack with encrypted config to provider %s", consumerID.Address, providerID.Address)
-	packedMsg, err := packSignedMsg(m.signer, consumerID, endExchangeMsg)
+	packedMsg, err := packSignedMsg(m.signer, consumerID, endExchangeMsg, config.peerIdentityKey)
 	if err != nil {
 		return fmt.Errorf("could not pack signed message: %v", err)
 	}
@@ -262,7 +298,7 @@ func (m *dialer) ackConfigExchange(config *p2pConnectConfig, ctx context.Context
 	//  until provider receives consumer config ( IP, ports ) and starts pinging Consumer first.
 	// This is why we use broker Request method to be sure that Provider processed our given configuration.
 	// To improve speed here investigate options to reduce broker communication round trip.
-	_, err = m.sendSignedMsg(ctx, configExchangeACKSubject(providerID, serviceType), packedMsg, brokerConn)
+	_, err = request(ctx, configExchangeACKSubject(providerID, serviceType), packedMsg)
 
 	if err != nil {
 		return fmt.Errorf("could not send signed msg: %v", err)
@@ -333,7 +369,7 @@ func (m *dialer) dialPinger(ctx context.Context, providerID identity.Identity, c
 	return conns[0], conns[1], nil
 }
 
-func (m *dialer) sendSignedMsg(ctx context.Context, subject string, msg []byte, brokerConn nats.Connection) ([]byte, error) {
+func (m *dialer) sendSignedMsg(ctx context.Context, subject string, msg []byte, brokerConn broker.Connection) ([]byte, error) {
 	reply, err := brokerConn.RequestWithContext(ctx, subject, msg)
 	if err != nil {
 		return nil, fmt.Errorf("could not send broker request to subject %s: %v", subject, err)
@@ -341,9 +377,9 @@ func (m *dialer) sendSignedMsg(ctx context.Context, subject string, msg []byte,
 	return reply.Data, nil
 }
 
-func (m *dialer) channelHandlersReady(msg *nats_lib.Msg) error {
+func (m *dialer) channelHandlersReady(data []byte) error {
 	var handlersReady pb.P2PChannelHandlersReady
-	if err := proto.Unmarshal(msg.Data, &handlersReady); err != nil {
+	if err := proto.Unmarshal(data, &handlersReady); err != nil {
 		return fmt.Errorf("failed to unmarshal handlers ready message: %w", err)
 	}
 	if handlersReady.Value != "HANDLERS READY" {
@@ -0,0 +1,210 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+var (
+	// ErrExchangeRateLimited is returned when an incoming exchange is dropped due to rate limits.
+	ErrExchangeRateLimited = errors.New("p2p exchange rate limited")
+	// ErrExchangePeerRejected is returned when an incoming exchange is dropped by the peer check.
+	ErrExchangePeerRejected = errors.New("p2p exchange peer rejected")
+	// ErrExchangeTooLarge is returned when an incoming exchange message exceeds the size limit.
+	ErrExchangeTooLarge = errors.New("p2p exchange message too large")
+)
+
+// maxExchangeMsgSize bounds incoming exchange messages, regular ones are a few hundred bytes.
+const maxExchangeMsgSize = 16 << 10
+
+// ExchangeLimits bounds incoming p2p config exchanges handled by the provider.
+type ExchangeLimits struct {
+	// Rate is the number of exchange messages per second accepted from all peers.
+	Rate  float64
+	Burst int
+
+	// PeerRate is the number of exchanges per second accepted from a single peer identity.
+	PeerRate  float64
+	PeerBurst int
+
+	// NewPeerRate is the number of exchanges per second accepted from peers not seen recently,
+	// so that rotating identities can't escape the per peer limits.
+	NewPeerRate  float64
+	NewPeerBurst int
+
+	// VerifyRate is the number of messages per second which signatures are verified.
+	VerifyRate  float64
+	VerifyBurst int
+
+	// PeerAllowed optionally rejects peers before any expensive work is done for them.
+	PeerAllowed func(peerID identity.Identity) error
+}
+
+// DefaultExchangeLimits returns exchange limits which are high enough for
+// regular consumer traffic while bounding the work a single peer can cause.
+func DefaultExchangeLimits() ExchangeLimits {
+	return ExchangeLimits{
+		Rate:         20,
+		Burst:        40,
+		PeerRate:     0.2,
+		PeerBurst:    5,
+		NewPeerRate:  2,
+		NewPeerBurst: 20,
+		VerifyRate:   100,
+		VerifyBurst:  200,
+	}
+}
+
+type exchangeLimiter struct {
+	global      *rate.Limiter
+	newPeers    *rate.Limiter
+	verify      *rate.Limiter
+	peerRate    rate.Limit
+	peerBurst   int
+	peerAllowed func(peerID identity.Identity) error
+	now         func() time.Time
+
+	mu        sync.Mutex
+	peers     map[identity.Identity]*peerLimit
+	lastSweep time.Time
+}
+
+type peerLimit struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func newExchangeLimiter(limits ExchangeLimits) *exchangeLimiter {
+	l := &exchangeLimiter{
+		global:      rate.NewLimiter(rate.Inf, 0),
+		newPeers:    rate.NewLimiter(rate.Inf, 0),
+		verify:      rate.NewLimiter(rate.Inf, 0),
+		peerRate:    rate.Inf,
+		peerAllowed: limits.PeerAllowed,
+		now:         time.Now,
+		peers:       make(map[identity.Identity]*peerLimit),
+	}
+	if limits.Rate > 0 {
+		l.global = rate.NewLimiter(rate.Limit(limits.Rate), atLeastOne(limits.Burst))
+	}
+	if limits.PeerRate > 0 {
+		l.peerRate = rate.Limit(limits.PeerRate)
+		l.peerBurst = atLeastOne(limits.PeerBurst)
+	}
+	if limits.NewPeerRate > 0 {
+		l.newPeers = rate.NewLimiter(rate.Limit(limits.NewPeerRate), atLeastOne(limits.NewPeerBurst))
+	}
+	if limits.VerifyRate > 0 {
+		l.verify = rate.NewLimiter(rate.Limit(limits.VerifyRate), atLeastOne(limits.VerifyBurst))
+	}
+	return l
+}
+
+// allowVerify bounds the work spent on recovering signers of incoming messages.
+// Its bucket is larger than the global one, as it is drained by unverified messages as well.
+func (l *exchangeLimiter) allowVerify(size int) error {
+	if size > maxExchangeMsgSize {
+		return ErrExchangeTooLarge
+	}
+	if !l.verify.AllowN(l.now(), 1) {
+		return ErrExchangeRateLimited
+	}
+	return nil
+}
+
+// allow checks the global rate limit. It must only be consulted for
+// messages with a verified signature, otherwise unsigned garbage could
+// exhaust the shared bucket and lock out every honest consumer.
+func (l *exchangeLimiter) allow() error {
+	if !l.global.AllowN(l.now(), 1) {
+		return ErrExchangeRateLimited
+	}
+	return nil
+}
+
+// allowPeer checks per peer limits once the peer identity is known, then the peer check
+// and only then the global limit, so neither a single nor a rejected peer can drain the global bucket.
+func (l *exchangeLimiter) allowPeer(peerID identity.Identity) error {
+	if err := l.allowPeerRate(peerID); err != nil {
+		return err
+	}
+	if l.peerAllowed != nil {
+		if err := l.peerAllowed(peerID); err != nil {
+			return fmt.Errorf("%w: %v", ErrExchangePeerRejected, err)
+		}
+	}
+	return l.allow()
+}
+
+func (l *exchangeLimiter) allowPeerRate(peerID identity.Identity) error {
+	if l.peerRate == rate.Inf {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	peer, ok := l.peers[peerID]
+	if !ok {
+		if !l.newPeers.AllowN(now, 1) {
+			return ErrExchangeRateLimited
+		}
+		peer = &peerLimit{limiter: rate.NewLimiter(l.peerRate, l.peerBurst)}
+		l.peers[peerID] = peer
+	}
+	peer.lastSeen = now
+
+	if !peer.limiter.AllowN(now, 1) {
+		return ErrExchangeRateLimited
+	}
+	return nil
+}
+
+// sweep forgets peers which were idle long enough to have their limiter
+// fully replenished, so forgetting them changes nothing.
+func (l *exchangeLimiter) sweep(now time.Time) {
+	idle := time.Duration(float64(l.peerBurst) / float64(l.peerRate) * float64(time.Second))
+	if now.Sub(l.lastSweep) < idle {
+		return
+	}
+	l.lastSweep = now
+
+	for id, peer := range l.peers {
+		if now.Sub(peer.lastSeen) >= idle {
+			delete(l.peers, id)
+		}
+	}
+}
+
+func atLeastOne(burst int) int {
+	if burst < 1 {
+		return 1
+	}
+	return burst
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+func TestExchangeLimiter_GlobalRate(t *testing.T) {
+	now := time.Date(2022, time.June, 15, 12, 0, 0, 0, time.UTC)
+	limiter := newExchangeLimiter(ExchangeLimits{Rate: 1, Burst: 2})
+	limiter.now = func() time.Time { return now }
+
+	assert.NoError(t, limiter.allow())
+	assert.NoError(t, limiter.allow())
+	assert.ErrorIs(t, limiter.allow(), ErrExchangeRateLimited)
+
+	now = now.Add(time.Second)
+	assert.NoError(t, limiter.allow())
+}
+
+func TestExchangeLimiter_PeerRate(t *testing.T) {
+	now := time.Date(2022, time.June, 15, 12, 0, 0, 0, time.UTC)
+	limiter := newExchangeLimiter(ExchangeLimits{PeerRate: 0.1, PeerBurst: 1})
+	limiter.now = func() time.Time { return now }
+
+	attacker := identity.FromAddress("0x1")
+	consumer := identity.FromAddress("0x2")
+
+	assert.NoError(t, limiter.allowPeer(attacker))
+	assert.ErrorIs(t, limiter.allowPeer(attacker), ErrExchangeRateLimited)
+	assert.NoError(t, limiter.allowPeer(consumer))
+
+	now = now.Add(10 * time.Second)
+	assert.NoError(t, limiter.allowPeer(attacker))
+}
+
+func TestExchangeLimiter_ForgetsIdlePeers(t *testing.T) {
+	now := time.Date(2022, time.June, 15, 12, 0, 0, 0, time.UTC)
+	limiter := newExchangeLimiter(ExchangeLimits{PeerRate: 1, PeerBurst: 1})
+	limiter.now = func() time.Time { return now }
+
+	assert.NoError(t, limiter.allowPeer(identity.FromAddress("0x1")))
+	assert.Len(t, limiter.peers, 1)
+
+	now = now.Add(time.Second)
+	assert.NoError(t, limiter.allowPeer(identity.FromAddress("0x2")))
+	assert.Len(t, limiter.peers, 1)
+}
+
+func TestExchangeLimiter_PeerAllowed(t *testing.T) {
+	limiter := newExchangeLimiter(ExchangeLimits{
+		PeerAllowed: func(peerID identity.Identity) error {
+			if peerID.Address == "0x1" {
+				return errors.New("not registered")
+			}
+			return nil
+		},
+	})
+
+	assert.ErrorIs(t, limiter.allowPeer(identity.FromAddress("0x1")), ErrExchangePeerRejected)
+	assert.NoError(t, limiter.allowPeer(identity.FromAddress("0x2")))
+}
+
+func TestExchangeLimiter_Unlimited(t *testing.T) {
+	limiter := newExchangeLimiter(ExchangeLimits{})
+
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, limiter.allow())
+		assert.NoError(t, limiter.allowPeer(identity.FromAddress("0x1")))
+	}
+}
+
+func TestExchangeLimiter_PeerLimitedBeforeGlobal(t *testing.T) {
+	now := time.Date(2022, time.June, 15, 12, 0, 0, 0, time.UTC)
+	limiter := newExchangeLimiter(ExchangeLimits{Rate: 1, Burst: 2, PeerRate: 0.1, PeerBurst: 1})
+	limiter.now = func() time.Time { return now }
+
+	attacker := identity.FromAddress("0x1")
+	consumer := identity.FromAddress("0x2")
+
+	assert.NoError(t, limiter.allowPeer(attacker))
+	for i := 0; i < 10; i++ {
+		assert.ErrorIs(t, limiter.allowPeer(attacker), ErrExchangeRateLimited)
+	}
+	assert.NoError(t, limiter.allowPeer(consumer))
+}
+
+func TestExchangeLimiter_RotatedIdentitiesShareNewPeerRate(t *testing.T) {
+	now := time.Date(2022, time.June, 15, 12, 0, 0, 0, time.UTC)
+	limiter := newExchangeLimiter(ExchangeLimits{PeerRate: 0.1, PeerBurst: 1, NewPeerRate: 1, NewPeerBurst: 2})
+	limiter.now = func() time.Time { return now }
+
+	assert.NoError(t, limiter.allowPeer(identity.FromAddress("0x1")))
+	assert.NoError(t, limiter.allowPeer(identity.FromAddress("0x2")))
+	assert.ErrorIs(t, limiter.allowPeer(identity.FromAddress("0x3")), ErrExchangeRateLimited)
+
+	now = now.Add(time.Second)
+	assert.NoError(t, limiter.allowPeer(identity.FromAddress("0x3")))
+}
+
+func TestExchangeLimiter_RejectedPeerDoesNotDrainGlobalRate(t *testing.T) {
+	limiter := newExchangeLimiter(ExchangeLimits{
+		Rate:  1,
+		Burst: 1,
+		PeerAllowed: func(peerID identity.Identity) error {
+			if peerID.Address != "0x2" {
+				return errors.New("not registered")
+			}
+			return nil
+		},
+	})
+
+	for i := 0; i < 10; i++ {
+		assert.ErrorIs(t, limiter.allowPeer(identity.FromAddress("0x1")), ErrExchangePeerRejected)
+	}
+	assert.NoError(t, limiter.allowPeer(identity.FromAddress("0x2")))
+}
+
+func TestExchangeLimiter_Verify(t *testing.T) {
+	now := time.Date(2022, time.June, 15, 12, 0, 0, 0, time.UTC)
+	limiter := newExchangeLimiter(ExchangeLimits{VerifyRate: 1, VerifyBurst: 1})
+	limiter.now = func() time.Time { return now }
+
+	assert.ErrorIs(t, limiter.allowVerify(maxExchangeMsgSize+1), ErrExchangeTooLarge)
+	assert.NoError(t, limiter.allowVerify(100))
+	assert.ErrorIs(t, limiter.allowVerify(100), ErrExchangeRateLimited)
+
+	now = now.Add(time.Second)
+	assert.NoError(t, limiter.allowVerify(100))
+}
@@ -19,21 +19,26 @@ package p2p
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"net"
 	"sync"
 	"time"
 
-	nats_lib "github.com/nats-io/nats.go"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/mysteriumnetwork/node/communication/broker"
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/core/privacy"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/metrics"
 	"github.com/mysteriumnetwork/node/nat/traversal"
+	"github.com/mysteriumnetwork/node/p2p/camouflage"
 	"github.com/mysteriumnetwork/node/p2p/compat"
 	"github.com/mysteriumnetwork/node/p2p/nat"
 	"github.com/mysteriumnetwork/node/pb"
@@ -46,41 +51,77 @@ type Listener interface {
 	// to channelHandlers
 	Listen(providerID identity.Identity, serviceType string, channelHandler func(ch Channel)) (func(), error)
 
-	// GetContact returns contract which is later can be added to proposal contacts definition so consumer can
+	// GetContacts returns contacts which are later added to proposal contacts definition so consumer can
 	// know how to connect to this p2p listener.
-	GetContact() market.Contact
+	GetContacts() market.ContactList
+
+	// ServeCamouflage accepts p2p channel setup over the TLS camouflage server, so that consumers
+	// allowed to use HTTPS only can connect without the broker and UDP hole punching.
+	ServeCamouflage(server *camouflage.Server)
 }
 
+var (
+	dialAttempts = metrics.NewCounter("p2p_provider_dial_attempts_total", "Provider attempts to reach consumers through NAT")
+	dialFailures = metrics.NewCounter("p2p_provider_dial_failures_total", "Failed provider attempts to reach consumers through NAT")
+	dialDuration = metrics.NewHistogram("p2p_provider_dial_duration_ms", "Duration of provider attempts to reach consumers through NAT", time.Hour.Milliseconds(), 3)
+)
+
+// pendingConfigTTL is how long provider waits for the consumer to acknowledge the exchange.
+const pendingConfigTTL = time.Minute
+
 // NewListener creates new p2p communication listener which is used on provider side.
-func NewListener(brokerConn nats.Connection, signer identity.SignerFactory, verifier identity.Verifier, ipResolver ip.Resolver, eventBus eventbus.EventBus) Listener {
+// Connections of services present in bindings are bound to their local source IPs.
+// Peers of established channels may call only the topics allowed for their role by acl.
+func NewListener(brokerConn broker.Connection, signer identity.SignerFactory, decrypter identity.DecrypterFactory, verifier identity.Verifier, ipResolver ip.Resolver, eventBus eventbus.EventBus, limits ExchangeLimits, bindings ip.Bindings, acl TopicACL, timeNow func() time.Time) Listener {
 	return &listener{
 		brokerConn:     brokerConn,
 		pendingConfigs: map[PublicKey]p2pConnectConfig{},
 		ipResolver:     ipResolver,
+		bindings:       bindings,
 		signer:         signer,
+		decrypter:      decrypter,
 		verifier:       verifier,
 		eventBus:       eventBus,
+		limiter:        newExchangeLimiter(limits),
+		acl:            acl,
+		timeNow:        timeNow,
+		streamServices: map[string]streamService{},
 	}
 }
 
 // listener implements Listener interface.
 type listener struct {
 	eventBus   eventbus.EventBus
-	brokerConn nats.Connection
+	brokerConn broker.Connection
 	signer     identity.SignerFactory
+	decrypter  identity.DecrypterFactory
 	verifier   identity.Verifier
 	ipResolver ip.Resolver
+	bindings   ip.Bindings
+	limiter    *exchangeLimiter
+	acl        TopicACL
+	// timeNow returns timestamps of signed broker subjects.
+	timeNow func() time.Time
 
 	// Keys holds pendingConfigs temporary configs for provider side since it
 	// need to handle key exchange in two steps.
 	pendingConfigs   map[PublicKey]p2pConnectConfig
 	pendingConfigsMu sync.Mutex
+
+	// streamServices holds services accepting channel setup over camouflage streams by config exchange subject.
+	streamServices map[string]streamService
+	camouflage     *camouflage.Server
+	streamMu       sync.Mutex
 }
 
 type p2pConnectConfig struct {
+	localIP          string
 	publicIP         string
 	peerPublicIP     string
 	compatibility    int
+	version          uint32
+	capabilities     compat.Capabilities
+	peerIdentityKey  *ecdsa.PublicKey
 	peerPorts        []int
 	localPorts       []int
 	publicPorts      []int
@@ -90,7 +131,9 @@ type p2pConnectConfig struct {
 	tracer           *trace.Tracer
 	upnpPortsRelease func()
 	start            nat.StartPorts
+	natMethod        string
 	peerID           identity.Identity
+	createdAt        time.Time
 }
 
 func (c *p2pConnectConfig) peerIP() string {
@@ -101,23 +144,39 @@ func (c *p2pConnectConfig) peerIP() string {
 	return c.peerPublicIP
 }
 
-func (m *listener) GetContact() market.Contact {
-	return market.Contact{
+// binding returns local source IP and IP resolver used by the service connections.
+func (m *listener) binding(serviceType string) (string, ip.Resolver) {
+	if binding, ok := m.bindings.Get(serviceType); ok {
+		return binding.IP, binding.Resolver
+	}
+	return "", m.ipResolver
+}
+
+func (m *listener) GetContacts() market.ContactList {
+	contacts := market.ContactList{{
 		Type:       ContactTypeV1,
 		Definition: ContactDefinition{BrokerAddresses: m.brokerConn.Servers()},
+	}}
+
+	if contact, ok := m.camouflageContact(); ok {
+		contacts = append(contacts, contact)
 	}
+	return contacts
 }
 
 // Listen listens for incoming peer connections to establish new p2p channels. Establishes p2p channel and passes it
 // to channelHandlers.
 func (m *listener) Listen(providerID identity.Identity, serviceType string, channelHandlers func(ch Channel)) (func(), error) {
-	configSignedSubject, err := nats.SignedSubject(m.signer(providerID), configExchangeSubject(providerID, serviceType))
+	configSignedSubject, err := nats.SignedSubject(m.signer(providerID), configExchangeSubject(providerID, serviceType), m.timeNow())
 	if err != nil {
 		return func() {}, fmt.Errorf("cannot sign config topic: %w", err)
 	}
 
-	configSub, err := m.brokerConn.Subscribe(configSignedSubject, func(msg *nats_lib.Msg) {
-		if err := m.providerStartConfigExchange(providerID, msg); err != nil {
+	configSub, err := m.brokerConn.Subscribe(configSignedSubject, func(msg *broker.Msg) {
+		reply := func(data []byte) error {
+			return m.brokerConn.Publish(msg.Reply, data)
+		}
+		if err := m.providerStartConfigExchange(providerID, serviceType, msg.Data, reply, false); err != nil {
 			log.Err(err).Msg("Could not handle initial exchange")
 			return
 		}
@@ -126,13 +185,13 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		return func() {}, fmt.Errorf("could not get subscribe to config exchange topic: %w", err)
 	}
 
-	ackSignedSubject, err := nats.SignedSubject(m.signer(providerID), configExchangeACKSubject(providerID, serviceType))
+	ackSignedSubject, err := nats.SignedSubject(m.signer(providerID), configExchangeACKSubject(providerID, serviceType), m.timeNow())
 	if err != nil {
 		return func() {}, fmt.Errorf("cannot sign ack topic: %w", err)
 	}
 
-	ackSub, err := m.brokerConn.Subscribe(ackSignedSubject, func(msg *nats_lib.Msg) {
-		config, err := m.providerAckConfigExchange(msg)
+	ackSub, err := m.brokerConn.Subscribe(ackSignedSubject, func(msg *broker.Msg) {
+		config, err := m.providerAckConfigExchange(providerID, msg.Data)
 		if err != nil {
 			log.Err(err).Msg("Could not handle exchange ack")
 			return
@@ -157,20 +216,27 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 			config.tracer.EndStage(trace)
 		}(msg.Reply)
 
+		dialStart := time.Now()
+		attemptFailed := func() {
+			m.publishTraversalAttempt(providerID, serviceType, config, dialStart, false)
+		}
+
 		var conn1, conn2 *net.UDPConn
 		if config.start != nil {
 			traceDial := config.tracer.StartStage("Provider P2P dial (preparation)")
 			log.Debug().Msgf("Pinging consumer with IP %s using ports %v:%v initial ttl: %v",
-				config.peerIP(), config.localPorts, config.peerPorts, 1)
+				privacy.ConsumerAddr(config.peerIP()), config.localPorts, config.peerPorts, 1)
 
-			conns, err := config.start(context.Background(), config.peerIP(), config.peerPorts, config.localPorts)
+			conns, err := config.start(context.Background(), config.localIP, config.peerIP(), config.peerPorts, config.localPorts)
 			if err != nil {
 				log.Err(err).Msg("Could not ping peer")
+				attemptFailed()
 				return
 			}
 
 			if len(conns) != requiredConnCount {
 				log.Err(err).Msg("Could not get required number of connections")
+				attemptFailed()
 				return
 			}
 
@@ -180,29 +246,29 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		} else {
 			traceDial := config.tracer.StartStage("Provider P2P dial (direct)")
 			log.Debug().Msg("Skipping consumer ping")
-			conn1, err = net.DialUDP("udp4", &net.UDPAddr{Port: config.localPorts[0]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[0]})
+			conn1, err = net.DialUDP("udp4", &net.UDPAddr{IP: net.ParseIP(config.localIP), Port: config.localPorts[0]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[0]})
 			if err != nil {
 				log.Err(err).Msg("Could not create UDP conn for p2p channel")
+				attemptFailed()
 				return
 			}
-			conn2, err = net.DialUDP("udp4", &net.UDPAddr{Port: config.localPorts[1]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[1]})
+			conn2, err = net.DialUDP("udp4", &net.UDPAddr{IP: net.ParseIP(config.localIP), Port: config.localPorts[1]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[1]})
 			if err != nil {
 				log.Err(err).Msg("Could not create UDP conn for service")
+				attemptFailed()
 				return
 			}
 			config.tracer.EndStage(traceDial)
 		}
 
+		m.publishTraversalAttempt(providerID, serviceType, config, dialStart, true)
+
 		traceAck := config.tracer.StartStage("Provider P2P dial ack")
-		channel, err := newChannel(conn1, config.privateKey, config.peerPubKey, config.compatibility)
+		channel, err := m.newChannel(conn1, conn2, config)
 		if err != nil {
 			log.Err(err).Msg("Could not create channel")
 			return
 		}
-		channel.setTracer(config.tracer)
-		channel.setServiceConn(conn2)
-		channel.setPeerID(config.peerID)
-		channel.setUpnpPortsRelease(config.upnpPortsRelease)
 
 		channelHandlers(channel)
 
@@ -223,7 +289,10 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		return func() {}, fmt.Errorf("could not get subscribe to config exchange acknowledge topic: %w", err)
 	}
 
+	removeStreamService := m.addStreamService(providerID, serviceType, channelHandlers)
+
 	return func() {
+		removeStreamService()
 		if err := configSub.Unsubscribe(); err != nil {
 			log.Err(err).Msg("Failed to unsubscribe from config exchange topic")
 		}
@@ -233,41 +302,86 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 	}, nil
 }
 
-func (m *listener) providerStartConfigExchange(providerID identity.Identity, msg *nats_lib.Msg) error {
+// newChannel creates provider side of the p2p channel over established connections.
+func (m *listener) newChannel(conn1, conn2 *net.UDPConn, config *p2pConnectConfig) (*channel, error) {
+	channel, err := newChannel(conn1, config.privateKey, config.peerPubKey, config.capabilities)
+	if err != nil {
+		return nil, err
+	}
+	channel.setTracer(config.tracer)
+	channel.setServiceConn(conn2)
+	channel.setPeerID(config.peerID)
+	channel.setACL(m.acl.forPeer(config.peerID))
+	channel.setUpnpPortsRelease(config.upnpPortsRelease)
+	return channel, nil
+}
+
+// providerStartConfigExchange replies to the initial consumer exchange. Ports are not prepared
+// for exchanges over camouflage streams, as the channel is carried by the stream itself.
+func (m *listener) providerStartConfigExchange(providerID identity.Identity, serviceType string, data []byte, reply func([]byte) error, stream bool) error {
 	tracer := trace.NewTracer("Provider whole Connect")
 
 	trace := tracer.StartStage("Provider P2P exchange")
 	defer tracer.EndStage(trace)
 
-	pubKey, privateKey, err := GenerateKey()
+	// Get initial peer exchange with it's public key.
+	if err := m.limiter.allowVerify(len(data)); err != nil {
+		return fmt.Errorf("exchange dropped: %w", err)
+	}
+	signedMsg, peerID, err := unpackSignedMsg(m.verifier, data)
 	if err != nil {
-		return fmt.Errorf("could not generate provider p2p keys: %w", err)
+		return fmt.Errorf("could not unpack signed msg: %w", err)
+	}
+	if err := m.limiter.allowPeer(peerID); err != nil {
+		return fmt.Errorf("exchange from %s dropped: %w", peerID.Address, err)
 	}
 
-	// Get initial peer exchange with it's public key.
-	signedMsg, peerID, err := unpackSignedMsg(m.verifier, msg.Data)
+	pubKey, privateKey, err := GenerateKey()
 	if err != nil {
-		return fmt.Errorf("could not unpack signed msg: %w", err)
+		return fmt.Errorf("could not generate provider p2p keys: %w", err)
 	}
-	var peerExchangeMsg pb.P2PConfigExchangeMsg
-	if err := proto.Unmarshal(signedMsg.Data, &peerExchangeMsg); err != nil {
+	peerExchangeMsg, err := openExchangeMsg(m.decrypter(providerID), signedMsg)
+	if err != nil {
 		return err
 	}
+	version, err := compat.NegotiateVersion(peerExchangeMsg.Version, peerExchangeMsg.MinVersion)
+	if err != nil {
+		// Still reply with our versions so that consumer can report the mismatch instead of timing out.
+		if replyErr := m.providerRejectVersion(providerID, reply); replyErr != nil {
+			log.Err(replyErr).Msg("Could not reply with supported protocol versions")
+		}
+		return fmt.Errorf("exchange from %s rejected: %w", peerID.Address, err)
+	}
 	peerPubKey, err := DecodePublicKey(peerExchangeMsg.PublicKey)
 	if err != nil {
 		return err
 	}
-	log.Debug().Msgf("Received consumer public key %s", peerPubKey.Hex())
+	log.Debug().Msgf("Received consumer public key %s, negotiated protocol version %d", peerPubKey.Hex(), version)
 
-	publicIP, localPorts, portsRelease, start, err := m.prepareLocalPorts(providerID.Address, tracer)
+	capabilities := compat.Supported.Negotiate(compat.Capabilities(peerExchangeMsg.Capabilities))
+	peerIdentityKey, err := sealingKey(signedMsg, capabilities)
 	if err != nil {
-		return fmt.Errorf("could not prepare ports: %w", err)
+		return fmt.Errorf("could not recover consumer identity key: %w", err)
+	}
+
+	localIP, resolver := m.binding(serviceType)
+	var publicIP, natMethod string
+	var localPorts, publicPorts []int
+	var portsRelease func()
+	var start nat.StartPorts
+	if !stream {
+		publicIP, localPorts, portsRelease, start, natMethod, err = m.prepareLocalPorts(providerID.Address, resolver, tracer)
+		if err != nil {
+			return fmt.Errorf("could not prepare ports: %w", err)
+		}
+		publicPorts = stunPorts(providerID, m.eventBus, localPorts...)
 	}
 
 	p2pConnConfig := p2pConnectConfig{
+		localIP:          localIP,
 		publicIP:         publicIP,
 		localPorts:       localPorts,
-		publicPorts:      stunPorts(providerID, m.eventBus, localPorts...),
+		publicPorts:      publicPorts,
 		publicKey:        pubKey,
 		privateKey:       privateKey,
 		peerPubKey:       peerPubKey,
@@ -276,7 +390,11 @@ func (m *listener) providerStartConfigExchange(providerID identity.Identity, msg
 		peerPublicIP:     "",
 		peerPorts:        nil,
 		start:            start,
+		natMethod:        natMethod,
 		peerID:           peerID,
+		createdAt:        time.Now(),
+		version:          version,
+		capabilities:     capabilities,
 	}
 	m.setPendingConfig(p2pConnConfig)
 
@@ -292,30 +410,47 @@ func (m *listener) providerStartConfigExchange(providerID identity.Identity, msg
 	exchangeMsg := pb.P2PConfigExchangeMsg{
 		PublicKey:        pubKey.Hex(),
 		ConfigCiphertext: configCiphertext,
+		Version:          compat.Version,
+		MinVersion:       compat.MinVersion,
+		Capabilities:     uint64(compat.Supported),
 	}
 	log.Debug().Msgf("Sending reply with public key %s and encrypted config to consumer", exchangeMsg.PublicKey)
-	packedMsg, err := packSignedMsg(m.signer, providerID, &exchangeMsg)
+	packedMsg, err := packSignedMsg(m.signer, providerID, &exchangeMsg, peerIdentityKey)
 	if err != nil {
 		return fmt.Errorf("could not pack signed message: %w", err)
 	}
-	err = m.brokerConn.Publish(msg.Reply, packedMsg)
-	if err != nil {
-		return fmt.Errorf("could not publish message via broker: %w", err)
+	if err := reply(packedMsg); err != nil {
+		return fmt.Errorf("could not send exchange reply: %w", err)
 	}
 	return nil
 }
 
+// providerRejectVersion replies with provider supported protocol versions only,
+// without allocating ports or keys for the exchange.
+func (m *listener) providerRejectVersion(providerID identity.Identity, reply func([]byte) error) error {
+	exchangeMsg := pb.P2PConfigExchangeMsg{
+		Version:      compat.Version,
+		MinVersion:   compat.MinVersion,
+		Capabilities: uint64(compat.Supported),
+	}
+	packedMsg, err := packSignedMsg(m.signer, providerID, &exchangeMsg, nil)
+	if err != nil {
+		return fmt.Errorf("could not pack signed message: %w", err)
+	}
+	return reply(packedMsg)
+}
+
 // prepareLocalPorts acquires ports for p2p connections. It tries to acquire only
 // required ports count for actual p2p and service connections and fallback to
 // acquiring extra ports for nat pinger if provider is behind nat, port mapping failed
 // and no manual port forwarding is enabled.
-func (m *listener) prepareLocalPorts(id string, tracer *trace.Tracer) (string, []int, func(), nat.StartPorts, error) {
+func (m *listener) prepareLocalPorts(id string, resolver ip.Resolver, tracer *trace.Tracer) (string, []int, func(), nat.StartPorts, string, error) {
 	trace := tracer.StartStage("Provider P2P exchange (ports)")
 	defer tracer.EndStage(trace)
 
-	publicIP, err := m.ipResolver.GetPublicIP()
+	publicIP, err := resolver.GetPublicIP()
 	if err != nil {
-		return "", nil, nil, nil, fmt.Errorf("could not get public IP: %w", err)
+		return "", nil, nil, nil, "", fmt.Errorf("could not get public IP: %w", err)
 	}
 
 	for _, p := range nat.OrderedPortProviders() {
@@ -326,7 +461,7 @@ func (m *listener) prepareLocalPorts(id string, tracer *trace.Tracer) (string, [
 				Method:   p.Method,
 				Success:  true,
 			})
-			return publicIP, ports, release, start, nil
+			return publicIP, ports, release, start, p.Method, nil
 		}
 
 		m.eventBus.Publish(nat.AppTopicNATTraversalMethod, nat.NATTraversalMethod{
@@ -336,16 +471,41 @@ func (m *listener) prepareLocalPorts(id string, tracer *trace.Tracer) (string, [
 		})
 	}
 
-	return "", nil, nil, nil, fmt.Errorf("failed to prepare local ports")
+	return "", nil, nil, nil, "", fmt.Errorf("failed to prepare local ports")
+}
+
+func (m *listener) publishTraversalAttempt(providerID identity.Identity, serviceType string, config *p2pConnectConfig, start time.Time, success bool) {
+	duration := time.Since(start)
+	dialAttempts.Inc()
+	if !success {
+		dialFailures.Inc()
+	}
+	dialDuration.Record(duration.Milliseconds())
+
+	m.eventBus.Publish(nat.AppTopicNATTraversalAttempt, nat.NATTraversalAttempt{
+		Identity:    providerID.Address,
+		ServiceType: serviceType,
+		Method:      config.natMethod,
+		Duration:    duration,
+		Success:     success,
+	})
 }
 
-func (m *listener) providerAckConfigExchange(msg *nats_lib.Msg) (*p2pConnectConfig, error) {
-	signedMsg, peerID, err := unpackSignedMsg(m.verifier, msg.Data)
+func (m *listener) providerAckConfigExchange(providerID identity.Identity, data []byte) (*p2pConnectConfig, error) {
+	if err := m.limiter.allowVerify(len(data)); err != nil {
+		return nil, fmt.Errorf("exchange ack dropped: %w", err)
+	}
+	signedMsg, peerID, err := unpackSignedMsg(m.verifier, data)
 	if err != nil {
 		return nil, fmt.Errorf("could not unpack signed msg: %w", err)
 	}
-	var peerExchangeMsg pb.P2PConfigExchangeMsg
-	if err := proto.Unmarshal(signedMsg.Data, &peerExchangeMsg); err != nil {
+	// Acks only complete exchanges which already passed the peer limits,
+	// so the global limit is enough here.
+	if err := m.limiter.allow(); err != nil {
+		return nil, fmt.Errorf("exchange ack from %s dropped: %w", peerID.Address, err)
+	}
+	peerExchangeMsg, err := openExchangeMsg(m.decrypter(providerID), signedMsg)
+	if err != nil {
 		return nil, fmt.Errorf("could not unmarshal exchange msg: %w", err)
 	}
 	peerPubKey, err := DecodePublicKey(peerExchangeMsg.PublicKey)
@@ -361,18 +521,25 @@ func (m *listener) providerAckConfigExchange(msg *nats_lib.Msg) (*p2pConnectConf
 	if peerID != config.peerID {
 		return nil, fmt.Errorf("acknowledged config signed by unexpected identity: %s", peerID.ToCommonAddress())
 	}
+	if config.capabilities.Has(compat.CapabilitySealedExchange) && !signedMsg.Sealed {
+		return nil, errors.New("acknowledged config is not sealed although sealing was negotiated")
+	}
 
 	peerConfig, err := decryptConnConfigMsg(peerExchangeMsg.ConfigCiphertext, config.privateKey, peerPubKey)
 	if err != nil {
 		return nil, fmt.Errorf("could not decrypt peer conn config: %w", err)
 	}
 
-	log.Debug().Msgf("Decrypted consumer config: %v", peerConfig)
+	log.Debug().Msgf("Decrypted consumer config: public IP %s, ports %v, compatibility %d",
+		privacy.ConsumerAddr(peerConfig.PublicIP), peerConfig.Ports, peerConfig.Compatibility)
 
 	return &p2pConnectConfig{
 		peerPublicIP:     peerConfig.PublicIP,
 		peerPorts:        int32ToIntSlice(peerConfig.Ports),
 		compatibility:    int(peerConfig.Compatibility),
+		version:          config.version,
+		capabilities:     negotiateCapabilities(config.version, config.capabilities, int(peerConfig.Compatibility)),
+		localIP:          config.localIP,
 		localPorts:       config.localPorts,
 		publicKey:        config.publicKey,
 		privateKey:       config.privateKey,
@@ -386,14 +553,12 @@ func (m *listener) providerAckConfigExchange(msg *nats_lib.Msg) (*p2pConnectConf
 }
 
 func (m *listener) providerChannelHandlersReady(providerID identity.Identity, serviceType string) error {
-	handlersReadyMsg := pb.P2PChannelHandlersReady{Value: "HANDLERS READY"}
-
-	message, err := proto.Marshal(&handlersReadyMsg)
+	message, err := handlersReadyMessage()
 	if err != nil {
-		return fmt.Errorf("could not marshal exchange msg: %w", err)
+		return err
 	}
 
-	signedSubject, err := nats.SignedSubject(m.signer(providerID), channelHandlersReadySubject(providerID, serviceType))
+	signedSubject, err := nats.SignedSubject(m.signer(providerID), channelHandlersReadySubject(providerID, serviceType), m.timeNow())
 	if err != nil {
 		return fmt.Errorf("unable to sign p2p-channel-handlers-ready subject: %w", err)
 	}
@@ -402,6 +567,16 @@ func (m *listener) providerChannelHandlersReady(providerID identity.Identity, se
 	return m.brokerConn.Publish(signedSubject, message)
 }
 
+func handlersReadyMessage() ([]byte, error) {
+	handlersReadyMsg := pb.P2PChannelHandlersReady{Value: "HANDLERS READY"}
+
+	message, err := proto.Marshal(&handlersReadyMsg)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal exchange msg: %w", err)
+	}
+	return message, nil
+}
+
 func (m *listener) pendingConfig(peerPubKey PublicKey) (p2pConnectConfig, bool) {
 	m.pendingConfigsMu.Lock()
 	defer m.pendingConfigsMu.Unlock()
@@ -412,6 +587,19 @@ func (m *listener) pendingConfig(peerPubKey PublicKey) (p2pConnectConfig, bool)
 func (m *listener) setPendingConfig(config p2pConnectConfig) {
 	m.pendingConfigsMu.Lock()
 	defer m.pendingConfigsMu.Unlock()
+
+	// Forget exchanges which were never acknowledged, so that peers
+	// can't pile up pending configs and reserved ports.
+	for key, pending := range m.pendingConfigs {
+		if config.createdAt.Sub(pending.createdAt) < pendingConfigTTL {
+			continue
+		}
+		if pending.upnpPortsRelease != nil {
+			pending.upnpPortsRelease()
+		}
+		delete(m.pendingConfigs, key)
+	}
+
 	m.pendingConfigs[config.peerPubKey] = config
 }
 
@@ -34,11 +34,24 @@ const (
 	TopicSessionStatus = "p2p-session-connectivity-status"
 	// TopicSessionDestroy is a session destroy endpoint for p2p communication.
 	TopicSessionDestroy = "p2p-session-destroy"
+	// TopicSessionQoS is a session quality of service report from provider for p2p communication.
+	TopicSessionQoS = "p2p-session-qos"
+	// TopicSessionProviderIP is a provider public IP change notification for p2p communication.
+	TopicSessionProviderIP = "p2p-session-provider-ip"
+	// TopicSessionReceipt is a mutually signed session receipt exchange endpoint for p2p communication.
+	TopicSessionReceipt = "p2p-session-receipt"
+	// TopicSessionRekey is a session keys rotation endpoint for p2p communication.
+	TopicSessionRekey = "p2p-session-rekey"
 
 	// TopicPaymentMessage is a payment messages endpoint for p2p communication.
 	TopicPaymentMessage = "p2p-payment-message"
 	// TopicPaymentInvoice is a payment invoices endpoint for p2p communication.
 	TopicPaymentInvoice = "p2p-payment-invoice"
+
+	// TopicManagementPair is a remote management pairing endpoint for p2p communication.
+	TopicManagementPair = "p2p-management-pair"
+	// TopicManagementRequest is a remote management API request endpoint for p2p communication.
+	TopicManagementRequest = "p2p-management-request"
 )
 
 // Message represent message with data bytes.
@@ -68,6 +81,7 @@ const (
 	statusCodePublicErr          = 2
 	statusCodeInternalErr        = 3
 	statusCodeHandlerNotFoundErr = 4
+	statusCodeTopicForbiddenErr  = 5
 )
 
 // transportMsg is internal structure for sending and receiving messages.
@@ -20,7 +20,14 @@ package p2p
 import (
 	"bytes"
 	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 // Size of Ethereum signature, for instance
@@ -136,3 +143,86 @@ func TestTransportMessagePiping(t *testing.T) {
 	}
 
 }
+
+func TestProtobufWireReaderRejectsOversizedFrame(t *testing.T) {
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(lenBuf, math.MaxUint64)
+
+	reader := newProtobufWireReader(bytes.NewReader(lenBuf[:n]))
+
+	var msg transportMsg
+	err := msg.readFrom(reader)
+
+	var decodeErr *DecodeError
+	assert.True(t, errors.As(err, &decodeErr))
+	assert.Equal(t, "length", decodeErr.Field)
+	assert.ErrorIs(t, err, ErrFrameTooLarge)
+
+	// Reader must stay closed after a framing error.
+	assert.Equal(t, io.EOF, msg.readFrom(reader))
+}
+
+func TestProtobufWireReaderRejectsLongTopic(t *testing.T) {
+	var out bytes.Buffer
+	err := newProtobufWireWriter(&out).writeMsg(&transportMsg{topic: strings.Repeat("t", maxTopicLen+1)})
+	assert.NoError(t, err)
+
+	var msg transportMsg
+	err = msg.readFrom(newProtobufWireReader(&out))
+	assert.ErrorIs(t, err, ErrFieldTooLong)
+}
+
+func TestProtobufWireReaderRejectsMalformedEnvelope(t *testing.T) {
+	frame := []byte{3, 0xff, 0xff, 0xff}
+
+	var msg transportMsg
+	err := msg.readFrom(newProtobufWireReader(bytes.NewReader(frame)))
+	assert.ErrorIs(t, err, ErrMalformedFrame)
+}
+
+func TestTextWireReaderRejectsOversizedFrame(t *testing.T) {
+	var in bytes.Buffer
+	in.WriteString("Request-ID:1\r\nTopic:test\r\nStatus-Code:0\r\nMessage:\r\n\r\n")
+	in.Write(bytes.Repeat([]byte("a"), maxTransportMsgLen))
+
+	var msg transportMsg
+	err := msg.readFrom(newTextWireReader(&in))
+	assert.ErrorIs(t, err, ErrFrameTooLarge)
+}
+
+func TestDecodeFrame(t *testing.T) {
+	var out bytes.Buffer
+	sent := transportMsg{id: 7, statusCode: 1, topic: "topic", msg: "msg", data: []byte("data")}
+	assert.NoError(t, newProtobufWireWriter(&out).writeMsg(&sent))
+	frame := out.Bytes()
+
+	var msg transportMsg
+	n, err := decodeFrame(frame, &msg)
+	assert.NoError(t, err)
+	assert.Equal(t, len(frame), n)
+	assert.Equal(t, sent, msg)
+
+	_, err = decodeFrame(frame[:len(frame)-1], &msg)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func FuzzDecodeFrame(f *testing.F) {
+	var out bytes.Buffer
+	_ = newProtobufWireWriter(&out).writeMsg(&transportMsg{id: 1, topic: "topic", data: []byte("data")})
+	f.Add(out.Bytes())
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var msg transportMsg
+		n, err := decodeFrame(data, &msg)
+		if err != nil {
+			return
+		}
+		if n > len(data) {
+			t.Fatalf("consumed %d bytes of %d", n, len(data))
+		}
+		if len(msg.topic) > maxTopicLen || len(msg.msg) > maxStatusMsgLen {
+			t.Fatal("field limits not enforced")
+		}
+	})
+}
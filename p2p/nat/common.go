@@ -17,10 +17,15 @@
 
 package nat
 
+import "time"
+
 const (
 	// AppTopicNATTraversalMethod represent NAT traversal method topic.
 	AppTopicNATTraversalMethod = "NAT-traversal-method"
 
+	// AppTopicNATTraversalAttempt represents topic of provider attempts to establish p2p connection with consumer.
+	AppTopicNATTraversalAttempt = "NAT-traversal-attempt"
+
 	requiredConnCount = 2
 	pingMaxPorts      = 20
 )
@@ -31,3 +36,13 @@ type NATTraversalMethod struct {
 	Method   string
 	Success  bool
 }
+
+// NATTraversalAttempt represents outcome of a single provider attempt to reach consumer
+// using the prepared NAT traversal method.
+type NATTraversalAttempt struct {
+	Identity    string
+	ServiceType string
+	Method      string
+	Duration    time.Duration
+	Success     bool
+}
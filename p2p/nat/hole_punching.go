@@ -29,8 +29,9 @@ import (
 	"github.com/mysteriumnetwork/node/nat/traversal"
 )
 
-// StartPorts starts the process of serving connections for the provided ports.
-type StartPorts func(ctx context.Context, peerIP string, peerPorts, localPorts []int) ([]*net.UDPConn, error)
+// StartPorts starts the process of serving connections for the provided ports
+// bound to the local IP address, any local address is used if it is empty.
+type StartPorts func(ctx context.Context, localIP, peerIP string, peerPorts, localPorts []int) ([]*net.UDPConn, error)
 
 type natHolePunchingPort struct {
 	pool   *port.Pool
@@ -68,6 +69,6 @@ func (hp *natHolePunchingPort) PreparePorts() (ports []int, release func(), star
 	return ports, func() {}, hp.Start, nil
 }
 
-func (hp *natHolePunchingPort) Start(ctx context.Context, peerIP string, peerPorts, localPorts []int) ([]*net.UDPConn, error) {
-	return hp.pinger.PingConsumerPeer(context.Background(), "remove this id", peerIP, localPorts, peerPorts, providerInitialTTL, requiredConnCount)
+func (hp *natHolePunchingPort) Start(ctx context.Context, localIP, peerIP string, peerPorts, localPorts []int) ([]*net.UDPConn, error) {
+	return hp.pinger.PingConsumerPeer(context.Background(), "remove this id", localIP, peerIP, localPorts, peerPorts, providerInitialTTL, requiredConnCount)
 }
@@ -28,6 +28,14 @@ import (
 	"github.com/mysteriumnetwork/node/nat/mapping"
 )
 
+// upnpPortMapper is shared by UPnP port providers, so that active mappings can be renewed at once.
+var upnpPortMapper = mapping.NewPortMapper(mapping.DefaultConfig(), eventbus.New())
+
+// RemapUPnPPorts re-adds active UPnP port mappings, e.g. after the router got a new public IP.
+func RemapUPnPPorts() {
+	upnpPortMapper.Remap()
+}
+
 type upnpPort struct {
 	pool       *port.Pool
 	portMapper mapping.PortMapper
@@ -47,7 +55,7 @@ func NewUPnPPortProvider() PortProvider {
 
 	return &upnpPort{
 		pool:       port.NewFixedRangePool(udpPortRange),
-		portMapper: mapping.NewPortMapper(mapping.DefaultConfig(), eventbus.New()),
+		portMapper: upnpPortMapper,
 	}
 }
 
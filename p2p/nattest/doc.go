@@ -0,0 +1,27 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package nattest runs p2p Dial/Listen, keepalive and service handshake between
+// consumer and provider processes placed behind simulated NATs.
+//
+// Every scenario builds its own topology of Linux network namespaces: a shared
+// "internet" with the broker relay and STUN servers, and a NAT router namespace
+// in front of each peer configured with iptables to behave as the requested NAT type.
+// Tests are behind the nattest build tag and require root, iproute2 and iptables:
+//
+//	sudo go test -tags nattest -v ./p2p/nattest/
+package nattest
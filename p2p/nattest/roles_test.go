@@ -0,0 +1,449 @@
+//go:build linux && nattest
+
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nattest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pion/stun"
+
+	"github.com/mysteriumnetwork/node/communication/relay"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/router"
+	"github.com/mysteriumnetwork/node/trace"
+)
+
+// Roles run in child processes of the test binary started inside namespaces.
+const (
+	envRole       = "NATTEST_ROLE"
+	envLocalIP    = "NATTEST_LOCAL_IP"
+	envPublicIP   = "NATTEST_PUBLIC_IP"
+	envProviderID = "NATTEST_PROVIDER_ID"
+
+	roleInternet = "internet"
+	roleProvider = "provider"
+	roleConsumer = "consumer"
+
+	// exitTraversalFailed is the consumer exit code when the p2p channel can not be dialed.
+	exitTraversalFailed = 3
+
+	readyPrefix = "READY"
+	serviceType = "wireguard"
+	dialTimeout = 30 * time.Second
+
+	keepAliveInterval = 2 * time.Second
+	// keepAliveDuration outlives the NAT mapping timeout, so the channel only survives if keepalive holds the mappings.
+	keepAliveDuration = 2*natUDPTimeout*time.Second + time.Second
+)
+
+var relayURL = fmt.Sprintf("http://%s:%d/", relayIP, relayPort)
+
+func runRole(role string) int {
+	var err error
+	switch role {
+	case roleInternet:
+		err = runInternet()
+	case roleProvider:
+		err = runProvider()
+	case roleConsumer:
+		err = runConsumer()
+	default:
+		err = fmt.Errorf("unknown role %q", role)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", role, err)
+		var traversalErr *traversalError
+		if errors.As(err, &traversalErr) {
+			return exitTraversalFailed
+		}
+		return 1
+	}
+	return 0
+}
+
+type traversalError struct {
+	err error
+}
+
+func (e *traversalError) Error() string {
+	return "traversal failed: " + e.err.Error()
+}
+
+// runInternet serves the broker relay and STUN servers until killed.
+func runInternet() error {
+	for _, addr := range []string{stunIP, stunAltIP} {
+		conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP(addr), Port: stunPort})
+		if err != nil {
+			return err
+		}
+		go serveSTUN(conn)
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", relayIP, relayPort))
+	if err != nil {
+		return err
+	}
+	fmt.Println(readyPrefix)
+	return http.Serve(listener, unsignedSubjects(relay.NewServer()))
+}
+
+// unsignedSubjects strips subject signatures before they reach the relay, as the broker does
+// once it has verified them, so that signed subscriptions match plain publishes.
+func unsignedSubjects(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if subject, ok := body["subject"].(string); ok && strings.HasPrefix(subject, "signed.") {
+			if parts := strings.SplitN(subject, ".", 4); len(parts) == 4 {
+				body["subject"] = parts[3]
+			}
+		}
+		raw, err := json.Marshal(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(raw))
+		r.ContentLength = int64(len(raw))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveSTUN answers binding requests with the address they were received from.
+func serveSTUN(conn *net.UDPConn) {
+	buf := make([]byte, 1024)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+
+		req := &stun.Message{Raw: append([]byte{}, buf[:n]...)}
+		if err := req.Decode(); err != nil {
+			continue
+		}
+		res, err := stun.Build(
+			stun.NewTransactionIDSetter(req.TransactionID),
+			stun.BindingSuccess,
+			&stun.XORMappedAddress{IP: from.IP, Port: from.Port},
+			stun.Fingerprint,
+		)
+		if err != nil {
+			continue
+		}
+		_, _ = conn.WriteToUDP(res.Raw, from)
+	}
+}
+
+// runProvider listens for p2p channels, answering handshakes, keepalives and echoing service traffic.
+func runProvider() error {
+	configure()
+	providerID, signerFactory, decrypterFactory, err := newIdentity()
+	if err != nil {
+		return err
+	}
+
+	brokerConn, err := relay.NewConnector(relayURL, &http.Transport{}).Connect()
+	if err != nil {
+		return err
+	}
+
+	resolver := ip.NewResolverMockMultiple(os.Getenv(envLocalIP), os.Getenv(envPublicIP))
+	listener := p2p.NewListener(brokerConn, signerFactory, decrypterFactory, identity.NewVerifierSigned(), resolver, eventbus.New(), p2p.DefaultExchangeLimits(), nil, p2p.TopicACL{}, time.Now)
+	_, err = listener.Listen(providerID, serviceType, func(ch p2p.Channel) {
+		ch.Handle(p2p.TopicSessionCreate, func(c p2p.Context) error {
+			var req pb.PingPong
+			if err := c.Request().UnmarshalProto(&req); err != nil {
+				return err
+			}
+			return c.OkWithReply(p2p.ProtoMessage(&pb.PingPong{Value: req.Value + "-ack"}))
+		})
+		ch.Handle(p2p.TopicKeepAlive, func(c p2p.Context) error {
+			return c.OK()
+		})
+		go echo(ch.ServiceConn())
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(readyPrefix, providerID.Address)
+	select {}
+}
+
+func echo(conn *net.UDPConn) {
+	buf := make([]byte, 1500)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+		_, _ = conn.Write(buf[:n])
+	}
+}
+
+// runConsumer dials the provider, runs the handshake, keeps the channel alive past NAT timeouts and
+// checks that both the channel and the service connection still work.
+func runConsumer() error {
+	configure()
+	consumerID, signerFactory, decrypterFactory, err := newIdentity()
+	if err != nil {
+		return err
+	}
+	providerID := identity.FromAddress(os.Getenv(envProviderID))
+
+	verifierFactory := func(id identity.Identity) identity.Verifier {
+		return identity.NewVerifierIdentity(id)
+	}
+	resolver := ip.NewResolverMockMultiple(os.Getenv(envLocalIP), os.Getenv(envPublicIP))
+	ports := port.NewFixedRangePool(port.Range{Start: 30000, End: 30200})
+	dialer := p2p.NewDialer(relay.NewConnector(relayURL, &http.Transport{}), signerFactory, decrypterFactory, verifierFactory, resolver, ports, eventbus.New())
+
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	contact := p2p.ContactDefinition{BrokerAddresses: []string{fmt.Sprintf("nats://%s:4222", relayIP)}}
+	ch, err := dialer.Dial(ctx, consumerID, providerID, serviceType, contact, trace.NewTracer("nattest"))
+	if err != nil {
+		return &traversalError{err: err}
+	}
+	defer ch.Close()
+
+	if err := handshake(ch, "hello"); err != nil {
+		return err
+	}
+	if err := echoService(ch.ServiceConn()); err != nil {
+		return err
+	}
+
+	for start := time.Now(); time.Since(start) < keepAliveDuration; time.Sleep(keepAliveInterval) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_, err := ch.Send(ctx, p2p.TopicKeepAlive, &p2p.Message{Data: []byte{}})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("keepalive failed: %w", err)
+		}
+	}
+
+	if err := handshake(ch, "again"); err != nil {
+		return fmt.Errorf("channel lost after keepalive: %w", err)
+	}
+	return nil
+}
+
+func handshake(ch p2p.Channel, value string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := ch.Send(ctx, p2p.TopicSessionCreate, p2p.ProtoMessage(&pb.PingPong{Value: value}))
+	if err != nil {
+		return fmt.Errorf("handshake failed: %w", err)
+	}
+	var reply pb.PingPong
+	if err := res.UnmarshalProto(&reply); err != nil {
+		return err
+	}
+	if reply.Value != value+"-ack" {
+		return fmt.Errorf("unexpected handshake reply %q", reply.Value)
+	}
+	return nil
+}
+
+func echoService(conn *net.UDPConn) error {
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		return err
+	}
+	defer conn.SetReadDeadline(time.Time{})
+
+	buf := make([]byte, 64)
+	for attempt := 0; attempt < 5; attempt++ {
+		if _, err := conn.Write([]byte("service")); err != nil {
+			return err
+		}
+		n, err := conn.Read(buf)
+		if err == nil && string(buf[:n]) == "service" {
+			return nil
+		}
+	}
+	return errors.New("service connection does not pass traffic")
+}
+
+func configure() {
+	config.Current.SetDefault(config.FlagSTUNservers.Name, []string{
+		net.JoinHostPort(stunIP, fmt.Sprint(stunPort)),
+		net.JoinHostPort(stunAltIP, fmt.Sprint(stunPort)),
+	})
+	config.Current.SetDefault(config.FlagTraversal.Name, "holepunching")
+	config.Current.SetDefault(config.FlagUDPListenPorts.Name, "20000:20200")
+	router.DefaultRouter = noopRouter{}
+}
+
+// newIdentity creates an unlocked identity, returning it with its signer and decrypter factories.
+func newIdentity() (identity.Identity, identity.SignerFactory, identity.DecrypterFactory, error) {
+	ks := identity.NewMockKeystore()
+	account, err := ks.NewAccount("")
+	if err != nil {
+		return identity.Identity{}, nil, nil, err
+	}
+	if err := ks.Unlock(account, ""); err != nil {
+		return identity.Identity{}, nil, nil, err
+	}
+
+	signerFactory := func(id identity.Identity) identity.Signer {
+		return identity.NewSigner(ks, id)
+	}
+	decrypterFactory := func(id identity.Identity) identity.Decrypter {
+		return identity.NewDecrypter(ks, id)
+	}
+	return identity.FromAddress(account.Address.Hex()), signerFactory, decrypterFactory, nil
+}
+
+// noopRouter keeps routes untouched, peers already reach each other directly.
+type noopRouter struct{}
+
+func (noopRouter) ExcludeIP(net.IP) error          { return nil }
+func (noopRouter) RemoveExcludedIP(net.IP) error   { return nil }
+func (noopRouter) AddTunnelRoutes(string) error    { return nil }
+func (noopRouter) DeleteTunnelRoutes(string) error { return nil }
+func (noopRouter) Clean() error                    { return nil }
+func (noopRouter) Recover()                        {}
+
+// process is a role running in a namespace.
+type process struct {
+	cmd     *exec.Cmd
+	readyCh chan string
+	exit    chan error
+}
+
+// startRole runs the test binary in the namespace as the given role.
+func startRole(t *testing.T, namespace, role string, env ...string) *process {
+	t.Helper()
+
+	stdout, stdoutWriter := io.Pipe()
+	cmd := exec.Command("ip", "netns", "exec", namespace, os.Args[0])
+	cmd.Env = append(os.Environ(), append(env, envRole+"="+role)...)
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = &testWriter{t: t, prefix: role}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &process{cmd: cmd, readyCh: make(chan string, 1), exit: make(chan error, 1)}
+	go p.scan(stdout)
+	go func() {
+		err := cmd.Wait()
+		stdoutWriter.Close()
+		p.exit <- err
+	}()
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		p.exit <- <-p.exit
+	})
+	return p
+}
+
+func (p *process) scan(stdout io.Reader) {
+	lines := bufio.NewScanner(stdout)
+	for lines.Scan() {
+		if text := lines.Text(); strings.HasPrefix(text, readyPrefix) {
+			p.readyCh <- strings.TrimSpace(strings.TrimPrefix(text, readyPrefix))
+			break
+		}
+	}
+	// Keep draining, so the role never blocks writing its output.
+	for lines.Scan() {
+	}
+	close(p.readyCh)
+}
+
+// ready waits for the role to report readiness, returning the rest of the ready line.
+func (p *process) ready(t *testing.T) string {
+	t.Helper()
+
+	select {
+	case text, ok := <-p.readyCh:
+		if !ok {
+			t.Fatal("role exited before becoming ready")
+		}
+		return text
+	case <-time.After(10 * time.Second):
+		t.Fatal("role did not become ready")
+	}
+	return ""
+}
+
+// wait returns the exit code of the role.
+func (p *process) wait(t *testing.T, timeout time.Duration) int {
+	t.Helper()
+
+	select {
+	case err := <-p.exit:
+		p.exit <- err
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return 0
+	case <-time.After(timeout):
+		t.Fatal("role did not finish in time")
+	}
+	return -1
+}
+
+// testWriter forwards role logs to the test log.
+type testWriter struct {
+	t      *testing.T
+	prefix string
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.t.Logf("%s: %s", w.prefix, strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
@@ -0,0 +1,198 @@
+//go:build linux && nattest
+
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nattest
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// NATType describes how the router in front of a peer translates and filters UDP traffic.
+type NATType string
+
+const (
+	// NATNone places the peer directly on the internet.
+	NATNone NATType = "public"
+	// NATFullCone maps every local port to the same public port and accepts packets from any host.
+	NATFullCone NATType = "full-cone"
+	// NATPortRestrictedCone maps every local port to the same public port, accepting packets only
+	// from hosts and ports the peer has sent to.
+	NATPortRestrictedCone NATType = "port-restricted-cone"
+	// NATSymmetric maps every destination to a different random public port.
+	NATSymmetric NATType = "symmetric"
+)
+
+const (
+	relayIP   = "203.0.113.254"
+	stunIP    = "203.0.113.254"
+	stunAltIP = "203.0.113.253"
+	relayPort = 8080
+	stunPort  = 3478
+	// natUDPTimeout is short for keepalive to matter within a test run.
+	natUDPTimeout = 10
+)
+
+// traversable tells whether hole punching is expected to succeed between peers behind the given NATs.
+// Symmetric NAT mappings are unpredictable, so they only work against peers accepting packets from any port.
+func traversable(a, b NATType) bool {
+	filtering := func(t NATType) bool { return t == NATPortRestrictedCone || t == NATSymmetric }
+	if a == NATSymmetric && filtering(b) || b == NATSymmetric && filtering(a) {
+		return false
+	}
+	return true
+}
+
+// peer is a host namespace behind an optional NAT router namespace.
+type peer struct {
+	namespace string
+	localIP   string
+	publicIP  string
+}
+
+// topology is a set of network namespaces simulating consumer and provider behind NATs.
+type topology struct {
+	prefix   string
+	wan      string
+	consumer peer
+	provider peer
+	created  []string
+}
+
+func requireTopologyTools(t *testing.T, nats ...NATType) {
+	if os.Geteuid() != 0 {
+		t.Skip("network namespaces require root")
+	}
+	if _, err := exec.LookPath("ip"); err != nil {
+		t.Skip("iproute2 is not installed")
+	}
+	for _, nat := range nats {
+		if nat == NATNone {
+			continue
+		}
+		if _, err := exec.LookPath("iptables"); err != nil {
+			t.Skip("iptables is not installed")
+		}
+	}
+}
+
+// newTopology creates namespaces connected to a shared internet bridge, which are removed once the test ends.
+func newTopology(t *testing.T, name string, consumerNAT, providerNAT NATType) *topology {
+	topo := &topology{prefix: name}
+	t.Cleanup(topo.teardown)
+
+	topo.wan = topo.namespace(t, "wan")
+	topo.ip(t, topo.wan, "link", "add", "br0", "type", "bridge")
+	topo.ip(t, topo.wan, "addr", "add", relayIP+"/24", "dev", "br0")
+	topo.ip(t, topo.wan, "addr", "add", stunAltIP+"/24", "dev", "br0")
+	topo.ip(t, topo.wan, "link", "set", "br0", "up")
+
+	topo.consumer = topo.addPeer(t, "c", 1, consumerNAT)
+	topo.provider = topo.addPeer(t, "p", 2, providerNAT)
+	return topo
+}
+
+func (topo *topology) addPeer(t *testing.T, name string, n int, nat NATType) peer {
+	publicIP := fmt.Sprintf("203.0.113.%d", n)
+	host := topo.namespace(t, name)
+	wanPort := name + "0"
+
+	if nat == NATNone {
+		topo.link(t, host, "eth0", topo.wan, wanPort)
+		topo.ip(t, topo.wan, "link", "set", wanPort, "master", "br0", "up")
+		topo.ip(t, host, "addr", "add", publicIP+"/24", "dev", "eth0")
+		topo.ip(t, host, "link", "set", "eth0", "up")
+		return peer{namespace: host, localIP: publicIP, publicIP: publicIP}
+	}
+
+	router := topo.namespace(t, name+"-nat")
+	localIP := fmt.Sprintf("10.0.%d.2", n)
+	gatewayIP := fmt.Sprintf("10.0.%d.1", n)
+
+	topo.link(t, router, "wan0", topo.wan, wanPort)
+	topo.ip(t, topo.wan, "link", "set", wanPort, "master", "br0", "up")
+	topo.ip(t, router, "addr", "add", publicIP+"/24", "dev", "wan0")
+	topo.ip(t, router, "link", "set", "wan0", "up")
+
+	topo.link(t, host, "eth0", router, "lan0")
+	topo.ip(t, router, "addr", "add", gatewayIP+"/24", "dev", "lan0")
+	topo.ip(t, router, "link", "set", "lan0", "up")
+	topo.ip(t, host, "addr", "add", localIP+"/24", "dev", "eth0")
+	topo.ip(t, host, "link", "set", "eth0", "up")
+	topo.ip(t, host, "route", "add", "default", "via", gatewayIP)
+
+	topo.exec(t, router, "sysctl", "-qw", "net.ipv4.ip_forward=1")
+	switch nat {
+	case NATFullCone:
+		topo.exec(t, router, "iptables", "-t", "nat", "-A", "POSTROUTING", "-o", "wan0", "-j", "SNAT", "--to-source", publicIP)
+		topo.exec(t, router, "iptables", "-t", "nat", "-A", "PREROUTING", "-i", "wan0", "-p", "udp", "-j", "DNAT", "--to-destination", localIP)
+	case NATPortRestrictedCone:
+		topo.exec(t, router, "iptables", "-t", "nat", "-A", "POSTROUTING", "-o", "wan0", "-j", "MASQUERADE")
+	case NATSymmetric:
+		topo.exec(t, router, "iptables", "-t", "nat", "-A", "POSTROUTING", "-o", "wan0", "-j", "MASQUERADE", "--random-fully")
+	default:
+		t.Fatalf("unknown NAT type %q", nat)
+	}
+	// The conntrack sysctl only exists once NAT rules load the module.
+	topo.tryExec(router, "sysctl", "-qw", fmt.Sprintf("net.netfilter.nf_conntrack_udp_timeout=%d", natUDPTimeout))
+
+	return peer{namespace: host, localIP: localIP, publicIP: publicIP}
+}
+
+func (topo *topology) namespace(t *testing.T, name string) string {
+	ns := topo.prefix + "-" + name
+	topo.run(t, "ip", "netns", "add", ns)
+	topo.created = append(topo.created, ns)
+	topo.ip(t, ns, "link", "set", "lo", "up")
+	return ns
+}
+
+func (topo *topology) link(t *testing.T, ns1, name1, ns2, name2 string) {
+	topo.run(t, "ip", "link", "add", name1, "netns", ns1, "type", "veth", "peer", "name", name2, "netns", ns2)
+}
+
+func (topo *topology) ip(t *testing.T, ns string, args ...string) {
+	topo.run(t, append([]string{"ip", "-n", ns}, args...)...)
+}
+
+func (topo *topology) exec(t *testing.T, ns string, args ...string) {
+	topo.run(t, append([]string{"ip", "netns", "exec", ns}, args...)...)
+}
+
+func (topo *topology) tryExec(ns string, args ...string) {
+	_ = exec.Command("ip", append([]string{"netns", "exec", ns}, args...)...).Run()
+}
+
+func (topo *topology) run(t *testing.T, args ...string) {
+	t.Helper()
+	out, err := exec.Command(args[0], args[1:]...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("%q failed: %v: %s", strings.Join(args, " "), err, out)
+	}
+}
+
+// teardown removes namespaces, which also removes links and NAT rules inside them.
+func (topo *topology) teardown() {
+	for i := len(topo.created) - 1; i >= 0; i-- {
+		_ = exec.Command("ip", "netns", "del", topo.created[i]).Run()
+	}
+}
@@ -0,0 +1,75 @@
+//go:build linux && nattest
+
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nattest
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	if role := os.Getenv(envRole); role != "" {
+		os.Exit(runRole(role))
+	}
+	os.Exit(m.Run())
+}
+
+func TestTraversal(t *testing.T) {
+	nats := []NATType{NATNone, NATFullCone, NATPortRestrictedCone, NATSymmetric}
+
+	n := 0
+	for _, consumerNAT := range nats {
+		for _, providerNAT := range nats {
+			consumerNAT, providerNAT := consumerNAT, providerNAT
+			name := fmt.Sprintf("nt%d-%d", os.Getpid()%10000, n)
+			n++
+
+			t.Run(fmt.Sprintf("%s consumer to %s provider", consumerNAT, providerNAT), func(t *testing.T) {
+				requireTopologyTools(t, consumerNAT, providerNAT)
+				topo := newTopology(t, name, consumerNAT, providerNAT)
+
+				startRole(t, topo.wan, roleInternet).ready(t)
+
+				provider := startRole(t, topo.provider.namespace, roleProvider,
+					envLocalIP+"="+topo.provider.localIP,
+					envPublicIP+"="+topo.provider.publicIP,
+				)
+				providerID := provider.ready(t)
+
+				consumer := startRole(t, topo.consumer.namespace, roleConsumer,
+					envLocalIP+"="+topo.consumer.localIP,
+					envPublicIP+"="+topo.consumer.publicIP,
+					envProviderID+"="+providerID,
+				)
+				code := consumer.wait(t, dialTimeout+keepAliveDuration+30*time.Second)
+
+				if traversable(consumerNAT, providerNAT) {
+					if code != 0 {
+						t.Fatalf("expected traversal to succeed, consumer exited with %d", code)
+					}
+				} else if code != exitTraversalFailed {
+					t.Fatalf("expected traversal to fail, consumer exited with %d", code)
+				}
+			})
+		}
+	}
+}
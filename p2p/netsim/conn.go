@@ -0,0 +1,207 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netsim
+
+import (
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const inboxSize = 1024
+
+var errNotUDPAddr = errors.New("not a UDP address")
+
+type packet struct {
+	from *net.UDPAddr
+	data []byte
+}
+
+// Conn is a simulated UDP connection implementing net.PacketConn.
+type Conn struct {
+	host *Host
+	port int
+
+	inbox     chan packet
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	mu              sync.Mutex
+	readDeadline    time.Time
+	deadlineChanged chan struct{}
+	writeDeadline   time.Time
+}
+
+var _ net.PacketConn = (*Conn)(nil)
+
+func newConn(host *Host, port int) *Conn {
+	return &Conn{
+		host:            host,
+		port:            port,
+		inbox:           make(chan packet, inboxSize),
+		closed:          make(chan struct{}),
+		deadlineChanged: make(chan struct{}),
+	}
+}
+
+// ReadFrom reads a packet from the connection, copying the payload into p.
+func (c *Conn) ReadFrom(p []byte) (int, net.Addr, error) {
+	n, addr, err := c.ReadFromUDP(p)
+	if addr == nil {
+		return n, nil, err
+	}
+	return n, addr, err
+}
+
+// ReadFromUDP acts like ReadFrom but returns a UDPAddr.
+func (c *Conn) ReadFromUDP(p []byte) (int, *net.UDPAddr, error) {
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		c.mu.Lock()
+		deadline, changed := c.readDeadline, c.deadlineChanged
+		c.mu.Unlock()
+
+		select {
+		case <-c.closed:
+			return 0, nil, c.opError("read", net.ErrClosed)
+		default:
+		}
+
+		var timeout <-chan time.Time
+		if !deadline.IsZero() {
+			d := time.Until(deadline)
+			if d <= 0 {
+				return 0, nil, c.opError("read", os.ErrDeadlineExceeded)
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.NewTimer(d)
+			timeout = timer.C
+		}
+
+		select {
+		case pkt := <-c.inbox:
+			return copy(p, pkt.data), pkt.from, nil
+		case <-c.closed:
+			return 0, nil, c.opError("read", net.ErrClosed)
+		case <-timeout:
+			return 0, nil, c.opError("read", os.ErrDeadlineExceeded)
+		case <-changed:
+		}
+	}
+}
+
+// WriteTo sends a packet to the given UDP address.
+func (c *Conn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	udpAddr, ok := addr.(*net.UDPAddr)
+	if !ok {
+		return 0, c.opError("write", errNotUDPAddr)
+	}
+	return c.WriteToUDP(p, udpAddr)
+}
+
+// WriteToUDP acts like WriteTo but takes a UDPAddr.
+func (c *Conn) WriteToUDP(p []byte, addr *net.UDPAddr) (int, error) {
+	select {
+	case <-c.closed:
+		return 0, c.opError("write", net.ErrClosed)
+	default:
+	}
+
+	c.mu.Lock()
+	deadline := c.writeDeadline
+	c.mu.Unlock()
+	if !deadline.IsZero() && !time.Now().Before(deadline) {
+		return 0, c.opError("write", os.ErrDeadlineExceeded)
+	}
+
+	c.host.network.send(c.host, c.port, p, addr)
+	return len(p), nil
+}
+
+// Close closes the connection and releases its port and NAT mappings.
+func (c *Conn) Close() error {
+	err := c.opError("close", net.ErrClosed)
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.host.unbind(c.port)
+		err = nil
+	})
+	return err
+}
+
+// LocalAddr returns the address the connection is bound to on the host.
+func (c *Conn) LocalAddr() net.Addr {
+	return &net.UDPAddr{IP: c.host.ip, Port: c.port}
+}
+
+// SetDeadline sets both read and write deadlines.
+func (c *Conn) SetDeadline(t time.Time) error {
+	if err := c.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.SetWriteDeadline(t)
+}
+
+// SetReadDeadline sets the read deadline, unblocking pending reads to re-evaluate it.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.readDeadline = t
+	close(c.deadlineChanged)
+	c.deadlineChanged = make(chan struct{})
+	return nil
+}
+
+// SetWriteDeadline sets the write deadline.
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.writeDeadline = t
+	return nil
+}
+
+func (c *Conn) push(pkt packet) bool {
+	select {
+	case <-c.closed:
+		return false
+	default:
+	}
+
+	select {
+	case c.inbox <- pkt:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Conn) opError(op string, err error) error {
+	return &net.OpError{Op: op, Net: "udp", Addr: c.LocalAddr(), Err: err}
+}
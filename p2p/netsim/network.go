@@ -0,0 +1,362 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package netsim implements an in-process UDP network simulation used to
+// regression test p2p dialing, listening and keepalive logic without real sockets.
+//
+// Every random decision (loss, duplication, jitter) is taken from a seeded
+// source, so a scenario replays the same way given the same seed.
+package netsim
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+// NATType describes how a host translates and filters UDP traffic.
+type NATType int
+
+const (
+	// NATNone exposes host ports as is and accepts traffic from anyone.
+	NATNone NATType = iota
+	// NATFullCone maps a local port to a single public port and accepts traffic from anyone.
+	NATFullCone
+	// NATRestrictedCone maps a local port to a single public port and only accepts
+	// traffic from IPs the local port has sent to.
+	NATRestrictedCone
+	// NATPortRestrictedCone maps a local port to a single public port and only accepts
+	// traffic from IP:port pairs the local port has sent to.
+	NATPortRestrictedCone
+	// NATSymmetric maps every local port and destination pair to a new public port
+	// and only accepts traffic from that destination.
+	NATSymmetric
+)
+
+// String returns a human readable NAT type.
+func (t NATType) String() string {
+	switch t {
+	case NATNone:
+		return "none"
+	case NATFullCone:
+		return "full-cone"
+	case NATRestrictedCone:
+		return "restricted-cone"
+	case NATPortRestrictedCone:
+		return "port-restricted-cone"
+	case NATSymmetric:
+		return "symmetric"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(t))
+	}
+}
+
+// Link describes conditions applied to packets travelling between two hosts.
+type Link struct {
+	// Loss is the probability in range [0, 1] of a packet being dropped.
+	Loss float64
+	// Duplicate is the probability in range [0, 1] of a packet being delivered twice.
+	Duplicate float64
+	// Latency is the base one way delay of a packet.
+	Latency time.Duration
+	// Jitter is the maximum random delay added on top of latency.
+	// Packets sent close to each other may be reordered by it.
+	Jitter time.Duration
+}
+
+// Stats holds packet counters of the network.
+type Stats struct {
+	Sent       uint64
+	Delivered  uint64
+	Lost       uint64
+	Duplicated uint64
+	Filtered   uint64
+}
+
+// ErrAddressInUse is returned when a port is already bound on the host.
+var ErrAddressInUse = errors.New("address already in use")
+
+const firstEphemeralPort = 40000
+
+// Network is a simulated network connecting hosts.
+type Network struct {
+	mu    sync.Mutex
+	rnd   *rand.Rand
+	link  Link
+	links map[linkKey]Link
+	hosts map[string]*Host
+	stats Stats
+}
+
+type linkKey struct {
+	from, to *Host
+}
+
+// New creates a network seeded with seed, where link conditions apply
+// between every pair of hosts unless overridden with SetLink.
+func New(seed int64, link Link) *Network {
+	return &Network{
+		rnd:   rand.New(rand.NewSource(seed)),
+		link:  link,
+		links: make(map[linkKey]Link),
+		hosts: make(map[string]*Host),
+	}
+}
+
+// AddHost attaches a host with the given public IP behind the given NAT type.
+func (n *Network) AddHost(publicIP string, nat NATType) *Host {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	h := &Host{
+		network:  n,
+		ip:       net.ParseIP(publicIP).To4(),
+		nat:      nat,
+		conns:    make(map[int]*Conn),
+		mappings: make(map[mappingKey]*mapping),
+		external: make(map[int]*mapping),
+		nextPort: firstEphemeralPort,
+	}
+	n.hosts[h.ip.String()] = h
+	return h
+}
+
+// SetLink overrides link conditions for packets sent from one host to another.
+func (n *Network) SetLink(from, to *Host, link Link) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.links[linkKey{from: from, to: to}] = link
+}
+
+// Stats returns a snapshot of network packet counters.
+func (n *Network) Stats() Stats {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.stats
+}
+
+func (n *Network) send(from *Host, localPort int, payload []byte, to *net.UDPAddr) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.stats.Sent++
+
+	src := from.outbound(localPort, to)
+	dst, ok := n.hosts[to.IP.String()]
+	if !ok {
+		n.stats.Lost++
+		return
+	}
+
+	link, ok := n.links[linkKey{from: from, to: dst}]
+	if !ok {
+		link = n.link
+	}
+
+	if n.rnd.Float64() < link.Loss {
+		n.stats.Lost++
+		return
+	}
+
+	copies := 1
+	if n.rnd.Float64() < link.Duplicate {
+		copies++
+		n.stats.Duplicated++
+	}
+
+	for i := 0; i < copies; i++ {
+		delay := link.Latency
+		if link.Jitter > 0 {
+			delay += time.Duration(n.rnd.Int63n(int64(link.Jitter)))
+		}
+
+		pkt := packet{from: src, data: append([]byte(nil), payload...)}
+		if delay <= 0 {
+			n.deliverLocked(dst, to.Port, pkt)
+			continue
+		}
+		time.AfterFunc(delay, func() {
+			n.mu.Lock()
+			defer n.mu.Unlock()
+
+			n.deliverLocked(dst, to.Port, pkt)
+		})
+	}
+}
+
+func (n *Network) deliverLocked(dst *Host, port int, pkt packet) {
+	conn := dst.inbound(port, pkt.from)
+	if conn == nil {
+		n.stats.Filtered++
+		return
+	}
+	if conn.push(pkt) {
+		n.stats.Delivered++
+	} else {
+		n.stats.Lost++
+	}
+}
+
+// Host is a simulated machine attached to the network.
+type Host struct {
+	network *Network
+	ip      net.IP
+	nat     NATType
+
+	conns    map[int]*Conn
+	mappings map[mappingKey]*mapping
+	external map[int]*mapping
+	nextPort int
+}
+
+type mappingKey struct {
+	localPort int
+	dst       string
+}
+
+type mapping struct {
+	localPort    int
+	externalPort int
+	allowedIPs   map[string]struct{}
+	allowedAddrs map[string]struct{}
+}
+
+// IP returns public IP of the host.
+func (h *Host) IP() net.IP {
+	return h.ip
+}
+
+// NAT returns NAT type of the host.
+func (h *Host) NAT() NATType {
+	return h.nat
+}
+
+// ListenUDP binds a simulated UDP connection on the host.
+// Port 0 picks a free ephemeral port.
+func (h *Host) ListenUDP(port int) (*Conn, error) {
+	h.network.mu.Lock()
+	defer h.network.mu.Unlock()
+
+	if port == 0 {
+		port = h.freePortLocked()
+	}
+	if _, ok := h.conns[port]; ok {
+		return nil, fmt.Errorf("could not listen on %s:%d: %w", h.ip, port, ErrAddressInUse)
+	}
+
+	conn := newConn(h, port)
+	h.conns[port] = conn
+	return conn, nil
+}
+
+// Mappings returns the number of active NAT mappings on the host.
+func (h *Host) Mappings() int {
+	h.network.mu.Lock()
+	defer h.network.mu.Unlock()
+
+	return len(h.external)
+}
+
+func (h *Host) freePortLocked() int {
+	for {
+		port := h.nextPort
+		h.nextPort++
+		_, bound := h.conns[port]
+		_, mapped := h.external[port]
+		if !bound && !mapped {
+			return port
+		}
+	}
+}
+
+func (h *Host) unbind(port int) {
+	h.network.mu.Lock()
+	defer h.network.mu.Unlock()
+
+	delete(h.conns, port)
+	for key, m := range h.mappings {
+		if m.localPort == port {
+			delete(h.mappings, key)
+			delete(h.external, m.externalPort)
+		}
+	}
+}
+
+// outbound translates the source address of a packet sent from local port to dst,
+// creating the NAT mapping and remembering the destination for inbound filtering.
+func (h *Host) outbound(localPort int, dst *net.UDPAddr) *net.UDPAddr {
+	if h.nat == NATNone {
+		return &net.UDPAddr{IP: h.ip, Port: localPort}
+	}
+
+	key := mappingKey{localPort: localPort}
+	if h.nat == NATSymmetric {
+		key.dst = dst.String()
+	}
+
+	m, ok := h.mappings[key]
+	if !ok {
+		m = &mapping{
+			localPort:    localPort,
+			allowedIPs:   make(map[string]struct{}),
+			allowedAddrs: make(map[string]struct{}),
+		}
+		// Cone NATs try to preserve the local port, symmetric ones never do.
+		if _, taken := h.external[localPort]; h.nat != NATSymmetric && !taken {
+			m.externalPort = localPort
+		} else {
+			m.externalPort = h.freePortLocked()
+		}
+		h.mappings[key] = m
+		h.external[m.externalPort] = m
+	}
+	m.allowedIPs[dst.IP.String()] = struct{}{}
+	m.allowedAddrs[dst.String()] = struct{}{}
+
+	return &net.UDPAddr{IP: h.ip, Port: m.externalPort}
+}
+
+// inbound returns connection a packet sent to the public port should be delivered to,
+// or nil if the NAT filters it out.
+func (h *Host) inbound(port int, from *net.UDPAddr) *Conn {
+	if h.nat == NATNone {
+		return h.conns[port]
+	}
+
+	m, ok := h.external[port]
+	if !ok {
+		return nil
+	}
+
+	switch h.nat {
+	case NATRestrictedCone:
+		if _, ok := m.allowedIPs[from.IP.String()]; !ok {
+			return nil
+		}
+	case NATPortRestrictedCone, NATSymmetric:
+		if _, ok := m.allowedAddrs[from.String()]; !ok {
+			return nil
+		}
+	}
+
+	return h.conns[m.localPort]
+}
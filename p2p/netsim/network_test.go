@@ -0,0 +1,315 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netsim
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetwork_DeliversPackets(t *testing.T) {
+	n := New(1, Link{})
+	a, err := n.AddHost("10.0.0.1", NATNone).ListenUDP(1000)
+	require.NoError(t, err)
+	b, err := n.AddHost("10.0.0.2", NATNone).ListenUDP(2000)
+	require.NoError(t, err)
+
+	_, err = a.WriteTo([]byte("ping"), b.LocalAddr())
+	require.NoError(t, err)
+
+	msg, from := read(t, b)
+	assert.Equal(t, "ping", msg)
+	assert.Equal(t, "10.0.0.1:1000", from.String())
+	assert.Equal(t, Stats{Sent: 1, Delivered: 1}, n.Stats())
+}
+
+func TestNetwork_LossIsDeterministic(t *testing.T) {
+	delivered := func(seed int64) []string {
+		n := New(seed, Link{Loss: 0.5})
+		a, err := n.AddHost("10.0.0.1", NATNone).ListenUDP(1000)
+		require.NoError(t, err)
+		b, err := n.AddHost("10.0.0.2", NATNone).ListenUDP(2000)
+		require.NoError(t, err)
+
+		for _, msg := range []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"} {
+			_, err := a.WriteTo([]byte(msg), b.LocalAddr())
+			require.NoError(t, err)
+		}
+
+		var res []string
+		require.NoError(t, b.SetReadDeadline(time.Now().Add(50*time.Millisecond)))
+		buf := make([]byte, 16)
+		for {
+			n, _, err := b.ReadFrom(buf)
+			if err != nil {
+				return res
+			}
+			res = append(res, string(buf[:n]))
+		}
+	}
+
+	first := delivered(42)
+	assert.Equal(t, first, delivered(42))
+	assert.NotEmpty(t, first)
+	assert.Less(t, len(first), 10)
+}
+
+func TestNetwork_LatencyAndReordering(t *testing.T) {
+	n := New(7, Link{Latency: 20 * time.Millisecond, Jitter: 20 * time.Millisecond})
+	a, err := n.AddHost("10.0.0.1", NATNone).ListenUDP(1000)
+	require.NoError(t, err)
+	b, err := n.AddHost("10.0.0.2", NATNone).ListenUDP(2000)
+	require.NoError(t, err)
+
+	sent := []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}
+	start := time.Now()
+	for _, msg := range sent {
+		_, err := a.WriteTo([]byte(msg), b.LocalAddr())
+		require.NoError(t, err)
+	}
+
+	var received []string
+	for range sent {
+		msg, _ := read(t, b)
+		received = append(received, msg)
+	}
+
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.ElementsMatch(t, sent, received)
+	assert.NotEqual(t, sent, received)
+}
+
+func TestNetwork_Duplicates(t *testing.T) {
+	n := New(1, Link{Duplicate: 1})
+	a, err := n.AddHost("10.0.0.1", NATNone).ListenUDP(1000)
+	require.NoError(t, err)
+	b, err := n.AddHost("10.0.0.2", NATNone).ListenUDP(2000)
+	require.NoError(t, err)
+
+	_, err = a.WriteTo([]byte("ping"), b.LocalAddr())
+	require.NoError(t, err)
+
+	first, _ := read(t, b)
+	second, _ := read(t, b)
+	assert.Equal(t, "ping", first)
+	assert.Equal(t, "ping", second)
+	assert.Equal(t, uint64(1), n.Stats().Duplicated)
+}
+
+func TestNetwork_SetLinkOverridesDirection(t *testing.T) {
+	n := New(1, Link{})
+	hostA, hostB := n.AddHost("10.0.0.1", NATNone), n.AddHost("10.0.0.2", NATNone)
+	n.SetLink(hostA, hostB, Link{Loss: 1})
+
+	a, err := hostA.ListenUDP(1000)
+	require.NoError(t, err)
+	b, err := hostB.ListenUDP(2000)
+	require.NoError(t, err)
+
+	_, err = a.WriteTo([]byte("lost"), b.LocalAddr())
+	require.NoError(t, err)
+	_, err = b.WriteTo([]byte("pong"), a.LocalAddr())
+	require.NoError(t, err)
+
+	msg, _ := read(t, a)
+	assert.Equal(t, "pong", msg)
+	assertNoPacket(t, b)
+}
+
+func TestNetwork_NATFiltering(t *testing.T) {
+	tests := []struct {
+		nat             NATType
+		fromSameIP      bool
+		fromOtherIP     bool
+		samePublicPorts bool
+	}{
+		{nat: NATFullCone, fromSameIP: true, fromOtherIP: true, samePublicPorts: true},
+		{nat: NATRestrictedCone, fromSameIP: true, fromOtherIP: false, samePublicPorts: true},
+		{nat: NATPortRestrictedCone, fromSameIP: false, fromOtherIP: false, samePublicPorts: true},
+		{nat: NATSymmetric, fromSameIP: false, fromOtherIP: false, samePublicPorts: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nat.String(), func(t *testing.T) {
+			n := New(1, Link{})
+			client, err := n.AddHost("10.0.0.1", tt.nat).ListenUDP(1000)
+			require.NoError(t, err)
+			serverHost := n.AddHost("10.0.0.2", NATNone)
+			server, err := serverHost.ListenUDP(2000)
+			require.NoError(t, err)
+			serverOtherPort, err := serverHost.ListenUDP(2001)
+			require.NoError(t, err)
+			other, err := n.AddHost("10.0.0.3", NATNone).ListenUDP(3000)
+			require.NoError(t, err)
+
+			_, err = client.WriteTo([]byte("hello"), server.LocalAddr())
+			require.NoError(t, err)
+			_, from := read(t, server)
+
+			_, err = client.WriteTo([]byte("hello"), other.LocalAddr())
+			require.NoError(t, err)
+			_, fromOther := read(t, other)
+			assert.Equal(t, tt.samePublicPorts, from.Port == fromOther.Port)
+
+			_, err = server.WriteTo([]byte("reply"), from)
+			require.NoError(t, err)
+			msg, _ := read(t, client)
+			assert.Equal(t, "reply", msg)
+
+			// Previous message to other opened its mapping, so close it before probing filtering.
+			require.NoError(t, other.Close())
+			other, err = n.AddHost("10.0.0.4", NATNone).ListenUDP(3000)
+			require.NoError(t, err)
+
+			_, err = serverOtherPort.WriteTo([]byte("same-ip"), from)
+			require.NoError(t, err)
+			_, err = other.WriteTo([]byte("other-ip"), from)
+			require.NoError(t, err)
+
+			var received []string
+			require.NoError(t, client.SetReadDeadline(time.Now().Add(50*time.Millisecond)))
+			buf := make([]byte, 16)
+			for {
+				n, _, err := client.ReadFrom(buf)
+				if err != nil {
+					break
+				}
+				received = append(received, string(buf[:n]))
+			}
+			assert.Equal(t, tt.fromSameIP, contains(received, "same-ip"))
+			assert.Equal(t, tt.fromOtherIP, contains(received, "other-ip"))
+		})
+	}
+}
+
+func TestNetwork_HolePunching(t *testing.T) {
+	tests := []struct {
+		consumer, provider NATType
+		punched            bool
+	}{
+		{consumer: NATFullCone, provider: NATSymmetric, punched: true},
+		{consumer: NATPortRestrictedCone, provider: NATPortRestrictedCone, punched: true},
+		{consumer: NATPortRestrictedCone, provider: NATSymmetric, punched: false},
+		{consumer: NATSymmetric, provider: NATSymmetric, punched: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.consumer.String()+"/"+tt.provider.String(), func(t *testing.T) {
+			n := New(1, Link{})
+			consumer, err := n.AddHost("10.0.0.1", tt.consumer).ListenUDP(1000)
+			require.NoError(t, err)
+			provider, err := n.AddHost("10.0.0.2", tt.provider).ListenUDP(2000)
+			require.NoError(t, err)
+
+			// Peers only know each other's port as announced via the broker, i.e. the local one.
+			_, err = consumer.WriteTo([]byte("ping"), &net.UDPAddr{IP: net.ParseIP("10.0.0.2"), Port: 2000})
+			require.NoError(t, err)
+			_, err = provider.WriteTo([]byte("ping"), &net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1000})
+			require.NoError(t, err)
+
+			require.NoError(t, consumer.SetReadDeadline(time.Now().Add(50*time.Millisecond)))
+			_, _, err = consumer.ReadFrom(make([]byte, 16))
+			assert.Equal(t, tt.punched, err == nil)
+		})
+	}
+}
+
+func TestConn_Deadline(t *testing.T) {
+	n := New(1, Link{})
+	c, err := n.AddHost("10.0.0.1", NATNone).ListenUDP(1000)
+	require.NoError(t, err)
+
+	require.NoError(t, c.SetReadDeadline(time.Now().Add(10*time.Millisecond)))
+	_, _, err = c.ReadFrom(make([]byte, 16))
+	assert.True(t, errors.Is(err, os.ErrDeadlineExceeded))
+	var netErr net.Error
+	assert.True(t, errors.As(err, &netErr) && netErr.Timeout())
+
+	done := make(chan error)
+	require.NoError(t, c.SetReadDeadline(time.Time{}))
+	go func() {
+		_, _, err := c.ReadFrom(make([]byte, 16))
+		done <- err
+	}()
+	require.NoError(t, c.SetReadDeadline(time.Now()))
+	select {
+	case err := <-done:
+		assert.True(t, errors.Is(err, os.ErrDeadlineExceeded))
+	case <-time.After(time.Second):
+		t.Fatal("read was not unblocked by deadline")
+	}
+}
+
+func TestConn_Close(t *testing.T) {
+	n := New(1, Link{})
+	host := n.AddHost("10.0.0.1", NATPortRestrictedCone)
+	c, err := host.ListenUDP(1000)
+	require.NoError(t, err)
+
+	_, err = host.ListenUDP(1000)
+	assert.True(t, errors.Is(err, ErrAddressInUse))
+
+	_, err = c.WriteTo([]byte("ping"), &net.UDPAddr{IP: net.ParseIP("10.0.0.2"), Port: 2000})
+	require.NoError(t, err)
+	assert.Equal(t, 1, host.Mappings())
+
+	require.NoError(t, c.Close())
+	assert.Error(t, c.Close())
+	assert.Equal(t, 0, host.Mappings())
+
+	_, _, err = c.ReadFrom(make([]byte, 16))
+	assert.True(t, errors.Is(err, net.ErrClosed))
+	_, err = c.WriteTo([]byte("ping"), &net.UDPAddr{IP: net.ParseIP("10.0.0.2"), Port: 2000})
+	assert.True(t, errors.Is(err, net.ErrClosed))
+
+	_, err = host.ListenUDP(1000)
+	assert.NoError(t, err)
+}
+
+func read(t *testing.T, c *Conn) (string, *net.UDPAddr) {
+	t.Helper()
+
+	require.NoError(t, c.SetReadDeadline(time.Now().Add(time.Second)))
+	buf := make([]byte, 1500)
+	n, from, err := c.ReadFromUDP(buf)
+	require.NoError(t, err)
+	return string(buf[:n]), from
+}
+
+func assertNoPacket(t *testing.T, c *Conn) {
+	t.Helper()
+
+	require.NoError(t, c.SetReadDeadline(time.Now().Add(50*time.Millisecond)))
+	_, _, err := c.ReadFrom(make([]byte, 16))
+	assert.Error(t, err)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package obfs
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CapabilityPrefix prefixes obfuscation methods advertised in service proposal capabilities.
+const CapabilityPrefix = "obfs."
+
+// KeySize is the size of obfuscation keys generated by GenerateKey.
+const KeySize = 32
+
+// Obfuscator disguises transport packets so that they do not match
+// known protocol signatures when inspected on the wire.
+type Obfuscator interface {
+	// Overhead returns the maximum number of bytes added to each packet.
+	Overhead() int
+	// Obfuscate appends packet wrapped for sending on the wire to dst and returns the extended buffer.
+	// Passing dst with enough capacity avoids allocations on the packet path.
+	Obfuscate(dst, packet []byte) ([]byte, error)
+	// Deobfuscate unwraps a packet received from the wire in place,
+	// the returned packet shares memory with the input.
+	Deobfuscate(packet []byte) ([]byte, error)
+}
+
+// Factory creates an obfuscator for the given key.
+type Factory func(key []byte) (Obfuscator, error)
+
+var (
+	methodsMu sync.RWMutex
+	methods   = map[string]Factory{
+		MethodXChaCha: NewXChaCha,
+	}
+)
+
+// Register makes obfuscation method available for negotiation.
+func Register(method string, factory Factory) {
+	methodsMu.Lock()
+	defer methodsMu.Unlock()
+
+	methods[method] = factory
+}
+
+// Methods returns names of all registered obfuscation methods.
+func Methods() []string {
+	methodsMu.RLock()
+	defer methodsMu.RUnlock()
+
+	list := make([]string, 0, len(methods))
+	for method := range methods {
+		list = append(list, method)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Supported reports whether obfuscation method is registered.
+func Supported(method string) bool {
+	methodsMu.RLock()
+	defer methodsMu.RUnlock()
+
+	_, ok := methods[method]
+	return ok
+}
+
+// New creates obfuscator of the given method.
+func New(method string, key []byte) (Obfuscator, error) {
+	methodsMu.RLock()
+	factory, ok := methods[method]
+	methodsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported obfuscation method: %q", method)
+	}
+
+	return factory(key)
+}
+
+// GenerateKey generates random obfuscation key.
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("could not generate obfuscation key: %w", err)
+	}
+	return key, nil
+}
+
+// Capability returns proposal capability advertising obfuscation method.
+func Capability(method string) string {
+	return CapabilityPrefix + method
+}
+
+// Capabilities returns proposal capabilities for all registered methods.
+func Capabilities() []string {
+	var list []string
+	for _, method := range Methods() {
+		list = append(list, Capability(method))
+	}
+	return list
+}
+
+// Negotiate picks the first of the offered methods which is supported locally and advertised by capabilities.
+func Negotiate(capabilities, offered []string) (string, bool) {
+	advertised := make(map[string]struct{})
+	for _, c := range capabilities {
+		if strings.HasPrefix(c, CapabilityPrefix) {
+			advertised[strings.TrimPrefix(c, CapabilityPrefix)] = struct{}{}
+		}
+	}
+
+	for _, method := range offered {
+		if _, ok := advertised[method]; ok && Supported(method) {
+			return method, true
+		}
+	}
+	return "", false
+}
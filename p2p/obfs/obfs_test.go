@@ -0,0 +1,216 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package obfs
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/utils/udpbatch"
+)
+
+func TestXChaCha_RoundTrip(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	o, err := New(MethodXChaCha, key)
+	require.NoError(t, err)
+
+	packet := []byte("\x01\x00\x00\x00wireguard handshake initiation")
+	wire, err := o.Obfuscate(nil, packet)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(wire, packet))
+	assert.LessOrEqual(t, len(wire), len(packet)+o.Overhead())
+
+	got, err := o.Deobfuscate(wire)
+	require.NoError(t, err)
+	assert.Equal(t, packet, got)
+
+	wire2, err := o.Obfuscate(nil, packet)
+	require.NoError(t, err)
+	assert.NotEqual(t, wire, wire2)
+}
+
+func TestXChaCha_AppendsToBuffer(t *testing.T) {
+	key, _ := GenerateKey()
+	o, _ := NewXChaCha(key)
+
+	packet := []byte("wireguard transport data")
+	buf := make([]byte, 0, udpbatch.BufferSize)
+	wire, err := o.Obfuscate(append(buf, "prefix"...), packet)
+	require.NoError(t, err)
+	assert.Equal(t, "prefix", string(wire[:6]))
+	assert.Equal(t, &buf[:1][0], &wire[0], "buffer with enough capacity must be reused")
+
+	got, err := o.Deobfuscate(wire[6:])
+	require.NoError(t, err)
+	assert.Equal(t, packet, got)
+}
+
+func TestXChaCha_Invalid(t *testing.T) {
+	_, err := NewXChaCha([]byte("short"))
+	assert.Error(t, err)
+
+	key, _ := GenerateKey()
+	o, _ := NewXChaCha(key)
+	_, err = o.Deobfuscate([]byte{1, 2, 3})
+	assert.Error(t, err)
+}
+
+func TestNew_UnknownMethod(t *testing.T) {
+	_, err := New("unknown", nil)
+	assert.Error(t, err)
+}
+
+func TestNegotiate(t *testing.T) {
+	method, ok := Negotiate([]string{"other", Capability(MethodXChaCha)}, []string{"unknown", MethodXChaCha})
+	assert.True(t, ok)
+	assert.Equal(t, MethodXChaCha, method)
+
+	_, ok = Negotiate([]string{"other"}, []string{MethodXChaCha})
+	assert.False(t, ok)
+
+	_, ok = Negotiate([]string{Capability("unknown")}, []string{"unknown"})
+	assert.False(t, ok)
+}
+
+func TestProxy_RelaysBothWays(t *testing.T) {
+	key, _ := GenerateKey()
+	o, _ := NewXChaCha(key)
+
+	consumerApp, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	defer consumerApp.Close()
+	providerApp, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	defer providerApp.Close()
+
+	consumerRemote, providerRemote := connectedPair(t)
+
+	consumer, err := NewProxy(consumerRemote, o, nil)
+	require.NoError(t, err)
+	defer consumer.Stop()
+	provider, err := NewProxy(providerRemote, o, providerApp.LocalAddr().(*net.UDPAddr))
+	require.NoError(t, err)
+	defer provider.Stop()
+	consumer.Start()
+	provider.Start()
+
+	_, err = consumerApp.WriteToUDP([]byte("ping"), consumer.LocalAddr())
+	require.NoError(t, err)
+	assert.Equal(t, "ping", read(t, providerApp))
+
+	_, err = providerApp.WriteToUDP([]byte("pong"), provider.LocalAddr())
+	require.NoError(t, err)
+	assert.Equal(t, "pong", read(t, consumerApp))
+}
+
+func TestRandomPadding_RedrawsBiasedBytes(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		padding, err := randomPadding([]byte{byte(i)})
+		require.NoError(t, err)
+		assert.True(t, padding >= 0 && padding <= xchachaMaxPadding)
+		if i < 195 {
+			assert.Equal(t, i%(xchachaMaxPadding+1), padding)
+		}
+	}
+}
+
+func TestProxy_RelaysBatches(t *testing.T) {
+	key, _ := GenerateKey()
+	o, _ := NewXChaCha(key)
+
+	consumerApp, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	defer consumerApp.Close()
+	providerApp, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	defer providerApp.Close()
+
+	consumerRemote, providerRemote := connectedPair(t)
+	consumer, err := NewProxy(consumerRemote, o, nil)
+	require.NoError(t, err)
+	defer consumer.Stop()
+	provider, err := NewProxy(providerRemote, o, providerApp.LocalAddr().(*net.UDPAddr))
+	require.NoError(t, err)
+	defer provider.Stop()
+	consumer.Start()
+	provider.Start()
+
+	// First packet lets consumer proxy learn its local peer.
+	_, err = consumerApp.WriteToUDP([]byte("hello"), consumer.LocalAddr())
+	require.NoError(t, err)
+	assert.Equal(t, "hello", read(t, providerApp))
+
+	count := 3 * udpbatch.Size
+	for i := 0; i < count; i++ {
+		_, err = providerApp.WriteToUDP([]byte(strconv.Itoa(i)), provider.LocalAddr())
+		require.NoError(t, err)
+	}
+	for i := 0; i < count; i++ {
+		assert.Equal(t, strconv.Itoa(i), read(t, consumerApp))
+	}
+}
+
+func BenchmarkXChaCha_Obfuscate(b *testing.B) {
+	key, _ := GenerateKey()
+	o, _ := NewXChaCha(key)
+	packet := make([]byte, 1420)
+	buf := make([]byte, 0, udpbatch.BufferSize)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(packet)))
+	for i := 0; i < b.N; i++ {
+		wire, err := o.Obfuscate(buf, packet)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := o.Deobfuscate(wire); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func connectedPair(t *testing.T) (*net.UDPConn, *net.UDPConn) {
+	a, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	b, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	aAddr, bAddr := a.LocalAddr().(*net.UDPAddr), b.LocalAddr().(*net.UDPAddr)
+	a.Close()
+	b.Close()
+
+	ca, err := net.DialUDP("udp4", aAddr, bAddr)
+	require.NoError(t, err)
+	cb, err := net.DialUDP("udp4", bAddr, aAddr)
+	require.NoError(t, err)
+	return ca, cb
+}
+
+func read(t *testing.T, conn *net.UDPConn) string {
+	buf := make([]byte, 1500)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	return string(buf[:n])
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package obfs
+
+import (
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/utils/udpbatch"
+)
+
+// Proxy relays packets between a local plain UDP endpoint and an obfuscated remote peer.
+// Packets received on the local socket are obfuscated and sent to the remote peer,
+// packets from the remote peer are deobfuscated and delivered to the local endpoint.
+type Proxy struct {
+	obfuscator Obfuscator
+	remote     *net.UDPConn
+	local      *net.UDPConn
+
+	mu         sync.Mutex
+	localPeer  *net.UDPAddr
+	remotePeer *net.UDPAddr
+
+	once sync.Once
+	done chan struct{}
+}
+
+// NewProxy creates obfuscating proxy on a random loopback port.
+// When localPeer is nil, it is learned from the first packet received on the local socket.
+func NewProxy(remote *net.UDPConn, obfuscator Obfuscator, localPeer *net.UDPAddr) (*Proxy, error) {
+	local, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Proxy{
+		obfuscator: obfuscator,
+		remote:     remote,
+		local:      local,
+		localPeer:  localPeer,
+		done:       make(chan struct{}),
+	}
+	if addr, ok := remote.RemoteAddr().(*net.UDPAddr); ok {
+		p.remotePeer = addr
+	}
+
+	return p, nil
+}
+
+// LocalAddr returns loopback address plain packets should be sent to.
+func (p *Proxy) LocalAddr() *net.UDPAddr {
+	return p.local.LocalAddr().(*net.UDPAddr)
+}
+
+// Start starts relaying packets in both directions.
+func (p *Proxy) Start() {
+	go p.localReadLoop()
+	go p.remoteReadLoop()
+}
+
+// Stop stops relaying and closes both sockets.
+func (p *Proxy) Stop() {
+	p.once.Do(func() {
+		close(p.done)
+		p.local.Close()
+		p.remote.Close()
+	})
+}
+
+func (p *Proxy) localReadLoop() {
+	local, remote := udpbatch.NewConn(p.local), udpbatch.NewConn(p.remote)
+	in, out := udpbatch.New(), udpbatch.New()
+	defer in.Release()
+	defer out.Release()
+	connected := p.remote.RemoteAddr() != nil
+
+	for {
+		n, err := in.Read(local)
+		if err != nil {
+			p.handleErr(err, "Read from local obfuscation proxy conn failed")
+			return
+		}
+		if n == 0 {
+			continue
+		}
+
+		p.mu.Lock()
+		if addr := in.Addr(n - 1); addr != nil {
+			p.localPeer = addr
+		}
+		remotePeer := p.remotePeer
+		p.mu.Unlock()
+		if remotePeer == nil {
+			continue
+		}
+		if connected {
+			remotePeer = nil
+		}
+
+		count := 0
+		for i := 0; i < n; i++ {
+			packet, err := p.obfuscator.Obfuscate(out.Buffer(count), in.Packet(i))
+			if err != nil {
+				log.Warn().Err(err).Msg("Failed to obfuscate packet")
+				continue
+			}
+			out.Set(count, packet, remotePeer)
+			count++
+		}
+
+		if err := out.Write(remote, count); err != nil {
+			p.handleErr(err, "Write to remote obfuscated conn failed")
+			return
+		}
+	}
+}
+
+func (p *Proxy) remoteReadLoop() {
+	remote, local := udpbatch.NewConn(p.remote), udpbatch.NewConn(p.local)
+	in, out := udpbatch.New(), udpbatch.NewMessages()
+	defer in.Release()
+
+	for {
+		n, err := in.Read(remote)
+		if err != nil {
+			p.handleErr(err, "Read from remote obfuscated conn failed")
+			return
+		}
+
+		var remotePeer *net.UDPAddr
+		count := 0
+		for i := 0; i < n; i++ {
+			packet, err := p.obfuscator.Deobfuscate(in.Packet(i))
+			if err != nil {
+				log.Trace().Err(err).Msg("Dropping packet which could not be deobfuscated")
+				continue
+			}
+			if addr := in.Addr(i); addr != nil {
+				remotePeer = addr
+			}
+			// Packet is deobfuscated in place, so it is written from the read buffer without copying.
+			out.Set(count, packet, nil)
+			count++
+		}
+		if count == 0 {
+			continue
+		}
+
+		p.mu.Lock()
+		if remotePeer != nil {
+			p.remotePeer = remotePeer
+		}
+		localPeer := p.localPeer
+		p.mu.Unlock()
+		if localPeer == nil {
+			continue
+		}
+
+		for i := 0; i < count; i++ {
+			out.SetAddr(i, localPeer)
+		}
+		if err := out.Write(local, count); err != nil {
+			p.handleErr(err, "Write to local obfuscation proxy conn failed")
+			return
+		}
+	}
+}
+
+func (p *Proxy) handleErr(err error, msg string) {
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+
+	if errors.Is(err, net.ErrClosed) {
+		return
+	}
+	log.Error().Err(err).Msg(msg)
+}
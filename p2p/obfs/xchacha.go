@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package obfs
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20"
+)
+
+// MethodXChaCha masks packets with XChaCha20 keystream and random length padding.
+const MethodXChaCha = "xchacha"
+
+const (
+	xchachaLengthSize = 2
+	xchachaMaxPadding = 64
+)
+
+var errShortPacket = errors.New("obfuscated packet is too short")
+
+type xchacha struct {
+	key []byte
+}
+
+// NewXChaCha creates obfuscator which encrypts every packet with XChaCha20 using
+// random nonce and appends random padding, so that neither packet content nor
+// packet length reveal the wrapped protocol.
+func NewXChaCha(key []byte) (Obfuscator, error) {
+	if len(key) != chacha20.KeySize {
+		return nil, fmt.Errorf("invalid xchacha key size %d, expected %d", len(key), chacha20.KeySize)
+	}
+
+	return &xchacha{key: append([]byte(nil), key...)}, nil
+}
+
+// Overhead returns the maximum number of bytes added to each packet.
+func (x *xchacha) Overhead() int {
+	return chacha20.NonceSizeX + xchachaLengthSize + xchachaMaxPadding
+}
+
+// Obfuscate appends packet wrapped for sending on the wire to dst.
+func (x *xchacha) Obfuscate(dst, packet []byte) ([]byte, error) {
+	if len(packet) > 0xffff {
+		return nil, fmt.Errorf("packet is too big: %d", len(packet))
+	}
+
+	// Nonce and one extra random byte for padding length are read with a single call.
+	start := len(dst)
+	dst = grow(dst, chacha20.NonceSizeX+1)
+	random := dst[start:]
+	if _, err := rand.Read(random); err != nil {
+		return nil, fmt.Errorf("could not generate nonce: %w", err)
+	}
+	padding, err := randomPadding(random[chacha20.NonceSizeX:])
+	if err != nil {
+		return nil, err
+	}
+
+	// Padding content is whatever the keystream makes of zeroes, so it looks as random as the rest.
+	dst = grow(dst[:start+chacha20.NonceSizeX], xchachaLengthSize+len(packet)+padding)
+	nonce, body := dst[start:start+chacha20.NonceSizeX], dst[start+chacha20.NonceSizeX:]
+	binary.BigEndian.PutUint16(body, uint16(len(packet)))
+	copy(body[xchachaLengthSize:], packet)
+	for i := xchachaLengthSize + len(packet); i < len(body); i++ {
+		body[i] = 0
+	}
+
+	cipher, err := chacha20.NewUnauthenticatedCipher(x.key, nonce)
+	if err != nil {
+		return nil, err
+	}
+	cipher.XORKeyStream(body, body)
+
+	return dst, nil
+}
+
+// Deobfuscate unwraps a packet received from the wire.
+func (x *xchacha) Deobfuscate(packet []byte) ([]byte, error) {
+	if len(packet) < chacha20.NonceSizeX+xchachaLengthSize {
+		return nil, errShortPacket
+	}
+
+	nonce, body := packet[:chacha20.NonceSizeX], packet[chacha20.NonceSizeX:]
+
+	cipher, err := chacha20.NewUnauthenticatedCipher(x.key, nonce)
+	if err != nil {
+		return nil, err
+	}
+	cipher.XORKeyStream(body, body)
+
+	size := int(binary.BigEndian.Uint16(body))
+	if size > len(body)-xchachaLengthSize {
+		return nil, errShortPacket
+	}
+
+	return body[xchachaLengthSize : xchachaLengthSize+size], nil
+}
+
+// randomPadding picks padding length uniformly from 0 to xchachaMaxPadding. Random bytes above
+// the largest multiple of the range are redrawn, as plain modulo would favour short paddings.
+func randomPadding(random []byte) (int, error) {
+	const limit = 256 - 256%(xchachaMaxPadding+1)
+	for int(random[0]) >= limit {
+		if _, err := rand.Read(random[:1]); err != nil {
+			return 0, fmt.Errorf("could not generate padding: %w", err)
+		}
+	}
+	return int(random[0]) % (xchachaMaxPadding + 1), nil
+}
+
+// grow extends buffer by n bytes, reallocating only when capacity is not enough.
+func grow(buf []byte, n int) []byte {
+	if len(buf)+n <= cap(buf) {
+		return buf[:len(buf)+n]
+	}
+
+	grown := make([]byte, len(buf)+n, 2*(len(buf)+n))
+	copy(grown, buf)
+	return grown
+}
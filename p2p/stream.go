@@ -0,0 +1,343 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/p2p/camouflage"
+	"github.com/mysteriumnetwork/node/router"
+	"github.com/mysteriumnetwork/node/trace"
+)
+
+// Channel setup over a camouflage stream replaces the broker and UDP hole punching:
+// consumer sends the config exchange and its ack as subject and message frames,
+// provider answers each with a reply frame and finally with handlers ready frame.
+// After that the stream carries p2p channel packets.
+
+// streamExchangeTimeout limits how long channel setup over a camouflage stream may take.
+const streamExchangeTimeout = 30 * time.Second
+
+// streamService is a service accepting channel setup over camouflage streams.
+type streamService struct {
+	providerID      identity.Identity
+	serviceType     string
+	channelHandlers func(ch Channel)
+}
+
+// useCamouflage tells whether consumer should set up the channel over provider camouflage server.
+func useCamouflage(contactDef ContactDefinition) bool {
+	return contactDef.Camouflage != nil && config.GetBool(config.FlagCamouflage)
+}
+
+// ServeCamouflage accepts p2p channel setup over the TLS camouflage server, so that consumers
+// allowed to use HTTPS only can connect without the broker and UDP hole punching.
+func (m *listener) ServeCamouflage(server *camouflage.Server) {
+	m.streamMu.Lock()
+	m.camouflage = server
+	m.streamMu.Unlock()
+
+	server.HandleBootstrap(m.serveStream)
+}
+
+func (m *listener) camouflageContact() (market.Contact, bool) {
+	m.streamMu.Lock()
+	server := m.camouflage
+	m.streamMu.Unlock()
+
+	if server == nil {
+		return market.Contact{}, false
+	}
+
+	publicIP, err := m.ipResolver.GetPublicIP()
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not resolve public IP for TLS camouflage contact")
+		return market.Contact{}, false
+	}
+
+	return market.Contact{
+		Type: ContactTypeCamouflageV1,
+		Definition: CamouflageContact{
+			Host:        publicIP,
+			Port:        server.PublicPort(),
+			ServerName:  server.ServerName(),
+			Fingerprint: server.Fingerprint(),
+		},
+	}, true
+}
+
+func (m *listener) addStreamService(providerID identity.Identity, serviceType string, channelHandlers func(ch Channel)) func() {
+	subject := configExchangeSubject(providerID, serviceType)
+
+	m.streamMu.Lock()
+	m.streamServices[subject] = streamService{
+		providerID:      providerID,
+		serviceType:     serviceType,
+		channelHandlers: channelHandlers,
+	}
+	m.streamMu.Unlock()
+
+	return func() {
+		m.streamMu.Lock()
+		delete(m.streamServices, subject)
+		m.streamMu.Unlock()
+	}
+}
+
+func (m *listener) streamService(subject string) (streamService, bool) {
+	m.streamMu.Lock()
+	defer m.streamMu.Unlock()
+
+	service, ok := m.streamServices[subject]
+	return service, ok
+}
+
+func (m *listener) serveStream(conn net.Conn) {
+	if err := m.acceptStream(conn); err != nil {
+		log.Err(err).Msg("Could not set up p2p channel over camouflage stream")
+		conn.Close()
+	}
+}
+
+func (m *listener) acceptStream(conn net.Conn) error {
+	conn.SetDeadline(time.Now().Add(streamExchangeTimeout))
+	buf := make([]byte, camouflage.MaxPacketSize)
+	reply := func(data []byte) error {
+		return camouflage.WriteFrame(conn, data)
+	}
+
+	subject, data, err := readStreamRequest(conn, buf)
+	if err != nil {
+		return fmt.Errorf("could not read exchange: %w", err)
+	}
+	service, ok := m.streamService(subject)
+	if !ok {
+		return fmt.Errorf("no service accepts exchange on %s", subject)
+	}
+	if err := m.providerStartConfigExchange(service.providerID, service.serviceType, data, reply, true); err != nil {
+		return fmt.Errorf("could not handle initial exchange: %w", err)
+	}
+
+	subject, data, err = readStreamRequest(conn, buf)
+	if err != nil {
+		return fmt.Errorf("could not read exchange ack: %w", err)
+	}
+	if subject != configExchangeACKSubject(service.providerID, service.serviceType) {
+		return fmt.Errorf("unexpected exchange ack subject %s", subject)
+	}
+	config, err := m.providerAckConfigExchange(service.providerID, data)
+	if err != nil {
+		return fmt.Errorf("could not handle exchange ack: %w", err)
+	}
+	if err := reply([]byte("OK")); err != nil {
+		return fmt.Errorf("could not send exchange ack: %w", err)
+	}
+
+	conn1, conn2, tunnel, err := streamConns(conn)
+	if err != nil {
+		return err
+	}
+
+	channel, err := m.newChannel(conn1, conn2, config)
+	if err != nil {
+		conn1.Close()
+		conn2.Close()
+		tunnel.Stop()
+		return fmt.Errorf("could not create channel: %w", err)
+	}
+	channel.setCloseStream(tunnel.Stop)
+
+	service.channelHandlers(channel)
+	channel.launchReadSendLoops()
+
+	ready, err := handlersReadyMessage()
+	if err == nil {
+		err = reply(ready)
+	}
+	if err != nil {
+		channel.Close()
+		return fmt.Errorf("could not send handlers ready: %w", err)
+	}
+
+	conn.SetDeadline(time.Time{})
+	tunnel.Start()
+	return nil
+}
+
+// dialStream sets up p2p channel over the provider camouflage server.
+func (m *dialer) dialStream(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, contact CamouflageContact, tracer *trace.Tracer) (Channel, error) {
+	hostIP := net.ParseIP(contact.Host)
+	if hostIP == nil {
+		return nil, fmt.Errorf("invalid camouflage host: %s", contact.Host)
+	}
+
+	if serviceType != "openvpn" { // OpenVPN does this automatically, we don't need to perform it manually.
+		if err := router.ExcludeIP(hostIP); err != nil {
+			return nil, fmt.Errorf("failed to exclude camouflage host from default routes: %w", err)
+		}
+	}
+
+	if _, err := firewall.AllowIPAccess(contact.Host); err != nil {
+		return nil, fmt.Errorf("could not add camouflage host firewall rule: %w", err)
+	}
+
+	trace := tracer.StartStage("Consumer P2P connect (camouflage)")
+	address := net.JoinHostPort(contact.Host, strconv.Itoa(contact.Port))
+	conn, err := camouflage.DialConn(ctx, address, camouflage.ClientConfig{
+		ServerName:  contact.ServerName,
+		Fingerprint: contact.Fingerprint,
+		Token:       camouflage.BootstrapToken,
+	})
+	tracer.EndStage(trace)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := m.exchangeStream(ctx, conn, consumerID, providerID, serviceType, tracer)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return channel, nil
+}
+
+func (m *dialer) exchangeStream(ctx context.Context, conn net.Conn, consumerID, providerID identity.Identity, serviceType string, tracer *trace.Tracer) (Channel, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	buf := make([]byte, camouflage.MaxPacketSize)
+	request := func(_ context.Context, subject string, msg []byte) ([]byte, error) {
+		if err := writeStreamRequest(conn, subject, msg); err != nil {
+			return nil, err
+		}
+		reply, err := camouflage.ReadFrame(conn, buf)
+		if err != nil {
+			return nil, fmt.Errorf("could not read reply to %s: %w", subject, err)
+		}
+		return append([]byte(nil), reply...), nil
+	}
+
+	config, err := m.startConfigExchange(&p2pConnectConfig{tracer: tracer}, ctx, request, providerID, serviceType, consumerID)
+	if err != nil {
+		return nil, fmt.Errorf("could not exchange config: %w", err)
+	}
+
+	if config.compatibility < 2 {
+		return nil, fmt.Errorf("peer using compatibility version lower than 2: %d", config.compatibility)
+	}
+
+	// Stream carries the channel, so there are no public IP and ports to share.
+	if err := m.ackConfigExchange(config, ctx, request, providerID, serviceType, consumerID); err != nil {
+		return nil, fmt.Errorf("could not ack config: %w", err)
+	}
+
+	traceAck := tracer.StartStage("Consumer P2P dial ack")
+	ready, err := camouflage.ReadFrame(conn, buf)
+	if err != nil {
+		return nil, fmt.Errorf("could not read handlers ready: %w", err)
+	}
+	if err := m.channelHandlersReady(ready); err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+
+	conn1, conn2, tunnel, err := streamConns(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := newChannel(conn1, config.privateKey, config.peerPubKey, config.capabilities)
+	if err != nil {
+		conn1.Close()
+		conn2.Close()
+		tunnel.Stop()
+		return nil, fmt.Errorf("could not create p2p channel during dial: %w", err)
+	}
+	channel.setTracer(tracer)
+	channel.setServiceConn(conn2)
+	channel.setPeerID(providerID)
+	channel.setCloseStream(func() {
+		tunnel.Stop()
+		router.RemoveExcludedIP(conn.RemoteAddr().(*net.TCPAddr).IP)
+	})
+	tunnel.Start()
+	channel.launchReadSendLoops()
+	tracer.EndStage(traceAck)
+
+	log.Info().Msgf("P2P channel with provider %s set up over TLS camouflage", providerID.Address)
+	return channel, nil
+}
+
+// streamConns creates loopback channel and service connections carried by the stream.
+func streamConns(conn net.Conn) (*net.UDPConn, *net.UDPConn, *camouflage.Tunnel, error) {
+	tunnel, err := camouflage.NewTunnel(conn)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("could not create camouflage tunnel: %w", err)
+	}
+
+	loopback := &net.UDPAddr{IP: net.ParseIP("127.0.0.1")}
+	conn1, err := net.DialUDP("udp4", loopback, tunnel.LocalAddr())
+	if err != nil {
+		tunnel.Stop()
+		return nil, nil, nil, fmt.Errorf("could not create UDP conn for p2p channel: %w", err)
+	}
+	conn2, err := net.DialUDP("udp4", loopback, tunnel.LocalAddr())
+	if err != nil {
+		conn1.Close()
+		tunnel.Stop()
+		return nil, nil, nil, fmt.Errorf("could not create UDP conn for service: %w", err)
+	}
+
+	// Packets from the peer may arrive before the channel sends anything.
+	tunnel.SetPeer(conn1.LocalAddr().(*net.UDPAddr))
+	return conn1, conn2, tunnel, nil
+}
+
+func writeStreamRequest(conn net.Conn, subject string, msg []byte) error {
+	if err := camouflage.WriteFrame(conn, []byte(subject)); err != nil {
+		return fmt.Errorf("could not send %s subject: %w", subject, err)
+	}
+	if err := camouflage.WriteFrame(conn, msg); err != nil {
+		return fmt.Errorf("could not send %s message: %w", subject, err)
+	}
+	return nil
+}
+
+func readStreamRequest(conn net.Conn, buf []byte) (string, []byte, error) {
+	subject, err := camouflage.ReadFrame(conn, buf)
+	if err != nil {
+		return "", nil, err
+	}
+	subjectStr := string(subject)
+
+	msg, err := camouflage.ReadFrame(conn, buf)
+	if err != nil {
+		return "", nil, err
+	}
+	return subjectStr, append([]byte(nil), msg...), nil
+}
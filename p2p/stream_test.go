@@ -0,0 +1,96 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/p2p/camouflage"
+)
+
+func TestStreamRequest_RoundTrip(t *testing.T) {
+	consumer, provider := net.Pipe()
+	defer consumer.Close()
+	defer provider.Close()
+
+	go writeStreamRequest(consumer, "0x1.wireguard.p2p-config-exchange", []byte("exchange"))
+
+	subject, msg, err := readStreamRequest(provider, make([]byte, camouflage.MaxPacketSize))
+	require.NoError(t, err)
+	assert.Equal(t, "0x1.wireguard.p2p-config-exchange", subject)
+	assert.Equal(t, "exchange", string(msg))
+}
+
+func TestStreamConns_RelayChannelPackets(t *testing.T) {
+	consumerStream, providerStream := net.Pipe()
+
+	consumerConn, consumerService, consumerTunnel, err := streamConns(consumerStream)
+	require.NoError(t, err)
+	defer consumerService.Close()
+	defer consumerTunnel.Stop()
+
+	providerConn, providerService, providerTunnel, err := streamConns(providerStream)
+	require.NoError(t, err)
+	defer providerService.Close()
+	defer providerTunnel.Stop()
+
+	consumerTunnel.Start()
+	providerTunnel.Start()
+
+	// Provider channel receives packets before it sends anything itself.
+	_, err = consumerConn.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	buf := make([]byte, 100)
+	providerConn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := providerConn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf[:n]))
+
+	_, err = providerConn.Write([]byte("pong"))
+	require.NoError(t, err)
+
+	consumerConn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err = consumerConn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(buf[:n]))
+}
+
+func TestParseContact_WithCamouflage(t *testing.T) {
+	camouflageContact := CamouflageContact{Host: "1.2.3.4", Port: 443, ServerName: "example.com", Fingerprint: []byte{1}}
+
+	def, err := ParseContact(market.ContactList{
+		{Type: ContactTypeCamouflageV1, Definition: camouflageContact},
+		{Type: ContactTypeV1, Definition: ContactDefinition{BrokerAddresses: []string{"nats://broker"}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nats://broker"}, def.BrokerAddresses)
+	assert.Equal(t, &camouflageContact, def.Camouflage)
+
+	def, err = ParseContact(market.ContactList{
+		{Type: ContactTypeV1, Definition: ContactDefinition{BrokerAddresses: []string{"nats://broker"}}},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, def.Camouflage)
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xtaci/kcp-go/v5"
+
+	"github.com/mysteriumnetwork/node/p2p/netsim"
+)
+
+func TestKCPSessionOverSimulatedNetwork(t *testing.T) {
+	tests := []struct {
+		name string
+		link netsim.Link
+	}{
+		{name: "perfect link", link: netsim.Link{}},
+		{name: "lossy link", link: netsim.Link{Loss: 0.1, Latency: 5 * time.Millisecond}},
+		{name: "reordering link", link: netsim.Link{Latency: 5 * time.Millisecond, Jitter: 10 * time.Millisecond, Duplicate: 0.05}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			network := netsim.New(1, tt.link)
+			consumerConn, err := network.AddHost("10.0.0.1", netsim.NATPortRestrictedCone).ListenUDP(1000)
+			require.NoError(t, err)
+			defer consumerConn.Close()
+			providerConn, err := network.AddHost("10.0.0.2", netsim.NATPortRestrictedCone).ListenUDP(2000)
+			require.NoError(t, err)
+			defer providerConn.Close()
+
+			// Open NAT mappings on both sides the same way hole punching does.
+			_, err = providerConn.WriteTo([]byte("ping"), consumerConn.LocalAddr())
+			require.NoError(t, err)
+			_, err = consumerConn.WriteTo([]byte("ping"), providerConn.LocalAddr())
+			require.NoError(t, err)
+			require.NoError(t, providerConn.SetReadDeadline(time.Now().Add(time.Second)))
+			_, _, err = providerConn.ReadFrom(make([]byte, 16))
+			require.NoError(t, err, "hole punching failed")
+			require.NoError(t, providerConn.SetReadDeadline(time.Time{}))
+
+			providerPublicKey, providerPrivateKey, err := GenerateKey()
+			require.NoError(t, err)
+			consumerPublicKey, consumerPrivateKey, err := GenerateKey()
+			require.NoError(t, err)
+
+			providerCrypt, err := newBlockCrypt(providerPrivateKey, consumerPublicKey)
+			require.NoError(t, err)
+			listener, err := kcp.ServeConn(providerCrypt, 10, 3, providerConn)
+			require.NoError(t, err)
+			defer listener.Close()
+
+			consumerCrypt, err := newBlockCrypt(consumerPrivateKey, providerPublicKey)
+			require.NoError(t, err)
+			consumerSess, err := kcp.NewConn3(1, providerConn.LocalAddr(), consumerCrypt, 10, 3, consumerConn)
+			require.NoError(t, err)
+			defer consumerSess.Close()
+			consumerSess.SetMtu(kcpMTUSize)
+
+			const messages = 30
+			go func() {
+				for i := 0; i < messages; i++ {
+					if _, err := consumerSess.Write([]byte(fmt.Sprintf("msg-%03d", i))); err != nil {
+						return
+					}
+				}
+			}()
+
+			require.NoError(t, listener.SetDeadline(time.Now().Add(5*time.Second)))
+			providerSess, err := listener.AcceptKCP()
+			require.NoError(t, err)
+			defer providerSess.Close()
+			providerSess.SetMtu(kcpMTUSize)
+
+			require.NoError(t, providerSess.SetReadDeadline(time.Now().Add(10*time.Second)))
+			buf := make([]byte, messages*len("msg-000"))
+			_, err = io.ReadFull(providerSess, buf)
+			require.NoError(t, err)
+
+			for i := 0; i < messages; i++ {
+				want := fmt.Sprintf("msg-%03d", i)
+				got := string(buf[i*len(want) : (i+1)*len(want)])
+				assert.Equal(t, want, got)
+			}
+		})
+	}
+}
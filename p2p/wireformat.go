@@ -34,7 +34,43 @@ import (
 	"github.com/mysteriumnetwork/node/pb"
 )
 
-const maxTransportMsgLen = 128 * 1024
+const (
+	// maxTransportMsgLen is the largest frame a peer is allowed to send.
+	maxTransportMsgLen = 128 * 1024
+	// maxTopicLen is the largest topic name a peer is allowed to send.
+	maxTopicLen = 256
+	// maxStatusMsgLen is the largest status message a peer is allowed to send.
+	maxStatusMsgLen = 4 * 1024
+)
+
+var (
+	// ErrFrameTooLarge indicates that the peer announced or sent a frame over the size limit.
+	ErrFrameTooLarge = errors.New("frame too large")
+	// ErrFieldTooLong indicates that a frame field is over its size limit.
+	ErrFieldTooLong = errors.New("field too long")
+	// ErrMalformedFrame indicates that the frame can't be parsed.
+	ErrMalformedFrame = errors.New("malformed frame")
+)
+
+// DecodeError is returned when a frame received from the peer can't be decoded.
+type DecodeError struct {
+	Field string
+	Err   error
+}
+
+// Error returns error message.
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("could not decode p2p frame %s: %v", e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func decodeErr(field string, err error) error {
+	return &DecodeError{Field: field, Err: err}
+}
 
 func init() {
 	// This is needed to initialize global common headers map state internally
@@ -58,8 +94,8 @@ const (
 	headerMsg            = "Message"
 )
 
-func newCompatibleWireReader(c io.Reader, peerCompatibility int) wireReader {
-	if compat.FeaturePBP2P(peerCompatibility) {
+func newCompatibleWireReader(c io.Reader, capabilities compat.Capabilities) wireReader {
+	if capabilities.Has(compat.CapabilityPBWire) {
 		log.Debug().Msg("Using protobufWireReader")
 		return newProtobufWireReader(c)
 	}
@@ -67,8 +103,8 @@ func newCompatibleWireReader(c io.Reader, peerCompatibility int) wireReader {
 	return newTextWireReader(c)
 }
 
-func newCompatibleWireWriter(c io.Writer, peerCompatibility int) wireWriter {
-	if compat.FeaturePBP2P(peerCompatibility) {
+func newCompatibleWireWriter(c io.Writer, capabilities compat.Capabilities) wireWriter {
+	if capabilities.Has(compat.CapabilityPBWire) {
 		log.Debug().Msg("Using protobufWireWriter")
 		return newProtobufWireWriter(c)
 	}
@@ -76,36 +112,56 @@ func newCompatibleWireWriter(c io.Writer, peerCompatibility int) wireWriter {
 	return newTextWireWriter(c)
 }
 
-type textWireReader textproto.Reader
+type textWireReader struct {
+	r     *textproto.Reader
+	limit *frameLimitReader
+}
 
 type textWireWriter textproto.Writer
 
 func newTextWireReader(c io.Reader) *textWireReader {
-	return (*textWireReader)(textproto.NewReader(bufio.NewReader(c)))
+	limit := &frameLimitReader{r: c}
+	return &textWireReader{
+		r:     textproto.NewReader(bufio.NewReader(limit)),
+		limit: limit,
+	}
 }
 
 func (r *textWireReader) readMsg(m *transportMsg) error {
+	// Text frames are not length prefixed, so the amount of bytes read
+	// from the peer is bounded per message instead.
+	r.limit.reset(maxTransportMsgLen)
+
 	// Read header.
-	header, err := (*textproto.Reader)(r).ReadMIMEHeader()
+	header, err := r.r.ReadMIMEHeader()
 	if err != nil {
+		if errors.Is(err, ErrFrameTooLarge) {
+			return decodeErr("header", err)
+		}
 		return fmt.Errorf("could not read mime header: %w", err)
 	}
 	id, err := strconv.ParseUint(header.Get(headerFieldRequestID), 10, 64)
 	if err != nil {
-		return fmt.Errorf("could not parse request id: %w", err)
+		return decodeErr("request id", ErrMalformedFrame)
 	}
 	m.id = id
 	statusCode, err := strconv.ParseUint(header.Get(headerStatusCode), 10, 64)
 	if err != nil {
-		return fmt.Errorf("could not parse status code: %w", err)
+		return decodeErr("status code", ErrMalformedFrame)
 	}
 	m.statusCode = statusCode
 	m.topic = header.Get(headerFieldTopic)
 	m.msg = header.Get(headerMsg)
+	if err := validateFields(m); err != nil {
+		return err
+	}
 
 	// Read data.
-	data, err := (*textproto.Reader)(r).ReadDotBytes()
+	data, err := r.r.ReadDotBytes()
 	if err != nil {
+		if errors.Is(err, ErrFrameTooLarge) {
+			return decodeErr("data", err)
+		}
 		return fmt.Errorf("could not read dot bytes: %w", err)
 	}
 	if len(data) > 0 {
@@ -114,6 +170,28 @@ func (r *textWireReader) readMsg(m *transportMsg) error {
 	return nil
 }
 
+// frameLimitReader fails reads once the per frame budget is exhausted.
+type frameLimitReader struct {
+	r         io.Reader
+	remaining int
+}
+
+func (l *frameLimitReader) reset(limit int) {
+	l.remaining = limit
+}
+
+func (l *frameLimitReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		return 0, ErrFrameTooLarge
+	}
+	if len(p) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= n
+	return n, err
+}
+
 func newTextWireWriter(c io.Writer) *textWireWriter {
 	return (*textWireWriter)(textproto.NewWriter(bufio.NewWriter(c)))
 }
@@ -150,12 +228,15 @@ func (r *protobufWireReader) readMsg(m *transportMsg) error {
 	msgLen, err := binary.ReadUvarint(r.r)
 	if err != nil {
 		r.closed = true
-		return err
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return err
+		}
+		return decodeErr("length", ErrMalformedFrame)
 	}
 
 	if msgLen > maxTransportMsgLen {
 		r.closed = true
-		return io.EOF
+		return decodeErr("length", ErrFrameTooLarge)
 	}
 
 	msgBytes := make([]byte, msgLen)
@@ -165,10 +246,39 @@ func (r *protobufWireReader) readMsg(m *transportMsg) error {
 		return err
 	}
 
+	return decodeEnvelope(msgBytes, m)
+}
+
+// decodeFrame decodes a single length prefixed protobuf frame from the
+// beginning of b and returns the number of bytes consumed. It never
+// allocates more than the frame size limit, so it is safe to feed
+// arbitrary peer input, e.g. from a fuzzer.
+func decodeFrame(b []byte, m *transportMsg) (int, error) {
+	msgLen, n := binary.Uvarint(b)
+	if n == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if n < 0 {
+		return 0, decodeErr("length", ErrMalformedFrame)
+	}
+	if msgLen > maxTransportMsgLen {
+		return 0, decodeErr("length", ErrFrameTooLarge)
+	}
+	if uint64(len(b)-n) < msgLen {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	end := n + int(msgLen)
+	if err := decodeEnvelope(b[n:end], m); err != nil {
+		return 0, err
+	}
+	return end, nil
+}
+
+func decodeEnvelope(b []byte, m *transportMsg) error {
 	var pbMsg pb.P2PChannelEnvelope
-	err = proto.Unmarshal(msgBytes, &pbMsg)
-	if err != nil {
-		return err
+	if err := proto.Unmarshal(b, &pbMsg); err != nil {
+		return decodeErr("envelope", fmt.Errorf("%w: %v", ErrMalformedFrame, err))
 	}
 
 	m.id = pbMsg.ID
@@ -177,6 +287,16 @@ func (r *protobufWireReader) readMsg(m *transportMsg) error {
 	m.msg = pbMsg.Msg
 	m.data = pbMsg.Data
 
+	return validateFields(m)
+}
+
+func validateFields(m *transportMsg) error {
+	if len(m.topic) > maxTopicLen {
+		return decodeErr("topic", ErrFieldTooLong)
+	}
+	if len(m.msg) > maxStatusMsgLen {
+		return decodeErr("message", ErrFieldTooLong)
+	}
 	return nil
 }
 
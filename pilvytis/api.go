@@ -244,6 +244,7 @@ type paymentOrderRequest struct {
 	State          string `json:"state"`
 	ChainID        int64  `json:"chain_id"`
 	ProjectId      string `json:"project_id"`
+	ReferralToken  string `json:"referral_token,omitempty"`
 
 	GatewayCallerData json.RawMessage `json:"gateway_caller_data"`
 }
@@ -258,7 +259,9 @@ type GatewayOrderRequest struct {
 	Country     string
 	State       string
 	ProjectID   string
-	CallerData  json.RawMessage
+	// ReferralToken lets distribution partners track top-ups, the configured token is used if empty.
+	ReferralToken string
+	CallerData    json.RawMessage
 }
 
 // createPaymentOrder creates a new payment order in the API service.
@@ -280,6 +283,7 @@ func (a *API) createPaymentGatewayOrder(cgo GatewayOrderRequest) (*GatewayOrderR
 		ChainID:           chainID,
 		GatewayCallerData: cgo.CallerData,
 		ProjectId:         cgo.ProjectID,
+		ReferralToken:     config.ReferralToken(cgo.ReferralToken),
 	}
 
 	path := fmt.Sprintf("api/v2/payment/%s/orders", cgo.Gateway)
@@ -0,0 +1,155 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pilvytis
+
+import (
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+// AutoTopupConfig describes when and how consumer balance is topped up automatically.
+type AutoTopupConfig struct {
+	// Threshold is the balance below which a top-up order is created, nil or zero disables auto top-up.
+	Threshold *big.Int
+	// Amount is the MYST amount of the top-up order.
+	Amount string
+	// Gateway is the payment gateway used for the order.
+	Gateway string
+	// PayCurrency is the currency the order is paid in.
+	PayCurrency string
+	// Interval is the minimum time between two automatic orders of the same identity.
+	Interval time.Duration
+}
+
+// Enabled tells if the auto top-up is configured.
+func (c AutoTopupConfig) Enabled() bool {
+	return c.Threshold != nil && c.Threshold.Sign() > 0 && c.Amount != "" && c.Gateway != ""
+}
+
+type autoTopupIssuer interface {
+	CreatePaymentGatewayOrder(cgo GatewayOrderRequest) (*GatewayOrderResponse, error)
+}
+
+// AutoTopup creates top-up orders when the balance of an identity drops below the configured threshold.
+// A new order is not created while the previous one is incomplete or sooner than the configured interval.
+type AutoTopup struct {
+	config    AutoTopupConfig
+	issuer    autoTopupIssuer
+	lp        locationProvider
+	publisher eventbus.Publisher
+
+	mu      sync.Mutex
+	last    map[string]time.Time
+	pending map[string]string
+	now     func() time.Time
+}
+
+// NewAutoTopup returns a new auto top-up.
+func NewAutoTopup(config AutoTopupConfig, issuer autoTopupIssuer, lp locationProvider, publisher eventbus.Publisher) *AutoTopup {
+	return &AutoTopup{
+		config:    config,
+		issuer:    issuer,
+		lp:        lp,
+		publisher: publisher,
+		last:      make(map[string]time.Time),
+		pending:   make(map[string]string),
+		now:       time.Now,
+	}
+}
+
+// Subscribe subscribes to balance and order events.
+func (a *AutoTopup) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.SubscribeAsync(pingpongEvent.AppTopicBalanceChanged, a.handleBalanceChanged); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(AppTopicOrderUpdated, a.handleOrderUpdated)
+}
+
+func (a *AutoTopup) handleBalanceChanged(e pingpongEvent.AppEventBalanceChanged) {
+	if !a.config.Enabled() || e.Current == nil || e.Current.Cmp(a.config.Threshold) >= 0 {
+		return
+	}
+
+	id := e.Identity.Address
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.pending[id]; ok {
+		return
+	}
+	if last, ok := a.last[id]; ok && a.now().Sub(last) < a.config.Interval {
+		log.Debug().Str("identity", id).Msg("Balance is below auto top-up threshold, but the previous top-up was too recent")
+		return
+	}
+	a.last[id] = a.now()
+
+	order, err := a.issuer.CreatePaymentGatewayOrder(GatewayOrderRequest{
+		Identity:    identity.FromAddress(id),
+		Gateway:     a.config.Gateway,
+		MystAmount:  a.config.Amount,
+		PayCurrency: a.config.PayCurrency,
+		Country:     strings.ToUpper(a.lp.GetOrigin().Country),
+	})
+	if err != nil {
+		log.Err(err).Str("identity", id).Msg("Failed to create auto top-up order")
+		a.publisher.Publish(AppTopicAutoTopup, AppEventAutoTopup{
+			Identity: id,
+			Gateway:  a.config.Gateway,
+			Amount:   a.config.Amount,
+			Status:   PaymentOrderStatusFailed,
+			Error:    err.Error(),
+		})
+		return
+	}
+
+	log.Info().Str("identity", id).Str("order", order.ID).Msgf("Balance dropped below auto top-up threshold, created top-up order for %s MYST", a.config.Amount)
+	a.pending[id] = order.ID
+	a.publisher.Publish(AppTopicAutoTopup, AppEventAutoTopup{
+		Identity: id,
+		OrderID:  order.ID,
+		Gateway:  a.config.Gateway,
+		Amount:   a.config.Amount,
+		Status:   order.Status,
+	})
+}
+
+func (a *AutoTopup) handleOrderUpdated(e AppEventOrderUpdated) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.pending[e.IdentityAddress] != e.ID || e.Status.Incomplete() {
+		return
+	}
+	delete(a.pending, e.IdentityAddress)
+
+	a.publisher.Publish(AppTopicAutoTopup, AppEventAutoTopup{
+		Identity: e.IdentityAddress,
+		OrderID:  e.ID,
+		Gateway:  a.config.Gateway,
+		Amount:   a.config.Amount,
+		Status:   PaymentOrderStatus(e.Status.Status()),
+	})
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pilvytis
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/mocks"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+type mockIssuer struct {
+	requests []GatewayOrderRequest
+	err      error
+}
+
+func (m *mockIssuer) CreatePaymentGatewayOrder(cgo GatewayOrderRequest) (*GatewayOrderResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.requests = append(m.requests, cgo)
+	return &GatewayOrderResponse{ID: fmt.Sprint(len(m.requests)), Status: PaymentOrderStatusNew}, nil
+}
+
+type mockLocation struct{}
+
+func (mockLocation) GetOrigin() locationstate.Location {
+	return locationstate.Location{Country: "lt"}
+}
+
+func TestAutoTopup(t *testing.T) {
+	id := identity.FromAddress("0x000000000000000000000000000000000000000a")
+	balance := func(myst float64) pingpongEvent.AppEventBalanceChanged {
+		return pingpongEvent.AppEventBalanceChanged{Identity: id, Current: crypto.FloatToBigMyst(myst)}
+	}
+
+	issuer := &mockIssuer{}
+	bus := mocks.NewEventBus()
+	now := time.Now()
+	topup := NewAutoTopup(AutoTopupConfig{
+		Threshold:   crypto.FloatToBigMyst(1),
+		Amount:      "5",
+		Gateway:     "coingate",
+		PayCurrency: "BTC",
+		Interval:    time.Hour,
+	}, issuer, mockLocation{}, bus)
+	topup.now = func() time.Time { return now }
+
+	topup.handleBalanceChanged(balance(2))
+	assert.Empty(t, issuer.requests)
+
+	topup.handleBalanceChanged(balance(0.5))
+	assert.Equal(t, []GatewayOrderRequest{{Identity: id, Gateway: "coingate", MystAmount: "5", PayCurrency: "BTC", Country: "LT"}}, issuer.requests)
+
+	// order is still pending
+	now = now.Add(2 * time.Hour)
+	topup.handleBalanceChanged(balance(0.4))
+	assert.Len(t, issuer.requests, 1)
+
+	topup.handleOrderUpdated(AppEventOrderUpdated{OrderSummary{ID: "1", IdentityAddress: id.Address, Status: PaymentOrderStatusPaid}})
+	topup.handleBalanceChanged(balance(0.3))
+	assert.Len(t, issuer.requests, 2)
+
+	// rate limited
+	topup.handleOrderUpdated(AppEventOrderUpdated{OrderSummary{ID: "2", IdentityAddress: id.Address, Status: PaymentOrderStatusFailed}})
+	topup.handleBalanceChanged(balance(0.3))
+	assert.Len(t, issuer.requests, 2)
+
+	history := bus.GetEventHistory()
+	assert.Len(t, history, 4)
+	assert.Equal(t, AppTopicAutoTopup, history[0].Topic)
+	assert.Equal(t, AppEventAutoTopup{Identity: id.Address, OrderID: "1", Gateway: "coingate", Amount: "5", Status: PaymentOrderStatusPaid}, history[1].Event)
+	assert.Equal(t, PaymentOrderStatusFailed, history[3].Event.(AppEventAutoTopup).Status)
+}
+
+func TestAutoTopup_OrderFailure(t *testing.T) {
+	id := identity.FromAddress("0x000000000000000000000000000000000000000a")
+	bus := mocks.NewEventBus()
+	topup := NewAutoTopup(AutoTopupConfig{
+		Threshold: crypto.FloatToBigMyst(1),
+		Amount:    "5",
+		Gateway:   "coingate",
+	}, &mockIssuer{err: errors.New("boom")}, mockLocation{}, bus)
+
+	topup.handleBalanceChanged(pingpongEvent.AppEventBalanceChanged{Identity: id, Current: crypto.FloatToBigMyst(0)})
+
+	history := bus.GetEventHistory()
+	assert.Len(t, history, 1)
+	assert.Equal(t, AppEventAutoTopup{Identity: id.Address, Gateway: "coingate", Amount: "5", Status: PaymentOrderStatusFailed, Error: "boom"}, history[0].Event)
+}
+
+func TestAutoTopupConfig_Enabled(t *testing.T) {
+	assert.False(t, AutoTopupConfig{}.Enabled())
+	assert.False(t, AutoTopupConfig{Threshold: crypto.FloatToBigMyst(0), Amount: "1", Gateway: "coingate"}.Enabled())
+	assert.True(t, AutoTopupConfig{Threshold: crypto.FloatToBigMyst(1), Amount: "1", Gateway: "coingate"}.Enabled())
+}
@@ -24,3 +24,16 @@ const AppTopicOrderUpdated = "order_updated"
 type AppEventOrderUpdated struct {
 	OrderSummary
 }
+
+// AppTopicAutoTopup is a topic for automatic top-up order creation and completion.
+const AppTopicAutoTopup = "auto_topup"
+
+// AppEventAutoTopup is the event payload for AppTopicAutoTopup topic.
+type AppEventAutoTopup struct {
+	Identity string             `json:"identity"`
+	OrderID  string             `json:"order_id,omitempty"`
+	Gateway  string             `json:"gateway"`
+	Amount   string             `json:"amount"`
+	Status   PaymentOrderStatus `json:"status"`
+	Error    string             `json:"error,omitempty"`
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pilvytis
+
+import (
+	"fmt"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/payments/exchange"
+)
+
+// Gateway is a top-up payment gateway handled by the node itself instead of pilvytis.
+type Gateway interface {
+	// Name returns the gateway name used in order requests.
+	Name() string
+	// Info returns gateway details in the same form as pilvytis gateways.
+	Info() GatewaysResponse
+	// CreateOrder creates a new top-up order.
+	CreateOrder(req GatewayOrderRequest) (*GatewayOrderResponse, error)
+	// Orders returns all orders of the identity with their current status.
+	Orders(id identity.Identity) ([]GatewayOrderResponse, error)
+}
+
+// Gateways combines pilvytis gateways with the ones handled by the node.
+// Requests for node gateways and their orders are routed to them, all other requests go to pilvytis.
+type Gateways struct {
+	*API
+	local []Gateway
+}
+
+// NewGateways returns pilvytis API extended with the given gateways.
+func NewGateways(api *API, local ...Gateway) *Gateways {
+	return &Gateways{
+		API:   api,
+		local: local,
+	}
+}
+
+// GetPaymentGateways returns a slice of supported gateways.
+func (g *Gateways) GetPaymentGateways(optionsCurrency exchange.Currency) ([]GatewaysResponse, error) {
+	gateways, err := g.API.GetPaymentGateways(optionsCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, gw := range g.local {
+		gateways = append(gateways, gw.Info())
+	}
+	return gateways, nil
+}
+
+// GetPaymentGatewayOrders returns a list of payment orders made by a given identity.
+func (g *Gateways) GetPaymentGatewayOrders(id identity.Identity) ([]GatewayOrderResponse, error) {
+	orders, err := g.API.GetPaymentGatewayOrders(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, gw := range g.local {
+		local, err := gw.Orders(id)
+		if err != nil {
+			return nil, fmt.Errorf("could not get %s orders: %w", gw.Name(), err)
+		}
+		orders = append(orders, local...)
+	}
+	return orders, nil
+}
+
+// GetPaymentGatewayOrder returns a payment order by ID that belongs to a given identity.
+func (g *Gateways) GetPaymentGatewayOrder(id identity.Identity, oid string) (*GatewayOrderResponse, error) {
+	order, err := g.localOrder(id, oid)
+	if err != nil || order != nil {
+		return order, err
+	}
+
+	return g.API.GetPaymentGatewayOrder(id, oid)
+}
+
+// GetPaymentGatewayOrderInvoice returns an invoice for a payment order by ID that belongs to a given identity.
+func (g *Gateways) GetPaymentGatewayOrderInvoice(id identity.Identity, oid string) ([]byte, error) {
+	order, err := g.localOrder(id, oid)
+	if err != nil {
+		return nil, err
+	}
+	if order != nil {
+		return nil, fmt.Errorf("invoices are not issued for %s orders", order.GatewayName)
+	}
+
+	return g.API.GetPaymentGatewayOrderInvoice(id, oid)
+}
+
+// GatewayClientCallback triggers a payment callback from the client-side.
+// Node gateways track payments themselves, so the callback is ignored for them.
+func (g *Gateways) GatewayClientCallback(id identity.Identity, gateway string, payload any) error {
+	if g.gateway(gateway) != nil {
+		return nil
+	}
+
+	return g.API.GatewayClientCallback(id, gateway, payload)
+}
+
+func (g *Gateways) createPaymentGatewayOrder(cgo GatewayOrderRequest) (*GatewayOrderResponse, error) {
+	if gw := g.gateway(cgo.Gateway); gw != nil {
+		return gw.CreateOrder(cgo)
+	}
+
+	return g.API.createPaymentGatewayOrder(cgo)
+}
+
+func (g *Gateways) gateway(name string) Gateway {
+	for _, gw := range g.local {
+		if gw.Name() == name {
+			return gw
+		}
+	}
+	return nil
+}
+
+func (g *Gateways) localOrder(id identity.Identity, oid string) (*GatewayOrderResponse, error) {
+	for _, gw := range g.local {
+		orders, err := gw.Orders(id)
+		if err != nil {
+			return nil, fmt.Errorf("could not get %s orders: %w", gw.Name(), err)
+		}
+		for i := range orders {
+			if orders[i].ID == oid {
+				return &orders[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,281 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pilvytis
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/gofrs/uuid"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/rs/zerolog/log"
+	"github.com/shopspring/decimal"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// DirectGatewayName is the name of the gateway accepting MYST transferred directly to the identity channel.
+const DirectGatewayName = "myst"
+
+const (
+	directOrdersBucket = "direct-topup-orders"
+	directOrderTimeout = 24 * time.Hour
+)
+
+// transferTopic is the topic of ERC-20 Transfer(address,address,uint256) event logs.
+var transferTopic = ethcrypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+type directOrderStorage interface {
+	Store(bucket string, data interface{}) error
+	Update(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+}
+
+type channelAddressProvider interface {
+	GetActiveChannelAddress(chainID int64, id common.Address) (common.Address, error)
+	GetMystAddress(chainID int64) (common.Address, error)
+}
+
+type transferLogProvider interface {
+	BlockNumber(chainID int64) (uint64, error)
+	FilterLogs(chainID int64, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+type directOrder struct {
+	ID             string `storm:"id"`
+	Identity       string `storm:"index"`
+	ChainID        int64
+	ChannelAddress string
+	MystAddress    string
+	Amount         *big.Int
+	// FromBlock follows the chain head at the time of creation, earlier transfers do not pay for the order.
+	FromBlock uint64
+	// TxHash is the transfer which paid for the order.
+	TxHash    string
+	Status    PaymentOrderStatus
+	CreatedAt time.Time
+}
+
+// DirectGateway accepts top-ups made by transferring MYST to the identity channel on chain.
+// An order is paid by a MYST transfer of at least the ordered amount to the channel, made after
+// the order was created and not paying for another order. Orders not paid in a day fail.
+type DirectGateway struct {
+	storage   directOrderStorage
+	addresses channelAddressProvider
+	chain     transferLogProvider
+	mu        sync.Mutex
+}
+
+// NewDirectGateway returns a new direct MYST transfer gateway.
+func NewDirectGateway(storage directOrderStorage, addresses channelAddressProvider, chain transferLogProvider) *DirectGateway {
+	return &DirectGateway{
+		storage:   storage,
+		addresses: addresses,
+		chain:     chain,
+	}
+}
+
+// Name returns the gateway name.
+func (g *DirectGateway) Name() string {
+	return DirectGatewayName
+}
+
+// Info returns gateway details.
+func (g *DirectGateway) Info() GatewaysResponse {
+	return GatewaysResponse{
+		Name:       DirectGatewayName,
+		Currencies: []string{"MYST"},
+	}
+}
+
+// CreateOrder creates a new order for a MYST transfer to the identity channel.
+func (g *DirectGateway) CreateOrder(req GatewayOrderRequest) (*GatewayOrderResponse, error) {
+	amount, err := decimal.NewFromString(req.MystAmount)
+	if err != nil || !amount.IsPositive() {
+		return nil, fmt.Errorf("invalid MYST amount %q", req.MystAmount)
+	}
+
+	chainID := config.Current.GetInt64(config.FlagChainID.Name)
+	channel, err := g.addresses.GetActiveChannelAddress(chainID, req.Identity.ToCommonAddress())
+	if err != nil {
+		return nil, fmt.Errorf("could not get channel address: %w", err)
+	}
+	myst, err := g.addresses.GetMystAddress(chainID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get MYST address: %w", err)
+	}
+	block, err := g.chain.BlockNumber(chainID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get chain head: %w", err)
+	}
+
+	uid, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	order := directOrder{
+		ID:             uid.String(),
+		Identity:       req.Identity.Address,
+		ChainID:        chainID,
+		ChannelAddress: channel.Hex(),
+		MystAddress:    myst.Hex(),
+		Amount:         crypto.DecimalToBigMyst(amount),
+		FromBlock:      block + 1,
+		Status:         PaymentOrderStatusNew,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if err := g.storage.Store(directOrdersBucket, &order); err != nil {
+		return nil, fmt.Errorf("could not store order: %w", err)
+	}
+
+	resp := order.response()
+	return &resp, nil
+}
+
+// Orders returns orders of the identity, looking up transfers paying for the unpaid ones.
+// Older orders are matched first and a transfer pays for a single order only.
+func (g *DirectGateway) Orders(id identity.Identity) ([]GatewayOrderResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	orders, err := g.orders(id)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].CreatedAt.Before(orders[j].CreatedAt) })
+
+	claimed := make(map[string]bool)
+	for _, o := range orders {
+		if o.TxHash != "" {
+			claimed[o.TxHash] = true
+		}
+	}
+
+	result := make([]GatewayOrderResponse, 0, len(orders))
+	for _, o := range orders {
+		if o.Status.Incomplete() {
+			if err := g.checkPayment(&o, claimed); err != nil {
+				log.Warn().Err(err).Str("order", o.ID).Msg("Could not check direct top-up order payment")
+			}
+		}
+		result = append(result, o.response())
+	}
+	return result, nil
+}
+
+func (g *DirectGateway) orders(id identity.Identity) ([]directOrder, error) {
+	var all []directOrder
+	if err := g.storage.GetAllFrom(directOrdersBucket, &all); err != nil {
+		return nil, fmt.Errorf("could not get orders: %w", err)
+	}
+
+	var orders []directOrder
+	for _, o := range all {
+		if o.Identity == id.Address {
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
+}
+
+func (g *DirectGateway) checkPayment(o *directOrder, claimed map[string]bool) error {
+	tx, err := g.findTransfer(o, claimed)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case tx != "":
+		o.Status = PaymentOrderStatusPaid
+		o.TxHash = tx
+		claimed[tx] = true
+	case time.Since(o.CreatedAt) > directOrderTimeout:
+		o.Status = PaymentOrderStatusFailed
+	default:
+		return nil
+	}
+
+	return g.storage.Update(directOrdersBucket, &directOrder{ID: o.ID, Status: o.Status, TxHash: o.TxHash})
+}
+
+// findTransfer returns the hash of an unclaimed MYST transfer to the order channel of at least the ordered amount.
+func (g *DirectGateway) findTransfer(o *directOrder, claimed map[string]bool) (string, error) {
+	logs, err := g.chain.FilterLogs(o.ChainID, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(o.FromBlock),
+		Addresses: []common.Address{common.HexToAddress(o.MystAddress)},
+		Topics:    [][]common.Hash{{transferTopic}, nil, {common.HexToAddress(o.ChannelAddress).Hash()}},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, l := range logs {
+		if l.Removed || len(l.Topics) != 3 || l.Topics[0] != transferTopic || len(l.Data) != 32 {
+			continue
+		}
+		if l.Topics[2] != common.HexToAddress(o.ChannelAddress).Hash() || claimed[l.TxHash.Hex()] {
+			continue
+		}
+		if new(big.Int).SetBytes(l.Data).Cmp(o.Amount) >= 0 {
+			return l.TxHash.Hex(), nil
+		}
+	}
+	return "", nil
+}
+
+func (o directOrder) response() GatewayOrderResponse {
+	amount := crypto.BigMystToDecimal(o.Amount).String()
+	data, _ := json.Marshal(struct {
+		PayTo string `json:"pay_to"`
+		Token string `json:"token"`
+	}{
+		PayTo: o.ChannelAddress,
+		Token: o.MystAddress,
+	})
+
+	return GatewayOrderResponse{
+		ID:                o.ID,
+		Status:            o.Status,
+		Identity:          o.Identity,
+		ChainID:           o.ChainID,
+		ChannelAddress:    o.ChannelAddress,
+		GatewayName:       DirectGatewayName,
+		ReceiveMYST:       amount,
+		PayAmount:         amount,
+		PayCurrency:       "MYST",
+		Currency:          "MYST",
+		ItemsSubTotal:     amount,
+		OrderTotal:        amount,
+		PublicGatewayData: data,
+	}
+}
+
+var _ Gateway = (*DirectGateway)(nil)
@@ -0,0 +1,167 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pilvytis
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+type mockChannelAddresses struct{}
+
+func (mockChannelAddresses) GetActiveChannelAddress(chainID int64, id common.Address) (common.Address, error) {
+	return common.HexToAddress("0x1"), nil
+}
+
+func (mockChannelAddresses) GetMystAddress(chainID int64) (common.Address, error) {
+	return common.HexToAddress("0x2"), nil
+}
+
+type mockTransferLogs struct {
+	block uint64
+	logs  []types.Log
+}
+
+func (m *mockTransferLogs) BlockNumber(chainID int64) (uint64, error) {
+	return m.block, nil
+}
+
+func (m *mockTransferLogs) FilterLogs(chainID int64, q ethereum.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	for _, l := range m.logs {
+		if l.BlockNumber >= q.FromBlock.Uint64() {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func (m *mockTransferLogs) transfer(to common.Address, amount *big.Int) {
+	m.block++
+	m.logs = append(m.logs, types.Log{
+		Topics:      []common.Hash{transferTopic, common.HexToAddress("0xf").Hash(), to.Hash()},
+		Data:        common.LeftPadBytes(amount.Bytes(), 32),
+		BlockNumber: m.block,
+		TxHash:      common.BigToHash(new(big.Int).SetUint64(m.block)),
+	})
+}
+
+func TestDirectGateway(t *testing.T) {
+	storage, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	defer storage.Close()
+
+	chain := &mockTransferLogs{block: 10}
+	chain.transfer(common.HexToAddress("0x1"), crypto.FloatToBigMyst(5))
+	gw := NewDirectGateway(storage, mockChannelAddresses{}, chain)
+	id := identity.FromAddress("0x000000000000000000000000000000000000000a")
+
+	_, err = gw.CreateOrder(GatewayOrderRequest{Identity: id, Gateway: DirectGatewayName, MystAmount: "-1"})
+	assert.Error(t, err)
+
+	first, err := gw.CreateOrder(GatewayOrderRequest{Identity: id, Gateway: DirectGatewayName, MystAmount: "2"})
+	require.NoError(t, err)
+	assert.Equal(t, PaymentOrderStatusNew, first.Status)
+	assert.Equal(t, "2", first.ReceiveMYST)
+	assert.Equal(t, common.HexToAddress("0x1").Hex(), first.ChannelAddress)
+
+	second, err := gw.CreateOrder(GatewayOrderRequest{Identity: id, Gateway: DirectGatewayName, MystAmount: "3"})
+	require.NoError(t, err)
+
+	// transfers made before the orders, to other addresses or below the amount do not pay
+	chain.transfer(common.HexToAddress("0x3"), crypto.FloatToBigMyst(5))
+	chain.transfer(common.HexToAddress("0x1"), crypto.FloatToBigMyst(1))
+	orders, err := gw.Orders(id)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]PaymentOrderStatus{first.ID: PaymentOrderStatusNew, second.ID: PaymentOrderStatusNew}, statuses(orders))
+
+	// a transfer pays for a single order only
+	chain.transfer(common.HexToAddress("0x1"), crypto.FloatToBigMyst(3))
+	orders, err = gw.Orders(id)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]PaymentOrderStatus{first.ID: PaymentOrderStatusPaid, second.ID: PaymentOrderStatusNew}, statuses(orders))
+
+	chain.transfer(common.HexToAddress("0x1"), crypto.FloatToBigMyst(3))
+	orders, err = gw.Orders(id)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]PaymentOrderStatus{first.ID: PaymentOrderStatusPaid, second.ID: PaymentOrderStatusPaid}, statuses(orders))
+
+	orders, err = gw.Orders(identity.FromAddress("0x000000000000000000000000000000000000000b"))
+	require.NoError(t, err)
+	assert.Empty(t, orders)
+}
+
+func TestDirectGateway_Expires(t *testing.T) {
+	storage, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	defer storage.Close()
+
+	gw := NewDirectGateway(storage, mockChannelAddresses{}, &mockTransferLogs{})
+	id := identity.FromAddress("0x000000000000000000000000000000000000000a")
+
+	require.NoError(t, storage.Store(directOrdersBucket, &directOrder{
+		ID:        "old",
+		Identity:  id.Address,
+		Amount:    big.NewInt(1),
+		Status:    PaymentOrderStatusNew,
+		CreatedAt: time.Now().Add(-directOrderTimeout - time.Minute),
+	}))
+
+	orders, err := gw.Orders(id)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]PaymentOrderStatus{"old": PaymentOrderStatusFailed}, statuses(orders))
+}
+
+func TestGateways_RoutesLocalGateway(t *testing.T) {
+	storage, err := boltdb.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	defer storage.Close()
+
+	gateways := NewGateways(nil, NewDirectGateway(storage, mockChannelAddresses{}, &mockTransferLogs{}))
+	id := identity.FromAddress("0x000000000000000000000000000000000000000a")
+
+	order, err := gateways.createPaymentGatewayOrder(GatewayOrderRequest{Identity: id, Gateway: DirectGatewayName, MystAmount: "1"})
+	require.NoError(t, err)
+
+	got, err := gateways.GetPaymentGatewayOrder(id, order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, order.ID, got.ID)
+
+	_, err = gateways.GetPaymentGatewayOrderInvoice(id, order.ID)
+	assert.Error(t, err)
+	assert.NoError(t, gateways.GatewayClientCallback(id, DirectGatewayName, nil))
+}
+
+func statuses(orders []GatewayOrderResponse) map[string]PaymentOrderStatus {
+	result := make(map[string]PaymentOrderStatus)
+	for _, o := range orders {
+		result[o.ID] = o.Status
+	}
+	return result
+}
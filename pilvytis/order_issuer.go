@@ -17,15 +17,19 @@
 
 package pilvytis
 
+type orderCreator interface {
+	createPaymentGatewayOrder(cgo GatewayOrderRequest) (*GatewayOrderResponse, error)
+}
+
 // OrderIssuer combines the pilvytis API and order tracker.
 // Only the order issuer can issue new payment orders.
 type OrderIssuer struct {
-	api     *API
+	api     orderCreator
 	tracker *StatusTracker
 }
 
 // NewOrderIssuer returns a new order issuer.
-func NewOrderIssuer(api *API, tracker *StatusTracker) *OrderIssuer {
+func NewOrderIssuer(api orderCreator, tracker *StatusTracker) *OrderIssuer {
 	return &OrderIssuer{
 		api:     api,
 		tracker: tracker,
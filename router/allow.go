@@ -39,7 +39,10 @@ var (
 type Manager interface {
 	ExcludeIP(net.IP) error
 	RemoveExcludedIP(net.IP) error
+	AddTunnelRoutes(iface string) error
+	DeleteTunnelRoutes(iface string) error
 	Clean() error
+	Recover()
 }
 
 func ensureRouterStarted() {
@@ -128,6 +131,20 @@ func ExcludeIP(ip net.IP) error {
 	return nil
 }
 
+// AddTunnelRoutes routes default traffic through the tunnel interface.
+func AddTunnelRoutes(iface string) error {
+	ensureRouterStarted()
+
+	return DefaultRouter.AddTunnelRoutes(iface)
+}
+
+// DeleteTunnelRoutes removes default traffic routes going through the tunnel interface.
+func DeleteTunnelRoutes(iface string) error {
+	ensureRouterStarted()
+
+	return DefaultRouter.DeleteTunnelRoutes(iface)
+}
+
 // Clean removes all previously added routing rules.
 func Clean() error {
 	ensureRouterStarted()
@@ -140,6 +157,13 @@ func Clean() error {
 	return nil
 }
 
+// Recover rolls back routes left behind by a previous unclean exit.
+func Recover() {
+	ensureRouterStarted()
+
+	DefaultRouter.Recover()
+}
+
 // RemoveExcludedIP removes IP based exception to route traffic directly.
 func RemoveExcludedIP(ip net.IP) error {
 	ensureRouterStarted()
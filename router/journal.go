@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// journalEntry is a single route installed by the node, either an excluded IP
+// routed via the gateway or default tunnel routes going through the interface.
+type journalEntry struct {
+	IP        net.IP `json:"ip,omitempty"`
+	Gateway   net.IP `json:"gateway,omitempty"`
+	Interface string `json:"interface,omitempty"`
+}
+
+// journal keeps installed routes on disk, so that routes left behind
+// by an unclean exit can be rolled back on the next start.
+type journal struct {
+	path string
+}
+
+func newJournal(path string) *journal {
+	return &journal{path: path}
+}
+
+// Entries returns routes recorded in the journal.
+func (j *journal) Entries() ([]journalEntry, error) {
+	if j == nil {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(j.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []journalEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Write replaces journal content with given routes.
+func (j *journal) Write(entries []journalEntry) error {
+	if j == nil {
+		return nil
+	}
+
+	if len(entries) == 0 {
+		if err := os.Remove(j.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0700); err != nil {
+		return err
+	}
+
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, j.path)
+}
@@ -22,6 +22,7 @@ package router
 import (
 	"fmt"
 	"net"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -29,16 +30,25 @@ import (
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/router/network"
+	"github.com/mysteriumnetwork/node/utils/netutil"
+)
+
+var (
+	addTunnelRoute    = netutil.AddDefaultRoute
+	deleteTunnelRoute = netutil.DeleteDefaultRoute
 )
 
 type manager struct {
-	mu   sync.Mutex
-	once sync.Once
+	mu          sync.Mutex
+	once        sync.Once
+	recoverOnce sync.Once
 
 	rules     []rule
+	tunnels   []string
 	currentGW net.IP
 
 	routingTable router
+	journal      *journal
 
 	gwCheckInterval time.Duration
 
@@ -65,11 +75,17 @@ func NewManager() *manager {
 		r = &network.RoutingTableRemote{}
 	}
 
+	var j *journal
+	if dataDir := config.GetString(config.FlagDataDir); dataDir != "" {
+		j = newJournal(filepath.Join(dataDir, "routes.json"))
+	}
+
 	return &manager{
 		stop: make(chan struct{}),
 
 		gwCheckInterval: 5 * time.Second,
 		routingTable:    r,
+		journal:         j,
 	}
 }
 
@@ -103,6 +119,7 @@ func (m *manager) ExcludeIP(ip net.IP) error {
 		ip:    ip,
 		usage: 1,
 	})
+	m.persist()
 
 	return nil
 }
@@ -124,6 +141,46 @@ func (m *manager) RemoveExcludedIP(ip net.IP) error {
 			if err := m.routingTable.DeleteRule(ip, m.currentGW); err != nil {
 				return fmt.Errorf("failed to remove excluded rule: %w", err)
 			}
+			m.persist()
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// AddTunnelRoutes routes default traffic through the tunnel interface.
+func (m *manager) AddTunnelRoutes(iface string) error {
+	m.Recover()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := addTunnelRoute(iface); err != nil {
+		return fmt.Errorf("failed to add tunnel routes: %w", err)
+	}
+
+	m.tunnels = append(m.tunnels, iface)
+	m.persist()
+
+	return nil
+}
+
+// DeleteTunnelRoutes removes default traffic routes going through the tunnel interface.
+func (m *manager) DeleteTunnelRoutes(iface string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, tunnel := range m.tunnels {
+		if tunnel != iface {
+			continue
+		}
+
+		m.tunnels = append(m.tunnels[:i], m.tunnels[i+1:]...)
+		m.persist()
+
+		if err := deleteTunnelRoute(iface); err != nil {
+			return fmt.Errorf("failed to delete tunnel routes: %w", err)
 		}
 
 		break
@@ -134,6 +191,7 @@ func (m *manager) RemoveExcludedIP(ip net.IP) error {
 
 func (m *manager) ensureStarted() {
 	m.once.Do(func() {
+		m.Recover()
 		m.forceCheckGW()
 
 		go m.start()
@@ -170,6 +228,7 @@ func (m *manager) Clean() (lastErr error) {
 	}
 
 	m.rules = nil
+	m.persist()
 
 	return nil
 }
@@ -232,5 +291,54 @@ func (m *manager) checkGW() {
 		if err := m.apply(gw); err != nil {
 			log.Error().Err(err).Msg("Failed to apply new routing rules")
 		}
+		m.persist()
+	}
+}
+
+// persist records currently installed routes in the journal, it must be called with the lock held.
+func (m *manager) persist() {
+	entries := make([]journalEntry, 0, len(m.rules)+len(m.tunnels))
+	for _, rule := range m.rules {
+		entries = append(entries, journalEntry{IP: rule.ip, Gateway: m.currentGW})
+	}
+	for _, iface := range m.tunnels {
+		entries = append(entries, journalEntry{Interface: iface})
+	}
+
+	if err := m.journal.Write(entries); err != nil {
+		log.Error().Err(err).Msg("Failed to write routes journal")
+	}
+}
+
+// Recover removes routes left behind by a previous run which did not exit cleanly.
+// It is done only once, before any route is installed by this run.
+func (m *manager) Recover() {
+	m.recoverOnce.Do(m.rollbackJournal)
+}
+
+func (m *manager) rollbackJournal() {
+	entries, err := m.journal.Entries()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read routes journal")
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.Interface != "" {
+			log.Info().Msgf("Rolling back stale tunnel routes of %s", entry.Interface)
+			if err := deleteTunnelRoute(entry.Interface); err != nil {
+				log.Warn().Err(err).Msgf("Failed to roll back stale tunnel routes of %s", entry.Interface)
+			}
+			continue
+		}
+
+		log.Info().Msgf("Rolling back stale route: %s via %s", entry.IP, entry.Gateway)
+		if err := m.routingTable.DeleteRule(entry.IP, entry.Gateway); err != nil {
+			log.Warn().Err(err).Msgf("Failed to roll back stale route: %s via %s", entry.IP, entry.Gateway)
+		}
+	}
+
+	if err := m.journal.Write(nil); err != nil {
+		log.Error().Err(err).Msg("Failed to clear routes journal")
 	}
 }
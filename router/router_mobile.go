@@ -38,8 +38,18 @@ func (m *manager) RemoveExcludedIP(ip net.IP) error {
 	return nil
 }
 
+func (m *manager) AddTunnelRoutes(iface string) error {
+	return nil
+}
+
+func (m *manager) DeleteTunnelRoutes(iface string) error {
+	return nil
+}
+
 func (m *manager) Stop() {}
 
+func (m *manager) Recover() {}
+
 func (m *manager) Clean() error {
 	return nil
 }
@@ -20,6 +20,7 @@ package router
 import (
 	"fmt"
 	"net"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -157,6 +158,84 @@ func Test_router_ReplaceGW(t *testing.T) {
 	assert.Len(t, table.rules, 2)
 }
 
+func Test_router_JournalRollback(t *testing.T) {
+	j := newJournal(filepath.Join(t.TempDir(), "routes.json"))
+
+	table := &mockRoutingTable{gw: net.ParseIP("1.1.1.1")}
+	r := &manager{
+		stop:         make(chan struct{}),
+		routingTable: table,
+		journal:      j,
+	}
+	r.ExcludeIP(net.ParseIP("2.2.2.2"))
+	r.ExcludeIP(net.ParseIP("3.3.3.3"))
+	r.RemoveExcludedIP(net.ParseIP("3.3.3.3"))
+
+	entries, err := j.Entries()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "2.2.2.2", entries[0].IP.String())
+	assert.Equal(t, "1.1.1.1", entries[0].Gateway.String())
+
+	// simulate unclean exit, new manager rolls back routes of the previous run
+	recovered := &manager{
+		stop:         make(chan struct{}),
+		routingTable: table,
+		journal:      j,
+	}
+	recovered.Recover()
+
+	assert.Empty(t, table.rules)
+	entries, err = j.Entries()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func Test_router_JournalRollbackTunnelRoutes(t *testing.T) {
+	tunnels := map[string]bool{}
+	defer func(add, del func(string) error) {
+		addTunnelRoute, deleteTunnelRoute = add, del
+	}(addTunnelRoute, deleteTunnelRoute)
+	addTunnelRoute = func(iface string) error {
+		tunnels[iface] = true
+		return nil
+	}
+	deleteTunnelRoute = func(iface string) error {
+		delete(tunnels, iface)
+		return nil
+	}
+
+	j := newJournal(filepath.Join(t.TempDir(), "routes.json"))
+
+	table := &mockRoutingTable{gw: net.ParseIP("1.1.1.1")}
+	r := &manager{
+		stop:         make(chan struct{}),
+		routingTable: table,
+		journal:      j,
+	}
+	assert.NoError(t, r.AddTunnelRoutes("myst0"))
+	assert.NoError(t, r.AddTunnelRoutes("myst1"))
+	assert.NoError(t, r.DeleteTunnelRoutes("myst1"))
+	assert.Equal(t, map[string]bool{"myst0": true}, tunnels)
+
+	entries, err := j.Entries()
+	assert.NoError(t, err)
+	assert.Equal(t, []journalEntry{{Interface: "myst0"}}, entries)
+
+	// simulate unclean exit, new manager rolls back tunnel routes of the previous run
+	recovered := &manager{
+		stop:         make(chan struct{}),
+		routingTable: table,
+		journal:      j,
+	}
+	assert.NoError(t, recovered.AddTunnelRoutes("myst2"))
+
+	assert.Equal(t, map[string]bool{"myst2": true}, tunnels)
+	entries, err = j.Entries()
+	assert.NoError(t, err)
+	assert.Equal(t, []journalEntry{{Interface: "myst2"}}, entries)
+}
+
 type mockRoutingTable struct {
 	rules map[string]int
 	gw    net.IP
@@ -20,23 +20,35 @@ package noop
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 )
 
+// Delays imitating tunnel setup and teardown.
+const (
+	connectDelay    = 5 * time.Second
+	disconnectDelay = 2 * time.Second
+)
+
 // NewConnection creates a new noop connnection
 func NewConnection() (connection.Connection, error) {
 	return &Connection{
 		stateCh: make(chan connectionstate.State, 100),
+		stopCh:  make(chan struct{}),
 	}, nil
 }
 
-// Connection which does no real tunneling
+// Connection which does no real tunneling, but goes through the session, payment and p2p flows
+// the same way as tunneling connections do.
 type Connection struct {
+	mu        sync.Mutex
 	isRunning bool
 	stateCh   chan connectionstate.State
+	stopCh    chan struct{}
+	stopOnce  sync.Once
 }
 
 var _ connection.Connection = &Connection{}
@@ -53,31 +65,50 @@ func (c *Connection) Statistics() (connectionstate.Statistics, error) {
 
 // Reconnect restarts a connection with a new options.
 func (c *Connection) Reconnect(ctx context.Context, options connection.ConnectOptions) error {
-	return fmt.Errorf("not supported")
+	return c.connect(ctx)
 }
 
 // Start implements the connection.Connection interface
 func (c *Connection) Start(ctx context.Context, params connection.ConnectOptions) error {
+	c.mu.Lock()
 	c.isRunning = true
+	c.mu.Unlock()
+
+	return c.connect(ctx)
+}
 
+func (c *Connection) connect(ctx context.Context) error {
 	c.stateCh <- connectionstate.Connecting
 
-	time.Sleep(5 * time.Second)
+	select {
+	case <-time.After(connectDelay):
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.stopCh:
+		return fmt.Errorf("connection stopped")
+	}
+
 	c.stateCh <- connectionstate.Connected
 	return nil
 }
 
 // Stop implements the connection.Connection interface
 func (c *Connection) Stop() {
-	if !c.isRunning {
+	c.mu.Lock()
+	running := c.isRunning
+	c.isRunning = false
+	c.mu.Unlock()
+	if !running {
 		return
 	}
 
-	c.isRunning = false
-	c.stateCh <- connectionstate.Disconnecting
-	time.Sleep(2 * time.Second)
-	c.stateCh <- connectionstate.NotConnected
-	close(c.stateCh)
+	c.stopOnce.Do(func() {
+		close(c.stopCh)
+		c.stateCh <- connectionstate.Disconnecting
+		time.Sleep(disconnectDelay)
+		c.stateCh <- connectionstate.NotConnected
+		close(c.stateCh)
+	})
 }
 
 // GetConfig returns the consumer configuration for session creation
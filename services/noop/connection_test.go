@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package noop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+)
+
+func TestConnection_StartIsCancellable(t *testing.T) {
+	conn, err := NewConnection()
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = conn.Start(ctx, connection.ConnectOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, connectionstate.Connecting, <-conn.State())
+}
+
+func TestConnection_StopInterruptsStart(t *testing.T) {
+	conn, err := NewConnection()
+	assert.NoError(t, err)
+
+	started := make(chan error)
+	go func() {
+		started <- conn.Start(context.Background(), connection.ConnectOptions{})
+	}()
+	assert.Equal(t, connectionstate.Connecting, <-conn.State())
+
+	go conn.Stop()
+	select {
+	case err := <-started:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("start was not interrupted")
+	}
+	assert.Equal(t, connectionstate.Disconnecting, <-conn.State())
+	assert.Equal(t, connectionstate.NotConnected, <-conn.State())
+
+	// Stop is idempotent.
+	conn.Stop()
+}
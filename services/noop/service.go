@@ -32,12 +32,14 @@ var ErrAlreadyStarted = errors.New("service already started")
 
 // NewManager creates new instance of Noop service
 func NewManager() *Manager {
-	return &Manager{}
+	return &Manager{done: make(chan struct{})}
 }
 
-// Manager represents entrypoint for Noop service
+// Manager represents entrypoint for Noop service.
+// It accepts sessions without creating tunnels, so that session, payment and p2p flows can be tested without root.
 type Manager struct {
-	process sync.WaitGroup
+	done     chan struct{}
+	stopOnce sync.Once
 }
 
 // ProvideConfig provides the session configuration
@@ -47,15 +49,16 @@ func (manager *Manager) ProvideConfig(_ string, _ json.RawMessage, _ *net.UDPCon
 
 // Serve starts service - does block
 func (manager *Manager) Serve(instance *service.Instance) error {
-	manager.process.Add(1)
 	log.Info().Msg("Noop service started successfully")
-	manager.process.Wait()
+	<-manager.done
 	return nil
 }
 
 // Stop stops service
 func (manager *Manager) Stop() error {
-	manager.process.Done()
-	log.Info().Msg("Noop service stopped")
+	manager.stopOnce.Do(func() {
+		close(manager.done)
+		log.Info().Msg("Noop service stopped")
+	})
 	return nil
 }
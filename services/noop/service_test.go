@@ -46,3 +46,10 @@ func Test_Manager_Serve_Stop(t *testing.T) {
 	err := manager.Stop()
 	assert.NoError(t, err)
 }
+
+func Test_Manager_StopTwice(t *testing.T) {
+	manager := NewManager()
+	assert.NoError(t, manager.Stop())
+	assert.NoError(t, manager.Stop())
+	assert.NoError(t, manager.Serve(&service.Instance{}))
+}
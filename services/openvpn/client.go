@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"sync"
 	"time"
 
@@ -38,8 +39,12 @@ import (
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
+// openvpnOverhead covers transport headers and OpenVPN data channel framing
+const openvpnOverhead = 100
+
 // ErrProcessNotStarted represents the error we return when the process is not started yet
 var ErrProcessNotStarted = errors.New("process not started yet")
 
@@ -50,6 +55,8 @@ type processFactory func(options connection.ConnectOptions, sessionConfig VPNCon
 func NewClient(openvpnBinary, scriptDir, runtimeDir string,
 	signerFactory identity.SignerFactory,
 	ipResolver ip.Resolver,
+	mtuProber netutil.PathMTUProber,
+	dnsLeakProtection firewall.DNSLeakProtection,
 ) (connection.Connection, error) {
 	stateCh := make(chan connectionstate.State, 100)
 	client := &Client{
@@ -58,7 +65,9 @@ func NewClient(openvpnBinary, scriptDir, runtimeDir string,
 		signerFactory:       signerFactory,
 		stateCh:             stateCh,
 		ipResolver:          ipResolver,
+		dnsLeakProtection:   dnsLeakProtection,
 		removeAllowedIPRule: func() {},
+		removeDNSLeakRule:   func() {},
 	}
 
 	procFactory := func(options connection.ConnectOptions, sessionConfig VPNConfig) (openvpn.Process, *ClientConfig, error) {
@@ -67,6 +76,10 @@ func NewClient(openvpnBinary, scriptDir, runtimeDir string,
 			return nil, nil, err
 		}
 
+		if mtu := netutil.TunnelMTU(net.ParseIP(sessionConfig.RemoteIP), openvpnOverhead, mtuProber); mtu > 0 {
+			vpnClientConfig.SetMTU(mtu)
+		}
+
 		signer := signerFactory(options.ConsumerID)
 
 		stateMiddleware := newStateMiddleware(stateCh)
@@ -91,7 +104,9 @@ type Client struct {
 	process             openvpn.Process
 	processFactory      processFactory
 	ipResolver          ip.Resolver
+	dnsLeakProtection   firewall.DNSLeakProtection
 	removeAllowedIPRule func()
+	removeDNSLeakRule   func()
 	stopOnce            sync.Once
 }
 
@@ -132,24 +147,58 @@ func (c *Client) Start(ctx context.Context, options connection.ConnectOptions) e
 	proc, clientConfig, err := c.processFactory(options, sessionConfig)
 	if err != nil {
 		log.Info().Err(err).Msg("Client config factory error")
+		c.removeAllowedIPRule()
 		return errors.Wrap(err, "client config factory error")
 	}
 	c.process = proc
 	log.Info().Interface("data", clientConfig).Msgf("Openvpn client configuration")
 
+	if err := c.blockDNSLeaks(options, sessionConfig); err != nil {
+		c.removeAllowedIPRule()
+		return errors.Wrap(err, "failed to block DNS leaks")
+	}
+
 	err = c.process.Start()
 	if err != nil {
+		c.removeDNSLeakRule()
 		c.removeAllowedIPRule()
 	}
 	return errors.Wrap(err, "failed to start client process")
 }
 
+// blockDNSLeaks restricts DNS traffic to the resolvers pushed into the tunnel.
+func (c *Client) blockDNSLeaks(options connection.ConnectOptions, sessionConfig VPNConfig) error {
+	if c.dnsLeakProtection == firewall.DNSLeakProtectionOff || c.dnsLeakProtection == "" {
+		return nil
+	}
+	dnsIPs, err := options.Params.DNS.ResolveIPs(sessionConfig.DNSIPs)
+	if err != nil {
+		return err
+	}
+	if len(dnsIPs) == 0 {
+		log.Warn().Msg("System DNS is used, DNS leak protection is disabled")
+		return nil
+	}
+
+	outboundIP, err := c.ipResolver.GetOutboundIP()
+	if err != nil {
+		return err
+	}
+	removeRule, err := firewall.BlockDNSLeaks(c.dnsLeakProtection, outboundIP, dnsIPs)
+	if err != nil {
+		return err
+	}
+	c.removeDNSLeakRule = removeRule
+	return nil
+}
+
 // Stop stops the connection
 func (c *Client) Stop() {
 	c.stopOnce.Do(func() {
 		if c.process != nil {
 			c.process.Stop()
 		}
+		c.removeDNSLeakRule()
 		c.removeAllowedIPRule()
 	})
 }
@@ -179,6 +228,8 @@ type VPNConfig struct {
 	RemoteProtocol  string `json:"protocol"`
 	TLSPresharedKey string `json:"TLSPresharedKey"`
 	CACertificate   string `json:"CACertificate"`
+	Cipher          string `json:"cipher,omitempty"`
+	TLSCiphers      string `json:"tls_ciphers,omitempty"`
 }
 
 func newAuthMiddleware(sessionID session.ID, signer identity.Signer) management.Middleware {
@@ -25,6 +25,15 @@ import (
 	"github.com/mysteriumnetwork/node/core/connection"
 )
 
+// Ciphers used with providers which don't announce their ones.
+const (
+	defaultCipher    = "AES-256-GCM"
+	defaultTLSCipher = "TLS-ECDHE-ECDSA-WITH-AES-256-GCM-SHA384"
+)
+
+// tcpIPHeaders is the size of IPv4 and TCP headers of segments tunneled through the tun device.
+const tcpIPHeaders = 40
+
 // ClientConfig represents specific "openvpn as client" configuration
 type ClientConfig struct {
 	*config.GenericConfig
@@ -55,13 +64,29 @@ func (c *ClientConfig) SetProtocol(protocol string) {
 	}
 }
 
+// SetCiphers sets data channel cipher and control channel TLS cipher suites, falling back to defaults if empty
+func (c *ClientConfig) SetCiphers(cipher, tlsCiphers string) {
+	if cipher == "" {
+		cipher = defaultCipher
+	}
+	if tlsCiphers == "" {
+		tlsCiphers = defaultTLSCipher
+	}
+	c.SetParam("cipher", cipher)
+	c.SetParam("tls-cipher", tlsCiphers)
+}
+
+// SetMTU sets the tunnel MTU and clamps TCP MSS of tunneled connections to fit it with their IP and TCP headers
+func (c *ClientConfig) SetMTU(mtu int) {
+	c.SetParam("tun-mtu", strconv.Itoa(mtu))
+	c.SetParam("mssfix", strconv.Itoa(mtu-tcpIPHeaders))
+}
+
 func defaultClientConfig(runtimeDir string, scriptSearchPath string) *ClientConfig {
 	clientConfig := ClientConfig{GenericConfig: config.NewConfig(runtimeDir, scriptSearchPath), VpnConfig: nil}
 
 	clientConfig.SetDevice("tun")
-	clientConfig.SetParam("cipher", "AES-256-GCM")
 	clientConfig.SetParam("verb", "3")
-	clientConfig.SetParam("tls-cipher", "TLS-ECDHE-ECDSA-WITH-AES-256-GCM-SHA384")
 	clientConfig.SetKeepAlive(10, 60)
 	clientConfig.SetPingTimerRemote()
 	clientConfig.SetPersistKey()
@@ -113,6 +138,7 @@ func NewClientConfigFromSession(vpnConfig VPNConfig, scriptDir string, runtimeDi
 	clientFileConfig.SetReconnectRetry(2)
 	clientFileConfig.SetClientMode(vpnConfig.RemoteIP, remotePort, localPort)
 	clientFileConfig.SetProtocol(vpnConfig.RemoteProtocol)
+	clientFileConfig.SetCiphers(vpnConfig.Cipher, vpnConfig.TLSCiphers)
 	clientFileConfig.SetTLSCACertificate(vpnConfig.CACertificate)
 	clientFileConfig.SetTLSCrypt(vpnConfig.TLSPresharedKey)
 
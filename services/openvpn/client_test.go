@@ -23,6 +23,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/stretchr/testify/assert"
 )
@@ -32,7 +33,7 @@ func fakeSignerFactory(_ identity.Identity) identity.Signer {
 }
 
 func TestConnection_ErrorsOnInvalidConfig(t *testing.T) {
-	conn, err := NewClient("./", "./", "./", fakeSignerFactory, ip.NewResolverMock("1.1.1.1"))
+	conn, err := NewClient("./", "./", "./", fakeSignerFactory, ip.NewResolverMock("1.1.1.1"), nil, firewall.DNSLeakProtectionOff)
 	connectionOptions := connection.ConnectOptions{}
 	assert.Nil(t, err)
 	err = conn.Start(context.Background(), connectionOptions)
@@ -40,7 +41,34 @@ func TestConnection_ErrorsOnInvalidConfig(t *testing.T) {
 }
 
 func TestConnection_CreatesConnection(t *testing.T) {
-	conn, err := NewClient("./", "./", "./", fakeSignerFactory, ip.NewResolverMock("1.1.1.1"))
+	conn, err := NewClient("./", "./", "./", fakeSignerFactory, ip.NewResolverMock("1.1.1.1"), nil, firewall.DNSLeakProtectionOff)
 	assert.Nil(t, err)
 	assert.NotNil(t, conn)
 }
+
+type dnsLeakFirewall struct {
+	firewall.OutgoingTrafficFirewall
+	outboundIP string
+	resolvers  []string
+}
+
+func (f *dnsLeakFirewall) BlockDNSLeaks(_ firewall.DNSLeakProtection, outboundIP string, resolvers []string) (firewall.OutgoingRuleRemove, error) {
+	f.outboundIP = outboundIP
+	f.resolvers = resolvers
+	return func() {}, nil
+}
+
+func TestClient_BlocksDNSLeaksToTunnelResolvers(t *testing.T) {
+	fw := &dnsLeakFirewall{}
+	defaultFirewall := firewall.DefaultOutgoingFirewall
+	firewall.DefaultOutgoingFirewall = fw
+	defer func() { firewall.DefaultOutgoingFirewall = defaultFirewall }()
+
+	client := &Client{ipResolver: ip.NewResolverMock("1.1.1.1"), dnsLeakProtection: firewall.DNSLeakProtectionStandard}
+	options := connection.ConnectOptions{Params: connection.ConnectParams{DNS: connection.DNSOptionProvider}}
+	err := client.blockDNSLeaks(options, VPNConfig{DNSIPs: "10.8.0.1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "1.1.1.1", fw.outboundIP)
+	assert.Equal(t, []string{"10.8.0.1"}, fw.resolvers)
+	assert.NotNil(t, client.removeDNSLeakRule)
+}
@@ -18,8 +18,12 @@
 package service
 
 import (
+	"net"
+	"sync"
+
 	"github.com/mysteriumnetwork/go-openvpn/openvpn/middlewares/server"
 	"github.com/mysteriumnetwork/go-openvpn/openvpn/middlewares/server/credentials"
+	"github.com/mysteriumnetwork/node/core/service/egress"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/services/openvpn"
 	"github.com/mysteriumnetwork/node/session"
@@ -34,15 +38,23 @@ type authHandler struct {
 
 	clientMap         *clientMap
 	identityExtractor identity.Extractor
+
+	egressSampler *egress.Sampler
+	authenticated map[int]session.ID
+	unwatchEgress map[int]func()
+	egressLock    sync.Mutex
 }
 
 // newAuthHandler return authHandler instance
-func newAuthHandler(clientMap *clientMap, extractor identity.Extractor) *authHandler {
+func newAuthHandler(clientMap *clientMap, extractor identity.Extractor, egressSampler *egress.Sampler) *authHandler {
 	ah := new(authHandler)
 	ah.Middleware = credentials.NewMiddleware(ah.validate)
 	ah.Middleware.ClientsSubscribe(ah.handleClientEvent)
 	ah.clientMap = clientMap
 	ah.identityExtractor = extractor
+	ah.egressSampler = egressSampler
+	ah.authenticated = make(map[int]session.ID)
+	ah.unwatchEgress = make(map[int]func())
 	return ah
 }
 
@@ -50,14 +62,69 @@ func (ah *authHandler) handleClientEvent(event server.ClientEvent) {
 	switch event.EventType {
 	case server.Connect:
 		ah.clientMap.Add(event.ClientID, session.ID(event.Env["username"]))
+		ah.watchEgress(event)
 	case server.Disconnect:
 		ah.clientMap.Remove(event.ClientID)
+		ah.stopWatchingEgress(event.ClientID)
+	}
+}
+
+// watchEgress samples destination ports reached by the client from its tunnel address.
+// Egress is attributed to the session the client authenticated with, never to the username it reports.
+func (ah *authHandler) watchEgress(event server.ClientEvent) {
+	if ah.egressSampler == nil {
+		return
+	}
+
+	clientIP := net.ParseIP(event.Env["ifconfig_pool_remote_ip"]).To4()
+	if clientIP == nil {
+		return
+	}
+
+	ah.egressLock.Lock()
+	defer ah.egressLock.Unlock()
+
+	sessionID, ok := ah.authenticated[event.ClientID]
+	if !ok {
+		return
+	}
+	if unwatch, ok := ah.unwatchEgress[event.ClientID]; ok {
+		unwatch()
+	}
+	ah.unwatchEgress[event.ClientID] = ah.egressSampler.Watch(string(sessionID), net.IPNet{IP: clientIP, Mask: net.CIDRMask(32, 32)})
+}
+
+func (ah *authHandler) stopWatchingEgress(clientID int) {
+	ah.egressLock.Lock()
+	unwatch, ok := ah.unwatchEgress[clientID]
+	delete(ah.unwatchEgress, clientID)
+	delete(ah.authenticated, clientID)
+	ah.egressLock.Unlock()
+
+	if ok {
+		unwatch()
 	}
 }
 
 // handleAuthorisation provides glue code for openvpn management interface to validate incoming client login request,
 // it expects session id as username, and session signature signed by client as password
-func (ah *authHandler) validate(_ int, username, password string) (bool, error) {
+func (ah *authHandler) validate(clientID int, username, password string) (bool, error) {
+	authenticated, err := ah.authenticate(username, password)
+	if err != nil {
+		return false, err
+	}
+
+	ah.egressLock.Lock()
+	defer ah.egressLock.Unlock()
+	if authenticated {
+		ah.authenticated[clientID] = session.ID(username)
+	} else {
+		delete(ah.authenticated, clientID)
+	}
+	return authenticated, nil
+}
+
+func (ah *authHandler) authenticate(username, password string) (bool, error) {
 	sessionID := session.ID(username)
 	currentSession, currentSessionFound := ah.clientMap.GetSession(sessionID)
 	if !currentSessionFound {
@@ -24,6 +24,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/trace"
 	"github.com/stretchr/testify/assert"
 )
@@ -85,6 +86,19 @@ func TestValidateReturnsTrueWhenSessionExistsAndSignatureIsValidAndClientIDMatch
 	assert.True(t, authenticated)
 }
 
+func TestValidateRemembersSessionOfAuthenticatedClient(t *testing.T) {
+	handler := createAuthHandlerWithSession(identityExisting, sessionExisting)
+	handler.validate(1, sessionExistingString, "not important")
+	assert.Equal(t, map[int]session.ID{1: sessionExisting.ID}, handler.authenticated)
+
+	handler.stopWatchingEgress(1)
+	assert.Empty(t, handler.authenticated)
+
+	handler = createAuthHandlerWithSession(identity.FromAddress("wrongsignature"), sessionExisting)
+	handler.validate(1, sessionExistingString, "not important")
+	assert.Empty(t, handler.authenticated)
+}
+
 func TestSecondClientIsNotDisconnectedWhenFirstClientDisconnects(t *testing.T) {
 	var firstClientConnected = []string{
 		">CLIENT:CONNECT,1,4",
@@ -29,7 +29,7 @@ func createAuthHandler(identityToExtract identity.Identity) *authHandler {
 		nil,
 	}
 	mockSessions := &mockSessions{}
-	return newAuthHandler(NewClientMap(mockSessions), mockExtractor)
+	return newAuthHandler(NewClientMap(mockSessions), mockExtractor, nil)
 }
 
 func createAuthHandlerWithSession(identityToExtract identity.Identity, sessionInstance *service.Session) *authHandler {
@@ -41,7 +41,7 @@ func createAuthHandlerWithSession(identityToExtract identity.Identity, sessionIn
 		sessionInstance,
 		true,
 	}
-	return newAuthHandler(NewClientMap(mockSessions), mockExtractor)
+	return newAuthHandler(NewClientMap(mockSessions), mockExtractor, nil)
 }
 
 // mockIdentityExtractor mocked identity extractor
@@ -26,6 +26,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/core/service/egress"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/nat"
@@ -53,6 +54,8 @@ func NewManager(nodeOptions node.Options,
 		ipResolver:      ipResolver,
 
 		openvpnClients: NewClientMap(sessionMap),
+		openvpnProbe:   newManagementProbe(),
+		egressSampler:  egress.NewSampler(bus),
 	}
 }
 
@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/config"
+)
+
+// Server authenticates with an ECDSA certificate and data channel runs with "auth none",
+// so only ECDSA TLS suites and AEAD data ciphers are allowed.
+var (
+	supportedDataCiphers = []string{"AES-256-GCM", "AES-128-GCM", "CHACHA20-POLY1305"}
+	supportedTLSCiphers  = []string{
+		"TLS-ECDHE-ECDSA-WITH-AES-256-GCM-SHA384",
+		"TLS-ECDHE-ECDSA-WITH-AES-128-GCM-SHA256",
+		"TLS-ECDHE-ECDSA-WITH-CHACHA20-POLY1305-SHA256",
+	}
+	supportedTLSVersions = []string{"1.2", "1.3"}
+)
+
+// Hardening describes security options of the OpenVPN server.
+type Hardening struct {
+	DataCiphers   []string `json:"data_ciphers"`
+	TLSCiphers    []string `json:"tls_ciphers"`
+	TLSVersionMin string   `json:"tls_version_min"`
+	DuplicateCN   bool     `json:"duplicate_cn"`
+	NoCompression bool     `json:"no_compression"`
+}
+
+// GetHardening returns OpenVPN server security options from application configuration.
+func GetHardening() Hardening {
+	return Hardening{
+		DataCiphers:   splitCiphers(config.GetString(config.FlagOpenvpnDataCiphers)),
+		TLSCiphers:    splitCiphers(config.GetString(config.FlagOpenvpnTLSCiphers)),
+		TLSVersionMin: config.GetString(config.FlagOpenvpnTLSVersionMin),
+		DuplicateCN:   config.GetBool(config.FlagOpenvpnDuplicateCN),
+		NoCompression: config.GetBool(config.FlagOpenvpnNoCompression),
+	}
+}
+
+// Validate checks that the options are supported.
+func (h Hardening) Validate() error {
+	if len(h.DataCiphers) == 0 {
+		return errors.New("at least one data cipher is required")
+	}
+	for _, cipher := range h.DataCiphers {
+		if !contains(supportedDataCiphers, cipher) {
+			return fmt.Errorf("unsupported data cipher %q, supported: %s", cipher, strings.Join(supportedDataCiphers, ", "))
+		}
+	}
+	if len(h.TLSCiphers) == 0 {
+		return errors.New("at least one TLS cipher is required")
+	}
+	for _, cipher := range h.TLSCiphers {
+		if !contains(supportedTLSCiphers, cipher) {
+			return fmt.Errorf("unsupported TLS cipher %q, supported: %s", cipher, strings.Join(supportedTLSCiphers, ", "))
+		}
+	}
+	if !contains(supportedTLSVersions, h.TLSVersionMin) {
+		return fmt.Errorf("unsupported minimal TLS version %q, supported: %s", h.TLSVersionMin, strings.Join(supportedTLSVersions, ", "))
+	}
+	return nil
+}
+
+// Cipher returns the preferred data cipher.
+func (h Hardening) Cipher() string {
+	return h.DataCiphers[0]
+}
+
+func (h Hardening) apply(c *ServerConfig) {
+	c.SetParam("cipher", h.Cipher())
+	// data-ciphers is available since OpenVPN 2.5, so it is only set if there is something to negotiate.
+	if len(h.DataCiphers) > 1 {
+		c.SetParam("data-ciphers", strings.Join(h.DataCiphers, ":"))
+	}
+	c.SetParam("tls-cipher", strings.Join(h.TLSCiphers, ":"))
+	c.SetParam("tls-version-min", h.TLSVersionMin)
+	if h.DuplicateCN {
+		c.SetFlag("duplicate-cn")
+	}
+	if h.NoCompression {
+		c.SetParam("allow-compression", "no")
+	}
+}
+
+func splitCiphers(list string) []string {
+	var ciphers []string
+	for _, cipher := range strings.Split(list, ":") {
+		if cipher = strings.TrimSpace(cipher); cipher != "" {
+			ciphers = append(ciphers, cipher)
+		}
+	}
+	return ciphers
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
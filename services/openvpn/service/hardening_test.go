@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package service
+
+import (
+	"testing"
+
+	"github.com/mysteriumnetwork/go-openvpn/openvpn/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHardening_Validate(t *testing.T) {
+	valid := Hardening{
+		DataCiphers:   []string{"AES-256-GCM", "CHACHA20-POLY1305"},
+		TLSCiphers:    []string{"TLS-ECDHE-ECDSA-WITH-AES-256-GCM-SHA384"},
+		TLSVersionMin: "1.3",
+	}
+	assert.NoError(t, valid.Validate())
+
+	for name, mutate := range map[string]func(h *Hardening){
+		"no data ciphers":     func(h *Hardening) { h.DataCiphers = nil },
+		"non AEAD cipher":     func(h *Hardening) { h.DataCiphers = []string{"AES-256-CBC"} },
+		"no TLS ciphers":      func(h *Hardening) { h.TLSCiphers = nil },
+		"RSA TLS cipher":      func(h *Hardening) { h.TLSCiphers = []string{"TLS-ECDHE-RSA-WITH-AES-256-GCM-SHA384"} },
+		"obsolete TLS":        func(h *Hardening) { h.TLSVersionMin = "1.0" },
+		"missing TLS version": func(h *Hardening) { h.TLSVersionMin = "" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			h := valid
+			mutate(&h)
+			assert.Error(t, h.Validate())
+		})
+	}
+}
+
+func TestHardening_Apply(t *testing.T) {
+	h := Hardening{
+		DataCiphers:   []string{"CHACHA20-POLY1305", "AES-256-GCM"},
+		TLSCiphers:    []string{"TLS-ECDHE-ECDSA-WITH-AES-256-GCM-SHA384", "TLS-ECDHE-ECDSA-WITH-CHACHA20-POLY1305-SHA256"},
+		TLSVersionMin: "1.3",
+		DuplicateCN:   true,
+		NoCompression: true,
+	}
+	c := &ServerConfig{config.NewConfig("", "")}
+	h.apply(c)
+
+	args, err := c.ToArguments()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"--cipher", "CHACHA20-POLY1305",
+		"--data-ciphers", "CHACHA20-POLY1305:AES-256-GCM",
+		"--tls-cipher", "TLS-ECDHE-ECDSA-WITH-AES-256-GCM-SHA384:TLS-ECDHE-ECDSA-WITH-CHACHA20-POLY1305-SHA256",
+		"--tls-version-min", "1.3",
+		"--duplicate-cn",
+		"--allow-compression", "no",
+	}, args)
+}
+
+func TestHardening_ApplySingleCipher(t *testing.T) {
+	h := Hardening{
+		DataCiphers:   []string{"AES-256-GCM"},
+		TLSCiphers:    []string{"TLS-ECDHE-ECDSA-WITH-AES-256-GCM-SHA384"},
+		TLSVersionMin: "1.2",
+	}
+	c := &ServerConfig{config.NewConfig("", "")}
+	h.apply(c)
+
+	args, err := c.ToArguments()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"--cipher", "AES-256-GCM",
+		"--tls-cipher", "TLS-ECDHE-ECDSA-WITH-AES-256-GCM-SHA384",
+		"--tls-version-min", "1.2",
+	}, args)
+}
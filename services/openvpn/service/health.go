@@ -0,0 +1,83 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/go-openvpn/openvpn/management"
+)
+
+const managementPingTimeout = 10 * time.Second
+
+// managementProbe is a middleware pinging the OpenVPN server through its management interface.
+type managementProbe struct {
+	mu      sync.Mutex
+	cmd     management.CommandWriter
+	timeout time.Duration
+}
+
+func newManagementProbe() *managementProbe {
+	return &managementProbe{timeout: managementPingTimeout}
+}
+
+func (p *managementProbe) Start(cmd management.CommandWriter) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cmd = cmd
+	return nil
+}
+
+func (p *managementProbe) Stop(_ management.CommandWriter) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cmd = nil
+	return nil
+}
+
+func (p *managementProbe) ConsumeLine(_ string) (bool, error) {
+	return false, nil
+}
+
+// ping checks that the OpenVPN server answers management commands in time.
+func (p *managementProbe) ping() error {
+	p.mu.Lock()
+	cmd := p.cmd
+	p.mu.Unlock()
+	if cmd == nil {
+		return errors.New("management interface is not connected")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := cmd.SingleLineCommand("pid")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("management interface ping failed: %w", err)
+		}
+		return nil
+	case <-time.After(p.timeout):
+		return fmt.Errorf("management interface did not answer in %s", p.timeout)
+	}
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/go-openvpn/openvpn/management"
+	"github.com/stretchr/testify/assert"
+)
+
+type blockingConnection struct {
+	management.MockConnection
+	unblock chan struct{}
+}
+
+func (c *blockingConnection) SingleLineCommand(format string, args ...interface{}) (string, error) {
+	<-c.unblock
+	return "", nil
+}
+
+func TestManagementProbe_Ping(t *testing.T) {
+	probe := newManagementProbe()
+	assert.EqualError(t, probe.ping(), "management interface is not connected")
+
+	conn := &management.MockConnection{CommandResult: "pid=123"}
+	assert.NoError(t, probe.Start(conn))
+	assert.NoError(t, probe.ping())
+	assert.Equal(t, "pid", conn.LastLine)
+
+	assert.NoError(t, probe.Stop(conn))
+	assert.Error(t, probe.ping())
+}
+
+func TestManagementProbe_PingTimeout(t *testing.T) {
+	probe := newManagementProbe()
+	probe.timeout = 10 * time.Millisecond
+	conn := &blockingConnection{unblock: make(chan struct{})}
+	defer close(conn.unblock)
+
+	assert.NoError(t, probe.Start(conn))
+	assert.EqualError(t, probe.ping(), "management interface did not answer in 10ms")
+}
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 
@@ -34,6 +35,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/service/egress"
 	"github.com/mysteriumnetwork/node/core/shaper"
 	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/eventbus"
@@ -62,6 +64,8 @@ type Manager struct {
 	openvpnProcess  openvpn.Process
 	openvpnClients  *clientMap
 	openvpnAuth     *authHandler
+	openvpnProbe    *managementProbe
+	egressSampler   *egress.Sampler
 	ipResolver      ip.Resolver
 	serviceOptions  Options
 	nodeOptions     node.Options
@@ -158,6 +162,16 @@ func (m *Manager) Serve(instance *service.Instance) (err error) {
 	return m.openvpnProcess.Wait()
 }
 
+// Probe pings the OpenVPN server through its management interface.
+func (m *Manager) Probe() error {
+	return m.openvpnProbe.ping()
+}
+
+// Exclusive returns true, since OpenVPN server holds its port and subnet, so a restarted service replaces the old one.
+func (m *Manager) Exclusive() bool {
+	return true
+}
+
 // Stop stops service
 func (m *Manager) Stop() error {
 	if m.openvpnProcess != nil {
@@ -191,6 +205,8 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		RemoteProtocol:  m.serviceOptions.Protocol,
 		TLSPresharedKey: m.tlsPrimitives.PresharedKey.ToPEMFormat(),
 		CACertificate:   m.tlsPrimitives.CertificateAuthority.ToPEMFormat(),
+		Cipher:          m.serviceOptions.Hardening.Cipher(),
+		TLSCiphers:      strings.Join(m.serviceOptions.Hardening.TLSCiphers, ":"),
 	}
 	if m.dnsOK {
 		vpnConfig.DNSIPs = m.dnsIP.String()
@@ -224,6 +240,7 @@ func (m *Manager) startServer() error {
 		m.nodeOptions.BindAddress,
 		m.vpnServerPort,
 		m.serviceOptions.Protocol,
+		m.serviceOptions.Hardening,
 	)
 
 	openvpnFilterDeny := stringutil.Split(config.GetString(config.FlagFirewallProtectedNetworks), ',')
@@ -233,7 +250,7 @@ func (m *Manager) startServer() error {
 	}
 
 	stateChannel := make(chan openvpn.State, 10)
-	m.openvpnAuth = newAuthHandler(m.openvpnClients, identity.NewExtractor())
+	m.openvpnAuth = newAuthHandler(m.openvpnClients, identity.NewExtractor(), m.egressSampler)
 	m.openvpnProcess = openvpn.CreateNewProcess(
 		m.nodeOptions.Openvpn.BinaryPath(),
 		vpnServerConfig.GenericConfig,
@@ -247,6 +264,7 @@ func (m *Manager) startServer() error {
 			}
 		}),
 		newStatsPublisher(m.openvpnClients, m.bus, 1),
+		m.openvpnProbe,
 	)
 	if err := m.openvpnProcess.Start(); err != nil {
 		return err
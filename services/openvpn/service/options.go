@@ -19,6 +19,7 @@ package service
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/service"
@@ -31,6 +32,8 @@ type Options struct {
 	Port     int    `json:"port"`
 	Subnet   string `json:"subnet"`
 	Netmask  string `json:"netmask"`
+
+	Hardening Hardening `json:"hardening"`
 }
 
 // GetOptions returns effective OpenVPN service options from application configuration.
@@ -40,6 +43,8 @@ func GetOptions() Options {
 		Port:     config.GetInt(config.FlagOpenvpnPort),
 		Subnet:   config.GetString(config.FlagOpenvpnSubnet),
 		Netmask:  config.GetString(config.FlagOpenvpnNetmask),
+
+		Hardening: GetHardening(),
 	}
 }
 
@@ -55,5 +60,8 @@ func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
 		log.Warn().Err(err).Msg("Failed to parse options from request, using effective options")
 		return &Options{}, err
 	}
+	if err := requestOptions.Hardening.Validate(); err != nil {
+		return &Options{}, fmt.Errorf("invalid OpenVPN hardening options: %w", err)
+	}
 	return requestOptions, nil
 }
@@ -32,6 +32,14 @@ var DefaultOptionsOpenvpn = Options{
 	Port:     config.FlagOpenvpnPort.Value,
 	Subnet:   config.FlagOpenvpnSubnet.Value,
 	Netmask:  config.FlagOpenvpnNetmask.Value,
+
+	Hardening: defaultHardening,
+}
+
+var defaultHardening = Hardening{
+	DataCiphers:   []string{config.FlagOpenvpnDataCiphers.Value},
+	TLSCiphers:    []string{config.FlagOpenvpnTLSCiphers.Value},
+	TLSVersionMin: config.FlagOpenvpnTLSVersionMin.Value,
 }
 
 func Test_ParseJSONOptions_HandlesNil(t *testing.T) {
@@ -62,9 +70,29 @@ func Test_ParseJSONOptions_ValidRequest(t *testing.T) {
 		Port:     1123,
 		Subnet:   "10.10.10.0",
 		Netmask:  "255.255.255.0",
+
+		Hardening: defaultHardening,
 	}, options)
 }
 
+func Test_ParseJSONOptions_Hardening(t *testing.T) {
+	configureDefaults()
+	request := json.RawMessage(`{"hardening": {"data_ciphers": ["AES-128-GCM"], "tls_ciphers": ["TLS-ECDHE-ECDSA-WITH-AES-128-GCM-SHA256"], "tls_version_min": "1.3", "no_compression": true}}`)
+	options, err := ParseJSONOptions(&request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, Hardening{
+		DataCiphers:   []string{"AES-128-GCM"},
+		TLSCiphers:    []string{"TLS-ECDHE-ECDSA-WITH-AES-128-GCM-SHA256"},
+		TLSVersionMin: "1.3",
+		NoCompression: true,
+	}, options.(Options).Hardening)
+
+	request = json.RawMessage(`{"hardening": {"data_ciphers": ["BF-CBC"]}}`)
+	_, err = ParseJSONOptions(&request)
+	assert.Error(t, err)
+}
+
 func configureDefaults() {
 	ctx := emptyContext()
 	config.ParseFlagsServiceOpenvpn(ctx)
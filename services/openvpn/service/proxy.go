@@ -23,6 +23,8 @@ import (
 	"net"
 
 	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/privacy"
 )
 
 func proxyOpenVPN(conn *net.UDPConn, serverPort int) error {
@@ -55,7 +57,7 @@ func copyStreams(dstConn *net.UDPConn, srcConn *net.UDPConn) {
 	}
 
 	log.Debug().Msgf("Total bytes transferred from %s to %s: %d",
-		srcConn.RemoteAddr().String(),
-		dstConn.RemoteAddr().String(),
+		privacy.ConsumerAddr(srcConn.RemoteAddr().String()),
+		privacy.ConsumerAddr(dstConn.RemoteAddr().String()),
 		totalBytes)
 }
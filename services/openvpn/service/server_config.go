@@ -58,6 +58,7 @@ func NewServerConfig(
 	bindAddress string,
 	port int,
 	protocol string,
+	hardening Hardening,
 ) *ServerConfig {
 	serverConfig := ServerConfig{config.NewConfig(runtimeDir, scriptDir)}
 	serverConfig.SetServerMode(port, network, netmask)
@@ -70,13 +71,11 @@ func NewServerConfig(
 	)
 	serverConfig.SetTLSCrypt(secPrimitives.PresharedKey.ToPEMFormat())
 
-	serverConfig.SetParam("cipher", "AES-256-GCM")
+	hardening.apply(&serverConfig)
 	serverConfig.SetParam("verb", "3")
-	serverConfig.SetParam("tls-version-min", "1.2")
 	serverConfig.SetFlag("management-client-pf")
 	serverConfig.SetFlag("management-client-auth")
 	serverConfig.SetParam("verify-client-cert", "none")
-	serverConfig.SetParam("tls-cipher", "TLS-ECDHE-ECDSA-WITH-AES-256-GCM-SHA384")
 	serverConfig.SetParam("reneg-sec", "3600")
 	serverConfig.SetKeepAlive(10, 60)
 	serverConfig.SetPingTimerRemote()
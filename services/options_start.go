@@ -26,6 +26,7 @@ import (
 	"github.com/mysteriumnetwork/node/services/noop"
 	"github.com/mysteriumnetwork/node/services/openvpn"
 	"github.com/mysteriumnetwork/node/services/scraping"
+	"github.com/mysteriumnetwork/node/services/speedtest"
 	"github.com/mysteriumnetwork/node/services/wireguard"
 	"github.com/urfave/cli/v2"
 )
@@ -51,6 +52,8 @@ func GetStartOptions(serviceType string) (opts StartOptions, err error) {
 		opts.AccessPolicyList = []string{"mysterium"}
 	case datatransfer.ServiceType:
 		opts.AccessPolicyList = []string{"mysterium"}
+	case speedtest.ServiceType:
+		opts.AccessPolicyList = getPolicies(config.FlagSpeedtestAccessPolicies, config.FlagAccessPolicyList)
 	}
 	return opts, nil
 }
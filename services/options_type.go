@@ -26,6 +26,7 @@ import (
 	"github.com/mysteriumnetwork/node/services/openvpn"
 	openvpn_service "github.com/mysteriumnetwork/node/services/openvpn/service"
 	"github.com/mysteriumnetwork/node/services/scraping"
+	"github.com/mysteriumnetwork/node/services/speedtest"
 	"github.com/mysteriumnetwork/node/services/wireguard"
 	wireguard_service "github.com/mysteriumnetwork/node/services/wireguard/service"
 	"github.com/pkg/errors"
@@ -39,6 +40,7 @@ var (
 		wireguard.ServiceType:    wireguard_service.ParseJSONOptions,
 		scraping.ServiceType:     wireguard_service.ParseJSONOptions,
 		datatransfer.ServiceType: wireguard_service.ParseJSONOptions,
+		speedtest.ServiceType:    speedtest.ParseJSONOptions,
 	}
 )
 
@@ -47,7 +49,7 @@ type ServiceOptionsParser func(*json.RawMessage) (service.Options, error)
 
 // Types returns all possible service types.
 func Types() []string {
-	return []string{openvpn.ServiceType, wireguard.ServiceType, noop.ServiceType, scraping.ServiceType, datatransfer.ServiceType}
+	return []string{openvpn.ServiceType, wireguard.ServiceType, noop.ServiceType, scraping.ServiceType, datatransfer.ServiceType, speedtest.ServiceType}
 }
 
 // TypeConfiguredOptions returns specific service options.
@@ -63,6 +65,8 @@ func TypeConfiguredOptions(serviceType string) (service.Options, error) {
 		return noop.GetOptions(), nil
 	case datatransfer.ServiceType:
 		return noop.GetOptions(), nil
+	case speedtest.ServiceType:
+		return speedtest.GetOptions(), nil
 	default:
 		return nil, errors.Errorf("unknown service type: %q", serviceType)
 	}
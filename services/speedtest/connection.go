@@ -0,0 +1,271 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package speedtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
+const (
+	requestRetries  = 3
+	requestInterval = 500 * time.Millisecond
+	finishTimeout   = 2 * time.Second
+)
+
+// Result is the outcome of a speedtest measurement.
+type Result struct {
+	// Download and Upload throughput in bits per second.
+	Download uint64
+	Upload   uint64
+}
+
+// NewConnection creates a new speedtest connection.
+func NewConnection(publisher eventbus.Publisher) (connection.Connection, error) {
+	return &Connection{
+		publisher: publisher,
+		stateCh:   make(chan connectionstate.State, 100),
+	}, nil
+}
+
+// Connection measures throughput to the provider instead of tunneling traffic.
+type Connection struct {
+	publisher eventbus.Publisher
+	stateCh   chan connectionstate.State
+
+	conn           *net.UDPConn
+	sent, received uint64
+
+	mu     sync.Mutex
+	result *Result
+	once   sync.Once
+}
+
+var _ connection.Connection = &Connection{}
+
+// State returns connection state channel.
+func (c *Connection) State() <-chan connectionstate.State {
+	return c.stateCh
+}
+
+// Statistics returns connection statistics.
+func (c *Connection) Statistics() (connectionstate.Statistics, error) {
+	return connectionstate.Statistics{
+		At:            time.Now(),
+		BytesSent:     atomic.LoadUint64(&c.sent),
+		BytesReceived: atomic.LoadUint64(&c.received),
+	}, nil
+}
+
+// Reconnect restarts a connection with a new options.
+func (c *Connection) Reconnect(ctx context.Context, options connection.ConnectOptions) error {
+	return fmt.Errorf("not supported")
+}
+
+// Start measures download and upload throughput to the provider and publishes the result.
+func (c *Connection) Start(ctx context.Context, options connection.ConnectOptions) error {
+	var config ServiceConfig
+	if err := json.Unmarshal(options.SessionConfig, &config); err != nil {
+		return fmt.Errorf("failed to unmarshal speedtest config: %w", err)
+	}
+	if options.ProviderNATConn == nil {
+		return errors.New("speedtest requires p2p NAT conn")
+	}
+	c.conn = options.ProviderNATConn
+
+	c.stateCh <- connectionstate.Connecting
+	result, err := c.measure(ctx, config)
+	if err != nil {
+		c.Stop()
+		return fmt.Errorf("speedtest failed: %w", err)
+	}
+
+	c.mu.Lock()
+	c.result = &result
+	c.mu.Unlock()
+
+	log.Info().Msgf("Speedtest result: download %d bit/s, upload %d bit/s", result.Download, result.Upload)
+	c.publisher.Publish(quality.AppTopicSpeedtestResult, quality.SpeedtestEvent{
+		SessionID: string(options.SessionID),
+		Download:  result.Download,
+		Upload:    result.Upload,
+	})
+
+	c.stateCh <- connectionstate.Connected
+	return nil
+}
+
+// Result returns the measurement result, nil until measurement is finished.
+func (c *Connection) Result() *Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.result
+}
+
+// Stop implements the connection.Connection interface
+func (c *Connection) Stop() {
+	c.once.Do(func() {
+		c.stateCh <- connectionstate.Disconnecting
+		if c.conn != nil {
+			c.conn.Close()
+		}
+		c.stateCh <- connectionstate.NotConnected
+		close(c.stateCh)
+	})
+}
+
+// GetConfig returns the consumer configuration for session creation
+func (c *Connection) GetConfig() (connection.ConsumerConfig, error) {
+	return nil, nil
+}
+
+func (c *Connection) measure(ctx context.Context, config ServiceConfig) (Result, error) {
+	download, err := c.download(ctx, config.Duration)
+	if err != nil {
+		return Result{}, fmt.Errorf("download measurement failed: %w", err)
+	}
+
+	upload, err := c.upload(ctx, config.Duration, config.MaxRate)
+	if err != nil {
+		return Result{}, fmt.Errorf("upload measurement failed: %w", err)
+	}
+
+	return Result{Download: download, Upload: upload}, nil
+}
+
+func (c *Connection) download(ctx context.Context, duration time.Duration) (uint64, error) {
+	buf := make([]byte, packetSize*2)
+	var bytes uint64
+	var first, last time.Time
+
+	for attempt := 0; attempt < requestRetries && first.IsZero(); attempt++ {
+		if err := c.write(downloadRequest(duration)); err != nil {
+			return 0, err
+		}
+
+		deadline := time.Now().Add(requestInterval)
+		if attempt == requestRetries-1 {
+			deadline = time.Now().Add(duration + finishTimeout)
+		}
+		for first.IsZero() || time.Now().Before(deadline) {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+
+			c.conn.SetReadDeadline(deadline)
+			n, err := c.conn.Read(buf)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					break
+				}
+				return 0, err
+			}
+			atomic.AddUint64(&c.received, uint64(n))
+
+			if buf[0] == msgFinish {
+				if first.IsZero() {
+					continue
+				}
+				return throughput(bytes, last.Sub(first)), nil
+			}
+			if buf[0] != msgData {
+				continue
+			}
+
+			now := time.Now()
+			if first.IsZero() {
+				first = now
+				deadline = now.Add(duration + finishTimeout)
+			}
+			last = now
+			bytes += uint64(n)
+		}
+	}
+
+	if first.IsZero() {
+		return 0, errors.New("provider did not respond")
+	}
+	return throughput(bytes, last.Sub(first)), nil
+}
+
+func (c *Connection) upload(ctx context.Context, duration time.Duration, maxRate uint64) (uint64, error) {
+	if err := c.write([]byte{msgUpload}); err != nil {
+		return 0, err
+	}
+
+	packet := dataPacket()
+	p := newPacer(maxRate)
+	deadline := time.Now().Add(duration)
+	for time.Now().Before(deadline) {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		// Socket buffers might be full while blasting, closed conn is checked on next read.
+		c.write(packet)
+		p.add(len(packet))
+	}
+
+	buf := make([]byte, packetSize*2)
+	for attempt := 0; attempt < requestRetries*2; attempt++ {
+		if err := c.write([]byte{msgFinish}); err != nil {
+			return 0, err
+		}
+
+		c.conn.SetReadDeadline(time.Now().Add(requestInterval))
+		for {
+			n, err := c.conn.Read(buf)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					break
+				}
+				return 0, err
+			}
+			atomic.AddUint64(&c.received, uint64(n))
+
+			bytes, elapsed, err := parseReport(buf[:n])
+			if err != nil {
+				continue
+			}
+			return throughput(bytes, elapsed), nil
+		}
+	}
+
+	return 0, errors.New("provider did not report upload")
+}
+
+func (c *Connection) write(msg []byte) error {
+	n, err := c.conn.Write(msg)
+	atomic.AddUint64(&c.sent, uint64(n))
+	return err
+}
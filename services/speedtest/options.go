@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package speedtest
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/service"
+)
+
+// Options describes options which are required to start speedtest service.
+type Options struct {
+	// Duration of each measurement direction.
+	Duration time.Duration
+	// MaxRate limits measurement traffic in bits per second, 0 means unlimited.
+	MaxRate uint64
+}
+
+// DefaultOptions is a speedtest service configuration that will be used if no options provided.
+var DefaultOptions = Options{
+	Duration: 5 * time.Second,
+	MaxRate:  0,
+}
+
+// GetOptions returns effective speedtest service options from application configuration.
+func GetOptions() Options {
+	return Options{
+		Duration: config.GetDuration(config.FlagSpeedtestDuration),
+		MaxRate:  config.GetUInt64(config.FlagSpeedtestMaxRate) * 1_000_000,
+	}
+}
+
+// ParseJSONOptions function fills in speedtest options from JSON request
+func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
+	requestOptions := GetOptions()
+	if request == nil {
+		return requestOptions, nil
+	}
+
+	err := json.Unmarshal(*request, &requestOptions)
+	return requestOptions, err
+}
+
+// MarshalJSON implements json.Marshaler interface to provide human readable configuration.
+func (o Options) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Duration string `json:"duration"`
+		MaxRate  uint64 `json:"max_rate_mbps"`
+	}{
+		Duration: o.Duration.String(),
+		MaxRate:  o.MaxRate / 1_000_000,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to receive human readable configuration.
+func (o *Options) UnmarshalJSON(data []byte) error {
+	var options struct {
+		Duration string  `json:"duration"`
+		MaxRate  *uint64 `json:"max_rate_mbps"`
+	}
+
+	if err := json.Unmarshal(data, &options); err != nil {
+		return err
+	}
+
+	if options.Duration != "" {
+		duration, err := time.ParseDuration(options.Duration)
+		if err != nil {
+			return err
+		}
+		o.Duration = duration
+	}
+	if options.MaxRate != nil {
+		o.MaxRate = *options.MaxRate * 1_000_000
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package speedtest
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// Measurement packets exchanged over the NAT conn of the session.
+const (
+	// msgDownload asks provider to send data: [type][uint32 duration ms].
+	msgDownload byte = 'D'
+	// msgUpload announces consumer starts sending data: [type].
+	msgUpload byte = 'U'
+	// msgData carries measurement payload: [type][payload...].
+	msgData byte = 'P'
+	// msgFinish tells the receiver that sending is finished: [type].
+	msgFinish byte = 'F'
+	// msgReport is the upload receiver report: [type][uint64 bytes][uint32 elapsed ms].
+	msgReport byte = 'R'
+)
+
+const packetSize = 1200
+
+var errInvalidMessage = errors.New("invalid speedtest message")
+
+// ServiceConfig is sent by provider to the consumer for establishing a speedtest session.
+type ServiceConfig struct {
+	Duration time.Duration `json:"duration"`
+	MaxRate  uint64        `json:"max_rate"`
+}
+
+func downloadRequest(duration time.Duration) []byte {
+	msg := make([]byte, 5)
+	msg[0] = msgDownload
+	binary.BigEndian.PutUint32(msg[1:], uint32(duration.Milliseconds()))
+	return msg
+}
+
+func parseDownloadRequest(msg []byte) (time.Duration, error) {
+	if len(msg) != 5 || msg[0] != msgDownload {
+		return 0, errInvalidMessage
+	}
+	return time.Duration(binary.BigEndian.Uint32(msg[1:])) * time.Millisecond, nil
+}
+
+func report(bytes uint64, elapsed time.Duration) []byte {
+	msg := make([]byte, 13)
+	msg[0] = msgReport
+	binary.BigEndian.PutUint64(msg[1:], bytes)
+	binary.BigEndian.PutUint32(msg[9:], uint32(elapsed.Milliseconds()))
+	return msg
+}
+
+func parseReport(msg []byte) (uint64, time.Duration, error) {
+	if len(msg) != 13 || msg[0] != msgReport {
+		return 0, 0, errInvalidMessage
+	}
+	return binary.BigEndian.Uint64(msg[1:]), time.Duration(binary.BigEndian.Uint32(msg[9:])) * time.Millisecond, nil
+}
+
+func dataPacket() []byte {
+	packet := make([]byte, packetSize)
+	packet[0] = msgData
+	return packet
+}
+
+// pacer keeps sending rate under the limit in bits per second.
+type pacer struct {
+	rate  uint64
+	start time.Time
+	sent  uint64
+}
+
+func newPacer(rate uint64) *pacer {
+	return &pacer{rate: rate, start: time.Now()}
+}
+
+func (p *pacer) add(n int) {
+	p.sent += uint64(n)
+	if p.rate == 0 {
+		return
+	}
+
+	due := time.Duration(float64(p.sent*8) / float64(p.rate) * float64(time.Second))
+	if wait := due - time.Since(p.start); wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// throughput returns bits per second transferred.
+func throughput(bytes uint64, elapsed time.Duration) uint64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return uint64(float64(bytes*8) / elapsed.Seconds())
+}
@@ -0,0 +1,212 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package speedtest
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/session/event"
+)
+
+// maxDuration caps measurement duration requested by consumers.
+const maxDuration = 30 * time.Second
+
+// NewManager creates new instance of speedtest service.
+func NewManager(publisher eventbus.Publisher, options Options) *Manager {
+	return &Manager{
+		publisher: publisher,
+		options:   options,
+		done:      make(chan struct{}),
+	}
+}
+
+// Manager represents entrypoint for speedtest service.
+type Manager struct {
+	publisher eventbus.Publisher
+	options   Options
+
+	once sync.Once
+	done chan struct{}
+}
+
+// ProvideConfig provides the session configuration and starts serving measurements over the NAT conn.
+func (m *Manager) ProvideConfig(sessionID string, _ json.RawMessage, remoteConn *net.UDPConn) (*service.ConfigParams, error) {
+	if remoteConn == nil {
+		return nil, errors.New("speedtest requires p2p NAT conn")
+	}
+
+	s := &session{
+		id:        sessionID,
+		conn:      remoteConn,
+		maxRate:   m.options.MaxRate,
+		publisher: m.publisher,
+		done:      make(chan struct{}),
+	}
+	go s.serve()
+	go s.publishStats(time.Second)
+
+	var once sync.Once
+	destroy := func() {
+		once.Do(s.stop)
+	}
+
+	return &service.ConfigParams{
+		SessionServiceConfig: ServiceConfig{
+			Duration: m.options.Duration,
+			MaxRate:  m.options.MaxRate,
+		},
+		SessionDestroyCallback: destroy,
+	}, nil
+}
+
+// Serve starts service - does block
+func (m *Manager) Serve(instance *service.Instance) error {
+	log.Info().Msg("Speedtest service started successfully")
+	<-m.done
+	return nil
+}
+
+// Stop stops service
+func (m *Manager) Stop() error {
+	m.once.Do(func() { close(m.done) })
+	log.Info().Msg("Speedtest service stopped")
+	return nil
+}
+
+// session serves measurements of a single consumer.
+type session struct {
+	id        string
+	conn      *net.UDPConn
+	maxRate   uint64
+	publisher eventbus.Publisher
+
+	sent, received uint64
+
+	done chan struct{}
+}
+
+func (s *session) serve() {
+	buf := make([]byte, packetSize*2)
+	var uploaded uint64
+	var uploadStart, uploadEnd time.Time
+	var sending int32
+
+	for {
+		n, err := s.conn.Read(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Warn().Err(err).Msgf("Speedtest session %s read failed", s.id)
+			}
+			return
+		}
+		atomic.AddUint64(&s.received, uint64(n))
+
+		switch buf[0] {
+		case msgDownload:
+			duration, err := parseDownloadRequest(buf[:n])
+			if err != nil {
+				continue
+			}
+			if duration > maxDuration {
+				duration = maxDuration
+			}
+			if atomic.CompareAndSwapInt32(&sending, 0, 1) {
+				go func() {
+					defer atomic.StoreInt32(&sending, 0)
+					s.download(duration)
+				}()
+			}
+		case msgUpload:
+			uploaded = 0
+			uploadStart, uploadEnd = time.Time{}, time.Time{}
+		case msgData:
+			now := time.Now()
+			if uploadStart.IsZero() {
+				uploadStart = now
+			}
+			uploadEnd = now
+			uploaded += uint64(n)
+		case msgFinish:
+			s.write(report(uploaded, uploadEnd.Sub(uploadStart)))
+		}
+	}
+}
+
+func (s *session) download(duration time.Duration) {
+	packet := dataPacket()
+	p := newPacer(s.maxRate)
+	deadline := time.Now().Add(duration)
+	for time.Now().Before(deadline) {
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+
+		if !s.write(packet) {
+			return
+		}
+		p.add(len(packet))
+	}
+
+	for i := 0; i < 3; i++ {
+		s.write([]byte{msgFinish})
+	}
+}
+
+func (s *session) write(msg []byte) bool {
+	n, err := s.conn.Write(msg)
+	atomic.AddUint64(&s.sent, uint64(n))
+	if err != nil {
+		// Buffers of the socket might be full while blasting, only closed conn ends the session.
+		return !errors.Is(err, net.ErrClosed)
+	}
+	return true
+}
+
+func (s *session) publishStats(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.publisher.Publish(event.AppTopicDataTransferred, event.AppEventDataTransferred{
+				ID:   s.id,
+				Up:   atomic.LoadUint64(&s.sent),
+				Down: atomic.LoadUint64(&s.received),
+			})
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *session) stop() {
+	close(s.done)
+	s.conn.Close()
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package speedtest
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/quality"
+)
+
+type mockPublisher struct {
+	mu     sync.Mutex
+	events map[string][]interface{}
+}
+
+func (p *mockPublisher) Publish(topic string, data interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.events == nil {
+		p.events = make(map[string][]interface{})
+	}
+	p.events[topic] = append(p.events[topic], data)
+}
+
+func (p *mockPublisher) get(topic string) []interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.events[topic]
+}
+
+func TestSpeedtest_MeasuresThroughput(t *testing.T) {
+	providerConn, consumerConn := connectedPair(t)
+	publisher := &mockPublisher{}
+
+	manager := NewManager(publisher, Options{Duration: 300 * time.Millisecond, MaxRate: 50_000_000})
+	params, err := manager.ProvideConfig("session-1", nil, providerConn)
+	require.NoError(t, err)
+	defer params.SessionDestroyCallback()
+
+	sessionConfig, err := json.Marshal(params.SessionServiceConfig)
+	require.NoError(t, err)
+
+	conn, err := NewConnection(publisher)
+	require.NoError(t, err)
+	err = conn.Start(context.Background(), connection.ConnectOptions{
+		SessionID:       "session-1",
+		SessionConfig:   sessionConfig,
+		ProviderNATConn: consumerConn,
+	})
+	require.NoError(t, err)
+	defer conn.Stop()
+
+	result := conn.(*Connection).Result()
+	require.NotNil(t, result)
+	assert.NotZero(t, result.Download)
+	assert.NotZero(t, result.Upload)
+
+	events := publisher.get(quality.AppTopicSpeedtestResult)
+	require.Len(t, events, 1)
+	assert.Equal(t, quality.SpeedtestEvent{
+		SessionID: "session-1",
+		Download:  result.Download,
+		Upload:    result.Upload,
+	}, events[0])
+
+	stats, err := conn.Statistics()
+	require.NoError(t, err)
+	assert.NotZero(t, stats.BytesSent)
+	assert.NotZero(t, stats.BytesReceived)
+}
+
+func TestSpeedtest_ProviderNotResponding(t *testing.T) {
+	_, consumerConn := connectedPair(t)
+
+	sessionConfig, err := json.Marshal(ServiceConfig{Duration: 100 * time.Millisecond})
+	require.NoError(t, err)
+
+	conn, err := NewConnection(&mockPublisher{})
+	require.NoError(t, err)
+	err = conn.Start(context.Background(), connection.ConnectOptions{
+		SessionConfig:   sessionConfig,
+		ProviderNATConn: consumerConn,
+	})
+	assert.Error(t, err)
+}
+
+func TestOptions_JSON(t *testing.T) {
+	opts := DefaultOptions
+	err := json.Unmarshal([]byte(`{"duration": "10s", "max_rate_mbps": 100}`), &opts)
+	require.NoError(t, err)
+	assert.Equal(t, Options{Duration: 10 * time.Second, MaxRate: 100_000_000}, opts)
+
+	data, err := json.Marshal(opts)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"duration": "10s", "max_rate_mbps": 100}`, string(data))
+}
+
+func connectedPair(t *testing.T) (*net.UDPConn, *net.UDPConn) {
+	a, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	b, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	aAddr, bAddr := a.LocalAddr().(*net.UDPAddr), b.LocalAddr().(*net.UDPAddr)
+	a.Close()
+	b.Close()
+
+	ca, err := net.DialUDP("udp4", aAddr, bAddr)
+	require.NoError(t, err)
+	cb, err := net.DialUDP("udp4", bAddr, aAddr)
+	require.NoError(t, err)
+	return ca, cb
+}
@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"strconv"
 	"sync"
 	"time"
 
@@ -32,30 +33,43 @@ import (
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/p2p/camouflage"
+	"github.com/mysteriumnetwork/node/p2p/obfs"
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
 	"github.com/mysteriumnetwork/node/services/wireguard/key"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
+	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
+// wgOverhead is the WireGuard encapsulation overhead of IPv6 transport.
+const wgOverhead = 80
+
 type startConn func(conf wgcfg.DeviceConfig) (wg.ConnectionEndpoint, error)
 
 // Options represents connection options.
 type Options struct {
 	DNSScriptDir     string
 	HandshakeTimeout time.Duration
+	// MTUProber discovers path MTU to the provider, nil keeps the default tunnel MTU.
+	MTUProber netutil.PathMTUProber
+	// Obfuscation requests provider to obfuscate WireGuard traffic.
+	Obfuscation bool
+	// Camouflage requests provider to tunnel WireGuard traffic over TLS.
+	Camouflage bool
+	// RekeyInterval is how often keys of the running session are rotated, 0 disables rotation.
+	RekeyInterval time.Duration
+	// RekeyConfirmTimeout is how long rotated keys wait for a handshake before previous ones are restored,
+	// wireguard.RekeyConfirmTimeout is used if 0.
+	RekeyConfirmTimeout time.Duration
+	// DNSLeakProtection blocks DNS traffic not sent to the tunnel resolvers.
+	DNSLeakProtection firewall.DNSLeakProtection
 }
 
 // NewConnection returns new WireGuard connection.
 func NewConnection(opts Options, ipResolver ip.Resolver, endpointFactory wg.EndpointFactory, handshakeWaiter HandshakeWaiter) (connection.Connection, error) {
-	privateKey, err := key.GeneratePrivateKey()
-	if err != nil {
-		return nil, errors.Wrap(err, "could not generate private key")
-	}
-
 	return &Connection{
 		done:                make(chan struct{}),
 		stateCh:             make(chan connectionstate.State, 100),
-		privateKey:          privateKey,
 		opts:                opts,
 		ipResolver:          ipResolver,
 		connEndpointFactory: endpointFactory,
@@ -70,16 +84,22 @@ type Connection struct {
 	stateCh  chan connectionstate.State
 
 	ports               []int
+	keyMu               sync.Mutex
 	privateKey          string
+	pendingKey          string
 	ipResolver          ip.Resolver
 	connectionEndpoint  wg.ConnectionEndpoint
 	removeAllowedIPRule func()
+	removeDNSLeakRule   func()
+	obfsProxy           *obfs.Proxy
+	camouflageTunnel    *camouflage.Tunnel
 	opts                Options
 	connEndpointFactory wg.EndpointFactory
 	handshakeWaiter     HandshakeWaiter
 }
 
 var _ connection.Connection = &Connection{}
+var _ connection.Rekeyer = &Connection{}
 
 // State returns connection state channel.
 func (c *Connection) State() <-chan connectionstate.State {
@@ -130,7 +150,29 @@ func (c *Connection) start(ctx context.Context, start startConn, options connect
 
 	c.stateCh <- connectionstate.Connecting
 
-	if options.ProviderNATConn != nil {
+	var obfuscator obfs.Obfuscator
+	overhead := wgOverhead
+	if config.Camouflage != nil {
+		overhead += camouflage.Overhead
+	} else if config.Obfuscation != nil {
+		obfuscator, err = obfs.New(config.Obfuscation.Method, config.Obfuscation.Key)
+		if err != nil {
+			return errors.Wrap(err, "could not create obfuscator")
+		}
+		overhead += obfuscator.Overhead()
+	}
+
+	mtu := netutil.TunnelMTU(config.Provider.Endpoint.IP, overhead, c.opts.MTUProber)
+
+	if config.Camouflage != nil {
+		if err = c.startCamouflage(ctx, &config, options.ProviderNATConn); err != nil {
+			return errors.Wrap(err, "could not start camouflage tunnel")
+		}
+	} else if obfuscator != nil {
+		if err = c.startObfuscation(&config, obfuscator, options.ProviderNATConn); err != nil {
+			return errors.Wrap(err, "could not start obfuscation proxy")
+		}
+	} else if options.ProviderNATConn != nil {
 		options.ProviderNATConn.Close()
 		config.LocalPort = options.ProviderNATConn.LocalAddr().(*net.UDPAddr).Port
 		config.Provider.Endpoint.Port = options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).Port
@@ -141,13 +183,20 @@ func (c *Connection) start(ctx context.Context, start startConn, options connect
 	if err != nil {
 		return errors.Wrap(err, "could not resolve DNS IPs")
 	}
+	if err = c.blockDNSLeaks(dnsIPs); err != nil {
+		return errors.Wrap(err, "could not block DNS leaks")
+	}
 
 	log.Info().Msg("Starting new connection")
 	var conn wg.ConnectionEndpoint
+	c.keyMu.Lock()
+	privateKey := c.privateKey
+	c.keyMu.Unlock()
+
 	conn, err = start(wgcfg.DeviceConfig{
 		IfaceName:    "", // Interface name will be generated by connection endpoint.
 		Subnet:       config.Consumer.IPAddress,
-		PrivateKey:   c.privateKey,
+		PrivateKey:   privateKey,
 		ListenPort:   config.LocalPort,
 		DNS:          dnsIPs,
 		DNSScriptDir: c.opts.DNSScriptDir,
@@ -159,6 +208,7 @@ func (c *Connection) start(ctx context.Context, start startConn, options connect
 		},
 		ReplacePeers: true,
 		ProxyPort:    options.Params.ProxyPort,
+		MTU:          mtu,
 	})
 	if err != nil {
 		return errors.Wrap(err, "could not start new connection")
@@ -176,6 +226,81 @@ func (c *Connection) start(ctx context.Context, start startConn, options connect
 	return nil
 }
 
+// blockDNSLeaks restricts DNS traffic to the tunnel resolvers, replacing the rules of the previous start.
+func (c *Connection) blockDNSLeaks(dnsIPs []string) error {
+	if c.removeDNSLeakRule != nil {
+		c.removeDNSLeakRule()
+		c.removeDNSLeakRule = nil
+	}
+	if c.opts.DNSLeakProtection == firewall.DNSLeakProtectionOff || c.opts.DNSLeakProtection == "" {
+		return nil
+	}
+	if len(dnsIPs) == 0 {
+		log.Warn().Msg("System DNS is used, DNS leak protection is disabled")
+		return nil
+	}
+
+	outboundIP, err := c.ipResolver.GetOutboundIP()
+	if err != nil {
+		return err
+	}
+	c.removeDNSLeakRule, err = firewall.BlockDNSLeaks(c.opts.DNSLeakProtection, outboundIP, dnsIPs)
+	return err
+}
+
+// startObfuscation routes WireGuard through a local proxy which obfuscates packets
+// sent over the NAT conn, so WireGuard talks to the proxy on loopback instead of the provider.
+func (c *Connection) startObfuscation(config *wg.ServiceConfig, obfuscator obfs.Obfuscator, natConn *net.UDPConn) error {
+	if natConn == nil {
+		return errors.New("obfuscation requires p2p NAT conn to the provider")
+	}
+
+	proxy, err := obfs.NewProxy(natConn, obfuscator, nil)
+	if err != nil {
+		return err
+	}
+
+	if c.obfsProxy != nil {
+		c.obfsProxy.Stop()
+	}
+	c.obfsProxy = proxy
+	proxy.Start()
+
+	log.Info().Msgf("Obfuscating WireGuard traffic using %s", config.Obfuscation.Method)
+	config.LocalPort = 0
+	config.Provider.Endpoint = *proxy.LocalAddr()
+	return nil
+}
+
+// startCamouflage routes WireGuard through a TLS tunnel to the provider camouflage server
+// for networks which allow HTTPS only.
+func (c *Connection) startCamouflage(ctx context.Context, config *wg.ServiceConfig, natConn *net.UDPConn) error {
+	if natConn != nil {
+		natConn.Close()
+	}
+
+	address := net.JoinHostPort(config.Provider.Endpoint.IP.String(), strconv.Itoa(config.Camouflage.Port))
+	tunnel, err := camouflage.Dial(ctx, address, camouflage.ClientConfig{
+		ServerName:  config.Camouflage.ServerName,
+		Fingerprint: config.Camouflage.Fingerprint,
+		Token:       config.Camouflage.Token,
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.camouflageTunnel != nil {
+		c.camouflageTunnel.Stop()
+	}
+	c.camouflageTunnel = tunnel
+	tunnel.Start()
+
+	log.Info().Msgf("Tunneling WireGuard traffic over TLS to %s", address)
+	config.LocalPort = 0
+	config.Provider.Endpoint = *tunnel.LocalAddr()
+	return nil
+}
+
 func (c *Connection) startConn(conf wgcfg.DeviceConfig) (wg.ConnectionEndpoint, error) {
 	conn, err := c.connEndpointFactory()
 	if err != nil {
@@ -201,19 +326,121 @@ func (c *Connection) restartConn(conf wgcfg.DeviceConfig) (wg.ConnectionEndpoint
 	return conn, nil
 }
 
-// GetConfig returns the consumer configuration for session creation
+// GetConfig returns the consumer configuration for session creation.
+// Every session gets a freshly generated key pair.
 func (c *Connection) GetConfig() (connection.ConsumerConfig, error) {
-	publicKey, err := key.PrivateKeyToPublicKey(c.privateKey)
+	privateKey, publicKey, err := generateKeyPair()
 	if err != nil {
-		return nil, errors.Wrap(err, "could not get public key from private key")
+		return nil, err
+	}
+
+	c.keyMu.Lock()
+	c.privateKey = privateKey
+	c.pendingKey = ""
+	c.keyMu.Unlock()
+
+	var obfuscation []string
+	if c.opts.Obfuscation {
+		obfuscation = obfs.Methods()
 	}
 
 	return wg.ConsumerConfig{
-		PublicKey: publicKey,
-		Ports:     c.ports,
+		PublicKey:   publicKey,
+		Ports:       c.ports,
+		Obfuscation: obfuscation,
+		Camouflage:  c.opts.Camouflage,
 	}, nil
 }
 
+// RekeyInterval returns how often keys of the running session should be rotated.
+func (c *Connection) RekeyInterval() time.Duration {
+	return c.opts.RekeyInterval
+}
+
+// RekeyConfig generates a new key pair for the running session and returns the config for the provider.
+// The key pair is applied once the provider answers with its new public key.
+func (c *Connection) RekeyConfig() (connection.ConsumerConfig, error) {
+	privateKey, publicKey, err := generateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	c.keyMu.Lock()
+	c.pendingKey = privateKey
+	c.keyMu.Unlock()
+
+	return wg.RekeyConfig{PublicKey: publicKey}, nil
+}
+
+// Rekey switches the running session to the pending key pair and the new provider public key.
+func (c *Connection) Rekey(sessionConfig []byte) error {
+	var config wg.RekeyConfig
+	if err := json.Unmarshal(sessionConfig, &config); err != nil {
+		return errors.Wrap(err, "failed to unmarshal rekey config")
+	}
+
+	c.keyMu.Lock()
+	defer c.keyMu.Unlock()
+
+	if c.pendingKey == "" {
+		return errors.New("no pending key to rekey with")
+	}
+	if c.connectionEndpoint == nil {
+		return errors.New("connection is not started")
+	}
+
+	rotatedAt := time.Now()
+	previousKey, rotatedKey := c.privateKey, c.pendingKey
+	rollback, err := c.connectionEndpoint.Rekey(rotatedKey, config.PublicKey)
+	if err != nil {
+		return fmt.Errorf("failed to rekey connection endpoint: %w", err)
+	}
+
+	c.privateKey = rotatedKey
+	c.pendingKey = ""
+
+	// Provider restores its previous keys too if no handshake happens with the rotated ones.
+	go func(conn wg.ConnectionEndpoint) {
+		confirmed := wg.ConfirmRekey(conn, rotatedAt, c.rekeyConfirmTimeout(), func() error {
+			if err := rollback(); err != nil {
+				return err
+			}
+
+			c.keyMu.Lock()
+			defer c.keyMu.Unlock()
+			if c.privateKey == rotatedKey {
+				c.privateKey = previousKey
+			}
+			return nil
+		})
+		if confirmed {
+			log.Info().Msg("WireGuard session keys rotated")
+		}
+	}(c.connectionEndpoint)
+	return nil
+}
+
+func (c *Connection) rekeyConfirmTimeout() time.Duration {
+	if c.opts.RekeyConfirmTimeout > 0 {
+		return c.opts.RekeyConfirmTimeout
+	}
+	return wg.RekeyConfirmTimeout
+}
+
+func generateKeyPair() (privateKey, publicKey string, err error) {
+	privateKey, err = key.GeneratePrivateKey()
+	if err != nil {
+		return "", "", errors.Wrap(err, "could not generate private key")
+	}
+
+	publicKey, err = key.PrivateKeyToPublicKey(privateKey)
+	if err != nil {
+		return "", "", errors.Wrap(err, "could not get public key from private key")
+	}
+
+	return privateKey, publicKey, nil
+}
+
 // Stop stops wireguard connection and closes connection endpoint.
 func (c *Connection) Stop() {
 	c.stopOnce.Do(func() {
@@ -224,12 +451,24 @@ func (c *Connection) Stop() {
 			c.removeAllowedIPRule()
 		}
 
+		if c.removeDNSLeakRule != nil {
+			c.removeDNSLeakRule()
+		}
+
 		if c.connectionEndpoint != nil {
 			if err := c.connectionEndpoint.Stop(); err != nil {
 				log.Error().Err(err).Msg("Failed to close wireguard connection")
 			}
 		}
 
+		if c.obfsProxy != nil {
+			c.obfsProxy.Stop()
+		}
+
+		if c.camouflageTunnel != nil {
+			c.camouflageTunnel.Stop()
+		}
+
 		c.stateCh <- connectionstate.NotConnected
 
 		close(c.stateCh)
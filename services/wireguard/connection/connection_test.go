@@ -31,6 +31,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/core/ip"
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/services/wireguard/key"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
 )
 
@@ -93,6 +94,45 @@ func TestConnectionStopOnceAfterHandshakeErrorAndStopCall(t *testing.T) {
 	assert.Equal(t, connectionstate.NotConnected, <-conn.State())
 }
 
+func TestConnectionGetConfigGeneratesFreshKeys(t *testing.T) {
+	conn := newConn(t)
+
+	config1, err := conn.GetConfig()
+	assert.NoError(t, err)
+	config2, err := conn.GetConfig()
+	assert.NoError(t, err)
+
+	assert.NotEqual(t, config1.(wg.ConsumerConfig).PublicKey, config2.(wg.ConsumerConfig).PublicKey)
+}
+
+func TestConnectionRekey(t *testing.T) {
+	conn := newConn(t)
+	_, err := conn.GetConfig()
+	assert.NoError(t, err)
+	sessionConfig, _ := json.Marshal(newServiceConfig())
+	err = conn.Start(context.Background(), connection.ConnectOptions{
+		Params:        connection.ConnectParams{DNS: "1.2.3.4"},
+		SessionConfig: sessionConfig,
+	})
+	assert.NoError(t, err)
+	oldKey := conn.privateKey
+
+	assert.Error(t, conn.Rekey([]byte(`{"PublicKey":"wg2"}`)), "rekey without prepared keys")
+
+	config, err := conn.RekeyConfig()
+	assert.NoError(t, err)
+	err = conn.Rekey([]byte(`{"PublicKey":"wg2"}`))
+	assert.NoError(t, err)
+
+	endpoint := conn.connectionEndpoint.(*mockConnectionEndpoint)
+	assert.Equal(t, "wg2", endpoint.peerPublicKey)
+	assert.Equal(t, conn.privateKey, endpoint.privateKey)
+	assert.NotEqual(t, oldKey, conn.privateKey)
+	publicKey, err := key.PrivateKeyToPublicKey(conn.privateKey)
+	assert.NoError(t, err)
+	assert.Equal(t, wg.RekeyConfig{PublicKey: publicKey}, config)
+}
+
 func newConn(t *testing.T) *Connection {
 	endpointFactory := func() (wg.ConnectionEndpoint, error) {
 		return &mockConnectionEndpoint{}, nil
@@ -130,7 +170,10 @@ func newServiceConfig() wg.ServiceConfig {
 	}
 }
 
-type mockConnectionEndpoint struct{}
+type mockConnectionEndpoint struct {
+	privateKey    string
+	peerPublicKey string
+}
 
 func (mce *mockConnectionEndpoint) ReconfigureConsumerMode(config wgcfg.DeviceConfig) error {
 	return nil
@@ -139,6 +182,23 @@ func (mce *mockConnectionEndpoint) StartConsumerMode(config wgcfg.DeviceConfig)
 func (mce *mockConnectionEndpoint) StartProviderMode(ip string, config wgcfg.DeviceConfig) error {
 	return nil
 }
+func (mce *mockConnectionEndpoint) Rekey(privateKey, peerPublicKey string) (func() error, error) {
+	previousKey, previousPeerKey := mce.privateKey, mce.peerPublicKey
+	mce.privateKey, mce.peerPublicKey = privateKey, peerPublicKey
+	return func() error {
+		mce.privateKey, mce.peerPublicKey = previousKey, previousPeerKey
+		return nil
+	}, nil
+}
+func (mce *mockConnectionEndpoint) Resume(_ string) error {
+	return nil
+}
+func (mce *mockConnectionEndpoint) Detach() (wg.EndpointState, error) {
+	return wg.EndpointState{}, nil
+}
+func (mce *mockConnectionEndpoint) RestoreProviderMode(_ wg.EndpointState) error {
+	return nil
+}
 func (mce *mockConnectionEndpoint) InterfaceName() string                { return "mce0" }
 func (mce *mockConnectionEndpoint) Stop() error                          { return nil }
 func (mce *mockConnectionEndpoint) Config() (wg.ServiceConfig, error)    { return wg.ServiceConfig{}, nil }
@@ -18,6 +18,8 @@
 package wireguard
 
 import (
+	"net"
+
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
 )
 
@@ -32,6 +34,17 @@ type ConnectionEndpoint interface {
 	StartProviderMode(publicIP string, config wgcfg.DeviceConfig) error
 	PeerStats() (wgcfg.Stats, error)
 	Config() (ServiceConfig, error)
+	Rekey(privateKey, peerPublicKey string) (rollback func() error, err error)
+	Resume(peerPublicKey string) error
+	Detach() (EndpointState, error)
+	RestoreProviderMode(state EndpointState) error
 	InterfaceName() string
 	Stop() error
 }
+
+// EndpointState describes provider mode interface which is left running when connection endpoint is detached.
+type EndpointState struct {
+	Config      wgcfg.DeviceConfig `json:"config"`
+	Endpoint    net.UDPAddr        `json:"endpoint"`
+	StatsOffset wgcfg.Stats        `json:"stats_offset"`
+}
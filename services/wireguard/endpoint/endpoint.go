@@ -20,6 +20,7 @@ package endpoint
 import (
 	"fmt"
 	"net"
+	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -44,8 +45,17 @@ func NewConnectionEndpoint(resourceAllocator *resources.Allocator) (wg.Connectio
 	}, nil
 }
 
+// deviceKeeper is implemented by WireGuard clients whose devices are able to outlive them.
+type deviceKeeper interface {
+	Detach() error
+	Adopt(iface string) error
+}
+
 type connectionEndpoint struct {
+	// mu serializes key changes of the running interface.
+	mu                sync.Mutex
 	cfg               wgcfg.DeviceConfig
+	statsOffset       wgcfg.Stats
 	endpoint          net.UDPAddr
 	resourceAllocator *resources.Allocator
 	wgClient          WgClient
@@ -85,6 +95,7 @@ func (ce *connectionEndpoint) StartConsumerMode(cfg wgcfg.DeviceConfig) error {
 func (ce *connectionEndpoint) ReconfigureConsumerMode(cfg wgcfg.DeviceConfig) error {
 	cfg.IfaceName = ce.cfg.IfaceName
 	ce.cfg = cfg
+	ce.statsOffset = wgcfg.Stats{}
 
 	if err := ce.wgClient.ReConfigureDevice(cfg); err != nil {
 		return fmt.Errorf("could not reconfigure device: %w", err)
@@ -124,6 +135,117 @@ func (ce *connectionEndpoint) StartProviderMode(publicIP string, config wgcfg.De
 	return nil
 }
 
+// Rekey replaces own private key and peer public key of the running interface.
+// Peer counters restart with the new peer, so they are carried over to keep stats cumulative.
+// Returned rollback restores the previous keys unless the keys were replaced again since.
+func (ce *connectionEndpoint) Rekey(privateKey, peerPublicKey string) (func() error, error) {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	previous := ce.cfg
+	cfg := ce.cfg
+	cfg.PrivateKey = privateKey
+	cfg.Peer.PublicKey = peerPublicKey
+	if err := ce.reconfigure(cfg); err != nil {
+		return nil, fmt.Errorf("could not rekey device: %w", err)
+	}
+
+	rollback := func() error {
+		ce.mu.Lock()
+		defer ce.mu.Unlock()
+
+		if ce.cfg.PrivateKey != privateKey || ce.cfg.Peer.PublicKey != peerPublicKey {
+			return nil
+		}
+		cfg := ce.cfg
+		cfg.PrivateKey = previous.PrivateKey
+		cfg.Peer.PublicKey = previous.Peer.PublicKey
+		if err := ce.reconfigure(cfg); err != nil {
+			return fmt.Errorf("could not restore device keys: %w", err)
+		}
+		return nil
+	}
+	return rollback, nil
+}
+
+// Resume hands the running interface over to the reconnected peer, keeping its key and listen port.
+// Interface is reconfigured only if the peer reconnected with a new key.
+func (ce *connectionEndpoint) Resume(peerPublicKey string) error {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	if peerPublicKey == ce.cfg.Peer.PublicKey {
+		return nil
+	}
+
+	cfg := ce.cfg
+	cfg.Peer.PublicKey = peerPublicKey
+	if err := ce.reconfigure(cfg); err != nil {
+		return fmt.Errorf("could not resume device: %w", err)
+	}
+	return nil
+}
+
+func (ce *connectionEndpoint) reconfigure(cfg wgcfg.DeviceConfig) error {
+	stats, err := ce.PeerStats()
+	if err != nil {
+		return fmt.Errorf("could not get peer stats: %w", err)
+	}
+
+	if err := ce.wgClient.ReConfigureDevice(cfg); err != nil {
+		return err
+	}
+
+	ce.cfg = cfg
+	ce.statsOffset.BytesSent = stats.BytesSent
+	ce.statsOffset.BytesReceived = stats.BytesReceived
+	return nil
+}
+
+// Detach closes wireguard client leaving provider mode interface running.
+// Interface stays allocated, so it is not destroyed as abandoned.
+func (ce *connectionEndpoint) Detach() (wg.EndpointState, error) {
+	keeper, ok := ce.wgClient.(deviceKeeper)
+	if !ok {
+		return wg.EndpointState{}, errors.New("wireguard client can not leave the device running")
+	}
+
+	if err := keeper.Detach(); err != nil {
+		return wg.EndpointState{}, fmt.Errorf("could not detach device: %w", err)
+	}
+
+	return wg.EndpointState{
+		Config:      ce.cfg,
+		Endpoint:    ce.endpoint,
+		StatsOffset: ce.statsOffset,
+	}, nil
+}
+
+// RestoreProviderMode takes over provider mode interface left running by detached connection endpoint.
+func (ce *connectionEndpoint) RestoreProviderMode(state wg.EndpointState) error {
+	keeper, ok := ce.wgClient.(deviceKeeper)
+	if !ok {
+		return errors.New("wireguard client can not take over a running device")
+	}
+
+	iface := state.Config.IfaceName
+	if err := ce.resourceAllocator.ReserveInterface(iface); err != nil {
+		return fmt.Errorf("could not reserve interface: %w", err)
+	}
+
+	if err := keeper.Adopt(iface); err != nil {
+		if err1 := ce.resourceAllocator.ReleaseInterface(iface); err1 != nil {
+			log.Error().Err(err1).Msg("Can't release reserved interface " + iface)
+		}
+		return fmt.Errorf("could not take over device: %w", err)
+	}
+
+	ce.cfg = state.Config
+	ce.endpoint = state.Endpoint
+	ce.statsOffset = state.StatsOffset
+	return nil
+}
+
 // InterfaceName returns a connection endpoint interface name.
 func (ce *connectionEndpoint) InterfaceName() string {
 	return ce.cfg.IfaceName
@@ -131,7 +253,14 @@ func (ce *connectionEndpoint) InterfaceName() string {
 
 // PeerStats returns stats information about connected peer.
 func (ce *connectionEndpoint) PeerStats() (wgcfg.Stats, error) {
-	return ce.wgClient.PeerStats(ce.cfg.IfaceName)
+	stats, err := ce.wgClient.PeerStats(ce.cfg.IfaceName)
+	if err != nil {
+		return wgcfg.Stats{}, err
+	}
+
+	stats.BytesSent += ce.statsOffset.BytesSent
+	stats.BytesReceived += ce.statsOffset.BytesReceived
+	return stats, nil
 }
 
 // Config provides wireguard service configuration for the current connection endpoint.
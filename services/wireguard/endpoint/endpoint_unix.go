@@ -24,6 +24,9 @@ import (
 )
 
 func (ce *connectionEndpoint) consumerIP(subnet net.IPNet) net.IP {
-	subnet.IP[len(subnet.IP)-1] = byte(2)
-	return subnet.IP
+	// Copy the address, it is shared with the provider interface config.
+	ip := make(net.IP, len(subnet.IP))
+	copy(ip, subnet.IP)
+	ip[len(ip)-1] = byte(2)
+	return ip
 }
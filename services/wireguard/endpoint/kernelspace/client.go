@@ -21,18 +21,19 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net"
+	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
 	"golang.zx2c4.com/wireguard/wgctrl"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 
+	"github.com/mysteriumnetwork/node/router"
 	"github.com/mysteriumnetwork/node/services/wireguard/connection/dns"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
 	"github.com/mysteriumnetwork/node/utils"
 	"github.com/mysteriumnetwork/node/utils/actionstack"
 	"github.com/mysteriumnetwork/node/utils/cmdutil"
-	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
 type client struct {
@@ -73,10 +74,13 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) error {
 	})
 
 	if config.Peer.Endpoint != nil {
-		if err := netutil.AddDefaultRoute(config.IfaceName); err != nil {
+		if err := router.AddTunnelRoutes(config.IfaceName); err != nil {
 			rollback.Run()
 			return err
 		}
+		rollback.Push(func() {
+			_ = router.DeleteTunnelRoutes(config.IfaceName)
+		})
 	}
 
 	err := c.configureDevice(config)
@@ -93,6 +97,12 @@ func (c *client) configureDevice(config wgcfg.DeviceConfig) error {
 		return err
 	}
 
+	if config.MTU > 0 {
+		if err := cmdutil.SudoExec("ip", "link", "set", "dev", config.IfaceName, "mtu", strconv.Itoa(config.MTU)); err != nil {
+			return err
+		}
+	}
+
 	peer, err := peerConfig(config.Peer)
 	if err != nil {
 		return err
@@ -200,6 +210,9 @@ func (c *client) up(iface string) error {
 
 func (c *client) Close() (err error) {
 	errs := utils.ErrorCollection{}
+	if err := router.DeleteTunnelRoutes(c.iface); err != nil {
+		errs.Add(err)
+	}
 	if err := c.DestroyDevice(c.iface); err != nil {
 		errs.Add(err)
 	}
@@ -215,6 +228,28 @@ func (c *client) Close() (err error) {
 	return nil
 }
 
+// Detach closes the client leaving the device running.
+func (c *client) Detach() error {
+	if err := c.wgClient.Close(); err != nil {
+		return fmt.Errorf("could not close client: %w", err)
+	}
+	return nil
+}
+
+// Adopt takes over the running device created by another client.
+func (c *client) Adopt(iface string) error {
+	d, err := c.wgClient.Device(iface)
+	if err != nil {
+		return fmt.Errorf("could not get device %s: %w", iface, err)
+	}
+	if len(d.Peers) != 1 {
+		return errors.New("kernelspace: exactly 1 peer expected")
+	}
+
+	c.iface = iface
+	return nil
+}
+
 func stringToKey(key string) (wgtypes.Key, error) {
 	k, err := base64.StdEncoding.DecodeString(key)
 	if err != nil {
@@ -23,17 +23,20 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
-	"golang.zx2c4.com/wireguard/conn"
+	"github.com/rs/zerolog/log"
 	"golang.zx2c4.com/wireguard/device"
 	"golang.zx2c4.com/wireguard/tun"
 
+	"github.com/mysteriumnetwork/node/router"
 	"github.com/mysteriumnetwork/node/services/wireguard/connection/dns"
+	"github.com/mysteriumnetwork/node/services/wireguard/wgbind"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
 	"github.com/mysteriumnetwork/node/utils/actionstack"
 	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
 type client struct {
+	iface      string
 	tun        tun.Device
 	devAPI     *device.Device
 	dnsManager dns.Manager
@@ -48,11 +51,11 @@ func NewWireguardClient() (*client, error) {
 
 func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) (err error) {
 	rollback := actionstack.NewActionStack()
-	if c.tun, err = CreateTUN(config.IfaceName, config.Subnet); err != nil {
+	if c.tun, err = CreateTUN(config.IfaceName, config.Subnet, config.MTU); err != nil {
 		return errors.Wrap(err, "failed to create TUN device")
 	}
 
-	devAPI := device.NewDevice(c.tun, conn.NewDefaultBind(), device.NewLogger(device.LogLevelVerbose, "[userspace-wg]"))
+	devAPI := device.NewDevice(c.tun, wgbind.NewDefaultBind(), device.NewLogger(device.LogLevelVerbose, "[userspace-wg]"))
 	c.devAPI = devAPI
 	rollback.Push(func() {
 		devAPI.Close()
@@ -65,10 +68,11 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) (err error) {
 	}
 
 	if config.Peer.Endpoint != nil {
-		if err := netutil.AddDefaultRoute(config.IfaceName); err != nil {
+		if err := router.AddTunnelRoutes(config.IfaceName); err != nil {
 			rollback.Run()
 			return fmt.Errorf("could not add default route for %s: %w", config.IfaceName, err)
 		}
+		c.iface = config.IfaceName
 	}
 
 	return nil
@@ -101,6 +105,11 @@ func (c *client) configureDevice(config wgcfg.DeviceConfig) (err error) {
 }
 
 func (c *client) Close() error {
+	if c.iface != "" {
+		if err := router.DeleteTunnelRoutes(c.iface); err != nil {
+			log.Warn().Err(err).Msgf("Failed to delete tunnel routes of %s", c.iface)
+		}
+	}
 	c.devAPI.Close() // c.devAPI.Close() closes c.tun too
 	if err := c.dnsManager.Clean(); err != nil {
 		return fmt.Errorf("could not clean DNS: %w", err)
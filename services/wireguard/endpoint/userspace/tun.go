@@ -28,9 +28,12 @@ import (
 	"golang.zx2c4.com/wireguard/tun"
 )
 
-// CreateTUN creates native TUN device for wireguard.
-func CreateTUN(name string, subnet net.IPNet) (tunDevice tun.Device, err error) {
-	if tunDevice, err = tun.CreateTUN(name, device.DefaultMTU); err != nil {
+// CreateTUN creates native TUN device for wireguard, zero mtu uses the default one.
+func CreateTUN(name string, subnet net.IPNet, mtu int) (tunDevice tun.Device, err error) {
+	if mtu <= 0 {
+		mtu = device.DefaultMTU
+	}
+	if tunDevice, err = tun.CreateTUN(name, mtu); err != nil {
 		return nil, errors.Wrap(err, "failed to create TUN device")
 	}
 	if err = netutil.AssignIP(name, subnet); err != nil {
@@ -20,6 +20,7 @@
 package userspace
 
 import (
+	"fmt"
 	"net"
 	"os"
 	"os/exec"
@@ -34,10 +35,15 @@ import (
 type nativeTun struct {
 	tun    *water.Interface
 	events chan tun.Event
+	mtu    int
 }
 
-// CreateTUN creates native TUN device for wireguard.
-func CreateTUN(name string, subnet net.IPNet) (tun.Device, error) {
+// CreateTUN creates native TUN device for wireguard, zero mtu uses the default one.
+func CreateTUN(name string, subnet net.IPNet, mtu int) (tun.Device, error) {
+	if mtu <= 0 {
+		mtu = device.DefaultMTU
+	}
+
 	tunDevice, err := water.New(water.Config{
 		DeviceType: water.TUN,
 		PlatformSpecificParams: water.PlatformSpecificParams{
@@ -59,9 +65,14 @@ func CreateTUN(name string, subnet net.IPNet) (tun.Device, error) {
 		}
 	}
 
+	if err := setMTU(name, mtu); err != nil {
+		return nil, errors.Wrap(err, "failed to set MTU")
+	}
+
 	return &nativeTun{
 		tun:    tunDevice,
 		events: make(chan tun.Event, 10),
+		mtu:    mtu,
 	}, nil
 }
 
@@ -95,7 +106,7 @@ func (tun *nativeTun) Flush() error {
 }
 
 func (tun *nativeTun) MTU() (int, error) {
-	return device.DefaultMTU, nil
+	return tun.mtu, nil
 }
 
 func renameInterface(name, newname string) error {
@@ -103,6 +114,11 @@ func renameInterface(name, newname string) error {
 	return errors.Wrap(err, string(out))
 }
 
+func setMTU(name string, mtu int) error {
+	out, err := exec.Command("powershell", "-Command", fmt.Sprintf("netsh interface ipv4 set subinterface \"%s\" mtu=%d store=active", name, mtu)).CombinedOutput()
+	return errors.Wrap(err, string(out))
+}
+
 func destroyDevice(name string) error {
 	// Windows implementation is using single device that are reused for the future needs.
 	// Nothing to destroy here.
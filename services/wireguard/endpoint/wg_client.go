@@ -54,7 +54,7 @@ func newWGClient() (WgClient, error) {
 		return remoteclient.New()
 	}
 
-	if isKernelSpaceSupported() {
+	if KernelSpaceSupported() {
 		return kernelspace.NewWireguardClient()
 	}
 
@@ -63,7 +63,8 @@ func newWGClient() (WgClient, error) {
 	return userspace.NewWireguardClient()
 }
 
-func isKernelSpaceSupported() bool {
+// KernelSpaceSupported checks if WireGuard kernel module is available.
+func KernelSpaceSupported() bool {
 	if runtime.GOOS != "linux" {
 		return false
 	}
@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package wireguard
+
+import (
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RekeyConfirmTimeout is how long both peers wait for a handshake with rotated keys.
+// Previous keys are restored if none happens, e.g. when the rekey reply was lost,
+// so that both peers end up with the same keys either way.
+const RekeyConfirmTimeout = 45 * time.Second
+
+// rekeyCheckInterval is how often handshakes are checked while waiting for rekey confirmation.
+const rekeyCheckInterval = time.Second
+
+// ConfirmRekey waits for a handshake with the keys rotated at the given time and
+// calls rollback if none happens before the timeout. It returns true if keys were confirmed.
+func ConfirmRekey(conn ConnectionEndpoint, rotatedAt time.Time, timeout time.Duration, rollback func() error) bool {
+	// Handshake times may be reported with a second precision only.
+	rotatedAt = rotatedAt.Truncate(time.Second)
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(rekeyCheckInterval)
+	defer ticker.Stop()
+
+	handshake := func() (bool, error) {
+		stats, err := conn.PeerStats()
+		if err != nil {
+			return false, err
+		}
+		return !stats.LastHandshake.Before(rotatedAt), nil
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			done, err := handshake()
+			if err != nil {
+				// Connection is gone, nothing to confirm or restore.
+				return false
+			}
+			if done {
+				return true
+			}
+		case <-deadline.C:
+			if done, _ := handshake(); done {
+				return true
+			}
+			log.Warn().Msgf("No handshake with rotated WireGuard keys in %s, restoring previous keys", timeout)
+			if err := rollback(); err != nil {
+				log.Error().Err(err).Msg("Could not restore previous WireGuard keys")
+			}
+			return false
+		}
+	}
+}
@@ -76,7 +76,7 @@ func (a *Allocator) AbandonedInterfaces() ([]net.Interface, error) {
 		if strings.HasPrefix(iface.Name, interfacePrefix) {
 			ifaceID, err := strconv.Atoi(strings.TrimPrefix(iface.Name, interfacePrefix))
 			if err == nil {
-				if _, ok := a.Ifaces[ifaceID]; !ok {
+				if _, ok := a.Ifaces[ifaceID]; !ok && !isDetachedInterface(iface.Name) {
 					list = append(list, iface)
 				}
 			}
@@ -97,13 +97,14 @@ func (a *Allocator) AllocateInterface() (string, error) {
 	}
 
 	for i := 0; i < MaxConnections; i++ {
-		if _, ok := a.Ifaces[i]; !ok {
+		name := fmt.Sprintf("%s%d", interfacePrefix, i)
+		if _, ok := a.Ifaces[i]; !ok && !isDetachedInterface(name) {
 			a.Ifaces[i] = struct{}{}
-			if interfaceExists(ifaces, fmt.Sprintf("%s%d", interfacePrefix, i)) {
+			if interfaceExists(ifaces, name) {
 				continue
 			}
 
-			return fmt.Sprintf("%s%d", interfacePrefix, i), nil
+			return name, nil
 		}
 	}
 
@@ -136,6 +137,45 @@ func (a *Allocator) AllocatePort() (int, error) {
 	return port.Num(), nil
 }
 
+// ReserveInterface marks the name of already existing wireguard network interface as allocated.
+// Interface reserved as detached is taken over by the allocator.
+func (a *Allocator) ReserveInterface(iface string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	i, err := strconv.Atoi(strings.TrimPrefix(iface, interfacePrefix))
+	if err != nil {
+		return err
+	}
+
+	if _, ok := a.Ifaces[i]; ok {
+		return errors.New("interface is already allocated")
+	}
+
+	a.Ifaces[i] = struct{}{}
+	claimDetachedInterface(iface)
+	return nil
+}
+
+// ReserveIPNet marks IP address of already configured wireguard connection as allocated.
+func (a *Allocator) ReserveIPNet(ipnet net.IPNet) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ip4 := ipnet.IP.To4()
+	if ip4 == nil {
+		return errors.New("subnet is not IPv4")
+	}
+
+	i := int(ip4[2])
+	if _, ok := a.IPAddresses[i]; ok {
+		return errors.New("subnet is already allocated")
+	}
+
+	a.IPAddresses[i] = struct{}{}
+	return nil
+}
+
 // ReleaseInterface releases name for the wireguard network interface.
 func (a *Allocator) ReleaseInterface(iface string) error {
 	a.mu.Lock()
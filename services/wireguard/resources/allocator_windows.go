@@ -58,6 +58,9 @@ func (a *Allocator) AbandonedInterfaces() ([]net.Interface, error) {
 	return nil, nil
 }
 
+// ReserveDetachedInterface is not required for Windows implementation and left here just to satisfy the callers.
+func ReserveDetachedInterface(iface string) {}
+
 // AllocateInterface provides available name for the wireguard network interface.
 func (a *Allocator) AllocateInterface() (string, error) {
 	return interfacePrefix, nil
@@ -110,6 +113,30 @@ func (a *Allocator) ReleaseIPNet(ipnet net.IPNet) error {
 	return nil
 }
 
+// ReserveInterface is not required for Windows implementation and left here just to satisfy the interface.
+func (a *Allocator) ReserveInterface(iface string) error {
+	return nil
+}
+
+// ReserveIPNet marks IP address of already configured wireguard connection as allocated.
+func (a *Allocator) ReserveIPNet(ipnet net.IPNet) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ip4 := ipnet.IP.To4()
+	if ip4 == nil {
+		return errors.New("subnet is not IPv4")
+	}
+
+	i := int(ip4[3])
+	if _, ok := a.IPAddresses[i]; ok {
+		return errors.New("subnet is already allocated")
+	}
+
+	a.IPAddresses[i] = struct{}{}
+	return nil
+}
+
 func calcIPNet(ipnet net.IPNet, index int) net.IPNet {
 	ip := make(net.IP, len(ipnet.IP))
 	copy(ip, ipnet.IP)
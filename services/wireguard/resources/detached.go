@@ -0,0 +1,53 @@
+//go:build !windows
+
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package resources
+
+import "sync"
+
+// detached holds names of interfaces left running by sessions detached before node restart.
+// Interface names are shared by all wireguard services, so they are reserved for the whole process.
+var detached = struct {
+	sync.Mutex
+	ifaces map[string]struct{}
+}{ifaces: make(map[string]struct{})}
+
+// ReserveDetachedInterface keeps the interface of the session detached before node restart
+// from being destroyed as abandoned or allocated to other sessions until its session is restored.
+func ReserveDetachedInterface(iface string) {
+	detached.Lock()
+	defer detached.Unlock()
+
+	detached.ifaces[iface] = struct{}{}
+}
+
+func isDetachedInterface(iface string) bool {
+	detached.Lock()
+	defer detached.Unlock()
+
+	_, ok := detached.ifaces[iface]
+	return ok
+}
+
+func claimDetachedInterface(iface string) {
+	detached.Lock()
+	defer detached.Unlock()
+
+	delete(detached.ifaces, iface)
+}
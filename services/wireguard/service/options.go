@@ -30,6 +30,8 @@ import (
 // Options describes options which are required to start Wireguard service.
 type Options struct {
 	Subnet net.IPNet
+	// Obfuscation allows consumers to request obfuscated WireGuard traffic.
+	Obfuscation bool
 }
 
 // DefaultOptions is a wireguard service configuration that will be used if no options provided.
@@ -49,7 +51,8 @@ func GetOptions() Options {
 	}
 
 	return Options{
-		Subnet: *ipnet,
+		Subnet:      *ipnet,
+		Obfuscation: config.GetBool(config.FlagObfuscation),
 	}
 }
 
@@ -68,16 +71,19 @@ func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
 // MarshalJSON implements json.Marshaler interface to provide human readable configuration.
 func (o Options) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
-		Subnet string `json:"subnet"`
+		Subnet      string `json:"subnet"`
+		Obfuscation bool   `json:"obfuscation"`
 	}{
-		Subnet: o.Subnet.String(),
+		Subnet:      o.Subnet.String(),
+		Obfuscation: o.Obfuscation,
 	})
 }
 
 // UnmarshalJSON implements json.Unmarshaler interface to receive human readable configuration.
 func (o *Options) UnmarshalJSON(data []byte) error {
 	var options struct {
-		Subnet string `json:"subnet"`
+		Subnet      string `json:"subnet"`
+		Obfuscation *bool  `json:"obfuscation"`
 	}
 
 	if err := json.Unmarshal(data, &options); err != nil {
@@ -91,6 +97,9 @@ func (o *Options) UnmarshalJSON(data []byte) error {
 		}
 		o.Subnet = *ipnet
 	}
+	if options.Obfuscation != nil {
+		o.Obfuscation = *options.Obfuscation
+	}
 
 	return nil
 }
@@ -60,6 +60,18 @@ func Test_ParseJSONOptions_ValidRequest(t *testing.T) {
 	}, options)
 }
 
+func Test_ParseJSONOptions_Obfuscation(t *testing.T) {
+	configureDefaults()
+	request := json.RawMessage(`{"obfuscation":true}`)
+	options, err := ParseJSONOptions(&request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, Options{
+		Subnet:      DefaultOptions.Subnet,
+		Obfuscation: true,
+	}, options)
+}
+
 func configureDefaults() {
 	ctx := emptyContext()
 	config.ParseFlagsServiceWireguard(ctx)
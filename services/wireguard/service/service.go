@@ -27,14 +27,18 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 
+	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/service/egress"
 	"github.com/mysteriumnetwork/node/core/shaper"
 	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/nat"
+	"github.com/mysteriumnetwork/node/p2p/camouflage"
+	"github.com/mysteriumnetwork/node/p2p/obfs"
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
 	"github.com/mysteriumnetwork/node/services/wireguard/endpoint"
 	"github.com/mysteriumnetwork/node/services/wireguard/key"
@@ -43,6 +47,9 @@ import (
 	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
+// Consumers keep the tunnel alive, so WireGuard renews their handshakes every 2 minutes.
+const handshakeStaleAfter = 5 * time.Minute
+
 // NewManager creates new instance of Wireguard service
 func NewManager(
 	ipResolver ip.Resolver,
@@ -52,28 +59,39 @@ func NewManager(
 	options Options,
 	portSupplier port.ServicePortSupplier,
 	trafficFirewall firewall.IncomingTrafficFirewall,
+	camouflageServer *camouflage.Server,
 ) *Manager {
 	resourcesAllocator := resources.NewAllocator(portSupplier, options.Subnet)
 
 	return &Manager{
 		done:               make(chan struct{}),
+		ready:              make(chan struct{}),
 		resourcesAllocator: resourcesAllocator,
 		ipResolver:         ipResolver,
 		natService:         natService,
 		eventBus:           eventBus,
 		trafficFirewall:    trafficFirewall,
+		egressSampler:      egress.NewSampler(eventBus),
 
 		connEndpointFactory: func() (wg.ConnectionEndpoint, error) {
 			return endpoint.NewConnectionEndpoint(resourcesAllocator)
 		},
 		country:        country,
-		sessionCleanup: map[string]func(){},
+		obfuscation:    options.Obfuscation,
+		camouflage:     camouflageServer,
+		sessionCleanup: map[string]func(stopTunnel bool){},
+		sessionConns:   map[string]wg.ConnectionEndpoint{},
+		sessionNAT:     map[string][]interface{}{},
+
+		statsResolution:     config.GetDuration(config.FlagStatsResolution),
+		rekeyConfirmTimeout: wg.RekeyConfirmTimeout,
 	}
 }
 
 // Manager represents an instance of Wireguard service
 type Manager struct {
 	done        chan struct{}
+	ready       chan struct{}
 	startStopMu sync.Mutex
 
 	resourcesAllocator *resources.Allocator
@@ -81,6 +99,7 @@ type Manager struct {
 	natService      nat.NATService
 	eventBus        eventbus.EventBus
 	trafficFirewall firewall.IncomingTrafficFirewall
+	egressSampler   *egress.Sampler
 
 	dnsOK    bool
 	dnsPort  int
@@ -91,11 +110,31 @@ type Manager struct {
 	ipResolver ip.Resolver
 
 	serviceInstance  *service.Instance
-	sessionCleanup   map[string]func()
+	sessionCleanup   map[string]func(stopTunnel bool)
+	sessionConns     map[string]wg.ConnectionEndpoint
+	sessionNAT       map[string][]interface{}
 	sessionCleanupMu sync.Mutex
 
-	country    string
-	outboundIP string
+	statsResolution time.Duration
+	// rekeyConfirmTimeout is how long rotated keys wait for consumer handshake before previous ones are restored.
+	rekeyConfirmTimeout time.Duration
+
+	country     string
+	outboundIP  string
+	obfuscation bool
+	camouflage  *camouflage.Server
+}
+
+// Capabilities returns optional transport features advertised in service proposal.
+func (m *Manager) Capabilities() []string {
+	var capabilities []string
+	if m.obfuscation {
+		capabilities = append(capabilities, obfs.Capabilities()...)
+	}
+	if m.camouflage != nil {
+		capabilities = append(capabilities, camouflage.Capability)
+	}
+	return capabilities
 }
 
 // ProvideConfig provides the config for consumer and handles new WireGuard connection.
@@ -107,8 +146,19 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		return nil, errors.Wrap(err, "could not unmarshal wg consumer config")
 	}
 
-	remoteConn.Close()
-	listenPort := remoteConn.LocalAddr().(*net.UDPAddr).Port
+	remotePort := remoteConn.LocalAddr().(*net.UDPAddr).Port
+	listenPort := remotePort
+	method, obfuscate := obfs.Negotiate(m.Capabilities(), consumerConfig.Obfuscation)
+	if obfuscate {
+		// WireGuard listens on a separate port, remote conn is kept open for the obfuscation proxy.
+		listenPort, err = m.resourcesAllocator.AllocatePort()
+		if err != nil {
+			return nil, fmt.Errorf("could not allocate port for obfuscated wg: %w", err)
+		}
+	} else {
+		remoteConn.Close()
+	}
+
 	providerConfig, err := m.createProviderConfig(listenPort, consumerConfig.PublicKey)
 	if err != nil {
 		return nil, fmt.Errorf("could not create provider mode wg config: %w", err)
@@ -129,11 +179,42 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		return nil, errors.Wrap(err, "could not get peer config")
 	}
 
+	var obfsProxy *obfs.Proxy
+	if obfuscate {
+		obfsProxy, config.Obfuscation, err = m.startObfuscation(method, remoteConn, listenPort)
+		if err != nil {
+			conn.Stop()
+			return nil, errors.Wrap(err, "could not start obfuscation proxy")
+		}
+		config.Provider.Endpoint.Port = remotePort
+	}
+
+	var releaseCamouflage func()
+	if m.camouflage != nil && consumerConfig.Camouflage {
+		releaseCamouflage, config.Camouflage, err = m.registerCamouflage(listenPort)
+		if err != nil {
+			conn.Stop()
+			return nil, errors.Wrap(err, "could not register camouflage session")
+		}
+	}
+
+	destroy, err := m.startSession(sessionID, conn, &config, providerConfig.Subnet, obfsProxy, releaseCamouflage, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &service.ConfigParams{SessionServiceConfig: config, SessionDestroyCallback: destroy}, nil
+}
+
+// startSession sets up traffic rules, stats and shaping of the session connection and returns the session cleanup.
+// NAT rules kept for the detached session are adopted instead of setting up new ones.
+func (m *Manager) startSession(sessionID string, conn wg.ConnectionEndpoint, config *wg.ServiceConfig, subnet net.IPNet, obfsProxy *obfs.Proxy, releaseCamouflage func(), keptNAT json.RawMessage) (service.DestroyCallback, error) {
+	var err error
 	var dnsIP net.IP
 	var releaseTrafficFirewall firewall.IncomingRuleRemove
 	if m.dnsOK {
 		if m.serviceInstance.Policies().HasDNSRules() {
-			releaseTrafficFirewall, err = m.trafficFirewall.BlockIncomingTraffic(providerConfig.Subnet)
+			releaseTrafficFirewall, err = m.trafficFirewall.BlockIncomingTraffic(subnet)
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to enable traffic blocking")
 			}
@@ -143,19 +224,29 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		config.Consumer.DNSIPs = dnsIP.String()
 	}
 
-	natRules, err := m.natService.Setup(nat.Options{
-		VPNNetwork:        config.Consumer.IPAddress,
-		DNSIP:             dnsIP,
-		ProviderExtIP:     net.ParseIP(m.outboundIP),
-		EnableDNSRedirect: m.dnsOK,
-		DNSPort:           m.dnsPort,
-	})
+	var natRules []interface{}
+	if keptNAT != nil {
+		keeper, ok := m.natService.(nat.RuleKeeper)
+		if !ok {
+			return nil, errors.New("NAT service can not adopt kept rules")
+		}
+		natRules, err = keeper.Adopt(keptNAT)
+	} else {
+		natRules, err = m.natService.Setup(nat.Options{
+			VPNNetwork:        config.Consumer.IPAddress,
+			DNSIP:             dnsIP,
+			ProviderExtIP:     net.ParseIP(m.outboundIP),
+			EnableDNSRedirect: m.dnsOK,
+			DNSPort:           m.dnsPort,
+		})
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to setup NAT/firewall rules")
 	}
 
-	statsPublisher := newStatsPublisher(m.eventBus, time.Second)
+	statsPublisher := newStatsPublisher(m.eventBus, time.Second, m.statsResolution)
 	go statsPublisher.start(sessionID, conn)
+	unwatchEgress := m.egressSampler.Watch(sessionID, config.Consumer.IPAddress)
 
 	ifaceName := conn.InterfaceName()
 	s := shaper.New(m.eventBus)
@@ -164,18 +255,22 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		log.Error().Err(err).Msg("Could not start traffic shaper")
 	}
 
-	destroy := func() {
+	cleanup := func(stopTunnel bool) {
 		log.Info().Msgf("Cleaning up session %s", sessionID)
 		m.sessionCleanupMu.Lock()
 		_, ok := m.sessionCleanup[sessionID]
 		if !ok {
+			m.sessionCleanupMu.Unlock()
 			log.Info().Msgf("Session '%s' was already cleaned up, returning without changes", sessionID)
 			return
 		}
 		delete(m.sessionCleanup, sessionID)
+		delete(m.sessionConns, sessionID)
+		delete(m.sessionNAT, sessionID)
 		m.sessionCleanupMu.Unlock()
 
 		statsPublisher.stop()
+		unwatchEgress()
 
 		s.Clear(ifaceName)
 
@@ -185,6 +280,19 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 			}
 		}
 
+		if obfsProxy != nil {
+			obfsProxy.Stop()
+		}
+
+		if releaseCamouflage != nil {
+			releaseCamouflage()
+		}
+
+		if !stopTunnel {
+			log.Info().Msgf("Leaving interface %s and NAT rules of session %s running", ifaceName, sessionID)
+			return
+		}
+
 		log.Trace().Msg("Deleting nat rules")
 		if err := m.natService.Del(natRules); err != nil {
 			log.Error().Err(err).Msg("Failed to delete NAT rules")
@@ -195,16 +303,244 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 			log.Error().Err(err).Msg("Failed to stop connection endpoint")
 		}
 
-		if err := m.resourcesAllocator.ReleaseIPNet(providerConfig.Subnet); err != nil {
+		if err := m.resourcesAllocator.ReleaseIPNet(subnet); err != nil {
 			log.Error().Err(err).Msg("Failed to release IP network")
 		}
 	}
 
 	m.sessionCleanupMu.Lock()
-	m.sessionCleanup[sessionID] = destroy
+	m.sessionCleanup[sessionID] = cleanup
+	m.sessionConns[sessionID] = conn
+	m.sessionNAT[sessionID] = natRules
 	m.sessionCleanupMu.Unlock()
 
-	return &service.ConfigParams{SessionServiceConfig: config, SessionDestroyCallback: destroy}, nil
+	return func() { cleanup(true) }, nil
+}
+
+// sessionState describes the detached session, which keeps its interface and NAT rules while the node restarts.
+type sessionState struct {
+	Endpoint wg.EndpointState `json:"endpoint"`
+	NAT      json.RawMessage  `json:"nat"`
+}
+
+// ReserveDetached keeps the interface of the detached session from being taken by other sessions
+// or destroyed as abandoned before the session is restored.
+func ReserveDetached(data json.RawMessage) error {
+	var state sessionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("could not unmarshal wg session state: %w", err)
+	}
+	if state.Endpoint.Config.IfaceName == "" {
+		return errors.New("no interface of detached session")
+	}
+
+	resources.ReserveDetachedInterface(state.Endpoint.Config.IfaceName)
+	return nil
+}
+
+// Detach ends the session leaving its WireGuard interface and NAT rules running, so it can be restored after node restart.
+// Sessions filtered by traffic firewall are not detached, since the firewall does not outlive the node.
+func (m *Manager) Detach(sessionID string) (json.RawMessage, error) {
+	if m.obfuscation || m.camouflage != nil {
+		return nil, errors.New("sessions of obfuscated service can not be restored")
+	}
+	if m.dnsOK && m.serviceInstance.Policies().HasDNSRules() {
+		return nil, errors.New("sessions of service with traffic firewall can not be restored")
+	}
+	keeper, ok := m.natService.(nat.RuleKeeper)
+	if !ok {
+		return nil, errors.New("NAT service can not keep rules of the session")
+	}
+
+	m.sessionCleanupMu.Lock()
+	conn, ok := m.sessionConns[sessionID]
+	cleanup := m.sessionCleanup[sessionID]
+	natRules := m.sessionNAT[sessionID]
+	m.sessionCleanupMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no connection of session %s", sessionID)
+	}
+
+	natState, err := keeper.Keep(natRules)
+	if err != nil {
+		return nil, fmt.Errorf("could not keep NAT rules: %w", err)
+	}
+
+	endpointState, err := conn.Detach()
+	if err != nil {
+		return nil, fmt.Errorf("could not detach connection endpoint: %w", err)
+	}
+	cleanup(false)
+
+	return json.Marshal(sessionState{Endpoint: endpointState, NAT: natState})
+}
+
+// Restore takes over WireGuard interface and NAT rules of the session detached before node restart.
+func (m *Manager) Restore(sessionID string, data json.RawMessage) (service.DestroyCallback, error) {
+	var detached sessionState
+	if err := json.Unmarshal(data, &detached); err != nil {
+		return nil, fmt.Errorf("could not unmarshal wg session state: %w", err)
+	}
+	state := detached.Endpoint
+
+	select {
+	case <-m.ready:
+	case <-m.done:
+		return nil, errors.New("service is stopped")
+	}
+
+	publicIP, err := m.ipResolver.GetPublicIP()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get public IP")
+	}
+	state.Endpoint.IP = net.ParseIP(publicIP)
+
+	subnet := net.IPNet{IP: state.Config.Subnet.IP.Mask(state.Config.Subnet.Mask), Mask: state.Config.Subnet.Mask}
+	if err := m.resourcesAllocator.ReserveIPNet(subnet); err != nil {
+		return nil, fmt.Errorf("could not reserve IP network: %w", err)
+	}
+
+	conn, err := m.connEndpointFactory()
+	if err == nil {
+		err = conn.RestoreProviderMode(state)
+	}
+	if err != nil {
+		if err1 := m.resourcesAllocator.ReleaseIPNet(subnet); err1 != nil {
+			log.Error().Err(err1).Msg("Failed to release IP network")
+		}
+		return nil, fmt.Errorf("could not restore connection endpoint: %w", err)
+	}
+
+	config, err := conn.Config()
+	if err != nil {
+		conn.Stop()
+		return nil, errors.Wrap(err, "could not get peer config")
+	}
+
+	destroy, err := m.startSession(sessionID, conn, &config, subnet, nil, nil, detached.NAT)
+	if err != nil {
+		conn.Stop()
+		return nil, err
+	}
+
+	log.Info().Msgf("WireGuard interface %s of session %s restored", conn.InterfaceName(), sessionID)
+	return destroy, nil
+}
+
+// Resume hands the restored session interface over to the reconnected consumer.
+// Provider keeps its key and listen port, so the tunnel keeps running under the kept NAT rules.
+func (m *Manager) Resume(sessionID string, sessionConfig json.RawMessage, remoteConn *net.UDPConn) (service.ServiceConfiguration, error) {
+	var consumerConfig wg.ConsumerConfig
+	if err := json.Unmarshal(sessionConfig, &consumerConfig); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal wg consumer config")
+	}
+	if consumerConfig.PublicKey == "" {
+		return nil, errors.New("consumer public key is required")
+	}
+
+	m.sessionCleanupMu.Lock()
+	conn, ok := m.sessionConns[sessionID]
+	m.sessionCleanupMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no connection of session %s", sessionID)
+	}
+
+	// Consumer reaches the tunnel on its original port, the port of the new session is not needed.
+	remoteConn.Close()
+
+	if err := conn.Resume(consumerConfig.PublicKey); err != nil {
+		return nil, fmt.Errorf("could not resume connection endpoint: %w", err)
+	}
+
+	config, err := conn.Config()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get peer config")
+	}
+	if m.dnsOK {
+		config.Consumer.DNSIPs = netutil.FirstIP(config.Consumer.IPAddress).String()
+	}
+
+	log.Info().Msgf("WireGuard session %s resumed", sessionID)
+	return config, nil
+}
+
+// Rekey rotates WireGuard keys of the running session to the new consumer public key and a fresh provider key.
+func (m *Manager) Rekey(sessionID string, sessionConfig json.RawMessage) (service.ServiceConfiguration, error) {
+	var consumerConfig wg.RekeyConfig
+	if err := json.Unmarshal(sessionConfig, &consumerConfig); err != nil {
+		return nil, fmt.Errorf("could not unmarshal wg rekey config: %w", err)
+	}
+	if consumerConfig.PublicKey == "" {
+		return nil, errors.New("consumer public key is required")
+	}
+
+	m.sessionCleanupMu.Lock()
+	conn, ok := m.sessionConns[sessionID]
+	m.sessionCleanupMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no connection of session %s", sessionID)
+	}
+
+	privateKey, err := key.GeneratePrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate private key: %w", err)
+	}
+	publicKey, err := key.PrivateKeyToPublicKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not get public key from private key: %w", err)
+	}
+
+	rotatedAt := time.Now()
+	rollback, err := conn.Rekey(privateKey, consumerConfig.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not rekey connection endpoint: %w", err)
+	}
+
+	// The reply may never reach the consumer, so the rotated keys are kept only once the consumer uses them.
+	go func() {
+		if wg.ConfirmRekey(conn, rotatedAt, m.rekeyConfirmTimeout, rollback) {
+			log.Info().Msgf("WireGuard keys of session %s rotated", sessionID)
+		}
+	}()
+	return wg.RekeyConfig{PublicKey: publicKey}, nil
+}
+
+// startObfuscation relays obfuscated consumer packets received on the remote conn to the local WireGuard port.
+func (m *Manager) startObfuscation(method string, remoteConn *net.UDPConn, listenPort int) (*obfs.Proxy, *wg.ObfuscationConfig, error) {
+	key, err := obfs.GenerateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	obfuscator, err := obfs.New(method, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proxy, err := obfs.NewProxy(remoteConn, obfuscator, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: listenPort})
+	if err != nil {
+		return nil, nil, err
+	}
+	proxy.Start()
+
+	log.Info().Msgf("Obfuscating WireGuard traffic using %s", method)
+	return proxy, &wg.ObfuscationConfig{Method: method, Key: key}, nil
+}
+
+// registerCamouflage routes TLS camouflage connections of the session to the local WireGuard port.
+func (m *Manager) registerCamouflage(listenPort int) (func(), *wg.CamouflageConfig, error) {
+	token, err := camouflage.GenerateToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	release := m.camouflage.Register(token, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: listenPort})
+	return release, &wg.CamouflageConfig{
+		Port:        m.camouflage.PublicPort(),
+		ServerName:  m.camouflage.ServerName(),
+		Fingerprint: m.camouflage.Fingerprint(),
+		Token:       token,
+	}, nil
 }
 
 func (m *Manager) createProviderConfig(listenPort int, peerPublicKey string) (wgcfg.DeviceConfig, error) {
@@ -281,11 +617,43 @@ func (m *Manager) Serve(instance *service.Instance) error {
 	}
 
 	m.startStopMu.Unlock()
+	close(m.ready)
 	log.Info().Msg("Wireguard: started")
 	<-m.done
 	return nil
 }
 
+// Probe checks that connection endpoints of active sessions answer and keep handshaking with consumers.
+// Service is unhealthy if none of the connected consumers completed a handshake recently.
+func (m *Manager) Probe() error {
+	m.sessionCleanupMu.Lock()
+	conns := make(map[string]wg.ConnectionEndpoint, len(m.sessionConns))
+	for sessionID, conn := range m.sessionConns {
+		conns[sessionID] = conn
+	}
+	m.sessionCleanupMu.Unlock()
+
+	connected, stale := 0, 0
+	for sessionID, conn := range conns {
+		stats, err := conn.PeerStats()
+		if err != nil {
+			return fmt.Errorf("connection endpoint of session %s is not responding: %w", sessionID, err)
+		}
+		if stats.LastHandshake.IsZero() {
+			continue
+		}
+		connected++
+		if time.Since(stats.LastHandshake) > handshakeStaleAfter {
+			stale++
+		}
+	}
+
+	if connected > 0 && stale == connected {
+		return fmt.Errorf("no handshakes with consumers of %d sessions for %s", connected, handshakeStaleAfter)
+	}
+	return nil
+}
+
 // Stop stops service.
 func (m *Manager) Stop() error {
 	log.Info().Msg("Wireguard: stopping")
@@ -295,9 +663,9 @@ func (m *Manager) Stop() error {
 	cleanupWg := sync.WaitGroup{}
 	for k, v := range m.sessionCleanup {
 		cleanupWg.Add(1)
-		go func(sessionID string, cleanup func()) {
+		go func(sessionID string, cleanup func(stopTunnel bool)) {
 			defer cleanupWg.Done()
-			cleanup()
+			cleanup(true)
 		}(k, v)
 	}
 	cleanupWg.Wait()
@@ -18,20 +18,29 @@
 package service
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/service/egress"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/mysteriumnetwork/node/nat"
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/services/wireguard/key"
+	"github.com/mysteriumnetwork/node/services/wireguard/resources"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
 )
 
@@ -75,12 +84,189 @@ func Test_Manager_ProviderConfig_FailsWhenSessionConfigIsInvalid(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func Test_Manager_Rekey(t *testing.T) {
+	manager := newManagerStub(pubIP, outIP, country)
+	conn := &mockConnectionEndpoint{}
+	manager.sessionConns = map[string]wg.ConnectionEndpoint{"session1": conn}
+
+	res, err := manager.Rekey("session1", json.RawMessage(`{"PublicKey":"consumer-key"}`))
+	assert.NoError(t, err)
+
+	privateKey, peerPublicKey := conn.keys()
+	providerPublicKey, err := key.PrivateKeyToPublicKey(privateKey)
+	assert.NoError(t, err)
+	assert.Equal(t, wg.RekeyConfig{PublicKey: providerPublicKey}, res)
+	assert.Equal(t, "consumer-key", peerPublicKey)
+
+	_, err = manager.Rekey("unknown", json.RawMessage(`{"PublicKey":"consumer-key"}`))
+	assert.Error(t, err)
+
+	_, err = manager.Rekey("session1", json.RawMessage(`{}`))
+	assert.Error(t, err)
+}
+
+func Test_Manager_RekeyRollsBackWithoutHandshake(t *testing.T) {
+	manager := newManagerStub(pubIP, outIP, country)
+	manager.rekeyConfirmTimeout = 10 * time.Millisecond
+	conn := &mockConnectionEndpoint{
+		privateKey:    "provider-key",
+		peerPublicKey: "consumer-key",
+		stats:         &wgcfg.Stats{LastHandshake: time.Now().Add(-time.Hour)},
+	}
+	manager.sessionConns = map[string]wg.ConnectionEndpoint{"session1": conn}
+
+	_, err := manager.Rekey("session1", json.RawMessage(`{"PublicKey":"new-consumer-key"}`))
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		privateKey, peerPublicKey := conn.keys()
+		return privateKey == "provider-key" && peerPublicKey == "consumer-key"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func Test_Manager_RekeyKeepsConfirmedKeys(t *testing.T) {
+	manager := newManagerStub(pubIP, outIP, country)
+	manager.rekeyConfirmTimeout = 10 * time.Millisecond
+	conn := &mockConnectionEndpoint{
+		privateKey:    "provider-key",
+		peerPublicKey: "consumer-key",
+		stats:         &wgcfg.Stats{LastHandshake: time.Now().Add(time.Minute)},
+	}
+	manager.sessionConns = map[string]wg.ConnectionEndpoint{"session1": conn}
+
+	_, err := manager.Rekey("session1", json.RawMessage(`{"PublicKey":"new-consumer-key"}`))
+	assert.NoError(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+	_, peerPublicKey := conn.keys()
+	assert.Equal(t, "new-consumer-key", peerPublicKey)
+}
+
+func Test_Manager_Probe(t *testing.T) {
+	manager := newManagerStub(pubIP, outIP, country)
+	assert.NoError(t, manager.Probe())
+
+	fresh := &mockConnectionEndpoint{stats: &wgcfg.Stats{LastHandshake: time.Now()}}
+	stale := &mockConnectionEndpoint{stats: &wgcfg.Stats{LastHandshake: time.Now().Add(-time.Hour)}}
+	pending := &mockConnectionEndpoint{stats: &wgcfg.Stats{}}
+
+	manager.sessionConns = map[string]wg.ConnectionEndpoint{"fresh": fresh, "stale": stale, "pending": pending}
+	assert.NoError(t, manager.Probe())
+
+	manager.sessionConns = map[string]wg.ConnectionEndpoint{"stale": stale, "pending": pending}
+	assert.EqualError(t, manager.Probe(), "no handshakes with consumers of 1 sessions for 5m0s")
+
+	manager.sessionConns = map[string]wg.ConnectionEndpoint{"broken": &mockConnectionEndpoint{statsErr: errors.New("no such device")}}
+	assert.EqualError(t, manager.Probe(), "connection endpoint of session broken is not responding: no such device")
+}
+
+func Test_Manager_RestoresDetachedSession(t *testing.T) {
+	manager := newManagerStub(pubIP, outIP, country)
+	_, subnet, _ := net.ParseCIDR("10.182.0.0/16")
+	manager.resourcesAllocator = resources.NewAllocator(nil, *subnet)
+	natService := &serviceFake{}
+	manager.natService = natService
+	conn := &mockConnectionEndpoint{privateKey: "provider-key"}
+	manager.connEndpointFactory = func() (wg.ConnectionEndpoint, error) {
+		return conn, nil
+	}
+	close(manager.ready)
+
+	sessionSubnet := net.IPNet{IP: net.IPv4(10, 182, 3, 1).To4(), Mask: net.CIDRMask(24, 32)}
+	state, err := json.Marshal(sessionState{
+		Endpoint: wg.EndpointState{
+			Config:   wgcfg.DeviceConfig{IfaceName: "myst3", Subnet: sessionSubnet, ListenPort: 51000},
+			Endpoint: net.UDPAddr{IP: net.IPv4(5, 6, 7, 8), Port: 51000},
+		},
+		NAT: json.RawMessage(`["rule"]`),
+	})
+	require.NoError(t, err)
+
+	destroy, err := manager.Restore("session1", state)
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", conn.state.Endpoint.IP.String())
+	assert.Equal(t, "myst3", conn.state.Config.IfaceName)
+	assert.Error(t, manager.resourcesAllocator.ReserveIPNet(sessionSubnet), "subnet of restored session must stay allocated")
+	assert.Equal(t, []interface{}{"rule"}, natService.rules, "kept NAT rules must be adopted")
+	assert.Equal(t, 0, natService.setups)
+
+	remoteConn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+
+	res, err := manager.Resume("session1", json.RawMessage(`{"PublicKey":"consumer-key"}`), remoteConn)
+	require.NoError(t, err)
+	assert.Equal(t, "provider-key", conn.privateKey, "provider key must be kept")
+	assert.Equal(t, "consumer-key", conn.peerPublicKey)
+	assert.Equal(t, 51000, res.(wg.ServiceConfig).Provider.Endpoint.Port, "listen port must be kept")
+
+	_, err = manager.Detach("session1")
+	require.NoError(t, err)
+	destroy()
+	assert.False(t, conn.stopped, "detached interface must be left running")
+	assert.Equal(t, []interface{}{"rule"}, natService.rules, "NAT rules of detached session must be kept")
+	_, err = manager.Detach("session1")
+	assert.Error(t, err)
+
+	// Restarted node starts with empty allocator.
+	manager.resourcesAllocator = resources.NewAllocator(nil, *subnet)
+	destroy, err = manager.Restore("session1", state)
+	require.NoError(t, err)
+	destroy()
+	assert.True(t, conn.stopped)
+	assert.Empty(t, natService.rules, "NAT rules must be deleted with the session")
+	assert.NoError(t, manager.resourcesAllocator.ReserveIPNet(sessionSubnet), "subnet must be released with the session")
+}
+
+func Test_ReserveDetached(t *testing.T) {
+	sessionSubnet := net.IPNet{IP: net.IPv4(10, 182, 7, 1).To4(), Mask: net.CIDRMask(24, 32)}
+	state, err := json.Marshal(sessionState{Endpoint: wg.EndpointState{Config: wgcfg.DeviceConfig{IfaceName: "myst7", Subnet: sessionSubnet}}})
+	require.NoError(t, err)
+	require.NoError(t, ReserveDetached(state))
+
+	allocator := resources.NewAllocator(nil, net.IPNet{IP: net.IPv4(10, 182, 0, 0).To4(), Mask: net.CIDRMask(16, 32)})
+	for i := 0; i < 7; i++ {
+		require.NoError(t, allocator.ReserveInterface(fmt.Sprintf("myst%d", i)))
+	}
+	iface, err := allocator.AllocateInterface()
+	require.NoError(t, err)
+	assert.NotEqual(t, "myst7", iface, "detached interface must not be allocated to other sessions")
+	assert.NoError(t, allocator.ReserveInterface("myst7"), "detached interface must be reserved by its restored session")
+
+	assert.Error(t, ReserveDetached(json.RawMessage(`{}`)))
+}
+
+func Test_Manager_Detach_RefusesObfuscatedService(t *testing.T) {
+	manager := newManagerStub(pubIP, outIP, country)
+	manager.obfuscation = true
+	manager.sessionConns["session1"] = &mockConnectionEndpoint{}
+
+	_, err := manager.Detach("session1")
+	assert.Error(t, err)
+}
+
+func Test_Manager_Detach_RefusesNATServiceWithoutKeeper(t *testing.T) {
+	manager := newManagerStub(pubIP, outIP, country)
+	manager.natService = &nonKeepingNATService{}
+	manager.sessionConns["session1"] = &mockConnectionEndpoint{}
+
+	_, err := manager.Detach("session1")
+	assert.Error(t, err)
+}
+
 // usually time.Sleep call gives a chance for other goroutines to kick in important when testing async code
 func waitABit() {
 	time.Sleep(10 * time.Millisecond)
 }
 
-type mockConnectionEndpoint struct{}
+type mockConnectionEndpoint struct {
+	mu            sync.Mutex
+	privateKey    string
+	peerPublicKey string
+	state         wg.EndpointState
+	stopped       bool
+	stats         *wgcfg.Stats
+	statsErr      error
+}
 
 func (mce *mockConnectionEndpoint) StartConsumerMode(config wgcfg.DeviceConfig) error { return nil }
 func (mce *mockConnectionEndpoint) ReconfigureConsumerMode(config wgcfg.DeviceConfig) error {
@@ -90,32 +276,111 @@ func (mce *mockConnectionEndpoint) ReconfigureConsumerMode(config wgcfg.DeviceCo
 func (mce *mockConnectionEndpoint) StartProviderMode(ip string, config wgcfg.DeviceConfig) error {
 	return nil
 }
-func (mce *mockConnectionEndpoint) InterfaceName() string                { return "mce0" }
-func (mce *mockConnectionEndpoint) Stop() error                          { return nil }
-func (mce *mockConnectionEndpoint) Config() (wg.ServiceConfig, error)    { return wg.ServiceConfig{}, nil }
+func (mce *mockConnectionEndpoint) Rekey(privateKey, peerPublicKey string) (func() error, error) {
+	mce.mu.Lock()
+	defer mce.mu.Unlock()
+
+	previousKey, previousPeerKey := mce.privateKey, mce.peerPublicKey
+	mce.privateKey, mce.peerPublicKey = privateKey, peerPublicKey
+	return func() error {
+		mce.mu.Lock()
+		defer mce.mu.Unlock()
+
+		mce.privateKey, mce.peerPublicKey = previousKey, previousPeerKey
+		return nil
+	}, nil
+}
+func (mce *mockConnectionEndpoint) Resume(peerPublicKey string) error {
+	mce.mu.Lock()
+	defer mce.mu.Unlock()
+
+	mce.peerPublicKey = peerPublicKey
+	return nil
+}
+func (mce *mockConnectionEndpoint) keys() (privateKey, peerPublicKey string) {
+	mce.mu.Lock()
+	defer mce.mu.Unlock()
+
+	return mce.privateKey, mce.peerPublicKey
+}
+func (mce *mockConnectionEndpoint) Detach() (wg.EndpointState, error) { return mce.state, nil }
+func (mce *mockConnectionEndpoint) RestoreProviderMode(state wg.EndpointState) error {
+	mce.state = state
+	return nil
+}
+func (mce *mockConnectionEndpoint) InterfaceName() string { return "mce0" }
+func (mce *mockConnectionEndpoint) Stop() error {
+	mce.stopped = true
+	return nil
+}
+func (mce *mockConnectionEndpoint) Config() (wg.ServiceConfig, error) {
+	var config wg.ServiceConfig
+	config.Provider.Endpoint = mce.state.Endpoint
+	config.Provider.Endpoint.Port = mce.state.Config.ListenPort
+	config.Consumer.IPAddress = mce.state.Config.Subnet
+	return config, nil
+}
 func (mce *mockConnectionEndpoint) AddPeer(_ string, _ wgcfg.Peer) error { return nil }
 func (mce *mockConnectionEndpoint) RemovePeer(_ string) error            { return nil }
 func (mce *mockConnectionEndpoint) ConfigureRoutes(_ net.IP) error       { return nil }
 func (mce *mockConnectionEndpoint) PeerStats() (wgcfg.Stats, error) {
+	if mce.statsErr != nil {
+		return wgcfg.Stats{}, mce.statsErr
+	}
+	if mce.stats != nil {
+		return *mce.stats, nil
+	}
 	return wgcfg.Stats{LastHandshake: time.Now()}, nil
 }
 
 func newManagerStub(pub, out, country string) *Manager {
 	return &Manager{
-		done:       make(chan struct{}),
-		ipResolver: ip.NewResolverMock("1.2.3.4"),
-		natService: &serviceFake{},
+		done:           make(chan struct{}),
+		ready:          make(chan struct{}),
+		ipResolver:     ip.NewResolverMock("1.2.3.4"),
+		natService:     &serviceFake{},
+		eventBus:       mocks.NewEventBus(),
+		egressSampler:  egress.NewSampler(mocks.NewEventBus()),
+		sessionCleanup: map[string]func(stopTunnel bool){},
+		sessionConns:   map[string]wg.ConnectionEndpoint{},
+		sessionNAT:     map[string][]interface{}{},
 		connEndpointFactory: func() (wg.ConnectionEndpoint, error) {
 			return connectionEndpointStub, nil
 		},
+		rekeyConfirmTimeout: wg.RekeyConfirmTimeout,
 	}
 }
 
-type serviceFake struct{}
+type serviceFake struct {
+	setups int
+	rules  []interface{}
+}
 
 func (service *serviceFake) Setup(nat.Options) (rules []interface{}, err error) {
+	service.setups++
+	return nil, nil
+}
+func (service *serviceFake) Del([]interface{}) error {
+	service.rules = nil
+	return nil
+}
+func (service *serviceFake) Enable() error  { return nil }
+func (service *serviceFake) Disable() error { return nil }
+func (service *serviceFake) Keep(rules []interface{}) (json.RawMessage, error) {
+	return json.Marshal(rules)
+}
+func (service *serviceFake) Adopt(state json.RawMessage) ([]interface{}, error) {
+	if err := json.Unmarshal(state, &service.rules); err != nil {
+		return nil, err
+	}
+	return service.rules, nil
+}
+
+type nonKeepingNATService struct{}
+
+func (service *nonKeepingNATService) Setup(nat.Options) (rules []interface{}, err error) {
 	return nil, nil
 }
-func (service *serviceFake) Del([]interface{}) error { return nil }
-func (service *serviceFake) Enable() error           { return nil }
-func (service *serviceFake) Disable() error          { return nil }
+func (service *nonKeepingNATService) Del([]interface{}) error { return nil }
+func (service *nonKeepingNATService) Enable() error           { return nil }
+func (service *nonKeepingNATService) Disable() error          { return nil }
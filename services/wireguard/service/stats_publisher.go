@@ -32,30 +32,65 @@ type statsSupplier interface {
 	PeerStats() (wgcfg.Stats, error)
 }
 
+// statsSample is a single reading of peer counters.
+type statsSample struct {
+	at            time.Time
+	bytesSent     uint64
+	bytesReceived uint64
+}
+
+// statsPublisher samples peer counters at high resolution and publishes them to the bus at a lower frequency.
+// Samples are kept in place, so fresh traffic rate is available without extra allocations or goroutines.
 type statsPublisher struct {
-	done      chan struct{}
-	bus       eventbus.Publisher
-	frequency time.Duration
-	once      sync.Once
+	done       chan struct{}
+	bus        eventbus.Publisher
+	frequency  time.Duration
+	resolution time.Duration
+	once       sync.Once
+
+	mu       sync.RWMutex
+	last     statsSample
+	prev     statsSample
+	activeAt time.Time
 }
 
-func newStatsPublisher(bus eventbus.Publisher, frequency time.Duration) statsPublisher {
-	return statsPublisher{
-		done:      make(chan struct{}),
-		bus:       bus,
-		frequency: frequency,
+func newStatsPublisher(bus eventbus.Publisher, frequency, resolution time.Duration) *statsPublisher {
+	if resolution <= 0 || resolution > frequency {
+		resolution = frequency
+	}
+
+	return &statsPublisher{
+		done:       make(chan struct{}),
+		bus:        bus,
+		frequency:  frequency,
+		resolution: resolution,
 	}
 }
 
 func (s *statsPublisher) start(sessionID string, supplier statsSupplier) {
+	ticker := time.NewTicker(s.resolution)
+	defer ticker.Stop()
+
+	var publishedAt time.Time
 	for {
 		select {
-		case <-time.After(s.frequency):
+		case now := <-ticker.C:
+			due := now.Sub(publishedAt) >= s.frequency
 			stats, err := supplier.PeerStats()
 			if err != nil {
-				log.Warn().Err(err).Msg("Could not get peer statistics")
+				if due {
+					log.Warn().Err(err).Msg("Could not get peer statistics")
+					publishedAt = now
+				}
 				continue
 			}
+
+			s.record(now, stats)
+			if !due {
+				continue
+			}
+
+			publishedAt = now
 			s.bus.Publish(event.AppTopicDataTransferred, event.AppEventDataTransferred{
 				ID:   sessionID,
 				Up:   stats.BytesSent,
@@ -68,6 +103,49 @@ func (s *statsPublisher) start(sessionID string, supplier statsSupplier) {
 	}
 }
 
+func (s *statsPublisher) record(at time.Time, stats wgcfg.Stats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prev = s.last
+	s.last.at = at
+	s.last.bytesSent = stats.BytesSent
+	s.last.bytesReceived = stats.BytesReceived
+	if s.last.bytesSent != s.prev.bytesSent || s.last.bytesReceived != s.prev.bytesReceived {
+		s.activeAt = at
+	}
+}
+
+// rate returns bytes per second sent and received between the two latest samples.
+func (s *statsPublisher) rate() (sent, received uint64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	elapsed := s.last.at.Sub(s.prev.at)
+	if s.prev.at.IsZero() || elapsed <= 0 {
+		return 0, 0
+	}
+
+	perSecond := func(current, previous uint64) uint64 {
+		if current < previous {
+			return 0
+		}
+		return uint64(float64(current-previous) / elapsed.Seconds())
+	}
+	return perSecond(s.last.bytesSent, s.prev.bytesSent), perSecond(s.last.bytesReceived, s.prev.bytesReceived)
+}
+
+// idle returns how long peer counters were not changing.
+func (s *statsPublisher) idle() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.activeAt.IsZero() {
+		return 0
+	}
+	return s.last.at.Sub(s.activeAt)
+}
+
 func (s *statsPublisher) stop() {
 	s.once.Do(func() {
 		close(s.done)
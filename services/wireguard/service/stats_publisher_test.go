@@ -40,7 +40,7 @@ func (f fakeSupplier) PeerStats() (wgcfg.Stats, error) {
 
 func Test_statsPublisher_start(t *testing.T) {
 	bus := mocks.NewEventBus()
-	publisher := newStatsPublisher(bus, time.Microsecond)
+	publisher := newStatsPublisher(bus, time.Microsecond, 0)
 
 	go publisher.start("kappa", &fakeSupplier{})
 
@@ -65,3 +65,49 @@ func Test_statsPublisher_start(t *testing.T) {
 		return bus.Pop() != nil
 	}, time.Millisecond, time.Microsecond)
 }
+
+type countingSupplier struct {
+	sent, received uint64
+}
+
+func (c *countingSupplier) PeerStats() (wgcfg.Stats, error) {
+	return wgcfg.Stats{BytesSent: c.sent, BytesReceived: c.received}, nil
+}
+
+func Test_statsPublisher_SamplesFasterThanPublishes(t *testing.T) {
+	bus := mocks.NewEventBus()
+	publisher := newStatsPublisher(bus, time.Hour, time.Millisecond)
+	assert.Equal(t, time.Millisecond, publisher.resolution)
+
+	go publisher.start("kappa", &countingSupplier{sent: 10, received: 20})
+	defer publisher.stop()
+
+	assert.Eventually(t, func() bool {
+		publisher.mu.RLock()
+		defer publisher.mu.RUnlock()
+		return !publisher.prev.at.IsZero()
+	}, 2*time.Second, time.Millisecond)
+
+	// Only the first sample is published, the following ones wait for the publishing frequency.
+	assert.NotNil(t, bus.Pop())
+	assert.Nil(t, bus.Pop())
+}
+
+func Test_statsPublisher_RateAndIdle(t *testing.T) {
+	publisher := newStatsPublisher(mocks.NewEventBus(), time.Second, 100*time.Millisecond)
+	start := time.Now()
+
+	publisher.record(start, wgcfg.Stats{BytesSent: 1000, BytesReceived: 2000})
+	publisher.record(start.Add(500*time.Millisecond), wgcfg.Stats{BytesSent: 1500, BytesReceived: 3000})
+	sent, received := publisher.rate()
+	assert.EqualValues(t, 1000, sent)
+	assert.EqualValues(t, 2000, received)
+	assert.Equal(t, time.Duration(0), publisher.idle())
+
+	publisher.record(start.Add(time.Second), wgcfg.Stats{BytesSent: 1500, BytesReceived: 3000})
+	publisher.record(start.Add(2*time.Second), wgcfg.Stats{BytesSent: 1500, BytesReceived: 3000})
+	sent, received = publisher.rate()
+	assert.Zero(t, sent)
+	assert.Zero(t, received)
+	assert.Equal(t, 1500*time.Millisecond, publisher.idle())
+}
@@ -40,6 +40,24 @@ type ServiceConfig struct {
 		IPAddress net.IPNet
 		DNSIPs    string
 	}
+	// Obfuscation is set when provider agreed to obfuscate WireGuard traffic.
+	Obfuscation *ObfuscationConfig
+	// Camouflage is set when provider accepts WireGuard traffic tunneled over TLS.
+	Camouflage *CamouflageConfig
+}
+
+// ObfuscationConfig describes obfuscation layer wrapping WireGuard packets.
+type ObfuscationConfig struct {
+	Method string `json:"method"`
+	Key    []byte `json:"key"`
+}
+
+// CamouflageConfig describes TLS camouflage server carrying WireGuard packets of the session.
+type CamouflageConfig struct {
+	Port        int    `json:"port"`
+	ServerName  string `json:"server_name,omitempty"`
+	Fingerprint []byte `json:"fingerprint"`
+	Token       []byte `json:"token"`
 }
 
 // ConsumerConfig is used for sending the public key and IP from consumer to provider.
@@ -48,6 +66,15 @@ type ConsumerConfig struct {
 	// IP is needed when provider is behind NAT. In such case provider parses this IP and tries to ping consumer.
 	IP    string `json:"IP,omitempty"`
 	Ports []int  `json:"Ports"`
+	// Obfuscation lists obfuscation methods consumer is able to use, in order of preference.
+	Obfuscation []string `json:"Obfuscation,omitempty"`
+	// Camouflage requests WireGuard traffic to be tunneled over TLS.
+	Camouflage bool `json:"Camouflage,omitempty"`
+}
+
+// RekeyConfig is exchanged by consumer and provider to rotate keys of the running session.
+type RekeyConfig struct {
+	PublicKey string `json:"PublicKey"`
 }
 
 // MarshalJSON implements json.Marshaler interface to provide human readable configuration.
@@ -62,11 +89,13 @@ func (s ServiceConfig) MarshalJSON() ([]byte, error) {
 	}
 
 	return json.Marshal(&struct {
-		LocalPort  int      `json:"local_port"`
-		RemotePort int      `json:"remote_port"`
-		Ports      []int    `json:"ports"`
-		Provider   provider `json:"provider"`
-		Consumer   consumer `json:"consumer"`
+		LocalPort   int                `json:"local_port"`
+		RemotePort  int                `json:"remote_port"`
+		Ports       []int              `json:"ports"`
+		Provider    provider           `json:"provider"`
+		Consumer    consumer           `json:"consumer"`
+		Obfuscation *ObfuscationConfig `json:"obfuscation,omitempty"`
+		Camouflage  *CamouflageConfig  `json:"camouflage,omitempty"`
 	}{
 		Ports:      s.Ports,
 		LocalPort:  s.LocalPort,
@@ -79,6 +108,8 @@ func (s ServiceConfig) MarshalJSON() ([]byte, error) {
 			IPAddress: s.Consumer.IPAddress.String(),
 			DNSIPs:    s.Consumer.DNSIPs,
 		},
+		Obfuscation: s.Obfuscation,
+		Camouflage:  s.Camouflage,
 	})
 }
 
@@ -93,11 +124,13 @@ func (s *ServiceConfig) UnmarshalJSON(data []byte) error {
 		DNSIPs    string `json:"dns_ips"`
 	}
 	var config struct {
-		LocalPort  int      `json:"local_port"`
-		RemotePort int      `json:"remote_port"`
-		Ports      []int    `json:"ports"`
-		Provider   provider `json:"provider"`
-		Consumer   consumer `json:"consumer"`
+		LocalPort   int                `json:"local_port"`
+		RemotePort  int                `json:"remote_port"`
+		Ports       []int              `json:"ports"`
+		Provider    provider           `json:"provider"`
+		Consumer    consumer           `json:"consumer"`
+		Obfuscation *ObfuscationConfig `json:"obfuscation,omitempty"`
+		Camouflage  *CamouflageConfig  `json:"camouflage,omitempty"`
 	}
 
 	if err := json.Unmarshal(data, &config); err != nil {
@@ -121,6 +154,8 @@ func (s *ServiceConfig) UnmarshalJSON(data []byte) error {
 	s.Consumer.DNSIPs = config.Consumer.DNSIPs
 	s.Consumer.IPAddress = *ipnet
 	s.Consumer.IPAddress.IP = ip
+	s.Obfuscation = config.Obfuscation
+	s.Camouflage = config.Camouflage
 
 	return nil
 }
@@ -89,3 +89,16 @@ func TestServiceConfig_UnmarshalJSON(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expecteConfig, actualConfig)
 }
+
+func TestServiceConfig_ObfuscationRoundTrip(t *testing.T) {
+	configJSON := json.RawMessage(`{"provider":{"public_key":"wg1","endpoint":"127.0.0.1:51001"},"consumer":{"ip_address":"127.0.0.1/25","dns_ips":"128.0.0.1"},"obfuscation":{"method":"xchacha","key":"AQID"}}`)
+
+	var config ServiceConfig
+	err := json.Unmarshal(configJSON, &config)
+	assert.NoError(t, err)
+	assert.Equal(t, &ObfuscationConfig{Method: "xchacha", Key: []byte{1, 2, 3}}, config.Obfuscation)
+
+	configBytes, err := json.Marshal(config)
+	assert.NoError(t, err)
+	assert.Contains(t, string(configBytes), `"obfuscation":{"method":"xchacha","key":"AQID"}`)
+}
@@ -0,0 +1,311 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package wgbind provides WireGuard bind for userspace devices which reads and writes
+// packets in batches with pooled buffers instead of one system call per packet.
+package wgbind
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"sync"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+	"golang.zx2c4.com/wireguard/conn"
+
+	"github.com/mysteriumnetwork/node/utils/udpbatch"
+)
+
+// sendQueueSize is the number of packets waiting to be written before Send blocks.
+const sendQueueSize = 4 * udpbatch.Size
+
+// Bind is WireGuard bind batching reads with recvmmsg and writes with sendmmsg where supported.
+type Bind struct {
+	mu   sync.Mutex
+	ipv4 *socket
+	ipv6 *socket
+}
+
+var _ conn.Bind = (*Bind)(nil)
+
+// NewBind creates batching WireGuard bind.
+func NewBind() *Bind {
+	return &Bind{}
+}
+
+// ParseEndpoint creates a new endpoint from a string.
+func (*Bind) ParseEndpoint(s string) (conn.Endpoint, error) {
+	e, err := netip.ParseAddrPort(s)
+	return (*conn.StdNetEndpoint)(&e), err
+}
+
+// Open starts listening for IPv4 and IPv6 packets on the given port, zero selects a random one.
+func (b *Bind) Open(port uint16) ([]conn.ReceiveFunc, uint16, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.ipv4 != nil || b.ipv6 != nil {
+		return nil, 0, conn.ErrBindAlreadyOpen
+	}
+
+	var tries int
+again:
+	actualPort := int(port)
+	ipv4, actualPort, err := listen("udp4", actualPort)
+	if err != nil && !errors.Is(err, syscall.EAFNOSUPPORT) {
+		return nil, 0, err
+	}
+
+	// Listen on the same port as we're using for ipv4.
+	ipv6, actualPort, err := listen("udp6", actualPort)
+	if port == 0 && errors.Is(err, syscall.EADDRINUSE) && tries < 100 {
+		ipv4.close()
+		tries++
+		goto again
+	}
+	if err != nil && !errors.Is(err, syscall.EAFNOSUPPORT) {
+		ipv4.close()
+		return nil, 0, err
+	}
+
+	var fns []conn.ReceiveFunc
+	if ipv4 != nil {
+		fns = append(fns, ipv4.receive)
+		b.ipv4 = ipv4
+	}
+	if ipv6 != nil {
+		fns = append(fns, ipv6.receive)
+		b.ipv6 = ipv6
+	}
+	if len(fns) == 0 {
+		return nil, 0, syscall.EAFNOSUPPORT
+	}
+	return fns, uint16(actualPort), nil
+}
+
+// Close closes both sockets, receive functions return net.ErrClosed afterwards.
+func (b *Bind) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var err1, err2 error
+	if b.ipv4 != nil {
+		err1 = b.ipv4.close()
+		b.ipv4 = nil
+	}
+	if b.ipv6 != nil {
+		err2 = b.ipv6.close()
+		b.ipv6 = nil
+	}
+	if err1 != nil {
+		return err1
+	}
+	return err2
+}
+
+// SetMark sets the mark for each packet sent through this bind.
+func (b *Bind) SetMark(mark uint32) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, s := range []*socket{b.ipv4, b.ipv6} {
+		if s == nil {
+			continue
+		}
+		if err := setMark(s.conn, mark); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Send queues packet for batched writing to the endpoint.
+func (b *Bind) Send(buf []byte, endpoint conn.Endpoint) error {
+	ep, ok := endpoint.(*conn.StdNetEndpoint)
+	if !ok {
+		return conn.ErrWrongEndpointType
+	}
+	addrPort := (*netip.AddrPort)(ep)
+
+	b.mu.Lock()
+	s := b.ipv4
+	if addrPort.Addr().Is6() {
+		s = b.ipv6
+	}
+	b.mu.Unlock()
+
+	if s == nil {
+		return syscall.EAFNOSUPPORT
+	}
+	return s.send(buf, net.UDPAddrFromAddrPort(*addrPort))
+}
+
+type outgoing struct {
+	buf  *[]byte
+	addr *net.UDPAddr
+}
+
+// socket is a UDP socket with its batched read state and write queue.
+type socket struct {
+	conn  *net.UDPConn
+	batch udpbatch.Conn
+
+	// Read state is used only by the receive function, which WireGuard calls from a single goroutine.
+	in         *udpbatch.Batch
+	read, next int
+
+	queue   chan outgoing
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+
+	errMu   sync.Mutex
+	sendErr error
+}
+
+func listen(network string, port int) (*socket, int, error) {
+	udpConn, err := net.ListenUDP(network, &net.UDPAddr{Port: port})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s := &socket{
+		conn:    udpConn,
+		batch:   udpbatch.NewConn(udpConn),
+		in:      udpbatch.New(),
+		queue:   make(chan outgoing, sendQueueSize),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go s.writeLoop()
+
+	return s, udpConn.LocalAddr().(*net.UDPAddr).Port, nil
+}
+
+// receive hands out packets one by one from the last batch, reading the next batch when it is exhausted.
+func (s *socket) receive(buf []byte) (int, conn.Endpoint, error) {
+	for s.next >= s.read {
+		n, err := s.in.Read(s.batch)
+		if err != nil {
+			return 0, nil, err
+		}
+		s.read, s.next = n, 0
+	}
+
+	i := s.next
+	s.next++
+
+	n := copy(buf, s.in.Packet(i))
+	var addrPort netip.AddrPort
+	if addr := s.in.Addr(i); addr != nil {
+		addrPort = addr.AddrPort()
+		addrPort = netip.AddrPortFrom(addrPort.Addr().Unmap(), addrPort.Port())
+	}
+	return n, (*conn.StdNetEndpoint)(&addrPort), nil
+}
+
+// send copies packet to a pooled buffer and queues it, returning the error of a previous failed write if any.
+func (s *socket) send(packet []byte, addr *net.UDPAddr) error {
+	if len(packet) > udpbatch.BufferSize {
+		return syscall.EMSGSIZE
+	}
+
+	s.errMu.Lock()
+	err := s.sendErr
+	s.sendErr = nil
+	s.errMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	buf := udpbatch.GetBuffer()
+	*buf = append((*buf)[:0], packet...)
+
+	select {
+	case s.queue <- outgoing{buf: buf, addr: addr}:
+		return nil
+	case <-s.done:
+		udpbatch.PutBuffer(buf)
+		return net.ErrClosed
+	}
+}
+
+// writeLoop writes queued packets, taking as many as are already waiting into a single batch.
+func (s *socket) writeLoop() {
+	defer close(s.stopped)
+
+	out := udpbatch.NewMessages()
+	bufs := make([]*[]byte, 0, udpbatch.Size)
+	for {
+		select {
+		case packet := <-s.queue:
+			bufs = append(bufs, packet.buf)
+			out.Set(0, *packet.buf, packet.addr)
+		case <-s.done:
+			return
+		}
+
+	drain:
+		for len(bufs) < udpbatch.Size {
+			select {
+			case packet := <-s.queue:
+				out.Set(len(bufs), *packet.buf, packet.addr)
+				bufs = append(bufs, packet.buf)
+			default:
+				break drain
+			}
+		}
+
+		if err := out.Write(s.batch, len(bufs)); err != nil && !errors.Is(err, net.ErrClosed) {
+			log.Debug().Err(err).Msg("Failed to write WireGuard packets")
+			s.errMu.Lock()
+			s.sendErr = err
+			s.errMu.Unlock()
+		}
+
+		for i, buf := range bufs {
+			udpbatch.PutBuffer(buf)
+			bufs[i] = nil
+		}
+		bufs = bufs[:0]
+	}
+}
+
+func (s *socket) close() error {
+	if s == nil {
+		return nil
+	}
+
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		err = s.conn.Close()
+		<-s.stopped
+
+		for {
+			select {
+			case packet := <-s.queue:
+				udpbatch.PutBuffer(packet.buf)
+			default:
+				return
+			}
+		}
+	})
+	return err
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package wgbind
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.zx2c4.com/wireguard/conn"
+
+	"github.com/mysteriumnetwork/node/utils/udpbatch"
+)
+
+func TestBind_SendsAndReceivesBatches(t *testing.T) {
+	receiver := NewBind()
+	fns, port, err := receiver.Open(0)
+	require.NoError(t, err)
+	defer receiver.Close()
+
+	sender := NewBind()
+	_, _, err = sender.Open(0)
+	require.NoError(t, err)
+	defer sender.Close()
+
+	ep, err := sender.ParseEndpoint("127.0.0.1:" + strconv.Itoa(int(port)))
+	require.NoError(t, err)
+
+	count := 3 * udpbatch.Size
+	for i := 0; i < count; i++ {
+		require.NoError(t, sender.Send([]byte(strconv.Itoa(i)), ep))
+	}
+
+	received := make(chan string, count)
+	go func() {
+		buf := make([]byte, udpbatch.BufferSize)
+		for {
+			n, src, err := fns[0](buf)
+			if err != nil {
+				return
+			}
+			if src.DstIP().Is4() {
+				received <- string(buf[:n])
+			}
+		}
+	}()
+
+	for i := 0; i < count; i++ {
+		select {
+		case packet := <-received:
+			assert.Equal(t, strconv.Itoa(i), packet)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("packet %d was not received", i)
+		}
+	}
+}
+
+func TestBind_CloseStopsReceiving(t *testing.T) {
+	bind := NewBind()
+	fns, _, err := bind.Open(0)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := fns[0](make([]byte, udpbatch.BufferSize))
+		done <- err
+	}()
+
+	require.NoError(t, bind.Close())
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("receive did not return after close")
+	}
+
+	ep, err := bind.ParseEndpoint("127.0.0.1:1")
+	require.NoError(t, err)
+	assert.Error(t, bind.Send([]byte("late"), ep))
+}
+
+func TestBind_RejectsForeignEndpoint(t *testing.T) {
+	bind := NewBind()
+	_, _, err := bind.Open(0)
+	require.NoError(t, err)
+	defer bind.Close()
+
+	assert.Equal(t, conn.ErrWrongEndpointType, bind.Send([]byte("x"), nil))
+}
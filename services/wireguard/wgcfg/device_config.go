@@ -51,6 +51,8 @@ type DeviceConfig struct {
 	ReplacePeers bool `json:"replace_peers,omitempty"`
 
 	ProxyPort int `json:"proxy_port,omitempty"`
+	// MTU of the tunnel interface, zero keeps the default.
+	MTU int `json:"mtu,omitempty"`
 }
 
 // MarshalJSON implements json.Marshaler interface to provide human readable configuration.
@@ -72,6 +74,7 @@ func (dc DeviceConfig) MarshalJSON() ([]byte, error) {
 		Peer         peer     `json:"peer"`
 		ReplacePeers bool     `json:"replace_peers,omitempty"`
 		ProxyPort    int      `json:"proxy_port,omitempty"`
+		MTU          int      `json:"mtu,omitempty"`
 	}
 
 	var peerEndpoint string
@@ -94,6 +97,7 @@ func (dc DeviceConfig) MarshalJSON() ([]byte, error) {
 		},
 		ReplacePeers: dc.ReplacePeers,
 		ProxyPort:    dc.ProxyPort,
+		MTU:          dc.MTU,
 	})
 }
 
@@ -116,6 +120,7 @@ func (dc *DeviceConfig) UnmarshalJSON(data []byte) error {
 		Peer         peer     `json:"peer"`
 		ReplacePeers bool     `json:"replace_peers,omitempty"`
 		ProxyPort    int      `json:"proxy_port"`
+		MTU          int      `json:"mtu,omitempty"`
 	}
 
 	cfg := deviceConfig{}
@@ -152,6 +157,7 @@ func (dc *DeviceConfig) UnmarshalJSON(data []byte) error {
 	}
 	dc.ReplacePeers = cfg.ReplacePeers
 	dc.ProxyPort = cfg.ProxyPort
+	dc.MTU = cfg.MTU
 
 	return nil
 }
@@ -0,0 +1,154 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dispute
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/crypto"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session"
+)
+
+// ErrInvalidSignature is returned when dispute bundle is not signed by its provider.
+var ErrInvalidSignature = errors.New("invalid bundle signature")
+
+// Status represents the state of a payment dispute.
+type Status string
+
+const (
+	// StatusOpen indicates the dispute holds promise settlement.
+	StatusOpen Status = "open"
+	// StatusResolved indicates the dispute was closed by provider.
+	StatusResolved Status = "resolved"
+)
+
+// Dispute is a provider claim that the consumer underpaid a session.
+type Dispute struct {
+	SessionID  session.ID        `json:"session_id" storm:"id"`
+	ProviderID identity.Identity `json:"provider_id"`
+	ConsumerID identity.Identity `json:"consumer_id"`
+	HermesID   common.Address    `json:"hermes_id"`
+	Reason     string            `json:"reason,omitempty"`
+	Status     Status            `json:"status"`
+	CreatedAt  time.Time         `json:"created_at"`
+	ResolvedAt time.Time         `json:"resolved_at,omitempty"`
+}
+
+// Trail is the payment audit trail of a provided session.
+type Trail struct {
+	SessionID        session.ID               `json:"session_id" storm:"id"`
+	ProviderID       identity.Identity        `json:"provider_id"`
+	ConsumerID       identity.Identity        `json:"consumer_id"`
+	HermesID         common.Address           `json:"hermes_id"`
+	ServiceType      string                   `json:"service_type"`
+	StartedAt        time.Time                `json:"started_at"`
+	EndedAt          time.Time                `json:"ended_at,omitempty"`
+	Invoices         []crypto.Invoice         `json:"invoices"`
+	ExchangeMessages []crypto.ExchangeMessage `json:"exchange_messages"`
+}
+
+// Invoiced returns the session total asked for by the last invoice.
+func (t Trail) Invoiced() *big.Int {
+	total := new(big.Int)
+	for _, i := range t.Invoices {
+		if i.AgreementTotal != nil && i.AgreementTotal.Cmp(total) > 0 {
+			total.Set(i.AgreementTotal)
+		}
+	}
+	return total
+}
+
+// Paid returns the session total promised by the last exchange message.
+func (t Trail) Paid() *big.Int {
+	total := new(big.Int)
+	for _, em := range t.ExchangeMessages {
+		if em.AgreementTotal != nil && em.AgreementTotal.Cmp(total) > 0 {
+			total.Set(em.AgreementTotal)
+		}
+	}
+	return total
+}
+
+// Bundle is an exportable dispute evidence signed by the provider.
+type Bundle struct {
+	Dispute   Dispute   `json:"dispute"`
+	Trail     Trail     `json:"trail"`
+	Invoiced  *big.Int  `json:"invoiced"`
+	Paid      *big.Int  `json:"paid"`
+	Unpaid    *big.Int  `json:"unpaid"`
+	CreatedAt time.Time `json:"created_at"`
+	Signature string    `json:"signature,omitempty"`
+}
+
+// NewBundle returns an unsigned dispute bundle of the given session trail.
+func NewBundle(d Dispute, t Trail, createdAt time.Time) Bundle {
+	invoiced, paid := t.Invoiced(), t.Paid()
+	unpaid := new(big.Int).Sub(invoiced, paid)
+	if unpaid.Sign() < 0 {
+		unpaid.SetInt64(0)
+	}
+
+	return Bundle{
+		Dispute:   d,
+		Trail:     t,
+		Invoiced:  invoiced,
+		Paid:      paid,
+		Unpaid:    unpaid,
+		CreatedAt: createdAt.UTC(),
+	}
+}
+
+// Message returns the canonical representation of bundle fields covered by signature.
+func (b Bundle) Message() ([]byte, error) {
+	b.Signature = ""
+	return json.Marshal(b)
+}
+
+// Sign signs the bundle on behalf of its provider.
+func (b *Bundle) Sign(signer identity.Signer) error {
+	msg, err := b.Message()
+	if err != nil {
+		return fmt.Errorf("could not encode bundle: %w", err)
+	}
+	signature, err := signer.Sign(msg)
+	if err != nil {
+		return fmt.Errorf("could not sign bundle: %w", err)
+	}
+	b.Signature = hex.EncodeToString(signature.Bytes())
+	return nil
+}
+
+// Verify checks that the bundle is signed by its provider.
+func (b Bundle) Verify() error {
+	msg, err := b.Message()
+	if err != nil {
+		return fmt.Errorf("could not encode bundle: %w", err)
+	}
+	if ok, _ := identity.NewVerifierIdentity(b.Dispute.ProviderID).Verify(msg, identity.SignatureHex(b.Signature)); !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
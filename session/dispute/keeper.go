@@ -0,0 +1,267 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dispute
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session"
+	session_event "github.com/mysteriumnetwork/node/session/event"
+	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+var (
+	// ErrUnknownSession is returned when there is no payment audit trail of the session.
+	ErrUnknownSession = errors.New("unknown session")
+	// ErrAlreadyDisputed is returned when the session already has an open dispute.
+	ErrAlreadyDisputed = errors.New("session is already disputed")
+	// ErrNotOpen is returned when resolving a dispute which is not open.
+	ErrNotOpen = errors.New("dispute is not open")
+)
+
+// Keeper records payment audit trails of provided sessions, flags disputed sessions
+// and holds promise settlement of the affected hermes channels until disputes are resolved.
+// Trails are stored as they grow, so sessions active before a restart can still be disputed.
+type Keeper struct {
+	storage       *Storage
+	signerFactory identity.SignerFactory
+	now           func() time.Time
+
+	mu     sync.Mutex
+	active map[session.ID]*Trail
+}
+
+// NewKeeper returns a new instance of the dispute Keeper.
+func NewKeeper(storage *Storage, signerFactory identity.SignerFactory) *Keeper {
+	return &Keeper{
+		storage:       storage,
+		signerFactory: signerFactory,
+		now:           time.Now,
+		active:        make(map[session.ID]*Trail),
+	}
+}
+
+// Subscribe subscribes to provided session and payment events.
+func (k *Keeper) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.Subscribe(session_event.AppTopicSession, k.consumeSessionEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(pingpong_event.AppTopicInvoiceSent, k.consumeInvoiceSentEvent); err != nil {
+		return err
+	}
+	return bus.Subscribe(pingpong_event.AppTopicExchangeMessageReceived, k.consumeExchangeMessageEvent)
+}
+
+// Flag marks the session as underpaid, which holds settlement of its hermes channel.
+func (k *Keeper) Flag(sessionID session.ID, reason string) (Dispute, error) {
+	d, err := k.storage.Dispute(sessionID)
+	if err == nil && d.Status == StatusOpen {
+		return Dispute{}, ErrAlreadyDisputed
+	}
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return Dispute{}, err
+	}
+
+	trail, err := k.trail(sessionID)
+	if err != nil {
+		return Dispute{}, err
+	}
+
+	d = Dispute{
+		SessionID:  sessionID,
+		ProviderID: trail.ProviderID,
+		ConsumerID: trail.ConsumerID,
+		HermesID:   trail.HermesID,
+		Reason:     reason,
+		Status:     StatusOpen,
+		CreatedAt:  k.now().UTC(),
+	}
+	if err := k.storage.StoreDispute(d); err != nil {
+		return Dispute{}, fmt.Errorf("could not store dispute: %w", err)
+	}
+
+	log.Warn().Msgf("Session %s flagged as disputed, holding settlement of hermes %s for provider %s", sessionID, d.HermesID.Hex(), d.ProviderID.Address)
+	return d, nil
+}
+
+// Resolve closes the open dispute of the session and lifts its settlement hold.
+func (k *Keeper) Resolve(sessionID session.ID) (Dispute, error) {
+	d, err := k.storage.Dispute(sessionID)
+	if err != nil {
+		return Dispute{}, err
+	}
+	if d.Status != StatusOpen {
+		return Dispute{}, ErrNotOpen
+	}
+
+	d.Status = StatusResolved
+	d.ResolvedAt = k.now().UTC()
+	if err := k.storage.StoreDispute(d); err != nil {
+		return Dispute{}, fmt.Errorf("could not store dispute: %w", err)
+	}
+	return d, nil
+}
+
+// Get returns the dispute of the session.
+func (k *Keeper) Get(sessionID session.ID) (Dispute, error) {
+	return k.storage.Dispute(sessionID)
+}
+
+// List returns all disputes, the newest first.
+func (k *Keeper) List() ([]Dispute, error) {
+	return k.storage.Disputes()
+}
+
+// Bundle collects the audit trail of the disputed session into an exportable bundle signed by provider.
+func (k *Keeper) Bundle(sessionID session.ID) (Bundle, error) {
+	d, err := k.storage.Dispute(sessionID)
+	if err != nil {
+		return Bundle{}, err
+	}
+	trail, err := k.trail(sessionID)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	bundle := NewBundle(d, trail, k.now())
+	if err := bundle.Sign(k.signerFactory(d.ProviderID)); err != nil {
+		return Bundle{}, err
+	}
+	return bundle, nil
+}
+
+// IsHeld returns true when the provider channel with hermes has open disputes.
+func (k *Keeper) IsHeld(providerID identity.Identity, hermesID common.Address) bool {
+	disputes, err := k.storage.OpenDisputes()
+	if err != nil {
+		log.Err(err).Msg("Could not check open payment disputes, holding settlement")
+		return true
+	}
+
+	for _, d := range disputes {
+		if strings.EqualFold(d.ProviderID.Address, providerID.Address) && d.HermesID == hermesID {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *Keeper) trail(sessionID session.ID) (Trail, error) {
+	k.mu.Lock()
+	if t, ok := k.active[sessionID]; ok {
+		trail := *t
+		trail.Invoices = append(trail.Invoices[:0:0], t.Invoices...)
+		trail.ExchangeMessages = append(trail.ExchangeMessages[:0:0], t.ExchangeMessages...)
+		k.mu.Unlock()
+		return trail, nil
+	}
+	k.mu.Unlock()
+
+	trail, err := k.storage.Trail(sessionID)
+	if errors.Is(err, ErrNotFound) {
+		return Trail{}, ErrUnknownSession
+	}
+	return trail, err
+}
+
+func (k *Keeper) update(sessionID session.ID, fn func(t *Trail)) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	t, ok := k.activeTrail(sessionID)
+	if !ok {
+		return
+	}
+
+	fn(t)
+	k.store(t)
+}
+
+// activeTrail returns the trail of a running session, loading it from storage for sessions started before a restart.
+// Must be called with the lock held.
+func (k *Keeper) activeTrail(sessionID session.ID) (*Trail, bool) {
+	if t, ok := k.active[sessionID]; ok {
+		return t, true
+	}
+
+	t, err := k.storage.Trail(sessionID)
+	if err != nil || !t.EndedAt.IsZero() {
+		return nil, false
+	}
+	k.active[sessionID] = &t
+	return &t, true
+}
+
+func (k *Keeper) store(t *Trail) {
+	if err := k.storage.StoreTrail(*t); err != nil {
+		log.Err(err).Msgf("Could not store payment audit trail of session %s", t.SessionID)
+	}
+}
+
+func (k *Keeper) consumeSessionEvent(e session_event.AppEventSession) {
+	id := session.ID(e.Session.ID)
+	switch e.Status {
+	case session_event.CreatedStatus:
+		k.mu.Lock()
+		defer k.mu.Unlock()
+
+		t := &Trail{
+			SessionID:   id,
+			ProviderID:  identity.FromAddress(e.Session.Proposal.ProviderID),
+			ConsumerID:  e.Session.ConsumerID,
+			HermesID:    e.Session.HermesID,
+			ServiceType: e.Session.Proposal.ServiceType,
+			StartedAt:   e.Session.StartedAt.UTC(),
+		}
+		k.active[id] = t
+		k.store(t)
+	case session_event.RemovedStatus:
+		k.mu.Lock()
+		defer k.mu.Unlock()
+
+		t, ok := k.activeTrail(id)
+		if !ok {
+			return
+		}
+		delete(k.active, id)
+
+		t.EndedAt = k.now().UTC()
+		k.store(t)
+	}
+}
+
+func (k *Keeper) consumeInvoiceSentEvent(e pingpong_event.AppEventInvoiceSent) {
+	k.update(session.ID(e.SessionID), func(t *Trail) {
+		t.Invoices = append(t.Invoices, e.Invoice)
+	})
+}
+
+func (k *Keeper) consumeExchangeMessageEvent(e pingpong_event.AppEventExchangeMessageReceived) {
+	k.update(session.ID(e.SessionID), func(t *Trail) {
+		t.ExchangeMessages = append(t.ExchangeMessages, e.ExchangeMessage)
+	})
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dispute
+
+import (
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	session_event "github.com/mysteriumnetwork/node/session/event"
+	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/payments/crypto"
+)
+
+var hermesID = common.HexToAddress("0x1")
+
+func TestKeeper_FlagHoldsSettlementUntilResolved(t *testing.T) {
+	signerFactory, providerID := newIdentity(t)
+	storage := newTestStorage(t)
+	keeper := NewKeeper(storage, signerFactory)
+	consumerID := identity.FromAddress("0xconsumer")
+
+	keeper.consumeSessionEvent(session_event.AppEventSession{
+		Status: session_event.CreatedStatus,
+		Session: session_event.SessionContext{
+			ID:         "session-1",
+			StartedAt:  time.Now().Add(-time.Hour),
+			ConsumerID: consumerID,
+			HermesID:   hermesID,
+			Proposal:   market.ServiceProposal{ProviderID: providerID.Address, ServiceType: "wireguard"},
+		},
+	})
+	keeper.consumeInvoiceSentEvent(pingpong_event.AppEventInvoiceSent{SessionID: "session-1", Invoice: crypto.Invoice{AgreementTotal: big.NewInt(100)}})
+	keeper.consumeExchangeMessageEvent(pingpong_event.AppEventExchangeMessageReceived{SessionID: "session-1", ExchangeMessage: crypto.ExchangeMessage{AgreementTotal: big.NewInt(100)}})
+	keeper.consumeInvoiceSentEvent(pingpong_event.AppEventInvoiceSent{SessionID: "session-1", Invoice: crypto.Invoice{AgreementTotal: big.NewInt(300)}})
+	keeper.consumeSessionEvent(session_event.AppEventSession{
+		Status:  session_event.RemovedStatus,
+		Session: session_event.SessionContext{ID: "session-1"},
+	})
+
+	_, err := keeper.Flag("unknown", "")
+	assert.ErrorIs(t, err, ErrUnknownSession)
+
+	assert.False(t, keeper.IsHeld(providerID, hermesID))
+	d, err := keeper.Flag("session-1", "second invoice not paid")
+	require.NoError(t, err)
+	assert.Equal(t, StatusOpen, d.Status)
+	assert.Equal(t, consumerID, d.ConsumerID)
+	assert.True(t, keeper.IsHeld(providerID, hermesID))
+	assert.False(t, keeper.IsHeld(providerID, common.HexToAddress("0x2")))
+
+	_, err = keeper.Flag("session-1", "")
+	assert.ErrorIs(t, err, ErrAlreadyDisputed)
+
+	bundle, err := keeper.Bundle("session-1")
+	require.NoError(t, err)
+	assert.Len(t, bundle.Trail.Invoices, 2)
+	assert.Len(t, bundle.Trail.ExchangeMessages, 1)
+	assert.Equal(t, big.NewInt(300), bundle.Invoiced)
+	assert.Equal(t, big.NewInt(100), bundle.Paid)
+	assert.Equal(t, big.NewInt(200), bundle.Unpaid)
+	assert.NoError(t, bundle.Verify())
+
+	bundle.Unpaid = big.NewInt(1000)
+	assert.ErrorIs(t, bundle.Verify(), ErrInvalidSignature)
+
+	d, err = keeper.Resolve("session-1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusResolved, d.Status)
+	assert.False(t, keeper.IsHeld(providerID, hermesID))
+
+	_, err = keeper.Resolve("session-1")
+	assert.ErrorIs(t, err, ErrNotOpen)
+}
+
+func TestKeeper_DisputesSessionsActiveBeforeRestart(t *testing.T) {
+	signerFactory, providerID := newIdentity(t)
+	storage := newTestStorage(t)
+	keeper := NewKeeper(storage, signerFactory)
+
+	keeper.consumeSessionEvent(session_event.AppEventSession{
+		Status: session_event.CreatedStatus,
+		Session: session_event.SessionContext{
+			ID:        "session-1",
+			StartedAt: time.Now(),
+			HermesID:  hermesID,
+			Proposal:  market.ServiceProposal{ProviderID: providerID.Address, ServiceType: "wireguard"},
+		},
+	})
+	keeper.consumeInvoiceSentEvent(pingpong_event.AppEventInvoiceSent{SessionID: "session-1", Invoice: crypto.Invoice{AgreementTotal: big.NewInt(100)}})
+
+	// Node restarts while the session is still running.
+	keeper = NewKeeper(storage, signerFactory)
+	keeper.consumeInvoiceSentEvent(pingpong_event.AppEventInvoiceSent{SessionID: "session-1", Invoice: crypto.Invoice{AgreementTotal: big.NewInt(200)}})
+
+	_, err := keeper.Flag("session-1", "not paid")
+	require.NoError(t, err)
+	bundle, err := keeper.Bundle("session-1")
+	require.NoError(t, err)
+	assert.Len(t, bundle.Trail.Invoices, 2)
+	assert.True(t, bundle.Trail.EndedAt.IsZero())
+
+	keeper.consumeSessionEvent(session_event.AppEventSession{
+		Status:  session_event.RemovedStatus,
+		Session: session_event.SessionContext{ID: "session-1"},
+	})
+	trail, err := storage.Trail("session-1")
+	require.NoError(t, err)
+	assert.False(t, trail.EndedAt.IsZero())
+}
+
+func TestStorage_PruneTrailsKeepsDisputed(t *testing.T) {
+	storage := newTestStorage(t)
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, storage.StoreTrail(Trail{SessionID: "old", StartedAt: old}))
+	require.NoError(t, storage.StoreTrail(Trail{SessionID: "disputed", StartedAt: old}))
+	require.NoError(t, storage.StoreTrail(Trail{SessionID: "new", StartedAt: time.Now()}))
+	require.NoError(t, storage.StoreDispute(Dispute{SessionID: "disputed", Status: StatusOpen}))
+
+	count, err := storage.PruneTrails(time.Now().Add(-24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	_, err = storage.Trail("old")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = storage.Trail("disputed")
+	assert.NoError(t, err)
+	_, err = storage.Trail("new")
+	assert.NoError(t, err)
+}
+
+func newIdentity(t *testing.T) (identity.SignerFactory, identity.Identity) {
+	ks := identity.NewMockKeystore()
+	acc, err := ks.NewAccount("")
+	require.NoError(t, err)
+	require.NoError(t, ks.Unlock(acc, ""))
+
+	signerFactory := func(id identity.Identity) identity.Signer {
+		return identity.NewSigner(ks, id)
+	}
+	return signerFactory, identity.FromAddress(acc.Address.Hex())
+}
+
+func newTestStorage(t *testing.T) *Storage {
+	dir, err := os.MkdirTemp("", "disputeStorageTest")
+	require.NoError(t, err)
+
+	db, err := boltdb.NewStorage(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+		os.RemoveAll(dir)
+	})
+
+	return NewStorage(db)
+}
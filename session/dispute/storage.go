@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dispute
+
+import (
+	"errors"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/session"
+)
+
+const (
+	disputesBucket = "payment-disputes"
+	trailsBucket   = "payment-trails"
+)
+
+// ErrNotFound is returned when there is no record for the session.
+var ErrNotFound = errors.New("not found")
+
+// Storage stores payment disputes and audit trails of provided sessions.
+type Storage struct {
+	bolt *boltdb.Bolt
+}
+
+// NewStorage returns a new instance of the dispute Storage.
+func NewStorage(bolt *boltdb.Bolt) *Storage {
+	return &Storage{bolt: bolt}
+}
+
+// StoreDispute stores a given dispute.
+func (s *Storage) StoreDispute(d Dispute) error {
+	s.bolt.Lock()
+	defer s.bolt.Unlock()
+	return s.bolt.DB().From(disputesBucket).Save(&d)
+}
+
+// Dispute returns the dispute of a given session.
+func (s *Storage) Dispute(sessionID session.ID) (Dispute, error) {
+	s.bolt.RLock()
+	defer s.bolt.RUnlock()
+
+	var d Dispute
+	err := s.bolt.DB().From(disputesBucket).One("SessionID", sessionID, &d)
+	if errors.Is(err, storm.ErrNotFound) {
+		return Dispute{}, ErrNotFound
+	}
+	return d, err
+}
+
+// Disputes returns all stored disputes, the newest first.
+func (s *Storage) Disputes() (result []Dispute, err error) {
+	s.bolt.RLock()
+	defer s.bolt.RUnlock()
+
+	err = s.bolt.DB().
+		From(disputesBucket).
+		Select().
+		OrderBy("CreatedAt").
+		Reverse().
+		Find(&result)
+	if errors.Is(err, storm.ErrNotFound) {
+		return []Dispute{}, nil
+	}
+	return result, err
+}
+
+// OpenDisputes returns disputes which hold promise settlement.
+func (s *Storage) OpenDisputes() (result []Dispute, err error) {
+	s.bolt.RLock()
+	defer s.bolt.RUnlock()
+
+	err = s.bolt.DB().From(disputesBucket).Select(q.Eq("Status", StatusOpen)).Find(&result)
+	if errors.Is(err, storm.ErrNotFound) {
+		return []Dispute{}, nil
+	}
+	return result, err
+}
+
+// StoreTrail stores a given audit trail.
+func (s *Storage) StoreTrail(t Trail) error {
+	s.bolt.Lock()
+	defer s.bolt.Unlock()
+	return s.bolt.DB().From(trailsBucket).Save(&t)
+}
+
+// Trail returns the audit trail of a given session.
+func (s *Storage) Trail(sessionID session.ID) (Trail, error) {
+	s.bolt.RLock()
+	defer s.bolt.RUnlock()
+
+	var t Trail
+	err := s.bolt.DB().From(trailsBucket).One("SessionID", sessionID, &t)
+	if errors.Is(err, storm.ErrNotFound) {
+		return Trail{}, ErrNotFound
+	}
+	return t, err
+}
+
+// PruneTrails removes audit trails of undisputed sessions started before the given time and returns their count.
+func (s *Storage) PruneTrails(before time.Time) (int, error) {
+	s.bolt.Lock()
+	defer s.bolt.Unlock()
+
+	var trails []Trail
+	err := s.bolt.DB().From(trailsBucket).Select(q.Lt("StartedAt", before)).Find(&trails)
+	if errors.Is(err, storm.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	for i := range trails {
+		var d Dispute
+		err := s.bolt.DB().From(disputesBucket).One("SessionID", trails[i].SessionID, &d)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, storm.ErrNotFound) {
+			return count, err
+		}
+
+		if err := s.bolt.DB().From(trailsBucket).DeleteStruct(&trails[i]); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
@@ -34,8 +34,16 @@ const (
 	AppTopicDataTransferred = "Session data transferred"
 	// AppTopicTokensEarned is a topic for publish events about tokens earned as a provider.
 	AppTopicTokensEarned = "SessionTokensEarned"
+	// AppTopicSessionEgress represents the topic destination ports reached by provided session consumers are published on.
+	AppTopicSessionEgress = "Session egress"
 )
 
+// AppEventSessionEgress lists destination ports the consumer of the session has reached
+type AppEventSessionEgress struct {
+	SessionID string
+	Ports     []uint16
+}
+
 // AppEventDataTransferred represents the data transfer event
 type AppEventDataTransferred struct {
 	ID       string
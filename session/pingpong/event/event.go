@@ -40,6 +40,10 @@ const (
 	AppTopicSettlementComplete = "provider_settlement_complete"
 	// AppTopicWithdrawalRequested topic for succesfull withdrawal requests.
 	AppTopicWithdrawalRequested = "provider_withdrawal_requested"
+	// AppTopicInvoiceSent is a topic for publish events about invoices sent to consumer as a provider.
+	AppTopicInvoiceSent = "invoice_sent"
+	// AppTopicExchangeMessageReceived is a topic for publish events about exchange messages received from consumer as a provider.
+	AppTopicExchangeMessageReceived = "exchange_message_received"
 )
 
 // AppEventSettlementRequest represents the payload that is sent on the AppTopicSettlementRequest topic.
@@ -89,6 +93,22 @@ type AppEventInvoicePaid struct {
 	Invoice    crypto.Invoice
 }
 
+// AppEventInvoiceSent is an invoice sent to consumer during current session.
+type AppEventInvoiceSent struct {
+	ProviderID identity.Identity
+	ConsumerID identity.Identity
+	SessionID  string
+	Invoice    crypto.Invoice
+}
+
+// AppEventExchangeMessageReceived is a valid exchange message received from consumer during current session.
+type AppEventExchangeMessageReceived struct {
+	ProviderID      identity.Identity
+	ConsumerID      identity.Identity
+	SessionID       string
+	ExchangeMessage crypto.ExchangeMessage
+}
+
 // AppTopicGrandTotalChanged represents a topic to which we send grand total change messages.
 const AppTopicGrandTotalChanged = "consumer_grand_total_change"
 
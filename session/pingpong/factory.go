@@ -33,8 +33,6 @@ import (
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/pb"
-	"github.com/mysteriumnetwork/node/session"
-	"github.com/mysteriumnetwork/node/session/mbtime"
 	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/rs/zerolog/log"
 )
@@ -63,15 +61,14 @@ func InvoiceFactoryCreator(
 	promiseHandler promiseHandler,
 	addressProvider addressProvider,
 	observer observerApi,
-) func(identity.Identity, identity.Identity, int64, common.Address, string, chan crypto.ExchangeMessage, market.Price) (service.PaymentEngine, error) {
-	return func(providerID, consumerID identity.Identity, chainID int64, hermesID common.Address, sessionID string, exchangeChan chan crypto.ExchangeMessage, price market.Price) (service.PaymentEngine, error) {
-		timeTracker := session.NewTracker(mbtime.Now)
+	freeTier freeTierTracker,
+) func(identity.Identity, identity.Identity, int64, common.Address, string, chan crypto.ExchangeMessage, market.Price, market.InvoiceTerms) (service.PaymentEngine, error) {
+	return func(providerID, consumerID identity.Identity, chainID int64, hermesID common.Address, sessionID string, exchangeChan chan crypto.ExchangeMessage, price market.Price, terms market.InvoiceTerms) (service.PaymentEngine, error) {
 		deps := InvoiceTrackerDeps{
 			AgreedPrice:                price,
 			Peer:                       consumerID,
 			PeerInvoiceSender:          NewInvoiceSender(channel),
 			InvoiceStorage:             invoiceStorage,
-			TimeTracker:                &timeTracker,
 			ExchangeMessageChan:        exchangeChan,
 			ExchangeMessageWaitTimeout: promiseTimeout,
 			ProviderID:                 providerID,
@@ -90,6 +87,8 @@ func InvoiceFactoryCreator(
 			LimitChargePeriod:          limitBalanceSendPeriod,
 			ChargePeriodLeeway:         2 * time.Minute,
 			Observer:                   observer,
+			RequestedInvoiceTerms:      terms,
+			FreeTier:                   freeTier,
 		}
 		paymentEngine := NewInvoiceTracker(deps)
 		return paymentEngine, nil
@@ -109,12 +108,10 @@ func ExchangeFactoryFunc(
 		if err != nil {
 			return nil, err
 		}
-		timeTracker := session.NewTracker(mbtime.Now)
 		deps := InvoicePayerDeps{
 			InvoiceChan:               invoices,
 			PeerExchangeMessageSender: NewExchangeSender(channel),
 			ConsumerTotalsStorage:     totalStorage,
-			TimeTracker:               &timeTracker,
 			Ks:                        keystore,
 			Identity:                  consumer,
 			Peer:                      provider,
@@ -0,0 +1,193 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+)
+
+const freeTierBucket = "free-tier-usage"
+
+// FreeTierAllowance is the traffic and time a consumer can use free of charge each day.
+// A zero value of either field leaves that dimension unlimited, zero values of both disable the free tier.
+type FreeTierAllowance struct {
+	Data     uint64
+	Duration time.Duration
+}
+
+func (a FreeTierAllowance) enabled() bool {
+	return a.Data > 0 || a.Duration > 0
+}
+
+func (a FreeTierAllowance) add(b FreeTierAllowance) FreeTierAllowance {
+	return FreeTierAllowance{Data: a.Data + b.Data, Duration: a.Duration + b.Duration}
+}
+
+// sub subtracts b from a, stopping at zero.
+func (a FreeTierAllowance) sub(b FreeTierAllowance) FreeTierAllowance {
+	var res FreeTierAllowance
+	if a.Data > b.Data {
+		res.Data = a.Data - b.Data
+	}
+	if a.Duration > b.Duration {
+		res.Duration = a.Duration - b.Duration
+	}
+	return res
+}
+
+type freeTierUsage struct {
+	Day      string
+	Data     uint64
+	Duration time.Duration
+}
+
+// FreeTier keeps track of the daily free allowance used by each consumer.
+// Allowance granted to running sessions is reserved, so parallel sessions of a consumer share a single allowance.
+type FreeTier struct {
+	allowance FreeTierAllowance
+	bolt      persistentStorage
+	registry  registrationStatusProvider
+	reserved  map[string]FreeTierAllowance
+	now       func() time.Time
+	lock      sync.Mutex
+}
+
+// NewFreeTier creates a new instance of free tier tracker.
+func NewFreeTier(bolt persistentStorage, allowance FreeTierAllowance, registry registrationStatusProvider) *FreeTier {
+	return &FreeTier{
+		allowance: allowance,
+		bolt:      bolt,
+		registry:  registry,
+		reserved:  make(map[string]FreeTierAllowance),
+		now:       time.Now,
+	}
+}
+
+// Reserve grants the consumer all the free allowance it has left for today, false if there is none.
+// Only registered consumers are granted the free tier, as new identities cost nothing to create.
+// The reservation is reduced by the usage consumed and must be released when the session ends.
+func (ft *FreeTier) Reserve(chainID int64, consumer identity.Identity) (FreeTierAllowance, bool) {
+	if !ft.allowance.enabled() {
+		return FreeTierAllowance{}, false
+	}
+
+	status, err := ft.registry.GetRegistrationStatus(chainID, consumer)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Could not check registration status of consumer %s, free tier not granted", consumer.Address)
+		return FreeTierAllowance{}, false
+	}
+	if status != registry.Registered {
+		return FreeTierAllowance{}, false
+	}
+
+	ft.lock.Lock()
+	defer ft.lock.Unlock()
+
+	usage, err := ft.usage(consumer)
+	if err != nil {
+		return FreeTierAllowance{}, false
+	}
+
+	reserved := ft.reserved[ft.key(consumer)]
+	taken := reserved.add(FreeTierAllowance{Data: usage.Data, Duration: usage.Duration})
+
+	var left FreeTierAllowance
+	if ft.allowance.Data > 0 {
+		if taken.Data >= ft.allowance.Data {
+			return FreeTierAllowance{}, false
+		}
+		left.Data = ft.allowance.Data - taken.Data
+	}
+	if ft.allowance.Duration > 0 {
+		if taken.Duration >= ft.allowance.Duration {
+			return FreeTierAllowance{}, false
+		}
+		left.Duration = ft.allowance.Duration - taken.Duration
+	}
+
+	ft.reserved[ft.key(consumer)] = reserved.add(left)
+	return left, true
+}
+
+// Consume adds the traffic and time used free of charge to the consumer usage for today,
+// taking it out of the consumer reservation.
+func (ft *FreeTier) Consume(consumer identity.Identity, data uint64, duration time.Duration) error {
+	if !ft.allowance.enabled() {
+		return nil
+	}
+
+	ft.lock.Lock()
+	defer ft.lock.Unlock()
+
+	usage, err := ft.usage(consumer)
+	if err != nil {
+		return err
+	}
+	usage.Data += data
+	usage.Duration += duration
+
+	if err := ft.bolt.SetValue(freeTierBucket, ft.key(consumer), usage); err != nil {
+		return errors.Wrap(err, "could not save free tier usage")
+	}
+	ft.unreserve(consumer, FreeTierAllowance{Data: data, Duration: duration})
+	return nil
+}
+
+// Release returns the unused reservation of an ended session.
+func (ft *FreeTier) Release(consumer identity.Identity, unused FreeTierAllowance) {
+	ft.lock.Lock()
+	defer ft.lock.Unlock()
+
+	ft.unreserve(consumer, unused)
+}
+
+func (ft *FreeTier) unreserve(consumer identity.Identity, allowance FreeTierAllowance) {
+	reserved := ft.reserved[ft.key(consumer)].sub(allowance)
+	if !reserved.enabled() {
+		delete(ft.reserved, ft.key(consumer))
+		return
+	}
+	ft.reserved[ft.key(consumer)] = reserved
+}
+
+func (ft *FreeTier) usage(consumer identity.Identity) (freeTierUsage, error) {
+	today := ft.now().UTC().Format("2006-01-02")
+
+	var usage freeTierUsage
+	err := ft.bolt.GetValue(freeTierBucket, ft.key(consumer), &usage)
+	if err != nil && !errors.Is(err, storm.ErrNotFound) {
+		return usage, errors.Wrap(err, "could not get free tier usage")
+	}
+	if usage.Day != today {
+		usage = freeTierUsage{Day: today}
+	}
+	return usage, nil
+}
+
+func (ft *FreeTier) key(consumer identity.Identity) string {
+	return strings.ToLower(consumer.Address)
+}
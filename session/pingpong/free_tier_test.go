@@ -0,0 +1,154 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+)
+
+func TestFreeTier_TracksDailyUsage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freeTierTest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	defer bolt.Close()
+
+	consumer := identity.FromAddress("0xConsumer")
+	registered := &mockRegistrationStatusProvider{identities: map[string]mockRegistrationStatus{
+		"1" + consumer.Address: {status: registry.Registered},
+	}}
+
+	now := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+	freeTier := NewFreeTier(bolt, FreeTierAllowance{Data: 100, Duration: 10 * time.Minute}, registered)
+	freeTier.now = func() time.Time { return now }
+
+	left, ok := freeTier.Reserve(1, consumer)
+	assert.True(t, ok)
+	assert.Equal(t, FreeTierAllowance{Data: 100, Duration: 10 * time.Minute}, left)
+
+	// Parallel session gets nothing while the allowance is reserved.
+	_, ok = freeTier.Reserve(1, consumer)
+	assert.False(t, ok)
+
+	assert.NoError(t, freeTier.Consume(consumer, 40, time.Minute))
+	freeTier.Release(consumer, FreeTierAllowance{Data: 60, Duration: 9 * time.Minute})
+	left, ok = freeTier.Reserve(1, consumer)
+	assert.True(t, ok)
+	assert.Equal(t, FreeTierAllowance{Data: 60, Duration: 9 * time.Minute}, left)
+
+	assert.NoError(t, freeTier.Consume(consumer, 60, time.Minute))
+	freeTier.Release(consumer, FreeTierAllowance{Duration: 8 * time.Minute})
+	_, ok = freeTier.Reserve(1, consumer)
+	assert.False(t, ok)
+
+	// Usage is persisted, so it survives a restart.
+	freeTier = NewFreeTier(bolt, FreeTierAllowance{Data: 100, Duration: 10 * time.Minute}, registered)
+	freeTier.now = func() time.Time { return now }
+	_, ok = freeTier.Reserve(1, consumer)
+	assert.False(t, ok)
+
+	now = now.Add(24 * time.Hour)
+	left, ok = freeTier.Reserve(1, consumer)
+	assert.True(t, ok)
+	assert.Equal(t, FreeTierAllowance{Data: 100, Duration: 10 * time.Minute}, left)
+}
+
+func TestFreeTier_RequiresRegisteredConsumer(t *testing.T) {
+	freeTier := NewFreeTier(nil, FreeTierAllowance{Data: 100}, &mockRegistrationStatusProvider{})
+
+	_, ok := freeTier.Reserve(1, identity.FromAddress("0xConsumer"))
+	assert.False(t, ok)
+}
+
+func TestFreeTier_Disabled(t *testing.T) {
+	freeTier := NewFreeTier(nil, FreeTierAllowance{}, nil)
+
+	_, ok := freeTier.Reserve(1, identity.FromAddress("0xConsumer"))
+	assert.False(t, ok)
+	assert.NoError(t, freeTier.Consume(identity.FromAddress("0xConsumer"), 1, time.Second))
+}
+
+type mockFreeTier struct {
+	left     FreeTierAllowance
+	data     uint64
+	duration time.Duration
+	released FreeTierAllowance
+}
+
+func (m *mockFreeTier) Reserve(_ int64, _ identity.Identity) (FreeTierAllowance, bool) {
+	return m.left, m.left.enabled()
+}
+
+func (m *mockFreeTier) Consume(_ identity.Identity, data uint64, duration time.Duration) error {
+	m.data += data
+	m.duration += duration
+	return nil
+}
+
+func (m *mockFreeTier) Release(_ identity.Identity, unused FreeTierAllowance) {
+	m.released = unused
+}
+
+func TestInvoiceTracker_ChargesAfterFreeTier(t *testing.T) {
+	freeTier := &mockFreeTier{left: FreeTierAllowance{Data: 1000}}
+	timeTracker := &mockTimeTracker{}
+	tracker := NewInvoiceTracker(InvoiceTrackerDeps{
+		AgreedPrice:       *market.NewPrice(3600, 0),
+		TimeTracker:       timeTracker,
+		FreeTier:          freeTier,
+		ChargePeriod:      time.Minute,
+		LimitChargePeriod: time.Minute,
+		MaxNotPaidInvoice: big.NewInt(1),
+		EventBus:          mocks.NewEventBus(),
+	})
+
+	tracker.reserveFreeTier()
+
+	tracker.updateDataTransfer(400, 500)
+	timeTracker.timeToReturn = time.Minute
+	assert.Equal(t, big.NewInt(0), tracker.amountDue(timeTracker.Elapsed()))
+
+	tracker.consumeFreeTier()
+	assert.Equal(t, uint64(900), freeTier.data)
+	assert.Equal(t, time.Minute, freeTier.duration)
+
+	tracker.updateDataTransfer(500, 600)
+	assert.Equal(t, big.NewInt(0), tracker.amountDue(timeTracker.Elapsed()))
+
+	timeTracker.timeToReturn = time.Minute + 10*time.Second
+	assert.Equal(t, big.NewInt(10), tracker.amountDue(timeTracker.Elapsed()))
+
+	tracker.Stop()
+	assert.Equal(t, uint64(1100), freeTier.data)
+	assert.Equal(t, time.Minute, freeTier.duration)
+	assert.Equal(t, FreeTierAllowance{}, freeTier.released)
+}
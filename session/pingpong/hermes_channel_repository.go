@@ -18,8 +18,6 @@
 package pingpong
 
 import (
-	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -27,7 +25,6 @@ import (
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
-	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/mysteriumnetwork/node/config"
 	nodeEvent "github.com/mysteriumnetwork/node/core/node/event"
 	"github.com/mysteriumnetwork/node/eventbus"
@@ -296,49 +293,10 @@ func (hcr *HermesChannelRepository) handleIdentityUnlock(payload identity.AppEve
 	}
 
 	if data.LatestPromise.Amount != nil && data.LatestPromise.Amount.Cmp(big.NewInt(0)) != 0 {
-		R := crypto.GenerateR()
-		hashlock := ethcrypto.Keccak256(R)
-		details := rRecoveryDetails{
-			R:           hex.EncodeToString(R),
-			AgreementID: big.NewInt(0),
-		}
-
-		bytes, err := json.Marshal(details)
-		if err != nil {
-			log.Err(err).Msgf("could not marshal R recovery details")
-			return
-		}
-
-		encrypted, err := hcr.encryption.Encrypt(payload.ID.ToCommonAddress(), bytes)
-		if err != nil {
-			log.Err(err).Msgf("could not encrypt R")
-			return
-		}
-		signer := hcr.signer(payload.ID)
-		promise, err := hcr.hermesCaller.RefreshLatestProviderPromise(config.GetInt64(config.FlagChainID), payload.ID.Address, hashlock, encrypted, signer)
-		if err != nil {
-			log.Err(err).Msgf("failed to refresh promise")
-			return
-		}
-		hermesPromise := HermesPromise{
-			R:         hex.EncodeToString(R),
-			ChannelID: data.ChannelID,
-			Identity:  identity.FromAddress(data.Identity),
-			HermesID:  hermes,
-			Promise:   promise,
-			Revealed:  false,
-		}
-
-		err = hcr.promiseProvider.Store(hermesPromise)
+		_, err := refreshProviderPromise(hcr.hermesCaller, hcr.promiseProvider, hcr.encryption, hcr.publisher, hcr.signer(payload.ID), config.GetInt64(config.FlagChainID), identity.FromAddress(data.Identity), hermes, data.ChannelID)
 		if err != nil {
-			log.Err(err).Msg("could not store hermes promise")
-			return
+			log.Err(err).Msg("could not refresh latest provider promise")
 		}
-		hcr.publisher.Publish(pingEvent.AppTopicHermesPromise, pingEvent.AppEventHermesPromise{
-			Promise:    promise,
-			HermesID:   hermes,
-			ProviderID: identity.FromAddress(data.Identity),
-		})
 	}
 }
 
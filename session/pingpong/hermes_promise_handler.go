@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"math/big"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/config"
@@ -31,6 +32,7 @@ import (
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/metrics"
 	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	pinge "github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/payments/crypto"
@@ -76,6 +78,8 @@ type HermesPromiseHandlerDeps struct {
 	HermesCallerFactory  HermesCallerFactory
 	Signer               identity.SignerFactory
 	Chains               []int64
+	// TimeNow checks validity of transactor fees, local time is used if not set.
+	TimeNow func() time.Time
 }
 
 // HermesPromiseHandler handles the hermes promises for ongoing sessions.
@@ -93,6 +97,9 @@ func NewHermesPromiseHandler(deps HermesPromiseHandlerDeps) *HermesPromiseHandle
 	if len(deps.Chains) == 0 {
 		deps.Chains = []int64{config.GetInt64(config.FlagChain1ChainID), config.GetInt64(config.FlagChain2ChainID)}
 	}
+	if deps.TimeNow == nil {
+		deps.TimeNow = time.Now
+	}
 
 	return &HermesPromiseHandler{
 		deps:           deps,
@@ -142,10 +149,13 @@ func (aph *HermesPromiseHandler) RequestPromise(r []byte, em crypto.ExchangeMess
 
 func (aph *HermesPromiseHandler) makeRequestPromiseFunc(providerID identity.Identity, caller HermesHTTPRequester) func(rp RequestPromise) (crypto.Promise, error) {
 	return func(rp RequestPromise) (crypto.Promise, error) {
+		start := time.Now()
 		p, err := caller.RequestPromise(rp)
+		promiseRequestDuration.Record(time.Since(start).Milliseconds())
 		if err == nil {
 			return p, nil
 		}
+		promiseRequestFailures.Inc()
 
 		if !stdErr.Is(err, ErrInvalidPreviuosLatestPromise) {
 			// We can only really handle the previuos promise is invalid error.
@@ -201,7 +211,7 @@ func (aph *HermesPromiseHandler) PayAndSettle(r []byte, em crypto.ExchangeMessag
 
 func (aph *HermesPromiseHandler) getFees(chainID int64) (*big.Int, error) {
 	fee, ok := aph.transactorFees[chainID]
-	if ok && fee.IsValid() {
+	if ok && fee.IsValid(aph.deps.TimeNow()) {
 		return fee.Fee, nil
 	}
 
@@ -412,6 +422,11 @@ func (aph *HermesPromiseHandler) revealR(hermesPromise HermesPromise) error {
 }
 
 var errRrecovered = errors.New("R recovered")
+
+var (
+	promiseRequestDuration = metrics.NewHistogram("payments_hermes_promise_request_duration_ms", "Duration of promise requests to hermes", time.Minute.Milliseconds(), 3)
+	promiseRequestFailures = metrics.NewCounter("payments_hermes_promise_request_failures_total", "Failed promise requests to hermes")
+)
 var errPreviuosPromise = errors.New("action cannot be performed as previuos promise is invalid")
 
 func (aph *HermesPromiseHandler) handleHermesError(err error, providerID identity.Identity, chainID int64, hermesID common.Address) error {
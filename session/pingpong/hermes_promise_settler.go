@@ -87,6 +87,14 @@ type transactor interface {
 	GetQueueStatus(ID string) (registry.QueueResponse, error)
 }
 
+type gasPriceChecker interface {
+	Check(chainID int64) error
+}
+
+type settlementHold interface {
+	IsHeld(providerID identity.Identity, hermesID common.Address) bool
+}
+
 type hermesChannelProvider interface {
 	Get(chainID int64, id identity.Identity, hermesID common.Address) (HermesChannel, bool)
 	Fetch(chainID int64, id identity.Identity, hermesID common.Address) (HermesChannel, error)
@@ -129,6 +137,8 @@ type hermesPromiseSettler struct {
 	publisher                  eventbus.Publisher
 	hf                         hermesFees
 	observerApi                observerApi
+	gasPrice                   gasPriceChecker
+	holds                      settlementHold
 	// TODO: Consider adding chain ID to this as well.
 	currentState map[identity.Identity]settlementState
 	settleQueue  chan receivedPromise
@@ -172,8 +182,11 @@ type HermesPromiseSettlerConfig struct {
 
 var errFeeNotCovered = errors.New("fee not covered, cannot continue")
 
+// ErrSettlementHeld indicates that settlement is held by an open payment dispute.
+var ErrSettlementHeld = errors.New("settlement is held by an open payment dispute")
+
 // NewHermesPromiseSettler creates a new instance of hermes promise settler.
-func NewHermesPromiseSettler(transactor transactor, promiseStorage promiseStorage, paySettler paySettler, addressProvider addressProvider, hermesCallerFactory HermesCallerFactory, hermesURLGetter hermesURLGetter, channelProvider hermesChannelProvider, providerChannelStatusProvider providerChannelStatusProvider, registrationStatusProvider registrationStatusProvider, ks ks, settlementHistoryStorage settlementHistoryStorage, publisher eventbus.Publisher, observerApi observerApi, config HermesPromiseSettlerConfig) *hermesPromiseSettler {
+func NewHermesPromiseSettler(transactor transactor, promiseStorage promiseStorage, paySettler paySettler, addressProvider addressProvider, hermesCallerFactory HermesCallerFactory, hermesURLGetter hermesURLGetter, channelProvider hermesChannelProvider, providerChannelStatusProvider providerChannelStatusProvider, registrationStatusProvider registrationStatusProvider, ks ks, settlementHistoryStorage settlementHistoryStorage, publisher eventbus.Publisher, observerApi observerApi, gasPrice gasPriceChecker, holds settlementHold, config HermesPromiseSettlerConfig) *hermesPromiseSettler {
 	return &hermesPromiseSettler{
 		bc:                         providerChannelStatusProvider,
 		ks:                         ks,
@@ -192,6 +205,8 @@ func NewHermesPromiseSettler(transactor transactor, promiseStorage promiseStorag
 			fees: make(map[string]uint16),
 		},
 		observerApi: observerApi,
+		gasPrice:    gasPrice,
+		holds:       holds,
 		// defaulting to a queue of 5, in case we have a few active identities.
 		settleQueue: make(chan receivedPromise, 5),
 		stop:        make(chan struct{}),
@@ -621,6 +636,10 @@ func (aps *hermesPromiseSettler) Withdraw(
 		return errors.New("provider already has settlement in progress")
 	}
 
+	if aps.isHeld(providerID, hermesID) {
+		return ErrSettlementHeld
+	}
+
 	aps.setSettling(providerID, hermesID, true)
 	log.Info().Msgf("Marked provider %v as requesting settlement", providerID)
 	defer aps.setSettling(providerID, hermesID, false)
@@ -633,6 +652,15 @@ func (aps *hermesPromiseSettler) Withdraw(
 		return fmt.Errorf("can only withdraw from chain with ID %v, requested with %v", aps.config.L2ChainID, fromChainID)
 	}
 
+	if err := aps.gasPrice.Check(fromChainID); err != nil {
+		return err
+	}
+	if toChainID != fromChainID {
+		if err := aps.gasPrice.Check(toChainID); err != nil {
+			return err
+		}
+	}
+
 	registry, err := aps.addressProvider.GetRegistryAddress(fromChainID)
 	if err != nil {
 		return err
@@ -1015,11 +1043,21 @@ func (aps *hermesPromiseSettler) settle(
 	if aps.isSettling(provider, hermesID) {
 		return errors.New("provider already has settlement in progress")
 	}
+	if aps.isHeld(provider, hermesID) {
+		log.Warn().Msgf("Settlement of hermes %v for provider %v is held by an open payment dispute", hermesID.Hex(), provider)
+		return ErrSettlementHeld
+	}
+
 	aps.setSettling(provider, hermesID, true)
 	defer aps.setSettling(provider, hermesID, false)
 
 	log.Info().Msgf("Marked provider %v as requesting settlement", provider)
 
+	if err := aps.gasPrice.Check(promise.ChainID); err != nil {
+		log.Warn().Err(err).Msgf("Postponing settlement for provider %v", provider)
+		return err
+	}
+
 	updatedPromise, err := aps.updatePromiseWithLatestFee(hermesID, promise, maxFee)
 	if err != nil {
 		log.Error().Err(err).Msg("Could not update promise fee")
@@ -1273,6 +1311,10 @@ func (aps *hermesPromiseSettler) isSettling(id identity.Identity, hermesID commo
 	return ok
 }
 
+func (aps *hermesPromiseSettler) isHeld(id identity.Identity, hermesID common.Address) bool {
+	return aps.holds != nil && aps.holds.IsHeld(id, hermesID)
+}
+
 func (aps *hermesPromiseSettler) setSettling(id identity.Identity, hermesID common.Address, settling bool) {
 	aps.lock.Lock()
 	defer aps.lock.Unlock()
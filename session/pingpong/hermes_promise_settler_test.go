@@ -62,6 +62,8 @@ func TestPromiseSettler_loadInitialState(t *testing.T) {
 		&settlementHistoryStorageMock{},
 		&mockPublisher{},
 		&mockObserver{},
+		&mockGasPriceChecker{},
+		nil,
 		cfg)
 
 	settler.currentState[mockID] = settlementState{}
@@ -139,6 +141,8 @@ func TestPromiseSettler_handleRegistrationEvent(t *testing.T) {
 		&settlementHistoryStorageMock{},
 		&mockPublisher{},
 		&mockObserver{},
+		&mockGasPriceChecker{},
+		nil,
 		cfg)
 
 	statusesWithNoChangeExpected := []registry.RegistrationStatus{registry.Unregistered, registry.InProgress, registry.RegistrationError}
@@ -181,7 +185,7 @@ func TestPromiseSettler_handleHermesPromiseReceived(t *testing.T) {
 			ValidUntil: time.Now().Add(30 * time.Minute),
 		},
 	}
-	settler := NewHermesPromiseSettler(tm, &mockHermesPromiseStorage{}, &mockPayAndSettler{}, &mockAddressProvider{}, fac.Get, &mockHermesURLGetter{}, channelProvider, channelStatusProvider, mrsp, ks, &settlementHistoryStorageMock{}, &mockPublisher{}, &mockObserver{}, cfg)
+	settler := NewHermesPromiseSettler(tm, &mockHermesPromiseStorage{}, &mockPayAndSettler{}, &mockAddressProvider{}, fac.Get, &mockHermesURLGetter{}, channelProvider, channelStatusProvider, mrsp, ks, &settlementHistoryStorageMock{}, &mockPublisher{}, &mockObserver{}, &mockGasPriceChecker{}, nil, cfg)
 
 	// no receive on unknown provider
 	channelProvider.channelToReturn = NewHermesChannel("1", mockID, hermesID, mockProviderChannel, HermesPromise{})
@@ -283,6 +287,8 @@ func TestPromiseSettler_handleNodeStart(t *testing.T) {
 		&settlementHistoryStorageMock{},
 		&mockPublisher{},
 		&mockObserver{},
+		&mockGasPriceChecker{},
+		nil,
 		cfg)
 
 	settler.handleNodeStart()
@@ -312,6 +318,7 @@ func TestPromiseSettler_RejectsIfFeesExceedSettlementAmount(t *testing.T) {
 		},
 		hermesCallerFactory: fac.Get,
 		hermesURLGetter:     &mockHermesURLGetter{},
+		gasPrice:            &mockGasPriceChecker{},
 		bc: &mockProviderChannelStatusProvider{
 			calculatedFees: hermesFee,
 		},
@@ -329,6 +336,27 @@ func TestPromiseSettler_RejectsIfFeesExceedSettlementAmount(t *testing.T) {
 	assert.Equal(t, "settlement fees exceed earning amount. Please provide more service and try again. Current earnings: 29000, current fees: 30000: fee not covered, cannot continue", err.Error())
 }
 
+type mockSettlementHold struct {
+	held bool
+}
+
+func (m *mockSettlementHold) IsHeld(_ identity.Identity, _ common.Address) bool {
+	return m.held
+}
+
+func TestPromiseSettler_RejectsIfHeldByDispute(t *testing.T) {
+	promiseSettler := hermesPromiseSettler{
+		currentState: map[identity.Identity]settlementState{},
+		gasPrice:     &mockGasPriceChecker{},
+		holds:        &mockSettlementHold{held: true},
+	}
+
+	mockSettler := func(crypto.Promise) (string, error) { return "", nil }
+	err := promiseSettler.settle(mockSettler, identity.Identity{}, common.Address{}, crypto.Promise{Amount: big.NewInt(35000)}, common.Address{}, big.NewInt(0), nil)
+	assert.ErrorIs(t, err, ErrSettlementHeld)
+	assert.False(t, promiseSettler.isSettling(identity.Identity{}, common.Address{}))
+}
+
 func TestPromiseSettler_RejectsIfFeesExceedMaxFee(t *testing.T) {
 	fac := &mockHermesCallerFactory{}
 	transactorFee := crypto.FloatToBigMyst(0.8)
@@ -343,6 +371,7 @@ func TestPromiseSettler_RejectsIfFeesExceedMaxFee(t *testing.T) {
 		},
 		hermesCallerFactory: fac.Get,
 		hermesURLGetter:     &mockHermesURLGetter{},
+		gasPrice:            &mockGasPriceChecker{},
 		bc: &mockProviderChannelStatusProvider{
 			calculatedFees: hermesFee,
 		},
@@ -404,6 +433,7 @@ func TestPromiseSettler_AcceptsIfFeesDoNotExceedSettlementAmount(t *testing.T) {
 			},
 		},
 		hermesCallerFactory: fac.Get,
+		gasPrice:            &mockGasPriceChecker{},
 		hermesURLGetter:     &mockHermesURLGetter{},
 		bc:                  bc,
 		channelProvider:     &mockHermesChannelProvider{},
@@ -432,6 +462,24 @@ func TestPromiseSettler_AcceptsIfFeesDoNotExceedSettlementAmount(t *testing.T) {
 	assert.True(t, ok)
 }
 
+func TestPromiseSettler_PostponesWhenGasPriceAboveCap(t *testing.T) {
+	errAboveCap := errors.New("gas price above cap")
+	settled := false
+	promiseSettler := hermesPromiseSettler{
+		currentState: map[identity.Identity]settlementState{},
+		gasPrice:     &mockGasPriceChecker{err: errAboveCap},
+	}
+
+	mockSettler := func(crypto.Promise) (string, error) {
+		settled = true
+		return "", nil
+	}
+	err := promiseSettler.settle(mockSettler, identity.Identity{}, common.Address{}, crypto.Promise{ChainID: 1}, common.Address{}, nil, nil)
+	assert.Equal(t, errAboveCap, err)
+	assert.False(t, settled)
+	assert.False(t, promiseSettler.isSettling(identity.Identity{}, common.Address{}))
+}
+
 func TestPromiseSettlerState_needsSettling(t *testing.T) {
 	hps := &hermesPromiseSettler{
 		transactor: &mockTransactor{
@@ -713,6 +761,14 @@ func (shsm *settlementHistoryStorageMock) Store(_ SettlementHistoryEntry) error
 	return nil
 }
 
+type mockGasPriceChecker struct {
+	err error
+}
+
+func (mgpc *mockGasPriceChecker) Check(_ int64) error {
+	return mgpc.err
+}
+
 type mockPayAndSettler struct{}
 
 func (mpas *mockPayAndSettler) PayAndSettle(r []byte, em crypto.ExchangeMessage, providerID identity.Identity, sessionID string) <-chan error {
@@ -35,7 +35,9 @@ import (
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/node/utils/clock"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
 
@@ -105,10 +107,20 @@ type InvoicePayerDeps struct {
 	HermesAddress             common.Address
 	DataLeeway                datasize.BitSize
 	ChainID                   int64
+	Clock                     clock.Clock
 }
 
 // NewInvoicePayer returns a new instance of exchange message tracker.
+// Session time runs on the system clock unless another one is given in deps.
 func NewInvoicePayer(ipd InvoicePayerDeps) *InvoicePayer {
+	if ipd.Clock == nil {
+		ipd.Clock = clock.System
+	}
+	if ipd.TimeTracker == nil {
+		timeTracker := session.NewClockTracker(ipd.Clock)
+		ipd.TimeTracker = &timeTracker
+	}
+
 	return &InvoicePayer{
 		stop: make(chan struct{}),
 		deps: ipd,
@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// negotiateInvoiceTerms fits the invoice terms requested by the consumer into the bounds configured by the provider.
+// Invoicing always starts at the starting charge period and unpaid invoice value and ramps up as the consumer pays,
+// requested terms only lower the limits the ramp grows to, so that a new consumer can not skip the ramp.
+// Requested data size is turned into the unpaid invoice value at the agreed price.
+// The deps are updated to follow the agreed terms, which are the limits the ramp grows to.
+func negotiateInvoiceTerms(requested market.InvoiceTerms, deps *InvoiceTrackerDeps) market.InvoiceTerms {
+	if requested.Period > 0 {
+		deps.LimitChargePeriod = clampDuration(requested.Period, deps.ChargePeriod, deps.LimitChargePeriod)
+	}
+
+	perGiB := deps.AgreedPrice.PricePerGiB
+	dataPriced := perGiB != nil && perGiB.Sign() > 0
+	if requested.DataBytes > 0 && dataPriced && deps.MaxNotPaidInvoice != nil {
+		value := CalculatePaymentAmount(0, DataTransferred{Down: requested.DataBytes}, market.Price{
+			PricePerHour: big.NewInt(0),
+			PricePerGiB:  perGiB,
+		})
+		deps.LimitNotPaidInvoice = new(big.Int).Set(clampBigInt(value, deps.MaxNotPaidInvoice, deps.LimitNotPaidInvoice))
+	}
+
+	agreed := market.InvoiceTerms{Period: deps.ChargePeriod}
+	if deps.LimitChargePeriod > agreed.Period {
+		agreed.Period = deps.LimitChargePeriod
+	}
+	if dataPriced && deps.MaxNotPaidInvoice != nil {
+		unpaid := deps.MaxNotPaidInvoice
+		if deps.LimitNotPaidInvoice != nil && deps.LimitNotPaidInvoice.Cmp(unpaid) > 0 {
+			unpaid = deps.LimitNotPaidInvoice
+		}
+		bytes := new(big.Int).Mul(unpaid, new(big.Int).SetUint64(datasize.GiB.Bytes()))
+		agreed.DataBytes = bytes.Div(bytes, perGiB).Uint64()
+	}
+	return agreed
+}
+
+func clampDuration(value, min, max time.Duration) time.Duration {
+	if max < min {
+		max = min
+	}
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+func clampBigInt(value, min, max *big.Int) *big.Int {
+	if max == nil || max.Cmp(min) < 0 {
+		max = min
+	}
+	if value.Cmp(min) < 0 {
+		return min
+	}
+	if value.Cmp(max) > 0 {
+		return max
+	}
+	return value
+}
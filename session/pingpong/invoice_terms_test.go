@@ -0,0 +1,136 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+func TestNegotiateInvoiceTerms(t *testing.T) {
+	newDeps := func() InvoiceTrackerDeps {
+		return InvoiceTrackerDeps{
+			AgreedPrice: market.Price{
+				PricePerHour: big.NewInt(0),
+				PricePerGiB:  big.NewInt(1000),
+			},
+			ChargePeriod:        5 * time.Second,
+			LimitChargePeriod:   5 * time.Minute,
+			MaxNotPaidInvoice:   big.NewInt(100),
+			LimitNotPaidInvoice: big.NewInt(1000),
+		}
+	}
+
+	for _, tc := range []struct {
+		name            string
+		requested       market.InvoiceTerms
+		expectedPeriod  time.Duration
+		expectedUnpaid  *big.Int
+		expectedDataGiB float64
+	}{
+		{
+			name:            "provider defaults",
+			requested:       market.InvoiceTerms{},
+			expectedPeriod:  5 * time.Minute,
+			expectedUnpaid:  big.NewInt(1000),
+			expectedDataGiB: 1,
+		},
+		{
+			name:            "within bounds",
+			requested:       market.InvoiceTerms{Period: time.Minute, DataBytes: datasize.GiB.Bytes() / 2},
+			expectedPeriod:  time.Minute,
+			expectedUnpaid:  big.NewInt(500),
+			expectedDataGiB: 0.5,
+		},
+		{
+			name:            "above bounds",
+			requested:       market.InvoiceTerms{Period: time.Hour, DataBytes: 10 * datasize.GiB.Bytes()},
+			expectedPeriod:  5 * time.Minute,
+			expectedUnpaid:  big.NewInt(1000),
+			expectedDataGiB: 1,
+		},
+		{
+			name:            "below bounds",
+			requested:       market.InvoiceTerms{Period: time.Second, DataBytes: datasize.MiB.Bytes()},
+			expectedPeriod:  5 * time.Second,
+			expectedUnpaid:  big.NewInt(100),
+			expectedDataGiB: 0.1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			deps := newDeps()
+			agreed := negotiateInvoiceTerms(tc.requested, &deps)
+
+			assert.Equal(t, tc.expectedPeriod, agreed.Period)
+			assert.Equal(t, tc.expectedPeriod, deps.LimitChargePeriod)
+			assert.Equal(t, tc.expectedUnpaid, deps.LimitNotPaidInvoice)
+			assert.InDelta(t, tc.expectedDataGiB*float64(datasize.GiB.Bytes()), float64(agreed.DataBytes), float64(datasize.MiB.Bytes()))
+
+			// Invoicing starts at the starting values regardless of the requested terms.
+			assert.Equal(t, 5*time.Second, deps.ChargePeriod)
+			assert.Equal(t, big.NewInt(100), deps.MaxNotPaidInvoice)
+		})
+	}
+}
+
+func TestNegotiateInvoiceTerms_RampStopsAtRequestedTerms(t *testing.T) {
+	deps := InvoiceTrackerDeps{
+		AgreedPrice: market.Price{
+			PricePerHour: big.NewInt(0),
+			PricePerGiB:  big.NewInt(1000),
+		},
+		ChargePeriod:        5 * time.Second,
+		LimitChargePeriod:   5 * time.Minute,
+		MaxNotPaidInvoice:   big.NewInt(100),
+		LimitNotPaidInvoice: big.NewInt(1000),
+	}
+	negotiateInvoiceTerms(market.InvoiceTerms{Period: 10 * time.Second, DataBytes: datasize.GiB.Bytes() / 2}, &deps)
+
+	tracker := &InvoiceTracker{deps: deps}
+	for i := 0; i < 10; i++ {
+		tracker.updateTimer()
+		tracker.updateMaxUnpaid()
+	}
+	assert.Equal(t, 10*time.Second, tracker.deps.ChargePeriod)
+	assert.Equal(t, big.NewInt(500), tracker.deps.MaxNotPaidInvoice)
+}
+
+func TestNegotiateInvoiceTerms_TimeOnlyPricing(t *testing.T) {
+	deps := InvoiceTrackerDeps{
+		AgreedPrice: market.Price{
+			PricePerHour: big.NewInt(1000),
+			PricePerGiB:  big.NewInt(0),
+		},
+		ChargePeriod:        5 * time.Second,
+		LimitChargePeriod:   5 * time.Minute,
+		MaxNotPaidInvoice:   big.NewInt(100),
+		LimitNotPaidInvoice: big.NewInt(1000),
+	}
+
+	agreed := negotiateInvoiceTerms(market.InvoiceTerms{DataBytes: datasize.GiB.Bytes()}, &deps)
+
+	assert.Equal(t, market.InvoiceTerms{Period: 5 * time.Minute}, agreed)
+	assert.Equal(t, big.NewInt(100), deps.MaxNotPaidInvoice)
+	assert.Equal(t, big.NewInt(1000), deps.LimitNotPaidInvoice)
+}
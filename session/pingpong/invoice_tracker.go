@@ -37,9 +37,12 @@ import (
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/metrics"
 	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/session"
 	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/node/utils/clock"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
 
@@ -69,6 +72,11 @@ var ErrConsumerNotRegistered = errors.New("consumer not registered")
 
 var providerFirstInvoiceValue = big.NewInt(1)
 
+var (
+	invoicesSent = metrics.NewCounter("payments_invoices_sent_total", "Invoices sent to consumers")
+	invoicesPaid = metrics.NewCounter("payments_invoices_paid_total", "Invoices paid by consumers")
+)
+
 // PeerInvoiceSender allows to send invoices.
 type PeerInvoiceSender interface {
 	Send(crypto.Invoice) error
@@ -126,6 +134,7 @@ type InvoiceTracker struct {
 	deps                           InvoiceTrackerDeps
 
 	dataTransferred     DataTransferred
+	dataSkipped         DataTransferred
 	dataTransferredLock sync.Mutex
 
 	criticalInvoiceErrors chan error
@@ -134,6 +143,22 @@ type InvoiceTracker struct {
 
 	lastExchangeMessage     crypto.ExchangeMessage
 	lastExchangeMessageLock sync.Mutex
+
+	invoiceTerms market.InvoiceTerms
+
+	freeTier        FreeTierAllowance
+	freeTierGranted bool
+	freeTierActive  bool
+	freeTierElapsed time.Duration
+	freeTierData    DataTransferred
+	freeTierSaved   FreeTierAllowance
+	freeTierLock    sync.Mutex
+}
+
+type freeTierTracker interface {
+	Reserve(chainID int64, consumer identity.Identity) (FreeTierAllowance, bool)
+	Consume(consumer identity.Identity, data uint64, duration time.Duration) error
+	Release(consumer identity.Identity, unused FreeTierAllowance)
 }
 
 // InvoiceTrackerDeps contains all the deps needed for invoice tracker.
@@ -161,12 +186,25 @@ type InvoiceTrackerDeps struct {
 	LimitNotPaidInvoice        *big.Int
 	MaxNotPaidInvoice          *big.Int
 	Observer                   observerApi
+	RequestedInvoiceTerms      market.InvoiceTerms
+	FreeTier                   freeTierTracker
+	Clock                      clock.Clock
 }
 
 // NewInvoiceTracker creates a new instance of invoice tracker.
+// Invoice timers and session time run on the system clock unless another one is given in deps.
 func NewInvoiceTracker(
 	itd InvoiceTrackerDeps,
 ) *InvoiceTracker {
+	terms := negotiateInvoiceTerms(itd.RequestedInvoiceTerms, &itd)
+	if itd.Clock == nil {
+		itd.Clock = clock.System
+	}
+	if itd.TimeTracker == nil {
+		timeTracker := session.NewClockTracker(itd.Clock)
+		itd.TimeTracker = &timeTracker
+	}
+
 	return &InvoiceTracker{
 		lastExchangeMessage: crypto.ExchangeMessage{
 			Promise: crypto.Promise{
@@ -185,9 +223,15 @@ func NewInvoiceTracker(
 		criticalInvoiceErrors:          make(chan error),
 		invoiceChannel:                 make(chan bool),
 		invoiceDebounceRate:            time.Second * 5,
+		invoiceTerms:                   terms,
 	}
 }
 
+// InvoiceTerms returns the invoice terms agreed for the session.
+func (it *InvoiceTracker) InvoiceTerms() market.InvoiceTerms {
+	return it.invoiceTerms
+}
+
 func calculateMaxNotReceivedExchangeMessageCount(chargeLeeway, chargePeriod time.Duration) uint64 {
 	return uint64(math.Round(float64(chargeLeeway) / float64(chargePeriod)))
 }
@@ -258,11 +302,18 @@ func (it *InvoiceTracker) handleExchangeMessage(em crypto.ExchangeMessage) error
 
 	it.saveLastExchangeMessage(em)
 	it.markInvoicePaid(em.Promise.Hashlock)
+	it.deps.EventBus.Publish(event.AppTopicExchangeMessageReceived, event.AppEventExchangeMessageReceived{
+		ProviderID:      it.deps.ProviderID,
+		ConsumerID:      it.deps.Peer,
+		SessionID:       it.deps.SessionID,
+		ExchangeMessage: em,
+	})
+	invoicesPaid.Inc()
 	it.resetNotReceivedExchangeMessageCount()
 	it.resetNotSentExchangeMessageCount()
 
 	// incase of zero payment, we'll just skip going to the hermes
-	if it.deps.AgreedPrice.IsFree() {
+	if it.deps.AgreedPrice.IsFree() || em.AgreementTotal.Sign() == 0 {
 		return nil
 	}
 
@@ -304,6 +355,7 @@ func (it *InvoiceTracker) Start() error {
 		return ErrHermesFeeTooLarge
 	}
 
+	it.reserveFreeTier()
 	it.generateAgreementID()
 
 	emErrors := make(chan error)
@@ -352,9 +404,9 @@ func (it *InvoiceTracker) sendInvoicesWhenNeeded(interval time.Duration) {
 		select {
 		case <-it.stop:
 			return
-		case <-time.After(interval):
+		case <-it.deps.Clock.After(interval):
 			currentlyElapsed := it.deps.TimeTracker.Elapsed()
-			shouldBe := CalculatePaymentAmount(currentlyElapsed, it.getDataTransferred(), it.deps.AgreedPrice)
+			shouldBe := it.amountDue(currentlyElapsed)
 			lastEM := it.getLastExchangeMessage()
 			diff := safeSub(shouldBe, lastEM.AgreementTotal)
 			if diff.Cmp(it.deps.MaxNotPaidInvoice) >= 0 && currentlyElapsed-it.lastInvoiceSent > it.invoiceDebounceRate {
@@ -406,11 +458,13 @@ func (it *InvoiceTracker) updateTimer() {
 
 // WaitFirstInvoice waits for a first invoice to be paid.
 func (it *InvoiceTracker) WaitFirstInvoice(wait time.Duration) error {
-	timeout := time.After(wait)
+	timeout := it.deps.Clock.After(wait)
+	ticker := it.deps.Clock.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
 
 	for {
 		select {
-		case <-time.After(10 * time.Millisecond):
+		case <-ticker.C():
 			it.invoiceLock.Lock()
 			paid := it.firstInvoicePaid
 			it.invoiceLock.Unlock()
@@ -492,7 +546,8 @@ func (it *InvoiceTracker) sendInvoice(isCritical bool) error {
 		return ErrExchangeWaitTimeout
 	}
 
-	shouldBe := CalculatePaymentAmount(it.deps.TimeTracker.Elapsed(), it.getDataTransferred(), it.deps.AgreedPrice)
+	shouldBe := it.amountDue(it.deps.TimeTracker.Elapsed())
+	it.consumeFreeTier()
 
 	lastEm := it.getLastExchangeMessage()
 	if lastEm.AgreementTotal.Cmp(big.NewInt(0)) == 0 && shouldBe.Cmp(big.NewInt(0)) == 1 {
@@ -509,6 +564,13 @@ func (it *InvoiceTracker) sendInvoice(isCritical bool) error {
 		return err
 	}
 
+	invoicesSent.Inc()
+	it.deps.EventBus.Publish(event.AppTopicInvoiceSent, event.AppEventInvoiceSent{
+		ProviderID: it.deps.ProviderID,
+		ConsumerID: it.deps.Peer,
+		SessionID:  it.deps.SessionID,
+		Invoice:    invoice,
+	})
 	it.markInvoiceSent(sentInvoice{
 		invoice:    invoice,
 		r:          r,
@@ -528,7 +590,7 @@ func (it *InvoiceTracker) sendInvoice(isCritical bool) error {
 
 func (it *InvoiceTracker) waitForInvoicePayment(hlock []byte) {
 	select {
-	case <-time.After(it.deps.ExchangeMessageWaitTimeout):
+	case <-it.deps.Clock.After(it.deps.ExchangeMessageWaitTimeout):
 		inv, ok := it.getMarkedInvoice(hlock)
 		if !ok {
 			return
@@ -676,11 +738,90 @@ func (it *InvoiceTracker) validateExchangeMessage(em crypto.ExchangeMessage) err
 func (it *InvoiceTracker) Stop() {
 	it.once.Do(func() {
 		log.Debug().Msgf("Stopping invoice tracker for session %s", it.deps.SessionID)
+		it.releaseFreeTier()
 		_ = it.deps.EventBus.UnsubscribeWithUID(sessionEvent.AppTopicDataTransferred, it.deps.SessionID, it.consumeDataTransferredEvent)
 		close(it.stop)
 	})
 }
 
+// amountDue returns the session value at the agreed price, not counting the free tier allowance.
+func (it *InvoiceTracker) amountDue(elapsed time.Duration) *big.Int {
+	elapsed, transferred := it.chargeable(elapsed, it.getDataTransferred())
+	return CalculatePaymentAmount(elapsed, transferred, it.deps.AgreedPrice)
+}
+
+func (it *InvoiceTracker) chargeable(elapsed time.Duration, transferred DataTransferred) (time.Duration, DataTransferred) {
+	it.freeTierLock.Lock()
+	defer it.freeTierLock.Unlock()
+
+	if it.freeTierActive {
+		timeUsed := it.freeTier.Duration > 0 && elapsed >= it.freeTier.Duration
+		dataUsed := it.freeTier.Data > 0 && transferred.sum() >= it.freeTier.Data
+		if !timeUsed && !dataUsed {
+			return 0, DataTransferred{}
+		}
+
+		log.Info().Msgf("Free tier allowance used up in session %s, invoicing starts", it.deps.SessionID)
+		it.freeTierActive = false
+		it.freeTierElapsed, it.freeTierData = elapsed, transferred
+	}
+
+	return elapsed - it.freeTierElapsed, DataTransferred{
+		Up:   transferred.Up - it.freeTierData.Up,
+		Down: transferred.Down - it.freeTierData.Down,
+	}
+}
+
+func (it *InvoiceTracker) reserveFreeTier() {
+	if it.deps.FreeTier == nil {
+		return
+	}
+
+	allowance, ok := it.deps.FreeTier.Reserve(it.deps.ChainID, it.deps.Peer)
+
+	it.freeTierLock.Lock()
+	defer it.freeTierLock.Unlock()
+
+	it.freeTier, it.freeTierGranted, it.freeTierActive = allowance, ok, ok
+}
+
+// consumeFreeTier saves the free tier usage since the last save, so it is not lost if the node goes down mid session.
+func (it *InvoiceTracker) consumeFreeTier() {
+	it.freeTierLock.Lock()
+	defer it.freeTierLock.Unlock()
+
+	if !it.freeTierGranted {
+		return
+	}
+
+	elapsed, transferred := it.freeTierElapsed, it.freeTierData
+	if it.freeTierActive {
+		elapsed, transferred = it.deps.TimeTracker.Elapsed(), it.getDataTransferred()
+	}
+	used := FreeTierAllowance{Data: transferred.sum(), Duration: elapsed}.sub(it.freeTierSaved)
+	if !used.enabled() {
+		return
+	}
+
+	if err := it.deps.FreeTier.Consume(it.deps.Peer, used.Data, used.Duration); err != nil {
+		log.Err(err).Msgf("Could not save free tier usage of consumer %s", it.deps.Peer.Address)
+		return
+	}
+	it.freeTierSaved = it.freeTierSaved.add(used)
+}
+
+func (it *InvoiceTracker) releaseFreeTier() {
+	it.consumeFreeTier()
+
+	it.freeTierLock.Lock()
+	defer it.freeTierLock.Unlock()
+
+	if !it.freeTierGranted {
+		return
+	}
+	it.deps.FreeTier.Release(it.deps.Peer, it.freeTier.sub(it.freeTierSaved))
+}
+
 func (it *InvoiceTracker) consumeDataTransferredEvent(e sessionEvent.AppEventDataTransferred) {
 	// skip irrelevant sessions
 	if !strings.EqualFold(e.ID, it.deps.SessionID) {
@@ -693,10 +834,22 @@ func (it *InvoiceTracker) consumeDataTransferredEvent(e sessionEvent.AppEventDat
 	it.updateDataTransfer(e.Down, e.Up)
 }
 
+// SkipDataTransferred leaves out of invoices the session traffic accounted before the tracker was created,
+// e.g. by the agreement of the session restored after node restart. Values are the ones of data transferred event.
+func (it *InvoiceTracker) SkipDataTransferred(up, down uint64) {
+	it.dataTransferredLock.Lock()
+	defer it.dataTransferredLock.Unlock()
+
+	it.dataSkipped = DataTransferred{Up: down, Down: up}
+}
+
 func (it *InvoiceTracker) updateDataTransfer(up, down uint64) {
 	it.dataTransferredLock.Lock()
 	defer it.dataTransferredLock.Unlock()
 
+	up = subtractSkipped(up, it.dataSkipped.Up)
+	down = subtractSkipped(down, it.dataSkipped.Down)
+
 	newUp := it.dataTransferred.Up
 	if up > it.dataTransferred.Up {
 		newUp = up
@@ -713,6 +866,13 @@ func (it *InvoiceTracker) updateDataTransfer(up, down uint64) {
 	}
 }
 
+func subtractSkipped(transferred, skipped uint64) uint64 {
+	if transferred < skipped {
+		return 0
+	}
+	return transferred - skipped
+}
+
 func (it *InvoiceTracker) getDataTransferred() DataTransferred {
 	it.dataTransferredLock.Lock()
 	defer it.dataTransferredLock.Unlock()
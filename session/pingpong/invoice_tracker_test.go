@@ -32,7 +32,9 @@ import (
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/mysteriumnetwork/node/session"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/session/mbtime"
+	"github.com/mysteriumnetwork/node/utils/clock"
 	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/mysteriumnetwork/payments/observer"
 	"github.com/pkg/errors"
@@ -554,6 +556,151 @@ func Test_sendsInvoiceIfDataUsed(t *testing.T) {
 
 }
 
+func Test_sendsInvoiceOnceChargePeriodElapses(t *testing.T) {
+	mockClock := clock.NewMock(time.Now())
+	deps := InvoiceTrackerDeps{
+		EventBus:            mocks.NewEventBus(),
+		AgreedPrice:         *market.NewPrice(600, 0),
+		MaxNotPaidInvoice:   big.NewInt(1e18),
+		ChargePeriod:        10 * time.Second,
+		LimitChargePeriod:   10 * time.Second,
+		LimitNotPaidInvoice: big.NewInt(0),
+		Clock:               mockClock,
+	}
+	invoiceTracker := NewInvoiceTracker(deps)
+	defer invoiceTracker.Stop()
+	invoiceTracker.deps.TimeTracker.StartTracking()
+	go invoiceTracker.sendInvoicesWhenNeeded(time.Second)
+
+	for period := 0; period < 2; period++ {
+		for i := 0; i < 10; i++ {
+			sent, _ := tickInvoiceTimer(mockClock, invoiceTracker, time.Second)
+			assert.False(t, sent, "invoice sent before charge period elapsed")
+		}
+		sent, critical := tickInvoiceTimer(mockClock, invoiceTracker, time.Second)
+		assert.True(t, sent, "invoice not sent after charge period elapsed")
+		assert.False(t, critical)
+	}
+}
+
+func Test_debouncesCriticalInvoices(t *testing.T) {
+	mockClock := clock.NewMock(time.Now())
+	deps := InvoiceTrackerDeps{
+		EventBus:          mocks.NewEventBus(),
+		AgreedPrice:       *market.NewPrice(600, 0),
+		MaxNotPaidInvoice: big.NewInt(0),
+		ChargePeriod:      time.Hour,
+		LimitChargePeriod: time.Hour,
+		Clock:             mockClock,
+	}
+	invoiceTracker := NewInvoiceTracker(deps)
+	defer invoiceTracker.Stop()
+	invoiceTracker.deps.TimeTracker.StartTracking()
+	go invoiceTracker.sendInvoicesWhenNeeded(time.Second)
+
+	for i := 0; i < 5; i++ {
+		sent, _ := tickInvoiceTimer(mockClock, invoiceTracker, time.Second)
+		assert.False(t, sent, "critical invoice sent within debounce period")
+	}
+	sent, critical := tickInvoiceTimer(mockClock, invoiceTracker, time.Second)
+	assert.True(t, sent)
+	assert.True(t, critical)
+}
+
+func Test_waitForInvoicePayment(t *testing.T) {
+	tests := []struct {
+		name        string
+		critical    bool
+		notReceived uint64
+	}{
+		{name: "unpaid invoice is counted as not received", critical: false, notReceived: 1},
+		{name: "unpaid critical invoice aborts session", critical: true, notReceived: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClock := clock.NewMock(time.Now())
+			deps := InvoiceTrackerDeps{
+				EventBus:                   mocks.NewEventBus(),
+				AgreedPrice:                *market.NewPrice(600, 0),
+				ExchangeMessageWaitTimeout: time.Minute,
+				Clock:                      mockClock,
+			}
+			invoiceTracker := NewInvoiceTracker(deps)
+			defer invoiceTracker.Stop()
+
+			hashlock := []byte{1, 2, 3}
+			invoiceTracker.markInvoiceSent(sentInvoice{invoice: crypto.Invoice{Hashlock: hex.EncodeToString(hashlock)}, isCritical: tt.critical})
+			done := make(chan struct{})
+			go func() {
+				invoiceTracker.waitForInvoicePayment(hashlock)
+				close(done)
+			}()
+
+			mockClock.BlockUntil(1)
+			mockClock.Add(time.Minute - time.Second)
+			assert.Equal(t, uint64(0), invoiceTracker.getNotReceivedExchangeMessageCount())
+
+			mockClock.Add(time.Second)
+			if tt.critical {
+				select {
+				case err := <-invoiceTracker.criticalInvoiceErrors:
+					assert.Error(t, err)
+				case <-time.After(2 * time.Second):
+					t.Fatal("unpaid critical invoice not reported")
+				}
+			}
+			<-done
+			assert.Equal(t, tt.notReceived, invoiceTracker.getNotReceivedExchangeMessageCount())
+		})
+	}
+}
+
+func Test_WaitFirstInvoice(t *testing.T) {
+	mockClock := clock.NewMock(time.Now())
+	invoiceTracker := NewInvoiceTracker(InvoiceTrackerDeps{
+		EventBus: mocks.NewEventBus(),
+		Clock:    mockClock,
+	})
+	defer invoiceTracker.Stop()
+
+	errs := make(chan error)
+	go func() { errs <- invoiceTracker.WaitFirstInvoice(time.Minute) }()
+	mockClock.BlockUntil(2)
+	invoiceTracker.markInvoicePaid([]byte{1})
+	mockClock.Add(10 * time.Millisecond)
+	assert.NoError(t, <-errs)
+
+	invoiceTracker = NewInvoiceTracker(InvoiceTrackerDeps{
+		EventBus: mocks.NewEventBus(),
+		Clock:    mockClock,
+	})
+	defer invoiceTracker.Stop()
+
+	go func() { errs <- invoiceTracker.WaitFirstInvoice(time.Minute) }()
+	mockClock.BlockUntil(2)
+	mockClock.Add(time.Minute)
+	assert.Error(t, <-errs)
+}
+
+// tickInvoiceTimer moves the clock once the invoice loop waits for it, returning whether an invoice was requested.
+func tickInvoiceTimer(c *clock.Mock, it *InvoiceTracker, d time.Duration) (sent, critical bool) {
+	c.BlockUntil(1)
+	c.Add(d)
+
+	waiting := make(chan struct{})
+	go func() {
+		c.BlockUntil(1)
+		close(waiting)
+	}()
+
+	select {
+	case critical = <-it.invoiceChannel:
+		return true, critical
+	case <-waiting:
+		return false, false
+	}
+}
+
 func Test_calculateMaxNotReceivedExchangeMessageCount(t *testing.T) {
 	res := calculateMaxNotReceivedExchangeMessageCount(time.Minute*5, time.Second*240)
 	assert.Equal(t, uint64(1), res)
@@ -926,6 +1073,20 @@ func TestInvoiceTracker_validateExchangeMessage(t *testing.T) {
 	}
 }
 
+func TestInvoiceTracker_SkipDataTransferred(t *testing.T) {
+	it := &InvoiceTracker{deps: InvoiceTrackerDeps{SessionID: "session1"}}
+	it.SkipDataTransferred(100, 1000)
+
+	it.consumeDataTransferredEvent(sessionEvent.AppEventDataTransferred{ID: "session1", Up: 50, Down: 400})
+	assert.Equal(t, DataTransferred{}, it.getDataTransferred())
+
+	it.consumeDataTransferredEvent(sessionEvent.AppEventDataTransferred{ID: "session1", Up: 150, Down: 1500})
+	assert.Equal(t, DataTransferred{Up: 500, Down: 50}, it.getDataTransferred())
+
+	it.consumeDataTransferredEvent(sessionEvent.AppEventDataTransferred{ID: "session2", Up: 5000, Down: 5000})
+	assert.Equal(t, DataTransferred{Up: 500, Down: 50}, it.getDataTransferred())
+}
+
 type mockHermesStatusChecker struct {
 	statusToReturn HermesStatus
 	errToReturn    error
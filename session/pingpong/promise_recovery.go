@@ -0,0 +1,267 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	pingEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/rs/zerolog/log"
+)
+
+// PromiseDiscrepancy describes how the locally stored hermes promise differs from the one hermes knows about.
+type PromiseDiscrepancy string
+
+const (
+	// PromiseConsistent means local storage and hermes agree on the latest promise.
+	PromiseConsistent PromiseDiscrepancy = "consistent"
+	// PromiseMissing means hermes has issued a promise that is not present in local storage.
+	PromiseMissing PromiseDiscrepancy = "missing"
+	// PromiseCorrupted means the locally stored promise can not be read or does not match its own hashlock.
+	PromiseCorrupted PromiseDiscrepancy = "corrupted"
+	// PromiseBehind means the locally stored promise is lower than the latest one issued by hermes.
+	PromiseBehind PromiseDiscrepancy = "behind"
+	// PromiseAhead means the locally stored promise is higher than the latest one hermes knows about.
+	PromiseAhead PromiseDiscrepancy = "ahead"
+)
+
+// PromiseAudit is the result of comparing local promise storage against hermes.
+type PromiseAudit struct {
+	ChainID      int64
+	Identity     identity.Identity
+	HermesID     common.Address
+	ChannelID    string
+	LocalAmount  *big.Int
+	HermesAmount *big.Int
+	Discrepancy  PromiseDiscrepancy
+	Detail       string
+	Recovered    bool
+}
+
+type recoveryPromiseStorage interface {
+	promiseProvider
+	Delete(promise HermesPromise) error
+}
+
+type recoveryHermesCaller interface {
+	hermesCaller
+	SyncProviderPromise(promise crypto.Promise, signer identity.Signer) error
+}
+
+// PromiseRecovery detects lost or corrupted hermes promises and restores them from hermes.
+type PromiseRecovery struct {
+	storage         recoveryPromiseStorage
+	hermesCaller    recoveryHermesCaller
+	addressProvider addressProvider
+	encryption      encryption
+	publisher       eventbus.Publisher
+	signer          identity.SignerFactory
+}
+
+// NewPromiseRecovery returns a new instance of promise recovery.
+func NewPromiseRecovery(storage recoveryPromiseStorage, hermesCaller recoveryHermesCaller, addressProvider addressProvider, encryption encryption, publisher eventbus.Publisher, signer identity.SignerFactory) *PromiseRecovery {
+	return &PromiseRecovery{
+		storage:         storage,
+		hermesCaller:    hermesCaller,
+		addressProvider: addressProvider,
+		encryption:      encryption,
+		publisher:       publisher,
+		signer:          signer,
+	}
+}
+
+// Audit compares the locally stored promise of the given identity with the latest promise known to the active hermes.
+func (pr *PromiseRecovery) Audit(chainID int64, id identity.Identity) (PromiseAudit, error) {
+	audit, _, err := pr.audit(chainID, id)
+	return audit, err
+}
+
+// Recover audits the promise of the given identity and repairs any discrepancy found.
+// Missing, corrupted or outdated promises are re-issued by hermes and stored locally,
+// while a local promise hermes has not seen yet is synced back to hermes.
+func (pr *PromiseRecovery) Recover(chainID int64, id identity.Identity) (PromiseAudit, error) {
+	audit, local, err := pr.audit(chainID, id)
+	if err != nil {
+		return audit, err
+	}
+
+	switch audit.Discrepancy {
+	case PromiseConsistent:
+		return audit, nil
+	case PromiseAhead:
+		if err := pr.hermesCaller.SyncProviderPromise(local.Promise, pr.signer(id)); err != nil {
+			return audit, fmt.Errorf("could not sync promise to hermes: %w", err)
+		}
+		audit.Recovered = true
+		return audit, nil
+	case PromiseCorrupted:
+		err := pr.storage.Delete(HermesPromise{ChannelID: audit.ChannelID, Promise: crypto.Promise{ChainID: chainID}})
+		if err != nil {
+			return audit, fmt.Errorf("could not delete corrupted promise: %w", err)
+		}
+		audit.LocalAmount = big.NewInt(0)
+		if audit.HermesAmount.Cmp(big.NewInt(0)) == 0 {
+			audit.Recovered = true
+			return audit, nil
+		}
+	}
+
+	promise, err := refreshProviderPromise(pr.hermesCaller, pr.storage, pr.encryption, pr.publisher, pr.signer(id), chainID, id, audit.HermesID, audit.ChannelID)
+	if err != nil {
+		return audit, err
+	}
+
+	log.Info().Msgf("Recovered %s hermes promise for %s, amount %v", audit.Discrepancy, id.Address, promise.Promise.Amount)
+	audit.LocalAmount = promise.Promise.Amount
+	audit.Recovered = true
+	return audit, nil
+}
+
+func (pr *PromiseRecovery) audit(chainID int64, id identity.Identity) (PromiseAudit, HermesPromise, error) {
+	audit := PromiseAudit{
+		ChainID:      chainID,
+		Identity:     id,
+		LocalAmount:  big.NewInt(0),
+		HermesAmount: big.NewInt(0),
+		Discrepancy:  PromiseConsistent,
+	}
+
+	hermesID, err := pr.addressProvider.GetActiveHermes(chainID)
+	if err != nil {
+		return audit, HermesPromise{}, fmt.Errorf("could not get active hermes: %w", err)
+	}
+	audit.HermesID = hermesID
+
+	channelID, err := crypto.GenerateProviderChannelID(id.Address, hermesID.Hex())
+	if err != nil {
+		return audit, HermesPromise{}, fmt.Errorf("could not generate provider channel id: %w", err)
+	}
+	audit.ChannelID = channelID
+
+	data, err := pr.hermesCaller.GetProviderData(chainID, id.Address)
+	if err != nil && !errors.Is(err, ErrHermesNotFound) {
+		return audit, HermesPromise{}, fmt.Errorf("could not get provider data from hermes: %w", err)
+	}
+	if data.LatestPromise.Amount != nil {
+		audit.HermesAmount = data.LatestPromise.Amount
+	}
+
+	local, err := pr.storage.Get(chainID, channelID)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		if audit.HermesAmount.Cmp(big.NewInt(0)) > 0 {
+			audit.Discrepancy = PromiseMissing
+		}
+		return audit, local, nil
+	case err != nil:
+		audit.Discrepancy = PromiseCorrupted
+		audit.Detail = err.Error()
+		return audit, local, nil
+	}
+
+	if local.Promise.Amount != nil {
+		audit.LocalAmount = local.Promise.Amount
+	}
+
+	if err := verifyStoredPromise(local, channelID); err != nil {
+		audit.Discrepancy = PromiseCorrupted
+		audit.Detail = err.Error()
+		return audit, local, nil
+	}
+
+	switch audit.LocalAmount.Cmp(audit.HermesAmount) {
+	case -1:
+		audit.Discrepancy = PromiseBehind
+	case 1:
+		audit.Discrepancy = PromiseAhead
+	}
+	return audit, local, nil
+}
+
+func verifyStoredPromise(promise HermesPromise, channelID string) error {
+	if promise.ChannelID != channelID {
+		return fmt.Errorf("promise stored under channel %v belongs to channel %v", channelID, promise.ChannelID)
+	}
+	if promise.R == "" {
+		return nil
+	}
+
+	r, err := hex.DecodeString(promise.R)
+	if err != nil {
+		return fmt.Errorf("could not decode R: %w", err)
+	}
+	if !bytes.Equal(ethcrypto.Keccak256(r), promise.Promise.Hashlock) {
+		return errors.New("R does not match promise hashlock")
+	}
+	return nil
+}
+
+// refreshProviderPromise asks hermes to re-issue the latest provider promise with a freshly generated R,
+// stores it and announces it so that channel earnings get recalculated.
+func refreshProviderPromise(caller hermesCaller, storage promiseProvider, enc encryption, publisher eventbus.Publisher, signer identity.Signer, chainID int64, id identity.Identity, hermesID common.Address, channelID string) (HermesPromise, error) {
+	R := crypto.GenerateR()
+	hashlock := ethcrypto.Keccak256(R)
+	details := rRecoveryDetails{
+		R:           hex.EncodeToString(R),
+		AgreementID: big.NewInt(0),
+	}
+
+	blob, err := json.Marshal(details)
+	if err != nil {
+		return HermesPromise{}, fmt.Errorf("could not marshal R recovery details: %w", err)
+	}
+
+	encrypted, err := enc.Encrypt(id.ToCommonAddress(), blob)
+	if err != nil {
+		return HermesPromise{}, fmt.Errorf("could not encrypt R: %w", err)
+	}
+
+	promise, err := caller.RefreshLatestProviderPromise(chainID, id.Address, hashlock, encrypted, signer)
+	if err != nil {
+		return HermesPromise{}, fmt.Errorf("failed to refresh promise: %w", err)
+	}
+
+	hermesPromise := HermesPromise{
+		R:         hex.EncodeToString(R),
+		ChannelID: channelID,
+		Identity:  id,
+		HermesID:  hermesID,
+		Promise:   promise,
+		Revealed:  false,
+	}
+	if err := storage.Store(hermesPromise); err != nil {
+		return HermesPromise{}, fmt.Errorf("could not store hermes promise: %w", err)
+	}
+
+	publisher.Publish(pingEvent.AppTopicHermesPromise, pingEvent.AppEventHermesPromise{
+		Promise:    promise,
+		HermesID:   hermesID,
+		ProviderID: id,
+	})
+	return hermesPromise, nil
+}
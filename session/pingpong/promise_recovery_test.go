@@ -0,0 +1,151 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockRecoveryHermesCaller struct {
+	mockHermesCaller
+	latest crypto.Promise
+	synced *crypto.Promise
+}
+
+func (m *mockRecoveryHermesCaller) GetProviderData(chainID int64, id string) (HermesUserInfo, error) {
+	return HermesUserInfo{LatestPromise: LatestPromise{Amount: m.latest.Amount}}, nil
+}
+
+func (m *mockRecoveryHermesCaller) RefreshLatestProviderPromise(chainID int64, id string, hashlock, recoveryData []byte, signer identity.Signer) (crypto.Promise, error) {
+	promise := m.latest
+	promise.Hashlock = hashlock
+	return promise, nil
+}
+
+func (m *mockRecoveryHermesCaller) SyncProviderPromise(promise crypto.Promise, signer identity.Signer) error {
+	m.synced = &promise
+	return nil
+}
+
+func newTestPromiseRecovery(t *testing.T, caller *mockRecoveryHermesCaller) (*PromiseRecovery, *HermesPromiseStorage, func()) {
+	dir, err := ioutil.TempDir("", "promiseRecoveryTest")
+	assert.NoError(t, err)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+
+	storage := NewHermesPromiseStorage(bolt)
+	recovery := NewPromiseRecovery(storage, caller, &mockAddressProvider{}, &mockEncryptor{}, mocks.NewEventBus(), signerFactory)
+	return recovery, storage, func() {
+		bolt.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestPromiseRecovery_RecoversMissingPromise(t *testing.T) {
+	caller := &mockRecoveryHermesCaller{latest: crypto.Promise{ChainID: 1, Amount: big.NewInt(100)}}
+	recovery, storage, cleanup := newTestPromiseRecovery(t, caller)
+	defer cleanup()
+
+	id := identity.FromAddress("0x44440954558C5bFA0D4153B0002B1d1E3E3f5Ff5")
+
+	audit, err := recovery.Audit(1, id)
+	assert.NoError(t, err)
+	assert.Equal(t, PromiseMissing, audit.Discrepancy)
+	assert.Equal(t, big.NewInt(0), audit.LocalAmount)
+	assert.Equal(t, big.NewInt(100), audit.HermesAmount)
+
+	audit, err = recovery.Recover(1, id)
+	assert.NoError(t, err)
+	assert.True(t, audit.Recovered)
+	assert.Equal(t, big.NewInt(100), audit.LocalAmount)
+
+	stored, err := storage.Get(1, audit.ChannelID)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(100), stored.Promise.Amount)
+
+	audit, err = recovery.Audit(1, id)
+	assert.NoError(t, err)
+	assert.Equal(t, PromiseConsistent, audit.Discrepancy)
+}
+
+func TestPromiseRecovery_ReplacesCorruptedPromise(t *testing.T) {
+	caller := &mockRecoveryHermesCaller{latest: crypto.Promise{ChainID: 1, Amount: big.NewInt(100)}}
+	recovery, storage, cleanup := newTestPromiseRecovery(t, caller)
+	defer cleanup()
+
+	id := identity.FromAddress("0x44440954558C5bFA0D4153B0002B1d1E3E3f5Ff5")
+	channelID, err := crypto.GenerateProviderChannelID(id.Address, common.Address{}.Hex())
+	assert.NoError(t, err)
+
+	err = storage.Store(HermesPromise{
+		ChannelID: channelID,
+		Identity:  id,
+		Promise:   crypto.Promise{ChainID: 1, Amount: big.NewInt(500), Hashlock: []byte("not a hashlock")},
+		R:         hex.EncodeToString([]byte("some r")),
+	})
+	assert.NoError(t, err)
+
+	audit, err := recovery.Audit(1, id)
+	assert.NoError(t, err)
+	assert.Equal(t, PromiseCorrupted, audit.Discrepancy)
+	assert.Equal(t, big.NewInt(500), audit.LocalAmount)
+
+	audit, err = recovery.Recover(1, id)
+	assert.NoError(t, err)
+	assert.True(t, audit.Recovered)
+
+	stored, err := storage.Get(1, channelID)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(100), stored.Promise.Amount)
+	r, err := hex.DecodeString(stored.R)
+	assert.NoError(t, err)
+	assert.Equal(t, ethcrypto.Keccak256(r), stored.Promise.Hashlock)
+}
+
+func TestPromiseRecovery_SyncsPromiseAheadOfHermes(t *testing.T) {
+	caller := &mockRecoveryHermesCaller{latest: crypto.Promise{ChainID: 1, Amount: big.NewInt(100)}}
+	recovery, storage, cleanup := newTestPromiseRecovery(t, caller)
+	defer cleanup()
+
+	id := identity.FromAddress("0x44440954558C5bFA0D4153B0002B1d1E3E3f5Ff5")
+	channelID, err := crypto.GenerateProviderChannelID(id.Address, common.Address{}.Hex())
+	assert.NoError(t, err)
+
+	local := crypto.Promise{ChainID: 1, Amount: big.NewInt(200)}
+	err = storage.Store(HermesPromise{ChannelID: channelID, Identity: id, Promise: local})
+	assert.NoError(t, err)
+
+	audit, err := recovery.Recover(1, id)
+	assert.NoError(t, err)
+	assert.Equal(t, PromiseAhead, audit.Discrepancy)
+	assert.True(t, audit.Recovered)
+	assert.Equal(t, &local, caller.synced)
+}
@@ -79,6 +79,11 @@ func (shs *SettlementHistoryStorage) Store(she SettlementHistoryEntry) error {
 	return shs.bolt.DB().From(settlementHistoryBucket).Save(&she)
 }
 
+// Prune removes entries settled before the given time and returns their count.
+func (shs *SettlementHistoryStorage) Prune(before time.Time) (int, error) {
+	return shs.bolt.DeleteBefore(settlementHistoryBucket, "Time", before, &SettlementHistoryEntry{})
+}
+
 // SettlementHistoryFilter defines all flags for filtering in settlement history storage.
 type SettlementHistoryFilter struct {
 	TimeFrom   *time.Time
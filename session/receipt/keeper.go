@@ -0,0 +1,282 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package receipt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/session"
+	session_event "github.com/mysteriumnetwork/node/session/event"
+	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+// minBytesTolerance is the smallest allowed difference between traffic counted by both parties,
+// peers count traffic independently and the last statistics of a session might be not reported yet.
+const minBytesTolerance = 1024 * 1024
+
+// bytesTolerancePercent is the allowed difference between traffic counted by both parties.
+const bytesTolerancePercent = 10
+
+var (
+	// ErrUnknownSession is returned when receipt refers to a session not observed by this node.
+	ErrUnknownSession = errors.New("unknown session")
+	// ErrAmountMismatch is returned when receipt claims more tokens than were received by provider.
+	ErrAmountMismatch = errors.New("amount exceeds tokens received")
+	// ErrTrafficMismatch is returned when receipt traffic differs from the one counted by provider.
+	ErrTrafficMismatch = errors.New("traffic differs from observed")
+	// ErrReceiptAltered is returned when provider countersigned a different receipt.
+	ErrReceiptAltered = errors.New("receipt altered by provider")
+)
+
+// observed is a session view of this node, traffic is always from the consumer perspective.
+type observed struct {
+	amount        *big.Int
+	bytesSent     uint64
+	bytesReceived uint64
+}
+
+// Keeper exchanges, verifies and stores mutually signed session receipts.
+type Keeper struct {
+	storage       *Storage
+	signerFactory identity.SignerFactory
+
+	mu       sync.Mutex
+	sessions map[session.ID]*observed
+}
+
+// NewKeeper returns a new instance of the receipt Keeper.
+func NewKeeper(storage *Storage, signerFactory identity.SignerFactory) *Keeper {
+	return &Keeper{
+		storage:       storage,
+		signerFactory: signerFactory,
+		sessions:      make(map[session.ID]*observed),
+	}
+}
+
+// Subscribe subscribes to session events of both consumer and provider sides.
+func (k *Keeper) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.Subscribe(connectionstate.AppTopicConnectionSession, k.consumeConnectionSessionEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(pingpong_event.AppTopicInvoicePaid, k.consumeInvoicePaidEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(session_event.AppTopicSession, k.consumeServiceSessionEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(session_event.AppTopicTokensEarned, k.consumeTokensEarnedEvent); err != nil {
+		return err
+	}
+	return bus.Subscribe(session_event.AppTopicDataTransferred, k.consumeDataTransferredEvent)
+}
+
+// Get returns the receipt of a given session.
+func (k *Keeper) Get(sessionID session.ID) (Receipt, error) {
+	return k.storage.Get(sessionID)
+}
+
+// List returns all stored receipts, the newest first.
+func (k *Keeper) List() ([]Receipt, error) {
+	return k.storage.List()
+}
+
+// Exchange signs the receipt of a consumed session, sends it to provider for countersigning
+// and stores the mutually signed result.
+func (k *Keeper) Exchange(ctx context.Context, ch p2p.ChannelSender, r Receipt) (Receipt, error) {
+	r.StartedAt = r.StartedAt.UTC().Truncate(time.Second)
+	r.EndedAt = r.EndedAt.UTC().Truncate(time.Second)
+	r.Amount = new(big.Int)
+	if o, ok := k.observed(r.SessionID); ok && o.amount != nil {
+		r.Amount.Set(o.amount)
+	}
+
+	if err := r.SignAsConsumer(k.signerFactory(r.ConsumerID)); err != nil {
+		return Receipt{}, err
+	}
+
+	msg, err := EncodeMessage(r)
+	if err != nil {
+		return Receipt{}, err
+	}
+	res, err := ch.Send(ctx, p2p.TopicSessionReceipt, msg)
+	if err != nil {
+		return Receipt{}, fmt.Errorf("could not send receipt: %w", err)
+	}
+
+	signed, err := DecodeMessage(res)
+	if err != nil {
+		return Receipt{}, err
+	}
+	if !bytes.Equal(signed.Message(), r.Message()) {
+		return Receipt{}, ErrReceiptAltered
+	}
+	signed.ConsumerSignature = r.ConsumerSignature
+	if err := signed.Verify(); err != nil {
+		return Receipt{}, err
+	}
+
+	if err := k.storage.Store(signed); err != nil {
+		return Receipt{}, fmt.Errorf("could not store receipt: %w", err)
+	}
+	return signed, nil
+}
+
+// Countersign verifies the receipt of a provided session against the session observed
+// by this node, signs it as provider and stores the mutually signed result.
+func (k *Keeper) Countersign(r Receipt) (Receipt, error) {
+	if err := r.VerifyConsumer(); err != nil {
+		return Receipt{}, err
+	}
+
+	o, ok := k.observed(r.SessionID)
+	if !ok {
+		return Receipt{}, ErrUnknownSession
+	}
+	if r.Amount == nil || r.Amount.Sign() < 0 {
+		return Receipt{}, ErrAmountMismatch
+	}
+	earned := o.amount
+	if earned == nil {
+		earned = new(big.Int)
+	}
+	if r.Amount.Cmp(earned) > 0 {
+		return Receipt{}, ErrAmountMismatch
+	}
+	if !withinTolerance(r.BytesSent, o.bytesSent) || !withinTolerance(r.BytesReceived, o.bytesReceived) {
+		return Receipt{}, ErrTrafficMismatch
+	}
+
+	if err := r.SignAsProvider(k.signerFactory(r.ProviderID)); err != nil {
+		return Receipt{}, err
+	}
+	if err := k.storage.Store(r); err != nil {
+		return Receipt{}, fmt.Errorf("could not store receipt: %w", err)
+	}
+	return r, nil
+}
+
+func (k *Keeper) observed(sessionID session.ID) (observed, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	o, ok := k.sessions[sessionID]
+	if !ok {
+		return observed{}, false
+	}
+	return *o, true
+}
+
+func (k *Keeper) update(sessionID session.ID, fn func(o *observed)) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if o, ok := k.sessions[sessionID]; ok {
+		fn(o)
+	}
+}
+
+func (k *Keeper) track(sessionID session.ID, active bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if active {
+		k.sessions[sessionID] = &observed{}
+	} else {
+		delete(k.sessions, sessionID)
+	}
+}
+
+func (k *Keeper) consumeConnectionSessionEvent(e connectionstate.AppEventConnectionSession) {
+	switch e.Status {
+	case connectionstate.SessionCreatedStatus:
+		k.track(e.SessionInfo.SessionID, true)
+	case connectionstate.SessionEndedStatus:
+		k.track(e.SessionInfo.SessionID, false)
+	}
+}
+
+func (k *Keeper) consumeInvoicePaidEvent(e pingpong_event.AppEventInvoicePaid) {
+	k.update(session.ID(e.SessionID), func(o *observed) {
+		o.amount = e.Invoice.AgreementTotal
+	})
+}
+
+func (k *Keeper) consumeServiceSessionEvent(e session_event.AppEventSession) {
+	switch e.Status {
+	case session_event.CreatedStatus:
+		k.track(session.ID(e.Session.ID), true)
+	case session_event.RemovedStatus:
+		k.track(session.ID(e.Session.ID), false)
+	}
+}
+
+func (k *Keeper) consumeTokensEarnedEvent(e session_event.AppEventTokensEarned) {
+	k.update(session.ID(e.SessionID), func(o *observed) {
+		o.amount = e.Total
+	})
+}
+
+func (k *Keeper) consumeDataTransferredEvent(e session_event.AppEventDataTransferred) {
+	k.update(session.ID(e.ID), func(o *observed) {
+		// Provider sends Up traffic to consumer and receives Down traffic from it.
+		o.bytesSent = e.Down
+		o.bytesReceived = e.Up
+	})
+}
+
+func withinTolerance(claimed, counted uint64) bool {
+	diff := claimed - counted
+	if counted > claimed {
+		diff = counted - claimed
+	}
+
+	tolerance := counted * bytesTolerancePercent / 100
+	if tolerance < minBytesTolerance {
+		tolerance = minBytesTolerance
+	}
+	return diff <= tolerance
+}
+
+// EncodeMessage returns p2p message carrying the receipt.
+func EncodeMessage(r Receipt) (*p2p.Message, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode receipt: %w", err)
+	}
+	return &p2p.Message{Data: data}, nil
+}
+
+// DecodeMessage returns the receipt carried by p2p message.
+func DecodeMessage(msg *p2p.Message) (Receipt, error) {
+	var r Receipt
+	if err := json.Unmarshal(msg.Data, &r); err != nil {
+		return Receipt{}, fmt.Errorf("could not decode receipt: %w", err)
+	}
+	return r, nil
+}
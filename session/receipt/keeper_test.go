@@ -0,0 +1,170 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package receipt
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p"
+	session_event "github.com/mysteriumnetwork/node/session/event"
+	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/payments/crypto"
+)
+
+type countersigningChannel struct {
+	provider *Keeper
+}
+
+func (ch *countersigningChannel) Send(_ context.Context, topic string, msg *p2p.Message) (*p2p.Message, error) {
+	r, err := DecodeMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := ch.provider.Countersign(r)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeMessage(signed)
+}
+
+func TestKeeper_ExchangeStoresMutuallySignedReceipt(t *testing.T) {
+	signerFactory, consumerID, providerID := newIdentities(t)
+	consumer := NewKeeper(newTestStorage(t), signerFactory)
+	provider := NewKeeper(newTestStorage(t), signerFactory)
+
+	consumer.consumeConnectionSessionEvent(connectionstate.AppEventConnectionSession{
+		Status:      connectionstate.SessionCreatedStatus,
+		SessionInfo: connectionstate.Status{SessionID: "session-1"},
+	})
+	consumer.consumeInvoicePaidEvent(pingpong_event.AppEventInvoicePaid{
+		SessionID: "session-1",
+		Invoice:   crypto.Invoice{AgreementTotal: big.NewInt(500)},
+	})
+	provider.consumeServiceSessionEvent(session_event.AppEventSession{
+		Status:  session_event.CreatedStatus,
+		Session: session_event.SessionContext{ID: "session-1"},
+	})
+	provider.consumeTokensEarnedEvent(session_event.AppEventTokensEarned{SessionID: "session-1", Total: big.NewInt(500)})
+	provider.consumeDataTransferredEvent(session_event.AppEventDataTransferred{ID: "session-1", Up: 20_000_000, Down: 2_000_000})
+
+	started := time.Now().Add(-time.Hour)
+	signed, err := consumer.Exchange(context.Background(), &countersigningChannel{provider: provider}, Receipt{
+		SessionID:     "session-1",
+		ConsumerID:    consumerID,
+		ProviderID:    providerID,
+		ServiceType:   "wireguard",
+		StartedAt:     started,
+		EndedAt:       started.Add(time.Hour),
+		BytesSent:     2_100_000,
+		BytesReceived: 21_000_000,
+	})
+	require.NoError(t, err)
+	assert.NoError(t, signed.Verify())
+	assert.Equal(t, big.NewInt(500), signed.Amount)
+	assert.Equal(t, time.Hour, signed.Duration())
+
+	stored, err := consumer.Get("session-1")
+	require.NoError(t, err)
+	assert.Equal(t, signed.Message(), stored.Message())
+	assert.NoError(t, stored.Verify())
+
+	stored, err = provider.Get("session-1")
+	require.NoError(t, err)
+	assert.NoError(t, stored.Verify())
+
+	list, err := provider.List()
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+}
+
+func TestKeeper_CountersignRejectsMismatches(t *testing.T) {
+	signerFactory, consumerID, providerID := newIdentities(t)
+	provider := NewKeeper(newTestStorage(t), signerFactory)
+	provider.consumeServiceSessionEvent(session_event.AppEventSession{
+		Status:  session_event.CreatedStatus,
+		Session: session_event.SessionContext{ID: "session-1"},
+	})
+	provider.consumeTokensEarnedEvent(session_event.AppEventTokensEarned{SessionID: "session-1", Total: big.NewInt(500)})
+	provider.consumeDataTransferredEvent(session_event.AppEventDataTransferred{ID: "session-1", Up: 100_000_000, Down: 2_000_000})
+
+	signed := func(r Receipt) Receipt {
+		r.ConsumerID, r.ProviderID = consumerID, providerID
+		require.NoError(t, r.SignAsConsumer(signerFactory(consumerID)))
+		return r
+	}
+
+	_, err := provider.Countersign(signed(Receipt{SessionID: "unknown", Amount: big.NewInt(1)}))
+	assert.ErrorIs(t, err, ErrUnknownSession)
+
+	_, err = provider.Countersign(signed(Receipt{SessionID: "session-1", Amount: big.NewInt(501), BytesSent: 2_000_000, BytesReceived: 100_000_000}))
+	assert.ErrorIs(t, err, ErrAmountMismatch)
+
+	_, err = provider.Countersign(signed(Receipt{SessionID: "session-1", Amount: big.NewInt(500), BytesSent: 2_000_000, BytesReceived: 50_000_000}))
+	assert.ErrorIs(t, err, ErrTrafficMismatch)
+
+	forged := signed(Receipt{SessionID: "session-1", Amount: big.NewInt(500), BytesSent: 2_000_000, BytesReceived: 100_000_000})
+	forged.Amount = big.NewInt(400)
+	_, err = provider.Countersign(forged)
+	assert.ErrorIs(t, err, ErrInvalidConsumerSignature)
+
+	provider.consumeServiceSessionEvent(session_event.AppEventSession{
+		Status:  session_event.RemovedStatus,
+		Session: session_event.SessionContext{ID: "session-1"},
+	})
+	_, err = provider.Countersign(signed(Receipt{SessionID: "session-1", Amount: big.NewInt(500), BytesSent: 2_000_000, BytesReceived: 100_000_000}))
+	assert.ErrorIs(t, err, ErrUnknownSession)
+}
+
+func newIdentities(t *testing.T) (identity.SignerFactory, identity.Identity, identity.Identity) {
+	ks := identity.NewMockKeystore()
+	newIdentity := func() identity.Identity {
+		acc, err := ks.NewAccount("")
+		require.NoError(t, err)
+		require.NoError(t, ks.Unlock(acc, ""))
+		return identity.FromAddress(acc.Address.Hex())
+	}
+
+	signerFactory := func(id identity.Identity) identity.Signer {
+		return identity.NewSigner(ks, id)
+	}
+	return signerFactory, newIdentity(), newIdentity()
+}
+
+func newTestStorage(t *testing.T) *Storage {
+	dir, err := os.MkdirTemp("", "receiptStorageTest")
+	require.NoError(t, err)
+
+	db, err := boltdb.NewStorage(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+		os.RemoveAll(dir)
+	})
+
+	return NewStorage(db)
+}
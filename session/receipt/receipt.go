@@ -0,0 +1,123 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package receipt
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session"
+)
+
+var (
+	// ErrInvalidConsumerSignature is returned when receipt is not signed by its consumer.
+	ErrInvalidConsumerSignature = errors.New("invalid consumer signature")
+	// ErrInvalidProviderSignature is returned when receipt is not signed by its provider.
+	ErrInvalidProviderSignature = errors.New("invalid provider signature")
+)
+
+// Receipt is a summary of a finished session signed by both its consumer and provider.
+type Receipt struct {
+	SessionID   session.ID        `json:"session_id" storm:"id"`
+	ConsumerID  identity.Identity `json:"consumer_id"`
+	ProviderID  identity.Identity `json:"provider_id"`
+	ServiceType string            `json:"service_type"`
+	StartedAt   time.Time         `json:"started_at"`
+	EndedAt     time.Time         `json:"ended_at"`
+	// BytesSent is traffic sent by consumer to provider.
+	BytesSent uint64 `json:"bytes_sent"`
+	// BytesReceived is traffic received by consumer from provider.
+	BytesReceived uint64   `json:"bytes_received"`
+	Amount        *big.Int `json:"amount"`
+
+	ConsumerSignature string `json:"consumer_signature,omitempty"`
+	ProviderSignature string `json:"provider_signature,omitempty"`
+}
+
+// Duration returns duration of the session.
+func (r Receipt) Duration() time.Duration {
+	return r.EndedAt.Sub(r.StartedAt)
+}
+
+// Message returns the canonical representation of receipt fields covered by signatures.
+func (r Receipt) Message() []byte {
+	amount := "0"
+	if r.Amount != nil {
+		amount = r.Amount.String()
+	}
+
+	return []byte(fmt.Sprintf("session-receipt|%s|%s|%s|%s|%d|%d|%d|%d|%s",
+		r.SessionID,
+		r.ConsumerID.Address,
+		r.ProviderID.Address,
+		r.ServiceType,
+		r.StartedAt.Unix(),
+		r.EndedAt.Unix(),
+		r.BytesSent,
+		r.BytesReceived,
+		amount,
+	))
+}
+
+// SignAsConsumer signs the receipt on behalf of its consumer.
+func (r *Receipt) SignAsConsumer(signer identity.Signer) error {
+	signature, err := signer.Sign(r.Message())
+	if err != nil {
+		return fmt.Errorf("could not sign receipt: %w", err)
+	}
+	r.ConsumerSignature = hex.EncodeToString(signature.Bytes())
+	return nil
+}
+
+// SignAsProvider signs the receipt on behalf of its provider.
+func (r *Receipt) SignAsProvider(signer identity.Signer) error {
+	signature, err := signer.Sign(r.Message())
+	if err != nil {
+		return fmt.Errorf("could not sign receipt: %w", err)
+	}
+	r.ProviderSignature = hex.EncodeToString(signature.Bytes())
+	return nil
+}
+
+// VerifyConsumer checks that receipt is signed by its consumer.
+func (r Receipt) VerifyConsumer() error {
+	if ok, _ := identity.NewVerifierIdentity(r.ConsumerID).Verify(r.Message(), identity.SignatureHex(r.ConsumerSignature)); !ok {
+		return ErrInvalidConsumerSignature
+	}
+	return nil
+}
+
+// VerifyProvider checks that receipt is signed by its provider.
+func (r Receipt) VerifyProvider() error {
+	if ok, _ := identity.NewVerifierIdentity(r.ProviderID).Verify(r.Message(), identity.SignatureHex(r.ProviderSignature)); !ok {
+		return ErrInvalidProviderSignature
+	}
+	return nil
+}
+
+// Verify checks that receipt is signed by both parties.
+func (r Receipt) Verify() error {
+	if err := r.VerifyConsumer(); err != nil {
+		return err
+	}
+	return r.VerifyProvider()
+}
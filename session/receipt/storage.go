@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package receipt
+
+import (
+	"errors"
+	"time"
+
+	"github.com/asdine/storm/v3"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/session"
+)
+
+const receiptsBucket = "session-receipts"
+
+// ErrNotFound is returned when there is no receipt for the session.
+var ErrNotFound = errors.New("receipt not found")
+
+// Storage stores mutually signed session receipts.
+type Storage struct {
+	bolt *boltdb.Bolt
+}
+
+// NewStorage returns a new instance of the receipt Storage.
+func NewStorage(bolt *boltdb.Bolt) *Storage {
+	return &Storage{bolt: bolt}
+}
+
+// Store stores a given receipt.
+func (s *Storage) Store(r Receipt) error {
+	s.bolt.Lock()
+	defer s.bolt.Unlock()
+	return s.bolt.DB().From(receiptsBucket).Save(&r)
+}
+
+// Get returns the receipt of a given session.
+func (s *Storage) Get(sessionID session.ID) (Receipt, error) {
+	s.bolt.RLock()
+	defer s.bolt.RUnlock()
+
+	var r Receipt
+	err := s.bolt.DB().From(receiptsBucket).One("SessionID", sessionID, &r)
+	if errors.Is(err, storm.ErrNotFound) {
+		return Receipt{}, ErrNotFound
+	}
+	return r, err
+}
+
+// Prune removes receipts of sessions started before the given time and returns their count.
+func (s *Storage) Prune(before time.Time) (int, error) {
+	return s.bolt.DeleteBefore(receiptsBucket, "StartedAt", before, &Receipt{})
+}
+
+// List returns all stored receipts, the newest first.
+func (s *Storage) List() (result []Receipt, err error) {
+	s.bolt.RLock()
+	defer s.bolt.RUnlock()
+
+	err = s.bolt.DB().
+		From(receiptsBucket).
+		Select().
+		OrderBy("StartedAt").
+		Reverse().
+		Find(&result)
+	if errors.Is(err, storm.ErrNotFound) {
+		return []Receipt{}, nil
+	}
+	return result, err
+}
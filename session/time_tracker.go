@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/mysteriumnetwork/node/session/mbtime"
+	"github.com/mysteriumnetwork/node/utils/clock"
 )
 
 // TimeTracker tracks elapsed time from the beginning of the session
@@ -38,6 +39,18 @@ func NewTracker(getTime func() mbtime.Time) TimeTracker {
 	}
 }
 
+// NewClockTracker initializes TimeTracker measuring time of the given clock.
+// Suspend-aware monotonic time is used for the system clock.
+func NewClockTracker(c clock.Clock) TimeTracker {
+	if c == clock.System {
+		return NewTracker(mbtime.Now)
+	}
+	return NewTracker(func() mbtime.Time {
+		t := c.Now()
+		return mbtime.New(t.Unix(), int64(t.Nanosecond()))
+	})
+}
+
 // StartTracking starts tracking the time
 func (tt *TimeTracker) StartTracking() {
 	tt.started = true
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/mysteriumnetwork/node/session/mbtime"
+	"github.com/mysteriumnetwork/node/utils/clock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -46,6 +47,16 @@ func Test_ElapsedReturnsCorrectValue(t *testing.T) {
 	assert.Equal(t, 3*time.Second, elapsed)
 }
 
+func Test_ClockTrackerMeasuresClockTime(t *testing.T) {
+	mockClock := clock.NewMock(time.Now())
+	tt := NewClockTracker(mockClock)
+
+	tt.StartTracking()
+	mockClock.Add(3 * time.Second)
+
+	assert.Equal(t, 3*time.Second, tt.Elapsed())
+}
+
 func newMockedTime(timeValues []mbtime.Time) func() mbtime.Time {
 	count := 0
 
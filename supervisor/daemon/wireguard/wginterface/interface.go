@@ -24,10 +24,10 @@ import (
 	"strings"
 
 	"github.com/rs/zerolog/log"
-	"golang.zx2c4.com/wireguard/conn"
 	"golang.zx2c4.com/wireguard/device"
 
 	"github.com/mysteriumnetwork/node/services/wireguard/connection/dns"
+	"github.com/mysteriumnetwork/node/services/wireguard/wgbind"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
 	"github.com/mysteriumnetwork/node/utils/netutil"
 )
@@ -42,7 +42,12 @@ type WgInterface struct {
 
 // New creates new WgInterface instance.
 func New(cfg wgcfg.DeviceConfig, uid string) (*WgInterface, error) {
-	tunnel, interfaceName, err := createTunnel(cfg.IfaceName, cfg.DNS)
+	mtu := cfg.MTU
+	if mtu <= 0 {
+		mtu = device.DefaultMTU
+	}
+
+	tunnel, interfaceName, err := createTunnel(cfg.IfaceName, cfg.DNS, mtu)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create TUN device %s: %w", cfg.IfaceName, err)
 	}
@@ -51,7 +56,7 @@ func New(cfg wgcfg.DeviceConfig, uid string) (*WgInterface, error) {
 	logger.Verbosef("Starting wireguard-go")
 
 	logger.Verbosef("Starting device")
-	wgDevice := device.NewDevice(tunnel, conn.NewDefaultBind(), logger)
+	wgDevice := device.NewDevice(tunnel, wgbind.NewDefaultBind(), logger)
 
 	log.Info().Msg("Creating UAPI listener")
 	uapi, err := newUAPIListener(interfaceName)
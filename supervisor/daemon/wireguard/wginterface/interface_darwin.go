@@ -25,13 +25,12 @@ import (
 	"strconv"
 
 	"github.com/rs/zerolog/log"
-	"golang.zx2c4.com/wireguard/device"
 	"golang.zx2c4.com/wireguard/ipc"
 	"golang.zx2c4.com/wireguard/tun"
 )
 
-func createTunnel(requestedInterfaceName string, _ []string) (tunnel tun.Device, interfaceName string, err error) {
-	tunnel, err = tun.CreateTUN(requestedInterfaceName, device.DefaultMTU)
+func createTunnel(requestedInterfaceName string, _ []string, mtu int) (tunnel tun.Device, interfaceName string, err error) {
+	tunnel, err = tun.CreateTUN(requestedInterfaceName, mtu)
 	if err == nil {
 		interfaceName = requestedInterfaceName
 		realInterfaceName, err2 := tunnel.Name()
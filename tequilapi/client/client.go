@@ -18,18 +18,21 @@
 package client
 
 import (
+	"crypto/tls"
 	"fmt"
 	"io/ioutil"
 	"math/big"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 
 	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/dispute"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/payments/exchange"
 )
@@ -44,6 +47,34 @@ func NewClient(ip string, port int) *Client {
 	}
 }
 
+// NewTLSClient returns a new instance of Client for Tequilapi served over TLS.
+func NewTLSClient(ip string, port int, tlsConfig *tls.Config) *Client {
+	return &Client{
+		http: newTLSHTTPClient(
+			fmt.Sprintf("https://%s:%d", ip, port),
+			"goclient-v0.1",
+			tlsConfig,
+		),
+	}
+}
+
+// NewRemoteClient returns a new instance of Client for Tequilapi of a node on another host,
+// reached through the authenticating API proxy of its UI server.
+// Requests are authenticated with the given token issued by the remote node.
+// Nil tlsConfig verifies the remote node certificate against system roots.
+func NewRemoteClient(baseURL, token string, tlsConfig *tls.Config) *Client {
+	c := newTLSHTTPClient(strings.TrimSuffix(baseURL, "/"), "goclient-v0.1", tlsConfig)
+	c.SetToken(token)
+	return &Client{http: c}
+}
+
+// NewUnixClient returns a new instance of Client for Tequilapi served on a unix domain socket.
+func NewUnixClient(socketPath string) *Client {
+	return &Client{
+		http: newUnixHTTPClient(socketPath, "goclient-v0.1"),
+	}
+}
+
 // Client is able perform remote requests to Tequilapi server
 type Client struct {
 	http httpClientInterface
@@ -217,6 +248,34 @@ func (client *Client) GetTransactorFees() (contract.FeesDTO, error) {
 	return fees, err
 }
 
+// RegistrationFees returns registration cost quotes for the given identity.
+func (client *Client) RegistrationFees(identityAddress string) (contract.RegistrationFeesResponse, error) {
+	fees := contract.RegistrationFeesResponse{}
+
+	res, err := client.http.Get("identities/"+identityAddress+"/registration-fees", nil)
+	if err != nil {
+		return fees, err
+	}
+	defer res.Body.Close()
+
+	err = parseResponseJSON(res, &fees)
+	return fees, err
+}
+
+// TransactorQueue returns queued transactor requests.
+func (client *Client) TransactorQueue() (contract.TransactorQueueResponse, error) {
+	queue := contract.TransactorQueueResponse{}
+
+	res, err := client.http.Get("transactor/queue", nil)
+	if err != nil {
+		return queue, err
+	}
+	defer res.Body.Close()
+
+	err = parseResponseJSON(res, &queue)
+	return queue, err
+}
+
 // RegisterIdentity registers identity
 func (client *Client) RegisterIdentity(address, beneficiary string, token *string) error {
 	payload := contract.IdentityRegisterRequest{
@@ -304,6 +363,18 @@ func (client *Client) ConnectionStatistics(sessionID ...string) (statistics cont
 	return statistics, err
 }
 
+// ConnectionEstimate projects session costs with the given providers.
+func (client *Client) ConnectionEstimate(request contract.ConnectionEstimateRequest) (estimate contract.ConnectionEstimateResponse, err error) {
+	response, err := client.http.Post("connection/estimate", request)
+	if err != nil {
+		return estimate, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &estimate)
+	return estimate, err
+}
+
 // ConnectionTraffic returns traffic information about current connection
 func (client *Client) ConnectionTraffic(sessionID ...string) (traffic contract.ConnectionTrafficDTO, err error) {
 	response, err := client.http.Get("connection/traffic", url.Values{
@@ -570,6 +641,30 @@ func (client *Client) ServiceStart(request contract.ServiceStartRequest) (servic
 	return service, err
 }
 
+// ServicePreview renders the proposal which would be published for the service configuration without starting it.
+func (client *Client) ServicePreview(request contract.ServiceStartRequest) (preview contract.ServicePreviewDTO, err error) {
+	response, err := client.http.Post("services/preview", request)
+	if err != nil {
+		return preview, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &preview)
+	return preview, err
+}
+
+// ServiceRestart replaces the running service instance with a new configuration.
+func (client *Client) ServiceRestart(id string, request contract.ServiceStartRequest) (service contract.ServiceInfoDTO, err error) {
+	response, err := client.http.Put("services/"+id, request)
+	if err != nil {
+		return service, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &service)
+	return service, err
+}
+
 // ServiceStop stops the running service instance by the requested id.
 func (client *Client) ServiceStop(id string) error {
 	path := fmt.Sprintf("services/%s", id)
@@ -594,6 +689,208 @@ func (client *Client) NATStatus() (status contract.NodeStatusResponse, err error
 	return status, err
 }
 
+// ProviderReputation returns provider reputation as seen by consumers during given range ("1d", "7d", "30d").
+func (client *Client) ProviderReputation(rangeTime string) (res contract.ProviderReputationResponse, err error) {
+	response, err := client.http.Get("node/reputation", url.Values{"range": []string{rangeTime}})
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// UplinkUsage returns bytes transferred by provider sessions per network interface.
+func (client *Client) UplinkUsage() (res contract.UplinkUsageResponse, err error) {
+	response, err := client.http.Get("node/provider/uplinks", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// ServiceSchedule returns access windows of provider services and their utilization.
+func (client *Client) ServiceSchedule() (res contract.ServiceScheduleResponse, err error) {
+	response, err := client.http.Get("node/provider/schedule", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// NodeSummary returns identity, balances, sessions, today's traffic, monitoring status and alerts of the node.
+func (client *Client) NodeSummary() (res contract.NodeSummaryResponse, err error) {
+	response, err := client.http.Get("node/summary", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// Alerts returns active and recently produced alerts.
+func (client *Client) Alerts() (res contract.AlertsResponse, err error) {
+	response, err := client.http.Get("node/alerts", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// AlertRules returns configured alert rules.
+func (client *Client) AlertRules() (res contract.AlertRulesResponse, err error) {
+	response, err := client.http.Get("node/alerts/rules", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// AddAlertRule adds an alert rule.
+func (client *Client) AddAlertRule(req contract.AlertRuleRequest) (res contract.AlertRuleDTO, err error) {
+	response, err := client.http.Post("node/alerts/rules", req)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// RemoveAlertRule removes the alert rule with the given ID.
+func (client *Client) RemoveAlertRule(id string) error {
+	response, err := client.http.Delete("node/alerts/rules/"+id, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// NotificationsConfig returns notification channels settings without secrets.
+func (client *Client) NotificationsConfig() (res contract.NotificationsConfigDTO, err error) {
+	response, err := client.http.Get("node/notifications", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// SetNotificationsConfig replaces notification channels settings.
+func (client *Client) SetNotificationsConfig(config contract.NotificationsConfigDTO) (res contract.NotificationsConfigDTO, err error) {
+	response, err := client.http.Put("node/notifications", config)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// TestNotifications sends a test notification to all configured channels.
+func (client *Client) TestNotifications() error {
+	response, err := client.http.Post("node/notifications/test", nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// ProviderPauseStatus returns provider pause status.
+func (client *Client) ProviderPauseStatus() (res contract.ProviderPauseStatusDTO, err error) {
+	response, err := client.http.Get("node/provider/pause", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// ProviderPause unpublishes proposals of running services and rejects new sessions.
+func (client *Client) ProviderPause(keepSessions bool) (res contract.ProviderPauseStatusDTO, err error) {
+	response, err := client.http.Post("node/provider/pause", contract.ProviderPauseRequest{KeepSessions: &keepSessions})
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// ProviderResume resumes paused provider services.
+func (client *Client) ProviderResume() (res contract.ProviderPauseStatusDTO, err error) {
+	response, err := client.http.Post("node/provider/resume", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// Preflight returns provider startup dependency checks report.
+func (client *Client) Preflight() (res contract.PreflightReportDTO, err error) {
+	response, err := client.http.Get("preflight", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// RunPreflight re-runs provider startup dependency checks.
+func (client *Client) RunPreflight() (res contract.PreflightReportDTO, err error) {
+	response, err := client.http.Post("preflight", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// DDNSStatus returns dynamic DNS status.
+func (client *Client) DDNSStatus() (res contract.DDNSStatusDTO, err error) {
+	response, err := client.http.Get("node/ddns", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
 // NATType returns type of NAT in sense of traversal capabilities
 func (client *Client) NATType() (status contract.NATTypeDTO, err error) {
 	response, err := client.http.Get("nat/type", nil)
@@ -965,6 +1262,23 @@ func (client *Client) FetchConfig() (map[string]interface{}, error) {
 	return config, err
 }
 
+// FetchUserConfig - fetches user set config values.
+func (client *Client) FetchUserConfig() (map[string]interface{}, error) {
+	resp, err := client.http.Get("config/user", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var res struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := parseResponseJSON(resp, &res); err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
 // SetConfig - set user config.
 func (client *Client) SetConfig(data map[string]interface{}) error {
 	req := struct {
@@ -985,3 +1299,276 @@ func (client *Client) SetConfig(data map[string]interface{}) error {
 
 	return nil
 }
+
+// DiagnosticsBundle generates diagnostics bundle on the node and returns its path.
+func (client *Client) DiagnosticsBundle() (contract.DiagnosticsResponse, error) {
+	var res contract.DiagnosticsResponse
+	response, err := client.http.Post("diagnostics", contract.DiagnosticsRequest{Consent: true})
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// NodeUpdateStatus checks the release channel and returns node update status.
+func (client *Client) NodeUpdateStatus(channel string) (contract.NodeUpdateStatus, error) {
+	var res contract.NodeUpdateStatus
+	response, err := client.http.Get("node/update", url.Values{"channel": []string{channel}})
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// NodeUpdate starts node update from the release channel.
+func (client *Client) NodeUpdate(channel string) (contract.NodeUpdateStatus, error) {
+	var res contract.NodeUpdateStatus
+	response, err := client.http.Post("node/update", contract.NodeUpdateRequest{Channel: channel})
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// FeatureFlags returns node feature states.
+func (client *Client) FeatureFlags() (contract.FeatureFlagsResponse, error) {
+	var res contract.FeatureFlagsResponse
+	response, err := client.http.Get("features", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// Chains returns configured chains.
+func (client *Client) Chains() (res contract.ChainsResponse, err error) {
+	response, err := client.http.Get("chains", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// ActiveChain returns the active chain.
+func (client *Client) ActiveChain() (res contract.ActiveChainResponse, err error) {
+	response, err := client.http.Get("chains/active", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// SwitchChain switches the active chain.
+func (client *Client) SwitchChain(chainID int64) (res contract.ActiveChainResponse, err error) {
+	response, err := client.http.Put("chains/active", contract.ActiveChainRequest{ChainID: chainID})
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// ChainBalances returns identity balances on all configured chains.
+func (client *Client) ChainBalances(identityAddress string) (res contract.ChainBalancesResponse, err error) {
+	response, err := client.http.Get("chains/balances/"+identityAddress, nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// StartManagementPairing generates a one-time code for pairing a remote managing node.
+func (client *Client) StartManagementPairing(identityAddress string) (res contract.ManagementPairingResponse, err error) {
+	response, err := client.http.Post("management/pairing", contract.ManagementPairingRequest{ID: identityAddress})
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// ManagementPeers returns remote identities allowed to manage this node.
+func (client *Client) ManagementPeers() (res contract.ManagementPeersResponse, err error) {
+	response, err := client.http.Get("management/peers", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// UnpairManagementPeer revokes management access of a remote identity.
+func (client *Client) UnpairManagementPeer(nodeAddress, peerAddress string) error {
+	response, err := client.http.Delete(fmt.Sprintf("management/peers/%s/%s", nodeAddress, peerAddress), nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// PairManagementRemote pairs with a remote node using the one-time code it generated.
+func (client *Client) PairManagementRemote(localAddress, nodeAddress, code string) (res contract.ManagementRemoteDTO, err error) {
+	response, err := client.http.Post("management/remotes", contract.ManagementRemoteRequest{
+		LocalID: localAddress,
+		NodeID:  nodeAddress,
+		Code:    code,
+	})
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// ManagementRemotes returns remote nodes managed by this node.
+func (client *Client) ManagementRemotes() (res contract.ManagementRemotesResponse, err error) {
+	response, err := client.http.Get("management/remotes", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// PaymentsAudit compares the locally stored hermes promise of the identity with hermes.
+func (client *Client) PaymentsAudit(identityAddress string) (res contract.PromiseAuditResponse, err error) {
+	response, err := client.http.Get("payments/audit/"+identityAddress, nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// PaymentsRecover repairs the locally stored hermes promise of the identity using hermes.
+func (client *Client) PaymentsRecover(identityAddress string) (res contract.PromiseAuditResponse, err error) {
+	response, err := client.http.Post("payments/audit/"+identityAddress+"/recover", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// PaymentDisputes returns payment disputes of provided sessions.
+func (client *Client) PaymentDisputes() (res contract.DisputeListResponse, err error) {
+	response, err := client.http.Get("payments/disputes", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// PaymentDisputeFlag flags a provided session as underpaid.
+func (client *Client) PaymentDisputeFlag(sessionID, reason string) (res contract.DisputeDTO, err error) {
+	response, err := client.http.Post("payments/disputes", contract.DisputeRequest{
+		SessionID: sessionID,
+		Reason:    reason,
+	})
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// PaymentDisputeResolve resolves the payment dispute of the session.
+func (client *Client) PaymentDisputeResolve(sessionID string) (res contract.DisputeDTO, err error) {
+	response, err := client.http.Post("payments/disputes/"+sessionID+"/resolve", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// PaymentDisputeBundle returns the signed dispute bundle of the session.
+func (client *Client) PaymentDisputeBundle(sessionID string) (res dispute.Bundle, err error) {
+	response, err := client.http.Get("payments/disputes/"+sessionID+"/bundle", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// PriceBook returns provider price book.
+func (client *Client) PriceBook() (res contract.PriceBookDTO, err error) {
+	response, err := client.http.Get("pricing/book", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// SetPriceBook replaces provider price book.
+func (client *Client) SetPriceBook(book contract.PriceBookDTO) (res contract.PriceBookDTO, err error) {
+	response, err := client.http.Put("pricing/book", book)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// ClearPriceBook removes all provider price book entries.
+func (client *Client) ClearPriceBook() error {
+	response, err := client.http.Delete("pricing/book", nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
@@ -19,8 +19,10 @@ package client
 
 import (
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -124,3 +126,34 @@ func (tc *trackingCloser) Close() error {
 }
 
 var _ io.ReadCloser = (*trackingCloser)(nil)
+
+func TestUnixClientTalksOverSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "tequilapi.sock")
+	listener, err := net.Listen("unix", socket)
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	go http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/healthcheck", r.URL.Path)
+		w.Write([]byte(`{"uptime": "1m"}`))
+	}))
+
+	healthcheck, err := NewUnixClient(socket).Healthcheck()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1m", healthcheck.Uptime)
+}
+
+func TestRemoteClientAuthenticatesRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "/healthcheck", r.URL.Path)
+		w.Write([]byte(`{"uptime": "1m"}`))
+	}))
+	defer server.Close()
+
+	healthcheck, err := NewRemoteClient(server.URL+"/", "secret", nil).Healthcheck()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1m", healthcheck.Uptime)
+}
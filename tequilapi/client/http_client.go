@@ -19,10 +19,13 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"time"
@@ -53,6 +56,28 @@ func newHTTPClient(baseURL string, ua string) *httpClient {
 	}
 }
 
+func newTLSHTTPClient(baseURL string, ua string, tlsConfig *tls.Config) *httpClient {
+	transport := requests.NewTransport(requests.NewDialer("0.0.0.0").DialContext)
+	transport.TLSClientConfig = tlsConfig
+	return &httpClient{
+		http:    requests.NewHTTPClientWithTransport(transport, 100*time.Second),
+		baseURL: baseURL,
+		ua:      ua,
+	}
+}
+
+func newUnixHTTPClient(socketPath string, ua string) *httpClient {
+	transport := requests.NewTransport(func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, "unix", socketPath)
+	})
+	return &httpClient{
+		http:    requests.NewHTTPClientWithTransport(transport, 100*time.Second),
+		baseURL: "http://localhost",
+		ua:      ua,
+	}
+}
+
 type httpClient struct {
 	http      httpRequestInterface
 	authToken string
@@ -0,0 +1,168 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/shopspring/decimal"
+
+	"github.com/mysteriumnetwork/node/core/alerting"
+)
+
+// AlertRuleRequest request used to add an alert rule.
+// swagger:model AlertRuleRequest
+type AlertRuleRequest struct {
+	// one of earnings_below, balance_below, monitoring_failed
+	// example: earnings_below
+	Kind string `json:"kind"`
+	// threshold in MYST, required by earnings_below and balance_below rules
+	// example: 5
+	Threshold string `json:"threshold,omitempty"`
+	// earnings period or time monitoring has to stay failed, defaults to 168h and 1h respectively
+	// example: 168h
+	Window string `json:"window,omitempty"`
+	// one of info, warning, error, defaults to warning
+	// example: warning
+	Severity string `json:"severity,omitempty"`
+}
+
+// Validate validates fields in request.
+func (r AlertRuleRequest) Validate() *apierror.APIError {
+	v := apierror.NewValidator()
+	if r.Kind == "" {
+		v.Required("kind")
+	}
+	if r.Threshold != "" {
+		if _, err := decimal.NewFromString(r.Threshold); err != nil {
+			v.Invalid("threshold", "Failed to parse threshold (not a valid decimal)")
+		}
+	}
+	if r.Window != "" {
+		if _, err := time.ParseDuration(r.Window); err != nil {
+			v.Invalid("window", "Failed to parse window (not a valid duration)")
+		}
+	}
+	return v.Err()
+}
+
+// ToRule maps validated request to an alert rule.
+func (r AlertRuleRequest) ToRule() alerting.Rule {
+	rule := alerting.Rule{Kind: alerting.Kind(r.Kind), Severity: r.Severity}
+	if r.Threshold != "" {
+		rule.Threshold, _ = decimal.NewFromString(r.Threshold)
+	}
+	if r.Window != "" {
+		rule.Window, _ = time.ParseDuration(r.Window)
+	}
+	return rule
+}
+
+// AlertRuleDTO describes an alert rule.
+// swagger:model AlertRuleDTO
+type AlertRuleDTO struct {
+	ID string `json:"id"`
+	// example: earnings_below
+	Kind string `json:"kind"`
+	// example: 5
+	Threshold string `json:"threshold,omitempty"`
+	// example: 168h0m0s
+	Window string `json:"window,omitempty"`
+	// example: warning
+	Severity string `json:"severity"`
+}
+
+// NewAlertRuleDTO maps alert rule to DTO.
+func NewAlertRuleDTO(rule alerting.Rule) AlertRuleDTO {
+	dto := AlertRuleDTO{ID: rule.ID, Kind: string(rule.Kind), Severity: rule.Severity}
+	if !rule.Threshold.IsZero() {
+		dto.Threshold = rule.Threshold.String()
+	}
+	if rule.Window > 0 {
+		dto.Window = rule.Window.String()
+	}
+	return dto
+}
+
+// AlertRulesResponse holds configured alert rules.
+// swagger:model AlertRulesResponse
+type AlertRulesResponse struct {
+	Rules []AlertRuleDTO `json:"rules"`
+}
+
+// NewAlertRulesResponse maps alert rules to response.
+func NewAlertRulesResponse(rules []alerting.Rule) AlertRulesResponse {
+	res := AlertRulesResponse{Rules: []AlertRuleDTO{}}
+	for _, r := range rules {
+		res.Rules = append(res.Rules, NewAlertRuleDTO(r))
+	}
+	return res
+}
+
+// AlertDTO is an alert produced by an alert rule.
+// swagger:model AlertDTO
+type AlertDTO struct {
+	ID     string `json:"id"`
+	RuleID string `json:"rule_id"`
+	// example: monitoring_failed
+	Kind string `json:"kind"`
+	// example: error
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	// example: 2022-03-04T15:30:00Z
+	FiredAt string `json:"fired_at"`
+	// empty while the alert is active
+	// example: 2022-03-04T16:30:00Z
+	ResolvedAt string `json:"resolved_at,omitempty"`
+}
+
+// NewAlertDTO maps alert to DTO.
+func NewAlertDTO(alert alerting.Alert) AlertDTO {
+	dto := AlertDTO{
+		ID:       alert.ID,
+		RuleID:   alert.RuleID,
+		Kind:     string(alert.Kind),
+		Severity: alert.Severity,
+		Message:  alert.Message,
+		FiredAt:  alert.FiredAt.Format(time.RFC3339),
+	}
+	if !alert.Active() {
+		dto.ResolvedAt = alert.ResolvedAt.Format(time.RFC3339)
+	}
+	return dto
+}
+
+// AlertsResponse holds active and recently produced alerts.
+// swagger:model AlertsResponse
+type AlertsResponse struct {
+	Active  []AlertDTO `json:"active"`
+	History []AlertDTO `json:"history"`
+}
+
+// NewAlertsResponse maps alerts to response.
+func NewAlertsResponse(active, history []alerting.Alert) AlertsResponse {
+	res := AlertsResponse{Active: []AlertDTO{}, History: []AlertDTO{}}
+	for _, a := range active {
+		res.Active = append(res.Active, NewAlertDTO(a))
+	}
+	for _, a := range history {
+		res.History = append(res.History, NewAlertDTO(a))
+	}
+	return res
+}
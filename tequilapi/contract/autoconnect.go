@@ -0,0 +1,49 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection/autoconnect"
+)
+
+// AutoConnectEventDTO is an auto-connect attempt outcome.
+// swagger:model AutoConnectEventDTO
+type AutoConnectEventDTO struct {
+	// example: network_restored
+	Trigger string `json:"trigger"`
+	// example: skipped
+	Outcome string `json:"outcome"`
+	// example: connection state is Connected
+	Reason     string `json:"reason,omitempty"`
+	ConsumerID string `json:"consumer_id,omitempty"`
+	// example: 2022-03-04T15:30:00Z
+	At string `json:"at"`
+}
+
+// NewAutoConnectEventDTO maps auto-connect event to DTO.
+func NewAutoConnectEventDTO(e autoconnect.AppEventAutoConnect) AutoConnectEventDTO {
+	return AutoConnectEventDTO{
+		Trigger:    string(e.Trigger),
+		Outcome:    string(e.Outcome),
+		Reason:     e.Reason,
+		ConsumerID: e.ConsumerID,
+		At:         e.At.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/policy"
+)
+
+// NewBlocklistStatusDTO maps to API blocklist status.
+func NewBlocklistStatusDTO(status policy.BlocklistStatus) BlocklistStatusDTO {
+	dto := BlocklistStatusDTO{
+		FeedURL:        status.FeedURL,
+		FeedIdentities: status.FeedIdentities,
+		FeedIPRanges:   status.FeedIPRanges,
+		FeedError:      status.FeedError,
+		Block:          status.Block,
+		Exempt:         status.Exempt,
+	}
+	if !status.FeedUpdatedAt.IsZero() {
+		dto.FeedUpdatedAt = status.FeedUpdatedAt.Format(time.RFC3339)
+	}
+	return dto
+}
+
+// BlocklistStatusDTO describes consumer blocklist sources.
+// swagger:model BlocklistStatusDTO
+type BlocklistStatusDTO struct {
+	// example: https://example.com/blocklist.json
+	FeedURL string `json:"feed_url,omitempty"`
+
+	// example: 2019-06-06T11:04:43Z
+	FeedUpdatedAt string `json:"feed_updated_at,omitempty"`
+
+	// example: 12
+	FeedIdentities int `json:"feed_identities"`
+
+	// example: 3
+	FeedIPRanges int `json:"feed_ip_ranges"`
+
+	// last feed fetch error
+	FeedError string `json:"feed_error,omitempty"`
+
+	// locally blocked identities and IP ranges
+	Block []string `json:"block"`
+
+	// identities and IP ranges never blocked
+	Exempt []string `json:"exempt"`
+}
+
+// NewBlockedAttemptsResponse maps to API blocked attempts audit log.
+func NewBlockedAttemptsResponse(attempts []policy.BlockedAttempt) BlockedAttemptsResponse {
+	dtoArray := make([]BlockedAttemptDTO, len(attempts))
+	for i, a := range attempts {
+		dtoArray[i] = BlockedAttemptDTO{
+			ConsumerID: a.ConsumerID,
+			IP:         a.IP,
+			Source:     a.Source,
+			Rule:       a.Rule,
+			At:         a.At.Format(time.RFC3339),
+		}
+	}
+	return BlockedAttemptsResponse{Items: dtoArray}
+}
+
+// BlockedAttemptsResponse is the audit log of consumers rejected by blocklist.
+// swagger:model BlockedAttemptsResponse
+type BlockedAttemptsResponse struct {
+	Items []BlockedAttemptDTO `json:"items"`
+}
+
+// BlockedAttemptDTO describes a consumer rejected by blocklist.
+// swagger:model BlockedAttemptDTO
+type BlockedAttemptDTO struct {
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id"`
+
+	// example: 10.0.0.1
+	IP string `json:"ip,omitempty"`
+
+	// example: feed
+	Source string `json:"source"`
+
+	// example: 10.0.0.0/8
+	Rule string `json:"rule"`
+
+	// example: 2019-06-06T11:04:43Z
+	At string `json:"at"`
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"math/big"
+
+	"github.com/mysteriumnetwork/node/core/chains"
+)
+
+// NewChainDTO maps to API chain.
+func NewChainDTO(chain chains.Chain, active bool) ChainDTO {
+	return ChainDTO{
+		ID:                           chain.ChainID,
+		Name:                         chain.Name,
+		RegistryAddress:              chain.RegistryAddress,
+		HermesID:                     chain.HermesID,
+		ChannelImplementationAddress: chain.ChannelImplAddress,
+		MystAddress:                  chain.MystAddress,
+		Active:                       active,
+	}
+}
+
+// ChainDTO describes a blockchain the node can work with.
+// swagger:model ChainDTO
+type ChainDTO struct {
+	// example: 137
+	ID int64 `json:"id"`
+
+	// example: Polygon Mainnet
+	Name string `json:"name"`
+
+	RegistryAddress              string `json:"registry_address"`
+	HermesID                     string `json:"hermes_id"`
+	ChannelImplementationAddress string `json:"channel_implementation_address"`
+	MystAddress                  string `json:"myst_address"`
+
+	// example: true
+	Active bool `json:"active"`
+}
+
+// ChainsResponse holds configured chains.
+// swagger:model ChainsResponse
+type ChainsResponse struct {
+	Chains []ChainDTO `json:"chains"`
+}
+
+// ActiveChainRequest request used to switch the active chain.
+// swagger:model ActiveChainRequest
+type ActiveChainRequest struct {
+	// example: 137
+	ChainID int64 `json:"chain_id"`
+}
+
+// ActiveChainResponse describes the active chain.
+// swagger:model ActiveChainResponse
+type ActiveChainResponse struct {
+	// chain the node is running with
+	Chain ChainDTO `json:"chain"`
+
+	// chain which becomes active after the node restart
+	PendingChain *ChainDTO `json:"pending_chain,omitempty"`
+
+	// whether the node must be restarted to switch to the pending chain
+	// example: false
+	RestartRequired bool `json:"restart_required"`
+}
+
+// ChainBalancesResponse holds identity balances on all configured chains.
+// swagger:model ChainBalancesResponse
+type ChainBalancesResponse struct {
+	Balances []ChainBalanceDTO `json:"balances"`
+}
+
+// ChainBalanceDTO holds identity balance on a single chain.
+// swagger:model ChainBalanceDTO
+type ChainBalanceDTO struct {
+	// example: 137
+	ChainID int64 `json:"chain_id"`
+
+	Balance       *big.Int `json:"balance"`
+	BalanceTokens Tokens   `json:"balance_tokens"`
+}
@@ -18,13 +18,16 @@
 package contract
 
 import (
+	"errors"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/consumer/bandwidth"
 	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/autoconnect"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/datasize"
@@ -68,6 +71,32 @@ type ConnectionInfoDTO struct {
 
 	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
 	SessionID string `json:"session_id,omitempty"`
+
+	// Present only when the OS reports the consumer uplink as metered
+	Metered *MeteredDTO `json:"metered,omitempty"`
+}
+
+// NewMeteredDTO maps to API metered uplink state, nil when the uplink is not metered.
+func NewMeteredDTO(status autoconnect.MeteredStatus) *MeteredDTO {
+	if !status.Metered {
+		return nil
+	}
+	return &MeteredDTO{
+		Policy: string(status.Policy),
+		Capped: status.Capped,
+	}
+}
+
+// MeteredDTO holds metered uplink state of the consumer.
+// swagger:model MeteredDTO
+type MeteredDTO struct {
+	// Policy applied on metered uplink: warn, cap or refuse
+	// example: warn
+	Policy string `json:"policy"`
+
+	// Throughput is limited while connected on the metered uplink
+	// example: false
+	Capped bool `json:"capped"`
 }
 
 // NewConnectionDTO maps to API connection.
@@ -131,6 +160,46 @@ type ConnectionStatisticsDTO struct {
 	TokensSpent *big.Int `json:"tokens_spent"`
 
 	SpentTokens Tokens `json:"spent_tokens"`
+
+	// Session quality of service as measured by provider
+	ProviderQoS *ProviderQoSDTO `json:"provider_qos,omitempty"`
+}
+
+// NewProviderQoSDTO maps to API provider QoS, nil when provider has not reported yet.
+func NewProviderQoSDTO(qos connectionstate.QoS) *ProviderQoSDTO {
+	if qos.ReportedAt.IsZero() {
+		return nil
+	}
+	return &ProviderQoSDTO{
+		PacketLoss:         qos.PacketLoss,
+		RTTMillis:          qos.RTT.Milliseconds(),
+		ThroughputSent:     datasize.FromBytes(qos.ThroughputSent).Bits(),
+		ThroughputReceived: datasize.FromBytes(qos.ThroughputReceived).Bits(),
+		ReportedAt:         qos.ReportedAt.Format(time.RFC3339),
+	}
+}
+
+// ProviderQoSDTO holds session quality of service reported by provider.
+// swagger:model ProviderQoSDTO
+type ProviderQoSDTO struct {
+	// Share of lost provider pings, 0..1
+	// example: 0.01
+	PacketLoss float64 `json:"packet_loss"`
+
+	// Average round trip time in milliseconds
+	// example: 40
+	RTTMillis int64 `json:"rtt_ms"`
+
+	// Upload speed in bits per second
+	// example: 1024
+	ThroughputSent uint64 `json:"throughput_sent"`
+
+	// Download speed in bits per second
+	// example: 1024
+	ThroughputReceived uint64 `json:"throughput_received"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	ReportedAt string `json:"reported_at"`
 }
 
 // ConnectionTrafficDTO holds consumer connection traffic information.
@@ -192,6 +261,20 @@ func (cr ConnectionCreateRequest) Validate() *apierror.APIError {
 	return v.Err()
 }
 
+// NewConnectionVetoedError maps connection vetoed by pre-connect hook to API error,
+// the veto reason is reported as a field named after the hook.
+func NewConnectionVetoedError(err error) *apierror.APIError {
+	apiErr := apierror.Unprocessable("Connection was vetoed: "+err.Error(), ErrCodeConnectionVetoed)
+
+	var veto *connection.VetoError
+	if errors.As(err, &veto) {
+		apiErr.Err.Fields = map[string]apierror.FieldError{
+			veto.Hook: {Code: veto.Reason, Message: veto.Message},
+		}
+	}
+	return apiErr
+}
+
 // Event creates a quality connection event to be send as a quality metric.
 func (cr ConnectionCreateRequest) Event(stage string, errMsg string) quality.ConnectionEvent {
 	return quality.ConnectionEvent{
@@ -219,3 +302,114 @@ type ConnectOptions struct {
 
 	ProxyPort int `json:"proxy_port"`
 }
+
+// NewConnectionTraceResponse maps to API connection trace.
+func NewConnectionTraceResponse(transitions []connectionstate.AppEventConnectionTransition) ConnectionTraceResponse {
+	res := ConnectionTraceResponse{Transitions: make([]ConnectionTransitionDTO, len(transitions))}
+	for i, t := range transitions {
+		res.Transitions[i] = ConnectionTransitionDTO{
+			From:      string(t.From),
+			To:        string(t.To),
+			Cause:     t.Cause,
+			At:        t.At.UTC().Format(time.RFC3339Nano),
+			SessionID: string(t.SessionID),
+		}
+	}
+	return res
+}
+
+// ConnectionTraceResponse holds consumer connection state transitions.
+// swagger:model ConnectionTraceResponse
+type ConnectionTraceResponse struct {
+	Transitions []ConnectionTransitionDTO `json:"transitions"`
+}
+
+// ConnectionTransitionDTO holds a single connection state transition.
+// swagger:model ConnectionTransitionDTO
+type ConnectionTransitionDTO struct {
+	// example: Connecting
+	From string `json:"from"`
+
+	// example: Connected
+	To string `json:"to"`
+
+	// example: tunnel connected
+	Cause string `json:"cause"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	At string `json:"at"`
+
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// ConnectionEstimateRequest request used to estimate session costs before connecting.
+// swagger:model ConnectionEstimateRequest
+type ConnectionEstimateRequest struct {
+	// providers to estimate, e.g. the candidates being compared
+	// required: true
+	// example: ["0x0000000000000000000000000000000000000002"]
+	Providers []string `json:"providers"`
+
+	// service type
+	// required: true
+	// example: wireguard
+	ServiceType string `json:"service_type"`
+
+	// hermes which would process payments, active hermes when empty
+	// required: false
+	HermesID string `json:"hermes_id,omitempty"`
+
+	// expected traffic in GiB
+	// example: 10
+	GiB float64 `json:"gib"`
+
+	// expected session length in hours
+	// example: 2.5
+	Hours float64 `json:"hours"`
+}
+
+// Validate validates fields in request.
+func (r ConnectionEstimateRequest) Validate() *apierror.APIError {
+	v := apierror.NewValidator()
+	if len(r.Providers) == 0 {
+		v.Required("providers")
+	}
+	if r.ServiceType == "" {
+		v.Required("service_type")
+	}
+	if r.GiB < 0 {
+		v.Invalid("gib", "Must not be negative")
+	}
+	if r.Hours < 0 {
+		v.Invalid("hours", "Must not be negative")
+	}
+	return v.Err()
+}
+
+// ConnectionEstimateResponse holds session cost estimates, cheapest first.
+// swagger:model ConnectionEstimateResponse
+type ConnectionEstimateResponse struct {
+	Estimates []ConnectionEstimate `json:"estimates"`
+}
+
+// ConnectionEstimate is a projected session cost with a single provider.
+// swagger:model ConnectionEstimate
+type ConnectionEstimate struct {
+	ProviderID  string `json:"provider_id"`
+	ServiceType string `json:"service_type"`
+	Price       *Price `json:"price,omitempty"`
+
+	TimeCost *Tokens `json:"time_cost,omitempty"`
+	DataCost *Tokens `json:"data_cost,omitempty"`
+	// total amount consumer would pay
+	Total *Tokens `json:"total,omitempty"`
+
+	// payment fee kept by hermes, included in total
+	HermesFee *Tokens `json:"hermes_fee,omitempty"`
+	// example: 0.2000
+	HermesPercent string `json:"hermes_percent,omitempty"`
+
+	// set instead of costs when proposal could not be found
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/ddns"
+)
+
+// DDNSStatusDTO is the state of the dynamic DNS record pointing at node public IP.
+// swagger:model DDNSStatusDTO
+type DDNSStatusDTO struct {
+	// example: mynode.duckdns.org
+	Hostname string `json:"hostname"`
+	// IP the hostname was last pointed at
+	// example: 1.2.3.4
+	IP string `json:"ip,omitempty"`
+	// example: 2022-01-01T12:00:00Z
+	UpdatedAt string `json:"updated_at,omitempty"`
+	// last update error
+	// example: duckdns update rejected: KO
+	Error string `json:"error,omitempty"`
+}
+
+// NewDDNSStatusDTO creates DTO from the dynamic DNS status.
+func NewDDNSStatusDTO(status ddns.Status) DDNSStatusDTO {
+	dto := DDNSStatusDTO{
+		Hostname: status.Hostname,
+		IP:       status.IP,
+		Error:    status.Error,
+	}
+	if !status.UpdatedAt.IsZero() {
+		dto.UpdatedAt = status.UpdatedAt.Format(time.RFC3339)
+	}
+	return dto
+}
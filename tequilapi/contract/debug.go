@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// DebugGCStatsResponse holds garbage collector and heap statistics of the node process.
+// swagger:model DebugGCStatsResponse
+type DebugGCStatsResponse struct {
+	// example: 42
+	Goroutines int `json:"goroutines"`
+	// number of completed GC cycles
+	NumGC int64 `json:"num_gc"`
+	// example: 2022-01-01T12:00:00Z
+	LastGC       string  `json:"last_gc,omitempty"`
+	PauseTotalMs float64 `json:"pause_total_ms"`
+	// minimum, 25th, 50th, 75th percentile and maximum GC pause durations
+	PauseQuantilesMs []float64 `json:"pause_quantiles_ms"`
+	// share of CPU time used by GC since the process start
+	GCCPUFraction  float64 `json:"gc_cpu_fraction"`
+	HeapAllocBytes uint64  `json:"heap_alloc_bytes"`
+	HeapSysBytes   uint64  `json:"heap_sys_bytes"`
+	HeapObjects    uint64  `json:"heap_objects"`
+	// heap size at which the next GC cycle starts
+	NextGCBytes uint64 `json:"next_gc_bytes"`
+	SysBytes    uint64 `json:"sys_bytes"`
+}
+
+// NewDebugGCStatsResponse creates response from runtime GC and memory stats
+func NewDebugGCStatsResponse(gc debug.GCStats, mem runtime.MemStats, goroutines int) DebugGCStatsResponse {
+	res := DebugGCStatsResponse{
+		Goroutines:       goroutines,
+		NumGC:            gc.NumGC,
+		PauseTotalMs:     float64(gc.PauseTotal) / float64(time.Millisecond),
+		PauseQuantilesMs: make([]float64, 0, len(gc.PauseQuantiles)),
+		GCCPUFraction:    mem.GCCPUFraction,
+		HeapAllocBytes:   mem.HeapAlloc,
+		HeapSysBytes:     mem.HeapSys,
+		HeapObjects:      mem.HeapObjects,
+		NextGCBytes:      mem.NextGC,
+		SysBytes:         mem.Sys,
+	}
+	if !gc.LastGC.IsZero() {
+		res.LastGC = gc.LastGC.UTC().Format(time.RFC3339)
+	}
+	for _, p := range gc.PauseQuantiles {
+		res.PauseQuantilesMs = append(res.PauseQuantilesMs, float64(p)/float64(time.Millisecond))
+	}
+	return res
+}
@@ -0,0 +1,33 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+// DiagnosticsRequest request to generate diagnostics bundle
+// swagger:model DiagnosticsRequestDTO
+type DiagnosticsRequest struct {
+	// user agrees to collect logs, configuration and network details of the node
+	// example: true
+	Consent bool `json:"consent"`
+}
+
+// DiagnosticsResponse points to the generated diagnostics bundle
+// swagger:model DiagnosticsResponseDTO
+type DiagnosticsResponse struct {
+	// example: /var/lib/mysterium-node/diagnostics-20220601-120000.zip
+	Path string `json:"path"`
+}
@@ -17,6 +17,8 @@
 
 package contract
 
+import "github.com/mysteriumnetwork/node/core/connection"
+
 // Err codes returned from TequilAPI.
 // Once created, do not change the string value, because consumers may depend on it - it's part of the contract.
 const (
@@ -50,6 +52,10 @@ const (
 	ErrCodePaymentGetOptions     = "err_payment_get_order_options"
 	ErrCodePaymentListGateways   = "err_payment_list_gateways"
 
+	// Pricing
+
+	ErrCodePriceBookSet = "err_price_book_set"
+
 	// Referral
 
 	ErrCodeReferralGetToken = "err_referral_get_token"
@@ -57,20 +63,39 @@ const (
 
 	// Config
 
-	ErrCodeConfigSave = "err_config_save"
+	ErrCodeConfigSave    = "err_config_save"
+	ErrCodeConfigCLIOnly = "err_config_cli_only"
+
+	// Terms
+
+	ErrCodeTermsNotAccepted = "err_terms_not_accepted"
+	ErrCodeTermsVersion     = "err_terms_version"
 
 	// Connection
 
-	ErrCodeConnectionAlreadyExists = "err_connection_already_exists"
-	ErrCodeConnectionCancelled     = "err_connection_cancelled"
+	ErrCodeConnectionAlreadyExists = connection.ErrCodeAlreadyExists
+	ErrCodeConnectionCancelled     = connection.ErrCodeConnectionCancelled
+	ErrCodeConnectionVetoed        = connection.ErrCodeConnectionVetoed
 	ErrCodeConnect                 = "err_connect"
-	ErrCodeNoConnectionExists      = "err_no_connection_exists"
+	ErrCodeNoConnectionExists      = connection.ErrCodeNoConnection
 	ErrCodeDisconnect              = "err_disconnect"
+	ErrCodeConnectionHistory       = "err_connection_history"
 
 	// Feedback
 
 	ErrCodeFeedbackSubmit = "err_feedback_submit"
 
+	// Diagnostics
+
+	ErrCodeDiagnosticsConsent = "err_diagnostics_consent"
+	ErrCodeDiagnosticsBundle  = "err_diagnostics_bundle"
+
+	// Updater
+
+	ErrCodeUpdateDisabled   = "err_update_disabled"
+	ErrCodeUpdateInProgress = "err_update_in_progress"
+	ErrCodeUpdateCheck      = "err_update_check"
+
 	// MMN
 
 	ErrCodeMMNNodeAlreadyClaimed = "err_mmn_node_already_claimed"
@@ -98,6 +123,7 @@ const (
 	ErrCodeServiceLocation = "err_service_location"
 	ErrCodeServiceStart    = "err_service_start"
 	ErrCodeServiceStop     = "err_service_stop"
+	ErrCodeServicePreview  = "err_service_preview"
 
 	// Sessions
 
@@ -105,6 +131,29 @@ const (
 	ErrCodeSessionListPaginate = "err_session_list_paginate"
 	ErrCodeSessionStats        = "err_session_stats"
 	ErrCodeSessionStatsDaily   = "err_session_stats_daily"
+	ErrCodeSessionLatency      = "err_session_latency"
+
+	// Session receipts
+
+	ErrCodeSessionReceiptList = "err_session_receipt_list"
+	ErrCodeSessionReceiptGet  = "err_session_receipt_get"
+
+	// Payments audit
+
+	ErrCodePaymentsAudit   = "err_payments_audit"
+	ErrCodePaymentsRecover = "err_payments_recover"
+
+	// Payment disputes
+
+	ErrCodeDisputeList    = "err_dispute_list"
+	ErrCodeDisputeGet     = "err_dispute_get"
+	ErrCodeDisputeFlag    = "err_dispute_flag"
+	ErrCodeDisputeResolve = "err_dispute_resolve"
+	ErrCodeDisputeBundle  = "err_dispute_bundle"
+
+	// Blocklist
+
+	ErrCodeBlocklistAudit = "err_blocklist_audit"
 
 	// Transactor
 
@@ -119,12 +168,38 @@ const (
 	ErrCodeTransactorNoReward              = "err_transactor_no_reward"
 	ErrCodeTransactorBeneficiary           = "err_transactor_beneficiary"
 	ErrCodeTransactorBeneficiaryTxStatus   = "err_transactor_beneficiary_tx_status"
+	ErrCodeTransactorQueue                 = "err_transactor_queue"
 
 	// Affiliator
 
 	ErrCodeAffiliatorNoReward = "err_affiliator_no_reward"
 	ErrCodeAffiliatorFailed   = "err_affiliator_failed"
 
+	// Remote management
+
+	ErrCodeManagementPairing = "err_management_pairing"
+	ErrCodeManagementPeers   = "err_management_peers"
+	ErrCodeManagementRemote  = "err_management_remote"
+
+	// Storage maintenance
+
+	ErrCodeStorageMaintenanceRunning = "err_storage_maintenance_running"
+
+	// Alerting
+
+	ErrCodeAlertRuleAdd    = "err_alert_rule_add"
+	ErrCodeAlertRuleRemove = "err_alert_rule_remove"
+
+	// Notifications
+
+	ErrCodeNotificationsConfig = "err_notifications_config"
+	ErrCodeNotificationsTest   = "err_notifications_test"
+
+	// Provider pause
+
+	ErrCodeProviderPause  = "err_provider_pause"
+	ErrCodeProviderResume = "err_provider_resume"
+
 	// Other
 
 	ErrCodeActiveHermes                    = "err_get_active_hermes"
@@ -143,4 +218,9 @@ const (
 	ErrorCodeProviderEarningsSeries        = "err_provider_earnings_series"
 	ErrorCodeProviderSessionsSeries        = "err_provider_sessions_series"
 	ErrorCodeProviderTransferredDataSeries = "err_provider_transferred_data_series"
+	ErrorCodeProviderEarningsForecast      = "err_provider_earnings_forecast"
+	ErrorCodeProviderEarningsGoal          = "err_provider_earnings_goal"
+	ErrorCodeNodeUptime                    = "err_node_uptime"
+	ErrorCodeProviderNATTraversal          = "err_provider_nat_traversal"
+	ErrorCodeProviderReputation            = "err_provider_reputation"
 )
@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/utils/errcode"
+)
+
+// errorHints holds remediation hints of errors created directly by endpoints.
+// Errors coming from inner packages carry their own hints.
+var errorHints = map[string]string{
+	apierror.ErrCodeParseFailed:      "Check that the request body is a valid JSON document",
+	apierror.ErrCodeValidationFailed: "Correct the listed fields and retry",
+	apierror.ErrCodeUnauthorized:     "Log in to obtain a valid token",
+	ErrCodeIDLocked:                  "Unlock the identity with its passphrase",
+	ErrCodeIDNotRegistered:           "Register the identity first",
+	ErrCodeIDRegistrationInProgress:  "Wait until the registration transaction is confirmed",
+	ErrCodeDiagnosticsConsent:        "Set consent to true to agree to collect diagnostics",
+	ErrCodeUpdateDisabled:            "Configure updater.url and updater.public-key to enable updates",
+	ErrCodeUpdateInProgress:          "Wait until the running update finishes",
+}
+
+// APIError represents an error returned by tequilapi.
+// It is compatible with apierror.APIError and additionally carries a remediation hint.
+// swagger:model APIError
+type APIError struct {
+	Err    APIErrorDetails `json:"error"`
+	Status int             `json:"status"`
+	Path   string          `json:"path"`
+}
+
+// APIErrorDetails describes the error.
+// swagger:model APIErrorDetails
+type APIErrorDetails struct {
+	// stable machine-readable error code
+	// example: err_insufficient_balance
+	Code string `json:"code"`
+	// human readable error message
+	Message string `json:"message"`
+	Detail  string `json:"detail,omitempty"`
+	// suggested action to resolve the error
+	// example: Top up the balance or choose a cheaper provider
+	Hint   string                         `json:"hint,omitempty"`
+	Fields map[string]apierror.FieldError `json:"fields,omitempty"`
+}
+
+// NewAPIError maps an error returned by an endpoint to the API error.
+// Code and hint of a coded error found in the chain take precedence over the generic endpoint error code,
+// coded errors not wrapped into an endpoint error are reported as unprocessable.
+func NewAPIError(err error, path string) APIError {
+	res := APIError{
+		Err:    APIErrorDetails{Code: apierror.ErrCodeInternal, Message: err.Error()},
+		Status: http.StatusInternalServerError,
+		Path:   path,
+	}
+
+	var apiErr *apierror.APIError
+	coded, isCoded := errcode.From(err)
+	switch {
+	case errors.As(err, &apiErr):
+		res.Status = apiErr.Status
+		res.Err = APIErrorDetails{
+			Code:    apiErr.Err.Code,
+			Message: apiErr.Err.Message,
+			Detail:  apiErr.Err.Detail,
+			Fields:  apiErr.Err.Fields,
+		}
+	case isCoded:
+		res.Status = http.StatusUnprocessableEntity
+		res.Err.Message = coded.Error()
+	}
+
+	if isCoded {
+		res.Err.Code = coded.Code
+		res.Err.Hint = coded.Hint
+	} else {
+		res.Err.Hint = errorHints[res.Err.Code]
+	}
+	return res
+}
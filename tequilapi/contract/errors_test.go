@@ -0,0 +1,97 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+)
+
+type causedAPIError struct {
+	*apierror.APIError
+	cause error
+}
+
+func (e *causedAPIError) Unwrap() error { return e.cause }
+
+func (e *causedAPIError) As(target interface{}) bool {
+	if t, ok := target.(**apierror.APIError); ok {
+		*t = e.APIError
+		return true
+	}
+	return false
+}
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantHint   string
+	}{
+		{
+			name:       "plain error",
+			err:        errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   apierror.ErrCodeInternal,
+		},
+		{
+			name:       "endpoint error with known hint",
+			err:        apierror.Unprocessable("not registered", ErrCodeIDNotRegistered),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   ErrCodeIDNotRegistered,
+			wantHint:   errorHints[ErrCodeIDNotRegistered],
+		},
+		{
+			name:       "coded error",
+			err:        fmt.Errorf("connect: %w", connection.ErrInsufficientBalance),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   connection.ErrCodeInsufficientBalance,
+			wantHint:   connection.ErrInsufficientBalance.Hint,
+		},
+		{
+			name: "endpoint error caused by coded error",
+			err: &causedAPIError{
+				APIError: apierror.Internal("Failed to connect", ErrCodeConnect),
+				cause:    connection.ErrInsufficientBalance,
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   connection.ErrCodeInsufficientBalance,
+			wantHint:   connection.ErrInsufficientBalance.Hint,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := NewAPIError(tt.err, "/path")
+
+			assert.Equal(t, tt.wantStatus, res.Status)
+			assert.Equal(t, tt.wantCode, res.Err.Code)
+			assert.Equal(t, tt.wantHint, res.Err.Hint)
+			assert.Equal(t, "/path", res.Path)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+// FeatureFlagsResponse lists node feature states
+// swagger:model FeatureFlagsResponseDTO
+type FeatureFlagsResponse struct {
+	Features []FeatureFlag `json:"features"`
+}
+
+// FeatureFlag represents effective state of a node feature
+// swagger:model FeatureFlagDTO
+type FeatureFlag struct {
+	// example: mtu-discovery
+	Name string `json:"name"`
+	// example: Discover path MTU of tunnels and clamp TCP MSS
+	Description string `json:"description"`
+	// example: true
+	Enabled bool `json:"enabled"`
+	// where the value comes from: default, local or remote
+	// example: remote
+	Source string `json:"source"`
+}
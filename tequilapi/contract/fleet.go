@@ -0,0 +1,167 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package contract
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/core/fleet"
+)
+
+// FleetOperationDTO is a single step of a bulk request.
+// swagger:model FleetOperationDTO
+type FleetOperationDTO struct {
+	// one of "apply_config", "rotate_token", "restart_services" or "health"
+	// example: apply_config
+	Op string `json:"op"`
+	// user config values for "apply_config", null values remove the key
+	// example: {"wireguard.listen.ports":"51820:51830"}
+	Config map[string]interface{} `json:"config,omitempty"`
+	// service types for "restart_services", all running services are restarted if empty
+	// example: ["wireguard"]
+	ServiceTypes []string `json:"service_types,omitempty"`
+}
+
+// FleetBulkRequest request to execute operations in one call.
+// swagger:model FleetBulkRequest
+type FleetBulkRequest struct {
+	// operations are executed in order
+	Operations []FleetOperationDTO `json:"operations"`
+	// execute remaining operations after a failure instead of skipping them
+	// example: false
+	ContinueOnError bool `json:"continue_on_error"`
+}
+
+// Validate validates fields in request.
+func (r FleetBulkRequest) Validate() *apierror.APIError {
+	v := apierror.NewValidator()
+	if len(r.Operations) == 0 {
+		v.Required("operations")
+	}
+	for i, op := range r.Operations {
+		switch op.Op {
+		case fleet.OpApplyConfig:
+			if len(op.Config) == 0 {
+				v.Required(fmt.Sprintf("operations[%d].config", i))
+			}
+		case fleet.OpRotateToken, fleet.OpRestartServices, fleet.OpHealth:
+		default:
+			v.Invalid(fmt.Sprintf("operations[%d].op", i), "Unknown operation")
+		}
+	}
+	return v.Err()
+}
+
+// FleetOperations converts request to fleet operations.
+func (r FleetBulkRequest) FleetOperations() []fleet.Operation {
+	ops := make([]fleet.Operation, 0, len(r.Operations))
+	for _, op := range r.Operations {
+		ops = append(ops, fleet.Operation{Op: op.Op, Config: op.Config, ServiceTypes: op.ServiceTypes})
+	}
+	return ops
+}
+
+// FleetRestartedServiceDTO describes a service replaced by a restart.
+// swagger:model FleetRestartedServiceDTO
+type FleetRestartedServiceDTO struct {
+	// example: wireguard
+	Type string `json:"type"`
+	// example: 6ba7b810-9dad-11d1-80b4-00c04fd430c8
+	OldID string `json:"old_id"`
+	// example: 6ba7b811-9dad-11d1-80b4-00c04fd430c8
+	NewID string `json:"new_id"`
+}
+
+// FleetServiceHealthDTO describes state of a single service.
+// swagger:model FleetServiceHealthDTO
+type FleetServiceHealthDTO struct {
+	// example: 6ba7b810-9dad-11d1-80b4-00c04fd430c8
+	ID string `json:"id"`
+	// example: wireguard
+	Type string `json:"type"`
+	// example: Running
+	Status string `json:"status"`
+}
+
+// FleetHealthDTO is a summary of the node health.
+// swagger:model FleetHealthDTO
+type FleetHealthDTO struct {
+	// example: 3600
+	UptimeSeconds int64 `json:"uptime_seconds"`
+	// example: passed
+	Monitoring     string                  `json:"monitoring"`
+	Services       []FleetServiceHealthDTO `json:"services"`
+	ActiveSessions int                     `json:"active_sessions"`
+}
+
+// FleetResultDTO is an outcome of a single operation.
+// swagger:model FleetResultDTO
+type FleetResultDTO struct {
+	// example: rotate_token
+	Op string `json:"op"`
+	// "ok", "failed" or "skipped"
+	// example: ok
+	Status string `json:"status"`
+	// example: failed to save config
+	Error string `json:"error,omitempty"`
+	// new API token, previously issued tokens are no longer valid
+	Token     *AuthResponse              `json:"token,omitempty"`
+	Restarted []FleetRestartedServiceDTO `json:"restarted,omitempty"`
+	Health    *FleetHealthDTO            `json:"health,omitempty"`
+}
+
+// FleetBulkResponse holds outcomes of bulk operations.
+// swagger:model FleetBulkResponse
+type FleetBulkResponse struct {
+	Results []FleetResultDTO `json:"results"`
+}
+
+// NewFleetBulkResponse creates DTO from the operation results.
+func NewFleetBulkResponse(results []fleet.Result) FleetBulkResponse {
+	res := FleetBulkResponse{Results: make([]FleetResultDTO, 0, len(results))}
+	for _, r := range results {
+		dto := FleetResultDTO{Op: r.Op, Status: r.Status, Error: r.Error}
+		if r.Token != nil {
+			token := NewAuthResponse(*r.Token)
+			dto.Token = &token
+		}
+		for _, s := range r.Restarted {
+			dto.Restarted = append(dto.Restarted, FleetRestartedServiceDTO{Type: s.Type, OldID: string(s.OldID), NewID: string(s.NewID)})
+		}
+		if r.Health != nil {
+			dto.Health = newFleetHealthDTO(*r.Health)
+		}
+		res.Results = append(res.Results, dto)
+	}
+	return res
+}
+
+func newFleetHealthDTO(health fleet.Health) *FleetHealthDTO {
+	dto := &FleetHealthDTO{
+		UptimeSeconds:  int64(health.Uptime / time.Second),
+		Monitoring:     string(health.Monitoring),
+		Services:       make([]FleetServiceHealthDTO, 0, len(health.Services)),
+		ActiveSessions: health.ActiveSessions,
+	}
+	for _, s := range health.Services {
+		dto.Services = append(dto.Services, FleetServiceHealthDTO{ID: string(s.ID), Type: s.Type, Status: string(s.Status)})
+	}
+	return dto
+}
@@ -29,6 +29,12 @@ type HealthCheckDTO struct {
 	// example: 0.0.6
 	Version   string       `json:"version"`
 	BuildInfo BuildInfoDTO `json:"build_info"`
+
+	// number of panics recovered since the node start
+	// example: 0
+	Crashes uint64 `json:"crashes"`
+	// example: 2022-01-01T12:00:00Z
+	LastCrashAt string `json:"last_crash_at,omitempty"`
 }
 
 // BuildInfoDTO holds info about build.
@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/core/management"
+)
+
+// ManagementPairingRequest request used to start remote management pairing.
+// swagger:model ManagementPairingRequest
+type ManagementPairingRequest struct {
+	// node identity to be managed remotely
+	// example: 0x0000000000000000000000000000000000000001
+	ID string `json:"id"`
+}
+
+// Validate validates fields in request.
+func (r ManagementPairingRequest) Validate() *apierror.APIError {
+	v := apierror.NewValidator()
+	if r.ID == "" {
+		v.Required("id")
+	}
+	return v.Err()
+}
+
+// ManagementPairingResponse holds the one-time pairing code.
+// swagger:model ManagementPairingResponse
+type ManagementPairingResponse struct {
+	// example: 0x0000000000000000000000000000000000000001
+	ID string `json:"id"`
+
+	// one-time code to be entered on the managing node
+	// example: K7QW2M9XHT
+	Code string `json:"code"`
+
+	ExpiresAt string `json:"expires_at"`
+}
+
+// NewManagementPairingResponse maps to API pairing code.
+func NewManagementPairingResponse(code management.PairingCode) ManagementPairingResponse {
+	return ManagementPairingResponse{
+		ID:        code.NodeID.Address,
+		Code:      code.Code,
+		ExpiresAt: code.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// ManagementPeerDTO describes a remote identity allowed to manage this node.
+// swagger:model ManagementPeerDTO
+type ManagementPeerDTO struct {
+	// example: 0x0000000000000000000000000000000000000001
+	NodeID string `json:"node_id"`
+
+	// example: 0x0000000000000000000000000000000000000002
+	PeerID string `json:"peer_id"`
+
+	PairedAt string `json:"paired_at"`
+}
+
+// ManagementPeersResponse holds paired management peers.
+// swagger:model ManagementPeersResponse
+type ManagementPeersResponse struct {
+	Peers []ManagementPeerDTO `json:"peers"`
+}
+
+// NewManagementPeersResponse maps to API management peers.
+func NewManagementPeersResponse(peers []management.Peer) ManagementPeersResponse {
+	res := ManagementPeersResponse{Peers: []ManagementPeerDTO{}}
+	for _, p := range peers {
+		res.Peers = append(res.Peers, ManagementPeerDTO{
+			NodeID:   p.NodeID.Address,
+			PeerID:   p.PeerID.Address,
+			PairedAt: p.PairedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	return res
+}
+
+// ManagementRemoteRequest request used to pair with a remote node.
+// swagger:model ManagementRemoteRequest
+type ManagementRemoteRequest struct {
+	// local identity used to authenticate to the remote node
+	// example: 0x0000000000000000000000000000000000000002
+	LocalID string `json:"local_id"`
+
+	// remote node identity
+	// example: 0x0000000000000000000000000000000000000001
+	NodeID string `json:"node_id"`
+
+	// one-time code shown by the remote node
+	// example: K7QW2M9XHT
+	Code string `json:"code"`
+}
+
+// Validate validates fields in request.
+func (r ManagementRemoteRequest) Validate() *apierror.APIError {
+	v := apierror.NewValidator()
+	if r.LocalID == "" {
+		v.Required("local_id")
+	}
+	if r.NodeID == "" {
+		v.Required("node_id")
+	}
+	if r.Code == "" {
+		v.Required("code")
+	}
+	return v.Err()
+}
+
+// ManagementRemoteDTO describes a remote node this node manages.
+// swagger:model ManagementRemoteDTO
+type ManagementRemoteDTO struct {
+	// example: 0x0000000000000000000000000000000000000001
+	NodeID string `json:"node_id"`
+
+	// example: 0x0000000000000000000000000000000000000002
+	LocalID string `json:"local_id"`
+
+	PairedAt string `json:"paired_at"`
+}
+
+// NewManagementRemoteDTO maps to API remote node.
+func NewManagementRemoteDTO(node management.Node) ManagementRemoteDTO {
+	return ManagementRemoteDTO{
+		NodeID:   node.NodeID.Address,
+		LocalID:  node.LocalID.Address,
+		PairedAt: node.PairedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// ManagementRemotesResponse holds remote nodes this node manages.
+// swagger:model ManagementRemotesResponse
+type ManagementRemotesResponse struct {
+	Remotes []ManagementRemoteDTO `json:"remotes"`
+}
+
+// NewManagementRemotesResponse maps to API remote nodes.
+func NewManagementRemotesResponse(nodes []management.Node) ManagementRemotesResponse {
+	res := ManagementRemotesResponse{Remotes: []ManagementRemoteDTO{}}
+	for _, n := range nodes {
+		res.Remotes = append(res.Remotes, NewManagementRemoteDTO(n))
+	}
+	return res
+}
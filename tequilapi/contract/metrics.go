@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"strconv"
+
+	"github.com/mysteriumnetwork/node/metrics"
+)
+
+// MetricsSnapshotResponse holds values of node internal metrics.
+// swagger:model MetricsSnapshotResponse
+type MetricsSnapshotResponse struct {
+	Counters   []MetricValueDTO     `json:"counters"`
+	Gauges     []MetricValueDTO     `json:"gauges"`
+	Histograms []MetricHistogramDTO `json:"histograms"`
+}
+
+// MetricValueDTO holds a counter or gauge value.
+// swagger:model MetricValueDTO
+type MetricValueDTO struct {
+	// example: p2p_provider_dial_attempts_total
+	Name  string  `json:"name"`
+	Help  string  `json:"help,omitempty"`
+	Value float64 `json:"value"`
+}
+
+// MetricHistogramDTO holds a histogram summary.
+// swagger:model MetricHistogramDTO
+type MetricHistogramDTO struct {
+	// example: p2p_provider_dial_duration_ms
+	Name  string  `json:"name"`
+	Help  string  `json:"help,omitempty"`
+	Count uint64  `json:"count"`
+	Sum   int64   `json:"sum"`
+	Min   int64   `json:"min"`
+	Max   int64   `json:"max"`
+	Mean  float64 `json:"mean"`
+	// values at quantiles keyed by quantile
+	// example: {"0.5": 120, "0.99": 900}
+	Quantiles map[string]int64 `json:"quantiles"`
+}
+
+// NewMetricsSnapshotResponse creates response from metrics.Snapshot
+func NewMetricsSnapshotResponse(s metrics.Snapshot) MetricsSnapshotResponse {
+	res := MetricsSnapshotResponse{
+		Counters:   make([]MetricValueDTO, 0, len(s.Counters)),
+		Gauges:     make([]MetricValueDTO, 0, len(s.Gauges)),
+		Histograms: make([]MetricHistogramDTO, 0, len(s.Histograms)),
+	}
+	for _, c := range s.Counters {
+		res.Counters = append(res.Counters, MetricValueDTO{Name: c.Name, Help: c.Help, Value: float64(c.Value)})
+	}
+	for _, g := range s.Gauges {
+		res.Gauges = append(res.Gauges, MetricValueDTO{Name: g.Name, Help: g.Help, Value: g.Value})
+	}
+	for _, h := range s.Histograms {
+		dto := MetricHistogramDTO{
+			Name:      h.Name,
+			Help:      h.Help,
+			Count:     h.Count,
+			Sum:       h.Sum,
+			Min:       h.Min,
+			Max:       h.Max,
+			Mean:      h.Mean,
+			Quantiles: make(map[string]int64, len(h.Quantiles)),
+		}
+		for _, q := range h.Quantiles {
+			dto.Quantiles[strconv.FormatFloat(q.Quantile, 'g', -1, 64)] = q.Value
+		}
+		res.Histograms = append(res.Histograms, dto)
+	}
+	return res
+}
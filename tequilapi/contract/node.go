@@ -18,11 +18,14 @@
 package contract
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
 
 	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/core/service"
 )
 
 // NodeStatusResponse a node status reflects monitoring agent POV on node availability
@@ -119,3 +122,354 @@ type ProviderSession struct {
 	Earnings         Tokens `json:"earnings"`
 	TransferredBytes int64  `json:"transferred_bytes"`
 }
+
+// ProviderEarningsForecastResponse reflects projected provider earnings until the end of the current month.
+// swagger:model ProviderEarningsForecastResponse
+type ProviderEarningsForecastResponse struct {
+	Earned     Tokens `json:"earned"`
+	Projected  Tokens `json:"projected"`
+	Total      Tokens `json:"total"`
+	DailyTrend Tokens `json:"daily_trend"`
+	MonthEnd   string `json:"month_end"`
+}
+
+// NewProviderEarningsForecastResponse creates response from node.EarningsForecast
+func NewProviderEarningsForecastResponse(forecast node.EarningsForecast) ProviderEarningsForecastResponse {
+	return ProviderEarningsForecastResponse{
+		Earned:     NewTokensFromDecimal(forecast.Earned),
+		Projected:  NewTokensFromDecimal(forecast.Projected),
+		Total:      NewTokensFromDecimal(forecast.Total),
+		DailyTrend: NewTokensFromDecimal(forecast.DailyTrend),
+		MonthEnd:   forecast.MonthEnd.Format(time.RFC3339),
+	}
+}
+
+// ProviderEarningsGoalResponse reflects provider progress towards the earnings goal.
+// swagger:model ProviderEarningsGoalResponse
+type ProviderEarningsGoalResponse struct {
+	Goal      Tokens `json:"goal"`
+	Earned    Tokens `json:"earned"`
+	Remaining Tokens `json:"remaining"`
+	// example: 42.5
+	PercentComplete float64 `json:"percent_complete"`
+	// amount to earn per day for the rest of the period to reach the goal
+	RequiredDailyRate Tokens `json:"required_daily_rate"`
+	Reached           bool   `json:"reached"`
+	// example: month
+	Period      string `json:"period"`
+	PeriodStart string `json:"period_start"`
+	PeriodEnd   string `json:"period_end"`
+}
+
+// NewProviderEarningsGoalResponse creates response from node.EarningsGoal
+func NewProviderEarningsGoalResponse(goal node.EarningsGoal) ProviderEarningsGoalResponse {
+	return ProviderEarningsGoalResponse{
+		Goal:              NewTokensFromDecimal(goal.Goal),
+		Earned:            NewTokensFromDecimal(goal.Earned),
+		Remaining:         NewTokensFromDecimal(goal.Remaining),
+		PercentComplete:   goal.PercentComplete,
+		RequiredDailyRate: NewTokensFromDecimal(goal.RequiredDailyRate),
+		Reached:           goal.Reached,
+		Period:            string(goal.Period),
+		PeriodStart:       goal.PeriodStart.Format(time.RFC3339),
+		PeriodEnd:         goal.PeriodEnd.Format(time.RFC3339),
+	}
+}
+
+// NodeUptimeReportResponse reflects node availability during a period of time.
+// swagger:model NodeUptimeReportResponse
+type NodeUptimeReportResponse struct {
+	// time the node process was started
+	StartedAt string `json:"started_at"`
+	// current node process uptime
+	UptimeSeconds int64             `json:"uptime_seconds"`
+	Total         UptimePeriodDTO   `json:"total"`
+	Periods       []UptimePeriodDTO `json:"periods"`
+}
+
+// UptimePeriodDTO describes node availability during a day or a week.
+// swagger:model UptimePeriodDTO
+type UptimePeriodDTO struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// time the node process was running
+	UpSeconds int64 `json:"up_seconds"`
+	// time at least one service was running
+	ServiceAvailableSeconds int64 `json:"service_available_seconds"`
+	// time services were running and confirmed reachable by the monitoring agent
+	ReachableSeconds int64 `json:"reachable_seconds"`
+	// example: 99.5
+	UptimePercent              float64 `json:"uptime_percent"`
+	ServiceAvailabilityPercent float64 `json:"service_availability_percent"`
+	ReachabilityPercent        float64 `json:"reachability_percent"`
+}
+
+// NewNodeUptimeReportResponse creates response from node.UptimeReport
+func NewNodeUptimeReportResponse(report node.UptimeReport) NodeUptimeReportResponse {
+	r := NodeUptimeReportResponse{
+		StartedAt:     report.StartedAt.Format(time.RFC3339),
+		UptimeSeconds: int64(report.Uptime.Seconds()),
+		Total:         newUptimePeriodDTO(report.Total),
+		Periods:       make([]UptimePeriodDTO, 0, len(report.Periods)),
+	}
+	for _, p := range report.Periods {
+		r.Periods = append(r.Periods, newUptimePeriodDTO(p))
+	}
+	return r
+}
+
+func newUptimePeriodDTO(p node.UptimePeriod) UptimePeriodDTO {
+	return UptimePeriodDTO{
+		Start:                      p.Start.Format(time.RFC3339),
+		End:                        p.End.Format(time.RFC3339),
+		UpSeconds:                  int64(p.Up.Seconds()),
+		ServiceAvailableSeconds:    int64(p.ServiceAvailable.Seconds()),
+		ReachableSeconds:           int64(p.Reachable.Seconds()),
+		UptimePercent:              p.Uptime,
+		ServiceAvailabilityPercent: p.ServiceAvailability,
+		ReachabilityPercent:        p.Reachability,
+	}
+}
+
+// ServiceReputationDTO describes how consumers see a single provider service.
+// swagger:model ServiceReputationDTO
+type ServiceReputationDTO struct {
+	// example: wireguard
+	ServiceType string `json:"service_type"`
+	// whether discovery offers the service to consumers
+	// example: true
+	Listed bool `json:"listed"`
+	// example: 2.5
+	Quality float64 `json:"quality"`
+	// example: 75.5
+	Latency float64 `json:"latency"`
+	// example: 12.4
+	Bandwidth float64 `json:"bandwidth"`
+	// example: 20
+	Uptime float64 `json:"uptime"`
+	// percentage of providers of the same service type with higher latency
+	// example: 82.5
+	LatencyPercentile float64 `json:"latency_percentile"`
+	// example: false
+	MonitoringFailed bool `json:"monitoring_failed"`
+	// number of consecutive samples monitoring failed in
+	// example: 0
+	MonitoringFailureStreak int `json:"monitoring_failure_streak"`
+	// quality trend during the requested period ("improving"/"declining"/"stable")
+	// example: stable
+	Trend string `json:"trend"`
+}
+
+// ReputationSampleDTO is a recorded provider reputation snapshot.
+// swagger:model ReputationSampleDTO
+type ReputationSampleDTO struct {
+	At       string                 `json:"at"`
+	Services []ServiceReputationDTO `json:"services"`
+}
+
+// ProviderReputationResponse reflects provider reputation as seen by consumers with its history.
+// swagger:model ProviderReputationResponse
+type ProviderReputationResponse struct {
+	ProviderID string                 `json:"provider_id"`
+	Services   []ServiceReputationDTO `json:"services"`
+	History    []ReputationSampleDTO  `json:"history"`
+}
+
+// NewProviderReputationResponse creates response from node.Reputation
+func NewProviderReputationResponse(reputation node.Reputation) ProviderReputationResponse {
+	res := ProviderReputationResponse{
+		ProviderID: reputation.ProviderID,
+		Services:   newServiceReputationDTOs(reputation.Services),
+		History:    []ReputationSampleDTO{},
+	}
+	for _, s := range reputation.History {
+		res.History = append(res.History, ReputationSampleDTO{
+			At:       s.At.Format(time.RFC3339),
+			Services: newServiceReputationDTOs(s.Services),
+		})
+	}
+	return res
+}
+
+func newServiceReputationDTOs(services []node.ServiceReputation) []ServiceReputationDTO {
+	dtos := []ServiceReputationDTO{}
+	for _, sr := range services {
+		dtos = append(dtos, ServiceReputationDTO{
+			ServiceType:             sr.ServiceType,
+			Listed:                  sr.Listed,
+			Quality:                 sr.Quality,
+			Latency:                 sr.Latency,
+			Bandwidth:               sr.Bandwidth,
+			Uptime:                  sr.Uptime,
+			LatencyPercentile:       sr.LatencyPercentile,
+			MonitoringFailed:        sr.MonitoringFailed,
+			MonitoringFailureStreak: sr.FailureStreak,
+			Trend:                   sr.Trend,
+		})
+	}
+	return dtos
+}
+
+// UplinkUsageDTO holds bytes provider sessions transferred through a single network interface.
+// swagger:model UplinkUsageDTO
+type UplinkUsageDTO struct {
+	// example: eth0
+	Interface string `json:"interface"`
+	// bytes sent to consumers
+	// example: 1048576
+	Up uint64 `json:"up"`
+	// bytes received from consumers
+	// example: 524288
+	Down uint64 `json:"down"`
+	// number of sessions served through the interface
+	// example: 3
+	Sessions int `json:"sessions"`
+	// example: 2022-01-01T12:00:00Z
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// UplinkUsageResponse holds provider traffic totals per network interface.
+// swagger:model UplinkUsageResponse
+type UplinkUsageResponse struct {
+	Interfaces []UplinkUsageDTO `json:"interfaces"`
+}
+
+// NewUplinkUsageResponse creates response from uplink usage totals.
+func NewUplinkUsageResponse(usage []service.UplinkUsage) UplinkUsageResponse {
+	res := UplinkUsageResponse{Interfaces: []UplinkUsageDTO{}}
+	for _, u := range usage {
+		dto := UplinkUsageDTO{
+			Interface: u.Interface,
+			Up:        u.Up,
+			Down:      u.Down,
+			Sessions:  u.Sessions,
+		}
+		if !u.UpdatedAt.IsZero() {
+			dto.UpdatedAt = u.UpdatedAt.Format(time.RFC3339)
+		}
+		res.Interfaces = append(res.Interfaces, dto)
+	}
+	return res
+}
+
+// AccessWindowDTO describes a weekly window during which the service is shared.
+// swagger:model AccessWindowDTO
+type AccessWindowDTO struct {
+	// example: wireguard
+	ServiceType string `json:"service_type"`
+	// weekdays the window starts on, every day if empty
+	// example: ["mon","tue"]
+	Days []string `json:"days,omitempty"`
+	// local time the window opens at
+	// example: 00:00
+	From string `json:"from"`
+	// local time the window closes at, on the next day if not after from
+	// example: 07:00
+	To string `json:"to"`
+	// whether the window is open now
+	Open bool `json:"open"`
+}
+
+// WindowUsageDTO holds utilization of a single access window occurrence.
+// swagger:model WindowUsageDTO
+type WindowUsageDTO struct {
+	// example: wireguard
+	ServiceType string `json:"service_type"`
+	// example: 2022-01-01T00:00:00Z
+	Start string `json:"start"`
+	// example: 2022-01-01T07:00:00Z
+	End string `json:"end"`
+	// number of sessions started during the window
+	// example: 3
+	Sessions int `json:"sessions"`
+	// bytes sent to consumers
+	// example: 1048576
+	Up uint64 `json:"up"`
+	// bytes received from consumers
+	// example: 524288
+	Down uint64 `json:"down"`
+}
+
+// ServiceScheduleResponse holds access windows of provider services and their utilization.
+// swagger:model ServiceScheduleResponse
+type ServiceScheduleResponse struct {
+	Windows []AccessWindowDTO `json:"windows"`
+	// service types drained until their next window opens
+	Paused []string         `json:"paused"`
+	Usage  []WindowUsageDTO `json:"usage"`
+}
+
+// NewServiceScheduleResponse creates response from service access schedule and window usage.
+func NewServiceScheduleResponse(schedule service.AccessSchedule, paused []string, usage []service.WindowUsage, now time.Time) ServiceScheduleResponse {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+
+	res := ServiceScheduleResponse{Windows: []AccessWindowDTO{}, Paused: paused, Usage: []WindowUsageDTO{}}
+	for _, w := range schedule {
+		dto := AccessWindowDTO{
+			ServiceType: w.ServiceType,
+			From:        clock(w.From),
+			To:          clock(w.To),
+		}
+		for _, d := range w.Days {
+			dto.Days = append(dto.Days, strings.ToLower(d.String()[:3]))
+		}
+		_, _, dto.Open = w.Occurrence(now)
+		res.Windows = append(res.Windows, dto)
+	}
+	for _, u := range usage {
+		res.Usage = append(res.Usage, WindowUsageDTO{
+			ServiceType: u.ServiceType,
+			Start:       u.Start.Format(time.RFC3339),
+			End:         u.End.Format(time.RFC3339),
+			Sessions:    u.Sessions,
+			Up:          u.Up,
+			Down:        u.Down,
+		})
+	}
+	return res
+}
+
+// ProviderNATTraversalResponse reflects provider NAT traversal success during a period of time.
+// swagger:model ProviderNATTraversalResponse
+type ProviderNATTraversalResponse struct {
+	NATTraversalMethodDTO
+	Methods []NATTraversalMethodDTO `json:"methods"`
+}
+
+// NATTraversalMethodDTO aggregates NAT traversal attempts of a single method.
+// swagger:model NATTraversalMethodDTO
+type NATTraversalMethodDTO struct {
+	// empty for the total of all methods
+	// example: port_mapping
+	Method    string `json:"method,omitempty"`
+	Attempts  int    `json:"attempts"`
+	Succeeded int    `json:"succeeded"`
+	// share of successful attempts
+	// example: 0.75
+	SuccessRate   float64 `json:"success_rate"`
+	AvgDurationMs int64   `json:"avg_duration_ms"`
+}
+
+// NewProviderNATTraversalResponse creates response from node.NATTraversalStats
+func NewProviderNATTraversalResponse(stats node.NATTraversalStats) ProviderNATTraversalResponse {
+	r := ProviderNATTraversalResponse{
+		NATTraversalMethodDTO: newNATTraversalMethodDTO(stats.NATTraversalMethodStats),
+		Methods:               make([]NATTraversalMethodDTO, 0, len(stats.Methods)),
+	}
+	for _, m := range stats.Methods {
+		r.Methods = append(r.Methods, newNATTraversalMethodDTO(m))
+	}
+	return r
+}
+
+func newNATTraversalMethodDTO(m node.NATTraversalMethodStats) NATTraversalMethodDTO {
+	return NATTraversalMethodDTO{
+		Method:        m.Method,
+		Attempts:      m.Attempts,
+		Succeeded:     m.Succeeded,
+		SuccessRate:   m.SuccessRate,
+		AvgDurationMs: m.AvgDuration.Milliseconds(),
+	}
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/summary"
+)
+
+// NodeSummaryResponse aggregates node state for dashboards.
+// swagger:model NodeSummaryResponse
+type NodeSummaryResponse struct {
+	// empty if there is no unlocked identity
+	Identity *SummaryIdentityDTO `json:"identity,omitempty"`
+	// example: passed
+	MonitoringStatus string              `json:"monitoring_status"`
+	Services         []SummaryServiceDTO `json:"services"`
+	ActiveSessions   SummarySessionsDTO  `json:"active_sessions"`
+	Today            SummaryTrafficDTO   `json:"today"`
+	Alerts           []SummaryAlertDTO   `json:"alerts"`
+	// example: 2022-03-04T15:30:00Z
+	GeneratedAt string `json:"generated_at"`
+}
+
+// SummaryIdentityDTO describes the unlocked node identity.
+// swagger:model SummaryIdentityDTO
+type SummaryIdentityDTO struct {
+	// example: 0x0000000000000000000000000000000000000001
+	Address string `json:"address"`
+	// example: Registered
+	RegistrationStatus string `json:"registration_status"`
+	Balance            Tokens `json:"balance_tokens"`
+	Earnings           Tokens `json:"earnings_tokens"`
+	EarningsTotal      Tokens `json:"earnings_total_tokens"`
+}
+
+// SummaryServiceDTO describes a running service.
+// swagger:model SummaryServiceDTO
+type SummaryServiceDTO struct {
+	ID string `json:"id"`
+	// example: wireguard
+	Type string `json:"type"`
+	// example: Running
+	Status string `json:"status"`
+	// empty until the first health probe
+	// example: healthy
+	Health string `json:"health,omitempty"`
+}
+
+// SummarySessionsDTO holds active sessions of the node.
+// swagger:model SummarySessionsDTO
+type SummarySessionsDTO struct {
+	Provided int `json:"provided"`
+	// state of the consumer connection
+	// example: NotConnected
+	ConsumerConnection string `json:"consumer_connection"`
+}
+
+// SummaryTrafficDTO holds totals of sessions provided since local midnight.
+// swagger:model SummaryTrafficDTO
+type SummaryTrafficDTO struct {
+	Sessions int `json:"sessions"`
+	// example: 1024
+	DataSent uint64 `json:"data_sent"`
+	// example: 1024
+	DataReceived uint64 `json:"data_received"`
+	Earnings     Tokens `json:"earnings_tokens"`
+}
+
+// SummaryAlertDTO is a condition requiring attention of the node operator.
+// swagger:model SummaryAlertDTO
+type SummaryAlertDTO struct {
+	// example: identity_unregistered
+	Code string `json:"code"`
+	// example: error
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// NewNodeSummaryResponse maps node summary to response.
+func NewNodeSummaryResponse(s summary.Summary) NodeSummaryResponse {
+	res := NodeSummaryResponse{
+		MonitoringStatus: string(s.Monitoring),
+		Services:         []SummaryServiceDTO{},
+		ActiveSessions: SummarySessionsDTO{
+			Provided:           s.ProviderSessions,
+			ConsumerConnection: string(s.ConsumerState),
+		},
+		Today: SummaryTrafficDTO{
+			Sessions:     s.Today.Sessions,
+			DataSent:     s.Today.DataSent,
+			DataReceived: s.Today.DataReceived,
+			Earnings:     NewTokens(s.Today.Earnings),
+		},
+		Alerts:      []SummaryAlertDTO{},
+		GeneratedAt: s.GeneratedAt.Format(time.RFC3339),
+	}
+	if s.Identity != nil {
+		res.Identity = &SummaryIdentityDTO{
+			Address:            s.Identity.Address,
+			RegistrationStatus: s.Identity.RegistrationStatus.String(),
+			Balance:            NewTokens(s.Identity.Balance),
+			Earnings:           NewTokens(s.Identity.Earnings),
+			EarningsTotal:      NewTokens(s.Identity.EarningsTotal),
+		}
+	}
+	for _, svc := range s.Services {
+		res.Services = append(res.Services, SummaryServiceDTO{
+			ID:     string(svc.ID),
+			Type:   svc.Type,
+			Status: string(svc.State),
+			Health: svc.Health,
+		})
+	}
+	for _, a := range s.Alerts {
+		res.Alerts = append(res.Alerts, SummaryAlertDTO{Code: a.Code, Severity: a.Severity, Message: a.Message})
+	}
+	return res
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"strings"
+
+	"github.com/mysteriumnetwork/node/core/notify"
+)
+
+// NotificationsConfigDTO holds notification channels settings. Secrets are never
+// returned, omitting them in a request keeps the stored ones.
+// swagger:model NotificationsConfigDTO
+type NotificationsConfigDTO struct {
+	// email channel is disabled if omitted
+	Email *EmailNotificationsDTO `json:"email,omitempty"`
+	// telegram channel is disabled if omitted
+	Telegram *TelegramNotificationsDTO `json:"telegram,omitempty"`
+	// overrides of message templates by event kind: alert, settlement_completed, service_down, test
+	// the first line of a rendered message is used as the subject
+	Templates map[string]string `json:"templates,omitempty"`
+	// limits notifications sent to each channel, defaults to 20
+	// example: 20
+	MaxPerHour int `json:"max_per_hour,omitempty"`
+}
+
+// EmailNotificationsDTO holds SMTP server settings.
+// swagger:model EmailNotificationsDTO
+type EmailNotificationsDTO struct {
+	// example: smtp.example.com
+	Host string `json:"host"`
+	// defaults to 587
+	// example: 587
+	Port     int    `json:"port,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// example: node@example.com
+	From string   `json:"from"`
+	To   []string `json:"to"`
+}
+
+// TelegramNotificationsDTO holds Telegram bot settings.
+// swagger:model TelegramNotificationsDTO
+type TelegramNotificationsDTO struct {
+	BotToken string `json:"bot_token,omitempty"`
+	// example: 123456789
+	ChatID string `json:"chat_id"`
+}
+
+// NewNotificationsConfigDTO maps notification config to DTO leaving out secrets.
+func NewNotificationsConfigDTO(config notify.Config) NotificationsConfigDTO {
+	dto := NotificationsConfigDTO{Templates: config.Templates, MaxPerHour: config.MaxPerHour}
+	if e := config.Email; e != nil {
+		dto.Email = &EmailNotificationsDTO{Host: e.Host, Port: e.Port, Username: e.Username, From: e.From, To: e.To}
+	}
+	if t := config.Telegram; t != nil {
+		dto.Telegram = &TelegramNotificationsDTO{ChatID: t.ChatID}
+	}
+	return dto
+}
+
+// ToConfig maps DTO to notification config, taking omitted secrets from the current config.
+// Stored SMTP password is kept only for the same server and user, so it is never sent anywhere else.
+func (dto NotificationsConfigDTO) ToConfig(current notify.Config) notify.Config {
+	config := notify.Config{Templates: dto.Templates, MaxPerHour: dto.MaxPerHour}
+	if e := dto.Email; e != nil {
+		config.Email = &notify.EmailConfig{Host: e.Host, Port: e.Port, Username: e.Username, Password: e.Password, From: e.From, To: e.To}
+		if config.Email.Password == "" && sameSMTPAccount(*config.Email, current.Email) {
+			config.Email.Password = current.Email.Password
+		}
+	}
+	if t := dto.Telegram; t != nil {
+		config.Telegram = &notify.TelegramConfig{BotToken: t.BotToken, ChatID: t.ChatID}
+		if config.Telegram.BotToken == "" && current.Telegram != nil {
+			config.Telegram.BotToken = current.Telegram.BotToken
+		}
+	}
+	return config
+}
+
+func sameSMTPAccount(e notify.EmailConfig, current *notify.EmailConfig) bool {
+	if current == nil {
+		return false
+	}
+
+	port, currentPort := e.Port, current.Port
+	if port == 0 {
+		port = notify.DefaultSMTPPort
+	}
+	if currentPort == 0 {
+		currentPort = notify.DefaultSMTPPort
+	}
+	return strings.EqualFold(e.Host, current.Host) && port == currentPort && e.Username == current.Username
+}
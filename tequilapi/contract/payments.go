@@ -0,0 +1,155 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/session/dispute"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+)
+
+// NewPromiseAuditResponse maps promise audit result to API response.
+func NewPromiseAuditResponse(audit pingpong.PromiseAudit) PromiseAuditResponse {
+	return PromiseAuditResponse{
+		ChainID:      audit.ChainID,
+		Identity:     audit.Identity.Address,
+		HermesID:     audit.HermesID.Hex(),
+		ChannelID:    audit.ChannelID,
+		LocalAmount:  audit.LocalAmount,
+		HermesAmount: audit.HermesAmount,
+		Discrepancy:  string(audit.Discrepancy),
+		Detail:       audit.Detail,
+		Recovered:    audit.Recovered,
+	}
+}
+
+// PromiseAuditResponse represents the comparison of locally stored hermes promise with the one hermes knows about.
+// swagger:model PromiseAuditResponse
+type PromiseAuditResponse struct {
+	// example: 137
+	ChainID int64 `json:"chain_id"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	Identity string `json:"identity"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	HermesID string `json:"hermes_id"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	ChannelID string `json:"channel_id"`
+
+	// amount of the latest promise in local storage
+	LocalAmount *big.Int `json:"local_amount"`
+
+	// amount of the latest promise issued by hermes
+	HermesAmount *big.Int `json:"hermes_amount"`
+
+	// one of: consistent, missing, corrupted, behind, ahead
+	// example: missing
+	Discrepancy string `json:"discrepancy"`
+
+	// example: R does not match promise hashlock
+	Detail string `json:"detail,omitempty"`
+
+	// whether the discrepancy was repaired
+	Recovered bool `json:"recovered"`
+}
+
+// DisputeRequest request used to flag a session as underpaid.
+// swagger:model DisputeRequest
+type DisputeRequest struct {
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	SessionID string `json:"session_id"`
+
+	// example: consumer stopped paying invoices
+	Reason string `json:"reason"`
+}
+
+// Validate validates fields in request.
+func (r DisputeRequest) Validate() *apierror.APIError {
+	v := apierror.NewValidator()
+	if r.SessionID == "" {
+		v.Required("session_id")
+	}
+	return v.Err()
+}
+
+// DisputeDTO represents a payment dispute of a provided session.
+// swagger:model DisputeDTO
+type DisputeDTO struct {
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	SessionID string `json:"session_id"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	ProviderID string `json:"provider_id"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	HermesID string `json:"hermes_id"`
+
+	// example: consumer stopped paying invoices
+	Reason string `json:"reason,omitempty"`
+
+	// open disputes hold promise settlement with hermes, one of: open, resolved
+	// example: open
+	Status string `json:"status"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	CreatedAt string `json:"created_at"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	ResolvedAt string `json:"resolved_at,omitempty"`
+}
+
+// NewDisputeDTO maps to API payment dispute.
+func NewDisputeDTO(d dispute.Dispute) DisputeDTO {
+	dto := DisputeDTO{
+		SessionID:  string(d.SessionID),
+		ProviderID: d.ProviderID.Address,
+		ConsumerID: d.ConsumerID.Address,
+		HermesID:   d.HermesID.Hex(),
+		Reason:     d.Reason,
+		Status:     string(d.Status),
+		CreatedAt:  d.CreatedAt.Format(time.RFC3339),
+	}
+	if !d.ResolvedAt.IsZero() {
+		dto.ResolvedAt = d.ResolvedAt.Format(time.RFC3339)
+	}
+	return dto
+}
+
+// DisputeListResponse defines payment disputes list representable as json.
+// swagger:model DisputeListResponse
+type DisputeListResponse struct {
+	Items []DisputeDTO `json:"items"`
+}
+
+// NewDisputeListResponse maps to API payment dispute list.
+func NewDisputeListResponse(disputes []dispute.Dispute) DisputeListResponse {
+	res := DisputeListResponse{Items: make([]DisputeDTO, 0, len(disputes))}
+	for _, d := range disputes {
+		res.Items = append(res.Items, NewDisputeDTO(d))
+	}
+	return res
+}
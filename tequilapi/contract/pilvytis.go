@@ -155,6 +155,10 @@ type PaymentOrderRequest struct {
 	// example: mysteriumvpn, mystnodes
 	ProjectID string `json:"project_id"`
 
+	// Referral token used to attribute the top-up to a distribution partner
+	// example: yxzNdJh1lMwS
+	ReferralToken string `json:"referral_token,omitempty"`
+
 	// example: {}
 	CallerData json.RawMessage `json:"gateway_caller_data"`
 }
@@ -162,14 +166,15 @@ type PaymentOrderRequest struct {
 // GatewayOrderRequest convenience mapper
 func (o *PaymentOrderRequest) GatewayOrderRequest(identity identity.Identity, gateway string) pilvytis.GatewayOrderRequest {
 	return pilvytis.GatewayOrderRequest{
-		Identity:    identity,
-		Gateway:     gateway,
-		MystAmount:  o.MystAmount,
-		AmountUSD:   o.AmountUSD,
-		PayCurrency: o.PayCurrency,
-		Country:     o.Country,
-		State:       o.State,
-		ProjectID:   o.ProjectID,
-		CallerData:  o.CallerData,
+		Identity:      identity,
+		Gateway:       gateway,
+		MystAmount:    o.MystAmount,
+		AmountUSD:     o.AmountUSD,
+		PayCurrency:   o.PayCurrency,
+		Country:       o.Country,
+		State:         o.State,
+		ProjectID:     o.ProjectID,
+		ReferralToken: o.ReferralToken,
+		CallerData:    o.CallerData,
 	}
 }
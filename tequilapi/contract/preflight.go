@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/preflight"
+)
+
+// PreflightCheckDTO is a result of a single startup dependency check.
+// swagger:model PreflightCheckDTO
+type PreflightCheckDTO struct {
+	// example: tun
+	Name string `json:"name"`
+	// critical check failures prevent provider from serving consumers
+	// example: true
+	Critical bool `json:"critical"`
+	// "ok", "failed" or "skipped"
+	// example: failed
+	Status string `json:"status"`
+	// example: could not open /dev/net/tun: no such file or directory
+	Error string `json:"error,omitempty"`
+	// example: Load the tun kernel module (modprobe tun) or pass /dev/net/tun to the container (--device /dev/net/tun)
+	Remedy string `json:"remedy,omitempty"`
+	// example: 12
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// PreflightReportDTO holds results of provider startup dependency checks.
+// swagger:model PreflightReportDTO
+type PreflightReportDTO struct {
+	// example: 2022-01-01T12:00:00Z
+	StartedAt string `json:"started_at,omitempty"`
+	// none of the critical checks failed
+	// example: true
+	Passed bool `json:"passed"`
+	// some of the non critical checks failed
+	// example: false
+	Degraded bool                `json:"degraded"`
+	Checks   []PreflightCheckDTO `json:"checks"`
+}
+
+// NewPreflightReportDTO creates DTO from the preflight report.
+func NewPreflightReportDTO(report preflight.Report) PreflightReportDTO {
+	dto := PreflightReportDTO{
+		Passed:   report.Passed(),
+		Degraded: report.Degraded(),
+		Checks:   []PreflightCheckDTO{},
+	}
+	if !report.StartedAt.IsZero() {
+		dto.StartedAt = report.StartedAt.Format(time.RFC3339)
+	}
+	for _, res := range report.Results {
+		dto.Checks = append(dto.Checks, PreflightCheckDTO{
+			Name:       res.Name,
+			Critical:   res.Critical,
+			Status:     res.Status,
+			Error:      res.Error,
+			Remedy:     res.Remedy,
+			DurationMS: res.Duration.Milliseconds(),
+		})
+	}
+	return dto
+}
@@ -17,7 +17,14 @@
 
 package contract
 
-import "math/big"
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/market"
+)
 
 // CurrentPriceResponse represents the price.
 // swagger:model CurrentPriceResponse
@@ -30,3 +37,89 @@ type CurrentPriceResponse struct {
 	PricePerGiB       *big.Int `json:"price_per_gib"`
 	PricePerGiBTokens Tokens   `json:"price_per_gib_tokens"`
 }
+
+// PriceBookDTO holds provider prices keyed by service type and consumer country.
+// swagger:model PriceBookDTO
+type PriceBookDTO struct {
+	// Named groups of country codes which entries can refer to instead of a single country
+	// example: {"baltics": ["LT", "LV", "EE"]}
+	Regions map[string][]string `json:"regions,omitempty"`
+	Entries []PriceBookEntryDTO `json:"entries"`
+}
+
+// PriceBookEntryDTO is a price applied to consumers of the service type from the country or region.
+// swagger:model PriceBookEntryDTO
+type PriceBookEntryDTO struct {
+	// Service type, empty for all services
+	// example: wireguard
+	ServiceType string `json:"service_type,omitempty"`
+	// Country code or region name, empty for all consumers
+	// example: DE
+	Country string `json:"country,omitempty"`
+
+	PricePerHour       *big.Int `json:"price_per_hour"`
+	PricePerHourTokens Tokens   `json:"price_per_hour_tokens"`
+	PricePerGiB        *big.Int `json:"price_per_gib"`
+	PricePerGiBTokens  Tokens   `json:"price_per_gib_tokens"`
+}
+
+// NewPriceBookDTO maps to the PriceBookDTO.
+func NewPriceBookDTO(book market.PriceBook) PriceBookDTO {
+	dto := PriceBookDTO{
+		Regions: book.Regions,
+		Entries: make([]PriceBookEntryDTO, 0, len(book.Entries)),
+	}
+	for _, e := range book.Entries {
+		dto.Entries = append(dto.Entries, PriceBookEntryDTO{
+			ServiceType:        e.ServiceType,
+			Country:            e.Country,
+			PricePerHour:       e.Price.PricePerHour,
+			PricePerHourTokens: NewTokens(e.Price.PricePerHour),
+			PricePerGiB:        e.Price.PricePerGiB,
+			PricePerGiBTokens:  NewTokens(e.Price.PricePerGiB),
+		})
+	}
+	return dto
+}
+
+// Validate validates fields in request.
+func (b PriceBookDTO) Validate() *apierror.APIError {
+	v := apierror.NewValidator()
+	for name, countries := range b.Regions {
+		if len(countries) == 0 {
+			v.Required(fmt.Sprintf("regions[%s]", name))
+		}
+	}
+	for i, e := range b.Entries {
+		if e.PricePerHour == nil {
+			v.Required(fmt.Sprintf("entries[%d].price_per_hour", i))
+		} else if e.PricePerHour.Sign() < 0 {
+			v.Invalid(fmt.Sprintf("entries[%d].price_per_hour", i), "Must not be negative")
+		}
+		if e.PricePerGiB == nil {
+			v.Required(fmt.Sprintf("entries[%d].price_per_gib", i))
+		} else if e.PricePerGiB.Sign() < 0 {
+			v.Invalid(fmt.Sprintf("entries[%d].price_per_gib", i), "Must not be negative")
+		}
+	}
+	return v.Err()
+}
+
+// PriceBook maps to the market.PriceBook.
+func (b PriceBookDTO) PriceBook() market.PriceBook {
+	book := market.PriceBook{
+		Regions: b.Regions,
+		Entries: make([]market.PriceBookEntry, 0, len(b.Entries)),
+	}
+	for _, e := range b.Entries {
+		book.Entries = append(book.Entries, market.PriceBookEntry{
+			ServiceType: e.ServiceType,
+			Country:     e.Country,
+			Price: market.Price{
+				PricePerHour: e.PricePerHour,
+				PricePerGiB:  e.PricePerGiB,
+			},
+		})
+	}
+	return book
+}
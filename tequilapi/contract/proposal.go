@@ -52,6 +52,20 @@ func NewProposalDTO(p proposal.PricedServiceProposal) ProposalDTO {
 			PerGiB:        p.Price.PricePerGiB.Uint64(),
 			PerGiBTokens:  NewTokens(p.Price.PricePerGiB),
 		},
+		Load: newProposalLoadDTO(p.Load),
+	}
+}
+
+func newProposalLoadDTO(l *market.Load) *ProposalLoadDTO {
+	if l == nil {
+		return nil
+	}
+	return &ProposalLoadDTO{
+		Sessions:          l.Sessions,
+		MaxSessions:       l.MaxSessions,
+		Throughput:        l.Throughput,
+		BandwidthHeadroom: l.BandwidthHeadroom(),
+		Utilization:       l.Utilization(),
 	}
 }
 
@@ -105,6 +119,32 @@ type ProposalDTO struct {
 
 	// Quality of the service.
 	Quality Quality `json:"quality"`
+
+	// Provider utilization, omitted when not advertised.
+	Load *ProposalLoadDTO `json:"load,omitempty"`
+}
+
+// ProposalLoadDTO holds provider utilization advertised in proposal.
+// swagger:model ProposalLoadDTO
+type ProposalLoadDTO struct {
+	// example: 3
+	Sessions int `json:"sessions"`
+
+	// Concurrent session limit, omitted when unlimited
+	// example: 10
+	MaxSessions int `json:"max_sessions,omitempty"`
+
+	// Provider traffic in bytes per second
+	// example: 1048576
+	Throughput uint64 `json:"throughput"`
+
+	// Unused bandwidth in bytes per second, omitted when capacity is unknown
+	// example: 5242880
+	BandwidthHeadroom uint64 `json:"bandwidth_headroom,omitempty"`
+
+	// Most saturated of the advertised limits, 0..1
+	// example: 0.3
+	Utilization float64 `json:"utilization"`
 }
 
 // Price represents the service price.
@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/service"
+)
+
+// ProviderPauseRequest request used to pause provider services.
+// swagger:model ProviderPauseRequest
+type ProviderPauseRequest struct {
+	// keep active sessions running while paused, defaults to true
+	// example: true
+	KeepSessions *bool `json:"keep_sessions,omitempty"`
+}
+
+// KeepActiveSessions tells if active sessions should be kept.
+func (r ProviderPauseRequest) KeepActiveSessions() bool {
+	return r.KeepSessions == nil || *r.KeepSessions
+}
+
+// ProviderPauseStatusDTO describes the provider pause.
+// swagger:model ProviderPauseStatusDTO
+type ProviderPauseStatusDTO struct {
+	Paused bool `json:"paused"`
+	// example: 2022-03-04T15:30:00Z
+	Since        string   `json:"since,omitempty"`
+	KeepSessions bool     `json:"keep_sessions"`
+	Services     []string `json:"services"`
+}
+
+// NewProviderPauseStatusDTO maps pause status to DTO.
+func NewProviderPauseStatusDTO(status service.PauseStatus) ProviderPauseStatusDTO {
+	dto := ProviderPauseStatusDTO{Paused: status.Paused, KeepSessions: status.KeepSessions, Services: []string{}}
+	if status.Paused {
+		dto.Since = status.Since.Format(time.RFC3339)
+	}
+	for _, id := range status.Services {
+		dto.Services = append(dto.Services, string(id))
+	}
+	return dto
+}
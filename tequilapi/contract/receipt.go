@@ -0,0 +1,103 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/mysteriumnetwork/node/session/receipt"
+)
+
+// NewSessionReceiptDTO maps to API session receipt.
+func NewSessionReceiptDTO(r receipt.Receipt) SessionReceiptDTO {
+	return SessionReceiptDTO{
+		SessionID:         string(r.SessionID),
+		ConsumerID:        r.ConsumerID.Address,
+		ProviderID:        r.ProviderID.Address,
+		ServiceType:       r.ServiceType,
+		StartedAt:         r.StartedAt.Format(time.RFC3339),
+		EndedAt:           r.EndedAt.Format(time.RFC3339),
+		Duration:          uint64(r.Duration().Seconds()),
+		BytesSent:         r.BytesSent,
+		BytesReceived:     r.BytesReceived,
+		Amount:            r.Amount,
+		ConsumerSignature: r.ConsumerSignature,
+		ProviderSignature: r.ProviderSignature,
+	}
+}
+
+// NewSessionReceiptListResponse maps to API session receipt list.
+func NewSessionReceiptListResponse(receipts []receipt.Receipt) SessionReceiptListResponse {
+	dtoArray := make([]SessionReceiptDTO, len(receipts))
+	for i, r := range receipts {
+		dtoArray[i] = NewSessionReceiptDTO(r)
+	}
+
+	return SessionReceiptListResponse{Items: dtoArray}
+}
+
+// SessionReceiptListResponse defines session receipt list representable as json.
+// swagger:model SessionReceiptListResponse
+type SessionReceiptListResponse struct {
+	Items []SessionReceiptDTO `json:"items"`
+}
+
+// SessionReceiptDTO represents a session receipt signed by both consumer and provider.
+// swagger:model SessionReceiptDTO
+type SessionReceiptDTO struct {
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	SessionID string `json:"session_id"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	ProviderID string `json:"provider_id"`
+
+	// example: wireguard
+	ServiceType string `json:"service_type"`
+
+	// example: 2019-06-06T11:04:43Z
+	StartedAt string `json:"started_at"`
+
+	// example: 2019-06-06T11:06:43Z
+	EndedAt string `json:"ended_at"`
+
+	// duration in seconds
+	// example: 120
+	Duration uint64 `json:"duration"`
+
+	// bytes sent by consumer to provider
+	// example: 1024
+	BytesSent uint64 `json:"bytes_sent"`
+
+	// bytes received by consumer from provider
+	// example: 1024
+	BytesReceived uint64 `json:"bytes_received"`
+
+	// amount paid by consumer
+	// example: 500000
+	Amount *big.Int `json:"amount"`
+
+	// hex encoded signature of consumer identity
+	ConsumerSignature string `json:"consumer_signature"`
+
+	// hex encoded signature of provider identity
+	ProviderSignature string `json:"provider_signature"`
+}
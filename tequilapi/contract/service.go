@@ -17,6 +17,8 @@
 
 package contract
 
+import "encoding/json"
+
 // ServiceStartRequest request used to start a service.
 // swagger:model ServiceStartRequestDTO
 type ServiceStartRequest struct {
@@ -74,6 +76,54 @@ type ServiceInfoDTO struct {
 	Proposal *ProposalDTO `json:"proposal,omitempty"`
 
 	ConnectionStatistics *ServiceStatisticsDTO `json:"connection_statistics,omitempty"`
+
+	Health *ServiceHealthDTO `json:"health,omitempty"`
+}
+
+// ServiceHealthDTO represents results of the service health probes.
+// swagger:model ServiceHealthDTO
+type ServiceHealthDTO struct {
+	// Possible values are "unknown", "healthy" and "unhealthy"
+	// example: healthy
+	Status string `json:"status"`
+
+	// example: 2022-01-01T12:00:00Z
+	CheckedAt string `json:"checked_at,omitempty"`
+
+	// error of the last failed probe
+	Error string `json:"error,omitempty"`
+
+	// number of consecutive failed probes
+	// example: 0
+	Failures int `json:"failures"`
+
+	// number of restarts caused by failed probes
+	// example: 0
+	Restarts int `json:"restarts"`
+}
+
+// ServicePreviewDTO represents the proposal which would be published to discovery for the service configuration.
+// swagger:model ServicePreviewDTO
+type ServicePreviewDTO struct {
+	// provider identity
+	// example: 0x0000000000000000000000000000000000000002
+	ProviderID string `json:"provider_id"`
+
+	// example: wireguard
+	Type string `json:"type"`
+
+	// proposal as seen by consumers, including the price
+	Proposal ProposalDTO `json:"proposal"`
+
+	// proposal payload exactly as it would be published to discovery
+	Payload json.RawMessage `json:"payload"`
+
+	// whether discovery would accept the proposal
+	// example: true
+	Valid bool `json:"valid"`
+
+	// reason discovery would reject the proposal
+	ValidationError string `json:"validation_error,omitempty"`
 }
 
 // ServiceStatisticsDTO shows the successful and attempted connection count
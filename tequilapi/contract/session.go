@@ -257,6 +257,7 @@ func NewSessionDTO(se session.History) SessionDTO {
 		ProviderID:      se.ProviderID.Address,
 		ServiceType:     se.ServiceType,
 		ConsumerCountry: se.ConsumerCountry,
+		ConsumerASN:     se.ConsumerASN,
 		ProviderCountry: se.ProviderCountry,
 		CreatedAt:       se.Started.Format(time.RFC3339),
 		BytesReceived:   se.DataReceived,
@@ -265,6 +266,9 @@ func NewSessionDTO(se session.History) SessionDTO {
 		Tokens:          se.Tokens,
 		Status:          se.Status,
 		IPType:          se.IPType,
+		LatencyMinMs:    uint64(se.LatencyMin.Milliseconds()),
+		LatencyAvgMs:    uint64(se.LatencyAvg.Milliseconds()),
+		LatencyP95Ms:    uint64(se.LatencyP95.Milliseconds()),
 	}
 }
 
@@ -292,6 +296,10 @@ type SessionDTO struct {
 	// example: NL
 	ConsumerCountry string `json:"consumer_country"`
 
+	// autonomous system of provided session consumers, resolved from the local GeoIP database
+	// example: 64500
+	ConsumerASN int `json:"consumer_asn,omitempty"`
+
 	// example: US
 	ProviderCountry string `json:"provider_country"`
 
@@ -316,4 +324,82 @@ type SessionDTO struct {
 
 	// example: residential
 	IPType string `json:"ip_type"`
+
+	// keepalive round trip times measured during the session, set when the session is completed
+	// example: 45
+	LatencyMinMs uint64 `json:"latency_min_ms"`
+	// example: 60
+	LatencyAvgMs uint64 `json:"latency_avg_ms"`
+	// example: 120
+	LatencyP95Ms uint64 `json:"latency_p95_ms"`
+}
+
+// SessionLatencyDTO represents keepalive round trip times measured during the session.
+// swagger:model SessionLatencyDTO
+type SessionLatencyDTO struct {
+	// number of measurements, zero for completed sessions
+	// example: 25
+	Samples int `json:"samples"`
+	// example: 50
+	LastMs uint64 `json:"last_ms"`
+	// example: 45
+	MinMs uint64 `json:"min_ms"`
+	// example: 60
+	AvgMs uint64 `json:"avg_ms"`
+	// example: 120
+	P95Ms uint64 `json:"p95_ms"`
+}
+
+// NewSessionLatencyDTO maps session latency to DTO.
+func NewSessionLatencyDTO(latency session.Latency) SessionLatencyDTO {
+	return SessionLatencyDTO{
+		Samples: latency.Samples,
+		LastMs:  uint64(latency.Last.Milliseconds()),
+		MinMs:   uint64(latency.Min.Milliseconds()),
+		AvgMs:   uint64(latency.Avg.Milliseconds()),
+		P95Ms:   uint64(latency.P95.Milliseconds()),
+	}
+}
+
+// NewConnectionHistoryResponse maps consumed sessions and their aggregates to API connection history.
+func NewConnectionHistoryResponse(sessions []session.History, stats session.Stats, spend session.SpendSummary) ConnectionHistoryResponse {
+	dtoArray := make([]SessionDTO, len(sessions))
+	for i, se := range sessions {
+		dtoArray[i] = NewSessionDTO(se)
+	}
+
+	spendDaily := make(map[string]*big.Int, len(spend.ByDay))
+	for date, sum := range spend.ByDay {
+		spendDaily[date.Format("2006-01-02")] = sum
+	}
+
+	return ConnectionHistoryResponse{
+		Items: dtoArray,
+		Stats: NewSessionStatsDTO(stats),
+		Spend: ConnectionSpendDTO{
+			SumTokens:         spend.Total,
+			ByProviderCountry: spend.ByProviderCountry,
+			ByServiceType:     spend.ByServiceType,
+			Daily:             spendDaily,
+		},
+	}
+}
+
+// ConnectionHistoryResponse defines consumer connection history representable as json.
+// swagger:model ConnectionHistoryResponse
+type ConnectionHistoryResponse struct {
+	Items []SessionDTO       `json:"items"`
+	Stats SessionStatsDTO    `json:"stats"`
+	Spend ConnectionSpendDTO `json:"spend"`
+}
+
+// ConnectionSpendDTO represents aggregated consumer spendings.
+// swagger:model ConnectionSpendDTO
+type ConnectionSpendDTO struct {
+	// example: 500000
+	SumTokens *big.Int `json:"sum_tokens"`
+
+	ByProviderCountry map[string]*big.Int `json:"by_provider_country"`
+	ByServiceType     map[string]*big.Int `json:"by_service_type"`
+	Daily             map[string]*big.Int `json:"daily"`
 }
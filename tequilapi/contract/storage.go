@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage/retention"
+)
+
+// StoragePruneResultDTO is an outcome of pruning a single kind of records.
+// swagger:model StoragePruneResultDTO
+type StoragePruneResultDTO struct {
+	// example: sessions
+	Name string `json:"name"`
+	// example: 120
+	Pruned int `json:"pruned"`
+	// example: database not open
+	Error string `json:"error,omitempty"`
+}
+
+// StorageMaintenanceDTO describes the running or the last storage maintenance.
+// swagger:model StorageMaintenanceDTO
+type StorageMaintenanceDTO struct {
+	// example: false
+	Running bool `json:"running"`
+	// example: 2022-01-01T12:00:00Z
+	StartedAt string `json:"started_at,omitempty"`
+	// example: 2022-01-01T12:00:05Z
+	FinishedAt string `json:"finished_at,omitempty"`
+	// finished steps, one per kind of records and the compaction
+	// example: 5
+	Step int `json:"step"`
+	// example: 5
+	Steps int `json:"steps"`
+	// step in progress
+	// example: compaction
+	Current string                  `json:"current,omitempty"`
+	Results []StoragePruneResultDTO `json:"results"`
+	// example: 1048576
+	SizeBefore int64 `json:"size_before"`
+	// example: 524288
+	SizeAfter int64 `json:"size_after"`
+	// example: 524288
+	FreedBytes int64 `json:"freed_bytes"`
+	// example: failed to compact database
+	Error string `json:"error,omitempty"`
+}
+
+// NewStorageMaintenanceDTO creates DTO from the storage maintenance report.
+func NewStorageMaintenanceDTO(report retention.Report) StorageMaintenanceDTO {
+	dto := StorageMaintenanceDTO{
+		Running:    report.Running,
+		Step:       report.Step,
+		Steps:      report.Steps,
+		Current:    report.Current,
+		Results:    []StoragePruneResultDTO{},
+		SizeBefore: report.SizeBefore,
+		SizeAfter:  report.SizeAfter,
+		FreedBytes: report.Freed(),
+		Error:      report.Error,
+	}
+	if !report.StartedAt.IsZero() {
+		dto.StartedAt = report.StartedAt.Format(time.RFC3339)
+	}
+	if !report.FinishedAt.IsZero() {
+		dto.FinishedAt = report.FinishedAt.Format(time.RFC3339)
+	}
+	for _, res := range report.Results {
+		dto.Results = append(dto.Results, StoragePruneResultDTO{Name: res.Name, Pruned: res.Pruned, Error: res.Error})
+	}
+	return dto
+}
@@ -18,8 +18,9 @@
 package contract
 
 import (
-	"github.com/mysteriumnetwork/node/config"
-	"github.com/mysteriumnetwork/terms/terms-go"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/tos"
 )
 
 // TermsRequest object is accepted by terms endpoints.
@@ -40,51 +41,109 @@ type TermsResponse struct {
 	AgreedProvider bool `json:"agreed_provider"`
 	// example: false
 	AgreedConsumer bool `json:"agreed_consumer"`
+	// Terms version accepted by provider, kept for compatibility.
 	// example: 0.0.27
 	AgreedVersion string `json:"agreed_version"`
 	// example: 0.0.27
+	ProviderAgreedVersion string `json:"provider_agreed_version,omitempty"`
+	// example: 0.0.27
+	ConsumerAgreedVersion string `json:"consumer_agreed_version,omitempty"`
+	// example: 0.0.27
 	CurrentVersion string `json:"current_version"`
+	// example: 2022-03-01T12:00:00Z
+	ProviderAgreedAt string `json:"provider_agreed_at,omitempty"`
+	// example: 2022-03-01T12:00:00Z
+	ConsumerAgreedAt string `json:"consumer_agreed_at,omitempty"`
+	// Whether the current provider terms are accepted and services may be started.
+	// example: false
+	ProviderAccepted bool `json:"provider_accepted"`
+	// Whether the current consumer terms are accepted.
+	// example: false
+	ConsumerAccepted bool `json:"consumer_accepted"`
+}
+
+// TermsDocumentsResponse object holds documents of the current terms version.
+// swagger:model TermsDocumentsResponse
+type TermsDocumentsResponse struct {
+	// example: 0.0.27
+	Version string `json:"version"`
+	// Terms documents in markdown by their name.
+	Documents map[string]string `json:"documents"`
 }
 
 const (
 	// TermsConsumerAgreed is the key which is used to store terms agreement
 	// for consumer features.
 	// This key can also be used to address the value directly in the config.
-	TermsConsumerAgreed = "terms.consumer-agreed"
+	TermsConsumerAgreed = tos.ConsumerAgreedKey
 
 	// TermsProviderAgreed is the key which is used to store terms agreement
 	// for provider features.
 	// This key can also be used to address the value directly in the config.
-	TermsProviderAgreed = "terms.provider-agreed"
+	TermsProviderAgreed = tos.ProviderAgreedKey
 
-	// TermsVersion is the key which is used to store terms agreement
+	// TermsVersion is the key which was used to store terms agreement
 	// version for both provider and consumer.
 	// This key can also be used to address the value directly in the config.
-	TermsVersion = "terms.version"
+	TermsVersion = tos.VersionKey
+
+	// TermsConsumerVersion is the key which is used to store terms
+	// version agreed by consumer.
+	TermsConsumerVersion = tos.ConsumerVersionKey
+
+	// TermsProviderVersion is the key which is used to store terms
+	// version agreed by provider.
+	TermsProviderVersion = tos.ProviderVersionKey
+
+	// TermsConsumerAgreedAt is the key which is used to store the time
+	// consumer terms were accepted.
+	TermsConsumerAgreedAt = tos.ConsumerAgreedAtKey
+
+	// TermsProviderAgreedAt is the key which is used to store the time
+	// provider terms were accepted.
+	TermsProviderAgreedAt = tos.ProviderAgreedAtKey
 )
 
 // NewTermsResp builds and returns terms agreement response.
-func NewTermsResp() *TermsResponse {
+func NewTermsResp(status tos.Status) *TermsResponse {
 	return &TermsResponse{
-		AgreedProvider: config.Current.GetBool(TermsProviderAgreed),
-		AgreedConsumer: config.Current.GetBool(TermsConsumerAgreed),
-		AgreedVersion:  config.Current.GetString(TermsVersion),
-		CurrentVersion: terms.TermsVersion,
+		AgreedProvider:        status.Provider.Agreed,
+		AgreedConsumer:        status.Consumer.Agreed,
+		AgreedVersion:         status.Provider.Version,
+		ProviderAgreedVersion: status.Provider.Version,
+		ConsumerAgreedVersion: status.Consumer.Version,
+		CurrentVersion:        status.CurrentVersion,
+		ProviderAgreedAt:      formatAgreedAt(status.Provider.AcceptedAt),
+		ConsumerAgreedAt:      formatAgreedAt(status.Consumer.AcceptedAt),
+		ProviderAccepted:      status.Provider.Accepted(status.CurrentVersion),
+		ConsumerAccepted:      status.Consumer.Accepted(status.CurrentVersion),
 	}
 }
 
-// ToMap turns a TermsRequest in to an iterable map which
-// can be mapped directly to a user config.
-func (t *TermsRequest) ToMap() map[string]interface{} {
-	give := map[string]interface{}{}
-	if t.AgreedConsumer != nil {
-		give[TermsConsumerAgreed] = *t.AgreedConsumer
+// NewTermsDocumentsResponse builds and returns current terms documents response.
+func NewTermsDocumentsResponse(version string, documents map[string][]byte) TermsDocumentsResponse {
+	resp := TermsDocumentsResponse{
+		Version:   version,
+		Documents: make(map[string]string, len(documents)),
 	}
+	for name, document := range documents {
+		resp.Documents[name] = string(document)
+	}
+	return resp
+}
 
-	if t.AgreedProvider != nil {
-		give[TermsProviderAgreed] = *t.AgreedProvider
+// ToUpdate turns a TermsRequest in to a terms acceptance update.
+func (t *TermsRequest) ToUpdate() tos.Update {
+	return tos.Update{
+		Provider: t.AgreedProvider,
+		Consumer: t.AgreedConsumer,
+		Version:  t.AgreedVersion,
 	}
-	give[TermsVersion] = t.AgreedVersion
+}
 
-	return give
+func formatAgreedAt(at time.Time) string {
+	if at.IsZero() {
+		return ""
+	}
+	return at.Format(time.RFC3339)
 }
@@ -25,6 +25,7 @@ import (
 
 	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/core/beneficiary"
+	"github.com/mysteriumnetwork/node/core/gasprice"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/payments/crypto"
 
@@ -43,10 +44,37 @@ type FeesDTO struct {
 	Settlement         *big.Int `json:"settlement"`
 	SettlementTokens   Tokens   `json:"settlement_tokens"`
 	// deprecated - confusing name
-	Hermes              uint16   `json:"hermes"`
-	HermesPercent       string   `json:"hermes_percent"`
-	DecreaseStake       *big.Int `json:"decreaseStake"`
-	DecreaseStakeTokens Tokens   `json:"decrease_stake_tokens"`
+	Hermes              uint16       `json:"hermes"`
+	HermesPercent       string       `json:"hermes_percent"`
+	DecreaseStake       *big.Int     `json:"decreaseStake"`
+	DecreaseStakeTokens Tokens       `json:"decrease_stake_tokens"`
+	GasPrice            *GasPriceDTO `json:"gas_price,omitempty"`
+}
+
+// GasPriceDTO represents the gas price quote settlements and withdrawals are checked against.
+// swagger:model GasPriceDTO
+type GasPriceDTO struct {
+	// gas price in wei
+	Price *big.Int `json:"price"`
+	// fast, standard or economy
+	Strategy string `json:"strategy"`
+	// oracle or node
+	Source string `json:"source"`
+	// gas price cap in wei, omitted if not capped
+	MaxPrice *big.Int `json:"max_price,omitempty"`
+	// settlements and withdrawals are postponed while true
+	AboveCap bool `json:"above_cap"`
+}
+
+// NewGasPriceDTO maps gas price quote to GasPriceDTO.
+func NewGasPriceDTO(q gasprice.Quote) *GasPriceDTO {
+	return &GasPriceDTO{
+		Price:    q.Price,
+		Strategy: string(q.Strategy),
+		Source:   q.Source,
+		MaxPrice: q.MaxPrice,
+		AboveCap: q.AboveCap(),
+	}
 }
 
 // CombinedFeesResponse represents transactor fees.
@@ -57,6 +85,8 @@ type CombinedFeesResponse struct {
 
 	ServerTime    time.Time `json:"server_time"`
 	HermesPercent string    `json:"hermes_percent"`
+
+	GasPrice *GasPriceDTO `json:"gas_price,omitempty"`
 }
 
 // TransactorFees represents transactor fees.
@@ -79,6 +109,42 @@ func NewTransactorFees(r *registry.Fees) TransactorFees {
 	}
 }
 
+// RegistrationFeesResponse represents registration cost quotes for an identity.
+// swagger:model RegistrationFeesResponse
+type RegistrationFeesResponse struct {
+	Identity string `json:"identity"`
+	// identity is eligible for registration paid by the network
+	FreeRegistration bool                    `json:"free_registration"`
+	Chains           []ChainRegistrationFees `json:"chains"`
+}
+
+// ChainRegistrationFees represents registration and settlement fee quotes on a single chain.
+// swagger:model ChainRegistrationFees
+type ChainRegistrationFees struct {
+	ChainID   int64  `json:"chain_id"`
+	ChainName string `json:"chain_name,omitempty"`
+
+	Registration Tokens `json:"registration"`
+	Settlement   Tokens `json:"settlement"`
+	// quote must be re-requested after this time, the earliest expiry of included fees
+	ValidUntil time.Time `json:"valid_until"`
+
+	Hermeses []HermesFee  `json:"hermeses"`
+	GasPrice *GasPriceDTO `json:"gas_price,omitempty"`
+
+	// set instead of fees when chain quote could not be fetched
+	Error string `json:"error,omitempty"`
+}
+
+// HermesFee represents the share of earnings kept by a hermes.
+// swagger:model HermesFee
+type HermesFee struct {
+	HermesID string `json:"hermes_id"`
+	Active   bool   `json:"active"`
+	// fee in percent, e.g. "0.2000"
+	HermesPercent string `json:"hermes_percent"`
+}
+
 // NewSettlementListQuery creates settlement list query with default values.
 func NewSettlementListQuery() SettlementListQuery {
 	return SettlementListQuery{
@@ -359,3 +425,46 @@ type ChainSummary struct {
 	Chains       map[int64]string `json:"chains"`
 	CurrentChain int64            `json:"current_chain"`
 }
+
+// TransactorQueueResponse represents queued transactor requests.
+// swagger:model TransactorQueueResponse
+type TransactorQueueResponse struct {
+	Jobs []TransactorJobDTO `json:"jobs"`
+}
+
+// TransactorJobDTO represents a queued transactor request.
+// swagger:model TransactorJobDTO
+type TransactorJobDTO struct {
+	// deduplication key of the request
+	Key string `json:"key"`
+	// registration or settlement
+	Kind string `json:"kind"`
+	// pending, done or failed
+	State     string `json:"state"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"last_error,omitempty"`
+	// transactor queue ID of the settlement
+	Result        string    `json:"result,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+}
+
+// NewTransactorQueueResponse maps transactor jobs to TransactorQueueResponse.
+func NewTransactorQueueResponse(jobs []registry.TransactorJob) TransactorQueueResponse {
+	res := TransactorQueueResponse{Jobs: make([]TransactorJobDTO, len(jobs))}
+	for i, job := range jobs {
+		res.Jobs[i] = TransactorJobDTO{
+			Key:           job.Key,
+			Kind:          string(job.Kind),
+			State:         string(job.State),
+			Attempts:      job.Attempts,
+			LastError:     job.LastError,
+			Result:        job.Result,
+			CreatedAt:     job.CreatedAt,
+			UpdatedAt:     job.UpdatedAt,
+			NextAttemptAt: job.NextAttemptAt,
+		}
+	}
+	return res
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+// NodeUpdateRequest request to update node from a release channel
+// swagger:model NodeUpdateRequestDTO
+type NodeUpdateRequest struct {
+	// release channel, configured channel is used if empty
+	// example: stable
+	Channel string `json:"channel"`
+}
+
+// NodeUpdateStatus represents node update status
+// swagger:model NodeUpdateStatusDTO
+type NodeUpdateStatus struct {
+	// example: idle
+	State string `json:"state"`
+	// example: stable
+	Channel string `json:"channel"`
+	// example: 1.10.0
+	CurrentVersion string `json:"current_version"`
+	// example: 1.11.0
+	LatestVersion string `json:"latest_version,omitempty"`
+	// example: true
+	UpdateAvailable bool `json:"update_available"`
+	// example: signature verification failed
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/core/alerting"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type alertingEngine interface {
+	Rules() []alerting.Rule
+	AddRule(rule alerting.Rule) (alerting.Rule, error)
+	RemoveRule(id string) error
+	Active() []alerting.Alert
+	History() []alerting.Alert
+}
+
+type alertingEndpoint struct {
+	engine alertingEngine
+}
+
+// NewAlertingEndpoint creates and returns alerting endpoint
+func NewAlertingEndpoint(engine alertingEngine) *alertingEndpoint {
+	return &alertingEndpoint{engine: engine}
+}
+
+// swagger:operation GET /node/alerts Alerting listAlerts
+// ---
+// summary: Returns alerts
+// description: Returns active alerts and recently produced alerts including resolved ones
+// responses:
+//   200:
+//     description: Alerts
+//     schema:
+//       "$ref": "#/definitions/AlertsResponse"
+func (e *alertingEndpoint) Alerts(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewAlertsResponse(e.engine.Active(), e.engine.History()), c.Writer)
+}
+
+// swagger:operation GET /node/alerts/rules Alerting listAlertRules
+// ---
+// summary: Returns alert rules
+// responses:
+//   200:
+//     description: Alert rules
+//     schema:
+//       "$ref": "#/definitions/AlertRulesResponse"
+func (e *alertingEndpoint) Rules(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewAlertRulesResponse(e.engine.Rules()), c.Writer)
+}
+
+// swagger:operation POST /node/alerts/rules Alerting addAlertRule
+// ---
+// summary: Adds alert rule
+// parameters:
+//   - in: body
+//     name: body
+//     required: true
+//     schema:
+//       $ref: "#/definitions/AlertRuleRequest"
+// responses:
+//   201:
+//     description: Added alert rule
+//     schema:
+//       "$ref": "#/definitions/AlertRuleDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (e *alertingEndpoint) AddRule(c *gin.Context) {
+	var req contract.AlertRuleRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	rule, err := e.engine.AddRule(req.ToRule())
+	if errors.Is(err, alerting.ErrInvalidRule) {
+		c.Error(apierror.BadRequest(err.Error(), contract.ErrCodeAlertRuleAdd))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal("Could not add alert rule: "+err.Error(), contract.ErrCodeAlertRuleAdd))
+		return
+	}
+	utils.WriteAsJSON(contract.NewAlertRuleDTO(rule), c.Writer, http.StatusCreated)
+}
+
+// swagger:operation DELETE /node/alerts/rules/{id} Alerting removeAlertRule
+// ---
+// summary: Removes alert rule
+// parameters:
+//   - in: path
+//     name: id
+//     description: alert rule ID
+//     type: string
+//     required: true
+// responses:
+//   202:
+//     description: Alert rule removed
+//   404:
+//     description: Alert rule not found
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (e *alertingEndpoint) RemoveRule(c *gin.Context) {
+	err := e.engine.RemoveRule(c.Param("id"))
+	if errors.Is(err, alerting.ErrRuleNotFound) {
+		c.Error(apierror.NotFound(err.Error()))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal("Could not remove alert rule: "+err.Error(), contract.ErrCodeAlertRuleRemove))
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// AddRoutesForAlerting attaches alerting endpoints to router
+func AddRoutesForAlerting(engine alertingEngine) func(*gin.Engine) error {
+	endpoint := NewAlertingEndpoint(engine)
+	return func(e *gin.Engine) error {
+		g := e.Group("/node/alerts")
+		{
+			g.GET("", endpoint.Alerts)
+			g.GET("/rules", endpoint.Rules)
+			g.POST("/rules", endpoint.AddRule)
+			g.DELETE("/rules/:id", endpoint.RemoveRule)
+		}
+		return nil
+	}
+}
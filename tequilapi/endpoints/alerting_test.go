@@ -0,0 +1,139 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/alerting"
+)
+
+type mockAlertingEngine struct {
+	rules   []alerting.Rule
+	history []alerting.Alert
+}
+
+func (m *mockAlertingEngine) Rules() []alerting.Rule {
+	return m.rules
+}
+
+func (m *mockAlertingEngine) AddRule(rule alerting.Rule) (alerting.Rule, error) {
+	if err := rule.Validate(); err != nil {
+		return alerting.Rule{}, err
+	}
+	rule.ID = "rule1"
+	m.rules = append(m.rules, rule)
+	return rule, nil
+}
+
+func (m *mockAlertingEngine) RemoveRule(id string) error {
+	for i, r := range m.rules {
+		if r.ID == id {
+			m.rules = append(m.rules[:i], m.rules[i+1:]...)
+			return nil
+		}
+	}
+	return alerting.ErrRuleNotFound
+}
+
+func (m *mockAlertingEngine) Active() []alerting.Alert {
+	var res []alerting.Alert
+	for _, a := range m.history {
+		if a.Active() {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+func (m *mockAlertingEngine) History() []alerting.Alert {
+	return m.history
+}
+
+func TestAlertingEndpoint_Rules(t *testing.T) {
+	engine := &mockAlertingEngine{}
+	router := summonTestGin()
+	require.NoError(t, AddRoutesForAlerting(engine)(router))
+
+	req := httptest.NewRequest(http.MethodPost, "/node/alerts/rules", strings.NewReader(`{"kind": "earnings_below", "threshold": "2.5", "window": "24h"}`))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.JSONEq(t, `{"id": "rule1", "kind": "earnings_below", "threshold": "2.5", "window": "24h0m0s", "severity": "warning"}`, resp.Body.String())
+	assert.Equal(t, decimal.RequireFromString("2.5"), engine.rules[0].Threshold)
+
+	req = httptest.NewRequest(http.MethodPost, "/node/alerts/rules", strings.NewReader(`{"kind": "balance_below"}`))
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/node/alerts/rules", strings.NewReader(`{"kind": "balance_below", "window": "week"}`))
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/node/alerts/rules", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"rules": [{"id": "rule1", "kind": "earnings_below", "threshold": "2.5", "window": "24h0m0s", "severity": "warning"}]}`, resp.Body.String())
+
+	req = httptest.NewRequest(http.MethodDelete, "/node/alerts/rules/rule1", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Empty(t, engine.rules)
+
+	req = httptest.NewRequest(http.MethodDelete, "/node/alerts/rules/rule1", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestAlertingEndpoint_Alerts(t *testing.T) {
+	fired := time.Date(2022, 3, 4, 10, 0, 0, 0, time.UTC)
+	engine := &mockAlertingEngine{history: []alerting.Alert{
+		{ID: "a2", RuleID: "r1", Kind: alerting.KindMonitoringFailed, Severity: alerting.SeverityError, Message: "failing", FiredAt: fired.Add(2 * time.Hour)},
+		{ID: "a1", RuleID: "r1", Kind: alerting.KindMonitoringFailed, Severity: alerting.SeverityError, Message: "failing", FiredAt: fired, ResolvedAt: fired.Add(time.Hour)},
+	}}
+	router := summonTestGin()
+	require.NoError(t, AddRoutesForAlerting(engine)(router))
+
+	req := httptest.NewRequest(http.MethodGet, "/node/alerts", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"active": [
+			{"id": "a2", "rule_id": "r1", "kind": "monitoring_failed", "severity": "error", "message": "failing", "fired_at": "2022-03-04T12:00:00Z"}
+		],
+		"history": [
+			{"id": "a2", "rule_id": "r1", "kind": "monitoring_failed", "severity": "error", "message": "failing", "fired_at": "2022-03-04T12:00:00Z"},
+			{"id": "a1", "rule_id": "r1", "kind": "monitoring_failed", "severity": "error", "message": "failing", "fired_at": "2022-03-04T10:00:00Z", "resolved_at": "2022-03-04T11:00:00Z"}
+		]
+	}`, resp.Body.String())
+}
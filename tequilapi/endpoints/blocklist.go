@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+const defaultBlockedAttemptsLimit = 100
+
+type consumerBlocklist interface {
+	Status() policy.BlocklistStatus
+	Audit(limit int) ([]policy.BlockedAttempt, error)
+}
+
+type blocklistEndpoint struct {
+	blocklist consumerBlocklist
+}
+
+// NewBlocklistEndpoint creates and returns blocklist endpoint
+func NewBlocklistEndpoint(blocklist consumerBlocklist) *blocklistEndpoint {
+	return &blocklistEndpoint{blocklist: blocklist}
+}
+
+// swagger:operation GET /blocklist Blocklist blocklistStatus
+// ---
+// summary: Returns consumer blocklist status
+// description: Returns local block lists and state of the signed abuse feed
+// responses:
+//   200:
+//     description: Blocklist status
+//     schema:
+//       "$ref": "#/definitions/BlocklistStatusDTO"
+func (endpoint *blocklistEndpoint) Status(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewBlocklistStatusDTO(endpoint.blocklist.Status()), c.Writer)
+}
+
+// swagger:operation GET /blocklist/audit Blocklist blocklistAudit
+// ---
+// summary: Returns blocked attempts
+// description: Returns the latest consumer session attempts rejected by blocklist, the newest first
+// parameters:
+//   - in: query
+//     name: limit
+//     description: maximum number of entries, 100 by default
+//     type: integer
+// responses:
+//   200:
+//     description: Blocked attempts
+//     schema:
+//       "$ref": "#/definitions/BlockedAttemptsResponse"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (endpoint *blocklistEndpoint) Audit(c *gin.Context) {
+	limit := defaultBlockedAttemptsLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.Error(apierror.BadRequestField("limit must be a positive integer", contract.ErrCodeBlocklistAudit, "limit"))
+			return
+		}
+		limit = parsed
+	}
+
+	attempts, err := endpoint.blocklist.Audit(limit)
+	if err != nil {
+		c.Error(apierror.Internal("Could not list blocked attempts: "+err.Error(), contract.ErrCodeBlocklistAudit))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewBlockedAttemptsResponse(attempts), c.Writer)
+}
+
+// AddRoutesForBlocklist attaches blocklist endpoints to router
+func AddRoutesForBlocklist(blocklist consumerBlocklist) func(*gin.Engine) error {
+	endpoint := NewBlocklistEndpoint(blocklist)
+	return func(e *gin.Engine) error {
+		g := e.Group("/blocklist")
+		{
+			g.GET("", endpoint.Status)
+			g.GET("/audit", endpoint.Audit)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/core/chains"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type chainSwitcher interface {
+	Chains() []chains.Chain
+	Active() chains.Chain
+	Pending() (chains.Chain, bool)
+	Switch(chainID int64) (bool, error)
+}
+
+type chainBalanceProvider interface {
+	ForceBalanceUpdateCached(chainID int64, id identity.Identity) *big.Int
+}
+
+type chainsEndpoint struct {
+	switcher chainSwitcher
+	balances chainBalanceProvider
+}
+
+// NewChainsEndpoint creates and returns chains endpoint
+func NewChainsEndpoint(switcher chainSwitcher, balances chainBalanceProvider) *chainsEndpoint {
+	return &chainsEndpoint{switcher: switcher, balances: balances}
+}
+
+// swagger:operation GET /chains Chains listChains
+// ---
+// summary: Returns configured chains
+// responses:
+//   200:
+//     description: Configured chains
+//     schema:
+//       "$ref": "#/definitions/ChainsResponse"
+func (ce *chainsEndpoint) List(c *gin.Context) {
+	active := ce.switcher.Active().ChainID
+
+	res := contract.ChainsResponse{Chains: []contract.ChainDTO{}}
+	for _, chain := range ce.switcher.Chains() {
+		res.Chains = append(res.Chains, contract.NewChainDTO(chain, chain.ChainID == active))
+	}
+	utils.WriteAsJSON(res, c.Writer)
+}
+
+// swagger:operation GET /chains/active Chains activeChain
+// ---
+// summary: Returns the active chain
+// responses:
+//   200:
+//     description: Active chain
+//     schema:
+//       "$ref": "#/definitions/ActiveChainResponse"
+func (ce *chainsEndpoint) Active(c *gin.Context) {
+	utils.WriteAsJSON(ce.activeChainResponse(), c.Writer)
+}
+
+// swagger:operation PUT /chains/active Chains switchActiveChain
+// ---
+// summary: Switches the active chain
+// description: The chain is saved to user config and becomes active after the node restart.
+//   Switching is rejected while a connection is active or services are running
+// parameters:
+// - in: body
+//   name: body
+//   required: true
+//   schema:
+//     $ref: "#/definitions/ActiveChainRequest"
+// responses:
+//   200:
+//     description: Active chain
+//     schema:
+//       "$ref": "#/definitions/ActiveChainResponse"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   422:
+//     description: Unable to process the request at this point
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ce *chainsEndpoint) SwitchActive(c *gin.Context) {
+	var req contract.ActiveChainRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+
+	switched, err := ce.switcher.Switch(req.ChainID)
+	switch {
+	case errors.Is(err, chains.ErrUnknownChain):
+		c.Error(apierror.BadRequestField(err.Error(), apierror.ValidateErrInvalidVal, "chain_id"))
+		return
+	case errors.Is(err, chains.ErrChainPinned), errors.Is(err, chains.ErrChainInUse):
+		c.Error(err)
+		return
+	case err != nil:
+		c.Error(apierror.Internal("Failed to switch chain: "+err.Error(), contract.ErrCodeConfigSave))
+		return
+	}
+
+	res := ce.activeChainResponse()
+	res.RestartRequired = switched
+	utils.WriteAsJSON(res, c.Writer)
+}
+
+func (ce *chainsEndpoint) activeChainResponse() contract.ActiveChainResponse {
+	res := contract.ActiveChainResponse{
+		Chain: contract.NewChainDTO(ce.switcher.Active(), true),
+	}
+	if pending, ok := ce.switcher.Pending(); ok {
+		dto := contract.NewChainDTO(pending, false)
+		res.PendingChain = &dto
+		res.RestartRequired = true
+	}
+	return res
+}
+
+// swagger:operation GET /chains/balances/{id} Chains chainBalances
+// ---
+// summary: Returns identity balances on all configured chains
+// parameters:
+// - name: id
+//   in: path
+//   description: hex address of identity
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: Identity balances per chain
+//     schema:
+//       "$ref": "#/definitions/ChainBalancesResponse"
+func (ce *chainsEndpoint) Balances(c *gin.Context) {
+	id := identity.FromAddress(c.Param("id"))
+
+	res := contract.ChainBalancesResponse{Balances: []contract.ChainBalanceDTO{}}
+	for _, chain := range ce.switcher.Chains() {
+		balance := ce.balances.ForceBalanceUpdateCached(chain.ChainID, id)
+		res.Balances = append(res.Balances, contract.ChainBalanceDTO{
+			ChainID:       chain.ChainID,
+			Balance:       balance,
+			BalanceTokens: contract.NewTokens(balance),
+		})
+	}
+	utils.WriteAsJSON(res, c.Writer)
+}
+
+// AddRoutesForChains attaches chains endpoints to router
+func AddRoutesForChains(switcher chainSwitcher, balances chainBalanceProvider) func(*gin.Engine) error {
+	ce := NewChainsEndpoint(switcher, balances)
+	return func(e *gin.Engine) error {
+		g := e.Group("/chains")
+		{
+			g.GET("", ce.List)
+			g.GET("/active", ce.Active)
+			g.PUT("/active", ce.SwitchActive)
+			g.GET("/balances/:id", ce.Balances)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"bytes"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/chains"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/metadata"
+)
+
+type mockChainSwitcher struct {
+	active    int64
+	pending   int64
+	switchErr error
+}
+
+func (m *mockChainSwitcher) Chains() []chains.Chain {
+	return []chains.Chain{
+		{ChainDefinition: metadata.ChainDefinition{ChainID: 5}, Name: "Ethereum Testnet Görli"},
+		{ChainDefinition: metadata.ChainDefinition{ChainID: 80001}, Name: "Polygon Testnet Mumbai"},
+	}
+}
+
+func (m *mockChainSwitcher) Active() chains.Chain {
+	for _, c := range m.Chains() {
+		if c.ChainID == m.active {
+			return c
+		}
+	}
+	return chains.Chain{}
+}
+
+func (m *mockChainSwitcher) Pending() (chains.Chain, bool) {
+	for _, c := range m.Chains() {
+		if c.ChainID == m.pending {
+			return c, true
+		}
+	}
+	return chains.Chain{}, false
+}
+
+func (m *mockChainSwitcher) Switch(chainID int64) (bool, error) {
+	if m.switchErr != nil {
+		return false, m.switchErr
+	}
+	m.pending = 0
+	if m.active != chainID {
+		m.pending = chainID
+	}
+	return m.pending != 0, nil
+}
+
+type mockChainBalances struct{}
+
+func (m *mockChainBalances) ForceBalanceUpdateCached(chainID int64, id identity.Identity) *big.Int {
+	return big.NewInt(chainID)
+}
+
+func Test_ChainsEndpoint_List(t *testing.T) {
+	g := summonTestGin()
+	assert.NoError(t, AddRoutesForChains(&mockChainSwitcher{active: 80001}, &mockChainBalances{})(g))
+
+	req, _ := http.NewRequest(http.MethodGet, "/chains", nil)
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"chains": [
+			{"id": 5, "name": "Ethereum Testnet Görli", "registry_address": "", "hermes_id": "", "channel_implementation_address": "", "myst_address": "", "active": false},
+			{"id": 80001, "name": "Polygon Testnet Mumbai", "registry_address": "", "hermes_id": "", "channel_implementation_address": "", "myst_address": "", "active": true}
+		]
+	}`, resp.Body.String())
+}
+
+func Test_ChainsEndpoint_SwitchActive(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		wantCode int
+	}{
+		{name: "switched", body: `{"chain_id": 5}`, wantCode: http.StatusOK},
+		{name: "invalid body", body: `{`, wantCode: http.StatusBadRequest},
+		{name: "unknown chain", body: `{"chain_id": 1}`, err: chains.ErrUnknownChain, wantCode: http.StatusBadRequest},
+		{name: "chain in use", body: `{"chain_id": 5}`, err: chains.ErrChainInUse, wantCode: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := summonTestGin()
+			assert.NoError(t, AddRoutesForChains(&mockChainSwitcher{active: 80001, switchErr: tt.err}, &mockChainBalances{})(g))
+
+			req, _ := http.NewRequest(http.MethodPut, "/chains/active", bytes.NewBufferString(tt.body))
+			resp := httptest.NewRecorder()
+			g.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantCode, resp.Code)
+		})
+	}
+}
+
+func Test_ChainsEndpoint_SwitchActiveRequiresRestart(t *testing.T) {
+	g := summonTestGin()
+	assert.NoError(t, AddRoutesForChains(&mockChainSwitcher{active: 80001}, &mockChainBalances{})(g))
+
+	req, _ := http.NewRequest(http.MethodPut, "/chains/active", bytes.NewBufferString(`{"chain_id": 5}`))
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"chain": {"id": 80001, "name": "Polygon Testnet Mumbai", "registry_address": "", "hermes_id": "", "channel_implementation_address": "", "myst_address": "", "active": true},
+		"pending_chain": {"id": 5, "name": "Ethereum Testnet Görli", "registry_address": "", "hermes_id": "", "channel_implementation_address": "", "myst_address": "", "active": false},
+		"restart_required": true
+	}`, resp.Body.String())
+}
+
+func Test_ChainsEndpoint_Balances(t *testing.T) {
+	g := summonTestGin()
+	assert.NoError(t, AddRoutesForChains(&mockChainSwitcher{active: 80001}, &mockChainBalances{})(g))
+
+	req, _ := http.NewRequest(http.MethodGet, "/chains/balances/0x1", nil)
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"chain_id":80001,"balance":80001`)
+}
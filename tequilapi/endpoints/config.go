@@ -19,6 +19,7 @@ package endpoints
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
 
 	"github.com/gin-gonic/gin"
@@ -129,6 +130,10 @@ func (api *configAPI) SetUserConfig(c *gin.Context) {
 		c.Error(apierror.ParseFailed())
 		return
 	}
+	if key, ok := cliOnlyKey("", req.Data); ok {
+		c.Error(apierror.BadRequest(fmt.Sprintf("%q can only be set via CLI flag", key), contract.ErrCodeConfigCLIOnly))
+		return
+	}
 	for k, v := range req.Data {
 		if isNil(v) {
 			log.Debug().Msgf("Clearing user config value: %q", v)
@@ -146,6 +151,22 @@ func (api *configAPI) SetUserConfig(c *gin.Context) {
 	api.GetUserConfig(c)
 }
 
+// cliOnlyKey returns the first key of the payload, including nested ones, which may only be set via CLI flag.
+func cliOnlyKey(prefix string, data map[string]interface{}) (string, bool) {
+	for k, v := range data {
+		key := prefix + k
+		if config.IsCLIOnly(key) {
+			return key, true
+		}
+		if nested, ok := v.(map[string]interface{}); ok {
+			if key, ok := cliOnlyKey(key+".", nested); ok {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
 func isNil(val interface{}) bool {
 	if val == nil {
 		return true
@@ -19,6 +19,7 @@ package endpoints
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -30,6 +31,8 @@ import (
 	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/autoconnect"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/eventbus"
@@ -51,6 +54,10 @@ type ProposalGetter interface {
 	GetProposal(id market.ProposalID) (*market.ServiceProposal, error)
 }
 
+type meteredProvider interface {
+	Status() autoconnect.MeteredStatus
+}
+
 type identityRegistry interface {
 	GetRegistrationStatus(int64, identity.Identity) (registry.RegistrationStatus, error)
 }
@@ -64,10 +71,11 @@ type ConnectionEndpoint struct {
 	proposalRepository proposalRepository
 	identityRegistry   identityRegistry
 	addressProvider    addressProvider
+	metered            meteredProvider
 }
 
 // NewConnectionEndpoint creates and returns connection endpoint
-func NewConnectionEndpoint(manager connection.MultiManager, stateProvider stateProvider, proposalRepository proposalRepository, identityRegistry identityRegistry, publisher eventbus.Publisher, addressProvider addressProvider) *ConnectionEndpoint {
+func NewConnectionEndpoint(manager connection.MultiManager, stateProvider stateProvider, proposalRepository proposalRepository, identityRegistry identityRegistry, publisher eventbus.Publisher, addressProvider addressProvider, metered meteredProvider) *ConnectionEndpoint {
 	return &ConnectionEndpoint{
 		manager:            manager,
 		publisher:          publisher,
@@ -75,6 +83,7 @@ func NewConnectionEndpoint(manager connection.MultiManager, stateProvider stateP
 		proposalRepository: proposalRepository,
 		identityRegistry:   identityRegistry,
 		addressProvider:    addressProvider,
+		metered:            metered,
 	}
 }
 
@@ -108,8 +117,7 @@ func (ce *ConnectionEndpoint) Status(c *gin.Context) {
 		}
 	}
 	status := ce.manager.Status(n)
-	statusResponse := contract.NewConnectionInfoDTO(status)
-	utils.WriteAsJSON(statusResponse, c.Writer)
+	utils.WriteAsJSON(ce.connectionInfo(status), c.Writer)
 }
 
 // Create starts new connection
@@ -200,31 +208,47 @@ func (ce *ConnectionEndpoint) Create(c *gin.Context) {
 		IncludeMonitoringFailed: cr.Filter.IncludeMonitoringFailed,
 		AccessPolicy:            "all",
 	}
-	proposalLookup := connection.FilteredProposals(f, cr.Filter.SortBy, ce.proposalRepository)
+	// Proposals are fetched while the connection manager prepares the connection.
+	proposalLookup := connection.PrefetchedProposals(f, cr.Filter.SortBy, ce.proposalRepository)
 
-	err = ce.manager.Connect(consumerID, common.HexToAddress(cr.HermesID), proposalLookup, getConnectOptions(cr))
+	params := getConnectOptions(cr)
+	err = ce.manager.Connect(consumerID, common.HexToAddress(cr.HermesID), proposalLookup, params)
 	if err != nil {
-		switch err {
-		case connection.ErrAlreadyExists:
+		switch {
+		case errors.Is(err, connection.ErrAlreadyExists):
 			ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageConnectionAlreadyExists, err.Error()))
-			c.Error(apierror.Unprocessable("Connection already exists", contract.ErrCodeConnectionAlreadyExists))
-		case connection.ErrConnectionCancelled:
+			c.Error(utils.WithCause(apierror.Unprocessable("Connection already exists", contract.ErrCodeConnectionAlreadyExists), err))
+		case errors.Is(err, connection.ErrConnectionCancelled):
 			ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageConnectionCanceled, err.Error()))
-			c.Error(apierror.Unprocessable("Connection cancelled", contract.ErrCodeConnectionCancelled))
+			c.Error(utils.WithCause(apierror.Unprocessable("Connection cancelled", contract.ErrCodeConnectionCancelled), err))
+		case errors.Is(err, connection.ErrConnectionVetoed):
+			ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageConnectionVetoed, err.Error()))
+			c.Error(utils.WithCause(contract.NewConnectionVetoedError(err), err))
+		case errors.Is(err, connection.ErrInsufficientBalance), errors.Is(err, connection.ErrUnlockRequired):
+			ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageConnectionUnknownError, err.Error()))
+			c.Error(utils.WithCause(apierror.Unprocessable("Failed to connect: "+err.Error(), contract.ErrCodeConnect), err))
 		default:
 			ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageConnectionUnknownError, err.Error()))
 			log.Error().Err(err).Msg("Failed to connect")
-			c.Error(apierror.Internal("Failed to connect: "+err.Error(), contract.ErrCodeConnect))
+			c.Error(utils.WithCause(apierror.Internal("Failed to connect: "+err.Error(), contract.ErrCodeConnect), err))
 		}
 		return
 	}
 
 	ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageConnectionOK, ""))
+	ce.publisher.Publish(autoconnect.AppTopicProfileUsed, autoconnect.AppEventProfileUsed{Profile: toAutoConnectProfile(cr, params)})
 	c.Status(http.StatusCreated)
 
 	statusResp := ce.manager.Status(cr.ConnectOptions.ProxyPort)
-	statusResponse := contract.NewConnectionInfoDTO(statusResp)
-	utils.WriteAsJSON(statusResponse, c.Writer)
+	utils.WriteAsJSON(ce.connectionInfo(statusResp), c.Writer)
+}
+
+func (ce *ConnectionEndpoint) connectionInfo(status connectionstate.Status) contract.ConnectionInfoDTO {
+	info := contract.NewConnectionInfoDTO(status)
+	if ce.metered != nil {
+		info.Metered = contract.NewMeteredDTO(ce.metered.Status())
+	}
+	return info
 }
 
 // Kill stops connection
@@ -261,11 +285,11 @@ func (ce *ConnectionEndpoint) Kill(c *gin.Context) {
 
 	err := ce.manager.Disconnect(n)
 	if err != nil {
-		switch err {
-		case connection.ErrNoConnection:
-			c.Error(apierror.Unprocessable("No connection exists", contract.ErrCodeNoConnectionExists))
+		switch {
+		case errors.Is(err, connection.ErrNoConnection):
+			c.Error(utils.WithCause(apierror.Unprocessable("No connection exists", contract.ErrCodeNoConnectionExists), err))
 		default:
-			c.Error(apierror.Internal("Could not disconnect: "+err.Error(), contract.ErrCodeDisconnect))
+			c.Error(utils.WithCause(apierror.Internal("Could not disconnect: "+err.Error(), contract.ErrCodeDisconnect), err))
 		}
 		return
 	}
@@ -287,6 +311,7 @@ func (ce *ConnectionEndpoint) GetStatistics(c *gin.Context) {
 	conn := ce.stateProvider.GetConnection(id)
 
 	response := contract.NewConnectionStatisticsDTO(conn.Session, conn.Statistics, conn.Throughput, conn.Invoice)
+	response.ProviderQoS = contract.NewProviderQoSDTO(conn.ProviderQoS)
 	utils.WriteAsJSON(response, c.Writer)
 }
 
@@ -340,8 +365,9 @@ func AddRoutesForConnection(
 	identityRegistry identityRegistry,
 	publisher eventbus.Publisher,
 	addressProvider addressProvider,
+	metered meteredProvider,
 ) func(*gin.Engine) error {
-	connectionEndpoint := NewConnectionEndpoint(manager, stateProvider, proposalRepository, identityRegistry, publisher, addressProvider)
+	connectionEndpoint := NewConnectionEndpoint(manager, stateProvider, proposalRepository, identityRegistry, publisher, addressProvider, metered)
 	return func(e *gin.Engine) error {
 		connGroup := e.Group("")
 		{
@@ -382,3 +408,17 @@ func getConnectOptions(cr *contract.ConnectionCreateRequest) connection.ConnectP
 		ProxyPort:         cr.ConnectOptions.ProxyPort,
 	}
 }
+
+func toAutoConnectProfile(cr *contract.ConnectionCreateRequest, params connection.ConnectParams) autoconnect.Profile {
+	return autoconnect.Profile{
+		ConsumerID:              cr.ConsumerID,
+		HermesID:                cr.HermesID,
+		ServiceType:             cr.ServiceType,
+		ProviderIDs:             cr.Filter.Providers,
+		CountryCode:             cr.Filter.CountryCode,
+		IPType:                  cr.Filter.IPType,
+		IncludeMonitoringFailed: cr.Filter.IncludeMonitoringFailed,
+		SortBy:                  cr.Filter.SortBy,
+		Params:                  params,
+	}
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/rs/zerolog/log"
+	"github.com/shopspring/decimal"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type hermesFeeProvider interface {
+	GetHermesFee(chainID int64, id common.Address) (uint16, error)
+}
+
+type connectionEstimateEndpoint struct {
+	proposalRepository proposalRepository
+	addressProvider    addressProvider
+	hermesFees         hermesFeeProvider
+}
+
+// NewConnectionEstimateEndpoint creates and returns connection cost estimate endpoint
+func NewConnectionEstimateEndpoint(proposalRepository proposalRepository, addressProvider addressProvider, hermesFees hermesFeeProvider) *connectionEstimateEndpoint {
+	return &connectionEstimateEndpoint{
+		proposalRepository: proposalRepository,
+		addressProvider:    addressProvider,
+		hermesFees:         hermesFees,
+	}
+}
+
+// swagger:operation POST /connection/estimate Connection connectionEstimate
+// ---
+// summary: Estimates session cost
+// description: Projects the cost of a session with each given provider for the expected usage, so providers can be compared by real cost instead of raw prices
+// parameters:
+//   - in: body
+//     name: body
+//     description: Providers and expected usage
+//     schema:
+//       $ref: "#/definitions/ConnectionEstimateRequest"
+// responses:
+//   200:
+//     description: Cost estimates, cheapest first
+//     schema:
+//       "$ref": "#/definitions/ConnectionEstimateResponse"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (endpoint *connectionEstimateEndpoint) Estimate(c *gin.Context) {
+	var req contract.ConnectionEstimateRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	chainID := config.GetInt64(config.FlagChainID)
+	hermesID := common.HexToAddress(req.HermesID)
+	if req.HermesID == "" {
+		active, err := endpoint.addressProvider.GetActiveHermes(chainID)
+		if err != nil {
+			c.Error(apierror.Internal("Failed to get active hermes", contract.ErrCodeActiveHermes))
+			return
+		}
+		hermesID = active
+	}
+
+	hermesPercent := decimal.Zero
+	if feePerMyriad, err := endpoint.hermesFees.GetHermesFee(chainID, hermesID); err != nil {
+		log.Warn().Err(err).Msgf("Could not get fee of hermes %s, estimating without it", hermesID.Hex())
+	} else {
+		hermesPercent = decimal.NewFromInt(int64(feePerMyriad)).Div(decimal.NewFromInt(10000))
+	}
+
+	duration := time.Duration(req.Hours * float64(time.Hour))
+	traffic := pingpong.DataTransferred{Down: uint64(req.GiB * float64(datasize.GiB.Bytes()))}
+
+	estimates := make([]contract.ConnectionEstimate, 0, len(req.Providers))
+	totals := make(map[string]*big.Int, len(req.Providers))
+	for _, providerID := range req.Providers {
+		estimate := contract.ConnectionEstimate{ProviderID: providerID, ServiceType: req.ServiceType}
+
+		p, err := endpoint.proposalRepository.Proposal(market.ProposalID{ProviderID: providerID, ServiceType: req.ServiceType})
+		if err != nil || p == nil {
+			estimate.Error = "proposal not found"
+			estimates = append(estimates, estimate)
+			continue
+		}
+
+		price := normalizedPrice(p.Price)
+		timeCost := pingpong.CalculatePaymentAmount(duration, pingpong.DataTransferred{}, market.Price{PricePerHour: price.PricePerHour, PricePerGiB: new(big.Int)})
+		dataCost := pingpong.CalculatePaymentAmount(0, traffic, market.Price{PricePerHour: new(big.Int), PricePerGiB: price.PricePerGiB})
+		total := new(big.Int).Add(timeCost, dataCost)
+		hermesFee := decimal.NewFromBigInt(total, 0).Mul(hermesPercent).BigInt()
+
+		priceDTO := contract.NewProposalDTO(proposal.PricedServiceProposal{ServiceProposal: p.ServiceProposal, Price: price}).Price
+		estimate.Price = &priceDTO
+		estimate.TimeCost = tokensRef(timeCost)
+		estimate.DataCost = tokensRef(dataCost)
+		estimate.Total = tokensRef(total)
+		estimate.HermesFee = tokensRef(hermesFee)
+		estimate.HermesPercent = hermesPercent.StringFixed(4)
+		estimates = append(estimates, estimate)
+		totals[providerID] = total
+	}
+
+	sort.SliceStable(estimates, func(i, j int) bool {
+		ti, tj := totals[estimates[i].ProviderID], totals[estimates[j].ProviderID]
+		if ti == nil || tj == nil {
+			return tj == nil && ti != nil
+		}
+		return ti.Cmp(tj) < 0
+	})
+
+	utils.WriteAsJSON(contract.ConnectionEstimateResponse{Estimates: estimates}, c.Writer)
+}
+
+func normalizedPrice(price market.Price) market.Price {
+	if price.PricePerHour == nil {
+		price.PricePerHour = new(big.Int)
+	}
+	if price.PricePerGiB == nil {
+		price.PricePerGiB = new(big.Int)
+	}
+	return price
+}
+
+func tokensRef(amount *big.Int) *contract.Tokens {
+	tokens := contract.NewTokens(amount)
+	return &tokens
+}
+
+// AddRoutesForConnectionEstimate attaches connection cost estimate endpoints to router
+func AddRoutesForConnectionEstimate(proposalRepository proposalRepository, addressProvider addressProvider, hermesFees hermesFeeProvider) func(*gin.Engine) error {
+	endpoint := NewConnectionEstimateEndpoint(proposalRepository, addressProvider, hermesFees)
+	return func(e *gin.Engine) error {
+		e.POST("/connection/estimate", endpoint.Estimate)
+		return nil
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+type estimateProposalRepository struct {
+	mockProposalRepository
+	prices map[string]market.Price
+}
+
+func (m *estimateProposalRepository) Proposal(id market.ProposalID) (*proposal.PricedServiceProposal, error) {
+	price, ok := m.prices[id.ProviderID]
+	if !ok {
+		return nil, nil
+	}
+	return &proposal.PricedServiceProposal{
+		ServiceProposal: market.ServiceProposal{ProviderID: id.ProviderID, ServiceType: id.ServiceType},
+		Price:           price,
+	}, nil
+}
+
+func Test_ConnectionEstimateEndpoint_Estimate(t *testing.T) {
+	myst := big.NewInt(1_000_000_000_000_000_000)
+	repository := &estimateProposalRepository{prices: map[string]market.Price{
+		// cheap per GiB, expensive per hour
+		"0x1": {PricePerHour: new(big.Int).Mul(myst, big.NewInt(3)), PricePerGiB: new(big.Int).Div(myst, big.NewInt(10))},
+		// no hourly price, pricier traffic
+		"0x2": {PricePerHour: new(big.Int), PricePerGiB: new(big.Int).Div(myst, big.NewInt(2))},
+	}}
+
+	g := summonTestGin()
+	err := AddRoutesForConnectionEstimate(repository, &mockAddressProvider{}, &mockSettler{feeToReturn: 2000})(g)
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest(http.MethodPost, "/connection/estimate", strings.NewReader(`{
+		"providers": ["0x1", "0x2", "0x3"],
+		"service_type": "wireguard",
+		"gib": 10,
+		"hours": 2
+	}`))
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var result contract.ConnectionEstimateResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Len(t, result.Estimates, 3)
+
+	// 10 GiB * 0.5 = 5 MYST
+	assert.Equal(t, "0x2", result.Estimates[0].ProviderID)
+	assert.Equal(t, "5", result.Estimates[0].Total.Human)
+	assert.Equal(t, "1", result.Estimates[0].HermesFee.Human)
+	assert.Equal(t, "0.2000", result.Estimates[0].HermesPercent)
+
+	// 2 h * 3 + 10 GiB * 0.1 = 7 MYST
+	assert.Equal(t, "0x1", result.Estimates[1].ProviderID)
+	assert.Equal(t, "6", result.Estimates[1].TimeCost.Human)
+	assert.Equal(t, "1", result.Estimates[1].DataCost.Human)
+	assert.Equal(t, "7", result.Estimates[1].Total.Human)
+
+	assert.Equal(t, "0x3", result.Estimates[2].ProviderID)
+	assert.Equal(t, "proposal not found", result.Estimates[2].Error)
+	assert.Nil(t, result.Estimates[2].Total)
+}
+
+func Test_ConnectionEstimateEndpoint_ValidatesRequest(t *testing.T) {
+	g := summonTestGin()
+	err := AddRoutesForConnectionEstimate(&estimateProposalRepository{}, &mockAddressProvider{}, &mockSettler{})(g)
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest(http.MethodPost, "/connection/estimate", strings.NewReader(`{"gib": -1}`))
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "providers")
+	assert.Contains(t, resp.Body.String(), "gib")
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+var connectionHistoryRanges = map[string]time.Duration{
+	"1d":  24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+	"90d": 90 * 24 * time.Hour,
+}
+
+type connectionHistoryEndpoint struct {
+	sessionStorage sessionStorage
+	timeGetter     func() time.Time
+}
+
+// NewConnectionHistoryEndpoint creates and returns connection history endpoint
+func NewConnectionHistoryEndpoint(sessionStorage sessionStorage) *connectionHistoryEndpoint {
+	return &connectionHistoryEndpoint{
+		sessionStorage: sessionStorage,
+		timeGetter:     time.Now,
+	}
+}
+
+// swagger:operation GET /connection/history Connection connectionHistory
+// ---
+// summary: Returns consumer connection history
+// description: Returns connections made by this node as a consumer during a period of time with aggregated spendings
+// parameters:
+//   - in: query
+//     name: range
+//     description: period of time ("1d", "7d", "30d", "90d"), defaults to "30d"
+//     type: string
+// responses:
+//   200:
+//     description: Connection history
+//     schema:
+//       "$ref": "#/definitions/ConnectionHistoryResponse"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (endpoint *connectionHistoryEndpoint) History(c *gin.Context) {
+	period, ok := connectionHistoryRanges[c.DefaultQuery("range", "30d")]
+	if !ok {
+		c.Error(apierror.BadRequest("Invalid time range", contract.ErrCodeConnectionHistory))
+		return
+	}
+
+	filter := session.NewFilter().
+		SetDirection(session.DirectionConsumed).
+		SetStartedFrom(endpoint.timeGetter().Add(-period))
+
+	sessions, err := endpoint.sessionStorage.List(filter)
+	if err != nil {
+		c.Error(apierror.Internal("Could not list connection history: "+err.Error(), contract.ErrCodeConnectionHistory))
+		return
+	}
+
+	stats := session.NewStats()
+	spend := session.NewSpendSummary()
+	for _, se := range sessions {
+		stats.Add(se)
+		spend.Add(se)
+	}
+
+	utils.WriteAsJSON(contract.NewConnectionHistoryResponse(sessions, stats, spend), c.Writer)
+}
+
+// AddRoutesForConnectionHistory attaches connection history endpoints to router
+func AddRoutesForConnectionHistory(sessionStorage sessionStorage) func(*gin.Engine) error {
+	endpoint := NewConnectionHistoryEndpoint(sessionStorage)
+	return func(e *gin.Engine) error {
+		e.GET("/connection/history", endpoint.History)
+		return nil
+	}
+}
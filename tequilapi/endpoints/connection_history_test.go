@@ -0,0 +1,91 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+func Test_ConnectionHistoryEndpoint_History(t *testing.T) {
+	now := time.Date(2022, time.June, 15, 12, 0, 0, 0, time.UTC)
+	paid := connectionSessionMock
+	paid.Tokens = big.NewInt(100)
+
+	ssm := &sessionStorageMock{sessionsToReturn: []session.History{paid}}
+	endpoint := NewConnectionHistoryEndpoint(ssm)
+	endpoint.timeGetter = func() time.Time { return now }
+
+	g := summonTestGin()
+	g.GET("/connection/history", endpoint.History)
+
+	req, _ := http.NewRequest(http.MethodGet, "/connection/history?range=7d", nil)
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(
+		t,
+		session.NewFilter().SetDirection(session.DirectionConsumed).SetStartedFrom(now.Add(-7*24*time.Hour)),
+		ssm.calledWithFilter,
+	)
+
+	parsedResponse := contract.ConnectionHistoryResponse{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &parsedResponse))
+	assert.Len(t, parsedResponse.Items, 1)
+	assert.Equal(t, 1, parsedResponse.Stats.Count)
+	assert.Equal(t, big.NewInt(100), parsedResponse.Spend.SumTokens)
+	assert.Equal(t, map[string]*big.Int{"ProviderCountry": big.NewInt(100)}, parsedResponse.Spend.ByProviderCountry)
+	assert.Equal(t, map[string]*big.Int{"serviceType": big.NewInt(100)}, parsedResponse.Spend.ByServiceType)
+	assert.Equal(t, map[string]*big.Int{"2010-01-01": big.NewInt(100)}, parsedResponse.Spend.Daily)
+}
+
+func Test_ConnectionHistoryEndpoint_HistoryErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		err      error
+		wantCode int
+	}{
+		{name: "invalid range", query: "?range=1y", wantCode: http.StatusBadRequest},
+		{name: "storage failure", err: errors.New("boom"), wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := summonTestGin()
+			g.GET("/connection/history", NewConnectionHistoryEndpoint(&sessionStorageMock{errToReturn: tt.err}).History)
+
+			req, _ := http.NewRequest(http.MethodGet, "/connection/history"+tt.query, nil)
+			resp := httptest.NewRecorder()
+			g.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantCode, resp.Code)
+		})
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/consumer/bandwidth"
 	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/autoconnect"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/state/event"
@@ -117,7 +118,7 @@ func TestAddRoutesForConnectionAddsRoutes(t *testing.T) {
 	}
 
 	mockedProposalProvider := mockRepositoryWithProposal("node1", "noop")
-	err := AddRoutesForConnection(fakeManager, fakeState, mockedProposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(router)
+	err := AddRoutesForConnection(fakeManager, fakeState, mockedProposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(router)
 	assert.NoError(t, err)
 
 	tests := []struct {
@@ -183,7 +184,7 @@ func TestStateIsReturnedFromStore(t *testing.T) {
 	}
 
 	router := summonTestGin()
-	err := AddRoutesForConnection(manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(router)
+	err := AddRoutesForConnection(manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(router)
 	assert.NoError(t, err)
 
 	req := httptest.NewRequest(http.MethodGet, "/connection", nil)
@@ -202,11 +203,52 @@ func TestStateIsReturnedFromStore(t *testing.T) {
 	)
 }
 
+type mockMeteredProvider struct {
+	status autoconnect.MeteredStatus
+}
+
+func (m mockMeteredProvider) Status() autoconnect.MeteredStatus {
+	return m.status
+}
+
+func TestAddRoutesForConnectionAddsMeteredStatus(t *testing.T) {
+	manager := &mockConnectionManager{
+		onStatusReturn: connectionstate.Status{
+			State:     connectionstate.Connected,
+			SessionID: "1",
+		},
+	}
+	metered := mockMeteredProvider{status: autoconnect.MeteredStatus{Metered: true, Policy: autoconnect.MeteredCap, Capped: true}}
+
+	router := summonTestGin()
+	err := AddRoutesForConnection(manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, metered)(router)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/connection", nil)
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"status" : "Connected",
+			"session_id" : "1",
+			"metered" : {
+				"policy" : "cap",
+				"capped" : true
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
+
 func TestPutReturns400ErrorIfRequestBodyIsNotJSON(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
 	router := summonTestGin()
-	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(router)
+	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(router)
 	assert.NoError(t, err)
 
 	req := httptest.NewRequest(http.MethodPut, "/connection", strings.NewReader("a"))
@@ -221,7 +263,7 @@ func TestPutReturns422ErrorIfRequestBodyIsMissingFieldValues(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
 	router := summonTestGin()
-	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(router)
+	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(router)
 	assert.NoError(t, err)
 
 	req := httptest.NewRequest(http.MethodPut, "/connection", strings.NewReader("{}"))
@@ -259,8 +301,14 @@ func TestPutWithValidBodyCreatesConnection(t *testing.T) {
 			}`))
 	resp := httptest.NewRecorder()
 
+	bus := eventbus.New()
+	var profiles []autoconnect.Profile
+	assert.NoError(t, bus.Subscribe(autoconnect.AppTopicProfileUsed, func(e autoconnect.AppEventProfileUsed) {
+		profiles = append(profiles, e.Profile)
+	}))
+
 	g := summonTestGin()
-	err := AddRoutesForConnection(&fakeManager, fakeState, proposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&fakeManager, fakeState, proposalProvider, mockIdentityRegistryInstance, bus, &mockAddressProvider{}, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -279,6 +327,10 @@ func TestPutWithValidBodyCreatesConnection(t *testing.T) {
 		}`,
 		resp.Body.String(),
 	)
+	assert.Len(t, profiles, 1)
+	assert.Equal(t, "my-identity", profiles[0].ConsumerID)
+	assert.Equal(t, "hermes", profiles[0].HermesID)
+	assert.Equal(t, []string{"required-node"}, profiles[0].ProviderIDs)
 }
 
 func TestPutUnregisteredIdentityReturnsError(t *testing.T) {
@@ -300,13 +352,14 @@ func TestPutUnregisteredIdentityReturnsError(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, proposalProvider, &mir, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, proposalProvider, &mir, eventbus.New(), &mockAddressProvider{}, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
 
 	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
 	assert.Equal(t, "err_id_not_registered", apierror.Parse(resp.Result()).Err.Code)
+	assert.Never(t, func() bool { return proposalProvider.lastFilter() != nil }, 50*time.Millisecond, 5*time.Millisecond, "unregistered identity must not fetch proposals")
 }
 
 func TestPutFailedRegistrationCheckReturnsError(t *testing.T) {
@@ -328,7 +381,7 @@ func TestPutFailedRegistrationCheckReturnsError(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, proposalProvider, &mir, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, proposalProvider, &mir, eventbus.New(), &mockAddressProvider{}, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -356,7 +409,7 @@ func TestPutWithServiceTypeOverridesDefault(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, mystAPI, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, mystAPI, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -376,7 +429,7 @@ func TestDeleteCallsDisconnect(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -392,6 +445,13 @@ func TestGetStatisticsEndpointReturnsStatistics(t *testing.T) {
 		Statistics: connectionstate.Statistics{BytesSent: 1, BytesReceived: 2},
 		Throughput: bandwidth.Throughput{Up: datasize.BitSpeed(1000), Down: datasize.BitSpeed(2000)},
 		Invoice:    crypto.Invoice{AgreementTotal: big.NewInt(10001)},
+		ProviderQoS: connectionstate.QoS{
+			PacketLoss:         0.25,
+			RTT:                40 * time.Millisecond,
+			ThroughputSent:     100,
+			ThroughputReceived: 200,
+			ReportedAt:         time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
 	}
 
 	manager := mockConnectionManager{}
@@ -410,7 +470,7 @@ func TestGetStatisticsEndpointReturnsStatistics(t *testing.T) {
 			}`))
 
 	g := summonTestGin()
-	err := AddRoutesForConnection(&manager, fakeState, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&manager, fakeState, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -428,6 +488,13 @@ func TestGetStatisticsEndpointReturnsStatistics(t *testing.T) {
 				"ether": "0.000000000000010001",
 				"human": "0",
 				"wei": "10001"
+			},
+			"provider_qos": {
+				"packet_loss": 0.25,
+				"rtt_ms": 40,
+				"throughput_sent": 800,
+				"throughput_received": 1600,
+				"reported_at": "2022-01-01T00:00:00Z"
 			}
 		}`,
 		resp.Body.String(),
@@ -452,7 +519,7 @@ func TestEndpointReturnsConflictStatusIfConnectionAlreadyExists(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForConnection(&manager, nil, mystAPI, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&manager, nil, mystAPI, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -465,7 +532,7 @@ func TestEndpointReturnsConflictStatusIfConnectionAlreadyExists(t *testing.T) {
 	manager := mockConnectionManager{}
 	manager.onDisconnectReturn = connection.ErrNoConnection
 
-	connectionEndpoint := NewConnectionEndpoint(&manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)
 
 	req := httptest.NewRequest(
 		http.MethodDelete,
@@ -503,7 +570,7 @@ func TestConnectReturnsConnectCancelledStatusWhenErrConnectionCancelledIsEncount
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForConnection(&manager, nil, mockProposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&manager, nil, mockProposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -512,6 +579,36 @@ func TestConnectReturnsConnectCancelledStatusWhenErrConnectionCancelledIsEncount
 	assert.Equal(t, "err_connection_cancelled", apierror.Parse(resp.Result()).Err.Code)
 }
 
+func TestConnectReturnsVetoReasonWhenPreConnectHookVetoes(t *testing.T) {
+	veto := connection.NewVetoError("country_blocked", "country is blocked by policy")
+	veto.Hook = "policy"
+	manager := mockConnectionManager{}
+	manager.onConnectReturn = connection.ErrConnectionVetoed.Wrap(veto)
+
+	mockProposalProvider := mockRepositoryWithProposal("required-node", "openvpn")
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/connection",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "required-node",
+				"hermes_id" : "hermes"
+			}`))
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&manager, nil, mockProposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	apiErr := apierror.Parse(resp.Result())
+	assert.Equal(t, "err_connection_vetoed", apiErr.Err.Code)
+	assert.Equal(t, apierror.FieldError{Code: "country_blocked", Message: "country is blocked by policy"}, apiErr.Err.Fields["policy"])
+}
+
 func TestConnectReturnsErrorIfNoProposals(t *testing.T) {
 	manager := mockConnectionManager{}
 	manager.onConnectReturn = connection.ErrConnectionCancelled
@@ -528,7 +625,7 @@ func TestConnectReturnsErrorIfNoProposals(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForConnection(&manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
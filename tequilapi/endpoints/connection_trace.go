@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type transitionLog interface {
+	Transitions() []connectionstate.AppEventConnectionTransition
+}
+
+type connectionTraceEndpoint struct {
+	transitions transitionLog
+}
+
+// NewConnectionTraceEndpoint creates and returns connection trace endpoint
+func NewConnectionTraceEndpoint(transitions transitionLog) *connectionTraceEndpoint {
+	return &connectionTraceEndpoint{transitions: transitions}
+}
+
+// swagger:operation GET /connection/trace Connection connectionTrace
+// ---
+// summary: Returns connection state transitions
+// description: Returns the last consumer connection state machine transitions with their causes, oldest first
+// parameters:
+//   - in: query
+//     name: session_id
+//     description: return only transitions of the given session
+//     type: string
+// responses:
+//   200:
+//     description: Connection state transitions
+//     schema:
+//       "$ref": "#/definitions/ConnectionTraceResponse"
+func (endpoint *connectionTraceEndpoint) Trace(c *gin.Context) {
+	sessionID := c.Query("session_id")
+
+	transitions := endpoint.transitions.Transitions()
+	if sessionID != "" {
+		filtered := transitions[:0]
+		for _, t := range transitions {
+			if string(t.SessionID) == sessionID {
+				filtered = append(filtered, t)
+			}
+		}
+		transitions = filtered
+	}
+
+	utils.WriteAsJSON(contract.NewConnectionTraceResponse(transitions), c.Writer)
+}
+
+// AddRoutesForConnectionTrace attaches connection trace endpoints to router
+func AddRoutesForConnectionTrace(transitions transitionLog) func(*gin.Engine) error {
+	endpoint := NewConnectionTraceEndpoint(transitions)
+	return func(e *gin.Engine) error {
+		e.GET("/connection/trace", endpoint.Trace)
+		return nil
+	}
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+)
+
+type mockTransitionLog struct {
+	transitions []connectionstate.AppEventConnectionTransition
+}
+
+func (m *mockTransitionLog) Transitions() []connectionstate.AppEventConnectionTransition {
+	return m.transitions
+}
+
+func Test_ConnectionTraceEndpoint_Trace(t *testing.T) {
+	at := time.Date(2022, time.June, 15, 12, 0, 0, 0, time.UTC)
+	transitions := &mockTransitionLog{transitions: []connectionstate.AppEventConnectionTransition{
+		{From: connectionstate.NotConnected, To: connectionstate.Connecting, Cause: "connect requested", At: at, SessionID: "s1"},
+		{From: connectionstate.Connecting, To: connectionstate.NotConnected, Cause: "connect failed: boom", At: at, SessionID: "s1"},
+		{From: connectionstate.NotConnected, To: connectionstate.Connecting, Cause: "connect requested", At: at, SessionID: "s2"},
+	}}
+
+	g := summonTestGin()
+	err := AddRoutesForConnectionTrace(transitions)(g)
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest(http.MethodGet, "/connection/trace?session_id=s1", nil)
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"transitions": [
+			{"from": "NotConnected", "to": "Connecting", "cause": "connect requested", "at": "2022-06-15T12:00:00Z", "session_id": "s1"},
+			{"from": "Connecting", "to": "NotConnected", "cause": "connect failed: boom", "at": "2022-06-15T12:00:00Z", "session_id": "s1"}
+		]
+	}`, resp.Body.String())
+}
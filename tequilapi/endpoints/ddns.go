@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/core/ddns"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type ddnsStatusProvider interface {
+	Status() ddns.Status
+}
+
+type ddnsEndpoint struct {
+	updater ddnsStatusProvider
+}
+
+// NewDDNSEndpoint creates and returns dynamic DNS endpoint
+func NewDDNSEndpoint(updater ddnsStatusProvider) *ddnsEndpoint {
+	return &ddnsEndpoint{updater: updater}
+}
+
+// swagger:operation GET /node/ddns Node getDDNSStatus
+// ---
+// summary: Returns dynamic DNS status
+// description: Returns the hostname kept pointed at node public IP, which can be used instead of the IP e.g. in remote management configuration.
+// responses:
+//   200:
+//     description: Dynamic DNS status
+//     schema:
+//       "$ref": "#/definitions/DDNSStatusDTO"
+func (e *ddnsEndpoint) Status(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewDDNSStatusDTO(e.updater.Status()), c.Writer)
+}
+
+// AddRoutesForDDNS attaches dynamic DNS endpoints to router
+func AddRoutesForDDNS(updater ddnsStatusProvider) func(*gin.Engine) error {
+	endpoint := NewDDNSEndpoint(updater)
+	return func(e *gin.Engine) error {
+		e.GET("/node/ddns", endpoint.Status)
+		return nil
+	}
+}
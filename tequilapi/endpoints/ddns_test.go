@@ -0,0 +1,52 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/ddns"
+)
+
+type mockDDNSUpdater struct {
+	status ddns.Status
+}
+
+func (m *mockDDNSUpdater) Status() ddns.Status {
+	return m.status
+}
+
+func TestDDNSEndpoint(t *testing.T) {
+	updater := &mockDDNSUpdater{status: ddns.Status{
+		Hostname:  "mynode.duckdns.org",
+		IP:        "1.2.3.4",
+		UpdatedAt: time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC),
+	}}
+	router := summonTestGin()
+	assert.NoError(t, AddRoutesForDDNS(updater)(router))
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/node/ddns", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"hostname": "mynode.duckdns.org", "ip": "1.2.3.4", "updated_at": "2022-01-01T12:00:00Z"}`, resp.Body.String())
+}
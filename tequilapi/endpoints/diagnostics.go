@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type diagnosticsBundler interface {
+	Generate(ctx context.Context) (string, error)
+}
+
+type diagnosticsEndpoint struct {
+	bundler diagnosticsBundler
+}
+
+// NewDiagnosticsEndpoint creates and returns diagnostics endpoint
+func NewDiagnosticsEndpoint(bundler diagnosticsBundler) *diagnosticsEndpoint {
+	return &diagnosticsEndpoint{bundler: bundler}
+}
+
+// swagger:operation POST /diagnostics Diagnostics createDiagnosticsBundle
+// ---
+// summary: Generates diagnostics bundle
+// description: Gathers recent logs, redacted configuration, NAT type, route table, firewall rules, active sessions and last errors into a single archive for support. Requires explicit consent.
+// parameters:
+//   - in: body
+//     name: body
+//     description: Diagnostics request
+//     schema:
+//       $ref: "#/definitions/DiagnosticsRequestDTO"
+// responses:
+//   200:
+//     description: Diagnostics bundle created
+//     schema:
+//       "$ref": "#/definitions/DiagnosticsResponseDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (e *diagnosticsEndpoint) Create(c *gin.Context) {
+	var req contract.DiagnosticsRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+	if !req.Consent {
+		c.Error(apierror.BadRequest("Consent is required to collect diagnostics", contract.ErrCodeDiagnosticsConsent))
+		return
+	}
+
+	path, err := e.bundler.Generate(c.Request.Context())
+	if err != nil {
+		c.Error(apierror.Internal("Could not create diagnostics bundle: "+err.Error(), contract.ErrCodeDiagnosticsBundle))
+		return
+	}
+
+	utils.WriteAsJSON(contract.DiagnosticsResponse{Path: path}, c.Writer)
+}
+
+// AddRoutesForDiagnostics attaches diagnostics endpoints to router
+func AddRoutesForDiagnostics(bundler diagnosticsBundler) func(*gin.Engine) error {
+	endpoint := NewDiagnosticsEndpoint(bundler)
+	return func(e *gin.Engine) error {
+		e.POST("/diagnostics", endpoint.Create)
+		return nil
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockDiagnosticsBundler struct {
+	generated bool
+}
+
+func (m *mockDiagnosticsBundler) Generate(ctx context.Context) (string, error) {
+	m.generated = true
+	return "/tmp/diagnostics.zip", nil
+}
+
+func TestDiagnosticsEndpoint_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		wantStatus    int
+		wantGenerated bool
+	}{
+		{name: "with consent", body: `{"consent": true}`, wantStatus: http.StatusOK, wantGenerated: true},
+		{name: "without consent", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid body", body: `{`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundler := &mockDiagnosticsBundler{}
+			router := summonTestGin()
+			err := AddRoutesForDiagnostics(bundler)(router)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/diagnostics", strings.NewReader(tt.body))
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			assert.Equal(t, tt.wantGenerated, bundler.generated)
+			if tt.wantGenerated {
+				assert.JSONEq(t, `{"path": "/tmp/diagnostics.zip"}`, resp.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/featureflag"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type featureFlags interface {
+	All() []featureflag.State
+}
+
+type featuresEndpoint struct {
+	flags featureFlags
+}
+
+// NewFeaturesEndpoint creates and returns feature flags endpoint
+func NewFeaturesEndpoint(flags featureFlags) *featuresEndpoint {
+	return &featuresEndpoint{flags: flags}
+}
+
+// swagger:operation GET /features Features listFeatures
+// ---
+// summary: Returns node feature flags
+// description: Returns effective state of node features and where each value comes from
+// responses:
+//   200:
+//     description: Feature flags
+//     schema:
+//       "$ref": "#/definitions/FeatureFlagsResponseDTO"
+func (e *featuresEndpoint) List(c *gin.Context) {
+	res := contract.FeatureFlagsResponse{Features: []contract.FeatureFlag{}}
+	for _, state := range e.flags.All() {
+		res.Features = append(res.Features, contract.FeatureFlag{
+			Name:        string(state.Name),
+			Description: state.Description,
+			Enabled:     state.Enabled,
+			Source:      string(state.Source),
+		})
+	}
+
+	utils.WriteAsJSON(res, c.Writer)
+}
+
+// AddRoutesForFeatures attaches feature flags endpoints to router
+func AddRoutesForFeatures(flags featureFlags) func(*gin.Engine) error {
+	endpoint := NewFeaturesEndpoint(flags)
+	return func(e *gin.Engine) error {
+		e.GET("/features", endpoint.List)
+		return nil
+	}
+}
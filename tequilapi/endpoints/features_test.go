@@ -0,0 +1,50 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/featureflag"
+)
+
+func TestFeaturesEndpoint_List(t *testing.T) {
+	router := summonTestGin()
+	flags := featureflag.NewFlags(map[featureflag.Flag]bool{featureflag.SelfUpdate: false})
+	err := AddRoutesForFeatures(flags)(router)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/features", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"features": [
+			{"name": "camouflage", "description": "Carry WireGuard traffic over TLS on the camouflage listener", "enabled": true, "source": "default"},
+			{"name": "direct-topup", "description": "Offer top-ups paid directly on chain", "enabled": true, "source": "default"},
+			{"name": "free-tier", "description": "Serve free tier allowance to consumers", "enabled": true, "source": "default"},
+			{"name": "mtu-discovery", "description": "Discover path MTU of tunnels and clamp TCP MSS", "enabled": true, "source": "default"},
+			{"name": "self-update", "description": "Update node binary from release channels", "enabled": false, "source": "local"}
+		]
+	}`, resp.Body.String())
+}
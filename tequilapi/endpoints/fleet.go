@@ -0,0 +1,92 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package endpoints
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/core/fleet"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type fleetOperator interface {
+	Run(ops []fleet.Operation, continueOnError bool) []fleet.Result
+}
+
+type fleetEndpoint struct {
+	operator fleetOperator
+}
+
+// NewFleetEndpoint creates and returns fleet orchestration endpoint
+func NewFleetEndpoint(operator fleetOperator) *fleetEndpoint {
+	return &fleetEndpoint{operator: operator}
+}
+
+// swagger:operation POST /fleet/bulk Fleet fleetBulk
+// ---
+// summary: Executes operations issued by an external fleet orchestrator in one call
+// description: Operations are executed in order and the outcome of each is reported. Once an operation fails the remaining ones are skipped unless continue_on_error is set.
+// parameters:
+// - in: body
+//   name: body
+//   required: true
+//   schema:
+//     $ref: "#/definitions/FleetBulkRequest"
+// responses:
+//   200:
+//     description: Outcomes of the operations
+//     schema:
+//       "$ref": "#/definitions/FleetBulkResponse"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   401:
+//     description: Unauthorized
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (e *fleetEndpoint) Bulk(c *gin.Context) {
+	var req contract.FleetBulkRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	results := e.operator.Run(req.FleetOperations(), req.ContinueOnError)
+	utils.WriteAsJSON(contract.NewFleetBulkResponse(results), c.Writer)
+}
+
+// AddRoutesForFleet attaches fleet orchestration endpoints to router.
+// Endpoints are only served to requests carrying an API login token.
+func AddRoutesForFleet(operator fleetOperator, validator tokenValidator) func(*gin.Engine) error {
+	endpoint := NewFleetEndpoint(operator)
+	return func(e *gin.Engine) error {
+		g := e.Group("/fleet", tokenAuth(validator, ""))
+		{
+			g.POST("/bulk", endpoint.Bulk)
+		}
+		return nil
+	}
+}
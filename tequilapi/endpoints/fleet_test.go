@@ -0,0 +1,97 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/fleet"
+	"github.com/mysteriumnetwork/node/core/node"
+)
+
+type mockFleetOperator struct {
+	ops             []fleet.Operation
+	continueOnError bool
+}
+
+func (m *mockFleetOperator) Run(ops []fleet.Operation, continueOnError bool) []fleet.Result {
+	m.ops, m.continueOnError = ops, continueOnError
+	return []fleet.Result{
+		{Op: fleet.OpRestartServices, Status: fleet.StatusFailed, Error: "boom", Restarted: []fleet.RestartedService{{Type: "wireguard", OldID: "1", NewID: "2"}}},
+		{Op: fleet.OpHealth, Status: fleet.StatusOK, Health: &fleet.Health{Uptime: time.Minute, Monitoring: node.Passed, ActiveSessions: 3}},
+	}
+}
+
+func TestFleetEndpoint_Bulk(t *testing.T) {
+	operator := &mockFleetOperator{}
+	router := summonTestGin()
+	assert.NoError(t, AddRoutesForFleet(operator, &mockTokenValidator{valid: "jwt"})(router))
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, fleetRequest(`{
+		"operations": [{"op": "restart_services", "service_types": ["wireguard"]}, {"op": "health"}],
+		"continue_on_error": true
+	}`, "jwt"))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"results": [
+		{"op": "restart_services", "status": "failed", "error": "boom", "restarted": [{"type": "wireguard", "old_id": "1", "new_id": "2"}]},
+		{"op": "health", "status": "ok", "health": {"uptime_seconds": 60, "monitoring": "passed", "services": [], "active_sessions": 3}}
+	]}`, resp.Body.String())
+	assert.Equal(t, []fleet.Operation{{Op: fleet.OpRestartServices, ServiceTypes: []string{"wireguard"}}, {Op: fleet.OpHealth}}, operator.ops)
+	assert.True(t, operator.continueOnError)
+}
+
+func TestFleetEndpoint_BulkValidation(t *testing.T) {
+	operator := &mockFleetOperator{}
+	router := summonTestGin()
+	assert.NoError(t, AddRoutesForFleet(operator, &mockTokenValidator{valid: "jwt"})(router))
+
+	for _, body := range []string{`{}`, `{"operations": [{"op": "apply_config"}]}`, `{"operations": [{"op": "reboot"}]}`} {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, fleetRequest(body, "jwt"))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, body)
+	}
+	assert.Nil(t, operator.ops)
+}
+
+func TestFleetEndpoint_BulkRequiresAuth(t *testing.T) {
+	operator := &mockFleetOperator{}
+	router := summonTestGin()
+	assert.NoError(t, AddRoutesForFleet(operator, &mockTokenValidator{valid: "jwt"})(router))
+
+	for _, token := range []string{"", "nope"} {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, fleetRequest(`{"operations": [{"op": "rotate_token"}]}`, token))
+		assert.Equal(t, http.StatusUnauthorized, resp.Code, token)
+	}
+	assert.Nil(t, operator.ops)
+}
+
+func fleetRequest(body, token string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/fleet/bulk", strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/mysteriumnetwork/node/crash"
 	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
@@ -49,13 +50,14 @@ func HealthCheckEndpointFactory(currentTimeFunc func() time.Time, procID func()
 // swagger:operation GET /healthcheck Client healthCheck
 // ---
 // summary: Returns information about client
-// description: Returns health check information about client
+// description: Returns health check information about client including the number of panics recovered since start. Also served on /healthz
 // responses:
 //   200:
 //     description: Health check information
 //     schema:
 //       "$ref": "#/definitions/HealthCheckDTO"
 func (hce *healthCheckEndpoint) HealthCheck(c *gin.Context) {
+	crashes := crash.GetStats()
 	status := contract.HealthCheckDTO{
 		Uptime:  hce.currentTimeFunc().Sub(hce.startTime).String(),
 		Process: hce.processNumber,
@@ -65,6 +67,10 @@ func (hce *healthCheckEndpoint) HealthCheck(c *gin.Context) {
 			Branch:      metadata.BuildBranch,
 			BuildNumber: metadata.BuildNumber,
 		},
+		Crashes: crashes.Crashes,
+	}
+	if !crashes.LastCrashAt.IsZero() {
+		status.LastCrashAt = crashes.LastCrashAt.Format(time.RFC3339)
 	}
 	utils.WriteAsJSON(status, c.Writer)
 }
@@ -63,7 +63,8 @@ func TestHealthCheckReturnsExpectedJSONObject(t *testing.T) {
                 "branch": "some",
                 "commit": "abc123",
                 "build_number": "travis build #"
-            }
+            },
+            "crashes": 0
         }`,
 		resp.Body.String())
 }
@@ -27,11 +27,11 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
-	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/session/pingpong"
 	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/node/tequilapi/middlewares"
 	"github.com/mysteriumnetwork/payments/client"
 	"github.com/stretchr/testify/assert"
 )
@@ -59,7 +59,7 @@ type selectorFake struct {
 
 func summonTestGin() *gin.Engine {
 	g := gin.Default()
-	g.Use(apierror.ErrorHandler)
+	g.Use(middlewares.ErrorHandler)
 	return g
 }
 
@@ -172,6 +172,7 @@ func TestUnlockIdentityWithNoPassphrase(t *testing.T) {
     "code": "validation_failed",
     "message": "Request validation failed",
     "detail": "Request validation failed: passphrase: 'passphrase' is required [required]",
+    "hint": "Correct the listed fields and retry",
     "fields": {
       "passphrase": {
         "code": "required",
@@ -331,6 +332,7 @@ func TestCreateNewIdentityNoPassphrase(t *testing.T) {
     "code": "validation_failed",
     "message": "Request validation failed",
     "detail": "Request validation failed: passphrase: 'passphrase' is required [required]",
+    "hint": "Correct the listed fields and retry",
     "fields": {
       "passphrase": {
         "code": "required",
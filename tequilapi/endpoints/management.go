@@ -0,0 +1,328 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/core/management"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type managementAgent interface {
+	StartPairing(nodeID identity.Identity) (management.PairingCode, error)
+	Peers() ([]management.Peer, error)
+	Unpair(nodeID, peerID identity.Identity) error
+}
+
+type managementRemote interface {
+	Pair(ctx context.Context, localID, nodeID identity.Identity, code string) (management.Node, error)
+	Nodes() ([]management.Node, error)
+	Forget(nodeID identity.Identity) error
+	Do(ctx context.Context, nodeID identity.Identity, req management.Request) (management.Response, error)
+}
+
+type managementEndpoint struct {
+	agent  managementAgent
+	remote managementRemote
+}
+
+// NewManagementEndpoint creates and returns remote management endpoint
+func NewManagementEndpoint(agent managementAgent, remote managementRemote) *managementEndpoint {
+	return &managementEndpoint{agent: agent, remote: remote}
+}
+
+// swagger:operation POST /management/pairing Management startManagementPairing
+// ---
+// summary: Generates a one-time code for pairing a remote managing node
+// description: The code must be entered on the managing node within its validity period
+// parameters:
+// - in: body
+//   name: body
+//   required: true
+//   schema:
+//     $ref: "#/definitions/ManagementPairingRequest"
+// responses:
+//   200:
+//     description: Pairing code
+//     schema:
+//       "$ref": "#/definitions/ManagementPairingResponse"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (me *managementEndpoint) StartPairing(c *gin.Context) {
+	var req contract.ManagementPairingRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	code, err := me.agent.StartPairing(identity.FromAddress(req.ID))
+	if err != nil {
+		c.Error(apierror.Internal("Failed to start pairing: "+err.Error(), contract.ErrCodeManagementPairing))
+		return
+	}
+	utils.WriteAsJSON(contract.NewManagementPairingResponse(code), c.Writer)
+}
+
+// swagger:operation GET /management/peers Management listManagementPeers
+// ---
+// summary: Returns remote identities allowed to manage this node
+// responses:
+//   200:
+//     description: Paired peers
+//     schema:
+//       "$ref": "#/definitions/ManagementPeersResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (me *managementEndpoint) Peers(c *gin.Context) {
+	peers, err := me.agent.Peers()
+	if err != nil {
+		c.Error(apierror.Internal("Failed to load peers: "+err.Error(), contract.ErrCodeManagementPeers))
+		return
+	}
+	utils.WriteAsJSON(contract.NewManagementPeersResponse(peers), c.Writer)
+}
+
+// swagger:operation DELETE /management/peers/{node_id}/{peer_id} Management unpairManagementPeer
+// ---
+// summary: Revokes management access of a remote identity
+// parameters:
+// - name: node_id
+//   in: path
+//   description: local node identity
+//   type: string
+//   required: true
+// - name: peer_id
+//   in: path
+//   description: remote managing identity
+//   type: string
+//   required: true
+// responses:
+//   202:
+//     description: Peer unpaired
+//   404:
+//     description: Peer not found
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (me *managementEndpoint) Unpair(c *gin.Context) {
+	err := me.agent.Unpair(identity.FromAddress(c.Param("node_id")), identity.FromAddress(c.Param("peer_id")))
+	switch {
+	case errors.Is(err, management.ErrNotPaired):
+		c.Error(apierror.NotFound(err.Error()))
+		return
+	case err != nil:
+		c.Error(apierror.Internal("Failed to unpair: "+err.Error(), contract.ErrCodeManagementPeers))
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// swagger:operation POST /management/remotes Management pairManagementRemote
+// ---
+// summary: Pairs with a remote node using the one-time code it generated
+// description: The management channel is established via the broker, so the remote node needs no open ports
+// parameters:
+// - in: body
+//   name: body
+//   required: true
+//   schema:
+//     $ref: "#/definitions/ManagementRemoteRequest"
+// responses:
+//   200:
+//     description: Paired remote node
+//     schema:
+//       "$ref": "#/definitions/ManagementRemoteDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   422:
+//     description: Pairing was rejected or the remote node is unreachable
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (me *managementEndpoint) PairRemote(c *gin.Context) {
+	var req contract.ManagementRemoteRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	node, err := me.remote.Pair(c.Request.Context(), identity.FromAddress(req.LocalID), identity.FromAddress(req.NodeID), req.Code)
+	if err != nil {
+		c.Error(apierror.Unprocessable("Failed to pair: "+err.Error(), contract.ErrCodeManagementPairing))
+		return
+	}
+	utils.WriteAsJSON(contract.NewManagementRemoteDTO(node), c.Writer)
+}
+
+// swagger:operation GET /management/remotes Management listManagementRemotes
+// ---
+// summary: Returns remote nodes managed by this node
+// responses:
+//   200:
+//     description: Remote nodes
+//     schema:
+//       "$ref": "#/definitions/ManagementRemotesResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (me *managementEndpoint) Remotes(c *gin.Context) {
+	nodes, err := me.remote.Nodes()
+	if err != nil {
+		c.Error(apierror.Internal("Failed to load remote nodes: "+err.Error(), contract.ErrCodeManagementRemote))
+		return
+	}
+	utils.WriteAsJSON(contract.NewManagementRemotesResponse(nodes), c.Writer)
+}
+
+// swagger:operation DELETE /management/remotes/{id} Management forgetManagementRemote
+// ---
+// summary: Forgets a remote node
+// parameters:
+// - name: id
+//   in: path
+//   description: remote node identity
+//   type: string
+//   required: true
+// responses:
+//   202:
+//     description: Remote node forgotten
+//   404:
+//     description: Remote node not found
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (me *managementEndpoint) ForgetRemote(c *gin.Context) {
+	err := me.remote.Forget(identity.FromAddress(c.Param("id")))
+	switch {
+	case errors.Is(err, management.ErrUnknownNode):
+		c.Error(apierror.NotFound(err.Error()))
+		return
+	case err != nil:
+		c.Error(apierror.Internal("Failed to forget remote node: "+err.Error(), contract.ErrCodeManagementRemote))
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// swagger:operation GET /management/remotes/{id}/api/{path} Management proxyManagementRemote
+// ---
+// summary: Proxies a tequilapi request to the remote node over the management channel
+// description: Any HTTP method is accepted and passed to the remote node as is
+// parameters:
+// - name: id
+//   in: path
+//   description: remote node identity
+//   type: string
+//   required: true
+// - name: path
+//   in: path
+//   description: tequilapi path on the remote node
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: Remote node response
+//   404:
+//     description: Remote node not found
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   502:
+//     description: Remote node unreachable
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (me *managementEndpoint) Proxy(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, management.MaxBodySize+1))
+	if err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+	if len(body) > management.MaxBodySize {
+		c.Error(apierror.Error(http.StatusRequestEntityTooLarge, "Request body is too large", contract.ErrCodeManagementRemote))
+		return
+	}
+
+	res, err := me.remote.Do(c.Request.Context(), identity.FromAddress(c.Param("id")), management.Request{
+		Method:      c.Request.Method,
+		Path:        c.Param("path"),
+		Query:       c.Request.URL.RawQuery,
+		ContentType: c.GetHeader("Content-Type"),
+		Body:        body,
+	})
+	switch {
+	case errors.Is(err, management.ErrUnknownNode):
+		c.Error(apierror.NotFound(err.Error()))
+		return
+	case err != nil:
+		c.Error(apierror.Error(http.StatusBadGateway, "Remote node request failed: "+err.Error(), contract.ErrCodeManagementRemote))
+		return
+	}
+
+	c.Data(res.Status, res.ContentType, res.Body)
+}
+
+// AddRoutesForManagement attaches remote management endpoints to router
+func AddRoutesForManagement(agent managementAgent, remote managementRemote) func(*gin.Engine) error {
+	me := NewManagementEndpoint(agent, remote)
+	return func(e *gin.Engine) error {
+		g := e.Group("/management")
+		{
+			g.POST("/pairing", me.StartPairing)
+			g.GET("/peers", me.Peers)
+			g.DELETE("/peers/:node_id/:peer_id", me.Unpair)
+			g.POST("/remotes", me.PairRemote)
+			g.GET("/remotes", me.Remotes)
+			g.DELETE("/remotes/:id", me.ForgetRemote)
+			g.Any("/remotes/:id/api/*path", me.Proxy)
+		}
+		return nil
+	}
+}
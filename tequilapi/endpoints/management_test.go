@@ -0,0 +1,169 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/management"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+type mockManagementAgent struct {
+	unpairErr error
+}
+
+func (m *mockManagementAgent) StartPairing(nodeID identity.Identity) (management.PairingCode, error) {
+	return management.PairingCode{
+		NodeID:    nodeID,
+		Code:      "K7QW2M9XHT",
+		ExpiresAt: time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC),
+	}, nil
+}
+
+func (m *mockManagementAgent) Peers() ([]management.Peer, error) {
+	return []management.Peer{{
+		NodeID:   identity.FromAddress("0x1"),
+		PeerID:   identity.FromAddress("0x2"),
+		PairedAt: time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC),
+	}}, nil
+}
+
+func (m *mockManagementAgent) Unpair(_, _ identity.Identity) error {
+	return m.unpairErr
+}
+
+type mockManagementRemote struct {
+	req management.Request
+}
+
+func (m *mockManagementRemote) Pair(_ context.Context, localID, nodeID identity.Identity, _ string) (management.Node, error) {
+	return management.Node{NodeID: nodeID, LocalID: localID, PairedAt: time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)}, nil
+}
+
+func (m *mockManagementRemote) Nodes() ([]management.Node, error) {
+	return nil, nil
+}
+
+func (m *mockManagementRemote) Forget(_ identity.Identity) error {
+	return management.ErrUnknownNode
+}
+
+func (m *mockManagementRemote) Do(_ context.Context, _ identity.Identity, req management.Request) (management.Response, error) {
+	m.req = req
+	return management.Response{Status: http.StatusCreated, ContentType: "application/json", Body: []byte(`{"id":"0x9"}`)}, nil
+}
+
+func Test_ManagementEndpoint_StartPairing(t *testing.T) {
+	g := summonTestGin()
+	assert.NoError(t, AddRoutesForManagement(&mockManagementAgent{}, &mockManagementRemote{})(g))
+
+	req, _ := http.NewRequest(http.MethodPost, "/management/pairing", bytes.NewBufferString(`{"id":"0x1"}`))
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"id":"0x1","code":"K7QW2M9XHT","expires_at":"2022-05-01T12:00:00Z"}`, resp.Body.String())
+}
+
+func Test_ManagementEndpoint_StartPairingRequiresID(t *testing.T) {
+	g := summonTestGin()
+	assert.NoError(t, AddRoutesForManagement(&mockManagementAgent{}, &mockManagementRemote{})(g))
+
+	req, _ := http.NewRequest(http.MethodPost, "/management/pairing", bytes.NewBufferString(`{}`))
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func Test_ManagementEndpoint_Peers(t *testing.T) {
+	g := summonTestGin()
+	assert.NoError(t, AddRoutesForManagement(&mockManagementAgent{}, &mockManagementRemote{})(g))
+
+	req, _ := http.NewRequest(http.MethodGet, "/management/peers", nil)
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"peers":[{"node_id":"0x1","peer_id":"0x2","paired_at":"2022-05-01T12:00:00Z"}]}`, resp.Body.String())
+}
+
+func Test_ManagementEndpoint_UnpairUnknownPeer(t *testing.T) {
+	g := summonTestGin()
+	assert.NoError(t, AddRoutesForManagement(&mockManagementAgent{unpairErr: management.ErrNotPaired}, &mockManagementRemote{})(g))
+
+	req, _ := http.NewRequest(http.MethodDelete, "/management/peers/0x1/0x2", nil)
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func Test_ManagementEndpoint_PairRemote(t *testing.T) {
+	g := summonTestGin()
+	assert.NoError(t, AddRoutesForManagement(&mockManagementAgent{}, &mockManagementRemote{})(g))
+
+	req, _ := http.NewRequest(http.MethodPost, "/management/remotes", bytes.NewBufferString(`{"local_id":"0x2","node_id":"0x1","code":"K7QW2M9XHT"}`))
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"node_id":"0x1","local_id":"0x2","paired_at":"2022-05-01T12:00:00Z"}`, resp.Body.String())
+}
+
+func Test_ManagementEndpoint_Proxy(t *testing.T) {
+	g := summonTestGin()
+	remote := &mockManagementRemote{}
+	assert.NoError(t, AddRoutesForManagement(&mockManagementAgent{}, remote)(g))
+
+	req, _ := http.NewRequest(http.MethodPut, "/management/remotes/0x1/api/services?force=1", bytes.NewBufferString(`{"type":"wireguard"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	body, _ := io.ReadAll(resp.Body)
+	assert.JSONEq(t, `{"id":"0x9"}`, string(body))
+	assert.Equal(t, management.Request{
+		Method:      http.MethodPut,
+		Path:        "/services",
+		Query:       "force=1",
+		ContentType: "application/json",
+		Body:        []byte(`{"type":"wireguard"}`),
+	}, remote.req)
+}
+
+func Test_ManagementEndpoint_ForgetUnknownRemote(t *testing.T) {
+	g := summonTestGin()
+	assert.NoError(t, AddRoutesForManagement(&mockManagementAgent{}, &mockManagementRemote{})(g))
+
+	req, _ := http.NewRequest(http.MethodDelete, "/management/remotes/0x1", nil)
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/metrics"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type metricsSnapshotter interface {
+	Snapshot() metrics.Snapshot
+}
+
+type metricsEndpoint struct {
+	registry metricsSnapshotter
+}
+
+// NewMetricsEndpoint creates and returns metrics endpoint
+func NewMetricsEndpoint(registry metricsSnapshotter) *metricsEndpoint {
+	return &metricsEndpoint{registry: registry}
+}
+
+// swagger:operation GET /metrics metrics GetMetricsPrometheus
+// ---
+// summary: Provides node internal metrics
+// description: Counters, gauges and histogram summaries in the Prometheus text exposition format
+// responses:
+//   200:
+//     description: Metrics in the Prometheus text format
+func (e *metricsEndpoint) Prometheus(c *gin.Context) {
+	c.Header("Content-Type", metrics.PrometheusContentType)
+	if err := metrics.WritePrometheus(c.Writer, e.registry.Snapshot()); err != nil {
+		log.Warn().Err(err).Msg("Failed to write metrics")
+	}
+}
+
+// swagger:operation GET /debug/metrics metrics GetMetricsSnapshot
+// ---
+// summary: Provides node internal metrics snapshot
+// description: Current values of counters, gauges and histogram quantiles
+// responses:
+//   200:
+//     description: Metrics snapshot
+//     schema:
+//       "$ref": "#/definitions/MetricsSnapshotResponse"
+func (e *metricsEndpoint) Snapshot(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewMetricsSnapshotResponse(e.registry.Snapshot()), c.Writer)
+}
+
+// AddRoutesForMetrics attaches metrics endpoints to router
+func AddRoutesForMetrics(registry metricsSnapshotter) func(*gin.Engine) error {
+	endpoint := NewMetricsEndpoint(registry)
+	return func(e *gin.Engine) error {
+		e.GET("/metrics", endpoint.Prometheus)
+		e.GET("/debug/metrics", endpoint.Snapshot)
+		return nil
+	}
+}
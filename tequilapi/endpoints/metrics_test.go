@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/metrics"
+)
+
+func Test_Metrics(t *testing.T) {
+	// given:
+	registry := metrics.NewRegistry()
+	registry.Counter("invoices_total", "Sent invoices").Add(3)
+	registry.Histogram("dial_ms", "", 1000, 2).Record(42)
+
+	router := gin.Default()
+	err := AddRoutesForMetrics(registry)(router)
+	assert.NoError(t, err)
+
+	// expect:
+	t.Run("exports prometheus format", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/metrics", nil)
+		assert.NoError(t, err)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, metrics.PrometheusContentType, resp.Header().Get("Content-Type"))
+		assert.Contains(t, resp.Body.String(), "invoices_total 3\n")
+		assert.Contains(t, resp.Body.String(), "dial_ms{quantile=\"0.5\"} 42\n")
+	})
+
+	t.Run("returns snapshot", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/debug/metrics", nil)
+		assert.NoError(t, err)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.JSONEq(t, `{
+			"counters": [{"name": "invoices_total", "help": "Sent invoices", "value": 3}],
+			"gauges": [],
+			"histograms": [{
+				"name": "dial_ms",
+				"count": 1,
+				"sum": 42,
+				"min": 42,
+				"max": 42,
+				"mean": 42,
+				"quantiles": {"0.5": 42, "0.9": 42, "0.95": 42, "0.99": 42, "0.999": 42}
+			}]
+		}`, resp.Body.String())
+	})
+}
@@ -18,6 +18,7 @@
 package endpoints
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -38,19 +39,34 @@ type nodeMonitoringAgent interface {
 	EarningsSeries(rangeTime string) (node.EarningsSeries, error)
 	SessionsSeries(rangeTime string) (node.SessionsSeries, error)
 	TransferredDataSeries(rangeTime string) (node.TransferredDataSeries, error)
+	EarningsForecast(rangeTime string) (node.EarningsForecast, error)
+	EarningsGoal() (node.EarningsGoal, error)
+	NATTraversal(rangeTime string) (node.NATTraversalStats, error)
+}
+
+type nodeReputationProvider interface {
+	Reputation(rangeTime string) (node.Reputation, error)
+}
+
+type nodeUptimeProvider interface {
+	UptimeReport(rangeTime string, grouping node.UptimeGrouping) (node.UptimeReport, error)
 }
 
 // NodeEndpoint struct represents endpoints about node status
 type NodeEndpoint struct {
-	nodeStatusProvider  nodeStatusProvider
-	nodeMonitoringAgent nodeMonitoringAgent
+	nodeStatusProvider     nodeStatusProvider
+	nodeMonitoringAgent    nodeMonitoringAgent
+	nodeReputationProvider nodeReputationProvider
+	nodeUptimeProvider     nodeUptimeProvider
 }
 
 // NewNodeEndpoint creates and returns node endpoints
-func NewNodeEndpoint(nodeStatusProvider nodeStatusProvider, nodeMonitoringAgent nodeMonitoringAgent) *NodeEndpoint {
+func NewNodeEndpoint(nodeStatusProvider nodeStatusProvider, nodeMonitoringAgent nodeMonitoringAgent, nodeReputationProvider nodeReputationProvider, nodeUptimeProvider nodeUptimeProvider) *NodeEndpoint {
 	return &NodeEndpoint{
-		nodeStatusProvider:  nodeStatusProvider,
-		nodeMonitoringAgent: nodeMonitoringAgent,
+		nodeStatusProvider:     nodeStatusProvider,
+		nodeMonitoringAgent:    nodeMonitoringAgent,
+		nodeReputationProvider: nodeReputationProvider,
+		nodeUptimeProvider:     nodeUptimeProvider,
 	}
 }
 
@@ -382,9 +398,219 @@ func (ne *NodeEndpoint) GetProviderTransferredDataSeries(c *gin.Context) {
 	utils.WriteAsJSON(res, c.Writer)
 }
 
+// GetEarningsForecast Projected earnings until the end of the current month
+// swagger:operation GET /node/earnings/forecast provider GetEarningsForecast
+// ---
+// summary: Provides Node earnings forecast
+// description: Node end of month earnings projected from the trend of earnings during a period of time.
+// parameters:
+//   - in: query
+//     name: range
+//     description: period of time the trend is calculated from ("7d", "30d")
+//     type: string
+// responses:
+//   200:
+//    description: Provider earnings forecast
+//    schema:
+//     "$ref": "#/definitions/ProviderEarningsForecastResponse"
+//   400:
+//    description: Failed to parse or request validation failed
+//    schema:
+//     "$ref": "#/definitions/APIError"
+//   500:
+//    description: Internal server error
+//    schema:
+//     "$ref": "#/definitions/APIError"
+func (ne *NodeEndpoint) GetEarningsForecast(c *gin.Context) {
+	rangeTime := c.DefaultQuery("range", "7d")
+
+	switch rangeTime {
+	case "7d", "30d":
+	default:
+		c.Error(apierror.BadRequest("Invalid time range", contract.ErrorCodeProviderEarningsForecast))
+		return
+	}
+
+	res, err := ne.nodeMonitoringAgent.EarningsForecast(rangeTime)
+	if err != nil {
+		c.Error(apierror.Internal("Could not get provider earnings forecast: "+err.Error(), contract.ErrorCodeProviderEarningsForecast))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewProviderEarningsForecastResponse(res), c.Writer)
+}
+
+// GetEarningsGoal Progress towards the earnings goal
+// swagger:operation GET /node/earnings/goal provider GetEarningsGoal
+// ---
+// summary: Provides Node earnings goal progress
+// description: Node progress towards the configured earnings goal of the current week or month, with the daily earnings required to reach it.
+// responses:
+//   200:
+//    description: Provider earnings goal progress
+//    schema:
+//     "$ref": "#/definitions/ProviderEarningsGoalResponse"
+//   404:
+//    description: Earnings goal is not set
+//    schema:
+//     "$ref": "#/definitions/APIError"
+//   500:
+//    description: Internal server error
+//    schema:
+//     "$ref": "#/definitions/APIError"
+func (ne *NodeEndpoint) GetEarningsGoal(c *gin.Context) {
+	res, err := ne.nodeMonitoringAgent.EarningsGoal()
+	if errors.Is(err, node.ErrEarningsGoalNotSet) {
+		c.Error(apierror.NotFound("Earnings goal is not set"))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal("Could not get provider earnings goal: "+err.Error(), contract.ErrorCodeProviderEarningsGoal))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewProviderEarningsGoalResponse(res), c.Writer)
+}
+
+// GetReputation Provider reputation as seen by consumers
+// swagger:operation GET /node/reputation provider GetReputation
+// ---
+// summary: Provides provider reputation as seen by consumers
+// description: Quality oracle metrics of provider services (quality score, latency percentile, monitoring failure streaks) with their history during a period of time
+// parameters:
+//   - in: query
+//     name: range
+//     description: period of time ("1d", "7d", "30d"), defaults to "7d"
+//     type: string
+// responses:
+//   200:
+//     description: Provider reputation
+//     schema:
+//       "$ref": "#/definitions/ProviderReputationResponse"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ne *NodeEndpoint) GetReputation(c *gin.Context) {
+	rangeTime := c.DefaultQuery("range", "7d")
+
+	switch rangeTime {
+	case "1d", "7d", "30d":
+	default:
+		c.Error(apierror.BadRequest("Invalid time range", contract.ErrorCodeProviderReputation))
+		return
+	}
+
+	res, err := ne.nodeReputationProvider.Reputation(rangeTime)
+	if err != nil {
+		c.Error(apierror.Internal("Could not get provider reputation: "+err.Error(), contract.ErrorCodeProviderReputation))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewProviderReputationResponse(res), c.Writer)
+}
+
+// GetUptime Node availability report
+// swagger:operation GET /node/uptime provider GetUptime
+// ---
+// summary: Provides node uptime and availability report
+// description: Node process uptime, service availability and monitoring agent confirmed reachability percentages per day or week during a period of time
+// parameters:
+//   - in: query
+//     name: range
+//     description: period of time ("1d", "7d", "30d"), defaults to "7d"
+//     type: string
+//   - in: query
+//     name: group
+//     description: report availability per "day" or "week", defaults to "day"
+//     type: string
+// responses:
+//   200:
+//     description: Node uptime report
+//     schema:
+//       "$ref": "#/definitions/NodeUptimeReportResponse"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ne *NodeEndpoint) GetUptime(c *gin.Context) {
+	rangeTime := c.DefaultQuery("range", "7d")
+
+	switch rangeTime {
+	case "1d", "7d", "30d":
+	default:
+		c.Error(apierror.BadRequest("Invalid time range", contract.ErrorCodeNodeUptime))
+		return
+	}
+
+	grouping := node.UptimeGrouping(c.DefaultQuery("group", string(node.UptimeGroupingDay)))
+	if grouping != node.UptimeGroupingDay && grouping != node.UptimeGroupingWeek {
+		c.Error(apierror.BadRequest("Invalid grouping", contract.ErrorCodeNodeUptime))
+		return
+	}
+
+	res, err := ne.nodeUptimeProvider.UptimeReport(rangeTime, grouping)
+	if err != nil {
+		c.Error(apierror.Internal("Could not get node uptime report: "+err.Error(), contract.ErrorCodeNodeUptime))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewNodeUptimeReportResponse(res), c.Writer)
+}
+
+// GetProviderNATTraversal NAT traversal success rate
+// swagger:operation GET /node/provider/nat-traversal provider GetProviderNATTraversal
+// ---
+// summary: Provides provider NAT traversal success rate
+// description: Number of attempts to reach consumers, their success rate and average duration per traversal method during a period of time
+// parameters:
+//   - in: query
+//     name: range
+//     description: period of time ("1d", "7d", "30d"), defaults to "7d"
+//     type: string
+// responses:
+//   200:
+//     description: Provider NAT traversal stats
+//     schema:
+//       "$ref": "#/definitions/ProviderNATTraversalResponse"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ne *NodeEndpoint) GetProviderNATTraversal(c *gin.Context) {
+	rangeTime := c.DefaultQuery("range", "7d")
+
+	switch rangeTime {
+	case "1d", "7d", "30d":
+	default:
+		c.Error(apierror.BadRequest("Invalid time range", contract.ErrorCodeProviderNATTraversal))
+		return
+	}
+
+	res, err := ne.nodeMonitoringAgent.NATTraversal(rangeTime)
+	if err != nil {
+		c.Error(apierror.Internal("Could not get provider NAT traversal stats: "+err.Error(), contract.ErrorCodeProviderNATTraversal))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewProviderNATTraversalResponse(res), c.Writer)
+}
+
 // AddRoutesForNode adds nat routes to given router
-func AddRoutesForNode(nodeStatusProvider nodeStatusProvider, nodeMonitoringAgent nodeMonitoringAgent) func(*gin.Engine) error {
-	nodeEndpoints := NewNodeEndpoint(nodeStatusProvider, nodeMonitoringAgent)
+func AddRoutesForNode(nodeStatusProvider nodeStatusProvider, nodeMonitoringAgent nodeMonitoringAgent, nodeReputationProvider nodeReputationProvider, nodeUptimeProvider nodeUptimeProvider) func(*gin.Engine) error {
+	nodeEndpoints := NewNodeEndpoint(nodeStatusProvider, nodeMonitoringAgent, nodeReputationProvider, nodeUptimeProvider)
 
 	return func(e *gin.Engine) error {
 		nodeGroup := e.Group("/node")
@@ -398,6 +624,11 @@ func AddRoutesForNode(nodeStatusProvider nodeStatusProvider, nodeMonitoringAgent
 			nodeGroup.GET("/provider/series/earnings", nodeEndpoints.GetProviderEarningsSeries)
 			nodeGroup.GET("/provider/series/sessions", nodeEndpoints.GetProviderSessionsSeries)
 			nodeGroup.GET("/provider/series/data", nodeEndpoints.GetProviderTransferredDataSeries)
+			nodeGroup.GET("/provider/nat-traversal", nodeEndpoints.GetProviderNATTraversal)
+			nodeGroup.GET("/earnings/forecast", nodeEndpoints.GetEarningsForecast)
+			nodeGroup.GET("/earnings/goal", nodeEndpoints.GetEarningsGoal)
+			nodeGroup.GET("/reputation", nodeEndpoints.GetReputation)
+			nodeGroup.GET("/uptime", nodeEndpoints.GetUptime)
 		}
 		return nil
 	}
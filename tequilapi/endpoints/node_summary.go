@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/core/summary"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type nodeSummaryProvider interface {
+	Summary() summary.Summary
+}
+
+type nodeSummaryEndpoint struct {
+	provider nodeSummaryProvider
+}
+
+// NewNodeSummaryEndpoint creates and returns node summary endpoint
+func NewNodeSummaryEndpoint(provider nodeSummaryProvider) *nodeSummaryEndpoint {
+	return &nodeSummaryEndpoint{provider: provider}
+}
+
+// swagger:operation GET /node/summary node GetNodeSummary
+// ---
+// summary: Provides node summary
+// description: Returns identity, balances, active sessions, today's traffic and earnings, monitoring status and alerts in a single document.
+// responses:
+//   200:
+//     description: Node summary
+//     schema:
+//       "$ref": "#/definitions/NodeSummaryResponse"
+func (e *nodeSummaryEndpoint) Summary(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewNodeSummaryResponse(e.provider.Summary()), c.Writer)
+}
+
+// AddRoutesForNodeSummary attaches node summary endpoints to router
+func AddRoutesForNodeSummary(provider nodeSummaryProvider) func(*gin.Engine) error {
+	endpoint := NewNodeSummaryEndpoint(provider)
+	return func(e *gin.Engine) error {
+		e.GET("/node/summary", endpoint.Summary)
+		return nil
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/core/summary"
+	"github.com/mysteriumnetwork/node/identity/registry"
+)
+
+type mockNodeSummaryProvider struct {
+	summary summary.Summary
+}
+
+func (m *mockNodeSummaryProvider) Summary() summary.Summary {
+	return m.summary
+}
+
+func TestNodeSummaryEndpoint_Summary(t *testing.T) {
+	provider := &mockNodeSummaryProvider{summary: summary.Summary{
+		Identity: &summary.Identity{
+			Address:            "0x1",
+			RegistrationStatus: registry.Registered,
+			Balance:            big.NewInt(1),
+			Earnings:           big.NewInt(2),
+			EarningsTotal:      big.NewInt(3),
+		},
+		Monitoring:       node.Failed,
+		Services:         []summary.Service{{ID: "svc1", Type: "wireguard", State: servicestate.Running, Health: "healthy"}},
+		ProviderSessions: 2,
+		ConsumerState:    connectionstate.NotConnected,
+		Today:            summary.Traffic{Sessions: 4, DataSent: 10, DataReceived: 20, Earnings: big.NewInt(5)},
+		Alerts:           []summary.Alert{{Code: summary.AlertMonitoringFailed, Severity: summary.SeverityError, Message: "failed"}},
+		GeneratedAt:      time.Date(2022, 3, 4, 15, 30, 0, 0, time.UTC),
+	}}
+	router := summonTestGin()
+	assert.NoError(t, AddRoutesForNodeSummary(provider)(router))
+
+	req := httptest.NewRequest(http.MethodGet, "/node/summary", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"identity": {
+			"address": "0x1",
+			"registration_status": "Registered",
+			"balance_tokens": {"wei": "1", "ether": "0.000000000000000001", "human": "0"},
+			"earnings_tokens": {"wei": "2", "ether": "0.000000000000000002", "human": "0"},
+			"earnings_total_tokens": {"wei": "3", "ether": "0.000000000000000003", "human": "0"}
+		},
+		"monitoring_status": "failed",
+		"services": [{"id": "svc1", "type": "wireguard", "status": "Running", "health": "healthy"}],
+		"active_sessions": {"provided": 2, "consumer_connection": "NotConnected"},
+		"today": {
+			"sessions": 4,
+			"data_sent": 10,
+			"data_received": 20,
+			"earnings_tokens": {"wei": "5", "ether": "0.000000000000000005", "human": "0"}
+		},
+		"alerts": [{"code": "monitoring_failed", "severity": "error", "message": "failed"}],
+		"generated_at": "2022-03-04T15:30:00Z"
+	}`, resp.Body.String())
+}
@@ -23,8 +23,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/mysteriumnetwork/node/core/node"
@@ -44,6 +47,32 @@ type mockMonitoringAgent struct {
 	earningsSeries        node.EarningsSeries
 	sessionsSeries        node.SessionsSeries
 	transferredDataSeries node.TransferredDataSeries
+	earningsForecast      node.EarningsForecast
+	earningsGoal          node.EarningsGoal
+	earningsGoalErr       error
+	natTraversal          node.NATTraversalStats
+}
+
+type mockReputationProvider struct {
+	reputation node.Reputation
+	rangeTime  string
+}
+
+func (m *mockReputationProvider) Reputation(rangeTime string) (node.Reputation, error) {
+	m.rangeTime = rangeTime
+	return m.reputation, nil
+}
+
+type mockUptimeProvider struct {
+	report    node.UptimeReport
+	rangeTime string
+	grouping  node.UptimeGrouping
+}
+
+func (m *mockUptimeProvider) UptimeReport(rangeTime string, grouping node.UptimeGrouping) (node.UptimeReport, error) {
+	m.rangeTime = rangeTime
+	m.grouping = grouping
+	return m.report, nil
 }
 
 func (nodeStatusTracker *mockNodeStatusProvider) Status() node.MonitoringStatus {
@@ -82,13 +111,25 @@ func (nodeMonitoringAgentTracker *mockMonitoringAgent) TransferredDataSeries(_ s
 	return nodeMonitoringAgentTracker.transferredDataSeries, nil
 }
 
+func (nodeMonitoringAgentTracker *mockMonitoringAgent) EarningsForecast(_ string) (node.EarningsForecast, error) {
+	return nodeMonitoringAgentTracker.earningsForecast, nil
+}
+
+func (nodeMonitoringAgentTracker *mockMonitoringAgent) EarningsGoal() (node.EarningsGoal, error) {
+	return nodeMonitoringAgentTracker.earningsGoal, nodeMonitoringAgentTracker.earningsGoalErr
+}
+
+func (nodeMonitoringAgentTracker *mockMonitoringAgent) NATTraversal(_ string) (node.NATTraversalStats, error) {
+	return nodeMonitoringAgentTracker.natTraversal, nil
+}
+
 func Test_NodeStatus(t *testing.T) {
 	// given:
 	mockStatusTracker := &mockNodeStatusProvider{}
 	mockMonitoringAgentTracker := &mockMonitoringAgent{}
 
 	router := gin.Default()
-	err := AddRoutesForNode(mockStatusTracker, mockMonitoringAgentTracker)(router)
+	err := AddRoutesForNode(mockStatusTracker, mockMonitoringAgentTracker, &mockReputationProvider{}, &mockUptimeProvider{})(router)
 	assert.NoError(t, err)
 
 	req, err := http.NewRequest(http.MethodGet, "/node/monitoring-status", nil)
@@ -111,3 +152,264 @@ func Test_NodeStatus(t *testing.T) {
 		})
 	}
 }
+
+func Test_EarningsForecast(t *testing.T) {
+	// given:
+	forecast := node.EarningsForecast{
+		Earned:     decimal.NewFromInt(2),
+		Projected:  decimal.NewFromInt(3),
+		Total:      decimal.NewFromInt(5),
+		DailyTrend: decimal.Zero,
+		MonthEnd:   time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC),
+	}
+	mockMonitoringAgentTracker := &mockMonitoringAgent{earningsForecast: forecast}
+
+	router := gin.Default()
+	router.Use(apierror.ErrorHandler)
+	err := AddRoutesForNode(&mockNodeStatusProvider{}, mockMonitoringAgentTracker, &mockReputationProvider{}, &mockUptimeProvider{})(router)
+	assert.NoError(t, err)
+
+	// expect:
+	t.Run("returns forecast", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/node/earnings/forecast?range=30d", nil)
+		assert.NoError(t, err)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		result, err := json.Marshal(contract.NewProviderEarningsForecastResponse(forecast))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.JSONEq(t, string(result), resp.Body.String())
+	})
+
+	t.Run("rejects invalid range", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/node/earnings/forecast?range=1d", nil)
+		assert.NoError(t, err)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func Test_EarningsGoal(t *testing.T) {
+	// given:
+	goal := node.EarningsGoal{
+		Goal:              decimal.NewFromInt(50),
+		Earned:            decimal.NewFromInt(20),
+		Remaining:         decimal.NewFromInt(30),
+		PercentComplete:   40,
+		RequiredDailyRate: decimal.NewFromInt(2),
+		Period:            node.EarningsGoalPeriodMonth,
+		PeriodStart:       time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:         time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	// expect:
+	t.Run("returns goal progress", func(t *testing.T) {
+		router := gin.Default()
+		router.Use(apierror.ErrorHandler)
+		err := AddRoutesForNode(&mockNodeStatusProvider{}, &mockMonitoringAgent{earningsGoal: goal}, &mockReputationProvider{}, &mockUptimeProvider{})(router)
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodGet, "/node/earnings/goal", nil)
+		assert.NoError(t, err)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		result, err := json.Marshal(contract.NewProviderEarningsGoalResponse(goal))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.JSONEq(t, string(result), resp.Body.String())
+	})
+
+	t.Run("returns not found when goal is not set", func(t *testing.T) {
+		router := gin.Default()
+		router.Use(apierror.ErrorHandler)
+		err := AddRoutesForNode(&mockNodeStatusProvider{}, &mockMonitoringAgent{earningsGoalErr: node.ErrEarningsGoalNotSet}, &mockReputationProvider{}, &mockUptimeProvider{})(router)
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodGet, "/node/earnings/goal", nil)
+		assert.NoError(t, err)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
+
+func Test_NATTraversal(t *testing.T) {
+	// given:
+	stats := node.NATTraversalStats{
+		NATTraversalMethodStats: node.NATTraversalMethodStats{Attempts: 4, Succeeded: 3, SuccessRate: 0.75, AvgDuration: 1500 * time.Millisecond},
+		Methods: []node.NATTraversalMethodStats{
+			{Method: "hole_punching", Attempts: 2, Succeeded: 1, SuccessRate: 0.5, AvgDuration: 2 * time.Second},
+			{Method: "port_mapping", Attempts: 2, Succeeded: 2, SuccessRate: 1, AvgDuration: time.Second},
+		},
+	}
+
+	router := gin.Default()
+	router.Use(apierror.ErrorHandler)
+	err := AddRoutesForNode(&mockNodeStatusProvider{}, &mockMonitoringAgent{natTraversal: stats}, &mockReputationProvider{}, &mockUptimeProvider{})(router)
+	assert.NoError(t, err)
+
+	// expect:
+	t.Run("returns success rate", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/node/provider/nat-traversal?range=1d", nil)
+		assert.NoError(t, err)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.JSONEq(t, `{
+			"attempts": 4,
+			"succeeded": 3,
+			"success_rate": 0.75,
+			"avg_duration_ms": 1500,
+			"methods": [
+				{"method": "hole_punching", "attempts": 2, "succeeded": 1, "success_rate": 0.5, "avg_duration_ms": 2000},
+				{"method": "port_mapping", "attempts": 2, "succeeded": 2, "success_rate": 1, "avg_duration_ms": 1000}
+			]
+		}`, resp.Body.String())
+	})
+
+	t.Run("rejects invalid range", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/node/provider/nat-traversal?range=2d", nil)
+		assert.NoError(t, err)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func Test_Reputation(t *testing.T) {
+	// given:
+	at := time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC)
+	service := node.ServiceReputation{
+		ServiceType:       "wireguard",
+		Listed:            true,
+		Quality:           2.5,
+		Latency:           50,
+		LatencyPercentile: 80,
+		MonitoringFailed:  true,
+		FailureStreak:     2,
+		Trend:             node.TrendDeclining,
+	}
+	reputation := &mockReputationProvider{reputation: node.Reputation{
+		ProviderID: "0x1",
+		Services:   []node.ServiceReputation{service},
+		History:    []node.ReputationSample{{ProviderID: "0x1", At: at, Services: []node.ServiceReputation{service}}},
+	}}
+
+	router := gin.Default()
+	router.Use(apierror.ErrorHandler)
+	err := AddRoutesForNode(&mockNodeStatusProvider{}, &mockMonitoringAgent{}, reputation, &mockUptimeProvider{})(router)
+	assert.NoError(t, err)
+
+	// expect:
+	t.Run("returns reputation for default range", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/node/reputation", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "7d", reputation.rangeTime)
+		serviceJSON := `{
+			"service_type": "wireguard",
+			"listed": true,
+			"quality": 2.5,
+			"latency": 50,
+			"bandwidth": 0,
+			"uptime": 0,
+			"latency_percentile": 80,
+			"monitoring_failed": true,
+			"monitoring_failure_streak": 2,
+			"trend": "declining"
+		}`
+		assert.JSONEq(t, `{
+			"provider_id": "0x1",
+			"services": [`+serviceJSON+`],
+			"history": [{"at": "2022-07-01T00:00:00Z", "services": [`+serviceJSON+`]}]
+		}`, resp.Body.String())
+	})
+
+	t.Run("rejects invalid range", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/node/reputation?range=2y", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func Test_Uptime(t *testing.T) {
+	// given:
+	start := time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC)
+	period := node.UptimePeriod{
+		Start:               start,
+		End:                 start.Add(24 * time.Hour),
+		Up:                  12 * time.Hour,
+		ServiceAvailable:    6 * time.Hour,
+		Reachable:           6 * time.Hour,
+		Uptime:              50,
+		ServiceAvailability: 25,
+		Reachability:        25,
+	}
+	uptime := &mockUptimeProvider{report: node.UptimeReport{
+		StartedAt: start.Add(12 * time.Hour),
+		Uptime:    time.Hour,
+		Total:     period,
+		Periods:   []node.UptimePeriod{period},
+	}}
+
+	router := gin.Default()
+	router.Use(apierror.ErrorHandler)
+	err := AddRoutesForNode(&mockNodeStatusProvider{}, &mockMonitoringAgent{}, &mockReputationProvider{}, uptime)(router)
+	assert.NoError(t, err)
+
+	// expect:
+	t.Run("returns report for default range", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/node/uptime", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "7d", uptime.rangeTime)
+		assert.Equal(t, node.UptimeGroupingDay, uptime.grouping)
+		periodJSON := `{
+			"start": "2022-07-01T00:00:00Z",
+			"end": "2022-07-02T00:00:00Z",
+			"up_seconds": 43200,
+			"service_available_seconds": 21600,
+			"reachable_seconds": 21600,
+			"uptime_percent": 50,
+			"service_availability_percent": 25,
+			"reachability_percent": 25
+		}`
+		assert.JSONEq(t, `{
+			"started_at": "2022-07-01T12:00:00Z",
+			"uptime_seconds": 3600,
+			"total": `+periodJSON+`,
+			"periods": [`+periodJSON+`]
+		}`, resp.Body.String())
+	})
+
+	t.Run("passes grouping", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/node/uptime?range=30d&group=week", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "30d", uptime.rangeTime)
+		assert.Equal(t, node.UptimeGroupingWeek, uptime.grouping)
+	})
+
+	t.Run("rejects invalid grouping", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/node/uptime?group=year", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/core/notify"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type notifier interface {
+	Config() notify.Config
+	SetConfig(config notify.Config) error
+	Test() error
+}
+
+type notificationsEndpoint struct {
+	notifier notifier
+}
+
+// NewNotificationsEndpoint creates and returns notifications endpoint
+func NewNotificationsEndpoint(notifier notifier) *notificationsEndpoint {
+	return &notificationsEndpoint{notifier: notifier}
+}
+
+// swagger:operation GET /node/notifications Notifications getNotificationsConfig
+// ---
+// summary: Returns notification channels settings
+// description: Returns email and Telegram notification settings without secrets
+// responses:
+//   200:
+//     description: Notification channels settings
+//     schema:
+//       "$ref": "#/definitions/NotificationsConfigDTO"
+func (e *notificationsEndpoint) Get(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewNotificationsConfigDTO(e.notifier.Config()), c.Writer)
+}
+
+// swagger:operation PUT /node/notifications Notifications setNotificationsConfig
+// ---
+// summary: Replaces notification channels settings
+// description: Replaces notification settings, omitted secrets keep their stored values
+// parameters:
+//   - in: body
+//     name: body
+//     required: true
+//     schema:
+//       $ref: "#/definitions/NotificationsConfigDTO"
+// responses:
+//   200:
+//     description: Notification channels settings
+//     schema:
+//       "$ref": "#/definitions/NotificationsConfigDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (e *notificationsEndpoint) Set(c *gin.Context) {
+	var req contract.NotificationsConfigDTO
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+
+	err := e.notifier.SetConfig(req.ToConfig(e.notifier.Config()))
+	if errors.Is(err, notify.ErrInvalidConfig) {
+		c.Error(apierror.BadRequest(err.Error(), contract.ErrCodeNotificationsConfig))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal("Could not save notification config: "+err.Error(), contract.ErrCodeNotificationsConfig))
+		return
+	}
+	utils.WriteAsJSON(contract.NewNotificationsConfigDTO(e.notifier.Config()), c.Writer)
+}
+
+// swagger:operation POST /node/notifications/test Notifications testNotifications
+// ---
+// summary: Sends test notification
+// description: Sends a test notification to all configured channels ignoring rate limits
+// responses:
+//   202:
+//     description: Test notification sent
+//   422:
+//     description: No notification channels configured
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Sending test notification failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (e *notificationsEndpoint) Test(c *gin.Context) {
+	err := e.notifier.Test()
+	if errors.Is(err, notify.ErrNoChannels) {
+		c.Error(apierror.Unprocessable(err.Error(), contract.ErrCodeNotificationsTest))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal(err.Error(), contract.ErrCodeNotificationsTest))
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// AddRoutesForNotifications attaches notification endpoints to router
+func AddRoutesForNotifications(notifier notifier) func(*gin.Engine) error {
+	endpoint := NewNotificationsEndpoint(notifier)
+	return func(e *gin.Engine) error {
+		g := e.Group("/node/notifications")
+		{
+			g.GET("", endpoint.Get)
+			g.PUT("", endpoint.Set)
+			g.POST("/test", endpoint.Test)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/notify"
+)
+
+type mockNotifier struct {
+	config notify.Config
+	tested int
+}
+
+func (m *mockNotifier) Config() notify.Config {
+	return m.config
+}
+
+func (m *mockNotifier) SetConfig(config notify.Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	m.config = config
+	return nil
+}
+
+func (m *mockNotifier) Test() error {
+	if m.config.Email == nil && m.config.Telegram == nil {
+		return notify.ErrNoChannels
+	}
+	m.tested++
+	return nil
+}
+
+func TestNotificationsEndpoint(t *testing.T) {
+	notifier := &mockNotifier{config: notify.Config{
+		Telegram:   &notify.TelegramConfig{BotToken: "secret", ChatID: "1"},
+		MaxPerHour: 20,
+	}}
+	router := summonTestGin()
+	require.NoError(t, AddRoutesForNotifications(notifier)(router))
+
+	req := httptest.NewRequest(http.MethodGet, "/node/notifications", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"telegram": {"chat_id": "1"}, "max_per_hour": 20}`, resp.Body.String())
+
+	req = httptest.NewRequest(http.MethodPut, "/node/notifications", strings.NewReader(`{
+		"telegram": {"chat_id": "2"},
+		"email": {"host": "smtp.example.com", "password": "pass", "from": "node@example.com", "to": ["me@example.com"]}
+	}`))
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"telegram": {"chat_id": "2"},
+		"email": {"host": "smtp.example.com", "port": 587, "from": "node@example.com", "to": ["me@example.com"]},
+		"max_per_hour": 20
+	}`, resp.Body.String())
+	assert.Equal(t, "secret", notifier.config.Telegram.BotToken)
+	assert.Equal(t, "pass", notifier.config.Email.Password)
+
+	req = httptest.NewRequest(http.MethodPut, "/node/notifications", strings.NewReader(`{
+		"email": {"host": "smtp.example.com", "port": 587, "from": "node@example.com", "to": ["me@example.com"]}
+	}`))
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "pass", notifier.config.Email.Password)
+
+	// Stored password is never sent to another server.
+	req = httptest.NewRequest(http.MethodPut, "/node/notifications", strings.NewReader(`{
+		"email": {"host": "smtp.attacker.com", "from": "node@example.com", "to": ["me@example.com"]}
+	}`))
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, notifier.config.Email.Password)
+
+	req = httptest.NewRequest(http.MethodPut, "/node/notifications", strings.NewReader(`{"email": {"host": "smtp.example.com", "from": "node", "to": []}}`))
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/node/notifications/test", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, 1, notifier.tested)
+
+	notifier.config = notify.Config{}
+	req = httptest.NewRequest(http.MethodPost, "/node/notifications/test", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
@@ -0,0 +1,326 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/dispute"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type promiseRecovery interface {
+	Audit(chainID int64, id identity.Identity) (pingpong.PromiseAudit, error)
+	Recover(chainID int64, id identity.Identity) (pingpong.PromiseAudit, error)
+}
+
+type disputeKeeper interface {
+	Flag(sessionID session.ID, reason string) (dispute.Dispute, error)
+	Resolve(sessionID session.ID) (dispute.Dispute, error)
+	Get(sessionID session.ID) (dispute.Dispute, error)
+	List() ([]dispute.Dispute, error)
+	Bundle(sessionID session.ID) (dispute.Bundle, error)
+}
+
+type paymentsEndpoint struct {
+	recovery promiseRecovery
+	disputes disputeKeeper
+}
+
+// NewPaymentsEndpoint creates and returns payments endpoint
+func NewPaymentsEndpoint(recovery promiseRecovery, disputes disputeKeeper) *paymentsEndpoint {
+	return &paymentsEndpoint{recovery: recovery, disputes: disputes}
+}
+
+// swagger:operation GET /payments/audit/{id} Payments paymentsAudit
+// ---
+// summary: Audits hermes promise storage
+// description: Compares the locally stored hermes promise of the identity with the latest promise issued by the active hermes
+// parameters:
+//   - in: path
+//     name: id
+//     description: provider identity
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Promise audit result
+//     schema:
+//       "$ref": "#/definitions/PromiseAuditResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (endpoint *paymentsEndpoint) Audit(c *gin.Context) {
+	id := identity.FromAddress(c.Param("id"))
+	audit, err := endpoint.recovery.Audit(config.GetInt64(config.FlagChainID), id)
+	if err != nil {
+		c.Error(apierror.Internal("Could not audit promises: "+err.Error(), contract.ErrCodePaymentsAudit))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewPromiseAuditResponse(audit), c.Writer)
+}
+
+// swagger:operation POST /payments/audit/{id}/recover Payments paymentsRecover
+// ---
+// summary: Recovers hermes promise storage
+// description: Audits the hermes promise of the identity and repairs lost, corrupted or outdated local promise by re-requesting it from hermes
+// parameters:
+//   - in: path
+//     name: id
+//     description: provider identity
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Promise audit result after recovery
+//     schema:
+//       "$ref": "#/definitions/PromiseAuditResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (endpoint *paymentsEndpoint) Recover(c *gin.Context) {
+	id := identity.FromAddress(c.Param("id"))
+	audit, err := endpoint.recovery.Recover(config.GetInt64(config.FlagChainID), id)
+	if err != nil {
+		c.Error(apierror.Internal("Could not recover promises: "+err.Error(), contract.ErrCodePaymentsRecover))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewPromiseAuditResponse(audit), c.Writer)
+}
+
+// swagger:operation GET /payments/disputes Payments listDisputes
+// ---
+// summary: Returns payment disputes
+// description: Returns payment disputes of provided sessions, the newest first
+// responses:
+//   200:
+//     description: List of payment disputes
+//     schema:
+//       "$ref": "#/definitions/DisputeListResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (endpoint *paymentsEndpoint) ListDisputes(c *gin.Context) {
+	disputes, err := endpoint.disputes.List()
+	if err != nil {
+		c.Error(apierror.Internal("Could not list disputes: "+err.Error(), contract.ErrCodeDisputeList))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewDisputeListResponse(disputes), c.Writer)
+}
+
+// swagger:operation POST /payments/disputes Payments flagDispute
+// ---
+// summary: Flags a session as underpaid
+// description: Opens a payment dispute of a provided session, settlement of its hermes promises is held until the dispute is resolved
+// parameters:
+//   - in: body
+//     name: body
+//     required: true
+//     schema:
+//       $ref: "#/definitions/DisputeRequest"
+// responses:
+//   200:
+//     description: Opened payment dispute
+//     schema:
+//       "$ref": "#/definitions/DisputeDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   404:
+//     description: Session has no payment audit trail
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   409:
+//     description: Session is already disputed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (endpoint *paymentsEndpoint) FlagDispute(c *gin.Context) {
+	var req contract.DisputeRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	d, err := endpoint.disputes.Flag(session.ID(req.SessionID), req.Reason)
+	switch {
+	case errors.Is(err, dispute.ErrUnknownSession):
+		c.Error(apierror.NotFound("Session has no payment audit trail"))
+		return
+	case errors.Is(err, dispute.ErrAlreadyDisputed):
+		c.Error(apierror.Conflict("Session is already disputed", contract.ErrCodeDisputeFlag, "session_id"))
+		return
+	case err != nil:
+		c.Error(apierror.Internal("Could not flag dispute: "+err.Error(), contract.ErrCodeDisputeFlag))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewDisputeDTO(d), c.Writer)
+}
+
+// swagger:operation GET /payments/disputes/{session_id} Payments getDispute
+// ---
+// summary: Returns payment dispute
+// parameters:
+//   - in: path
+//     name: session_id
+//     description: disputed session id
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Payment dispute
+//     schema:
+//       "$ref": "#/definitions/DisputeDTO"
+//   404:
+//     description: Dispute not found
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (endpoint *paymentsEndpoint) GetDispute(c *gin.Context) {
+	d, err := endpoint.disputes.Get(session.ID(c.Param("session_id")))
+	if err != nil {
+		endpoint.disputeError(c, err, contract.ErrCodeDisputeGet)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewDisputeDTO(d), c.Writer)
+}
+
+// swagger:operation POST /payments/disputes/{session_id}/resolve Payments resolveDispute
+// ---
+// summary: Resolves payment dispute
+// description: Closes the open payment dispute and lifts its hold on hermes promise settlement
+// parameters:
+//   - in: path
+//     name: session_id
+//     description: disputed session id
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Resolved payment dispute
+//     schema:
+//       "$ref": "#/definitions/DisputeDTO"
+//   404:
+//     description: Dispute not found
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   409:
+//     description: Dispute is not open
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (endpoint *paymentsEndpoint) ResolveDispute(c *gin.Context) {
+	d, err := endpoint.disputes.Resolve(session.ID(c.Param("session_id")))
+	if errors.Is(err, dispute.ErrNotOpen) {
+		c.Error(apierror.Conflict("Dispute is not open", contract.ErrCodeDisputeResolve, "session_id"))
+		return
+	}
+	if err != nil {
+		endpoint.disputeError(c, err, contract.ErrCodeDisputeResolve)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewDisputeDTO(d), c.Writer)
+}
+
+// swagger:operation GET /payments/disputes/{session_id}/bundle Payments disputeBundle
+// ---
+// summary: Exports payment dispute bundle
+// description: Returns the dispute with invoices and exchange messages of the session, signed by provider identity
+// parameters:
+//   - in: path
+//     name: session_id
+//     description: disputed session id
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Signed dispute bundle
+//   404:
+//     description: Dispute not found
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (endpoint *paymentsEndpoint) DisputeBundle(c *gin.Context) {
+	sessionID := c.Param("session_id")
+	bundle, err := endpoint.disputes.Bundle(session.ID(sessionID))
+	if err != nil {
+		endpoint.disputeError(c, err, contract.ErrCodeDisputeBundle)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=dispute-%s.json", sessionID))
+	utils.WriteAsJSON(bundle, c.Writer)
+}
+
+func (endpoint *paymentsEndpoint) disputeError(c *gin.Context, err error, code string) {
+	switch {
+	case errors.Is(err, dispute.ErrNotFound):
+		c.Error(apierror.NotFound("Dispute not found"))
+	case errors.Is(err, dispute.ErrUnknownSession):
+		c.Error(apierror.NotFound("Session has no payment audit trail"))
+	default:
+		c.Error(apierror.Internal(err.Error(), code))
+	}
+}
+
+// AddRoutesForPayments attaches payments endpoints to router
+func AddRoutesForPayments(recovery promiseRecovery, disputes disputeKeeper) func(*gin.Engine) error {
+	endpoint := NewPaymentsEndpoint(recovery, disputes)
+	return func(e *gin.Engine) error {
+		g := e.Group("/payments")
+		{
+			g.GET("/audit/:id", endpoint.Audit)
+			g.POST("/audit/:id/recover", endpoint.Recover)
+			g.GET("/disputes", endpoint.ListDisputes)
+			g.POST("/disputes", endpoint.FlagDispute)
+			g.GET("/disputes/:session_id", endpoint.GetDispute)
+			g.POST("/disputes/:session_id/resolve", endpoint.ResolveDispute)
+			g.GET("/disputes/:session_id/bundle", endpoint.DisputeBundle)
+		}
+		return nil
+	}
+}
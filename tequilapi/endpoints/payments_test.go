@@ -0,0 +1,210 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/dispute"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+type mockPromiseRecovery struct {
+	audit     pingpong.PromiseAudit
+	err       error
+	recovered bool
+}
+
+func (m *mockPromiseRecovery) Audit(chainID int64, id identity.Identity) (pingpong.PromiseAudit, error) {
+	m.audit.Identity = id
+	return m.audit, m.err
+}
+
+func (m *mockPromiseRecovery) Recover(chainID int64, id identity.Identity) (pingpong.PromiseAudit, error) {
+	m.recovered = true
+	m.audit.Identity = id
+	m.audit.Recovered = m.err == nil
+	return m.audit, m.err
+}
+
+type mockDisputeKeeper struct {
+	disputes map[session.ID]dispute.Dispute
+}
+
+func (m *mockDisputeKeeper) Flag(sessionID session.ID, reason string) (dispute.Dispute, error) {
+	if sessionID != "session-1" {
+		return dispute.Dispute{}, dispute.ErrUnknownSession
+	}
+	if d, ok := m.disputes[sessionID]; ok && d.Status == dispute.StatusOpen {
+		return dispute.Dispute{}, dispute.ErrAlreadyDisputed
+	}
+	d := dispute.Dispute{SessionID: sessionID, Reason: reason, Status: dispute.StatusOpen}
+	m.disputes[sessionID] = d
+	return d, nil
+}
+
+func (m *mockDisputeKeeper) Resolve(sessionID session.ID) (dispute.Dispute, error) {
+	d, ok := m.disputes[sessionID]
+	if !ok {
+		return dispute.Dispute{}, dispute.ErrNotFound
+	}
+	d.Status = dispute.StatusResolved
+	m.disputes[sessionID] = d
+	return d, nil
+}
+
+func (m *mockDisputeKeeper) Get(sessionID session.ID) (dispute.Dispute, error) {
+	d, ok := m.disputes[sessionID]
+	if !ok {
+		return dispute.Dispute{}, dispute.ErrNotFound
+	}
+	return d, nil
+}
+
+func (m *mockDisputeKeeper) List() (res []dispute.Dispute, err error) {
+	for _, d := range m.disputes {
+		res = append(res, d)
+	}
+	return res, nil
+}
+
+func (m *mockDisputeKeeper) Bundle(sessionID session.ID) (dispute.Bundle, error) {
+	d, err := m.Get(sessionID)
+	if err != nil {
+		return dispute.Bundle{}, err
+	}
+	return dispute.NewBundle(d, dispute.Trail{SessionID: sessionID}, time.Now()), nil
+}
+
+func newPaymentsRouter(recovery promiseRecovery) *gin.Engine {
+	return newPaymentsRouterWithDisputes(recovery, &mockDisputeKeeper{disputes: map[session.ID]dispute.Dispute{}})
+}
+
+func newPaymentsRouterWithDisputes(recovery promiseRecovery, disputes disputeKeeper) *gin.Engine {
+	router := gin.Default()
+	router.Use(apierror.ErrorHandler)
+	AddRoutesForPayments(recovery, disputes)(router)
+	return router
+}
+
+func Test_PaymentsAudit(t *testing.T) {
+	recovery := &mockPromiseRecovery{audit: pingpong.PromiseAudit{
+		LocalAmount:  big.NewInt(0),
+		HermesAmount: big.NewInt(100),
+		Discrepancy:  pingpong.PromiseMissing,
+	}}
+
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, "/payments/audit/0x1", nil)
+	assert.NoError(t, err)
+	newPaymentsRouter(recovery).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var res contract.PromiseAuditResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+	assert.Equal(t, "0x1", res.Identity)
+	assert.Equal(t, "missing", res.Discrepancy)
+	assert.Equal(t, big.NewInt(100), res.HermesAmount)
+	assert.False(t, res.Recovered)
+	assert.False(t, recovery.recovered)
+}
+
+func Test_PaymentsRecover(t *testing.T) {
+	recovery := &mockPromiseRecovery{audit: pingpong.PromiseAudit{
+		LocalAmount:  big.NewInt(100),
+		HermesAmount: big.NewInt(100),
+		Discrepancy:  pingpong.PromiseCorrupted,
+	}}
+
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, "/payments/audit/0x1/recover", nil)
+	assert.NoError(t, err)
+	newPaymentsRouter(recovery).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var res contract.PromiseAuditResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+	assert.True(t, res.Recovered)
+	assert.True(t, recovery.recovered)
+}
+
+func Test_PaymentsRecover_Error(t *testing.T) {
+	recovery := &mockPromiseRecovery{err: errors.New("hermes unavailable")}
+
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, "/payments/audit/0x1/recover", nil)
+	assert.NoError(t, err)
+	newPaymentsRouter(recovery).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Equal(t, contract.ErrCodePaymentsRecover, apierror.Parse(resp.Result()).Err.Code)
+}
+
+func Test_PaymentsDisputes(t *testing.T) {
+	router := newPaymentsRouterWithDisputes(&mockPromiseRecovery{}, &mockDisputeKeeper{disputes: map[session.ID]dispute.Dispute{}})
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/payments/disputes", strings.NewReader(`{"session_id": "unknown"}`)))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/payments/disputes", strings.NewReader(`{"reason": "underpaid"}`)))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/payments/disputes", strings.NewReader(`{"session_id": "session-1", "reason": "underpaid"}`)))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var res contract.DisputeDTO
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+	assert.Equal(t, "session-1", res.SessionID)
+	assert.Equal(t, "open", res.Status)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/payments/disputes", strings.NewReader(`{"session_id": "session-1"}`)))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/payments/disputes/session-1/bundle", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "attachment; filename=dispute-session-1.json", resp.Header().Get("Content-Disposition"))
+	assert.Contains(t, resp.Body.String(), `"reason":"underpaid"`)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/payments/disputes/session-1/resolve", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+	assert.Equal(t, "resolved", res.Status)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/payments/disputes/session-2", nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -18,15 +18,66 @@
 package endpoints
 
 import (
+	"crypto/subtle"
 	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	rpprof "runtime/pprof"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
 )
 
-// AddRoutesForPProf adds pprof http handlers to given router
-func AddRoutesForPProf(e *gin.Engine) {
-	e.GET("/debug/pprof/", pprofHandler)
-	e.GET("/debug/pprof/:profile", pprofHandler)
+type tokenValidator interface {
+	ValidateToken(token string) (bool, error)
+}
+
+// AddRoutesForPProf adds pprof and runtime debug http handlers to given router.
+// Handlers are only served to requests carrying an API login token or the admin token, if set.
+func AddRoutesForPProf(e *gin.Engine, validator tokenValidator, adminToken string) {
+	g := e.Group("/debug", tokenAuth(validator, adminToken))
+	g.GET("/pprof/", pprofHandler)
+	g.GET("/pprof/:profile", pprofHandler)
+	g.GET("/goroutines", goroutineDumpHandler)
+	g.GET("/gc", gcStatsHandler)
+}
+
+// tokenAuth lets through requests carrying a valid API login token or the admin token, if set.
+func tokenAuth(validator tokenValidator, adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := requestToken(c)
+		if token == "" {
+			c.Error(apierror.Unauthorized())
+			c.Abort()
+			return
+		}
+
+		if adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1 {
+			return
+		}
+		if ok, err := validator.ValidateToken(token); err != nil || !ok {
+			log.Warn().Str("path", c.Request.URL.Path).Msg("Rejected unauthorized request")
+			c.Error(apierror.Unauthorized())
+			c.Abort()
+		}
+	}
+}
+
+func requestToken(c *gin.Context) string {
+	parts := strings.Fields(c.GetHeader("Authorization"))
+	if len(parts) == 2 && strings.EqualFold(parts[0], "bearer") {
+		return parts[1]
+	}
+
+	token, _ := c.Cookie(auth.JWTCookieName)
+	return token
 }
 
 func pprofHandler(c *gin.Context) {
@@ -46,3 +97,21 @@ func pprofHandler(c *gin.Context) {
 		pprof.Index(w, r)
 	}
 }
+
+// goroutineDumpHandler writes stack traces of all goroutines in the panic format.
+func goroutineDumpHandler(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	if err := rpprof.Lookup("goroutine").WriteTo(c.Writer, 2); err != nil {
+		log.Warn().Err(err).Msg("Failed to write goroutine dump")
+	}
+}
+
+func gcStatsHandler(c *gin.Context) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	gc := debug.GCStats{PauseQuantiles: make([]time.Duration, 5)}
+	debug.ReadGCStats(&gc)
+
+	utils.WriteAsJSON(contract.NewDebugGCStatsResponse(gc, mem, runtime.NumGoroutine()), c.Writer)
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+type mockTokenValidator struct {
+	valid string
+}
+
+func (v *mockTokenValidator) ValidateToken(token string) (bool, error) {
+	if token != v.valid {
+		return false, errors.New("invalid JWT token")
+	}
+	return true, nil
+}
+
+func Test_PProfRequiresAuth(t *testing.T) {
+	router := gin.New()
+	router.Use(apierror.ErrorHandler)
+	AddRoutesForPProf(router, &mockTokenValidator{valid: "jwt"}, "admin")
+
+	for name, tc := range map[string]struct {
+		header, cookie string
+		status         int
+	}{
+		"no token":       {status: http.StatusUnauthorized},
+		"invalid token":  {header: "Bearer nope", status: http.StatusUnauthorized},
+		"malformed":      {header: "admin", status: http.StatusUnauthorized},
+		"admin token":    {header: "Bearer admin", status: http.StatusOK},
+		"login token":    {header: "Bearer jwt", status: http.StatusOK},
+		"login cookie":   {cookie: "jwt", status: http.StatusOK},
+		"invalid cookie": {cookie: "nope", status: http.StatusUnauthorized},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/debug/goroutines", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: auth.JWTCookieName, Value: tc.cookie})
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.status, resp.Code)
+			if tc.status == http.StatusOK {
+				assert.Contains(t, resp.Body.String(), "goroutine ")
+			}
+		})
+	}
+}
+
+func Test_PProfWithoutAdminToken(t *testing.T) {
+	router := gin.New()
+	router.Use(apierror.ErrorHandler)
+	AddRoutesForPProf(router, &mockTokenValidator{valid: "jwt"}, "")
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/gc", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/debug/gc", nil)
+	req.Header.Set("Authorization", "Bearer jwt")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var stats contract.DebugGCStatsResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &stats))
+	assert.Greater(t, stats.Goroutines, 0)
+	assert.Greater(t, stats.HeapAllocBytes, uint64(0))
+	assert.Len(t, stats.PauseQuantilesMs, 5)
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/core/preflight"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type preflightChecker interface {
+	Run(ctx context.Context) preflight.Report
+	Report() preflight.Report
+}
+
+type preflightEndpoint struct {
+	checker preflightChecker
+}
+
+// NewPreflightEndpoint creates and returns preflight endpoint
+func NewPreflightEndpoint(checker preflightChecker) *preflightEndpoint {
+	return &preflightEndpoint{checker: checker}
+}
+
+// swagger:operation GET /preflight Preflight getPreflightReport
+// ---
+// summary: Returns provider startup dependency checks report
+// description: Returns results of the checks performed on provider startup (TUN, capabilities, WireGuard, UDP reachability, clock, disk space) with remedies for failures.
+// responses:
+//   200:
+//     description: Startup dependency checks report
+//     schema:
+//       "$ref": "#/definitions/PreflightReportDTO"
+func (e *preflightEndpoint) Report(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewPreflightReportDTO(e.checker.Report()), c.Writer)
+}
+
+// swagger:operation POST /preflight Preflight runPreflight
+// ---
+// summary: Re-runs provider startup dependency checks
+// description: Performs the startup dependency checks again, e.g. after fixing reported failures, and returns the new report.
+// responses:
+//   200:
+//     description: Startup dependency checks report
+//     schema:
+//       "$ref": "#/definitions/PreflightReportDTO"
+func (e *preflightEndpoint) Run(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewPreflightReportDTO(e.checker.Run(c.Request.Context())), c.Writer)
+}
+
+// AddRoutesForPreflight attaches preflight endpoints to router
+func AddRoutesForPreflight(checker preflightChecker) func(*gin.Engine) error {
+	endpoint := NewPreflightEndpoint(checker)
+	return func(e *gin.Engine) error {
+		g := e.Group("/preflight")
+		{
+			g.GET("", endpoint.Report)
+			g.POST("", endpoint.Run)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/preflight"
+)
+
+type mockPreflightChecker struct {
+	report preflight.Report
+	runs   int
+}
+
+func (m *mockPreflightChecker) Run(ctx context.Context) preflight.Report {
+	m.runs++
+	return m.report
+}
+
+func (m *mockPreflightChecker) Report() preflight.Report {
+	return m.report
+}
+
+func TestPreflightEndpoint(t *testing.T) {
+	checker := &mockPreflightChecker{report: preflight.Report{
+		StartedAt: time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC),
+		Results: []preflight.Result{
+			{Name: "tun", Critical: true, Status: preflight.StatusFailed, Error: "no tun", Remedy: "modprobe tun", Duration: 2 * time.Millisecond},
+			{Name: "wireguard", Status: preflight.StatusOK},
+		},
+	}}
+	router := summonTestGin()
+	assert.NoError(t, AddRoutesForPreflight(checker)(router))
+
+	expected := `{
+		"started_at": "2022-01-01T12:00:00Z",
+		"passed": false,
+		"degraded": false,
+		"checks": [
+			{"name": "tun", "critical": true, "status": "failed", "error": "no tun", "remedy": "modprobe tun", "duration_ms": 2},
+			{"name": "wireguard", "critical": false, "status": "ok", "duration_ms": 0}
+		]
+	}`
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/preflight", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, expected, resp.Body.String())
+	assert.Equal(t, 0, checker.runs)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/preflight", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, expected, resp.Body.String())
+	assert.Equal(t, 1, checker.runs)
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type priceBookKeeper interface {
+	Get() market.PriceBook
+	Set(book market.PriceBook) error
+}
+
+type priceBookEndpoint struct {
+	keeper priceBookKeeper
+}
+
+// NewPriceBookEndpoint creates and returns provider price book endpoint
+func NewPriceBookEndpoint(keeper priceBookKeeper) *priceBookEndpoint {
+	return &priceBookEndpoint{keeper: keeper}
+}
+
+// swagger:operation GET /pricing/book Pricing getPriceBook
+// ---
+// summary: Returns provider price book
+// description: Returns prices provider sets per service type and consumer country instead of network prices
+// responses:
+//   200:
+//     description: Price book
+//     schema:
+//       "$ref": "#/definitions/PriceBookDTO"
+func (e *priceBookEndpoint) Get(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewPriceBookDTO(e.keeper.Get()), c.Writer)
+}
+
+// swagger:operation PUT /pricing/book Pricing setPriceBook
+// ---
+// summary: Replaces provider price book
+// description: Replaces the price book, running services advertise it with the next proposal announcement
+// parameters:
+//   - in: body
+//     name: body
+//     required: true
+//     schema:
+//       $ref: "#/definitions/PriceBookDTO"
+// responses:
+//   200:
+//     description: Price book
+//     schema:
+//       "$ref": "#/definitions/PriceBookDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (e *priceBookEndpoint) Set(c *gin.Context) {
+	var req contract.PriceBookDTO
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := e.keeper.Set(req.PriceBook()); err != nil {
+		c.Error(apierror.Internal("Could not save price book: "+err.Error(), contract.ErrCodePriceBookSet))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewPriceBookDTO(e.keeper.Get()), c.Writer)
+}
+
+// swagger:operation DELETE /pricing/book Pricing clearPriceBook
+// ---
+// summary: Clears provider price book
+// description: Removes all price book entries, network prices apply to all consumers
+// responses:
+//   202:
+//     description: Price book cleared
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (e *priceBookEndpoint) Clear(c *gin.Context) {
+	if err := e.keeper.Set(market.PriceBook{}); err != nil {
+		c.Error(apierror.Internal("Could not clear price book: "+err.Error(), contract.ErrCodePriceBookSet))
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// AddRoutesForPriceBook attaches provider price book endpoints to router
+func AddRoutesForPriceBook(keeper priceBookKeeper) func(*gin.Engine) error {
+	endpoint := NewPriceBookEndpoint(keeper)
+	return func(e *gin.Engine) error {
+		g := e.Group("/pricing")
+		{
+			g.GET("/book", endpoint.Get)
+			g.PUT("/book", endpoint.Set)
+			g.DELETE("/book", endpoint.Clear)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/market"
+)
+
+type mockPriceBookKeeper struct {
+	book market.PriceBook
+}
+
+func (m *mockPriceBookKeeper) Get() market.PriceBook {
+	return m.book
+}
+
+func (m *mockPriceBookKeeper) Set(book market.PriceBook) error {
+	m.book = book
+	return nil
+}
+
+func TestPriceBookEndpoint_SetAndGet(t *testing.T) {
+	keeper := &mockPriceBookKeeper{}
+	router := summonTestGin()
+	assert.NoError(t, AddRoutesForPriceBook(keeper)(router))
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPut, "/pricing/book", strings.NewReader(`{
+		"regions": {"baltics": ["LT", "LV", "EE"]},
+		"entries": [{"service_type": "wireguard", "country": "baltics", "price_per_hour": 100, "price_per_gib": 200}]
+	}`)))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, market.PriceBook{
+		Regions: map[string][]string{"baltics": {"LT", "LV", "EE"}},
+		Entries: []market.PriceBookEntry{{ServiceType: "wireguard", Country: "baltics", Price: *market.NewPrice(100, 200)}},
+	}, keeper.book)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/pricing/book", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"country":"baltics"`)
+	assert.Contains(t, resp.Body.String(), `"price_per_gib":200`)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodDelete, "/pricing/book", nil))
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Empty(t, keeper.book.Entries)
+}
+
+func TestPriceBookEndpoint_SetRejectsInvalid(t *testing.T) {
+	keeper := &mockPriceBookKeeper{}
+	router := summonTestGin()
+	assert.NoError(t, AddRoutesForPriceBook(keeper)(router))
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPut, "/pricing/book", strings.NewReader(`{
+		"entries": [{"country": "DE", "price_per_hour": -1}]
+	}`)))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "entries[0].price_per_gib")
+	assert.Nil(t, keeper.book.Entries)
+}
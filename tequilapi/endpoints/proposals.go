@@ -30,6 +30,7 @@ import (
 	"github.com/mysteriumnetwork/node/nat"
 	"github.com/mysteriumnetwork/node/services/datatransfer"
 	"github.com/mysteriumnetwork/node/services/scraping"
+	"github.com/mysteriumnetwork/node/services/speedtest"
 	"github.com/mysteriumnetwork/node/services/wireguard"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
@@ -296,7 +297,7 @@ func (pe *proposalsEndpoint) CurrentPrice(c *gin.Context) {
 	serviceType := c.Request.URL.Query().Get("service_type")
 	if len(serviceType) == 0 {
 		serviceType = wireguard.ServiceType
-	} else if serviceType != wireguard.ServiceType && serviceType != scraping.ServiceType && serviceType != datatransfer.ServiceType {
+	} else if serviceType != wireguard.ServiceType && serviceType != scraping.ServiceType && serviceType != datatransfer.ServiceType && serviceType != speedtest.ServiceType {
 		c.Error(apierror.BadRequest("Invalid service type", contract.ErrCodeProposalsServiceType))
 		return
 	}
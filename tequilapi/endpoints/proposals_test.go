@@ -24,6 +24,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -127,7 +128,7 @@ func TestProposalsEndpointListByNodeId(t *testing.T) {
             "proposals": [
                 {
                     "format": "service-proposal/v3",
-                    "compatibility": 2,
+                    "compatibility": 3,
                     "provider_id": "0xProviderId",
                     "service_type": "testprotocol",
                     "location": {
@@ -166,7 +167,7 @@ func TestProposalsEndpointListByNodeId(t *testing.T) {
 		ProviderID:         "0xProviderId",
 		ExcludeUnsupported: true,
 		CompatibilityMin:   2,
-	}, repository.recordedFilter)
+	}, repository.lastFilter())
 }
 
 func TestProposalsEndpointAcceptsAccessPolicyParams(t *testing.T) {
@@ -199,7 +200,7 @@ func TestProposalsEndpointAcceptsAccessPolicyParams(t *testing.T) {
             "proposals": [
                 {
                     "format": "service-proposal/v3",
-                    "compatibility": 2,
+                    "compatibility": 3,
                     "provider_id": "0xProviderId",
                     "service_type": "testprotocol",
                     "location": {
@@ -240,7 +241,7 @@ func TestProposalsEndpointAcceptsAccessPolicyParams(t *testing.T) {
 			ExcludeUnsupported: true,
 			CompatibilityMin:   2,
 		},
-		repository.recordedFilter,
+		repository.lastFilter(),
 	)
 }
 
@@ -335,7 +336,7 @@ func TestProposalsEndpointFilterByPresetID(t *testing.T) {
             "proposals": [
                 {
                     "format": "service-proposal/v3",
-                    "compatibility": 2,
+                    "compatibility": 3,
                     "provider_id": "0xProviderId",
                     "service_type": "testprotocol",
                     "location": {
@@ -367,7 +368,7 @@ func TestProposalsEndpointFilterByPresetID(t *testing.T) {
                 },
                 {
                     "format": "service-proposal/v3",
-                    "compatibility": 2,
+                    "compatibility": 3,
                     "provider_id": "other_provider",
                     "service_type": "testprotocol",
                     "location": {
@@ -405,8 +406,9 @@ func TestProposalsEndpointFilterByPresetID(t *testing.T) {
 
 type mockProposalRepository struct {
 	proposals      []proposal.PricedServiceProposal
-	recordedFilter *proposal.Filter
 	priceToAdd     market.Price
+	mu             sync.Mutex
+	recordedFilter *proposal.Filter
 }
 
 func (m *mockProposalRepository) Proposal(_ market.ProposalID) (*proposal.PricedServiceProposal, error) {
@@ -417,15 +419,27 @@ func (m *mockProposalRepository) Proposal(_ market.ProposalID) (*proposal.Priced
 }
 
 func (m *mockProposalRepository) Proposals(filter *proposal.Filter) ([]proposal.PricedServiceProposal, error) {
-	m.recordedFilter = filter
+	m.recordFilter(filter)
 	return m.proposals, nil
 }
 
 func (m *mockProposalRepository) Countries(filter *proposal.Filter) (map[string]int, error) {
-	m.recordedFilter = filter
+	m.recordFilter(filter)
 	return nil, nil
 }
 
+func (m *mockProposalRepository) recordFilter(filter *proposal.Filter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordedFilter = filter
+}
+
+func (m *mockProposalRepository) lastFilter() *proposal.Filter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.recordedFilter
+}
+
 func (m *mockProposalRepository) EnrichProposalWithPrice(in market.ServiceProposal) (proposal.PricedServiceProposal, error) {
 	return proposal.PricedServiceProposal{
 		Price:           m.priceToAdd,
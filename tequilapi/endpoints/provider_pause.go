@@ -0,0 +1,139 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type servicePauser interface {
+	Status() service.PauseStatus
+	Pause(keepSessions bool) (service.PauseStatus, error)
+	Resume() (service.PauseStatus, error)
+}
+
+type providerPauseEndpoint struct {
+	pauser servicePauser
+}
+
+// NewProviderPauseEndpoint creates and returns provider pause endpoint
+func NewProviderPauseEndpoint(pauser servicePauser) *providerPauseEndpoint {
+	return &providerPauseEndpoint{pauser: pauser}
+}
+
+// swagger:operation GET /node/provider/pause provider GetProviderPause
+// ---
+// summary: Provides provider pause status
+// responses:
+//   200:
+//     description: Provider pause status
+//     schema:
+//       "$ref": "#/definitions/ProviderPauseStatusDTO"
+func (e *providerPauseEndpoint) Status(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewProviderPauseStatusDTO(e.pauser.Status()), c.Writer)
+}
+
+// swagger:operation POST /node/provider/pause provider PauseProvider
+// ---
+// summary: Pauses provider services
+// description: Unpublishes proposals of all running services and rejects new sessions without stopping services. Active sessions are kept unless requested otherwise.
+// parameters:
+//   - in: body
+//     name: body
+//     required: false
+//     schema:
+//       $ref: "#/definitions/ProviderPauseRequest"
+// responses:
+//   200:
+//     description: Provider pause status
+//     schema:
+//       "$ref": "#/definitions/ProviderPauseStatusDTO"
+//   400:
+//     description: Failed to parse request
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   409:
+//     description: Services are already paused
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (e *providerPauseEndpoint) Pause(c *gin.Context) {
+	var req contract.ProviderPauseRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+
+	status, err := e.pauser.Pause(req.KeepActiveSessions())
+	if errors.Is(err, service.ErrAlreadyPaused) {
+		c.Error(apierror.Conflict(err.Error(), contract.ErrCodeProviderPause, "paused"))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal(err.Error(), contract.ErrCodeProviderPause))
+		return
+	}
+	utils.WriteAsJSON(contract.NewProviderPauseStatusDTO(status), c.Writer)
+}
+
+// swagger:operation POST /node/provider/resume provider ResumeProvider
+// ---
+// summary: Resumes paused provider services
+// responses:
+//   200:
+//     description: Provider pause status
+//     schema:
+//       "$ref": "#/definitions/ProviderPauseStatusDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (e *providerPauseEndpoint) Resume(c *gin.Context) {
+	status, err := e.pauser.Resume()
+	if err != nil {
+		c.Error(apierror.Internal(err.Error(), contract.ErrCodeProviderResume))
+		return
+	}
+	utils.WriteAsJSON(contract.NewProviderPauseStatusDTO(status), c.Writer)
+}
+
+// AddRoutesForProviderPause attaches provider pause endpoints to router
+func AddRoutesForProviderPause(pauser servicePauser) func(*gin.Engine) error {
+	endpoint := NewProviderPauseEndpoint(pauser)
+	return func(e *gin.Engine) error {
+		g := e.Group("/node/provider")
+		{
+			g.GET("/pause", endpoint.Status)
+			g.POST("/pause", endpoint.Pause)
+			g.POST("/resume", endpoint.Resume)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/service"
+)
+
+type mockServicePauser struct {
+	status service.PauseStatus
+}
+
+func (m *mockServicePauser) Status() service.PauseStatus {
+	return m.status
+}
+
+func (m *mockServicePauser) Pause(keepSessions bool) (service.PauseStatus, error) {
+	if m.status.Paused {
+		return m.status, service.ErrAlreadyPaused
+	}
+	m.status = service.PauseStatus{
+		Paused:       true,
+		Since:        time.Date(2022, 3, 4, 10, 0, 0, 0, time.UTC),
+		KeepSessions: keepSessions,
+		Services:     []service.ID{"svc1"},
+	}
+	return m.status, nil
+}
+
+func (m *mockServicePauser) Resume() (service.PauseStatus, error) {
+	m.status = service.PauseStatus{}
+	return m.status, nil
+}
+
+func TestProviderPauseEndpoint(t *testing.T) {
+	pauser := &mockServicePauser{}
+	router := summonTestGin()
+	require.NoError(t, AddRoutesForProviderPause(pauser)(router))
+
+	req := httptest.NewRequest(http.MethodPost, "/node/provider/pause", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"paused": true, "since": "2022-03-04T10:00:00Z", "keep_sessions": true, "services": ["svc1"]}`, resp.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/node/provider/pause", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/node/provider/resume", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"paused": false, "keep_sessions": false, "services": []}`, resp.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/node/provider/pause", strings.NewReader(`{"keep_sessions": false}`))
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.False(t, pauser.status.KeepSessions)
+
+	req = httptest.NewRequest(http.MethodGet, "/node/provider/pause", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"paused": true, "since": "2022-03-04T10:00:00Z", "keep_sessions": false, "services": ["svc1"]}`, resp.Body.String())
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/receipt"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type receiptStorage interface {
+	Get(sessionID session.ID) (receipt.Receipt, error)
+	List() ([]receipt.Receipt, error)
+}
+
+type sessionReceiptsEndpoint struct {
+	receipts receiptStorage
+}
+
+// NewSessionReceiptsEndpoint creates and returns session receipts endpoint
+func NewSessionReceiptsEndpoint(receipts receiptStorage) *sessionReceiptsEndpoint {
+	return &sessionReceiptsEndpoint{receipts: receipts}
+}
+
+// swagger:operation GET /session-receipts Session sessionReceiptList
+// ---
+// summary: Returns session receipts
+// description: Returns receipts of finished sessions signed by both consumer and provider, the newest first
+// responses:
+//   200:
+//     description: List of session receipts
+//     schema:
+//       "$ref": "#/definitions/SessionReceiptListResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (endpoint *sessionReceiptsEndpoint) List(c *gin.Context) {
+	receipts, err := endpoint.receipts.List()
+	if err != nil {
+		c.Error(apierror.Internal("Could not list session receipts: "+err.Error(), contract.ErrCodeSessionReceiptList))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewSessionReceiptListResponse(receipts), c.Writer)
+}
+
+// swagger:operation GET /session-receipts/{id} Session sessionReceiptGet
+// ---
+// summary: Returns session receipt
+// description: Returns receipt of the given session signed by both consumer and provider
+// parameters:
+//   - in: path
+//     name: id
+//     description: session id
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Session receipt
+//     schema:
+//       "$ref": "#/definitions/SessionReceiptDTO"
+//   404:
+//     description: Receipt not found
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (endpoint *sessionReceiptsEndpoint) Get(c *gin.Context) {
+	r, err := endpoint.receipts.Get(session.ID(c.Param("id")))
+	if errors.Is(err, receipt.ErrNotFound) {
+		c.Error(apierror.NotFound("Session receipt not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal("Could not get session receipt: "+err.Error(), contract.ErrCodeSessionReceiptGet))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewSessionReceiptDTO(r), c.Writer)
+}
+
+// AddRoutesForSessionReceipts attaches session receipts endpoints to router
+func AddRoutesForSessionReceipts(receipts receiptStorage) func(*gin.Engine) error {
+	endpoint := NewSessionReceiptsEndpoint(receipts)
+	return func(e *gin.Engine) error {
+		g := e.Group("/session-receipts")
+		{
+			g.GET("", endpoint.List)
+			g.GET("/:id", endpoint.Get)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/receipt"
+)
+
+type mockReceiptStorage struct {
+	receipts []receipt.Receipt
+}
+
+func (m *mockReceiptStorage) Get(sessionID session.ID) (receipt.Receipt, error) {
+	for _, r := range m.receipts {
+		if r.SessionID == sessionID {
+			return r, nil
+		}
+	}
+	return receipt.Receipt{}, receipt.ErrNotFound
+}
+
+func (m *mockReceiptStorage) List() ([]receipt.Receipt, error) {
+	return m.receipts, nil
+}
+
+func Test_SessionReceiptsEndpoint(t *testing.T) {
+	started := time.Date(2022, time.June, 15, 12, 0, 0, 0, time.UTC)
+	storage := &mockReceiptStorage{receipts: []receipt.Receipt{{
+		SessionID:         "s1",
+		ConsumerID:        identity.FromAddress("0x1"),
+		ProviderID:        identity.FromAddress("0x2"),
+		ServiceType:       "wireguard",
+		StartedAt:         started,
+		EndedAt:           started.Add(2 * time.Minute),
+		BytesSent:         10,
+		BytesReceived:     20,
+		Amount:            big.NewInt(500),
+		ConsumerSignature: "aa",
+		ProviderSignature: "bb",
+	}}}
+
+	g := summonTestGin()
+	err := AddRoutesForSessionReceipts(storage)(g)
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest(http.MethodGet, "/session-receipts/s1", nil)
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"session_id": "s1",
+		"consumer_id": "0x1",
+		"provider_id": "0x2",
+		"service_type": "wireguard",
+		"started_at": "2022-06-15T12:00:00Z",
+		"ended_at": "2022-06-15T12:02:00Z",
+		"duration": 120,
+		"bytes_sent": 10,
+		"bytes_received": 20,
+		"amount": 500,
+		"consumer_signature": "aa",
+		"provider_signature": "bb"
+	}`, resp.Body.String())
+
+	req, _ = http.NewRequest(http.MethodGet, "/session-receipts/s2", nil)
+	resp = httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/session-receipts", nil)
+	resp = httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"session_id":"s1"`)
+}
@@ -22,12 +22,15 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mysteriumnetwork/go-rest/apierror"
 
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/services"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
@@ -39,6 +42,12 @@ type ServiceEndpoint struct {
 	serviceManager     ServiceManager
 	optionsParser      map[string]services.ServiceOptionsParser
 	proposalRepository proposalRepository
+	terms              providerTerms
+}
+
+// providerTerms tells whether provider terms of service are accepted.
+type providerTerms interface {
+	CheckProvider() error
 }
 
 var (
@@ -49,11 +58,12 @@ var (
 )
 
 // NewServiceEndpoint creates and returns service endpoint
-func NewServiceEndpoint(serviceManager ServiceManager, optionsParser map[string]services.ServiceOptionsParser, proposalRepository proposalRepository) *ServiceEndpoint {
+func NewServiceEndpoint(serviceManager ServiceManager, optionsParser map[string]services.ServiceOptionsParser, proposalRepository proposalRepository, terms providerTerms) *ServiceEndpoint {
 	return &ServiceEndpoint{
 		serviceManager:     serviceManager,
 		optionsParser:      optionsParser,
 		proposalRepository: proposalRepository,
+		terms:              terms,
 	}
 }
 
@@ -147,6 +157,10 @@ func (se *ServiceEndpoint) ServiceGet(c *gin.Context) {
 //     description: Failed to parse or request validation failed
 //     schema:
 //       "$ref": "#/definitions/APIError"
+//   403:
+//     description: Provider terms of service are not accepted
+//     schema:
+//       "$ref": "#/definitions/APIError"
 //   422:
 //     description: Unable to process the request at this point
 //     schema:
@@ -231,8 +245,166 @@ func (se *ServiceEndpoint) ServiceStop(c *gin.Context) {
 	c.Status(http.StatusAccepted)
 }
 
+// ServiceRestart replaces running service with a new configuration.
+// swagger:operation PUT /services/:id Service serviceRestart
+// ---
+// summary: Restarts service with new configuration
+// description: Starts a new service instance with given configuration and drains the old one, keeping its active sessions until they end. Services holding a fixed port, e.g. openvpn, are stopped before the new instance starts
+// parameters:
+//   - in: body
+//     name: body
+//     description: New service configuration
+//     schema:
+//       $ref: "#/definitions/ServiceStartRequestDTO"
+// responses:
+//   200:
+//     description: Service replaced
+//     schema:
+//       "$ref": "#/definitions/ServiceInfoDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   403:
+//     description: Provider terms of service are not accepted
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   404:
+//     description: No service exists
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   422:
+//     description: Unable to process the request at this point
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (se *ServiceEndpoint) ServiceRestart(c *gin.Context) {
+	id := service.ID(c.Param("id"))
+	instance := se.serviceManager.Service(id)
+	if instance == nil {
+		c.Error(apierror.NotFound("Service not found"))
+		return
+	}
+
+	sr, err := se.toServiceRequest(c.Request)
+	if err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+
+	if err := validateServiceRequest(sr); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if sr.ProviderID != instance.ProviderID.Address || sr.Type != instance.Type {
+		c.Error(apierror.Unprocessable("Provider and service type can not be changed", contract.ErrCodeServiceRunning))
+		return
+	}
+
+	log.Info().Msgf("Restarting service %s of type %s", id, sr.Type)
+	newID, err := se.serviceManager.Restart(id, sr.AccessPolicies.IDs, sr.Options)
+	if err == service.ErrorLocation {
+		c.Error(apierror.Unprocessable("Cannot detect location", contract.ErrCodeServiceLocation))
+		return
+	} else if err != nil {
+		c.Error(apierror.Internal("Cannot restart service: "+err.Error(), contract.ErrCodeServiceStart))
+		return
+	}
+
+	statusResponse, err := se.toServiceInfoResponse(newID, se.serviceManager.Service(newID))
+	if err != nil {
+		c.Error(apierror.Internal("Cannot generate response: "+err.Error(), contract.ErrCodeServiceGet))
+		return
+	}
+
+	utils.WriteAsJSON(statusResponse, c.Writer)
+}
+
+// ServicePreview renders proposal of the service configuration without starting it.
+// swagger:operation POST /services/preview Service servicePreview
+// ---
+// summary: Previews service proposal
+// description: Renders the proposal (price, location, access policies, capabilities) which would be published to discovery for the given service configuration, without starting the service
+// parameters:
+//   - in: body
+//     name: body
+//     description: Service configuration
+//     schema:
+//       $ref: "#/definitions/ServiceStartRequestDTO"
+// responses:
+//   200:
+//     description: Service proposal preview
+//     schema:
+//       "$ref": "#/definitions/ServicePreviewDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   422:
+//     description: Unable to process the request at this point
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (se *ServiceEndpoint) ServicePreview(c *gin.Context) {
+	sr, err := se.toServiceRequest(c.Request)
+	if err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+
+	if err := validateServiceRequest(sr); err != nil {
+		c.Error(err)
+		return
+	}
+
+	proposal, err := se.serviceManager.Preview(identity.FromAddress(sr.ProviderID), sr.Type, sr.AccessPolicies.IDs)
+	if err == service.ErrorLocation {
+		c.Error(apierror.Unprocessable("Cannot detect location", contract.ErrCodeServiceLocation))
+		return
+	} else if err != nil {
+		c.Error(apierror.Internal("Cannot preview service: "+err.Error(), contract.ErrCodeServicePreview))
+		return
+	}
+
+	priced, err := se.proposalRepository.EnrichProposalWithPrice(proposal)
+	if err != nil {
+		c.Error(apierror.Internal("Cannot get service price: "+err.Error(), contract.ErrCodeServicePreview))
+		return
+	}
+
+	payload, err := json.Marshal(proposal)
+	if err != nil {
+		c.Error(apierror.Internal("Cannot render proposal: "+err.Error(), contract.ErrCodeServicePreview))
+		return
+	}
+
+	res := contract.ServicePreviewDTO{
+		ProviderID: sr.ProviderID,
+		Type:       sr.Type,
+		Proposal:   contract.NewProposalDTO(priced),
+		Payload:    payload,
+		Valid:      true,
+	}
+	if err := proposal.Validate(); err != nil {
+		res.Valid = false
+		res.ValidationError = err.Error()
+	}
+
+	utils.WriteAsJSON(res, c.Writer)
+}
+
 func (se *ServiceEndpoint) isAlreadyRunning(sr contract.ServiceStartRequest) bool {
 	for _, instance := range se.serviceManager.List(false) {
+		if instance.State() == servicestate.Draining {
+			continue
+		}
 		if instance.ProviderID.Address == sr.ProviderID && instance.Type == sr.Type {
 			return true
 		}
@@ -245,21 +417,33 @@ func AddRoutesForService(
 	serviceManager ServiceManager,
 	optionsParser map[string]services.ServiceOptionsParser,
 	proposalRepository proposalRepository,
+	terms providerTerms,
 ) func(*gin.Engine) error {
-	serviceEndpoint := NewServiceEndpoint(serviceManager, optionsParser, proposalRepository)
+	serviceEndpoint := NewServiceEndpoint(serviceManager, optionsParser, proposalRepository, terms)
 
 	return func(e *gin.Engine) error {
 		g := e.Group("/services")
 		{
 			g.GET("", serviceEndpoint.ServiceList)
-			g.POST("", serviceEndpoint.ServiceStart)
+			g.POST("", serviceEndpoint.requireProviderTerms, serviceEndpoint.ServiceStart)
+			g.POST("/preview", serviceEndpoint.ServicePreview)
 			g.GET("/:id", serviceEndpoint.ServiceGet)
+			g.PUT("/:id", serviceEndpoint.requireProviderTerms, serviceEndpoint.ServiceRestart)
 			g.DELETE("/:id", serviceEndpoint.ServiceStop)
 		}
 		return nil
 	}
 }
 
+// requireProviderTerms rejects requests until provider terms of service are accepted.
+func (se *ServiceEndpoint) requireProviderTerms(c *gin.Context) {
+	if err := se.terms.CheckProvider(); err != nil {
+		c.Error(apierror.Forbidden("Provider terms of service must be accepted: "+err.Error(), contract.ErrCodeTermsNotAccepted))
+		c.Abort()
+		return
+	}
+}
+
 func (se *ServiceEndpoint) toServiceRequest(req *http.Request) (contract.ServiceStartRequest, error) {
 	var jsonData struct {
 		ProviderID     string                          `json:"provider_id"`
@@ -322,9 +506,11 @@ func (se *ServiceEndpoint) toServiceInfoResponse(id service.ID, instance *servic
 	}
 
 	var prop *contract.ProposalDTO
+	var health *contract.ServiceHealthDTO
 	if len(id) > 0 {
 		tmp := contract.NewProposalDTO(priced)
 		prop = &tmp
+		health = toServiceHealthDTO(instance.Health())
 	}
 
 	return contract.ServiceInfoDTO{
@@ -334,9 +520,23 @@ func (se *ServiceEndpoint) toServiceInfoResponse(id service.ID, instance *servic
 		Options:    instance.Options,
 		Status:     string(instance.State()),
 		Proposal:   prop,
+		Health:     health,
 	}, nil
 }
 
+func toServiceHealthDTO(health service.Health) *contract.ServiceHealthDTO {
+	dto := &contract.ServiceHealthDTO{
+		Status:   health.Status,
+		Error:    health.Error,
+		Failures: health.Failures,
+		Restarts: health.Restarts,
+	}
+	if !health.CheckedAt.IsZero() {
+		dto.CheckedAt = health.CheckedAt.Format(time.RFC3339)
+	}
+	return dto
+}
+
 func (se *ServiceEndpoint) toServiceListResponse(instances []*service.Instance) (contract.ServiceListResponse, error) {
 	res := make([]contract.ServiceInfoDTO, 0)
 	for _, instance := range instances {
@@ -369,6 +569,8 @@ func validateServiceRequest(sr contract.ServiceStartRequest) *apierror.APIError
 type ServiceManager interface {
 	Start(providerID identity.Identity, serviceType string, policies []string, options service.Options) (service.ID, error)
 	Stop(id service.ID) error
+	Restart(id service.ID, policies []string, options service.Options) (service.ID, error)
+	Preview(providerID identity.Identity, serviceType string, policies []string) (market.ServiceProposal, error)
 	Service(id service.ID) *service.Instance
 	Kill() error
 	List(includeAll bool) []*service.Instance
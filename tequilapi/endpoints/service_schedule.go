@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type serviceScheduleProvider interface {
+	Schedule() service.AccessSchedule
+	Paused() []string
+	Usage() []service.WindowUsage
+}
+
+type serviceScheduleEndpoint struct {
+	provider serviceScheduleProvider
+	now      func() time.Time
+}
+
+// NewServiceScheduleEndpoint creates and returns service schedule endpoint
+func NewServiceScheduleEndpoint(provider serviceScheduleProvider) *serviceScheduleEndpoint {
+	return &serviceScheduleEndpoint{provider: provider, now: time.Now}
+}
+
+// swagger:operation GET /node/provider/schedule provider GetProviderServiceSchedule
+// ---
+// summary: Provides service access windows
+// description: Returns weekly access windows of provider services, services paused until their next window and utilization of past windows.
+// responses:
+//   200:
+//     description: Service access windows and their utilization
+//     schema:
+//       "$ref": "#/definitions/ServiceScheduleResponse"
+func (e *serviceScheduleEndpoint) Schedule(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewServiceScheduleResponse(e.provider.Schedule(), e.provider.Paused(), e.provider.Usage(), e.now()), c.Writer)
+}
+
+// AddRoutesForServiceSchedule attaches service schedule endpoints to router
+func AddRoutesForServiceSchedule(provider serviceScheduleProvider) func(*gin.Engine) error {
+	endpoint := NewServiceScheduleEndpoint(provider)
+	return func(e *gin.Engine) error {
+		e.GET("/node/provider/schedule", endpoint.Schedule)
+		return nil
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/service"
+)
+
+type mockServiceScheduleProvider struct {
+	schedule service.AccessSchedule
+	paused   []string
+	usage    []service.WindowUsage
+}
+
+func (m *mockServiceScheduleProvider) Schedule() service.AccessSchedule {
+	return m.schedule
+}
+
+func (m *mockServiceScheduleProvider) Paused() []string {
+	return m.paused
+}
+
+func (m *mockServiceScheduleProvider) Usage() []service.WindowUsage {
+	return m.usage
+}
+
+func TestServiceScheduleEndpoint_Schedule(t *testing.T) {
+	start := time.Date(2022, 6, 3, 0, 0, 0, 0, time.UTC)
+	provider := &mockServiceScheduleProvider{
+		schedule: service.AccessSchedule{
+			{ServiceType: "wireguard", Days: []time.Weekday{time.Friday, time.Saturday}, From: 0, To: 7 * time.Hour},
+			{ServiceType: "scraping", From: 10*time.Hour + 30*time.Minute, To: 12 * time.Hour},
+		},
+		paused: []string{"scraping"},
+		usage: []service.WindowUsage{
+			{ServiceType: "wireguard", Start: start, End: start.Add(7 * time.Hour), Sessions: 2, Up: 100, Down: 10},
+		},
+	}
+	router := summonTestGin()
+	endpoint := NewServiceScheduleEndpoint(provider)
+	endpoint.now = func() time.Time { return start.Add(time.Hour) }
+	router.GET("/node/provider/schedule", endpoint.Schedule)
+
+	req := httptest.NewRequest(http.MethodGet, "/node/provider/schedule", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"windows": [
+			{"service_type": "wireguard", "days": ["fri", "sat"], "from": "00:00", "to": "07:00", "open": true},
+			{"service_type": "scraping", "from": "10:30", "to": "12:00", "open": false}
+		],
+		"paused": ["scraping"],
+		"usage": [
+			{"service_type": "wireguard", "start": "2022-06-03T00:00:00Z", "end": "2022-06-03T07:00:00Z", "sessions": 2, "up": 100, "down": 10}
+		]
+	}`, resp.Body.String())
+}
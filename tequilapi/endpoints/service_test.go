@@ -30,9 +30,11 @@ import (
 	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/core/tos"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/services"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -91,6 +93,15 @@ func (sm *mockServiceManager) Start(_ identity.Identity, serviceType string, _ [
 	return mockServiceID, nil
 }
 func (sm *mockServiceManager) Stop(id service.ID) error { return nil }
+func (sm *mockServiceManager) Restart(id service.ID, _ []string, _ service.Options) (service.ID, error) {
+	return mockServiceID, nil
+}
+func (sm *mockServiceManager) Preview(_ identity.Identity, serviceType string, _ []string) (market.ServiceProposal, error) {
+	if serviceType == serviceTypeWithAccessPolicy {
+		return mockProposalWithAccessPolicy, nil
+	}
+	return mockProposal, nil
+}
 func (sm *mockServiceManager) Service(id service.ID) *service.Instance {
 	if id == "6ba7b810-9dad-11d1-80b4-00c04fd430c8" {
 		return mockServiceRunning
@@ -110,6 +121,12 @@ func (sm *mockServiceManager) ListAll() []*service.Instance {
 }
 func (sm *mockServiceManager) Kill() error { return nil }
 
+type mockProviderTerms struct {
+	err error
+}
+
+func (mt *mockProviderTerms) CheckProvider() error { return mt.err }
+
 var fakeOptionsParser = map[string]services.ServiceOptionsParser{
 	"testprotocol": func(opts *json.RawMessage) (service.Options, error) {
 		return nil, nil
@@ -129,7 +146,7 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 			PricePerHour: big.NewInt(500_000_000_000_000_000),
 			PricePerGiB:  big.NewInt(1_000_000_000_000_000_000),
 		},
-	})(router)
+	}, &mockProviderTerms{})(router)
 	assert.NoError(t, err)
 	tests := []struct {
 		method         string
@@ -161,9 +178,10 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 				"type": "testprotocol",
 				"options": {"foo": "bar"},
 				"status": "Running",
+				"health": {"status": "unknown", "failures": 0, "restarts": 0},
 				"proposal": {
 		            "format": "service-proposal/v3",
-		            "compatibility": 2,
+		            "compatibility": 3,
 					"provider_id": "0xproviderid",
 					"service_type": "testprotocol",
 					"location": {
@@ -206,9 +224,10 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 				"type": "testprotocol",
 				"options": {"foo": "bar"},
 				"status": "Running",
+				"health": {"status": "unknown", "failures": 0, "restarts": 0},
 				"proposal": {
 		            "format": "service-proposal/v3",
-		            "compatibility": 2,
+		            "compatibility": 3,
 					"provider_id": "0xproviderid",
 					"service_type": "testprotocol",
 					"location": {
@@ -244,6 +263,14 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 			http.MethodDelete, "/services/6ba7b810-9dad-11d1-80b4-00c04fd430c8", "",
 			http.StatusAccepted, "",
 		},
+		{
+			http.MethodPut, "/services/00000000-9dad-11d1-80b4-00c04fd43000", `{"provider_id": "0xproviderid", "type": "testprotocol"}`,
+			http.StatusNotFound, `{ "error": {"code":"not_found", "message":"Service not found"}, "path":"/services/00000000-9dad-11d1-80b4-00c04fd43000", "status":404 }`,
+		},
+		{
+			http.MethodPut, "/services/6ba7b810-9dad-11d1-80b4-00c04fd430c8", `{"provider_id": "node1", "type": "testprotocol"}`,
+			http.StatusUnprocessableEntity, `{ "error": {"code":"err_service_running", "message":"Provider and service type can not be changed"}, "path":"/services/6ba7b810-9dad-11d1-80b4-00c04fd430c8", "status":422 }`,
+		},
 		{
 			http.MethodDelete, "/services/00000000-9dad-11d1-80b4-00c04fd43000", "",
 			http.StatusNotFound, `{ "error": {"code":"not_found", "message":"Service not found"}, "path":"/services/00000000-9dad-11d1-80b4-00c04fd43000", "status":404 }`,
@@ -264,6 +291,81 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 	}
 }
 
+func Test_ServiceRestart(t *testing.T) {
+	router := summonTestGin()
+	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{
+		priceToAdd: market.Price{
+			PricePerHour: big.NewInt(1),
+			PricePerGiB:  big.NewInt(1),
+		},
+	}, &mockProviderTerms{})(router)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/services/6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		strings.NewReader(`{"provider_id": "0xproviderid", "type": "testprotocol"}`),
+	)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var info contract.ServiceInfoDTO
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &info))
+	assert.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", info.ID)
+}
+
+func Test_ServicePreview(t *testing.T) {
+	router := summonTestGin()
+	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{
+		priceToAdd: market.Price{
+			PricePerHour: big.NewInt(1),
+			PricePerGiB:  big.NewInt(2),
+		},
+	}, &mockProviderTerms{})(router)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/services/preview",
+		strings.NewReader(`{"provider_id": "0xproviderid", "type": "mockAccessPolicyService"}`),
+	)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var preview contract.ServicePreviewDTO
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &preview))
+	assert.Equal(t, "0xproviderid", preview.ProviderID)
+	assert.Equal(t, serviceTypeWithAccessPolicy, preview.Type)
+	assert.Equal(t, uint64(2), preview.Proposal.Price.PerGiB)
+	assert.Len(t, *preview.Proposal.AccessPolicies, len(ap))
+
+	expectedPayload, err := json.Marshal(mockProposalWithAccessPolicy)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(expectedPayload), string(preview.Payload))
+
+	// mock proposal has no contacts, so discovery would reject it
+	assert.False(t, preview.Valid)
+	assert.Contains(t, preview.ValidationError, "contacts")
+}
+
+func Test_ServicePreviewInvalidType(t *testing.T) {
+	router := summonTestGin()
+	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{}, &mockProviderTerms{})(router)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/services/preview",
+		strings.NewReader(`{"provider_id": "0xproviderid", "type": "openvpn"}`),
+	)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
 func Test_ServiceStartInvalidType(t *testing.T) {
 	path := "/services"
 	req := httptest.NewRequest(
@@ -278,7 +380,7 @@ func Test_ServiceStartInvalidType(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{})(g)
+	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{}, &mockProviderTerms{})(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -290,6 +392,29 @@ func Test_ServiceStartInvalidType(t *testing.T) {
 	assert.Equal(t, "invalid_value", apiErr.Err.Fields["type"].Code)
 }
 
+func Test_ServiceStart_TermsNotAccepted(t *testing.T) {
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/services",
+		strings.NewReader(`{
+			"type": "testprotocol",
+			"provider_id": "0x9edf75f870d87d2d1a69f0d950a99984ae955ee0",
+			"options": {}
+		}`),
+	)
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{}, &mockProviderTerms{err: tos.ErrNotAccepted})(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	apiErr := apierror.Parse(resp.Result())
+	assert.Equal(t, contract.ErrCodeTermsNotAccepted, apiErr.Err.Code)
+}
+
 func Test_ServiceStart_InvalidType(t *testing.T) {
 	req := httptest.NewRequest(
 		http.MethodPost,
@@ -303,7 +428,7 @@ func Test_ServiceStart_InvalidType(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{})(g)
+	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{}, &mockProviderTerms{})(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -328,7 +453,7 @@ func Test_ServiceStart_InvalidOptions(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{})(g)
+	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{}, &mockProviderTerms{})(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -353,7 +478,7 @@ func Test_ServiceStartAlreadyRunning(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{})(g)
+	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{}, &mockProviderTerms{})(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -367,7 +492,7 @@ func Test_ServiceStatus_NotFoundIsReturnedWhenNotStarted(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{})(g)
+	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{}, &mockProviderTerms{})(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -389,6 +514,7 @@ func Test_ServiceGetReturnsServiceInfo(t *testing.T) {
 				PricePerGiB:  big.NewInt(1_000_000_000_000_000_000),
 			},
 		},
+		&mockProviderTerms{},
 	)(g)
 	assert.NoError(t, err)
 
@@ -403,9 +529,10 @@ func Test_ServiceGetReturnsServiceInfo(t *testing.T) {
 			"type": "testprotocol",
 			"options": {"foo": "bar"},
 			"status": "Running",
+			"health": {"status": "unknown", "failures": 0, "restarts": 0},
 			"proposal": {
 				"format": "service-proposal/v3",
-				"compatibility": 2,
+				"compatibility": 3,
 				"provider_id": "0xproviderid",
 				"service_type": "testprotocol",
 				"location": {
@@ -444,7 +571,7 @@ func Test_ServiceCreate_Returns400ErrorIfRequestBodyIsNotJSON(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{})(g)
+	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{}, &mockProviderTerms{})(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -458,7 +585,7 @@ func Test_ServiceCreate_Returns422ErrorIfRequestBodyIsMissingFieldValues(t *test
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{})(g)
+	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{}, &mockProviderTerms{})(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -492,7 +619,7 @@ func Test_ServiceStart_WithAccessPolicy(t *testing.T) {
 			PricePerHour: big.NewInt(500_000_000_000_000_000),
 			PricePerGiB:  big.NewInt(1_000_000_000_000_000_000),
 		},
-	})(g)
+	}, &mockProviderTerms{})(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -506,9 +633,10 @@ func Test_ServiceStart_WithAccessPolicy(t *testing.T) {
 			"type": "mockAccessPolicyService",
 			"options": {"foo": "bar"},
 			"status": "Running",
+			"health": {"status": "unknown", "failures": 0, "restarts": 0},
 			"proposal": {
 				"format": "service-proposal/v3",
-				"compatibility": 2,
+				"compatibility": 3,
 				"provider_id": "0xproviderid",
 				"service_type": "mockAccessPolicyService",
 				"location": {
@@ -576,7 +704,7 @@ func Test_ServiceStart_ReturnsBadRequest_WithUnknownParams(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{})(g)
+	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{}, &mockProviderTerms{})(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -18,6 +18,7 @@
 package endpoints
 
 import (
+	"errors"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -25,6 +26,7 @@ import (
 	"github.com/go-openapi/strfmt/conv"
 	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/consumer/session"
+	node_session "github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
 	"github.com/vcraescu/go-paginator/adapter"
@@ -34,6 +36,7 @@ type sessionStorage interface {
 	List(*session.Filter) ([]session.History, error)
 	Stats(*session.Filter) (session.Stats, error)
 	StatsByDay(*session.Filter) (map[time.Time]session.Stats, error)
+	Latency(node_session.ID) (session.Latency, error)
 }
 
 type sessionsEndpoint struct {
@@ -166,6 +169,43 @@ func (endpoint *sessionsEndpoint) StatsDaily(c *gin.Context) {
 	utils.WriteAsJSON(sessionsDTO, c.Writer)
 }
 
+// swagger:operation GET /sessions/{id}/latency Session sessionLatency
+// ---
+// summary: Returns session latency
+// description: Returns keepalive round trip times measured so far for an active session or the final ones for a completed session
+// parameters:
+//   - name: id
+//     in: path
+//     description: Session ID
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Session latency
+//     schema:
+//       "$ref": "#/definitions/SessionLatencyDTO"
+//   404:
+//     description: Session not found
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (endpoint *sessionsEndpoint) Latency(c *gin.Context) {
+	latency, err := endpoint.sessionStorage.Latency(node_session.ID(c.Param("id")))
+	if errors.Is(err, session.ErrSessionNotFound) {
+		c.Error(apierror.NotFound("Session not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal("Could not get session latency: "+err.Error(), contract.ErrCodeSessionLatency))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewSessionLatencyDTO(latency), c.Writer)
+}
+
 // AddRoutesForSessions attaches sessions endpoints to router
 func AddRoutesForSessions(sessionStorage sessionStorage) func(*gin.Engine) error {
 	sessionsEndpoint := NewSessionsEndpoint(sessionStorage)
@@ -175,6 +215,7 @@ func AddRoutesForSessions(sessionStorage sessionStorage) func(*gin.Engine) error
 			g.GET("", sessionsEndpoint.List)
 			g.GET("/stats-aggregated", sessionsEndpoint.StatsAggregated)
 			g.GET("/stats-daily", sessionsEndpoint.StatsDaily)
+			g.GET("/:id/latency", sessionsEndpoint.Latency)
 		}
 		return nil
 	}
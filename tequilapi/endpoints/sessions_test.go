@@ -246,13 +246,53 @@ func Test_SessionsEndpoint_StatsDaily(t *testing.T) {
 	assert.Equal(t, time.Now().UTC().Day(), ssm.calledWithFilter.StartedTo.Day())
 }
 
+func Test_SessionsEndpoint_Latency(t *testing.T) {
+	ssm := &sessionStorageMock{
+		latencyToReturn: session.Latency{
+			Samples: 10,
+			Last:    50 * time.Millisecond,
+			Min:     40 * time.Millisecond,
+			Avg:     55 * time.Millisecond,
+			P95:     90 * time.Millisecond,
+		},
+	}
+	g := summonTestGin()
+	err := AddRoutesForSessions(ssm)(g)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "/sessions/ID/latency", nil)
+	assert.NoError(t, err)
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, node_session.ID("ID"), ssm.calledWithID)
+	assert.JSONEq(t, `{"samples": 10, "last_ms": 50, "min_ms": 40, "avg_ms": 55, "p95_ms": 90}`, resp.Body.String())
+}
+
+func Test_SessionsEndpoint_LatencyNotFound(t *testing.T) {
+	ssm := &sessionStorageMock{errToReturn: session.ErrSessionNotFound}
+	g := summonTestGin()
+	err := AddRoutesForSessions(ssm)(g)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "/sessions/unknown/latency", nil)
+	assert.NoError(t, err)
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
 type sessionStorageMock struct {
 	sessionsToReturn   []session.History
 	statsToReturn      session.Stats
 	statsByDayToReturn map[time.Time]session.Stats
 	errToReturn        error
 
+	latencyToReturn  session.Latency
 	calledWithFilter *session.Filter
+	calledWithID     node_session.ID
 }
 
 func (ssm *sessionStorageMock) List(filter *session.Filter) ([]session.History, error) {
@@ -269,3 +309,8 @@ func (ssm *sessionStorageMock) StatsByDay(filter *session.Filter) (map[time.Time
 	ssm.calledWithFilter = filter
 	return ssm.statsByDayToReturn, ssm.errToReturn
 }
+
+func (ssm *sessionStorageMock) Latency(id node_session.ID) (session.Latency, error) {
+	ssm.calledWithID = id
+	return ssm.latencyToReturn, ssm.errToReturn
+}
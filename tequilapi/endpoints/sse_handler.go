@@ -29,10 +29,13 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/alerting"
+	"github.com/mysteriumnetwork/node/core/connection/autoconnect"
 	nodeEvent "github.com/mysteriumnetwork/node/core/node/event"
 	"github.com/mysteriumnetwork/node/core/state/event"
 	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
 	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/pilvytis"
 	"github.com/mysteriumnetwork/node/session/pingpong"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 )
@@ -53,6 +56,12 @@ const (
 	ServiceStatusEvent EventType = "service-status"
 	// StateChangeEvent represents the state change
 	StateChangeEvent EventType = "state-change"
+	// AutoTopupEvent represents the automatic top-up order event type
+	AutoTopupEvent EventType = "auto-topup"
+	// AlertEvent represents the alert fired or resolved event type
+	AlertEvent EventType = "alert"
+	// AutoConnectEvent represents the consumer auto-connect attempt event type
+	AutoConnectEvent EventType = "auto-connect"
 )
 
 // Handler represents an sse handler
@@ -90,7 +99,18 @@ func (h *Handler) Subscribe(bus eventbus.Subscriber) error {
 		return err
 	}
 	err = bus.Subscribe(stateEvent.AppTopicState, h.ConsumeStateEvent)
-	return err
+	if err != nil {
+		return err
+	}
+	err = bus.Subscribe(pilvytis.AppTopicAutoTopup, h.ConsumeAutoTopupEvent)
+	if err != nil {
+		return err
+	}
+	err = bus.Subscribe(alerting.AppTopicAlert, h.ConsumeAlertEvent)
+	if err != nil {
+		return err
+	}
+	return bus.Subscribe(autoconnect.AppTopicAutoConnect, h.ConsumeAutoConnectEvent)
 }
 
 // Sub subscribes a user to sse
@@ -218,6 +238,30 @@ func (h *Handler) ConsumeNodeEvent(e nodeEvent.Payload) {
 	}
 }
 
+// ConsumeAutoTopupEvent consumes the automatic top-up event
+func (h *Handler) ConsumeAutoTopupEvent(e pilvytis.AppEventAutoTopup) {
+	h.send(Event{
+		Type:    AutoTopupEvent,
+		Payload: e,
+	})
+}
+
+// ConsumeAlertEvent consumes the alert event
+func (h *Handler) ConsumeAlertEvent(e alerting.AppEventAlert) {
+	h.send(Event{
+		Type:    AlertEvent,
+		Payload: contract.NewAlertDTO(e.Alert),
+	})
+}
+
+// ConsumeAutoConnectEvent consumes the auto-connect attempt event
+func (h *Handler) ConsumeAutoConnectEvent(e autoconnect.AppEventAutoConnect) {
+	h.send(Event{
+		Type:    AutoConnectEvent,
+		Payload: contract.NewAutoConnectEventDTO(e),
+	})
+}
+
 type stateRes struct {
 	Services      []contract.ServiceInfoDTO    `json:"service_info"`
 	Sessions      []contract.SessionDTO        `json:"sessions"`
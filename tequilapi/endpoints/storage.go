@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package endpoints
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/core/storage/retention"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type storageMaintainer interface {
+	Trigger() error
+	Report() retention.Report
+}
+
+type storageEndpoint struct {
+	maintainer storageMaintainer
+}
+
+// NewStorageEndpoint creates and returns storage maintenance endpoint
+func NewStorageEndpoint(maintainer storageMaintainer) *storageEndpoint {
+	return &storageEndpoint{maintainer: maintainer}
+}
+
+// swagger:operation GET /storage/maintenance Storage getStorageMaintenance
+// ---
+// summary: Returns storage maintenance progress
+// description: Returns progress of the running storage maintenance or the report of the last one, with the number of pruned records and freed space.
+// responses:
+//   200:
+//     description: Storage maintenance report
+//     schema:
+//       "$ref": "#/definitions/StorageMaintenanceDTO"
+func (e *storageEndpoint) Report(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewStorageMaintenanceDTO(e.maintainer.Report()), c.Writer)
+}
+
+// swagger:operation POST /storage/maintenance Storage startStorageMaintenance
+// ---
+// summary: Starts storage maintenance
+// description: Removes session history, payment records and events past their retention periods and compacts the database in the background.
+// responses:
+//   202:
+//     description: Storage maintenance started
+//     schema:
+//       "$ref": "#/definitions/StorageMaintenanceDTO"
+//   409:
+//     description: Storage maintenance is already running
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (e *storageEndpoint) Start(c *gin.Context) {
+	if err := e.maintainer.Trigger(); errors.Is(err, retention.ErrRunning) {
+		c.Error(apierror.Error(http.StatusConflict, err.Error(), contract.ErrCodeStorageMaintenanceRunning))
+		return
+	}
+	utils.WriteAsJSON(contract.NewStorageMaintenanceDTO(e.maintainer.Report()), c.Writer, http.StatusAccepted)
+}
+
+// AddRoutesForStorage attaches storage maintenance endpoints to router
+func AddRoutesForStorage(maintainer storageMaintainer) func(*gin.Engine) error {
+	endpoint := NewStorageEndpoint(maintainer)
+	return func(e *gin.Engine) error {
+		g := e.Group("/storage")
+		{
+			g.GET("/maintenance", endpoint.Report)
+			g.POST("/maintenance", endpoint.Start)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/storage/retention"
+)
+
+type mockStorageMaintainer struct {
+	report   retention.Report
+	triggers int
+}
+
+func (m *mockStorageMaintainer) Trigger() error {
+	if m.report.Running {
+		return retention.ErrRunning
+	}
+	m.triggers++
+	m.report = retention.Report{Running: true, Steps: 2, Current: "sessions"}
+	return nil
+}
+
+func (m *mockStorageMaintainer) Report() retention.Report {
+	return m.report
+}
+
+func TestStorageEndpoint(t *testing.T) {
+	maintainer := &mockStorageMaintainer{report: retention.Report{
+		StartedAt:  time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC),
+		FinishedAt: time.Date(2022, 1, 1, 12, 0, 5, 0, time.UTC),
+		Step:       2,
+		Steps:      2,
+		Results:    []retention.Result{{Name: "sessions", Pruned: 3}},
+		SizeBefore: 1000,
+		SizeAfter:  400,
+	}}
+	router := summonTestGin()
+	assert.NoError(t, AddRoutesForStorage(maintainer)(router))
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/storage/maintenance", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"running": false,
+		"started_at": "2022-01-01T12:00:00Z",
+		"finished_at": "2022-01-01T12:00:05Z",
+		"step": 2,
+		"steps": 2,
+		"results": [{"name": "sessions", "pruned": 3}],
+		"size_before": 1000,
+		"size_after": 400,
+		"freed_bytes": 600
+	}`, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/storage/maintenance", nil))
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.JSONEq(t, `{"running": true, "step": 0, "steps": 2, "current": "sessions", "results": [], "size_before": 0, "size_after": 0, "freed_bytes": 0}`, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/storage/maintenance", nil))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, 1, maintainer.triggers)
+}
@@ -19,22 +19,29 @@ package endpoints
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mysteriumnetwork/go-rest/apierror"
 
-	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/tos"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
-	"github.com/rs/zerolog/log"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
 )
 
+type termsKeeper interface {
+	CurrentVersion() string
+	Status() tos.Status
+	Update(update tos.Update) error
+}
+
 type termsAPI struct {
-	config configProvider
+	keeper termsKeeper
 }
 
-func newTermsAPI(config configProvider) *termsAPI {
-	return &termsAPI{config: config}
+func newTermsAPI(keeper termsKeeper) *termsAPI {
+	return &termsAPI{keeper: keeper}
 }
 
 // GetTerms returns current terms config
@@ -42,14 +49,29 @@ func newTermsAPI(config configProvider) *termsAPI {
 // swagger:operation GET /terms Terms getTerms
 // ---
 // summary: Get terms
-// description: Return an object with the current terms config
+// description: Return an object with the current terms config and acceptance state
 // responses:
 //   200:
 //     description: Terms object
 //     schema:
 //       "$ref": "#/definitions/TermsResponse"
 func (api *termsAPI) GetTerms(c *gin.Context) {
-	c.JSON(http.StatusOK, contract.NewTermsResp())
+	c.JSON(http.StatusOK, contract.NewTermsResp(api.keeper.Status()))
+}
+
+// GetCurrentTerms returns documents of the current terms version
+//
+// swagger:operation GET /terms/current Terms getCurrentTerms
+// ---
+// summary: Get current terms documents
+// description: Returns the current terms version and its documents which must be accepted
+// responses:
+//   200:
+//     description: Terms documents
+//     schema:
+//       "$ref": "#/definitions/TermsDocumentsResponse"
+func (api *termsAPI) GetCurrentTerms(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewTermsDocumentsResponse(api.keeper.CurrentVersion(), tos.Documents), c.Writer)
 }
 
 // UpdateTerms accepts new terms and updates user config
@@ -57,7 +79,7 @@ func (api *termsAPI) GetTerms(c *gin.Context) {
 // swagger:operation POST /terms Terms updateTerms
 // ---
 // summary: Update terms agreement
-// description: Takes the given data and tries to update terms agreement config.
+// description: Takes the given data and tries to update terms agreement config, acceptance time is recorded.
 // parameters:
 // - in: body
 //   name: body
@@ -83,12 +105,11 @@ func (api *termsAPI) UpdateTerms(c *gin.Context) {
 		return
 	}
 
-	for k, v := range req.ToMap() {
-		log.Debug().Msgf("Setting user config value: %q = %q", k, v)
-		api.config.SetUser(k, v)
+	err = api.keeper.Update(req.ToUpdate())
+	if errors.Is(err, tos.ErrVersionMismatch) {
+		c.Error(apierror.BadRequestField(err.Error(), contract.ErrCodeTermsVersion, "agreed_version"))
+		return
 	}
-
-	err = api.config.SaveUserConfig()
 	if err != nil {
 		c.Error(apierror.Internal("Failed to save config", contract.ErrCodeConfigSave))
 		return
@@ -97,11 +118,14 @@ func (api *termsAPI) UpdateTerms(c *gin.Context) {
 }
 
 // AddRoutesForTerms registers /terms endpoints in Tequilapi
-func AddRoutesForTerms(e *gin.Engine) error {
-	api := newTermsAPI(config.Current)
+func AddRoutesForTerms(keeper termsKeeper) func(*gin.Engine) error {
+	api := newTermsAPI(keeper)
 
-	g := e.Group("/terms")
-	g.GET("", api.GetTerms)
-	g.POST("", api.UpdateTerms)
-	return nil
+	return func(e *gin.Engine) error {
+		g := e.Group("/terms")
+		g.GET("", api.GetTerms)
+		g.GET("/current", api.GetCurrentTerms)
+		g.POST("", api.UpdateTerms)
+		return nil
+	}
 }
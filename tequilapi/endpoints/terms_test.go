@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/tos"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+type mockTermsKeeper struct {
+	status tos.Status
+	update tos.Update
+}
+
+func (m *mockTermsKeeper) CurrentVersion() string { return m.status.CurrentVersion }
+func (m *mockTermsKeeper) Status() tos.Status     { return m.status }
+func (m *mockTermsKeeper) Update(update tos.Update) error {
+	if update.Version != "" && update.Version != m.status.CurrentVersion {
+		return tos.ErrVersionMismatch
+	}
+	m.update = update
+	return nil
+}
+
+func newTermsRouter(t *testing.T, keeper termsKeeper) *gin.Engine {
+	router := gin.Default()
+	router.Use(apierror.ErrorHandler)
+	require.NoError(t, AddRoutesForTerms(keeper)(router))
+	return router
+}
+
+func Test_GetTerms(t *testing.T) {
+	router := newTermsRouter(t, &mockTermsKeeper{status: tos.Status{
+		CurrentVersion: "0.0.40",
+		Provider:       tos.Acceptance{Agreed: true, Version: "0.0.40", AcceptedAt: time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)},
+		Consumer:       tos.Acceptance{Version: "0.0.39"},
+	}})
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/terms", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"agreed_provider": true,
+		"agreed_consumer": false,
+		"agreed_version": "0.0.40",
+		"provider_agreed_version": "0.0.40",
+		"consumer_agreed_version": "0.0.39",
+		"current_version": "0.0.40",
+		"provider_agreed_at": "2022-03-01T12:00:00Z",
+		"provider_accepted": true,
+		"consumer_accepted": false
+	}`, resp.Body.String())
+}
+
+func Test_GetCurrentTerms(t *testing.T) {
+	router := newTermsRouter(t, &mockTermsKeeper{status: tos.Status{CurrentVersion: "0.0.40"}})
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/terms/current", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var documents contract.TermsDocumentsResponse
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &documents))
+	assert.Equal(t, "0.0.40", documents.Version)
+	assert.NotEmpty(t, documents.Documents["exit_node"])
+}
+
+func Test_UpdateTerms(t *testing.T) {
+	keeper := &mockTermsKeeper{status: tos.Status{CurrentVersion: "0.0.40"}}
+	router := newTermsRouter(t, keeper)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/terms", strings.NewReader(`{"agreed_provider": true, "agreed_version": "0.0.40"}`)))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	require.NotNil(t, keeper.update.Provider)
+	assert.True(t, *keeper.update.Provider)
+	assert.Nil(t, keeper.update.Consumer)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/terms", strings.NewReader(`{"agreed_provider": true, "agreed_version": "0.0.1"}`)))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	apiErr := apierror.Parse(resp.Result())
+	assert.Equal(t, contract.ErrCodeTermsVersion, apiErr.Err.Fields["agreed_version"].Code)
+}
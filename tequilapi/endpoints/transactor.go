@@ -38,6 +38,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/beneficiary"
+	"github.com/mysteriumnetwork/node/core/gasprice"
 	"github.com/mysteriumnetwork/node/core/payout"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
@@ -59,6 +60,7 @@ type Transactor interface {
 	GetFreeProviderRegistrationEligibility() (bool, error)
 	OpenChannel(chainID int64, id, hermesID, registryAddress string) error
 	ChannelStatus(chainID int64, id, hermesID, registryAddress string) (registry.ChannelStatusResponse, error)
+	QueuedJobs() ([]registry.TransactorJob, error)
 }
 
 // promiseSettler settles the given promises
@@ -99,6 +101,11 @@ type transactorEndpoint struct {
 	bprovider                 beneficiaryProvider
 	bhandler                  beneficiarySaver
 	pilvytis                  pilvytisApi
+	gasPrice                  gasPriceQuoter
+}
+
+type gasPriceQuoter interface {
+	Quote(chainID int64) (gasprice.Quote, error)
 }
 
 // NewTransactorEndpoint creates and returns transactor endpoint
@@ -111,6 +118,7 @@ func NewTransactorEndpoint(
 	bprovider beneficiaryProvider,
 	bhandler beneficiarySaver,
 	pilvytis pilvytisApi,
+	gasPrice gasPriceQuoter,
 ) *transactorEndpoint {
 	return &transactorEndpoint{
 		transactor:                transactor,
@@ -121,6 +129,7 @@ func NewTransactorEndpoint(
 		bprovider:                 bprovider,
 		bhandler:                  bhandler,
 		pilvytis:                  pilvytis,
+		gasPrice:                  gasPrice,
 	}
 }
 
@@ -168,6 +177,7 @@ func (te *transactorEndpoint) TransactorFeesV2(c *gin.Context) {
 		ServerTime: fees.ServerTime,
 
 		HermesPercent: hermesPercent.StringFixed(4),
+		GasPrice:      te.gasPriceQuote(chainID),
 	}
 
 	utils.WriteAsJSON(f, c.Writer)
@@ -231,11 +241,26 @@ func (te *transactorEndpoint) TransactorFees(c *gin.Context) {
 		Hermes:              hermesFeePerMyriad,
 		DecreaseStake:       decreaseStakeFees.Fee,
 		DecreaseStakeTokens: contract.NewTokens(decreaseStakeFees.Fee),
+		GasPrice:            te.gasPriceQuote(chainID),
 	}
 
 	utils.WriteAsJSON(f, c.Writer)
 }
 
+func (te *transactorEndpoint) gasPriceQuote(chainID int64) *contract.GasPriceDTO {
+	if te.gasPrice == nil {
+		return nil
+	}
+
+	quote, err := te.gasPrice.Quote(chainID)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Could not quote gas price for chain %d", chainID)
+		return nil
+	}
+
+	return contract.NewGasPriceDTO(quote)
+}
+
 // swagger:operation POST /transactor/settle/sync SettleSync
 // ---
 // summary: Forces the settlement of promises for the given provider and hermes
@@ -378,6 +403,11 @@ func (te *transactorEndpoint) RegisterIdentity(c *gin.Context) {
 		c.Error(apierror.ParseFailed())
 		return
 	}
+	if req.ReferralToken == nil {
+		if token := config.ReferralToken(""); token != "" {
+			req.ReferralToken = &token
+		}
+	}
 
 	registrationStatus, err := te.identityRegistry.GetRegistrationStatus(chainID, id)
 	if err != nil {
@@ -411,6 +441,11 @@ func (te *transactorEndpoint) RegisterIdentity(c *gin.Context) {
 	}
 
 	err = te.transactor.RegisterIdentity(id.Address, big.NewInt(0), regFee, req.Beneficiary, chainID, req.ReferralToken)
+	if errors.Is(err, registry.ErrTransactorJobInProgress) {
+		log.Info().Msgf("Identity registration for ID: %s is already queued", id.Address)
+		c.Status(http.StatusAccepted)
+		return
+	}
 	if err != nil {
 		log.Err(err).Msgf("Failed identity registration request for ID: %s, %+v", id.Address, req)
 		utils.ForwardError(c, err, apierror.Internal("Failed to register identity", contract.ErrCodeTransactorRegistration))
@@ -730,6 +765,29 @@ func (te *transactorEndpoint) ChainSummary(c *gin.Context) {
 	})
 }
 
+// swagger:operation GET /transactor/queue TransactorQueue
+// ---
+// summary: Returns queued transactor requests
+// description: Returns registration and settlement requests sent to transactor, newest first. Pending requests are retried in the background.
+// responses:
+//   200:
+//     description: Transactor queue
+//     schema:
+//       "$ref": "#/definitions/TransactorQueueResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (te *transactorEndpoint) Queue(c *gin.Context) {
+	jobs, err := te.transactor.QueuedJobs()
+	if err != nil {
+		c.Error(apierror.Internal("Could not get transactor queue: "+err.Error(), contract.ErrCodeTransactorQueue))
+		return
+	}
+
+	c.JSON(http.StatusOK, contract.NewTransactorQueueResponse(jobs))
+}
+
 // EligibilityResponse represents the eligibility response
 // swagger:model EligibilityResponse
 type EligibilityResponse struct {
@@ -766,6 +824,113 @@ func (te *transactorEndpoint) FreeRegistrationEligibility(c *gin.Context) {
 	c.JSON(http.StatusOK, EligibilityResponse{Eligible: res})
 }
 
+// swagger:operation GET /identities/{id}/registration-fees Identity RegistrationFees
+// ---
+// summary: Provides registration cost quotes
+// description: Returns current registration and settlement fee quotes on every configured chain, together with hermes fees, so the cost can be shown before registering. Quotes must be re-requested after they expire.
+// parameters:
+// - name: id
+//   in: path
+//   description: Identity address to register
+//   type: string
+//   required: true
+// - name: chain_id
+//   in: query
+//   description: Limit quotes to a single chain
+//   type: integer
+// responses:
+//   200:
+//     description: Registration fee quotes
+//     schema:
+//       "$ref": "#/definitions/RegistrationFeesResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (te *transactorEndpoint) RegistrationFees(c *gin.Context) {
+	id := identity.FromAddress(c.Param("id"))
+
+	chainIDs := []int64{config.GetInt64(config.FlagChain1ChainID), config.GetInt64(config.FlagChain2ChainID)}
+	if qcid, err := cast.ToInt64E(c.Query("chain_id")); err == nil {
+		chainIDs = []int64{qcid}
+	}
+
+	resp := contract.RegistrationFeesResponse{Identity: id.Address}
+	failed := 0
+	for i, chainID := range chainIDs {
+		if i > 0 && chainID == chainIDs[0] {
+			continue
+		}
+		fees := te.chainRegistrationFees(chainID)
+		if fees.Error != "" {
+			failed++
+		}
+		resp.Chains = append(resp.Chains, fees)
+	}
+	if failed == len(resp.Chains) {
+		c.Error(apierror.Internal("Failed to fetch registration fees: "+resp.Chains[0].Error, contract.ErrCodeTransactorFetchFees))
+		return
+	}
+
+	eligible, err := te.transactor.GetFreeRegistrationEligibility(id)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Could not check free registration eligibility of %s", id.Address)
+	}
+	resp.FreeRegistration = eligible
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (te *transactorEndpoint) chainRegistrationFees(chainID int64) contract.ChainRegistrationFees {
+	result := contract.ChainRegistrationFees{
+		ChainID:   chainID,
+		ChainName: registry.Chains()[chainID],
+	}
+
+	registrationFees, err := te.transactor.FetchRegistrationFees(chainID)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	settlementFees, err := te.transactor.FetchSettleFees(chainID)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Registration = contract.NewTokens(registrationFees.Fee)
+	result.Settlement = contract.NewTokens(settlementFees.Fee)
+	result.ValidUntil = registrationFees.ValidUntil
+	if settlementFees.ValidUntil.Before(result.ValidUntil) {
+		result.ValidUntil = settlementFees.ValidUntil
+	}
+	result.GasPrice = te.gasPriceQuote(chainID)
+
+	active, err := te.addressProvider.GetActiveHermes(chainID)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	hermeses, err := te.addressProvider.GetKnownHermeses(chainID)
+	if err != nil || len(hermeses) == 0 {
+		hermeses = []common.Address{active}
+	}
+	for _, hermesID := range hermeses {
+		feePerMyriad, err := te.promiseSettler.GetHermesFee(chainID, hermesID)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Could not get fee of hermes %s on chain %d", hermesID.Hex(), chainID)
+			continue
+		}
+		result.Hermeses = append(result.Hermeses, contract.HermesFee{
+			HermesID:      hermesID.Hex(),
+			Active:        hermesID == active,
+			HermesPercent: decimal.NewFromInt(int64(feePerMyriad)).Div(decimal.NewFromInt(10000)).StringFixed(4),
+		})
+	}
+
+	return result
+}
+
 // swagger:operation GET /identities/provider/eligibility ProviderEligibility
 // ---
 // summary: Checks if provider is eligible for free registration
@@ -903,8 +1068,9 @@ func AddRoutesForTransactor(
 	bprovider beneficiaryProvider,
 	bhandler beneficiarySaver,
 	pilvytis pilvytisApi,
+	gasPrice gasPriceQuoter,
 ) func(*gin.Engine) error {
-	te := NewTransactorEndpoint(transactor, identityRegistry, promiseSettler, settlementHistoryProvider, addressProvider, bprovider, bhandler, pilvytis)
+	te := NewTransactorEndpoint(transactor, identityRegistry, promiseSettler, settlementHistoryProvider, addressProvider, bprovider, bhandler, pilvytis, gasPrice)
 	a := NewAffiliatorEndpoint(affiliator)
 
 	return func(e *gin.Engine) error {
@@ -913,6 +1079,7 @@ func AddRoutesForTransactor(
 			idGroup.POST("/:id/register", te.RegisterIdentity)
 			idGroup.GET("/provider/eligibility", te.FreeProviderRegistrationEligibility)
 			idGroup.GET("/:id/eligibility", te.FreeRegistrationEligibility)
+			idGroup.GET("/:id/registration-fees", te.RegistrationFees)
 			idGroup.GET("/:id/beneficiary-status", te.BeneficiaryTxStatus)
 			idGroup.POST("/:id/beneficiary", te.SettleWithBeneficiaryAsync)
 		}
@@ -929,6 +1096,7 @@ func AddRoutesForTransactor(
 			transGroup.POST("/settle/withdraw", te.Withdraw)
 			transGroup.GET("/token/:token/reward", a.TokenRewardAmount)
 			transGroup.GET("/chain-summary", te.ChainSummary)
+			transGroup.GET("/queue", te.Queue)
 		}
 		transGroupV2 := e.Group("/v2/transactor")
 		{
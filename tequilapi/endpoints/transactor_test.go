@@ -31,6 +31,8 @@ import (
 	"github.com/mysteriumnetwork/go-rest/apierror"
 
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/gasprice"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 
@@ -57,9 +59,9 @@ func Test_RegisterIdentity(t *testing.T) {
 
 	router := summonTestGin()
 
-	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute)
+	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
 	a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
-	err := AddRoutesForTransactor(&registry.FakeRegistry{RegistrationStatus: registry.Unregistered}, tr, a, nil, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, &mockPilvytis{})(router)
+	err := AddRoutesForTransactor(&registry.FakeRegistry{RegistrationStatus: registry.Unregistered}, tr, a, nil, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, &mockPilvytis{}, nil)(router)
 	assert.NoError(t, err)
 
 	req, err := http.NewRequest(
@@ -76,17 +78,99 @@ func Test_RegisterIdentity(t *testing.T) {
 	assert.Equal(t, "", resp.Body.String())
 }
 
+func Test_RegisterIdentity_QueuesOnTransientFailure(t *testing.T) {
+	server := newTestTransactorServer(http.StatusServiceUnavailable, `{}`)
+
+	router := summonTestGin()
+
+	bolt, err := boltdb.NewStorage(t.TempDir())
+	assert.NoError(t, err)
+	defer bolt.Close()
+	queue := registry.NewTransactorQueue(bolt, nil, registry.DefaultTransactorQueueConfig())
+
+	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, queue)
+	a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
+	err = AddRoutesForTransactor(&registry.FakeRegistry{RegistrationStatus: registry.Unregistered}, tr, a, nil, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, &mockPilvytis{}, nil)(router)
+	assert.NoError(t, err)
+
+	register := func() *httptest.ResponseRecorder {
+		req, err := http.NewRequest(
+			http.MethodPost,
+			"/identities/0x0000000000000000000000000000000000000000/register",
+			bytes.NewBufferString(identityRegData),
+		)
+		assert.Nil(t, err)
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// accepted and retried in the background
+	resp := register()
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	// repeated requests are not sent again
+	resp = register()
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	req, err := http.NewRequest(http.MethodGet, "/transactor/queue", nil)
+	assert.Nil(t, err)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var queueRes contract.TransactorQueueResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &queueRes))
+	assert.Len(t, queueRes.Jobs, 1)
+	assert.Equal(t, "registration", queueRes.Jobs[0].Kind)
+	assert.Equal(t, "pending", queueRes.Jobs[0].State)
+	assert.Equal(t, 1, queueRes.Jobs[0].Attempts)
+}
+
+func Test_RegisterIdentity_ConfiguredReferralToken(t *testing.T) {
+	config.Current.SetDefault(config.FlagReferralToken.Name, "partner-token")
+	defer config.Current.SetDefault(config.FlagReferralToken.Name, "")
+
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	router := summonTestGin()
+
+	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
+	a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
+	err := AddRoutesForTransactor(&registry.FakeRegistry{RegistrationStatus: registry.Unregistered}, tr, a, nil, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, &mockPilvytis{}, nil)(router)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(
+		http.MethodPost,
+		"/identities/0x0000000000000000000000000000000000000000/register",
+		bytes.NewBufferString(`{}`),
+	)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, []string{"/identity/register/referer"}, paths)
+}
+
 func Test_Get_TransactorFees(t *testing.T) {
 	mockResponse := `{ "fee": 1000000000000000000 }`
 	server := newTestTransactorServer(http.StatusOK, mockResponse)
 
 	router := summonTestGin()
 
-	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute)
+	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
 	a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
 	err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, &mockSettler{
 		feeToReturn: 11_000,
-	}, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, nil)(router)
+	}, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, nil, nil)(router)
 	assert.NoError(t, err)
 
 	req, err := http.NewRequest(
@@ -127,17 +211,129 @@ func Test_Get_TransactorFees(t *testing.T) {
 		resp.Body.String())
 }
 
+func Test_Get_TransactorFees_IncludesGasPrice(t *testing.T) {
+	mockResponse := `{ "fee": 1000000000000000000 }`
+	server := newTestTransactorServer(http.StatusOK, mockResponse)
+
+	router := summonTestGin()
+
+	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
+	a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
+	quoter := &mockGasPriceQuoter{quote: gasprice.Quote{
+		Price:    big.NewInt(40_000_000_000),
+		Strategy: gasprice.StrategyFast,
+		Source:   gasprice.SourceOracle,
+		MaxPrice: big.NewInt(30_000_000_000),
+	}}
+	err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, &mockSettler{}, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, nil, quoter)(router)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "/transactor/fees", nil)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var fees contract.FeesDTO
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &fees))
+	assert.Equal(t, &contract.GasPriceDTO{
+		Price:    big.NewInt(40_000_000_000),
+		Strategy: "fast",
+		Source:   "oracle",
+		MaxPrice: big.NewInt(30_000_000_000),
+		AboveCap: true,
+	}, fees.GasPrice)
+
+	// quote failures do not fail the fees request
+	quoter.quote, quoter.err = gasprice.Quote{}, errors.New("oracle down")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotContains(t, resp.Body.String(), "gas_price")
+}
+
+func Test_Get_RegistrationFees(t *testing.T) {
+	config.Current.SetUser(config.FlagChain1ChainID.Name, 5)
+	config.Current.SetUser(config.FlagChain2ChainID.Name, 80001)
+	defer config.Current.RemoveUser(config.FlagChain1ChainID.Name)
+	defer config.Current.RemoveUser(config.FlagChain2ChainID.Name)
+
+	mockResponse := `{ "fee": 1000000000000000000, "valid_until": "2030-01-01T00:00:00Z" }`
+	server := newTestTransactorServer(http.StatusOK, mockResponse)
+
+	router := summonTestGin()
+
+	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
+	a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
+	hermesID := common.HexToAddress("0x1")
+	err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, &mockSettler{
+		feeToReturn: 2_000,
+	}, &settlementHistoryProviderMock{}, &mockAddressProvider{hermesToReturn: hermesID}, nil, nil, nil, nil)(router)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "/identities/0x0000000000000000000000000000000000000002/registration-fees", nil)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var fees contract.RegistrationFeesResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &fees))
+	assert.Equal(t, "0x0000000000000000000000000000000000000002", fees.Identity)
+	assert.Len(t, fees.Chains, 2)
+	assert.Equal(t, int64(5), fees.Chains[0].ChainID)
+	assert.Equal(t, int64(80001), fees.Chains[1].ChainID)
+	assert.Equal(t, "Polygon Testnet Mumbai", fees.Chains[1].ChainName)
+	assert.Equal(t, "1", fees.Chains[1].Registration.Human)
+	assert.Equal(t, "1", fees.Chains[1].Settlement.Human)
+	assert.Equal(t, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), fees.Chains[1].ValidUntil.UTC())
+	assert.Equal(t, []contract.HermesFee{{HermesID: hermesID.Hex(), Active: true, HermesPercent: "0.2000"}}, fees.Chains[1].Hermeses)
+	assert.Empty(t, fees.Chains[1].Error)
+
+	req, err = http.NewRequest(http.MethodGet, "/identities/0x0000000000000000000000000000000000000002/registration-fees?chain_id=5", nil)
+	assert.Nil(t, err)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &fees))
+	assert.Len(t, fees.Chains, 1)
+}
+
+func Test_Get_RegistrationFees_AllChainsFail(t *testing.T) {
+	server := newTestTransactorServer(http.StatusInternalServerError, `{}`)
+
+	router := summonTestGin()
+
+	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
+	a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
+	err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, &mockSettler{}, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, nil, nil)(router)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "/identities/0x0000000000000000000000000000000000000002/registration-fees?chain_id=5", nil)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
+
 func Test_SettleAsync_OK(t *testing.T) {
 	mockResponse := ""
 	server := newTestTransactorServer(http.StatusAccepted, mockResponse)
 
 	router := summonTestGin()
 
-	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute)
+	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
 	a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
 	err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, &mockSettler{}, &settlementHistoryProviderMock{}, &mockAddressProvider{}, &mockBeneficiaryProvider{
 		b: common.HexToAddress("0x0000000000000000000000000000000000000001"),
-	}, nil, nil)(router)
+	}, nil, nil, nil)(router)
 	assert.NoError(t, err)
 
 	settleRequest := `{"hermes_id": "0xbe180c8CA53F280C7BE8669596fF7939d933AA10", "provider_id": "0xbe180c8CA53F280C7BE8669596fF7939d933AA10"}`
@@ -161,9 +357,9 @@ func Test_SettleAsync_ReturnsError(t *testing.T) {
 
 	router := summonTestGin()
 
-	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute)
+	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
 	a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
-	err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, &mockSettler{errToReturn: errors.New("explosions everywhere")}, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, nil)(router)
+	err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, &mockSettler{errToReturn: errors.New("explosions everywhere")}, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, nil, nil)(router)
 	assert.NoError(t, err)
 
 	settleRequest := `asdasdasd`
@@ -187,9 +383,9 @@ func Test_SettleSync_OK(t *testing.T) {
 
 	router := summonTestGin()
 
-	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute)
+	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
 	a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
-	err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, &mockSettler{}, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, nil)(router)
+	err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, &mockSettler{}, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, nil, nil)(router)
 	assert.NoError(t, err)
 
 	settleRequest := `{"hermes_id": "0xbe180c8CA53F280C7BE8669596fF7939d933AA10", "provider_id": "0xbe180c8CA53F280C7BE8669596fF7939d933AA10"}`
@@ -213,9 +409,9 @@ func Test_SettleSync_ReturnsError(t *testing.T) {
 
 	router := summonTestGin()
 
-	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute)
+	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
 	a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
-	err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, &mockSettler{errToReturn: errors.New("explosions everywhere")}, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, nil)(router)
+	err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, &mockSettler{errToReturn: errors.New("explosions everywhere")}, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, nil, nil)(router)
 	assert.NoError(t, err)
 
 	settleRequest := `{"hermes_id": "0xbe180c8CA53F280C7BE8669596fF7939d933AA10", "provider_id": "0xbe180c8CA53F280C7BE8669596fF7939d933AA10"}`
@@ -240,9 +436,9 @@ func Test_SettleHistory(t *testing.T) {
 		defer server.Close()
 
 		router := summonTestGin()
-		tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute)
+		tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
 		a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
-		err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, nil, &settlementHistoryProviderMock{errToReturn: errors.New("explosions everywhere")}, &mockAddressProvider{}, nil, nil, nil)(router)
+		err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, nil, &settlementHistoryProviderMock{errToReturn: errors.New("explosions everywhere")}, &mockAddressProvider{}, nil, nil, nil, nil)(router)
 		assert.NoError(t, err)
 
 		req, err := http.NewRequest(http.MethodGet, "/transactor/settle/history", nil)
@@ -277,9 +473,9 @@ func Test_SettleHistory(t *testing.T) {
 		defer server.Close()
 
 		router := summonTestGin()
-		tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute)
+		tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
 		a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
-		err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, nil, mockStorage, &mockAddressProvider{}, nil, nil, nil)(router)
+		err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, nil, mockStorage, &mockAddressProvider{}, nil, nil, nil, nil)(router)
 		assert.NoError(t, err)
 
 		req, err := http.NewRequest(http.MethodGet, "/transactor/settle/history", nil)
@@ -335,9 +531,9 @@ func Test_SettleHistory(t *testing.T) {
 		server := newTestTransactorServer(http.StatusAccepted, "")
 		defer server.Close()
 		router := summonTestGin()
-		tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute)
+		tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
 		a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
-		err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, nil, mockStorage, &mockAddressProvider{}, nil, nil, nil)(router)
+		err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, nil, mockStorage, &mockAddressProvider{}, nil, nil, nil, nil)(router)
 		assert.NoError(t, err)
 
 		req, err := http.NewRequest(
@@ -372,7 +568,7 @@ func Test_SettleHistory(t *testing.T) {
 func Test_AvailableChains(t *testing.T) {
 	// given
 	router := summonTestGin()
-	err := AddRoutesForTransactor(nil, nil, nil, nil, nil, nil, nil, nil, nil)(router)
+	err := AddRoutesForTransactor(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)(router)
 	assert.NoError(t, err)
 	config.Current.SetUser(config.FlagChainID.Name, config.FlagChainID.Value)
 
@@ -401,7 +597,7 @@ func Test_Withdrawal(t *testing.T) {
 	settler := &mockSettler{
 		feeToReturn: 11,
 	}
-	err := AddRoutesForTransactor(nil, nil, nil, settler, nil, nil, nil, nil, nil)(router)
+	err := AddRoutesForTransactor(nil, nil, nil, settler, nil, nil, nil, nil, nil, nil)(router)
 	assert.NoError(t, err)
 
 	config.Current.SetUser(config.FlagChainID.Name, config.FlagChainID.Value)
@@ -544,3 +740,12 @@ func (shpm *settlementHistoryProviderMock) List(filter pingpong.SettlementHistor
 	shpm.calledWithFilter = &filter
 	return shpm.settlementHistoryToReturn, shpm.errToReturn
 }
+
+type mockGasPriceQuoter struct {
+	quote gasprice.Quote
+	err   error
+}
+
+func (m *mockGasPriceQuoter) Quote(_ int64) (gasprice.Quote, error) {
+	return m.quote, m.err
+}
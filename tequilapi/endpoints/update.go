@@ -0,0 +1,175 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/pkg/errors"
+
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/updater"
+)
+
+type nodeUpdater interface {
+	Status() updater.Status
+	Check(channel string) (updater.Release, bool, error)
+	Update(channel string) (updater.Release, error)
+}
+
+type updateEndpoint struct {
+	updater nodeUpdater
+}
+
+// NewUpdateEndpoint creates and returns node update endpoint
+func NewUpdateEndpoint(updater nodeUpdater) *updateEndpoint {
+	return &updateEndpoint{updater: updater}
+}
+
+// swagger:operation GET /node/update Node nodeUpdateStatus
+// ---
+// summary: Returns node update status
+// description: Checks the release channel for a newer signed release and returns the current update status
+// parameters:
+//   - in: query
+//     name: channel
+//     description: Release channel to check, configured channel is used if empty
+//     type: string
+// responses:
+//   200:
+//     description: Node update status
+//     schema:
+//       "$ref": "#/definitions/NodeUpdateStatusDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   503:
+//     description: Updates are disabled
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (e *updateEndpoint) Status(c *gin.Context) {
+	_, available, err := e.updater.Check(c.Query("channel"))
+	if err != nil {
+		c.Error(updateError(err))
+		return
+	}
+
+	utils.WriteAsJSON(toUpdateStatus(e.updater.Status(), available), c.Writer)
+}
+
+// swagger:operation POST /node/update Node nodeUpdate
+// ---
+// summary: Updates node
+// description: Downloads and verifies the latest release of the channel, waits for active sessions to end, swaps the binary and restarts the node
+// parameters:
+//   - in: body
+//     name: body
+//     description: Node update request
+//     schema:
+//       $ref: "#/definitions/NodeUpdateRequestDTO"
+// responses:
+//   202:
+//     description: Update started
+//     schema:
+//       "$ref": "#/definitions/NodeUpdateStatusDTO"
+//   200:
+//     description: Node is up to date
+//     schema:
+//       "$ref": "#/definitions/NodeUpdateStatusDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   409:
+//     description: Update is already in progress
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   503:
+//     description: Updates are disabled
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (e *updateEndpoint) Update(c *gin.Context) {
+	var req contract.NodeUpdateRequest
+	if c.Request.ContentLength > 0 {
+		if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+			c.Error(apierror.ParseFailed())
+			return
+		}
+	}
+	if req.Channel != "" && !updater.ValidChannel(req.Channel) {
+		c.Error(apierror.BadRequestField("Unknown release channel", apierror.ValidateErrInvalidVal, "channel"))
+		return
+	}
+
+	release, err := e.updater.Update(req.Channel)
+	if err != nil {
+		c.Error(updateError(err))
+		return
+	}
+
+	status := e.updater.Status()
+	if status.State == updater.StateIdle {
+		utils.WriteAsJSON(toUpdateStatus(status, false), c.Writer)
+		return
+	}
+
+	status.LatestVersion = release.Version
+	utils.WriteAsJSON(toUpdateStatus(status, true), c.Writer, http.StatusAccepted)
+}
+
+func updateError(err error) error {
+	switch {
+	case errors.Is(err, updater.ErrDisabled):
+		return apierror.Error(http.StatusServiceUnavailable, "Updates are disabled", contract.ErrCodeUpdateDisabled)
+	case errors.Is(err, updater.ErrInProgress):
+		return apierror.Error(http.StatusConflict, "Update is already in progress", contract.ErrCodeUpdateInProgress)
+	default:
+		return apierror.Internal("Could not check for updates: "+err.Error(), contract.ErrCodeUpdateCheck)
+	}
+}
+
+func toUpdateStatus(status updater.Status, available bool) contract.NodeUpdateStatus {
+	return contract.NodeUpdateStatus{
+		State:           string(status.State),
+		Channel:         status.Channel,
+		CurrentVersion:  status.CurrentVersion,
+		LatestVersion:   status.LatestVersion,
+		UpdateAvailable: available,
+		Error:           status.Error,
+	}
+}
+
+// AddRoutesForUpdate attaches node update endpoints to router
+func AddRoutesForUpdate(updater nodeUpdater) func(*gin.Engine) error {
+	endpoint := NewUpdateEndpoint(updater)
+	return func(e *gin.Engine) error {
+		g := e.Group("/node")
+		g.GET("/update", endpoint.Status)
+		g.POST("/update", endpoint.Update)
+		return nil
+	}
+}
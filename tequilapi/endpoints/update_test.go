@@ -0,0 +1,132 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/updater"
+)
+
+type mockNodeUpdater struct {
+	err     error
+	release updater.Release
+	newer   bool
+	channel string
+}
+
+func (m *mockNodeUpdater) Status() updater.Status {
+	state := updater.StateIdle
+	if m.channel != "" && m.newer {
+		state = updater.StateDownloading
+	}
+	return updater.Status{State: state, Channel: "stable", CurrentVersion: "1.0.0", LatestVersion: m.release.Version}
+}
+
+func (m *mockNodeUpdater) Check(channel string) (updater.Release, bool, error) {
+	return m.release, m.newer, m.err
+}
+
+func (m *mockNodeUpdater) Update(channel string) (updater.Release, error) {
+	m.channel = channel
+	if m.channel == "" {
+		m.channel = "stable"
+	}
+	return m.release, m.err
+}
+
+func TestUpdateEndpoint_Status(t *testing.T) {
+	router := summonTestGin()
+	err := AddRoutesForUpdate(&mockNodeUpdater{release: updater.Release{Version: "1.1.0"}, newer: true})(router)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/node/update", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"state": "idle",
+		"channel": "stable",
+		"current_version": "1.0.0",
+		"latest_version": "1.1.0",
+		"update_available": true
+	}`, resp.Body.String())
+}
+
+func TestUpdateEndpoint_Update(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		updater     *mockNodeUpdater
+		wantStatus  int
+		wantChannel string
+	}{
+		{
+			name:        "update started",
+			body:        `{"channel": "beta"}`,
+			updater:     &mockNodeUpdater{release: updater.Release{Version: "1.1.0"}, newer: true},
+			wantStatus:  http.StatusAccepted,
+			wantChannel: "beta",
+		},
+		{
+			name:        "up to date",
+			updater:     &mockNodeUpdater{release: updater.Release{Version: "1.0.0"}},
+			wantStatus:  http.StatusOK,
+			wantChannel: "stable",
+		},
+		{
+			name:       "unknown channel",
+			body:       `{"channel": "nightly"}`,
+			updater:    &mockNodeUpdater{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "disabled",
+			updater:     &mockNodeUpdater{err: updater.ErrDisabled},
+			wantStatus:  http.StatusServiceUnavailable,
+			wantChannel: "stable",
+		},
+		{
+			name:        "in progress",
+			updater:     &mockNodeUpdater{err: updater.ErrInProgress},
+			wantStatus:  http.StatusConflict,
+			wantChannel: "stable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := summonTestGin()
+			err := AddRoutesForUpdate(tt.updater)(router)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/node/update", strings.NewReader(tt.body))
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			assert.Equal(t, tt.wantChannel, tt.updater.channel)
+		})
+	}
+}
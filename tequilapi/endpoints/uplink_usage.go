@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type uplinkUsageProvider interface {
+	Usage() []service.UplinkUsage
+}
+
+type uplinkUsageEndpoint struct {
+	provider uplinkUsageProvider
+}
+
+// NewUplinkUsageEndpoint creates and returns uplink usage endpoint
+func NewUplinkUsageEndpoint(provider uplinkUsageProvider) *uplinkUsageEndpoint {
+	return &uplinkUsageEndpoint{provider: provider}
+}
+
+// swagger:operation GET /node/provider/uplinks provider GetProviderUplinkUsage
+// ---
+// summary: Provides provider traffic per network interface
+// description: Attributes bytes transferred by provider sessions to the network interface (uplink) they were served through.
+// responses:
+//   200:
+//     description: Traffic totals per network interface
+//     schema:
+//       "$ref": "#/definitions/UplinkUsageResponse"
+func (e *uplinkUsageEndpoint) Usage(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewUplinkUsageResponse(e.provider.Usage()), c.Writer)
+}
+
+// AddRoutesForUplinkUsage attaches uplink usage endpoints to router
+func AddRoutesForUplinkUsage(provider uplinkUsageProvider) func(*gin.Engine) error {
+	endpoint := NewUplinkUsageEndpoint(provider)
+	return func(e *gin.Engine) error {
+		e.GET("/node/provider/uplinks", endpoint.Usage)
+		return nil
+	}
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/service"
+)
+
+type mockUplinkUsageProvider struct {
+	usage []service.UplinkUsage
+}
+
+func (m *mockUplinkUsageProvider) Usage() []service.UplinkUsage {
+	return m.usage
+}
+
+func TestUplinkUsageEndpoint_Usage(t *testing.T) {
+	provider := &mockUplinkUsageProvider{usage: []service.UplinkUsage{
+		{Interface: "eth0", Up: 100, Down: 50, Sessions: 2, UpdatedAt: time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{Interface: "wwan0", Up: 10, Down: 5, Sessions: 1},
+	}}
+	router := summonTestGin()
+	err := AddRoutesForUplinkUsage(provider)(router)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/node/provider/uplinks", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"interfaces": [
+			{"interface": "eth0", "up": 100, "down": 50, "sessions": 2, "updated_at": "2022-01-01T12:00:00Z"},
+			{"interface": "wwan0", "up": 10, "down": 5, "sessions": 1}
+		]
+	}`, resp.Body.String())
+}
@@ -24,7 +24,6 @@ import (
 	"time"
 
 	"github.com/gin-contrib/cors"
-	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/tequilapi/middlewares"
 
 	"github.com/mysteriumnetwork/node/core/node"
@@ -69,7 +68,7 @@ func NewServer(
 	g.Use(gin.Recovery())
 	g.Use(cors.New(corsConfig))
 	g.Use(middlewares.NewHostFilter())
-	g.Use(apierror.ErrorHandler)
+	g.Use(middlewares.ErrorHandler)
 
 	for _, h := range handlers {
 		err := h(g)
@@ -128,6 +127,9 @@ func (server *apiServer) serve() {
 
 func extractBoundAddress(listener net.Listener) (string, error) {
 	addr := listener.Addr()
+	if addr.Network() == "unix" {
+		return addr.String(), nil
+	}
 	parts := strings.Split(addr.String(), ":")
 	if len(parts) < 2 {
 		return "", errors.New("Unable to locate address: " + addr.String())
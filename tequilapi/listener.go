@@ -17,13 +17,164 @@
 
 package tequilapi
 
-import "net"
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
 
 // NewListener returns tequilapi listener.
 func NewListener(network, address string) (net.Listener, error) {
 	return net.Listen(network, address)
 }
 
+// NewUnixListener returns tequilapi listener on a unix domain socket with given file permissions.
+// A stale socket left by a previous run is removed, any other file at the path is left untouched.
+// The socket is removed when the listener is closed.
+func NewUnixListener(path string, mode os.FileMode) (net.Listener, error) {
+	if err := removeSocket(path); err != nil {
+		return nil, errors.Wrap(err, "could not remove stale socket")
+	}
+
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	// Socket is removed by the wrapper, which makes sure it is still a socket.
+	listener.SetUnlinkOnClose(false)
+
+	if err := os.Chmod(path, mode); err != nil {
+		listener.Close()
+		removeSocket(path)
+		return nil, errors.Wrap(err, "could not set socket permissions")
+	}
+	return &unixListener{UnixListener: listener, path: path}, nil
+}
+
+type unixListener struct {
+	*net.UnixListener
+	path string
+}
+
+func (l *unixListener) Close() error {
+	err := l.UnixListener.Close()
+	if removeErr := removeSocket(l.path); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// removeSocket removes unix domain socket at the path, refusing to remove other kinds of files.
+func removeSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode().Type() != os.ModeSocket {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// NewMultiListener returns listener accepting connections from all given listeners.
+// Address of the first listener is reported as the address of multi listener.
+func NewMultiListener(listeners ...net.Listener) net.Listener {
+	l := &multiListener{
+		listeners: listeners,
+		conns:     make(chan acceptResult),
+		done:      make(chan struct{}),
+	}
+	for _, listener := range listeners {
+		go l.acceptLoop(listener)
+	}
+	return l
+}
+
+type acceptResult struct {
+	conn net.Conn
+	err  error
+}
+
+type multiListener struct {
+	listeners []net.Listener
+	conns     chan acceptResult
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// maxAcceptDelay caps the backoff between retries of temporary accept errors.
+const maxAcceptDelay = time.Second
+
+func (m *multiListener) acceptLoop(listener net.Listener) {
+	var delay time.Duration
+	for {
+		conn, err := listener.Accept()
+		if ne, ok := err.(net.Error); ok && ne.Temporary() {
+			if delay == 0 {
+				delay = 5 * time.Millisecond
+			} else if delay *= 2; delay > maxAcceptDelay {
+				delay = maxAcceptDelay
+			}
+
+			select {
+			case <-time.After(delay):
+				continue
+			case <-m.done:
+				return
+			}
+		}
+		delay = 0
+
+		select {
+		case m.conns <- acceptResult{conn: conn, err: err}:
+		case <-m.done:
+			if conn != nil {
+				conn.Close()
+			}
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (m *multiListener) Accept() (net.Conn, error) {
+	select {
+	case res := <-m.conns:
+		return res.conn, res.err
+	case <-m.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (m *multiListener) Close() (err error) {
+	m.closeOnce.Do(func() {
+		close(m.done)
+		for _, listener := range m.listeners {
+			if closeErr := listener.Close(); closeErr != nil {
+				err = closeErr
+			}
+		}
+	})
+	return err
+}
+
+func (m *multiListener) Addr() net.Addr {
+	return m.listeners[0].Addr()
+}
+
 // NewNoopListener returns noop tequilapi listener.
 func NewNoopListener() (net.Listener, error) {
 	return &noopListener{}, nil
@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tequilapi
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnixListenerSetsPermissionsAndReplacesStaleSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "tequilapi.sock")
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: socket, Net: "unix"})
+	require.NoError(t, err)
+	stale.SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	listener, err := NewUnixListener(socket, 0600)
+	require.NoError(t, err)
+
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.Equal(t, os.ModeSocket, info.Mode().Type())
+
+	require.NoError(t, listener.Close())
+	_, err = os.Stat(socket)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestUnixListenerKeepsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tequilapi.sock")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0644))
+
+	_, err := NewUnixListener(path, 0600)
+	assert.Error(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(content))
+}
+
+type temporaryError struct{}
+
+func (temporaryError) Error() string   { return "temporary" }
+func (temporaryError) Timeout() bool   { return false }
+func (temporaryError) Temporary() bool { return true }
+
+type flakyListener struct {
+	net.Listener
+	failures int
+}
+
+func (l *flakyListener) Accept() (net.Conn, error) {
+	if l.failures > 0 {
+		l.failures--
+		return nil, temporaryError{}
+	}
+	return l.Listener.Accept()
+}
+
+func TestMultiListenerSurvivesTemporaryErrors(t *testing.T) {
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	listener := NewMultiListener(&flakyListener{Listener: tcp, failures: 3})
+	defer listener.Close()
+
+	client, err := net.Dial("tcp", tcp.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	conn.Close()
+}
+
+func TestMultiListenerAcceptsFromAllListeners(t *testing.T) {
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	socket := filepath.Join(t.TempDir(), "tequilapi.sock")
+	unix, err := NewUnixListener(socket, 0600)
+	require.NoError(t, err)
+
+	listener := NewMultiListener(tcp, unix)
+	assert.Equal(t, tcp.Addr(), listener.Addr())
+
+	for _, addr := range []net.Addr{tcp.Addr(), unix.Addr()} {
+		client, err := net.Dial(addr.Network(), addr.String())
+		require.NoError(t, err)
+
+		conn, err := listener.Accept()
+		require.NoError(t, err)
+		assert.Equal(t, addr.Network(), conn.LocalAddr().Network())
+
+		conn.Close()
+		client.Close()
+	}
+
+	assert.NoError(t, listener.Close())
+	_, err = listener.Accept()
+	assert.ErrorIs(t, err, net.ErrClosed)
+}
@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+// ErrorHandler writes the first error attached to the request as contract.APIError.
+func ErrorHandler(c *gin.Context) {
+	c.Next()
+	if len(c.Errors) < 1 {
+		return
+	}
+
+	res := contract.NewAPIError(c.Errors[0].Err, c.Request.URL.String())
+	blob, err := json.Marshal(res)
+	if err != nil {
+		c.Data(http.StatusInternalServerError, apierror.ContentTypeV1, apierror.DefaultErrStatic)
+		return
+	}
+	c.Data(res.Status, apierror.ContentTypeV1, blob)
+}
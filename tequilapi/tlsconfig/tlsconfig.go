@@ -0,0 +1,238 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+const (
+	certFile = "tequilapi-cert.pem"
+	keyFile  = "tequilapi-key.pem"
+	acmeDir  = "acme"
+
+	certValidity = 365 * 24 * time.Hour
+	// certRenewBefore regenerates self-signed certificates this long before they expire.
+	certRenewBefore = 30 * 24 * time.Hour
+)
+
+// Options configures TLS of tequilapi and UI listeners.
+type Options struct {
+	// Dir stores generated certificates and the ACME cache.
+	Dir string
+	// Hosts are names and IPs the self-signed certificate is valid for.
+	Hosts []string
+	// CurrentHosts are added to a newly generated self-signed certificate, but the stored
+	// certificate is not regenerated when they change, so that clients trusting it keep working.
+	CurrentHosts []string
+	// Domain enables ACME certificates for the given domain instead of a self-signed one.
+	Domain string
+	// ACMEEmail is the contact address for the ACME account, optional.
+	ACMEEmail string
+}
+
+// Dir returns the directory TLS files are stored in.
+func Dir(dataDir string) string {
+	return filepath.Join(dataDir, "tls")
+}
+
+// New creates TLS config serving an ACME certificate if a domain is configured,
+// otherwise a self-signed certificate stored in the options dir.
+// For ACME it also returns the handler of HTTP-01 challenges, which must be served on
+// port 80 of the domain, since API and UI listeners can't answer TLS-ALPN challenges on port 443.
+func New(opts Options) (*tls.Config, http.Handler, error) {
+	if err := os.MkdirAll(opts.Dir, 0700); err != nil {
+		return nil, nil, fmt.Errorf("could not create TLS directory: %w", err)
+	}
+
+	if opts.Domain != "" {
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(opts.Domain),
+			Cache:      autocert.DirCache(filepath.Join(opts.Dir, acmeDir)),
+			Email:      opts.ACMEEmail,
+		}
+		log.Info().Msgf("Using ACME certificate for %s", opts.Domain)
+		return &tls.Config{
+			GetCertificate: m.GetCertificate,
+			MinVersion:     tls.VersionTLS12,
+		}, m.HTTPHandler(http.NotFoundHandler()), nil
+	}
+
+	cert, err := LoadOrCreateSelfSigned(opts.Dir, opts.Hosts, opts.CurrentHosts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil, nil
+}
+
+// ClientConfig returns TLS config for local clients of a listener configured with New.
+// It trusts the stored self-signed certificate unless a domain is configured.
+func ClientConfig(dir, domain string) (*tls.Config, error) {
+	if domain != "" {
+		return &tls.Config{ServerName: domain}, nil
+	}
+
+	certPEM, err := os.ReadFile(filepath.Join(dir, certFile))
+	if err != nil {
+		return nil, fmt.Errorf("could not read tequilapi certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(certPEM) {
+		return nil, errors.New("could not parse tequilapi certificate")
+	}
+	// self-signed certificate always covers localhost, while the API may be bound to a wildcard address.
+	return &tls.Config{RootCAs: pool, ServerName: "localhost"}, nil
+}
+
+// LoadOrCreateSelfSigned loads the self-signed certificate from dir,
+// generating a new one if it is missing, about to expire or does not cover the hosts.
+// Current hosts are added to a generated certificate, but don't cause regeneration.
+// The stored key is reused when the certificate is regenerated.
+func LoadOrCreateSelfSigned(dir string, hosts []string, currentHosts ...string) (tls.Certificate, error) {
+	certPath := filepath.Join(dir, certFile)
+	keyPath := filepath.Join(dir, keyFile)
+
+	hosts = certHosts(hosts)
+
+	var key *ecdsa.PrivateKey
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err == nil {
+		reason := unusable(cert, hosts)
+		if reason == "" {
+			return cert, nil
+		}
+		log.Info().Msgf("Regenerating tequilapi certificate: %s", reason)
+		key, _ = cert.PrivateKey.(*ecdsa.PrivateKey)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		log.Warn().Err(err).Msg("Could not load tequilapi certificate, generating a new one")
+	}
+
+	certPEM, keyPEM, err := generateSelfSigned(key, certHosts(append(hosts, currentHosts...)), time.Now())
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := os.WriteFile(keyPath, keyPEM, 0600); err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not store TLS key: %w", err)
+	}
+	if err := os.WriteFile(certPath, certPEM, 0644); err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not store TLS certificate: %w", err)
+	}
+	log.Info().Msgf("Generated self-signed tequilapi certificate %s", certPath)
+
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+// certHosts adds loopback names to the hosts and drops wildcard addresses.
+func certHosts(hosts []string) []string {
+	seen := make(map[string]struct{})
+	var res []string
+	for _, host := range append([]string{"localhost", "127.0.0.1", "::1"}, hosts...) {
+		if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+			continue
+		}
+		if _, ok := seen[host]; ok {
+			continue
+		}
+		seen[host] = struct{}{}
+		res = append(res, host)
+	}
+	return res
+}
+
+// unusable returns the reason the certificate should be regenerated, empty if it is fine.
+func unusable(cert tls.Certificate, hosts []string) string {
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return err.Error()
+	}
+	if time.Until(leaf.NotAfter) < certRenewBefore {
+		return "certificate expires soon"
+	}
+	for _, host := range hosts {
+		if err := leaf.VerifyHostname(host); err != nil {
+			return err.Error()
+		}
+	}
+	return ""
+}
+
+// generateSelfSigned creates a leaf certificate for the hosts, generating a new key if none is given.
+func generateSelfSigned(key *ecdsa.PrivateKey, hosts []string, now time.Time) (certPEM, keyPEM []byte, err error) {
+	if key == nil {
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not generate TLS key: %w", err)
+		}
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not generate certificate serial: %w", err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Mysterium Node"}, CommonName: "tequilapi"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not encode TLS key: %w", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
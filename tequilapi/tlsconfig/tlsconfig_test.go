@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadOrCreateSelfSigned(t *testing.T) {
+	dir := t.TempDir()
+
+	cert, err := LoadOrCreateSelfSigned(dir, []string{"0.0.0.0", "192.168.1.10"})
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	for _, host := range []string{"localhost", "127.0.0.1", "::1", "192.168.1.10"} {
+		assert.NoError(t, leaf.VerifyHostname(host))
+	}
+
+	info, err := os.Stat(filepath.Join(dir, keyFile))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// stored certificate is reused
+	again, err := LoadOrCreateSelfSigned(dir, []string{"192.168.1.10"})
+	require.NoError(t, err)
+	assert.Equal(t, cert.Certificate, again.Certificate)
+
+	// not regenerated when a current host changes
+	again, err = LoadOrCreateSelfSigned(dir, []string{"192.168.1.10"}, "10.0.0.7")
+	require.NoError(t, err)
+	assert.Equal(t, cert.Certificate, again.Certificate)
+
+	// regenerated with the same key when a host is not covered
+	other, err := LoadOrCreateSelfSigned(dir, []string{"node.local"}, "10.0.0.7")
+	require.NoError(t, err)
+	assert.NotEqual(t, cert.Certificate, other.Certificate)
+	assert.Equal(t, cert.PrivateKey, other.PrivateKey)
+
+	otherLeaf, err := x509.ParseCertificate(other.Certificate[0])
+	require.NoError(t, err)
+	assert.NoError(t, otherLeaf.VerifyHostname("node.local"))
+	assert.NoError(t, otherLeaf.VerifyHostname("10.0.0.7"))
+	assert.False(t, otherLeaf.IsCA)
+	assert.Zero(t, otherLeaf.KeyUsage&x509.KeyUsageCertSign)
+}
+
+func TestNew_ServesSelfSignedCertificate(t *testing.T) {
+	cfg, challenges, err := New(Options{Dir: filepath.Join(t.TempDir(), "tls")})
+	require.NoError(t, err)
+	assert.Nil(t, challenges)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = cfg
+	server.StartTLS()
+	defer server.Close()
+
+	leaf, err := x509.ParseCertificate(cfg.Certificates[0].Certificate[0])
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
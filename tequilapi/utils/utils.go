@@ -49,19 +49,37 @@ func WriteAsJSON(v interface{}, writer http.ResponseWriter, httpCode ...int) {
 	}
 }
 
-// swagger:model APIError
-type apiErrorSwagger struct {
-	apierror.APIError
-}
-
 // ForwardError writes err to the response if it's in `apierror.APIError` format.
-// Otherwise, appends it to the fallback APIError's message.
+// Otherwise, appends it to the fallback APIError's message and keeps it as the cause.
 func ForwardError(c *gin.Context, err error, fallback *apierror.APIError) {
 	var apiErr *apierror.APIError
 	if errors.As(err, &apiErr) {
 		c.Error(err)
 	} else {
 		fallback.Err.Message = fallback.Err.Message + ": " + fmt.Errorf("%w", err).Error()
-		c.Error(fallback)
+		c.Error(WithCause(fallback, err))
+	}
+}
+
+// WithCause attaches the cause to the API error, so that the code and the hint
+// of a coded error returned by inner packages reach the client.
+func WithCause(apiErr *apierror.APIError, cause error) error {
+	return &apiErrorWithCause{APIError: apiErr, cause: cause}
+}
+
+type apiErrorWithCause struct {
+	*apierror.APIError
+	cause error
+}
+
+func (e *apiErrorWithCause) Unwrap() error {
+	return e.cause
+}
+
+func (e *apiErrorWithCause) As(target interface{}) bool {
+	if t, ok := target.(**apierror.APIError); ok {
+		*t = e.APIError
+		return true
 	}
+	return false
 }
@@ -18,6 +18,7 @@
 package ui
 
 import (
+	"crypto/tls"
 	"errors"
 	"net"
 	"net/http"
@@ -31,8 +32,8 @@ import (
 	"github.com/mysteriumnetwork/node/core/auth"
 )
 
-func buildTransport() *http.Transport {
-	return &http.Transport{
+func buildTransport(tequilapiTLS bool) *http.Transport {
+	t := &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
 		DialContext: (&net.Dialer{
 			Timeout:   20 * time.Second,
@@ -41,12 +42,23 @@ func buildTransport() *http.Transport {
 		MaxIdleConnsPerHost: 5,
 		IdleConnTimeout:     15,
 	}
+	if tequilapiTLS {
+		// proxy only talks to the node's own tequilapi listener,
+		// which may serve a self-signed certificate or one not valid for the loopback address.
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return t
 }
 
-func buildReverseProxy(tequilapiAddress string, tequilapiPort int) *httputil.ReverseProxy {
+func buildReverseProxy(tequilapiAddress string, tequilapiPort int, tequilapiTLS bool) *httputil.ReverseProxy {
+	scheme := "http"
+	if tequilapiTLS {
+		scheme = "https"
+	}
+
 	proxy := &httputil.ReverseProxy{
 		Director: func(req *http.Request) {
-			req.URL.Scheme = "http"
+			req.URL.Scheme = scheme
 			req.URL.Host = tequilapiAddress + ":" + strconv.Itoa(tequilapiPort)
 			req.URL.Path = strings.Replace(req.URL.Path, tequilapiUrlPrefix, "", 1)
 			req.URL.Path = strings.TrimRight(req.URL.Path, "/")
@@ -61,7 +73,7 @@ func buildReverseProxy(tequilapiAddress string, tequilapiPort int) *httputil.Rev
 			res.Header.Del("Access-Control-Allow-Methods")
 			return nil
 		},
-		Transport: buildTransport(),
+		Transport: buildTransport(tequilapiTLS),
 	}
 
 	proxy.FlushInterval = 10 * time.Millisecond
@@ -70,8 +82,8 @@ func buildReverseProxy(tequilapiAddress string, tequilapiPort int) *httputil.Rev
 }
 
 // ReverseTequilapiProxy proxies UIServer requests to the TequilAPI server
-func ReverseTequilapiProxy(tequilapiAddress string, tequilapiPort int, authenticator jwtAuthenticator) gin.HandlerFunc {
-	proxy := buildReverseProxy(tequilapiAddress, tequilapiPort)
+func ReverseTequilapiProxy(tequilapiAddress string, tequilapiPort int, tequilapiTLS bool, authenticator jwtAuthenticator) gin.HandlerFunc {
+	proxy := buildReverseProxy(tequilapiAddress, tequilapiPort, tequilapiTLS)
 
 	return func(c *gin.Context) {
 		// skip non Tequilapi routes
@@ -19,6 +19,7 @@ package ui
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"strings"
@@ -75,7 +76,8 @@ var corsConfig = cors.Config{
 }
 
 // NewServer creates a new instance of the server for the given port
-// you can chain addresses with ',' i.e. "192.168.0.1,127.0.0.1"
+// you can chain addresses with ',' i.e. "192.168.0.1,127.0.0.1".
+// If tlsConfig is given, both UI and tequilapi are served over TLS.
 func NewServer(
 	bindAddress string,
 	port int,
@@ -84,9 +86,10 @@ func NewServer(
 	authenticator jwtAuthenticator,
 	httpClient *requests.HTTPClient,
 	uiVersionConfig versionmanager.NodeUIVersionConfig,
+	tlsConfig *tls.Config,
 ) *Server {
 	gin.SetMode(gin.ReleaseMode)
-	reverseProxy := ReverseTequilapiProxy(tequilapiAddress, tequilapiPort, authenticator)
+	reverseProxy := ReverseTequilapiProxy(tequilapiAddress, tequilapiPort, tlsConfig != nil, authenticator)
 
 	var r *gin.Engine
 	version, err := uiVersionConfig.Version()
@@ -105,8 +108,9 @@ func NewServer(
 	var srvs []*http.Server
 	for _, addr := range addrs {
 		s := &http.Server{
-			Addr:    fmt.Sprintf("%v:%v", addr, port),
-			Handler: r,
+			Addr:      fmt.Sprintf("%v:%v", addr, port),
+			Handler:   r,
+			TLSConfig: tlsConfig,
 		}
 		srvs = append(srvs, s)
 	}
@@ -158,7 +162,12 @@ func (s *Server) Serve() {
 
 func startListen(s *http.Server) {
 	log.Info().Msgf("UI starting on: %s", s.Addr)
-	err := s.ListenAndServe()
+	var err error
+	if s.TLSConfig != nil {
+		err = s.ListenAndServeTLS("", "")
+	} else {
+		err = s.ListenAndServe()
+	}
 	if err != http.ErrServerClosed {
 		log.Err(err).Msg("UI server crashed")
 	}
@@ -55,6 +55,7 @@ func Test_Server_ServesHTML(t *testing.T) {
 		&jwtAuth{},
 		requests.NewHTTPClient("0.0.0.0", requests.DefaultTimeout),
 		config,
+		nil,
 	)
 	s.discovery = &mockDiscovery{}
 	s.Serve()
@@ -0,0 +1,177 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package updater
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ChannelStable is the release channel of stable node releases.
+	ChannelStable = "stable"
+	// ChannelBeta is the release channel of pre-releases.
+	ChannelBeta = "beta"
+)
+
+// Release describes a node release artifact published in a release channel.
+type Release struct {
+	Version string `json:"version"`
+	URL     string `json:"url"`
+	// SHA256 is the hex encoded digest of the artifact.
+	SHA256 string `json:"sha256"`
+	// Signature is the base64 encoded ed25519 signature of the release manifest, see Release.message.
+	Signature string `json:"signature"`
+}
+
+// message returns the signed content of the release manifest, binding the artifact to its version, channel and platform.
+func (r Release) message(channel string) []byte {
+	return []byte(strings.Join([]string{r.Version, channel, runtime.GOOS, runtime.GOARCH, r.URL, strings.ToLower(r.SHA256)}, "\n"))
+}
+
+// ValidChannel returns true if the channel is a known release channel.
+func ValidChannel(channel string) bool {
+	return channel == ChannelStable || channel == ChannelBeta
+}
+
+// manifestURL returns the location of the release manifest of the current platform.
+func manifestURL(baseURL, channel string) string {
+	return fmt.Sprintf("%s/%s/%s-%s.json", strings.TrimSuffix(baseURL, "/"), channel, runtime.GOOS, runtime.GOARCH)
+}
+
+func fetchRelease(client *http.Client, baseURL, channel string) (Release, error) {
+	res, err := client.Get(manifestURL(baseURL, channel))
+	if err != nil {
+		return Release{}, errors.Wrap(err, "could not fetch release manifest")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Release{}, errors.Errorf("release manifest request failed with status %d", res.StatusCode)
+	}
+
+	var release Release
+	if err := json.NewDecoder(res.Body).Decode(&release); err != nil {
+		return Release{}, errors.Wrap(err, "could not parse release manifest")
+	}
+	return release, nil
+}
+
+func download(client *http.Client, url, path string) error {
+	res, err := client.Get(url)
+	if err != nil {
+		return errors.Wrap(err, "could not download release")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("release download failed with status %d", res.StatusCode)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, res.Body); err != nil {
+		f.Close()
+		return errors.Wrap(err, "could not download release")
+	}
+	return f.Close()
+}
+
+// verifyRelease checks that the release manifest of the channel is signed by the release key.
+func verifyRelease(release Release, channel string, publicKey ed25519.PublicKey) error {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return errors.New("release signing key is not configured")
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(release.Signature)
+	if err != nil {
+		return errors.Wrap(err, "could not decode release signature")
+	}
+	if !ed25519.Verify(publicKey, release.message(channel), signature) {
+		return errors.New("invalid release signature")
+	}
+	return nil
+}
+
+// verifyChecksum checks that the artifact matches the release digest.
+func verifyChecksum(path string, release Release) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return err
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != strings.ToLower(release.SHA256) {
+		return errors.New("release checksum mismatch")
+	}
+	return nil
+}
+
+// newerVersion returns true if the version a is newer than b.
+// Versions are compared by their dot separated numeric components, pre-release suffixes are ignored.
+func newerVersion(a, b string) bool {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
+
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+
+	var parts []int
+	for _, s := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
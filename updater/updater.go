@@ -0,0 +1,350 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package updater
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const pendingUpdateFile = "update.json"
+
+var (
+	// ErrDisabled is returned when release channel location is not configured.
+	ErrDisabled = errors.New("updates are disabled")
+	// ErrInProgress is returned when an update is already running.
+	ErrInProgress = errors.New("update is already in progress")
+	// ErrRolledBack is returned on startup when the previously installed update failed to start and was rolled back.
+	// The running process is still the failed version, it has to restart into the restored binary.
+	ErrRolledBack = errors.New("update failed to start, previous version restored")
+)
+
+// State represents the stage of the update.
+type State string
+
+const (
+	// StateIdle no update is running.
+	StateIdle State = "idle"
+	// StateDownloading the release artifact is being downloaded and verified.
+	StateDownloading State = "downloading"
+	// StateDraining waiting for active sessions to end.
+	StateDraining State = "draining"
+	// StateRestarting the binary is swapped and the node restarts.
+	StateRestarting State = "restarting"
+	// StateFailed the last update failed.
+	StateFailed State = "failed"
+)
+
+// Config holds updater configuration.
+type Config struct {
+	// BaseURL is the location of release channels, updates are disabled when empty.
+	BaseURL string
+	// Channel is the default release channel.
+	Channel string
+	// PublicKey verifies signatures of release artifacts.
+	PublicKey ed25519.PublicKey
+	// DrainTimeout is how long to wait for active sessions to end before restarting.
+	DrainTimeout time.Duration
+	// Allowed reports whether updates are currently allowed, updates are always allowed if nil.
+	Allowed func() bool
+}
+
+// Status describes the updater state.
+type Status struct {
+	State          State  `json:"state"`
+	Channel        string `json:"channel"`
+	CurrentVersion string `json:"current_version"`
+	LatestVersion  string `json:"latest_version,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// pendingUpdate is stored between the binary swap and the confirmed start of the new version.
+type pendingUpdate struct {
+	Version string `json:"version"`
+	Binary  string `json:"binary"`
+	Backup  string `json:"backup"`
+	Started bool   `json:"started"`
+}
+
+// Updater checks release channels and replaces the running node binary with a newer signed release.
+type Updater struct {
+	config         Config
+	pendingPath    string
+	binary         string
+	currentVersion string
+	http           *http.Client
+	activeSessions func() int
+	shutdown       func()
+	pollInterval   time.Duration
+
+	lock           sync.Mutex
+	status         Status
+	restartPending bool
+}
+
+// New creates an updater of the running executable.
+// Shutdown is called to stop the node once the new binary is in place.
+func New(config Config, dataDir, currentVersion string, activeSessions func() int, shutdown func()) (*Updater, error) {
+	binary, err := os.Executable()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not locate node executable")
+	}
+	if binary, err = filepath.EvalSymlinks(binary); err != nil {
+		return nil, errors.Wrap(err, "could not locate node executable")
+	}
+
+	return &Updater{
+		config:         config,
+		pendingPath:    filepath.Join(dataDir, pendingUpdateFile),
+		binary:         binary,
+		currentVersion: currentVersion,
+		http:           &http.Client{Timeout: 5 * time.Minute},
+		activeSessions: activeSessions,
+		shutdown:       shutdown,
+		pollInterval:   5 * time.Second,
+		status:         Status{State: StateIdle, Channel: config.Channel, CurrentVersion: currentVersion},
+	}, nil
+}
+
+// Status returns the current updater status.
+func (u *Updater) Status() Status {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	return u.status
+}
+
+// Check returns the latest release of the channel and whether it is newer than the running version.
+func (u *Updater) Check(channel string) (Release, bool, error) {
+	if u.config.BaseURL == "" || (u.config.Allowed != nil && !u.config.Allowed()) {
+		return Release{}, false, ErrDisabled
+	}
+	if channel == "" {
+		channel = u.config.Channel
+	}
+	if !ValidChannel(channel) {
+		return Release{}, false, errors.Errorf("unknown release channel %q", channel)
+	}
+
+	release, err := fetchRelease(u.http, u.config.BaseURL, channel)
+	if err != nil {
+		return Release{}, false, err
+	}
+	if err := verifyRelease(release, channel, u.config.PublicKey); err != nil {
+		return Release{}, false, err
+	}
+
+	u.lock.Lock()
+	u.status.Channel = channel
+	u.status.LatestVersion = release.Version
+	u.lock.Unlock()
+
+	return release, newerVersion(release.Version, u.currentVersion), nil
+}
+
+// Update installs the latest release of the channel in background, if it is newer than the running version.
+func (u *Updater) Update(channel string) (Release, error) {
+	u.lock.Lock()
+	if state := u.status.State; state != StateIdle && state != StateFailed {
+		u.lock.Unlock()
+		return Release{}, ErrInProgress
+	}
+	u.status.State = StateDownloading
+	u.status.Error = ""
+	u.lock.Unlock()
+
+	release, newer, err := u.Check(channel)
+	if err != nil || !newer {
+		u.setState(StateIdle, nil)
+		return release, err
+	}
+
+	go func() {
+		if err := u.install(release); err != nil {
+			log.Error().Err(err).Msgf("Failed to update node to %s", release.Version)
+			u.setState(StateFailed, err)
+		}
+	}()
+	return release, nil
+}
+
+func (u *Updater) install(release Release) error {
+	log.Info().Msgf("Updating node from %s to %s", u.currentVersion, release.Version)
+
+	newBinary := u.binary + ".new"
+	defer os.Remove(newBinary)
+
+	if err := download(u.http, release.URL, newBinary); err != nil {
+		return err
+	}
+	if err := verifyChecksum(newBinary, release); err != nil {
+		return err
+	}
+
+	u.setState(StateDraining, nil)
+	u.drain()
+
+	backup := u.binary + ".old"
+	if err := os.Rename(u.binary, backup); err != nil {
+		return errors.Wrap(err, "could not back up current binary")
+	}
+	if err := os.Rename(newBinary, u.binary); err != nil {
+		if rerr := os.Rename(backup, u.binary); rerr != nil {
+			log.Error().Err(rerr).Msg("Could not restore node binary")
+		}
+		return errors.Wrap(err, "could not install new binary")
+	}
+
+	if err := u.writePending(pendingUpdate{Version: release.Version, Binary: u.binary, Backup: backup}); err != nil {
+		return err
+	}
+
+	u.lock.Lock()
+	u.restartPending = true
+	u.status.State = StateRestarting
+	u.lock.Unlock()
+
+	log.Info().Msgf("Node binary replaced with %s, restarting", release.Version)
+	u.shutdown()
+	return nil
+}
+
+// drain waits until there are no active sessions or the drain timeout passes.
+func (u *Updater) drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), u.config.DrainTimeout)
+	defer cancel()
+
+	for {
+		active := u.activeSessions()
+		if active == 0 {
+			return
+		}
+		log.Info().Msgf("Waiting for %d active sessions to end before restart", active)
+
+		select {
+		case <-ctx.Done():
+			log.Warn().Msgf("Drain timeout reached, restarting with %d active sessions", active)
+			return
+		case <-time.After(u.pollInterval):
+		}
+	}
+}
+
+// Recover must be called on startup. The first start of an updated binary is remembered,
+// if the node is started again without confirming the start, the update is rolled back
+// and RestartPending reports that the node has to be restarted.
+func (u *Updater) Recover() error {
+	pending, ok, err := u.readPending()
+	if err != nil || !ok {
+		return err
+	}
+
+	if !pending.Started {
+		log.Info().Msgf("Starting updated node version %s", pending.Version)
+		pending.Started = true
+		return u.writePending(pending)
+	}
+
+	log.Error().Msgf("Node version %s failed to start, rolling back", pending.Version)
+	if err := os.Rename(pending.Backup, pending.Binary); err != nil {
+		return errors.Wrap(err, "could not restore previous binary")
+	}
+	if err := os.Remove(u.pendingPath); err != nil {
+		return err
+	}
+
+	u.lock.Lock()
+	u.restartPending = true
+	u.lock.Unlock()
+	return ErrRolledBack
+}
+
+// Confirm marks the updated binary as successfully started and removes the backup.
+func (u *Updater) Confirm() {
+	pending, ok, err := u.readPending()
+	if err != nil || !ok {
+		return
+	}
+
+	if err := os.Remove(u.pendingPath); err != nil {
+		log.Error().Err(err).Msg("Could not confirm node update")
+		return
+	}
+	if err := os.Remove(pending.Backup); err != nil && !os.IsNotExist(err) {
+		log.Warn().Err(err).Msg("Could not remove previous node binary")
+	}
+	log.Info().Msgf("Node update to %s confirmed", pending.Version)
+}
+
+// RestartPending returns true if the node should be restarted into the swapped binary after shutdown.
+func (u *Updater) RestartPending() bool {
+	if u == nil {
+		return false
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	return u.restartPending
+}
+
+// Restart replaces the current process with the node binary.
+func (u *Updater) Restart() error {
+	return restart(u.binary)
+}
+
+func (u *Updater) setState(state State, err error) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	u.status.State = state
+	u.status.Error = ""
+	if err != nil {
+		u.status.Error = err.Error()
+	}
+}
+
+func (u *Updater) readPending() (pendingUpdate, bool, error) {
+	var pending pendingUpdate
+	data, err := os.ReadFile(u.pendingPath)
+	if os.IsNotExist(err) {
+		return pending, false, nil
+	}
+	if err != nil {
+		return pending, false, errors.Wrap(err, "could not read pending update")
+	}
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return pending, false, errors.Wrap(err, "could not parse pending update")
+	}
+	return pending, true, nil
+}
+
+func (u *Updater) writePending(pending pendingUpdate) error {
+	data, err := json.Marshal(pending)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(os.WriteFile(u.pendingPath, data, 0600), "could not save pending update")
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package updater
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newerVersion(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		newer bool
+	}{
+		{a: "1.2.0", b: "1.1.9", newer: true},
+		{a: "1.10.0", b: "1.9.0", newer: true},
+		{a: "v1.2.1", b: "1.2.0", newer: true},
+		{a: "1.2", b: "1.2.0", newer: false},
+		{a: "1.2.0-beta.1", b: "1.2.0", newer: false},
+		{a: "1.1.0", b: "1.2.0", newer: false},
+		{a: "1.2.0", b: "source", newer: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+">"+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.newer, newerVersion(tt.a, tt.b))
+		})
+	}
+}
+
+func Test_verifyRelease(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	release := signedRelease(priv, ChannelStable, "1.0.0", "https://example.com/myst", []byte("binary"))
+
+	assert.NoError(t, verifyRelease(release, ChannelStable, pub))
+	assert.EqualError(t, verifyRelease(release, ChannelStable, otherPub), "invalid release signature")
+	assert.EqualError(t, verifyRelease(release, ChannelStable, nil), "release signing key is not configured")
+	assert.EqualError(t, verifyRelease(release, ChannelBeta, pub), "invalid release signature")
+
+	tampered := release
+	tampered.URL = "https://example.org/myst"
+	assert.EqualError(t, verifyRelease(tampered, ChannelStable, pub), "invalid release signature")
+
+	tampered = release
+	tampered.Version = "9.0.0"
+	assert.EqualError(t, verifyRelease(tampered, ChannelStable, pub), "invalid release signature")
+}
+
+func Test_verifyChecksum(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "myst")
+	require.NoError(t, os.WriteFile(path, []byte("binary"), 0755))
+	release := signedRelease(priv, ChannelStable, "1.0.0", "", []byte("binary"))
+
+	assert.NoError(t, verifyChecksum(path, release))
+
+	tampered := release
+	tampered.SHA256 = hex.EncodeToString(make([]byte, sha256.Size))
+	assert.EqualError(t, verifyChecksum(path, tampered), "release checksum mismatch")
+}
+
+func TestUpdater_UpdateAndRollback(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/binary" {
+			w.Write([]byte("new"))
+			return
+		}
+		json.NewEncoder(w).Encode(signedRelease(priv, ChannelStable, "2.0.0", server.URL+"/binary", []byte("new")))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "myst")
+	require.NoError(t, os.WriteFile(binary, []byte("old"), 0755))
+
+	stopped := make(chan struct{})
+	sessions := 1
+	u := newTestUpdater(Config{BaseURL: server.URL, Channel: ChannelStable, PublicKey: pub, DrainTimeout: time.Second}, dir, binary, func() int {
+		sessions--
+		return sessions
+	}, func() { close(stopped) })
+
+	release, err := u.Update("")
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", release.Version)
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("node was not stopped after update")
+	}
+	assert.True(t, u.RestartPending())
+	assertContent(t, binary, "new")
+	assertContent(t, binary+".old", "old")
+
+	// First start of the new binary is remembered.
+	restarted := newTestUpdater(u.config, dir, binary, nil, nil)
+	require.NoError(t, restarted.Recover())
+	assert.False(t, restarted.RestartPending())
+
+	// Second start without confirmation rolls back.
+	failed := newTestUpdater(u.config, dir, binary, nil, nil)
+	assert.ErrorIs(t, failed.Recover(), ErrRolledBack)
+	assert.True(t, failed.RestartPending())
+	assertContent(t, binary, "old")
+	assert.NoFileExists(t, filepath.Join(dir, pendingUpdateFile))
+}
+
+func TestUpdater_Confirm(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "myst")
+	require.NoError(t, os.WriteFile(binary, []byte("new"), 0755))
+	require.NoError(t, os.WriteFile(binary+".old", []byte("old"), 0755))
+
+	u := newTestUpdater(Config{}, dir, binary, nil, nil)
+	require.NoError(t, u.writePending(pendingUpdate{Version: "2.0.0", Binary: binary, Backup: binary + ".old"}))
+	require.NoError(t, u.Recover())
+
+	u.Confirm()
+
+	assert.NoFileExists(t, binary+".old")
+	assert.NoFileExists(t, filepath.Join(dir, pendingUpdateFile))
+	require.NoError(t, u.Recover())
+	assertContent(t, binary, "new")
+}
+
+func TestUpdater_Disabled(t *testing.T) {
+	u := newTestUpdater(Config{}, t.TempDir(), "myst", nil, nil)
+
+	_, err := u.Update(ChannelStable)
+	assert.ErrorIs(t, err, ErrDisabled)
+	assert.Equal(t, StateIdle, u.Status().State)
+}
+
+func newTestUpdater(config Config, dir, binary string, activeSessions func() int, shutdown func()) *Updater {
+	return &Updater{
+		config:         config,
+		pendingPath:    filepath.Join(dir, pendingUpdateFile),
+		binary:         binary,
+		currentVersion: "1.0.0",
+		http:           http.DefaultClient,
+		activeSessions: activeSessions,
+		shutdown:       shutdown,
+		pollInterval:   time.Millisecond,
+		status:         Status{State: StateIdle, Channel: config.Channel, CurrentVersion: "1.0.0"},
+	}
+}
+
+func signedRelease(key ed25519.PrivateKey, channel, version, url string, content []byte) Release {
+	digest := sha256.Sum256(content)
+	release := Release{
+		Version: version,
+		URL:     url,
+		SHA256:  hex.EncodeToString(digest[:]),
+	}
+	release.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, release.message(channel)))
+	return release
+}
+
+func assertContent(t *testing.T, path, expected string) {
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(content))
+}
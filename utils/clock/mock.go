@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Mock is a clock which only moves when told to, firing timers and tickers due on the way.
+type Mock struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	timers  []*mockTimer
+}
+
+type mockTimer struct {
+	deadline time.Time
+	period   time.Duration
+	c        chan time.Time
+}
+
+// NewMock returns a mock clock set to the given time.
+func NewMock(now time.Time) *Mock {
+	m := &Mock{now: now}
+	m.changed = sync.NewCond(&m.mu)
+	return m
+}
+
+// Now returns current mock time.
+func (m *Mock) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.now
+}
+
+// Since returns the mock time elapsed since t.
+func (m *Mock) Since(t time.Time) time.Duration {
+	return m.Now().Sub(t)
+}
+
+// After returns a channel receiving mock time once the clock is moved by the duration.
+func (m *Mock) After(d time.Duration) <-chan time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	timer := &mockTimer{deadline: m.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		timer.c <- m.now
+		return timer.c
+	}
+	m.add(timer)
+	return timer.c
+}
+
+// NewTicker returns a ticker firing each time the clock is moved by the period.
+func (m *Mock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for clock.Mock.NewTicker")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	timer := &mockTimer{deadline: m.now.Add(d), period: d, c: make(chan time.Time, 1)}
+	m.add(timer)
+	return &mockTicker{clock: m, timer: timer}
+}
+
+// Add moves the clock forward, firing due timers in deadline order.
+// Like time.Ticker, a ticker drops ticks its receiver is not keeping up with.
+func (m *Mock) Add(d time.Duration) {
+	m.Set(m.Now().Add(d))
+}
+
+// Set moves the clock to the given time, firing due timers in deadline order.
+func (m *Mock) Set(t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for len(m.timers) > 0 && !m.timers[0].deadline.After(t) {
+		timer := m.timers[0]
+		m.timers = m.timers[1:]
+		m.now = timer.deadline
+
+		select {
+		case timer.c <- m.now:
+		default:
+		}
+		if timer.period > 0 {
+			timer.deadline = timer.deadline.Add(timer.period)
+			m.add(timer)
+		}
+	}
+	if t.After(m.now) {
+		m.now = t
+	}
+	m.changed.Broadcast()
+}
+
+// BlockUntil blocks until at least n timers and tickers are waiting for the clock to move.
+// It lets tests advance the clock only once the code under test has started waiting.
+func (m *Mock) BlockUntil(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for len(m.timers) < n {
+		m.changed.Wait()
+	}
+}
+
+// add must be called with m.mu held.
+func (m *Mock) add(timer *mockTimer) {
+	m.timers = append(m.timers, timer)
+	sort.SliceStable(m.timers, func(i, j int) bool {
+		return m.timers[i].deadline.Before(m.timers[j].deadline)
+	})
+	m.changed.Broadcast()
+}
+
+func (m *Mock) remove(timer *mockTimer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.timers {
+		if m.timers[i] == timer {
+			m.timers = append(m.timers[:i], m.timers[i+1:]...)
+			break
+		}
+	}
+	m.changed.Broadcast()
+}
+
+type mockTicker struct {
+	clock *Mock
+	timer *mockTimer
+}
+
+func (t *mockTicker) C() <-chan time.Time {
+	return t.timer.c
+}
+
+func (t *mockTicker) Stop() {
+	t.clock.remove(t.timer)
+}
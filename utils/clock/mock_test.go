@@ -0,0 +1,132 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2022, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+func TestMock_After(t *testing.T) {
+	// given
+	clock := NewMock(start)
+	fired := clock.After(time.Minute)
+
+	// when
+	clock.Add(59 * time.Second)
+
+	// then
+	assert.Len(t, fired, 0)
+
+	// when
+	clock.Add(time.Second)
+
+	// then
+	assert.Equal(t, start.Add(time.Minute), <-fired)
+	assert.Equal(t, start.Add(time.Minute), clock.Now())
+}
+
+func TestMock_AfterNonPositiveFiresImmediately(t *testing.T) {
+	clock := NewMock(start)
+
+	assert.Equal(t, start, <-clock.After(0))
+}
+
+func TestMock_TickerFiresEveryPeriod(t *testing.T) {
+	// given
+	clock := NewMock(start)
+	ticker := clock.NewTicker(10 * time.Second)
+
+	// when
+	var ticks []time.Time
+	for i := 0; i < 3; i++ {
+		clock.Add(10 * time.Second)
+		ticks = append(ticks, <-ticker.C())
+	}
+
+	// then
+	assert.Equal(t, []time.Time{start.Add(10 * time.Second), start.Add(20 * time.Second), start.Add(30 * time.Second)}, ticks)
+}
+
+func TestMock_TickerDropsMissedTicks(t *testing.T) {
+	// given
+	clock := NewMock(start)
+	ticker := clock.NewTicker(time.Second)
+
+	// when
+	clock.Add(5 * time.Second)
+
+	// then
+	assert.Equal(t, start.Add(time.Second), <-ticker.C())
+	assert.Len(t, ticker.C(), 0)
+}
+
+func TestMock_StoppedTickerDoesNotFire(t *testing.T) {
+	// given
+	clock := NewMock(start)
+	ticker := clock.NewTicker(time.Second)
+
+	// when
+	ticker.Stop()
+	clock.Add(time.Minute)
+
+	// then
+	assert.Len(t, ticker.C(), 0)
+}
+
+func TestMock_FiresTimersInDeadlineOrder(t *testing.T) {
+	// given
+	clock := NewMock(start)
+	late := clock.After(2 * time.Second)
+	early := clock.After(time.Second)
+
+	// when
+	clock.Add(time.Hour)
+
+	// then
+	assert.Equal(t, start.Add(time.Second), <-early)
+	assert.Equal(t, start.Add(2*time.Second), <-late)
+	assert.Equal(t, start.Add(time.Hour), clock.Now())
+}
+
+func TestMock_BlockUntil(t *testing.T) {
+	// given
+	clock := NewMock(start)
+	done := make(chan time.Time)
+	go func() {
+		done <- <-clock.After(time.Second)
+	}()
+
+	// when
+	clock.BlockUntil(1)
+	clock.Add(time.Second)
+
+	// then
+	assert.Equal(t, start.Add(time.Second), <-done)
+}
+
+func TestMock_Since(t *testing.T) {
+	clock := NewMock(start)
+	clock.Add(time.Minute)
+
+	assert.Equal(t, time.Minute, clock.Since(start))
+}
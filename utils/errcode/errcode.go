@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package errcode provides errors with stable machine-readable codes,
+// which are propagated from inner packages up to the API clients.
+package errcode
+
+import "errors"
+
+// Error is an error with a stable machine-readable code, a human readable message and a remediation hint.
+// Once created, the code must not change, because API clients may depend on it.
+type Error struct {
+	Code    string
+	Message string
+	Hint    string
+
+	cause error
+}
+
+// New creates a coded error.
+func New(code, message, hint string) *Error {
+	return &Error{Code: code, Message: message, Hint: hint}
+}
+
+// Wrap returns a copy of the coded error caused by the given error.
+// The result matches the original coded error with errors.Is.
+func (e *Error) Wrap(cause error) *Error {
+	return &Error{Code: e.Code, Message: e.Message, Hint: e.Hint, cause: cause}
+}
+
+// Error returns the message of the error followed by its cause.
+func (e *Error) Error() string {
+	if e.cause == nil {
+		return e.Message
+	}
+	return e.Message + ": " + e.cause.Error()
+}
+
+// Unwrap returns the cause of the error.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether the target is a coded error with the same code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// From returns the outermost coded error in the chain of err.
+func From(err error) (*Error, bool) {
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded, true
+	}
+	return nil, false
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTest = New("err_test", "test failed", "try again")
+
+func TestError_Wrapping(t *testing.T) {
+	cause := errors.New("timeout")
+	err := fmt.Errorf("connecting: %w", errTest.Wrap(cause))
+
+	assert.True(t, errors.Is(err, errTest))
+	assert.True(t, errors.Is(err, cause))
+	assert.Equal(t, "connecting: test failed: timeout", err.Error())
+
+	coded, ok := From(err)
+	assert.True(t, ok)
+	assert.Equal(t, "err_test", coded.Code)
+	assert.Equal(t, "try again", coded.Hint)
+
+	_, ok = From(cause)
+	assert.False(t, ok)
+}
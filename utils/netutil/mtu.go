@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netutil
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/utils/cmdutil"
+)
+
+const (
+	// MinPathMTU is the smallest path MTU probed, tunnels never go below IPv6 minimum of 1280 inside.
+	MinPathMTU = 1280
+	// MaxPathMTU is the largest path MTU probed, it matches Ethernet.
+	MaxPathMTU = 1500
+
+	icmpEchoOverhead = 28 // IPv4 and ICMP headers
+)
+
+// PathMTUProber checks if an IP packet of given size reaches the host without fragmentation.
+type PathMTUProber func(ip net.IP, size int) error
+
+// PingProbe probes path MTU with a single ICMP echo request which has "don't fragment" flag set.
+func PingProbe(ip net.IP, size int) error {
+	return cmdutil.Exec(pingDFArgs(ip, size-icmpEchoOverhead)...)
+}
+
+// DiscoverPathMTU finds the largest packet size between min and max which reaches the host unfragmented.
+func DiscoverPathMTU(ip net.IP, min, max int, probe PathMTUProber) (int, error) {
+	if err := probe(ip, min); err != nil {
+		return 0, fmt.Errorf("host does not respond to probes: %w", err)
+	}
+
+	for min < max {
+		size := (min + max + 1) / 2
+		if probe(ip, size) == nil {
+			min = size
+		} else {
+			max = size - 1
+		}
+	}
+	return min, nil
+}
+
+// TunnelMTU returns MTU of a tunnel to the host, so that encapsulated packets fit into the path MTU.
+// Zero is returned if path MTU could not be discovered and the tunnel default should be kept.
+func TunnelMTU(ip net.IP, overhead int, probe PathMTUProber) int {
+	if probe == nil || ip == nil {
+		return 0
+	}
+
+	pathMTU, err := DiscoverPathMTU(ip, MinPathMTU, MaxPathMTU, probe)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Could not discover path MTU to %s, using default tunnel MTU", ip)
+		return 0
+	}
+
+	mtu := pathMTU - overhead
+	if mtu < MinPathMTU {
+		mtu = MinPathMTU
+	}
+	log.Info().Msgf("Discovered path MTU to %s: %d, tunnel MTU: %d", ip, pathMTU, mtu)
+	return mtu
+}
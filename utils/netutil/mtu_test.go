@@ -0,0 +1,56 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netutil
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pathWithMTU(mtu int) PathMTUProber {
+	return func(ip net.IP, size int) error {
+		if size > mtu {
+			return errors.New("message too long")
+		}
+		return nil
+	}
+}
+
+func TestDiscoverPathMTU(t *testing.T) {
+	ip := net.ParseIP("1.2.3.4")
+	for _, mtu := range []int{1280, 1420, 1492, 1500} {
+		got, err := DiscoverPathMTU(ip, MinPathMTU, MaxPathMTU, pathWithMTU(mtu))
+		assert.NoError(t, err)
+		assert.Equal(t, mtu, got)
+	}
+
+	_, err := DiscoverPathMTU(ip, MinPathMTU, MaxPathMTU, pathWithMTU(1000))
+	assert.Error(t, err)
+}
+
+func TestTunnelMTU(t *testing.T) {
+	ip := net.ParseIP("1.2.3.4")
+
+	assert.Equal(t, 1412, TunnelMTU(ip, 80, pathWithMTU(1492)))
+	assert.Equal(t, MinPathMTU, TunnelMTU(ip, 80, pathWithMTU(1300)))
+	assert.Equal(t, 0, TunnelMTU(ip, 80, pathWithMTU(1000)))
+	assert.Equal(t, 0, TunnelMTU(ip, 80, nil))
+}
@@ -18,6 +18,7 @@
 package netutil
 
 import (
+	"errors"
 	"net"
 	"strings"
 
@@ -28,11 +29,30 @@ import (
 // LogNetworkStats logs network information to the Trace log level.
 var LogNetworkStats = defaultLogNetworkStats
 
+// ErrSourceRouteNotSupported is returned when source based routing is not available on the platform.
+var ErrSourceRouteNotSupported = errors.New("source based routing is not supported on this platform")
+
 // AddDefaultRoute adds default VPN tunnel route.
 func AddDefaultRoute(iface string) error {
 	return addDefaultRoute(iface)
 }
 
+// DeleteDefaultRoute removes default VPN tunnel route added by AddDefaultRoute.
+func DeleteDefaultRoute(iface string) error {
+	return deleteDefaultRoute(iface)
+}
+
+// AddSourceRoute routes traffic originating from the source network through the given interface,
+// regardless of the default route.
+func AddSourceRoute(src net.IPNet, iface string) error {
+	return addSourceRoute(src, iface)
+}
+
+// DeleteSourceRoute removes a route previously added by AddSourceRoute.
+func DeleteSourceRoute(src net.IPNet, iface string) error {
+	return deleteSourceRoute(src, iface)
+}
+
 // AssignIP assigns subnet to given interface.
 func AssignIP(iface string, subnet net.IPNet) error {
 	return assignIP(iface, subnet)
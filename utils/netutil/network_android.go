@@ -21,6 +21,7 @@ package netutil
 
 import (
 	"net"
+	"strconv"
 	"strings"
 
 	"github.com/rs/zerolog/log"
@@ -44,6 +45,10 @@ func addDefaultRoute(iface string) error {
 	return nil
 }
 
+func deleteDefaultRoute(iface string) error {
+	return nil
+}
+
 func logNetworkStats() {
 }
 
@@ -56,3 +61,15 @@ func ipv6Enabled() bool {
 
 	return strings.Contains(string(out), "net.ipv6.conf.all.disable_ipv6 = 0")
 }
+
+func pingDFArgs(ip net.IP, payload int) []string {
+	return []string{"ping", "-c", "1", "-W", "1", "-M", "do", "-s", strconv.Itoa(payload), ip.String()}
+}
+
+func addSourceRoute(src net.IPNet, iface string) error {
+	return ErrSourceRouteNotSupported
+}
+
+func deleteSourceRoute(src net.IPNet, iface string) error {
+	return ErrSourceRouteNotSupported
+}
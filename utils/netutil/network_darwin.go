@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net"
 	"os/exec"
+	"strconv"
 
 	"github.com/mysteriumnetwork/node/utils/cmdutil"
 )
@@ -65,6 +66,21 @@ func addDefaultRoute(iface string) error {
 	return nil
 }
 
+func deleteDefaultRoute(iface string) (lastErr error) {
+	for _, args := range [][]string{
+		{"route", "delete", "-net", "0.0.0.0/1", "-interface", iface},
+		{"route", "delete", "-net", "128.0.0.0/1", "-interface", iface},
+		{"route", "delete", "-inet6", "::/1", fmt.Sprintf("100::1%%%s", iface)},
+		{"route", "delete", "-inet6", "8000::/1", fmt.Sprintf("100::1%%%s", iface)},
+	} {
+		if err := cmdutil.SudoExec(args...); err != nil {
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
 func peerIP(subnet net.IPNet) net.IP {
 	lastOctetID := len(subnet.IP) - 1
 	if subnet.IP[lastOctetID] == byte(1) {
@@ -81,3 +97,15 @@ func logNetworkStats() {
 		logOutputToTrace(out, err, args...)
 	}
 }
+
+func pingDFArgs(ip net.IP, payload int) []string {
+	return []string{"ping", "-c", "1", "-t", "1", "-D", "-s", strconv.Itoa(payload), ip.String()}
+}
+
+func addSourceRoute(src net.IPNet, iface string) error {
+	return ErrSourceRouteNotSupported
+}
+
+func deleteSourceRoute(src net.IPNet, iface string) error {
+	return ErrSourceRouteNotSupported
+}
@@ -20,8 +20,10 @@
 package netutil
 
 import (
+	"fmt"
 	"net"
 	"os/exec"
+	"strconv"
 	"strings"
 
 	"github.com/rs/zerolog/log"
@@ -66,6 +68,146 @@ func addDefaultRoute(iface string) error {
 	return nil
 }
 
+func deleteDefaultRoute(iface string) (lastErr error) {
+	routes := [][]string{
+		{"ip", "route", "delete", "0.0.0.0/1", "dev", iface},
+		{"ip", "route", "delete", "128.0.0.0/1", "dev", iface},
+	}
+	if ipv6Enabled() {
+		routes = append(routes,
+			[]string{"ip", "-6", "route", "delete", "::/1", "dev", iface},
+			[]string{"ip", "-6", "route", "delete", "8000::/1", "dev", iface},
+		)
+	}
+
+	for _, args := range routes {
+		if err := cmdutil.SudoExec(args...); err != nil {
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+// sourceRouteTableBase is added to the interface index to get
+// a routing table dedicated to the interface.
+const sourceRouteTableBase = 5000
+
+func addSourceRoute(src net.IPNet, iface string) error {
+	table, err := sourceRouteTable(iface)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"ip", "route", "replace", "default"}
+	if gw, err := interfaceGateway(iface, table); err == nil {
+		args = append(args, "via", gw)
+	}
+	args = append(args, "dev", iface, "table", table)
+	if err := cmdutil.SudoExec(args...); err != nil {
+		return err
+	}
+
+	out, err := cmdutil.ExecOutput("ip", "rule", "show")
+	if err != nil {
+		return err
+	}
+
+	// Rules without an explicit priority are inserted above the existing ones, so the
+	// main table rule added last is consulted first. It ignores default routes and keeps
+	// LAN and local destinations on their usual routes.
+	for _, rule := range sourceRouteRules(src, table) {
+		if ruleExists(out, rule) {
+			continue
+		}
+		if err := cmdutil.SudoExec(append([]string{"ip", "rule", "add"}, rule...)...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deleteSourceRoute(src net.IPNet, iface string) (lastErr error) {
+	table, err := sourceRouteTable(iface)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range sourceRouteRules(src, table) {
+		if err := cmdutil.SudoExec(append([]string{"ip", "rule", "delete"}, rule...)...); err != nil {
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+func sourceRouteRules(src net.IPNet, table string) [][]string {
+	return [][]string{
+		{"from", src.String(), "lookup", table},
+		{"from", src.String(), "lookup", "main", "suppress_prefixlength", "0"},
+	}
+}
+
+// ruleExists checks whether the `ip rule show` output contains the rule.
+func ruleExists(out string, rule []string) bool {
+	want := strings.Join(rule, " ")
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) == 2 && strings.Join(strings.Fields(parts[1]), " ") == want {
+			return true
+		}
+	}
+	return false
+}
+
+func sourceRouteTable(iface string) (string, error) {
+	i, err := net.InterfaceByName(iface)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(sourceRouteTableBase + i.Index), nil
+}
+
+// interfaceGateway returns gateway of the default route going through the interface.
+// All routing tables are searched since multi-uplink hosts often keep the default
+// route of a secondary uplink outside of the main table.
+func interfaceGateway(iface, ownTable string) (string, error) {
+	out, err := cmdutil.ExecOutput("ip", "route", "show", "table", "all", "default", "dev", iface)
+	if err != nil {
+		return "", err
+	}
+
+	if gw, ok := parseGateway(out, ownTable); ok {
+		return gw, nil
+	}
+	return "", fmt.Errorf("no default gateway on %s", iface)
+}
+
+// parseGateway returns the first gateway found in `ip route show` output,
+// skipping routes of the given table.
+func parseGateway(out, skipTable string) (string, bool) {
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		gw := ""
+		skip := false
+		for i := 0; i < len(fields)-1; i++ {
+			switch fields[i] {
+			case "via":
+				gw = fields[i+1]
+			case "table":
+				skip = fields[i+1] == skipTable
+			}
+		}
+		if gw != "" && !skip {
+			return gw, true
+		}
+	}
+	return "", false
+}
+
 func logNetworkStats() {
 	for _, args := range [][]string{{"iptables", "-L", "-n"}, {"iptables", "-L", "-n", "-t", "nat"}, {"ip", "route", "list"}, {"ip", "address", "list"}} {
 		out, err := exec.Command("sudo", args...).CombinedOutput()
@@ -83,3 +225,7 @@ func ipv6Enabled() bool {
 
 	return strings.Contains(string(out), "net.ipv6.conf.all.disable_ipv6 = 0")
 }
+
+func pingDFArgs(ip net.IP, payload int) []string {
+	return []string{"ping", "-c", "1", "-W", "1", "-M", "do", "-s", strconv.Itoa(payload), ip.String()}
+}
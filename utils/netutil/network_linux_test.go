@@ -0,0 +1,46 @@
+//go:build linux && !android
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package netutil
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGateway(t *testing.T) {
+	out := "default dev eth1 table 5003 scope link\ndefault via 10.1.0.1 dev eth1 table 100 proto static\n"
+
+	gw, ok := parseGateway(out, "5003")
+	assert.True(t, ok)
+	assert.Equal(t, "10.1.0.1", gw)
+
+	_, ok = parseGateway("default via 10.1.0.1 dev eth1 table 5003\n", "5003")
+	assert.False(t, ok)
+}
+
+func TestRuleExists(t *testing.T) {
+	src := net.IPNet{IP: net.IPv4(10, 182, 0, 0), Mask: net.CIDRMask(24, 32)}
+	out := "0:\tfrom all lookup local\n32765:\tfrom 10.182.0.0/24 lookup 5003\n32766:\tfrom all lookup main\n"
+
+	rules := sourceRouteRules(src, "5003")
+	assert.True(t, ruleExists(out, rules[0]))
+	assert.False(t, ruleExists(out, rules[1]))
+}
@@ -73,6 +73,22 @@ func addDefaultRoute(name string) error {
 	return nil
 }
 
+func deleteDefaultRoute(name string) (lastErr error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to get interface "+name)
+	}
+
+	id := strconv.Itoa(iface.Index)
+	for _, route := range []string{"0.0.0.0/1", "128.0.0.0/1", "::/1", "8000::/1"} {
+		if out, err := exec.Command("powershell", "-Command", "route delete "+route+" if "+id).CombinedOutput(); err != nil {
+			lastErr = errors.Wrap(err, string(out))
+		}
+	}
+
+	return lastErr
+}
+
 func interfaceInfo(name string) (id, gw string, err error) {
 	iface, err := net.InterfaceByName(name)
 	if err != nil {
@@ -112,3 +128,15 @@ func logNetworkStats() {
 		logOutputToTrace(out, err, args)
 	}
 }
+
+func pingDFArgs(ip net.IP, payload int) []string {
+	return []string{"ping", "-n", "1", "-w", "1000", "-f", "-l", strconv.Itoa(payload), ip.String()}
+}
+
+func addSourceRoute(src net.IPNet, iface string) error {
+	return ErrSourceRouteNotSupported
+}
+
+func deleteSourceRoute(src net.IPNet, iface string) error {
+	return ErrSourceRouteNotSupported
+}
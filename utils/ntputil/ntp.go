@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ntputil
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"time"
+)
+
+// epochOffset is the number of seconds between NTP (1900) and Unix (1970) epochs.
+const epochOffset = 2208988800
+
+const (
+	packetSize = 48
+	modeServer = 4
+	// maxStratum is the highest stratum of a synchronized server, 0 is a kiss-o'-death reply.
+	maxStratum = 15
+)
+
+// ErrInvalidReply is returned when the server reply can't be trusted.
+var ErrInvalidReply = errors.New("invalid NTP reply")
+
+// Offset returns the difference between the NTP server time and local clock.
+func Offset(ctx context.Context, server string) (time.Duration, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", server)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return 0, err
+		}
+	}
+
+	// Random transmit timestamp is echoed back as the origin timestamp of the reply,
+	// which rejects spoofed replies from off-path attackers.
+	req := make([]byte, packetSize)
+	req[0] = 0x23 // LI = 0, version = 4, mode = client.
+	if _, err := rand.Read(req[40:48]); err != nil {
+		return 0, err
+	}
+
+	sent := time.Now()
+	if _, err := conn.Write(req); err != nil {
+		return 0, err
+	}
+
+	res := make([]byte, packetSize)
+	n, err := conn.Read(res)
+	if err != nil {
+		return 0, err
+	}
+	received := time.Now()
+
+	if err := validate(req, res[:n]); err != nil {
+		return 0, err
+	}
+
+	serverReceived := ntpTime(res[32:40])
+	serverSent := ntpTime(res[40:48])
+	return (serverReceived.Sub(sent) + serverSent.Sub(received)) / 2, nil
+}
+
+func validate(req, res []byte) error {
+	switch {
+	case len(res) < packetSize:
+		return ErrInvalidReply
+	case res[0]&0x7 != modeServer:
+		return ErrInvalidReply
+	case res[1] == 0 || res[1] > maxStratum:
+		return ErrInvalidReply
+	case !bytes.Equal(res[24:32], req[40:48]):
+		return ErrInvalidReply
+	}
+	return nil
+}
+
+func ntpTime(b []byte) time.Time {
+	seconds := int64(binary.BigEndian.Uint32(b[0:4])) - epochOffset
+	fraction := int64(binary.BigEndian.Uint32(b[4:8]))
+	return time.Unix(seconds, fraction*int64(time.Second)>>32)
+}
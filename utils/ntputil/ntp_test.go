@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ntputil
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffset(t *testing.T) {
+	server := func(skew time.Duration, echoOrigin bool) string {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+
+		go func() {
+			req := make([]byte, packetSize)
+			_, addr, err := conn.ReadFromUDP(req)
+			if err != nil {
+				return
+			}
+			now := time.Now().Add(skew)
+			seconds := uint32(now.Unix() + epochOffset)
+			fraction := uint32((int64(now.Nanosecond()) << 32) / int64(time.Second))
+			res := make([]byte, packetSize)
+			res[0] = 0x24 // LI = 0, version = 4, mode = server.
+			res[1] = 2
+			for _, offset := range []int{32, 40} {
+				binary.BigEndian.PutUint32(res[offset:], seconds)
+				binary.BigEndian.PutUint32(res[offset+4:], fraction)
+			}
+			if echoOrigin {
+				copy(res[24:32], req[40:48])
+			}
+			conn.WriteToUDP(res, addr)
+		}()
+		return conn.LocalAddr().String()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	offset, err := Offset(ctx, server(time.Hour, true))
+	require.NoError(t, err)
+	assert.InDelta(t, time.Hour, offset, float64(time.Second))
+
+	_, err = Offset(ctx, server(time.Hour, false))
+	assert.ErrorIs(t, err, ErrInvalidReply)
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package udpbatch reads and writes UDP packets in batches, using recvmmsg/sendmmsg on Linux
+// and falling back to single packets elsewhere. Packet buffers are taken from a shared pool.
+package udpbatch
+
+import (
+	"net"
+	"sync"
+
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	// Size is the number of packets read or written with a single system call.
+	Size = 16
+	// BufferSize fits jumbo frames together with obfuscation overhead.
+	BufferSize = 9 << 10
+)
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, BufferSize)
+		return &buf
+	},
+}
+
+// GetBuffer takes a packet buffer of BufferSize from the pool.
+func GetBuffer() *[]byte {
+	return bufferPool.Get().(*[]byte)
+}
+
+// PutBuffer returns a packet buffer taken with GetBuffer to the pool.
+func PutBuffer(buf *[]byte) {
+	*buf = (*buf)[:BufferSize]
+	bufferPool.Put(buf)
+}
+
+// Conn is a UDP socket able to read and write batches of messages.
+type Conn interface {
+	ReadBatch(ms []ipv4.Message, flags int) (int, error)
+	WriteBatch(ms []ipv4.Message, flags int) (int, error)
+}
+
+// NewConn wraps UDP socket for batched reads and writes according to its address family.
+func NewConn(conn *net.UDPConn) Conn {
+	if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok && addr.IP.To4() == nil && addr.IP.To16() != nil {
+		return ipv6.NewPacketConn(conn)
+	}
+	return ipv4.NewPacketConn(conn)
+}
+
+// Batch is a set of messages reused for every batched read or write.
+type Batch struct {
+	msgs []ipv4.Message
+	bufs []*[]byte
+}
+
+// New creates a batch with pooled buffers for reading packets, Release returns them to the pool.
+func New() *Batch {
+	b := NewMessages()
+	b.bufs = make([]*[]byte, Size)
+	for i := range b.msgs {
+		b.bufs[i] = GetBuffer()
+		b.msgs[i].Buffers[0] = *b.bufs[i]
+	}
+	return b
+}
+
+// NewMessages creates a batch without own buffers for writing packets which live elsewhere.
+func NewMessages() *Batch {
+	b := &Batch{msgs: make([]ipv4.Message, Size)}
+	for i := range b.msgs {
+		b.msgs[i].Buffers = make([][]byte, 1)
+	}
+	return b
+}
+
+// Release returns batch buffers to the pool, batch must not be used afterwards.
+func (b *Batch) Release() {
+	for i, buf := range b.bufs {
+		PutBuffer(buf)
+		b.bufs[i] = nil
+	}
+	b.bufs = nil
+}
+
+// Read reads a batch of packets into batch buffers.
+func (b *Batch) Read(conn Conn) (int, error) {
+	for i := range b.msgs {
+		b.msgs[i].Buffers[0] = *b.bufs[i]
+	}
+	return conn.ReadBatch(b.msgs, 0)
+}
+
+// Packet returns payload of the i-th message of the last read.
+func (b *Batch) Packet(i int) []byte {
+	return b.msgs[i].Buffers[0][:b.msgs[i].N]
+}
+
+// Addr returns source address of the i-th message of the last read.
+func (b *Batch) Addr(i int) *net.UDPAddr {
+	addr, _ := b.msgs[i].Addr.(*net.UDPAddr)
+	return addr
+}
+
+// Buffer returns empty i-th buffer to append a packet to.
+func (b *Batch) Buffer(i int) []byte {
+	return (*b.bufs[i])[:0]
+}
+
+// Set prepares i-th message for writing, nil addr is used for connected sockets.
+func (b *Batch) Set(i int, packet []byte, addr *net.UDPAddr) {
+	b.msgs[i].Buffers[0] = packet
+	if addr == nil {
+		b.msgs[i].Addr = nil
+	} else {
+		b.msgs[i].Addr = addr
+	}
+}
+
+// SetAddr changes destination address of the i-th message.
+func (b *Batch) SetAddr(i int, addr *net.UDPAddr) {
+	b.msgs[i].Addr = addr
+}
+
+// Write writes the first n messages, retrying partial batch writes.
+func (b *Batch) Write(conn Conn, n int) error {
+	msgs := b.msgs[:n]
+	for len(msgs) > 0 {
+		written, err := conn.WriteBatch(msgs, 0)
+		if err != nil {
+			return err
+		}
+		msgs = msgs[written:]
+	}
+	return nil
+}